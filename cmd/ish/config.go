@@ -0,0 +1,109 @@
+// ABOUTME: The config command, which reads and writes per-plugin config files.
+// ABOUTME: Keys are written plugin.setting, e.g. github.seed_density.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and write plugin configuration",
+		Long: `Read and write plugin config files in ~/.config/ish/plugins/{plugin}.json,
+or $ISH_CONFIG_DIR/plugins/{plugin}.json when ISH_CONFIG_DIR is set.
+
+Settings:
+  enabled         true or false; disabled plugins serve no routes and are
+                  skipped by 'ish seed' unless named (default: true)
+  seed_density    small, medium, or large (default: medium)
+  anything else   a custom setting passed to the plugin as a string
+
+The server reads these files at startup.
+
+Examples:
+  ish config set github.seed_density large
+  ish config get github.seed_density
+  ish config set slack.enabled false`,
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <plugin.setting>",
+		Short: "Print a plugin setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := core.ConfigDir()
+			if err != nil {
+				return err
+			}
+			return configGet(cmd.OutOrStdout(), dir, args[0])
+		},
+	}
+	setCmd := &cobra.Command{
+		Use:   "set <plugin.setting> <value>",
+		Short: "Change a plugin setting",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := core.ConfigDir()
+			if err != nil {
+				return err
+			}
+			return configSet(dir, args[0], args[1])
+		},
+	}
+
+	configCmd.AddCommand(getCmd, setCmd)
+	return configCmd
+}
+
+// splitConfigKey splits plugin.setting, checking that the plugin exists
+func splitConfigKey(key string) (plugin, setting string, err error) {
+	plugin, setting, ok := strings.Cut(key, ".")
+	if !ok || plugin == "" || setting == "" {
+		return "", "", fmt.Errorf("key must be plugin.setting, got %q", key)
+	}
+	if _, ok := core.Get(plugin); !ok {
+		return "", "", fmt.Errorf("unknown plugin %q", plugin)
+	}
+	return plugin, setting, nil
+}
+
+// configGet prints one setting from a plugin's config file under dir
+func configGet(out io.Writer, dir, key string) error {
+	plugin, setting, err := splitConfigKey(key)
+	if err != nil {
+		return err
+	}
+	config, err := core.LoadPluginConfig(dir, plugin)
+	if err != nil {
+		return err
+	}
+	value, ok := config.Get(setting)
+	if !ok {
+		return fmt.Errorf("%s is not set", key)
+	}
+	fmt.Fprintln(out, value)
+	return nil
+}
+
+// configSet changes one setting in a plugin's config file under dir
+func configSet(dir, key, value string) error {
+	plugin, setting, err := splitConfigKey(key)
+	if err != nil {
+		return err
+	}
+	config, err := core.LoadPluginConfig(dir, plugin)
+	if err != nil {
+		return err
+	}
+	if err := config.Set(setting, value); err != nil {
+		return err
+	}
+	return core.SavePluginConfig(dir, plugin, config)
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Tests for the `ish config` command and plugin config loading at startup.
+// ABOUTME: Verifies get/set round trips, key validation, and that disabled plugins serve no routes.
+
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func TestConfig_SetAndGet(t *testing.T) {
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	if err := configGet(&out, dir, "github.seed_density"); err != nil {
+		t.Fatalf("configGet() error = %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != core.DefaultSeedDensity {
+		t.Errorf("default seed_density = %q, want %q", got, core.DefaultSeedDensity)
+	}
+
+	if err := configSet(dir, "github.seed_density", "large"); err != nil {
+		t.Fatalf("configSet() error = %v", err)
+	}
+	out.Reset()
+	configGet(&out, dir, "github.seed_density")
+	if got := strings.TrimSpace(out.String()); got != "large" {
+		t.Errorf("seed_density = %q, want large", got)
+	}
+
+	config, err := core.LoadPluginConfig(dir, "github")
+	if err != nil || config.SeedDensity != "large" || !config.Enabled {
+		t.Errorf("LoadPluginConfig() = %+v, %v, want seed_density large and enabled", config, err)
+	}
+
+	for _, tt := range []struct{ key, value string }{
+		{"seed_density", "large"},
+		{"nope.seed_density", "large"},
+		{"github.seed_density", "huge"},
+		{"github.enabled", "sometimes"},
+	} {
+		if err := configSet(dir, tt.key, tt.value); err == nil {
+			t.Errorf("configSet(%q, %q) should fail", tt.key, tt.value)
+		}
+	}
+}
+
+func TestServer_DisabledPluginServesNoRoutes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ISH_CONFIG_DIR", dir)
+	dbPath := filepath.Join(t.TempDir(), "config.db")
+
+	userStatus := func() int {
+		srv, err := newServer(t.Context(), dbPath, nil)
+		if err != nil {
+			t.Fatalf("newServer() error = %v", err)
+		}
+		req := httptest.NewRequest("GET", "/user", nil)
+		req.Header.Set("Authorization", "Bearer ghp_config")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := userStatus(); code == http.StatusNotFound {
+		t.Fatal("GET /user with GitHub enabled = 404, want the route served")
+	}
+
+	if err := configSet(dir, "github.enabled", "false"); err != nil {
+		t.Fatalf("configSet() error = %v", err)
+	}
+	if code := userStatus(); code != http.StatusNotFound {
+		t.Errorf("GET /user with GitHub disabled = %d, want 404", code)
+	}
+}
@@ -0,0 +1,73 @@
+// ABOUTME: `ish seed --from` creates the exact records a JSON fixture file describes.
+// ABOUTME: Each plugin that implements core.FixtureImporter takes the sections it owns.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// seedFrom is the fixture file given to `ish seed --from`
+var seedFrom string
+
+// seedFixture imports the fixture at path through every plugin that can
+// import fixtures, or only the named one, printing the records each created
+func seedFixture(out io.Writer, db *sql.DB, path, pluginFilter string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fixture, err := core.ParseFixture(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	initPlugins(db)
+
+	imported, total := 0, 0
+	for _, plugin := range core.All() {
+		if pluginFilter != "" && plugin.Name() != pluginFilter {
+			continue
+		}
+		importer, ok := plugin.(core.FixtureImporter)
+		if !ok {
+			continue
+		}
+		data, err := importer.ImportFixture(context.Background(), fixture)
+		if err != nil {
+			return fmt.Errorf("%s: %w", plugin.Name(), err)
+		}
+		imported++
+
+		kinds := make([]string, 0, len(data.Records))
+		for kind := range data.Records {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		var counts []string
+		for _, kind := range kinds {
+			if n := data.Records[kind]; n > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", n, kind))
+				total += n
+			}
+		}
+		if len(counts) > 0 {
+			fmt.Fprintf(out, "%s: %s\n", plugin.Name(), strings.Join(counts, ", "))
+		}
+	}
+
+	if pluginFilter != "" && imported == 0 {
+		return fmt.Errorf("plugin '%s' not found or can't import fixtures", pluginFilter)
+	}
+	fmt.Fprintf(out, "Imported %d records from %s\n", total, path)
+	return nil
+}
@@ -0,0 +1,129 @@
+// ABOUTME: Tests for `ish seed --from`.
+// ABOUTME: Checks that a fixture creates exactly the records it describes and that bad fixtures write nothing.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/store"
+)
+
+// writeFixture writes a fixture file and returns its path
+func writeFixture(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return path
+}
+
+// gmailSubjects returns the Subject header of every stored Gmail message
+func gmailSubjects(t *testing.T, s *store.Store) []string {
+	t.Helper()
+	rows, err := s.GetDB().Query("SELECT payload FROM gmail_messages")
+	if err != nil {
+		t.Fatalf("query messages: %v", err)
+	}
+	defer rows.Close()
+
+	var subjects []string
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			t.Fatalf("scan message: %v", err)
+		}
+		var payload struct {
+			Headers []struct{ Name, Value string } `json:"headers"`
+		}
+		json.Unmarshal([]byte(raw), &payload)
+		for _, h := range payload.Headers {
+			if h.Name == "Subject" {
+				subjects = append(subjects, h.Value)
+			}
+		}
+	}
+	slices.Sort(subjects)
+	return subjects
+}
+
+func TestSeedFixture(t *testing.T) {
+	s, err := store.New(filepath.Join(t.TempDir(), "fixture.db"))
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	defer s.Close()
+
+	path := writeFixture(t, `{
+		"emails": [
+			{"from": "bob@example.com", "to": "me@example.com", "subject": "Invoice #1042 overdue", "body": "Please pay."},
+			{"from": "carol@example.com", "subject": "Lunch on Friday?", "labels": ["INBOX", "UNREAD"], "date": "2025-03-01T12:00:00Z"}
+		],
+		"events": [{"summary": "Planning", "start": "2025-03-03T09:00:00Z", "end": "2025-03-03T10:00:00Z"}],
+		"contacts": [{"name": "Bob", "email": "bob@example.com"}, {"name": "Carol", "email": "carol@example.com"}],
+		"repos": [{"owner": "acme", "name": "api"}],
+		"issues": [
+			{"repo": "acme/api", "title": "Crash on startup"},
+			{"repo": "acme/api", "title": "Old bug", "state": "closed", "author": "dana"}
+		]
+	}`)
+
+	var out strings.Builder
+	if err := seedFixture(&out, s.GetDB(), path, ""); err != nil {
+		t.Fatalf("seedFixture() error = %v", err)
+	}
+
+	want := []string{"Invoice #1042 overdue", "Lunch on Friday?"}
+	if got := gmailSubjects(t, s); !slices.Equal(got, want) {
+		t.Errorf("Gmail subjects = %q, want exactly %q", got, want)
+	}
+
+	for _, line := range []string{
+		"google: 2 contacts, 2 emails, 1 events",
+		"github: 2 issues, 1 repos",
+		"Imported 8 records",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("output missing %q:\n%s", line, out.String())
+		}
+	}
+
+	var closed int
+	s.GetDB().QueryRow(`SELECT COUNT(*) FROM github_issues i JOIN github_users u ON u.id = i.user_id
+		WHERE i.title = 'Old bug' AND i.state = 'closed' AND u.login = 'dana'`).Scan(&closed)
+	if closed != 1 {
+		t.Error("expected the closed issue to be authored by dana")
+	}
+}
+
+func TestSeedFixture_Invalid(t *testing.T) {
+	s, err := store.New(filepath.Join(t.TempDir(), "fixture.db"))
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	defer s.Close()
+
+	path := writeFixture(t, `{"emails": [{"from": "bob@example.com", "subject": "Kept out"}, {"subject": "No sender"}]}`)
+	err = seedFixture(&strings.Builder{}, s.GetDB(), path, "")
+	if err == nil || !strings.Contains(err.Error(), "emails[1]: from is required") {
+		t.Fatalf("seedFixture() error = %v, want a validation error", err)
+	}
+	initPlugins(s.GetDB())
+	if got := gmailSubjects(t, s); len(got) != 0 {
+		t.Errorf("expected nothing written for an invalid fixture, got %q", got)
+	}
+
+	path = writeFixture(t, `{"issues": [{"repo": "nobody/nothing", "title": "Orphan"}]}`)
+	if err := seedFixture(&strings.Builder{}, s.GetDB(), path, "github"); err == nil || !strings.Contains(err.Error(), "repository nobody/nothing not found") {
+		t.Errorf("seedFixture() error = %v, want a missing repository error", err)
+	}
+	if err := seedFixture(&strings.Builder{}, s.GetDB(), path, "twilio"); err == nil {
+		t.Error("expected an error for a plugin that can't import fixtures")
+	}
+}
@@ -0,0 +1,150 @@
+// ABOUTME: `ish inspect` prints stored records from the command line.
+// ABOUTME: Single records use each plugin's API response format; lists print as tables.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+)
+
+// inspectListLimit caps the rows printed when listing a resource
+const inspectListLimit = 50
+
+var (
+	inspectPlugin   string
+	inspectResource string
+	inspectID       string
+	inspectOutput   string
+)
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	if inspectOutput != "json" && inspectOutput != "yaml" {
+		return fmt.Errorf("invalid --output %q: use json or yaml", inspectOutput)
+	}
+
+	var err error
+	dbPath, err = validateAndCleanDBPath(dbPath)
+	if err != nil {
+		return err
+	}
+
+	s, err := store.New(dbPath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	initPlugins(s.GetDB())
+
+	return inspect(cmd.OutOrStdout(), inspectPlugin, inspectResource, inspectID, inspectOutput)
+}
+
+// inspect prints one record when id is set, the records of a resource when
+// only plugin and resource are set, and the inspectable resources otherwise
+func inspect(out io.Writer, pluginName, resourceName, id, output string) error {
+	if pluginName == "" {
+		if resourceName != "" || id != "" {
+			return fmt.Errorf("--resource and --id require --plugin")
+		}
+		return inspectSummary(out, core.All())
+	}
+
+	plugin, ok := core.Get(pluginName)
+	if !ok {
+		return fmt.Errorf("unknown plugin %q", pluginName)
+	}
+	if resourceName == "" {
+		if id != "" {
+			return fmt.Errorf("--id requires --resource")
+		}
+		return inspectSummary(out, []core.Plugin{plugin})
+	}
+
+	resource, ok := core.ResolveResource(plugin.Schema(), resourceName)
+	if !ok {
+		return fmt.Errorf("plugin %s has no resource %q", plugin.Name(), resourceName)
+	}
+	if id == "" {
+		return inspectList(out, plugin, resource)
+	}
+
+	inspector, ok := plugin.(core.Inspector)
+	if !ok {
+		return fmt.Errorf("plugin %s does not support inspect", plugin.Name())
+	}
+	record, err := inspector.Inspect(resource.Slug, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no %s %s with ID %q", plugin.Name(), resource.Slug, id)
+	}
+	if err != nil {
+		return fmt.Errorf("inspect %s %s %s: %w", plugin.Name(), resource.Slug, id, err)
+	}
+
+	if output == "yaml" {
+		return writeYAML(out, record)
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// inspectSummary lists each plugin's resources
+func inspectSummary(out io.Writer, plugins []core.Plugin) error {
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name() < plugins[j].Name() })
+
+	var rows [][]string
+	for _, plugin := range plugins {
+		for _, resource := range plugin.Schema().Resources {
+			rows = append(rows, []string{plugin.Name(), resource.Slug, resource.Name})
+		}
+	}
+	writeTable(out, []string{"plugin", "resource", "name"}, rows)
+	return nil
+}
+
+// inspectList prints a resource's records using its admin list columns
+func inspectList(out io.Writer, plugin core.Plugin, resource core.ResourceSchema) error {
+	provider, ok := plugin.(core.DataProvider)
+	if !ok {
+		return fmt.Errorf("plugin %s does not support listing %s", plugin.Name(), resource.Slug)
+	}
+	items, err := provider.ListResources(context.Background(), resource.Slug, core.ListOptions{Limit: inspectListLimit})
+	if err != nil {
+		return fmt.Errorf("list %s %s: %w", plugin.Name(), resource.Slug, err)
+	}
+
+	// Lead with the ID --id expects when the list columns leave it out
+	columns := resource.ListColumns
+	if len(items) > 0 && !slices.Contains(columns, "id") {
+		if _, ok := items[0]["id"]; ok {
+			columns = append([]string{"id"}, columns...)
+		}
+	}
+	if len(columns) == 0 {
+		columns = []string{"id"}
+	}
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = formatCell(item[column])
+		}
+		rows = append(rows, row)
+	}
+	writeTable(out, columns, rows)
+	fmt.Fprintf(out, "(%d %s)\n", len(rows), plural(int64(len(rows)), "row"))
+	return nil
+}
@@ -0,0 +1,219 @@
+// ABOUTME: Tests for `ish inspect`.
+// ABOUTME: Checks inspected records against live API responses and the YAML and table output.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/auth"
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// newInspectDB returns a database with the named plugins seeded
+func newInspectDB(t *testing.T, plugins ...string) *sql.DB {
+	t.Helper()
+	s, err := store.New(filepath.Join(t.TempDir(), "inspect.db"))
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	initPlugins(s.GetDB())
+
+	for _, name := range plugins {
+		plugin, _ := core.Get(name)
+		if _, err := plugin.Seed(context.Background(), "small"); err != nil {
+			t.Fatalf("seed %s: %v", name, err)
+		}
+	}
+	return s.GetDB()
+}
+
+// runInspectCommand calls inspect and returns its output
+func runInspectCommand(t *testing.T, plugin, resource, id, output string) string {
+	t.Helper()
+	var out strings.Builder
+	if err := inspect(&out, plugin, resource, id, output); err != nil {
+		t.Fatalf("inspect(%q, %q, %q) error = %v", plugin, resource, id, err)
+	}
+	return out.String()
+}
+
+// apiResponse serves one authenticated GET through a plugin's routes and decodes the body
+func apiResponse(t *testing.T, pluginName, path, token string) any {
+	t.Helper()
+	plugin, _ := core.Get(pluginName)
+	r := chi.NewRouter()
+	r.Use(auth.Middleware)
+	plugin.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d, body %s", path, w.Code, w.Body.String())
+	}
+
+	var body any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	return body
+}
+
+func decodeInspectJSON(t *testing.T, out string) any {
+	t.Helper()
+	var record any
+	if err := json.Unmarshal([]byte(out), &record); err != nil {
+		t.Fatalf("inspect output is not JSON: %v\n%s", err, out)
+	}
+	return record
+}
+
+func TestInspect_GmailMessageMatchesAPI(t *testing.T) {
+	db := newInspectDB(t, "google")
+
+	var id, userID string
+	if err := db.QueryRow("SELECT id, user_id FROM gmail_messages ORDER BY id LIMIT 1").Scan(&id, &userID); err != nil {
+		t.Fatalf("find message: %v", err)
+	}
+
+	out := runInspectCommand(t, "google", "message", id, "json")
+	if !strings.HasPrefix(out, "{\n  \"") {
+		t.Errorf("output = %q, want indented JSON", out)
+	}
+	got := decodeInspectJSON(t, out)
+	want := apiResponse(t, "google", "/gmail/v1/users/me/messages/"+id, "user:"+userID)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inspect = %v\nAPI     = %v", got, want)
+	}
+	if got.(map[string]any)["threadId"] == nil {
+		t.Errorf("inspect = %v, want Gmail's threadId key", got)
+	}
+}
+
+func TestInspect_GitHubIssueMatchesAPI(t *testing.T) {
+	db := newInspectDB(t, "github")
+
+	var id, number, userID int64
+	var fullName string
+	err := db.QueryRow(`SELECT i.id, i.number, r.full_name, r.owner_id FROM github_issues i
+		JOIN github_repositories r ON r.id = i.repo_id ORDER BY i.id LIMIT 1`).Scan(&id, &number, &fullName, &userID)
+	if err != nil {
+		t.Fatalf("find issue: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO github_tokens (token, user_id) VALUES ('ghp_inspect', ?)", userID); err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+
+	got := decodeInspectJSON(t, runInspectCommand(t, "github", "issue", strconv.FormatInt(id, 10), "json"))
+	want := apiResponse(t, "github", "/repos/"+fullName+"/issues/"+strconv.FormatInt(number, 10), "ghp_inspect")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inspect = %v\nAPI     = %v", got, want)
+	}
+}
+
+func TestInspect_YAMLOutput(t *testing.T) {
+	db := newInspectDB(t, "github")
+
+	var id int64
+	var title string
+	if err := db.QueryRow("SELECT id, title FROM github_issues ORDER BY id LIMIT 1").Scan(&id, &title); err != nil {
+		t.Fatalf("find issue: %v", err)
+	}
+
+	out := runInspectCommand(t, "github", "issues", strconv.FormatInt(id, 10), "yaml")
+	for _, want := range []string{"id: " + strconv.FormatInt(id, 10) + "\n", "title: " + title + "\n", "user:\n  id: "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %q, want %q", out, want)
+		}
+	}
+	if strings.Contains(out, "{\n") {
+		t.Errorf("output = %q, want YAML, not JSON", out)
+	}
+}
+
+func TestInspect_WithoutIDPrintsTables(t *testing.T) {
+	newInspectDB(t, "github")
+
+	out := runInspectCommand(t, "github", "issues", "", "json")
+	if !strings.Contains(out, "| id ") || !strings.Contains(out, "| title ") {
+		t.Errorf("output = %q, want a table with id and title columns", out)
+	}
+	if !strings.Contains(out, " rows)") {
+		t.Errorf("output = %q, want a row count", out)
+	}
+
+	out = runInspectCommand(t, "github", "", "", "json")
+	if !strings.Contains(out, "| github ") || !strings.Contains(out, "| pull_requests ") {
+		t.Errorf("output = %q, want the plugin's resources", out)
+	}
+}
+
+func TestInspect_Errors(t *testing.T) {
+	newInspectDB(t)
+
+	tests := []struct {
+		name                 string
+		plugin, resource, id string
+		want                 string
+	}{
+		{"unknown plugin", "nope", "", "", `unknown plugin "nope"`},
+		{"unknown resource", "github", "widgets", "", `no resource "widgets"`},
+		{"id without resource", "github", "", "1", "--id requires --resource"},
+		{"missing record", "github", "issue", "999", `no github issues with ID "999"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := inspect(&strings.Builder{}, tt.plugin, tt.resource, tt.id, "json")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("inspect() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	var out strings.Builder
+	value := map[string]any{
+		"name":   "plain",
+		"empty":  "",
+		"number": "42",
+		"flag":   "yes",
+		"colon":  "a: b",
+		"list":   []any{"x", map[string]any{"k": 1, "l": []any{}}},
+		"nested": map[string]any{"ok": true, "none": nil},
+	}
+	if err := writeYAML(&out, value); err != nil {
+		t.Fatalf("writeYAML() error = %v", err)
+	}
+
+	want := `colon: "a: b"
+empty: ""
+flag: "yes"
+list:
+  - x
+  - k: 1
+    l: []
+name: plain
+nested:
+  none: null
+  ok: true
+number: "42"
+`
+	if out.String() != want {
+		t.Errorf("writeYAML() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -5,35 +5,56 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/spf13/cobra"
 	"github.com/2389/ish/internal/admin"
 	"github.com/2389/ish/internal/auth"
+	"github.com/2389/ish/internal/cors"
 	"github.com/2389/ish/internal/logging"
+	"github.com/2389/ish/internal/migrations"
+	"github.com/2389/ish/internal/pretty"
 	"github.com/2389/ish/internal/store"
 	"github.com/2389/ish/plugins/core"
 	_ "github.com/2389/ish/plugins/discord"       // Register Discord plugin
 	_ "github.com/2389/ish/plugins/github"        // Register GitHub plugin
 	_ "github.com/2389/ish/plugins/google"        // Register Google plugin
 	_ "github.com/2389/ish/plugins/homeassistant" // Register Home Assistant plugin
+	_ "github.com/2389/ish/plugins/hubspot"       // Register HubSpot plugin
+	_ "github.com/2389/ish/plugins/jira"          // Register Jira plugin
+	_ "github.com/2389/ish/plugins/linear"        // Register Linear plugin
+	_ "github.com/2389/ish/plugins/notion"        // Register Notion plugin
 	_ "github.com/2389/ish/plugins/oauth"         // Register OAuth plugin
+	_ "github.com/2389/ish/plugins/salesforce"    // Register Salesforce plugin
 	_ "github.com/2389/ish/plugins/sendgrid"      // Register SendGrid plugin
+	_ "github.com/2389/ish/plugins/slack"         // Register Slack plugin
 	_ "github.com/2389/ish/plugins/twilio"        // Register Twilio plugin
+	_ "github.com/2389/ish/plugins/zendesk"       // Register Zendesk plugin
 )
 
 var (
-	port   string
-	dbPath string
+	port    string
+	dbPath  string
+	useTLS  bool
+	tlsCert string
+	tlsKey  string
+	dryRun  bool
 )
 
 func main() {
@@ -79,18 +100,43 @@ The server provides:
   • Health check at http://localhost:PORT/healthz
   • Google API Discovery Service
 
+TLS:
+  Plain HTTP is the default. --tls serves HTTPS with a self-signed certificate
+  generated at startup and prints the CA to trust; --cert/--key load your own.
+
 Authentication:
   Use Bearer tokens in the format: Bearer user:USERNAME
   Example: curl -H "Authorization: Bearer user:me" http://localhost:9000/gmail/v1/users/me/messages
+  Set ISH_DEFAULT_USER=me to call the same endpoints without a token
 
 Environment Variables:
   ISH_PORT          Server port (default: 9000)
   OPENAI_API_KEY    Enable AI-powered features
-  ISH_AUTO_REPLY    Enable auto-reply (true/false)`,
+  ISH_AUTO_REPLY    Enable auto-reply (true/false)
+  ISH_AUTO_REPLY_DOMAINS  Domains whose addresses auto-reply, * for any (default: example.com)
+  ISH_AUTO_REPLY_TEMPLATE  Go template for auto-reply bodies ({{.From}}, {{.To}}, {{.Subject}}, {{.Body}})
+  ISH_FROZEN_TIME   Freeze generated timestamps at an RFC 3339 time
+  ISH_DEFAULT_USER  User for requests without an Authorization header
+  ISH_CORS_ORIGINS  Comma-separated origins allowed by CORS (default: *)
+  ISH_LOG_RETENTION_DAYS  Delete request logs older than this, 0 to keep (default: 7)
+  ISH_MAX_LOG_ROWS  Keep at most this many request logs, 0 for no cap (default: 100000)
+  ISH_WEBHOOK_TIMEOUT  Give up on a webhook delivery after this long (default: 10s)
+  ISH_ERROR_RATE_{PLUGIN}  Fraction of a plugin's requests to fail with 500, e.g. ISH_ERROR_RATE_GITHUB=0.1
+  ISH_RANDOM_SEED   Seed for random choices such as injected errors, for reproducible runs
+  ISH_CONFIG_DIR    Directory holding plugins/{plugin}.json configs (default: ~/.config/ish)
+  ISH_LOG_FORMAT    Console log format: text or json (default: text)
+  ISH_LOG_PLUGINS   Comma-separated plugins whose requests are logged (default: all)
+  ISH_LOG_MIN_DURATION_MS  Skip logging requests faster than this (default: 0)
+  ISH_LOG_IGNORE_PATHS  Comma-separated paths never logged, e.g. /healthz,/favicon.ico
+  ISH_BASE_PATH     Serve every route under this prefix, e.g. /ish
+  ISH_PAGE_TOKEN_SECRET  Key that signs Google page tokens (default: generated and kept in the database)`,
 		RunE: runServe,
 	}
 	serveCmd.Flags().StringVarP(&port, "port", "p", getEnv("ISH_PORT", "9000"), "Port to listen on")
 	serveCmd.Flags().StringVarP(&dbPath, "db", "d", defaultDBPath, "Database path")
+	serveCmd.Flags().BoolVar(&useTLS, "tls", false, "Serve HTTPS with a generated self-signed certificate")
+	serveCmd.Flags().StringVar(&tlsCert, "cert", "", "TLS certificate file (implies --tls)")
+	serveCmd.Flags().StringVar(&tlsKey, "key", "", "TLS private key file (implies --tls)")
 
 	seedCmd := &cobra.Command{
 		Use:   "seed [plugin]",
@@ -105,9 +151,10 @@ Usage:
   ish seed              # Seed all plugins with test data
   ish seed google       # Seed only Google plugin
   ish seed github       # Seed only GitHub plugin
+  ish seed --from fixtures.json  # Create exactly the records in a fixture file
 
 Available Plugins:
-  google, github, twilio, discord, sendgrid, homeassistant, oauth
+  google, github, twilio, discord, sendgrid, homeassistant, slack, jira, linear, notion, salesforce, hubspot, zendesk, oauth
 
 Data Generated:
   • Gmail: 8 messages, threads, labels
@@ -119,11 +166,17 @@ Data Generated:
   • SendGrid: Email accounts, API keys, messages
   • Home Assistant: Devices, entities, states
 
+Fixture Files:
+  --from takes a JSON file with any of "emails", "events", "contacts"
+  (Google) and "repos", "issues" (GitHub). Unknown fields and incomplete
+  records are rejected before anything is written.
+
 Note: Seed is not idempotent. Use 'ish reset' to clear data before reseeding.`,
 		RunE: runSeed,
 		Args: cobra.MaximumNArgs(1),
 	}
 	seedCmd.Flags().StringVarP(&dbPath, "db", "d", defaultDBPath, "Database path")
+	seedCmd.Flags().StringVar(&seedFrom, "from", "", "Seed exactly the records in a JSON fixture file")
 
 	resetCmd := &cobra.Command{
 		Use:   "reset",
@@ -146,7 +199,69 @@ Warning: This permanently deletes all data in the database!`,
 	}
 	resetCmd.Flags().StringVarP(&dbPath, "db", "d", defaultDBPath, "Database path")
 
-	rootCmd.AddCommand(serveCmd, seedCmd, resetCmd)
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending database migrations",
+		Long: `Apply any schema migrations that have not yet run against the database.
+
+Migrations also run automatically whenever the server or seed command opens
+the database; this command runs them on their own or previews them.
+
+Usage:
+  ish migrate            # Apply pending migrations
+  ish migrate --dry-run  # List pending migrations without applying them`,
+		RunE: runMigrate,
+	}
+	migrateCmd.Flags().StringVarP(&dbPath, "db", "d", defaultDBPath, "Database path")
+	migrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print pending migrations without applying them")
+
+	shellCmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Start an interactive shell for queries and plugin commands",
+		Long: `Start an interactive shell against the ISH database.
+
+Statements ending in ';' run as SQL and print their results as a table. Only
+SELECT, INSERT, UPDATE, and DELETE are allowed, and only against plugin
+tables, request_logs, and audit_log, so a typo can't drop a table.
+
+Commands:
+  select * from gmail_messages limit 5;   # Run SQL (may span lines)
+  plugin github seed                      # Seed one plugin (optionally: small, medium, large)
+  plugin google reset                     # Delete one plugin's data
+  count gmail                             # Row counts for a plugin or resource type
+  tables                                  # List queryable tables
+  history                                 # Show previous input
+  exit                                    # Leave the shell
+
+Input is saved to ~/.ish_history.`,
+		RunE: runShell,
+	}
+	shellCmd.Flags().StringVarP(&dbPath, "db", "d", defaultDBPath, "Database path")
+
+	inspectCmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Print stored records as the plugin APIs return them",
+		Long: `Print records from the ISH database without running the server.
+
+With --id, prints one record in the same format its API returns it, as JSON
+or YAML. With --plugin and --resource only, prints a table of that resource's
+records. With neither, lists the resources each plugin can inspect.
+
+Examples:
+  ish inspect                                          # List plugins and resources
+  ish inspect --plugin github --resource issues        # Table of GitHub issues
+  ish inspect --plugin github --resource issue --id 1  # One issue as JSON
+  ish inspect --plugin google --resource messages --id msg_1 --output yaml`,
+		Args: cobra.NoArgs,
+		RunE: runInspect,
+	}
+	inspectCmd.Flags().StringVarP(&dbPath, "db", "d", defaultDBPath, "Database path")
+	inspectCmd.Flags().StringVar(&inspectPlugin, "plugin", "", "Plugin name (e.g. github)")
+	inspectCmd.Flags().StringVar(&inspectResource, "resource", "", "Resource type, singular or plural (e.g. issue)")
+	inspectCmd.Flags().StringVar(&inspectID, "id", "", "Record ID to print")
+	inspectCmd.Flags().StringVarP(&inspectOutput, "output", "o", "json", "Output format for --id: json or yaml")
+
+	rootCmd.AddCommand(serveCmd, seedCmd, resetCmd, migrateCmd, shellCmd, inspectCmd, newConfigCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -200,42 +315,215 @@ func runServe(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	srv, err := newServer(dbPath)
+	logger, err := logging.FromEnv(os.Stderr)
+	if err != nil {
+		return err
+	}
+	if _, ok := logger.(*logging.JSON); ok {
+		log.SetFlags(0)
+		log.SetOutput(logging.Writer(logger))
+	}
+
+	if err := applyFrozenClock(); err != nil {
+		return err
+	}
+	webhookTimeout, err := core.WebhookTimeoutFromEnv()
+	if err != nil {
+		return err
+	}
+	core.SetWebhookTimeout(webhookTimeout)
+	if user := auth.DefaultUserFromEnv(); user != "" {
+		log.Printf("Requests without credentials act as %q", user)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv, err := newServer(ctx, dbPath, logger)
 	if err != nil {
 		return err
 	}
 
+	httpServer := newHTTPServer(srv)
+	scheme := "http"
+	if useTLS || tlsCert != "" || tlsKey != "" {
+		config, caPEM, err := newTLSConfig(tlsCert, tlsKey)
+		if err != nil {
+			return err
+		}
+		httpServer.TLSConfig = config
+		scheme = "https"
+		if caPEM != nil {
+			log.Printf("Serving HTTPS with a generated certificate for localhost; trust this CA:")
+			fmt.Print(string(caPEM))
+		}
+	}
+
 	addr := ":" + port
-	log.Printf("ISH server listening on %s", addr)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	basePath, _ := core.BasePathFromEnv()
+	log.Printf("ISH server listening on %s://localhost%s%s", scheme, addr, basePath)
 	log.Printf("Database: %s", dbPath)
-	return http.ListenAndServe(addr, srv)
+	return serve(ctx, httpServer, ln)
+}
+
+// shutdownTimeout bounds how long shutdown waits for requests and webhook deliveries
+const shutdownTimeout = 30 * time.Second
+
+// newHTTPServer wraps the handler in a server that speaks HTTP/2 over TLS when
+// TLSConfig is set and over cleartext (h2c) otherwise, so HTTP/2 clients work
+// either way
+func newHTTPServer(handler http.Handler) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Server{
+		Handler:           handler,
+		Protocols:         protocols,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// serve runs srv on ln until ctx is cancelled, then stops accepting
+// connections, waits for in-flight requests, and stops plugin background
+// workers so pending webhook deliveries aren't cut off. Requests see
+// core.ShuttingDown close as shutdown starts, so streams don't hold it up.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener) error {
+	shuttingDown := make(chan struct{})
+	srv.BaseContext = func(net.Listener) context.Context {
+		return core.WithShutdownSignal(context.Background(), shuttingDown)
+	}
+	srv.RegisterOnShutdown(func() { close(shuttingDown) })
+
+	errc := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			// Certificates come from TLSConfig, so no files are passed
+			errc <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, waiting up to %s for requests to finish", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := srv.Shutdown(shutdownCtx)
+	if pluginErr := core.ShutdownAll(shutdownCtx); pluginErr != nil {
+		err = errors.Join(err, pluginErr)
+	}
+	if serveErr := <-errc; serveErr != http.ErrServerClosed {
+		err = errors.Join(err, serveErr)
+	}
+	return err
+}
+
+// applyFrozenClock freezes store timestamps when ISH_FROZEN_TIME is set, for
+// reproducible demos and screenshots.
+func applyFrozenClock() error {
+	clock, err := core.ClockFromEnv()
+	if err != nil {
+		return err
+	}
+	if clock != nil {
+		core.SetClock(clock)
+		log.Printf("Clock frozen at %s", clock.Now().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// logRetentionInterval is how often request logs are pruned after startup
+const logRetentionInterval = time.Hour
+
+// logRetentionFromEnv reads ISH_LOG_RETENTION_DAYS and ISH_MAX_LOG_ROWS
+func logRetentionFromEnv() (store.LogRetention, error) {
+	days, err := strconv.Atoi(getEnv("ISH_LOG_RETENTION_DAYS", "7"))
+	if err != nil || days < 0 {
+		return store.LogRetention{}, fmt.Errorf("ISH_LOG_RETENTION_DAYS must be a non-negative number of days")
+	}
+	maxRows, err := strconv.Atoi(getEnv("ISH_MAX_LOG_ROWS", "100000"))
+	if err != nil || maxRows < 0 {
+		return store.LogRetention{}, fmt.Errorf("ISH_MAX_LOG_ROWS must be a non-negative number")
+	}
+	return store.LogRetention{
+		MaxAge:   time.Duration(days) * 24 * time.Hour,
+		MaxRows:  maxRows,
+		Interval: logRetentionInterval,
+	}, nil
 }
 
-func newServer(dbPath string) (http.Handler, error) {
+// newServer builds the router; logger writes a console line per request when non-nil.
+// The database watchdog runs until ctx is cancelled.
+func newServer(ctx context.Context, dbPath string, logger logging.Logger) (http.Handler, error) {
+	retention, err := logRetentionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	logOptions, err := logging.OptionsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	logOptions.Logger = logger
+	logOptions.Stream = logging.NewStream()
+	basePath, err := core.BasePathFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	s, err := store.New(dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open store: %w", err)
 	}
 
+	// Prune request logs now and hourly so long-running servers don't grow without bound
+	if err := s.StartLogRetention(retention); err != nil {
+		return nil, err
+	}
+
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(logging.Middleware(s))
+	// CORS answers preflight requests, so it must run before auth
+	r.Use(cors.Middleware(cors.OriginsFromEnv()))
 	r.Use(auth.Middleware)
+	// Pretty-printing wraps request logging so stored response bodies stay compact
+	r.Use(pretty.Middleware(pretty.EnabledFromEnv()))
+	// Request logging reads the user auth.Middleware puts in the context
+	r.Use(logging.Middleware(s, logOptions))
 
-	// Health check
-	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]any{"ok": true})
-	})
+	// Health check, failing while the watchdog can't reach the database
+	watchdog := newDBWatchdog(dbPath, s)
+	r.Get("/healthz", watchdog.healthz)
 
 	// Favicon
 	r.Get("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 	})
 
-	// Initialize all plugins with database access
-	for _, plugin := range core.All() {
+	// Load plugin config files; disabled plugins serve no routes
+	configDir, err := core.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	plugins, err := core.ConfigurePlugins(configDir, core.All())
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize enabled plugins with database access
+	pluginMiddleware := make(map[string]chi.Middlewares, len(plugins))
+	for _, plugin := range plugins {
 		// Set database for plugins that need it
 		if dbPlugin, ok := plugin.(core.DatabasePlugin); ok {
 			if err := dbPlugin.SetDB(s.GetDB()); err != nil {
@@ -243,15 +531,64 @@ func newServer(dbPath string) (http.Handler, error) {
 			}
 		}
 
-		// Register routes
+		// Register routes; auth routes (token exchange) are always anonymous
 		plugin.RegisterAuth(r)
-		plugin.RegisterRoutes(r)
+		injectErrors, err := core.PluginErrorInjection(plugin)
+		if err != nil {
+			return nil, err
+		}
+		pluginMiddleware[plugin.Name()] = routeMiddleware(plugin, injectErrors)
+		registerPluginRoutes(r, plugin, pluginMiddleware[plugin.Name()])
 	}
 
+	go watchdog.run(ctx, dbWatchdogInterval)
+
+	// GitHub and Linear both serve POST /graphql; route by credentials, behind
+	// the same middleware as the claiming plugin's other routes
+	r.Post("/graphql", core.GraphQLHandler(func(plugin core.GraphQLPlugin) http.Handler {
+		middleware, ok := pluginMiddleware[plugin.Name()]
+		if !ok {
+			// Disabled plugins serve no routes
+			return nil
+		}
+		return middleware.HandlerFunc(plugin.ServeGraphQL)
+	}))
+
 	// Admin UI
-	admin.NewHandlers(s).RegisterRoutes(r)
+	admin.NewHandlers(s).WithLogStream(logOptions.Stream).RegisterRoutes(r)
+
+	if basePath == "" {
+		return r, nil
+	}
 
-	return r, nil
+	// Serve everything under ISH_BASE_PATH; paths outside it are 404s
+	root := chi.NewRouter()
+	root.Use(core.WithBasePath(basePath))
+	root.Mount(basePath, r)
+	return root, nil
+}
+
+// routeMiddleware returns the middleware a plugin's API routes run behind,
+// requiring credentials on plugins that declare which of their routes allow
+// anonymous access. A non-nil injectErrors fails a share of the plugin's
+// requests on purpose.
+func routeMiddleware(plugin core.Plugin, injectErrors func(http.Handler) http.Handler) chi.Middlewares {
+	var middleware chi.Middlewares
+	if injectErrors != nil {
+		middleware = append(middleware, injectErrors)
+	}
+	if anon, ok := plugin.(core.AnonymousRoutesPlugin); ok {
+		middleware = append(middleware, auth.RequireUser(anon.AnonymousRoutes()))
+	}
+	return middleware
+}
+
+// registerPluginRoutes mounts a plugin's API routes behind its middleware
+func registerPluginRoutes(r chi.Router, plugin core.Plugin, middleware chi.Middlewares) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware...)
+		plugin.RegisterRoutes(r)
+	})
 }
 
 func runSeed(cmd *cobra.Command, args []string) error {
@@ -272,6 +609,9 @@ func runSeed(cmd *cobra.Command, args []string) error {
 		pluginName = args[0]
 	}
 
+	if seedFrom != "" {
+		return seedFixture(cmd.OutOrStdout(), s.GetDB(), seedFrom, pluginName)
+	}
 	return seedData(s, pluginName)
 }
 
@@ -296,6 +636,60 @@ func runReset(cmd *cobra.Command, args []string) error {
 	return seedData(s, "") // Reset always seeds all plugins
 }
 
+func runMigrate(cmd *cobra.Command, args []string) error {
+	var err error
+	dbPath, err = validateAndCleanDBPath(dbPath)
+	if err != nil {
+		return err
+	}
+	return migrate(cmd.OutOrStdout(), dbPath, dryRun)
+}
+
+// migrate applies pending migrations to the database at path, or with dryRun
+// only lists them, leaving a missing database uncreated
+func migrate(out io.Writer, path string, dryRun bool) error {
+	if dryRun {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			printMigrations(out, "Pending migrations:", migrations.All)
+			return nil
+		}
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var list []migrations.Migration
+	if dryRun {
+		list, err = migrations.Pending(db, migrations.All)
+		if err != nil {
+			return err
+		}
+		printMigrations(out, "Pending migrations:", list)
+		return nil
+	}
+
+	list, err = migrations.Apply(db, migrations.All)
+	if err != nil {
+		return err
+	}
+	printMigrations(out, "Applied migrations:", list)
+	return nil
+}
+
+func printMigrations(out io.Writer, heading string, list []migrations.Migration) {
+	if len(list) == 0 {
+		fmt.Fprintln(out, "Database schema is up to date.")
+		return
+	}
+	fmt.Fprintln(out, heading)
+	for _, m := range list {
+		fmt.Fprintf(out, "  v%d  %s\n", m.Version, m.Description)
+	}
+}
+
 func seedData(s *store.Store, pluginFilter string) error {
 	if pluginFilter != "" {
 		log.Printf("Seeding database with test data for plugin: %s", pluginFilter)
@@ -303,14 +697,11 @@ func seedData(s *store.Store, pluginFilter string) error {
 		log.Println("Seeding database with test data...")
 	}
 
-	// Initialize all plugins with database access
-	for _, plugin := range core.All() {
-		if dbPlugin, ok := plugin.(core.DatabasePlugin); ok {
-			if err := dbPlugin.SetDB(s.GetDB()); err != nil {
-				log.Printf("Failed to initialize plugin %s: %v", plugin.Name(), err)
-				continue
-			}
-		}
+	initPlugins(s.GetDB())
+
+	configDir, err := core.ConfigDir()
+	if err != nil {
+		return err
 	}
 
 	// Seed each plugin (optionally filtered by name)
@@ -323,7 +714,17 @@ func seedData(s *store.Store, pluginFilter string) error {
 			continue
 		}
 
-		seedData, err := plugin.Seed(context.Background(), "medium")
+		// Seed at the plugin's configured density; disabled plugins are
+		// only seeded when named
+		config, err := core.LoadPluginConfig(configDir, plugin.Name())
+		if err != nil {
+			return err
+		}
+		if !config.Enabled && pluginFilter == "" {
+			continue
+		}
+
+		seedData, err := plugin.Seed(context.Background(), config.SeedSize())
 		if err != nil {
 			errMsg := err.Error()
 			if strings.Contains(errMsg, "UNIQUE constraint failed") {
@@ -367,6 +768,17 @@ func seedData(s *store.Store, pluginFilter string) error {
 	return nil
 }
 
+// initPlugins gives every database plugin access to db, logging plugins that fail to initialize
+func initPlugins(db *sql.DB) {
+	for _, plugin := range core.All() {
+		if dbPlugin, ok := plugin.(core.DatabasePlugin); ok {
+			if err := dbPlugin.SetDB(db); err != nil {
+				log.Printf("Failed to initialize plugin %s: %v", plugin.Name(), err)
+			}
+		}
+	}
+}
+
 func getEnv(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -1,22 +1,33 @@
 // ABOUTME: Tests for CLI commands and server wiring.
-// ABOUTME: Verifies health check, path validation, and basic server functionality.
+// ABOUTME: Verifies health check, CORS, admin stats and log stream, path validation, migrations, graceful shutdown, h2c, and TLS serving.
 
 package main
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 func TestServer_Healthz(t *testing.T) {
 	dbPath := "test_main.db"
 	defer os.Remove(dbPath)
 
-	srv, err := newServer(dbPath)
+	srv, err := newServer(t.Context(), dbPath, nil)
 	if err != nil {
 		t.Fatalf("newServer() error = %v", err)
 	}
@@ -39,6 +50,115 @@ func TestServer_Healthz(t *testing.T) {
 	}
 }
 
+func TestServer_BasePath(t *testing.T) {
+	t.Setenv("ISH_CONFIG_DIR", t.TempDir())
+	t.Setenv(core.BasePathEnv, "/api/")
+	srv, err := newServer(t.Context(), filepath.Join(t.TempDir(), "basepath.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	if rr := get("/api/healthz"); rr.Code != http.StatusOK {
+		t.Errorf("GET /api/healthz status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	if rr := get("/healthz"); rr.Code != http.StatusNotFound {
+		t.Errorf("GET /healthz status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+
+	// URL params resolve under the prefix, and generated URLs include it
+	rr := get("/api/discovery/v1/apis/gmail/v1/rest")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET discovery status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var doc map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &doc)
+	if doc["rootUrl"] != "http://example.com/api/" || doc["baseUrl"] != "http://example.com/api/gmail/v1/" {
+		t.Errorf("rootUrl = %v, baseUrl = %v, want them under /api", doc["rootUrl"], doc["baseUrl"])
+	}
+}
+
+func TestServer_CORSPreflightSkipsAuth(t *testing.T) {
+	srv, err := newServer(t.Context(), filepath.Join(t.TempDir(), "cors.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+
+	req := httptest.NewRequest("OPTIONS", "/gmail/v1/users/me/messages", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	req.Header.Set("Access-Control-Request-Headers", "authorization")
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusNoContent)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Headers"); got != "authorization" {
+		t.Errorf("Access-Control-Allow-Headers = %q, want %q", got, "authorization")
+	}
+}
+
+func TestLogRetentionFromEnv(t *testing.T) {
+	t.Setenv("ISH_LOG_RETENTION_DAYS", "")
+	t.Setenv("ISH_MAX_LOG_ROWS", "")
+	got, err := logRetentionFromEnv()
+	if err != nil {
+		t.Fatalf("logRetentionFromEnv() error = %v", err)
+	}
+	if got.MaxAge != 7*24*time.Hour || got.MaxRows != 100000 || got.Interval != time.Hour {
+		t.Errorf("defaults = %+v, want 7 days, 100000 rows, hourly", got)
+	}
+
+	t.Setenv("ISH_LOG_RETENTION_DAYS", "0")
+	t.Setenv("ISH_MAX_LOG_ROWS", "500")
+	got, _ = logRetentionFromEnv()
+	if got.MaxAge != 0 || got.MaxRows != 500 {
+		t.Errorf("logRetentionFromEnv() = %+v, want no age limit and 500 rows", got)
+	}
+
+	t.Setenv("ISH_MAX_LOG_ROWS", "lots")
+	if _, err := newServer(t.Context(), filepath.Join(t.TempDir(), "retention.db"), nil); err == nil {
+		t.Error("newServer() with an invalid ISH_MAX_LOG_ROWS should fail")
+	}
+}
+
+func TestMigrate_DryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrate.db")
+
+	var out strings.Builder
+	if err := migrate(&out, path, true); err != nil {
+		t.Fatalf("migrate(dry run) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Pending migrations:") || !strings.Contains(out.String(), "Add history_id to gmail_messages") {
+		t.Errorf("dry run output = %q, want every migration listed", out.String())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("dry run should not create the database")
+	}
+
+	out.Reset()
+	if err := migrate(&out, path, false); err != nil {
+		t.Fatalf("migrate() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Applied migrations:") {
+		t.Errorf("migrate output = %q, want applied migrations", out.String())
+	}
+
+	out.Reset()
+	if err := migrate(&out, path, true); err != nil {
+		t.Fatalf("migrate(dry run) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "up to date") {
+		t.Errorf("dry run after migrating = %q, want up to date", out.String())
+	}
+}
+
 func TestValidateAndCleanDBPath_Valid(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -225,3 +345,372 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestServe_ShutdownWaitsForInFlightRequest(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, newHTTPServer(handler), ln) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{string(body), err}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-served:
+		t.Fatalf("serve() returned %v before the in-flight request finished", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	res := <-responses
+	if res.err != nil || res.body != "done" {
+		t.Fatalf("in-flight request = %q, %v; want \"done\"", res.body, res.err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("serve() error = %v", err)
+	}
+	if _, err := http.Get("http://" + ln.Addr().String() + "/slow"); err == nil {
+		t.Error("expected new connections to be refused after shutdown")
+	}
+}
+
+func TestServe_ShutdownEndsLogStream(t *testing.T) {
+	t.Setenv("ISH_CONFIG_DIR", t.TempDir())
+	handler, err := newServer(t.Context(), filepath.Join(t.TempDir(), "stream.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, newHTTPServer(handler), ln) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/admin/logs/stream")
+	if err != nil {
+		t.Fatalf("GET /admin/logs/stream error = %v", err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewReader(resp.Body)
+	if line, err := events.ReadString('\n'); err != nil || !strings.HasPrefix(line, ":") {
+		t.Fatalf("first stream line = %q, %v", line, err)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve() is still waiting for the log stream")
+	}
+	if _, err := io.ReadAll(events); err != nil {
+		t.Errorf("expected the stream to end cleanly, got %v", err)
+	}
+}
+
+func TestServe_H2C(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, newHTTPServer(handler), ln) }()
+	defer func() {
+		cancel()
+		<-served
+	}()
+
+	// A client that only speaks HTTP/2 over cleartext
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	resp, err := client.Get("http://" + ln.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("h2c request error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.ProtoMajor != 2 || string(body) != "HTTP/2.0" {
+		t.Errorf("proto = %s (server saw %q), want HTTP/2.0", resp.Proto, body)
+	}
+}
+
+func TestServe_TLS(t *testing.T) {
+	handler, err := newServer(t.Context(), filepath.Join(t.TempDir(), "tls.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+	config, caPEM, err := newTLSConfig("", "")
+	if err != nil {
+		t.Fatalf("newTLSConfig() error = %v", err)
+	}
+	srv := newHTTPServer(handler)
+	srv.TLSConfig = config
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, srv, ln) }()
+	defer func() {
+		cancel()
+		<-served
+	}()
+
+	// Trust only the printed CA, as a client following the startup instructions would
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		t.Fatal("generated CA is not valid PEM")
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}, ForceAttemptHTTP2: true}}
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	base := "https://localhost:" + port
+
+	resp, err := client.Get(base + "/healthz")
+	if err != nil {
+		t.Fatalf("HTTPS request error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if resp.ProtoMajor != 2 {
+		t.Errorf("proto = %s, want HTTP/2 negotiated over TLS", resp.Proto)
+	}
+
+	// Discovery documents advertise the https scheme
+	resp, err = client.Get(base + "/discovery/v1/apis/gmail/v1/rest")
+	if err != nil {
+		t.Fatalf("discovery request error = %v", err)
+	}
+	defer resp.Body.Close()
+	var doc map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("decoding discovery doc: %v", err)
+	}
+	if doc["rootUrl"] != base+"/" {
+		t.Errorf("rootUrl = %v, want %s/", doc["rootUrl"], base)
+	}
+
+	// A client without the CA refuses the certificate
+	if _, err := http.Get(base + "/healthz"); err == nil {
+		t.Error("expected the default client to reject the self-signed certificate")
+	}
+}
+
+func TestNewTLSConfig_RequiresCertAndKey(t *testing.T) {
+	if _, _, err := newTLSConfig("cert.pem", ""); err == nil {
+		t.Error("expected an error when --key is missing")
+	}
+}
+
+func TestServer_AdminStatsAfterSeeding(t *testing.T) {
+	t.Setenv("ISH_CONFIG_DIR", t.TempDir())
+	srv, err := newServer(t.Context(), filepath.Join(t.TempDir(), "stats.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+	for _, name := range []string{"google", "github"} {
+		plugin, _ := core.Get(name)
+		if _, err := plugin.Seed(context.Background(), "small"); err != nil {
+			t.Fatalf("seed %s: %v", name, err)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, httptest.NewRequest("GET", "/admin/api/stats", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rr.Code, rr.Body.String())
+	}
+	var stats map[string]map[string]int
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if stats["google"]["gmail_messages"] == 0 {
+		t.Errorf("gmail_messages = 0 after seeding, stats: %v", stats["google"])
+	}
+	if stats["github"]["github_issues"] == 0 || stats["github"]["github_repositories"] == 0 {
+		t.Errorf("GitHub counts are zero after seeding, stats: %v", stats["github"])
+	}
+	if _, ok := stats["twilio"]["twilio_messages"]; !ok {
+		t.Errorf("expected unseeded plugins to report their tables, got %v", stats["twilio"])
+	}
+}
+
+func TestServer_AdminLogStream(t *testing.T) {
+	t.Setenv("ISH_CONFIG_DIR", t.TempDir())
+	handler, err := newServer(t.Context(), filepath.Join(t.TempDir(), "stream.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/admin/logs/stream?plugin=github", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /admin/logs/stream error = %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+	events := bufio.NewReader(resp.Body)
+	// The stream opens with a comment once the subscription is in place
+	if line, err := events.ReadString('\n'); err != nil || !strings.HasPrefix(line, ":") {
+		t.Fatalf("first stream line = %q, %v", line, err)
+	}
+
+	// A Google request is filtered out; the GitHub one arrives
+	for _, path := range []string{"/gmail/v1/users/me/profile", "/repos/alice/stream-test"} {
+		apiReq, _ := http.NewRequest("GET", srv.URL+path, nil)
+		apiReq.Header.Set("Authorization", "Bearer user:alice")
+		apiResp, err := http.DefaultClient.Do(apiReq)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		apiResp.Body.Close()
+	}
+
+	var event, data string
+	for data == "" {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			event = strings.TrimSpace(name)
+		}
+		if value, ok := strings.CutPrefix(line, "data: "); ok {
+			data = value
+		}
+	}
+	var logged struct {
+		ID     int64  `json:"id"`
+		Plugin string `json:"plugin"`
+		Method string `json:"method"`
+		Path   string `json:"path"`
+	}
+	if err := json.Unmarshal([]byte(data), &logged); err != nil {
+		t.Fatalf("json.Unmarshal(%q) error = %v", data, err)
+	}
+	if event != "request" || logged.Plugin != "github" || logged.Path != "/repos/alice/stream-test" || logged.ID == 0 {
+		t.Errorf("got event %q with %+v, want the GitHub request", event, logged)
+	}
+}
+
+func TestServer_ErrorRateFailsOnlyThatPlugin(t *testing.T) {
+	t.Setenv("ISH_CONFIG_DIR", t.TempDir())
+	t.Setenv("ISH_ERROR_RATE_GITHUB", "1.0")
+	t.Setenv(core.RandomSeedEnv, "42")
+	srv, err := newServer(t.Context(), filepath.Join(t.TempDir(), "errorrate.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer user:alice")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for _, path := range []string{"/user", "/user/repos", "/repos/alice/demo/issues"} {
+		rr := get(path)
+		if rr.Code != http.StatusInternalServerError || rr.Header().Get(core.InjectedErrorHeader) != "true" {
+			t.Errorf("GET %s status = %d, want an injected 500", path, rr.Code)
+		}
+		var body map[string]any
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil || body["message"] == nil {
+			t.Errorf("GET %s body = %s, want a JSON error message", path, rr.Body.String())
+		}
+	}
+
+	if rr := get("/gmail/v1/users/me/profile"); rr.Code != http.StatusOK {
+		t.Errorf("GET Gmail profile status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	for _, path := range []string{"/admin/", "/admin/github/dispatch-events"} {
+		if rr := get(path); rr.Code != http.StatusOK {
+			t.Errorf("GET %s status = %d, want %d: %s", path, rr.Code, http.StatusOK, rr.Body.String())
+		}
+	}
+}
+
+func TestServer_GraphQLRunsBehindPluginMiddleware(t *testing.T) {
+	t.Setenv("ISH_CONFIG_DIR", t.TempDir())
+	t.Setenv("ISH_ERROR_RATE_LINEAR", "1.0")
+	t.Setenv(core.RandomSeedEnv, "42")
+	srv, err := newServer(t.Context(), filepath.Join(t.TempDir(), "graphql.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ viewer { id } }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "lin_api_test")
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError || rr.Header().Get(core.InjectedErrorHeader) != "true" {
+		t.Errorf("POST /graphql status = %d, want Linear's injected 500: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestServer_GraphQLRefusesUnknownGitHubToken(t *testing.T) {
+	t.Setenv("ISH_CONFIG_DIR", t.TempDir())
+	srv, err := newServer(t.Context(), filepath.Join(t.TempDir(), "graphql.db"), nil)
+	if err != nil {
+		t.Fatalf("newServer() error = %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ viewer { login } }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer ghp_unknown")
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized || !strings.Contains(rr.Body.String(), "bad credentials") {
+		t.Errorf("POST /graphql status = %d, want GitHub's 401 bad credentials: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,504 @@
+// ABOUTME: Interactive REPL for `ish shell`.
+// ABOUTME: Runs whitelisted SQL against the database and plugin seed, reset, and count commands.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	shellPrompt         = "ish> "
+	shellContinuePrompt = "  -> "
+	// maxCellWidth truncates long values so result tables stay readable
+	maxCellWidth = 60
+)
+
+// coreTables are the non-plugin tables the shell may query
+var coreTables = []string{"audit_log", "request_logs"}
+
+// allowedStatements are the SQL verbs the shell runs
+var allowedStatements = map[string]bool{"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true}
+
+// authorizedActions are the SQLite authorizer actions a shell statement may
+// take; the table ones are further limited to the whitelist
+var authorizedActions = map[int]bool{
+	sqlite3.SQLITE_SELECT:   true,
+	sqlite3.SQLITE_READ:     true,
+	sqlite3.SQLITE_INSERT:   true,
+	sqlite3.SQLITE_UPDATE:   true,
+	sqlite3.SQLITE_DELETE:   true,
+	sqlite3.SQLITE_FUNCTION: true,
+}
+
+func runShell(cmd *cobra.Command, args []string) error {
+	var err error
+	dbPath, err = validateAndCleanDBPath(dbPath)
+	if err != nil {
+		return err
+	}
+
+	s, err := store.New(dbPath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	initPlugins(s.GetDB())
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Connected to %s\n", dbPath)
+	fmt.Fprintln(out, `Type "help" for commands, "exit" to quit.`)
+	return newShell(s.GetDB(), out, historyFilePath()).run(cmd.InOrStdin())
+}
+
+// historyFilePath returns ~/.ish_history, or "" when there is no home directory
+func historyFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".ish_history")
+}
+
+// shell is one interactive session
+type shell struct {
+	db          *sql.DB
+	out         io.Writer
+	tables      map[string]bool
+	historyPath string
+	history     []string
+}
+
+func newShell(db *sql.DB, out io.Writer, historyPath string) *shell {
+	return &shell{
+		db:          db,
+		out:         out,
+		tables:      shellTables(),
+		historyPath: historyPath,
+		history:     readHistory(historyPath),
+	}
+}
+
+// shellTables returns the whitelist of tables SQL may touch: every table a
+// plugin owns plus the request and audit logs
+func shellTables() map[string]bool {
+	tables := make(map[string]bool)
+	for _, table := range coreTables {
+		tables[table] = true
+	}
+	for _, plugin := range core.All() {
+		if truncatable, ok := plugin.(core.Truncatable); ok {
+			for _, table := range core.GroupTables(truncatable.TruncateGroups()) {
+				tables[table] = true
+			}
+		}
+	}
+	return tables
+}
+
+// run reads input until EOF or exit. SQL is buffered until a line ends with ';'.
+func (sh *shell) run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	var pending []string
+
+	fmt.Fprint(sh.out, shellPrompt)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			sh.remember(line)
+		}
+
+		if len(pending) == 0 {
+			if line == "" {
+				fmt.Fprint(sh.out, shellPrompt)
+				continue
+			}
+			if handled, quit := sh.command(line); quit {
+				return nil
+			} else if handled {
+				fmt.Fprint(sh.out, shellPrompt)
+				continue
+			}
+		}
+
+		pending = append(pending, line)
+		if !strings.HasSuffix(line, ";") {
+			fmt.Fprint(sh.out, shellContinuePrompt)
+			continue
+		}
+		if err := sh.execSQL(strings.Join(pending, "\n")); err != nil {
+			fmt.Fprintf(sh.out, "Error: %v\n", err)
+		}
+		pending = nil
+		fmt.Fprint(sh.out, shellPrompt)
+	}
+	fmt.Fprintln(sh.out)
+	return scanner.Err()
+}
+
+var errPluginUsage = errors.New("usage: plugin <name> seed [size] | plugin <name> reset")
+
+// command runs a shell command, reporting whether line was one and whether to quit
+func (sh *shell) command(line string) (handled, quit bool) {
+	fields := strings.Fields(strings.TrimSuffix(line, ";"))
+	if len(fields) == 0 {
+		// A bare ";" is an empty statement, reported by execSQL
+		return false, false
+	}
+	var err error
+	switch strings.ToLower(fields[0]) {
+	case "exit", "quit", `\q`:
+		return true, true
+	case "help", `\?`:
+		sh.printHelp()
+	case "tables":
+		sh.printTables()
+	case "history":
+		for i, entry := range sh.history {
+			fmt.Fprintf(sh.out, "%5d  %s\n", i+1, entry)
+		}
+	case "count":
+		if len(fields) != 2 {
+			err = fmt.Errorf("usage: count <plugin or resource type>")
+		} else {
+			err = sh.count(fields[1])
+		}
+	case "plugin":
+		if len(fields) < 3 {
+			err = errPluginUsage
+		} else {
+			err = sh.pluginCommand(fields[1:])
+		}
+	default:
+		return false, false
+	}
+	if err != nil {
+		fmt.Fprintf(sh.out, "Error: %v\n", err)
+	}
+	return true, false
+}
+
+func (sh *shell) printHelp() {
+	fmt.Fprintln(sh.out, `Commands:
+  <sql>;                        Run SELECT, INSERT, UPDATE, or DELETE against a plugin table
+  plugin <name> seed [size]     Seed a plugin (size: small, medium, large; default medium)
+  plugin <name> reset           Delete all of a plugin's data
+  count <name>                  Row counts for a plugin (github) or resource type (gmail)
+  tables                        List tables SQL may use
+  history                       Show previous input
+  exit                          Leave the shell`)
+}
+
+func (sh *shell) printTables() {
+	names := make([]string, 0, len(sh.tables))
+	for name := range sh.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(sh.out, name)
+	}
+}
+
+// validateSQL rejects anything but a single SELECT, INSERT, UPDATE, or
+// DELETE, so the shell can't drop or alter the schema. The tables a statement
+// touches are checked by SQLite as it compiles it (see execSQL).
+func validateSQL(query string) error {
+	query = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	if query == "" {
+		return fmt.Errorf("empty statement")
+	}
+	if strings.Contains(query, ";") {
+		return fmt.Errorf("only one statement may run at a time")
+	}
+	verb := strings.ToUpper(strings.Fields(query)[0])
+	if !allowedStatements[verb] {
+		return fmt.Errorf("%s statements are not allowed; use SELECT, INSERT, UPDATE, or DELETE", verb)
+	}
+	return nil
+}
+
+// execSQL validates and runs a statement, printing rows as a table or the number of rows changed
+func (sh *shell) execSQL(query string) error {
+	if err := validateSQL(query); err != nil {
+		return err
+	}
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+
+	ctx := context.Background()
+	conn, err := sh.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// SQLite reports every table the statement reads or writes, including
+	// comma joins and subqueries, so the whitelist can't be talked around
+	var denied string
+	err = setAuthorizer(conn, func(action int, table, _, _ string) int {
+		if !authorizedActions[action] {
+			return sqlite3.SQLITE_DENY
+		}
+		switch action {
+		case sqlite3.SQLITE_READ, sqlite3.SQLITE_INSERT, sqlite3.SQLITE_UPDATE, sqlite3.SQLITE_DELETE:
+			if !sh.tables[strings.ToLower(table)] {
+				if denied == "" {
+					denied = table
+				}
+				return sqlite3.SQLITE_DENY
+			}
+		}
+		return sqlite3.SQLITE_OK
+	})
+	if err != nil {
+		return err
+	}
+	defer setAuthorizer(conn, nil)
+
+	err = sh.runSQL(ctx, conn, query)
+	if denied != "" {
+		return fmt.Errorf("table %q is not available in the shell (see \"tables\")", denied)
+	}
+	return err
+}
+
+// setAuthorizer installs callback as the SQLite authorizer of conn, or
+// removes it when callback is nil
+func setAuthorizer(conn *sql.Conn, callback func(int, string, string, string) int) error {
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(interface {
+			RegisterAuthorizer(func(int, string, string, string) int)
+		})
+		if !ok {
+			return fmt.Errorf("the database driver cannot check which tables a statement uses")
+		}
+		c.RegisterAuthorizer(callback)
+		return nil
+	})
+}
+
+// runSQL runs an authorized statement on conn and prints its result
+func (sh *shell) runSQL(ctx context.Context, conn *sql.Conn, query string) error {
+	if strings.ToUpper(strings.Fields(query)[0]) != "SELECT" {
+		result, err := conn.ExecContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		n, _ := result.RowsAffected()
+		fmt.Fprintf(sh.out, "%d %s affected\n", n, plural(n, "row"))
+		return nil
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	var table [][]string
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		row := make([]string, len(columns))
+		for i, v := range values {
+			row[i] = formatCell(v)
+		}
+		table = append(table, row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	writeTable(sh.out, columns, table)
+	fmt.Fprintf(sh.out, "(%d %s)\n", len(table), plural(int64(len(table)), "row"))
+	return nil
+}
+
+// formatCell renders a scanned value for display
+func formatCell(v any) string {
+	var s string
+	switch val := v.(type) {
+	case nil:
+		s = "NULL"
+	case []byte:
+		s = string(val)
+	case time.Time:
+		s = val.Format(time.RFC3339)
+	default:
+		s = fmt.Sprint(val)
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) > maxCellWidth {
+		s = string([]rune(s)[:maxCellWidth-3]) + "..."
+	}
+	return s
+}
+
+// writeTable prints rows as an ASCII table with a header row
+func writeTable(out io.Writer, columns []string, rows [][]string) {
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = utf8.RuneCountInString(c)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	border := "+"
+	for _, w := range widths {
+		border += strings.Repeat("-", w+2) + "+"
+	}
+	writeRow := func(cells []string) {
+		line := "|"
+		for i, cell := range cells {
+			line += " " + cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)) + " |"
+		}
+		fmt.Fprintln(out, line)
+	}
+
+	fmt.Fprintln(out, border)
+	writeRow(columns)
+	fmt.Fprintln(out, border)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	fmt.Fprintln(out, border)
+}
+
+func plural(n int64, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// pluginCommand runs "plugin <name> seed [size]" or "plugin <name> reset"
+func (sh *shell) pluginCommand(args []string) error {
+	if len(args) < 2 {
+		return errPluginUsage
+	}
+	plugin, ok := core.Get(args[0])
+	if !ok {
+		return fmt.Errorf("unknown plugin %q", args[0])
+	}
+
+	switch strings.ToLower(args[1]) {
+	case "seed":
+		size := "medium"
+		if len(args) > 2 {
+			size = args[2]
+		}
+		data, err := plugin.Seed(context.Background(), size)
+		if err != nil {
+			return fmt.Errorf("seed %s: %w", plugin.Name(), err)
+		}
+		fmt.Fprintf(sh.out, "%s: %s\n", plugin.Name(), data.Summary)
+	case "reset":
+		truncatable, ok := plugin.(core.Truncatable)
+		if !ok {
+			return fmt.Errorf("plugin %s cannot be reset", plugin.Name())
+		}
+		if err := truncatable.Truncate(sh.db); err != nil {
+			return fmt.Errorf("reset %s: %w", plugin.Name(), err)
+		}
+		fmt.Fprintf(sh.out, "%s: all data deleted\n", plugin.Name())
+	default:
+		return fmt.Errorf("unknown plugin command %q; use seed or reset", args[1])
+	}
+	return nil
+}
+
+// count prints row counts for each table of a plugin, or of a resource type
+// such as gmail that names one of a plugin's truncate groups
+func (sh *shell) count(name string) error {
+	var tables []string
+	for _, plugin := range core.All() {
+		truncatable, ok := plugin.(core.Truncatable)
+		if !ok {
+			continue
+		}
+		groups := truncatable.TruncateGroups()
+		if plugin.Name() == name {
+			tables = core.GroupTables(groups)
+			break
+		}
+		if group, ok := groups[name]; ok {
+			tables = group
+			break
+		}
+	}
+	if len(tables) == 0 {
+		return fmt.Errorf("no plugin or resource type named %q", name)
+	}
+
+	rows := make([][]string, 0, len(tables))
+	for _, table := range tables {
+		var n int
+		if err := sh.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			return err
+		}
+		rows = append(rows, []string{table, fmt.Sprint(n)})
+	}
+	writeTable(sh.out, []string{"table", "rows"}, rows)
+	return nil
+}
+
+// readHistory loads previous input from the history file, if any
+func readHistory(path string) []string {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// remember records a line of input in memory and appends it to the history file
+func (sh *shell) remember(line string) {
+	sh.history = append(sh.history, line)
+	if sh.historyPath == "" {
+		return
+	}
+	f, err := os.OpenFile(sh.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
@@ -0,0 +1,186 @@
+// ABOUTME: Tests for the interactive `ish shell` REPL.
+// ABOUTME: Pipes input through the shell to verify SQL whitelisting, table output, plugin commands, and history.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/store"
+)
+
+// runShellInput pipes input through a fresh shell and returns its output
+func runShellInput(t *testing.T, historyPath, input string) string {
+	t.Helper()
+	s, err := store.New(filepath.Join(t.TempDir(), "shell.db"))
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	initPlugins(s.GetDB())
+
+	var out strings.Builder
+	if err := newShell(s.GetDB(), &out, historyPath).run(strings.NewReader(input)); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	return out.String()
+}
+
+func TestShell_QueryPrintsTable(t *testing.T) {
+	out := runShellInput(t, "", strings.Join([]string{
+		"plugin google seed small",
+		"select id,",
+		"  snippet from gmail_messages order by id limit 2;",
+		"exit",
+	}, "\n"))
+
+	if !strings.Contains(out, "google: ") {
+		t.Errorf("output = %q, want seed summary", out)
+	}
+	if !strings.Contains(out, "| id ") || !strings.Contains(out, "| snippet ") {
+		t.Errorf("output = %q, want a header row with id and snippet", out)
+	}
+	if !strings.Contains(out, "+----") {
+		t.Errorf("output = %q, want ASCII table borders", out)
+	}
+	if !strings.Contains(out, "(2 rows)") {
+		t.Errorf("output = %q, want row count", out)
+	}
+	if !strings.Contains(out, shellContinuePrompt) {
+		t.Errorf("output = %q, want continuation prompt for multi-line SQL", out)
+	}
+}
+
+func TestShell_RejectsUnsafeSQL(t *testing.T) {
+	out := runShellInput(t, "", strings.Join([]string{
+		"drop table gmail_messages;",
+		"select * from sqlite_master;",
+		"delete from gmail_messages; drop table gmail_threads;",
+		"select count(*) from gmail_messages;",
+	}, "\n"))
+
+	for _, want := range []string{
+		"DROP statements are not allowed",
+		`table "sqlite_master" is not available`,
+		"only one statement may run at a time",
+		"(1 row)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %q, want %q", out, want)
+		}
+	}
+}
+
+func TestShell_CountAndReset(t *testing.T) {
+	out := runShellInput(t, "", strings.Join([]string{
+		"plugin google seed small",
+		"plugin google reset",
+		"count gmail",
+		"count nope",
+	}, "\n"))
+
+	if !strings.Contains(out, "google: all data deleted") {
+		t.Errorf("output = %q, want reset confirmation", out)
+	}
+	if !strings.Contains(out, "| gmail_messages ") || !strings.Contains(out, "| 0    |") {
+		t.Errorf("output = %q, want zero gmail rows after reset", out)
+	}
+	if !strings.Contains(out, `no plugin or resource type named "nope"`) {
+		t.Errorf("output = %q, want unknown name error", out)
+	}
+}
+
+func TestShell_EmptyAndIncompleteCommands(t *testing.T) {
+	out := runShellInput(t, "", strings.Join([]string{
+		";",
+		"plugin",
+		"plugin google",
+		"select 1 from request_logs;",
+	}, "\n"))
+
+	if !strings.Contains(out, "Error: empty statement") {
+		t.Errorf("output = %q, want a bare ; reported as an empty statement", out)
+	}
+	if got := strings.Count(out, "usage: plugin <name>"); got != 2 {
+		t.Errorf("output = %q, want plugin usage shown twice", out)
+	}
+	if !strings.Contains(out, "(0 rows)") {
+		t.Errorf("output = %q, want the shell to keep running", out)
+	}
+}
+
+func TestShell_History(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), ".ish_history")
+	runShellInput(t, historyPath, "tables\nselect 1 from request_logs;\n")
+
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "tables\nselect 1 from request_logs;\n"; got != want {
+		t.Errorf("history file = %q, want %q", got, want)
+	}
+
+	out := runShellInput(t, historyPath, "history\n")
+	if !strings.Contains(out, "    1  tables") || !strings.Contains(out, "    3  history") {
+		t.Errorf("history output = %q, want earlier session's input numbered", out)
+	}
+}
+
+func TestShell_RejectsHiddenTables(t *testing.T) {
+	out := runShellInput(t, "", strings.Join([]string{
+		"select * from request_logs, sqlite_master;",
+		"select * from gmail_messages,schema_migrations;",
+		"select id from gmail_messages where id in (select version from schema_migrations);",
+		"select (select count(*) from sqlite_master);",
+		"insert into gmail_threads (id) select name from sqlite_master;",
+	}, "\n"))
+
+	if got := strings.Count(out, `is not available in the shell`); got != 5 {
+		t.Errorf("output = %q, want all 5 statements rejected", out)
+	}
+	if strings.Contains(out, "| ") || strings.Contains(out, "affected") {
+		t.Errorf("output = %q, want no statement to run", out)
+	}
+}
+
+func TestShell_AllowsWhitelistedJoins(t *testing.T) {
+	out := runShellInput(t, "", strings.Join([]string{
+		"plugin google seed small",
+		"SELECT m.id FROM gmail_messages m, gmail_threads t WHERE t.id = m.thread_id LIMIT 1;",
+		"update gmail_messages set snippet = 'x' where id in (select id from gmail_messages limit 1);",
+		"select count(*) from request_logs join audit_log on 1=0;",
+	}, "\n"))
+
+	for _, want := range []string{"(1 row)", "1 row affected"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %q, want %q", out, want)
+		}
+	}
+	if strings.Contains(out, "not available") {
+		t.Errorf("output = %q, want whitelisted tables allowed", out)
+	}
+}
+
+func TestValidateSQL(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"select * from gmail_messages;", false},
+		{"update gmail_messages set snippet = 'x';", false},
+		{"insert into gmail_threads (id) values ('t1');", false},
+		{"alter table gmail_messages add column x;", true},
+		{"pragma table_info(gmail_messages);", true},
+		{";", true},
+	}
+	for _, tt := range tests {
+		err := validateSQL(tt.query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateSQL(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Database health watchdog for the ISH server.
+// ABOUTME: Pings the database periodically, reports failures on /healthz, and reopens the database to recover.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+)
+
+// dbWatchdogInterval is how often the watchdog pings the database
+const dbWatchdogInterval = 30 * time.Second
+
+// dbWatchdog tracks whether the database is answering. When a ping fails it
+// marks the server unhealthy and reconnects the store to its file. The store
+// keeps its *sql.DB, so plugins, request logging, and the admin UI use the
+// new connections without being rebound.
+type dbWatchdog struct {
+	path  string
+	store *store.Store
+
+	mu      sync.RWMutex
+	healthy bool
+}
+
+func newDBWatchdog(path string, s *store.Store) *dbWatchdog {
+	return &dbWatchdog{path: path, store: s, healthy: true}
+}
+
+// run checks the database every interval until ctx is cancelled
+func (w *dbWatchdog) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check pings the database, and after a failure tries to reconnect. A
+// missing file stays a failure until it is restored, rather than being
+// replaced by an empty database.
+func (w *dbWatchdog) check() {
+	err := w.store.Ping()
+	if err == nil {
+		w.setHealthy(true)
+		return
+	}
+	log.Printf("Database health check failed: %v", err)
+	w.setHealthy(false)
+
+	if err := w.store.Reconnect(); err != nil {
+		log.Printf("Database reconnect failed: %v", err)
+		return
+	}
+	w.setHealthy(true)
+	log.Printf("Reconnected to database %s", w.path)
+}
+
+func (w *dbWatchdog) setHealthy(healthy bool) {
+	w.mu.Lock()
+	w.healthy = healthy
+	w.mu.Unlock()
+}
+
+// Healthy reports whether the last check reached the database
+func (w *dbWatchdog) Healthy() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.healthy
+}
+
+// healthz handles GET /healthz, answering 503 while the database is unavailable
+func (w *dbWatchdog) healthz(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	if !w.Healthy() {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(rw).Encode(map[string]any{"ok": false, "reason": "database_unavailable"})
+		return
+	}
+	json.NewEncoder(rw).Encode(map[string]any{"ok": true})
+}
@@ -0,0 +1,87 @@
+// ABOUTME: Tests for the database health watchdog.
+// ABOUTME: Simulates a lost database and checks /healthz fails until the database file is restored.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/2389/ish/internal/store"
+)
+
+func TestDBWatchdog_ReportsAndRecoversFromFailure(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "watchdog.db")
+	s, err := store.New(dbPath)
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	defer s.Close()
+	db := s.GetDB()
+
+	watchdog := newDBWatchdog(dbPath, s)
+	healthz := func() (int, map[string]any) {
+		rr := httptest.NewRecorder()
+		watchdog.healthz(rr, httptest.NewRequest("GET", "/healthz", nil))
+		var resp map[string]any
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return rr.Code, resp
+	}
+
+	watchdog.check()
+	if code, resp := healthz(); code != http.StatusOK || resp["ok"] != true {
+		t.Fatalf("healthz = %d %v, want 200 ok", code, resp)
+	}
+
+	// A backup to restore later, with a request the live database doesn't have
+	backupPath := filepath.Join(dir, "backup.db")
+	backup, err := store.New(backupPath)
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	if _, err := backup.GetDB().Exec(`INSERT INTO request_logs (method, path) VALUES ('GET', '/restored')`); err != nil {
+		t.Fatal(err)
+	}
+	backup.Close()
+
+	// The database files disappear
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+	}
+	for range 2 {
+		watchdog.check()
+		code, resp := healthz()
+		if code != http.StatusServiceUnavailable || resp["ok"] != false || resp["reason"] != "database_unavailable" {
+			t.Fatalf("healthz = %d %v, want 503 database_unavailable", code, resp)
+		}
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no empty database in place of the missing file, Stat() error = %v", err)
+	}
+
+	// Once the file is restored, the same *sql.DB reads from it
+	if err := os.Rename(backupPath, dbPath); err != nil {
+		t.Fatal(err)
+	}
+	watchdog.check()
+	if code, resp := healthz(); code != http.StatusOK || resp["ok"] != true {
+		t.Errorf("healthz after restore = %d %v, want 200 ok", code, resp)
+	}
+	if s.GetDB() != db {
+		t.Error("expected the store to keep its *sql.DB")
+	}
+	var path string
+	if err := db.QueryRow(`SELECT path FROM request_logs`).Scan(&path); err != nil || path != "/restored" {
+		t.Errorf("request_logs after restore = %q, %v; want /restored", path, err)
+	}
+	if err := s.Ping(); err != nil {
+		t.Errorf("Ping() after restore error = %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+// ABOUTME: Minimal YAML encoder for `ish inspect --output yaml`.
+// ABOUTME: Converts values through JSON so keys keep their API response order.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// yamlField is one key of a JSON object, kept in document order
+type yamlField struct {
+	key   string
+	value any
+}
+
+// yamlReserved are plain scalars YAML would read as something other than a string
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true, "~": true,
+}
+
+// writeYAML encodes v as a block-style YAML document. Values are marshaled
+// to JSON first, so struct tags and MarshalJSON methods shape the output
+// exactly as they shape API responses.
+func writeYAML(out io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch node.(type) {
+	case []yamlField, []any:
+		if isEmptyCollection(node) {
+			buf.WriteString(yamlScalar(node) + "\n")
+		} else {
+			writeYAMLNode(&buf, node, 0)
+		}
+	default:
+		buf.WriteString(yamlScalar(node) + "\n")
+	}
+	_, err = out.Write(buf.Bytes())
+	return err
+}
+
+// decodeOrdered reads the next JSON value, keeping object keys in order
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		fields := []yamlField{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, yamlField{key: key.(string), value: value})
+		}
+		_, err = dec.Token()
+		return fields, err
+	case json.Delim('['):
+		items := []any{}
+		for dec.More() {
+			item, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err = dec.Token()
+		return items, err
+	}
+	return tok, nil
+}
+
+// writeYAMLNode writes a non-empty mapping or sequence at the given indent
+func writeYAMLNode(buf *bytes.Buffer, node any, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch n := node.(type) {
+	case []yamlField:
+		for _, f := range n {
+			buf.WriteString(pad + yamlString(f.key) + ":")
+			if isEmptyCollection(f.value) || !isCollection(f.value) {
+				buf.WriteString(" " + yamlScalar(f.value) + "\n")
+				continue
+			}
+			buf.WriteString("\n")
+			writeYAMLNode(buf, f.value, indent+2)
+		}
+	case []any:
+		for _, item := range n {
+			if isEmptyCollection(item) || !isCollection(item) {
+				buf.WriteString(pad + "- " + yamlScalar(item) + "\n")
+				continue
+			}
+			// Render the item one level deeper, then hang its first line off the dash
+			var nested bytes.Buffer
+			writeYAMLNode(&nested, item, indent+2)
+			buf.WriteString(pad + "- " + strings.TrimPrefix(nested.String(), pad+"  "))
+		}
+	}
+}
+
+func isCollection(v any) bool {
+	switch v.(type) {
+	case []yamlField, []any:
+		return true
+	}
+	return false
+}
+
+func isEmptyCollection(v any) bool {
+	switch n := v.(type) {
+	case []yamlField:
+		return len(n) == 0
+	case []any:
+		return len(n) == 0
+	}
+	return false
+}
+
+// yamlScalar renders a JSON scalar, or an empty collection in flow style
+func yamlScalar(v any) string {
+	switch n := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(n)
+	case json.Number:
+		return n.String()
+	case string:
+		return yamlString(n)
+	case []yamlField:
+		return "{}"
+	case []any:
+		return "[]"
+	}
+	return fmt.Sprint(v)
+}
+
+// yamlString quotes s when YAML would otherwise misread it as a plain scalar
+func yamlString(s string) string {
+	if s == "" || yamlReserved[strings.ToLower(s)] ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@` \t") ||
+		strings.HasSuffix(s, " ") || strings.HasSuffix(s, ":") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.ContainsFunc(s, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
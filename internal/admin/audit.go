@@ -0,0 +1,45 @@
+// ABOUTME: Admin endpoint for the plugin audit log.
+// ABOUTME: Lists recorded creates, updates, and deletes filtered by plugin, resource type, and actor.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// auditList returns audit entries newest first, e.g. GET /admin/audit?plugin=linear&resource_type=issue&actor=alice
+func (h *Handlers) auditList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := core.AuditQuery{
+		PluginName:   q.Get("plugin"),
+		ResourceType: q.Get("resource_type"),
+		Actor:        q.Get("actor"),
+	}
+	for name, dst := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+		raw := q.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid "+name, http.StatusBadRequest)
+			return
+		}
+		*dst = n
+	}
+	if query.Limit > 1000 {
+		query.Limit = 1000
+	}
+
+	entries, err := core.ListAudit(h.store.GetDB(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"entries": entries})
+}
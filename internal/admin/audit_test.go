@@ -0,0 +1,63 @@
+// ABOUTME: Tests for the audit log admin endpoint.
+// ABOUTME: Checks filtering by plugin, resource type, and actor.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func TestAuditList(t *testing.T) {
+	s, r := newScenarioRouter(t)
+	s.GetDB().SetMaxOpenConns(1)
+
+	for _, e := range []core.AuditEntry{
+		{PluginName: "linear", ResourceType: "issue", ResourceID: "ENG-1", Action: core.AuditCreate, Actor: "alice"},
+		{PluginName: "linear", ResourceType: "team", ResourceID: "ENG", Action: core.AuditCreate, Actor: "bob"},
+		{PluginName: "jira", ResourceType: "issue", ResourceID: "PROJ-1", Action: core.AuditUpdate, Actor: "alice"},
+	} {
+		if err := core.LogAudit(s.GetDB(), e); err != nil {
+			t.Fatalf("LogAudit failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 3},
+		{"?plugin=linear", 2},
+		{"?plugin=linear&resource_type=issue", 1},
+		{"?resource_type=issue", 2},
+		{"?actor=alice", 2},
+		{"?actor=carol", 0},
+		{"?limit=1", 1},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/audit"+tt.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: expected 200, got %d: %s", tt.query, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Entries []core.AuditEntry `json:"entries"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("%q: invalid response: %v", tt.query, err)
+		}
+		if len(resp.Entries) != tt.want {
+			t.Errorf("%q: expected %d entries, got %d", tt.query, tt.want, len(resp.Entries))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/audit?limit=lots", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid limit, got %d", w.Code)
+	}
+}
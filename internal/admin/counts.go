@@ -0,0 +1,99 @@
+// ABOUTME: Record count badges for the admin navigation.
+// ABOUTME: Counts each plugin's primary tables and caches the result briefly.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// countsCacheTTL bounds how often nav badges hit the database
+const countsCacheTTL = 5 * time.Second
+
+// tableNamePattern guards the table names interpolated into COUNT(*) queries
+var tableNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// NavCount is a record count badge for one admin nav item
+type NavCount struct {
+	Key   string
+	Count int
+	URL   string
+}
+
+// countsCache holds the most recent nav counts
+type countsCache struct {
+	mu      sync.Mutex
+	fetched time.Time
+	counts  []NavCount
+}
+
+// apiCounts returns record counts as JSON, or as nav badge HTML for htmx polling
+func (h *Handlers) apiCounts(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.navCounts()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html")
+		renderPartial(w, r, "nav-counts", counts)
+		return
+	}
+
+	result := make(map[string]int, len(counts))
+	for _, c := range counts {
+		result[c.Key] = c.Count
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// navCounts returns cached counts, refreshing them once they are older than countsCacheTTL
+func (h *Handlers) navCounts() ([]NavCount, error) {
+	h.counts.mu.Lock()
+	defer h.counts.mu.Unlock()
+
+	now := h.clock.Now()
+	if h.counts.counts != nil && now.Sub(h.counts.fetched) < countsCacheTTL {
+		return h.counts.counts, nil
+	}
+
+	counts := []NavCount{}
+	for _, plugin := range core.All() {
+		counted, ok := plugin.(core.CountedPlugin)
+		if !ok {
+			continue
+		}
+		for _, t := range counted.CountedTables() {
+			if !tableNamePattern.MatchString(t.Table) {
+				return nil, fmt.Errorf("plugin %s: invalid table name %q", plugin.Name(), t.Table)
+			}
+			var n int
+			if err := h.store.GetDB().QueryRow("SELECT COUNT(*) FROM " + t.Table).Scan(&n); err != nil {
+				// Plugins that haven't created their tables yet simply have no badge
+				log.Printf("Counting %s for %s: %v", t.Table, plugin.Name(), err)
+				continue
+			}
+			counts = append(counts, NavCount{
+				Key:   t.Key,
+				Count: n,
+				URL:   fmt.Sprintf("/admin/plugins/%s/%s", plugin.Name(), t.Resource),
+			})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Key < counts[j].Key })
+
+	h.counts.counts = counts
+	h.counts.fetched = now
+	return counts, nil
+}
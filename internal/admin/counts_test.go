@@ -0,0 +1,103 @@
+// ABOUTME: Tests for the admin nav record count badges.
+// ABOUTME: Verifies counts are cached briefly and refresh after new records are created.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+)
+
+// countedMockPlugin exposes one counted table for badge tests
+type countedMockPlugin struct {
+	dashboardMockPlugin
+}
+
+func (m *countedMockPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{{Key: "widgets", Table: "counted_widgets", Resource: "widgets"}}
+}
+
+var countedPluginRegistered = false
+
+func setupCountedPlugin() {
+	if countedPluginRegistered {
+		return
+	}
+	core.Register(&countedMockPlugin{dashboardMockPlugin{name: "counted"}})
+	countedPluginRegistered = true
+}
+
+func getCounts(t *testing.T, h *Handlers) map[string]int {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/admin/api/counts", nil)
+	w := httptest.NewRecorder()
+	h.apiCounts(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var counts map[string]int
+	if err := json.NewDecoder(w.Body).Decode(&counts); err != nil {
+		t.Fatalf("failed to decode counts: %v", err)
+	}
+	return counts
+}
+
+func TestAPICountsCachesAndRefreshes(t *testing.T) {
+	setupCountedPlugin()
+
+	// A frozen store clock, as with ISH_FROZEN_TIME, must not stall the cache
+	core.SetClock(core.NewFrozenClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	defer core.SetClock(nil)
+
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	db := s.GetDB()
+	if _, err := db.Exec(`CREATE TABLE counted_widgets (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO counted_widgets DEFAULT VALUES`); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	h := NewHandlers(s)
+	clock := core.NewFrozenClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	h.clock = clock
+	if got := getCounts(t, h)["widgets"]; got != 1 {
+		t.Fatalf("expected 1 widget, got %d", got)
+	}
+
+	if _, err := db.Exec(`INSERT INTO counted_widgets DEFAULT VALUES`); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	if got := getCounts(t, h)["widgets"]; got != 1 {
+		t.Errorf("expected cached count of 1, got %d", got)
+	}
+
+	clock.Advance(countsCacheTTL + time.Second)
+	if got := getCounts(t, h)["widgets"]; got != 2 {
+		t.Errorf("expected refreshed count of 2, got %d", got)
+	}
+
+	req := httptest.NewRequest("GET", "/admin/api/counts", nil)
+	req.Header.Set("HX-Request", "true")
+	w := httptest.NewRecorder()
+	h.apiCounts(w, req)
+	body := w.Body.String()
+	if !strings.Contains(body, `href="/admin/plugins/counted/widgets"`) {
+		t.Errorf("expected badge link to widgets resource, got %s", body)
+	}
+	if !strings.Contains(body, `data-count="widgets">2</span>`) {
+		t.Errorf("expected badge showing 2 widgets, got %s", body)
+	}
+}
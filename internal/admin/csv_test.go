@@ -0,0 +1,88 @@
+// ABOUTME: Tests for admin CSV exports.
+// ABOUTME: Exports request logs through the router and parses the result with encoding/csv.
+
+package admin
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestExportLogsCSV(t *testing.T) {
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	ts := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, entry := range []*store.RequestLog{
+		{PluginName: "github", Method: "POST", Path: "/repos/a,b/issues", StatusCode: 201, DurationMs: 42, UserID: "alice", IPAddress: "10.0.0.1", Timestamp: ts},
+		{PluginName: "slack", Method: "GET", Path: "/api/chat", StatusCode: 200, DurationMs: 5, Timestamp: ts},
+	} {
+		if err := s.LogRequest(entry); err != nil {
+			t.Fatalf("Failed to insert test log: %v", err)
+		}
+	}
+
+	r := chi.NewRouter()
+	NewHandlers(s).RegisterRoutes(r)
+
+	req := httptest.NewRequest("GET", "/admin/logs.csv?plugin=github", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="request-logs.csv"` {
+		t.Errorf("Unexpected Content-Disposition %q", cd)
+	}
+
+	body := w.Body.String()
+	if !strings.Contains(body, `,"/repos/a,b/issues",201,42,`) {
+		t.Errorf("Expected numbers unquoted and commas quoted, got:\n%s", body)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected header and 1 filtered row, got %d records", len(records))
+	}
+	want := []string{"Timestamp", "Plugin", "Method", "Path", "Status", "Duration", "User", "IP"}
+	if strings.Join(records[0], ",") != strings.Join(want, ",") {
+		t.Errorf("Expected headers %v, got %v", want, records[0])
+	}
+	if records[1][0] != "2025-03-01T12:00:00Z" || records[1][1] != "github" || records[1][6] != "alice" {
+		t.Errorf("Unexpected row %v", records[1])
+	}
+}
+
+func TestSchemaCSVUsesDisplayColumns(t *testing.T) {
+	exporter := schemaCSV{schema: core.ResourceSchema{
+		ListColumns: []string{"name", "count"},
+		Fields: []core.FieldSchema{
+			{Name: "name", Type: "string", Display: "Name"},
+			{Name: "count", Type: "number", Display: "Count"},
+			{Name: "secret", Type: "string", Display: "Secret"},
+		},
+	}}
+
+	if got := strings.Join(exporter.Header(), ","); got != "Name,Count" {
+		t.Errorf("Expected display headers, got %q", got)
+	}
+	row := exporter.ToCSVRow(map[string]interface{}{"name": "widget", "count": float64(1500000), "secret": "x"})
+	if got := strings.Join(row, ","); got != "widget,1500000" {
+		t.Errorf("Expected list columns in order with plain numbers, got %q", got)
+	}
+}
@@ -0,0 +1,211 @@
+// ABOUTME: Line diffs between logged response bodies, using the Myers algorithm.
+// ABOUTME: Backs the side-by-side comparison page at /admin/logs/{id}/diff.
+
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// DiffOp says whether a diff line is in both texts, only the replay, or only the original
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffLine is one line of a diff. OldLine and NewLine are 1-based line
+// numbers in the original and replay, or 0 when the line isn't in that text.
+type DiffLine struct {
+	Op      DiffOp
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// splitLines splits text into lines, treating empty text as no lines and
+// ignoring a trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// computeDiff returns a shortest line diff turning original into replay,
+// found with Myers' O(ND) algorithm
+func computeDiff(original, replay string) []DiffLine {
+	a, b := splitLines(original), splitLines(replay)
+	n, m := len(a), len(b)
+	offset := n + m
+
+	// v[offset+k] is the furthest x reached on diagonal k (k = x - y). trace
+	// keeps v as it was before each round d, for walking the path back.
+	v := make([]int, 2*(n+m)+2)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // step down: insert from replay
+			} else {
+				x = v[offset+k-1] + 1 // step right: delete from original
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b, offset)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrackDiff walks the recorded Myers rounds back from the end of both
+// texts, emitting the edit script in order
+func backtrackDiff(trace [][]int, a, b []string, offset int) []DiffLine {
+	var lines []DiffLine
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			lines = append(lines, DiffLine{Op: DiffEqual, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				lines = append(lines, DiffLine{Op: DiffInsert, Text: b[y-1], NewLine: y})
+			} else {
+				lines = append(lines, DiffLine{Op: DiffDelete, Text: a[x-1], OldLine: x})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+// DiffRow is one row of a side-by-side diff; a nil side is blank
+type DiffRow struct {
+	Left  *DiffLine
+	Right *DiffLine
+}
+
+// sideBySide lays a diff out in two columns, pairing each run of deletions
+// with the insertions that follow it
+func sideBySide(lines []DiffLine) []DiffRow {
+	var rows []DiffRow
+	for i := 0; i < len(lines); {
+		if lines[i].Op == DiffEqual {
+			rows = append(rows, DiffRow{Left: &lines[i], Right: &lines[i]})
+			i++
+			continue
+		}
+
+		var deleted, inserted []*DiffLine
+		for ; i < len(lines) && lines[i].Op == DiffDelete; i++ {
+			deleted = append(deleted, &lines[i])
+		}
+		for ; i < len(lines) && lines[i].Op == DiffInsert; i++ {
+			inserted = append(inserted, &lines[i])
+		}
+		for j := 0; j < len(deleted) || j < len(inserted); j++ {
+			var row DiffRow
+			if j < len(deleted) {
+				row.Left = deleted[j]
+			}
+			if j < len(inserted) {
+				row.Right = inserted[j]
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// logsDiff compares the response bodies of two logged requests, the one in
+// the URL as the original and ?compare= as the replay
+func (h *Handlers) logsDiff(w http.ResponseWriter, r *http.Request) {
+	original, ok := h.diffLog(w, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+	if r.URL.Query().Get("compare") == "" {
+		http.Error(w, "compare parameter is required", http.StatusBadRequest)
+		return
+	}
+	replay, ok := h.diffLog(w, r.URL.Query().Get("compare"))
+	if !ok {
+		return
+	}
+
+	// Pretty-printing puts JSON on stable lines, with object keys sorted
+	lines := computeDiff(prettyJSON(original.ResponseBody), prettyJSON(replay.ResponseBody))
+	var added, removed int
+	for _, line := range lines {
+		switch line.Op {
+		case DiffInsert:
+			added++
+		case DiffDelete:
+			removed++
+		}
+	}
+
+	data := map[string]any{
+		"Original": original,
+		"Replay":   replay,
+		"Rows":     sideBySide(lines),
+		"Added":    added,
+		"Removed":  removed,
+	}
+	if err := renderPage(w, r, "logs-diff", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// diffLog loads a request log by its ID as written in the URL, writing an
+// error response if it is malformed or missing
+func (h *Handlers) diffLog(w http.ResponseWriter, rawID string) (*store.RequestLog, bool) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid log id %q", rawID), http.StatusBadRequest)
+		return nil, false
+	}
+	entry, err := h.store.GetRequestLog(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, fmt.Sprintf("request log %d not found", id), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return entry, true
+}
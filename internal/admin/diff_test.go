@@ -0,0 +1,148 @@
+// ABOUTME: Tests for response diffs between request logs.
+// ABOUTME: Checks the Myers diff on JSON payloads and the side-by-side diff page.
+
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// applyDiff rebuilds both texts from a diff, which must give back the inputs
+func applyDiff(lines []DiffLine) (original, replay []string) {
+	for _, line := range lines {
+		if line.Op != DiffInsert {
+			original = append(original, line.Text)
+		}
+		if line.Op != DiffDelete {
+			replay = append(replay, line.Text)
+		}
+	}
+	return original, replay
+}
+
+func TestComputeDiffJSON(t *testing.T) {
+	original := prettyJSON(`{"id": 7, "status": "open", "labels": ["bug"], "title": "Crash on save"}`)
+	replay := prettyJSON(`{"id": 7, "status": "closed", "labels": ["bug", "fixed"], "title": "Crash on save"}`)
+
+	lines := computeDiff(original, replay)
+
+	gotOriginal, gotReplay := applyDiff(lines)
+	if strings.Join(gotOriginal, "\n") != original || strings.Join(gotReplay, "\n") != replay {
+		t.Fatalf("Diff does not reproduce its inputs:\n%v", lines)
+	}
+
+	var inserted, deleted []string
+	for _, line := range lines {
+		switch line.Op {
+		case DiffInsert:
+			inserted = append(inserted, strings.TrimSpace(line.Text))
+		case DiffDelete:
+			deleted = append(deleted, strings.TrimSpace(line.Text))
+		}
+	}
+	// "bug" gains a trailing comma when "fixed" follows it
+	wantDeleted := []string{`"bug"`, `"status": "open",`}
+	wantInserted := []string{`"bug",`, `"fixed"`, `"status": "closed",`}
+	if strings.Join(deleted, "|") != strings.Join(wantDeleted, "|") {
+		t.Errorf("Deleted lines = %q, want %q", deleted, wantDeleted)
+	}
+	if strings.Join(inserted, "|") != strings.Join(wantInserted, "|") {
+		t.Errorf("Inserted lines = %q, want %q", inserted, wantInserted)
+	}
+
+	for _, line := range lines {
+		if line.Op == DiffEqual && strings.Contains(line.Text, `"title"`) && (line.OldLine != 7 || line.NewLine != 8) {
+			t.Errorf("title line numbers = %d/%d, want 7/8", line.OldLine, line.NewLine)
+		}
+	}
+}
+
+func TestComputeDiffEdgeCases(t *testing.T) {
+	tests := []struct {
+		name             string
+		original, replay string
+		want             string // one op initial per line
+	}{
+		{"both empty", "", "", ""},
+		{"identical", "{\n  \"ok\": true\n}", "{\n  \"ok\": true\n}", "eee"},
+		{"all added", "", "{}", "i"},
+		{"all removed", "{}", "", "d"},
+		{"replaced", `{"a": 1}`, `{"a": 2}`, "di"},
+		{"trailing newline ignored", "{}\n", "{}", "e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got strings.Builder
+			for _, line := range computeDiff(tt.original, tt.replay) {
+				got.WriteByte(string(line.Op)[0])
+			}
+			if got.String() != tt.want {
+				t.Errorf("computeDiff ops = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSideBySidePairsChanges(t *testing.T) {
+	rows := sideBySide(computeDiff("a\nb\nc\nd", "a\nB\nC\nX\nd"))
+	if len(rows) != 5 {
+		t.Fatalf("Expected 5 rows, got %d", len(rows))
+	}
+	// b and c are paired with B and C; X has nothing opposite it
+	if rows[1].Left.Text != "b" || rows[1].Right.Text != "B" || rows[2].Left.Text != "c" || rows[2].Right.Text != "C" {
+		t.Errorf("Changed lines not paired: %+v %+v", rows[1], rows[2])
+	}
+	if rows[3].Left != nil || rows[3].Right.Text != "X" {
+		t.Errorf("Expected an unpaired insertion, got %+v", rows[3])
+	}
+}
+
+func TestLogsDiffPage(t *testing.T) {
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	for _, body := range []string{`{"state": "open", "number": 1}`, `{"state": "closed", "number": 1}`} {
+		if err := s.LogRequest(&store.RequestLog{PluginName: "github", Method: "GET", Path: "/repos/a/b/issues/1", StatusCode: 200, ResponseBody: body}); err != nil {
+			t.Fatalf("Failed to insert test log: %v", err)
+		}
+	}
+
+	r := chi.NewRouter()
+	NewHandlers(s).RegisterRoutes(r)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/admin/logs/1/diff?compare=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	for _, want := range []string{`bg-red-100 text-red-800">  &#34;state&#34;: &#34;open&#34;`, `bg-green-100 text-green-800">  &#34;state&#34;: &#34;closed&#34;`, "+1", "&minus;1"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Diff page missing %q", want)
+		}
+	}
+
+	for path, code := range map[string]int{
+		"/admin/logs/1/diff":           http.StatusBadRequest,
+		"/admin/logs/1/diff?compare=x": http.StatusBadRequest,
+		"/admin/logs/1/diff?compare=9": http.StatusNotFound,
+		"/admin/logs/9/diff?compare=1": http.StatusNotFound,
+	} {
+		if w := get(path); w.Code != code {
+			t.Errorf("GET %s = %d, want %d", path, w.Code, code)
+		}
+	}
+}
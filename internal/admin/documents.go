@@ -0,0 +1,105 @@
+// ABOUTME: Generic JSON Patch endpoint for JSON-backed plugin resources.
+// ABOUTME: Applies RFC 6902 patches to documents exposed through core.DocumentAdmin.
+
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// jsonPatchContentType is the media type required for PATCH /admin/api/{type}/{id}
+const jsonPatchContentType = "application/json-patch+json"
+
+// documentAdmin finds the plugin serving a document type
+func documentAdmin(docType string) (core.DocumentAdmin, bool) {
+	for _, plugin := range core.All() {
+		if docs, ok := plugin.(core.DocumentAdmin); ok && slices.Contains(docs.DocumentTypes(), docType) {
+			return docs, true
+		}
+	}
+	return nil, false
+}
+
+// apiPatchDocument applies a JSON Patch to a stored document and returns the result
+func (h *Handlers) apiPatchDocument(w http.ResponseWriter, r *http.Request) {
+	docType := chi.URLParam(r, "type")
+	id := chi.URLParam(r, "id")
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != jsonPatchContentType {
+		documentError(w, http.StatusUnsupportedMediaType, "Content-Type must be "+jsonPatchContentType)
+		return
+	}
+
+	docs, ok := documentAdmin(docType)
+	if !ok {
+		documentError(w, http.StatusNotFound, fmt.Sprintf("No plugin serves %q documents", docType))
+		return
+	}
+
+	var ops []patchOperation
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		documentError(w, http.StatusBadRequest, "Invalid JSON Patch: "+err.Error())
+		return
+	}
+
+	for _, op := range ops {
+		for _, protected := range docs.ImmutablePaths(docType) {
+			if touchesPath(op.Path, protected) {
+				documentError(w, http.StatusUnprocessableEntity, fmt.Sprintf("%s is immutable", protected))
+				return
+			}
+		}
+	}
+
+	doc, err := docs.GetDocument(r.Context(), docType, id)
+	if errors.Is(err, core.ErrDocumentNotFound) {
+		documentError(w, http.StatusNotFound, fmt.Sprintf("%s %q not found", docType, id))
+		return
+	}
+	if err != nil {
+		documentError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	patched, err := applyJSONPatch(doc, ops)
+	if err != nil {
+		documentError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	patchedDoc, ok := patched.(map[string]any)
+	if !ok {
+		documentError(w, http.StatusBadRequest, "Patched document must be a JSON object")
+		return
+	}
+
+	err = docs.PutDocument(r.Context(), docType, id, patchedDoc)
+	if errors.Is(err, core.ErrInvalidDocument) {
+		documentError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		documentError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"type": docType,
+		"id":   id,
+		"data": patchedDoc,
+	})
+}
+
+func documentError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": message})
+}
@@ -5,32 +5,70 @@ package admin
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/2389/ish/internal/logging"
 	"github.com/2389/ish/internal/store"
 	"github.com/2389/ish/plugins/core"
 	"github.com/go-chi/chi/v5"
 )
 
 type Handlers struct {
-	store *store.Store
+	store     *store.Store
+	counts    *countsCache
+	metrics   *metricsCache
+	undo      *undoStore
+	logStream *logging.Stream
+
+	// clock ages the counts and metrics caches and sets the metrics window.
+	// It reads wall time so ISH_FROZEN_TIME, which only freezes stored
+	// timestamps, can't stop the caches from expiring.
+	clock core.Clock
 }
 
 func NewHandlers(s *store.Store) *Handlers {
-	return &Handlers{store: s}
+	if s != nil {
+		if err := dropOrphanedSnapshots(s.GetDB()); err != nil {
+			log.Printf("Dropping orphaned undo snapshots: %v", err)
+		}
+	}
+	return &Handlers{
+		store:   s,
+		clock:   core.RealClock{},
+		counts:  &countsCache{},
+		metrics: &metricsCache{},
+		undo:    &undoStore{snapshots: make(map[string]*undoSnapshot)},
+	}
 }
 
 func (h *Handlers) RegisterRoutes(r chi.Router) {
+	// Plugin metrics for external dashboards as well as the admin UI
+	r.Get("/api/metrics", h.apiMetrics)
+
 	r.Route("/admin", func(r chi.Router) {
 		r.Get("/", h.dashboard)
 		r.Get("/guide", h.guide)
+		r.Get("/api/counts", h.apiCounts)
+		r.Get("/api/stats", h.apiStats)
+		r.Patch("/api/{type}/{id}", h.apiPatchDocument)
 
-		// Redirect old Google routes to new plugin routes
+		// Gmail messages are managed directly through core.MailAdmin
 		r.Get("/gmail", h.redirectToPluginRoute("/admin/plugins/google/messages"))
-		r.Get("/gmail/new", h.redirectToPluginRoute("/admin/plugins/google/messages/new"))
-		r.Get("/gmail/{id}", h.redirectGmailView)
+		r.Post("/gmail", h.gmailCreate)
+		r.Get("/gmail/new", h.gmailForm)
+		r.Post("/gmail/import/mbox", h.gmailImportMbox)
+		r.Get("/gmail/{id}", h.gmailView)
+		r.Delete("/gmail/{id}", h.gmailDelete)
+		r.Patch("/gmail/{id}/labels", h.gmailUpdateLabels)
+
+		// Redirect old Google routes to new plugin routes
 		r.Get("/calendar", h.redirectToPluginRoute("/admin/plugins/google/events"))
 		r.Get("/calendar/new", h.redirectToPluginRoute("/admin/plugins/google/events/new"))
 		r.Get("/calendar/{id}", h.redirectCalendarView)
@@ -42,6 +80,23 @@ func (h *Handlers) RegisterRoutes(r chi.Router) {
 		r.Get("/tasks/{id}", h.redirectTasksView)
 
 		r.Get("/logs", h.logsList)
+		r.Get("/logs.csv", h.exportLogsCSV)
+		r.Get("/logs/stats", h.logsStats)
+		r.Get("/logs/stream", h.logsStream)
+		r.Get("/logs/{id}/diff", h.logsDiff)
+		r.Post("/logs/prune", h.logsPrune)
+		r.Get("/audit", h.auditList)
+
+		// Scenario bundles for replaying a captured session on another instance
+		r.Get("/export/scenario", h.exportScenario)
+		r.Post("/import/scenario", h.importScenario)
+		r.Get("/{plugin}/{resource}.csv", h.exportResourceCSV)
+		r.Patch("/{plugin}/{resource}/{id}", h.patchResourceFields)
+
+		// Bulk delete by plugin or resource type, undoable for undoTTL
+		r.Delete("/all", h.truncateAll)
+		r.Delete("/{target}", h.truncatePlugin)
+		r.Post("/undo/{token}", h.undoTruncate)
 	})
 
 	// Register plugin admin routes
@@ -54,7 +109,7 @@ func (h *Handlers) dashboard(w http.ResponseWriter, r *http.Request) {
 	plugins := getPluginDashboardData(h.store)
 
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "dashboard", map[string]any{
+	renderPage(w, r, "dashboard", map[string]any{
 		"MessageCount": 0, // Stats aggregation not implemented - view resources directly
 		"ThreadCount":  0, // Stats aggregation not implemented - view resources directly
 		"EventCount":   0, // Stats aggregation not implemented - view resources directly
@@ -67,46 +122,178 @@ func (h *Handlers) dashboard(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handlers) guide(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "guide", nil)
+	renderPage(w, r, "guide", nil)
 }
 
 func (h *Handlers) gmailList(w http.ResponseWriter, r *http.Request) {
 	// TODO: Access Google plugin store directly
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "gmail-list", map[string]any{
+	renderPage(w, r, "gmail-list", map[string]any{
 		"Messages": []any{},
 	})
 }
 
 func (h *Handlers) gmailView(w http.ResponseWriter, r *http.Request) {
-	// TODO: Access Google plugin store directly
-	http.Error(w, "Admin UI is view-only - use API endpoints for detailed operations", http.StatusNotImplemented)
+	mail, ok := mailAdmin(w)
+	if !ok {
+		return
+	}
+	msg, err := mail.GetMailMessage(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		mailError(w, err)
+		return
+	}
+	labels, err := mail.ListMailLabels(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	renderPage(w, r, "gmail-view", map[string]any{
+		"Message":      msg,
+		"Received":     time.UnixMilli(msg.InternalDate).UTC().Format(time.RFC1123Z),
+		"LabelOptions": labelOptions(labels, msg.LabelIDs),
+	})
 }
 
 func (h *Handlers) gmailDelete(w http.ResponseWriter, r *http.Request) {
-	// TODO: Access Google plugin store directly
-	http.Error(w, "Admin UI is view-only - use API endpoints for detailed operations", http.StatusNotImplemented)
+	mail, ok := mailAdmin(w)
+	if !ok {
+		return
+	}
+	if err := mail.DeleteMailMessage(r.Context(), chi.URLParam(r, "id")); err != nil {
+		mailError(w, err)
+		return
+	}
+	// Empty 200 lets htmx remove the row with hx-swap="delete"
+	w.WriteHeader(http.StatusOK)
 }
 
 func (h *Handlers) gmailForm(w http.ResponseWriter, r *http.Request) {
+	mail, ok := mailAdmin(w)
+	if !ok {
+		return
+	}
+	labels, err := mail.ListMailLabels(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "gmail-form", nil)
+	renderPage(w, r, "gmail-form", map[string]any{
+		"LabelOptions": labelOptions(labels, []string{"INBOX"}),
+	})
 }
 
 func (h *Handlers) gmailCreate(w http.ResponseWriter, r *http.Request) {
-	// TODO: Access Google plugin store directly
-	http.Error(w, "Admin UI is view-only - use API endpoints for detailed operations", http.StatusNotImplemented)
+	mail, ok := mailAdmin(w)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	input := core.MailMessageInput{
+		From:    strings.TrimSpace(r.FormValue("from")),
+		To:      strings.TrimSpace(r.FormValue("to")),
+		Subject: strings.TrimSpace(r.FormValue("subject")),
+		Body:    r.FormValue("body"),
+		Labels:  r.Form["labels"],
+	}
+	if input.From == "" || input.Subject == "" {
+		http.Error(w, "From and Subject are required", http.StatusBadRequest)
+		return
+	}
+	if input.Labels == nil {
+		input.Labels = []string{}
+	}
+
+	id, err := mail.CreateMailMessage(r.Context(), input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, core.BasePath(r)+"/admin/gmail/"+id, http.StatusSeeOther)
+}
+
+func (h *Handlers) gmailUpdateLabels(w http.ResponseWriter, r *http.Request) {
+	mail, ok := mailAdmin(w)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := mail.SetMailMessageLabels(r.Context(), id, r.Form["labels"]); err != nil {
+		mailError(w, err)
+		return
+	}
+	msg, err := mail.GetMailMessage(r.Context(), id)
+	if err != nil {
+		mailError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	renderPartial(w, r, "gmail-labels", msg)
+}
+
+// mailAdmin finds the Google plugin's message management interface,
+// writing a 503 if the plugin isn't registered or doesn't support it
+func mailAdmin(w http.ResponseWriter) (core.MailAdmin, bool) {
+	if p, ok := core.Get("google"); ok {
+		if mail, ok := p.(core.MailAdmin); ok {
+			return mail, true
+		}
+	}
+	http.Error(w, "Gmail admin is unavailable - the google plugin is not loaded", http.StatusServiceUnavailable)
+	return nil, false
+}
+
+// mailError maps MailAdmin errors to HTTP responses
+func mailError(w http.ResponseWriter, err error) {
+	if errors.Is(err, core.ErrMessageNotFound) {
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// LabelOption is a label choice in the Gmail label multi-select
+type LabelOption struct {
+	Name     string
+	Selected bool
+}
+
+// labelOptions marks which of the available labels are currently selected
+func labelOptions(available, selected []string) []LabelOption {
+	isSelected := make(map[string]bool, len(selected))
+	for _, l := range selected {
+		isSelected[l] = true
+	}
+	options := make([]LabelOption, len(available))
+	for i, l := range available {
+		options[i] = LabelOption{Name: l, Selected: isSelected[l]}
+	}
+	return options
 }
 
 func (h *Handlers) calendarList(w http.ResponseWriter, r *http.Request) {
 	// TODO: Access Google plugin store directly
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "calendar-list", map[string]any{"Events": []any{}})
+	renderPage(w, r, "calendar-list", map[string]any{"Events": []any{}})
 }
 
 func (h *Handlers) calendarForm(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "calendar-form", nil)
+	renderPage(w, r, "calendar-form", nil)
 }
 
 func (h *Handlers) calendarCreate(w http.ResponseWriter, r *http.Request) {
@@ -127,12 +314,12 @@ func (h *Handlers) calendarDelete(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) peopleList(w http.ResponseWriter, r *http.Request) {
 	// TODO: Access Google plugin store directly
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "people-list", map[string]any{"People": []any{}})
+	renderPage(w, r, "people-list", map[string]any{"People": []any{}})
 }
 
 func (h *Handlers) peopleForm(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "people-form", nil)
+	renderPage(w, r, "people-form", nil)
 }
 
 func (h *Handlers) peopleCreate(w http.ResponseWriter, r *http.Request) {
@@ -168,12 +355,12 @@ func (h *Handlers) peopleGenerate(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) tasksList(w http.ResponseWriter, r *http.Request) {
 	// TODO: Access Google plugin store directly
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "tasks-list", map[string]any{"Tasks": []any{}})
+	renderPage(w, r, "tasks-list", map[string]any{"Tasks": []any{}})
 }
 
 func (h *Handlers) tasksForm(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "tasks-form", nil)
+	renderPage(w, r, "tasks-form", nil)
 }
 
 func (h *Handlers) tasksCreate(w http.ResponseWriter, r *http.Request) {
@@ -196,28 +383,74 @@ func (h *Handlers) tasksGenerate(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Admin UI is view-only - use API endpoints for detailed operations", http.StatusNotImplemented)
 }
 
+// Log pagination defaults
+const (
+	defaultLogsPerPage = 50
+	maxLogsPerPage     = 500
+)
+
 func (h *Handlers) logsList(w http.ResponseWriter, r *http.Request) {
-	// Get filter parameters
-	pluginName := r.URL.Query().Get("plugin")
-	method := r.URL.Query().Get("method")
-	pathPrefix := r.URL.Query().Get("path")
-	statusCode := 0
-	if sc := r.URL.Query().Get("status"); sc != "" {
-		fmt.Sscanf(sc, "%d", &statusCode)
+	page := positiveIntParam(r, "page", 1)
+	perPage := positiveIntParam(r, "per_page", defaultLogsPerPage)
+	if perPage > maxLogsPerPage {
+		perPage = maxLogsPerPage
 	}
 
-	logs, err := h.store.GetRequestLogs(&store.RequestLogQuery{
-		Limit:      100,
-		Offset:     0,
-		PluginName: pluginName,
-		Method:     method,
-		PathPrefix: pathPrefix,
-		StatusCode: statusCode,
-	})
+	query := logFilterQuery(r)
+
+	total, err := h.store.CountRequestLogs(query)
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
 	}
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	// The first page and next links page by keyset cursor, so paging forward
+	// stays stable while new requests arrive; jumping to a page number falls
+	// back to OFFSET
+	var logs []*store.RequestLog
+	var next *store.LogCursor
+	after := r.URL.Query().Get("after")
+	if after != "" || page == 1 {
+		var cursor *store.LogCursor
+		if after != "" {
+			if cursor, err = store.DecodeLogCursor(after); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		entries, nextCursor, err := h.store.GetFilteredRequestLogsPaginated(query, cursor, perPage)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		for i := range entries {
+			logs = append(logs, &entries[i])
+		}
+		next = nextCursor
+	} else {
+		query.Limit = perPage
+		query.Offset = (page - 1) * perPage
+		if logs, err = h.store.GetRequestLogs(query); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		if page < totalPages && len(logs) > 0 {
+			next = logs[len(logs)-1].Cursor()
+		}
+	}
+
+	pagination := newLogPagination(r, page, perPage, total, totalPages, next)
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if link := pagination.linkHeader(); link != "" {
+		w.Header().Set("Link", link)
+	}
 
 	// Pretty-print JSON in request/response bodies
 	for _, log := range logs {
@@ -241,15 +474,112 @@ func (h *Handlers) logsList(w http.ResponseWriter, r *http.Request) {
 	pluginNames := core.Names()
 
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "logs-list", map[string]any{
+	renderPage(w, r, "logs-list", map[string]any{
 		"Logs":           logs,
 		"Stats":          stats,
 		"TopEndpoints":   topEndpoints,
 		"PluginNames":    pluginNames,
-		"SelectedPlugin": pluginName,
+		"SelectedPlugin": query.PluginName,
+		"Pagination":     pagination,
+		"CSVURL":         logsCSVURL(r),
 	})
 }
 
+// LogPagination holds page numbers and navigation URLs for the logs page
+type LogPagination struct {
+	Page       int
+	PerPage    int
+	Total      int
+	TotalPages int
+	FirstURL   string
+	PrevURL    string
+	NextURL    string
+	LastURL    string
+}
+
+// newLogPagination builds navigation URLs that preserve the current filters,
+// linking the next page through next when there is one
+func newLogPagination(r *http.Request, page, perPage, total, totalPages int, next *store.LogCursor) *LogPagination {
+	pageURL := func(n int, after string) string {
+		q := r.URL.Query()
+		q.Del("after")
+		q.Set("page", strconv.Itoa(n))
+		q.Set("per_page", strconv.Itoa(perPage))
+		if after != "" {
+			q.Set("after", after)
+		}
+		return core.BasePath(r) + "/admin/logs?" + q.Encode()
+	}
+
+	p := &LogPagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+		FirstURL:   pageURL(1, ""),
+		LastURL:    pageURL(totalPages, ""),
+	}
+	if page > 1 {
+		p.PrevURL = pageURL(page-1, "")
+	}
+	if next != nil {
+		p.NextURL = pageURL(page+1, next.Encode())
+	}
+	return p
+}
+
+// linkHeader renders the navigation URLs as an RFC 8288 Link header
+func (p *LogPagination) linkHeader() string {
+	var links []string
+	for _, l := range []struct{ url, rel string }{
+		{p.FirstURL, "first"},
+		{p.PrevURL, "prev"},
+		{p.NextURL, "next"},
+		{p.LastURL, "last"},
+	} {
+		if l.url != "" {
+			links = append(links, fmt.Sprintf("<%s>; rel=\"%s\"", l.url, l.rel))
+		}
+	}
+	return strings.Join(links, ", ")
+}
+
+// logFilterQuery builds a log query from the plugin, method, path, and status filters
+func logFilterQuery(r *http.Request) *store.RequestLogQuery {
+	statusCode := 0
+	if sc := r.URL.Query().Get("status"); sc != "" {
+		fmt.Sscanf(sc, "%d", &statusCode)
+	}
+	return &store.RequestLogQuery{
+		PluginName: r.URL.Query().Get("plugin"),
+		Method:     r.URL.Query().Get("method"),
+		PathPrefix: r.URL.Query().Get("path"),
+		StatusCode: statusCode,
+	}
+}
+
+// logsCSVURL links to the CSV export of the logs matching the current filters
+func logsCSVURL(r *http.Request) string {
+	filters := url.Values{}
+	for _, name := range []string{"plugin", "method", "path", "status"} {
+		if v := r.URL.Query().Get(name); v != "" {
+			filters.Set(name, v)
+		}
+	}
+	if len(filters) == 0 {
+		return core.BasePath(r) + "/admin/logs.csv"
+	}
+	return core.BasePath(r) + "/admin/logs.csv?" + filters.Encode()
+}
+
+// positiveIntParam reads a positive integer query parameter, or returns def
+func positiveIntParam(r *http.Request, name string, def int) int {
+	if v, err := strconv.Atoi(r.URL.Query().Get(name)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
 // prettyJSON formats JSON with indentation, or returns original string if not valid JSON
 func prettyJSON(s string) string {
 	if s == "" {
@@ -274,6 +604,8 @@ type PluginDashboardData struct {
 	ErrorRate      float64
 	RecentRequests []*store.RequestLog
 	Resources      []PluginResourceLink
+	Truncatable    bool
+	ResourceTypes  []string // resource types that can be bulk deleted on their own
 }
 
 // PluginResourceLink represents a quick link to a plugin resource
@@ -319,6 +651,8 @@ func getPluginDashboardData(s *store.Store) []PluginDashboardData {
 			ErrorRate:      errorRate,
 			RecentRequests: recentRequests,
 			Resources:      resources,
+			Truncatable:    isTruncatable(plugin),
+			ResourceTypes:  truncateGroupNames(plugin),
 		})
 	}
 
@@ -328,26 +662,21 @@ func getPluginDashboardData(s *store.Store) []PluginDashboardData {
 // Redirect helper functions for old Google routes
 func (h *Handlers) redirectToPluginRoute(newPath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, newPath, http.StatusMovedPermanently)
+		http.Redirect(w, r, core.BasePath(r)+newPath, http.StatusMovedPermanently)
 	}
 }
 
-func (h *Handlers) redirectGmailView(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	http.Redirect(w, r, "/admin/plugins/google/messages/"+id, http.StatusMovedPermanently)
-}
-
 func (h *Handlers) redirectCalendarView(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	http.Redirect(w, r, "/admin/plugins/google/events/"+id, http.StatusMovedPermanently)
+	http.Redirect(w, r, core.BasePath(r)+"/admin/plugins/google/events/"+id, http.StatusMovedPermanently)
 }
 
 func (h *Handlers) redirectPeopleView(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	http.Redirect(w, r, "/admin/plugins/google/contacts/"+id, http.StatusMovedPermanently)
+	http.Redirect(w, r, core.BasePath(r)+"/admin/plugins/google/contacts/"+id, http.StatusMovedPermanently)
 }
 
 func (h *Handlers) redirectTasksView(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	http.Redirect(w, r, "/admin/plugins/google/tasks/"+id, http.StatusMovedPermanently)
+	http.Redirect(w, r, core.BasePath(r)+"/admin/plugins/google/tasks/"+id, http.StatusMovedPermanently)
 }
@@ -1,13 +1,18 @@
 // ABOUTME: Tests for admin HTTP handlers.
-// ABOUTME: Verifies dashboard and CRUD pages render correctly.
+// ABOUTME: Verifies dashboard and CRUD pages render correctly, and log paging stays stable under writes.
 
 package admin
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -462,3 +467,208 @@ func TestLogsListCombinedFilters(t *testing.T) {
 		t.Error("Expected limited 404 entries when filtering by status")
 	}
 }
+
+func TestLogsListPagination(t *testing.T) {
+	setupDashboardPlugins()
+
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		if err := s.LogRequest(&store.RequestLog{
+			PluginName: "google",
+			Method:     "GET",
+			Path:       fmt.Sprintf("/page-test/%02d", i),
+			StatusCode: 200,
+			Timestamp:  base.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatalf("Failed to insert test log: %v", err)
+		}
+	}
+
+	// Top Endpoints also lists paths, so match on the log table's path cell
+	row := func(body, path string) bool {
+		return strings.Contains(body, `text-gray-900">`+path+`</td>`)
+	}
+
+	h := NewHandlers(s)
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.logsList(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", url, w.Code)
+		}
+		return w
+	}
+
+	w := get("/admin/logs?page=1&per_page=5")
+	if got := w.Header().Get("X-Total-Count"); got != "12" {
+		t.Errorf("Expected X-Total-Count 12, got %q", got)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Page 1 of 3") {
+		t.Error("Expected 'Page 1 of 3' on first page")
+	}
+	if !row(body, "/page-test/11") || row(body, "/page-test/06") {
+		t.Error("Expected newest five logs on first page")
+	}
+
+	link := w.Header().Get("Link")
+	if strings.Contains(link, `rel="prev"`) || !strings.Contains(link, `rel="last"`) {
+		t.Errorf("Unexpected Link header on first page: %s", link)
+	}
+	next := regexp.MustCompile(`<([^>]+)>; rel="next"`).FindStringSubmatch(link)
+	if next == nil || !strings.Contains(next[1], "after=") {
+		t.Fatalf("Expected cursor-based next link, got %s", link)
+	}
+
+	// A request logged mid-pagination must not shift the next page
+	s.LogRequest(&store.RequestLog{PluginName: "google", Method: "GET", Path: "/page-test/new", StatusCode: 200, Timestamp: base.Add(time.Hour)})
+
+	body = get(next[1]).Body.String()
+	if !strings.Contains(body, "Page 2 of 3") {
+		t.Error("Expected 'Page 2 of 3' on second page")
+	}
+	if !row(body, "/page-test/06") || row(body, "/page-test/07") || row(body, "/page-test/new") {
+		t.Error("Expected logs 06 down to 02 on second page")
+	}
+
+	// Jumping straight to the last page uses page numbers
+	w = get("/admin/logs?page=3&per_page=5")
+	body = w.Body.String()
+	if !strings.Contains(body, "Page 3 of 3") || !row(body, "/page-test/00") {
+		t.Error("Expected oldest logs on last page")
+	}
+	if strings.Contains(w.Header().Get("Link"), `rel="next"`) {
+		t.Error("Expected no next link on last page")
+	}
+}
+
+func TestLogsListPaginationWithConcurrentWrites(t *testing.T) {
+	setupDashboardPlugins()
+
+	// File-backed so the writer goroutine and the handler share one database across pooled connections
+	s, err := store.New(filepath.Join(t.TempDir(), "logs.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 35; i++ {
+		if err := s.LogRequest(&store.RequestLog{
+			PluginName: "google", Method: "GET", Path: fmt.Sprintf("/existing/%02d", i), StatusCode: 200,
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatalf("Failed to insert test log: %v", err)
+		}
+	}
+
+	h := NewHandlers(s)
+	pathCell := regexp.MustCompile(`text-gray-900">(/[^<]+)</td>`)
+	nextLink := regexp.MustCompile(`<([^>]+)>; rel="next"`)
+	var seen []string
+	get := func(url string) string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.logsList(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", url, w.Code)
+		}
+		for _, m := range pathCell.FindAllStringSubmatch(w.Body.String(), -1) {
+			seen = append(seen, m[1])
+		}
+		if next := nextLink.FindStringSubmatch(w.Header().Get("Link")); next != nil {
+			return next[1]
+		}
+		return ""
+	}
+
+	next := get("/admin/logs?plugin=google&per_page=10")
+
+	// Keep logging newer requests to the same plugin while the remaining pages are read
+	var written atomic.Int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 1; ; n++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			s.LogRequest(&store.RequestLog{PluginName: "google", Method: "POST", Path: "/concurrent", StatusCode: 201,
+				Timestamp: base.Add(time.Hour + time.Duration(n)*time.Millisecond)})
+			written.Add(1)
+		}
+	}()
+
+	for next != "" {
+		// Make sure at least one write lands between every page
+		for before := written.Load(); written.Load() == before; {
+			time.Sleep(time.Millisecond)
+		}
+		next = get(next)
+	}
+	close(stop)
+	wg.Wait()
+
+	if len(seen) != 35 {
+		t.Fatalf("Expected 35 logs across pages, got %d: %v", len(seen), seen)
+	}
+	for i, path := range seen {
+		if want := fmt.Sprintf("/existing/%02d", 34-i); path != want {
+			t.Fatalf("Page order diverged at %d: expected %s, got %s", i, want, path)
+		}
+	}
+}
+
+func TestAdminUnderBasePath(t *testing.T) {
+	setupDashboardPlugins()
+
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	// Mounted under a base path, as the server does with ISH_BASE_PATH
+	routes := chi.NewRouter()
+	NewHandlers(s).RegisterRoutes(routes)
+	root := chi.NewRouter()
+	root.Use(core.WithBasePath("/ish"))
+	root.Mount("/ish", routes)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		root.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		return w
+	}
+
+	rootRelative := regexp.MustCompile(`(?:href|action|hx-[a-z]+)="(/[^"]*)"`)
+	for _, page := range []string{"/ish/admin/", "/ish/admin/logs?per_page=1", "/ish/admin/guide"} {
+		w := get(page)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", page, w.Code)
+		}
+		for _, m := range rootRelative.FindAllStringSubmatch(w.Body.String(), -1) {
+			if !strings.HasPrefix(m[1], "/ish/") {
+				t.Errorf("GET %s: expected %s under the base path", page, m[0])
+			}
+		}
+	}
+
+	w := get("/ish/admin/calendar/evt_1")
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("Expected 301 from old calendar route, got %d", w.Code)
+	}
+	if got, want := w.Header().Get("Location"), "/ish/admin/plugins/google/events/evt_1"; got != want {
+		t.Errorf("Expected redirect to %s, got %s", want, got)
+	}
+}
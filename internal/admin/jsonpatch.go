@@ -0,0 +1,152 @@
+// ABOUTME: Minimal RFC 6902 JSON Patch support for admin document edits.
+// ABOUTME: Applies add, replace, and remove operations addressed by RFC 6901 JSON Pointers.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// patchOperation is a single JSON Patch operation
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// applyJSONPatch applies operations in order to a copy of doc. Either every
+// operation applies or an error is returned and doc is left untouched.
+func applyJSONPatch(doc any, ops []patchOperation) (any, error) {
+	// Work on a deep copy so a failing operation can't leave a half-patched document
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var value any
+		switch op.Op {
+		case "add", "replace":
+			if len(op.Value) == 0 {
+				return nil, fmt.Errorf("operation %d: %s requires a value", i, op.Op)
+			}
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, fmt.Errorf("operation %d: invalid value: %w", i, err)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d: unsupported op %q", i, op.Op)
+		}
+
+		tokens, err := parseJSONPointer(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		if len(tokens) == 0 {
+			if op.Op == "remove" {
+				return nil, fmt.Errorf("operation %d: cannot remove the whole document", i)
+			}
+			result = value
+			continue
+		}
+		if result, err = patchAt(result, tokens, op.Op, value); err != nil {
+			return nil, fmt.Errorf("operation %d: %s %s: %w", i, op.Op, op.Path, err)
+		}
+	}
+	return result, nil
+}
+
+// patchAt applies one operation at the location named by tokens below node
+func patchAt(node any, tokens []string, op string, value any) (any, error) {
+	key, last := tokens[0], len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, exists := n[key]
+		if last {
+			if op != "add" && !exists {
+				return nil, fmt.Errorf("no member %q", key)
+			}
+			if op == "remove" {
+				delete(n, key)
+			} else {
+				n[key] = value
+			}
+			return n, nil
+		}
+		if !exists {
+			return nil, fmt.Errorf("no member %q", key)
+		}
+		updated, err := patchAt(child, tokens[1:], op, value)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = updated
+		return n, nil
+
+	case []any:
+		if last && op == "add" && key == "-" {
+			return append(n, value), nil
+		}
+		idx, err := strconv.Atoi(key)
+		limit := len(n)
+		if last && op == "add" {
+			limit++ // add may insert at the end
+		}
+		if err != nil || idx < 0 || idx >= limit {
+			return nil, fmt.Errorf("invalid array index %q", key)
+		}
+		if !last {
+			updated, err := patchAt(n[idx], tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[idx] = updated
+			return n, nil
+		}
+		switch op {
+		case "add":
+			n = append(n, nil)
+			copy(n[idx+1:], n[idx:])
+			n[idx] = value
+		case "replace":
+			n[idx] = value
+		case "remove":
+			n = append(n[:idx], n[idx+1:]...)
+		}
+		return n, nil
+
+	default:
+		return nil, fmt.Errorf("cannot address %q inside a scalar value", key)
+	}
+}
+
+// parseJSONPointer splits an RFC 6901 pointer into unescaped reference tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// touchesPath reports whether an operation on path would change the value at
+// protected, either directly, inside it, or by replacing one of its ancestors
+func touchesPath(path, protected string) bool {
+	return path == protected ||
+		strings.HasPrefix(path, protected+"/") ||
+		strings.HasPrefix(protected, path+"/")
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Tests for the admin JSON Patch implementation.
+// ABOUTME: Covers add, replace, and remove on objects and arrays plus pointer escaping.
+
+package admin
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add member", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{"replace nested", `{"a":{"b":[1,2]}}`, `[{"op":"replace","path":"/a/b/1","value":3}]`, `{"a":{"b":[1,3]}}`},
+		{"insert into array", `{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		{"append to array", `{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		{"remove from array", `{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/0"}]`, `{"a":[2,3]}`},
+		{"escaped pointer", `{"a/b":1,"c~d":2}`, `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/c~0d","value":3}]`, `{"c~d":3}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc, want any
+			var ops []patchOperation
+			json.Unmarshal([]byte(tt.doc), &doc)
+			json.Unmarshal([]byte(tt.want), &want)
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatalf("bad patch: %v", err)
+			}
+
+			got, err := applyJSONPatch(doc, ops)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatchErrorsLeaveDocumentUnchanged(t *testing.T) {
+	var doc any
+	json.Unmarshal([]byte(`{"a":[1,2]}`), &doc)
+
+	for _, patch := range []string{
+		`[{"op":"replace","path":"/a/0","value":9},{"op":"remove","path":"/missing"}]`,
+		`[{"op":"move","from":"/a","path":"/b"}]`,
+		`[{"op":"add","path":"/a/5","value":1}]`,
+		`[{"op":"replace","path":"/a/0/x","value":1}]`,
+		`[{"op":"remove","path":""}]`,
+	} {
+		var ops []patchOperation
+		json.Unmarshal([]byte(patch), &ops)
+		if _, err := applyJSONPatch(doc, ops); err == nil {
+			t.Errorf("expected error for %s", patch)
+		}
+	}
+
+	if a := doc.(map[string]any)["a"].([]any); a[0] != float64(1) {
+		t.Errorf("expected original document untouched, got %v", doc)
+	}
+}
+
+func TestTouchesPath(t *testing.T) {
+	for _, tt := range []struct {
+		path string
+		want bool
+	}{
+		{"/id", true},
+		{"/id/x", true},
+		{"", true},
+		{"/identity", false},
+		{"/names/0", false},
+	} {
+		if got := touchesPath(tt.path, "/id"); got != tt.want {
+			t.Errorf("touchesPath(%q, /id) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
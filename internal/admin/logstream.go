@@ -0,0 +1,105 @@
+// ABOUTME: Server-Sent Events stream of request logs for live tailing.
+// ABOUTME: Pushes each log the logging middleware stores, optionally filtered by plugin.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/2389/ish/internal/logging"
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+)
+
+// streamKeepalive is how often an idle log stream sends a comment, so
+// proxies and clients don't time the connection out
+const streamKeepalive = 15 * time.Second
+
+// WithLogStream lets GET /admin/logs/stream tail the request logs published
+// to stream, which should be the logging middleware's Options.Stream
+func (h *Handlers) WithLogStream(stream *logging.Stream) *Handlers {
+	h.logStream = stream
+	return h
+}
+
+// logEvent is the JSON data of a request event on the log stream. Bodies are
+// left out to keep events small; they're on the logs page.
+type logEvent struct {
+	ID         int64     `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Plugin     string    `json:"plugin"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"status_code"`
+	DurationMs int       `json:"duration_ms"`
+	UserID     string    `json:"user_id,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+}
+
+func newLogEvent(entry *store.RequestLog) logEvent {
+	return logEvent{
+		ID:         entry.ID,
+		Timestamp:  entry.Timestamp,
+		Plugin:     entry.PluginName,
+		Method:     entry.Method,
+		Path:       entry.Path,
+		StatusCode: entry.StatusCode,
+		DurationMs: entry.DurationMs,
+		UserID:     entry.UserID,
+		IPAddress:  entry.IPAddress,
+	}
+}
+
+// logsStream handles GET /admin/logs/stream, sending a "request" event for
+// each new request log until the client disconnects or the server shuts
+// down. ?plugin= limits events to one plugin. A client too slow to keep up
+// misses events rather than slowing down requests.
+func (h *Handlers) logsStream(w http.ResponseWriter, r *http.Request) {
+	if h.logStream == nil {
+		http.Error(w, "live log streaming is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub := h.logStream.Subscribe(r.URL.Query().Get("plugin"))
+	defer h.logStream.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	// Send something right away so clients see the stream open
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-core.ShuttingDown(r.Context()):
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case entry, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(newLogEvent(entry))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: request\nid: %d\ndata: %s\n\n", entry.ID, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// ABOUTME: Bulk import of Gmail messages from an uploaded mbox file.
+// ABOUTME: Splits on "From " delimiter lines and decodes base64 and quoted-printable bodies.
+
+package admin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// maxMboxBytes caps the size of an uploaded mbox file
+const maxMboxBytes = 32 << 20
+
+// mboxUser owns imported messages unless the form names another mailbox
+const mboxUser = "me"
+
+// gmailImportMbox stores every message in an uploaded mbox file in a user's
+// inbox and reports how many were imported
+func (h *Handlers) gmailImportMbox(w http.ResponseWriter, r *http.Request) {
+	mailbox, ok := mailAdmin(w)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxMboxBytes)
+	if err := r.ParseMultipartForm(maxMboxBytes); err != nil {
+		http.Error(w, "Invalid upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("mbox")
+	if err != nil {
+		http.Error(w, "An mbox file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	messages, err := parseMbox(file)
+	if err != nil {
+		http.Error(w, "Invalid mbox: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID := strings.TrimSpace(r.FormValue("user"))
+	if userID == "" {
+		userID = mboxUser
+	}
+	for i, msg := range messages {
+		msg.UserID = userID
+		msg.Labels = []string{"INBOX"}
+		if _, err := mailbox.CreateMailMessage(r.Context(), msg); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to import message %d: %v", i+1, err), http.StatusInternalServerError)
+			return
+		}
+	}
+	h.invalidateCounts()
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<p class="text-sm text-green-700">Imported %d messages into %s</p>`, len(messages), html.EscapeString(userID))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"imported": len(messages),
+		"user":     userID,
+	})
+}
+
+// parseMbox splits an mbox file into messages. Each message starts with a
+// "From " line; body lines escaped as ">From " (mboxrd) lose one ">".
+func parseMbox(r io.Reader) ([]core.MailMessageInput, error) {
+	var messages []core.MailMessageInput
+	var current *bytes.Buffer
+
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		msg, err := parseMboxMessage(current.Bytes())
+		if err != nil {
+			return fmt.Errorf("message %d: %w", len(messages)+1, err)
+		}
+		messages = append(messages, msg)
+		return nil
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "From "):
+				if err := flush(); err != nil {
+					return nil, err
+				}
+				current = &bytes.Buffer{}
+			case current == nil:
+				if strings.TrimSpace(line) != "" {
+					return nil, errors.New(`expected the file to start with a "From " line`)
+				}
+			default:
+				if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+					line = line[1:]
+				}
+				current.WriteString(line)
+				current.WriteByte('\n')
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("no messages found")
+	}
+	return messages, nil
+}
+
+// parseMboxMessage reads one RFC 5322 message into the fields the mailbox stores
+func parseMboxMessage(raw []byte) (core.MailMessageInput, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return core.MailMessageInput{}, err
+	}
+
+	body, err := decodeMailBody(textproto.MIMEHeader(msg.Header), msg.Body)
+	if err != nil {
+		return core.MailMessageInput{}, err
+	}
+
+	input := core.MailMessageInput{
+		From:    decodeMailHeader(msg.Header.Get("From")),
+		To:      decodeMailHeader(msg.Header.Get("To")),
+		Subject: decodeMailHeader(msg.Header.Get("Subject")),
+		Body:    strings.TrimRight(body, "\n"),
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		input.Date = date
+	}
+	return input, nil
+}
+
+// decodeMailBody returns a message's text, undoing its transfer encoding.
+// Multipart messages yield their first text/plain part, or else the first text part.
+func decodeMailBody(header textproto.MIMEHeader, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		var fallback string
+		for {
+			part, err := parts.NextRawPart()
+			if err == io.EOF {
+				return fallback, nil
+			}
+			if err != nil {
+				return "", err
+			}
+			text, err := decodeMailBody(part.Header, part)
+			if err != nil {
+				return "", err
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			if partType == "" || partType == "text/plain" {
+				return text, nil
+			}
+			if fallback == "" && strings.HasPrefix(partType, "text/") {
+				fallback = text
+			}
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	decoded, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("decoding body: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// decodeMailHeader decodes RFC 2047 encoded words such as =?UTF-8?B?...?=
+func decodeMailHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
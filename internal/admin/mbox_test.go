@@ -0,0 +1,72 @@
+// ABOUTME: Tests for parsing mbox files into Gmail messages.
+// ABOUTME: Covers delimiter lines, >From unescaping, transfer encodings, and multipart bodies.
+
+package admin
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMbox = `From alice@example.com Sat Mar  1 09:00:00 2025
+From: Alice <alice@example.com>
+To: me@example.com
+Subject: =?UTF-8?B?Q2Fmw6k=?= plans
+Date: Sat, 01 Mar 2025 09:00:00 +0000
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: base64
+
+TWVldCBhdCB0aGUgY2Fmw6kgYXQgbm9vbi4K
+
+From bob@example.com Sun Mar  2 10:30:00 2025
+From: bob@example.com
+Subject: Notes
+Content-Type: multipart/alternative; boundary="b1"
+
+--b1
+Content-Type: text/html
+
+<p>ignored</p>
+--b1
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: quoted-printable
+
+Line one is long enough to be soft=
+ wrapped.
+>From the archive.
+--b1--
+`
+
+func TestParseMbox(t *testing.T) {
+	messages, err := parseMbox(strings.NewReader(testMbox))
+	if err != nil {
+		t.Fatalf("parseMbox failed: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(messages))
+	}
+
+	first := messages[0]
+	if first.From != "Alice <alice@example.com>" || first.Subject != "Café plans" || first.Body != "Meet at the café at noon." {
+		t.Errorf("Unexpected first message: %+v", first)
+	}
+	if first.Date.IsZero() || first.Date.Day() != 1 {
+		t.Errorf("Expected the Date header to be kept, got %v", first.Date)
+	}
+
+	second := messages[1]
+	if second.Body != "Line one is long enough to be soft wrapped.\nFrom the archive." {
+		t.Errorf("Expected the decoded text/plain part, got %q", second.Body)
+	}
+	if !second.Date.IsZero() {
+		t.Errorf("Expected no date without a Date header, got %v", second.Date)
+	}
+}
+
+func TestParseMboxRejectsOtherFiles(t *testing.T) {
+	for _, input := range []string{"", "Subject: not an mbox\n\nhello\n"} {
+		if _, err := parseMbox(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
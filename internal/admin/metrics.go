@@ -0,0 +1,83 @@
+// ABOUTME: Plugin metrics API for dashboards and the admin UI.
+// ABOUTME: Serves last-hour traffic, errors, latency and record counts per plugin, cached briefly.
+
+package admin
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// metricsCacheTTL bounds how often the metrics API aggregates request logs
+const metricsCacheTTL = 30 * time.Second
+
+// metricsWindow is how far back request figures reach
+const metricsWindow = time.Hour
+
+// PluginMetric is one plugin's entry in the /api/metrics response
+type PluginMetric struct {
+	Name             string         `json:"name"`
+	RequestsLastHour int            `json:"requests_last_hour"`
+	ErrorsLastHour   int            `json:"errors_last_hour"`
+	AvgLatencyMs     float64        `json:"avg_latency_ms"`
+	Resources        map[string]int `json:"resources"`
+}
+
+// metricsCache holds the most recent plugin metrics
+type metricsCache struct {
+	mu      sync.Mutex
+	fetched time.Time
+	metrics []PluginMetric
+}
+
+// apiMetrics handles GET /api/metrics, returning {"plugins": [...]} as JSON,
+// or a metrics table for htmx polling from the dashboard
+func (h *Handlers) apiMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := h.pluginMetrics()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html")
+		renderPartial(w, r, "plugin-metrics", metrics)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"plugins": metrics})
+}
+
+// pluginMetrics returns cached metrics, refreshing them once they are older than metricsCacheTTL
+func (h *Handlers) pluginMetrics() ([]PluginMetric, error) {
+	h.metrics.mu.Lock()
+	defer h.metrics.mu.Unlock()
+
+	now := h.clock.Now()
+	if h.metrics.metrics != nil && now.Sub(h.metrics.fetched) < metricsCacheTTL {
+		return h.metrics.metrics, nil
+	}
+
+	aggregated, err := h.store.GetPluginMetrics(now.Add(-metricsWindow))
+	if err != nil {
+		return nil, err
+	}
+	metrics := make([]PluginMetric, len(aggregated))
+	for i, m := range aggregated {
+		metrics[i] = PluginMetric{
+			Name:             m.Name,
+			RequestsLastHour: m.Requests,
+			ErrorsLastHour:   m.Errors,
+			AvgLatencyMs:     math.Round(m.AvgLatencyMs*10) / 10,
+			Resources:        m.Resources,
+		}
+	}
+
+	h.metrics.metrics = metrics
+	h.metrics.fetched = now
+	return metrics, nil
+}
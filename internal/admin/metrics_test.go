@@ -0,0 +1,139 @@
+// ABOUTME: Tests for the plugin metrics API.
+// ABOUTME: Verifies the JSON shape, latency rounding, the htmx partial, the 30 second cache, and a frozen store clock.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+)
+
+// getMetric fetches /api/metrics and returns the named plugin's entry
+func getMetric(t *testing.T, h *Handlers, name string) PluginMetric {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.apiMetrics(w, httptest.NewRequest("GET", "/api/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Plugins []PluginMetric `json:"plugins"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode metrics: %v", err)
+	}
+	for _, m := range resp.Plugins {
+		if m.Name == name {
+			return m
+		}
+	}
+	t.Fatalf("no metrics for %s in %+v", name, resp.Plugins)
+	return PluginMetric{}
+}
+
+func TestAPIMetrics(t *testing.T) {
+	setupCountedPlugin()
+
+	clock := core.NewFrozenClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	db := s.GetDB()
+	if _, err := db.Exec(`CREATE TABLE counted_widgets (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO counted_widgets DEFAULT VALUES`); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	logRequest := func(status, durationMs int) {
+		t.Helper()
+		err := s.LogRequest(&store.RequestLog{
+			Timestamp: clock.Now().Add(-time.Minute), PluginName: "counted",
+			Method: "GET", Path: "/widgets", StatusCode: status, DurationMs: durationMs,
+		})
+		if err != nil {
+			t.Fatalf("LogRequest: %v", err)
+		}
+	}
+	logRequest(200, 3)
+	logRequest(200, 5)
+	logRequest(502, 8)
+
+	h := NewHandlers(s)
+	h.clock = clock
+	m := getMetric(t, h, "counted")
+	if m.RequestsLastHour != 3 || m.ErrorsLastHour != 1 || m.AvgLatencyMs != 5.3 || m.Resources["widgets"] != 1 {
+		t.Fatalf("unexpected metrics: %+v", m)
+	}
+
+	// Cached until the TTL passes
+	logRequest(200, 4)
+	if got := getMetric(t, h, "counted").RequestsLastHour; got != 3 {
+		t.Errorf("expected cached request count 3, got %d", got)
+	}
+	clock.Advance(metricsCacheTTL)
+	if got := getMetric(t, h, "counted").RequestsLastHour; got != 4 {
+		t.Errorf("expected refreshed request count 4, got %d", got)
+	}
+
+	req := httptest.NewRequest("GET", "/api/metrics", nil)
+	req.Header.Set("HX-Request", "true")
+	w := httptest.NewRecorder()
+	h.apiMetrics(w, req)
+	if ct := w.Header().Get("Content-Type"); ct != "text/html" || !strings.Contains(w.Body.String(), `data-plugin="counted"`) {
+		t.Errorf("expected the metrics table partial, got %s: %s", ct, w.Body.String())
+	}
+}
+
+func TestAPIMetricsIgnoreFrozenStoreClock(t *testing.T) {
+	setupCountedPlugin()
+
+	// ISH_FROZEN_TIME freezes stored timestamps in the past, while request
+	// logs are stamped with wall time
+	core.SetClock(core.NewFrozenClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	defer core.SetClock(nil)
+
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	logRequest := func(at time.Time) {
+		t.Helper()
+		err := s.LogRequest(&store.RequestLog{
+			Timestamp: at, PluginName: "counted", Method: "GET", Path: "/widgets", StatusCode: 200, DurationMs: 1,
+		})
+		if err != nil {
+			t.Fatalf("LogRequest: %v", err)
+		}
+	}
+	now := time.Now()
+	logRequest(now.Add(-2 * time.Hour))
+	logRequest(now.Add(-time.Minute))
+
+	h := NewHandlers(s)
+	if got := getMetric(t, h, "counted").RequestsLastHour; got != 1 {
+		t.Errorf("expected only the request from the last wall-clock hour, got %d", got)
+	}
+
+	// The cache still expires while the store clock stands still
+	clock := core.NewFrozenClock(now)
+	h.clock = clock
+	logRequest(now)
+	clock.Advance(metricsCacheTTL + time.Second)
+	if got := getMetric(t, h, "counted").RequestsLastHour; got != 2 {
+		t.Errorf("expected the refreshed count of 2, got %d", got)
+	}
+}
@@ -71,7 +71,7 @@ func (h *PluginHandlers) PluginListView(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Render list view using schema renderer
-	listHTML := RenderResourceList(*resourceSchema, resources)
+	listHTML := RenderResourceList(actionsUnderBasePath(*resourceSchema, core.BasePath(r)), resources)
 
 	// Wrap in admin layout
 	pageData := pluginListData{
@@ -82,7 +82,7 @@ func (h *PluginHandlers) PluginListView(w http.ResponseWriter, r *http.Request)
 	}
 
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "plugin-list", pageData)
+	renderPage(w, r, "plugin-list", pageData)
 }
 
 // PluginCreateForm renders a create form using the schema renderer
@@ -119,7 +119,7 @@ func (h *PluginHandlers) PluginCreateForm(w http.ResponseWriter, r *http.Request
 	}
 
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "plugin-form", pageData)
+	renderPage(w, r, "plugin-form", pageData)
 }
 
 // PluginDetailView renders a detail view using the schema renderer
@@ -184,7 +184,7 @@ func (h *PluginHandlers) PluginDetailView(w http.ResponseWriter, r *http.Request
 	}
 
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "plugin-detail", pageData)
+	renderPage(w, r, "plugin-detail", pageData)
 }
 
 // PluginEditForm renders an edit form using the schema renderer
@@ -248,7 +248,7 @@ func (h *PluginHandlers) PluginEditForm(w http.ResponseWriter, r *http.Request)
 	}
 
 	w.Header().Set("Content-Type", "text/html")
-	renderPage(w, "plugin-form", pageData)
+	renderPage(w, r, "plugin-form", pageData)
 }
 
 // JSON API handlers for agents to verify integrations
@@ -389,6 +389,21 @@ func findResourceSchema(schema core.PluginSchema, slug string) *core.ResourceSch
 	return nil
 }
 
+// actionsUnderBasePath returns schema with its action endpoints, which are
+// plugin API routes, moved under the base path
+func actionsUnderBasePath(schema core.ResourceSchema, prefix string) core.ResourceSchema {
+	if prefix == "" {
+		return schema
+	}
+	actions := make([]core.ActionSchema, len(schema.Actions))
+	for i, action := range schema.Actions {
+		action.Endpoint = prefix + action.Endpoint
+		actions[i] = action
+	}
+	schema.Actions = actions
+	return schema
+}
+
 type pluginListData struct {
 	PluginName   string
 	ResourceName string
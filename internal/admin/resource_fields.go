@@ -0,0 +1,86 @@
+// ABOUTME: Field-level PATCH endpoint for plugin resources shown in the admin UI.
+// ABOUTME: Saves JSON-valued fields like attendees or payload through core.ResourceEditor.
+
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// patchResourceFields replaces fields of a plugin resource with the values in
+// a JSON object body and returns the stored resource. Values are kept as
+// json.RawMessage so the plugin parses each one only once.
+func (h *Handlers) patchResourceFields(w http.ResponseWriter, r *http.Request) {
+	pluginName := chi.URLParam(r, "plugin")
+	resourceSlug := chi.URLParam(r, "resource")
+	id := chi.URLParam(r, "id")
+
+	plugin, ok := core.Get(pluginName)
+	if !ok {
+		documentError(w, http.StatusNotFound, fmt.Sprintf("Plugin %q not found", pluginName))
+		return
+	}
+	editor, ok := plugin.(core.ResourceEditor)
+	if !ok {
+		documentError(w, http.StatusMethodNotAllowed, fmt.Sprintf("Plugin %q does not support editing resources", pluginName))
+		return
+	}
+	resourceSchema := findResourceSchema(plugin.Schema(), resourceSlug)
+	if resourceSchema == nil {
+		documentError(w, http.StatusNotFound, fmt.Sprintf("Resource %q not found", resourceSlug))
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		documentError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+	if len(fields) == 0 {
+		documentError(w, http.StatusBadRequest, "Body must be a JSON object with at least one field")
+		return
+	}
+	for name := range fields {
+		if field := findField(resourceSchema.Fields, name); field == nil || !field.Editable {
+			documentError(w, http.StatusUnprocessableEntity, fmt.Sprintf("%s is not an editable field", name))
+			return
+		}
+	}
+
+	err := editor.UpdateResourceFields(r.Context(), resourceSlug, id, fields)
+	if errors.Is(err, core.ErrResourceNotFound) {
+		documentError(w, http.StatusNotFound, fmt.Sprintf("%s %q not found", resourceSlug, id))
+		return
+	}
+	if errors.Is(err, core.ErrInvalidField) {
+		documentError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		documentError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var data map[string]interface{}
+	if dataProvider, ok := plugin.(core.DataProvider); ok {
+		data, err = dataProvider.GetResource(r.Context(), resourceSlug, id)
+		if err != nil {
+			documentError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"plugin":   pluginName,
+		"resource": resourceSlug,
+		"id":       id,
+		"data":     data,
+	})
+}
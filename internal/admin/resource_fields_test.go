@@ -0,0 +1,133 @@
+// ABOUTME: Tests for the field-level PATCH endpoint on plugin resources.
+// ABOUTME: Covers JSON validation, editable-field checks, and plugin error mapping.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// editableMockPlugin keeps one event in memory and implements core.ResourceEditor
+type editableMockPlugin struct {
+	mockPlugin
+	attendees json.RawMessage
+}
+
+func (m *editableMockPlugin) GetResource(ctx context.Context, slug, id string) (map[string]interface{}, error) {
+	return map[string]interface{}{"id": id, "attendees": m.attendees}, nil
+}
+
+func (m *editableMockPlugin) UpdateResourceFields(ctx context.Context, slug, id string, fields map[string]json.RawMessage) error {
+	if id != "evt1" {
+		return core.ErrResourceNotFound
+	}
+	if !strings.HasPrefix(string(fields["attendees"]), "[") {
+		return fmt.Errorf("%w: attendees must be an array", core.ErrInvalidField)
+	}
+	m.attendees = fields["attendees"]
+	return nil
+}
+
+var editablePlugin = &editableMockPlugin{mockPlugin: mockPlugin{
+	name: "editable",
+	schema: core.PluginSchema{Resources: []core.ResourceSchema{{
+		Name: "Events",
+		Slug: "events",
+		Fields: []core.FieldSchema{
+			{Name: "id", Type: "string", Display: "ID"},
+			{Name: "attendees", Type: "json", Display: "Attendees", Editable: true},
+		},
+	}}},
+}}
+
+var editablePluginRegistered = false
+
+func setupEditablePlugin() {
+	if editablePluginRegistered {
+		return
+	}
+	core.Register(editablePlugin)
+	editablePluginRegistered = true
+}
+
+func TestPatchResourceFields(t *testing.T) {
+	setupTestPlugin()
+	setupEditablePlugin()
+	r := chi.NewRouter()
+	NewHandlers(nil).RegisterRoutes(r)
+
+	patch := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch("/admin/editable/events/evt1", `{"attendees": [{"email": "bob@example.com"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			Attendees []map[string]string `json:"attendees"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Data.Attendees) != 1 || resp.Data.Attendees[0]["email"] != "bob@example.com" {
+		t.Errorf("Expected the saved attendees back, got %s", w.Body.String())
+	}
+
+	tests := []struct {
+		name, path, body string
+		want             int
+	}{
+		{"unparseable JSON", "/admin/editable/events/evt1", `{"attendees": [`, http.StatusBadRequest},
+		{"not an object", "/admin/editable/events/evt1", `[1, 2]`, http.StatusBadRequest},
+		{"no fields", "/admin/editable/events/evt1", `{}`, http.StatusBadRequest},
+		{"read-only field", "/admin/editable/events/evt1", `{"id": "evt2"}`, http.StatusUnprocessableEntity},
+		{"unknown field", "/admin/editable/events/evt1", `{"color": "red"}`, http.StatusUnprocessableEntity},
+		{"rejected by plugin", "/admin/editable/events/evt1", `{"attendees": {}}`, http.StatusUnprocessableEntity},
+		{"missing resource", "/admin/editable/events/evt2", `{"attendees": []}`, http.StatusNotFound},
+		{"unknown resource type", "/admin/editable/tasks/evt1", `{"attendees": []}`, http.StatusNotFound},
+		{"unknown plugin", "/admin/nope/events/evt1", `{"attendees": []}`, http.StatusNotFound},
+		{"plugin without editor", "/admin/testplugin/messages/1", `{"body": "\"hi\""}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if w := patch(tt.path, tt.body); w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, w.Code, w.Body.String())
+		}
+	}
+
+	if string(editablePlugin.attendees) != `[{"email": "bob@example.com"}]` {
+		t.Errorf("Rejected requests changed the stored attendees: %s", editablePlugin.attendees)
+	}
+}
+
+func TestRenderJSONEditor(t *testing.T) {
+	field := core.FieldSchema{Name: "attendees", Type: "json", Display: "Attendees", Editable: true}
+	got := RenderResourceDetail(core.ResourceSchema{Fields: []core.FieldSchema{field}},
+		map[string]interface{}{"attendees": json.RawMessage(`[{"email":"a@example.com"}]`)})
+
+	if !strings.Contains(got, `data-json-field="attendees"`) || !strings.Contains(got, "data-json-save") {
+		t.Errorf("Expected an editor with a Save button, got %s", got)
+	}
+	if !strings.Contains(got, "[\n  {\n    &#34;email&#34;: &#34;a@example.com&#34;\n  }\n]") {
+		t.Errorf("Expected pretty-printed JSON, got %s", got)
+	}
+
+	field.Editable = false
+	got = RenderResourceDetail(core.ResourceSchema{Fields: []core.FieldSchema{field}}, map[string]interface{}{"attendees": "[]"})
+	if strings.Contains(got, "data-json-save") || !strings.Contains(got, "readonly") {
+		t.Errorf("Expected a read-only editor without Save, got %s", got)
+	}
+}
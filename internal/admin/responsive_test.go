@@ -0,0 +1,173 @@
+// ABOUTME: Tests for the admin UI's small-screen layout.
+// ABOUTME: Checks the rendered pages for the viewport tag, hamburger menu, small-screen media query, and stacked log tables.
+
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// smallScreenQuery opens the responsive stylesheet's small-screen rules;
+// 767px is just under Tailwind's md breakpoint
+const smallScreenQuery = "@media (max-width: 767px) {"
+
+// getAdminPage serves url through the admin routes and returns the body
+func getAdminPage(t *testing.T, s *store.Store, url string) string {
+	t.Helper()
+	r := chi.NewRouter()
+	NewHandlers(s).RegisterRoutes(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: expected 200, got %d", url, w.Code)
+	}
+	return w.Body.String()
+}
+
+// compactCSS collapses whitespace so rules can be matched regardless of layout
+func compactCSS(css string) string {
+	return strings.Join(strings.Fields(css), " ")
+}
+
+// smallScreenRules returns the body of the small-screen media query, compacted
+func smallScreenRules(t *testing.T, page string) string {
+	t.Helper()
+	start := strings.Index(page, smallScreenQuery)
+	if start < 0 {
+		t.Fatalf("rendered page missing %q", smallScreenQuery)
+	}
+	depth := 0
+	for i := start + len(smallScreenQuery) - 1; i < len(page); i++ {
+		switch page[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return compactCSS(page[start+len(smallScreenQuery) : i])
+			}
+		}
+	}
+	t.Fatal("small-screen media query is not closed")
+	return ""
+}
+
+func TestLayoutCollapsesNavIntoHamburger(t *testing.T) {
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+	page := getAdminPage(t, s, "/admin/guide")
+
+	if !strings.Contains(page, `<meta name="viewport" content="width=device-width, initial-scale=1">`) {
+		t.Error("layout missing the device-width viewport meta tag")
+	}
+
+	// The menu is a checkbox toggle, so it works without JavaScript
+	toggle := strings.Index(page, `<input type="checkbox" id="ish-nav-toggle" class="ish-nav-toggle">`)
+	links := strings.Index(page, `<div class="ish-nav-links">`)
+	if toggle < 0 || links < 0 || toggle > links {
+		t.Fatal("expected the hamburger toggle to precede the nav links it reveals")
+	}
+	if !strings.Contains(page, `<label for="ish-nav-toggle" class="ish-nav-burger`) {
+		t.Error("expected a hamburger label for the toggle")
+	}
+
+	// Outside the media query the hamburger is hidden and the links show
+	desktop := compactCSS(page[strings.Index(page, `<style id="ish-responsive">`):strings.Index(page, smallScreenQuery)])
+	for _, rule := range []string{
+		".ish-nav-toggle, .ish-nav-burger { display: none; }",
+		".ish-nav-links { display: flex;",
+	} {
+		if !strings.Contains(desktop, rule) {
+			t.Errorf("desktop styles missing %q", rule)
+		}
+	}
+
+	mobile := smallScreenRules(t, page)
+	for _, rule := range []string{
+		".ish-nav-burger { display: inline-flex;",
+		".ish-nav-links { display: none; grid-column: 1 / -1; flex-direction: column;",
+		".ish-nav-toggle:checked ~ .ish-nav-links { display: flex; }",
+		`main button[type="submit"] { width: 100% !important;`,
+	} {
+		if !strings.Contains(mobile, rule) {
+			t.Errorf("small-screen styles missing %q", rule)
+		}
+	}
+}
+
+var (
+	headerCellPattern = regexp.MustCompile(`<th[^>]*>([^<]+)</th>`)
+	dataLabelPattern  = regexp.MustCompile(`<td data-label="([^"]+)"`)
+)
+
+func TestLogsTableStacksOnMobile(t *testing.T) {
+	setupDashboardPlugins()
+
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+	const logs = 2
+	for i := 0; i < logs; i++ {
+		if err := s.LogRequest(&store.RequestLog{
+			PluginName: "google",
+			Method:     "GET",
+			Path:       "/gmail/v1/users/me/messages",
+			StatusCode: 200,
+			DurationMs: 12,
+			Timestamp:  time.Now(),
+		}); err != nil {
+			t.Fatalf("Failed to insert test log: %v", err)
+		}
+	}
+	page := getAdminPage(t, s, "/admin/logs")
+
+	if !strings.Contains(page, `<table class="ish-stack-table`) {
+		t.Fatal("expected the logs table to be marked for stacking")
+	}
+
+	// Each stacked cell is labelled with its column header
+	var headers, labels []string
+	for _, m := range headerCellPattern.FindAllStringSubmatch(page, -1) {
+		headers = append(headers, strings.TrimSpace(m[1]))
+	}
+	for _, m := range dataLabelPattern.FindAllStringSubmatch(page, -1) {
+		labels = append(labels, m[1])
+	}
+	var want []string
+	for i := 0; i < logs; i++ {
+		want = append(want, headers...)
+	}
+	if len(headers) == 0 || !slices.Equal(labels, want) {
+		t.Errorf("cell labels %v don't match column headers %v", labels, headers)
+	}
+
+	// On small screens the header row hides and each cell shows its label
+	mobile := smallScreenRules(t, page)
+	for _, rule := range []string{
+		"table.ish-stack-table, table.ish-stack-table tbody, table.ish-stack-table tr, table.ish-stack-table td { display: block; width: 100%; }",
+		"table.ish-stack-table thead { display: none; }",
+		"table.ish-stack-table td[data-label]::before { content: attr(data-label);",
+	} {
+		if !strings.Contains(mobile, rule) {
+			t.Errorf("small-screen styles missing %q", rule)
+		}
+	}
+	desktop := page[strings.Index(page, `<style id="ish-responsive">`):strings.Index(page, smallScreenQuery)]
+	if strings.Contains(desktop, "ish-stack-table") {
+		t.Error("expected tables to stack only on small screens")
+	}
+}
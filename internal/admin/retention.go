@@ -0,0 +1,71 @@
+// ABOUTME: Admin endpoints for request log retention.
+// ABOUTME: Reports log table statistics and prunes logs older than a given age on demand.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logsStats returns the request log count, time span, and approximate size, e.g. GET /admin/logs/stats
+func (h *Handlers) logsStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.store.GetLogStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// logsPrune deletes request logs older than an age, e.g. POST /admin/logs/prune?older_than=7d
+func (h *Handlers) logsPrune(w http.ResponseWriter, r *http.Request) {
+	age, err := parseAge(r.URL.Query().Get("older_than"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cutoff := time.Now().Add(-age)
+	deleted, err := h.store.PruneOldLogs(cutoff)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"deleted":    deleted,
+		"older_than": cutoff.UTC().Format(time.RFC3339),
+	})
+}
+
+// parseAge parses a non-negative age such as "7d", "12h", or "90m"
+func parseAge(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, fmt.Errorf("older_than is required, e.g. older_than=7d")
+	}
+	var age time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid older_than %q", raw)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf("invalid older_than %q", raw)
+		}
+		age = d
+	}
+	if age < 0 {
+		return 0, fmt.Errorf("older_than must not be negative")
+	}
+	return age, nil
+}
@@ -0,0 +1,79 @@
+// ABOUTME: Tests for the request log retention admin endpoints.
+// ABOUTME: Checks manual pruning by age and the log stats report.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+)
+
+func TestLogsPrune(t *testing.T) {
+	s, r := newScenarioRouter(t)
+
+	now := time.Now()
+	for _, age := range []time.Duration{30 * 24 * time.Hour, 8 * 24 * time.Hour, time.Hour} {
+		if err := s.LogRequest(&store.RequestLog{Timestamp: now.Add(-age), Method: "GET", Path: "/x", StatusCode: 200}); err != nil {
+			t.Fatalf("Failed to insert test log: %v", err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/admin/logs/prune?older_than=7d", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Deleted int64 `json:"deleted"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Deleted != 2 {
+		t.Errorf("Expected 2 deleted logs, got %d", resp.Deleted)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/logs/stats", nil))
+	var stats struct {
+		TotalCount int        `json:"total_count"`
+		OldestLog  *time.Time `json:"oldest_log"`
+		NewestLog  *time.Time `json:"newest_log"`
+		SizeBytes  int64      `json:"size_bytes"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("Invalid stats response: %v", err)
+	}
+	if stats.TotalCount != 1 || stats.OldestLog == nil || stats.NewestLog == nil || stats.SizeBytes == 0 {
+		t.Errorf("Expected stats for the one remaining log, got %+v", stats)
+	}
+
+	for _, bad := range []string{"", "?older_than=soon", "?older_than=-1d"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/admin/logs/prune"+bad, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", bad, w.Code)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Duration
+	}{
+		{"7d", 7 * 24 * time.Hour},
+		{"0d", 0},
+		{"12h", 12 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseAge(tt.raw)
+		if err != nil || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", tt.raw, got, err, tt.want)
+		}
+	}
+}
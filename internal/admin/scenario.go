@@ -0,0 +1,403 @@
+// ABOUTME: Scenario export and import for replaying captured sessions on another instance.
+// ABOUTME: Bundles request logs with a row-level snapshot of every truncatable plugin's tables.
+
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+)
+
+// scenarioVersion is bumped when the bundle format changes incompatibly
+const scenarioVersion = 1
+
+// maxScenarioBytes caps the size of an imported bundle
+const maxScenarioBytes = 64 << 20
+
+// Scenario is a portable capture of request logs and plugin data
+type Scenario struct {
+	Version    int                       `json:"version"`
+	ExportedAt time.Time                 `json:"exportedAt"`
+	Since      *time.Time                `json:"since,omitempty"`
+	Truncated  bool                      `json:"truncated,omitempty"` // older requests past maxCSVRows were left out
+	Requests   []ScenarioRequest         `json:"requests"`
+	State      map[string]ScenarioTables `json:"state"` // plugin name -> tables
+}
+
+// ScenarioTables holds the rows of a plugin's tables, keyed by table name
+type ScenarioTables map[string][]map[string]any
+
+// ScenarioRequest is a captured request log entry
+type ScenarioRequest struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Plugin       string    `json:"plugin,omitempty"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	StatusCode   int       `json:"statusCode"`
+	DurationMs   int       `json:"durationMs"`
+	UserID       string    `json:"userId,omitempty"`
+	IPAddress    string    `json:"ipAddress,omitempty"`
+	UserAgent    string    `json:"userAgent,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	RequestBody  string    `json:"requestBody,omitempty"`
+	ResponseBody string    `json:"responseBody,omitempty"`
+}
+
+// exportScenario returns a scenario bundle, e.g. GET /admin/export/scenario?since=2025-01-01T00:00:00Z.
+// since limits the request logs; plugin (comma separated) limits the plugins whose data is included.
+// Only the newest maxCSVRows requests are bundled, and the bundle is marked truncated when more matched.
+func (h *Handlers) exportScenario(w http.ResponseWriter, r *http.Request) {
+	query := &store.RequestLogQuery{Limit: maxCSVRows}
+	scenario := Scenario{Version: scenarioVersion, ExportedAt: core.Now().UTC(), Requests: []ScenarioRequest{}}
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, "since must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		query.Since = since
+		scenario.Since = &since
+	}
+
+	logs, err := h.store.GetRequestLogs(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(logs) == maxCSVRows {
+		total, err := h.store.CountRequestLogs(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		scenario.Truncated = total > len(logs)
+	}
+	// Logs come newest first; bundles replay in the order requests happened
+	for i := len(logs) - 1; i >= 0; i-- {
+		entry := logs[i]
+		scenario.Requests = append(scenario.Requests, ScenarioRequest{
+			Timestamp:    entry.Timestamp,
+			Plugin:       entry.PluginName,
+			Method:       entry.Method,
+			Path:         entry.Path,
+			StatusCode:   entry.StatusCode,
+			DurationMs:   entry.DurationMs,
+			UserID:       entry.UserID,
+			IPAddress:    entry.IPAddress,
+			UserAgent:    entry.UserAgent,
+			Error:        entry.Error,
+			RequestBody:  entry.RequestBody,
+			ResponseBody: entry.ResponseBody,
+		})
+	}
+
+	var only map[string]bool
+	if raw := r.URL.Query().Get("plugin"); raw != "" {
+		only = make(map[string]bool)
+		for _, name := range strings.Split(raw, ",") {
+			only[strings.TrimSpace(name)] = true
+		}
+	}
+
+	scenario.State = make(map[string]ScenarioTables)
+	db := h.store.GetDB()
+	for _, plugin := range core.All() {
+		truncatable, ok := plugin.(core.Truncatable)
+		if !ok || (only != nil && !only[plugin.Name()]) {
+			continue
+		}
+		tables, err := dumpTables(db, core.GroupTables(truncatable.TruncateGroups()))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to export %s data: %v", plugin.Name(), err), http.StatusInternalServerError)
+			return
+		}
+		scenario.State[plugin.Name()] = tables
+	}
+
+	filename := "scenario-" + scenario.ExportedAt.Format("20060102-150405") + ".json"
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	json.NewEncoder(w).Encode(scenario)
+}
+
+// importScenario replaces the data of each plugin in a bundle with the bundle's
+// rows and appends its request logs in one transaction, so a failed import
+// leaves everything as it was, e.g. POST /admin/import/scenario
+func (h *Handlers) importScenario(w http.ResponseWriter, r *http.Request) {
+	var scenario Scenario
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxScenarioBytes))
+	// Numbers stay exact so large integer IDs survive the round trip
+	decoder.UseNumber()
+	if err := decoder.Decode(&scenario); err != nil {
+		http.Error(w, "Invalid scenario: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if scenario.Version != scenarioVersion {
+		http.Error(w, fmt.Sprintf("Unsupported scenario version %d", scenario.Version), http.StatusBadRequest)
+		return
+	}
+
+	// Only tables owned by a registered plugin can be written
+	allowed := make(map[string][]string)
+	for name, tables := range scenario.State {
+		plugin, ok := core.Get(name)
+		if !ok {
+			http.Error(w, fmt.Sprintf("Unknown plugin %q", name), http.StatusBadRequest)
+			return
+		}
+		truncatable, ok := plugin.(core.Truncatable)
+		if !ok {
+			http.Error(w, fmt.Sprintf("Plugin %q does not support import", name), http.StatusBadRequest)
+			return
+		}
+		owned := core.GroupTables(truncatable.TruncateGroups())
+		for table := range tables {
+			if !slices.Contains(owned, table) {
+				http.Error(w, fmt.Sprintf("Table %q does not belong to plugin %q", table, name), http.StatusBadRequest)
+				return
+			}
+		}
+		allowed[name] = owned
+	}
+
+	tx, err := h.store.GetDB().Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, req := range scenario.Requests {
+		if err := h.store.LogRequestTx(tx, &store.RequestLog{
+			Timestamp:    req.Timestamp,
+			PluginName:   req.Plugin,
+			Method:       req.Method,
+			Path:         req.Path,
+			StatusCode:   req.StatusCode,
+			DurationMs:   req.DurationMs,
+			UserID:       req.UserID,
+			IPAddress:    req.IPAddress,
+			UserAgent:    req.UserAgent,
+			Error:        req.Error,
+			RequestBody:  req.RequestBody,
+			ResponseBody: req.ResponseBody,
+		}); err != nil {
+			http.Error(w, "Failed to import request logs: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	rows, err := loadTables(tx, scenario.State, allowed)
+	if err != nil {
+		http.Error(w, "Failed to import data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to import data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.invalidateCounts()
+
+	plugins := make([]string, 0, len(scenario.State))
+	for name := range scenario.State {
+		plugins = append(plugins, name)
+	}
+	sort.Strings(plugins)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"plugins":  plugins,
+		"rows":     rows,
+		"requests": len(scenario.Requests),
+	})
+}
+
+// dumpTables reads every row of the existing tables in rowid order
+func dumpTables(db *sql.DB, tables []string) (ScenarioTables, error) {
+	dump := make(ScenarioTables)
+	for _, table := range tables {
+		if !tableNamePattern.MatchString(table) {
+			return nil, fmt.Errorf("invalid table name %q", table)
+		}
+		var exists int
+		if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			continue
+		}
+
+		columns, err := columnNames(db, table)
+		if err != nil {
+			return nil, err
+		}
+		// Selecting +column rather than column hides the declared type from the
+		// driver, so timestamps come back as the stored text and import unchanged
+		selects := make([]string, len(columns))
+		for i, column := range columns {
+			selects[i] = fmt.Sprintf(`+"%s"`, column)
+		}
+		rows, err := db.Query("SELECT " + strings.Join(selects, ", ") + " FROM " + table + " ORDER BY rowid")
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", table, err)
+		}
+		records := []map[string]any{}
+		for rows.Next() {
+			values := make([]any, len(columns))
+			ptrs := make([]any, len(columns))
+			for i := range values {
+				ptrs[i] = &values[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			record := make(map[string]any, len(columns))
+			for i, column := range columns {
+				if b, ok := values[i].([]byte); ok {
+					values[i] = string(b)
+				}
+				record[column] = values[i]
+			}
+			records = append(records, record)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		dump[table] = records
+	}
+	return dump, nil
+}
+
+// loadTables empties each plugin's tables and inserts the bundle's rows as
+// part of tx, returning how many rows were written. Foreign keys are checked
+// at commit so tables can be loaded in any order.
+func loadTables(tx *sql.Tx, state map[string]ScenarioTables, owned map[string][]string) (int, error) {
+	if _, err := tx.Exec("PRAGMA defer_foreign_keys = ON"); err != nil {
+		return 0, err
+	}
+
+	plugins := make([]string, 0, len(state))
+	for plugin := range state {
+		plugins = append(plugins, plugin)
+	}
+	sort.Strings(plugins)
+
+	var written int
+	for _, plugin := range plugins {
+		for _, table := range owned[plugin] {
+			exists, err := tableExists(tx, table)
+			if err != nil {
+				return 0, err
+			}
+			if !exists {
+				continue
+			}
+			if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+				return 0, fmt.Errorf("clear %s: %w", table, err)
+			}
+		}
+
+		// Tables load in the plugin's truncate order so rowids come out the same on every import
+		tables := state[plugin]
+		for _, table := range owned[plugin] {
+			records, ok := tables[table]
+			if !ok {
+				continue
+			}
+			names, err := columnNames(tx, table)
+			if err != nil {
+				return 0, err
+			}
+			columns := make(map[string]bool, len(names))
+			for _, name := range names {
+				columns[name] = true
+			}
+			for _, record := range records {
+				names := make([]string, 0, len(record))
+				for name := range record {
+					// Columns dropped since the export are skipped
+					if columns[name] {
+						names = append(names, name)
+					}
+				}
+				sort.Strings(names)
+				if len(names) == 0 {
+					continue
+				}
+
+				args := make([]any, len(names))
+				for i, name := range names {
+					args[i] = scenarioValue(record[name])
+				}
+				query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+					table, strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+				if _, err := tx.Exec(query, args...); err != nil {
+					return 0, fmt.Errorf("import %s: %w", table, err)
+				}
+				written++
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// queryer is satisfied by *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// columnNames returns the column names of a table in declaration order
+func columnNames(q queryer, table string) ([]string, error) {
+	rows, err := q.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+	return columns, nil
+}
+
+// scenarioValue converts a decoded JSON value to a SQL argument
+func scenarioValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any, []any:
+		// Structured values only appear if a bundle was edited by hand; store them as JSON text
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return v
+	}
+}
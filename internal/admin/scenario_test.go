@@ -0,0 +1,177 @@
+// ABOUTME: Tests for scenario export and import.
+// ABOUTME: Checks the since filter, the truncated flag, and that imports only touch plugin-owned tables and roll back on error.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func newScenarioRouter(t *testing.T) (*store.Store, chi.Router) {
+	t.Helper()
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	r := chi.NewRouter()
+	NewHandlers(s).RegisterRoutes(r)
+	return s, r
+}
+
+func TestScenarioRequestLogsSince(t *testing.T) {
+	setupTruncatablePlugin()
+	s, r := newScenarioRouter(t)
+
+	base := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	for i, path := range []string{"/before", "/after"} {
+		if err := s.LogRequest(&store.RequestLog{
+			Timestamp:    base.Add(time.Duration(i) * time.Hour),
+			PluginName:   "github",
+			Method:       "POST",
+			Path:         path,
+			StatusCode:   201,
+			RequestBody:  `{"title":"x"}`,
+			ResponseBody: `{"id":1}`,
+		}); err != nil {
+			t.Fatalf("Failed to insert test log: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/admin/export/scenario?since=2025-06-01T09:30:00Z&plugin=wipeable", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(w.Body.Bytes(), &scenario); err != nil {
+		t.Fatalf("Invalid bundle: %v", err)
+	}
+	if len(scenario.Requests) != 1 || scenario.Requests[0].Path != "/after" {
+		t.Fatalf("Expected only the request after since, got %+v", scenario.Requests)
+	}
+	if scenario.Requests[0].RequestBody != `{"title":"x"}` || scenario.Requests[0].ResponseBody != `{"id":1}` {
+		t.Errorf("Expected captured bodies in the bundle, got %+v", scenario.Requests[0])
+	}
+	if _, ok := scenario.State["wipeable"]; !ok || len(scenario.State) != 1 {
+		t.Errorf("Expected state limited to the wipeable plugin, got %v", scenario.State)
+	}
+
+	// Replaying the bundle elsewhere recreates the request log
+	fresh, freshRouter := newScenarioRouter(t)
+	req = httptest.NewRequest("POST", "/admin/import/scenario", strings.NewReader(w.Body.String()))
+	w = httptest.NewRecorder()
+	freshRouter.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	logs, err := fresh.GetRequestLogs(&store.RequestLogQuery{})
+	if err != nil {
+		t.Fatalf("Failed to read imported logs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Path != "/after" || !logs[0].Timestamp.Equal(base.Add(time.Hour)) {
+		t.Errorf("Expected the /after request at its original time, got %+v", logs)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/export/scenario?since=yesterday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid since, got %d", w.Code)
+	}
+}
+
+func TestScenarioImportRejectsForeignTables(t *testing.T) {
+	setupTruncatablePlugin()
+	_, r := newScenarioRouter(t)
+
+	for name, bundle := range map[string]string{
+		"unknown plugin":  `{"version": 1, "state": {"nope": {}}}`,
+		"unowned table":   `{"version": 1, "state": {"wipeable": {"request_logs": [{"path": "/x"}]}}}`,
+		"unknown version": `{"version": 99, "state": {}}`,
+		"malformed":       `{"version": `,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/admin/import/scenario", strings.NewReader(bundle)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestScenarioExportFlagsTruncatedLogs(t *testing.T) {
+	s, r := newScenarioRouter(t)
+
+	insert := func(n int) {
+		t.Helper()
+		if _, err := s.GetDB().Exec(`
+			WITH RECURSIVE seq(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM seq WHERE i < ?)
+			INSERT INTO request_logs (method, path, status_code, duration_ms) SELECT 'GET', '/bulk', 200, 1 FROM seq`, n); err != nil {
+			t.Fatalf("Failed to insert test logs: %v", err)
+		}
+	}
+	export := func() Scenario {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/export/scenario?plugin=none", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var scenario Scenario
+		if err := json.Unmarshal(w.Body.Bytes(), &scenario); err != nil {
+			t.Fatalf("Invalid bundle: %v", err)
+		}
+		return scenario
+	}
+
+	insert(maxCSVRows)
+	if scenario := export(); scenario.Truncated || len(scenario.Requests) != maxCSVRows {
+		t.Fatalf("Expected all %d requests untruncated, got %d (truncated=%v)", maxCSVRows, len(scenario.Requests), scenario.Truncated)
+	}
+
+	insert(1)
+	if scenario := export(); !scenario.Truncated || len(scenario.Requests) != maxCSVRows {
+		t.Errorf("Expected %d requests marked truncated, got %d (truncated=%v)", maxCSVRows, len(scenario.Requests), scenario.Truncated)
+	}
+}
+
+func TestScenarioImportRollsBackOnError(t *testing.T) {
+	setupTruncatablePlugin()
+	s, r := newScenarioRouter(t)
+	db := s.GetDB()
+	for _, q := range []string{
+		`CREATE TABLE wipe_widgets (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO wipe_widgets (name) VALUES ('sprocket')`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	// The duplicate id fails after the request log and the first widget are written
+	bundle := `{"version": 1,
+		"requests": [{"method": "GET", "path": "/imported", "statusCode": 200}],
+		"state": {"wipeable": {"wipe_widgets": [{"id": 1, "name": "a"}, {"id": 1, "name": "b"}]}}}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/admin/import/scenario", strings.NewReader(bundle)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var name string
+	if err := db.QueryRow(`SELECT group_concat(name) FROM wipe_widgets`).Scan(&name); err != nil || name != "sprocket" {
+		t.Errorf("Expected the original widgets to survive, got %q (%v)", name, err)
+	}
+	if count, err := s.CountRequestLogs(&store.RequestLogQuery{}); err != nil || count != 0 {
+		t.Errorf("Expected no imported request logs, got %d (%v)", count, err)
+	}
+}
@@ -4,6 +4,8 @@
 package admin
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"html"
 	"strings"
@@ -83,7 +85,7 @@ func RenderResourceForm(schema core.ResourceSchema, data map[string]interface{})
 		}
 
 		switch field.Type {
-		case "text":
+		case "text", "json":
 			sb.WriteString(fmt.Sprintf(`<textarea name="%s" %s class="mt-1 block w-full rounded border-gray-300 shadow-sm px-3 py-2 border">%s</textarea>`,
 				html.EscapeString(field.Name),
 				requiredAttr(field.Required),
@@ -166,7 +168,12 @@ func RenderResourceDetail(schema core.ResourceSchema, data map[string]interface{
 			html.EscapeString(field.Display)))
 
 		value := data[field.Name]
-		displayValue := formatDetailValue(field.Type, value)
+		var displayValue string
+		if field.Type == "json" {
+			displayValue = renderJSONEditor(field, value)
+		} else {
+			displayValue = formatDetailValue(field.Type, value)
+		}
 
 		sb.WriteString(fmt.Sprintf(`<dd class="text-sm text-gray-900 col-span-2">%s</dd>`,
 			displayValue))
@@ -177,6 +184,33 @@ func RenderResourceDetail(schema core.ResourceSchema, data map[string]interface{
 	return sb.String()
 }
 
+// renderJSONEditor renders a JSON field as a pretty-printed textarea. Editable
+// fields get a Save button; the plugin-detail page script validates and
+// PATCHes them.
+func renderJSONEditor(field core.FieldSchema, value interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div data-json-field="%s">`, html.EscapeString(field.Name)))
+
+	readonly := " readonly"
+	if field.Editable {
+		readonly = ""
+	}
+	sb.WriteString(fmt.Sprintf(`<textarea name="%s" rows="8" spellcheck="false"%s class="block w-full font-mono text-xs rounded border-gray-300 shadow-sm px-3 py-2 border">%s</textarea>`,
+		html.EscapeString(field.Name),
+		readonly,
+		html.EscapeString(formatJSONValue(value))))
+
+	if field.Editable {
+		sb.WriteString(`<div class="mt-2 flex items-center gap-3">`)
+		sb.WriteString(`<button type="button" data-json-save class="px-3 py-1 bg-purple-600 text-white rounded hover:bg-purple-700">Save</button>`)
+		sb.WriteString(`<span data-json-status class="text-xs"></span>`)
+		sb.WriteString(`</div>`)
+	}
+
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
 // RenderActions generates action buttons from ActionSchema
 func RenderActions(actions []core.ActionSchema, resourceID string) string {
 	var sb strings.Builder
@@ -256,6 +290,32 @@ func formatDetailValue(fieldType string, value interface{}) string {
 	return html.EscapeString(strValue)
 }
 
+// formatJSONValue pretty-prints a JSON field, which plugins may hand over as
+// raw JSON, a JSON string, or a decoded value
+func formatJSONValue(value interface{}) string {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case json.RawMessage:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		raw = encoded
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return out.String()
+}
+
 func isTruthy(value interface{}) bool {
 	if value == nil {
 		return false
@@ -0,0 +1,33 @@
+// ABOUTME: Live dataset statistics for the admin API.
+// ABOUTME: Reports per-plugin, per-table row counts from each plugin's store.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// apiStats handles GET /admin/api/stats, returning row counts keyed by plugin
+// and then table, e.g. {"google": {"gmail_messages": 8, ...}}. Unlike the nav
+// counts these are never cached.
+func (h *Handlers) apiStats(w http.ResponseWriter, r *http.Request) {
+	stats := make(map[string]map[string]int)
+	for _, plugin := range core.All() {
+		provider, ok := plugin.(core.StatsProvider)
+		if !ok {
+			continue
+		}
+		counts, err := provider.Stats()
+		if err != nil {
+			http.Error(w, plugin.Name()+": "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		stats[plugin.Name()] = counts
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
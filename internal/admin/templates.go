@@ -7,6 +7,9 @@ import (
 	"embed"
 	"html/template"
 	"io"
+	"net/http"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 //go:embed templates/*
@@ -18,9 +21,19 @@ var (
 	partialTmpls *template.Template
 )
 
+// templateFuncs are available to every template. basePath is replaced on each
+// render with the request's base path, which prefixes every admin URL.
+var templateFuncs = template.FuncMap{
+	"basePath": func() string { return "" },
+}
+
 // partialPaths defines all row templates used for htmx partial rendering
 var partialPaths = []string{
 	"templates/gmail/row.html",
+	"templates/gmail/labels.html",
+	"templates/nav_counts.html",
+	"templates/metrics.html",
+	"templates/truncate.html",
 	"templates/calendar/row.html",
 	"templates/people/row.html",
 	"templates/tasks/row.html",
@@ -44,6 +57,7 @@ func getPageDefinitions() map[string]string {
 		"tasks-form":    "templates/tasks/form.html",
 		"tasks-view":    "templates/tasks/view.html",
 		"logs-list":     "templates/logs/list.html",
+		"logs-diff":     "templates/logs/diff.html",
 		"plugin-list":   "templates/plugins/list.html",
 		"plugin-form":   "templates/plugins/form.html",
 		"plugin-detail": "templates/plugins/detail.html",
@@ -52,7 +66,7 @@ func getPageDefinitions() map[string]string {
 
 // parsePartialTemplates creates a template bundle with all row templates for htmx rendering
 func parsePartialTemplates() *template.Template {
-	return template.Must(template.ParseFS(templateFS, partialPaths...))
+	return template.Must(template.New("partials").Funcs(templateFuncs).ParseFS(templateFS, partialPaths...))
 }
 
 // parsePageTemplates creates a map of page templates, each with layout and partials
@@ -75,8 +89,8 @@ func parsePageTemplates() map[string]*template.Template {
 }
 
 func init() {
-	// Parse layout as base
-	layoutTmpl = template.Must(template.ParseFS(templateFS, "templates/layout.html"))
+	// Parse layout as base, with the theme and responsive styles it includes
+	layoutTmpl = template.Must(template.New("layout.html").Funcs(templateFuncs).ParseFS(templateFS, "templates/layout.html", "templates/theme.html", "templates/responsive.html"))
 
 	// Parse partials (row templates for htmx)
 	partialTmpls = parsePartialTemplates()
@@ -85,14 +99,33 @@ func init() {
 	pageTmpls = parsePageTemplates()
 }
 
-func renderPage(w io.Writer, page string, data any) error {
+func renderPage(w io.Writer, r *http.Request, page string, data any) error {
 	tmpl, ok := pageTmpls[page]
 	if !ok {
 		return nil
 	}
+	tmpl, err := withBasePath(tmpl, r)
+	if err != nil {
+		return err
+	}
 	return tmpl.ExecuteTemplate(w, "layout", data)
 }
 
-func renderPartial(w io.Writer, name string, data any) error {
-	return partialTmpls.ExecuteTemplate(w, name, data)
+func renderPartial(w io.Writer, r *http.Request, name string, data any) error {
+	tmpl, err := withBasePath(partialTmpls, r)
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+// withBasePath returns a copy of tmpl whose basePath is the request's. The
+// parsed templates are only ever cloned, never executed, so they stay clonable.
+func withBasePath(tmpl *template.Template, r *http.Request) (*template.Template, error) {
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	prefix := core.BasePath(r)
+	return clone.Funcs(template.FuncMap{"basePath": func() string { return prefix }}), nil
 }
@@ -0,0 +1,96 @@
+// ABOUTME: Tests for the admin light/dark themes.
+// ABOUTME: Checks the rendered pages for the theme toggle and that both dark palettes cover every light variable.
+
+package admin
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/store"
+)
+
+var themeVarPattern = regexp.MustCompile(`(--ish-[a-z0-9-]+):\s*(#[0-9a-f]{6});`)
+
+// themePalette returns the CSS variables declared in the block opened by selector
+func themePalette(t *testing.T, css, selector string) map[string]string {
+	t.Helper()
+	start := strings.Index(css, selector+" {")
+	if start < 0 {
+		t.Fatalf("theme block %q not found", selector)
+	}
+	block := css[start : start+strings.Index(css[start:], "}")]
+
+	palette := make(map[string]string)
+	for _, m := range themeVarPattern.FindAllStringSubmatch(block, -1) {
+		palette[m[1]] = m[2]
+	}
+	return palette
+}
+
+// renderedThemeCSS returns the theme stylesheet as served in an admin page
+func renderedThemeCSS(t *testing.T) string {
+	t.Helper()
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+	page := getAdminPage(t, s, "/admin/guide")
+
+	for _, want := range []string{
+		`<button type="button" onclick="toggleTheme()" title="Toggle dark mode" aria-label="Toggle dark mode"`,
+		"localStorage.getItem('ish-theme')",
+		"localStorage.setItem('ish-theme', next)",
+		`<style id="ish-theme">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("rendered page missing %q", want)
+		}
+	}
+	start := strings.Index(page, `<style id="ish-theme">`)
+	return page[start : start+strings.Index(page[start:], "</style>")]
+}
+
+func TestThemePalettesAreConsistent(t *testing.T) {
+	css := renderedThemeCSS(t)
+	if !strings.Contains(css, "@media (prefers-color-scheme: dark) {") {
+		t.Fatal("expected the dark theme to follow the system setting")
+	}
+	light := themePalette(t, css, "body")
+	system := themePalette(t, css, "body:not(.theme-light)")
+	override := themePalette(t, css, "body.theme-dark")
+
+	if len(light) == 0 {
+		t.Fatal("no theme variables found")
+	}
+	for name, value := range light {
+		if _, ok := system[name]; !ok {
+			t.Errorf("dark theme does not set %s", name)
+		}
+		if system[name] == value {
+			t.Errorf("%s is %s in both themes", name, value)
+		}
+		if system[name] != override[name] {
+			t.Errorf("%s differs between the system dark theme and the manual override", name)
+		}
+	}
+}
+
+func TestThemeRulesUseVariables(t *testing.T) {
+	css := compactCSS(renderedThemeCSS(t))
+
+	// The Tailwind classes the templates use are remapped onto the palette
+	for _, rule := range []string{
+		"body.bg-gray-100, body .bg-gray-100 { background-color: var(--ish-page); }",
+		"body .bg-white { background-color: var(--ish-surface); }",
+		"body .text-gray-900, body .hover\\:text-gray-900:hover { color: var(--ish-text-strong); }",
+		"body .text-blue-600, body .text-blue-700 { color: var(--ish-link); }",
+		"body .border-gray-300 { border-color: var(--ish-border-strong); }",
+	} {
+		if !strings.Contains(css, rule) {
+			t.Errorf("theme styles missing %q", rule)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+// ABOUTME: Bulk delete handlers for wiping plugin data from the admin UI.
+// ABOUTME: Snapshots tables before truncation so a delete can be undone for a short window.
+
+package admin
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// undoTTL is how long a bulk delete can be undone. Snapshots age by the wall
+// clock, not core.Now, so they still expire when ISH_FROZEN_TIME is set.
+const undoTTL = 60 * time.Second
+
+// undoTablePrefix starts the name of every snapshot table
+const undoTablePrefix = "undo_"
+
+// undoSnapshot records the snapshot tables taken before a bulk delete
+type undoSnapshot struct {
+	Token   string
+	Target  string
+	Tables  []string
+	Created time.Time
+}
+
+// snapshotTable names the table holding a table's rows for an undo token
+func (u *undoSnapshot) snapshotTable(table string) string {
+	return undoTablePrefix + u.Token + "_" + table
+}
+
+// ExpiresAt is when the snapshot stops being restorable
+func (u *undoSnapshot) ExpiresAt() time.Time {
+	return u.Created.Add(undoTTL)
+}
+
+// undoStore tracks the snapshots that can still be restored
+type undoStore struct {
+	mu        sync.Mutex
+	snapshots map[string]*undoSnapshot
+}
+
+// truncateTarget is a resolved bulk delete target
+type truncateTarget struct {
+	plugin core.Truncatable
+	tables []string
+	whole  bool // the target names the whole plugin rather than one resource type
+}
+
+// resolveTruncateTarget maps a plugin name or resource type to the tables it covers
+func resolveTruncateTarget(name string) (truncateTarget, bool) {
+	if plugin, ok := core.Get(name); ok {
+		truncatable, ok := plugin.(core.Truncatable)
+		if !ok {
+			return truncateTarget{}, false
+		}
+		return truncateTarget{plugin: truncatable, tables: core.GroupTables(truncatable.TruncateGroups()), whole: true}, true
+	}
+
+	for _, plugin := range core.All() {
+		truncatable, ok := plugin.(core.Truncatable)
+		if !ok {
+			continue
+		}
+		if tables, ok := truncatable.TruncateGroups()[name]; ok {
+			return truncateTarget{plugin: truncatable, tables: tables}, true
+		}
+	}
+	return truncateTarget{}, false
+}
+
+// truncatePlugin deletes all records for a plugin or resource type, e.g. DELETE /admin/gmail
+func (h *Handlers) truncatePlugin(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "target")
+	target, ok := resolveTruncateTarget(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("No plugin or resource type named %q supports bulk delete", name), http.StatusNotFound)
+		return
+	}
+
+	snapshot, err := h.snapshot(name, target.tables)
+	if err != nil {
+		http.Error(w, "Failed to snapshot data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	db := h.store.GetDB()
+	if target.whole {
+		err = target.plugin.Truncate(db)
+	} else {
+		err = core.TruncateTables(db, snapshot.Tables...)
+	}
+	if err != nil {
+		h.discardSnapshot(snapshot)
+		http.Error(w, "Failed to delete data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.truncated(w, r, snapshot)
+}
+
+// truncateAll deletes the data of every truncatable plugin, like `ish reset` without reseeding
+func (h *Handlers) truncateAll(w http.ResponseWriter, r *http.Request) {
+	var plugins []core.Truncatable
+	var tables []string
+	for _, plugin := range core.All() {
+		if truncatable, ok := plugin.(core.Truncatable); ok {
+			plugins = append(plugins, truncatable)
+			tables = append(tables, core.GroupTables(truncatable.TruncateGroups())...)
+		}
+	}
+
+	snapshot, err := h.snapshot("all", tables)
+	if err != nil {
+		http.Error(w, "Failed to snapshot data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	db := h.store.GetDB()
+	for _, plugin := range plugins {
+		if err := plugin.Truncate(db); err != nil {
+			// Put back whatever was already deleted so a failed reset is all-or-nothing
+			if restoreErr := h.restore(snapshot); restoreErr != nil {
+				log.Printf("Restoring after failed reset: %v", restoreErr)
+			}
+			http.Error(w, fmt.Sprintf("Failed to delete %s data: %v", plugin.Name(), err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.truncated(w, r, snapshot)
+}
+
+// undoTruncate restores the tables captured by a bulk delete, e.g. POST /admin/undo/{token}
+func (h *Handlers) undoTruncate(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	h.undo.mu.Lock()
+	h.expireSnapshotsLocked()
+	snapshot, ok := h.undo.snapshots[token]
+	h.undo.mu.Unlock()
+	if !ok {
+		http.Error(w, "Undo window has expired", http.StatusGone)
+		return
+	}
+
+	if err := h.restore(snapshot); err != nil {
+		http.Error(w, "Failed to restore data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.invalidateCounts()
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html")
+		renderPartial(w, r, "truncate-restored", snapshot)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"restored": snapshot.Target,
+		"tables":   snapshot.Tables,
+	})
+}
+
+// truncated writes the response for a successful bulk delete
+func (h *Handlers) truncated(w http.ResponseWriter, r *http.Request, snapshot *undoSnapshot) {
+	h.invalidateCounts()
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html")
+		renderPartial(w, r, "truncate-result", snapshot)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"deleted":   snapshot.Target,
+		"tables":    snapshot.Tables,
+		"undo":      core.BasePath(r) + "/admin/undo/" + snapshot.Token,
+		"expiresAt": snapshot.ExpiresAt().UTC().Format(time.RFC3339),
+	})
+}
+
+// snapshot copies each existing table into an undo table and registers the snapshot
+func (h *Handlers) snapshot(target string, tables []string) (*undoSnapshot, error) {
+	h.undo.mu.Lock()
+	defer h.undo.mu.Unlock()
+	h.expireSnapshotsLocked()
+
+	token, err := newUndoToken()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &undoSnapshot{Token: token, Target: target, Created: time.Now()}
+
+	db := h.store.GetDB()
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		if !tableNamePattern.MatchString(table) {
+			return nil, fmt.Errorf("invalid table name %q", table)
+		}
+		exists, err := tableExists(tx, table)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			// Plugins that haven't created their tables yet have nothing to delete
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM %s", snapshot.snapshotTable(table), table)); err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", table, err)
+		}
+		snapshot.Tables = append(snapshot.Tables, table)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	h.undo.snapshots[token] = snapshot
+	return snapshot, nil
+}
+
+// restore copies snapshot rows back into their tables and drops the snapshot
+func (h *Handlers) restore(snapshot *undoSnapshot) error {
+	tx, err := h.store.GetDB().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range snapshot.Tables {
+		if _, err := tx.Exec(fmt.Sprintf("INSERT OR REPLACE INTO %s SELECT * FROM %s", table, snapshot.snapshotTable(table))); err != nil {
+			return fmt.Errorf("restore %s: %w", table, err)
+		}
+		if _, err := tx.Exec("DROP TABLE " + snapshot.snapshotTable(table)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	h.undo.mu.Lock()
+	delete(h.undo.snapshots, snapshot.Token)
+	h.undo.mu.Unlock()
+	return nil
+}
+
+// discardSnapshot drops a snapshot that is no longer needed
+func (h *Handlers) discardSnapshot(snapshot *undoSnapshot) {
+	h.undo.mu.Lock()
+	defer h.undo.mu.Unlock()
+	h.dropSnapshotLocked(snapshot)
+}
+
+// expireSnapshotsLocked drops snapshots older than undoTTL. Callers hold h.undo.mu.
+func (h *Handlers) expireSnapshotsLocked() {
+	now := time.Now()
+	for _, snapshot := range h.undo.snapshots {
+		if now.After(snapshot.ExpiresAt()) {
+			h.dropSnapshotLocked(snapshot)
+		}
+	}
+}
+
+// dropSnapshotLocked removes a snapshot's tables. Callers hold h.undo.mu.
+func (h *Handlers) dropSnapshotLocked(snapshot *undoSnapshot) {
+	for _, table := range snapshot.Tables {
+		if _, err := h.store.GetDB().Exec("DROP TABLE IF EXISTS " + snapshot.snapshotTable(table)); err != nil {
+			log.Printf("Dropping undo snapshot of %s: %v", table, err)
+		}
+	}
+	delete(h.undo.snapshots, snapshot.Token)
+}
+
+// dropOrphanedSnapshots drops snapshot tables left by an earlier run. Undo
+// tokens only live in memory, so after a restart nothing can restore them.
+func dropOrphanedSnapshots(db *sql.DB) error {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name LIKE ? ESCAPE '\\'", `undo\_%`)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if !tableNamePattern.MatchString(table) {
+			continue
+		}
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			return fmt.Errorf("drop %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// invalidateCounts forces the nav badges to refresh after data changes
+func (h *Handlers) invalidateCounts() {
+	h.counts.mu.Lock()
+	defer h.counts.mu.Unlock()
+	h.counts.counts = nil
+}
+
+// isTruncatable reports whether a plugin supports bulk delete
+func isTruncatable(plugin core.Plugin) bool {
+	_, ok := plugin.(core.Truncatable)
+	return ok
+}
+
+// truncateGroupNames lists the resource types a plugin can bulk delete separately
+func truncateGroupNames(plugin core.Plugin) []string {
+	truncatable, ok := plugin.(core.Truncatable)
+	if !ok {
+		return nil
+	}
+	groups := truncatable.TruncateGroups()
+	if len(groups) < 2 {
+		return nil
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func tableExists(tx *sql.Tx, table string) (bool, error) {
+	var n int
+	err := tx.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n)
+	return n > 0, err
+}
+
+func newUndoToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,177 @@
+// ABOUTME: Tests for admin bulk delete and undo.
+// ABOUTME: Uses a mock truncatable plugin with two resource types.
+
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// truncatableMockPlugin stores widgets and gadgets as separate resource types
+type truncatableMockPlugin struct {
+	dashboardMockPlugin
+}
+
+func (m *truncatableMockPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"widgets": {"wipe_widgets"},
+		"gadgets": {"wipe_gadgets"},
+	}
+}
+
+func (m *truncatableMockPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(m.TruncateGroups())...)
+}
+
+var truncatablePluginRegistered = false
+
+func setupTruncatablePlugin() {
+	if truncatablePluginRegistered {
+		return
+	}
+	core.Register(&truncatableMockPlugin{dashboardMockPlugin{name: "wipeable"}})
+	truncatablePluginRegistered = true
+}
+
+func TestTruncateWithHtmxRendersUndo(t *testing.T) {
+	setupTruncatablePlugin()
+
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+
+	db := s.GetDB()
+	for _, q := range []string{
+		`CREATE TABLE wipe_widgets (id INTEGER PRIMARY KEY, name TEXT)`,
+		`CREATE TABLE wipe_gadgets (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO wipe_widgets (name) VALUES ('sprocket')`,
+		`INSERT INTO wipe_gadgets (name) VALUES ('gizmo')`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	r := chi.NewRouter()
+	NewHandlers(s).RegisterRoutes(r)
+
+	req := httptest.NewRequest("GET", "/admin/", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+	if !strings.Contains(body, `confirmTruncate('/admin/wipeable'`) || !strings.Contains(body, `confirmTruncate('/admin/widgets'`) {
+		t.Error("expected bulk delete buttons for the plugin and its resource types")
+	}
+	if !strings.Contains(body, `id="truncate-modal"`) {
+		t.Error("expected confirmation modal on dashboard")
+	}
+
+	req = httptest.NewRequest("DELETE", "/admin/widgets", nil)
+	req.Header.Set("HX-Request", "true")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body = w.Body.String()
+	if !strings.Contains(body, `hx-post="/admin/undo/`) {
+		t.Errorf("expected undo button in response, got %s", body)
+	}
+
+	var widgets, gadgets int
+	db.QueryRow(`SELECT COUNT(*) FROM wipe_widgets`).Scan(&widgets)
+	db.QueryRow(`SELECT COUNT(*) FROM wipe_gadgets`).Scan(&gadgets)
+	if widgets != 0 || gadgets != 1 {
+		t.Errorf("expected only widgets deleted, got widgets=%d gadgets=%d", widgets, gadgets)
+	}
+
+	start := strings.Index(body, `hx-post="`) + len(`hx-post="`)
+	undoPath := body[start : start+strings.Index(body[start:], `"`)]
+	req = httptest.NewRequest("POST", undoPath, nil)
+	req.Header.Set("HX-Request", "true")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Restored") {
+		t.Fatalf("expected restore confirmation, got %d: %s", w.Code, w.Body.String())
+	}
+	db.QueryRow(`SELECT COUNT(*) FROM wipe_widgets`).Scan(&widgets)
+	if widgets != 1 {
+		t.Errorf("expected widget restored, got %d", widgets)
+	}
+}
+
+func TestUndoSnapshotsExpireWithFrozenClock(t *testing.T) {
+	core.SetClock(core.NewFrozenClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	defer core.SetClock(core.RealClock{})
+
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+	db := s.GetDB()
+	if _, err := db.Exec(`CREATE TABLE expire_widgets (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	h := NewHandlers(s)
+	snapshot, err := h.snapshot("widgets", []string{"expire_widgets"})
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	// The frozen clock never moves, so only wall-clock age can expire the snapshot
+	snapshot.Created = time.Now().Add(-2 * undoTTL)
+
+	h.undo.mu.Lock()
+	h.expireSnapshotsLocked()
+	h.undo.mu.Unlock()
+
+	if _, ok := h.undo.snapshots[snapshot.Token]; ok {
+		t.Error("expected the snapshot to expire")
+	}
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = ?`, snapshot.snapshotTable("expire_widgets")).Scan(&n)
+	if n != 0 {
+		t.Error("expected the snapshot table to be dropped")
+	}
+}
+
+func TestNewHandlersDropsOrphanedSnapshots(t *testing.T) {
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test store: %v", err)
+	}
+	defer s.Close()
+	db := s.GetDB()
+	for _, q := range []string{
+		`CREATE TABLE undo_0123456789abcdef_widgets (id INTEGER PRIMARY KEY)`,
+		`CREATE TABLE undone_widgets (id INTEGER PRIMARY KEY)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	NewHandlers(s)
+
+	var orphans, kept int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'undo_0123456789abcdef_widgets'`).Scan(&orphans)
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'undone_widgets'`).Scan(&kept)
+	if orphans != 0 {
+		t.Error("expected the orphaned snapshot table to be dropped")
+	}
+	if kept != 1 {
+		t.Error("expected tables that only share the prefix's letters to be kept")
+	}
+}
@@ -1,22 +1,67 @@
 // ABOUTME: Authentication middleware for fake Google API requests.
-// ABOUTME: Parses Bearer tokens and extracts user identity for request context.
+// ABOUTME: Parses Bearer tokens, applies the configured default user, and guards non-anonymous routes.
 
 package auth
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 type contextKey string
 
-const userContextKey contextKey = "user"
+const (
+	userContextKey      contextKey = "user"
+	anonymousContextKey contextKey = "anonymous"
+)
+
+// DefaultUserEnv names the environment variable holding the fallback user for requests without credentials
+const DefaultUserEnv = "ISH_DEFAULT_USER"
+
+// fallbackUser is used for anonymous requests when no default user is configured
+const fallbackUser = "default"
+
+var (
+	defaultUser   string
+	defaultUserMu sync.RWMutex
+)
+
+// SetDefaultUser sets the user that requests without an Authorization header act as.
+// An empty user clears it, so anonymous requests are only allowed on declared routes.
+func SetDefaultUser(user string) {
+	defaultUserMu.Lock()
+	defer defaultUserMu.Unlock()
+	defaultUser = strings.TrimSpace(user)
+}
+
+// DefaultUser returns the configured default user, or "" if none is set
+func DefaultUser() string {
+	defaultUserMu.RLock()
+	defer defaultUserMu.RUnlock()
+	return defaultUser
+}
+
+// DefaultUserFromEnv applies ISH_DEFAULT_USER and returns the resulting default user
+func DefaultUserFromEnv() string {
+	SetDefaultUser(os.Getenv(DefaultUserEnv))
+	return DefaultUser()
+}
 
 func Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := extractUser(r.Header.Get("Authorization"))
+		anonymous := user == ""
+		if anonymous {
+			user = DefaultUser()
+		}
 		ctx := context.WithValue(r.Context(), userContextKey, user)
+		ctx = context.WithValue(ctx, anonymousContextKey, anonymous)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -24,14 +69,86 @@ func Middleware(next http.Handler) http.Handler {
 func UserFromContext(ctx context.Context) string {
 	user, ok := ctx.Value(userContextKey).(string)
 	if !ok || user == "" {
-		return "default"
+		return fallbackUser
 	}
 	return user
 }
 
+// IsAnonymous reports whether the request carried no credentials
+func IsAnonymous(ctx context.Context) bool {
+	anonymous, _ := ctx.Value(anonymousContextKey).(bool)
+	return anonymous
+}
+
+// RequireUser rejects anonymous requests with 401 unless a default user is
+// configured or the route matches one of the anonymous patterns. Patterns are
+// paths with optional "{param}" segments and a trailing "*", optionally
+// prefixed by an HTTP method, e.g. "GET /discovery/v1/apis/{api}/{version}/rest".
+func RequireUser(anonymousRoutes []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !IsAnonymous(r.Context()) || DefaultUser() != "" || allowsAnonymous(anonymousRoutes, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]any{
+				"error": map[string]any{
+					"code":    http.StatusUnauthorized,
+					"message": "Request is missing required authentication credential. Send an Authorization header or set " + DefaultUserEnv + ".",
+					"status":  "UNAUTHENTICATED",
+				},
+			})
+		})
+	}
+}
+
+// allowsAnonymous reports whether the request matches any anonymous route
+// pattern. Patterns are relative to ISH_BASE_PATH.
+func allowsAnonymous(patterns []string, r *http.Request) bool {
+	for _, pattern := range patterns {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !ok {
+			method, path = "", pattern
+		}
+		if method != "" && !strings.EqualFold(method, r.Method) {
+			continue
+		}
+		if matchRoute(path, strings.TrimPrefix(r.URL.Path, core.BasePath(r))) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRoute compares a route pattern to a request path segment by segment
+func matchRoute(pattern, path string) bool {
+	patternSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, seg := range patternSegs {
+		if seg == "*" {
+			return true
+		}
+		if i >= len(pathSegs) {
+			return false
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			continue
+		}
+		if seg != pathSegs[i] {
+			return false
+		}
+	}
+	return len(patternSegs) == len(pathSegs)
+}
+
+// extractUser returns the user named by the Authorization header, or "" if it carries no credentials
 func extractUser(authHeader string) string {
 	if authHeader == "" {
-		return "default"
+		return ""
 	}
 
 	// Remove "Bearer " prefix
@@ -39,7 +156,7 @@ func extractUser(authHeader string) string {
 	token = strings.TrimSpace(token)
 
 	if token == "" {
-		return "default"
+		return ""
 	}
 
 	// Check for "user:" prefix - allows explicit user specification
@@ -48,3 +48,72 @@ func TestMiddleware_ExtractsUser(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddleware_DefaultUser(t *testing.T) {
+	SetDefaultUser("quickstart")
+	t.Cleanup(func() { SetDefaultUser("") })
+
+	var gotUser string
+	var gotAnonymous bool
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = UserFromContext(r.Context())
+		gotAnonymous = IsAnonymous(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+	if gotUser != "quickstart" || !gotAnonymous {
+		t.Errorf("no header: user = %q, anonymous = %v; want quickstart, true", gotUser, gotAnonymous)
+	}
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer user:harper")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if gotUser != "harper" || gotAnonymous {
+		t.Errorf("explicit user: user = %q, anonymous = %v; want harper, false", gotUser, gotAnonymous)
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	anonymousRoutes := []string{
+		"GET /discovery/v1/apis/{api}/{version}/rest",
+		"/public/*",
+	}
+	handler := Middleware(RequireUser(anonymousRoutes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		authHeader  string
+		defaultUser string
+		wantStatus  int
+	}{
+		{"anonymous private route", "GET", "/gmail/v1/users/me/messages", "", "", http.StatusUnauthorized},
+		{"authenticated private route", "GET", "/gmail/v1/users/me/messages", "Bearer user:harper", "", http.StatusOK},
+		{"default user private route", "GET", "/gmail/v1/users/me/messages", "", "harper", http.StatusOK},
+		{"anonymous declared route", "GET", "/discovery/v1/apis/gmail/v1/rest", "", "", http.StatusOK},
+		{"declared route wrong method", "POST", "/discovery/v1/apis/gmail/v1/rest", "", "", http.StatusUnauthorized},
+		{"declared route extra segment", "GET", "/discovery/v1/apis/gmail/v1/rest/extra", "", "", http.StatusUnauthorized},
+		{"wildcard route", "DELETE", "/public/a/b/c", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDefaultUser(tt.defaultUser)
+			defer SetDefaultUser("")
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -1,36 +1,65 @@
-// ABOUTME: Auto-reply feature for sent emails using OpenAI.
-// ABOUTME: Generates realistic email responses with random delays.
+// ABOUTME: Auto-reply feature for sent emails, using a template or OpenAI.
+// ABOUTME: Internal recipients answer into the sender's INBOX on the same thread after a random delay.
 
 package autoreply
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
 	"math/rand"
+	"net/mail"
 	"os"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
 )
 
-// GmailMessageSender is the interface for sending Gmail messages
-type GmailMessageSender interface {
-	SendGmailMessage(userID, from, to, subject, body string) (any, error)
+// GmailMessageInserter is the interface for placing Gmail messages in a mailbox
+type GmailMessageInserter interface {
+	InsertGmailMessage(userID, threadID, from, to, subject, body string, labels []string) (any, error)
+}
+
+// Message is a sent message that recipients may auto-reply to
+type Message struct {
+	UserID   string
+	ThreadID string
+	From     string
+	To       string
+	Subject  string
+	Body     string
+	// AutoSubmitted is the message's Auto-Submitted header (RFC 3834); any
+	// value but "no" marks it machine-generated, so it gets no reply
+	AutoSubmitted string
+}
+
+// automatedMailboxes are local parts that never answer mail, and that
+// replies aren't sent to either, so two responders can't reply to each other
+var automatedMailboxes = []string{
+	"noreply", "no-reply", "donotreply", "do-not-reply",
+	"autoreply", "auto-reply", "mailer-daemon", "postmaster", "bounce", "bounces",
 }
 
 // AutoReply handles automatic email responses
 type AutoReply struct {
-	store     GmailMessageSender
+	store     GmailMessageInserter
 	openaiKey string
 	enabled   bool
 	minDelay  int
 	maxDelay  int
+	domains   []string
+	template  *template.Template
 	templates []string
 }
 
-// New creates a new AutoReply instance
-func New(s GmailMessageSender) *AutoReply {
+// New creates a new AutoReply instance configured from the environment:
+// ISH_AUTO_REPLY enables it, ISH_REPLY_DELAY_MIN/MAX bound the delay in
+// seconds, ISH_AUTO_REPLY_DOMAINS lists the internal domains whose addresses
+// reply ("*" for any), and ISH_AUTO_REPLY_TEMPLATE sets the reply text.
+func New(s GmailMessageInserter) *AutoReply {
 	enabled := os.Getenv("ISH_AUTO_REPLY") == "true"
 	openaiKey := os.Getenv("OPENAI_API_KEY")
 
@@ -43,6 +72,32 @@ func New(s GmailMessageSender) *AutoReply {
 	if val := os.Getenv("ISH_REPLY_DELAY_MAX"); val != "" {
 		fmt.Sscanf(val, "%d", &maxDelay)
 	}
+	if minDelay < 0 {
+		minDelay = 0
+	}
+	if maxDelay < minDelay {
+		maxDelay = minDelay
+	}
+
+	domains := []string{"example.com"}
+	if val := os.Getenv("ISH_AUTO_REPLY_DOMAINS"); val != "" {
+		domains = nil
+		for _, domain := range strings.Split(val, ",") {
+			if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+				domains = append(domains, domain)
+			}
+		}
+	}
+
+	var tmpl *template.Template
+	if val := os.Getenv("ISH_AUTO_REPLY_TEMPLATE"); val != "" {
+		var err error
+		tmpl, err = template.New("reply").Parse(val)
+		if err != nil {
+			log.Printf("Invalid ISH_AUTO_REPLY_TEMPLATE, using built-in replies: %v", err)
+			tmpl = nil
+		}
+	}
 
 	return &AutoReply{
 		store:     s,
@@ -50,6 +105,8 @@ func New(s GmailMessageSender) *AutoReply {
 		enabled:   enabled,
 		minDelay:  minDelay,
 		maxDelay:  maxDelay,
+		domains:   domains,
+		template:  tmpl,
 		templates: []string{
 			"Thanks for your email! I'll get back to you shortly.",
 			"Got it, I'll take a look at this.",
@@ -60,13 +117,69 @@ func New(s GmailMessageSender) *AutoReply {
 	}
 }
 
-// GenerateReply generates an auto-reply for a sent message
-func (ar *AutoReply) GenerateReply(userID, from, to, subject, body string, threadID string) {
+// GenerateReply has each internal recipient of a sent message reply to it
+func (ar *AutoReply) GenerateReply(msg Message) {
 	if !ar.enabled {
 		return
 	}
+	for _, responder := range ar.responders(msg) {
+		ar.replyAfterDelay(msg, responder)
+	}
+}
 
-	// Run in background goroutine with timeout context
+// responders returns the recipients that should auto-reply to msg
+func (ar *AutoReply) responders(msg Message) []string {
+	if msg.AutoSubmitted != "" && !strings.EqualFold(strings.TrimSpace(msg.AutoSubmitted), "no") {
+		return nil
+	}
+	sender, err := mail.ParseAddress(msg.From)
+	if err != nil || isAutomated(sender.Address) {
+		return nil
+	}
+	recipients, err := mail.ParseAddressList(msg.To)
+	if err != nil {
+		return nil
+	}
+
+	var responders []string
+	for _, recipient := range recipients {
+		address := recipient.Address
+		if strings.EqualFold(address, sender.Address) || isAutomated(address) || !ar.isInternal(address) {
+			continue
+		}
+		if recipient.Name != "" {
+			address = recipient.String()
+		}
+		responders = append(responders, address)
+	}
+	return responders
+}
+
+// isInternal reports whether address belongs to one of the internal domains
+func (ar *AutoReply) isInternal(address string) bool {
+	_, domain, _ := strings.Cut(strings.ToLower(address), "@")
+	for _, internal := range ar.domains {
+		if internal == "*" || internal == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// isAutomated reports whether address is a mailbox that never answers mail
+func isAutomated(address string) bool {
+	local, _, _ := strings.Cut(strings.ToLower(address), "@")
+	for _, mailbox := range automatedMailboxes {
+		if local == mailbox {
+			return true
+		}
+	}
+	return false
+}
+
+// replyAfterDelay waits a random delay in the background, then places the
+// responder's reply in the sender's INBOX on the original thread
+func (ar *AutoReply) replyAfterDelay(msg Message, responder string) {
 	go func() {
 		// Create context with 5-minute timeout for entire operation
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -80,38 +193,48 @@ func (ar *AutoReply) GenerateReply(userID, from, to, subject, body string, threa
 		case <-time.After(delay):
 			// Delay completed, continue
 		case <-ctx.Done():
-			log.Printf("Auto-reply cancelled for message from %s: %v", from, ctx.Err())
+			log.Printf("Auto-reply cancelled for message from %s: %v", msg.From, ctx.Err())
 			return
 		}
 
-		// Generate reply content
-		var replyBody string
-		if ar.openaiKey != "" {
-			var err error
-			replyBody, err = ar.generateWithOpenAI(ctx, subject, from, body)
-			if err != nil {
-				log.Printf("OpenAI generation failed, using template: %v", err)
-				replyBody = ar.getRandomTemplate()
-			}
-		} else {
-			replyBody = ar.getRandomTemplate()
-		}
+		replyBody := ar.replyBody(ctx, msg, responder)
 
-		// Create reply message
-		replySubject := subject
-		if len(subject) > 3 && subject[:3] != "Re:" {
-			replySubject = "Re: " + subject
+		replySubject := msg.Subject
+		if !strings.HasPrefix(strings.ToLower(replySubject), "re:") {
+			replySubject = "Re: " + replySubject
 		}
 
-		_, err := ar.store.SendGmailMessage(userID, to, from, replySubject, replyBody)
+		_, err := ar.store.InsertGmailMessage(msg.UserID, msg.ThreadID, responder, msg.From, replySubject, replyBody, []string{"INBOX", "UNREAD"})
 		if err != nil {
 			log.Printf("Failed to create auto-reply: %v", err)
 		} else {
-			log.Printf("Auto-reply sent from %s to %s", to, from)
+			log.Printf("Auto-reply sent from %s to %s", responder, msg.From)
 		}
 	}()
 }
 
+// replyBody renders ISH_AUTO_REPLY_TEMPLATE when set, otherwise asks OpenAI
+// when a key is configured, falling back to a built-in reply
+func (ar *AutoReply) replyBody(ctx context.Context, msg Message, responder string) string {
+	if ar.template != nil {
+		var buf bytes.Buffer
+		data := struct{ From, To, Subject, Body string }{msg.From, responder, msg.Subject, msg.Body}
+		if err := ar.template.Execute(&buf, data); err != nil {
+			log.Printf("Auto-reply template failed, using built-in reply: %v", err)
+			return ar.getRandomTemplate()
+		}
+		return buf.String()
+	}
+	if ar.openaiKey != "" {
+		replyBody, err := ar.generateWithOpenAI(ctx, msg.Subject, msg.From, msg.Body)
+		if err == nil {
+			return replyBody
+		}
+		log.Printf("OpenAI generation failed, using template: %v", err)
+	}
+	return ar.getRandomTemplate()
+}
+
 func (ar *AutoReply) generateWithOpenAI(ctx context.Context, subject, from, body string) (string, error) {
 	// Create a child context with 30-second timeout for OpenAI request
 	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -0,0 +1,54 @@
+// ABOUTME: Tests for choosing which recipients auto-reply.
+// ABOUTME: Covers internal domains and the guards that stop responders replying to each other.
+
+package autoreply
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResponders(t *testing.T) {
+	t.Setenv("ISH_AUTO_REPLY_DOMAINS", "example.com, corp.test")
+	ar := New(nil)
+
+	tests := []struct {
+		name string
+		msg  Message
+		want []string
+	}{
+		{
+			name: "internal recipients reply",
+			msg:  Message{From: "alice@example.com", To: "bob@example.com, Dana <dana@corp.test>, eve@gmail.com"},
+			want: []string{"bob@example.com", `"Dana" <dana@corp.test>`},
+		},
+		{
+			name: "automated mailboxes don't reply",
+			msg:  Message{From: "alice@example.com", To: "noreply@example.com, Auto-Reply@example.com"},
+		},
+		{
+			name: "mail from an automated sender gets no reply",
+			msg:  Message{From: "mailer-daemon@example.com", To: "bob@example.com"},
+		},
+		{
+			name: "auto-submitted mail gets no reply",
+			msg:  Message{From: "alice@example.com", To: "bob@example.com", AutoSubmitted: "auto-replied"},
+		},
+		{
+			name: "Auto-Submitted: no is a person",
+			msg:  Message{From: "alice@example.com", To: "bob@example.com", AutoSubmitted: "no"},
+			want: []string{"bob@example.com"},
+		},
+		{
+			name: "senders don't reply to themselves",
+			msg:  Message{From: "alice@example.com", To: "Alice@example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ar.responders(tt.msg); !slices.Equal(got, tt.want) {
+				t.Errorf("responders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
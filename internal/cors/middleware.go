@@ -0,0 +1,84 @@
+// ABOUTME: CORS middleware so browser-based apps can call the mock APIs.
+// ABOUTME: Answers preflight requests and sets Access-Control-Allow-* for origins in ISH_CORS_ORIGINS.
+
+package cors
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// OriginsEnv names the environment variable holding a comma-separated list of allowed origins
+const OriginsEnv = "ISH_CORS_ORIGINS"
+
+const (
+	allowMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	// allowHeaders is used when a preflight does not list the headers it needs
+	allowHeaders = "Authorization, Content-Type, Accept"
+	// exposeHeaders lets scripts read pagination and resource location headers
+	exposeHeaders = "Link, Location, ETag"
+	maxAge        = "600"
+)
+
+// OriginsFromEnv returns the origins in ISH_CORS_ORIGINS, or "*" (any origin) if unset
+func OriginsFromEnv() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv(OriginsEnv), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, strings.TrimSuffix(origin, "/"))
+		}
+	}
+	if len(origins) == 0 {
+		return []string{"*"}
+	}
+	return origins
+}
+
+// Middleware adds CORS headers for requests from allowed origins and answers
+// their preflight OPTIONS requests itself, so it must run before auth.
+// Origins listed explicitly are echoed back with credentials allowed; "*"
+// answers any other origin with a literal "*" and no credentials.
+// The admin UI is never shared with other origins.
+func Middleware(origins []string) func(http.Handler) http.Handler {
+	anyOrigin := slices.Contains(origins, "*")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+			listed := slices.Contains(origins, origin)
+			if origin == "" || !(anyOrigin || listed) || core.IsAdminPath(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			if listed {
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Credentials", "true")
+			} else {
+				h.Set("Access-Control-Allow-Origin", "*")
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				h.Set("Access-Control-Allow-Methods", allowMethods)
+				if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+					h.Set("Access-Control-Allow-Headers", requested)
+				} else {
+					h.Set("Access-Control-Allow-Headers", allowHeaders)
+				}
+				h.Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			h.Set("Access-Control-Expose-Headers", exposeHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// ABOUTME: Tests for CORS middleware.
+// ABOUTME: Verifies preflight responses, allowed and rejected origins, and ISH_CORS_ORIGINS parsing.
+
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// okHandler stands in for the auth-protected API behind the middleware
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestMiddleware_Preflight(t *testing.T) {
+	handler := Middleware([]string{"http://localhost:5173"})(okHandler)
+
+	req := httptest.NewRequest("OPTIONS", "/gmail/v1/users/me/messages", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d (preflight must not reach auth)", w.Code, http.StatusNoContent)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "http://localhost:5173",
+		"Access-Control-Allow-Methods":     allowMethods,
+		"Access-Control-Allow-Headers":     "authorization, content-type",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           maxAge,
+	}
+	for header, value := range want {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+func TestMiddleware_AnyOrigin(t *testing.T) {
+	// The default configuration, with ISH_CORS_ORIGINS unset
+	t.Setenv(OriginsEnv, "")
+	handler := Middleware(OriginsFromEnv())(okHandler)
+
+	req := httptest.NewRequest("OPTIONS", "/gmail/v1/users/me/messages/1", nil)
+	req.Header.Set("Origin", "http://evil.test")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want a literal *", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want none for any origin", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, "DELETE") {
+		t.Errorf("Access-Control-Allow-Methods = %q, want DELETE allowed", got)
+	}
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DELETE preflight status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestMiddleware_SkipsAdmin(t *testing.T) {
+	handler := Middleware([]string{"*", "http://app.test"})(okHandler)
+
+	for _, path := range []string{"/admin/all", "/admin/github/dispatch-events"} {
+		req := httptest.NewRequest("OPTIONS", path, nil)
+		req.Header.Set("Origin", "http://app.test")
+		req.Header.Set("Access-Control-Request-Method", "DELETE")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want none", path, got)
+		}
+		if w.Code == http.StatusNoContent {
+			t.Errorf("%s: preflight was approved", path)
+		}
+	}
+}
+
+func TestMiddleware_PreflightDefaultHeaders(t *testing.T) {
+	handler := Middleware([]string{"*"})(okHandler)
+
+	req := httptest.NewRequest("OPTIONS", "/user", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got != allowHeaders {
+		t.Errorf("Access-Control-Allow-Headers = %q, want %q", got, allowHeaders)
+	}
+}
+
+func TestMiddleware_Origins(t *testing.T) {
+	handler := Middleware([]string{"http://app.test"})(okHandler)
+
+	tests := []struct {
+		name       string
+		origin     string
+		wantOrigin string
+	}{
+		{"allowed origin", "http://app.test", "http://app.test"},
+		{"other origin", "http://evil.test", ""},
+		{"no origin", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/user", nil)
+			req.Header.Set("Authorization", "Bearer user:me")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+		})
+	}
+}
+
+func TestOriginsFromEnv(t *testing.T) {
+	tests := []struct {
+		env  string
+		want []string
+	}{
+		{"", []string{"*"}},
+		{"http://a.test", []string{"http://a.test"}},
+		{" http://a.test/ , http://b.test:8080,", []string{"http://a.test", "http://b.test:8080"}},
+	}
+	for _, tt := range tests {
+		t.Setenv(OriginsEnv, tt.env)
+		if got := OriginsFromEnv(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("OriginsFromEnv() with %q = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// ABOUTME: Request log filters configured from ISH_LOG_PLUGINS, ISH_LOG_MIN_DURATION_MS and ISH_LOG_IGNORE_PATHS.
+// ABOUTME: Filtered requests are dropped before they reach request_logs or the console.
+
+package logging
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables that configure request log filtering
+const (
+	PluginsEnv     = "ISH_LOG_PLUGINS"
+	MinDurationEnv = "ISH_LOG_MIN_DURATION_MS"
+	IgnorePathsEnv = "ISH_LOG_IGNORE_PATHS"
+)
+
+// Options configures Middleware
+type Options struct {
+	// Logger writes a console line per request; nil disables console output
+	Logger Logger
+	// IncludePlugins, when set, limits logging to requests for these plugins
+	IncludePlugins []string
+	// MinDuration drops requests that finish faster than this
+	MinDuration time.Duration
+	// IgnorePaths drops requests to these exact paths
+	IgnorePaths []string
+	// Stream, when set, receives each request log after it is stored
+	Stream *Stream
+}
+
+// OptionsFromEnv reads the request log filters from the environment. The
+// Logger is left nil for the caller to set.
+func OptionsFromEnv() (Options, error) {
+	opts := Options{
+		IncludePlugins: splitList(os.Getenv(PluginsEnv)),
+		IgnorePaths:    splitList(os.Getenv(IgnorePathsEnv)),
+	}
+	if value := strings.TrimSpace(os.Getenv(MinDurationEnv)); value != "" {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 {
+			return Options{}, fmt.Errorf("%s must be a non-negative number of milliseconds", MinDurationEnv)
+		}
+		opts.MinDuration = time.Duration(ms) * time.Millisecond
+	}
+	return opts, nil
+}
+
+// splitList splits a comma-separated list, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ignores reports whether a request is filtered out before it is handled
+func (o Options) ignores(path, plugin string) bool {
+	if len(o.IncludePlugins) > 0 && !slices.Contains(o.IncludePlugins, plugin) {
+		return true
+	}
+	return slices.Contains(o.IgnorePaths, path)
+}
@@ -0,0 +1,179 @@
+// ABOUTME: Tests for request log filtering.
+// ABOUTME: Verifies filtered requests never reach request_logs and the env vars parse.
+
+package logging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+)
+
+// loggedPaths waits for want request logs to be written, then returns their paths
+func loggedPaths(t *testing.T, s *store.Store, want int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		logs, err := s.GetRequestLogs(&store.RequestLogQuery{Limit: 100})
+		if err != nil {
+			t.Fatalf("GetRequestLogs() error = %v", err)
+		}
+		if len(logs) >= want || time.Now().After(deadline) {
+			var paths []string
+			for _, l := range logs {
+				paths = append(paths, l.Path)
+			}
+			slices.Sort(paths)
+			return paths
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMiddleware_FilteredRequestsAreNotStored(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "include plugins",
+			opts: Options{IncludePlugins: []string{"github"}},
+			want: []string{"/repos/alice/app/issues"},
+		},
+		{
+			name: "ignore paths",
+			opts: Options{IgnorePaths: []string{"/favicon.ico", "/gmail/v1/users/me/messages"}},
+			want: []string{"/repos/alice/app/issues", "/slow"},
+		},
+		{
+			name: "min duration",
+			opts: Options{MinDuration: 20 * time.Millisecond},
+			want: []string{"/slow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := store.New(filepath.Join(t.TempDir(), "logs.db"))
+			if err != nil {
+				t.Fatalf("store.New() error = %v", err)
+			}
+			t.Cleanup(func() { s.Close() })
+
+			handler := Middleware(s, tt.opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/slow" {
+					time.Sleep(30 * time.Millisecond)
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			for _, path := range []string{"/gmail/v1/users/me/messages", "/repos/alice/app/issues", "/favicon.ico", "/slow"} {
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+				if rr.Code != http.StatusOK {
+					t.Fatalf("GET %s: status %d, want filtered requests still served", path, rr.Code)
+				}
+			}
+
+			if got := loggedPaths(t, s, len(tt.want)); !slices.Equal(got, tt.want) {
+				t.Errorf("request_logs paths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware_MatchesPathsUnderBasePath(t *testing.T) {
+	s, err := store.New(filepath.Join(t.TempDir(), "logs.db"))
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	// Mounted under a base path, as the server does with ISH_BASE_PATH
+	opts := Options{
+		IncludePlugins: []string{"github", "google", "unknown"},
+		IgnorePaths:    []string{"/gmail/v1/users/me/messages"},
+	}
+	handler := core.WithBasePath("/ish")(Middleware(s, opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	for _, path := range []string{
+		"/ish/repos/alice/app/issues",
+		"/ish/gmail/v1/users/me/messages",
+		"/ish/v3/mail/send",
+		"/ish/healthz",
+		"/ish/api/metrics",
+		"/ish/admin/logs",
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	// Give any wrongly stored request time to arrive
+	loggedPaths(t, s, 2)
+	logs, err := s.GetRequestLogs(&store.RequestLogQuery{Limit: 100})
+	if err != nil {
+		t.Fatalf("GetRequestLogs() error = %v", err)
+	}
+	if len(logs) != 1 || logs[0].Path != "/ish/repos/alice/app/issues" || logs[0].PluginName != "github" {
+		var got []string
+		for _, l := range logs {
+			got = append(got, l.PluginName+" "+l.Path)
+		}
+		t.Errorf("request_logs = %v, want only github /ish/repos/alice/app/issues", got)
+	}
+}
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv(PluginsEnv, "github, google,")
+	t.Setenv(MinDurationEnv, "10")
+	t.Setenv(IgnorePathsEnv, "/healthz,/favicon.ico")
+
+	opts, err := OptionsFromEnv()
+	if err != nil {
+		t.Fatalf("OptionsFromEnv() error = %v", err)
+	}
+	if !slices.Equal(opts.IncludePlugins, []string{"github", "google"}) {
+		t.Errorf("IncludePlugins = %v", opts.IncludePlugins)
+	}
+	if opts.MinDuration != 10*time.Millisecond {
+		t.Errorf("MinDuration = %v, want 10ms", opts.MinDuration)
+	}
+	if !slices.Equal(opts.IgnorePaths, []string{"/healthz", "/favicon.ico"}) {
+		t.Errorf("IgnorePaths = %v", opts.IgnorePaths)
+	}
+
+	t.Setenv(MinDurationEnv, "fast")
+	if _, err := OptionsFromEnv(); err == nil {
+		t.Error("OptionsFromEnv() with a non-numeric duration should fail")
+	}
+}
+
+func TestGetPluginFromPath(t *testing.T) {
+	tests := map[string]string{
+		"/gmail/v1/users/me/messages":            "google",
+		"/repos/alice/app/pulls/1":               "github",
+		"/user":                                  "github",
+		"/2010-04-01/Accounts/AC1/Messages.json": "twilio",
+		"/v3/mail/send":                          "sendgrid",
+		"/v1/pages/abc":                          "notion",
+		"/services/data/v58.0/sobjects/Account":  "salesforce",
+		"/crm/v3/objects/contacts/51":            "hubspot",
+		"/rest/api/3/issue/PROJ-1":               "jira",
+		"/api/v2/tickets/3.json":                 "zendesk",
+		"/api/webhooks/1/token":                  "discord",
+		"/api/chat.postMessage":                  "slack",
+		"/api/states/light.kitchen":              "homeassistant",
+		"/healthz":                               "unknown",
+	}
+	for path, want := range tests {
+		if got := GetPluginFromPath(path); got != want {
+			t.Errorf("GetPluginFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// ABOUTME: Console loggers for server output, in plain text or structured JSON.
+// ABOUTME: ISH_LOG_FORMAT picks the format; JSON lines are one object each so jq can parse them.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FormatEnv names the environment variable that selects the log format
+const FormatEnv = "ISH_LOG_FORMAT"
+
+// Log levels
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Entry is one log line. Method is set for request logs; the other request
+// fields are only written when it is.
+type Entry struct {
+	Time       time.Time
+	Level      string
+	Msg        string
+	Method     string
+	Path       string
+	Status     int
+	DurationMs int64
+	Plugin     string
+	User       string
+}
+
+// Logger writes log entries to the console
+type Logger interface {
+	Log(e Entry)
+}
+
+// Text writes entries as human-readable lines, like the standard log package
+type Text struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewText returns a text logger writing to out
+func NewText(out io.Writer) *Text {
+	return &Text{out: out}
+}
+
+func (l *Text) Log(e Entry) {
+	line := e.Time.Format("2006/01/02 15:04:05") + " "
+	if e.Method != "" {
+		line += fmt.Sprintf("%s %s %d %dms plugin=%s user=%s", e.Method, e.Path, e.Status, e.DurationMs, e.Plugin, e.User)
+	} else {
+		line += e.Msg
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintln(l.out, line)
+}
+
+// JSON writes each entry as a single JSON object on its own line
+type JSON struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewJSON returns a JSON logger writing to out
+func NewJSON(out io.Writer) *JSON {
+	return &JSON{out: out}
+}
+
+// jsonMessage is the JSON form of a plain log message
+type jsonMessage struct {
+	Time  string `json:"time"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// jsonRequest is the JSON form of a request log
+type jsonRequest struct {
+	jsonMessage
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Status     int    `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Plugin     string `json:"plugin"`
+	User       string `json:"user"`
+}
+
+func (l *JSON) Log(e Entry) {
+	msg := jsonMessage{Time: e.Time.UTC().Format(time.RFC3339Nano), Level: e.Level, Msg: e.Msg}
+	var v any = msg
+	if e.Method != "" {
+		v = jsonRequest{
+			jsonMessage: msg,
+			Method:      e.Method,
+			Path:        e.Path,
+			Status:      e.Status,
+			DurationMs:  e.DurationMs,
+			Plugin:      e.Plugin,
+			User:        e.User,
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(append(data, '\n'))
+}
+
+// FromEnv returns the logger ISH_LOG_FORMAT selects ("text" or "json"),
+// defaulting to text
+func FromEnv(out io.Writer) (Logger, error) {
+	switch format := strings.ToLower(strings.TrimSpace(os.Getenv(FormatEnv))); format {
+	case "", "text":
+		return NewText(out), nil
+	case "json":
+		return NewJSON(out), nil
+	default:
+		return nil, fmt.Errorf("invalid %s %q: use text or json", FormatEnv, format)
+	}
+}
+
+// Writer adapts a logger for log.SetOutput, turning each standard log call
+// into one entry. Set log flags to 0 so the logger supplies the timestamp.
+func Writer(l Logger) io.Writer {
+	return stdWriter{l}
+}
+
+type stdWriter struct {
+	logger Logger
+}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	w.logger.Log(Entry{Time: time.Now(), Level: messageLevel(msg), Msg: msg})
+	return len(p), nil
+}
+
+// messageLevel guesses a level for a standard log message from how it starts
+func messageLevel(msg string) string {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.HasPrefix(lower, "warning"):
+		return LevelWarn
+	case strings.HasPrefix(lower, "error"), strings.HasPrefix(lower, "failed"), strings.HasPrefix(lower, "fatal"):
+		return LevelError
+	}
+	return LevelInfo
+}
+
+// statusLevel is the level a request log is written at
+func statusLevel(status int) string {
+	switch {
+	case status >= 500:
+		return LevelError
+	case status >= 400:
+		return LevelWarn
+	}
+	return LevelInfo
+}
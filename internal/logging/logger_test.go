@@ -0,0 +1,175 @@
+// ABOUTME: Tests for the text and JSON console loggers.
+// ABOUTME: Verifies every JSON line parses with the expected fields for several kinds of request.
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/auth"
+	"github.com/2389/ish/internal/store"
+)
+
+// jsonLines parses each line of out as a JSON object
+func jsonLines(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("log line is not valid JSON: %v\n%s", err, scanner.Text())
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestJSONLogger_RequestLines(t *testing.T) {
+	s, err := store.New(filepath.Join(t.TempDir(), "logs.db"))
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	var out bytes.Buffer
+	handler := auth.Middleware(Middleware(s, Options{Logger: NewJSON(&out)})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gmail/v1/users/me/messages/send":
+			w.WriteHeader(http.StatusOK)
+		case "/repos/alice/app/issues":
+			w.WriteHeader(http.StatusCreated)
+		case "/boom":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/healthz":
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})))
+
+	requests := []struct {
+		method, path, body string
+		status             int
+		level, plugin      string
+	}{
+		{"POST", "/gmail/v1/users/me/messages/send", `{"raw":"x"}`, 200, LevelInfo, "google"},
+		{"POST", "/repos/alice/app/issues", `{"title":"Bug \"quoted\""}`, 201, LevelInfo, "github"},
+		{"GET", "/missing?q=a%20b", "", 404, LevelWarn, "unknown"},
+		{"DELETE", "/boom", "", 500, LevelError, "unknown"},
+		{"GET", "/healthz", "", 200, LevelInfo, "unknown"},
+	}
+	for _, req := range requests {
+		r := httptest.NewRequest(req.method, req.path, strings.NewReader(req.body))
+		r.Header.Set("Authorization", "Bearer user:alice")
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	lines := jsonLines(t, out.String())
+	if len(lines) != len(requests) {
+		t.Fatalf("got %d log lines, want %d:\n%s", len(lines), len(requests), out.String())
+	}
+	for i, req := range requests {
+		line := lines[i]
+		for _, key := range []string{"time", "level", "msg", "method", "path", "status", "duration_ms", "plugin", "user"} {
+			if _, ok := line[key]; !ok {
+				t.Errorf("line %d missing %q: %v", i, key, line)
+			}
+		}
+		if line["method"] != req.method || line["path"] != strings.Split(req.path, "?")[0] {
+			t.Errorf("line %d = %v, want %s %s", i, line, req.method, req.path)
+		}
+		if line["status"] != float64(req.status) || line["level"] != req.level {
+			t.Errorf("line %d status/level = %v/%v, want %d/%s", i, line["status"], line["level"], req.status, req.level)
+		}
+		if line["plugin"] != req.plugin || line["user"] != "alice" {
+			t.Errorf("line %d plugin/user = %v/%v, want %s/alice", i, line["plugin"], line["user"], req.plugin)
+		}
+	}
+}
+
+func TestJSONLogger_StandardLogMessages(t *testing.T) {
+	var out bytes.Buffer
+	logger := log.New(Writer(NewJSON(&out)), "", 0)
+	logger.Printf("ISH server listening on %s", "http://localhost:9000")
+	logger.Printf("Warning: ISH_DB_PATH is invalid")
+	logger.Printf("Failed to initialize plugin %s: %v", "github", "boom\nsecond line")
+
+	lines := jsonLines(t, out.String())
+	if len(lines) != 3 {
+		t.Fatalf("got %d log lines, want 3:\n%s", len(lines), out.String())
+	}
+	wantLevels := []string{LevelInfo, LevelWarn, LevelError}
+	for i, line := range lines {
+		if line["level"] != wantLevels[i] {
+			t.Errorf("line %d level = %v, want %s", i, line["level"], wantLevels[i])
+		}
+		if _, ok := line["method"]; ok {
+			t.Errorf("line %d has request fields: %v", i, line)
+		}
+	}
+	if lines[0]["msg"] != "ISH server listening on http://localhost:9000" {
+		t.Errorf("msg = %v", lines[0]["msg"])
+	}
+	if lines[2]["msg"] != "Failed to initialize plugin github: boom\nsecond line" {
+		t.Errorf("multi-line msg = %q", lines[2]["msg"])
+	}
+}
+
+func TestTextLogger_RequestLine(t *testing.T) {
+	var out bytes.Buffer
+	handler := auth.Middleware(Middleware(&store.Store{}, Options{Logger: NewText(&out)})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})))
+	r := httptest.NewRequest("GET", "/healthz", nil)
+	r.Header.Set("Authorization", "Bearer user:bob")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	line := out.String()
+	if !strings.Contains(line, "GET /healthz 202 ") || !strings.Contains(line, "user=bob") {
+		t.Errorf("text line = %q", line)
+	}
+	if strings.HasPrefix(line, "{") {
+		t.Errorf("text line = %q, want plain text", line)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"", "text", false},
+		{"text", "text", false},
+		{"JSON", "json", false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			t.Setenv(FormatEnv, tt.format)
+			logger, err := FromEnv(&bytes.Buffer{})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("FromEnv() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromEnv() error = %v", err)
+			}
+			_, isJSON := logger.(*JSON)
+			if isJSON != (tt.want == "json") {
+				t.Errorf("FromEnv() = %T, want %s", logger, tt.want)
+			}
+		})
+	}
+}
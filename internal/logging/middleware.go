@@ -1,5 +1,5 @@
 // ABOUTME: HTTP request logging middleware.
-// ABOUTME: Captures method, path, status, duration, request/response bodies, and stores in database.
+// ABOUTME: Captures method, path, status, duration, request/response bodies, stores them in the database, and writes a console line.
 
 package logging
 
@@ -14,6 +14,7 @@ import (
 
 	"github.com/2389/ish/internal/auth"
 	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
 )
 
 const maxBodySize = 10 * 1024 // 10KB limit for body capture
@@ -49,6 +50,14 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Flush implements http.Flusher so streaming responses (such as the admin
+// log tail) reach the client as they are written
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Hijack implements http.Hijacker to support WebSocket upgrades
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	h, ok := rw.ResponseWriter.(http.Hijacker)
@@ -58,22 +67,34 @@ func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return h.Hijack()
 }
 
-// Middleware logs all HTTP requests to the database
-func Middleware(s *store.Store) func(http.Handler) http.Handler {
+// Middleware logs HTTP requests to the database and, when opts.Logger is
+// non-nil, to the console. Requests the options filter out are logged to
+// neither. It reads the user from the request context, so it must run after
+// auth.Middleware.
+func Middleware(s *store.Store, opts Options) func(http.Handler) http.Handler {
+	logger := opts.Logger
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Skip logging for health checks and admin UI assets
-			if r.URL.Path == "/healthz" || strings.HasPrefix(r.URL.Path, "/admin/") {
+			// Match routes as they are registered, without the base path
+			path := strings.TrimPrefix(r.URL.Path, core.BasePath(r))
+
+			// Determine plugin
+			pluginName := GetPluginFromPath(path)
+			if opts.ignores(path, pluginName) {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Determine plugin
-			pluginName := GetPluginFromPath(r.URL.Path)
+			// Skip database logging for health checks, the metrics API, and admin UI assets
+			skipStore := path == "/healthz" || path == "/api/metrics" || strings.HasPrefix(path, "/admin/")
+			if skipStore && logger == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
 
 			// Capture request body (if present)
 			var requestBody string
-			if r.Body != nil {
+			if r.Body != nil && !skipStore {
 				bodyBytes, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
 				if err == nil {
 					requestBody = string(bodyBytes)
@@ -92,19 +113,40 @@ func Middleware(s *store.Store) func(http.Handler) http.Handler {
 			// Call the next handler
 			next.ServeHTTP(wrapped, r)
 
-			duration := time.Since(start).Milliseconds()
+			elapsed := time.Since(start)
+			if elapsed < opts.MinDuration {
+				return
+			}
+			duration := elapsed.Milliseconds()
 
 			// Get user from context (if authenticated)
 			userID := auth.UserFromContext(r.Context())
 
+			if logger != nil {
+				logger.Log(Entry{
+					Time:       time.Now(),
+					Level:      statusLevel(wrapped.statusCode),
+					Msg:        "request",
+					Method:     r.Method,
+					Path:       r.URL.Path,
+					Status:     wrapped.statusCode,
+					DurationMs: duration,
+					Plugin:     pluginName,
+					User:       userID,
+				})
+			}
+			if skipStore {
+				return
+			}
+
 			// Get client IP
 			ip := r.RemoteAddr
 			if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
 				ip = strings.Split(forwarded, ",")[0]
 			}
 
-			// Log to database (fire and forget)
-			go s.LogRequest(&store.RequestLog{
+			// Log to database (fire and forget), then tell live subscribers
+			entry := &store.RequestLog{
 				Timestamp:    time.Now(),
 				PluginName:   pluginName,
 				Method:       r.Method,
@@ -116,7 +158,12 @@ func Middleware(s *store.Store) func(http.Handler) http.Handler {
 				UserAgent:    r.Header.Get("User-Agent"),
 				RequestBody:  requestBody,
 				ResponseBody: wrapped.body.String(),
-			})
+			}
+			go func() {
+				if err := s.LogRequest(entry); err == nil {
+					opts.Stream.Publish(entry)
+				}
+			}()
 		})
 	}
 }
@@ -149,7 +149,7 @@ func TestMiddleware_RequestBodySizeLimit(t *testing.T) {
 	s := &store.Store{}
 
 	// Create middleware
-	handler := Middleware(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := Middleware(s, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("response"))
 	}))
@@ -170,7 +170,7 @@ func TestMiddleware_RequestBodySizeLimit(t *testing.T) {
 func TestMiddleware_SkipsHealthcheckLogging(t *testing.T) {
 	s := &store.Store{}
 
-	handler := Middleware(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := Middleware(s, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -188,7 +188,7 @@ func TestMiddleware_SkipsHealthcheckLogging(t *testing.T) {
 func TestMiddleware_SkipsAdminAssets(t *testing.T) {
 	s := &store.Store{}
 
-	handler := Middleware(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := Middleware(s, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -209,7 +209,7 @@ func TestResponseWriter_RestoresRequestBody(t *testing.T) {
 	originalBody := "test request body"
 	var handlerReadBody string
 
-	handler := Middleware(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := Middleware(s, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, _ := io.ReadAll(r.Body)
 		handlerReadBody = string(body)
 		w.WriteHeader(http.StatusOK)
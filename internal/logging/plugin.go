@@ -5,7 +5,8 @@ package logging
 
 import "strings"
 
-// GetPluginFromPath determines which plugin handles a given path
+// GetPluginFromPath determines which plugin handles a given path, which must
+// not include the base path
 func GetPluginFromPath(path string) string {
 	// Google APIs
 	if strings.HasPrefix(path, "/gmail/") {
@@ -24,6 +25,50 @@ func GetPluginFromPath(path string) string {
 		return "google"
 	}
 
+	// GitHub APIs
+	if strings.HasPrefix(path, "/repos/") || strings.HasPrefix(path, "/users/") || strings.HasPrefix(path, "/archives/") {
+		return "github"
+	}
+	if path == "/user" || strings.HasPrefix(path, "/user/") || path == "/graphql" {
+		return "github"
+	}
+
+	// Other plugins with distinct prefixes
+	if strings.HasPrefix(path, "/2010-04-01/") || strings.HasPrefix(path, "/twiml/") {
+		return "twilio"
+	}
+	if strings.HasPrefix(path, "/v3/") {
+		return "sendgrid"
+	}
+	if strings.HasPrefix(path, "/v1/pages") || strings.HasPrefix(path, "/v1/blocks") || strings.HasPrefix(path, "/v1/databases") {
+		return "notion"
+	}
+	if strings.HasPrefix(path, "/services/") {
+		return "salesforce"
+	}
+	if strings.HasPrefix(path, "/crm/") {
+		return "hubspot"
+	}
+	if strings.HasPrefix(path, "/rest/api/") {
+		return "jira"
+	}
+	if strings.HasPrefix(path, "/api/v2/") {
+		return "zendesk"
+	}
+	if strings.HasPrefix(path, "/api/webhooks/") {
+		return "discord"
+	}
+	// Slack methods are dotted (/api/chat.postMessage); Home Assistant's aren't
+	if method, ok := strings.CutPrefix(path, "/api/"); ok && method != "" {
+		if strings.Contains(method, ".") && !strings.Contains(method, "/") {
+			return "slack"
+		}
+		return "homeassistant"
+	}
+	if strings.HasPrefix(path, "/oauth/") {
+		return "oauth"
+	}
+
 	// Unknown
 	return "unknown"
 }
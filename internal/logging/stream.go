@@ -0,0 +1,83 @@
+// ABOUTME: In-process pub/sub of request logs for live tailing.
+// ABOUTME: The middleware publishes each stored log; slow subscribers miss entries instead of blocking requests.
+
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/2389/ish/internal/store"
+)
+
+// streamBuffer is how many logs a subscriber can fall behind before it
+// starts missing them
+const streamBuffer = 64
+
+// Stream fans request logs out to live subscribers, such as the admin log tail
+type Stream struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription receives published logs on C, optionally only for one plugin
+type Subscription struct {
+	C <-chan *store.RequestLog
+
+	c       chan *store.RequestLog
+	plugin  string
+	dropped atomic.Int64
+}
+
+// NewStream returns a stream with no subscribers
+func NewStream() *Stream {
+	return &Stream{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe starts receiving logs; plugin, when set, limits them to that
+// plugin's requests. Call Unsubscribe when done.
+func (s *Stream) Subscribe(plugin string) *Subscription {
+	c := make(chan *store.RequestLog, streamBuffer)
+	sub := &Subscription{C: c, c: c, plugin: plugin}
+
+	s.mu.Lock()
+	s.subs[sub] = struct{}{}
+	s.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe stops a subscription and closes its channel
+func (s *Stream) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[sub]; ok {
+		delete(s.subs, sub)
+		close(sub.c)
+	}
+}
+
+// Publish sends a log to every matching subscriber without blocking; a
+// subscriber whose buffer is full drops it. Publishing to a nil stream does
+// nothing.
+func (s *Stream) Publish(entry *store.RequestLog) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		if sub.plugin != "" && sub.plugin != entry.PluginName {
+			continue
+		}
+		select {
+		case sub.c <- entry:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped is how many logs this subscriber missed because it fell behind
+func (sub *Subscription) Dropped() int64 {
+	return sub.dropped.Load()
+}
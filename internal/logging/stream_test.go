@@ -0,0 +1,59 @@
+// ABOUTME: Tests for the request log stream.
+// ABOUTME: Covers plugin filtering, dropping logs for slow subscribers, and unsubscribing.
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/2389/ish/internal/store"
+)
+
+func TestStream_FiltersByPlugin(t *testing.T) {
+	stream := NewStream()
+	all := stream.Subscribe("")
+	github := stream.Subscribe("github")
+	defer stream.Unsubscribe(all)
+	defer stream.Unsubscribe(github)
+
+	stream.Publish(&store.RequestLog{ID: 1, PluginName: "google"})
+	stream.Publish(&store.RequestLog{ID: 2, PluginName: "github"})
+
+	if got := (<-all.C).ID; got != 1 {
+		t.Errorf("first log for all plugins = %d, want 1", got)
+	}
+	if got := (<-all.C).ID; got != 2 {
+		t.Errorf("second log for all plugins = %d, want 2", got)
+	}
+	if got := (<-github.C).ID; got != 2 {
+		t.Errorf("github subscriber got log %d, want 2", got)
+	}
+	if len(github.C) != 0 {
+		t.Errorf("github subscriber has %d extra logs", len(github.C))
+	}
+}
+
+func TestStream_SlowSubscriberDropsLogs(t *testing.T) {
+	stream := NewStream()
+	sub := stream.Subscribe("")
+
+	// Nobody reads, so publishing past the buffer must drop rather than block
+	for i := 0; i < streamBuffer+10; i++ {
+		stream.Publish(&store.RequestLog{ID: int64(i + 1)})
+	}
+	if sub.Dropped() != 10 {
+		t.Errorf("Dropped() = %d, want 10", sub.Dropped())
+	}
+	if got := (<-sub.C).ID; got != 1 {
+		t.Errorf("oldest buffered log = %d, want 1", got)
+	}
+
+	stream.Unsubscribe(sub)
+	for range sub.C {
+	}
+	stream.Unsubscribe(sub) // unsubscribing twice is harmless
+	stream.Publish(&store.RequestLog{ID: 99})
+
+	var nilStream *Stream
+	nilStream.Publish(&store.RequestLog{ID: 100})
+}
@@ -0,0 +1,36 @@
+// ABOUTME: Migration 1 creates the core request_logs table and its indexes.
+// ABOUTME: Released with the first migration runner, so its schema never changes.
+
+package migrations
+
+import "database/sql"
+
+func migration001(tx *sql.Tx) error {
+	_, err := tx.Exec(requestLogsTables)
+	return err
+}
+
+// requestLogsTables creates request_logs with the single-column indexes used
+// for ordering and filtering logs
+const requestLogsTables = `
+	CREATE TABLE IF NOT EXISTS request_logs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		plugin_name TEXT DEFAULT '',
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		status_code INTEGER,
+		duration_ms INTEGER,
+		user_id TEXT,
+		ip_address TEXT,
+		user_agent TEXT,
+		request_body TEXT,
+		response_body TEXT,
+		error TEXT
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_request_logs_timestamp ON request_logs(timestamp DESC);
+	CREATE INDEX IF NOT EXISTS idx_request_logs_path ON request_logs(path);
+	CREATE INDEX IF NOT EXISTS idx_request_logs_status ON request_logs(status_code);
+	CREATE INDEX IF NOT EXISTS idx_request_logs_plugin ON request_logs(plugin_name);
+	`
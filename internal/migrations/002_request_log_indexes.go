@@ -0,0 +1,40 @@
+// ABOUTME: Migration 2 adds composite request_logs indexes.
+// ABOUTME: Backs the aggregation and multi-column filtering queries used by the admin dashboard.
+
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func migration002(tx *sql.Tx) error {
+	indexes := []string{
+		// Composite index for GetTopEndpoints query (GROUP BY path, ORDER BY COUNT)
+		// Optimizes aggregation queries by allowing SQLite to use index to count occurrences per path
+		"CREATE INDEX IF NOT EXISTS idx_request_logs_path_count ON request_logs(path, status_code)",
+
+		// Composite index for GetPluginRequestCount and GetPluginErrorRate queries
+		// Optimizes filtering by plugin_name AND timestamp range queries used in metrics
+		"CREATE INDEX IF NOT EXISTS idx_request_logs_plugin_timestamp ON request_logs(plugin_name, timestamp DESC)",
+
+		// Composite index for GetRequestLogs multi-column filtering
+		// Optimizes queries filtering by plugin_name AND method AND status_code together
+		"CREATE INDEX IF NOT EXISTS idx_request_logs_plugin_method_status ON request_logs(plugin_name, method, status_code)",
+
+		// Index for user_id filtering and uniqueness checks in GetRequestLogStats
+		// WHERE clause filters to non-empty user IDs to keep index smaller
+		"CREATE INDEX IF NOT EXISTS idx_request_logs_user_id ON request_logs(user_id) WHERE user_id != ''",
+
+		// Index for date-based filtering in GetRequestLogStats
+		// Supports timestamp-based queries with status code filtering
+		"CREATE INDEX IF NOT EXISTS idx_request_logs_timestamp_status ON request_logs(timestamp DESC, status_code)",
+	}
+
+	for _, indexSQL := range indexes {
+		if _, err := tx.Exec(indexSQL); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// ABOUTME: Migration 3 creates the Discord plugin's tables and indexes.
+// ABOUTME: Webhooks, the messages they post, and guild audit log entries.
+
+package migrations
+
+import "database/sql"
+
+func migration003(tx *sql.Tx) error {
+	// Databases from before versioned plugin migrations already have
+	// these tables, possibly without newer columns; later migrations add those
+	_, err := tx.Exec(discordTables)
+	return err
+}
+
+// discordTables creates the Discord plugin's tables
+const discordTables = `
+	CREATE TABLE IF NOT EXISTS discord_webhooks (
+		id TEXT PRIMARY KEY,
+		token TEXT NOT NULL,
+		type INTEGER DEFAULT 1,
+		name TEXT,
+		avatar TEXT,
+		channel_id TEXT,
+		guild_id TEXT,
+		application_id TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		deleted_at TIMESTAMP,
+		UNIQUE(id, token)
+	);
+
+	CREATE TABLE IF NOT EXISTS discord_webhook_messages (
+		id TEXT PRIMARY KEY,
+		webhook_id TEXT NOT NULL,
+		content TEXT,
+		username TEXT,
+		avatar_url TEXT,
+		embeds TEXT,
+		components TEXT,
+		attachments TEXT,
+		thread_id TEXT,
+		flags INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		edited_at TIMESTAMP,
+		deleted_at TIMESTAMP,
+		FOREIGN KEY (webhook_id) REFERENCES discord_webhooks(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_messages_webhook_id ON discord_webhook_messages(webhook_id);
+	CREATE INDEX IF NOT EXISTS idx_webhook_messages_created_at ON discord_webhook_messages(created_at DESC);
+
+	-- Guild audit log; ids are time-ordered snowflakes so before/after paginate
+	CREATE TABLE IF NOT EXISTS discord_audit_logs (
+		id BIGINT PRIMARY KEY,
+		guild_id TEXT NOT NULL,
+		user_id TEXT,
+		target_id TEXT,
+		action_type INTEGER NOT NULL,
+		changes TEXT,
+		options TEXT,
+		reason TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_logs_guild_id ON discord_audit_logs(guild_id, id);
+`
@@ -0,0 +1,446 @@
+// ABOUTME: Migration 4 creates the GitHub plugin's tables and indexes.
+// ABOUTME: Users and tokens, repositories and their contents, issues and pull requests, webhooks, Actions, and app installations.
+
+package migrations
+
+import "database/sql"
+
+func migration004(tx *sql.Tx) error {
+	// Databases from before versioned plugin migrations already have
+	// these tables, possibly without newer columns; later migrations add those
+	_, err := tx.Exec(githubTables)
+	return err
+}
+
+// githubTables creates the GitHub plugin's tables
+const githubTables = `
+	CREATE TABLE IF NOT EXISTS github_users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		login TEXT UNIQUE NOT NULL,
+		name TEXT,
+		email TEXT,
+		avatar_url TEXT,
+		type TEXT DEFAULT 'User',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_users_login ON github_users(login);
+
+	CREATE TABLE IF NOT EXISTS github_tokens (
+		token TEXT PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		token_type TEXT DEFAULT 'personal',
+		scopes TEXT,
+		installation_id INTEGER,
+		expires_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES github_users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_tokens_user ON github_tokens(user_id);
+
+	CREATE TABLE IF NOT EXISTS github_repositories (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		owner_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		full_name TEXT NOT NULL,
+		description TEXT,
+		private INTEGER DEFAULT 0,
+		default_branch TEXT DEFAULT 'main',
+		fork INTEGER DEFAULT 0,
+		archived INTEGER DEFAULT 0,
+		disabled INTEGER DEFAULT 0,
+		stargazers_count INTEGER DEFAULT 0,
+		watchers_count INTEGER DEFAULT 0,
+		forks_count INTEGER DEFAULT 0,
+		open_issues_count INTEGER DEFAULT 0,
+		language TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		pushed_at TIMESTAMP,
+		FOREIGN KEY (owner_id) REFERENCES github_users(id),
+		UNIQUE(owner_id, name)
+	);
+	CREATE INDEX IF NOT EXISTS idx_repos_owner ON github_repositories(owner_id);
+	CREATE INDEX IF NOT EXISTS idx_repos_full_name ON github_repositories(full_name);
+
+	CREATE TABLE IF NOT EXISTS github_branches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		commit_sha TEXT NOT NULL,
+		protected INTEGER DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+		UNIQUE(repo_id, name)
+	);
+	CREATE INDEX IF NOT EXISTS idx_branches_repo ON github_branches(repo_id);
+
+	CREATE TABLE IF NOT EXISTS github_repository_forks (
+		repo_id INTEGER PRIMARY KEY,
+		parent_id INTEGER NOT NULL,
+		source_id INTEGER NOT NULL,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+		FOREIGN KEY (parent_id) REFERENCES github_repositories(id),
+		FOREIGN KEY (source_id) REFERENCES github_repositories(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_forks_parent ON github_repository_forks(parent_id);
+
+	CREATE TABLE IF NOT EXISTS github_commits (
+		sha TEXT PRIMARY KEY,
+		repo_id INTEGER NOT NULL,
+		author_login TEXT,
+		author_name TEXT NOT NULL,
+		author_email TEXT NOT NULL,
+		committer_login TEXT,
+		message TEXT NOT NULL,
+		parent_sha TEXT,
+		tree_sha TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_commits_repo ON github_commits(repo_id);
+	CREATE INDEX IF NOT EXISTS idx_commits_parent ON github_commits(parent_sha);
+
+	CREATE TABLE IF NOT EXISTS github_issues (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		number INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT,
+		state TEXT DEFAULT 'open',
+		state_reason TEXT,
+		user_id INTEGER NOT NULL,
+		assignee_ids TEXT,
+		label_ids TEXT,
+		milestone_id INTEGER,
+		locked INTEGER DEFAULT 0,
+		lock_reason TEXT,
+		comments_count INTEGER DEFAULT 0,
+		is_pull_request INTEGER DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		closed_at TIMESTAMP,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES github_users(id),
+		UNIQUE(repo_id, number)
+	);
+	CREATE INDEX IF NOT EXISTS idx_issues_repo ON github_issues(repo_id);
+	CREATE INDEX IF NOT EXISTS idx_issues_state ON github_issues(state);
+	CREATE INDEX IF NOT EXISTS idx_issues_number ON github_issues(repo_id, number);
+
+	CREATE TABLE IF NOT EXISTS github_pull_requests (
+		issue_id INTEGER PRIMARY KEY,
+		head_repo_id INTEGER NOT NULL,
+		head_ref TEXT NOT NULL,
+		base_repo_id INTEGER NOT NULL,
+		base_ref TEXT NOT NULL,
+		merged INTEGER DEFAULT 0,
+		mergeable INTEGER DEFAULT 1,
+		rebaseable INTEGER DEFAULT 1,
+		merge_commit_sha TEXT,
+		merged_at TIMESTAMP,
+		merged_by_id INTEGER,
+		base_sha TEXT,
+		draft INTEGER DEFAULT 0,
+		review_comments_count INTEGER DEFAULT 0,
+		commits_count INTEGER DEFAULT 1,
+		additions INTEGER DEFAULT 0,
+		deletions INTEGER DEFAULT 0,
+		changed_files INTEGER DEFAULT 0,
+		FOREIGN KEY (issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+		FOREIGN KEY (head_repo_id) REFERENCES github_repositories(id),
+		FOREIGN KEY (base_repo_id) REFERENCES github_repositories(id),
+		FOREIGN KEY (merged_by_id) REFERENCES github_users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_prs_head_repo ON github_pull_requests(head_repo_id);
+	CREATE INDEX IF NOT EXISTS idx_prs_base_repo ON github_pull_requests(base_repo_id);
+
+	CREATE TABLE IF NOT EXISTS github_comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		issue_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES github_users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_comments_issue ON github_comments(issue_id);
+	CREATE INDEX IF NOT EXISTS idx_comments_created ON github_comments(created_at);
+
+	CREATE TABLE IF NOT EXISTS github_issue_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		issue_id INTEGER NOT NULL,
+		actor_id INTEGER NOT NULL,
+		event TEXT NOT NULL,
+		lock_reason TEXT,
+		commit_id TEXT,
+		source_issue_id INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+		FOREIGN KEY (actor_id) REFERENCES github_users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_issue_events_issue ON github_issue_events(issue_id);
+
+	CREATE TABLE IF NOT EXISTS github_issue_references (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_issue_id INTEGER NOT NULL,
+		target_issue_id INTEGER NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (source_issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+		FOREIGN KEY (target_issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+		UNIQUE(source_issue_id, target_issue_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_issue_references_target ON github_issue_references(target_issue_id);
+
+	CREATE TABLE IF NOT EXISTS github_reviews (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		pull_request_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		state TEXT NOT NULL,
+		body TEXT,
+		commit_sha TEXT,
+		submitted_at TIMESTAMP,
+		dismissed_at TIMESTAMP,
+		FOREIGN KEY (pull_request_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES github_users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_reviews_pr ON github_reviews(pull_request_id);
+	CREATE INDEX IF NOT EXISTS idx_reviews_state ON github_reviews(state);
+
+	CREATE TABLE IF NOT EXISTS github_review_comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		pull_request_id INTEGER NOT NULL,
+		review_id INTEGER,
+		user_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		path TEXT NOT NULL,
+		position INTEGER,
+		commit_sha TEXT NOT NULL,
+		in_reply_to_id INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (pull_request_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+		FOREIGN KEY (review_id) REFERENCES github_reviews(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES github_users(id),
+		FOREIGN KEY (in_reply_to_id) REFERENCES github_review_comments(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_review_comments_pr ON github_review_comments(pull_request_id);
+	CREATE INDEX IF NOT EXISTS idx_review_comments_review ON github_review_comments(review_id);
+
+	CREATE TABLE IF NOT EXISTS github_webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		content_type TEXT DEFAULT 'json',
+		secret TEXT,
+		events TEXT NOT NULL,
+		active INTEGER DEFAULT 1,
+		delay_ms INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhooks_repo ON github_webhooks(repo_id);
+	CREATE INDEX IF NOT EXISTS idx_webhooks_active ON github_webhooks(active);
+
+	CREATE TABLE IF NOT EXISTS github_webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		delivered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		status_code INTEGER,
+		error_message TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_retry_at TIMESTAMP,
+		redelivery BOOLEAN NOT NULL DEFAULT 0,
+		FOREIGN KEY (webhook_id) REFERENCES github_webhooks(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_deliveries_webhook ON github_webhook_deliveries(webhook_id);
+	CREATE INDEX IF NOT EXISTS idx_deliveries_delivered ON github_webhook_deliveries(delivered_at DESC);
+
+	CREATE TABLE IF NOT EXISTS github_reactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		subject_type TEXT NOT NULL,
+		subject_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(subject_type, subject_id, user_id, content),
+		FOREIGN KEY (user_id) REFERENCES github_users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_reactions_subject ON github_reactions(subject_type, subject_id);
+
+	CREATE TABLE IF NOT EXISTS github_commit_statuses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		sha TEXT NOT NULL,
+		state TEXT NOT NULL,
+		context TEXT NOT NULL DEFAULT 'default',
+		description TEXT,
+		target_url TEXT,
+		creator_id INTEGER NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+		FOREIGN KEY (creator_id) REFERENCES github_users(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_commit_statuses_sha ON github_commit_statuses(repo_id, sha);
+
+	CREATE TABLE IF NOT EXISTS github_check_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		head_sha TEXT NOT NULL,
+		name TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'queued',
+		conclusion TEXT,
+		details_url TEXT,
+		external_id TEXT,
+		output_title TEXT,
+		output_summary TEXT,
+		output_text TEXT,
+		started_at TIMESTAMP,
+		completed_at TIMESTAMP,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_check_runs_sha ON github_check_runs(repo_id, head_sha);
+
+	CREATE TABLE IF NOT EXISTS github_secret_scanning_alerts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		number INTEGER NOT NULL,
+		state TEXT NOT NULL DEFAULT 'open',
+		secret_type TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		resolution TEXT,
+		resolved_by_id INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		resolved_at TIMESTAMP,
+		UNIQUE (repo_id, number),
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+		FOREIGN KEY (resolved_by_id) REFERENCES github_users(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS github_workflows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		path TEXT NOT NULL,
+		state TEXT NOT NULL DEFAULT 'active',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (repo_id, path),
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS github_workflow_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		workflow_id INTEGER NOT NULL,
+		run_number INTEGER NOT NULL,
+		event TEXT NOT NULL,
+		head_branch TEXT NOT NULL,
+		head_sha TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'queued',
+		conclusion TEXT,
+		actor_id INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		started_at TIMESTAMP,
+		completed_at TIMESTAMP,
+		inputs TEXT,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+		FOREIGN KEY (workflow_id) REFERENCES github_workflows(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_workflow_runs_repo ON github_workflow_runs(repo_id, id);
+
+	CREATE TABLE IF NOT EXISTS github_repository_dispatch_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		client_payload TEXT NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS github_branch_protection (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_id INTEGER NOT NULL,
+		branch TEXT NOT NULL,
+		require_reviews INTEGER NOT NULL DEFAULT 0,
+		required_approving_review_count INTEGER NOT NULL DEFAULT 0,
+		dismiss_stale_reviews INTEGER NOT NULL DEFAULT 0,
+		require_code_owner_reviews INTEGER NOT NULL DEFAULT 0,
+		require_status_checks INTEGER NOT NULL DEFAULT 0,
+		strict_status_checks INTEGER NOT NULL DEFAULT 0,
+		status_check_contexts TEXT NOT NULL DEFAULT '[]',
+		enforce_admins INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+		UNIQUE(repo_id, branch)
+	);
+
+	CREATE TABLE IF NOT EXISTS github_repo_templates (
+		repo_id INTEGER NOT NULL,
+		template_type TEXT NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		content TEXT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (repo_id, template_type, name),
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS github_repo_files (
+		repo_id INTEGER NOT NULL,
+		path TEXT NOT NULL,
+		content TEXT NOT NULL DEFAULT '',
+		deleted INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (repo_id, path),
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS github_repo_collaborators (
+		repo_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		permission TEXT NOT NULL DEFAULT 'push',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (repo_id, user_id),
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES github_users(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS github_repo_topics (
+		repo_id INTEGER NOT NULL,
+		topic TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY (repo_id, topic),
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS github_computed_stats (
+		repo_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		data TEXT NOT NULL,
+		computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (repo_id, kind),
+		FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS github_app_installations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		permissions TEXT NOT NULL DEFAULT '{}',
+		repository_selection TEXT NOT NULL DEFAULT 'all',
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (account_id) REFERENCES github_users(id)
+	);
+
+	-- Columns added later reach older databases through internal/migrations
+`
@@ -0,0 +1,224 @@
+// ABOUTME: Migration 5 creates the Google plugin's tables and indexes.
+// ABOUTME: Gmail mailboxes, calendars and events, contacts and directory people, and task lists.
+
+package migrations
+
+import "database/sql"
+
+func migration005(tx *sql.Tx) error {
+	// Databases from before versioned plugin migrations already have
+	// these tables, possibly without newer columns; later migrations add those
+	_, err := tx.Exec(googleTables)
+	return err
+}
+
+// googleTables creates the Google plugin's tables
+const googleTables = `
+	-- Gmail tables
+	CREATE TABLE IF NOT EXISTS gmail_messages (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		thread_id TEXT,
+		label_ids TEXT,
+		snippet TEXT,
+		internal_date INTEGER,
+		payload TEXT,
+		history_id INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_gmail_messages_user_id ON gmail_messages(user_id);
+	CREATE INDEX IF NOT EXISTS idx_gmail_messages_thread_id ON gmail_messages(thread_id);
+	CREATE INDEX IF NOT EXISTS idx_gmail_messages_internal_date ON gmail_messages(internal_date);
+	CREATE INDEX IF NOT EXISTS idx_gmail_messages_label_ids ON gmail_messages(label_ids);
+
+	CREATE TABLE IF NOT EXISTS gmail_threads (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		snippet TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_gmail_threads_user_id ON gmail_threads(user_id);
+
+	CREATE TABLE IF NOT EXISTS gmail_attachments (
+		id TEXT PRIMARY KEY,
+		message_id TEXT NOT NULL,
+		filename TEXT,
+		mime_type TEXT,
+		size INTEGER,
+		data TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_gmail_attachments_message_id ON gmail_attachments(message_id);
+
+	-- One row per mailbox change; the row ID is the Gmail historyId
+	CREATE TABLE IF NOT EXISTS gmail_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		thread_id TEXT,
+		label_ids TEXT,
+		changed_label_ids TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_gmail_history_user_id ON gmail_history(user_id, id);
+
+	-- User-created labels; system labels are built in and not stored
+	CREATE TABLE IF NOT EXISTS gmail_labels (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		label_list_visibility TEXT,
+		message_list_visibility TEXT,
+		text_color TEXT,
+		background_color TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user_id, name)
+	);
+
+	-- One vacation responder per mailbox; times are epoch milliseconds, 0 when unset
+	CREATE TABLE IF NOT EXISTS gmail_vacation_settings (
+		user_id TEXT PRIMARY KEY,
+		enable_auto_reply INTEGER NOT NULL DEFAULT 0,
+		response_subject TEXT NOT NULL DEFAULT '',
+		response_body_plain TEXT NOT NULL DEFAULT '',
+		response_body_html TEXT NOT NULL DEFAULT '',
+		restrict_to_contacts INTEGER NOT NULL DEFAULT 0,
+		restrict_to_domain INTEGER NOT NULL DEFAULT 0,
+		start_time INTEGER NOT NULL DEFAULT 0,
+		end_time INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Senders the current vacation responder has already answered, so each gets one reply
+	CREATE TABLE IF NOT EXISTS gmail_vacation_replies (
+		user_id TEXT NOT NULL,
+		sender TEXT NOT NULL,
+		replied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, sender)
+	);
+
+	-- Calendar tables
+	CREATE TABLE IF NOT EXISTS calendars (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		summary TEXT,
+		sync_token TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_calendars_user_id ON calendars(user_id);
+
+	CREATE TABLE IF NOT EXISTS calendar_events (
+		id TEXT PRIMARY KEY,
+		calendar_id TEXT NOT NULL,
+		summary TEXT,
+		description TEXT,
+		start_time TEXT,
+		end_time TEXT,
+		attendees TEXT,
+		location TEXT,
+		organizer_email TEXT,
+		organizer_name TEXT,
+		recurrence TEXT,
+		color_id TEXT,
+		updated_at TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_calendar_events_calendar_id ON calendar_events(calendar_id);
+	CREATE INDEX IF NOT EXISTS idx_calendar_events_start_time ON calendar_events(start_time);
+
+	CREATE TABLE IF NOT EXISTS calendar_channels (
+		id TEXT PRIMARY KEY,
+		resource_id TEXT NOT NULL,
+		calendar_id TEXT NOT NULL,
+		address TEXT NOT NULL,
+		token TEXT,
+		expiration INTEGER NOT NULL,
+		message_number INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_calendar_channels_calendar_id ON calendar_channels(calendar_id);
+
+	-- People tables
+	CREATE TABLE IF NOT EXISTS people (
+		resource_name TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		data TEXT,
+		updated_at TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_people_user_id ON people(user_id);
+
+	CREATE TABLE IF NOT EXISTS people_photos (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		resource_name TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		data BLOB NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user_id, resource_name)
+	);
+
+	CREATE TABLE IF NOT EXISTS people_contact_groups (
+		user_id TEXT NOT NULL,
+		resource_name TEXT NOT NULL,
+		name TEXT NOT NULL,
+		group_type TEXT NOT NULL,
+		updated_at TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, resource_name)
+	);
+
+	CREATE TABLE IF NOT EXISTS people_contact_group_members (
+		user_id TEXT NOT NULL,
+		group_resource_name TEXT NOT NULL,
+		person_resource_name TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, group_resource_name, person_resource_name)
+	);
+
+	CREATE TABLE IF NOT EXISTS directory_people (
+		resource_name TEXT PRIMARY KEY,
+		source_type TEXT NOT NULL,
+		data TEXT,
+		change_seq INTEGER NOT NULL,
+		updated_at TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_directory_people_change_seq ON directory_people(change_seq);
+
+	CREATE TABLE IF NOT EXISTS sync_tokens (
+		id TEXT PRIMARY KEY,
+		resource_type TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		token TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_sync_tokens_resource_user ON sync_tokens(resource_type, user_id);
+
+	-- Tasks tables
+	CREATE TABLE IF NOT EXISTS task_lists (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		title TEXT,
+		updated_at TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_task_lists_user_id ON task_lists(user_id);
+
+	CREATE TABLE IF NOT EXISTS tasks (
+		id TEXT PRIMARY KEY,
+		list_id TEXT NOT NULL,
+		title TEXT,
+		notes TEXT,
+		due TEXT,
+		status TEXT DEFAULT 'needsAction',
+		completed TEXT,
+		updated_at TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_tasks_list_id ON tasks(list_id);
+	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+
+	-- Columns added later reach older databases through internal/migrations
+`
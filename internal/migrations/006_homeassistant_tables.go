@@ -0,0 +1,115 @@
+// ABOUTME: Migration 6 creates the Home Assistant plugin's tables and indexes.
+// ABOUTME: Instances, entities and their state history, service calls, events, and the area and device registries.
+
+package migrations
+
+import "database/sql"
+
+func migration006(tx *sql.Tx) error {
+	// Databases from before versioned plugin migrations already have
+	// these tables, possibly without newer columns; later migrations add those
+	_, err := tx.Exec(homeassistantTables)
+	return err
+}
+
+// homeassistantTables creates the Home Assistant plugin's tables
+const homeassistantTables = `
+	CREATE TABLE IF NOT EXISTS homeassistant_instances (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		url TEXT NOT NULL,
+		token TEXT NOT NULL,
+		name TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS homeassistant_entities (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		entity_id TEXT NOT NULL,
+		friendly_name TEXT,
+		domain TEXT NOT NULL,
+		platform TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id),
+		UNIQUE(instance_id, entity_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS homeassistant_states (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		entity_id TEXT NOT NULL,
+		state TEXT NOT NULL,
+		attributes TEXT, -- JSON
+		last_changed DATETIME NOT NULL,
+		last_updated DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS homeassistant_service_calls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		domain TEXT NOT NULL,
+		service TEXT NOT NULL,
+		service_data TEXT, -- JSON
+		entity_id TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		called_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS homeassistant_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		event_data TEXT, -- JSON
+		origin TEXT NOT NULL DEFAULT 'LOCAL',
+		time_fired DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS homeassistant_areas (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		area_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		aliases TEXT NOT NULL DEFAULT '[]', -- JSON
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id),
+		UNIQUE(instance_id, area_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS homeassistant_devices (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		device_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		area_id TEXT,
+		manufacturer TEXT,
+		model TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id),
+		UNIQUE(instance_id, device_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS homeassistant_entity_registry (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		entity_id TEXT NOT NULL,
+		device_id TEXT,
+		area_id TEXT, -- overrides the device's area when set
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id),
+		UNIQUE(instance_id, entity_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_entities_instance ON homeassistant_entities(instance_id);
+	CREATE INDEX IF NOT EXISTS idx_states_instance ON homeassistant_states(instance_id);
+	CREATE INDEX IF NOT EXISTS idx_states_entity ON homeassistant_states(entity_id);
+	CREATE INDEX IF NOT EXISTS idx_service_calls_instance ON homeassistant_service_calls(instance_id);
+	CREATE INDEX IF NOT EXISTS idx_events_instance_type ON homeassistant_events(instance_id, event_type);
+`
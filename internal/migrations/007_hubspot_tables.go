@@ -0,0 +1,46 @@
+// ABOUTME: Migration 7 creates the HubSpot plugin's tables and indexes.
+// ABOUTME: One table per CRM object type, plus the associations between records.
+
+package migrations
+
+import "database/sql"
+
+func migration007(tx *sql.Tx) error {
+	_, err := tx.Exec(hubspotTables)
+	return err
+}
+
+// hubspotTables creates the HubSpot plugin's tables
+const hubspotTables = `
+	CREATE TABLE IF NOT EXISTS hubspot_contacts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		properties TEXT NOT NULL DEFAULT '{}',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS hubspot_companies (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		properties TEXT NOT NULL DEFAULT '{}',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS hubspot_deals (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		properties TEXT NOT NULL DEFAULT '{}',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS hubspot_associations (
+		from_type TEXT NOT NULL,
+		from_id INTEGER NOT NULL,
+		to_type TEXT NOT NULL,
+		to_id INTEGER NOT NULL,
+		association_type TEXT NOT NULL,
+		PRIMARY KEY (from_type, from_id, to_type, to_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_hubspot_associations_to ON hubspot_associations(to_type, to_id);
+`
@@ -0,0 +1,53 @@
+// ABOUTME: Migration 8 creates the Jira plugin's tables and indexes.
+// ABOUTME: Projects, issues, and issue comments.
+
+package migrations
+
+import "database/sql"
+
+func migration008(tx *sql.Tx) error {
+	_, err := tx.Exec(jiraTables)
+	return err
+}
+
+// jiraTables creates the Jira plugin's tables
+const jiraTables = `
+	CREATE TABLE IF NOT EXISTS jira_projects (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		key TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		lead TEXT,
+		project_type TEXT DEFAULT 'software',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS jira_issues (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		key TEXT NOT NULL UNIQUE,
+		project_key TEXT NOT NULL,
+		number INTEGER NOT NULL,
+		summary TEXT NOT NULL,
+		description TEXT,
+		status TEXT NOT NULL DEFAULT 'To Do',
+		issue_type TEXT NOT NULL DEFAULT 'Task',
+		priority TEXT NOT NULL DEFAULT 'Medium',
+		assignee TEXT,
+		reporter TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (project_key) REFERENCES jira_projects(key)
+	);
+
+	CREATE TABLE IF NOT EXISTS jira_comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		issue_id INTEGER NOT NULL,
+		author TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (issue_id) REFERENCES jira_issues(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_jira_issues_project ON jira_issues(project_key);
+	CREATE INDEX IF NOT EXISTS idx_jira_issues_status ON jira_issues(status);
+	CREATE INDEX IF NOT EXISTS idx_jira_comments_issue ON jira_comments(issue_id);
+`
@@ -0,0 +1,65 @@
+// ABOUTME: Migration 9 creates the Linear plugin's tables and indexes.
+// ABOUTME: Teams, users, workflow states, and issues.
+
+package migrations
+
+import "database/sql"
+
+func migration009(tx *sql.Tx) error {
+	_, err := tx.Exec(linearTables)
+	return err
+}
+
+// linearTables creates the Linear plugin's tables
+const linearTables = `
+	CREATE TABLE IF NOT EXISTS linear_teams (
+		id TEXT PRIMARY KEY,
+		key TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		description TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS linear_users (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		display_name TEXT NOT NULL,
+		email TEXT NOT NULL UNIQUE,
+		active INTEGER DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS linear_workflow_states (
+		id TEXT PRIMARY KEY,
+		team_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		color TEXT,
+		position REAL DEFAULT 0,
+		FOREIGN KEY (team_id) REFERENCES linear_teams(id) ON DELETE CASCADE,
+		UNIQUE(team_id, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS linear_issues (
+		id TEXT PRIMARY KEY,
+		identifier TEXT NOT NULL UNIQUE,
+		team_id TEXT NOT NULL,
+		number INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT,
+		state TEXT NOT NULL,
+		priority INTEGER DEFAULT 0,
+		assignee_id TEXT,
+		creator_id TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		started_at TIMESTAMP,
+		completed_at TIMESTAMP,
+		canceled_at TIMESTAMP,
+		FOREIGN KEY (team_id) REFERENCES linear_teams(id) ON DELETE CASCADE,
+		UNIQUE(team_id, number)
+	);
+	CREATE INDEX IF NOT EXISTS idx_linear_states_team ON linear_workflow_states(team_id);
+	CREATE INDEX IF NOT EXISTS idx_linear_issues_team ON linear_issues(team_id);
+	CREATE INDEX IF NOT EXISTS idx_linear_issues_assignee ON linear_issues(assignee_id);
+`
@@ -0,0 +1,57 @@
+// ABOUTME: Migration 10 creates the Notion plugin's tables and indexes.
+// ABOUTME: Databases, pages, and page blocks.
+
+package migrations
+
+import "database/sql"
+
+func migration010(tx *sql.Tx) error {
+	_, err := tx.Exec(notionTables)
+	return err
+}
+
+// notionTables creates the Notion plugin's tables
+const notionTables = `
+	CREATE TABLE IF NOT EXISTS notion_databases (
+		id TEXT PRIMARY KEY,
+		parent_type TEXT NOT NULL,
+		parent_id TEXT,
+		title TEXT NOT NULL,
+		properties TEXT NOT NULL,
+		archived INTEGER NOT NULL DEFAULT 0,
+		created_by TEXT,
+		created_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_edited_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS notion_pages (
+		id TEXT PRIMARY KEY,
+		parent_type TEXT NOT NULL,
+		parent_id TEXT,
+		properties TEXT NOT NULL,
+		icon TEXT,
+		cover TEXT,
+		archived INTEGER NOT NULL DEFAULT 0,
+		created_by TEXT,
+		last_edited_by TEXT,
+		created_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_edited_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS notion_blocks (
+		id TEXT PRIMARY KEY,
+		parent_type TEXT NOT NULL,
+		parent_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		content TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		has_children INTEGER NOT NULL DEFAULT 0,
+		archived INTEGER NOT NULL DEFAULT 0,
+		created_by TEXT,
+		last_edited_by TEXT,
+		created_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_edited_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_notion_pages_parent ON notion_pages(parent_id);
+	CREATE INDEX IF NOT EXISTS idx_notion_blocks_parent ON notion_blocks(parent_id, position);
+`
@@ -0,0 +1,27 @@
+// ABOUTME: Migration 11 creates the OAuth plugin's tables and indexes.
+// ABOUTME: Tokens issued by the mock OAuth server to every plugin.
+
+package migrations
+
+import "database/sql"
+
+func migration011(tx *sql.Tx) error {
+	// Databases from before versioned plugin migrations already have
+	// these tables, possibly without newer columns; later migrations add those
+	_, err := tx.Exec(oauthTables)
+	return err
+}
+
+// oauthTables creates the OAuth plugin's tables
+const oauthTables = `
+	CREATE TABLE IF NOT EXISTS oauth_tokens (
+		token TEXT PRIMARY KEY,
+		plugin_name TEXT NOT NULL,
+		user_id TEXT,
+		scopes TEXT,
+		expires_at TIMESTAMP,
+		refresh_token TEXT,
+		revoked BOOLEAN DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+`
@@ -0,0 +1,57 @@
+// ABOUTME: Migration 12 creates the Salesforce plugin's tables and indexes.
+// ABOUTME: Contacts and opportunities.
+
+package migrations
+
+import "database/sql"
+
+func migration012(tx *sql.Tx) error {
+	_, err := tx.Exec(salesforceTables)
+	return err
+}
+
+// salesforceTables creates the Salesforce plugin's tables
+const salesforceTables = `
+	CREATE TABLE IF NOT EXISTS sf_contacts (
+		Id TEXT PRIMARY KEY,
+		FirstName TEXT,
+		LastName TEXT NOT NULL,
+		Name TEXT NOT NULL,
+		Email TEXT,
+		Phone TEXT,
+		MobilePhone TEXT,
+		Title TEXT,
+		Department TEXT,
+		MailingStreet TEXT,
+		MailingCity TEXT,
+		MailingState TEXT,
+		MailingPostalCode TEXT,
+		MailingCountry TEXT,
+		LeadSource TEXT,
+		Description TEXT,
+		CreatedDate TEXT NOT NULL,
+		LastModifiedDate TEXT NOT NULL,
+		SystemModstamp TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS sf_opportunities (
+		Id TEXT PRIMARY KEY,
+		Name TEXT NOT NULL,
+		StageName TEXT NOT NULL,
+		Amount REAL,
+		CloseDate TEXT NOT NULL,
+		Probability REAL,
+		Type TEXT,
+		LeadSource TEXT,
+		NextStep TEXT,
+		Description TEXT,
+		ContactId TEXT,
+		IsClosed INTEGER NOT NULL DEFAULT 0,
+		IsWon INTEGER NOT NULL DEFAULT 0,
+		CreatedDate TEXT NOT NULL,
+		LastModifiedDate TEXT NOT NULL,
+		SystemModstamp TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_sf_contacts_email ON sf_contacts(Email);
+	CREATE INDEX IF NOT EXISTS idx_sf_opportunities_contact ON sf_opportunities(ContactId);
+`
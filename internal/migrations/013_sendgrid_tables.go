@@ -0,0 +1,142 @@
+// ABOUTME: Migration 13 creates the SendGrid plugin's tables and indexes.
+// ABOUTME: Accounts and API keys, sent mail, suppressions, event webhooks, and email validations.
+
+package migrations
+
+import "database/sql"
+
+func migration013(tx *sql.Tx) error {
+	// Databases from before versioned plugin migrations already have
+	// these tables, possibly without newer columns; later migrations add those
+	_, err := tx.Exec(sendgridTables)
+	return err
+}
+
+// sendgridTables creates the SendGrid plugin's tables
+const sendgridTables = `
+	CREATE TABLE IF NOT EXISTS sendgrid_accounts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		email TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_api_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		key TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		scopes TEXT NOT NULL DEFAULT 'mail.send',
+		last_used_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_api_keys_key ON sendgrid_api_keys(key);
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_api_keys_account ON sendgrid_api_keys(account_id);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_messages (
+		id TEXT PRIMARY KEY,
+		account_id INTEGER NOT NULL,
+		from_email TEXT NOT NULL,
+		from_name TEXT,
+		to_email TEXT NOT NULL,
+		to_name TEXT,
+		subject TEXT,
+		text_content TEXT,
+		html_content TEXT,
+		status TEXT NOT NULL DEFAULT 'delivered',
+		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_messages_account ON sendgrid_messages(account_id);
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_messages_to_email ON sendgrid_messages(to_email);
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_messages_sent_at ON sendgrid_messages(sent_at);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_suppressions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		email TEXT NOT NULL,
+		type TEXT NOT NULL CHECK(type IN ('bounce', 'block', 'spam_report')),
+		reason TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE,
+		UNIQUE(account_id, email, type)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_suppressions_account ON sendgrid_suppressions(account_id);
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_suppressions_email ON sendgrid_suppressions(email);
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_suppressions_type ON sendgrid_suppressions(type);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_webhook_config (
+		account_id INTEGER PRIMARY KEY,
+		url TEXT NOT NULL,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status_code INTEGER,
+		error_message TEXT,
+		delivered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_webhook_deliveries_account ON sendgrid_webhook_deliveries(account_id);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		message_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		email TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_events_account ON sendgrid_events(account_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_events_message ON sendgrid_events(message_id);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_ip_whitelist (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		ip TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_ip_whitelist_account ON sendgrid_ip_whitelist(account_id);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_access_activity (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		ip TEXT NOT NULL,
+		allowed INTEGER NOT NULL,
+		request_date DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_access_activity_account ON sendgrid_access_activity(account_id);
+
+	CREATE TABLE IF NOT EXISTS sendgrid_email_validations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		job_id TEXT NOT NULL DEFAULT '',
+		email TEXT NOT NULL,
+		verdict TEXT NOT NULL,
+		score REAL NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_email_validations_account ON sendgrid_email_validations(account_id);
+	CREATE INDEX IF NOT EXISTS idx_sendgrid_email_validations_job ON sendgrid_email_validations(job_id);
+`
@@ -0,0 +1,66 @@
+// ABOUTME: Migration 14 creates the Slack plugin's tables and indexes.
+// ABOUTME: Users, channels, messages, and uploaded files.
+
+package migrations
+
+import "database/sql"
+
+func migration014(tx *sql.Tx) error {
+	_, err := tx.Exec(slackTables)
+	return err
+}
+
+// slackTables creates the Slack plugin's tables
+const slackTables = `
+	CREATE TABLE IF NOT EXISTS slack_users (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		real_name TEXT,
+		email TEXT,
+		title TEXT,
+		is_bot BOOLEAN DEFAULT 0,
+		is_admin BOOLEAN DEFAULT 0,
+		deleted BOOLEAN DEFAULT 0,
+		tz TEXT DEFAULT 'America/Los_Angeles',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS slack_channels (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		topic TEXT,
+		purpose TEXT,
+		is_private BOOLEAN DEFAULT 0,
+		is_archived BOOLEAN DEFAULT 0,
+		creator TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS slack_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		channel_id TEXT NOT NULL,
+		ts TEXT NOT NULL,
+		thread_ts TEXT,
+		user_id TEXT,
+		bot_id TEXT,
+		username TEXT,
+		text TEXT,
+		reactions TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(channel_id, ts),
+		FOREIGN KEY (channel_id) REFERENCES slack_channels(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS slack_files (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		title TEXT,
+		filetype TEXT,
+		mimetype TEXT,
+		size INTEGER DEFAULT 0,
+		user_id TEXT,
+		channels TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_slack_messages_channel_ts ON slack_messages(channel_id, ts DESC);
+`
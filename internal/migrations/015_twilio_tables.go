@@ -0,0 +1,158 @@
+// ABOUTME: Migration 15 creates the Twilio plugin's tables and indexes.
+// ABOUTME: Accounts, phone numbers, messages and calls, webhook queues, and Studio flows.
+
+package migrations
+
+import "database/sql"
+
+func migration015(tx *sql.Tx) error {
+	// Databases from before versioned plugin migrations already have
+	// these tables, possibly without newer columns; later migrations add those
+	_, err := tx.Exec(twilioTables)
+	return err
+}
+
+// twilioTables creates the Twilio plugin's tables
+const twilioTables = `
+	CREATE TABLE IF NOT EXISTS twilio_accounts (
+		account_sid TEXT PRIMARY KEY,
+		auth_token TEXT NOT NULL,
+		friendly_name TEXT,
+		status TEXT DEFAULT 'active',
+		owner_account_sid TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS twilio_phone_numbers (
+		sid TEXT PRIMARY KEY,
+		account_sid TEXT NOT NULL,
+		phone_number TEXT NOT NULL,
+		friendly_name TEXT,
+		voice_url TEXT,
+		voice_method TEXT DEFAULT 'POST',
+		sms_url TEXT,
+		sms_method TEXT DEFAULT 'POST',
+		status_callback TEXT,
+		status_callback_method TEXT DEFAULT 'POST',
+		webhook_delay_ms INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_phone_numbers_account ON twilio_phone_numbers(account_sid);
+
+	CREATE TABLE IF NOT EXISTS twilio_messages (
+		sid TEXT PRIMARY KEY,
+		account_sid TEXT NOT NULL,
+		from_number TEXT NOT NULL,
+		to_number TEXT NOT NULL,
+		body TEXT,
+		status TEXT DEFAULT 'queued',
+		direction TEXT,
+		date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		date_sent TIMESTAMP,
+		date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		num_segments INTEGER DEFAULT 1,
+		price REAL,
+		price_unit TEXT DEFAULT 'USD',
+		next_status_at TIMESTAMP,
+		FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_messages_account ON twilio_messages(account_sid);
+	CREATE INDEX IF NOT EXISTS idx_messages_status ON twilio_messages(status);
+	CREATE INDEX IF NOT EXISTS idx_messages_date ON twilio_messages(date_created);
+
+	CREATE TABLE IF NOT EXISTS twilio_message_media (
+		sid TEXT PRIMARY KEY,
+		account_sid TEXT NOT NULL,
+		message_sid TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		url TEXT NOT NULL,
+		date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (message_sid) REFERENCES twilio_messages(sid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_message_media_message ON twilio_message_media(message_sid);
+	CREATE INDEX IF NOT EXISTS idx_message_media_account ON twilio_message_media(account_sid);
+
+	CREATE TABLE IF NOT EXISTS twilio_calls (
+		sid TEXT PRIMARY KEY,
+		account_sid TEXT NOT NULL,
+		from_number TEXT NOT NULL,
+		to_number TEXT NOT NULL,
+		status TEXT DEFAULT 'initiated',
+		direction TEXT,
+		duration INTEGER,
+		date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		answered_by TEXT,
+		FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_calls_account ON twilio_calls(account_sid);
+	CREATE INDEX IF NOT EXISTS idx_calls_status ON twilio_calls(status);
+
+	CREATE TABLE IF NOT EXISTS twilio_webhook_configs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_sid TEXT NOT NULL,
+		resource_type TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		url TEXT NOT NULL,
+		method TEXT DEFAULT 'POST',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_configs_account ON twilio_webhook_configs(account_sid);
+
+	CREATE TABLE IF NOT EXISTS twilio_webhook_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		resource_sid TEXT NOT NULL,
+		webhook_url TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		scheduled_at TIMESTAMP NOT NULL,
+		delivered_at TIMESTAMP,
+		status TEXT DEFAULT 'pending',
+		attempts INTEGER DEFAULT 0,
+		status_code INTEGER,
+		error_message TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_queue_schedule ON twilio_webhook_queue(scheduled_at, status);
+
+	CREATE TABLE IF NOT EXISTS twilio_twiml_responses (
+		phone_number_sid TEXT NOT NULL,
+		type TEXT NOT NULL CHECK (type IN ('voice', 'sms')),
+		twiml TEXT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (phone_number_sid, type),
+		FOREIGN KEY (phone_number_sid) REFERENCES twilio_phone_numbers(sid)
+	);
+
+	CREATE TABLE IF NOT EXISTS twilio_flows (
+		sid TEXT PRIMARY KEY,
+		account_sid TEXT NOT NULL,
+		friendly_name TEXT NOT NULL,
+		status TEXT NOT NULL,
+		definition TEXT NOT NULL,
+		revision INTEGER NOT NULL DEFAULT 1,
+		date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_flows_account ON twilio_flows(account_sid);
+
+	CREATE TABLE IF NOT EXISTS twilio_flow_executions (
+		sid TEXT PRIMARY KEY,
+		account_sid TEXT NOT NULL,
+		flow_sid TEXT NOT NULL,
+		contact_channel_address TEXT NOT NULL,
+		status TEXT NOT NULL,
+		context TEXT NOT NULL,
+		date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (flow_sid) REFERENCES twilio_flows(sid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_flow_executions_flow ON twilio_flow_executions(flow_sid);
+
+	-- Columns added later reach older databases through internal/migrations
+`
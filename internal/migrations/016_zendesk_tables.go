@@ -0,0 +1,47 @@
+// ABOUTME: Migration 16 creates the Zendesk plugin's tables and indexes.
+// ABOUTME: Users, tickets, and ticket comments.
+
+package migrations
+
+import "database/sql"
+
+func migration016(tx *sql.Tx) error {
+	_, err := tx.Exec(zendeskTables)
+	return err
+}
+
+// zendeskTables creates the Zendesk plugin's tables
+const zendeskTables = `
+	CREATE TABLE IF NOT EXISTS zendesk_users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		email TEXT NOT NULL UNIQUE,
+		role TEXT NOT NULL DEFAULT 'end-user',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS zendesk_tickets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		subject TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'new',
+		priority TEXT NOT NULL DEFAULT '',
+		type TEXT NOT NULL DEFAULT '',
+		requester_id INTEGER NOT NULL,
+		submitter_id INTEGER NOT NULL,
+		assignee_id INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS zendesk_comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ticket_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		author_id INTEGER NOT NULL,
+		public INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_zendesk_comments_ticket ON zendesk_comments(ticket_id);
+`
@@ -0,0 +1,17 @@
+// ABOUTME: Migration 17 adds the request_logs keyset pagination index.
+// ABOUTME: Lets each page of logs be read as an index range scan.
+
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func migration017(tx *sql.Tx) error {
+	// Matches ORDER BY timestamp DESC, id DESC so each page is an index range scan
+	if _, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_request_logs_timestamp_id ON request_logs(timestamp DESC, id DESC)"); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+// ABOUTME: Migration 18 adds history_id to gmail_messages.
+// ABOUTME: Upgrades databases created before Gmail history tracking existed.
+
+package migrations
+
+import "database/sql"
+
+func migration018(tx *sql.Tx) error {
+	return addColumn(tx, "gmail_messages", "history_id", "INTEGER")
+}
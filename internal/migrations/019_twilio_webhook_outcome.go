@@ -0,0 +1,13 @@
+// ABOUTME: Migration 19 adds status_code and error_message to twilio_webhook_queue.
+// ABOUTME: Lets older databases record each webhook delivery's outcome, including timeouts.
+
+package migrations
+
+import "database/sql"
+
+func migration019(tx *sql.Tx) error {
+	if err := addColumn(tx, "twilio_webhook_queue", "status_code", "INTEGER"); err != nil {
+		return err
+	}
+	return addColumn(tx, "twilio_webhook_queue", "error_message", "TEXT")
+}
@@ -0,0 +1,25 @@
+// ABOUTME: Migration 20 adds the columns GitHub tables gained after their original schema.
+// ABOUTME: Upgrades pull requests, issues, webhooks, repositories, issue events, tokens, and workflow runs.
+
+package migrations
+
+import "database/sql"
+
+func migration020(tx *sql.Tx) error {
+	for _, c := range []struct{ table, column, definition string }{
+		{"github_pull_requests", "base_sha", "TEXT"},
+		{"github_issues", "lock_reason", "TEXT"},
+		{"github_webhooks", "delay_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"github_repositories", "language", "TEXT"},
+		{"github_issue_events", "commit_id", "TEXT"},
+		{"github_issue_events", "source_issue_id", "INTEGER"},
+		{"github_tokens", "expires_at", "TIMESTAMP"},
+		{"github_tokens", "installation_id", "INTEGER"},
+		{"github_workflow_runs", "inputs", "TEXT"},
+	} {
+		if err := addColumn(tx, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
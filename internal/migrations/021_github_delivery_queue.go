@@ -0,0 +1,43 @@
+// ABOUTME: Migration 21 adds the retry queue columns to github_webhook_deliveries.
+// ABOUTME: Marks deliveries logged before the queue existed as delivered or exhausted, and indexes due retries.
+
+package migrations
+
+import "database/sql"
+
+func migration021(tx *sql.Tx) error {
+	found, err := hasColumn(tx, "github_webhook_deliveries", "status")
+	if err != nil {
+		return err
+	}
+	if !found {
+		if err := addQueueColumns(tx); err != nil {
+			return err
+		}
+	}
+	// The delivery worker polls this index, so it waits for the columns
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_deliveries_due ON github_webhook_deliveries(status, next_retry_at)`)
+	return err
+}
+
+// addQueueColumns upgrades a deliveries log from before the queue
+func addQueueColumns(tx *sql.Tx) error {
+	// Deliveries logged before the queue existed each made one attempt, and
+	// are never retried
+	for _, c := range []struct{ column, definition string }{
+		{"status", "TEXT NOT NULL DEFAULT ''"},
+		{"attempts", "INTEGER NOT NULL DEFAULT 1"},
+		{"next_retry_at", "TIMESTAMP"},
+		{"redelivery", "BOOLEAN NOT NULL DEFAULT 0"},
+	} {
+		if err := addColumn(tx, "github_webhook_deliveries", c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`
+		UPDATE github_webhook_deliveries
+		SET status = CASE WHEN status_code BETWEEN 200 AND 299 THEN 'delivered' ELSE 'exhausted' END
+		WHERE status = ''
+	`)
+	return err
+}
@@ -0,0 +1,19 @@
+// ABOUTME: Migration 22 adds the columns Twilio tables gained after their original schema.
+// ABOUTME: Upgrades accounts for subaccounts, phone numbers for webhook delays, and messages for status updates.
+
+package migrations
+
+import "database/sql"
+
+func migration022(tx *sql.Tx) error {
+	for _, c := range []struct{ table, column, definition string }{
+		{"twilio_accounts", "owner_account_sid", "TEXT"},
+		{"twilio_phone_numbers", "webhook_delay_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"twilio_messages", "next_status_at", "TIMESTAMP"},
+	} {
+		if err := addColumn(tx, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+// ABOUTME: Migration 23 adds color_id to calendar_events.
+// ABOUTME: Upgrades databases created before Google Calendar event colors existed.
+
+package migrations
+
+import "database/sql"
+
+func migration023(tx *sql.Tx) error {
+	return addColumn(tx, "calendar_events", "color_id", "TEXT")
+}
@@ -0,0 +1,26 @@
+// ABOUTME: Migration 24 creates the shared audit_log table.
+// ABOUTME: Plugin stores append a row to it for every create, update, and delete.
+
+package migrations
+
+import "database/sql"
+
+func migration024(tx *sql.Tx) error {
+	// Older versions created audit_log on first use, so it may already exist
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		plugin_name TEXT NOT NULL,
+		resource_type TEXT NOT NULL,
+		resource_id TEXT NOT NULL,
+		action TEXT NOT NULL,
+		actor TEXT NOT NULL DEFAULT '',
+		changes TEXT,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_plugin ON audit_log(plugin_name, resource_type, id DESC);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, id DESC);
+	`)
+	return err
+}
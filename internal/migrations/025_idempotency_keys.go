@@ -0,0 +1,27 @@
+// ABOUTME: Migration 25 creates the idempotency_keys table.
+// ABOUTME: Caches responses to requests sent with an Idempotency-Key so retries replay them.
+
+package migrations
+
+import "database/sql"
+
+func migration025(tx *sql.Tx) error {
+	// Older versions created idempotency_keys on first use, so it may already exist
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		user_id TEXT NOT NULL,
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		idempotency_key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		content_type TEXT NOT NULL DEFAULT '',
+		body BLOB,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, method, path, idempotency_key)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys(created_at);
+	`)
+	return err
+}
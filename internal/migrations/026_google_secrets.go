@@ -0,0 +1,16 @@
+// ABOUTME: Migration 26 creates the google_secrets table.
+// ABOUTME: Holds keys the Google plugin generates once, such as the page token signing key.
+
+package migrations
+
+import "database/sql"
+
+func migration026(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS google_secrets (
+		name TEXT PRIMARY KEY,
+		value BLOB NOT NULL
+	);
+	`)
+	return err
+}
@@ -0,0 +1,22 @@
+// ABOUTME: Migration 27 adds fork_of to github_repositories and drops github_repository_forks.
+// ABOUTME: Copies each fork's parent from the old lineage table so upgraded forks keep their upstream.
+
+package migrations
+
+import "database/sql"
+
+func migration027(tx *sql.Tx) error {
+	if err := addColumn(tx, "github_repositories", "fork_of", "INTEGER REFERENCES github_repositories(id)"); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+	UPDATE github_repositories
+	SET fork_of = (SELECT parent_id FROM github_repository_forks WHERE repo_id = github_repositories.id)
+	WHERE fork_of IS NULL AND id IN (SELECT repo_id FROM github_repository_forks);
+
+	DROP TABLE IF EXISTS github_repository_forks;
+
+	CREATE INDEX IF NOT EXISTS idx_repositories_fork_of ON github_repositories(fork_of);
+	`)
+	return err
+}
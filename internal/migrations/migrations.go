@@ -0,0 +1,196 @@
+// ABOUTME: Versioned schema migrations for the ISH database.
+// ABOUTME: Tracks applied versions in schema_migrations and runs pending migrations in order, each in a transaction.
+
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Migration is a single numbered schema change
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *sql.Tx) error
+}
+
+// All lists every migration in version order. Versions are never reused or
+// renumbered once released; add new migrations to the end.
+var All = []Migration{
+	{Version: 1, Description: "Create request_logs table and indexes", Up: migration001},
+	{Version: 2, Description: "Add composite indexes for aggregation and filtering queries", Up: migration002},
+	{Version: 3, Description: "Create Discord tables", Up: migration003},
+	{Version: 4, Description: "Create GitHub tables", Up: migration004},
+	{Version: 5, Description: "Create Google tables", Up: migration005},
+	{Version: 6, Description: "Create Home Assistant tables", Up: migration006},
+	{Version: 7, Description: "Create HubSpot tables", Up: migration007},
+	{Version: 8, Description: "Create Jira tables", Up: migration008},
+	{Version: 9, Description: "Create Linear tables", Up: migration009},
+	{Version: 10, Description: "Create Notion tables", Up: migration010},
+	{Version: 11, Description: "Create OAuth tables", Up: migration011},
+	{Version: 12, Description: "Create Salesforce tables", Up: migration012},
+	{Version: 13, Description: "Create SendGrid tables", Up: migration013},
+	{Version: 14, Description: "Create Slack tables", Up: migration014},
+	{Version: 15, Description: "Create Twilio tables", Up: migration015},
+	{Version: 16, Description: "Create Zendesk tables", Up: migration016},
+	{Version: 17, Description: "Add keyset pagination index for request logs", Up: migration017},
+	{Version: 18, Description: "Add history_id to gmail_messages", Up: migration018},
+	{Version: 19, Description: "Add delivery outcome to twilio_webhook_queue", Up: migration019},
+	{Version: 20, Description: "Add columns to GitHub tables from older versions", Up: migration020},
+	{Version: 21, Description: "Add retry queue columns to github_webhook_deliveries", Up: migration021},
+	{Version: 22, Description: "Add columns to Twilio tables from older versions", Up: migration022},
+	{Version: 23, Description: "Add color_id to calendar_events", Up: migration023},
+	{Version: 24, Description: "Create audit_log table", Up: migration024},
+	{Version: 25, Description: "Create idempotency_keys table", Up: migration025},
+	{Version: 26, Description: "Create google_secrets table", Up: migration026},
+	{Version: 27, Description: "Track GitHub fork parents in github_repositories.fork_of", Up: migration027},
+}
+
+// Latest returns the highest version in All
+func Latest() int {
+	return All[len(All)-1].Version
+}
+
+// Pending returns the migrations that have not been applied to db, in order.
+// It does not modify the database.
+func Pending(db *sql.DB, migrations []Migration) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Apply runs every pending migration in order and returns the ones it applied.
+// Each migration and its schema_migrations record commit together, so a failed
+// migration leaves no partial changes and is retried on the next run.
+func Apply(db *sql.DB, migrations []Migration) ([]Migration, error) {
+	if err := createMigrationsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	pending, err := Pending(db, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	for i, m := range pending {
+		if err := apply(db, m); err != nil {
+			return pending[:i], fmt.Errorf("migration v%d failed: %w", m.Version, err)
+		}
+		log.Printf("Applied migration v%d: %s", m.Version, m.Description)
+	}
+	return pending, nil
+}
+
+func apply(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.Up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, description) VALUES (?, ?)", m.Version, m.Description); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// createMigrationsTable creates the schema_migrations tracking table
+func createMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			description TEXT
+		)
+	`)
+	return err
+}
+
+// appliedVersions returns the recorded migration versions, or none if the
+// tracking table does not exist yet
+func appliedVersions(db *sql.DB) (map[int]bool, error) {
+	applied := make(map[int]bool)
+
+	var exists int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return applied, nil
+	}
+
+	rows, err := db.Query("SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// hasColumn reports whether table has the named column. Each plugin's table
+// migration runs before its column upgrades, so a missing table is an error.
+func hasColumn(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	tableExists, found := false, false
+	for rows.Next() {
+		tableExists = true
+		var (
+			cid        int
+			name, kind string
+			notNull    int
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			found = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	if !tableExists {
+		return false, fmt.Errorf("table %s does not exist", table)
+	}
+	return found, nil
+}
+
+// addColumn adds a column to a table created by an older version. Tables
+// created by their plugin's migration on fresh databases already have it.
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	found, err := hasColumn(tx, table, column)
+	if err != nil || found {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
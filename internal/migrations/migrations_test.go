@@ -0,0 +1,353 @@
+// ABOUTME: Tests for the versioned migration runner.
+// ABOUTME: Covers idempotency, pending detection, rollback on failure, creating plugin tables, and upgrading legacy tables.
+
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// upTo returns the migrations in All up to and including version
+func upTo(version int) []Migration {
+	var out []Migration
+	for _, m := range All {
+		if m.Version <= version {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func versions(migrations []Migration) []int {
+	var out []int
+	for _, m := range migrations {
+		out = append(out, m.Version)
+	}
+	return out
+}
+
+func TestApplyIsIdempotent(t *testing.T) {
+	db := openTestDB(t)
+
+	applied, err := Apply(db, All)
+	if err != nil {
+		t.Fatalf("First Apply failed: %v", err)
+	}
+	if len(applied) != len(All) {
+		t.Errorf("Expected all %d migrations applied, got %v", len(All), versions(applied))
+	}
+
+	applied, err = Apply(db, All)
+	if err != nil {
+		t.Fatalf("Second Apply failed: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no migrations on second run, got %v", versions(applied))
+	}
+
+	var count, max int
+	if err := db.QueryRow("SELECT COUNT(*), MAX(version) FROM schema_migrations").Scan(&count, &max); err != nil {
+		t.Fatalf("Failed to query schema_migrations: %v", err)
+	}
+	if count != len(All) || max != Latest() {
+		t.Errorf("Expected %d records up to v%d, got %d up to v%d", len(All), Latest(), count, max)
+	}
+}
+
+func TestPendingDoesNotModifyDatabase(t *testing.T) {
+	db := openTestDB(t)
+
+	pending, err := Pending(db, All)
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) != len(All) {
+		t.Errorf("Expected every migration pending on an empty database, got %v", versions(pending))
+	}
+
+	var tables int
+	db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables)
+	if tables != 0 {
+		t.Errorf("Expected Pending to create no tables, found %d", tables)
+	}
+
+	if _, err := Apply(db, All[:2]); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	pending, _ = Pending(db, All)
+	if got := versions(pending); len(got) != len(All)-2 || got[0] != 3 {
+		t.Errorf("Expected migrations from v3 pending, got %v", got)
+	}
+}
+
+func TestApplyRollsBackFailedMigration(t *testing.T) {
+	db := openTestDB(t)
+
+	failing := []Migration{
+		{Version: 1, Description: "create widgets", Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY)")
+			return err
+		}},
+		{Version: 2, Description: "half-done change", Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec("ALTER TABLE widgets ADD COLUMN name TEXT"); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		}},
+	}
+
+	applied, err := Apply(db, failing)
+	if err == nil {
+		t.Fatal("Expected Apply to fail")
+	}
+	if got := versions(applied); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Expected only v1 applied, got %v", got)
+	}
+
+	// The column added by the failed migration was rolled back with it
+	if _, err := db.Exec("SELECT name FROM widgets"); err == nil {
+		t.Error("Expected the failed migration's column to be rolled back")
+	}
+	pending, _ := Pending(db, failing)
+	if got := versions(pending); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected v2 still pending, got %v", got)
+	}
+}
+
+// dropColumns turns a table created by its plugin's migration into one from an older
+// version that predates the columns
+func dropColumns(t *testing.T, db *sql.DB, table string, columns ...string) {
+	t.Helper()
+	for _, column := range columns {
+		if _, err := db.Exec("ALTER TABLE " + table + " DROP COLUMN " + column); err != nil {
+			t.Fatalf("Failed to drop %s.%s: %v", table, column, err)
+		}
+	}
+}
+
+func TestMigrationOneCreatesOnlyRequestLogs(t *testing.T) {
+	db := openTestDB(t)
+
+	// Migration 1 was released before plugin tables were migrations, so
+	// databases recorded at v1 may have none of them
+	if _, err := Apply(db, upTo(1)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	var tables []string
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		tables = append(tables, name)
+	}
+	if len(tables) != 2 || tables[0] != "request_logs" || tables[1] != "schema_migrations" {
+		t.Errorf("Expected only request_logs and schema_migrations after v1, got %v", tables)
+	}
+}
+
+func TestMigrationsCreatePluginTables(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := Apply(db, All); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	for _, name := range []string{
+		"request_logs", "discord_webhooks", "github_repositories", "gmail_messages", "homeassistant_entities",
+		"google_secrets", "hubspot_contacts", "jira_issues", "linear_issues", "notion_pages", "oauth_tokens", "sf_contacts",
+		"sendgrid_messages", "slack_messages", "twilio_messages", "zendesk_tickets",
+		"idx_deliveries_due",
+	} {
+		var count int
+		db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = ?", name).Scan(&count)
+		if count != 1 {
+			t.Errorf("Expected %s after migrating a fresh database", name)
+		}
+	}
+}
+
+func TestAddColumnRequiresTable(t *testing.T) {
+	db := openTestDB(t)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	defer tx.Rollback()
+	if err := addColumn(tx, "gmail_messages", "history_id", "INTEGER"); err == nil {
+		t.Error("Expected adding a column to a missing table to fail")
+	}
+}
+
+func TestGmailHistoryIDMigration(t *testing.T) {
+	db := openTestDB(t)
+
+	// A gmail_messages table from before history tracking, already at v17
+	if _, err := Apply(db, upTo(17)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	dropColumns(t, db, "gmail_messages", "history_id")
+	if _, err := db.Exec("INSERT INTO gmail_messages (id, user_id) VALUES ('msg_1', 'me')"); err != nil {
+		t.Fatalf("Failed to insert legacy message: %v", err)
+	}
+
+	applied, err := Apply(db, upTo(18))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := versions(applied); len(got) != 1 || got[0] != 18 {
+		t.Errorf("Expected only v18 applied, got %v", got)
+	}
+
+	var historyID sql.NullInt64
+	if err := db.QueryRow("SELECT history_id FROM gmail_messages WHERE id = 'msg_1'").Scan(&historyID); err != nil {
+		t.Fatalf("Expected history_id column after migration: %v", err)
+	}
+}
+
+func TestTwilioWebhookOutcomeMigration(t *testing.T) {
+	db := openTestDB(t)
+
+	// A twilio_webhook_queue table from before delivery outcomes, already at v18
+	if _, err := Apply(db, upTo(18)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	dropColumns(t, db, "twilio_webhook_queue", "status_code", "error_message")
+
+	applied, err := Apply(db, upTo(19))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := versions(applied); len(got) != 1 || got[0] != 19 {
+		t.Errorf("Expected only v19 applied, got %v", got)
+	}
+
+	for _, column := range []string{"status_code", "error_message"} {
+		var count int
+		db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('twilio_webhook_queue') WHERE name = ?", column).Scan(&count)
+		if count != 1 {
+			t.Errorf("Expected twilio_webhook_queue.%s after migration", column)
+		}
+	}
+}
+
+func TestGitHubForkOfMigration(t *testing.T) {
+	db := openTestDB(t)
+
+	// A fork recorded in the old github_repository_forks table, at v26
+	if _, err := Apply(db, upTo(26)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO github_users (id, login) VALUES (1, 'alice');
+		INSERT INTO github_repositories (id, owner_id, name, full_name) VALUES (1, 1, 'app', 'alice/app'), (2, 1, 'app-1', 'alice/app-1');
+		INSERT INTO github_repository_forks (repo_id, parent_id, source_id) VALUES (2, 1, 1);
+	`); err != nil {
+		t.Fatalf("Failed to insert legacy fork: %v", err)
+	}
+
+	if _, err := Apply(db, upTo(27)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	var forkOf sql.NullInt64
+	if err := db.QueryRow("SELECT fork_of FROM github_repositories WHERE id = 2").Scan(&forkOf); err != nil || forkOf.Int64 != 1 {
+		t.Errorf("Expected the fork's parent copied into fork_of, got %v (%v)", forkOf, err)
+	}
+	var tables int
+	db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'github_repository_forks'").Scan(&tables)
+	if tables != 0 {
+		t.Error("Expected github_repository_forks to be dropped")
+	}
+}
+
+func TestPluginColumnMigrations(t *testing.T) {
+	db := openTestDB(t)
+
+	columns := []struct{ table, column string }{
+		{"github_pull_requests", "base_sha"},
+		{"github_issues", "lock_reason"},
+		{"github_webhooks", "delay_ms"},
+		{"github_repositories", "language"},
+		{"github_issue_events", "commit_id"},
+		{"github_issue_events", "source_issue_id"},
+		{"github_tokens", "expires_at"},
+		{"github_tokens", "installation_id"},
+		{"github_workflow_runs", "inputs"},
+		{"twilio_accounts", "owner_account_sid"},
+		{"twilio_phone_numbers", "webhook_delay_ms"},
+		{"twilio_messages", "next_status_at"},
+		{"calendar_events", "color_id"},
+	}
+
+	// Plugin tables from before their later columns, already at v19
+	if _, err := Apply(db, upTo(19)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	for _, c := range columns {
+		dropColumns(t, db, c.table, c.column)
+	}
+
+	if _, err := Apply(db, upTo(23)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	for _, c := range columns {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", c.table, c.column).Scan(&count); err != nil {
+			t.Fatalf("Failed to read %s columns: %v", c.table, err)
+		}
+		if count != 1 {
+			t.Errorf("Expected %s.%s after migration", c.table, c.column)
+		}
+	}
+}
+
+func TestGitHubDeliveryQueueMigration(t *testing.T) {
+	db := openTestDB(t)
+
+	// Deliveries logged before the retry queue, already at v20
+	if _, err := Apply(db, upTo(20)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	dropColumns(t, db, "github_webhook_deliveries", "status", "attempts", "next_retry_at", "redelivery")
+	if _, err := db.Exec(`
+		INSERT INTO github_webhook_deliveries (id, webhook_id, event_type, payload, status_code)
+		VALUES (1, 1, 'push', '{}', 200), (2, 1, 'push', '{}', 500)
+	`); err != nil {
+		t.Fatalf("Failed to insert legacy deliveries: %v", err)
+	}
+
+	if _, err := Apply(db, upTo(21)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	for id, want := range map[int]string{1: "delivered", 2: "exhausted"} {
+		var status string
+		var attempts int
+		if err := db.QueryRow("SELECT status, attempts FROM github_webhook_deliveries WHERE id = ?", id).Scan(&status, &attempts); err != nil {
+			t.Fatalf("Expected queue columns after migration: %v", err)
+		}
+		if status != want || attempts != 1 {
+			t.Errorf("Delivery %d: expected %s after 1 attempt, got %s after %d", id, want, status, attempts)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// ABOUTME: JSON pretty-printing middleware for debugging raw API responses.
+// ABOUTME: Indents JSON bodies when a request has ?pretty=true or ISH_PRETTY_JSON=true is set.
+
+package pretty
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Env names the environment variable that pretty-prints every JSON response
+const Env = "ISH_PRETTY_JSON"
+
+// indent is the indentation used for each nesting level
+const indent = "  "
+
+// EnabledFromEnv reports whether ISH_PRETTY_JSON=true
+func EnabledFromEnv() bool {
+	return os.Getenv(Env) == "true"
+}
+
+// Middleware indents JSON response bodies. A request's ?pretty=true or
+// ?pretty=false overrides byDefault. Only complete application/json (and
+// +json) bodies are rewritten, with Content-Length corrected; other content
+// types, and responses a handler flushes while streaming, pass through as written.
+func Middleware(byDefault bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			enabled := byDefault
+			if value := r.URL.Query().Get("pretty"); value != "" {
+				enabled, _ = strconv.ParseBool(value)
+			}
+			if !enabled || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			pw := &responseWriter{ResponseWriter: w}
+			next.ServeHTTP(pw, r)
+			pw.finish()
+		})
+	}
+}
+
+// isJSON reports whether a Content-Type is JSON, such as application/json or
+// application/vnd.github+json
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// responseWriter holds back a JSON body until the handler returns so it can
+// be indented. Anything else is written straight through.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (pw *responseWriter) WriteHeader(code int) {
+	if pw.wroteHeader {
+		return
+	}
+	pw.wroteHeader = true
+	pw.status = code
+	bodyless := code == http.StatusNoContent || code == http.StatusNotModified || code < 200
+	pw.buffering = !bodyless && isJSON(pw.Header().Get("Content-Type"))
+	if !pw.buffering {
+		pw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (pw *responseWriter) Write(b []byte) (int, error) {
+	if !pw.wroteHeader {
+		pw.WriteHeader(http.StatusOK)
+	}
+	if pw.buffering {
+		return pw.body.Write(b)
+	}
+	return pw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher. A streaming handler gets its body as
+// written, since it can't be indented until it ends.
+func (pw *responseWriter) Flush() {
+	if pw.buffering {
+		pw.passThrough(pw.body.Bytes())
+	}
+	if f, ok := pw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker to support WebSocket upgrades
+func (pw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := pw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+// passThrough stops buffering and writes the held-back header and body
+func (pw *responseWriter) passThrough(body []byte) {
+	pw.buffering = false
+	pw.ResponseWriter.WriteHeader(pw.status)
+	pw.ResponseWriter.Write(body)
+	pw.body.Reset()
+}
+
+// finish writes a buffered body, indented unless it isn't valid JSON
+func (pw *responseWriter) finish() {
+	if !pw.buffering {
+		return
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, pw.body.Bytes(), "", indent); err != nil {
+		pw.Header().Set("Content-Length", strconv.Itoa(pw.body.Len()))
+		pw.passThrough(pw.body.Bytes())
+		return
+	}
+	if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	pw.Header().Set("Content-Length", strconv.Itoa(out.Len()))
+	pw.passThrough(out.Bytes())
+}
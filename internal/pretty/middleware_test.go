@@ -0,0 +1,86 @@
+// ABOUTME: Tests for JSON pretty-printing middleware.
+// ABOUTME: Verifies ?pretty=true indents JSON, Content-Length matches, and other responses pass through.
+
+package pretty
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// jsonHandler writes a compact JSON body the way plugin handlers do
+var jsonHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{"id": 1, "labels": []string{"bug"}})
+})
+
+func serve(handler http.Handler, byDefault bool, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	Middleware(byDefault)(handler).ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+	return w
+}
+
+func TestMiddleware_PrettyQuery(t *testing.T) {
+	w := serve(jsonHandler, false, "/repos/alice/app/issues?pretty=true")
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	want := "{\n  \"id\": 1,\n  \"labels\": [\n    \"bug\"\n  ]\n}\n"
+	if got := w.Body.String(); got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+	if got := w.Header().Get("Content-Length"); got != strconv.Itoa(len(want)) {
+		t.Errorf("Content-Length = %s, want %d", got, len(want))
+	}
+}
+
+func TestMiddleware_Default(t *testing.T) {
+	if body := serve(jsonHandler, false, "/").Body.String(); strings.Contains(body, "\n  ") {
+		t.Errorf("expected compact JSON without ?pretty, got %q", body)
+	}
+	if body := serve(jsonHandler, true, "/").Body.String(); !strings.Contains(body, "\n  \"id\": 1") {
+		t.Errorf("expected indented JSON when on by default, got %q", body)
+	}
+	if body := serve(jsonHandler, true, "/?pretty=false").Body.String(); strings.Contains(body, "\n  ") {
+		t.Errorf("expected ?pretty=false to turn indenting off, got %q", body)
+	}
+}
+
+func TestMiddleware_PassThrough(t *testing.T) {
+	html := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<p>{\"a\":1}</p>"))
+	})
+	if body := serve(html, true, "/").Body.String(); body != "<p>{\"a\":1}</p>" {
+		t.Errorf("expected HTML unchanged, got %q", body)
+	}
+
+	// Streaming JSON is flushed as written rather than held back
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"event":1}`))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(`{"event":2}`))
+	})
+	w := serve(stream, true, "/")
+	if body := w.Body.String(); body != `{"event":1}{"event":2}` {
+		t.Errorf("expected streamed JSON unchanged, got %q", body)
+	}
+	if !w.Flushed {
+		t.Error("expected the flush to reach the client")
+	}
+
+	invalid := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"truncated":`))
+	})
+	if body := serve(invalid, true, "/").Body.String(); body != `{"truncated":` {
+		t.Errorf("expected invalid JSON unchanged, got %q", body)
+	}
+}
@@ -0,0 +1,77 @@
+// ABOUTME: Per-plugin metrics aggregated from request logs and plugin tables.
+// ABOUTME: Backs the /api/metrics summary of traffic, errors, latency and record counts.
+
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// metricsTablePattern guards the table names interpolated into COUNT(*) queries
+var metricsTablePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// PluginMetrics summarizes one plugin's traffic since a point in time and
+// the records it currently holds
+type PluginMetrics struct {
+	Name         string
+	Requests     int
+	Errors       int            // responses with status 400 or above
+	AvgLatencyMs float64        // mean request duration, 0 without requests
+	Resources    map[string]int // row counts keyed by resource slug
+}
+
+// GetPluginMetrics returns metrics for every registered plugin, sorted by
+// name. Request figures cover logs at or after since; resource counts come
+// from each plugin's counted tables, skipping tables that don't exist yet.
+func (s *Store) GetPluginMetrics(since time.Time) ([]PluginMetrics, error) {
+	rows, err := s.db.Query(`
+		SELECT plugin_name, COUNT(*), SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END), AVG(duration_ms)
+		FROM request_logs
+		WHERE timestamp >= ?
+		GROUP BY plugin_name
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	traffic := make(map[string]PluginMetrics)
+	for rows.Next() {
+		var m PluginMetrics
+		if err := rows.Scan(&m.Name, &m.Requests, &m.Errors, &m.AvgLatencyMs); err != nil {
+			return nil, err
+		}
+		traffic[m.Name] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	plugins := core.All()
+	metrics := make([]PluginMetrics, 0, len(plugins))
+	for _, plugin := range plugins {
+		m := traffic[plugin.Name()]
+		m.Name = plugin.Name()
+		m.Resources = make(map[string]int)
+		if counted, ok := plugin.(core.CountedPlugin); ok {
+			for _, t := range counted.CountedTables() {
+				if !metricsTablePattern.MatchString(t.Table) {
+					return nil, fmt.Errorf("plugin %s: invalid table name %q", m.Name, t.Table)
+				}
+				var n int
+				if err := s.db.QueryRow("SELECT COUNT(*) FROM " + t.Table).Scan(&n); err != nil {
+					continue
+				}
+				m.Resources[t.Resource] += n
+			}
+		}
+		metrics = append(metrics, m)
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics, nil
+}
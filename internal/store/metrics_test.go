@@ -0,0 +1,98 @@
+// ABOUTME: Tests for per-plugin metrics aggregation.
+// ABOUTME: Checks request, error, latency and record counts against known request logs and tables.
+
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// metricsMockPlugin counts one table for the metrics tests
+type metricsMockPlugin struct{ name string }
+
+func (m *metricsMockPlugin) Name() string                { return m.name }
+func (m *metricsMockPlugin) Health() core.HealthStatus   { return core.HealthStatus{Status: "healthy"} }
+func (m *metricsMockPlugin) RegisterRoutes(r chi.Router) {}
+func (m *metricsMockPlugin) RegisterAuth(r chi.Router)   {}
+func (m *metricsMockPlugin) Schema() core.PluginSchema   { return core.PluginSchema{} }
+func (m *metricsMockPlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
+	return core.SeedData{}, nil
+}
+func (m *metricsMockPlugin) ValidateToken(token string) bool { return true }
+func (m *metricsMockPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "metrics-widgets", Table: "metrics_widgets", Resource: "widgets"},
+		{Key: "metrics-missing", Table: "metrics_missing", Resource: "missing"},
+	}
+}
+
+func init() {
+	core.Register(&metricsMockPlugin{name: "metrics-widgets"})
+	core.Register(&metricsMockPlugin{name: "metrics-idle"})
+}
+
+func TestGetPluginMetrics(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	now := time.Now()
+	since := now.Add(-time.Hour)
+	logs := []*RequestLog{
+		{PluginName: "metrics-widgets", Method: "GET", Path: "/widgets", StatusCode: 200, DurationMs: 4, Timestamp: now.Add(-10 * time.Minute)},
+		{PluginName: "metrics-widgets", Method: "POST", Path: "/widgets", StatusCode: 201, DurationMs: 10, Timestamp: now.Add(-20 * time.Minute)},
+		{PluginName: "metrics-widgets", Method: "GET", Path: "/widgets/9", StatusCode: 404, DurationMs: 3, Timestamp: now.Add(-30 * time.Minute)},
+		{PluginName: "metrics-widgets", Method: "GET", Path: "/widgets", StatusCode: 500, DurationMs: 16, Timestamp: now.Add(-40 * time.Minute)},
+		// Outside the window
+		{PluginName: "metrics-widgets", Method: "GET", Path: "/widgets", StatusCode: 500, DurationMs: 900, Timestamp: now.Add(-2 * time.Hour)},
+	}
+	for _, l := range logs {
+		if err := s.LogRequest(l); err != nil {
+			t.Fatalf("LogRequest: %v", err)
+		}
+	}
+	if _, err := s.db.Exec(`CREATE TABLE metrics_widgets (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := s.db.Exec(`INSERT INTO metrics_widgets DEFAULT VALUES`); err != nil {
+			t.Fatalf("failed to insert: %v", err)
+		}
+	}
+
+	metrics, err := s.GetPluginMetrics(since)
+	if err != nil {
+		t.Fatalf("GetPluginMetrics: %v", err)
+	}
+	byName := make(map[string]PluginMetrics)
+	for i, m := range metrics {
+		if i > 0 && metrics[i-1].Name > m.Name {
+			t.Errorf("metrics not sorted by name: %q before %q", metrics[i-1].Name, m.Name)
+		}
+		byName[m.Name] = m
+	}
+
+	widgets, ok := byName["metrics-widgets"]
+	if !ok {
+		t.Fatalf("expected metrics for metrics-widgets, got %+v", metrics)
+	}
+	if widgets.Requests != 4 || widgets.Errors != 2 || widgets.AvgLatencyMs != 8.25 {
+		t.Errorf("expected 4 requests, 2 errors, 8.25ms, got %+v", widgets)
+	}
+	if widgets.Resources["widgets"] != 3 {
+		t.Errorf("expected 3 widgets, got %v", widgets.Resources)
+	}
+	if _, ok := widgets.Resources["missing"]; ok {
+		t.Errorf("expected tables that don't exist to be skipped, got %v", widgets.Resources)
+	}
+
+	// Plugins without traffic are still listed
+	idle, ok := byName["metrics-idle"]
+	if !ok || idle.Requests != 0 || idle.AvgLatencyMs != 0 {
+		t.Errorf("expected zeroed metrics for metrics-idle, got %+v (listed %v)", idle, ok)
+	}
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Reconnecting the store to its database file without replacing the *sql.DB.
+// ABOUTME: Retires every open SQLite connection so plugins, request logs, and the admin UI follow the new file.
+
+package store
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// connector opens SQLite connections tagged with the generation they were
+// opened in. Bumping the generation retires every open connection, so
+// database/sql closes them instead of reusing them, and the next query opens
+// the file at the path afresh.
+type connector struct {
+	path       string
+	driver     *sqlite3.SQLiteDriver
+	generation atomic.Int64
+}
+
+func newConnector(path string) *connector {
+	return &connector{path: path, driver: &sqlite3.SQLiteDriver{}}
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	generation := c.generation.Load()
+	conn, err := c.driver.Open(c.path)
+	if err != nil {
+		return nil, err
+	}
+	return &generationConn{SQLiteConn: conn.(*sqlite3.SQLiteConn), connector: c, generation: generation}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// generationConn is a SQLite connection that stops being reused once its
+// connector moves on to a new generation
+type generationConn struct {
+	*sqlite3.SQLiteConn
+	connector  *connector
+	generation int64
+}
+
+// IsValid implements driver.Validator
+func (c *generationConn) IsValid() bool {
+	return c.generation == c.connector.generation.Load()
+}
+
+// isFile reports whether path names a database file, as opposed to an
+// in-memory database or a URI
+func isFile(path string) bool {
+	return path != "" && path != ":memory:" && !strings.HasPrefix(path, "file:")
+}
+
+// Reconnect retires every open connection and opens the database file at the
+// store's path again, re-applying the pragmas and any pending migrations;
+// a database without ISH's tables, plugin tables included, gets them all.
+// The *sql.DB returned by GetDB stays the same, so everything holding it
+// uses the new connections without being rebound. A missing file is an
+// error rather than a new empty database.
+func (s *Store) Reconnect() error {
+	if isFile(s.path) {
+		if _, err := os.Stat(s.path); err != nil {
+			return fmt.Errorf("database file unavailable: %w", err)
+		}
+	}
+
+	s.connector.generation.Add(1)
+	if err := s.configure(); err != nil {
+		return err
+	}
+	return s.migrate()
+}
@@ -4,8 +4,12 @@
 package store
 
 import (
+	"database/sql"
+	"encoding/base64"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -52,14 +56,70 @@ type RequestLog struct {
 	Error        string
 	RequestBody  string
 	ResponseBody string
+
+	// rawTimestamp is the stored timestamp text, used to build keyset cursors
+	rawTimestamp string
+}
+
+// Cursor returns the keyset position of this entry in the newest-first listing
+func (l *RequestLog) Cursor() *LogCursor {
+	return &LogCursor{Timestamp: l.rawTimestamp, ID: l.ID}
+}
+
+// LogCursor marks a position in the newest-first request log listing.
+// Pages after a cursor stay consistent while new requests are being logged,
+// unlike OFFSET pagination which shifts as rows are inserted.
+type LogCursor struct {
+	Timestamp string // stored timestamp text, compared the same way ORDER BY does
+	ID        int64  // tiebreaker for requests logged in the same instant
+}
+
+// Encode returns an opaque, URL-safe token for the cursor
+func (c *LogCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.ID, 10) + "|" + c.Timestamp))
+}
+
+// DecodeLogCursor parses a token produced by LogCursor.Encode
+func DecodeLogCursor(token string) (*LogCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log cursor: %w", err)
+	}
+	idStr, timestamp, ok := strings.Cut(string(raw), "|")
+	if !ok || timestamp == "" {
+		return nil, fmt.Errorf("invalid log cursor")
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log cursor: %w", err)
+	}
+	return &LogCursor{Timestamp: timestamp, ID: id}, nil
 }
 
-// LogRequest inserts a request log entry
+// LogRequest inserts a request log entry and sets its ID
 func (s *Store) LogRequest(log *RequestLog) error {
-	_, err := s.db.Exec(`
+	return insertRequestLog(s.db, log)
+}
+
+// LogRequestTx saves a request log entry as part of tx
+func (s *Store) LogRequestTx(tx *sql.Tx, log *RequestLog) error {
+	return insertRequestLog(tx, log)
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func insertRequestLog(db execer, log *RequestLog) error {
+	result, err := db.Exec(`
 		INSERT INTO request_logs (timestamp, plugin_name, method, path, status_code, duration_ms, user_id, ip_address, user_agent, error, request_body, response_body)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, log.Timestamp, log.PluginName, log.Method, log.Path, log.StatusCode, log.DurationMs, log.UserID, log.IPAddress, log.UserAgent, log.Error, log.RequestBody, log.ResponseBody)
+	if err != nil {
+		return err
+	}
+	log.ID, err = result.LastInsertId()
 	return err
 }
 
@@ -72,6 +132,8 @@ type RequestLogQuery struct {
 	PathPrefix string
 	StatusCode int
 	UserID     string
+	Since      time.Time  // only return logs at or after this time, when set
+	After      *LogCursor // only return logs older than this position
 }
 
 // RequestLogStats represents aggregate statistics
@@ -96,34 +158,17 @@ func (s *Store) GetRequestLogs(q *RequestLogQuery) ([]*RequestLog, error) {
 
 	query := `SELECT id, timestamp, COALESCE(plugin_name, ''), method, path, status_code, duration_ms,
 	          COALESCE(user_id, ''), COALESCE(ip_address, ''), COALESCE(user_agent, ''), COALESCE(error, ''),
-	          COALESCE(request_body, ''), COALESCE(response_body, '')
+	          COALESCE(request_body, ''), COALESCE(response_body, ''), CAST(timestamp AS TEXT)
 	          FROM request_logs WHERE 1=1`
-	args := []any{}
+	where, args := requestLogFilters(q)
+	query += where
 
-	if q.PluginName != "" {
-		query += " AND plugin_name = ?"
-		args = append(args, q.PluginName)
-	}
-	if q.Method != "" {
-		query += " AND method = ?"
-		args = append(args, q.Method)
-	}
-	if q.PathPrefix != "" {
-		// Use helper function to escape SQL LIKE pattern
-		escaped := escapeSQLLike(q.PathPrefix)
-		query += " AND path LIKE ? ESCAPE '\\'"
-		args = append(args, escaped+"%")
-	}
-	if q.StatusCode > 0 {
-		query += " AND status_code = ?"
-		args = append(args, q.StatusCode)
-	}
-	if q.UserID != "" {
-		query += " AND user_id = ?"
-		args = append(args, q.UserID)
+	if q.After != nil {
+		query += " AND (timestamp < ? OR (timestamp = ? AND id < ?))"
+		args = append(args, q.After.Timestamp, q.After.Timestamp, q.After.ID)
 	}
 
-	query += " ORDER BY timestamp DESC LIMIT ? OFFSET ?"
+	query += " ORDER BY timestamp DESC, id DESC LIMIT ? OFFSET ?"
 	args = append(args, q.Limit, q.Offset)
 
 	rows, err := s.db.Query(query, args...)
@@ -138,7 +183,7 @@ func (s *Store) GetRequestLogs(q *RequestLogQuery) ([]*RequestLog, error) {
 		var timestamp string
 		if err := rows.Scan(&entry.ID, &timestamp, &entry.PluginName, &entry.Method, &entry.Path, &entry.StatusCode,
 			&entry.DurationMs, &entry.UserID, &entry.IPAddress, &entry.UserAgent, &entry.Error,
-			&entry.RequestBody, &entry.ResponseBody); err != nil {
+			&entry.RequestBody, &entry.ResponseBody, &entry.rawTimestamp); err != nil {
 			return nil, err
 		}
 
@@ -163,6 +208,106 @@ func (s *Store) GetRequestLogs(q *RequestLogQuery) ([]*RequestLog, error) {
 	return logs, nil
 }
 
+// GetRequestLog returns a single request log by ID, or sql.ErrNoRows
+func (s *Store) GetRequestLog(id int64) (*RequestLog, error) {
+	entry := &RequestLog{}
+	var timestamp string
+	err := s.db.QueryRow(`SELECT id, timestamp, COALESCE(plugin_name, ''), method, path, status_code, duration_ms,
+	          COALESCE(user_id, ''), COALESCE(ip_address, ''), COALESCE(user_agent, ''), COALESCE(error, ''),
+	          COALESCE(request_body, ''), COALESCE(response_body, ''), CAST(timestamp AS TEXT)
+	          FROM request_logs WHERE id = ?`, id).Scan(&entry.ID, &timestamp, &entry.PluginName, &entry.Method, &entry.Path,
+		&entry.StatusCode, &entry.DurationMs, &entry.UserID, &entry.IPAddress, &entry.UserAgent, &entry.Error,
+		&entry.RequestBody, &entry.ResponseBody, &entry.rawTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedTime, err := parseTimestamp(timestamp)
+	if err != nil {
+		log.Printf("Error parsing timestamp '%s' for request log ID %d: %v", timestamp, entry.ID, err)
+	} else {
+		entry.Timestamp = parsedTime
+	}
+	return entry, nil
+}
+
+// requestLogFilters builds the WHERE conditions shared by log listing and counting
+func requestLogFilters(q *RequestLogQuery) (string, []any) {
+	var where string
+	args := []any{}
+
+	if q.PluginName != "" {
+		where += " AND plugin_name = ?"
+		args = append(args, q.PluginName)
+	}
+	if q.Method != "" {
+		where += " AND method = ?"
+		args = append(args, q.Method)
+	}
+	if q.PathPrefix != "" {
+		// Use helper function to escape SQL LIKE pattern
+		escaped := escapeSQLLike(q.PathPrefix)
+		where += " AND path LIKE ? ESCAPE '\\'"
+		args = append(args, escaped+"%")
+	}
+	if q.StatusCode > 0 {
+		where += " AND status_code = ?"
+		args = append(args, q.StatusCode)
+	}
+	if q.UserID != "" {
+		where += " AND user_id = ?"
+		args = append(args, q.UserID)
+	}
+	if !q.Since.IsZero() {
+		where += " AND julianday(timestamp) >= julianday(?)"
+		args = append(args, q.Since.UTC().Format("2006-01-02 15:04:05.000"))
+	}
+	return where, args
+}
+
+// CountRequestLogs returns how many logs match the query's filters, ignoring limit, offset, and cursor
+func (s *Store) CountRequestLogs(q *RequestLogQuery) (int, error) {
+	where, args := requestLogFilters(q)
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM request_logs WHERE 1=1"+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetRequestLogsPaginated returns up to limit logs, newest first, starting after
+// cursor (or from the newest log when cursor is nil). The returned cursor points
+// at the next page and is nil on the last page.
+func (s *Store) GetRequestLogsPaginated(cursor *LogCursor, limit int) ([]RequestLog, *LogCursor, error) {
+	return s.GetFilteredRequestLogsPaginated(&RequestLogQuery{}, cursor, limit)
+}
+
+// GetFilteredRequestLogsPaginated pages like GetRequestLogsPaginated through
+// only the logs matching filter. Its limit, offset, and cursor are ignored.
+func (s *Store) GetFilteredRequestLogsPaginated(filter *RequestLogQuery, cursor *LogCursor, limit int) ([]RequestLog, *LogCursor, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	q := *filter
+	q.Limit, q.Offset, q.After = limit+1, 0, cursor
+	logs, err := s.GetRequestLogs(&q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var next *LogCursor
+	if len(logs) > limit {
+		logs = logs[:limit]
+		next = logs[limit-1].Cursor()
+	}
+
+	page := make([]RequestLog, len(logs))
+	for i, entry := range logs {
+		page[i] = *entry
+	}
+	return page, next, nil
+}
+
 // GetRequestLogStats returns aggregate statistics
 func (s *Store) GetRequestLogStats() (*RequestLogStats, error) {
 	stats := &RequestLogStats{}
@@ -4,6 +4,9 @@
 package store
 
 import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -419,6 +422,121 @@ func TestEscapeSQLLikeIntegration(t *testing.T) {
 	}
 }
 
+func TestGetRequestLogsPaginatedConcurrentWrites(t *testing.T) {
+	// File-backed so the writer goroutine and reader share one database across pooled connections
+	s, err := New(filepath.Join(t.TempDir(), "logs.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer s.Close()
+
+	// 35 existing logs, newest first by construction; pairs share a timestamp to exercise the id tiebreak
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 35; i++ {
+		ts := base.Add(-time.Duration(i/2) * time.Second)
+		if err := s.LogRequest(&RequestLog{Timestamp: ts, Method: "GET", Path: "/existing", StatusCode: 200}); err != nil {
+			t.Fatalf("LogRequest failed: %v", err)
+		}
+	}
+	expected, err := s.GetRequestLogs(&RequestLogQuery{Limit: 100})
+	if err != nil {
+		t.Fatalf("GetRequestLogs failed: %v", err)
+	}
+
+	page, next, err := s.GetRequestLogsPaginated(nil, 10)
+	if err != nil {
+		t.Fatalf("GetRequestLogsPaginated failed: %v", err)
+	}
+	seen := page
+
+	// Keep logging newer requests while the remaining pages are read
+	var written atomic.Int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 1; ; n++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			s.LogRequest(&RequestLog{Timestamp: base.Add(time.Duration(n) * time.Millisecond), Method: "POST", Path: "/concurrent", StatusCode: 201})
+			written.Add(1)
+		}
+	}()
+
+	for next != nil {
+		// Make sure at least one write lands between every page
+		for before := written.Load(); written.Load() == before; {
+			time.Sleep(time.Millisecond)
+		}
+		page, next, err = s.GetRequestLogsPaginated(next, 10)
+		if err != nil {
+			t.Fatalf("GetRequestLogsPaginated failed: %v", err)
+		}
+		seen = append(seen, page...)
+	}
+	close(stop)
+	wg.Wait()
+
+	if written.Load() == 0 {
+		t.Fatal("expected concurrent writes during pagination")
+	}
+	if len(seen) != len(expected) {
+		t.Fatalf("Expected %d logs across pages, got %d", len(expected), len(seen))
+	}
+	for i := range expected {
+		if seen[i].ID != expected[i].ID {
+			t.Fatalf("Page order diverged at %d: expected id %d, got %d", i, expected[i].ID, seen[i].ID)
+		}
+		if seen[i].Path != "/existing" {
+			t.Errorf("Concurrent write %d leaked into older pages", seen[i].ID)
+		}
+	}
+}
+
+func TestGetRequestLogsPaginatedLastPage(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	for i := 0; i < 20; i++ {
+		s.LogRequest(&RequestLog{Timestamp: time.Now().Add(time.Duration(i) * time.Second), Method: "GET", Path: "/x", StatusCode: 200})
+	}
+
+	page, next, err := s.GetRequestLogsPaginated(nil, 10)
+	if err != nil || len(page) != 10 || next == nil {
+		t.Fatalf("Expected full first page with cursor, got %d logs, next=%v, err=%v", len(page), next, err)
+	}
+	page, next, err = s.GetRequestLogsPaginated(next, 10)
+	if err != nil || len(page) != 10 || next != nil {
+		t.Errorf("Expected exactly-full last page without cursor, got %d logs, next=%v, err=%v", len(page), next, err)
+	}
+
+	count, err := s.CountRequestLogs(&RequestLogQuery{PathPrefix: "/x"})
+	if err != nil || count != 20 {
+		t.Errorf("Expected count 20, got %d (%v)", count, err)
+	}
+}
+
+func TestLogCursorEncoding(t *testing.T) {
+	cursor := &LogCursor{Timestamp: "2025-01-01 12:00:00.5+00:00", ID: 42}
+	decoded, err := DecodeLogCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeLogCursor failed: %v", err)
+	}
+	if *decoded != *cursor {
+		t.Errorf("Round trip mismatch: %+v != %+v", decoded, cursor)
+	}
+
+	for _, bad := range []string{"", "!!!", "bm90LWEtY3Vyc29y"} {
+		if _, err := DecodeLogCursor(bad); err == nil {
+			t.Errorf("Expected error decoding %q", bad)
+		}
+	}
+}
+
 // Helper to setup a test database
 func setupTestDB(t *testing.T) *Store {
 	s, err := New(":memory:")
@@ -0,0 +1,155 @@
+// ABOUTME: Request log retention: pruning by age, a row cap, and size statistics.
+// ABOUTME: Keeps request_logs from growing without bound on long-running servers.
+
+package store
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// logCapTrigger is the trigger that enforces the maximum number of request logs
+const logCapTrigger = "request_logs_cap"
+
+// LogStats describes the request log table
+type LogStats struct {
+	TotalCount int        `json:"total_count"`
+	OldestLog  *time.Time `json:"oldest_log"`
+	NewestLog  *time.Time `json:"newest_log"`
+	// SizeBytes approximates the stored log data by summing the text of every column
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+// LogRetention configures automatic request log pruning
+type LogRetention struct {
+	MaxAge   time.Duration // logs older than this are deleted; 0 keeps logs forever
+	MaxRows  int           // at most this many logs are kept; 0 means no cap
+	Interval time.Duration // how often to prune after the initial run at startup
+}
+
+// PruneOldLogs deletes request logs recorded before olderThan and returns how many were deleted
+func (s *Store) PruneOldLogs(olderThan time.Time) (int64, error) {
+	result, err := s.db.Exec(
+		"DELETE FROM request_logs WHERE julianday(timestamp) < julianday(?)",
+		olderThan.UTC().Format("2006-01-02 15:04:05.000"),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SetMaxLogRows caps request_logs at max rows, deleting the oldest logs now
+// and on every insert beyond the cap. A max of 0 or less removes the cap.
+func (s *Store) SetMaxLogRows(max int) (int64, error) {
+	if _, err := s.db.Exec("DROP TRIGGER IF EXISTS " + logCapTrigger); err != nil {
+		return 0, err
+	}
+	if max <= 0 {
+		return 0, nil
+	}
+
+	// IDs increase with every insert, so keeping the newest max IDs keeps the newest max logs
+	result, err := s.db.Exec(`
+		DELETE FROM request_logs
+		WHERE id <= (SELECT COALESCE(MAX(id), 0) FROM request_logs) - ?
+	`, max)
+	if err != nil {
+		return 0, err
+	}
+	// Triggers can't take parameters, so the cap is part of the trigger body
+	_, err = s.db.Exec(fmt.Sprintf(`
+		CREATE TRIGGER %s AFTER INSERT ON request_logs
+		BEGIN
+			DELETE FROM request_logs WHERE id <= NEW.id - %d;
+		END
+	`, logCapTrigger, max))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetLogStats returns the number of request logs, their time span, and approximate size
+func (s *Store) GetLogStats() (*LogStats, error) {
+	stats := &LogStats{}
+	err := s.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(
+			length(method) + length(path) + COALESCE(length(plugin_name), 0) +
+			COALESCE(length(user_id), 0) + COALESCE(length(ip_address), 0) +
+			COALESCE(length(user_agent), 0) + COALESCE(length(error), 0) +
+			COALESCE(length(request_body), 0) + COALESCE(length(response_body), 0)
+		), 0)
+		FROM request_logs
+	`).Scan(&stats.TotalCount, &stats.SizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if stats.TotalCount == 0 {
+		return stats, nil
+	}
+
+	if stats.OldestLog, err = s.logTimestamp("ASC"); err != nil {
+		return nil, err
+	}
+	if stats.NewestLog, err = s.logTimestamp("DESC"); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// logTimestamp returns the timestamp of the first log in the given order
+func (s *Store) logTimestamp(order string) (*time.Time, error) {
+	var raw string
+	err := s.db.QueryRow("SELECT timestamp FROM request_logs ORDER BY timestamp " + order + ", id " + order + " LIMIT 1").Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseTimestamp(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// StartLogRetention applies the row cap and prunes old logs now, then prunes
+// again every Interval until the store is closed
+func (s *Store) StartLogRetention(policy LogRetention) error {
+	if _, err := s.SetMaxLogRows(policy.MaxRows); err != nil {
+		return fmt.Errorf("failed to cap request logs: %w", err)
+	}
+	if policy.MaxAge <= 0 {
+		return nil
+	}
+
+	s.pruneExpiredLogs(policy.MaxAge)
+	if policy.Interval <= 0 {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.closed:
+				return
+			case <-ticker.C:
+				s.pruneExpiredLogs(policy.MaxAge)
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *Store) pruneExpiredLogs(maxAge time.Duration) {
+	deleted, err := s.PruneOldLogs(time.Now().Add(-maxAge))
+	if err != nil {
+		log.Printf("Failed to prune request logs: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Pruned %d request logs older than %s", deleted, maxAge)
+	}
+}
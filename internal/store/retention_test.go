@@ -0,0 +1,143 @@
+// ABOUTME: Tests for request log retention.
+// ABOUTME: Covers pruning by age, the row cap trigger, log stats, and the background pruner.
+
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func logAt(t *testing.T, s *Store, path string, at time.Time) {
+	t.Helper()
+	if err := s.LogRequest(&RequestLog{Timestamp: at, Method: "GET", Path: path, StatusCode: 200}); err != nil {
+		t.Fatalf("Failed to log request: %v", err)
+	}
+}
+
+func TestPruneOldLogs(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	now := time.Now()
+	logAt(t, s, "/old", now.Add(-10*24*time.Hour))
+	logAt(t, s, "/older", now.Add(-30*24*time.Hour))
+	logAt(t, s, "/recent", now.Add(-time.Hour))
+
+	deleted, err := s.PruneOldLogs(now.Add(-7 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("PruneOldLogs failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 deleted logs, got %d", deleted)
+	}
+
+	logs, _ := s.GetRequestLogs(&RequestLogQuery{})
+	if len(logs) != 1 || logs[0].Path != "/recent" {
+		t.Errorf("Expected only /recent to remain, got %+v", logs)
+	}
+}
+
+func TestSetMaxLogRows(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		logAt(t, s, "/before-cap", base.Add(time.Duration(i)*time.Second))
+	}
+
+	deleted, err := s.SetMaxLogRows(3)
+	if err != nil {
+		t.Fatalf("SetMaxLogRows failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected the 2 oldest logs to be deleted, got %d", deleted)
+	}
+
+	// New logs beyond the cap push out the oldest
+	logAt(t, s, "/after-cap", time.Now())
+	stats, err := s.GetLogStats()
+	if err != nil {
+		t.Fatalf("GetLogStats failed: %v", err)
+	}
+	if stats.TotalCount != 3 {
+		t.Errorf("Expected 3 logs under the cap, got %d", stats.TotalCount)
+	}
+	if !stats.OldestLog.Equal(base.Add(3 * time.Second)) {
+		t.Errorf("Expected the oldest remaining log at %v, got %v", base.Add(3*time.Second), stats.OldestLog)
+	}
+
+	// Removing the cap stops trimming
+	if _, err := s.SetMaxLogRows(0); err != nil {
+		t.Fatalf("SetMaxLogRows(0) failed: %v", err)
+	}
+	logAt(t, s, "/uncapped", time.Now())
+	if stats, _ := s.GetLogStats(); stats.TotalCount != 4 {
+		t.Errorf("Expected 4 logs without a cap, got %d", stats.TotalCount)
+	}
+}
+
+func TestGetLogStats(t *testing.T) {
+	s := setupTestDB(t)
+	defer s.Close()
+
+	stats, err := s.GetLogStats()
+	if err != nil {
+		t.Fatalf("GetLogStats failed: %v", err)
+	}
+	if stats.TotalCount != 0 || stats.OldestLog != nil || stats.NewestLog != nil || stats.SizeBytes != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+
+	first := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	last := first.Add(48 * time.Hour)
+	logAt(t, s, "/a", last)
+	logAt(t, s, "/bb", first)
+
+	stats, _ = s.GetLogStats()
+	if stats.TotalCount != 2 || !stats.OldestLog.Equal(first) || !stats.NewestLog.Equal(last) {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	// "GET" + "/a" + "GET" + "/bb"
+	if stats.SizeBytes != 11 {
+		t.Errorf("Expected 11 bytes of log data, got %d", stats.SizeBytes)
+	}
+}
+
+func TestStartLogRetention(t *testing.T) {
+	// A file database, since the pruner runs on its own connection
+	s, err := New(filepath.Join(t.TempDir(), "retention.db"))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	logAt(t, s, "/stale-at-startup", time.Now().Add(-8*24*time.Hour))
+	policy := LogRetention{MaxAge: 7 * 24 * time.Hour, MaxRows: 100, Interval: 10 * time.Millisecond}
+	if err := s.StartLogRetention(policy); err != nil {
+		t.Fatalf("StartLogRetention failed: %v", err)
+	}
+	if stats, _ := s.GetLogStats(); stats.TotalCount != 0 {
+		t.Fatalf("Expected stale logs pruned at startup, got %d", stats.TotalCount)
+	}
+
+	// Logs that age past the limit while running are pruned on the next tick
+	logAt(t, s, "/stale-later", time.Now().Add(-8*24*time.Hour))
+	logAt(t, s, "/fresh", time.Now())
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stats, err := s.GetLogStats()
+		if err != nil {
+			t.Fatalf("GetLogStats failed: %v", err)
+		}
+		if stats.TotalCount == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the background pruner to leave 1 log, got %d", stats.TotalCount)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -7,40 +7,94 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/2389/ish/internal/migrations"
 )
 
-// Migration version constants
+// Migration version constants, matching the versions in migrations.All
 const (
-	MigrationV1 = 1 // Initial schema with request_logs table
-	MigrationV2 = 2 // Add performance indexes for aggregation and filtering queries
+	MigrationV1  = 1  // Create request_logs table and indexes
+	MigrationV2  = 2  // Add performance indexes for aggregation and filtering queries
+	MigrationV3  = 3  // Create Discord tables
+	MigrationV4  = 4  // Create GitHub tables
+	MigrationV5  = 5  // Create Google tables
+	MigrationV6  = 6  // Create Home Assistant tables
+	MigrationV7  = 7  // Create HubSpot tables
+	MigrationV8  = 8  // Create Jira tables
+	MigrationV9  = 9  // Create Linear tables
+	MigrationV10 = 10 // Create Notion tables
+	MigrationV11 = 11 // Create OAuth tables
+	MigrationV12 = 12 // Create Salesforce tables
+	MigrationV13 = 13 // Create SendGrid tables
+	MigrationV14 = 14 // Create Slack tables
+	MigrationV15 = 15 // Create Twilio tables
+	MigrationV16 = 16 // Create Zendesk tables
+	MigrationV17 = 17 // Add keyset pagination index for request logs
+	MigrationV18 = 18 // Add history_id to gmail_messages
+	MigrationV19 = 19 // Add delivery outcome to twilio_webhook_queue
+	MigrationV20 = 20 // Add columns to GitHub tables from older versions
+	MigrationV21 = 21 // Add retry queue columns to github_webhook_deliveries
+	MigrationV22 = 22 // Add columns to Twilio tables from older versions
+	MigrationV23 = 23 // Add color_id to calendar_events
+	MigrationV24 = 24 // Create audit_log table
+	MigrationV25 = 25 // Create idempotency_keys table
+	MigrationV26 = 26 // Create google_secrets table
+	MigrationV27 = 27 // Track GitHub fork parents in github_repositories.fork_of
 )
 
 // CurrentSchemaVersion is the target version for the database schema
-const CurrentSchemaVersion = MigrationV2
+const CurrentSchemaVersion = MigrationV27
 
 type Store struct {
 	db *sql.DB
+
+	// path is the database file, checked by Ping
+	path string
+	// connector opens db's connections; Reconnect retires them
+	connector *connector
+	// file is the database file the current connections opened, so Ping
+	// can tell when it has been replaced
+	file atomic.Pointer[os.FileInfo]
+
+	// closed stops background work such as log retention when the store is closed
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
 func New(dbPath string) (*Store, error) {
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		return nil, err
-	}
-
-	// Verify connection works
-	if err := db.Ping(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
+	conn := newConnector(dbPath)
+	db := sql.OpenDB(conn)
 
 	// Configure connection pooling
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(0) // Connections don't expire
 
+	s := &Store{db: db, path: dbPath, connector: conn, closed: make(chan struct{})}
+	if err := s.configure(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// configure checks the database answers, enables foreign keys and WAL mode,
+// and records which file it opened
+func (s *Store) configure() error {
+	// Verify connection works
+	if err := s.db.Ping(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
 	// Enable foreign keys and WAL mode
 	pragmas := []string{
 		"PRAGMA foreign_keys = ON",
@@ -50,25 +104,52 @@ func New(dbPath string) (*Store, error) {
 	}
 
 	for _, pragma := range pragmas {
-		if _, err := db.Exec(pragma); err != nil {
-			db.Close()
-			return nil, err
+		if _, err := s.db.Exec(pragma); err != nil {
+			return err
 		}
 	}
 
-	s := &Store{db: db}
-	if err := s.migrate(); err != nil {
-		db.Close()
-		return nil, err
+	if isFile(s.path) {
+		info, err := os.Stat(s.path)
+		if err != nil {
+			return fmt.Errorf("database file unavailable: %w", err)
+		}
+		s.file.Store(&info)
 	}
-
-	return s, nil
+	return nil
 }
 
 func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		if s.closed != nil {
+			close(s.closed)
+		}
+	})
 	return s.db.Close()
 }
 
+// Ping checks that the database answers a query and, for a database file,
+// that the file still exists and is the one the store opened. SQLite keeps
+// serving a deleted or replaced file from its open handle, so the query alone
+// can't tell.
+func (s *Store) Ping() error {
+	var one int
+	if err := s.db.QueryRow("SELECT 1").Scan(&one); err != nil {
+		return err
+	}
+	if !isFile(s.path) {
+		return nil
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("database file unavailable: %w", err)
+	}
+	if opened := s.file.Load(); opened != nil && !os.SameFile(*opened, info) {
+		return fmt.Errorf("database file %s was replaced", s.path)
+	}
+	return nil
+}
+
 // GetDB returns the underlying database connection for plugins
 func (s *Store) GetDB() *sql.DB {
 	return s.db
@@ -76,12 +157,6 @@ func (s *Store) GetDB() *sql.DB {
 
 // migrate runs all pending migrations
 func (s *Store) migrate() error {
-	// Create schema_migrations table if it doesn't exist
-	if err := s.createMigrationsTable(); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
-
-	// Get current schema version
 	currentVersion, err := s.getCurrentMigrationVersion()
 	if err != nil {
 		return fmt.Errorf("failed to get current migration version: %w", err)
@@ -89,125 +164,22 @@ func (s *Store) migrate() error {
 
 	log.Printf("Database schema version: %d, target version: %d", currentVersion, CurrentSchemaVersion)
 
-	// Run migrations in order
-	if currentVersion < MigrationV1 {
-		if err := s.migrateV1(); err != nil {
-			return fmt.Errorf("migration v1 failed: %w", err)
-		}
-	}
-
-	if currentVersion < MigrationV2 {
-		if err := s.migrateV2(); err != nil {
-			return fmt.Errorf("migration v2 failed: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// createMigrationsTable creates the schema_migrations tracking table
-func (s *Store) createMigrationsTable() error {
-	_, err := s.db.Exec(`
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version INTEGER PRIMARY KEY,
-			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			description TEXT
-		)
-	`)
+	_, err = migrations.Apply(s.db, migrations.All)
 	return err
 }
 
-// getCurrentMigrationVersion retrieves the current schema version
+// getCurrentMigrationVersion retrieves the highest applied schema version,
+// or 0 for a database that has never been migrated
 func (s *Store) getCurrentMigrationVersion() (int, error) {
 	var version int
 	err := s.db.QueryRow(`
 		SELECT COALESCE(MAX(version), 0) FROM schema_migrations
 	`).Scan(&version)
 	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return 0, nil
+		}
 		return 0, err
 	}
 	return version, nil
 }
-
-// recordMigration records a completed migration
-func (s *Store) recordMigration(version int, description string) error {
-	_, err := s.db.Exec(`
-		INSERT INTO schema_migrations (version, description)
-		VALUES (?, ?)
-	`, version, description)
-	return err
-}
-
-// migrateV1 creates the initial request_logs table and indexes
-func (s *Store) migrateV1() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS request_logs (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		plugin_name TEXT DEFAULT '',
-		method TEXT NOT NULL,
-		path TEXT NOT NULL,
-		status_code INTEGER,
-		duration_ms INTEGER,
-		user_id TEXT,
-		ip_address TEXT,
-		user_agent TEXT,
-		request_body TEXT,
-		response_body TEXT,
-		error TEXT
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_request_logs_timestamp ON request_logs(timestamp DESC);
-	CREATE INDEX IF NOT EXISTS idx_request_logs_path ON request_logs(path);
-	CREATE INDEX IF NOT EXISTS idx_request_logs_status ON request_logs(status_code);
-	CREATE INDEX IF NOT EXISTS idx_request_logs_plugin ON request_logs(plugin_name);
-	`
-	if _, err := s.db.Exec(schema); err != nil {
-		return err
-	}
-
-	if err := s.recordMigration(MigrationV1, "Create request_logs table and indexes"); err != nil {
-		return err
-	}
-
-	log.Printf("Applied migration v%d: Create request_logs table and indexes", MigrationV1)
-	return nil
-}
-
-// migrateV2 adds performance-optimized composite indexes for aggregation and filtering queries
-func (s *Store) migrateV2() error {
-	indexes := []string{
-		// Composite index for GetTopEndpoints query (GROUP BY path, ORDER BY COUNT)
-		// Optimizes aggregation queries by allowing SQLite to use index to count occurrences per path
-		"CREATE INDEX IF NOT EXISTS idx_request_logs_path_count ON request_logs(path, status_code)",
-
-		// Composite index for GetPluginRequestCount and GetPluginErrorRate queries
-		// Optimizes filtering by plugin_name AND timestamp range queries used in metrics
-		"CREATE INDEX IF NOT EXISTS idx_request_logs_plugin_timestamp ON request_logs(plugin_name, timestamp DESC)",
-
-		// Composite index for GetRequestLogs multi-column filtering
-		// Optimizes queries filtering by plugin_name AND method AND status_code together
-		"CREATE INDEX IF NOT EXISTS idx_request_logs_plugin_method_status ON request_logs(plugin_name, method, status_code)",
-
-		// Index for user_id filtering and uniqueness checks in GetRequestLogStats
-		// WHERE clause filters to non-empty user IDs to keep index smaller
-		"CREATE INDEX IF NOT EXISTS idx_request_logs_user_id ON request_logs(user_id) WHERE user_id != ''",
-
-		// Index for date-based filtering in GetRequestLogStats
-		// Supports timestamp-based queries with status code filtering
-		"CREATE INDEX IF NOT EXISTS idx_request_logs_timestamp_status ON request_logs(timestamp DESC, status_code)",
-	}
-
-	for _, indexSQL := range indexes {
-		if _, err := s.db.Exec(indexSQL); err != nil {
-			return fmt.Errorf("failed to create index: %w", err)
-		}
-	}
-
-	if err := s.recordMigration(MigrationV2, "Add composite indexes for aggregation and filtering queries"); err != nil {
-		return err
-	}
-
-	log.Printf("Applied migration v%d: Add composite indexes for query optimization", MigrationV2)
-	return nil
-}
@@ -1,12 +1,16 @@
 // ABOUTME: Tests for core SQLite store initialization and schema migrations.
-// ABOUTME: Verifies database setup and request_logs table creation.
+// ABOUTME: Verifies database setup, request_logs table creation, and health pings.
 
 package store
 
 import (
+	"database/sql"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/2389/ish/internal/migrations"
 )
 
 func TestNewStore_CreatesDatabase(t *testing.T) {
@@ -128,6 +132,17 @@ func TestMigrationRecords(t *testing.T) {
 	if versions[1] != MigrationV2 {
 		t.Errorf("Expected second migration to be V%d, got V%d", MigrationV2, versions[1])
 	}
+
+	// Verify V3 exists
+	if len(versions) < 3 || versions[2] != MigrationV3 {
+		t.Errorf("Expected third migration to be V%d, got %v", MigrationV3, versions)
+	}
+}
+
+func TestCurrentSchemaVersionMatchesMigrations(t *testing.T) {
+	if CurrentSchemaVersion != migrations.Latest() {
+		t.Errorf("CurrentSchemaVersion is %d but the latest migration is v%d", CurrentSchemaVersion, migrations.Latest())
+	}
 }
 
 func TestParseTimestamp_MultipleFormats(t *testing.T) {
@@ -273,3 +288,175 @@ func TestRequestLogRoundtrip_TimezonePreservation(t *testing.T) {
 		}
 	}
 }
+
+func TestStore_Ping(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "ping.db")
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Ping(); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if err := os.Remove(dbPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ping(); err == nil {
+		t.Error("Ping() should fail once the database file is deleted")
+	}
+}
+
+func TestStore_Reconnect(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "reconnect.db")
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer s.Close()
+
+	if err := os.Remove(dbPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reconnect(); err == nil {
+		t.Error("Reconnect() should fail while the database file is missing")
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatalf("Reconnect() should not create an empty database, Stat() error = %v", err)
+	}
+
+	// Another database moved into place is a replacement Ping notices
+	other, err := New(filepath.Join(dir, "other.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	other.Close()
+	if err := os.Rename(filepath.Join(dir, "other.db"), dbPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ping(); err == nil {
+		t.Error("Ping() should fail while connected to the replaced file")
+	}
+	if err := s.Reconnect(); err != nil {
+		t.Fatalf("Reconnect() error = %v", err)
+	}
+	if err := s.Ping(); err != nil {
+		t.Errorf("Ping() after Reconnect() error = %v", err)
+	}
+
+	// An empty database gets every table back, plugin tables included
+	if err := os.Remove(dbPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dbPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reconnect(); err != nil {
+		t.Fatalf("Reconnect() to an empty database error = %v", err)
+	}
+	for _, table := range []string{"request_logs", "github_repositories", "gmail_messages", "twilio_messages"} {
+		var count int
+		s.GetDB().QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+		if count != 1 {
+			t.Errorf("Expected %s after Reconnect() to an empty database", table)
+		}
+	}
+}
+
+// schemaOf lists each table's columns and each index in the store's database
+func schemaOf(t *testing.T, s *Store) (map[string]map[string]bool, map[string]bool) {
+	t.Helper()
+	rows, err := s.db.Query("SELECT type, name FROM sqlite_master WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	tables, indexes := map[string]map[string]bool{}, map[string]bool{}
+	for rows.Next() {
+		var kind, name string
+		if err := rows.Scan(&kind, &name); err != nil {
+			t.Fatalf("Failed to scan schema: %v", err)
+		}
+		if kind == "index" {
+			indexes[name] = true
+		} else {
+			tables[name] = map[string]bool{}
+		}
+	}
+	rows.Close()
+
+	for table, columns := range tables {
+		rows, err := s.db.Query("SELECT name FROM pragma_table_info(?)", table)
+		if err != nil {
+			t.Fatalf("Failed to read %s columns: %v", table, err)
+		}
+		for rows.Next() {
+			var column string
+			rows.Scan(&column)
+			columns[column] = true
+		}
+		rows.Close()
+	}
+	return tables, indexes
+}
+
+func TestNewUpgradesBaselineDatabase(t *testing.T) {
+	dir := t.TempDir()
+
+	// A database written by ish before plugin tables were migrations,
+	// recorded at v2
+	schema, err := os.ReadFile(filepath.Join("testdata", "baseline_v2.sql"))
+	if err != nil {
+		t.Fatalf("Failed to read baseline schema: %v", err)
+	}
+	baselinePath := filepath.Join(dir, "baseline.db")
+	db, err := sql.Open("sqlite3", baselinePath)
+	if err != nil {
+		t.Fatalf("Failed to open baseline database: %v", err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatalf("Failed to create baseline database: %v", err)
+	}
+	db.Close()
+
+	upgraded, err := New(baselinePath)
+	if err != nil {
+		t.Fatalf("Failed to upgrade baseline database: %v", err)
+	}
+	defer upgraded.Close()
+
+	version, err := upgraded.getCurrentMigrationVersion()
+	if err != nil {
+		t.Fatalf("Failed to get current migration version: %v", err)
+	}
+	if version != CurrentSchemaVersion {
+		t.Errorf("Expected schema version %d after upgrade, got %d", CurrentSchemaVersion, version)
+	}
+
+	fresh, err := New(filepath.Join(dir, "fresh.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer fresh.Close()
+
+	// The upgraded database has everything a fresh one has
+	wantTables, wantIndexes := schemaOf(t, fresh)
+	gotTables, gotIndexes := schemaOf(t, upgraded)
+	for table, columns := range wantTables {
+		if gotTables[table] == nil {
+			t.Errorf("Expected table %s after upgrade", table)
+			continue
+		}
+		for column := range columns {
+			if !gotTables[table][column] {
+				t.Errorf("Expected %s.%s after upgrade", table, column)
+			}
+		}
+	}
+	for index := range wantIndexes {
+		if !gotIndexes[index] {
+			t.Errorf("Expected index %s after upgrade", index)
+		}
+	}
+}
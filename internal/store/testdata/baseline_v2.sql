@@ -0,0 +1,539 @@
+-- Schema of a database created by ish before versioned plugin migrations,
+-- dumped after 'ish seed' with schema_migrations at v2.
+CREATE TABLE schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			description TEXT
+		);
+CREATE TABLE request_logs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		plugin_name TEXT DEFAULT '',
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		status_code INTEGER,
+		duration_ms INTEGER,
+		user_id TEXT,
+		ip_address TEXT,
+		user_agent TEXT,
+		request_body TEXT,
+		response_body TEXT,
+		error TEXT
+	);
+CREATE INDEX idx_request_logs_timestamp ON request_logs(timestamp DESC);
+CREATE INDEX idx_request_logs_path ON request_logs(path);
+CREATE INDEX idx_request_logs_status ON request_logs(status_code);
+CREATE INDEX idx_request_logs_plugin ON request_logs(plugin_name);
+CREATE INDEX idx_request_logs_path_count ON request_logs(path, status_code);
+CREATE INDEX idx_request_logs_plugin_timestamp ON request_logs(plugin_name, timestamp DESC);
+CREATE INDEX idx_request_logs_plugin_method_status ON request_logs(plugin_name, method, status_code);
+CREATE INDEX idx_request_logs_user_id ON request_logs(user_id) WHERE user_id != '';
+CREATE INDEX idx_request_logs_timestamp_status ON request_logs(timestamp DESC, status_code);
+CREATE TABLE github_users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			login TEXT UNIQUE NOT NULL,
+			name TEXT,
+			email TEXT,
+			avatar_url TEXT,
+			type TEXT DEFAULT 'User',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_users_login ON github_users(login);
+CREATE TABLE github_tokens (
+			token TEXT PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			token_type TEXT DEFAULT 'personal',
+			scopes TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES github_users(id)
+		);
+CREATE INDEX idx_tokens_user ON github_tokens(user_id);
+CREATE TABLE github_repositories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			owner_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			full_name TEXT NOT NULL,
+			description TEXT,
+			private INTEGER DEFAULT 0,
+			default_branch TEXT DEFAULT 'main',
+			fork INTEGER DEFAULT 0,
+			archived INTEGER DEFAULT 0,
+			disabled INTEGER DEFAULT 0,
+			stargazers_count INTEGER DEFAULT 0,
+			watchers_count INTEGER DEFAULT 0,
+			forks_count INTEGER DEFAULT 0,
+			open_issues_count INTEGER DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			pushed_at TIMESTAMP,
+			FOREIGN KEY (owner_id) REFERENCES github_users(id),
+			UNIQUE(owner_id, name)
+		);
+CREATE INDEX idx_repos_owner ON github_repositories(owner_id);
+CREATE INDEX idx_repos_full_name ON github_repositories(full_name);
+CREATE TABLE github_branches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repo_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			commit_sha TEXT NOT NULL,
+			protected INTEGER DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+			UNIQUE(repo_id, name)
+		);
+CREATE INDEX idx_branches_repo ON github_branches(repo_id);
+CREATE TABLE github_commits (
+			sha TEXT PRIMARY KEY,
+			repo_id INTEGER NOT NULL,
+			author_login TEXT,
+			author_name TEXT NOT NULL,
+			author_email TEXT NOT NULL,
+			committer_login TEXT,
+			message TEXT NOT NULL,
+			parent_sha TEXT,
+			tree_sha TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+		);
+CREATE INDEX idx_commits_repo ON github_commits(repo_id);
+CREATE INDEX idx_commits_parent ON github_commits(parent_sha);
+CREATE TABLE github_issues (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repo_id INTEGER NOT NULL,
+			number INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			body TEXT,
+			state TEXT DEFAULT 'open',
+			state_reason TEXT,
+			user_id INTEGER NOT NULL,
+			assignee_ids TEXT,
+			label_ids TEXT,
+			milestone_id INTEGER,
+			locked INTEGER DEFAULT 0,
+			comments_count INTEGER DEFAULT 0,
+			is_pull_request INTEGER DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			closed_at TIMESTAMP,
+			FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES github_users(id),
+			UNIQUE(repo_id, number)
+		);
+CREATE INDEX idx_issues_repo ON github_issues(repo_id);
+CREATE INDEX idx_issues_state ON github_issues(state);
+CREATE INDEX idx_issues_number ON github_issues(repo_id, number);
+CREATE TABLE github_pull_requests (
+			issue_id INTEGER PRIMARY KEY,
+			head_repo_id INTEGER NOT NULL,
+			head_ref TEXT NOT NULL,
+			base_repo_id INTEGER NOT NULL,
+			base_ref TEXT NOT NULL,
+			merged INTEGER DEFAULT 0,
+			mergeable INTEGER DEFAULT 1,
+			rebaseable INTEGER DEFAULT 1,
+			merge_commit_sha TEXT,
+			merged_at TIMESTAMP,
+			merged_by_id INTEGER,
+			draft INTEGER DEFAULT 0,
+			review_comments_count INTEGER DEFAULT 0,
+			commits_count INTEGER DEFAULT 1,
+			additions INTEGER DEFAULT 0,
+			deletions INTEGER DEFAULT 0,
+			changed_files INTEGER DEFAULT 0,
+			FOREIGN KEY (issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+			FOREIGN KEY (head_repo_id) REFERENCES github_repositories(id),
+			FOREIGN KEY (base_repo_id) REFERENCES github_repositories(id),
+			FOREIGN KEY (merged_by_id) REFERENCES github_users(id)
+		);
+CREATE INDEX idx_prs_head_repo ON github_pull_requests(head_repo_id);
+CREATE INDEX idx_prs_base_repo ON github_pull_requests(base_repo_id);
+CREATE TABLE github_comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			issue_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			body TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES github_users(id)
+		);
+CREATE INDEX idx_comments_issue ON github_comments(issue_id);
+CREATE INDEX idx_comments_created ON github_comments(created_at);
+CREATE TABLE github_reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pull_request_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			state TEXT NOT NULL,
+			body TEXT,
+			commit_sha TEXT,
+			submitted_at TIMESTAMP,
+			dismissed_at TIMESTAMP,
+			FOREIGN KEY (pull_request_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES github_users(id)
+		);
+CREATE INDEX idx_reviews_pr ON github_reviews(pull_request_id);
+CREATE INDEX idx_reviews_state ON github_reviews(state);
+CREATE TABLE github_review_comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pull_request_id INTEGER NOT NULL,
+			review_id INTEGER,
+			user_id INTEGER NOT NULL,
+			body TEXT NOT NULL,
+			path TEXT NOT NULL,
+			position INTEGER,
+			commit_sha TEXT NOT NULL,
+			in_reply_to_id INTEGER,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (pull_request_id) REFERENCES github_issues(id) ON DELETE CASCADE,
+			FOREIGN KEY (review_id) REFERENCES github_reviews(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES github_users(id),
+			FOREIGN KEY (in_reply_to_id) REFERENCES github_review_comments(id)
+		);
+CREATE INDEX idx_review_comments_pr ON github_review_comments(pull_request_id);
+CREATE INDEX idx_review_comments_review ON github_review_comments(review_id);
+CREATE TABLE github_webhooks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repo_id INTEGER NOT NULL,
+			url TEXT NOT NULL,
+			content_type TEXT DEFAULT 'json',
+			secret TEXT,
+			events TEXT NOT NULL,
+			active INTEGER DEFAULT 1,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
+		);
+CREATE INDEX idx_webhooks_repo ON github_webhooks(repo_id);
+CREATE INDEX idx_webhooks_active ON github_webhooks(active);
+CREATE TABLE github_webhook_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			webhook_id INTEGER NOT NULL,
+			event_type TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			delivered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			status_code INTEGER,
+			error_message TEXT,
+			FOREIGN KEY (webhook_id) REFERENCES github_webhooks(id) ON DELETE CASCADE
+		);
+CREATE INDEX idx_deliveries_webhook ON github_webhook_deliveries(webhook_id);
+CREATE INDEX idx_deliveries_delivered ON github_webhook_deliveries(delivered_at DESC);
+CREATE TABLE oauth_tokens (
+		token TEXT PRIMARY KEY,
+		plugin_name TEXT NOT NULL,
+		user_id TEXT,
+		scopes TEXT,
+		expires_at TIMESTAMP,
+		refresh_token TEXT,
+		revoked BOOLEAN DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+CREATE TABLE sendgrid_accounts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		email TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+CREATE TABLE sendgrid_api_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		key TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		scopes TEXT NOT NULL DEFAULT 'mail.send',
+		last_used_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+CREATE INDEX idx_sendgrid_api_keys_key ON sendgrid_api_keys(key);
+CREATE INDEX idx_sendgrid_api_keys_account ON sendgrid_api_keys(account_id);
+CREATE TABLE sendgrid_messages (
+		id TEXT PRIMARY KEY,
+		account_id INTEGER NOT NULL,
+		from_email TEXT NOT NULL,
+		from_name TEXT,
+		to_email TEXT NOT NULL,
+		to_name TEXT,
+		subject TEXT,
+		text_content TEXT,
+		html_content TEXT,
+		status TEXT NOT NULL DEFAULT 'delivered',
+		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
+	);
+CREATE INDEX idx_sendgrid_messages_account ON sendgrid_messages(account_id);
+CREATE INDEX idx_sendgrid_messages_to_email ON sendgrid_messages(to_email);
+CREATE INDEX idx_sendgrid_messages_sent_at ON sendgrid_messages(sent_at);
+CREATE TABLE sendgrid_suppressions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL,
+		email TEXT NOT NULL,
+		type TEXT NOT NULL CHECK(type IN ('bounce', 'block', 'spam_report')),
+		reason TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE,
+		UNIQUE(account_id, email, type)
+	);
+CREATE INDEX idx_sendgrid_suppressions_account ON sendgrid_suppressions(account_id);
+CREATE INDEX idx_sendgrid_suppressions_email ON sendgrid_suppressions(email);
+CREATE INDEX idx_sendgrid_suppressions_type ON sendgrid_suppressions(type);
+CREATE TABLE twilio_accounts (
+			account_sid TEXT PRIMARY KEY,
+			auth_token TEXT NOT NULL,
+			friendly_name TEXT,
+			status TEXT DEFAULT 'active',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE TABLE twilio_phone_numbers (
+			sid TEXT PRIMARY KEY,
+			account_sid TEXT NOT NULL,
+			phone_number TEXT NOT NULL,
+			friendly_name TEXT,
+			voice_url TEXT,
+			voice_method TEXT DEFAULT 'POST',
+			sms_url TEXT,
+			sms_method TEXT DEFAULT 'POST',
+			status_callback TEXT,
+			status_callback_method TEXT DEFAULT 'POST',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+		);
+CREATE INDEX idx_phone_numbers_account ON twilio_phone_numbers(account_sid);
+CREATE TABLE twilio_messages (
+			sid TEXT PRIMARY KEY,
+			account_sid TEXT NOT NULL,
+			from_number TEXT NOT NULL,
+			to_number TEXT NOT NULL,
+			body TEXT,
+			status TEXT DEFAULT 'queued',
+			direction TEXT,
+			date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			date_sent TIMESTAMP,
+			date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			num_segments INTEGER DEFAULT 1,
+			price REAL,
+			price_unit TEXT DEFAULT 'USD',
+			FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+		);
+CREATE INDEX idx_messages_account ON twilio_messages(account_sid);
+CREATE INDEX idx_messages_status ON twilio_messages(status);
+CREATE INDEX idx_messages_date ON twilio_messages(date_created);
+CREATE TABLE twilio_calls (
+			sid TEXT PRIMARY KEY,
+			account_sid TEXT NOT NULL,
+			from_number TEXT NOT NULL,
+			to_number TEXT NOT NULL,
+			status TEXT DEFAULT 'initiated',
+			direction TEXT,
+			duration INTEGER,
+			date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			answered_by TEXT,
+			FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+		);
+CREATE INDEX idx_calls_account ON twilio_calls(account_sid);
+CREATE INDEX idx_calls_status ON twilio_calls(status);
+CREATE TABLE twilio_webhook_configs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			account_sid TEXT NOT NULL,
+			resource_type TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			url TEXT NOT NULL,
+			method TEXT DEFAULT 'POST',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
+		);
+CREATE INDEX idx_webhook_configs_account ON twilio_webhook_configs(account_sid);
+CREATE TABLE twilio_webhook_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			resource_sid TEXT NOT NULL,
+			webhook_url TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			scheduled_at TIMESTAMP NOT NULL,
+			delivered_at TIMESTAMP,
+			status TEXT DEFAULT 'pending',
+			attempts INTEGER DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_webhook_queue_schedule ON twilio_webhook_queue(scheduled_at, status);
+CREATE TABLE homeassistant_instances (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		url TEXT NOT NULL,
+		token TEXT NOT NULL,
+		name TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+CREATE TABLE homeassistant_entities (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		entity_id TEXT NOT NULL,
+		friendly_name TEXT,
+		domain TEXT NOT NULL,
+		platform TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id),
+		UNIQUE(instance_id, entity_id)
+	);
+CREATE TABLE homeassistant_states (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		entity_id TEXT NOT NULL,
+		state TEXT NOT NULL,
+		attributes TEXT, -- JSON
+		last_changed DATETIME NOT NULL,
+		last_updated DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id)
+	);
+CREATE TABLE homeassistant_service_calls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance_id INTEGER NOT NULL,
+		domain TEXT NOT NULL,
+		service TEXT NOT NULL,
+		service_data TEXT, -- JSON
+		entity_id TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		called_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id)
+	);
+CREATE INDEX idx_entities_instance ON homeassistant_entities(instance_id);
+CREATE INDEX idx_states_instance ON homeassistant_states(instance_id);
+CREATE INDEX idx_states_entity ON homeassistant_states(entity_id);
+CREATE INDEX idx_service_calls_instance ON homeassistant_service_calls(instance_id);
+CREATE TABLE gmail_messages (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			thread_id TEXT,
+			label_ids TEXT,
+			snippet TEXT,
+			internal_date INTEGER,
+			payload TEXT,
+			history_id INTEGER,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_gmail_messages_user_id ON gmail_messages(user_id);
+CREATE INDEX idx_gmail_messages_thread_id ON gmail_messages(thread_id);
+CREATE INDEX idx_gmail_messages_internal_date ON gmail_messages(internal_date);
+CREATE INDEX idx_gmail_messages_label_ids ON gmail_messages(label_ids);
+CREATE TABLE gmail_threads (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			snippet TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_gmail_threads_user_id ON gmail_threads(user_id);
+CREATE TABLE gmail_attachments (
+			id TEXT PRIMARY KEY,
+			message_id TEXT NOT NULL,
+			filename TEXT,
+			mime_type TEXT,
+			size INTEGER,
+			data TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_gmail_attachments_message_id ON gmail_attachments(message_id);
+CREATE TABLE calendars (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			summary TEXT,
+			sync_token TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_calendars_user_id ON calendars(user_id);
+CREATE TABLE calendar_events (
+			id TEXT PRIMARY KEY,
+			calendar_id TEXT NOT NULL,
+			summary TEXT,
+			description TEXT,
+			start_time TEXT,
+			end_time TEXT,
+			attendees TEXT,
+			location TEXT,
+			organizer_email TEXT,
+			organizer_name TEXT,
+			recurrence TEXT,
+			updated_at TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_calendar_events_calendar_id ON calendar_events(calendar_id);
+CREATE INDEX idx_calendar_events_start_time ON calendar_events(start_time);
+CREATE TABLE people (
+			resource_name TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			data TEXT,
+			updated_at TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_people_user_id ON people(user_id);
+CREATE TABLE sync_tokens (
+			id TEXT PRIMARY KEY,
+			resource_type TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			token TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_sync_tokens_resource_user ON sync_tokens(resource_type, user_id);
+CREATE TABLE task_lists (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			title TEXT,
+			updated_at TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_task_lists_user_id ON task_lists(user_id);
+CREATE TABLE tasks (
+			id TEXT PRIMARY KEY,
+			list_id TEXT NOT NULL,
+			title TEXT,
+			notes TEXT,
+			due TEXT,
+			status TEXT DEFAULT 'needsAction',
+			completed TEXT,
+			updated_at TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+CREATE INDEX idx_tasks_list_id ON tasks(list_id);
+CREATE INDEX idx_tasks_status ON tasks(status);
+CREATE TABLE discord_webhooks (
+			id TEXT PRIMARY KEY,
+			token TEXT NOT NULL,
+			type INTEGER DEFAULT 1,
+			name TEXT,
+			avatar TEXT,
+			channel_id TEXT,
+			guild_id TEXT,
+			application_id TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			deleted_at TIMESTAMP,
+			UNIQUE(id, token)
+		);
+CREATE TABLE discord_webhook_messages (
+			id TEXT PRIMARY KEY,
+			webhook_id TEXT NOT NULL,
+			content TEXT,
+			username TEXT,
+			avatar_url TEXT,
+			embeds TEXT,
+			components TEXT,
+			attachments TEXT,
+			thread_id TEXT,
+			flags INTEGER,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			edited_at TIMESTAMP,
+			deleted_at TIMESTAMP,
+			FOREIGN KEY (webhook_id) REFERENCES discord_webhooks(id) ON DELETE CASCADE
+		);
+CREATE INDEX idx_webhook_messages_webhook_id ON discord_webhook_messages(webhook_id);
+CREATE INDEX idx_webhook_messages_created_at ON discord_webhook_messages(created_at DESC);
+
+INSERT INTO schema_migrations (version, description) VALUES
+	(1, 'Create request_logs table and indexes'),
+	(2, 'Add composite indexes for aggregation and filtering queries');
@@ -0,0 +1,117 @@
+// ABOUTME: Shared audit log of plugin data mutations
+// ABOUTME: Plugin stores append a row for every create, update, and delete so unexpected state changes can be traced
+
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry is one recorded mutation of a plugin resource
+type AuditEntry struct {
+	ID           int64  `json:"id"`
+	PluginName   string `json:"plugin_name"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	Action       string `json:"action"`
+	// Actor is the user making the change when the store knows it, e.g. an
+	// issue's creator or a mailbox owner; empty otherwise
+	Actor string `json:"actor"`
+	// Changes is marshaled to JSON when logged and read back as json.RawMessage
+	Changes   any       `json:"changes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditQuery filters ListAudit; empty fields match everything
+type AuditQuery struct {
+	PluginName   string
+	ResourceType string
+	Actor        string
+	Limit        int
+	Offset       int
+}
+
+// Execer runs a statement. *sql.DB and *sql.Tx both satisfy it, so an audit
+// row can be written in the same transaction as the change it records.
+type Execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// LogAudit appends a mutation to the audit log. The audit_log table is created
+// by the core migrations when the store opens.
+func LogAudit(db Execer, entry AuditEntry) error {
+	switch entry.Action {
+	case AuditCreate, AuditUpdate, AuditDelete:
+	default:
+		return fmt.Errorf("invalid audit action %q", entry.Action)
+	}
+
+	var changes sql.NullString
+	if entry.Changes != nil {
+		data, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit changes: %w", err)
+		}
+		changes = sql.NullString{String: string(data), Valid: true}
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO audit_log (plugin_name, resource_type, resource_id, action, actor, changes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, entry.PluginName, entry.ResourceType, entry.ResourceID, entry.Action, entry.Actor, changes, Now())
+	return err
+}
+
+// ListAudit returns audit entries matching the query, newest first
+func ListAudit(db *sql.DB, q AuditQuery) ([]AuditEntry, error) {
+	query := `SELECT id, plugin_name, resource_type, resource_id, action, actor, changes, created_at FROM audit_log WHERE 1=1`
+	var args []any
+	if q.PluginName != "" {
+		query += " AND plugin_name = ?"
+		args = append(args, q.PluginName)
+	}
+	if q.ResourceType != "" {
+		query += " AND resource_type = ?"
+		args = append(args, q.ResourceType)
+	}
+	if q.Actor != "" {
+		query += " AND actor = ?"
+		args = append(args, q.Actor)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, q.Offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var changes sql.NullString
+		if err := rows.Scan(&e.ID, &e.PluginName, &e.ResourceType, &e.ResourceID, &e.Action, &e.Actor, &changes, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if changes.Valid {
+			e.Changes = json.RawMessage(changes.String)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
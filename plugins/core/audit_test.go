@@ -0,0 +1,65 @@
+// ABOUTME: Tests for the shared audit log.
+// ABOUTME: Verifies entries are stored with their changes and filtered by plugin, resource type, and actor.
+
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/2389/ish/internal/migrations"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLogAuditAndList(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	defer db.Close()
+
+	entries := []AuditEntry{
+		{PluginName: "linear", ResourceType: "issue", ResourceID: "ENG-1", Action: AuditCreate, Actor: "alice", Changes: map[string]any{"title": "Bug"}},
+		{PluginName: "linear", ResourceType: "issue", ResourceID: "ENG-1", Action: AuditDelete, Actor: "bob"},
+		{PluginName: "jira", ResourceType: "issue", ResourceID: "PROJ-1", Action: AuditUpdate, Actor: "alice"},
+	}
+	for _, e := range entries {
+		if err := LogAudit(db, e); err != nil {
+			t.Fatalf("LogAudit failed: %v", err)
+		}
+	}
+
+	all, err := ListAudit(db, AuditQuery{})
+	if err != nil {
+		t.Fatalf("ListAudit failed: %v", err)
+	}
+	if len(all) != 3 || all[0].PluginName != "jira" {
+		t.Fatalf("Expected 3 entries newest first, got %+v", all)
+	}
+
+	linear, _ := ListAudit(db, AuditQuery{PluginName: "linear", ResourceType: "issue"})
+	if len(linear) != 2 || linear[0].Action != AuditDelete || linear[1].Action != AuditCreate {
+		t.Errorf("Expected linear delete then create, got %+v", linear)
+	}
+	var changes map[string]string
+	if err := json.Unmarshal(linear[1].Changes.(json.RawMessage), &changes); err != nil || changes["title"] != "Bug" {
+		t.Errorf("Expected changes to round-trip, got %v (%v)", linear[1].Changes, err)
+	}
+	if linear[0].Changes != nil {
+		t.Errorf("Expected no changes on the delete, got %v", linear[0].Changes)
+	}
+
+	alice, _ := ListAudit(db, AuditQuery{Actor: "alice"})
+	if len(alice) != 2 {
+		t.Errorf("Expected 2 entries by alice, got %d", len(alice))
+	}
+
+	if err := LogAudit(db, AuditEntry{PluginName: "linear", ResourceType: "issue", ResourceID: "x", Action: "archive"}); err == nil {
+		t.Error("Expected an error for an unknown action")
+	}
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Base path prefix that every route is mounted under.
+// ABOUTME: Lets ISH run behind a gateway that serves it at e.g. /ish; plugins use it when building absolute URLs.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// BasePathEnv names the environment variable that mounts every route under a prefix
+const BasePathEnv = "ISH_BASE_PATH"
+
+type basePathKey struct{}
+
+// BasePathFromEnv reads ISH_BASE_PATH, normalized to a leading slash and no
+// trailing slash. It returns "" when unset or "/", meaning routes are at the root.
+func BasePathFromEnv() (string, error) {
+	value := strings.TrimSpace(os.Getenv(BasePathEnv))
+	if value == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(value, "?#{}*") {
+		return "", fmt.Errorf("invalid %s %q: expected a path such as /ish", BasePathEnv, value)
+	}
+	cleaned := path.Clean("/" + value)
+	if cleaned == "/" {
+		return "", nil
+	}
+	return cleaned, nil
+}
+
+// WithBasePath records the prefix routes are mounted under in each request's context
+func WithBasePath(prefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), basePathKey{}, prefix)))
+		})
+	}
+}
+
+// BasePath returns the prefix the request's routes are mounted under, or ""
+// when they are at the root. Append it to the scheme and host when building
+// absolute URLs that point back at ISH.
+func BasePath(r *http.Request) string {
+	prefix, _ := r.Context().Value(basePathKey{}).(string)
+	return prefix
+}
+
+// IsAdminPath reports whether the request is for the admin UI or a plugin's
+// admin routes, which live under /admin after the base path
+func IsAdminPath(r *http.Request) bool {
+	p := strings.TrimPrefix(r.URL.Path, BasePath(r))
+	return p == "/admin" || strings.HasPrefix(p, "/admin/")
+}
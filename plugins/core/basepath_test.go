@@ -0,0 +1,74 @@
+// ABOUTME: Tests for the ISH_BASE_PATH prefix.
+// ABOUTME: Covers normalizing the variable and reading the prefix back from a request.
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasePathFromEnv(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"", ""},
+		{"/", ""},
+		{"/ish", "/ish"},
+		{"ish/", "/ish"},
+		{" /api/v1/ ", "/api/v1"},
+	}
+	for _, tt := range tests {
+		t.Setenv(BasePathEnv, tt.value)
+		got, err := BasePathFromEnv()
+		if err != nil || got != tt.want {
+			t.Errorf("BasePathFromEnv() with %q = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+
+	t.Setenv(BasePathEnv, "/{api}")
+	if _, err := BasePathFromEnv(); err == nil {
+		t.Error("BasePathFromEnv() with a route pattern should fail")
+	}
+}
+
+func TestWithBasePath(t *testing.T) {
+	var got string
+	handler := WithBasePath("/ish")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = BasePath(r)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ish/healthz", nil))
+	if got != "/ish" {
+		t.Errorf("BasePath() = %q, want /ish", got)
+	}
+
+	if prefix := BasePath(httptest.NewRequest("GET", "/healthz", nil)); prefix != "" {
+		t.Errorf("BasePath() without the middleware = %q, want empty", prefix)
+	}
+}
+
+func TestIsAdminPath(t *testing.T) {
+	tests := []struct {
+		prefix string
+		path   string
+		want   bool
+	}{
+		{"", "/admin", true},
+		{"", "/admin/github/dispatch-events", true},
+		{"", "/administrator", false},
+		{"", "/repos/alice/admin", false},
+		{"/ish", "/ish/admin/slack/simulate/message", true},
+		{"/ish", "/ish/user", false},
+	}
+	for _, tt := range tests {
+		var got bool
+		handler := WithBasePath(tt.prefix)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = IsAdminPath(r)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+		if got != tt.want {
+			t.Errorf("IsAdminPath(%q under %q) = %v, want %v", tt.path, tt.prefix, got, tt.want)
+		}
+	}
+}
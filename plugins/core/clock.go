@@ -0,0 +1,93 @@
+// ABOUTME: Injectable clock so stores can produce deterministic timestamps.
+// ABOUTME: Defaults to the real clock; tests and demos can freeze time.
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FrozenTimeEnv names the environment variable that freezes the clock at an RFC 3339 time
+const FrozenTimeEnv = "ISH_FROZEN_TIME"
+
+// Clock provides the current time to stores
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock reads the system clock
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// FrozenClock always returns the same instant until it is moved explicitly
+type FrozenClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewFrozenClock returns a clock stopped at t
+func NewFrozenClock(t time.Time) *FrozenClock {
+	return &FrozenClock{t: t}
+}
+
+func (c *FrozenClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Set moves the clock to t
+func (c *FrozenClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+// Advance moves the clock forward by d
+func (c *FrozenClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+var (
+	clock   Clock = RealClock{}
+	clockMu sync.RWMutex
+)
+
+// SetClock replaces the package-level clock used by stores without their own.
+// Passing nil restores the real clock.
+func SetClock(c Clock) {
+	clockMu.Lock()
+	defer clockMu.Unlock()
+	if c == nil {
+		c = RealClock{}
+	}
+	clock = c
+}
+
+// Now returns the current time from the package-level clock
+func Now() time.Time {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+	return clock.Now()
+}
+
+// ClockFromEnv returns a frozen clock if ISH_FROZEN_TIME is set, or nil otherwise
+func ClockFromEnv() (Clock, error) {
+	value := os.Getenv(FrozenTimeEnv)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: expected RFC 3339 (e.g. 2025-01-01T09:00:00Z): %w", FrozenTimeEnv, value, err)
+	}
+	return NewFrozenClock(t), nil
+}
@@ -0,0 +1,65 @@
+// ABOUTME: Tests for the injectable clock.
+// ABOUTME: Covers frozen clocks, the package-level override, and ISH_FROZEN_TIME parsing.
+
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrozenClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	c := NewFrozenClock(start)
+
+	if !c.Now().Equal(start) {
+		t.Fatalf("expected %v, got %v", start, c.Now())
+	}
+
+	c.Advance(time.Hour)
+	if want := start.Add(time.Hour); !c.Now().Equal(want) {
+		t.Errorf("expected %v after Advance, got %v", want, c.Now())
+	}
+
+	c.Set(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("expected %v after Set, got %v", start, c.Now())
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	frozen := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	SetClock(NewFrozenClock(frozen))
+	t.Cleanup(func() { SetClock(nil) })
+
+	if !Now().Equal(frozen) {
+		t.Fatalf("expected package clock at %v, got %v", frozen, Now())
+	}
+
+	SetClock(nil)
+	if Now().Equal(frozen) {
+		t.Error("expected SetClock(nil) to restore the real clock")
+	}
+}
+
+func TestClockFromEnv(t *testing.T) {
+	t.Setenv(FrozenTimeEnv, "")
+	c, err := ClockFromEnv()
+	if err != nil || c != nil {
+		t.Fatalf("expected no clock when unset, got %v, %v", c, err)
+	}
+
+	t.Setenv(FrozenTimeEnv, "2025-06-01T12:30:00Z")
+	c, err = ClockFromEnv()
+	if err != nil {
+		t.Fatalf("ClockFromEnv failed: %v", err)
+	}
+	if want := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC); !c.Now().Equal(want) {
+		t.Errorf("expected %v, got %v", want, c.Now())
+	}
+
+	t.Setenv(FrozenTimeEnv, "yesterday")
+	if _, err := ClockFromEnv(); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
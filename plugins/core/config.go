@@ -0,0 +1,175 @@
+// ABOUTME: Per-plugin configuration files loaded from the ISH config directory
+// ABOUTME: Defines PluginConfig, the optional Configurable interface, and get/set by key
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Seed densities a plugin config may name; they are the sizes Seed accepts
+var seedDensities = []string{"small", "medium", "large"}
+
+// DefaultSeedDensity is the seed size used when a plugin config names none
+const DefaultSeedDensity = "medium"
+
+// PluginConfig is a plugin's settings from {config dir}/plugins/{plugin}.json
+type PluginConfig struct {
+	Enabled        bool              `json:"enabled"`
+	SeedDensity    string            `json:"seed_density,omitempty"`
+	CustomSettings map[string]string `json:"custom_settings,omitempty"`
+}
+
+// Configurable is an optional interface for plugins that read settings from
+// their config file. Configure runs once at startup, before routes are registered.
+type Configurable interface {
+	Plugin
+	Configure(config PluginConfig) error
+}
+
+// DefaultPluginConfig is the config of a plugin without a config file
+func DefaultPluginConfig() PluginConfig {
+	return PluginConfig{Enabled: true}
+}
+
+// ConfigDir returns ISH_CONFIG_DIR, or ~/.config/ish when it is unset
+func ConfigDir() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("ISH_CONFIG_DIR")); dir != "" {
+		return filepath.Clean(dir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine config directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "ish"), nil
+}
+
+// PluginConfigPath is where a plugin's config file lives under dir
+func PluginConfigPath(dir, plugin string) string {
+	return filepath.Join(dir, "plugins", plugin+".json")
+}
+
+// LoadPluginConfig reads a plugin's config file from dir, returning the
+// default config when the file does not exist. Fields the file leaves out
+// keep their defaults.
+func LoadPluginConfig(dir, plugin string) (PluginConfig, error) {
+	config := DefaultPluginConfig()
+	path := PluginConfigPath(dir, plugin)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if err := validateSeedDensity(config.SeedDensity); err != nil {
+		return config, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// SavePluginConfig writes a plugin's config file under dir, creating the
+// plugins directory if needed
+func SavePluginConfig(dir, plugin string, config PluginConfig) error {
+	path := PluginConfigPath(dir, plugin)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// SeedSize returns the config's seed density, or DefaultSeedDensity when unset
+func (c PluginConfig) SeedSize() string {
+	if c.SeedDensity == "" {
+		return DefaultSeedDensity
+	}
+	return c.SeedDensity
+}
+
+// Get returns a setting by key: "enabled", "seed_density", or the name of a
+// custom setting
+func (c PluginConfig) Get(key string) (string, bool) {
+	switch key {
+	case "enabled":
+		return strconv.FormatBool(c.Enabled), true
+	case "seed_density":
+		return c.SeedSize(), true
+	}
+	value, ok := c.CustomSettings[key]
+	return value, ok
+}
+
+// Set changes a setting by key, validating enabled as a boolean and
+// seed_density as small, medium, or large. Other keys are custom settings.
+func (c *PluginConfig) Set(key, value string) error {
+	switch key {
+	case "enabled":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("enabled must be true or false, got %q", value)
+		}
+		c.Enabled = enabled
+		return nil
+	case "seed_density":
+		if err := validateSeedDensity(value); err != nil {
+			return err
+		}
+		c.SeedDensity = value
+		return nil
+	}
+	if key == "" {
+		return errors.New("setting name is required")
+	}
+	if c.CustomSettings == nil {
+		c.CustomSettings = make(map[string]string)
+	}
+	c.CustomSettings[key] = value
+	return nil
+}
+
+func validateSeedDensity(density string) error {
+	if density == "" {
+		return nil
+	}
+	for _, d := range seedDensities {
+		if density == d {
+			return nil
+		}
+	}
+	return fmt.Errorf("seed_density must be one of %s, got %q", strings.Join(seedDensities, ", "), density)
+}
+
+// ConfigurePlugins loads each plugin's config from dir, passes it to plugins
+// implementing Configurable, and returns the plugins that are enabled
+func ConfigurePlugins(dir string, plugins []Plugin) ([]Plugin, error) {
+	enabled := make([]Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		config, err := LoadPluginConfig(dir, plugin.Name())
+		if err != nil {
+			return nil, err
+		}
+		if !config.Enabled {
+			continue
+		}
+		if c, ok := plugin.(Configurable); ok {
+			if err := c.Configure(config); err != nil {
+				return nil, fmt.Errorf("failed to configure plugin %s: %w", plugin.Name(), err)
+			}
+		}
+		enabled = append(enabled, plugin)
+	}
+	return enabled, nil
+}
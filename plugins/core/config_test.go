@@ -0,0 +1,110 @@
+// ABOUTME: Tests for per-plugin configuration files.
+// ABOUTME: Covers loading defaults, saving, setting by key, and configuring plugins.
+
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// configurablePlugin records the config it was given
+type configurablePlugin struct {
+	mockPlugin
+	config *PluginConfig
+}
+
+func (p *configurablePlugin) Configure(config PluginConfig) error {
+	p.config = &config
+	return nil
+}
+
+func TestLoadPluginConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	config, err := LoadPluginConfig(dir, "github")
+	if err != nil {
+		t.Fatalf("LoadPluginConfig() without a file error = %v", err)
+	}
+	if !config.Enabled || config.SeedSize() != DefaultSeedDensity {
+		t.Errorf("missing file should give the default config, got %+v", config)
+	}
+
+	path := PluginConfigPath(dir, "github")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte(`{"seed_density": "large", "custom_settings": {"org": "acme"}}`), 0644)
+
+	config, err = LoadPluginConfig(dir, "github")
+	if err != nil {
+		t.Fatalf("LoadPluginConfig() error = %v", err)
+	}
+	if !config.Enabled {
+		t.Error("enabled should default to true when the file leaves it out")
+	}
+	if config.SeedDensity != "large" || config.CustomSettings["org"] != "acme" {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	os.WriteFile(path, []byte(`{"seed_density": "huge"}`), 0644)
+	if _, err := LoadPluginConfig(dir, "github"); err == nil {
+		t.Error("expected an error for an unknown seed density")
+	}
+}
+
+func TestPluginConfigSetAndSave(t *testing.T) {
+	dir := t.TempDir()
+
+	config := DefaultPluginConfig()
+	for key, value := range map[string]string{"enabled": "false", "seed_density": "small", "region": "eu"} {
+		if err := config.Set(key, value); err != nil {
+			t.Fatalf("Set(%q, %q) error = %v", key, value, err)
+		}
+	}
+	if err := config.Set("enabled", "maybe"); err == nil {
+		t.Error("expected an error for a non-boolean enabled")
+	}
+	if err := config.Set("seed_density", "huge"); err == nil {
+		t.Error("expected an error for an unknown seed density")
+	}
+
+	if err := SavePluginConfig(dir, "slack", config); err != nil {
+		t.Fatalf("SavePluginConfig() error = %v", err)
+	}
+	loaded, err := LoadPluginConfig(dir, "slack")
+	if err != nil {
+		t.Fatalf("LoadPluginConfig() error = %v", err)
+	}
+	for key, want := range map[string]string{"enabled": "false", "seed_density": "small", "region": "eu"} {
+		if got, ok := loaded.Get(key); !ok || got != want {
+			t.Errorf("Get(%q) = %q, %v, want %q", key, got, ok, want)
+		}
+	}
+	if _, ok := loaded.Get("missing"); ok {
+		t.Error("Get() of an unset custom setting should report false")
+	}
+}
+
+func TestConfigurePlugins(t *testing.T) {
+	dir := t.TempDir()
+	SavePluginConfig(dir, "configured", PluginConfig{Enabled: true, SeedDensity: "large", CustomSettings: map[string]string{"k": "v"}})
+	SavePluginConfig(dir, "disabled", PluginConfig{Enabled: false})
+
+	configured := &configurablePlugin{mockPlugin: mockPlugin{name: "configured"}}
+	disabled := &configurablePlugin{mockPlugin: mockPlugin{name: "disabled"}}
+	plain := &mockPlugin{name: "plain"}
+
+	enabled, err := ConfigurePlugins(dir, []Plugin{configured, disabled, plain})
+	if err != nil {
+		t.Fatalf("ConfigurePlugins() error = %v", err)
+	}
+	if len(enabled) != 2 || enabled[0] != configured || enabled[1] != plain {
+		t.Errorf("expected the configured and plain plugins enabled, got %v", enabled)
+	}
+	if configured.config == nil || configured.config.SeedDensity != "large" || configured.config.CustomSettings["k"] != "v" {
+		t.Errorf("Configure got %+v, want the saved config", configured.config)
+	}
+	if disabled.config != nil {
+		t.Error("disabled plugins should not be configured")
+	}
+}
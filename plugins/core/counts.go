@@ -0,0 +1,18 @@
+// ABOUTME: Optional CountedPlugin interface for admin navigation record counts
+// ABOUTME: Plugins name the tables whose row counts appear as badges in the admin UI
+
+package core
+
+// CountedPlugin is an optional interface for plugins that want record count
+// badges in the admin navigation
+type CountedPlugin interface {
+	Plugin
+	CountedTables() []CountedTable
+}
+
+// CountedTable names a table counted with COUNT(*) for an admin nav badge
+type CountedTable struct {
+	Key      string // name in the /admin/api/counts response, e.g. "gmail"
+	Table    string // table whose rows are counted
+	Resource string // schema resource slug the nav item links to
+}
@@ -0,0 +1,27 @@
+// ABOUTME: Optional DocumentAdmin interface for editing JSON-backed resources from the admin API
+// ABOUTME: Plugins expose whole resources as JSON documents so admin edits can use JSON Patch
+
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDocumentNotFound is returned by DocumentAdmin methods when no document has the given ID
+var ErrDocumentNotFound = errors.New("document not found")
+
+// ErrInvalidDocument is returned by PutDocument when an edited document no longer fits the resource
+var ErrInvalidDocument = errors.New("invalid document")
+
+// DocumentAdmin is an optional interface for plugins whose resources are stored
+// as JSON, letting the admin API edit arbitrary fields without per-field forms
+type DocumentAdmin interface {
+	Plugin
+	// DocumentTypes lists the resource types served as documents, e.g. "people"
+	DocumentTypes() []string
+	GetDocument(ctx context.Context, docType, id string) (map[string]any, error)
+	PutDocument(ctx context.Context, docType, id string, doc map[string]any) error
+	// ImmutablePaths lists the JSON Pointers edits may not touch, e.g. "/resourceName"
+	ImmutablePaths(docType string) []string
+}
@@ -0,0 +1,175 @@
+// ABOUTME: Dry-run support for mutating API requests.
+// ABOUTME: Runs a handler against a database handle whose writes are rolled back when it returns.
+
+package core
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// DryRunHeader requests a dry run, and marks the response to one
+const DryRunHeader = "X-Ish-Dry-Run"
+
+// IsDryRun reports whether r asks to be validated without persisting, with
+// ?dryRun=true or an X-Ish-Dry-Run: true header
+func IsDryRun(r *http.Request) bool {
+	for _, value := range []string{r.URL.Query().Get("dryRun"), r.Header.Get(DryRunHeader)} {
+		if dry, err := strconv.ParseBool(value); err == nil && dry {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleDryRun serves r with serve against a handle on db whose writes are all
+// rolled back afterwards, so the client gets the would-be response without
+// anything being persisted. The response carries X-Ish-Dry-Run: true.
+func HandleDryRun(db *sql.DB, w http.ResponseWriter, r *http.Request, serve func(dry *sql.DB, w http.ResponseWriter, r *http.Request)) {
+	w.Header().Set(DryRunHeader, "true")
+	err := WithRollback(r.Context(), db, func(dry *sql.DB) error {
+		serve(dry, w, r)
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "dry run failed: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WithRollback runs fn with a handle on one of db's connections inside a
+// transaction that is rolled back when fn returns. Transactions fn begins on
+// the handle become savepoints within it. The handle must not be used after
+// fn returns.
+func WithRollback(ctx context.Context, db *sql.DB, fn func(dry *sql.DB) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		inner, ok := driverConn.(driver.Conn)
+		if !ok {
+			return errors.New("dry run: unsupported database driver")
+		}
+		connector := &rollbackConnector{inner: inner}
+		if err := connector.exec(ctx, "BEGIN IMMEDIATE"); err != nil {
+			return fmt.Errorf("dry run: %w", err)
+		}
+		dry := sql.OpenDB(connector)
+		defer func() {
+			dry.Close()
+			connector.exec(context.Background(), "ROLLBACK")
+		}()
+		return fn(dry)
+	})
+}
+
+// rollbackConnector hands out views of a single driver connection that is
+// already inside a transaction. Closing a view leaves the connection open.
+type rollbackConnector struct {
+	inner driver.Conn
+
+	mu         sync.Mutex
+	savepoints int
+}
+
+func (c *rollbackConnector) Connect(context.Context) (driver.Conn, error) {
+	return &rollbackConn{connector: c}, nil
+}
+
+func (c *rollbackConnector) Driver() driver.Driver {
+	return rollbackDriver{}
+}
+
+func (c *rollbackConnector) exec(ctx context.Context, query string) error {
+	execer, ok := c.inner.(driver.ExecerContext)
+	if !ok {
+		return errors.New("dry run: database driver cannot execute statements")
+	}
+	_, err := execer.ExecContext(ctx, query, nil)
+	return err
+}
+
+func (c *rollbackConnector) nextSavepoint() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.savepoints++
+	return fmt.Sprintf("dry_run_%d", c.savepoints)
+}
+
+type rollbackDriver struct{}
+
+func (rollbackDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("dry run: connections come from WithRollback")
+}
+
+// rollbackConn forwards statements to the shared connection and turns
+// transactions into savepoints
+type rollbackConn struct {
+	connector *rollbackConnector
+}
+
+func (c *rollbackConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *rollbackConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.connector.inner.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.connector.inner.Prepare(query)
+}
+
+func (c *rollbackConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.connector.inner.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *rollbackConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.connector.inner.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *rollbackConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *rollbackConn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	name := c.connector.nextSavepoint()
+	if err := c.connector.exec(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &rollbackSavepoint{connector: c.connector, name: name}, nil
+}
+
+// Close leaves the shared connection to WithRollback
+func (c *rollbackConn) Close() error {
+	return nil
+}
+
+type rollbackSavepoint struct {
+	connector *rollbackConnector
+	name      string
+}
+
+func (s *rollbackSavepoint) Commit() error {
+	return s.connector.exec(context.Background(), "RELEASE "+s.name)
+}
+
+func (s *rollbackSavepoint) Rollback() error {
+	if err := s.connector.exec(context.Background(), "ROLLBACK TO "+s.name); err != nil {
+		return err
+	}
+	return s.connector.exec(context.Background(), "RELEASE "+s.name)
+}
@@ -0,0 +1,85 @@
+// ABOUTME: Tests for dry-run request handling.
+// ABOUTME: Verifies writes made through the dry-run handle, including committed transactions, are rolled back.
+
+package core
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestIsDryRun(t *testing.T) {
+	tests := []struct {
+		target string
+		header string
+		want   bool
+	}{
+		{"/send", "", false},
+		{"/send?dryRun=true", "", true},
+		{"/send?dryRun=false", "", false},
+		{"/send", "true", true},
+		{"/send", "nope", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", tt.target, nil)
+		if tt.header != "" {
+			r.Header.Set(DryRunHeader, tt.header)
+		}
+		if got := IsDryRun(r); got != tt.want {
+			t.Errorf("IsDryRun(%s, header %q) = %v, want %v", tt.target, tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestHandleDryRunRollsBack(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE notes (body TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/notes?dryRun=true", nil)
+	HandleDryRun(db, w, r, func(dry *sql.DB, w http.ResponseWriter, r *http.Request) {
+		if _, err := dry.Exec(`INSERT INTO notes (body) VALUES ('plain')`); err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+		tx, err := dry.Begin()
+		if err != nil {
+			t.Fatalf("Begin failed: %v", err)
+		}
+		tx.Exec(`INSERT INTO notes (body) VALUES ('committed')`)
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+		tx, _ = dry.Begin()
+		tx.Exec(`INSERT INTO notes (body) VALUES ('rolled back')`)
+		tx.Rollback()
+
+		var count int
+		dry.QueryRow(`SELECT COUNT(*) FROM notes`).Scan(&count)
+		if count != 2 {
+			t.Errorf("Expected the dry run to see its own 2 writes, got %d", count)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if w.Code != http.StatusCreated || w.Header().Get(DryRunHeader) != "true" {
+		t.Fatalf("Expected 201 with %s: true, got %d %v", DryRunHeader, w.Code, w.Header())
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM notes`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no rows after the dry run, got %d", count)
+	}
+}
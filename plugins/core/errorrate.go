@@ -0,0 +1,111 @@
+// ABOUTME: Random error injection for resilience testing.
+// ABOUTME: Fails a configured fraction of a plugin's requests with a 500, reproducibly under a fixed seed.
+
+package core
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorRateEnvPrefix prefixes the per-plugin error rate variables, e.g. ISH_ERROR_RATE_GITHUB=0.1
+const ErrorRateEnvPrefix = "ISH_ERROR_RATE_"
+
+// RandomSeedEnv names the environment variable that fixes the seed of ISH's random choices
+const RandomSeedEnv = "ISH_RANDOM_SEED"
+
+// InjectedErrorHeader marks responses failed on purpose by error injection
+const InjectedErrorHeader = "X-Ish-Injected-Error"
+
+// ErrorBodyPlugin is implemented by plugins whose real API reports server
+// errors in a shape other than {"message": "..."}
+type ErrorBodyPlugin interface {
+	InjectedErrorBody() string
+}
+
+const defaultInjectedErrorBody = `{"message":"Internal Server Error"}`
+
+// ErrorRateFromEnv returns the fraction of requests to fail for the named
+// plugin, from ISH_ERROR_RATE_{NAME}. Unset means 0.
+func ErrorRateFromEnv(name string) (float64, error) {
+	key := ErrorRateEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("invalid %s %q: expected a fraction between 0 and 1", key, value)
+	}
+	return rate, nil
+}
+
+// NewRand returns a random source for one consumer. With ISH_RANDOM_SEED set,
+// each name gets its own stream that is the same on every run; otherwise the
+// source is seeded from the time.
+func NewRand(name string) (*rand.Rand, error) {
+	value := os.Getenv(RandomSeedEnv)
+	if value == "" {
+		return rand.New(rand.NewSource(time.Now().UnixNano())), nil
+	}
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: expected an integer", RandomSeedEnv, value)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return rand.New(rand.NewSource(seed ^ int64(h.Sum64()))), nil
+}
+
+// InjectErrors returns middleware that answers a rate fraction of requests
+// with a 500 and body instead of passing them on. Admin routes are never
+// failed, so the admin UI keeps working while a plugin's API is degraded.
+func InjectErrors(rate float64, rng *rand.Rand, body string) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	fail := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return rng.Float64() < rate
+	}
+	if body == "" {
+		body = defaultInjectedErrorBody
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsAdminPath(r) || !fail() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(InjectedErrorHeader, "true")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(body))
+		})
+	}
+}
+
+// PluginErrorInjection returns the error injection middleware configured for
+// plugin, or nil when its error rate is 0
+func PluginErrorInjection(plugin Plugin) (func(http.Handler) http.Handler, error) {
+	rate, err := ErrorRateFromEnv(plugin.Name())
+	if err != nil || rate == 0 {
+		return nil, err
+	}
+	rng, err := NewRand("errors:" + plugin.Name())
+	if err != nil {
+		return nil, err
+	}
+	var body string
+	if p, ok := plugin.(ErrorBodyPlugin); ok {
+		body = p.InjectedErrorBody()
+	}
+	return InjectErrors(rate, rng, body), nil
+}
@@ -0,0 +1,78 @@
+// ABOUTME: Tests for random error injection.
+// ABOUTME: Verifies rate parsing, that a fixed seed fails the same requests on every run, and that admin routes are spared.
+
+package core
+
+import (
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorRateFromEnv(t *testing.T) {
+	t.Setenv("ISH_ERROR_RATE_GITHUB", "")
+	if rate, err := ErrorRateFromEnv("github"); err != nil || rate != 0 {
+		t.Errorf("unset rate = %v, %v, want 0", rate, err)
+	}
+	t.Setenv("ISH_ERROR_RATE_GITHUB", "0.25")
+	if rate, err := ErrorRateFromEnv("github"); err != nil || rate != 0.25 {
+		t.Errorf("rate = %v, %v, want 0.25", rate, err)
+	}
+	for _, bad := range []string{"1.5", "-0.1", "often"} {
+		t.Setenv("ISH_ERROR_RATE_GITHUB", bad)
+		if _, err := ErrorRateFromEnv("github"); err == nil {
+			t.Errorf("rate %q should be rejected", bad)
+		}
+	}
+}
+
+func TestInjectErrorsIsReproducibleWithSeed(t *testing.T) {
+	t.Setenv(RandomSeedEnv, "7")
+	failures := func() []bool {
+		rng, err := NewRand("errors:github")
+		if err != nil {
+			t.Fatalf("NewRand() error = %v", err)
+		}
+		handler := InjectErrors(0.5, rng, "")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		var failed []bool
+		for range 50 {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+			failed = append(failed, rr.Code == http.StatusInternalServerError)
+		}
+		return failed
+	}
+
+	first, second := failures(), failures()
+	count := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("request %d failed differently across runs with the same seed", i)
+		}
+		if first[i] {
+			count++
+		}
+	}
+	if count == 0 || count == len(first) {
+		t.Errorf("expected about half of the requests to fail at rate 0.5, got %d of %d", count, len(first))
+	}
+}
+
+func TestInjectErrorsSkipsAdminRoutes(t *testing.T) {
+	inject := InjectErrors(1, rand.New(rand.NewSource(1)), "")
+	handler := WithBasePath("/ish")(inject(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	for path, want := range map[string]int{
+		"/ish/admin/github/dispatch-events": http.StatusOK,
+		"/ish/admin":                        http.StatusOK,
+		"/ish/administrators":               http.StatusInternalServerError,
+		"/ish/repos/alice/demo":             http.StatusInternalServerError,
+	} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rr.Code, want)
+		}
+	}
+}
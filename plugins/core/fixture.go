@@ -0,0 +1,140 @@
+// ABOUTME: Fixture files for `ish seed --from`, describing exact records to create
+// ABOUTME: Parses and validates fixtures and defines the optional FixtureImporter interface
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// FixtureImporter is an optional interface for plugins that can create the
+// records a fixture describes. Importers take only the sections they own and
+// report what they created in SeedData.Records, keyed by fixture section.
+type FixtureImporter interface {
+	Plugin
+	ImportFixture(ctx context.Context, fixture *Fixture) (SeedData, error)
+}
+
+// Fixture is the exact data a scenario starts from. Every section is optional.
+type Fixture struct {
+	Emails   []FixtureEmail   `json:"emails"`
+	Events   []FixtureEvent   `json:"events"`
+	Contacts []FixtureContact `json:"contacts"`
+	Repos    []FixtureRepo    `json:"repos"`
+	Issues   []FixtureIssue   `json:"issues"`
+}
+
+// FixtureEmail is a Gmail message in the default mailbox. Labels default to
+// INBOX, and a zero Date means the time of import.
+type FixtureEmail struct {
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	Labels  []string  `json:"labels"`
+	Date    time.Time `json:"date"`
+}
+
+// FixtureEvent is an event on the primary calendar
+type FixtureEvent struct {
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+// FixtureContact is a person in the default user's contacts
+type FixtureContact struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// FixtureRepo is a GitHub repository, created along with its owner
+type FixtureRepo struct {
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+}
+
+// FixtureIssue is an issue in a repository named "owner/name". Author
+// defaults to the repository owner and State to "open".
+type FixtureIssue struct {
+	Repo   string `json:"repo"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+	Author string `json:"author"`
+}
+
+// ParseFixture reads a fixture, rejecting unknown fields and records missing
+// what they need, so typos fail loudly instead of seeding partial data
+func ParseFixture(r io.Reader) (*Fixture, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var fixture Fixture
+	if err := dec.Decode(&fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture: %w", err)
+	}
+	if err := fixture.Validate(); err != nil {
+		return nil, err
+	}
+	return &fixture, nil
+}
+
+// Validate checks every record, returning all problems found
+func (f *Fixture) Validate() error {
+	var errs []error
+	invalid := func(section string, i int, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s[%d]: %s", section, i, fmt.Sprintf(format, args...)))
+	}
+
+	for i, email := range f.Emails {
+		if email.From == "" {
+			invalid("emails", i, "from is required")
+		}
+	}
+	for i, event := range f.Events {
+		if event.Summary == "" {
+			invalid("events", i, "summary is required")
+		}
+		if event.Start.IsZero() || event.End.IsZero() {
+			invalid("events", i, "start and end are required")
+		} else if !event.End.After(event.Start) {
+			invalid("events", i, "end must be after start")
+		}
+	}
+	for i, contact := range f.Contacts {
+		if contact.Name == "" && contact.Email == "" {
+			invalid("contacts", i, "name or email is required")
+		}
+	}
+	for i, repo := range f.Repos {
+		if repo.Owner == "" || repo.Name == "" {
+			invalid("repos", i, "owner and name are required")
+		}
+	}
+	for i, issue := range f.Issues {
+		if owner, name, ok := strings.Cut(issue.Repo, "/"); !ok || owner == "" || name == "" {
+			invalid("issues", i, "repo must be \"owner/name\", got %q", issue.Repo)
+		}
+		if issue.Title == "" {
+			invalid("issues", i, "title is required")
+		}
+		if issue.State != "" && issue.State != "open" && issue.State != "closed" {
+			invalid("issues", i, "state must be \"open\" or \"closed\", got %q", issue.State)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid fixture: %w", errors.Join(errs...))
+	}
+	return nil
+}
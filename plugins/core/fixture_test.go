@@ -0,0 +1,56 @@
+// ABOUTME: Tests for fixture parsing.
+// ABOUTME: Verifies fixtures decode as written and that malformed records are rejected with their position.
+
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFixture(t *testing.T) {
+	fixture, err := ParseFixture(strings.NewReader(`{
+		"emails": [{"from": "bob@example.com", "subject": "Quarterly report", "labels": ["INBOX", "UNREAD"]}],
+		"events": [{"summary": "Standup", "start": "2025-03-03T09:00:00Z", "end": "2025-03-03T09:15:00Z"}],
+		"contacts": [{"name": "Bob", "email": "bob@example.com"}],
+		"repos": [{"owner": "acme", "name": "api"}],
+		"issues": [{"repo": "acme/api", "title": "Crash on startup", "state": "closed"}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseFixture() error = %v", err)
+	}
+	if len(fixture.Emails) != 1 || fixture.Emails[0].Subject != "Quarterly report" || len(fixture.Emails[0].Labels) != 2 {
+		t.Errorf("unexpected emails: %+v", fixture.Emails)
+	}
+	if fixture.Events[0].End.Sub(fixture.Events[0].Start).Minutes() != 15 {
+		t.Errorf("unexpected event times: %+v", fixture.Events[0])
+	}
+	if fixture.Repos[0].Owner != "acme" || fixture.Issues[0].State != "closed" {
+		t.Errorf("unexpected GitHub records: %+v %+v", fixture.Repos, fixture.Issues)
+	}
+}
+
+func TestParseFixtureInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    string
+	}{
+		{"not JSON", `{"emails": [`, "invalid fixture"},
+		{"unknown section", `{"tweets": []}`, `unknown field "tweets"`},
+		{"unknown field", `{"emails": [{"from": "a@example.com", "subjet": "Typo"}]}`, `unknown field "subjet"`},
+		{"email without sender", `{"emails": [{"from": "a@example.com"}, {"subject": "Hi"}]}`, "emails[1]: from is required"},
+		{"event ends first", `{"events": [{"summary": "Late", "start": "2025-03-03T10:00:00Z", "end": "2025-03-03T09:00:00Z"}]}`, "events[0]: end must be after start"},
+		{"event without times", `{"events": [{"summary": "Someday"}]}`, "events[0]: start and end are required"},
+		{"empty contact", `{"contacts": [{}]}`, "contacts[0]: name or email is required"},
+		{"repo without owner", `{"repos": [{"name": "api"}]}`, "repos[0]: owner and name are required"},
+		{"issue repo", `{"issues": [{"repo": "api", "title": "Bug"}]}`, `issues[0]: repo must be "owner/name"`},
+		{"issue state", `{"issues": [{"repo": "acme/api", "title": "Bug", "state": "merged"}]}`, `issues[0]: state must be "open" or "closed"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseFixture(strings.NewReader(tt.fixture))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+	}
+}
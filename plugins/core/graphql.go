@@ -0,0 +1,84 @@
+// ABOUTME: Optional GraphQLPlugin interface for plugins that answer on POST /graphql
+// ABOUTME: Several mocked APIs share that path, so requests go to the plugin that owns their credentials
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// GraphQLPlugin is an optional interface for plugins served at POST /graphql.
+// Plugins still register the route themselves so they work on their own;
+// the server replaces it with GraphQLHandler when plugins are combined.
+type GraphQLPlugin interface {
+	Plugin
+	// ClaimsGraphQL reports whether the request's credentials belong to this plugin
+	ClaimsGraphQL(r *http.Request) bool
+	// ServeGraphQL handles a claimed request
+	ServeGraphQL(w http.ResponseWriter, r *http.Request)
+}
+
+// UnknownTokenClaimer is an optional interface for GraphQL plugins whose
+// tokens have a recognizable shape. Such a plugin is asked first, so it can
+// refuse a token it didn't issue before a plugin that accepts any key takes it.
+type UnknownTokenClaimer interface {
+	// ClaimsUnknownToken reports whether token is shaped like this plugin's tokens
+	ClaimsUnknownToken(token string) bool
+}
+
+// graphQLToken returns the credential in a request's Authorization header,
+// after its scheme ("Bearer", "token") if it has one
+func graphQLToken(r *http.Request) string {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if _, token, ok := strings.Cut(auth, " "); ok {
+		return strings.TrimSpace(token)
+	}
+	return auth
+}
+
+// GraphQLHandler dispatches POST /graphql. A plugin whose ClaimsUnknownToken
+// recognizes the request's token gets it first; otherwise the request goes to
+// the first GraphQL plugin, in name order, that claims it. serve returns the
+// handler for a plugin, wrapped in the middleware its other routes run
+// behind, or nil for a plugin that isn't being served.
+func GraphQLHandler(serve func(GraphQLPlugin) http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var plugins []GraphQLPlugin
+		for _, plugin := range All() {
+			if gql, ok := plugin.(GraphQLPlugin); ok {
+				plugins = append(plugins, gql)
+			}
+		}
+		sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name() < plugins[j].Name() })
+
+		if token := graphQLToken(r); token != "" {
+			for _, gql := range plugins {
+				claimer, ok := gql.(UnknownTokenClaimer)
+				if !ok || !claimer.ClaimsUnknownToken(token) {
+					continue
+				}
+				if handler := serve(gql); handler != nil {
+					handler.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+
+		for _, gql := range plugins {
+			handler := serve(gql)
+			if handler != nil && gql.ClaimsGraphQL(r) {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]any{
+			"errors": []map[string]string{{"message": "requires authentication"}},
+		})
+	}
+}
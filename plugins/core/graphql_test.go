@@ -0,0 +1,80 @@
+// ABOUTME: Tests for dispatching the shared POST /graphql endpoint.
+// ABOUTME: Verifies requests reach the claiming plugin in name order and unclaimed ones get 401.
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// graphQLMockPlugin claims requests with a matching token, or any token if empty
+type graphQLMockPlugin struct {
+	mockPlugin
+	token string
+}
+
+func (m *graphQLMockPlugin) ClaimsGraphQL(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	return auth != "" && (m.token == "" || auth == "Bearer "+m.token)
+}
+
+func (m *graphQLMockPlugin) ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(m.name))
+}
+
+// shapedGraphQLMockPlugin also claims unknown tokens starting with its prefix
+type shapedGraphQLMockPlugin struct {
+	graphQLMockPlugin
+	prefix string
+}
+
+func (m *shapedGraphQLMockPlugin) ClaimsUnknownToken(token string) bool {
+	return strings.HasPrefix(token, m.prefix)
+}
+
+func TestGraphQLHandler(t *testing.T) {
+	resetRegistry()
+	defer resetRegistry()
+	Register(&graphQLMockPlugin{mockPlugin: mockPlugin{name: "linear"}})
+	Register(&shapedGraphQLMockPlugin{graphQLMockPlugin{mockPlugin: mockPlugin{name: "github"}, token: "ghp_known"}, "ghp_"})
+	Register(&mockPlugin{name: "slack"})
+	Register(&graphQLMockPlugin{mockPlugin: mockPlugin{name: "disabled"}})
+
+	serve := func(plugin GraphQLPlugin) http.Handler {
+		if plugin.Name() == "disabled" {
+			return nil
+		}
+		return http.HandlerFunc(plugin.ServeGraphQL)
+	}
+
+	tests := []struct {
+		auth     string
+		wantCode int
+		wantBody string
+	}{
+		{"Bearer ghp_known", http.StatusOK, "github"},
+		{"Bearer lin_api_key", http.StatusOK, "linear"},
+		// An unknown token of GitHub's shape goes to GitHub ahead of linear's catch-all
+		{"Bearer ghp_unknown", http.StatusOK, "github"},
+		{"token ghp_unknown", http.StatusOK, "github"},
+		{"", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/graphql", nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		GraphQLHandler(serve)(w, req)
+
+		if w.Code != tt.wantCode {
+			t.Errorf("%q: status = %d, want %d", tt.auth, w.Code, tt.wantCode)
+		}
+		if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+			t.Errorf("%q: served by %q, want %q", tt.auth, w.Body.String(), tt.wantBody)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// ABOUTME: Idempotency-Key support for mutating endpoints
+// ABOUTME: Caches the first response to a keyed request and replays it when a client retries
+
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header clients set to make a retry safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks responses replayed from the cache
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// IdempotencyTTL is how long a key's cached response is replayed
+const IdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds client-supplied keys
+const maxIdempotencyKeyLength = 255
+
+// idempotencyLocks holds a lock for each key with a request in flight, so
+// concurrent retries of one request can't both run the handler while requests
+// with other keys run in parallel
+var idempotencyLocks = struct {
+	sync.Mutex
+	held map[string]*idempotencyLock
+}{held: make(map[string]*idempotencyLock)}
+
+// idempotencyLock is one key's lock and the number of requests holding or waiting for it
+type idempotencyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lockIdempotencyKey locks a key and returns the function that unlocks it
+func lockIdempotencyKey(key string) func() {
+	idempotencyLocks.Lock()
+	l, ok := idempotencyLocks.held[key]
+	if !ok {
+		l = &idempotencyLock{}
+		idempotencyLocks.held[key] = l
+	}
+	l.refs++
+	idempotencyLocks.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		idempotencyLocks.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(idempotencyLocks.held, key)
+		}
+		idempotencyLocks.Unlock()
+	}
+}
+
+// HandleIdempotent serves r with next, caching the response when the request
+// carries an Idempotency-Key. A retry with the same key from the same user to
+// the same method and path replays the cached response within IdempotencyTTL
+// instead of running next again; reusing a key with a different body is
+// rejected. Requests without a key, dry runs, and 5xx responses are never
+// cached. The idempotency_keys table is created by the core migrations.
+func HandleIdempotent(db *sql.DB, user string, w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if key == "" || db == nil || IsDryRun(r) {
+		next(w, r)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		writeIdempotencyError(w, http.StatusBadRequest, fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeIdempotencyError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	requestHash := hex.EncodeToString(sum[:])
+
+	unlock := lockIdempotencyKey(strings.Join([]string{user, r.Method, r.URL.Path, key}, "\x00"))
+	defer unlock()
+
+	now := time.Now().UTC()
+	if _, err := db.Exec(`DELETE FROM idempotency_keys WHERE created_at < ?`, now.Add(-IdempotencyTTL)); err != nil {
+		writeIdempotencyError(w, http.StatusInternalServerError, "failed to expire idempotency keys")
+		return
+	}
+
+	var cachedHash, contentType string
+	var status int
+	var cached []byte
+	err = db.QueryRow(`
+		SELECT request_hash, status_code, content_type, body FROM idempotency_keys
+		WHERE user_id = ? AND method = ? AND path = ? AND idempotency_key = ?
+	`, user, r.Method, r.URL.Path, key).Scan(&cachedHash, &status, &contentType, &cached)
+	switch {
+	case err == nil:
+		if cachedHash != requestHash {
+			writeIdempotencyError(w, http.StatusUnprocessableEntity, fmt.Sprintf("%s %q was already used with a different request body", IdempotencyKeyHeader, key))
+			return
+		}
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(status)
+		w.Write(cached)
+		return
+	case err != sql.ErrNoRows:
+		writeIdempotencyError(w, http.StatusInternalServerError, "failed to look up idempotency key")
+		return
+	}
+
+	rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+	next(rec, r)
+	if rec.status >= http.StatusInternalServerError {
+		return
+	}
+	// The response is already sent; a failed insert only means a retry runs the handler again
+	db.Exec(`
+		INSERT OR REPLACE INTO idempotency_keys
+			(user_id, method, path, idempotency_key, request_hash, status_code, content_type, body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user, r.Method, r.URL.Path, key, requestHash, rec.status, w.Header().Get("Content-Type"), rec.body.Bytes(), now)
+}
+
+// idempotencyRecorder passes a response through while keeping a copy of it
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// writeIdempotencyError reports a problem with the key itself, before any plugin handler runs
+func writeIdempotencyError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    "idempotency_error",
+			"message": message,
+			"status":  status,
+		},
+	})
+}
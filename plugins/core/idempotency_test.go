@@ -0,0 +1,147 @@
+// ABOUTME: Tests for Idempotency-Key handling.
+// ABOUTME: Verifies keyed retries replay the first response, unkeyed or failed requests run again, and keys lock separately.
+
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/migrations"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestHandleIdempotent(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	defer db.Close()
+
+	calls := 0
+	status := http.StatusCreated
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	}
+	serve := func(user, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		HandleIdempotent(db, user, w, req, handler)
+		return w
+	}
+
+	first := serve("alice", "k1", `{"a":1}`)
+	retry := serve("alice", "k1", `{"a":1}`)
+	if calls != 1 {
+		t.Fatalf("Expected the handler to run once, ran %d times", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected replay of %d %s, got %d %s", first.Code, first.Body, retry.Code, retry.Body)
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("Expected only the replay to be marked Idempotent-Replayed")
+	}
+	if retry.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected replayed Content-Type, got %q", retry.Header().Get("Content-Type"))
+	}
+
+	if w := serve("alice", "k1", `{"a":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a reused key with a different body, got %d", w.Code)
+	}
+
+	// Keys are scoped per user, and requests without a key always run
+	serve("bob", "k1", `{"a":1}`)
+	serve("alice", "", `{"a":1}`)
+	if calls != 3 {
+		t.Errorf("Expected 3 handler calls, got %d", calls)
+	}
+
+	// Server errors are not cached, so a retry runs again
+	status = http.StatusInternalServerError
+	serve("alice", "k2", `{}`)
+	status = http.StatusCreated
+	if w := serve("alice", "k2", `{}`); w.Code != http.StatusCreated || calls != 5 {
+		t.Errorf("Expected a retry after a 500 to run the handler, got %d after %d calls", w.Code, calls)
+	}
+
+	if w := serve("alice", strings.Repeat("k", 256), `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an oversized key, got %d", w.Code)
+	}
+}
+
+func TestHandleIdempotentLocksPerKey(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	defer db.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	serve := func(key string, handler http.HandlerFunc) {
+		req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, key)
+		HandleIdempotent(db, "alice", httptest.NewRecorder(), req, handler)
+	}
+
+	// The first request holds its key until released
+	done := make(chan struct{})
+	go func() {
+		serve("slow", func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusCreated)
+		})
+		close(done)
+	}()
+	<-started
+
+	// A request with another key runs meanwhile
+	other := make(chan struct{})
+	go func() {
+		serve("fast", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) })
+		close(other)
+	}()
+	select {
+	case <-other:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a request with another key not to wait for the first")
+	}
+
+	// A retry of the first key waits for it, then replays its response
+	retried := make(chan bool, 1)
+	go func() {
+		ran := false
+		serve("slow", func(w http.ResponseWriter, r *http.Request) { ran = true })
+		retried <- ran
+	}()
+	select {
+	case <-retried:
+		t.Fatal("Expected the retry to wait for the first request")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-done
+	if ran := <-retried; ran {
+		t.Error("Expected the retry to replay the first response instead of running the handler")
+	}
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Optional Inspector interface for printing one record from the CLI
+// ABOUTME: Plugins return records in the same shape their API responses use
+
+package core
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// InspectHost is the host self links point at in inspected records, matching
+// a server on the default port
+const InspectHost = "localhost:9000"
+
+// Inspector is an optional interface for plugins whose records `ish inspect`
+// can print. Resources are schema slugs such as "issues"; records come back
+// in API response format, or in admin format for resources the API never
+// returns on their own.
+type Inspector interface {
+	Plugin
+	Inspect(resource, id string) (interface{}, error)
+}
+
+// InspectRequest returns a stand-in request for response formatters that
+// build self links from the incoming request
+func InspectRequest() *http.Request {
+	return &http.Request{
+		Method: http.MethodGet,
+		URL:    &url.URL{Scheme: "http", Host: InspectHost, Path: "/"},
+		Host:   InspectHost,
+		Header: http.Header{},
+	}
+}
+
+// ResolveResource finds the schema resource a CLI name refers to, accepting
+// the slug itself ("pull_requests") or its singular ("pull-request")
+func ResolveResource(schema PluginSchema, name string) (ResourceSchema, bool) {
+	name = strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	candidates := []string{name, name + "s", name + "es"}
+	if strings.HasSuffix(name, "y") {
+		candidates = append(candidates, strings.TrimSuffix(name, "y")+"ies")
+	}
+	for _, resource := range schema.Resources {
+		for _, candidate := range candidates {
+			if resource.Slug == candidate {
+				return resource, true
+			}
+		}
+	}
+	return ResourceSchema{}, false
+}
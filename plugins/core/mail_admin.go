@@ -0,0 +1,66 @@
+// ABOUTME: Optional MailAdmin interface for managing mailbox messages from the admin UI
+// ABOUTME: Plugins with a mailbox implement this so admin pages can create, inspect, relabel, and delete messages
+
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMessageNotFound is returned by MailAdmin methods when no message has the given ID
+var ErrMessageNotFound = errors.New("message not found")
+
+// MailAdmin is an optional interface that plugins with a mailbox can implement
+// to let the admin UI manage messages directly
+type MailAdmin interface {
+	Plugin
+	CreateMailMessage(ctx context.Context, input MailMessageInput) (string, error)
+	GetMailMessage(ctx context.Context, id string) (*MailMessage, error)
+	DeleteMailMessage(ctx context.Context, id string) error
+	SetMailMessageLabels(ctx context.Context, id string, labels []string) error
+	ListMailLabels(ctx context.Context) ([]string, error)
+}
+
+// MailMessageInput holds the fields submitted from the admin create form or
+// read from an imported mailbox
+type MailMessageInput struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+	Labels  []string
+	// UserID is the mailbox to store the message in; empty means the admin mailbox
+	UserID string
+	// Date is when the message was received; zero means now
+	Date time.Time
+}
+
+// MailHeader is a single message header
+type MailHeader struct {
+	Name  string
+	Value string
+}
+
+// MailMessage is a fully decoded message for display in the admin UI
+type MailMessage struct {
+	ID           string
+	UserID       string
+	ThreadID     string
+	Snippet      string
+	LabelIDs     []string
+	InternalDate int64
+	Headers      []MailHeader
+	Body         string
+}
+
+// Header returns the first header with the given name, or an empty string
+func (m *MailMessage) Header(name string) string {
+	for _, h := range m.Headers {
+		if h.Name == name {
+			return h.Value
+		}
+	}
+	return ""
+}
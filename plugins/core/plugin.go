@@ -36,6 +36,15 @@ type DatabasePlugin interface {
 	SetDB(db *sql.DB) error
 }
 
+// AnonymousRoutesPlugin is implemented by plugins whose routes require credentials.
+// Requests without an Authorization header are rejected unless a default user is
+// configured or the route matches one of the declared patterns, written as
+// "[METHOD ]/path/{param}/*".
+type AnonymousRoutesPlugin interface {
+	Plugin
+	AnonymousRoutes() []string
+}
+
 // HealthStatus represents plugin health
 type HealthStatus struct {
 	Status  string // "healthy", "degraded", "unavailable"
@@ -0,0 +1,26 @@
+// ABOUTME: Optional ResourceEditor interface for updating individual resource fields from the admin UI
+// ABOUTME: Lets admin pages save JSON-valued fields like attendees or payload without a full form
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ErrResourceNotFound is returned by UpdateResourceFields when no resource has the given ID
+var ErrResourceNotFound = errors.New("resource not found")
+
+// ErrInvalidField is returned by UpdateResourceFields when a field can't be
+// edited or its value doesn't fit the resource
+var ErrInvalidField = errors.New("invalid field")
+
+// ResourceEditor is an optional interface for plugins whose DataProvider
+// resources can be edited a field at a time from the admin UI
+type ResourceEditor interface {
+	Plugin
+	// UpdateResourceFields replaces the named fields of a stored resource.
+	// Field names match the resource's schema; values are already valid JSON.
+	UpdateResourceFields(ctx context.Context, resourceSlug, id string, fields map[string]json.RawMessage) error
+}
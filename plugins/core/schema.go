@@ -20,7 +20,7 @@ type ResourceSchema struct {
 // FieldSchema defines a field in a resource
 type FieldSchema struct {
 	Name     string // "subject", "from", "date"
-	Type     string // "string", "datetime", "email", "text"
+	Type     string // "string", "datetime", "email", "text", "json"
 	Display  string // "Subject", "From", "Date"
 	Required bool
 	Editable bool
@@ -0,0 +1,65 @@
+// ABOUTME: Optional Shutdowner interface for plugins that run background work
+// ABOUTME: Lets the server stop webhook workers and drain deliveries on exit, and tells long-lived responses to end
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Shutdowner is an optional interface for plugins that start goroutines, such
+// as webhook queues, which must finish before the process exits
+type Shutdowner interface {
+	Plugin
+	// Shutdown stops background work and waits for in-flight work to finish,
+	// giving up when ctx is done
+	Shutdown(ctx context.Context) error
+}
+
+// ShutdownAll shuts down every registered plugin that implements Shutdowner
+func ShutdownAll(ctx context.Context) error {
+	var errs []error
+	for _, plugin := range All() {
+		if s, ok := plugin.(Shutdowner); ok {
+			if err := s.Shutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", plugin.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type shutdownKey struct{}
+
+// WithShutdownSignal returns a copy of ctx carrying done, which the server
+// closes when it starts shutting down
+func WithShutdownSignal(ctx context.Context, done <-chan struct{}) context.Context {
+	return context.WithValue(ctx, shutdownKey{}, done)
+}
+
+// ShuttingDown returns a channel that is closed when the server starts
+// shutting down, so long-lived responses such as event streams can end
+// instead of holding up shutdown. Outside a server it returns nil, which
+// never receives.
+func ShuttingDown(ctx context.Context) <-chan struct{} {
+	done, _ := ctx.Value(shutdownKey{}).(<-chan struct{})
+	return done
+}
+
+// WaitGroupContext waits for wg, returning ctx.Err() if ctx is done first
+func WaitGroupContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
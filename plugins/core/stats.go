@@ -0,0 +1,29 @@
+// ABOUTME: Optional StatsProvider interface for live per-table row counts
+// ABOUTME: Backs the admin stats API that reports each plugin's dataset composition
+
+package core
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// StatsProvider is implemented by plugin stores, and by plugins on their
+// stores' behalf, to report how many rows each of their tables holds
+type StatsProvider interface {
+	// Stats maps table names (e.g. "gmail_messages") to their row counts
+	Stats() (map[string]int, error)
+}
+
+// CountTables counts the rows in each of the given tables
+func CountTables(db *sql.DB, tables ...string) (map[string]int, error) {
+	counts := make(map[string]int, len(tables))
+	for _, table := range tables {
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			return nil, fmt.Errorf("count %s: %w", table, err)
+		}
+		counts[table] = n
+	}
+	return counts, nil
+}
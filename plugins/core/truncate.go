@@ -0,0 +1,52 @@
+// ABOUTME: Optional Truncatable interface for wiping plugin data in place
+// ABOUTME: Lets the admin UI delete a plugin's records without removing the database file
+
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// Truncatable is an optional interface for plugins whose data can be wiped
+// without deleting the database
+type Truncatable interface {
+	Plugin
+	// TruncateGroups maps resource types (e.g. "gmail") to the tables holding
+	// them, children before parents
+	TruncateGroups() map[string][]string
+	// Truncate deletes every row from all of the plugin's tables
+	Truncate(db *sql.DB) error
+}
+
+// GroupTables returns every table named in a set of truncate groups, in a stable order
+func GroupTables(groups map[string][]string) []string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var tables []string
+	for _, key := range keys {
+		tables = append(tables, groups[key]...)
+	}
+	return tables
+}
+
+// TruncateTables deletes every row from the given tables in a single transaction
+func TruncateTables(db *sql.DB, tables ...string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return fmt.Errorf("truncate %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,93 @@
+// ABOUTME: Shared HTTP client for outbound webhook deliveries.
+// ABOUTME: Bounds every delivery with a configurable timeout so a hung receiver can't pin a goroutine.
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WebhookTimeoutEnv names the environment variable that sets the per-delivery webhook timeout
+const WebhookTimeoutEnv = "ISH_WEBHOOK_TIMEOUT"
+
+// DefaultWebhookTimeout bounds a delivery when ISH_WEBHOOK_TIMEOUT is unset
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookTimeoutError is the error message delivery logs record for a timed-out delivery
+const WebhookTimeoutError = "timeout"
+
+var (
+	webhookClient   = NewWebhookClient(DefaultWebhookTimeout)
+	webhookClientMu sync.RWMutex
+)
+
+// NewWebhookClient returns a client whose requests give up after timeout.
+// Redirects are not followed, so a receiver can't bounce a delivery to an
+// address that URL validation would have rejected.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: min(timeout, 5*time.Second), KeepAlive: 30 * time.Second}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   min(timeout, 5*time.Second),
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// WebhookClient returns the client plugins use to deliver webhooks
+func WebhookClient() *http.Client {
+	webhookClientMu.RLock()
+	defer webhookClientMu.RUnlock()
+	return webhookClient
+}
+
+// SetWebhookTimeout replaces the shared webhook client with one bounded by timeout
+func SetWebhookTimeout(timeout time.Duration) {
+	client := NewWebhookClient(timeout)
+	webhookClientMu.Lock()
+	defer webhookClientMu.Unlock()
+	webhookClient = client
+}
+
+// WebhookTimeoutFromEnv reads ISH_WEBHOOK_TIMEOUT as a duration (e.g. 30s,
+// 500ms) or a whole number of seconds, returning the default when it is unset
+func WebhookTimeoutFromEnv() (time.Duration, error) {
+	value := os.Getenv(WebhookTimeoutEnv)
+	if value == "" {
+		return DefaultWebhookTimeout, nil
+	}
+	if _, err := strconv.Atoi(value); err == nil {
+		value += "s"
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a positive duration (e.g. 10s)", WebhookTimeoutEnv, os.Getenv(WebhookTimeoutEnv))
+	}
+	return timeout, nil
+}
+
+// IsTimeout reports whether a delivery failed because the receiver took too long
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
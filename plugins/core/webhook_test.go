@@ -0,0 +1,78 @@
+// ABOUTME: Tests for the shared webhook delivery client.
+// ABOUTME: Covers ISH_WEBHOOK_TIMEOUT parsing, timeout detection, and redirect handling.
+
+package core
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookTimeoutFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultWebhookTimeout, false},
+		{"30s", 30 * time.Second, false},
+		{"500ms", 500 * time.Millisecond, false},
+		{"15", 15 * time.Second, false},
+		{"0", 0, true},
+		{"-5s", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(WebhookTimeoutEnv, tt.value)
+		got, err := WebhookTimeoutFromEnv()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("WebhookTimeoutFromEnv(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("WebhookTimeoutFromEnv(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookClientTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	SetWebhookTimeout(50 * time.Millisecond)
+	t.Cleanup(func() { SetWebhookTimeout(DefaultWebhookTimeout) })
+
+	_, err := WebhookClient().Post(receiver.URL, "application/json", nil)
+	if err == nil {
+		t.Fatal("expected a hung receiver to time out")
+	}
+	if !IsTimeout(err) {
+		t.Errorf("IsTimeout(%v) = false, want true", err)
+	}
+	if IsTimeout(errors.New("connection refused")) {
+		t.Error("IsTimeout should be false for other errors")
+	}
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer receiver.Close()
+
+	resp, err := WebhookClient().Post(receiver.URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, want the receiver's %d", resp.StatusCode, http.StatusFound)
+	}
+}
@@ -0,0 +1,276 @@
+// ABOUTME: Guild audit log for Discord plugin
+// ABOUTME: Records moderation actions and serves GET /guilds/{guild.id}/audit-logs to bots
+
+package discord
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Audit log action types, as numbered by the Discord API
+const (
+	AuditLogChannelCreate = 10
+	AuditLogMemberKick    = 20
+	AuditLogMessageDelete = 72
+)
+
+// discordEpoch is the first millisecond of 2015, where snowflake timestamps start
+const discordEpoch = 1420070400000
+
+// Audit log page sizes
+const (
+	defaultAuditLogLimit = 50
+	maxAuditLogLimit     = 100
+)
+
+var auditLogSequence atomic.Uint64
+
+// nextAuditLogID returns a snowflake that sorts after every earlier one, so
+// audit log entries can be paged with before and after
+func nextAuditLogID() int64 {
+	ms := uint64(time.Now().UnixMilli() - discordEpoch)
+	return int64(ms<<22 | auditLogSequence.Add(1)&0x3FFFFF)
+}
+
+// auditLogReason reads the X-Audit-Log-Reason header, which Discord expects
+// URL-encoded
+func auditLogReason(r *http.Request) string {
+	reason := r.Header.Get("X-Audit-Log-Reason")
+	if decoded, err := url.PathUnescape(reason); err == nil {
+		return decoded
+	}
+	return reason
+}
+
+// recordMessageDelete adds a MESSAGE_DELETE entry to the webhook's guild audit
+// log. The webhook is both the actor and the author of the deleted message.
+func (p *DiscordPlugin) recordMessageDelete(r *http.Request, webhook *Webhook, msg *WebhookMessage) {
+	channelID := webhook.ChannelID
+	if msg.ThreadID != "" {
+		channelID = msg.ThreadID
+	}
+	options, _ := json.Marshal(map[string]string{"channel_id": channelID, "count": "1"})
+
+	err := p.store.CreateAuditLogEntry(&AuditLogEntry{
+		GuildID:    webhook.GuildID,
+		UserID:     webhook.ID,
+		TargetID:   webhook.ID,
+		ActionType: AuditLogMessageDelete,
+		Options:    string(options),
+		Reason:     auditLogReason(r),
+	})
+	if err != nil {
+		log.Printf("Discord: Failed to record audit log entry: %v", err)
+	}
+}
+
+// simulateAuditLogEntry handles POST /admin/discord/guilds/{guild.id}/audit-logs.
+// ISH's Discord plugin has no guild channels or members, so channel creation
+// and member kicks reach the audit log through this admin route instead.
+func (p *DiscordPlugin) simulateAuditLogEntry(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized")
+		return
+	}
+
+	var req struct {
+		ActionType  int    `json:"action_type"`
+		UserID      string `json:"user_id"`
+		TargetID    string `json:"target_id"`
+		Reason      string `json:"reason"`
+		ChannelName string `json:"channel_name"`
+		ChannelType int    `json:"channel_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid JSON")
+		return
+	}
+
+	entry := &AuditLogEntry{
+		GuildID:    chi.URLParam(r, "guildID"),
+		UserID:     req.UserID,
+		TargetID:   req.TargetID,
+		ActionType: req.ActionType,
+		Reason:     req.Reason,
+	}
+	switch req.ActionType {
+	case AuditLogChannelCreate:
+		if req.ChannelName == "" {
+			writeError(w, 400, "channel_name is required for CHANNEL_CREATE")
+			return
+		}
+		if entry.TargetID == "" {
+			entry.TargetID = generateSnowflake()
+		}
+		changes, _ := json.Marshal([]map[string]interface{}{
+			{"key": "name", "new_value": req.ChannelName},
+			{"key": "type", "new_value": req.ChannelType},
+		})
+		entry.Changes = string(changes)
+	case AuditLogMemberKick:
+		if entry.TargetID == "" {
+			writeError(w, 400, "target_id is required for MEMBER_KICK")
+			return
+		}
+	default:
+		writeError(w, 400, "action_type must be 10 (CHANNEL_CREATE) or 20 (MEMBER_KICK)")
+		return
+	}
+
+	if err := p.store.CreateAuditLogEntry(entry); err != nil {
+		writeError(w, 500, "Failed to record audit log entry")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(auditLogEntryToResponse(entry))
+}
+
+// requireBotToken rejects requests without an "Authorization: Bot <token>"
+// header. Any non-empty token is accepted.
+func requireBotToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bot ")
+		if !ok || strings.TrimSpace(token) == "" {
+			writeError(w, 401, "401: Unauthorized")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// parseAuditLogFilter reads the audit log query parameters, reporting the
+// name of the first invalid one
+func parseAuditLogFilter(r *http.Request) (AuditLogFilter, string) {
+	q := r.URL.Query()
+	filter := AuditLogFilter{UserID: q.Get("user_id"), Limit: defaultAuditLogLimit}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return filter, "limit"
+		}
+		filter.Limit = min(limit, maxAuditLogLimit)
+	}
+	if v := q.Get("action_type"); v != "" {
+		actionType, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, "action_type"
+		}
+		filter.ActionType = actionType
+	}
+	for name, dst := range map[string]*int64{"before": &filter.Before, "after": &filter.After} {
+		if v := q.Get(name); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return filter, name
+			}
+			*dst = id
+		}
+	}
+	return filter, ""
+}
+
+// getGuildAuditLog handles GET /api/guilds/{guild.id}/audit-logs
+func (p *DiscordPlugin) getGuildAuditLog(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized")
+		return
+	}
+
+	filter, invalid := parseAuditLogFilter(r)
+	if invalid != "" {
+		writeError(w, 400, "Invalid "+invalid)
+		return
+	}
+
+	entries, err := p.store.ListAuditLogEntries(chi.URLParam(r, "guildID"), filter)
+	if err != nil {
+		writeError(w, 500, "Failed to list audit log entries")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(entries))
+	webhooks := []map[string]interface{}{}
+	var seen []string
+	for _, entry := range entries {
+		response = append(response, auditLogEntryToResponse(entry))
+		for _, id := range []string{entry.UserID, entry.TargetID} {
+			if id == "" || slices.Contains(seen, id) {
+				continue
+			}
+			seen = append(seen, id)
+			if webhook, err := p.store.GetWebhookByID(id); err == nil {
+				webhooks = append(webhooks, auditLogWebhookToResponse(webhook))
+			}
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"audit_log_entries":      response,
+		"users":                  []interface{}{},
+		"webhooks":               webhooks,
+		"integrations":           []interface{}{},
+		"threads":                []interface{}{},
+		"application_commands":   []interface{}{},
+		"auto_moderation_rules":  []interface{}{},
+		"guild_scheduled_events": []interface{}{},
+	})
+}
+
+// auditLogEntryToResponse converts an AuditLogEntry to Discord's audit log entry object
+func auditLogEntryToResponse(entry *AuditLogEntry) map[string]interface{} {
+	resp := map[string]interface{}{
+		"id":          strconv.FormatInt(entry.ID, 10),
+		"action_type": entry.ActionType,
+		"user_id":     nil,
+		"target_id":   nil,
+	}
+	if entry.UserID != "" {
+		resp["user_id"] = entry.UserID
+	}
+	if entry.TargetID != "" {
+		resp["target_id"] = entry.TargetID
+	}
+	if entry.Changes != "" {
+		resp["changes"] = json.RawMessage(entry.Changes)
+	}
+	if entry.Options != "" {
+		resp["options"] = json.RawMessage(entry.Options)
+	}
+	if entry.Reason != "" {
+		resp["reason"] = entry.Reason
+	}
+	return resp
+}
+
+// auditLogWebhookToResponse converts a Webhook to the webhook object embedded
+// in an audit log. The token is left out, as Discord does.
+func auditLogWebhookToResponse(webhook *Webhook) map[string]interface{} {
+	resp := map[string]interface{}{
+		"id":             webhook.ID,
+		"type":           webhook.Type,
+		"guild_id":       webhook.GuildID,
+		"channel_id":     webhook.ChannelID,
+		"name":           webhook.Name,
+		"avatar":         nil,
+		"application_id": nil,
+	}
+	if webhook.Avatar != "" {
+		resp["avatar"] = webhook.Avatar
+	}
+	if webhook.ApplicationID != "" {
+		resp["application_id"] = webhook.ApplicationID
+	}
+	return resp
+}
@@ -0,0 +1,230 @@
+// ABOUTME: Tests for the Discord guild audit log
+// ABOUTME: Covers entries recorded on message deletion or by the admin, bot auth, and the query filters
+
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type auditLogResponse struct {
+	AuditLogEntries []struct {
+		ID         string            `json:"id"`
+		UserID     string            `json:"user_id"`
+		TargetID   string            `json:"target_id"`
+		ActionType int               `json:"action_type"`
+		Options    map[string]string `json:"options"`
+		Reason     string            `json:"reason"`
+		Changes    []struct {
+			Key      string      `json:"key"`
+			NewValue interface{} `json:"new_value"`
+		} `json:"changes"`
+	} `json:"audit_log_entries"`
+	Users    []map[string]interface{} `json:"users"`
+	Webhooks []map[string]interface{} `json:"webhooks"`
+}
+
+// getAuditLog fetches a guild's audit log through the router
+func getAuditLog(t *testing.T, r chi.Router, guildID, query string) auditLogResponse {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/guilds/"+guildID+"/audit-logs"+query, nil)
+	req.Header.Set("Authorization", "Bot test-token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp auditLogResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode audit log: %v", err)
+	}
+	return resp
+}
+
+// deleteMessage deletes a webhook message through the router
+func deleteMessage(t *testing.T, r chi.Router, msgID, reason string) {
+	t.Helper()
+	req := httptest.NewRequest("DELETE", "/api/webhooks/123/token123/messages/"+msgID, nil)
+	if reason != "" {
+		req.Header.Set("X-Audit-Log-Reason", reason)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuditLogMessageDelete(t *testing.T) {
+	plugin := setupTestPlugin(t)
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+
+	webhook, _ := plugin.store.GetOrCreateWebhook("123", "token123")
+	msg := &WebhookMessage{WebhookID: webhook.ID, Content: "Spam"}
+	plugin.store.CreateMessage(msg)
+
+	if log := getAuditLog(t, r, webhook.GuildID, ""); len(log.AuditLogEntries) != 0 {
+		t.Fatalf("Expected an empty audit log, got %v", log.AuditLogEntries)
+	}
+
+	deleteMessage(t, r, msg.ID, "Cleaning%20up%20spam")
+
+	log := getAuditLog(t, r, webhook.GuildID, "")
+	if len(log.AuditLogEntries) != 1 {
+		t.Fatalf("Expected 1 audit log entry, got %d", len(log.AuditLogEntries))
+	}
+	entry := log.AuditLogEntries[0]
+	if entry.ActionType != AuditLogMessageDelete {
+		t.Errorf("Expected action_type %d, got %d", AuditLogMessageDelete, entry.ActionType)
+	}
+	if entry.UserID != webhook.ID || entry.TargetID != webhook.ID {
+		t.Errorf("Expected the webhook as actor and target, got %s and %s", entry.UserID, entry.TargetID)
+	}
+	if entry.Options["channel_id"] != webhook.ChannelID || entry.Options["count"] != "1" {
+		t.Errorf("Unexpected options: %v", entry.Options)
+	}
+	if entry.Reason != "Cleaning up spam" {
+		t.Errorf("Expected the decoded reason, got %q", entry.Reason)
+	}
+	if log.Users == nil || len(log.Webhooks) != 1 || log.Webhooks[0]["id"] != webhook.ID {
+		t.Errorf("Expected the webhook embedded once, got users %v and webhooks %v", log.Users, log.Webhooks)
+	}
+	if _, ok := log.Webhooks[0]["token"]; ok {
+		t.Error("Embedded webhook should not include its token")
+	}
+
+	// Deleting a message that isn't there records nothing
+	deleteMessage(t, r, "999", "")
+	if log := getAuditLog(t, r, webhook.GuildID, ""); len(log.AuditLogEntries) != 1 {
+		t.Errorf("Expected no entry for a missing message, got %d entries", len(log.AuditLogEntries))
+	}
+
+	// Other guilds don't see the entry
+	if log := getAuditLog(t, r, "other-guild", ""); len(log.AuditLogEntries) != 0 {
+		t.Errorf("Expected another guild's audit log to be empty, got %v", log.AuditLogEntries)
+	}
+}
+
+func TestAuditLogFilters(t *testing.T) {
+	plugin := setupTestPlugin(t)
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+
+	webhook, _ := plugin.store.GetOrCreateWebhook("123", "token123")
+	for i := 0; i < 3; i++ {
+		msg := &WebhookMessage{WebhookID: webhook.ID, Content: fmt.Sprintf("Message %d", i)}
+		plugin.store.CreateMessage(msg)
+		deleteMessage(t, r, msg.ID, "")
+	}
+	plugin.store.CreateAuditLogEntry(&AuditLogEntry{GuildID: webhook.GuildID, UserID: "42", TargetID: "43", ActionType: AuditLogMemberKick})
+
+	all := getAuditLog(t, r, webhook.GuildID, "").AuditLogEntries
+	if len(all) != 4 || all[0].ActionType != AuditLogMemberKick {
+		t.Fatalf("Expected 4 entries, newest first, got %v", all)
+	}
+
+	if got := getAuditLog(t, r, webhook.GuildID, "?limit=2").AuditLogEntries; len(got) != 2 || got[0].ID != all[0].ID {
+		t.Errorf("Expected the 2 newest entries, got %v", got)
+	}
+	if got := getAuditLog(t, r, webhook.GuildID, "?action_type=72").AuditLogEntries; len(got) != 3 {
+		t.Errorf("Expected 3 message deletions, got %d", len(got))
+	}
+	if got := getAuditLog(t, r, webhook.GuildID, "?user_id=42").AuditLogEntries; len(got) != 1 || got[0].TargetID != "43" {
+		t.Errorf("Expected the kick by user 42, got %v", got)
+	}
+	if got := getAuditLog(t, r, webhook.GuildID, "?before="+all[1].ID).AuditLogEntries; len(got) != 2 || got[0].ID != all[2].ID {
+		t.Errorf("Expected the 2 entries before %s, got %v", all[1].ID, got)
+	}
+	// Paging forward returns the oldest entries first
+	if got := getAuditLog(t, r, webhook.GuildID, "?after="+all[3].ID+"&limit=2").AuditLogEntries; len(got) != 2 || got[0].ID != all[2].ID {
+		t.Errorf("Expected the 2 entries after %s, oldest first, got %v", all[3].ID, got)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=abc", "?action_type=kick", "?before=x"} {
+		req := httptest.NewRequest("GET", "/api/guilds/"+webhook.GuildID+"/audit-logs"+query, nil)
+		req.Header.Set("Authorization", "Bot test-token")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestAuditLogRequiresBotToken(t *testing.T) {
+	plugin := setupTestPlugin(t)
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+
+	for _, auth := range []string{"", "Bearer test-token", "Bot "} {
+		req := httptest.NewRequest("GET", "/api/guilds/guild1/audit-logs", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", auth, w.Code)
+		}
+	}
+}
+
+// simulateEntry records an audit log entry through the admin route
+func simulateEntry(t *testing.T, r chi.Router, guildID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/admin/discord/guilds/"+guildID+"/audit-logs", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuditLogSimulatedEntries(t *testing.T) {
+	plugin := setupTestPlugin(t)
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+
+	if w := simulateEntry(t, r, "guild1", `{"action_type":10,"user_id":"42","channel_name":"announcements","channel_type":5}`); w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 for CHANNEL_CREATE, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := simulateEntry(t, r, "guild1", `{"action_type":20,"user_id":"42","target_id":"99","reason":"Spamming"}`); w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 for MEMBER_KICK, got %d: %s", w.Code, w.Body.String())
+	}
+
+	log := getAuditLog(t, r, "guild1", "")
+	if len(log.AuditLogEntries) != 2 {
+		t.Fatalf("Expected 2 audit log entries, got %d", len(log.AuditLogEntries))
+	}
+	kick, create := log.AuditLogEntries[0], log.AuditLogEntries[1]
+	if kick.ActionType != AuditLogMemberKick || kick.UserID != "42" || kick.TargetID != "99" || kick.Reason != "Spamming" {
+		t.Errorf("Unexpected kick entry: %+v", kick)
+	}
+	if create.ActionType != AuditLogChannelCreate || create.TargetID == "" {
+		t.Errorf("Expected a channel creation targeting a new channel, got %+v", create)
+	}
+	if len(create.Changes) != 2 || create.Changes[0].Key != "name" || create.Changes[0].NewValue != "announcements" || create.Changes[1].NewValue != float64(5) {
+		t.Errorf("Expected the channel's name and type in changes, got %+v", create.Changes)
+	}
+
+	if got := getAuditLog(t, r, "guild1", "?action_type=20").AuditLogEntries; len(got) != 1 || got[0].TargetID != "99" {
+		t.Errorf("Expected only the kick, got %v", got)
+	}
+
+	for _, body := range []string{
+		`{"action_type":72}`,
+		`{"action_type":10}`,
+		`{"action_type":20,"user_id":"42"}`,
+		`not json`,
+	} {
+		if w := simulateEntry(t, r, "guild1", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
@@ -296,16 +296,21 @@ func (p *DiscordPlugin) deleteWebhookMessage(w http.ResponseWriter, r *http.Requ
 	messageID := chi.URLParam(r, "messageID")
 
 	// Validate webhook exists with correct token
-	_, err := p.store.GetWebhook(webhookID, webhookToken)
+	webhook, err := p.store.GetWebhook(webhookID, webhookToken)
 	if err != nil {
 		writeError(w, 404, "Webhook not found")
 		return
 	}
+	msg, msgErr := p.store.GetMessage(webhookID, messageID)
 
 	if err := p.store.DeleteMessage(webhookID, messageID); err != nil {
 		writeError(w, 500, "Failed to delete message")
 		return
 	}
+	// Only a message that was actually there makes it into the audit log
+	if msgErr == nil {
+		p.recordMessageDelete(r, webhook, msg)
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
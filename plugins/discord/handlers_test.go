@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/2389/ish/internal/migrations"
 	"github.com/go-chi/chi/v5"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -21,6 +22,9 @@ func setupTestPlugin(t *testing.T) *DiscordPlugin {
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
 
 	plugin := &DiscordPlugin{}
 	if err := plugin.SetDB(db); err != nil {
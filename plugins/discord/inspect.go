@@ -0,0 +1,18 @@
+// ABOUTME: core.Inspector implementation for the Discord plugin
+// ABOUTME: Renders webhooks and messages as the webhook API does
+
+package discord
+
+import "fmt"
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *DiscordPlugin) Inspect(resource, id string) (interface{}, error) {
+	switch resource {
+	case "webhooks":
+		return p.store.GetWebhookByID(id)
+	case "messages":
+		return p.store.GetMessageByID(id)
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
@@ -44,6 +44,11 @@ func (p *DiscordPlugin) RegisterRoutes(r chi.Router) {
 		r.Patch("/messages/{messageID}", p.editWebhookMessage)
 		r.Delete("/messages/{messageID}", p.deleteWebhookMessage)
 	})
+
+	r.Get("/api/guilds/{guildID}/audit-logs", requireBotToken(p.getGuildAuditLog))
+
+	// Admin simulation of actions ISH doesn't model
+	r.Post("/admin/discord/guilds/{guildID}/audit-logs", p.simulateAuditLogEntry)
 }
 
 func (p *DiscordPlugin) RegisterAuth(r chi.Router) {
@@ -3,7 +3,11 @@
 
 package discord
 
-import "github.com/2389/ish/plugins/core"
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
 
 func (p *DiscordPlugin) Schema() core.PluginSchema {
 	return core.PluginSchema{
@@ -61,3 +65,30 @@ func (p *DiscordPlugin) Schema() core.PluginSchema {
 		},
 	}
 }
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *DiscordPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "discord", Table: "discord_webhook_messages", Resource: "messages"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *DiscordPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"discord": {"discord_audit_logs", "discord_webhook_messages", "discord_webhooks"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *DiscordPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *DiscordPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Database layer for Discord webhook plugin
-// ABOUTME: Manages discord_webhooks and discord_webhook_messages tables
+// ABOUTME: Manages discord_webhooks, discord_webhook_messages, and discord_audit_logs tables
 
 package discord
 
@@ -9,65 +9,43 @@ import (
 	"encoding/binary"
 	"fmt"
 	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 type DiscordStore struct {
 	db *sql.DB
 }
 
+// auditIn records a mutation of a Discord resource in the shared audit log
+// through db, the transaction making the change so both commit together. The
+// actor is the ID of the webhook whose token made the change.
+func auditIn(db core.Execer, resourceType, resourceID, action, actor string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "discord",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
 func NewDiscordStore(db *sql.DB) (*DiscordStore, error) {
-	store := &DiscordStore{db: db}
-	if err := store.initTables(); err != nil {
-		return nil, err
-	}
-	return store, nil
+	return &DiscordStore{db: db}, nil
 }
 
-func (s *DiscordStore) initTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS discord_webhooks (
-			id TEXT PRIMARY KEY,
-			token TEXT NOT NULL,
-			type INTEGER DEFAULT 1,
-			name TEXT,
-			avatar TEXT,
-			channel_id TEXT,
-			guild_id TEXT,
-			application_id TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			deleted_at TIMESTAMP,
-			UNIQUE(id, token)
-		)`,
-
-		`CREATE TABLE IF NOT EXISTS discord_webhook_messages (
-			id TEXT PRIMARY KEY,
-			webhook_id TEXT NOT NULL,
-			content TEXT,
-			username TEXT,
-			avatar_url TEXT,
-			embeds TEXT,
-			components TEXT,
-			attachments TEXT,
-			thread_id TEXT,
-			flags INTEGER,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			edited_at TIMESTAMP,
-			deleted_at TIMESTAMP,
-			FOREIGN KEY (webhook_id) REFERENCES discord_webhooks(id) ON DELETE CASCADE
-		)`,
-
-		`CREATE INDEX IF NOT EXISTS idx_webhook_messages_webhook_id ON discord_webhook_messages(webhook_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_webhook_messages_created_at ON discord_webhook_messages(created_at DESC)`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return err
-		}
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *DiscordStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
 	}
-	return nil
+	return tx.Commit()
 }
 
 type Webhook struct {
@@ -101,6 +79,29 @@ type WebhookMessage struct {
 	DeletedAt   *time.Time
 }
 
+// AuditLogEntry is an action recorded in a guild's audit log. Changes and
+// Options hold JSON, empty when the action has none.
+type AuditLogEntry struct {
+	ID         int64
+	GuildID    string
+	UserID     string
+	TargetID   string
+	ActionType int
+	Changes    string
+	Options    string
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// AuditLogFilter narrows a guild's audit log; zero fields match every entry
+type AuditLogFilter struct {
+	UserID     string
+	ActionType int
+	Before     int64
+	After      int64
+	Limit      int
+}
+
 // generateSnowflake creates a Discord-like snowflake ID (simplified)
 func generateSnowflake() string {
 	var n uint64
@@ -136,7 +137,13 @@ func (s *DiscordStore) GetOrCreateWebhook(id, token string) (*Webhook, error) {
 
 	query := `INSERT INTO discord_webhooks (id, token, type, name, channel_id, guild_id, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err = s.db.Exec(query, webhook.ID, webhook.Token, webhook.Type, webhook.Name, webhook.ChannelID, webhook.GuildID, webhook.CreatedAt, webhook.UpdatedAt)
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, webhook.ID, webhook.Token, webhook.Type, webhook.Name, webhook.ChannelID, webhook.GuildID, webhook.CreatedAt, webhook.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "webhook", webhook.ID, core.AuditCreate, webhook.ID, map[string]any{"name": webhook.Name})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -171,17 +178,35 @@ func (s *DiscordStore) GetWebhook(id, token string) (*Webhook, error) {
 	return webhook, nil
 }
 
+// GetWebhookByID looks up a live webhook without its token
+func (s *DiscordStore) GetWebhookByID(id string) (*Webhook, error) {
+	var token string
+	err := s.db.QueryRow(`SELECT token FROM discord_webhooks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&token)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetWebhook(id, token)
+}
+
 func (s *DiscordStore) UpdateWebhook(webhook *Webhook) error {
 	query := `UPDATE discord_webhooks SET name = ?, avatar = ?, updated_at = ? WHERE id = ? AND token = ?`
 	webhook.UpdatedAt = time.Now()
-	_, err := s.db.Exec(query, webhook.Name, webhook.Avatar, webhook.UpdatedAt, webhook.ID, webhook.Token)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(query, webhook.Name, webhook.Avatar, webhook.UpdatedAt, webhook.ID, webhook.Token); err != nil {
+			return err
+		}
+		return auditIn(tx, "webhook", webhook.ID, core.AuditUpdate, webhook.ID, map[string]any{"name": webhook.Name, "avatar": webhook.Avatar})
+	})
 }
 
 func (s *DiscordStore) DeleteWebhook(id, token string) error {
 	query := `UPDATE discord_webhooks SET deleted_at = ? WHERE id = ? AND token = ?`
-	_, err := s.db.Exec(query, time.Now(), id, token)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(query, time.Now(), id, token); err != nil {
+			return err
+		}
+		return auditIn(tx, "webhook", id, core.AuditDelete, id, nil)
+	})
 }
 
 func (s *DiscordStore) CreateMessage(msg *WebhookMessage) error {
@@ -195,12 +220,27 @@ func (s *DiscordStore) CreateMessage(msg *WebhookMessage) error {
 		(id, webhook_id, content, username, avatar_url, embeds, components, attachments, thread_id, flags, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := s.db.Exec(query,
-		msg.ID, msg.WebhookID, msg.Content, msg.Username, msg.AvatarURL,
-		msg.Embeds, msg.Components, msg.Attachments, msg.ThreadID, msg.Flags,
-		msg.CreatedAt, msg.UpdatedAt,
-	)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(query,
+			msg.ID, msg.WebhookID, msg.Content, msg.Username, msg.AvatarURL,
+			msg.Embeds, msg.Components, msg.Attachments, msg.ThreadID, msg.Flags,
+			msg.CreatedAt, msg.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "message", msg.ID, core.AuditCreate, msg.WebhookID, map[string]any{"webhook_id": msg.WebhookID, "content": msg.Content})
+	})
+}
+
+// GetMessageByID looks up a live message without its webhook ID
+func (s *DiscordStore) GetMessageByID(id string) (*WebhookMessage, error) {
+	var webhookID string
+	err := s.db.QueryRow(`SELECT webhook_id FROM discord_webhook_messages WHERE id = ? AND deleted_at IS NULL`, id).Scan(&webhookID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMessage(webhookID, id)
 }
 
 func (s *DiscordStore) GetMessage(webhookID, messageID string) (*WebhookMessage, error) {
@@ -261,17 +301,26 @@ func (s *DiscordStore) UpdateMessage(msg *WebhookMessage) error {
 		SET content = ?, username = ?, avatar_url = ?, embeds = ?, components = ?, attachments = ?, updated_at = ?, edited_at = ?
 		WHERE webhook_id = ? AND id = ?`
 
-	_, err := s.db.Exec(query,
-		msg.Content, msg.Username, msg.AvatarURL, msg.Embeds, msg.Components, msg.Attachments,
-		msg.UpdatedAt, msg.EditedAt, msg.WebhookID, msg.ID,
-	)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(query,
+			msg.Content, msg.Username, msg.AvatarURL, msg.Embeds, msg.Components, msg.Attachments,
+			msg.UpdatedAt, msg.EditedAt, msg.WebhookID, msg.ID,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "message", msg.ID, core.AuditUpdate, msg.WebhookID, map[string]any{"content": msg.Content})
+	})
 }
 
 func (s *DiscordStore) DeleteMessage(webhookID, messageID string) error {
 	query := `UPDATE discord_webhook_messages SET deleted_at = ? WHERE webhook_id = ? AND id = ?`
-	_, err := s.db.Exec(query, time.Now(), webhookID, messageID)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(query, time.Now(), webhookID, messageID); err != nil {
+			return err
+		}
+		return auditIn(tx, "message", messageID, core.AuditDelete, webhookID, map[string]any{"webhook_id": webhookID})
+	})
 }
 
 func (s *DiscordStore) ListMessages(webhookID string, limit int) ([]*WebhookMessage, error) {
@@ -334,6 +383,74 @@ func (s *DiscordStore) ListMessages(webhookID string, limit int) ([]*WebhookMess
 	return messages, rows.Err()
 }
 
+// CreateAuditLogEntry records an entry in a guild's audit log
+func (s *DiscordStore) CreateAuditLogEntry(entry *AuditLogEntry) error {
+	entry.ID = nextAuditLogID()
+	entry.CreatedAt = time.Now()
+
+	query := `INSERT INTO discord_audit_logs (id, guild_id, user_id, target_id, action_type, changes, options, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.db.Exec(query,
+		entry.ID, entry.GuildID, entry.UserID, entry.TargetID, entry.ActionType,
+		entry.Changes, entry.Options, entry.Reason, entry.CreatedAt,
+	)
+	return err
+}
+
+// ListAuditLogEntries returns a guild's audit log entries, newest first, or
+// oldest first when paging forward with After
+func (s *DiscordStore) ListAuditLogEntries(guildID string, filter AuditLogFilter) ([]*AuditLogEntry, error) {
+	query := `SELECT id, guild_id, user_id, target_id, action_type, changes, options, reason, created_at
+		FROM discord_audit_logs WHERE guild_id = ?`
+	args := []any{guildID}
+	if filter.UserID != "" {
+		query += " AND user_id = ?"
+		args = append(args, filter.UserID)
+	}
+	if filter.ActionType != 0 {
+		query += " AND action_type = ?"
+		args = append(args, filter.ActionType)
+	}
+	if filter.Before != 0 {
+		query += " AND id < ?"
+		args = append(args, filter.Before)
+	}
+	if filter.After != 0 {
+		query += " AND id > ? ORDER BY id ASC"
+		args = append(args, filter.After)
+	} else {
+		query += " ORDER BY id DESC"
+	}
+	query += " LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditLogEntry
+	for rows.Next() {
+		entry := &AuditLogEntry{}
+		var userID, targetID, changes, options, reason sql.NullString
+		err := rows.Scan(
+			&entry.ID, &entry.GuildID, &userID, &targetID, &entry.ActionType,
+			&changes, &options, &reason, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.UserID = userID.String
+		entry.TargetID = targetID.String
+		entry.Changes = changes.String
+		entry.Options = options.String
+		entry.Reason = reason.String
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
 // ListAllWebhooks retrieves webhooks across all accounts for admin view
 func (s *DiscordStore) ListAllWebhooks(limit, offset int) ([]*Webhook, error) {
 	query := `SELECT id, token, type, name, avatar, channel_id, guild_id, application_id, created_at, updated_at, deleted_at
@@ -447,3 +564,8 @@ func (s *DiscordStore) ListAllMessages(limit, offset int) ([]*WebhookMessage, er
 
 	return messages, nil
 }
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *DiscordStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db, "discord_webhooks", "discord_webhook_messages", "discord_audit_logs")
+}
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/2389/ish/internal/migrations"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -15,6 +16,9 @@ func setupTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
 	return db
 }
 
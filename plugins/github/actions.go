@@ -0,0 +1,438 @@
+// ABOUTME: GitHub Actions workflow and workflow run endpoints
+// ABOUTME: Dispatched runs start queued and complete successfully after a configurable delay
+
+package github
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultWorkflowRunDelay is how long a dispatched run stays queued before it
+// completes, unless the workflow_run_delay setting says otherwise
+const defaultWorkflowRunDelay = 2 * time.Second
+
+// Workflow run conclusions; with checkRunStatuses these are the values the
+// status filter accepts
+var workflowRunConclusions = []string{"success", "failure", "cancelled"}
+
+// Configure implements core.Configurable. The workflow_run_delay and
+// installation_token_ttl custom settings are Go durations such as "500ms"
+// or "10m".
+func (p *GitHubPlugin) Configure(config core.PluginConfig) error {
+	if value, ok := config.CustomSettings["workflow_run_delay"]; ok {
+		delay, err := time.ParseDuration(value)
+		if err != nil || delay < 0 {
+			return fmt.Errorf("workflow_run_delay must be a non-negative duration, got %q", value)
+		}
+		p.workflowRunDelay = &delay
+	}
+	if value, ok := config.CustomSettings["installation_token_ttl"]; ok {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("installation_token_ttl must be a positive duration, got %q", value)
+		}
+		p.installationTokenTTL = &ttl
+	}
+	return nil
+}
+
+// runDelay is how long dispatched workflow runs stay queued
+func (p *GitHubPlugin) runDelay() time.Duration {
+	if p.workflowRunDelay != nil {
+		return *p.workflowRunDelay
+	}
+	return defaultWorkflowRunDelay
+}
+
+// listWorkflows handles GET /repos/{owner}/{repo}/actions/workflows
+func (p *GitHubPlugin) listWorkflows(w http.ResponseWriter, r *http.Request) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	workflows, err := p.store.ListWorkflows(repo.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list workflows")
+		return
+	}
+
+	items := make([]map[string]interface{}, 0, len(workflows))
+	for _, workflow := range workflows {
+		items = append(items, workflowToResponse(workflow, repo))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total_count": len(workflows),
+		"workflows":   items,
+	})
+}
+
+// dispatchWorkflow handles POST /repos/{owner}/{repo}/actions/workflows/{workflow_id}/dispatches.
+// {workflow_id} may also be the workflow's file name.
+func (p *GitHubPlugin) dispatchWorkflow(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	var req struct {
+		Ref    string                 `json:"ref"`
+		Inputs map[string]interface{} `json:"inputs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Ref == "" {
+		writeValidationError(w, "WorkflowDispatch", "ref", "missing_field")
+		return
+	}
+
+	repo, workflow, ok := p.lookupWorkflow(w, r)
+	if !ok {
+		return
+	}
+
+	sha, err := p.store.ResolveRef(repo.ID, req.Ref)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to resolve ref")
+		return
+	}
+	if req.Inputs == nil {
+		req.Inputs = map[string]interface{}{}
+	}
+	inputs, err := json.Marshal(req.Inputs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode inputs")
+		return
+	}
+
+	run := &WorkflowRun{
+		RepoID:     repo.ID,
+		WorkflowID: workflow.ID,
+		Event:      "workflow_dispatch",
+		HeadBranch: req.Ref,
+		HeadSHA:    stableRefSHA(repo, sha),
+		Status:     "queued",
+		ActorID:    user.ID,
+		Inputs:     string(inputs),
+	}
+	if err := p.store.CreateWorkflowRun(run); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create workflow run")
+		return
+	}
+
+	// workflow_dispatch is delivered before the run's requested event
+	sender := map[string]interface{}{"login": user.Login, "id": user.ID}
+	dispatched := map[string]interface{}{
+		"inputs":   req.Inputs,
+		"ref":      "refs/heads/" + req.Ref,
+		"workflow": workflow.Path,
+		"repository": map[string]interface{}{
+			"id":        repo.ID,
+			"name":      repo.Name,
+			"full_name": repo.FullName,
+		},
+		"sender": sender,
+	}
+	requested := map[string]interface{}{
+		"action":       "requested",
+		"workflow_run": workflowRunToResponse(run, workflow, repo, user),
+		"sender":       sender,
+	}
+	if !p.dryRun {
+		p.deliveries.Add(1)
+		go func() {
+			defer p.deliveries.Done()
+			p.fireWebhooksForEvent(repo.ID, "workflow_dispatch", dispatched)
+			p.fireWebhooksForEvent(repo.ID, "workflow_run", requested)
+		}()
+	}
+	p.completeWorkflowRunLater(run, workflow, repo, user)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// completeWorkflowRunLater finishes a queued run successfully once the run
+// delay has passed, tracked so Shutdown can wait for it
+func (p *GitHubPlugin) completeWorkflowRunLater(run *WorkflowRun, workflow *Workflow, repo *Repository, actor *User) {
+	delay := p.runDelay()
+	p.deliveries.Add(1)
+	go func() {
+		defer p.deliveries.Done()
+		time.Sleep(delay)
+
+		// The run may have been truncated away while it was queued
+		current, err := p.store.GetWorkflowRun(run.RepoID, run.ID)
+		if err != nil || current.Status == "completed" {
+			return
+		}
+
+		now := p.store.now()
+		current.Status = "completed"
+		current.Conclusion = "success"
+		current.StartedAt = &now
+		current.CompletedAt = &now
+		if err := p.store.UpdateWorkflowRun(current); err != nil {
+			return
+		}
+
+		p.fireWebhooksForEvent(repo.ID, "workflow_run", map[string]interface{}{
+			"action":       "completed",
+			"workflow_run": workflowRunToResponse(current, workflow, repo, actor),
+			"sender":       map[string]interface{}{"login": actor.Login, "id": actor.ID},
+		})
+	}()
+}
+
+// listWorkflowRuns handles GET /repos/{owner}/{repo}/actions/runs and
+// GET /repos/{owner}/{repo}/actions/workflows/{workflow_id}/runs
+func (p *GitHubPlugin) listWorkflowRuns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := WorkflowRunFilter{
+		Branch:  query.Get("branch"),
+		Event:   query.Get("event"),
+		Status:  query.Get("status"),
+		HeadSHA: query.Get("head_sha"),
+	}
+	if filter.Status != "" && !slices.Contains(checkRunStatuses, filter.Status) && !slices.Contains(workflowRunConclusions, filter.Status) {
+		writeValidationError(w, "WorkflowRun", "status", "invalid")
+		return
+	}
+
+	var repo *Repository
+	if chi.URLParam(r, "workflow_id") != "" {
+		var workflow *Workflow
+		var ok bool
+		if repo, workflow, ok = p.lookupWorkflow(w, r); !ok {
+			return
+		}
+		filter.WorkflowID = workflow.ID
+	} else {
+		fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+		var err error
+		if repo, err = p.store.GetRepositoryByFullName(fullName); err != nil {
+			writeError(w, http.StatusNotFound, "repository not found")
+			return
+		}
+	}
+
+	perPage := queryInt(r, "per_page", 30)
+	if perPage > 100 {
+		perPage = 100
+	}
+	page := queryInt(r, "page", 1)
+
+	runs, total, err := p.store.ListWorkflowRuns(repo.ID, filter, perPage, (page-1)*perPage)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list workflow runs")
+		return
+	}
+
+	items := make([]map[string]interface{}, 0, len(runs))
+	for _, run := range runs {
+		items = append(items, p.workflowRunResponse(run, repo))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total_count":   total,
+		"workflow_runs": items,
+	})
+}
+
+// getWorkflowRun handles GET /repos/{owner}/{repo}/actions/runs/{run_id}
+func (p *GitHubPlugin) getWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	repo, run, ok := p.lookupWorkflowRun(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.workflowRunResponse(run, repo))
+}
+
+// listWorkflowRunJobs handles GET /repos/{owner}/{repo}/actions/runs/{run_id}/jobs.
+// Every run has a single build job whose state follows the run's.
+func (p *GitHubPlugin) listWorkflowRunJobs(w http.ResponseWriter, r *http.Request) {
+	repo, run, ok := p.lookupWorkflowRun(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total_count": 1,
+		"jobs":        []map[string]interface{}{workflowJobToResponse(run, repo)},
+	})
+}
+
+// lookupWorkflow resolves the repository and {workflow_id} (an ID or file
+// name) from the URL, writing an error response if either doesn't exist
+func (p *GitHubPlugin) lookupWorkflow(w http.ResponseWriter, r *http.Request) (*Repository, *Workflow, bool) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return nil, nil, false
+	}
+
+	workflow, err := p.store.GetWorkflow(repo.ID, chi.URLParam(r, "workflow_id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "workflow not found")
+		return nil, nil, false
+	}
+
+	return repo, workflow, true
+}
+
+// lookupWorkflowRun resolves the repository and {run_id} from the URL,
+// writing an error response if either doesn't exist
+func (p *GitHubPlugin) lookupWorkflowRun(w http.ResponseWriter, r *http.Request) (*Repository, *WorkflowRun, bool) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return nil, nil, false
+	}
+
+	var id int64
+	if _, err := fmt.Sscanf(chi.URLParam(r, "run_id"), "%d", &id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid run id")
+		return nil, nil, false
+	}
+
+	run, err := p.store.GetWorkflowRun(repo.ID, id)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, "workflow run not found")
+		return nil, nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get workflow run")
+		return nil, nil, false
+	}
+
+	return repo, run, true
+}
+
+// workflowRunResponse looks up a run's workflow and actor to render it
+func (p *GitHubPlugin) workflowRunResponse(run *WorkflowRun, repo *Repository) map[string]interface{} {
+	workflow, err := p.store.GetWorkflow(repo.ID, strconv.FormatInt(run.WorkflowID, 10))
+	if err != nil {
+		workflow = &Workflow{ID: run.WorkflowID}
+	}
+	actor, _ := p.store.GetUserByID(run.ActorID)
+	return workflowRunToResponse(run, workflow, repo, actor)
+}
+
+// queryInt reads a positive integer query parameter, falling back to def
+func queryInt(r *http.Request, name string, def int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || n < 1 {
+		return def
+	}
+	return n
+}
+
+func workflowToResponse(workflow *Workflow, repo *Repository) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         workflow.ID,
+		"name":       workflow.Name,
+		"path":       workflow.Path,
+		"state":      workflow.State,
+		"created_at": workflow.CreatedAt.Format(time.RFC3339),
+		"updated_at": workflow.UpdatedAt.Format(time.RFC3339),
+		"url":        fmt.Sprintf("/repos/%s/actions/workflows/%d", repo.FullName, workflow.ID),
+		"badge_url":  fmt.Sprintf("/%s/workflows/%s/badge.svg", repo.FullName, workflow.Name),
+	}
+}
+
+func workflowRunToResponse(run *WorkflowRun, workflow *Workflow, repo *Repository, actor *User) map[string]interface{} {
+	url := fmt.Sprintf("/repos/%s/actions/runs/%d", repo.FullName, run.ID)
+	response := map[string]interface{}{
+		"id":             run.ID,
+		"name":           workflow.Name,
+		"path":           workflow.Path,
+		"workflow_id":    run.WorkflowID,
+		"run_number":     run.RunNumber,
+		"event":          run.Event,
+		"head_branch":    run.HeadBranch,
+		"head_sha":       run.HeadSHA,
+		"status":         run.Status,
+		"conclusion":     nilIfEmpty(run.Conclusion),
+		"created_at":     run.CreatedAt.Format(time.RFC3339),
+		"updated_at":     run.UpdatedAt.Format(time.RFC3339),
+		"run_started_at": nil,
+		"url":            url,
+		"jobs_url":       url + "/jobs",
+		"workflow_url":   fmt.Sprintf("/repos/%s/actions/workflows/%d", repo.FullName, run.WorkflowID),
+	}
+
+	if run.StartedAt != nil {
+		response["run_started_at"] = run.StartedAt.Format(time.RFC3339)
+	}
+	if actor != nil {
+		response["actor"] = map[string]interface{}{
+			"login": actor.Login,
+			"id":    actor.ID,
+			"type":  actor.Type,
+		}
+	}
+
+	return response
+}
+
+// workflowJobSteps are the steps of the single job every run has
+var workflowJobSteps = []string{"Set up job", "Run actions/checkout@v4", "Run tests", "Complete job"}
+
+func workflowJobToResponse(run *WorkflowRun, repo *Repository) map[string]interface{} {
+	var startedAt, completedAt interface{}
+	if run.StartedAt != nil {
+		startedAt = run.StartedAt.Format(time.RFC3339)
+	}
+	if run.CompletedAt != nil {
+		completedAt = run.CompletedAt.Format(time.RFC3339)
+	}
+
+	steps := make([]map[string]interface{}, len(workflowJobSteps))
+	for i, name := range workflowJobSteps {
+		steps[i] = map[string]interface{}{
+			"name":         name,
+			"number":       i + 1,
+			"status":       run.Status,
+			"conclusion":   nilIfEmpty(run.Conclusion),
+			"started_at":   startedAt,
+			"completed_at": completedAt,
+		}
+	}
+
+	return map[string]interface{}{
+		"id":           run.ID,
+		"run_id":       run.ID,
+		"name":         "build",
+		"head_branch":  run.HeadBranch,
+		"head_sha":     run.HeadSHA,
+		"status":       run.Status,
+		"conclusion":   nilIfEmpty(run.Conclusion),
+		"created_at":   run.CreatedAt.Format(time.RFC3339),
+		"started_at":   startedAt,
+		"completed_at": completedAt,
+		"url":          fmt.Sprintf("/repos/%s/actions/jobs/%d", repo.FullName, run.ID),
+		"run_url":      fmt.Sprintf("/repos/%s/actions/runs/%d", repo.FullName, run.ID),
+		"steps":        steps,
+	}
+}
@@ -0,0 +1,115 @@
+// ABOUTME: Tests for GitHub Actions workflow and workflow run endpoints
+// ABOUTME: Covers dispatching a workflow through queued to completed, run filters, and jobs
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func TestWorkflowDispatchLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+	if err := plugin.Configure(core.PluginConfig{CustomSettings: map[string]string{"workflow_run_delay": "50ms"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	defer plugin.Shutdown(context.Background())
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	workflow, _ := store.CreateWorkflow(repo.ID, "CI", ".github/workflows/ci.yml")
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/actions/workflows", "")
+	var workflows struct {
+		TotalCount int                      `json:"total_count"`
+		Workflows  []map[string]interface{} `json:"workflows"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &workflows)
+	if w.Code != http.StatusOK || workflows.TotalCount != 1 || workflows.Workflows[0]["path"] != ".github/workflows/ci.yml" {
+		t.Fatalf("Expected the CI workflow, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/actions/workflows/ci.yml/dispatches", `{}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Dispatch without ref got %d, want 422", w.Code)
+	}
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/actions/workflows/missing.yml/dispatches", `{"ref": "main"}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Dispatch of a missing workflow got %d, want 404", w.Code)
+	}
+
+	// Workflows can be dispatched by file name or ID
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/actions/workflows/ci.yml/dispatches", `{"ref": "main"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Dispatch got %d: %s", w.Code, w.Body.String())
+	}
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/actions/workflows/"+strconv.FormatInt(workflow.ID, 10)+"/dispatches", `{"ref": "main"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Dispatch by ID got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/actions/runs?status=queued", "")
+	var runs struct {
+		TotalCount   int                      `json:"total_count"`
+		WorkflowRuns []map[string]interface{} `json:"workflow_runs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &runs)
+	if runs.TotalCount != 2 {
+		t.Fatalf("Expected 2 queued runs, got %d: %s", runs.TotalCount, w.Body.String())
+	}
+	run := runs.WorkflowRuns[0]
+	if run["run_number"] != float64(2) || run["event"] != "workflow_dispatch" || run["conclusion"] != nil || !fullSHAPattern.MatchString(run["head_sha"].(string)) {
+		t.Errorf("Unexpected queued run: %v", run)
+	}
+	runPath := "/repos/alice/test-repo/actions/runs/" + strconv.FormatInt(int64(run["id"].(float64)), 10)
+
+	plugin.Shutdown(context.Background())
+
+	w = serveGitHub(plugin, "GET", runPath, "")
+	json.Unmarshal(w.Body.Bytes(), &run)
+	if w.Code != http.StatusOK || run["status"] != "completed" || run["conclusion"] != "success" || run["run_started_at"] == nil {
+		t.Fatalf("Expected a successful completed run, got %d: %v", w.Code, run)
+	}
+
+	w = serveGitHub(plugin, "GET", runPath+"/jobs", "")
+	var jobs struct {
+		TotalCount int `json:"total_count"`
+		Jobs       []struct {
+			Status     string                   `json:"status"`
+			Conclusion string                   `json:"conclusion"`
+			Steps      []map[string]interface{} `json:"steps"`
+		} `json:"jobs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &jobs)
+	if jobs.TotalCount != 1 || jobs.Jobs[0].Conclusion != "success" || len(jobs.Jobs[0].Steps) == 0 {
+		t.Errorf("Unexpected jobs: %s", w.Body.String())
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/actions/runs/999", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Missing run got %d, want 404", w.Code)
+	}
+}
+
+func TestConfigureWorkflowRunDelay(t *testing.T) {
+	plugin := &GitHubPlugin{}
+	if plugin.runDelay() != defaultWorkflowRunDelay {
+		t.Errorf("runDelay() = %v, want the default", plugin.runDelay())
+	}
+	if err := plugin.Configure(core.PluginConfig{CustomSettings: map[string]string{"workflow_run_delay": "0s"}}); err != nil || plugin.runDelay() != 0 {
+		t.Errorf("Configure(0s) = %v, runDelay() = %v", err, plugin.runDelay())
+	}
+	for _, bad := range []string{"soon", "-1s"} {
+		if err := plugin.Configure(core.PluginConfig{CustomSettings: map[string]string{"workflow_run_delay": bad}}); err == nil {
+			t.Errorf("Configure(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// ABOUTME: Repository archive endpoints for GitHub tarball and zipball downloads
+// ABOUTME: Redirects to a per-commit archive URL and builds a one-file archive on the fly
+
+package github
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// commitSHAPattern matches a full hex commit SHA
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// archiveFormats maps the download endpoint to its archive file extension
+var archiveFormats = map[string]string{
+	"tarball": "tar.gz",
+	"zipball": "zip",
+}
+
+// downloadArchive handles GET /repos/{owner}/{repo}/{format}/{ref}, where format
+// is tarball or zipball, by redirecting to the archive for the ref's commit
+func (p *GitHubPlugin) downloadArchive(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner := chi.URLParam(r, "owner")
+		repoName := chi.URLParam(r, "repo")
+
+		repo, err := p.store.GetRepositoryByFullName(owner + "/" + repoName)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "repository not found")
+			return
+		}
+
+		ref := chi.URLParam(r, "ref")
+		if ref == "" {
+			ref = repo.DefaultBranch
+		}
+		sha, err := p.store.ResolveRef(repo.ID, ref)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to resolve ref")
+			return
+		}
+		sha = stableRefSHA(repo, sha)
+
+		http.Redirect(w, r, fmt.Sprintf("%s/archives/%s/%s/%s.%s", core.BasePath(r), owner, repoName, sha, archiveFormats[format]), http.StatusFound)
+	}
+}
+
+// stableRefSHA returns a resolved ref unchanged if it is a commit SHA.
+// Repositories without recorded commits still need a stable SHA per ref, so
+// anything else is hashed with the repository name.
+func stableRefSHA(repo *Repository, resolved string) string {
+	if commitSHAPattern.MatchString(resolved) {
+		return resolved
+	}
+	sum := sha1.Sum([]byte(repo.FullName + "@" + resolved))
+	return hex.EncodeToString(sum[:])
+}
+
+// serveArchive handles GET /archives/{owner}/{repo}/{sha}.tar.gz and .zip.
+// Archives are public like GitHub's codeload links, except for private
+// repositories, which still need a token.
+func (p *GitHubPlugin) serveArchive(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner := chi.URLParam(r, "owner")
+		repoName := chi.URLParam(r, "repo")
+		sha := chi.URLParam(r, "sha")
+
+		repo, err := p.store.GetRepositoryByFullName(owner + "/" + repoName)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "repository not found")
+			return
+		}
+		if repo.Private {
+			token, ok := extractToken(r)
+			if !ok {
+				writeError(w, http.StatusNotFound, "repository not found")
+				return
+			}
+			if _, err := p.store.ValidateToken(token); err != nil {
+				writeError(w, http.StatusUnauthorized, "bad credentials")
+				return
+			}
+		}
+
+		// GitHub archives hold everything under an {owner}-{repo}-{short sha} directory
+		shortSHA := sha
+		if len(shortSHA) > 7 {
+			shortSHA = shortSHA[:7]
+		}
+		prefix := fmt.Sprintf("%s-%s-%s", owner, repoName, shortSHA)
+		readme := []byte(fmt.Sprintf("# %s\n", repo.Name))
+		if repo.Description != "" {
+			readme = append(readme, fmt.Sprintf("\n%s\n", repo.Description)...)
+		}
+
+		filename := fmt.Sprintf("%s.%s", prefix, archiveFormats[format])
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+
+		var writeErr error
+		if format == "zipball" {
+			w.Header().Set("Content-Type", "application/zip")
+			writeErr = writeZipArchive(w, prefix, readme, repo.UpdatedAt)
+		} else {
+			w.Header().Set("Content-Type", "application/x-gzip")
+			writeErr = writeTarGzArchive(w, prefix, readme, repo.UpdatedAt)
+		}
+		if writeErr != nil {
+			// Headers are already sent, so the client sees a truncated archive
+			log.Printf("Writing archive %s: %v", filename, writeErr)
+		}
+	}
+}
+
+// writeTarGzArchive writes a gzipped tar holding prefix/README.md
+func writeTarGzArchive(w io.Writer, prefix string, readme []byte, modTime time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := tw.WriteHeader(&tar.Header{Name: prefix + "/", Typeflag: tar.TypeDir, Mode: 0o755, ModTime: modTime}); err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: prefix + "/README.md", Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(readme)), ModTime: modTime}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(readme); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeZipArchive writes a zip holding prefix/README.md
+func writeZipArchive(w io.Writer, prefix string, readme []byte, modTime time.Time) error {
+	zw := zip.NewWriter(w)
+	if _, err := zw.CreateHeader(&zip.FileHeader{Name: prefix + "/", Modified: modTime}); err != nil {
+		return err
+	}
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: prefix + "/README.md", Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(readme); err != nil {
+		return err
+	}
+	return zw.Close()
+}
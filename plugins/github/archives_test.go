@@ -0,0 +1,179 @@
+// ABOUTME: Tests for GitHub tarball and zipball downloads
+// ABOUTME: Follows the redirect through a live router and unpacks the generated archives
+
+package github
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+func setupArchiveServer(t *testing.T) (*GitHubStore, *httptest.Server) {
+	t.Helper()
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	// Each :memory: connection is its own database, so the server must reuse one
+	db.SetMaxOpenConns(1)
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return store, srv
+}
+
+// download fetches path with a token, following the redirect to the archive
+func download(t *testing.T, srv *httptest.Server, path, token string) (*http.Response, []byte) {
+	t.Helper()
+	req, _ := http.NewRequest("GET", srv.URL+path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, body
+}
+
+func TestTarballDownload(t *testing.T) {
+	store, srv := setupArchiveServer(t)
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "widgets", "Widget factory", false)
+
+	resp, body := download(t, srv, "/repos/alice/widgets/tarball/main", "ghp_alice")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 after redirect, got %d: %s", resp.StatusCode, body)
+	}
+	path := resp.Request.URL.Path
+	if !strings.HasPrefix(path, "/archives/alice/widgets/") || !strings.HasSuffix(path, ".tar.gz") {
+		t.Fatalf("Expected redirect to /archives/alice/widgets/{sha}.tar.gz, got %s", path)
+	}
+	sha := strings.TrimSuffix(strings.TrimPrefix(path, "/archives/alice/widgets/"), ".tar.gz")
+	if !commitSHAPattern.MatchString(sha) {
+		t.Errorf("Expected a 40 character SHA in the archive URL, got %q", sha)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Tarball is not gzipped: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var readme string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Reading tar: %v", err)
+		}
+		if hdr.Name == "alice-widgets-"+sha[:7]+"/README.md" {
+			content, _ := io.ReadAll(tr)
+			readme = string(content)
+		}
+	}
+	if !strings.Contains(readme, "widgets") {
+		t.Errorf("Expected README.md naming the repository, got %q", readme)
+	}
+
+	// The same ref always maps to the same archive
+	again, _ := download(t, srv, "/repos/alice/widgets/tarball", "ghp_alice")
+	if again.Request.URL.Path != path {
+		t.Errorf("Expected default branch to redirect to %s, got %s", path, again.Request.URL.Path)
+	}
+}
+
+func TestZipballDownload(t *testing.T) {
+	store, srv := setupArchiveServer(t)
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "widgets", "", false)
+
+	resp, body := download(t, srv, "/repos/alice/widgets/zipball/"+testSHA, "ghp_alice")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 after redirect, got %d: %s", resp.StatusCode, body)
+	}
+	if want := "/archives/alice/widgets/" + testSHA + ".zip"; resp.Request.URL.Path != want {
+		t.Errorf("Expected a commit SHA ref to be used as is, got %s", resp.Request.URL.Path)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("Zipball is not a valid zip: %v", err)
+	}
+	var readme string
+	for _, f := range zr.File {
+		if f.Name == "alice-widgets-6dcb09b/README.md" {
+			rc, _ := f.Open()
+			content, _ := io.ReadAll(rc)
+			rc.Close()
+			readme = string(content)
+		}
+	}
+	if !strings.Contains(readme, "widgets") {
+		t.Errorf("Expected README.md naming the repository, got %q", readme)
+	}
+}
+
+func TestArchiveRedirectKeepsBasePath(t *testing.T) {
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "widgets", "", false)
+
+	// Mounted under a base path, as the server does with ISH_BASE_PATH
+	routes := chi.NewRouter()
+	plugin.RegisterRoutes(routes)
+	root := chi.NewRouter()
+	root.Use(core.WithBasePath("/ish"))
+	root.Mount("/ish", routes)
+	srv := httptest.NewServer(root)
+	t.Cleanup(srv.Close)
+
+	resp, body := download(t, srv, "/ish/repos/alice/widgets/zipball/"+testSHA, "ghp_alice")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 after redirect, got %d: %s", resp.StatusCode, body)
+	}
+	if want := "/ish/archives/alice/widgets/" + testSHA + ".zip"; resp.Request.URL.Path != want {
+		t.Errorf("Expected redirect to %s, got %s", want, resp.Request.URL.Path)
+	}
+}
+
+func TestArchiveAccess(t *testing.T) {
+	store, srv := setupArchiveServer(t)
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "public-repo", "", false)
+	store.CreateRepository(alice.ID, "private-repo", "", true)
+
+	if resp, _ := download(t, srv, "/repos/alice/public-repo/tarball/main", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the API endpoint to require a token, got %d", resp.StatusCode)
+	}
+	if resp, _ := download(t, srv, "/archives/alice/public-repo/"+testSHA+".tar.gz", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected public archives without a token, got %d", resp.StatusCode)
+	}
+	if resp, _ := download(t, srv, "/archives/alice/private-repo/"+testSHA+".tar.gz", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected private archives to be hidden without a token, got %d", resp.StatusCode)
+	}
+	if resp, _ := download(t, srv, "/repos/alice/private-repo/zipball/main", "ghp_alice"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected private archives with a token, got %d", resp.StatusCode)
+	}
+	if resp, _ := download(t, srv, "/repos/alice/missing/tarball/main", "ghp_alice"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing repository, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,84 @@
+// ABOUTME: Tests for the GitHub plugin's audit logging
+// ABOUTME: Drives each mutating route through the router and checks it leaves an audit row naming the actor
+
+package github
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMutatingRoutesAreAudited(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+	defer plugin.deliveries.Wait()
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.GetOrCreateUser("bob", "ghp_bob")
+	repo, _ := store.CreateRepository(alice.ID, "widgets", "", false)
+	issue, _, _ := store.CreatePullRequest(repo.ID, alice.ID, "Add widgets", "", "feature", "main")
+	review, _ := store.CreateReview(issue.ID, alice.ID, "APPROVED", "LGTM")
+
+	inst, err := store.CreateInstallation(alice.ID, map[string]string{"issues": "write"})
+	if err != nil {
+		t.Fatalf("CreateInstallation failed: %v", err)
+	}
+
+	reviewPath := "/repos/alice/widgets/pulls/" + strconv.FormatInt(issue.Number, 10) + "/reviews/" + strconv.FormatInt(review.ID, 10)
+	templatesPath := "/admin/github/repos/" + strconv.FormatInt(repo.ID, 10) + "/templates"
+	tokensPath := "/app/installations/" + strconv.FormatInt(inst.ID, 10) + "/access_tokens"
+
+	tests := []struct {
+		method, path, token, body string
+		resourceType, action      string
+		actor                     string
+	}{
+		{"POST", "/repos/alice/widgets/forks", "ghp_bob", "", "repository", core.AuditCreate, "bob"},
+		{"PUT", reviewPath, "ghp_alice", "{}", "review", core.AuditUpdate, "alice"},
+		{"DELETE", reviewPath, "ghp_bob", "", "review", core.AuditUpdate, "bob"},
+		{"POST", templatesPath, "", `{"type":"issue","content":"Steps:"}`, "repo_template", core.AuditUpdate, ""},
+		{"POST", templatesPath, "", `{"type":"issue","content":""}`, "repo_template", core.AuditDelete, ""},
+		{"POST", tokensPath, appJWT, "", "installation_token", core.AuditCreate, appSlug + "[bot]"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code >= 300 {
+			t.Fatalf("%s %s: got %d: %s", tt.method, tt.path, w.Code, w.Body.String())
+		}
+
+		entries, err := core.ListAudit(db, core.AuditQuery{PluginName: "github", ResourceType: tt.resourceType, Limit: 1})
+		if err != nil {
+			t.Fatalf("ListAudit failed: %v", err)
+		}
+		if len(entries) == 0 || entries[0].Action != tt.action || entries[0].Actor != tt.actor {
+			t.Errorf("%s %s: expected a %s %s entry by %q, got %+v", tt.method, tt.path, tt.resourceType, tt.action, tt.actor, entries)
+		}
+	}
+
+	// Installations are only created by seeding, straight through the store
+	entries, _ := core.ListAudit(db, core.AuditQuery{PluginName: "github", ResourceType: "installation"})
+	if len(entries) != 1 || entries[0].Actor != "alice" || entries[0].ResourceID != strconv.FormatInt(inst.ID, 10) {
+		t.Errorf("Expected alice's installation in the audit log, got %+v", entries)
+	}
+
+	// Tokens are secrets and stay out of the log
+	entries, _ = core.ListAudit(db, core.AuditQuery{PluginName: "github", ResourceType: "installation_token"})
+	if len(entries) != 1 || strings.Contains(fmt.Sprint(entries[0].Changes), "ghs_") {
+		t.Errorf("Expected one token entry without the token, got %+v", entries)
+	}
+}
@@ -0,0 +1,279 @@
+// ABOUTME: Branch protection endpoints and their enforcement on pull request merges
+// ABOUTME: Protected branches can require approving reviews and passing status checks before merging
+
+package github
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxRequiredApprovingReviews is the most approvals a branch can require
+const maxRequiredApprovingReviews = 6
+
+// protectedBranch looks up the repository and the {branch} it names, which
+// may be URL-encoded, writing an error response if either is missing
+func (p *GitHubPlugin) protectedBranch(w http.ResponseWriter, r *http.Request) (*Repository, string, bool) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return nil, "", false
+	}
+
+	branch, err := url.PathUnescape(chi.URLParam(r, "branch"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid branch name")
+		return nil, "", false
+	}
+	exists, err := p.store.BranchExists(repo, branch)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to look up branch")
+		return nil, "", false
+	}
+	if !exists {
+		writeError(w, http.StatusNotFound, "Branch not found")
+		return nil, "", false
+	}
+	return repo, branch, true
+}
+
+// branchProtectionToResponse converts a BranchProtection to GitHub's format.
+// Required reviews and status checks only appear when they are enabled.
+func branchProtectionToResponse(bp *BranchProtection, repo *Repository) map[string]interface{} {
+	base := fmt.Sprintf("/repos/%s/branches/%s/protection", repo.FullName, url.PathEscape(bp.Branch))
+	response := map[string]interface{}{
+		"url": base,
+		"enforce_admins": map[string]interface{}{
+			"url":     base + "/enforce_admins",
+			"enabled": bp.EnforceAdmins,
+		},
+	}
+	if bp.RequireReviews {
+		response["required_pull_request_reviews"] = map[string]interface{}{
+			"url":                             base + "/required_pull_request_reviews",
+			"dismiss_stale_reviews":           bp.DismissStaleReviews,
+			"require_code_owner_reviews":      bp.RequireCodeOwnerReviews,
+			"required_approving_review_count": bp.RequiredApprovingReviewCount,
+		}
+	}
+	if bp.RequireStatusChecks {
+		checks := make([]map[string]interface{}, 0, len(bp.StatusCheckContexts))
+		for _, context := range bp.StatusCheckContexts {
+			checks = append(checks, map[string]interface{}{"context": context, "app_id": nil})
+		}
+		response["required_status_checks"] = map[string]interface{}{
+			"url":          base + "/required_status_checks",
+			"strict":       bp.StrictStatusChecks,
+			"contexts":     bp.StatusCheckContexts,
+			"checks":       checks,
+			"contexts_url": base + "/required_status_checks/contexts",
+		}
+	}
+	return response
+}
+
+// getBranchProtection handles GET /repos/{owner}/{repo}/branches/{branch}/protection
+func (p *GitHubPlugin) getBranchProtection(w http.ResponseWriter, r *http.Request) {
+	repo, branch, ok := p.protectedBranch(w, r)
+	if !ok {
+		return
+	}
+
+	bp, err := p.store.GetBranchProtection(repo.ID, branch)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "Branch not protected")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get branch protection")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(branchProtectionToResponse(bp, repo))
+}
+
+// updateBranchProtection handles PUT /repos/{owner}/{repo}/branches/{branch}/protection,
+// replacing the branch's protection. A null or missing required_status_checks or
+// required_pull_request_reviews turns that requirement off.
+func (p *GitHubPlugin) updateBranchProtection(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	var req struct {
+		RequiredStatusChecks *struct {
+			Strict   bool     `json:"strict"`
+			Contexts []string `json:"contexts"`
+			Checks   []struct {
+				Context string `json:"context"`
+			} `json:"checks"`
+		} `json:"required_status_checks"`
+		EnforceAdmins              *bool `json:"enforce_admins"`
+		RequiredPullRequestReviews *struct {
+			DismissStaleReviews          bool `json:"dismiss_stale_reviews"`
+			RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews"`
+			RequiredApprovingReviewCount *int `json:"required_approving_review_count"`
+		} `json:"required_pull_request_reviews"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	repo, branch, ok := p.protectedBranch(w, r)
+	if !ok {
+		return
+	}
+
+	bp := &BranchProtection{
+		RepoID:        repo.ID,
+		Branch:        branch,
+		EnforceAdmins: req.EnforceAdmins != nil && *req.EnforceAdmins,
+	}
+	if reviews := req.RequiredPullRequestReviews; reviews != nil {
+		bp.RequireReviews = true
+		bp.RequiredApprovingReviewCount = 1
+		if reviews.RequiredApprovingReviewCount != nil {
+			bp.RequiredApprovingReviewCount = *reviews.RequiredApprovingReviewCount
+		}
+		if bp.RequiredApprovingReviewCount < 0 || bp.RequiredApprovingReviewCount > maxRequiredApprovingReviews {
+			writeValidationError(w, "BranchProtection", "required_approving_review_count", "invalid")
+			return
+		}
+		bp.DismissStaleReviews = reviews.DismissStaleReviews
+		bp.RequireCodeOwnerReviews = reviews.RequireCodeOwnerReviews
+	}
+	if checks := req.RequiredStatusChecks; checks != nil {
+		bp.RequireStatusChecks = true
+		bp.StrictStatusChecks = checks.Strict
+		seen := map[string]bool{}
+		for _, context := range checks.Contexts {
+			if !seen[context] {
+				seen[context] = true
+				bp.StatusCheckContexts = append(bp.StatusCheckContexts, context)
+			}
+		}
+		for _, check := range checks.Checks {
+			if check.Context == "" {
+				writeValidationError(w, "BranchProtection", "checks", "missing_field")
+				return
+			}
+			if !seen[check.Context] {
+				seen[check.Context] = true
+				bp.StatusCheckContexts = append(bp.StatusCheckContexts, check.Context)
+			}
+		}
+	}
+
+	if err := p.store.SetBranchProtection(bp, user.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update branch protection")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(branchProtectionToResponse(bp, repo))
+}
+
+// deleteBranchProtection handles DELETE /repos/{owner}/{repo}/branches/{branch}/protection
+func (p *GitHubPlugin) deleteBranchProtection(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+	repo, branch, ok := p.protectedBranch(w, r)
+	if !ok {
+		return
+	}
+
+	err := p.store.DeleteBranchProtection(repo.ID, branch, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "Branch not protected")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete branch protection")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// mergeBlockedReason explains why a pull request can't be merged into its
+// protected base branch, or returns "" when protection allows the merge.
+// Protection applies to everyone, admins included.
+func (p *GitHubPlugin) mergeBlockedReason(issue *Issue, pr *PullRequest) (string, error) {
+	bp, err := p.store.GetBranchProtection(pr.BaseRepoID, pr.BaseRef)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if bp.RequireReviews {
+		reviews, err := p.store.ListReviews(issue.ID)
+		if err != nil {
+			return "", err
+		}
+		// Each reviewer's latest submitted review counts; the author's own don't
+		latest := map[int64]string{}
+		for _, review := range reviews {
+			if review.UserID == issue.UserID || review.State == "PENDING" || review.State == "COMMENTED" {
+				continue
+			}
+			latest[review.UserID] = review.State
+		}
+		approvals := 0
+		for _, state := range latest {
+			switch state {
+			case "CHANGES_REQUESTED":
+				return "Changes have been requested on this pull request.", nil
+			case "APPROVED":
+				approvals++
+			}
+		}
+		if approvals < bp.RequiredApprovingReviewCount {
+			noun := "review is"
+			if bp.RequiredApprovingReviewCount > 1 {
+				noun = "reviews are"
+			}
+			return fmt.Sprintf("At least %d approving %s required by reviewers with write access.", bp.RequiredApprovingReviewCount, noun), nil
+		}
+	}
+
+	if bp.RequireStatusChecks && len(bp.StatusCheckContexts) > 0 {
+		sha, err := p.store.ResolveRef(pr.HeadRepoID, pr.HeadRef)
+		if err != nil {
+			return "", err
+		}
+		statuses, err := p.store.LatestCommitStatuses(pr.HeadRepoID, sha)
+		if err != nil {
+			return "", err
+		}
+		states := map[string]string{}
+		for _, status := range statuses {
+			states[status.Context] = status.State
+		}
+		for _, context := range bp.StatusCheckContexts {
+			switch states[context] {
+			case "success":
+			case "failure", "error":
+				return fmt.Sprintf("Required status check %q is failing.", context), nil
+			default:
+				return fmt.Sprintf("Required status check %q is expected.", context), nil
+			}
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,134 @@
+// ABOUTME: Tests for branch protection endpoints and their enforcement on merge
+// ABOUTME: Covers configuring, reading and removing protection and blocking merges until approved
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBranchProtectionEndpoints(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	if w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/branches/main/protection", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 for an unprotected branch, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/branches/nope/protection", `{}`); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 for a missing branch, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/branches/main/protection",
+		`{"required_pull_request_reviews": {"required_approving_review_count": 7}}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422 for too many required reviews, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body := `{
+		"required_status_checks": {"strict": true, "contexts": ["ci/build"]},
+		"enforce_admins": true,
+		"required_pull_request_reviews": {"dismiss_stale_reviews": true, "required_approving_review_count": 2},
+		"restrictions": null
+	}`
+	w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/branches/main/protection", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/branches/main/protection", "")
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	reviews, _ := resp["required_pull_request_reviews"].(map[string]interface{})
+	checks, _ := resp["required_status_checks"].(map[string]interface{})
+	if reviews == nil || reviews["required_approving_review_count"] != float64(2) || reviews["dismiss_stale_reviews"] != true {
+		t.Fatalf("Unexpected required reviews: %v", resp)
+	}
+	if checks == nil || checks["strict"] != true || len(checks["contexts"].([]interface{})) != 1 {
+		t.Fatalf("Unexpected required status checks: %v", resp)
+	}
+	if resp["enforce_admins"].(map[string]interface{})["enabled"] != true {
+		t.Fatalf("Expected enforce_admins enabled, got %v", resp["enforce_admins"])
+	}
+
+	if w := serveGitHub(plugin, "DELETE", "/repos/alice/test-repo/branches/main/protection", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/branches/main/protection", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 after removing protection, got %d", w.Code)
+	}
+}
+
+func TestMergeBlockedUntilApproved(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	bob, _ := store.GetOrCreateUser("bob", "ghp_bob")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	issue, _, _ := store.CreatePullRequest(repo.ID, alice.ID, "Test PR", "Body", "feature", "main")
+
+	w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/branches/main/protection",
+		`{"required_pull_request_reviews": {"required_approving_review_count": 1}, "required_status_checks": null, "enforce_admins": false, "restrictions": null}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/pulls/1/merge", `{}`); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected 405 without an approval, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The author's own approval doesn't count, and comments aren't approvals
+	store.CreateReview(issue.ID, alice.ID, "APPROVED", "Looks good to me")
+	store.CreateReview(issue.ID, bob.ID, "COMMENTED", "Question inline")
+	w = serveGitHub(plugin, "PUT", "/repos/alice/test-repo/pulls/1/merge", `{}`)
+	if w.Code != http.StatusMethodNotAllowed || !strings.Contains(w.Body.String(), "approving review") {
+		t.Fatalf("Expected 405 citing required reviews, got %d: %s", w.Code, w.Body.String())
+	}
+
+	store.CreateReview(issue.ID, bob.ID, "APPROVED", "LGTM")
+	w = serveGitHub(plugin, "PUT", "/repos/alice/test-repo/pulls/1/merge", `{}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 once approved, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["merged"] != true {
+		t.Fatalf("Expected merged true, got %v", resp["merged"])
+	}
+}
+
+func TestMergeBlockedByRequiredStatusCheck(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.CreatePullRequest(repo.ID, alice.ID, "Test PR", "Body", "feature", "main")
+
+	serveGitHub(plugin, "PUT", "/repos/alice/test-repo/branches/main/protection",
+		`{"required_status_checks": {"strict": false, "contexts": ["ci/build"]}}`)
+
+	w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/pulls/1/merge", `{}`)
+	if w.Code != http.StatusMethodNotAllowed || !strings.Contains(w.Body.String(), "ci/build") {
+		t.Fatalf("Expected 405 citing ci/build, got %d: %s", w.Code, w.Body.String())
+	}
+
+	sha, err := store.ResolveRef(repo.ID, "feature")
+	if err != nil {
+		t.Fatalf("ResolveRef: %v", err)
+	}
+	store.CreateCommitStatus(repo.ID, sha, "success", "ci/build", "Build passed", "", alice.ID)
+	if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/pulls/1/merge", `{}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 once the check passes, got %d: %s", w.Code, w.Body.String())
+	}
+}
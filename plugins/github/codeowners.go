@@ -0,0 +1,146 @@
+// ABOUTME: CODEOWNERS support for GitHub repositories
+// ABOUTME: Generates CODEOWNERS from a repository's collaborators and reports syntax errors in it
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// codeownersPath is where the generated CODEOWNERS file lives
+const codeownersPath = ".github/CODEOWNERS"
+
+// codeownersLocations are where GitHub looks for a CODEOWNERS file, in order
+var codeownersLocations = []string{codeownersPath, "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownerPaths are the areas of a repository handed to collaborators in
+// turn; with more collaborators than areas, areas gain extra owners
+var codeownerPaths = []string{"/.github/", "/docs/", "/src/", "/tests/", "/scripts/"}
+
+var (
+	codeownerUserPattern  = regexp.MustCompile(`^@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:/[A-Za-z0-9_.-]+)?$`)
+	codeownerEmailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// codeownersFile is the CODEOWNERS file generated for a repository: the
+// owner owns everything, and each collaborator owns one area
+func codeownersFile(ownerLogin string, collaborators []*Collaborator) string {
+	owners := make([][]string, min(len(collaborators), len(codeownerPaths)))
+	for i, c := range collaborators {
+		owners[i%len(codeownerPaths)] = append(owners[i%len(codeownerPaths)], "@"+c.Login)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated from the repository's collaborators. Later rules take precedence.\n")
+	fmt.Fprintf(&b, "* @%s\n", ownerLogin)
+	for i, logins := range owners {
+		fmt.Fprintf(&b, "%s %s\n", codeownerPaths[i], strings.Join(logins, " "))
+	}
+	return b.String()
+}
+
+// getCodeownersErrors handles GET /repos/{owner}/{repo}/codeowners/errors,
+// checking the first CODEOWNERS file GitHub would use
+func (p *GitHubPlugin) getCodeownersErrors(w http.ResponseWriter, r *http.Request) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	files, err := p.repoFiles(repo)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load repository files")
+		return
+	}
+	contents := map[string]string{}
+	for _, f := range files {
+		contents[f.Path] = f.Content
+	}
+
+	for _, path := range codeownersLocations {
+		content, ok := contents[path]
+		if !ok {
+			continue
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": p.codeownersErrors(path, content)})
+		return
+	}
+	writeError(w, http.StatusNotFound, "No CODEOWNERS file found")
+}
+
+// codeownersErrors lists the syntax errors in a CODEOWNERS file: patterns
+// using unsupported gitignore syntax, malformed owners, and owners that
+// don't exist
+func (p *GitHubPlugin) codeownersErrors(path, content string) []map[string]interface{} {
+	codeownersError := func(line, column int, kind, source, suggestion string) map[string]interface{} {
+		message := fmt.Sprintf("%s on line %d", kind, line)
+		if suggestion != "" {
+			message += ": " + suggestion
+		}
+		message += fmt.Sprintf("\n\n  %s\n  %s^", source, strings.Repeat(" ", column-1))
+		return map[string]interface{}{
+			"line":       line,
+			"column":     column,
+			"kind":       kind,
+			"source":     source,
+			"suggestion": nilIfEmpty(suggestion),
+			"message":    message,
+			"path":       path,
+		}
+	}
+
+	errs := []map[string]interface{}{}
+	for i, source := range strings.Split(content, "\n") {
+		line := i + 1
+		trimmed := strings.TrimSpace(source)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		fields := strings.Fields(source)
+		pattern := fields[0]
+		column := strings.Index(source, pattern) + 1
+		switch {
+		case strings.HasPrefix(pattern, "!"):
+			errs = append(errs, codeownersError(line, column, "Invalid pattern", source, "Negated patterns are not supported"))
+			continue
+		case strings.ContainsAny(pattern, "[]"):
+			errs = append(errs, codeownersError(line, column, "Invalid pattern", source, "Character ranges are not supported"))
+			continue
+		case strings.Contains(pattern, "***"):
+			errs = append(errs, codeownersError(line, column, "Invalid pattern", source, fmt.Sprintf("Did you mean `%s`?", strings.ReplaceAll(pattern, "***", "**"))))
+			continue
+		}
+
+		offset := column - 1 + len(pattern)
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			column := offset + strings.Index(source[offset:], owner) + 1
+			offset = column - 1 + len(owner)
+
+			switch {
+			case codeownerEmailPattern.MatchString(owner):
+			case !codeownerUserPattern.MatchString(owner):
+				errs = append(errs, codeownersError(line, column, "Invalid owner", source, ""))
+			default:
+				login, _, _ := strings.Cut(strings.TrimPrefix(owner, "@"), "/")
+				if _, err := p.store.GetUserByLogin(login); err != nil {
+					errs = append(errs, codeownersError(line, column, "Unknown owner", source,
+						fmt.Sprintf("make sure %s exists and has write access to the repository", owner)))
+				}
+			}
+		}
+	}
+	return errs
+}
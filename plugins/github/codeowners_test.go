@@ -0,0 +1,117 @@
+// ABOUTME: Tests for collaborators and the generated CODEOWNERS file
+// ABOUTME: Covers CODEOWNERS generation from collaborators and syntax error reporting
+
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedCodeowners(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.GetOrCreateUser("bob", "ghp_bob")
+	store.GetOrCreateUser("carol", "ghp_carol")
+	store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	// No collaborators, no CODEOWNERS
+	if w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github/CODEOWNERS", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 without collaborators, got %d", w.Code)
+	}
+	if w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/codeowners/errors", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 from codeowners/errors without a file, got %d", w.Code)
+	}
+
+	for _, login := range []string{"bob", "carol"} {
+		if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/collaborators/"+login, `{"permission": "push"}`); w.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204 adding %s, got %d: %s", login, w.Code, w.Body.String())
+		}
+	}
+	var collaborators []map[string]interface{}
+	json.Unmarshal(serveGitHub(plugin, "GET", "/repos/alice/test-repo/collaborators", "").Body.Bytes(), &collaborators)
+	if len(collaborators) != 2 || collaborators[0]["login"] != "bob" || collaborators[0]["role_name"] != "write" {
+		t.Fatalf("Expected bob and carol as writers, got %v", collaborators)
+	}
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github/CODEOWNERS", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var file struct {
+		Type    string `json:"type"`
+		Content string `json:"content"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &file)
+	content, _ := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	for _, rule := range []string{"* @alice\n", "/.github/ @bob\n", "/docs/ @carol\n"} {
+		if !strings.Contains(string(content), rule) {
+			t.Errorf("Expected CODEOWNERS to contain %q, got:\n%s", rule, content)
+		}
+	}
+
+	var errs struct {
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	json.Unmarshal(serveGitHub(plugin, "GET", "/repos/alice/test-repo/codeowners/errors", "").Body.Bytes(), &errs)
+	if errs.Errors == nil || len(errs.Errors) != 0 {
+		t.Errorf("Expected an empty error list for the generated file, got %v", errs.Errors)
+	}
+
+	// Removing the last collaborators removes the file
+	serveGitHub(plugin, "DELETE", "/repos/alice/test-repo/collaborators/bob", "")
+	serveGitHub(plugin, "DELETE", "/repos/alice/test-repo/collaborators/carol", "")
+	if w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github/CODEOWNERS", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after removing collaborators, got %d", w.Code)
+	}
+}
+
+func TestCodeownersErrors(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	file := "# Owners\n* @alice\n!/vendor/ @alice\n/docs/ docs-team\n/src/ @ghost dev@example.com # inline comment\n"
+	body := `{"message": "Add CODEOWNERS", "content": "` + base64.StdEncoding.EncodeToString([]byte(file)) + `"}`
+	if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/contents/CODEOWNERS", body); w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Errors []struct {
+			Line   int    `json:"line"`
+			Column int    `json:"column"`
+			Kind   string `json:"kind"`
+			Path   string `json:"path"`
+		} `json:"errors"`
+	}
+	json.Unmarshal(serveGitHub(plugin, "GET", "/repos/alice/test-repo/codeowners/errors", "").Body.Bytes(), &resp)
+	want := []struct {
+		line, column int
+		kind         string
+	}{
+		{3, 1, "Invalid pattern"},
+		{4, 8, "Invalid owner"},
+		{5, 7, "Unknown owner"},
+	}
+	if len(resp.Errors) != len(want) {
+		t.Fatalf("Expected %d errors, got %+v", len(want), resp.Errors)
+	}
+	for i, w := range want {
+		got := resp.Errors[i]
+		if got.Line != w.line || got.Column != w.column || got.Kind != w.kind || got.Path != "CODEOWNERS" {
+			t.Errorf("Error %d = %+v, want line %d column %d %s", i, got, w.line, w.column, w.kind)
+		}
+	}
+}
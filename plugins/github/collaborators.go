@@ -0,0 +1,150 @@
+// ABOUTME: Repository collaborator endpoints for GitHub repositories
+// ABOUTME: Lists, adds, and removes collaborators; adding takes effect at once instead of sending an invitation
+
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// collaboratorPermissions are the roles a collaborator can have, weakest first
+var collaboratorPermissions = []string{"pull", "triage", "push", "maintain", "admin"}
+
+// listCollaborators handles GET /repos/{owner}/{repo}/collaborators
+func (p *GitHubPlugin) listCollaborators(w http.ResponseWriter, r *http.Request) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	collaborators, err := p.store.ListCollaborators(repo.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list collaborators")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(collaborators))
+	for _, c := range collaborators {
+		response = append(response, collaboratorToResponse(c))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// addCollaborator handles PUT /repos/{owner}/{repo}/collaborators/{username}.
+// ISH accepts on the user's behalf, so this always answers 204 No Content.
+func (p *GitHubPlugin) addCollaborator(w http.ResponseWriter, r *http.Request) {
+	actor, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	var req struct {
+		Permission string `json:"permission"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Permission == "" {
+		req.Permission = "push"
+	}
+	if !slices.Contains(collaboratorPermissions, req.Permission) {
+		writeValidationError(w, "Collaborator", "permission", "invalid")
+		return
+	}
+
+	repo, user, ok := p.lookupCollaborator(w, r)
+	if !ok {
+		return
+	}
+	if user.ID == repo.OwnerID {
+		writeError(w, http.StatusUnprocessableEntity, "Repository owner cannot be a collaborator")
+		return
+	}
+
+	if err := p.store.AddCollaborator(repo.ID, user.ID, req.Permission, actor.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to add collaborator")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeCollaborator handles DELETE /repos/{owner}/{repo}/collaborators/{username}
+func (p *GitHubPlugin) removeCollaborator(w http.ResponseWriter, r *http.Request) {
+	actor, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	repo, user, ok := p.lookupCollaborator(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := p.store.RemoveCollaborator(repo.ID, user.ID, actor.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to remove collaborator")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookupCollaborator resolves the repository and {username} from the URL,
+// writing an error response if either doesn't exist
+func (p *GitHubPlugin) lookupCollaborator(w http.ResponseWriter, r *http.Request) (*Repository, *User, bool) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return nil, nil, false
+	}
+
+	user, err := p.store.GetUserByLogin(chi.URLParam(r, "username"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return nil, nil, false
+	}
+
+	return repo, user, true
+}
+
+func collaboratorToResponse(c *Collaborator) map[string]interface{} {
+	level := slices.Index(collaboratorPermissions, c.Permission)
+	permissions := map[string]bool{}
+	for i, permission := range collaboratorPermissions {
+		permissions[permission] = i <= level
+	}
+
+	return map[string]interface{}{
+		"login":       c.Login,
+		"id":          c.ID,
+		"type":        c.Type,
+		"avatar_url":  c.AvatarURL,
+		"url":         "/users/" + c.Login,
+		"permissions": permissions,
+		"role_name":   roleName(c.Permission),
+	}
+}
+
+// roleName is the role GitHub reports for a permission: pull and push are
+// shown as read and write
+func roleName(permission string) string {
+	switch permission {
+	case "pull":
+		return "read"
+	case "push":
+		return "write"
+	}
+	return permission
+}
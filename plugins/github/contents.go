@@ -0,0 +1,566 @@
+// ABOUTME: Repository files for the contents and git trees APIs
+// ABOUTME: Files come from templates, workflows, collaborators, and contents API writes; trees and SHAs are derived like git would
+
+package github
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// workflowsDir is where GitHub Actions workflow files live
+const workflowsDir = ".github/workflows"
+
+// repoFile is one file in a repository's tree
+type repoFile struct {
+	Path    string
+	Content string
+}
+
+// repoFiles lists the files a repository has: its issue and pull request
+// templates, its workflow files, a CODEOWNERS file when it has collaborators,
+// and files written through the contents API, sorted by path
+func (p *GitHubPlugin) repoFiles(repo *Repository) ([]repoFile, error) {
+	contents := map[string]string{}
+	for _, templateType := range []string{TemplateTypeIssue, TemplateTypePullRequest} {
+		templates, err := p.store.ListRepoTemplates(repo.ID, templateType)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range templates {
+			contents[templatePath(t)] = t.Content
+		}
+	}
+
+	workflows, err := p.store.ListWorkflows(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, wf := range workflows {
+		contents[wf.Path] = workflowYAML(repo, wf.Name)
+	}
+
+	collaborators, err := p.store.ListCollaborators(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(collaborators) > 0 {
+		owner, _, _ := strings.Cut(repo.FullName, "/")
+		contents[codeownersPath] = codeownersFile(owner, collaborators)
+	}
+
+	// Written files replace, or when deleted hide, generated ones
+	written, err := p.store.ListRepoFiles(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range written {
+		if f.Deleted {
+			delete(contents, f.Path)
+		} else {
+			contents[f.Path] = f.Content
+		}
+	}
+
+	var files []repoFile
+	for path, content := range contents {
+		files = append(files, repoFile{Path: path, Content: content})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// defaultRepoFiles is the tree reported for a repository with no files, so
+// clients walking it always find something
+func defaultRepoFiles(repo *Repository) []repoFile {
+	owner, _, _ := strings.Cut(repo.FullName, "/")
+	return []repoFile{
+		{Path: workflowsDir + "/ci.yml", Content: workflowYAML(repo, "CI")},
+		{Path: "LICENSE", Content: fmt.Sprintf("MIT License\n\nCopyright (c) %s\n", owner)},
+		{Path: "README.md", Content: fmt.Sprintf("# %s\n\n%s\n", repo.Name, repo.Description)},
+	}
+}
+
+// workflowYAML is the generated content of a workflow file
+func workflowYAML(repo *Repository, name string) string {
+	return fmt.Sprintf(`name: %s
+
+on:
+  push:
+    branches: [%s]
+  pull_request:
+  workflow_dispatch:
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: make test
+`, name, repo.DefaultBranch)
+}
+
+// blobSHA is the git blob SHA of content, as the contents API reports it
+func blobSHA(content string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("blob %d\x00%s", len(content), content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// gitCommitSHA is the SHA git would give commit, hashing its tree, parent,
+// author, committer, and message
+func gitCommitSHA(commit *GitCommit) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "tree %s\n", commit.TreeSHA)
+	if commit.ParentSHA != "" {
+		fmt.Fprintf(&body, "parent %s\n", commit.ParentSHA)
+	}
+	signature := fmt.Sprintf("%s <%s> %d +0000", commit.AuthorName, commit.AuthorEmail, commit.CreatedAt.Unix())
+	fmt.Fprintf(&body, "author %s\ncommitter %s\n\n%s\n", signature, signature, commit.Message)
+	sum := sha1.Sum([]byte(fmt.Sprintf("commit %d\x00%s", body.Len(), body.String())))
+	return hex.EncodeToString(sum[:])
+}
+
+// gitTree is a directory of a repository's files. Blobs have no entries.
+type gitTree struct {
+	Name    string
+	Path    string
+	Content string
+	Entries []*gitTree
+	SHA     string
+}
+
+func (t *gitTree) isBlob() bool {
+	return t.Entries == nil
+}
+
+// buildGitTree assembles files into nested trees and computes every SHA
+func buildGitTree(files []repoFile) *gitTree {
+	root := &gitTree{Entries: []*gitTree{}}
+	for _, file := range files {
+		dir := root
+		parts := strings.Split(file.Path, "/")
+		for i, part := range parts[:len(parts)-1] {
+			dir = dir.subtree(part, strings.Join(parts[:i+1], "/"))
+		}
+		dir.Entries = append(dir.Entries, &gitTree{Name: parts[len(parts)-1], Path: file.Path, Content: file.Content})
+	}
+	root.hash()
+	return root
+}
+
+// subtree returns the directory entry called name, adding it if needed
+func (t *gitTree) subtree(name, path string) *gitTree {
+	for _, entry := range t.Entries {
+		if entry.Name == name && !entry.isBlob() {
+			return entry
+		}
+	}
+	entry := &gitTree{Name: name, Path: path, Entries: []*gitTree{}}
+	t.Entries = append(t.Entries, entry)
+	return entry
+}
+
+// hash sorts entries in git's order and sets the SHA of the tree and
+// everything under it, the same way git hashes tree objects
+func (t *gitTree) hash() {
+	if t.isBlob() {
+		t.SHA = blobSHA(t.Content)
+		return
+	}
+
+	// git sorts directories as if their names ended in a slash
+	sortKey := func(entry *gitTree) string {
+		if entry.isBlob() {
+			return entry.Name
+		}
+		return entry.Name + "/"
+	}
+	sort.Slice(t.Entries, func(i, j int) bool { return sortKey(t.Entries[i]) < sortKey(t.Entries[j]) })
+
+	var body strings.Builder
+	for _, entry := range t.Entries {
+		entry.hash()
+		raw, _ := hex.DecodeString(entry.SHA)
+		fmt.Fprintf(&body, "%s %s\x00%s", entry.mode(), entry.Name, raw)
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("tree %d\x00%s", body.Len(), body.String())))
+	t.SHA = hex.EncodeToString(sum[:])
+}
+
+// mode is the git file mode, as written in tree objects
+func (t *gitTree) mode() string {
+	if t.isBlob() {
+		return "100644"
+	}
+	return "40000"
+}
+
+// find returns the first tree or blob, in depth-first order, that matches
+func (t *gitTree) find(match func(*gitTree) bool) *gitTree {
+	if match(t) {
+		return t
+	}
+	for _, entry := range t.Entries {
+		if found := entry.find(match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// getContents handles GET /repos/{owner}/{repo}/contents/{path}, returning a
+// file with its content or a directory listing
+func (p *GitHubPlugin) getContents(w http.ResponseWriter, r *http.Request) {
+	owner := chi.URLParam(r, "owner")
+	repoName := chi.URLParam(r, "repo")
+	path := strings.Trim(chi.URLParam(r, "*"), "/")
+
+	repo, err := p.store.GetRepositoryByFullName(owner + "/" + repoName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+	files, err := p.repoFiles(repo)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list files")
+		return
+	}
+
+	entry := buildGitTree(files).find(func(t *gitTree) bool { return t.Path == path })
+	if entry == nil || (path == "" && len(entry.Entries) == 0) {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !entry.isBlob() {
+		listing := make([]map[string]interface{}, 0, len(entry.Entries))
+		for _, child := range entry.Entries {
+			listing = append(listing, contentEntry(repo, child))
+		}
+		sort.Slice(listing, func(i, j int) bool { return listing[i]["name"].(string) < listing[j]["name"].(string) })
+		json.NewEncoder(w).Encode(listing)
+		return
+	}
+
+	response := contentEntry(repo, entry)
+	response["encoding"] = "base64"
+	response["content"] = base64.StdEncoding.EncodeToString([]byte(entry.Content))
+	json.NewEncoder(w).Encode(response)
+}
+
+// contentEntry is the contents API representation of a file or directory, without its content
+func contentEntry(repo *Repository, entry *gitTree) map[string]interface{} {
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/contents/%s", repo.FullName, entry.Path)
+	htmlURL := fmt.Sprintf("https://github.com/%s/blob/%s/%s", repo.FullName, repo.DefaultBranch, entry.Path)
+	response := map[string]interface{}{
+		"type":         "file",
+		"name":         entry.Name,
+		"path":         entry.Path,
+		"sha":          entry.SHA,
+		"size":         len(entry.Content),
+		"url":          apiURL,
+		"html_url":     htmlURL,
+		"download_url": fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", repo.FullName, repo.DefaultBranch, entry.Path),
+		"_links": map[string]interface{}{
+			"self": apiURL,
+			"html": htmlURL,
+		},
+	}
+	if !entry.isBlob() {
+		htmlURL = fmt.Sprintf("https://github.com/%s/tree/%s/%s", repo.FullName, repo.DefaultBranch, entry.Path)
+		response["type"] = "dir"
+		response["html_url"] = htmlURL
+		response["download_url"] = nil
+		response["_links"] = map[string]interface{}{"self": apiURL, "html": htmlURL}
+	}
+	return response
+}
+
+// getGitTree handles GET /repos/{owner}/{repo}/git/trees/{sha}. The SHA can
+// be the root tree, any subtree, or a branch or commit, which all name the
+// root. With ?recursive=1 every entry below the tree is listed by full path.
+func (p *GitHubPlugin) getGitTree(w http.ResponseWriter, r *http.Request) {
+	owner := chi.URLParam(r, "owner")
+	repoName := chi.URLParam(r, "repo")
+	sha := chi.URLParam(r, "sha")
+
+	repo, err := p.store.GetRepositoryByFullName(owner + "/" + repoName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+	files, err := p.repoFiles(repo)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list files")
+		return
+	}
+	if len(files) == 0 {
+		files = defaultRepoFiles(repo)
+	}
+	root := buildGitTree(files)
+
+	tree := root.find(func(t *gitTree) bool { return t.SHA == sha && !t.isBlob() })
+	if tree == nil {
+		resolved, err := p.store.ResolveRef(repo.ID, sha)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to resolve ref")
+			return
+		}
+		// There is no commit history, so every commit and branch has the same tree
+		if resolved != sha || commitSHAPattern.MatchString(sha) || sha == repo.DefaultBranch || sha == "HEAD" {
+			tree = root
+		}
+	}
+	if tree == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	// Like GitHub, any value of recursive turns it on, even 0 or false
+	recursive := r.URL.Query().Has("recursive")
+	entries := []map[string]interface{}{}
+	var walk func(t *gitTree, prefix string)
+	walk = func(t *gitTree, prefix string) {
+		for _, entry := range t.Entries {
+			entries = append(entries, treeEntryToResponse(repo, entry, prefix+entry.Name))
+			if recursive && !entry.isBlob() {
+				walk(entry, prefix+entry.Name+"/")
+			}
+		}
+	}
+	walk(tree, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sha":       tree.SHA,
+		"url":       fmt.Sprintf("https://api.github.com/repos/%s/git/trees/%s", repo.FullName, tree.SHA),
+		"tree":      entries,
+		"truncated": false,
+	})
+}
+
+// treeEntryToResponse converts a tree entry to the git trees API format,
+// with its path relative to the tree being listed
+func treeEntryToResponse(repo *Repository, entry *gitTree, path string) map[string]interface{} {
+	if entry.isBlob() {
+		return map[string]interface{}{
+			"path": path,
+			"mode": "100644",
+			"type": "blob",
+			"sha":  entry.SHA,
+			"size": len(entry.Content),
+			"url":  fmt.Sprintf("https://api.github.com/repos/%s/git/blobs/%s", repo.FullName, entry.SHA),
+		}
+	}
+	return map[string]interface{}{
+		"path": path,
+		"mode": "040000",
+		"type": "tree",
+		"sha":  entry.SHA,
+		"url":  fmt.Sprintf("https://api.github.com/repos/%s/git/trees/%s", repo.FullName, entry.SHA),
+	}
+}
+
+// validContentPath reports whether path names a file: non-empty, relative,
+// and without empty, "." or ".." segments
+func validContentPath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, part := range strings.Split(path, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// contentsWriteRequest is the body of PUT and DELETE /repos/{owner}/{repo}/contents/{path}
+type contentsWriteRequest struct {
+	Message string `json:"message"`
+	Content string `json:"content"`
+	SHA     string `json:"sha"`
+	Branch  string `json:"branch"`
+	Author  *struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"author"`
+}
+
+// putContents handles PUT /repos/{owner}/{repo}/contents/{path}, creating a
+// file or, given the SHA of the current one, replacing it. Each write is a
+// commit on the default branch.
+func (p *GitHubPlugin) putContents(w http.ResponseWriter, r *http.Request) {
+	p.writeContents(w, r, false)
+}
+
+// deleteContents handles DELETE /repos/{owner}/{repo}/contents/{path}, which
+// needs the SHA of the file being deleted
+func (p *GitHubPlugin) deleteContents(w http.ResponseWriter, r *http.Request) {
+	p.writeContents(w, r, true)
+}
+
+func (p *GitHubPlugin) writeContents(w http.ResponseWriter, r *http.Request, remove bool) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+	owner := chi.URLParam(r, "owner")
+	repoName := chi.URLParam(r, "repo")
+	path := chi.URLParam(r, "*")
+
+	repo, err := p.store.GetRepositoryByFullName(owner + "/" + repoName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	var req contentsWriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Message == "" {
+		writeValidationError(w, "Commit", "message", "missing_field")
+		return
+	}
+	if !validContentPath(path) {
+		writeValidationError(w, "Commit", "path", "invalid")
+		return
+	}
+	if req.Branch != "" && req.Branch != repo.DefaultBranch {
+		writeError(w, http.StatusNotFound, "Branch "+req.Branch+" not found")
+		return
+	}
+
+	var content []byte
+	if !remove {
+		content, err = base64.StdEncoding.DecodeString(req.Content)
+		if err != nil {
+			writeValidationError(w, "Commit", "content", "invalid")
+			return
+		}
+	}
+
+	files, err := p.repoFiles(repo)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list files")
+		return
+	}
+	existing := buildGitTree(files).find(func(t *gitTree) bool {
+		return t.Path == path || (t.isBlob() && strings.HasPrefix(path, t.Path+"/"))
+	})
+	switch {
+	case existing != nil && (!existing.isBlob() || existing.Path != path):
+		// The path is a directory, or runs through a file
+		writeValidationError(w, "Commit", "path", "invalid")
+		return
+	case existing == nil && remove:
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	case existing != nil && req.SHA == "":
+		writeValidationError(w, "Commit", "sha", "missing_field")
+		return
+	case existing != nil && req.SHA != existing.SHA:
+		writeError(w, http.StatusConflict, fmt.Sprintf("%s does not match %s", path, req.SHA))
+		return
+	}
+
+	// Hash the tree as it will be after the change
+	var next []repoFile
+	for _, f := range files {
+		if f.Path != path {
+			next = append(next, f)
+		}
+	}
+	if !remove {
+		next = append(next, repoFile{Path: path, Content: string(content)})
+	}
+	root := buildGitTree(next)
+
+	commit := &GitCommit{
+		AuthorLogin:    user.Login,
+		AuthorName:     user.Login,
+		AuthorEmail:    fmt.Sprintf("%d+%s@users.noreply.github.com", user.ID, user.Login),
+		CommitterLogin: user.Login,
+		Message:        req.Message,
+		TreeSHA:        root.SHA,
+	}
+	if user.Name != "" {
+		commit.AuthorName = user.Name
+	}
+	if user.Email != "" {
+		commit.AuthorEmail = user.Email
+	}
+	if req.Author != nil && req.Author.Name != "" && req.Author.Email != "" {
+		commit.AuthorName, commit.AuthorEmail = req.Author.Name, req.Author.Email
+	}
+
+	file := RepoFile{Path: path, Content: string(content), Deleted: remove}
+	if err := p.store.CommitRepoFile(repo, file, commit, user.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to commit file")
+		return
+	}
+	if err := p.closeReferencedIssues(repo, commit.Message, user.ID, commit.SHA, 0); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to close referenced issues")
+		return
+	}
+
+	response := map[string]interface{}{
+		"content": nil,
+		"commit":  commitToResponse(repo, commit),
+	}
+	status := http.StatusOK
+	if !remove {
+		entry := root.find(func(t *gitTree) bool { return t.Path == path })
+		response["content"] = contentEntry(repo, entry)
+		if existing == nil {
+			status = http.StatusCreated
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// commitToResponse converts a commit to the git commits API format
+func commitToResponse(repo *Repository, commit *GitCommit) map[string]interface{} {
+	signature := map[string]interface{}{
+		"name":  commit.AuthorName,
+		"email": commit.AuthorEmail,
+		"date":  commit.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	parents := []map[string]interface{}{}
+	if commit.ParentSHA != "" {
+		parents = append(parents, map[string]interface{}{
+			"sha":      commit.ParentSHA,
+			"url":      fmt.Sprintf("https://api.github.com/repos/%s/git/commits/%s", repo.FullName, commit.ParentSHA),
+			"html_url": fmt.Sprintf("https://github.com/%s/commit/%s", repo.FullName, commit.ParentSHA),
+		})
+	}
+	return map[string]interface{}{
+		"sha":       commit.SHA,
+		"url":       fmt.Sprintf("https://api.github.com/repos/%s/git/commits/%s", repo.FullName, commit.SHA),
+		"html_url":  fmt.Sprintf("https://github.com/%s/commit/%s", repo.FullName, commit.SHA),
+		"author":    signature,
+		"committer": signature,
+		"message":   commit.Message,
+		"tree": map[string]interface{}{
+			"sha": commit.TreeSHA,
+			"url": fmt.Sprintf("https://api.github.com/repos/%s/git/trees/%s", repo.FullName, commit.TreeSHA),
+		},
+		"parents": parents,
+	}
+}
@@ -0,0 +1,215 @@
+// ABOUTME: Tests for the repository contents and git trees APIs
+// ABOUTME: Covers workflow files, recursive trees, and git-compatible blob and tree SHAs
+
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWorkflowContents(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.CreateWorkflow(repo.ID, "Release", ".github/workflows/release.yml")
+	store.CreateWorkflow(repo.ID, "CI", ".github/workflows/ci.yml")
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github/workflows/", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var entries []map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &entries)
+	if len(entries) != 2 || entries[0]["path"] != ".github/workflows/ci.yml" || entries[1]["name"] != "release.yml" || entries[0]["type"] != "file" {
+		t.Fatalf("Unexpected workflows listing: %v", entries)
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github/workflows/ci.yml", "")
+	var file map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &file)
+	content, _ := base64.StdEncoding.DecodeString(file["content"].(string))
+	if !strings.HasPrefix(string(content), "name: CI\n") || file["sha"] != blobSHA(string(content)) {
+		t.Errorf("Unexpected workflow file: %v", file)
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github", "")
+	json.Unmarshal(w.Body.Bytes(), &entries)
+	if len(entries) != 1 || entries[0]["type"] != "dir" || entries[0]["name"] != "workflows" {
+		t.Errorf("Unexpected .github listing: %v", entries)
+	}
+}
+
+func TestGitTree(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.SetRepoTemplate(repo.ID, TemplateTypePullRequest, "", "PR template\n")
+	store.CreateWorkflow(repo.ID, "CI", ".github/workflows/ci.yml")
+
+	type treeResponse struct {
+		SHA  string `json:"sha"`
+		Tree []struct {
+			Path string `json:"path"`
+			Mode string `json:"mode"`
+			Type string `json:"type"`
+			SHA  string `json:"sha"`
+		} `json:"tree"`
+	}
+	getTree := func(sha string) (int, treeResponse) {
+		w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/git/trees/"+sha, "")
+		var tree treeResponse
+		json.Unmarshal(w.Body.Bytes(), &tree)
+		return w.Code, tree
+	}
+
+	code, tree := getTree("main?recursive=1")
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	var paths []string
+	for _, entry := range tree.Tree {
+		paths = append(paths, entry.Type+" "+entry.Path)
+	}
+	want := "tree .github|blob .github/PULL_REQUEST_TEMPLATE.md|tree .github/workflows|blob .github/workflows/ci.yml"
+	if strings.Join(paths, "|") != want {
+		t.Fatalf("Tree entries = %q, want %q", strings.Join(paths, "|"), want)
+	}
+	// The same SHAs git computes for these files and directories
+	if tree.SHA != "156f9ead0bb1def4040d4a70284eccf87aa29d8d" {
+		t.Errorf("Root tree SHA = %s, want the SHA from git write-tree", tree.SHA)
+	}
+	if tree.Tree[1].SHA != "51e0122178b489795f5af5e6b78c3a5c0f6822e3" || tree.Tree[1].Mode != "100644" {
+		t.Errorf("Unexpected template blob: %+v", tree.Tree[1])
+	}
+	if tree.Tree[3].SHA != blobSHA(workflowYAML(repo, "CI")) {
+		t.Errorf("Workflow blob SHA = %s, want the blob SHA of its content", tree.Tree[3].SHA)
+	}
+
+	// Without recursive only the top level is listed, and subtrees can be fetched by SHA
+	code, top := getTree(tree.SHA)
+	if code != http.StatusOK || top.SHA != tree.SHA || len(top.Tree) != 1 || top.Tree[0].SHA != tree.Tree[0].SHA {
+		t.Fatalf("Unexpected top-level tree (status %d): %+v", code, top)
+	}
+	code, sub := getTree(tree.Tree[2].SHA)
+	if code != http.StatusOK || len(sub.Tree) != 1 || sub.Tree[0].Path != "ci.yml" {
+		t.Errorf("Unexpected workflows subtree (status %d): %+v", code, sub)
+	}
+
+	if code, _ := getTree("not-a-ref"); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown tree, got %d", code)
+	}
+}
+
+func TestGitTreeDefaultFiles(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "empty-repo", "", false)
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/empty-repo/git/trees/main?recursive=1", "")
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+		} `json:"tree"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &tree)
+	var paths []string
+	for _, entry := range tree.Tree {
+		paths = append(paths, entry.Path)
+	}
+	if strings.Join(paths, ",") != ".github,.github/workflows,.github/workflows/ci.yml,LICENSE,README.md" {
+		t.Errorf("Unexpected default tree: %v", paths)
+	}
+}
+
+func TestWriteContents(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	type writeResponse struct {
+		Content struct {
+			Path string `json:"path"`
+			SHA  string `json:"sha"`
+		} `json:"content"`
+		Commit struct {
+			SHA     string `json:"sha"`
+			Message string `json:"message"`
+			Parents []struct {
+				SHA string `json:"sha"`
+			} `json:"parents"`
+		} `json:"commit"`
+	}
+	put := func(body string) (int, writeResponse) {
+		w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/contents/docs/notes.md", body)
+		var resp writeResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	encoded := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	code, created := put(`{"message": "Add notes", "content": "` + encoded("first draft\n") + `"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", code)
+	}
+	if created.Content.Path != "docs/notes.md" || created.Content.SHA != blobSHA("first draft\n") || len(created.Commit.Parents) != 0 {
+		t.Fatalf("Unexpected create response: %+v", created)
+	}
+
+	if code, _ := put(`{"message": "Rewrite notes", "content": "` + encoded("second\n") + `"}`); code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 updating without a sha, got %d", code)
+	}
+	if code, _ := put(`{"message": "Rewrite notes", "content": "` + encoded("second\n") + `", "sha": "` + blobSHA("stale\n") + `"}`); code != http.StatusConflict {
+		t.Errorf("Expected 409 for a stale sha, got %d", code)
+	}
+
+	code, updated := put(`{"message": "Rewrite notes", "content": "` + encoded("second\n") + `", "sha": "` + created.Content.SHA + `"}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if updated.Content.SHA != blobSHA("second\n") || len(updated.Commit.Parents) != 1 || updated.Commit.Parents[0].SHA != created.Commit.SHA {
+		t.Errorf("Expected the update to follow the first commit, got %+v", updated)
+	}
+	if head, _ := store.ResolveRef(repo.ID, "main"); head != updated.Commit.SHA {
+		t.Errorf("Expected main to point at %s, got %s", updated.Commit.SHA, head)
+	}
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/docs/notes.md", "")
+	var file map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &file)
+	if content, _ := base64.StdEncoding.DecodeString(file["content"].(string)); string(content) != "second\n" {
+		t.Errorf("Expected the updated content, got %q", content)
+	}
+
+	w = serveGitHub(plugin, "DELETE", "/repos/alice/test-repo/contents/docs/notes.md", `{"message": "Remove notes", "sha": "`+updated.Content.SHA+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 deleting, got %d: %s", w.Code, w.Body.String())
+	}
+	if w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/docs/notes.md", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after deleting, got %d", w.Code)
+	}
+	var commits int
+	db.QueryRow(`SELECT COUNT(*) FROM github_commits WHERE repo_id = ?`, repo.ID).Scan(&commits)
+	if commits != 3 {
+		t.Errorf("Expected 3 commits, got %d", commits)
+	}
+}
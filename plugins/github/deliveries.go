@@ -0,0 +1,210 @@
+// ABOUTME: Persistent webhook delivery queue for the GitHub plugin
+// ABOUTME: A background worker sends queued deliveries and retries failures with exponential backoff
+
+package github
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// deliveryPollInterval is how often the worker looks for due deliveries
+	deliveryPollInterval = 100 * time.Millisecond
+	// deliveryAttemptTimeout bounds each delivery attempt
+	deliveryAttemptTimeout = 5 * time.Second
+	// maxDeliveryAttempts is how many times a delivery is tried before it is exhausted
+	maxDeliveryAttempts = 3
+	// deliveryRetryBase is doubled for every failed attempt to space out retries
+	deliveryRetryBase = 30 * time.Second
+)
+
+// deliveryRetryDelay is how long to wait before retrying a delivery that has
+// failed attempts times: 2^attempts * 30s
+func deliveryRetryDelay(attempts int) time.Duration {
+	return time.Duration(1<<attempts) * deliveryRetryBase
+}
+
+// startDeliveryWorker polls the queue for due deliveries until ctx is done
+func (p *GitHubPlugin) startDeliveryWorker(ctx context.Context) {
+	ticker := time.NewTicker(deliveryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.processDeliveryQueue(ctx)
+		}
+	}
+}
+
+// processDeliveryQueue attempts every delivery that is due
+func (p *GitHubPlugin) processDeliveryQueue(ctx context.Context) {
+	deliveries, err := p.store.DueWebhookDeliveries(p.store.now())
+	if err != nil {
+		log.Printf("GitHub: failed to fetch due webhook deliveries: %v", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		p.attemptDelivery(ctx, delivery)
+	}
+}
+
+// attemptDelivery sends a queued delivery once and records the outcome,
+// scheduling a retry or exhausting it on failure. It returns the send error.
+func (p *GitHubPlugin) attemptDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	webhook, err := p.store.GetWebhook(delivery.WebhookID)
+	if err == nil {
+		attemptCtx, cancel := context.WithTimeout(ctx, deliveryAttemptTimeout)
+		err = fireWebhook(attemptCtx, webhook, delivery.EventType, delivery.ID, []byte(delivery.Payload))
+		cancel()
+	}
+
+	now := p.store.now()
+	delivery.Attempts++
+	delivery.DeliveredAt = &now
+	delivery.StatusCode, delivery.ErrorMessage = deliveryOutcome(err)
+	delivery.NextRetryAt = nil
+	switch {
+	case err == nil:
+		delivery.Status = DeliveryStatusDelivered
+	case delivery.Attempts >= maxDeliveryAttempts:
+		delivery.Status = DeliveryStatusExhausted
+	default:
+		delivery.Status = DeliveryStatusFailed
+		retryAt := now.Add(deliveryRetryDelay(delivery.Attempts))
+		delivery.NextRetryAt = &retryAt
+	}
+
+	if updateErr := p.store.UpdateWebhookDelivery(delivery); updateErr != nil {
+		log.Printf("GitHub: failed to record webhook delivery %d: %v", delivery.ID, updateErr)
+	}
+	return err
+}
+
+// webhookForRequest loads the webhook named by {id} in a request, making
+// sure it belongs to the repository in the URL
+func (p *GitHubPlugin) webhookForRequest(w http.ResponseWriter, r *http.Request) (*Webhook, bool) {
+	repo, err := p.store.GetRepositoryByFullName(chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return nil, false
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid hook id")
+		return nil, false
+	}
+	webhook, err := p.store.GetWebhook(id)
+	if err != nil || webhook.RepoID != repo.ID {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return nil, false
+	}
+	return webhook, true
+}
+
+// listWebhookDeliveries handles GET /repos/{owner}/{repo}/hooks/{id}/deliveries
+func (p *GitHubPlugin) listWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := p.webhookForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := p.store.ListWebhookDeliveries(webhook.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list deliveries")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, webhookDeliveryToResponse(webhook, delivery))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// redeliverWebhookDelivery handles POST
+// /repos/{owner}/{repo}/hooks/{id}/deliveries/{delivery_id}/attempts by
+// queueing the delivery's payload again as a new delivery
+func (p *GitHubPlugin) redeliverWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := p.webhookForRequest(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, "delivery_id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid delivery id")
+		return
+	}
+
+	original, err := p.store.GetWebhookDelivery(webhook.ID, deliveryID)
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, "delivery not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get delivery")
+		return
+	}
+	if _, err := p.store.QueueWebhookDelivery(webhook.ID, original.EventType, original.Payload, p.store.now(), true); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to queue redelivery")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{})
+}
+
+// webhookDeliveryToResponse converts a delivery to GitHub API response
+// format, plus the queue state: delivery_status, attempts, and next_retry_at
+func webhookDeliveryToResponse(webhook *Webhook, delivery *WebhookDelivery) map[string]interface{} {
+	// GitHub reports "OK" for a successful delivery and the error otherwise
+	status := "OK"
+	switch {
+	case delivery.Status == DeliveryStatusPending:
+		status = "Pending"
+	case delivery.Status != DeliveryStatusDelivered:
+		status = delivery.ErrorMessage
+	}
+
+	var action interface{}
+	var payload map[string]interface{}
+	if json.Unmarshal([]byte(delivery.Payload), &payload) == nil && payload["action"] != nil {
+		action = payload["action"]
+	}
+
+	response := map[string]interface{}{
+		"id":              delivery.ID,
+		"delivered_at":    nil,
+		"redelivery":      delivery.Redelivery,
+		"status":          status,
+		"status_code":     delivery.StatusCode,
+		"event":           delivery.EventType,
+		"action":          action,
+		"repository_id":   webhook.RepoID,
+		"delivery_status": delivery.Status,
+		"attempts":        delivery.Attempts,
+		"next_retry_at":   nil,
+	}
+	if delivery.DeliveredAt != nil {
+		response["delivered_at"] = delivery.DeliveredAt.Format(time.RFC3339)
+	}
+	if delivery.NextRetryAt != nil {
+		response["next_retry_at"] = delivery.NextRetryAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,156 @@
+// ABOUTME: Tests for the persistent webhook delivery queue
+// ABOUTME: Covers queueing, retry backoff, exhaustion, and the deliveries and redelivery endpoints
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// setupDeliveryTest creates alice/test-repo with a webhook for issues events
+// pointing at a local receiver that answers with *status
+func setupDeliveryTest(t *testing.T, status *atomic.Int32) (*GitHubPlugin, *GitHubStore, *core.FrozenClock, *Repository, int64) {
+	t.Helper()
+
+	// The receiver is on loopback, which webhook URLs may not normally use
+	checkDeliveryURL = func(string) error { return nil }
+	t.Cleanup(func() { checkDeliveryURL = validateWebhookURL })
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(receiver.Close)
+
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	store, _ := NewGitHubStore(db)
+	clock := core.NewFrozenClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	store.SetClock(clock)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	result, err := db.Exec(`
+		INSERT INTO github_webhooks (repo_id, url, content_type, secret, events, active, delay_ms, created_at, updated_at)
+		VALUES (?, ?, 'json', '', 'issues', 1, 0, ?, ?)
+	`, repo.ID, receiver.URL, clock.Now(), clock.Now())
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	webhookID, _ := result.LastInsertId()
+	return plugin, store, clock, repo, webhookID
+}
+
+func TestWebhookDeliveryRetries(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	plugin, store, clock, repo, webhookID := setupDeliveryTest(t, &status)
+	ctx := context.Background()
+
+	plugin.fireWebhooksForEvent(repo.ID, "issues", map[string]interface{}{"action": "opened"})
+	deliveries, _ := store.ListWebhookDeliveries(webhookID)
+	if len(deliveries) != 1 || deliveries[0].Status != DeliveryStatusPending || deliveries[0].Attempts != 0 {
+		t.Fatalf("Expected one pending delivery, got %+v", deliveries)
+	}
+	id := deliveries[0].ID
+
+	// Each failure waits twice as long as the last: 60s, then 120s
+	for attempt, wait := range []time.Duration{60 * time.Second, 120 * time.Second} {
+		plugin.processDeliveryQueue(ctx)
+		d, _ := store.GetWebhookDelivery(webhookID, id)
+		if d.Status != DeliveryStatusFailed || d.Attempts != attempt+1 || d.StatusCode != 500 {
+			t.Fatalf("After attempt %d: got %+v", attempt+1, d)
+		}
+		if d.NextRetryAt == nil || !d.NextRetryAt.Equal(clock.Now().Add(wait)) {
+			t.Fatalf("After attempt %d: next_retry_at = %v, want %v", attempt+1, d.NextRetryAt, clock.Now().Add(wait))
+		}
+
+		// Nothing is sent before the retry is due
+		clock.Advance(wait - time.Second)
+		plugin.processDeliveryQueue(ctx)
+		if d, _ := store.GetWebhookDelivery(webhookID, id); d.Attempts != attempt+1 {
+			t.Fatalf("Delivery retried early: %+v", d)
+		}
+		clock.Advance(time.Second)
+	}
+
+	plugin.processDeliveryQueue(ctx)
+	d, _ := store.GetWebhookDelivery(webhookID, id)
+	if d.Status != DeliveryStatusExhausted || d.Attempts != maxDeliveryAttempts || d.NextRetryAt != nil {
+		t.Fatalf("Expected exhausted after %d attempts, got %+v", maxDeliveryAttempts, d)
+	}
+
+	// Exhausted deliveries are never picked up again
+	clock.Advance(time.Hour)
+	plugin.processDeliveryQueue(ctx)
+	if d, _ := store.GetWebhookDelivery(webhookID, id); d.Attempts != maxDeliveryAttempts {
+		t.Errorf("Exhausted delivery was retried: %+v", d)
+	}
+}
+
+func TestWebhookDeliveryRecoversOnRetry(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	plugin, store, clock, repo, webhookID := setupDeliveryTest(t, &status)
+	ctx := context.Background()
+
+	plugin.fireWebhooksForEvent(repo.ID, "issues", map[string]interface{}{"action": "opened"})
+	plugin.processDeliveryQueue(ctx)
+
+	status.Store(http.StatusOK)
+	clock.Advance(deliveryRetryDelay(1))
+	plugin.processDeliveryQueue(ctx)
+
+	deliveries, _ := store.ListWebhookDeliveries(webhookID)
+	d := deliveries[0]
+	if d.Status != DeliveryStatusDelivered || d.Attempts != 2 || d.StatusCode != 200 || d.ErrorMessage != "" || d.NextRetryAt != nil {
+		t.Errorf("Expected delivered on the second attempt, got %+v", d)
+	}
+}
+
+func TestWebhookDeliveriesEndpoints(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	plugin, store, _, repo, webhookID := setupDeliveryTest(t, &status)
+
+	plugin.fireWebhooksForEvent(repo.ID, "issues", map[string]interface{}{"action": "opened"})
+	plugin.processDeliveryQueue(context.Background())
+
+	hookPath := "/repos/alice/test-repo/hooks/" + strconv.FormatInt(webhookID, 10)
+	w := serveGitHub(plugin, "GET", hookPath+"/deliveries", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var listed []map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	if len(listed) != 1 || listed[0]["status"] != "OK" || listed[0]["event"] != "issues" || listed[0]["action"] != "opened" ||
+		listed[0]["delivery_status"] != DeliveryStatusDelivered || listed[0]["redelivery"] != false {
+		t.Fatalf("Unexpected deliveries: %v", listed)
+	}
+
+	deliveryID := int64(listed[0]["id"].(float64))
+	w = serveGitHub(plugin, "POST", hookPath+"/deliveries/"+strconv.FormatInt(deliveryID, 10)+"/attempts", "")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	deliveries, _ := store.ListWebhookDeliveries(webhookID)
+	if len(deliveries) != 2 || !deliveries[0].Redelivery || deliveries[0].Status != DeliveryStatusPending || deliveries[0].Payload != deliveries[1].Payload {
+		t.Fatalf("Expected a pending redelivery of the same payload, got %+v", deliveries)
+	}
+
+	if w := serveGitHub(plugin, "POST", hookPath+"/deliveries/9999/attempts", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown delivery, got %d", w.Code)
+	}
+	if w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/hooks/9999/deliveries", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown webhook, got %d", w.Code)
+	}
+}
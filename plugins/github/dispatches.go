@@ -0,0 +1,188 @@
+// ABOUTME: Repository dispatch endpoint and the admin listing of dispatch events
+// ABOUTME: Custom events fire repository_dispatch webhooks; the listing also shows workflow_dispatch runs
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// maxDispatchEventTypeLength is the longest event_type GitHub accepts
+	maxDispatchEventTypeLength = 100
+
+	// maxClientPayloadProperties is how many top-level properties a
+	// client_payload may have
+	maxClientPayloadProperties = 10
+
+	defaultDispatchEventsLimit = 30
+	maxDispatchEventsLimit     = 100
+)
+
+// dispatchRepositoryEvent handles POST /repos/{owner}/{repo}/dispatches
+func (p *GitHubPlugin) dispatchRepositoryEvent(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	var req struct {
+		EventType     string                     `json:"event_type"`
+		ClientPayload map[string]json.RawMessage `json:"client_payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.EventType == "" {
+		writeValidationError(w, "RepositoryDispatch", "event_type", "missing_field")
+		return
+	}
+	if utf8.RuneCountInString(req.EventType) > maxDispatchEventTypeLength {
+		writeValidationError(w, "RepositoryDispatch", "event_type", "invalid")
+		return
+	}
+	if len(req.ClientPayload) > maxClientPayloadProperties {
+		writeValidationError(w, "RepositoryDispatch", "client_payload", "too_many")
+		return
+	}
+	if req.ClientPayload == nil {
+		req.ClientPayload = map[string]json.RawMessage{}
+	}
+
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	payload, err := json.Marshal(req.ClientPayload)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode client_payload")
+		return
+	}
+	if _, err := p.store.CreateRepositoryDispatchEvent(repo.ID, req.EventType, string(payload), user.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to record dispatch event")
+		return
+	}
+
+	p.fireWebhooksAsync(repo.ID, "repository_dispatch", map[string]interface{}{
+		"action":         req.EventType,
+		"branch":         repo.DefaultBranch,
+		"client_payload": req.ClientPayload,
+		"repository": map[string]interface{}{
+			"id":        repo.ID,
+			"name":      repo.Name,
+			"full_name": repo.FullName,
+		},
+		"sender": map[string]interface{}{"login": user.Login, "id": user.ID},
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rawJSONObject passes a stored JSON object through, treating empty as {}
+func rawJSONObject(stored string) json.RawMessage {
+	if stored == "" {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(stored)
+}
+
+// listDispatchEvents handles GET /admin/github/dispatch-events, listing recent
+// repository_dispatch events and workflow_dispatch runs together, newest
+// first. ?limit= caps the number returned.
+func (p *GitHubPlugin) listDispatchEvents(w http.ResponseWriter, r *http.Request) {
+	limit := defaultDispatchEventsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxDispatchEventsLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	events, err := p.store.ListRepositoryDispatchEvents(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list dispatch events")
+		return
+	}
+	runs, err := p.store.ListWorkflowDispatchRuns(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list workflow dispatches")
+		return
+	}
+
+	// Look each repository and workflow up once
+	repoNames := map[int64]string{}
+	repoName := func(id int64) string {
+		if name, ok := repoNames[id]; ok {
+			return name
+		}
+		if repo, err := p.store.GetRepositoryByID(id); err == nil {
+			repoNames[id] = repo.FullName
+		}
+		return repoNames[id]
+	}
+	workflowPaths := map[int64]string{}
+
+	type item struct {
+		createdAt time.Time
+		body      map[string]interface{}
+	}
+	items := make([]item, 0, len(events)+len(runs))
+	for _, event := range events {
+		items = append(items, item{event.CreatedAt, map[string]interface{}{
+			"id":             event.ID,
+			"event":          "repository_dispatch",
+			"repository":     repoName(event.RepoID),
+			"event_type":     event.EventType,
+			"client_payload": rawJSONObject(event.ClientPayload),
+			"created_at":     event.CreatedAt.UTC().Format(time.RFC3339),
+		}})
+	}
+	for _, run := range runs {
+		path, ok := workflowPaths[run.WorkflowID]
+		if !ok {
+			if workflow, err := p.store.GetWorkflow(run.RepoID, strconv.FormatInt(run.WorkflowID, 10)); err == nil {
+				path = workflow.Path
+			}
+			workflowPaths[run.WorkflowID] = path
+		}
+		items = append(items, item{run.CreatedAt, map[string]interface{}{
+			"id":         run.ID,
+			"event":      "workflow_dispatch",
+			"repository": repoName(run.RepoID),
+			"workflow":   path,
+			"ref":        run.HeadBranch,
+			"inputs":     rawJSONObject(run.Inputs),
+			"run_id":     run.ID,
+			"status":     run.Status,
+			"created_at": run.CreatedAt.UTC().Format(time.RFC3339),
+		}})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].createdAt.After(items[j].createdAt) })
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	response := make([]map[string]interface{}, 0, len(items))
+	for _, it := range items {
+		response = append(response, it.body)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total_count":     len(response),
+		"dispatch_events": response,
+	})
+}
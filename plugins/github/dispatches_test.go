@@ -0,0 +1,112 @@
+// ABOUTME: Tests for repository dispatch events and the admin dispatch event listing
+// ABOUTME: Covers event_type validation, repository_dispatch and workflow_dispatch webhooks, and the listing
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func TestRepositoryDispatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	clock := core.NewFrozenClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	store.SetClock(clock)
+	plugin := &GitHubPlugin{store: store}
+	if err := plugin.Configure(core.PluginConfig{CustomSettings: map[string]string{"workflow_run_delay": "0s"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.CreateWorkflow(repo.ID, "CI", ".github/workflows/ci.yml")
+	result, err := db.Exec(`
+		INSERT INTO github_webhooks (repo_id, url, content_type, secret, events, active, delay_ms, created_at, updated_at)
+		VALUES (?, 'https://hooks.example.com/github', 'json', '', 'repository_dispatch,workflow_dispatch', 1, 0, ?, ?)
+	`, repo.ID, clock.Now(), clock.Now())
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	webhookID, _ := result.LastInsertId()
+
+	for body, wantField := range map[string]string{
+		`{"client_payload": {"a": 1}}`:                       "event_type",
+		`{"event_type": "` + strings.Repeat("x", 101) + `"}`: "event_type",
+		`{"event_type": "deploy", "client_payload": {"k0": 0, "k1": 1, "k2": 2, "k3": 3, "k4": 4, "k5": 5, "k6": 6, "k7": 7, "k8": 8, "k9": 9, "k10": 10}}`: "client_payload",
+	} {
+		w := serveGitHub(plugin, "POST", "/repos/alice/test-repo/dispatches", body)
+		var resp struct {
+			Errors []fieldError `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusUnprocessableEntity || len(resp.Errors) != 1 || resp.Errors[0].Field != wantField {
+			t.Errorf("Dispatch %s got %d: %s, want a 422 for %s", body, w.Code, w.Body.String(), wantField)
+		}
+	}
+	if w := serveGitHub(plugin, "POST", "/repos/alice/missing/dispatches", `{"event_type": "deploy"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Dispatch to a missing repo got %d, want 404", w.Code)
+	}
+
+	w := serveGitHub(plugin, "POST", "/repos/alice/test-repo/dispatches", `{"event_type": "deploy", "client_payload": {"env": "staging", "unit": false}}`)
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("Dispatch got %d: %s, want 204", w.Code, w.Body.String())
+	}
+	clock.Advance(time.Second)
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/actions/workflows/ci.yml/dispatches", `{"ref": "main", "inputs": {"debug": "true"}}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Workflow dispatch got %d: %s", w.Code, w.Body.String())
+	}
+	// Wait for the webhooks and for the run to complete
+	plugin.deliveries.Wait()
+
+	deliveries, _ := store.ListWebhookDeliveries(webhookID)
+	payloads := map[string]map[string]interface{}{}
+	for _, d := range deliveries {
+		var payload map[string]interface{}
+		json.Unmarshal([]byte(d.Payload), &payload)
+		payloads[d.EventType] = payload
+	}
+	if p := payloads["repository_dispatch"]; p == nil || p["action"] != "deploy" || p["branch"] != "main" ||
+		p["client_payload"].(map[string]interface{})["env"] != "staging" {
+		t.Errorf("Unexpected repository_dispatch payload: %v", p)
+	}
+	if p := payloads["workflow_dispatch"]; p == nil || p["ref"] != "refs/heads/main" || p["workflow"] != ".github/workflows/ci.yml" ||
+		p["inputs"].(map[string]interface{})["debug"] != "true" {
+		t.Errorf("Unexpected workflow_dispatch payload: %v", p)
+	}
+
+	w = serveGitHub(plugin, "GET", "/admin/github/dispatch-events", "")
+	var listing struct {
+		TotalCount     int                      `json:"total_count"`
+		DispatchEvents []map[string]interface{} `json:"dispatch_events"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &listing)
+	if w.Code != http.StatusOK || listing.TotalCount != 2 {
+		t.Fatalf("Expected 2 dispatch events, got %d: %s", w.Code, w.Body.String())
+	}
+	newest, oldest := listing.DispatchEvents[0], listing.DispatchEvents[1]
+	if newest["event"] != "workflow_dispatch" || newest["workflow"] != ".github/workflows/ci.yml" || newest["repository"] != "alice/test-repo" ||
+		newest["inputs"].(map[string]interface{})["debug"] != "true" || newest["status"] != "completed" {
+		t.Errorf("Unexpected workflow dispatch entry: %v", newest)
+	}
+	if oldest["event"] != "repository_dispatch" || oldest["event_type"] != "deploy" ||
+		oldest["client_payload"].(map[string]interface{})["unit"] != false {
+		t.Errorf("Unexpected repository dispatch entry: %v", oldest)
+	}
+
+	w = serveGitHub(plugin, "GET", "/admin/github/dispatch-events?limit=1", "")
+	json.Unmarshal(w.Body.Bytes(), &listing)
+	if listing.TotalCount != 1 || listing.DispatchEvents[0]["event"] != "workflow_dispatch" {
+		t.Errorf("Expected only the newest event with limit=1, got %s", w.Body.String())
+	}
+	if w := serveGitHub(plugin, "GET", "/admin/github/dispatch-events?limit=0", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("limit=0 got %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,66 @@
+// ABOUTME: Tests for dry runs of GitHub mutations
+// ABOUTME: Verifies a dry-run issue creation answers like the real thing without persisting
+
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDryRunCreateIssue(t *testing.T) {
+	db := setupTestDB(t)
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+
+	user, _ := store.GetOrCreateUser("alice", "ghp_test")
+	repo, _ := store.CreateRepository(user.ID, "test-repo", "", false)
+
+	createIssue := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(`{"title": "Bug in login"}`))
+		req.Header.Set("Authorization", "Bearer ghp_test")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := createIssue("/repos/alice/test-repo/issues?dryRun=true")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Ish-Dry-Run") != "true" {
+		t.Errorf("Expected X-Ish-Dry-Run: true, got %q", w.Header().Get("X-Ish-Dry-Run"))
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["number"] != float64(1) || resp["title"] != "Bug in login" {
+		t.Errorf("Expected the would-be issue #1, got %v", resp)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM github_issues WHERE repo_id = ?`, repo.ID).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("Expected the dry run to persist no issue, found %d", count)
+	}
+
+	// The real request gets the number the dry run predicted
+	w = createIssue("/repos/alice/test-repo/issues")
+	if w.Code != http.StatusCreated || w.Header().Get("X-Ish-Dry-Run") != "" {
+		t.Fatalf("Expected a real 201, got %d %v: %s", w.Code, w.Header(), w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["number"] != float64(1) {
+		t.Errorf("Expected issue #1, got %v", resp["number"])
+	}
+}
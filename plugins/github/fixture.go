@@ -0,0 +1,67 @@
+// ABOUTME: Fixture import for GitHub plugin
+// ABOUTME: Creates a fixture's repositories and issues, and the users they name, for `ish seed --from`
+
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// fixtureUser returns the user with login, creating them with the token
+// "ghp_<login>" if they don't exist yet
+func (p *GitHubPlugin) fixtureUser(login string) (*User, error) {
+	if user, err := p.store.GetUserByLogin(login); err == nil {
+		return user, nil
+	}
+	return p.store.GetOrCreateUser(login, "ghp_"+login)
+}
+
+// ImportFixture implements core.FixtureImporter, creating the fixture's
+// repositories and then its issues, which may name repositories that already
+// existed
+func (p *GitHubPlugin) ImportFixture(ctx context.Context, fixture *core.Fixture) (core.SeedData, error) {
+	for i, r := range fixture.Repos {
+		owner, err := p.fixtureUser(r.Owner)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("repos[%d]: %w", i, err)
+		}
+		if _, err := p.store.CreateRepository(owner.ID, r.Name, r.Description, r.Private); err != nil {
+			return core.SeedData{}, fmt.Errorf("repos[%d]: %w", i, err)
+		}
+	}
+
+	for i, fi := range fixture.Issues {
+		repo, err := p.store.GetRepositoryByFullName(fi.Repo)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("issues[%d]: repository %s not found", i, fi.Repo)
+		}
+		author := &User{ID: repo.OwnerID}
+		if fi.Author != "" {
+			if author, err = p.fixtureUser(fi.Author); err != nil {
+				return core.SeedData{}, fmt.Errorf("issues[%d]: %w", i, err)
+			}
+		}
+		issue, err := p.store.CreateIssue(repo.ID, author.ID, fi.Title, fi.Body, false)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("issues[%d]: %w", i, err)
+		}
+		if fi.State == "closed" {
+			issue.State = "closed"
+			issue.StateReason = "completed"
+			if err := p.store.UpdateIssue(issue); err != nil {
+				return core.SeedData{}, fmt.Errorf("issues[%d]: %w", i, err)
+			}
+		}
+	}
+
+	return core.SeedData{
+		Summary: fmt.Sprintf("Imported %d repos, %d issues", len(fixture.Repos), len(fixture.Issues)),
+		Records: map[string]int{
+			"repos":  len(fixture.Repos),
+			"issues": len(fixture.Issues),
+		},
+	}, nil
+}
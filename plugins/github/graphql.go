@@ -0,0 +1,516 @@
+// ABOUTME: Minimal GitHub GraphQL API at POST /graphql
+// ABOUTME: Supports viewer, repository issues, the createIssue mutation, __typename, and basic introspection
+
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxGraphQLBytes bounds the size of a GraphQL request body
+const maxGraphQLBytes = 1 << 20
+
+// maxConnectionSize is the largest page GitHub allows for first/last
+const maxConnectionSize = 100
+
+// graphQLError is one entry in a GraphQL response's errors list
+type graphQLError struct {
+	Type       string         `json:"type,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+	Message    string         `json:"message"`
+}
+
+// gqlField is one field of a selection set, such as issues(first: 10) { nodes { number } }
+type gqlField struct {
+	Alias     string
+	Name      string
+	Args      map[string]any
+	Selection []gqlField
+}
+
+// key is the response key for the field, its alias if it has one
+func (f gqlField) key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// ClaimsGraphQL implements core.GraphQLPlugin, taking requests whose token
+// this plugin issued
+func (p *GitHubPlugin) ClaimsGraphQL(r *http.Request) bool {
+	token, ok := extractToken(r)
+	if !ok {
+		return false
+	}
+	_, err := p.store.ValidateToken(token)
+	return err == nil
+}
+
+// gitHubTokenPrefixes start each kind of token GitHub issues
+var gitHubTokenPrefixes = []string{"ghp_", "gho_", "ghu_", "ghs_", "ghr_", "github_pat_"}
+
+// ClaimsUnknownToken implements core.UnknownTokenClaimer, so a GitHub-shaped
+// token this plugin didn't issue is refused with GitHub's bad credentials
+// error instead of reaching a plugin that accepts any key
+func (p *GitHubPlugin) ClaimsUnknownToken(token string) bool {
+	for _, prefix := range gitHubTokenPrefixes {
+		if strings.HasPrefix(token, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeGraphQL implements core.GraphQLPlugin
+func (p *GitHubPlugin) ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	p.requireAuth(p.handleGraphQL)(w, r)
+}
+
+// handleGraphQL handles POST /graphql. Documents are read with a small
+// selection-set scanner rather than a full GraphQL parser: only the first
+// operation is run, and named fragments and directives are not supported.
+func (p *GitHubPlugin) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	var req struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeGraphQL(w, nil, []graphQLError{{Message: "A query attribute must be specified and must be a string."}})
+		return
+	}
+
+	kind, fields, err := parseGraphQLOperation(req.Query, req.Variables)
+	if err != nil {
+		writeGraphQL(w, nil, []graphQLError{{Message: "Parse error: " + err.Error()}})
+		return
+	}
+
+	res := &gqlResolver{plugin: p, viewer: user}
+	var data map[string]any
+	if kind == "mutation" {
+		data = res.object("Mutation", fields, nil, res.mutationField)
+	} else {
+		data = res.object("Query", fields, nil, res.queryField)
+	}
+
+	// Unknown fields fail validation, so GitHub returns no data at all
+	if res.invalid {
+		data = nil
+	}
+	writeGraphQL(w, data, res.errs)
+}
+
+// writeGraphQL writes a {"data": ..., "errors": [...]} response. GraphQL
+// errors still use status 200, as on GitHub.
+func writeGraphQL(w http.ResponseWriter, data map[string]any, errs []graphQLError) {
+	resp := map[string]any{}
+	if data != nil {
+		resp["data"] = data
+	}
+	if len(errs) > 0 {
+		resp["errors"] = errs
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("GitHub: Failed to encode GraphQL response: %v", err)
+	}
+}
+
+// gqlResolver resolves one operation for the authenticated viewer
+type gqlResolver struct {
+	plugin  *GitHubPlugin
+	viewer  *User
+	errs    []graphQLError
+	invalid bool
+}
+
+// fieldResolver returns a field's value, or false if the type has no such field
+type fieldResolver func(f gqlField, path []any) (any, bool)
+
+// object resolves a selection set on a value of typeName
+func (res *gqlResolver) object(typeName string, sel []gqlField, path []any, resolve fieldResolver) map[string]any {
+	out := make(map[string]any, len(sel))
+	for _, f := range sel {
+		fieldPath := append(append([]any{}, path...), f.key())
+		if f.Name == "__typename" {
+			out[f.key()] = typeName
+			continue
+		}
+		value, ok := resolve(f, fieldPath)
+		if !ok {
+			res.invalid = true
+			res.errs = append(res.errs, graphQLError{
+				Path:       fieldPath,
+				Extensions: map[string]any{"code": "undefinedField", "typeName": typeName, "fieldName": f.Name},
+				Message:    fmt.Sprintf("Field '%s' doesn't exist on type '%s'", f.Name, typeName),
+			})
+			continue
+		}
+		out[f.key()] = value
+	}
+	return out
+}
+
+// notFound records a NOT_FOUND error at path
+func (res *gqlResolver) notFound(path []any, message string) {
+	res.errs = append(res.errs, graphQLError{Type: "NOT_FOUND", Path: path, Message: message})
+}
+
+func (res *gqlResolver) queryField(f gqlField, path []any) (any, bool) {
+	switch f.Name {
+	case "viewer":
+		return res.user(res.viewer, f.Selection, path), true
+	case "repository":
+		owner, _ := f.Args["owner"].(string)
+		name, _ := f.Args["name"].(string)
+		repo, err := res.plugin.store.GetRepositoryByFullName(owner + "/" + name)
+		if err != nil {
+			res.notFound(path, fmt.Sprintf("Could not resolve to a Repository with the name '%s/%s'.", owner, name))
+			return nil, true
+		}
+		return res.repository(repo, f.Selection, path), true
+	case "__schema":
+		return project(introspectionSchema(), f.Selection), true
+	case "__type":
+		name, _ := f.Args["name"].(string)
+		if t := introspectionType(name); t != nil {
+			return project(t, f.Selection), true
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+func (res *gqlResolver) mutationField(f gqlField, path []any) (any, bool) {
+	if f.Name != "createIssue" {
+		return nil, false
+	}
+
+	input, _ := f.Args["input"].(map[string]any)
+	repoID, _ := input["repositoryId"].(string)
+	title, _ := input["title"].(string)
+	body, _ := input["body"].(string)
+
+	typeName, id, ok := parseNodeID(repoID)
+	if !ok || typeName != "Repository" {
+		res.notFound(path, fmt.Sprintf("Could not resolve to a node with the global id of '%s'", repoID))
+		return nil, true
+	}
+	repo, err := res.plugin.store.GetRepositoryByID(id)
+	if err != nil {
+		res.notFound(path, fmt.Sprintf("Could not resolve to a node with the global id of '%s'", repoID))
+		return nil, true
+	}
+	if strings.TrimSpace(title) == "" {
+		res.errs = append(res.errs, graphQLError{Type: "UNPROCESSABLE", Path: path, Message: "Title can't be blank"})
+		return nil, true
+	}
+
+	issue, err := res.plugin.store.CreateIssue(repo.ID, res.viewer.ID, title, body, false)
+	if err != nil {
+		res.errs = append(res.errs, graphQLError{Path: path, Message: "Something went wrong while executing your query."})
+		return nil, true
+	}
+	res.plugin.fireIssueOpened(issue, res.viewer, repo)
+
+	return res.object("CreateIssuePayload", f.Selection, path, func(f gqlField, path []any) (any, bool) {
+		switch f.Name {
+		case "clientMutationId":
+			return input["clientMutationId"], true
+		case "issue":
+			return res.issue(issue, repo, f.Selection, path), true
+		}
+		return nil, false
+	}), true
+}
+
+func (res *gqlResolver) user(user *User, sel []gqlField, path []any) map[string]any {
+	return res.object("User", sel, path, func(f gqlField, path []any) (any, bool) {
+		switch f.Name {
+		case "id":
+			return nodeID("User", user.ID), true
+		case "databaseId":
+			return user.ID, true
+		case "login":
+			return user.Login, true
+		case "name":
+			return user.Name, true
+		case "email":
+			return user.Email, true
+		case "avatarUrl":
+			return user.AvatarURL, true
+		case "url":
+			return "https://github.com/" + user.Login, true
+		case "createdAt":
+			return user.CreatedAt.UTC().Format(time.RFC3339), true
+		case "updatedAt":
+			return user.UpdatedAt.UTC().Format(time.RFC3339), true
+		}
+		return nil, false
+	})
+}
+
+func (res *gqlResolver) repository(repo *Repository, sel []gqlField, path []any) map[string]any {
+	return res.object("Repository", sel, path, func(f gqlField, path []any) (any, bool) {
+		switch f.Name {
+		case "id":
+			return nodeID("Repository", repo.ID), true
+		case "databaseId":
+			return repo.ID, true
+		case "name":
+			return repo.Name, true
+		case "nameWithOwner":
+			return repo.FullName, true
+		case "description":
+			if repo.Description == "" {
+				return nil, true
+			}
+			return repo.Description, true
+		case "url":
+			return "https://github.com/" + repo.FullName, true
+		case "isPrivate":
+			return repo.Private, true
+		case "isFork":
+			return repo.Fork, true
+		case "isArchived":
+			return repo.Archived, true
+		case "stargazerCount":
+			return repo.StargazersCount, true
+		case "forkCount":
+			return repo.ForksCount, true
+		case "createdAt":
+			return repo.CreatedAt.UTC().Format(time.RFC3339), true
+		case "updatedAt":
+			return repo.UpdatedAt.UTC().Format(time.RFC3339), true
+		case "owner":
+			owner, err := res.plugin.store.GetUserByID(repo.OwnerID)
+			if err != nil {
+				return nil, true
+			}
+			return res.user(owner, f.Selection, path), true
+		case "defaultBranchRef":
+			return res.object("Ref", f.Selection, path, func(f gqlField, _ []any) (any, bool) {
+				if f.Name == "name" {
+					return repo.DefaultBranch, true
+				}
+				return nil, false
+			}), true
+		case "issues":
+			return res.issues(repo, f, path), true
+		}
+		return nil, false
+	})
+}
+
+// issues resolves an IssueConnection, oldest first like GitHub's default order
+func (res *gqlResolver) issues(repo *Repository, f gqlField, path []any) any {
+	state := ""
+	if states, ok := f.Args["states"].([]any); ok && len(states) == 1 {
+		if s, ok := states[0].(string); ok {
+			state = strings.ToLower(s)
+		}
+	}
+	all, err := res.plugin.store.ListIssues(repo.ID, state, false)
+	if err != nil {
+		res.errs = append(res.errs, graphQLError{Path: path, Message: "Something went wrong while executing your query."})
+		return nil
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Number < all[j].Number })
+
+	first := maxConnectionSize
+	if n, ok := intArg(f.Args["first"]); ok && n >= 0 && n < first {
+		first = n
+	}
+	offset := 0
+	if after, ok := f.Args["after"].(string); ok {
+		if n, ok := parseCursor(after); ok && n < len(all) {
+			offset = n + 1
+		} else if ok {
+			offset = len(all)
+		}
+	}
+	page := all[offset:]
+	if len(page) > first {
+		page = page[:first]
+	}
+
+	cursor := func(i int) string { return encodeCursor(offset + i) }
+	return res.object("IssueConnection", f.Selection, path, func(f gqlField, path []any) (any, bool) {
+		switch f.Name {
+		case "totalCount":
+			return len(all), true
+		case "nodes":
+			nodes := make([]any, len(page))
+			for i, issue := range page {
+				nodes[i] = res.issue(issue, repo, f.Selection, append(path, i))
+			}
+			return nodes, true
+		case "edges":
+			edges := make([]any, len(page))
+			for i, issue := range page {
+				edges[i] = res.object("IssueEdge", f.Selection, append(path, i), func(f gqlField, path []any) (any, bool) {
+					switch f.Name {
+					case "cursor":
+						return cursor(i), true
+					case "node":
+						return res.issue(issue, repo, f.Selection, path), true
+					}
+					return nil, false
+				})
+			}
+			return edges, true
+		case "pageInfo":
+			return res.object("PageInfo", f.Selection, path, func(f gqlField, _ []any) (any, bool) {
+				switch f.Name {
+				case "hasNextPage":
+					return offset+len(page) < len(all), true
+				case "hasPreviousPage":
+					return offset > 0, true
+				case "startCursor", "endCursor":
+					if len(page) == 0 {
+						return nil, true
+					}
+					if f.Name == "startCursor" {
+						return cursor(0), true
+					}
+					return cursor(len(page) - 1), true
+				}
+				return nil, false
+			}), true
+		}
+		return nil, false
+	})
+}
+
+func (res *gqlResolver) issue(issue *Issue, repo *Repository, sel []gqlField, path []any) map[string]any {
+	return res.object("Issue", sel, path, func(f gqlField, path []any) (any, bool) {
+		switch f.Name {
+		case "id":
+			return nodeID("Issue", issue.ID), true
+		case "databaseId":
+			return issue.ID, true
+		case "number":
+			return issue.Number, true
+		case "title":
+			return issue.Title, true
+		case "body":
+			return issue.Body, true
+		case "state":
+			return strings.ToUpper(issue.State), true
+		case "closed":
+			return issue.State == "closed", true
+		case "url":
+			return fmt.Sprintf("https://github.com/%s/issues/%d", repo.FullName, issue.Number), true
+		case "createdAt":
+			return issue.CreatedAt.UTC().Format(time.RFC3339), true
+		case "updatedAt":
+			return issue.UpdatedAt.UTC().Format(time.RFC3339), true
+		case "closedAt":
+			if issue.ClosedAt == nil {
+				return nil, true
+			}
+			return issue.ClosedAt.UTC().Format(time.RFC3339), true
+		case "author":
+			author, err := res.plugin.store.GetUserByID(issue.UserID)
+			if err != nil {
+				return nil, true
+			}
+			return res.user(author, f.Selection, path), true
+		case "repository":
+			return res.repository(repo, f.Selection, path), true
+		}
+		return nil, false
+	})
+}
+
+// nodeID builds a legacy-format global node ID, e.g. "010:Repository42" base64 encoded
+func nodeID(typeName string, id int64) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("0%d:%s%d", len(typeName), typeName, id)))
+}
+
+// parseNodeID reverses nodeID
+func parseNodeID(s string) (string, int64, bool) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", 0, false
+	}
+	length, rest, ok := strings.Cut(string(raw), ":")
+	n, err := strconv.Atoi(length)
+	if !ok || err != nil || n <= 0 || n > len(rest) {
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(rest[n:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return rest[:n], id, true
+}
+
+// encodeCursor and parseCursor map list offsets to opaque connection cursors
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte("cursor:" + strconv.Itoa(offset)))
+}
+
+func parseCursor(cursor string) (int, bool) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(string(raw), "cursor:"))
+	return n, err == nil && n >= 0
+}
+
+// intArg reads an integer argument given inline or as a JSON variable
+func intArg(v any) (int, bool) {
+	switch n := v.(type) {
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// project picks the selected fields out of a static value, such as the
+// introspection schema. An empty selection returns the value as is.
+func project(v any, sel []gqlField) any {
+	if len(sel) == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(sel))
+		for _, f := range sel {
+			out[f.key()] = project(v[f.Name], f.Selection)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = project(item, sel)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,330 @@
+// ABOUTME: Selection-set scanner for the GitHub GraphQL endpoint
+// ABOUTME: Reads one operation's fields, arguments, and variables without a full GraphQL grammar
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gqlScanner walks a GraphQL document one token at a time
+type gqlScanner struct {
+	src  string
+	pos  int
+	vars map[string]any
+}
+
+// parseGraphQLOperation returns the kind ("query" or "mutation") and top-level
+// fields of the first operation in a document
+func parseGraphQLOperation(src string, vars map[string]any) (string, []gqlField, error) {
+	s := &gqlScanner{src: src, vars: vars}
+	s.skip()
+
+	kind := "query"
+	if !s.peek('{') {
+		kind = s.name()
+		if kind != "query" && kind != "mutation" {
+			return "", nil, fmt.Errorf("unsupported operation %q", kind)
+		}
+		s.skip()
+		if !s.peek('{') && !s.peek('(') {
+			s.name() // operation name
+			s.skip()
+		}
+		if s.peek('(') {
+			// Variable definitions only declare types; values come from the request
+			if err := s.skipBalanced('(', ')'); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
+	fields, err := s.selectionSet()
+	if err != nil {
+		return "", nil, err
+	}
+	return kind, fields, nil
+}
+
+// skip moves past whitespace, commas, and comments
+func (s *gqlScanner) skip() {
+	for s.pos < len(s.src) {
+		switch c := s.src[s.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			s.pos++
+		case c == '#':
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *gqlScanner) peek(c byte) bool {
+	return s.pos < len(s.src) && s.src[s.pos] == c
+}
+
+// expect consumes c and any whitespace after it
+func (s *gqlScanner) expect(c byte) error {
+	if !s.peek(c) {
+		return s.errorf("expected %q", c)
+	}
+	s.pos++
+	s.skip()
+	return nil
+}
+
+func (s *gqlScanner) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s at offset %d", fmt.Sprintf(format, args...), s.pos)
+}
+
+// name reads an identifier, returning "" if there is none
+func (s *gqlScanner) name() string {
+	start := s.pos
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || s.pos > start && c >= '0' && c <= '9' {
+			s.pos++
+			continue
+		}
+		break
+	}
+	return s.src[start:s.pos]
+}
+
+// skipBalanced skips a bracketed section such as variable definitions
+func (s *gqlScanner) skipBalanced(open, close byte) error {
+	depth := 0
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				s.pos++
+				s.skip()
+				return nil
+			}
+		}
+		s.pos++
+	}
+	return s.errorf("unterminated %q", open)
+}
+
+// selectionSet reads { field field ... }
+func (s *gqlScanner) selectionSet() ([]gqlField, error) {
+	if err := s.expect('{'); err != nil {
+		return nil, err
+	}
+	var fields []gqlField
+	for !s.peek('}') {
+		if s.pos >= len(s.src) {
+			return nil, s.errorf("unterminated selection set")
+		}
+		if strings.HasPrefix(s.src[s.pos:], "...") {
+			inline, err := s.inlineFragment()
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, inline...)
+			continue
+		}
+		field, err := s.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	s.pos++
+	s.skip()
+	return fields, nil
+}
+
+// inlineFragment reads "... on Type { ... }", merging its fields into the
+// parent; named fragment spreads are not supported
+func (s *gqlScanner) inlineFragment() ([]gqlField, error) {
+	s.pos += len("...")
+	s.skip()
+	if s.peek('{') {
+		return s.selectionSet()
+	}
+	if s.name() != "on" {
+		return nil, s.errorf("named fragments are not supported")
+	}
+	s.skip()
+	s.name()
+	s.skip()
+	return s.selectionSet()
+}
+
+// field reads [alias:] name [(args)] [{ selection }]
+func (s *gqlScanner) field() (gqlField, error) {
+	var f gqlField
+	f.Name = s.name()
+	if f.Name == "" {
+		return f, s.errorf("expected a field name")
+	}
+	s.skip()
+	if s.peek(':') {
+		s.pos++
+		s.skip()
+		f.Alias = f.Name
+		if f.Name = s.name(); f.Name == "" {
+			return f, s.errorf("expected a field name after alias %q", f.Alias)
+		}
+		s.skip()
+	}
+	if s.peek('(') {
+		args, err := s.arguments()
+		if err != nil {
+			return f, err
+		}
+		f.Args = args
+	}
+	if s.peek('@') {
+		return f, s.errorf("directives are not supported")
+	}
+	if s.peek('{') {
+		sel, err := s.selectionSet()
+		if err != nil {
+			return f, err
+		}
+		f.Selection = sel
+	}
+	return f, nil
+}
+
+// arguments reads (name: value, ...)
+func (s *gqlScanner) arguments() (map[string]any, error) {
+	if err := s.expect('('); err != nil {
+		return nil, err
+	}
+	args := map[string]any{}
+	for !s.peek(')') {
+		name := s.name()
+		if name == "" {
+			return nil, s.errorf("expected an argument name")
+		}
+		s.skip()
+		if err := s.expect(':'); err != nil {
+			return nil, err
+		}
+		value, err := s.value()
+		if err != nil {
+			return nil, err
+		}
+		args[name] = value
+	}
+	s.pos++
+	s.skip()
+	return args, nil
+}
+
+// value reads a literal, list, input object, or $variable. Integers come back
+// as int64 and floats as float64, matching JSON variables after conversion.
+func (s *gqlScanner) value() (any, error) {
+	if s.pos >= len(s.src) {
+		return nil, s.errorf("expected a value")
+	}
+	defer s.skip()
+
+	switch c := s.src[s.pos]; {
+	case c == '$':
+		s.pos++
+		return s.vars[s.name()], nil
+	case c == '"':
+		return s.stringValue()
+	case c == '[':
+		s.pos++
+		s.skip()
+		list := []any{}
+		for !s.peek(']') {
+			v, err := s.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		s.pos++
+		return list, nil
+	case c == '{':
+		s.pos++
+		s.skip()
+		obj := map[string]any{}
+		for !s.peek('}') {
+			name := s.name()
+			if name == "" {
+				return nil, s.errorf("expected an input field name")
+			}
+			s.skip()
+			if err := s.expect(':'); err != nil {
+				return nil, err
+			}
+			v, err := s.value()
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = v
+		}
+		s.pos++
+		return obj, nil
+	case c == '-' || c >= '0' && c <= '9':
+		start := s.pos
+		for s.pos < len(s.src) && strings.IndexByte("-+.eE0123456789", s.src[s.pos]) >= 0 {
+			s.pos++
+		}
+		text := s.src[start:s.pos]
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, s.errorf("invalid number %q", text)
+		}
+		return f, nil
+	}
+
+	switch name := s.name(); name {
+	case "":
+		return nil, s.errorf("expected a value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	default:
+		// Enum values such as OPEN are passed on as strings
+		return name, nil
+	}
+}
+
+// stringValue reads a quoted string; GraphQL escapes are a subset of JSON's
+func (s *gqlScanner) stringValue() (string, error) {
+	if strings.HasPrefix(s.src[s.pos:], `"""`) {
+		return "", s.errorf("block strings are not supported")
+	}
+	start := s.pos
+	s.pos++
+	for s.pos < len(s.src) && s.src[s.pos] != '"' {
+		if s.src[s.pos] == '\\' {
+			s.pos++
+		}
+		s.pos++
+	}
+	if s.pos >= len(s.src) {
+		return "", s.errorf("unterminated string")
+	}
+	s.pos++
+	var str string
+	if err := json.Unmarshal([]byte(s.src[start:s.pos]), &str); err != nil {
+		return "", s.errorf("invalid string")
+	}
+	return str, nil
+}
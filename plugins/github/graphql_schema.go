@@ -0,0 +1,144 @@
+// ABOUTME: Introspection data for the GitHub GraphQL endpoint
+// ABOUTME: Describes only the types and fields the mock resolves, enough for clients probing __schema
+
+package github
+
+import "strings"
+
+// gqlTypeDef describes one type in the mock schema. Field types use SDL
+// notation such as "String!" or "[Issue]".
+type gqlTypeDef struct {
+	Kind   string
+	Name   string
+	Fields [][2]string
+}
+
+var gqlTypes = []gqlTypeDef{
+	{Kind: "OBJECT", Name: "Query", Fields: [][2]string{
+		{"viewer", "User!"}, {"repository", "Repository"},
+	}},
+	{Kind: "OBJECT", Name: "Mutation", Fields: [][2]string{
+		{"createIssue", "CreateIssuePayload"},
+	}},
+	{Kind: "OBJECT", Name: "User", Fields: [][2]string{
+		{"id", "ID!"}, {"databaseId", "Int"}, {"login", "String!"}, {"name", "String"},
+		{"email", "String!"}, {"avatarUrl", "URI!"}, {"url", "URI!"},
+		{"createdAt", "DateTime!"}, {"updatedAt", "DateTime!"},
+	}},
+	{Kind: "OBJECT", Name: "Repository", Fields: [][2]string{
+		{"id", "ID!"}, {"databaseId", "Int"}, {"name", "String!"}, {"nameWithOwner", "String!"},
+		{"description", "String"}, {"url", "URI!"}, {"isPrivate", "Boolean!"}, {"isFork", "Boolean!"},
+		{"isArchived", "Boolean!"}, {"stargazerCount", "Int!"}, {"forkCount", "Int!"},
+		{"createdAt", "DateTime!"}, {"updatedAt", "DateTime!"}, {"owner", "User!"},
+		{"defaultBranchRef", "Ref"}, {"issues", "IssueConnection!"},
+	}},
+	{Kind: "OBJECT", Name: "Ref", Fields: [][2]string{
+		{"name", "String!"},
+	}},
+	{Kind: "OBJECT", Name: "Issue", Fields: [][2]string{
+		{"id", "ID!"}, {"databaseId", "Int"}, {"number", "Int!"}, {"title", "String!"},
+		{"body", "String!"}, {"state", "IssueState!"}, {"closed", "Boolean!"}, {"url", "URI!"},
+		{"createdAt", "DateTime!"}, {"updatedAt", "DateTime!"}, {"closedAt", "DateTime"},
+		{"author", "User"}, {"repository", "Repository!"},
+	}},
+	{Kind: "OBJECT", Name: "IssueConnection", Fields: [][2]string{
+		{"totalCount", "Int!"}, {"nodes", "[Issue]"}, {"edges", "[IssueEdge]"}, {"pageInfo", "PageInfo!"},
+	}},
+	{Kind: "OBJECT", Name: "IssueEdge", Fields: [][2]string{
+		{"cursor", "String!"}, {"node", "Issue"},
+	}},
+	{Kind: "OBJECT", Name: "PageInfo", Fields: [][2]string{
+		{"hasNextPage", "Boolean!"}, {"hasPreviousPage", "Boolean!"},
+		{"startCursor", "String"}, {"endCursor", "String"},
+	}},
+	{Kind: "OBJECT", Name: "CreateIssuePayload", Fields: [][2]string{
+		{"clientMutationId", "String"}, {"issue", "Issue"},
+	}},
+	{Kind: "INPUT_OBJECT", Name: "CreateIssueInput"},
+	{Kind: "ENUM", Name: "IssueState"},
+	{Kind: "SCALAR", Name: "ID"},
+	{Kind: "SCALAR", Name: "String"},
+	{Kind: "SCALAR", Name: "Int"},
+	{Kind: "SCALAR", Name: "Boolean"},
+	{Kind: "SCALAR", Name: "DateTime"},
+	{Kind: "SCALAR", Name: "URI"},
+}
+
+// gqlTypeRef converts SDL notation into an introspection __Type reference
+func gqlTypeRef(sdl string) map[string]any {
+	if inner, ok := strings.CutSuffix(sdl, "!"); ok {
+		return map[string]any{"kind": "NON_NULL", "name": nil, "ofType": gqlTypeRef(inner)}
+	}
+	if strings.HasPrefix(sdl, "[") {
+		return map[string]any{"kind": "LIST", "name": nil, "ofType": gqlTypeRef(sdl[1 : len(sdl)-1])}
+	}
+	for _, t := range gqlTypes {
+		if t.Name == sdl {
+			return map[string]any{"kind": t.Kind, "name": t.Name, "ofType": nil}
+		}
+	}
+	return map[string]any{"kind": "SCALAR", "name": sdl, "ofType": nil}
+}
+
+// introspectionTypeDef builds the __Type value for a type definition
+func introspectionTypeDef(t gqlTypeDef) map[string]any {
+	var fields any
+	if t.Kind == "OBJECT" {
+		list := make([]any, len(t.Fields))
+		for i, f := range t.Fields {
+			list[i] = map[string]any{
+				"name":              f[0],
+				"description":       nil,
+				"args":              []any{},
+				"type":              gqlTypeRef(f[1]),
+				"isDeprecated":      false,
+				"deprecationReason": nil,
+			}
+		}
+		fields = list
+	}
+	var enumValues any
+	if t.Name == "IssueState" {
+		enumValues = []any{
+			map[string]any{"name": "OPEN", "description": nil, "isDeprecated": false, "deprecationReason": nil},
+			map[string]any{"name": "CLOSED", "description": nil, "isDeprecated": false, "deprecationReason": nil},
+		}
+	}
+	return map[string]any{
+		"kind":          t.Kind,
+		"name":          t.Name,
+		"description":   nil,
+		"fields":        fields,
+		"inputFields":   nil,
+		"interfaces":    []any{},
+		"enumValues":    enumValues,
+		"possibleTypes": nil,
+		"ofType":        nil,
+	}
+}
+
+// introspectionSchema returns the __Schema value
+func introspectionSchema() map[string]any {
+	types := make([]any, len(gqlTypes))
+	for i, t := range gqlTypes {
+		types[i] = introspectionTypeDef(t)
+	}
+	return map[string]any{
+		"description":      nil,
+		"queryType":        map[string]any{"name": "Query", "kind": "OBJECT"},
+		"mutationType":     map[string]any{"name": "Mutation", "kind": "OBJECT"},
+		"subscriptionType": nil,
+		"types":            types,
+		"directives":       []any{},
+	}
+}
+
+// introspectionType returns the __Type value for a named type, or nil if unknown
+func introspectionType(name string) map[string]any {
+	for _, t := range gqlTypes {
+		if t.Name == name {
+			return introspectionTypeDef(t)
+		}
+	}
+	return nil
+}
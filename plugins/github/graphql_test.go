@@ -0,0 +1,222 @@
+// ABOUTME: Tests for the GitHub GraphQL endpoint
+// ABOUTME: Covers viewer and repository queries, createIssue, __typename, introspection, and field errors
+
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func setupGraphQLPlugin(t *testing.T) (*GitHubPlugin, *GitHubStore) {
+	t.Helper()
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	store, _ := NewGitHubStore(db)
+	return &GitHubPlugin{store: store}, store
+}
+
+// graphQL posts a query as token and decodes the response envelope
+func graphQL(t *testing.T, plugin *GitHubPlugin, token, query string, variables map[string]any) (int, map[string]any) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"query": query, "variables": variables})
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	plugin.ServeGraphQL(w, req)
+
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON response %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// dig walks nested maps and lists by key or index
+func dig(v any, path ...any) any {
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			m, _ := v.(map[string]any)
+			v = m[key]
+		case int:
+			list, _ := v.([]any)
+			if key >= len(list) {
+				return nil
+			}
+			v = list[key]
+		}
+	}
+	return v
+}
+
+func TestGraphQLViewer(t *testing.T) {
+	plugin, store := setupGraphQLPlugin(t)
+	store.GetOrCreateUser("alice", "ghp_alice")
+
+	code, resp := graphQL(t, plugin, "ghp_alice", `query { viewer { login email __typename } }`, nil)
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", code, resp)
+	}
+	viewer, _ := dig(resp, "data", "viewer").(map[string]any)
+	if viewer["login"] != "alice" || viewer["__typename"] != "User" {
+		t.Errorf("Expected alice as a User, got %v", viewer)
+	}
+	if _, ok := viewer["email"]; !ok || len(viewer) != 3 {
+		t.Errorf("Expected only the selected fields, got %v", viewer)
+	}
+
+	_, resp = graphQL(t, plugin, "ghp_alice", `{ __typename }`, nil)
+	if dig(resp, "data", "__typename") != "Query" {
+		t.Errorf("Expected __typename Query on the root, got %v", resp)
+	}
+}
+
+func TestGraphQLRepositoryIssues(t *testing.T) {
+	plugin, store := setupGraphQLPlugin(t)
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "widgets", "", false)
+	for _, title := range []string{"First", "Second", "Third"} {
+		store.CreateIssue(repo.ID, alice.ID, title, "", false)
+	}
+
+	query := `query($owner: String!, $name: String!) {
+		repository(owner: $owner, name: $name) {
+			name
+			issues(first: 2) { totalCount nodes { number title state } pageInfo { hasNextPage endCursor } }
+		}
+	}`
+	_, resp := graphQL(t, plugin, "ghp_alice", query, map[string]any{"owner": "alice", "name": "widgets"})
+	if errs := resp["errors"]; errs != nil {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	issues := dig(resp, "data", "repository", "issues")
+	if dig(resp, "data", "repository", "name") != "widgets" || dig(issues, "totalCount") != float64(3) {
+		t.Fatalf("Expected widgets with 3 issues, got %v", resp)
+	}
+	if dig(issues, "nodes", 0, "number") != float64(1) || dig(issues, "nodes", 1, "title") != "Second" {
+		t.Errorf("Expected the first two issues oldest first, got %v", dig(issues, "nodes"))
+	}
+	if dig(issues, "nodes", 0, "state") != "OPEN" || dig(issues, "pageInfo", "hasNextPage") != true {
+		t.Errorf("Expected OPEN issues and a next page, got %v", issues)
+	}
+
+	// The end cursor continues where the first page stopped
+	after, _ := dig(issues, "pageInfo", "endCursor").(string)
+	_, resp = graphQL(t, plugin, "ghp_alice",
+		`{ repository(owner: "alice", name: "widgets") { issues(first: 10, after: "`+after+`") { nodes { title } } } }`, nil)
+	if nodes, _ := dig(resp, "data", "repository", "issues", "nodes").([]any); len(nodes) != 1 || dig(nodes, 0, "title") != "Third" {
+		t.Errorf("Expected only Third after the cursor, got %v", resp)
+	}
+
+	_, resp = graphQL(t, plugin, "ghp_alice", `{ repository(owner: "alice", name: "missing") { name } }`, nil)
+	if dig(resp, "errors", 0, "type") != "NOT_FOUND" || dig(resp, "data", "repository") != nil {
+		t.Errorf("Expected NOT_FOUND with a null repository, got %v", resp)
+	}
+}
+
+func TestGraphQLCreateIssue(t *testing.T) {
+	plugin, store := setupGraphQLPlugin(t)
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "widgets", "", false)
+
+	_, resp := graphQL(t, plugin, "ghp_alice", `{ repository(owner: "alice", name: "widgets") { id } }`, nil)
+	repoID, _ := dig(resp, "data", "repository", "id").(string)
+	if repoID == "" {
+		t.Fatalf("Expected a repository node ID, got %v", resp)
+	}
+
+	mutation := `mutation { createIssue(input: {repositoryId: "` + repoID + `", title: "Broken \"build\"", body: "CI is red"}) { issue { number title } } }`
+	_, resp = graphQL(t, plugin, "ghp_alice", mutation, nil)
+	if dig(resp, "data", "createIssue", "issue", "number") != float64(1) {
+		t.Fatalf("Expected issue number 1, got %v", resp)
+	}
+	if dig(resp, "data", "createIssue", "issue", "title") != `Broken "build"` {
+		t.Errorf("Expected escaped quotes to be decoded, got %v", resp)
+	}
+
+	// Input can also come from variables
+	_, resp = graphQL(t, plugin, "ghp_alice",
+		`mutation CreateIssue($input: CreateIssueInput!) { createIssue(input: $input) { issue { number } } }`,
+		map[string]any{"input": map[string]any{"repositoryId": repoID, "title": "Second"}})
+	if dig(resp, "data", "createIssue", "issue", "number") != float64(2) {
+		t.Fatalf("Expected issue number 2, got %v", resp)
+	}
+
+	issue, err := store.GetIssueByNumber(repo.ID, 1)
+	if err != nil || issue.Body != "CI is red" || issue.UserID != alice.ID {
+		t.Errorf("Expected the issue stored with its body and author, got %+v, %v", issue, err)
+	}
+
+	_, resp = graphQL(t, plugin, "ghp_alice", `mutation { createIssue(input: {repositoryId: "bogus", title: "x"}) { issue { number } } }`, nil)
+	if dig(resp, "errors", 0, "type") != "NOT_FOUND" {
+		t.Errorf("Expected NOT_FOUND for an unknown repository ID, got %v", resp)
+	}
+}
+
+func TestGraphQLIntrospection(t *testing.T) {
+	plugin, store := setupGraphQLPlugin(t)
+	store.GetOrCreateUser("alice", "ghp_alice")
+
+	_, resp := graphQL(t, plugin, "ghp_alice", `{ __schema { queryType { name } mutationType { name } types { name } } }`, nil)
+	if dig(resp, "data", "__schema", "queryType", "name") != "Query" || dig(resp, "data", "__schema", "mutationType", "name") != "Mutation" {
+		t.Fatalf("Expected Query and Mutation root types, got %v", resp)
+	}
+	if types, _ := dig(resp, "data", "__schema", "types").([]any); len(types) == 0 || len(types[0].(map[string]any)) != 1 {
+		t.Errorf("Expected types projected to their names, got %v", types)
+	}
+
+	_, resp = graphQL(t, plugin, "ghp_alice", `{ __type(name: "Issue") { kind fields { name } } }`, nil)
+	if dig(resp, "data", "__type", "kind") != "OBJECT" || dig(resp, "data", "__type", "fields", 0, "name") != "id" {
+		t.Errorf("Expected the Issue type, got %v", resp)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	plugin, store := setupGraphQLPlugin(t)
+	store.GetOrCreateUser("alice", "ghp_alice")
+
+	_, resp := graphQL(t, plugin, "ghp_alice", `{ viewer { login shoeSize } }`, nil)
+	if resp["data"] != nil || dig(resp, "errors", 0, "message") != "Field 'shoeSize' doesn't exist on type 'User'" {
+		t.Errorf("Expected an undefined field error and no data, got %v", resp)
+	}
+
+	_, resp = graphQL(t, plugin, "ghp_alice", `{ viewer { login `, nil)
+	if resp["data"] != nil || dig(resp, "errors", 0, "message") == nil {
+		t.Errorf("Expected a parse error, got %v", resp)
+	}
+
+	if code, resp := graphQL(t, plugin, "ghp_unknown", `{ viewer { login } }`, nil); code != http.StatusUnauthorized || resp["message"] != "bad credentials" {
+		t.Errorf("Expected 401 bad credentials for an unknown token, got %d %v", code, resp)
+	}
+}
+
+func TestGraphQLClaims(t *testing.T) {
+	plugin, store := setupGraphQLPlugin(t)
+	store.GetOrCreateUser("alice", "ghp_alice")
+
+	for token, want := range map[string]bool{"ghp_alice": true, "ghp_unknown": false, "lin_api_key": false, "": false} {
+		req := httptest.NewRequest("POST", "/graphql", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if got := plugin.ClaimsGraphQL(req); got != want {
+			t.Errorf("ClaimsGraphQL(%q) = %v, want %v", token, got, want)
+		}
+	}
+
+	// Unknown GitHub-shaped tokens are claimed too, so GitHub, not Linear, refuses them
+	for token, want := range map[string]bool{
+		"ghp_unknown":    true,
+		"gho_unknown":    true,
+		"github_pat_abc": true,
+		"lin_api_key":    false,
+	} {
+		if got := plugin.ClaimsUnknownToken(token); got != want {
+			t.Errorf("ClaimsUnknownToken(%q) = %v, want %v", token, got, want)
+		}
+	}
+}
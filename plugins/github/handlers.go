@@ -6,6 +6,8 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -21,7 +23,13 @@ func (p *GitHubPlugin) getAuthenticatedUser(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	response := map[string]interface{}{
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(userToResponse(user))
+}
+
+// userToResponse formats a user as GET /user returns it
+func userToResponse(user *User) map[string]interface{} {
+	return map[string]interface{}{
 		"login":      user.Login,
 		"id":         user.ID,
 		"type":       user.Type,
@@ -31,9 +39,6 @@ func (p *GitHubPlugin) getAuthenticatedUser(w http.ResponseWriter, r *http.Reque
 		"created_at": user.CreatedAt.Format(time.RFC3339),
 		"updated_at": user.UpdatedAt.Format(time.RFC3339),
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
 }
 
 // createUserRepository handles POST /user/repos
@@ -56,7 +61,7 @@ func (p *GitHubPlugin) createUserRepository(w http.ResponseWriter, r *http.Reque
 	}
 
 	if req.Name == "" {
-		writeError(w, http.StatusBadRequest, "name is required")
+		writeValidationError(w, "Repository", "name", "missing_field")
 		return
 	}
 
@@ -116,13 +121,133 @@ func (p *GitHubPlugin) getRepository(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := repositoryToResponse(repo, ownerUser)
+	if repo.Fork {
+		p.addForkLineage(response, repo.ID)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// forkRepository handles POST /repos/{owner}/{repo}/forks
+func (p *GitHubPlugin) forkRepository(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+	owner := chi.URLParam(r, "owner")
+	repoName := chi.URLParam(r, "repo")
+
+	// Body is optional; an empty request forks with the source name
+	var req struct {
+		Name string `json:"name"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	fullName := owner + "/" + repoName
+	source, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	fork, err := p.store.ForkRepositoryNamed(user.ID, source, req.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to fork repository")
+		return
+	}
+
+	response := repositoryToResponse(fork, user)
+	p.addForkLineage(response, fork.ID)
+
+	// Fire webhooks for fork event on the forked repository
+	webhookPayload := map[string]interface{}{
+		"forkee": response,
+		"repository": map[string]interface{}{
+			"id":        source.ID,
+			"name":      source.Name,
+			"full_name": source.FullName,
+		},
+	}
+	p.fireWebhooksAsync(source.ID, "fork", webhookPayload)
+
+	// GitHub creates forks asynchronously and responds with 202 Accepted
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// listForks handles GET /repos/{owner}/{repo}/forks
+func (p *GitHubPlugin) listForks(w http.ResponseWriter, r *http.Request) {
+	owner := chi.URLParam(r, "owner")
+	repoName := chi.URLParam(r, "repo")
+
+	fullName := owner + "/" + repoName
+	source, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && sort != "newest" && sort != "oldest" && sort != "stargazers" && sort != "watchers" {
+		writeError(w, http.StatusUnprocessableEntity, "sort must be one of newest, oldest, stargazers, watchers")
+		return
+	}
+
+	forks, err := p.store.ListForks(source.ID, sort)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list forks")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(forks))
+	for _, fork := range forks {
+		forkOwner, err := p.store.GetUserByID(fork.OwnerID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get owner")
+			return
+		}
+		response = append(response, repositoryToResponse(fork, forkOwner))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// addForkLineage adds the parent and source repositories to a fork's response
+func (p *GitHubPlugin) addForkLineage(response map[string]interface{}, repoID int64) {
+	parentID, sourceID, err := p.store.GetForkLineage(repoID)
+	if err != nil {
+		return
+	}
+
+	for key, id := range map[string]int64{"parent": parentID, "source": sourceID} {
+		repo, err := p.store.GetRepositoryByID(id)
+		if err != nil {
+			continue
+		}
+		owner, err := p.store.GetUserByID(repo.OwnerID)
+		if err != nil {
+			continue
+		}
+		response[key] = repositoryToResponse(repo, owner)
+	}
+}
+
 // repositoryToResponse converts Repository to GitHub API response format
 func repositoryToResponse(repo *Repository, owner *User) map[string]interface{} {
+	topics := repo.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+
 	response := map[string]interface{}{
 		"id":                repo.ID,
 		"name":              repo.Name,
@@ -137,6 +262,8 @@ func repositoryToResponse(repo *Repository, owner *User) map[string]interface{}
 		"watchers_count":    repo.WatchersCount,
 		"forks_count":       repo.ForksCount,
 		"open_issues_count": repo.OpenIssuesCount,
+		"language":          nilIfEmpty(repo.Language),
+		"topics":            topics,
 		"created_at":        repo.CreatedAt.Format(time.RFC3339),
 		"updated_at":        repo.UpdatedAt.Format(time.RFC3339),
 		"owner": map[string]interface{}{
@@ -174,7 +301,7 @@ func (p *GitHubPlugin) createIssue(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.Title == "" {
-		writeError(w, http.StatusBadRequest, "title is required")
+		writeValidationError(w, "Issue", "title", "missing_field")
 		return
 	}
 
@@ -191,10 +318,24 @@ func (p *GitHubPlugin) createIssue(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to create issue")
 		return
 	}
+	if err := p.recordIssueReferences(issue); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to record issue references")
+		return
+	}
+
+	response := p.fireIssueOpened(issue, user, repo)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
 
+// fireIssueOpened sends the issues "opened" webhook for a new issue and
+// returns the issue's REST representation
+func (p *GitHubPlugin) fireIssueOpened(issue *Issue, user *User, repo *Repository) map[string]interface{} {
 	response := issueToResponse(issue, user, repo)
+	p.addIssueReactions(response, issue, repo)
 
-	// Fire webhooks for issues event
 	webhookPayload := map[string]interface{}{
 		"action": "opened",
 		"issue":  response,
@@ -204,11 +345,8 @@ func (p *GitHubPlugin) createIssue(w http.ResponseWriter, r *http.Request) {
 			"full_name": repo.FullName,
 		},
 	}
-	go p.fireWebhooksForEvent(repo.ID, "issues", webhookPayload)
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	p.fireWebhooksAsync(repo.ID, "issues", webhookPayload)
+	return response
 }
 
 // listIssues handles GET /repos/{owner}/{repo}/issues
@@ -235,7 +373,9 @@ func (p *GitHubPlugin) listIssues(w http.ResponseWriter, r *http.Request) {
 	var response []map[string]interface{}
 	for _, issue := range issues {
 		issueUser, _ := p.store.GetUserByID(issue.UserID)
-		response = append(response, issueToResponse(issue, issueUser, repo))
+		issueResponse := issueToResponse(issue, issueUser, repo)
+		p.addIssueReactions(issueResponse, issue, repo)
+		response = append(response, issueResponse)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -271,6 +411,7 @@ func (p *GitHubPlugin) getIssue(w http.ResponseWriter, r *http.Request) {
 
 	issueUser, _ := p.store.GetUserByID(issue.UserID)
 	response := issueToResponse(issue, issueUser, repo)
+	p.addIssueReactions(response, issue, repo)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -325,7 +466,7 @@ func (p *GitHubPlugin) updateIssue(w http.ResponseWriter, r *http.Request) {
 	if req.State != nil {
 		issue.State = *req.State
 		if *req.State == "closed" && issue.ClosedAt == nil {
-			now := time.Now()
+			now := p.store.now()
 			issue.ClosedAt = &now
 		} else if *req.State == "open" {
 			issue.ClosedAt = nil
@@ -339,9 +480,16 @@ func (p *GitHubPlugin) updateIssue(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to update issue")
 		return
 	}
+	if req.Body != nil {
+		if err := p.recordIssueReferences(issue); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to record issue references")
+			return
+		}
+	}
 
 	issueUser, _ := p.store.GetUserByID(issue.UserID)
 	response := issueToResponse(issue, issueUser, repo)
+	p.addIssueReactions(response, issue, repo)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -381,6 +529,11 @@ func issueToResponse(issue *Issue, user *User, repo *Repository) map[string]inte
 		response["state_reason"] = issue.StateReason
 	}
 
+	response["active_lock_reason"] = nil
+	if issue.LockReason != "" {
+		response["active_lock_reason"] = issue.LockReason
+	}
+
 	if issue.ClosedAt != nil {
 		response["closed_at"] = issue.ClosedAt.Format(time.RFC3339)
 	}
@@ -410,16 +563,14 @@ func (p *GitHubPlugin) createPullRequest(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if req.Title == "" {
-		writeError(w, http.StatusBadRequest, "title is required")
-		return
-	}
-	if req.Head == "" {
-		writeError(w, http.StatusBadRequest, "head is required")
-		return
+	var missing []fieldError
+	for _, field := range []struct{ name, value string }{{"title", req.Title}, {"head", req.Head}, {"base", req.Base}} {
+		if field.value == "" {
+			missing = append(missing, fieldError{Resource: "PullRequest", Field: field.name, Code: "missing_field"})
+		}
 	}
-	if req.Base == "" {
-		writeError(w, http.StatusBadRequest, "base is required")
+	if len(missing) > 0 {
+		writeValidationErrors(w, missing)
 		return
 	}
 
@@ -449,7 +600,7 @@ func (p *GitHubPlugin) createPullRequest(w http.ResponseWriter, r *http.Request)
 			"full_name": repo.FullName,
 		},
 	}
-	go p.fireWebhooksForEvent(repo.ID, "pull_request", webhookPayload)
+	p.fireWebhooksAsync(repo.ID, "pull_request", webhookPayload)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -561,12 +712,38 @@ func (p *GitHubPlugin) mergePullRequest(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusMethodNotAllowed, "pull request already merged")
 		return
 	}
+	if !pr.Mergeable {
+		writeError(w, http.StatusMethodNotAllowed, "Pull Request is not mergeable")
+		return
+	}
+	reason, err := p.mergeBlockedReason(issue, pr)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to check branch protection")
+		return
+	}
+	if reason != "" {
+		writeError(w, http.StatusMethodNotAllowed, reason)
+		return
+	}
 
 	if err := p.store.MergePullRequest(issue.ID, user.ID); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to merge pull request")
 		return
 	}
 
+	// Like GitHub, closing keywords only take effect when merging into the
+	// default branch. The pull request cross-references every issue it mentions.
+	if err := p.recordIssueReferences(issue); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to record issue references")
+		return
+	}
+	if pr.BaseRepoID == repo.ID && pr.BaseRef == repo.DefaultBranch {
+		if err := p.closeReferencedIssues(repo, issue.Body, user.ID, "", issue.ID); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to close referenced issues")
+			return
+		}
+	}
+
 	// Reload PR to get updated data
 	issue, pr, _ = p.store.GetPullRequest(repo.ID, prNum)
 	issueUser, _ := p.store.GetUserByID(issue.UserID)
@@ -576,6 +753,76 @@ func (p *GitHubPlugin) mergePullRequest(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
+// updatePullRequestBranch handles PUT /repos/{owner}/{repo}/pulls/{number}/update-branch
+func (p *GitHubPlugin) updatePullRequestBranch(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+	owner := chi.URLParam(r, "owner")
+	repoName := chi.URLParam(r, "repo")
+	number := chi.URLParam(r, "number")
+
+	// The body is optional
+	var req struct {
+		ExpectedHeadSHA string `json:"expected_head_sha"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	// Get repository
+	fullName := owner + "/" + repoName
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	// Parse number
+	var prNum int
+	if _, err := fmt.Sscanf(number, "%d", &prNum); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid pull request number")
+		return
+	}
+
+	_, pr, err := p.store.GetPullRequest(repo.ID, prNum)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "pull request not found")
+		return
+	}
+
+	if pr.Merged {
+		writeError(w, http.StatusUnprocessableEntity, "pull request already merged")
+		return
+	}
+	if req.ExpectedHeadSHA != "" {
+		headSHA, err := p.store.ResolveRef(pr.HeadRepoID, pr.HeadRef)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to resolve head ref")
+			return
+		}
+		if headSHA != req.ExpectedHeadSHA {
+			writeError(w, http.StatusUnprocessableEntity, "expected head sha didn't match current head ref.")
+			return
+		}
+	}
+
+	if err := p.store.UpdatePullRequestBranch(pr, user.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update pull request branch")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Updating pull request branch.",
+		"url":     fmt.Sprintf("https://github.com/%s/pull/%d", repo.FullName, prNum),
+	})
+}
+
 // pullRequestToResponse converts Issue + PullRequest to GitHub API response format
 func pullRequestToResponse(issue *Issue, pr *PullRequest, user *User, repo *Repository) map[string]interface{} {
 	response := map[string]interface{}{
@@ -622,6 +869,7 @@ func pullRequestToResponse(issue *Issue, pr *PullRequest, user *User, repo *Repo
 	}
 	response["merged"] = pr.Merged
 	response["mergeable"] = pr.Mergeable
+	response["mergeable_state"] = pr.MergeableState
 	response["rebaseable"] = pr.Rebaseable
 	response["draft"] = pr.Draft
 
@@ -671,7 +919,7 @@ func (p *GitHubPlugin) createComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.Body == "" {
-		writeError(w, http.StatusBadRequest, "body is required")
+		writeValidationError(w, "IssueComment", "body", "missing_field")
 		return
 	}
 
@@ -696,6 +944,10 @@ func (p *GitHubPlugin) createComment(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "issue not found")
 		return
 	}
+	if issue.Locked {
+		writeError(w, http.StatusForbidden, "Unable to create comment because issue is locked.")
+		return
+	}
 
 	// Create comment
 	comment, err := p.store.CreateComment(issue.ID, user.ID, req.Body)
@@ -705,6 +957,7 @@ func (p *GitHubPlugin) createComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := commentToResponse(comment, user)
+	p.addCommentReactions(response, comment, repo.FullName)
 
 	// Fire webhooks for issue_comment event
 	issueResponse := issueToResponse(issue, user, repo)
@@ -718,7 +971,7 @@ func (p *GitHubPlugin) createComment(w http.ResponseWriter, r *http.Request) {
 			"full_name": repo.FullName,
 		},
 	}
-	go p.fireWebhooksForEvent(repo.ID, "issue_comment", webhookPayload)
+	p.fireWebhooksAsync(repo.ID, "issue_comment", webhookPayload)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -763,7 +1016,9 @@ func (p *GitHubPlugin) listComments(w http.ResponseWriter, r *http.Request) {
 	var response []map[string]interface{}
 	for _, comment := range comments {
 		commentUser, _ := p.store.GetUserByID(comment.UserID)
-		response = append(response, commentToResponse(comment, commentUser))
+		commentResponse := commentToResponse(comment, commentUser)
+		p.addCommentReactions(commentResponse, comment, repo.FullName)
+		response = append(response, commentResponse)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -812,6 +1067,7 @@ func (p *GitHubPlugin) updateComment(w http.ResponseWriter, r *http.Request) {
 	// Get user for response
 	commentUser, _ := p.store.GetUserByID(comment.UserID)
 	response := commentToResponse(comment, commentUser)
+	p.addCommentReactions(response, comment, chi.URLParam(r, "owner")+"/"+chi.URLParam(r, "repo"))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -893,7 +1149,7 @@ func (p *GitHubPlugin) createReview(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.State == "" {
-		writeError(w, http.StatusBadRequest, "state is required")
+		writeValidationError(w, "PullRequestReview", "state", "missing_field")
 		return
 	}
 
@@ -905,7 +1161,7 @@ func (p *GitHubPlugin) createReview(w http.ResponseWriter, r *http.Request) {
 		"CHANGES_REQUESTED":  true,
 	}
 	if !validStates[req.State] {
-		writeError(w, http.StatusBadRequest, "invalid state")
+		writeValidationError(w, "PullRequestReview", "state", "invalid")
 		return
 	}
 
@@ -992,6 +1248,11 @@ func (p *GitHubPlugin) listReviews(w http.ResponseWriter, r *http.Request) {
 
 // submitReview handles PUT /repos/{owner}/{repo}/pulls/{number}/reviews/{id}
 func (p *GitHubPlugin) submitReview(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
 	owner := chi.URLParam(r, "owner")
 	repoName := chi.URLParam(r, "repo")
 	number := chi.URLParam(r, "number")
@@ -1034,7 +1295,7 @@ func (p *GitHubPlugin) submitReview(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Submit the review
-	if err := p.store.SubmitReview(id); err != nil {
+	if err := p.store.SubmitReview(id, user.ID); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to submit review")
 		return
 	}
@@ -1050,6 +1311,11 @@ func (p *GitHubPlugin) submitReview(w http.ResponseWriter, r *http.Request) {
 
 // dismissReview handles DELETE /repos/{owner}/{repo}/pulls/{number}/reviews/{id}
 func (p *GitHubPlugin) dismissReview(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
 	owner := chi.URLParam(r, "owner")
 	repoName := chi.URLParam(r, "repo")
 	number := chi.URLParam(r, "number")
@@ -1092,7 +1358,7 @@ func (p *GitHubPlugin) dismissReview(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Dismiss the review
-	if err := p.store.DismissReview(id); err != nil {
+	if err := p.store.DismissReview(id, user.ID); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to dismiss review")
 		return
 	}
@@ -1141,7 +1407,20 @@ func reviewToResponse(review *Review, user *User) map[string]interface{} {
 	return response
 }
 
-// fireWebhooksForEvent finds active webhooks for an event and fires them
+// fireWebhooksAsync delivers an event's webhooks in the background, tracked so
+// Shutdown can wait for deliveries in flight
+func (p *GitHubPlugin) fireWebhooksAsync(repoID int64, eventType string, payload interface{}) {
+	if p.dryRun {
+		return
+	}
+	p.deliveries.Add(1)
+	go func() {
+		defer p.deliveries.Done()
+		p.fireWebhooksForEvent(repoID, eventType, payload)
+	}()
+}
+
+// fireWebhooksForEvent finds active webhooks for an event and queues a delivery to each
 // Includes panic recovery to prevent goroutine crashes from affecting the server
 func (p *GitHubPlugin) fireWebhooksForEvent(repoID int64, eventType string, payload interface{}) {
 	// Add panic recovery to prevent goroutine crashes
@@ -1159,20 +1438,15 @@ func (p *GitHubPlugin) fireWebhooksForEvent(repoID int64, eventType string, payl
 		return
 	}
 
-	// Fire webhooks synchronously for now
+	// Queue a delivery per webhook for the worker to send, held back by the
+	// webhook's delay
+	payloadBytes, _ := json.Marshal(payload)
+	now := p.store.now()
 	for _, webhook := range webhooks {
-		payloadBytes, _ := json.Marshal(payload)
-		err := fireWebhook(webhook, eventType, payload)
-
-		statusCode := 200
-		errorMsg := ""
-		if err != nil {
-			statusCode = 500
-			errorMsg = err.Error()
+		dueAt := now.Add(time.Duration(webhook.DelayMs) * time.Millisecond)
+		if _, err := p.store.QueueWebhookDelivery(webhook.ID, eventType, string(payloadBytes), dueAt, false); err != nil {
+			log.Printf("GitHub: failed to queue %s delivery for webhook %d: %v", eventType, webhook.ID, err)
 		}
-
-		// Log delivery
-		p.store.CreateWebhookDelivery(webhook.ID, eventType, string(payloadBytes), statusCode, errorMsg)
 	}
 }
 
@@ -1186,6 +1460,7 @@ func (p *GitHubPlugin) createWebhook(w http.ResponseWriter, r *http.Request) {
 			URL         string `json:"url"`
 			ContentType string `json:"content_type"`
 			Secret      string `json:"secret"`
+			DelayMs     int    `json:"delay_ms"`
 		} `json:"config"`
 		Events []string `json:"events"`
 		Active *bool    `json:"active"`
@@ -1197,7 +1472,11 @@ func (p *GitHubPlugin) createWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.Config.URL == "" {
-		writeError(w, http.StatusBadRequest, "url is required")
+		writeValidationError(w, "Hook", "url", "missing_field")
+		return
+	}
+	if req.Config.DelayMs < 0 {
+		writeValidationError(w, "Hook", "delay_ms", "invalid")
 		return
 	}
 
@@ -1216,7 +1495,7 @@ func (p *GitHubPlugin) createWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create webhook
-	webhook, err := p.store.CreateWebhook(repo.ID, req.Config.URL, contentType, req.Config.Secret, req.Events)
+	webhook, err := p.store.CreateWebhook(repo.ID, req.Config.URL, contentType, req.Config.Secret, req.Events, req.Config.DelayMs)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -1466,20 +1745,16 @@ func (p *GitHubPlugin) testWebhook(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	// The ping is queued like any delivery but sent right away. It is due
+	// only after this attempt's timeout, so the worker can't send it too, and
+	// retries it if it fails.
 	payloadBytes, _ := json.Marshal(payload)
-	err = fireWebhook(webhook, "ping", payload)
-
-	statusCode := 200
-	errorMsg := ""
+	delivery, err := p.store.QueueWebhookDelivery(webhook.ID, "ping", string(payloadBytes), p.store.now().Add(deliveryAttemptTimeout), false)
 	if err != nil {
-		statusCode = 500
-		errorMsg = err.Error()
+		writeError(w, http.StatusInternalServerError, "failed to queue delivery")
+		return
 	}
-
-	// Log delivery
-	p.store.CreateWebhookDelivery(webhook.ID, "ping", string(payloadBytes), statusCode, errorMsg)
-
-	if err != nil {
+	if err := p.attemptDelivery(r.Context(), delivery); err != nil {
 		writeError(w, http.StatusInternalServerError, "webhook delivery failed: "+err.Error())
 		return
 	}
@@ -1502,6 +1777,7 @@ func webhookToResponse(webhook *Webhook) map[string]interface{} {
 		"config": map[string]interface{}{
 			"url":          webhook.URL,
 			"content_type": webhook.ContentType,
+			"delay_ms":     webhook.DelayMs,
 		},
 		"created_at": webhook.CreatedAt.Format(time.RFC3339),
 		"updated_at": webhook.UpdatedAt.Format(time.RFC3339),
@@ -159,6 +159,165 @@ func TestGetRepository(t *testing.T) {
 	}
 }
 
+func TestForkRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+	// Wait for webhook deliveries before the database closes
+	defer plugin.deliveries.Wait()
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.GetOrCreateUser("bob", "ghp_bob")
+	source, _ := store.CreateRepository(alice.ID, "my-repo", "Test repo", false)
+	store.ReplaceRepoTopics(source.ID, []string{"go", "testing"}, alice.ID)
+
+	fork := func(token, owner, repo string) map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/repos/"+owner+"/"+repo+"/forks", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("owner", owner)
+		rctx.URLParams.Add("repo", repo)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+
+		plugin.requireAuth(plugin.forkRepository)(w, req)
+
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	resp := fork("ghp_bob", "alice", "my-repo")
+	if resp["full_name"] != "bob/my-repo" {
+		t.Fatalf("Expected full_name 'bob/my-repo', got %v", resp["full_name"])
+	}
+	if resp["fork"] != true {
+		t.Fatalf("Expected fork=true, got %v", resp["fork"])
+	}
+	if resp["default_branch"] != "main" {
+		t.Fatalf("Expected default branch copied, got %v", resp["default_branch"])
+	}
+	if fmt.Sprint(resp["topics"]) != "[go testing]" {
+		t.Fatalf("Expected topics copied, got %v", resp["topics"])
+	}
+	parent, _ := resp["parent"].(map[string]interface{})
+	if parent["full_name"] != "alice/my-repo" {
+		t.Fatalf("Expected parent 'alice/my-repo', got %v", resp["parent"])
+	}
+
+	updated, _ := store.GetRepositoryByID(source.ID)
+	if updated.ForksCount != 1 {
+		t.Fatalf("Expected source forks_count 1, got %d", updated.ForksCount)
+	}
+	var forkOf int64
+	if err := db.QueryRow(`SELECT fork_of FROM github_repositories WHERE full_name = 'bob/my-repo'`).Scan(&forkOf); err != nil || forkOf != source.ID {
+		t.Fatalf("Expected fork_of %d, got %d (%v)", source.ID, forkOf, err)
+	}
+
+	// Forking your own repository gets a suffixed name
+	resp = fork("ghp_alice", "alice", "my-repo")
+	if resp["full_name"] != "alice/my-repo-1" {
+		t.Fatalf("Expected full_name 'alice/my-repo-1', got %v", resp["full_name"])
+	}
+
+	// Forking a fork keeps the original as source
+	resp = fork("ghp_alice", "bob", "my-repo")
+	parent, _ = resp["parent"].(map[string]interface{})
+	sourceResp, _ := resp["source"].(map[string]interface{})
+	if parent["full_name"] != "bob/my-repo" || sourceResp["full_name"] != "alice/my-repo" {
+		t.Fatalf("Expected parent bob/my-repo and source alice/my-repo, got %v / %v", parent["full_name"], sourceResp["full_name"])
+	}
+
+	updated, _ = store.GetRepositoryByID(source.ID)
+	if updated.ForksCount != 2 {
+		t.Fatalf("Expected source forks_count 2, got %d", updated.ForksCount)
+	}
+}
+
+func TestListForks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	bob, _ := store.GetOrCreateUser("bob", "ghp_bob")
+	carol, _ := store.GetOrCreateUser("carol", "ghp_carol")
+	source, _ := store.CreateRepository(alice.ID, "my-repo", "Test repo", false)
+	store.ReplaceRepoTopics(source.ID, []string{"go", "testing"}, alice.ID)
+
+	bobFork, _ := store.ForkRepository(bob.ID, source)
+	store.ForkRepository(carol.ID, source)
+	// A fork of a fork is listed under its parent, not the source
+	store.ForkRepositoryNamed(carol.ID, bobFork, "bobs-fork")
+
+	list := func(owner, repo, query string) (int, []map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/repos/"+owner+"/"+repo+"/forks"+query, nil)
+		req.Header.Set("Authorization", "Bearer ghp_alice")
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("owner", owner)
+		rctx.URLParams.Add("repo", repo)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		w := httptest.NewRecorder()
+
+		plugin.requireAuth(plugin.listForks)(w, req)
+
+		var resp []map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, forks := list("alice", "my-repo", "?sort=oldest")
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if len(forks) != 2 {
+		t.Fatalf("Expected 2 direct forks, got %d", len(forks))
+	}
+	if forks[0]["full_name"] != "bob/my-repo" || forks[1]["full_name"] != "carol/my-repo" {
+		t.Fatalf("Expected bob's then carol's fork, got %v and %v", forks[0]["full_name"], forks[1]["full_name"])
+	}
+	for _, fork := range forks {
+		if fork["fork"] != true {
+			t.Errorf("Expected fork=true for %v", fork["full_name"])
+		}
+		// Listed forks match the repository as GET /repos/{owner}/{repo} loads it
+		single, _ := store.GetRepositoryByFullName(fork["full_name"].(string))
+		if fmt.Sprint(fork["topics"]) != fmt.Sprint(single.Topics) || len(single.Topics) != 2 {
+			t.Errorf("Expected topics %v for %v, got %v", single.Topics, fork["full_name"], fork["topics"])
+		}
+	}
+
+	updated, _ := store.GetRepositoryByID(source.ID)
+	if updated.ForksCount != len(forks) {
+		t.Errorf("Expected forks_count %d to match listed forks, got %d", len(forks), updated.ForksCount)
+	}
+
+	_, forks = list("bob", "my-repo", "")
+	if len(forks) != 1 || forks[0]["full_name"] != "carol/bobs-fork" {
+		t.Fatalf("Expected carol/bobs-fork under bob/my-repo, got %v", forks)
+	}
+
+	// Repositories without forks return an empty array
+	_, forks = list("carol", "bobs-fork", "")
+	if forks == nil || len(forks) != 0 {
+		t.Fatalf("Expected empty array, got %v", forks)
+	}
+
+	if code, _ := list("alice", "my-repo", "?sort=size"); code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for invalid sort, got %d", code)
+	}
+	if code, _ := list("alice", "missing", ""); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing repository, got %d", code)
+	}
+}
+
 func TestCreateIssue(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -211,6 +370,51 @@ func TestCreateIssue(t *testing.T) {
 	}
 }
 
+func TestCreateIssueWithoutTitle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	user, _ := store.GetOrCreateUser("alice", "ghp_test")
+	repo, _ := store.CreateRepository(user.ID, "test-repo", "", false)
+
+	req := httptest.NewRequest("POST", "/repos/alice/test-repo/issues", bytes.NewBufferString(`{"body": "No title here"}`))
+	req.Header.Set("Authorization", "Bearer ghp_test")
+	w := httptest.NewRecorder()
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("owner", "alice")
+	rctx.URLParams.Add("repo", "test-repo")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	plugin.requireAuth(plugin.createIssue)(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Message          string       `json:"message"`
+		DocumentationURL string       `json:"documentation_url"`
+		Status           string       `json:"status"`
+		Errors           []fieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Message != "Validation Failed" || resp.DocumentationURL == "" || resp.Status != "422" {
+		t.Errorf("Unexpected error body: %s", w.Body.String())
+	}
+	want := fieldError{Resource: "Issue", Field: "title", Code: "missing_field"}
+	if len(resp.Errors) != 1 || resp.Errors[0] != want {
+		t.Errorf("Expected errors [%+v], got %+v", want, resp.Errors)
+	}
+
+	if issues, _ := store.ListIssues(repo.ID, "all", true); len(issues) != 0 {
+		t.Errorf("Expected no issue to be created, got %d", len(issues))
+	}
+}
+
 func TestListIssues(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -523,6 +727,74 @@ func TestMergePullRequest(t *testing.T) {
 	}
 }
 
+func TestPullRequestMergeabilityFollowsBaseBranch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	user, _ := store.GetOrCreateUser("alice", "ghp_test")
+	repo, _ := store.CreateRepository(user.ID, "test-repo", "", false)
+	db.Exec(`INSERT INTO github_branches (repo_id, name, commit_sha) VALUES (?, 'main', ?)`, repo.ID, testSHA)
+	store.CreatePullRequest(repo.ID, user.ID, "Test PR", "Body", "feature", "main")
+
+	params := map[string]string{"owner": "alice", "repo": "test-repo", "number": "1"}
+	getPR := func() map[string]interface{} {
+		w := authedRequest(plugin, plugin.getPullRequest, "GET", "ghp_test", "", params)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	if resp := getPR(); resp["mergeable"] != true || resp["mergeable_state"] != "clean" {
+		t.Fatalf("Expected a clean, mergeable PR, got mergeable=%v state=%v", resp["mergeable"], resp["mergeable_state"])
+	}
+
+	// Advance the base branch past the PR's base
+	db.Exec(`UPDATE github_branches SET commit_sha = ? WHERE repo_id = ? AND name = 'main'`,
+		"7fd1a60b01f91b314f59955a4e4d4e80d8edf11d", repo.ID)
+
+	if resp := getPR(); resp["mergeable"] != false || resp["mergeable_state"] != "dirty" {
+		t.Fatalf("Expected a dirty PR after the base advanced, got mergeable=%v state=%v", resp["mergeable"], resp["mergeable_state"])
+	}
+	w := authedRequest(plugin, plugin.mergePullRequest, "PUT", "ghp_test", "{}", params)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected 405 merging a dirty PR, got %d", w.Code)
+	}
+
+	w = authedRequest(plugin, plugin.updatePullRequestBranch, "PUT", "ghp_test", "", params)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if resp := getPR(); resp["mergeable"] != true || resp["mergeable_state"] != "clean" {
+		t.Fatalf("Expected a clean PR after update-branch, got mergeable=%v state=%v", resp["mergeable"], resp["mergeable_state"])
+	}
+}
+
+func TestPullRequestBlockedByFailingStatus(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+
+	user, _ := store.GetOrCreateUser("alice", "ghp_test")
+	repo, _ := store.CreateRepository(user.ID, "test-repo", "", false)
+	db.Exec(`INSERT INTO github_branches (repo_id, name, commit_sha) VALUES (?, 'feature', ?)`, repo.ID, testSHA)
+	store.CreatePullRequest(repo.ID, user.ID, "Test PR", "Body", "feature", "main")
+	store.CreateCommitStatus(repo.ID, testSHA, "failure", "ci", "", "", user.ID)
+
+	_, pr, err := store.GetPullRequest(repo.ID, 1)
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v", err)
+	}
+	if !pr.Mergeable || pr.MergeableState != "blocked" {
+		t.Fatalf("Expected a mergeable but blocked PR, got mergeable=%v state=%q", pr.Mergeable, pr.MergeableState)
+	}
+}
+
 func TestCreateComment(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -1096,7 +1368,7 @@ func TestListWebhooks(t *testing.T) {
 	// Create user, repo, and webhook
 	user, _ := store.GetOrCreateUser("alice", "ghp_test")
 	repo, _ := store.CreateRepository(user.ID, "test-repo", "", false)
-	store.CreateWebhook(repo.ID, "https://example.com/webhook", "json", "secret", []string{"issues"})
+	store.CreateWebhook(repo.ID, "https://example.com/webhook", "json", "secret", []string{"issues"}, 0)
 
 	req := httptest.NewRequest("GET", "/repos/alice/test-repo/hooks", nil)
 	req.Header.Set("Authorization", "Bearer ghp_test")
@@ -1132,7 +1404,7 @@ func TestDeleteWebhook(t *testing.T) {
 	// Create user, repo, and webhook
 	user, _ := store.GetOrCreateUser("alice", "ghp_test")
 	repo, _ := store.CreateRepository(user.ID, "test-repo", "", false)
-	webhook, _ := store.CreateWebhook(repo.ID, "https://example.com/webhook", "json", "secret", []string{"issues"})
+	webhook, _ := store.CreateWebhook(repo.ID, "https://example.com/webhook", "json", "secret", []string{"issues"}, 0)
 
 	req := httptest.NewRequest("DELETE", "/repos/alice/test-repo/hooks/1", nil)
 	req.Header.Set("Authorization", "Bearer ghp_test")
@@ -0,0 +1,70 @@
+// ABOUTME: core.Inspector implementation for the GitHub plugin
+// ABOUTME: Looks up records by ID and renders them as the REST API does
+
+package github
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *GitHubPlugin) Inspect(resource, id string) (interface{}, error) {
+	if resource != "users" && resource != "repositories" && resource != "issues" && resource != "pull_requests" && resource != "webhooks" {
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+	recordID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s ID: %s", resource, id)
+	}
+
+	switch resource {
+	case "users":
+		user, err := p.store.GetUserByID(recordID)
+		if err != nil {
+			return nil, err
+		}
+		return userToResponse(user), nil
+	case "repositories":
+		repo, err := p.store.GetRepositoryByID(recordID)
+		if err != nil {
+			return nil, err
+		}
+		owner, err := p.store.GetUserByID(repo.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		response := repositoryToResponse(repo, owner)
+		if repo.Fork {
+			p.addForkLineage(response, repo.ID)
+		}
+		return response, nil
+	case "webhooks":
+		webhook, err := p.store.GetWebhook(recordID)
+		if err != nil {
+			return nil, err
+		}
+		return webhookToResponse(webhook), nil
+	}
+
+	issue, err := p.store.GetIssueByID(recordID)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := p.store.GetRepositoryByID(issue.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	user, _ := p.store.GetUserByID(issue.UserID)
+
+	if resource == "pull_requests" {
+		issue, pr, err := p.store.GetPullRequest(repo.ID, int(issue.Number))
+		if err != nil {
+			return nil, err
+		}
+		return pullRequestToResponse(issue, pr, user, repo), nil
+	}
+	response := issueToResponse(issue, user, repo)
+	p.addIssueReactions(response, issue, repo)
+	return response, nil
+}
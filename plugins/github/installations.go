@@ -0,0 +1,159 @@
+// ABOUTME: GitHub App installation endpoints
+// ABOUTME: Lists installations and mints short-lived installation tokens that act as the app's bot user
+
+package github
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultInstallationTokenTTL is how long installation tokens last, unless
+// the installation_token_ttl setting says otherwise
+const defaultInstallationTokenTTL = time.Hour
+
+// permissionLevels ranks the access levels a permission can grant
+var permissionLevels = map[string]int{"read": 1, "write": 2, "admin": 3}
+
+// tokenTTL is how long newly minted installation tokens last
+func (p *GitHubPlugin) tokenTTL() time.Duration {
+	if p.installationTokenTTL != nil {
+		return *p.installationTokenTTL
+	}
+	return defaultInstallationTokenTTL
+}
+
+// requireAppJWT accepts requests authenticated as the app itself. Apps sign
+// a JWT with their private key; any well-formed JWT is accepted here.
+func (p *GitHubPlugin) requireAppJWT(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := extractToken(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "requires authentication")
+			return
+		}
+		if parts := strings.Split(token, "."); len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			writeError(w, http.StatusUnauthorized, "A JSON web token could not be decoded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// installationToResponse formats an installation as GET /app/installations returns it
+func installationToResponse(inst *Installation, account *User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                   inst.ID,
+		"account":              simpleUser(account),
+		"repository_selection": inst.RepositorySelection,
+		"access_tokens_url":    fmt.Sprintf("/app/installations/%d/access_tokens", inst.ID),
+		"repositories_url":     "/installation/repositories",
+		"html_url":             fmt.Sprintf("https://github.com/settings/installations/%d", inst.ID),
+		"app_id":               appID,
+		"app_slug":             appSlug,
+		"target_id":            account.ID,
+		"target_type":          account.Type,
+		"permissions":          inst.Permissions,
+		"events":               []string{},
+		"created_at":           inst.CreatedAt.Format(time.RFC3339),
+		"updated_at":           inst.UpdatedAt.Format(time.RFC3339),
+		"single_file_name":     nil,
+		"suspended_at":         nil,
+	}
+}
+
+// simpleUser is the abbreviated user object nested in other resources
+func simpleUser(user *User) map[string]interface{} {
+	return map[string]interface{}{
+		"login":      user.Login,
+		"id":         user.ID,
+		"type":       user.Type,
+		"avatar_url": user.AvatarURL,
+		"url":        "/users/" + user.Login,
+	}
+}
+
+// listInstallations handles GET /app/installations
+func (p *GitHubPlugin) listInstallations(w http.ResponseWriter, r *http.Request) {
+	installations, err := p.store.ListInstallations()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list installations")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(installations))
+	for i := range installations {
+		account, err := p.store.GetUserByID(installations[i].AccountID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get installation account")
+			return
+		}
+		response = append(response, installationToResponse(&installations[i], account))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// createInstallationToken handles POST /app/installations/{installation_id}/access_tokens.
+// The token gets the installation's permissions, or the subset the request names.
+func (p *GitHubPlugin) createInstallationToken(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "installation_id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	inst, err := p.store.GetInstallation(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get installation")
+		return
+	}
+
+	var req struct {
+		Permissions map[string]string `json:"permissions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	permissions := inst.Permissions
+	if len(req.Permissions) > 0 {
+		for name, level := range req.Permissions {
+			granted, ok := inst.Permissions[name]
+			if permissionLevels[level] == 0 || !ok || permissionLevels[level] > permissionLevels[granted] {
+				writeError(w, http.StatusUnprocessableEntity, "The permissions requested are not granted to this installation.")
+				return
+			}
+		}
+		permissions = req.Permissions
+	}
+
+	token, expiresAt, err := p.store.CreateInstallationToken(inst, permissions, p.tokenTTL())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create installation token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":                token,
+		"expires_at":           expiresAt.UTC().Format(time.RFC3339),
+		"permissions":          permissions,
+		"repository_selection": inst.RepositorySelection,
+	})
+}
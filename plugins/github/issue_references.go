@@ -0,0 +1,182 @@
+// ABOUTME: Issue cross-references for the GitHub plugin
+// ABOUTME: Records #number mentions in issue bodies and serves them on the timeline and as dependencies
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// issueMentionPattern matches #123 mentions of an issue in the same
+// repository, but not owner/repo#123, URL fragments, or HTML entities
+var issueMentionPattern = regexp.MustCompile(`(?:^|[^\w/#&])#(\d+)\b`)
+
+// mentionedIssueNumbers returns the issue numbers a body mentions, in order
+// of first mention
+func mentionedIssueNumbers(body string) []int {
+	var numbers []int
+	for _, match := range issueMentionPattern.FindAllStringSubmatch(body, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || slices.Contains(numbers, n) {
+			continue
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// closingKeywordPattern matches a closing keyword followed by a #123
+// mention of an issue in the same repository, such as "Fixes #123"
+var closingKeywordPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+#(\d+)\b`)
+
+// closedIssueNumbers returns the issue numbers text closes with a closing
+// keyword, in order of first mention
+func closedIssueNumbers(text string) []int {
+	var numbers []int
+	for _, match := range closingKeywordPattern.FindAllStringSubmatch(text, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || slices.Contains(numbers, n) {
+			continue
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// closeReferencedIssues closes the open issues in repo that text names with a
+// closing keyword, crediting the commit or merged pull request (sourceIssueID)
+// it came from. Pull requests and issues in other repositories are never closed.
+func (p *GitHubPlugin) closeReferencedIssues(repo *Repository, text string, actorID int64, commitID string, sourceIssueID int64) error {
+	for _, number := range closedIssueNumbers(text) {
+		issue, err := p.store.GetIssueByNumber(repo.ID, number)
+		if err != nil || issue.IsPullRequest || issue.State == "closed" {
+			continue
+		}
+		if err := p.store.CloseIssueByReference(issue, actorID, commitID, sourceIssueID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordIssueReferences records a reference from issue to every other issue
+// in its repository that its body mentions. Mentions of issues that don't
+// exist are ignored, and references are never removed by later edits.
+func (p *GitHubPlugin) recordIssueReferences(issue *Issue) error {
+	var targets []int64
+	for _, number := range mentionedIssueNumbers(issue.Body) {
+		if int64(number) == issue.Number {
+			continue
+		}
+		target, err := p.store.GetIssueByNumber(issue.RepoID, number)
+		if err != nil {
+			continue
+		}
+		targets = append(targets, target.ID)
+	}
+	return p.store.AddIssueReferences(issue.ID, targets)
+}
+
+// listIssueTimeline handles GET /repos/{owner}/{repo}/issues/{number}/timeline,
+// the issue's events plus a cross-referenced event for each issue that mentions it
+func (p *GitHubPlugin) listIssueTimeline(w http.ResponseWriter, r *http.Request) {
+	repo, issue, ok := p.issueForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := p.store.ListIssueEvents(issue.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list events")
+		return
+	}
+	refs, err := p.store.ListIssueReferencesTo(issue.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list references")
+		return
+	}
+
+	type timelineEntry struct {
+		at       time.Time
+		response map[string]interface{}
+	}
+	entries := make([]timelineEntry, 0, len(events)+len(refs))
+	// References go first so that a reference and the close it caused stay
+	// in order when they share a timestamp
+	for _, ref := range refs {
+		source, err := p.store.GetIssueByID(ref.SourceIssueID)
+		if err != nil {
+			continue
+		}
+		author, _ := p.store.GetUserByID(source.UserID)
+		entries = append(entries, timelineEntry{ref.CreatedAt, crossReferenceToResponse(ref, source, author, repo)})
+	}
+	for _, event := range events {
+		entries = append(entries, timelineEntry{event.CreatedAt, p.issueEventResponse(event, repo)})
+	}
+	slices.SortStableFunc(entries, func(a, b timelineEntry) int {
+		return a.at.Compare(b.at)
+	})
+
+	response := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		response[i] = entry.response
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// crossReferenceToResponse converts an IssueReference to a cross-referenced
+// timeline event on its target issue. The actor is the mentioning issue's author.
+func crossReferenceToResponse(ref *IssueReference, source *Issue, author *User, repo *Repository) map[string]interface{} {
+	response := map[string]interface{}{
+		"event":      "cross-referenced",
+		"actor":      nil,
+		"created_at": ref.CreatedAt.Format(time.RFC3339),
+		"updated_at": ref.CreatedAt.Format(time.RFC3339),
+		"source": map[string]interface{}{
+			"type":  "issue",
+			"issue": issueToResponse(source, author, repo),
+		},
+	}
+	if author != nil {
+		response["actor"] = map[string]interface{}{
+			"login": author.Login,
+			"id":    author.ID,
+			"type":  author.Type,
+		}
+	}
+	return response
+}
+
+// listIssueDependencies handles GET /repos/{owner}/{repo}/issues/{number}/dependencies,
+// a non-standard endpoint listing the issues this issue's body has referenced
+func (p *GitHubPlugin) listIssueDependencies(w http.ResponseWriter, r *http.Request) {
+	repo, issue, ok := p.issueForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	refs, err := p.store.ListIssueReferencesFrom(issue.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list references")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(refs))
+	for _, ref := range refs {
+		target, err := p.store.GetIssueByID(ref.TargetIssueID)
+		if err != nil {
+			continue
+		}
+		author, _ := p.store.GetUserByID(target.UserID)
+		response = append(response, issueToResponse(target, author, repo))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,157 @@
+// ABOUTME: Tests for issue cross-references
+// ABOUTME: Covers #number mentions, the timeline's cross-referenced events, dependencies, and closing keywords
+
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestMentionedIssueNumbers(t *testing.T) {
+	got := mentionedIssueNumbers("Fixes #5, see #12 and #5 again.\nNot alice/other#7, page#3, &#39; or https://example.com/#8")
+	if !slices.Equal(got, []int{5, 12}) {
+		t.Errorf("Expected [5 12], got %v", got)
+	}
+}
+
+func TestIssueBodyCreatesReference(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+	// Wait for webhook deliveries before the database closes
+	defer plugin.deliveries.Wait()
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	for i := 0; i < 5; i++ {
+		store.CreateIssue(repo.ID, alice.ID, "Bug", "", false)
+	}
+
+	w := serveGitHub(plugin, "POST", "/repos/alice/test-repo/issues", `{"title": "Fix the bug", "body": "Fixes #5 and mentions #99, which doesn't exist"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/5/timeline", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var timeline []map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &timeline)
+	if len(timeline) != 1 || timeline[0]["event"] != "cross-referenced" {
+		t.Fatalf("Expected one cross-referenced event, got %v", timeline)
+	}
+	source, _ := timeline[0]["source"].(map[string]interface{})
+	sourceIssue, _ := source["issue"].(map[string]interface{})
+	if source["type"] != "issue" || sourceIssue["number"] != float64(6) {
+		t.Errorf("Expected issue #6 as the source, got %v", source)
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/6/dependencies", "")
+	var deps []map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &deps)
+	if len(deps) != 1 || deps[0]["number"] != float64(5) {
+		t.Fatalf("Expected issue #5 as the only dependency, got %v", deps)
+	}
+
+	// Editing the body adds new references and keeps the old ones
+	if w := serveGitHub(plugin, "PATCH", "/repos/alice/test-repo/issues/6", `{"body": "Also blocked by #2. Mentions itself, #6"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/6/dependencies", "")
+	deps = nil
+	json.Unmarshal(w.Body.Bytes(), &deps)
+	if len(deps) != 2 || deps[0]["number"] != float64(5) || deps[1]["number"] != float64(2) {
+		t.Errorf("Expected issues #5 and #2 as dependencies, got %v", deps)
+	}
+
+	// The timeline also includes the issue's other events
+	serveGitHub(plugin, "PUT", "/repos/alice/test-repo/issues/5/lock", "")
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/5/timeline", "")
+	timeline = nil
+	json.Unmarshal(w.Body.Bytes(), &timeline)
+	if len(timeline) != 2 || timeline[0]["event"] != "cross-referenced" || timeline[1]["event"] != "locked" {
+		t.Errorf("Expected cross-referenced then locked, got %v", timeline)
+	}
+}
+
+func TestMergeClosesFixedIssue(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	other, _ := store.CreateRepository(alice.ID, "other-repo", "", false)
+	store.CreateIssue(repo.ID, alice.ID, "Crash on startup", "", false)
+	store.CreateIssue(repo.ID, alice.ID, "Only mentioned", "", false)
+	store.CreateIssue(other.ID, alice.ID, "Elsewhere", "", false)
+	store.CreatePullRequest(repo.ID, alice.ID, "Fix crash", "Fixes #1, relates to #2 and alice/other-repo#1", "fix", "main")
+
+	if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/pulls/3/merge", `{}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/1", "")
+	var issue map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &issue)
+	if issue["state"] != "closed" || issue["state_reason"] != "completed" {
+		t.Fatalf("Expected issue #1 closed as completed, got state=%v state_reason=%v", issue["state"], issue["state_reason"])
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/1/timeline", "")
+	var timeline []map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &timeline)
+	if len(timeline) != 2 || timeline[0]["event"] != "cross-referenced" || timeline[1]["event"] != "closed" {
+		t.Fatalf("Expected cross-referenced then closed, got %v", timeline)
+	}
+	source, _ := timeline[1]["source"].(map[string]interface{})
+	sourceIssue, _ := source["issue"].(map[string]interface{})
+	if sourceIssue["number"] != float64(3) {
+		t.Errorf("Expected the closed event to cite pull request #3, got %v", timeline[1])
+	}
+
+	// A plain mention is cross-referenced but not closed, and other repositories are untouched
+	if i, _ := store.GetIssueByNumber(repo.ID, 2); i.State != "open" {
+		t.Errorf("Expected issue #2 to stay open, got %s", i.State)
+	}
+	if i, _ := store.GetIssueByNumber(other.ID, 1); i.State != "open" {
+		t.Errorf("Expected the other repository's issue to stay open, got %s", i.State)
+	}
+}
+
+func TestCommitClosesFixedIssue(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.CreateIssue(repo.ID, alice.ID, "Typo in notes", "", false)
+
+	w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/contents/notes.md",
+		`{"message": "Correct spelling\n\nCloses #1", "content": "`+base64.StdEncoding.EncodeToString([]byte("fixed\n"))+`"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/1/events", "")
+	var events []map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &events)
+	if len(events) != 1 || events[0]["event"] != "closed" || events[0]["commit_id"] != resp.Commit.SHA {
+		t.Fatalf("Expected a closed event citing commit %s, got %v", resp.Commit.SHA, events)
+	}
+}
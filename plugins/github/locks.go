@@ -0,0 +1,160 @@
+// ABOUTME: Issue locking endpoints for the GitHub plugin
+// ABOUTME: Locks and unlocks conversations, and lists the events that records on the issue
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// issueForRequest loads the repository and issue named by {owner}, {repo},
+// and {number}, writing an error response and returning false if either is missing
+func (p *GitHubPlugin) issueForRequest(w http.ResponseWriter, r *http.Request) (*Repository, *Issue, bool) {
+	repo, err := p.store.GetRepositoryByFullName(chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return nil, nil, false
+	}
+
+	var issueNum int
+	if _, err := fmt.Sscanf(chi.URLParam(r, "number"), "%d", &issueNum); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid issue number")
+		return nil, nil, false
+	}
+
+	issue, err := p.store.GetIssueByNumber(repo.ID, issueNum)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return nil, nil, false
+	}
+	return repo, issue, true
+}
+
+// lockIssue handles PUT /repos/{owner}/{repo}/issues/{number}/lock
+func (p *GitHubPlugin) lockIssue(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	// The body is optional; without one the issue is locked with no reason
+	var req struct {
+		LockReason string `json:"lock_reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.LockReason != "" && !slices.Contains(LockReasons, req.LockReason) {
+		writeError(w, http.StatusUnprocessableEntity, "lock_reason must be one of: "+strings.Join(LockReasons, ", "))
+		return
+	}
+
+	_, issue, ok := p.issueForRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := p.store.SetIssueLock(issue, user.ID, true, req.LockReason); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to lock issue")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unlockIssue handles DELETE /repos/{owner}/{repo}/issues/{number}/lock
+func (p *GitHubPlugin) unlockIssue(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	_, issue, ok := p.issueForRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := p.store.SetIssueLock(issue, user.ID, false, ""); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to unlock issue")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listIssueEvents handles GET /repos/{owner}/{repo}/issues/{number}/events
+func (p *GitHubPlugin) listIssueEvents(w http.ResponseWriter, r *http.Request) {
+	repo, issue, ok := p.issueForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := p.store.ListIssueEvents(issue.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list events")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		response = append(response, p.issueEventResponse(event, repo))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// issueEventResponse converts an IssueEvent to GitHub API response format,
+// loading its actor and, for an issue closed by a pull request, the pull request
+func (p *GitHubPlugin) issueEventResponse(event *IssueEvent, repo *Repository) map[string]interface{} {
+	actor, _ := p.store.GetUserByID(event.ActorID)
+	response := issueEventToResponse(event, actor, repo)
+	if event.SourceIssueID != nil {
+		if source, err := p.store.GetIssueByID(*event.SourceIssueID); err == nil {
+			author, _ := p.store.GetUserByID(source.UserID)
+			response["source"] = map[string]interface{}{
+				"type":  "issue",
+				"issue": issueToResponse(source, author, repo),
+			}
+		}
+	}
+	return response
+}
+
+// issueEventToResponse converts an IssueEvent to GitHub API response format
+func issueEventToResponse(event *IssueEvent, actor *User, repo *Repository) map[string]interface{} {
+	response := map[string]interface{}{
+		"id":         event.ID,
+		"event":      event.Event,
+		"actor":      nil,
+		"created_at": event.CreatedAt.Format(time.RFC3339),
+	}
+	if actor != nil {
+		response["actor"] = map[string]interface{}{
+			"login": actor.Login,
+			"id":    actor.ID,
+			"type":  actor.Type,
+		}
+	}
+	if event.Event == "locked" {
+		response["lock_reason"] = nil
+		if event.LockReason != "" {
+			response["lock_reason"] = event.LockReason
+		}
+	}
+	if event.Event == "closed" {
+		response["commit_id"] = nil
+		response["commit_url"] = nil
+		if event.CommitID != "" {
+			response["commit_id"] = event.CommitID
+			response["commit_url"] = fmt.Sprintf("https://api.github.com/repos/%s/commits/%s", repo.FullName, event.CommitID)
+		}
+	}
+	return response
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Tests for issue locking
+// ABOUTME: Covers lock reasons, rejected comments on locked issues, and the locked/unlocked events
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestLockIssueBlocksComments(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.CreateIssue(repo.ID, alice.ID, "Heated debate", "", false)
+
+	comment := func() int {
+		return serveGitHub(plugin, "POST", "/repos/alice/test-repo/issues/1/comments", `{"body": "One more thing"}`).Code
+	}
+	if code := comment(); code != http.StatusCreated {
+		t.Fatalf("Expected 201 before locking, got %d", code)
+	}
+
+	if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/issues/1/lock", `{"lock_reason": "sideways"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for an invalid lock reason, got %d", w.Code)
+	}
+	if w := serveGitHub(plugin, "PUT", "/repos/alice/test-repo/issues/1/lock", `{"lock_reason": "too heated"}`); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 from lock, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/1", "")
+	var issue map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &issue)
+	if issue["locked"] != true || issue["active_lock_reason"] != "too heated" {
+		t.Errorf("Expected a locked issue, got locked=%v active_lock_reason=%v", issue["locked"], issue["active_lock_reason"])
+	}
+	if code := comment(); code != http.StatusForbidden {
+		t.Errorf("Expected 403 commenting on a locked issue, got %d", code)
+	}
+
+	if w := serveGitHub(plugin, "DELETE", "/repos/alice/test-repo/issues/1/lock", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 from unlock, got %d: %s", w.Code, w.Body.String())
+	}
+	if code := comment(); code != http.StatusCreated {
+		t.Errorf("Expected 201 after unlocking, got %d", code)
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/issues/1/events", "")
+	var events []map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &events)
+	if len(events) != 2 || events[0]["event"] != "locked" || events[0]["lock_reason"] != "too heated" || events[1]["event"] != "unlocked" {
+		t.Fatalf("Unexpected events: %v", events)
+	}
+	if actor, _ := events[0]["actor"].(map[string]interface{}); actor["login"] != "alice" {
+		t.Errorf("Expected alice as the actor, got %v", events[0]["actor"])
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/2389/ish/plugins/core"
 	"github.com/go-chi/chi/v5"
@@ -63,6 +66,34 @@ func (p *GitHubPlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// idempotent lets clients retry a create with an Idempotency-Key without
+// creating a duplicate. It must run inside requireAuth.
+func (p *GitHubPlugin) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, _ := getUserFromContext(r)
+		var login string
+		if user != nil {
+			login = user.Login
+		}
+		core.HandleIdempotent(p.store.db, login, w, r, next)
+	}
+}
+
+// dryRunnable serves handler normally, or for a dry run against a copy of the
+// plugin whose store writes are rolled back afterwards
+func (p *GitHubPlugin) dryRunnable(handler func(*GitHubPlugin, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !core.IsDryRun(r) {
+			handler(p, w, r)
+			return
+		}
+		core.HandleDryRun(p.store.db, w, r, func(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+			dry := &GitHubPlugin{store: &GitHubStore{db: db, clock: p.store.clock}, dryRun: true}
+			handler(dry, w, r)
+		})
+	}
+}
+
 // getUserFromContext safely extracts the authenticated user from request context
 // Returns the user and true if found, or nil and false if not present or wrong type
 func getUserFromContext(r *http.Request) (*User, bool) {
@@ -74,15 +105,73 @@ func getUserFromContext(r *http.Request) (*User, bool) {
 	return user, ok
 }
 
+// documentationURL is the documentation_url of error responses
+const documentationURL = "https://docs.github.com/rest"
+
 // writeError writes a GitHub-style JSON error response
 func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorBody(w, status, map[string]interface{}{"message": message})
+}
+
+// writeErrorBody adds documentation_url and status to an error body and writes it
+func writeErrorBody(w http.ResponseWriter, status int, body map[string]interface{}) {
+	body["documentation_url"] = documentationURL
+	body["status"] = strconv.Itoa(status)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]string{"message": message})
+	json.NewEncoder(w).Encode(body)
+}
+
+// fieldError is one entry in the errors array of a 422 response. Code is
+// missing_field, invalid, already_exists, or another GitHub error code.
+type fieldError struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+}
+
+// writeValidationError writes a GitHub-style 422 "Validation Failed" response for one field
+func writeValidationError(w http.ResponseWriter, resource, field, code string) {
+	writeValidationErrors(w, []fieldError{{Resource: resource, Field: field, Code: code}})
+}
+
+// writeValidationErrors writes a 422 "Validation Failed" response listing every invalid field
+func writeValidationErrors(w http.ResponseWriter, errs []fieldError) {
+	writeErrorBody(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"message": "Validation Failed",
+		"errors":  errs,
+	})
 }
 
 type GitHubPlugin struct {
-	store *GitHubStore
+	store      *GitHubStore
+	deliveries sync.WaitGroup
+
+	// stopWorker stops the webhook delivery worker started by SetDB
+	stopWorker context.CancelFunc
+	workers    sync.WaitGroup
+
+	// workflowRunDelay overrides defaultWorkflowRunDelay when configured
+	workflowRunDelay *time.Duration
+
+	// installationTokenTTL overrides defaultInstallationTokenTTL when configured
+	installationTokenTTL *time.Duration
+
+	// dryRun marks the throwaway copy that serves a dry run, which never
+	// fires webhooks
+	dryRun bool
+}
+
+// Shutdown implements core.Shutdowner, stopping the delivery worker and
+// waiting for webhook deliveries in flight
+func (p *GitHubPlugin) Shutdown(ctx context.Context) error {
+	if p.stopWorker != nil {
+		p.stopWorker()
+	}
+	if err := core.WaitGroupContext(ctx, &p.workers); err != nil {
+		return err
+	}
+	return core.WaitGroupContext(ctx, &p.deliveries)
 }
 
 func (p *GitHubPlugin) Name() string {
@@ -97,29 +186,71 @@ func (p *GitHubPlugin) Health() core.HealthStatus {
 }
 
 func (p *GitHubPlugin) RegisterRoutes(r chi.Router) {
+	// GraphQL endpoint, shared with other plugins through core.GraphQLHandler
+	r.Post("/graphql", p.requireAuth(p.handleGraphQL))
+
 	// User endpoints
 	r.Get("/user", p.requireAuth(p.getAuthenticatedUser))
 	r.Patch("/user", p.requireAuth(p.updateAuthenticatedUser))
 	r.Get("/users/{username}", p.requireAuth(p.getUser))
 
+	// GitHub App endpoints, authenticated with the app's JWT
+	r.Get("/app/installations", p.requireAppJWT(p.listInstallations))
+	r.Post("/app/installations/{installation_id}/access_tokens", p.requireAppJWT(p.createInstallationToken))
+
+	// Search endpoints
+	r.Get("/search/repositories", p.requireAuth(p.searchRepositories))
+	r.Get("/search/users", p.requireAuth(p.searchUsers))
+
 	// Repository endpoints
 	r.Get("/user/repos", p.requireAuth(p.listAuthenticatedUserRepositories))
 	r.Post("/user/repos", p.requireAuth(p.createUserRepository))
 	r.Get("/repos/{owner}/{repo}", p.requireAuth(p.getRepository))
 	r.Patch("/repos/{owner}/{repo}", p.requireAuth(p.updateRepository))
 	r.Delete("/repos/{owner}/{repo}", p.requireAuth(p.deleteRepository))
+	r.Get("/repos/{owner}/{repo}/forks", p.requireAuth(p.listForks))
+	r.Post("/repos/{owner}/{repo}/forks", p.requireAuth(p.forkRepository))
+	r.Get("/repos/{owner}/{repo}/topics", p.requireAuth(p.getTopics))
+	r.Put("/repos/{owner}/{repo}/topics", p.requireAuth(p.replaceTopics))
+	r.Get("/repos/{owner}/{repo}/collaborators", p.requireAuth(p.listCollaborators))
+	r.Put("/repos/{owner}/{repo}/collaborators/{username}", p.requireAuth(p.addCollaborator))
+	r.Delete("/repos/{owner}/{repo}/collaborators/{username}", p.requireAuth(p.removeCollaborator))
+	r.Get("/repos/{owner}/{repo}/codeowners/errors", p.requireAuth(p.getCodeownersErrors))
+	r.Get("/repos/{owner}/{repo}/stats/code_frequency", p.requireAuth(p.repoStats(statsCodeFrequency)))
+	r.Get("/repos/{owner}/{repo}/stats/participation", p.requireAuth(p.repoStats(statsParticipation)))
+	r.Get("/repos/{owner}/{repo}/stats/punch_card", p.requireAuth(p.repoStats(statsPunchCard)))
+
+	// Source archive downloads redirect to archives generated on the fly
+	r.Get("/repos/{owner}/{repo}/tarball", p.requireAuth(p.downloadArchive("tarball")))
+	r.Get("/repos/{owner}/{repo}/tarball/{ref}", p.requireAuth(p.downloadArchive("tarball")))
+	r.Get("/repos/{owner}/{repo}/zipball", p.requireAuth(p.downloadArchive("zipball")))
+	r.Get("/repos/{owner}/{repo}/zipball/{ref}", p.requireAuth(p.downloadArchive("zipball")))
+	r.Get("/archives/{owner}/{repo}/{sha}.tar.gz", p.serveArchive("tarball"))
+	r.Get("/archives/{owner}/{repo}/{sha}.zip", p.serveArchive("zipball"))
+
+	// Repository contents and git trees (templates, workflow files, and written files)
+	r.Get("/repos/{owner}/{repo}/contents/*", p.requireAuth(p.getContents))
+	r.Put("/repos/{owner}/{repo}/contents/*", p.requireAuth(p.putContents))
+	r.Delete("/repos/{owner}/{repo}/contents/*", p.requireAuth(p.deleteContents))
+	r.Get("/repos/{owner}/{repo}/git/trees/{sha}", p.requireAuth(p.getGitTree))
 
 	// Issue endpoints
 	r.Get("/repos/{owner}/{repo}/issues", p.requireAuth(p.listIssues))
-	r.Post("/repos/{owner}/{repo}/issues", p.requireAuth(p.createIssue))
+	r.Post("/repos/{owner}/{repo}/issues", p.requireAuth(p.idempotent(p.dryRunnable((*GitHubPlugin).createIssue))))
 	r.Get("/repos/{owner}/{repo}/issues/{number}", p.requireAuth(p.getIssue))
 	r.Patch("/repos/{owner}/{repo}/issues/{number}", p.requireAuth(p.updateIssue))
+	r.Put("/repos/{owner}/{repo}/issues/{number}/lock", p.requireAuth(p.lockIssue))
+	r.Delete("/repos/{owner}/{repo}/issues/{number}/lock", p.requireAuth(p.unlockIssue))
+	r.Get("/repos/{owner}/{repo}/issues/{number}/events", p.requireAuth(p.listIssueEvents))
+	r.Get("/repos/{owner}/{repo}/issues/{number}/timeline", p.requireAuth(p.listIssueTimeline))
+	r.Get("/repos/{owner}/{repo}/issues/{number}/dependencies", p.requireAuth(p.listIssueDependencies))
 
 	// Pull Request endpoints
-	r.Post("/repos/{owner}/{repo}/pulls", p.requireAuth(p.createPullRequest))
+	r.Post("/repos/{owner}/{repo}/pulls", p.requireAuth(p.dryRunnable((*GitHubPlugin).createPullRequest)))
 	r.Get("/repos/{owner}/{repo}/pulls", p.requireAuth(p.listPullRequests))
 	r.Get("/repos/{owner}/{repo}/pulls/{number}", p.requireAuth(p.getPullRequest))
 	r.Put("/repos/{owner}/{repo}/pulls/{number}/merge", p.requireAuth(p.mergePullRequest))
+	r.Put("/repos/{owner}/{repo}/pulls/{number}/update-branch", p.requireAuth(p.updatePullRequestBranch))
 
 	// Comment endpoints
 	r.Post("/repos/{owner}/{repo}/issues/{number}/comments", p.requireAuth(p.createComment))
@@ -127,6 +258,47 @@ func (p *GitHubPlugin) RegisterRoutes(r chi.Router) {
 	r.Patch("/repos/{owner}/{repo}/issues/comments/{comment_id}", p.requireAuth(p.updateComment))
 	r.Delete("/repos/{owner}/{repo}/issues/comments/{comment_id}", p.requireAuth(p.deleteComment))
 
+	// Reaction endpoints
+	r.Post("/repos/{owner}/{repo}/issues/{number}/reactions", p.requireAuth(p.createReaction(p.issueReactionSubject)))
+	r.Get("/repos/{owner}/{repo}/issues/{number}/reactions", p.requireAuth(p.listReactions(p.issueReactionSubject)))
+	r.Delete("/repos/{owner}/{repo}/issues/{number}/reactions/{reaction_id}", p.requireAuth(p.deleteReaction(p.issueReactionSubject)))
+	r.Post("/repos/{owner}/{repo}/issues/comments/{comment_id}/reactions", p.requireAuth(p.createReaction(p.commentReactionSubject)))
+	r.Get("/repos/{owner}/{repo}/issues/comments/{comment_id}/reactions", p.requireAuth(p.listReactions(p.commentReactionSubject)))
+	r.Delete("/repos/{owner}/{repo}/issues/comments/{comment_id}/reactions/{reaction_id}", p.requireAuth(p.deleteReaction(p.commentReactionSubject)))
+	r.Post("/repos/{owner}/{repo}/pulls/comments/{comment_id}/reactions", p.requireAuth(p.createReaction(p.reviewCommentReactionSubject)))
+	r.Get("/repos/{owner}/{repo}/pulls/comments/{comment_id}/reactions", p.requireAuth(p.listReactions(p.reviewCommentReactionSubject)))
+	r.Delete("/repos/{owner}/{repo}/pulls/comments/{comment_id}/reactions/{reaction_id}", p.requireAuth(p.deleteReaction(p.reviewCommentReactionSubject)))
+
+	// Commit statuses and check runs
+	r.Post("/repos/{owner}/{repo}/statuses/{sha}", p.requireAuth(p.createCommitStatus))
+	r.Get("/repos/{owner}/{repo}/commits/{ref}/statuses", p.requireAuth(p.listCommitStatuses))
+	r.Get("/repos/{owner}/{repo}/commits/{ref}/status", p.requireAuth(p.getCombinedStatus))
+	r.Get("/repos/{owner}/{repo}/commits/{ref}/check-runs", p.requireAuth(p.listCheckRunsForRef))
+	r.Post("/repos/{owner}/{repo}/check-runs", p.requireAuth(p.createCheckRun))
+	r.Get("/repos/{owner}/{repo}/check-runs/{check_run_id}", p.requireAuth(p.getCheckRun))
+	r.Patch("/repos/{owner}/{repo}/check-runs/{check_run_id}", p.requireAuth(p.updateCheckRun))
+
+	// Secret scanning alerts
+	r.Get("/repos/{owner}/{repo}/secret-scanning/alerts", p.requireAuth(p.listSecretScanningAlerts))
+	r.Get("/repos/{owner}/{repo}/secret-scanning/alerts/{alert_number}", p.requireAuth(p.getSecretScanningAlert))
+	r.Patch("/repos/{owner}/{repo}/secret-scanning/alerts/{alert_number}", p.requireAuth(p.updateSecretScanningAlert))
+
+	// Actions workflows and runs
+	r.Get("/repos/{owner}/{repo}/actions/workflows", p.requireAuth(p.listWorkflows))
+	r.Post("/repos/{owner}/{repo}/actions/workflows/{workflow_id}/dispatches", p.requireAuth(p.dispatchWorkflow))
+	r.Get("/repos/{owner}/{repo}/actions/workflows/{workflow_id}/runs", p.requireAuth(p.listWorkflowRuns))
+	r.Get("/repos/{owner}/{repo}/actions/runs", p.requireAuth(p.listWorkflowRuns))
+	r.Get("/repos/{owner}/{repo}/actions/runs/{run_id}", p.requireAuth(p.getWorkflowRun))
+	r.Get("/repos/{owner}/{repo}/actions/runs/{run_id}/jobs", p.requireAuth(p.listWorkflowRunJobs))
+
+	// Repository dispatch events
+	r.Post("/repos/{owner}/{repo}/dispatches", p.requireAuth(p.dispatchRepositoryEvent))
+
+	// Branch protection
+	r.Get("/repos/{owner}/{repo}/branches/{branch}/protection", p.requireAuth(p.getBranchProtection))
+	r.Put("/repos/{owner}/{repo}/branches/{branch}/protection", p.requireAuth(p.updateBranchProtection))
+	r.Delete("/repos/{owner}/{repo}/branches/{branch}/protection", p.requireAuth(p.deleteBranchProtection))
+
 	// Review endpoints
 	r.Post("/repos/{owner}/{repo}/pulls/{number}/reviews", p.requireAuth(p.createReview))
 	r.Get("/repos/{owner}/{repo}/pulls/{number}/reviews", p.requireAuth(p.listReviews))
@@ -140,6 +312,12 @@ func (p *GitHubPlugin) RegisterRoutes(r chi.Router) {
 	r.Patch("/repos/{owner}/{repo}/hooks/{id}", p.requireAuth(p.updateWebhook))
 	r.Delete("/repos/{owner}/{repo}/hooks/{id}", p.requireAuth(p.deleteWebhook))
 	r.Post("/repos/{owner}/{repo}/hooks/{id}/tests", p.requireAuth(p.testWebhook))
+	r.Get("/repos/{owner}/{repo}/hooks/{id}/deliveries", p.requireAuth(p.listWebhookDeliveries))
+	r.Post("/repos/{owner}/{repo}/hooks/{id}/deliveries/{delivery_id}/attempts", p.requireAuth(p.redeliverWebhookDelivery))
+
+	// Admin configuration
+	r.Post("/admin/github/repos/{id}/templates", p.configureTemplate)
+	r.Get("/admin/github/dispatch-events", p.listDispatchEvents)
 }
 
 // Placeholder handlers for routes not yet implemented
@@ -261,6 +439,62 @@ func (p *GitHubPlugin) Schema() core.PluginSchema {
 	}
 }
 
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *GitHubPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "github", Table: "github_repositories", Resource: "repositories"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *GitHubPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"github": {
+			"github_review_comments",
+			"github_reviews",
+			"github_comments",
+			"github_issue_events",
+			"github_issue_references",
+			"github_reactions",
+			"github_workflow_runs",
+			"github_workflows",
+			"github_repository_dispatch_events",
+			"github_secret_scanning_alerts",
+			"github_check_runs",
+			"github_commit_statuses",
+			"github_repo_templates",
+			"github_repo_files",
+			"github_repo_topics",
+			"github_computed_stats",
+			"github_repo_collaborators",
+			"github_webhook_deliveries",
+			"github_webhooks",
+			"github_pull_requests",
+			"github_issues",
+			"github_commits",
+			"github_branch_protection",
+			"github_branches",
+			"github_repositories",
+			"github_tokens",
+			"github_app_installations",
+			"github_users",
+		},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *GitHubPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *GitHubPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
+
 // Seed is implemented in seed.go
 
 func (p *GitHubPlugin) ValidateToken(token string) bool {
@@ -277,6 +511,19 @@ func (p *GitHubPlugin) SetDB(db *sql.DB) error {
 		return err
 	}
 	p.store = store
+
+	// Start the webhook delivery worker, replacing any worker bound to a previous database
+	if p.stopWorker != nil {
+		p.stopWorker()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stopWorker = cancel
+	p.workers.Add(1)
+	go func() {
+		defer p.workers.Done()
+		p.startDeliveryWorker(ctx)
+	}()
+
 	return nil
 }
 
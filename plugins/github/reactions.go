@@ -0,0 +1,256 @@
+// ABOUTME: Reaction endpoints for GitHub issues, issue comments, and review comments
+// ABOUTME: Handles create/list/delete and the reactions summary embedded in responses
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// reactionSubject identifies what a reactions request targets
+type reactionSubject struct {
+	Type string
+	ID   int64
+}
+
+// subjectResolver looks up the reaction subject from the URL, writing an error
+// response and returning false if it doesn't exist
+type subjectResolver func(w http.ResponseWriter, r *http.Request) (reactionSubject, bool)
+
+// issueReactionSubject resolves /repos/{owner}/{repo}/issues/{number}
+func (p *GitHubPlugin) issueReactionSubject(w http.ResponseWriter, r *http.Request) (reactionSubject, bool) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return reactionSubject{}, false
+	}
+
+	var issueNum int
+	if _, err := fmt.Sscanf(chi.URLParam(r, "number"), "%d", &issueNum); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid issue number")
+		return reactionSubject{}, false
+	}
+
+	issue, err := p.store.GetIssueByNumber(repo.ID, issueNum)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "issue not found")
+		return reactionSubject{}, false
+	}
+
+	return reactionSubject{Type: ReactionSubjectIssue, ID: issue.ID}, true
+}
+
+// commentReactionSubject resolves /repos/{owner}/{repo}/issues/comments/{comment_id}
+func (p *GitHubPlugin) commentReactionSubject(w http.ResponseWriter, r *http.Request) (reactionSubject, bool) {
+	var id int64
+	if _, err := fmt.Sscanf(chi.URLParam(r, "comment_id"), "%d", &id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid comment id")
+		return reactionSubject{}, false
+	}
+
+	if _, err := p.store.GetComment(id); err != nil {
+		writeError(w, http.StatusNotFound, "comment not found")
+		return reactionSubject{}, false
+	}
+
+	return reactionSubject{Type: ReactionSubjectIssueComment, ID: id}, true
+}
+
+// reviewCommentReactionSubject resolves /repos/{owner}/{repo}/pulls/comments/{comment_id}
+func (p *GitHubPlugin) reviewCommentReactionSubject(w http.ResponseWriter, r *http.Request) (reactionSubject, bool) {
+	var id int64
+	if _, err := fmt.Sscanf(chi.URLParam(r, "comment_id"), "%d", &id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid comment id")
+		return reactionSubject{}, false
+	}
+
+	exists, err := p.store.ReviewCommentExists(id)
+	if err != nil || !exists {
+		writeError(w, http.StatusNotFound, "comment not found")
+		return reactionSubject{}, false
+	}
+
+	return reactionSubject{Type: ReactionSubjectReviewComment, ID: id}, true
+}
+
+// createReaction handles POST .../reactions. A repeated reaction returns 200
+// with the existing reaction; a new one returns 201.
+func (p *GitHubPlugin) createReaction(resolve subjectResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := getUserFromContext(r)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "authentication context invalid")
+			return
+		}
+
+		var req struct {
+			Content string `json:"content"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.Content == "" {
+			writeValidationError(w, "Reaction", "content", "missing_field")
+			return
+		}
+		if !slices.Contains(ReactionContents, req.Content) {
+			writeValidationError(w, "Reaction", "content", "invalid")
+			return
+		}
+
+		subject, ok := resolve(w, r)
+		if !ok {
+			return
+		}
+
+		reaction, created, err := p.store.CreateReaction(subject.Type, subject.ID, user.ID, req.Content)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to create reaction")
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(reactionToResponse(reaction, user))
+	}
+}
+
+// listReactions handles GET .../reactions, with an optional ?content= filter
+func (p *GitHubPlugin) listReactions(resolve subjectResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		content := r.URL.Query().Get("content")
+		if content != "" && !slices.Contains(ReactionContents, content) {
+			writeValidationError(w, "Reaction", "content", "invalid")
+			return
+		}
+
+		subject, ok := resolve(w, r)
+		if !ok {
+			return
+		}
+
+		reactions, err := p.store.ListReactions(subject.Type, subject.ID, content)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to list reactions")
+			return
+		}
+
+		response := []map[string]interface{}{}
+		for _, reaction := range reactions {
+			reactionUser, _ := p.store.GetUserByID(reaction.UserID)
+			response = append(response, reactionToResponse(reaction, reactionUser))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// deleteReaction handles DELETE .../reactions/{reaction_id}. Users can only
+// delete their own reactions.
+func (p *GitHubPlugin) deleteReaction(resolve subjectResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := getUserFromContext(r)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "authentication context invalid")
+			return
+		}
+
+		var id int64
+		if _, err := fmt.Sscanf(chi.URLParam(r, "reaction_id"), "%d", &id); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid reaction id")
+			return
+		}
+
+		subject, ok := resolve(w, r)
+		if !ok {
+			return
+		}
+
+		reaction, err := p.store.GetReaction(id)
+		if err != nil || reaction.SubjectType != subject.Type || reaction.SubjectID != subject.ID {
+			writeError(w, http.StatusNotFound, "reaction not found")
+			return
+		}
+		if reaction.UserID != user.ID {
+			writeError(w, http.StatusForbidden, "you can only delete your own reactions")
+			return
+		}
+
+		if err := p.store.DeleteReaction(id); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to delete reaction")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// reactionToResponse converts Reaction to GitHub API response format
+func reactionToResponse(reaction *Reaction, user *User) map[string]interface{} {
+	response := map[string]interface{}{
+		"id":         reaction.ID,
+		"content":    reaction.Content,
+		"created_at": reaction.CreatedAt.Format(time.RFC3339),
+	}
+
+	// Handle nil user gracefully (user might have been deleted)
+	if user != nil {
+		response["user"] = map[string]interface{}{
+			"login": user.Login,
+			"id":    user.ID,
+			"type":  user.Type,
+		}
+	} else {
+		response["user"] = map[string]interface{}{
+			"login": "[deleted]",
+			"id":    0,
+			"type":  "User",
+		}
+	}
+
+	return response
+}
+
+// addReactionSummary sets the "reactions" rollup GitHub embeds in issue and comment responses
+func (p *GitHubPlugin) addReactionSummary(response map[string]interface{}, subjectType string, subjectID int64, url string) {
+	counts, err := p.store.CountReactions(subjectType, subjectID)
+	if err != nil {
+		counts = map[string]int{}
+	}
+
+	summary := map[string]interface{}{"url": url}
+	total := 0
+	for _, content := range ReactionContents {
+		summary[content] = counts[content]
+		total += counts[content]
+	}
+	summary["total_count"] = total
+
+	response["reactions"] = summary
+}
+
+// addIssueReactions adds the reactions summary to an issue response
+func (p *GitHubPlugin) addIssueReactions(response map[string]interface{}, issue *Issue, repo *Repository) {
+	url := fmt.Sprintf("/repos/%s/issues/%d/reactions", repo.FullName, issue.Number)
+	p.addReactionSummary(response, ReactionSubjectIssue, issue.ID, url)
+}
+
+// addCommentReactions adds the reactions summary to an issue comment response
+func (p *GitHubPlugin) addCommentReactions(response map[string]interface{}, comment *Comment, repoFullName string) {
+	url := fmt.Sprintf("/repos/%s/issues/comments/%d/reactions", repoFullName, comment.ID)
+	p.addReactionSummary(response, ReactionSubjectIssueComment, comment.ID, url)
+}
@@ -0,0 +1,159 @@
+// ABOUTME: Tests for GitHub reaction endpoints
+// ABOUTME: Covers creating, listing, deleting reactions and the issue reactions summary
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// authedRequest runs a handler as the given token with chi URL params set
+func authedRequest(plugin *GitHubPlugin, handler http.HandlerFunc, method, token, body string, params map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/reactions", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	plugin.requireAuth(handler)(w, req)
+	return w
+}
+
+func TestIssueReactionsSummary(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.GetOrCreateUser("bob", "ghp_bob")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.CreateIssue(repo.ID, alice.ID, "Test issue", "Body", false)
+
+	params := map[string]string{"owner": "alice", "repo": "test-repo", "number": "1"}
+	create := plugin.createReaction(plugin.issueReactionSubject)
+
+	w := authedRequest(plugin, create, "POST", "ghp_alice", `{"content": "+1"}`, params)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	w = authedRequest(plugin, create, "POST", "ghp_bob", `{"content": "heart"}`, params)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Repeating a reaction returns the existing one
+	w = authedRequest(plugin, create, "POST", "ghp_alice", `{"content": "+1"}`, params)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for duplicate reaction, got %d", w.Code)
+	}
+
+	w = authedRequest(plugin, plugin.getIssue, "GET", "ghp_alice", "", params)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var issue map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&issue)
+	reactions, ok := issue["reactions"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected reactions summary, got %v", issue["reactions"])
+	}
+	if reactions["total_count"] != float64(2) {
+		t.Errorf("Expected total_count 2, got %v", reactions["total_count"])
+	}
+	if reactions["+1"] != float64(1) || reactions["heart"] != float64(1) {
+		t.Errorf("Expected one +1 and one heart, got %v", reactions)
+	}
+	if reactions["rocket"] != float64(0) {
+		t.Errorf("Expected zero rocket reactions, got %v", reactions["rocket"])
+	}
+	if reactions["url"] != "/repos/alice/test-repo/issues/1/reactions" {
+		t.Errorf("Unexpected reactions url: %v", reactions["url"])
+	}
+}
+
+func TestCreateReactionInvalidContent(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.CreateIssue(repo.ID, alice.ID, "Test issue", "Body", false)
+
+	params := map[string]string{"owner": "alice", "repo": "test-repo", "number": "1"}
+	for _, body := range []string{`{"content": "thumbsup"}`, `{}`} {
+		w := authedRequest(plugin, plugin.createReaction(plugin.issueReactionSubject), "POST", "ghp_alice", body, params)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected 422 for %s, got %d", body, w.Code)
+		}
+
+		var resp map[string]interface{}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if resp["message"] != "Validation Failed" {
+			t.Errorf("Expected Validation Failed message, got %v", resp["message"])
+		}
+	}
+}
+
+func TestCommentReactionsListAndDelete(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.GetOrCreateUser("bob", "ghp_bob")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	issue, _ := store.CreateIssue(repo.ID, alice.ID, "Test issue", "Body", false)
+	comment, _ := store.CreateComment(issue.ID, alice.ID, "Nice")
+
+	params := map[string]string{"owner": "alice", "repo": "test-repo", "comment_id": "1"}
+	authedRequest(plugin, plugin.createReaction(plugin.commentReactionSubject), "POST", "ghp_alice", `{"content": "rocket"}`, params)
+	authedRequest(plugin, plugin.createReaction(plugin.commentReactionSubject), "POST", "ghp_bob", `{"content": "eyes"}`, params)
+
+	w := authedRequest(plugin, plugin.listReactions(plugin.commentReactionSubject), "GET", "ghp_alice", "", params)
+	var reactions []map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&reactions)
+	if len(reactions) != 2 {
+		t.Fatalf("Expected 2 reactions, got %d", len(reactions))
+	}
+
+	// Bob can't delete Alice's reaction
+	params["reaction_id"] = "1"
+	w = authedRequest(plugin, plugin.deleteReaction(plugin.commentReactionSubject), "DELETE", "ghp_bob", "", params)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403, got %d", w.Code)
+	}
+
+	w = authedRequest(plugin, plugin.deleteReaction(plugin.commentReactionSubject), "DELETE", "ghp_alice", "", params)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	remaining, _ := store.ListReactions(ReactionSubjectIssueComment, comment.ID, "")
+	if len(remaining) != 1 || remaining[0].Content != "eyes" {
+		t.Errorf("Expected only the eyes reaction to remain, got %v", remaining)
+	}
+
+	// Deleting the comment removes its reactions
+	store.DeleteComment(comment.ID)
+	remaining, _ = store.ListReactions(ReactionSubjectIssueComment, comment.ID, "")
+	if len(remaining) != 0 {
+		t.Errorf("Expected reactions to be deleted with the comment, got %d", len(remaining))
+	}
+}
@@ -0,0 +1,158 @@
+// ABOUTME: Repository and user search endpoints for GitHub
+// ABOUTME: Parses search qualifiers and returns GitHub's search result envelope
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// parseSearchQuery splits q into free-text terms and the qualifiers named in
+// known (such as user:octocat). Unknown qualifiers are searched as text.
+func parseSearchQuery(q string, known ...string) ([]string, map[string]string) {
+	var terms []string
+	qualifiers := map[string]string{}
+	for _, field := range strings.Fields(q) {
+		if key, value, ok := strings.Cut(field, ":"); ok && value != "" {
+			key = strings.ToLower(key)
+			if slices.Contains(known, key) {
+				qualifiers[key] = value
+				continue
+			}
+		}
+		terms = append(terms, field)
+	}
+	return terms, qualifiers
+}
+
+// searchPage reads per_page (at most 100) and page, returning the limit and offset
+func searchPage(r *http.Request) (int, int) {
+	perPage := queryInt(r, "per_page", 30)
+	if perPage > 100 {
+		perPage = 100
+	}
+	return perPage, (queryInt(r, "page", 1) - 1) * perPage
+}
+
+// writeSearchResults writes the {total_count, incomplete_results, items} envelope
+func writeSearchResults(w http.ResponseWriter, total int, items []map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total_count":        total,
+		"incomplete_results": false,
+		"items":              items,
+	})
+}
+
+// searchRepositories handles GET /search/repositories. q supports free text
+// over names and descriptions and the user: and language: qualifiers; sort
+// is stars, forks, or updated.
+func (p *GitHubPlugin) searchRepositories(w http.ResponseWriter, r *http.Request) {
+	viewer, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeValidationError(w, "Search", "q", "missing")
+		return
+	}
+	sort := r.URL.Query().Get("sort")
+	if _, ok := repositorySearchSorts[sort]; sort != "" && !ok {
+		writeValidationError(w, "Search", "sort", "invalid")
+		return
+	}
+
+	terms, qualifiers := parseSearchQuery(q, "user", "language")
+	limit, offset := searchPage(r)
+	repos, total, err := p.store.SearchRepositories(RepositorySearch{
+		Terms:    terms,
+		Owner:    qualifiers["user"],
+		Language: qualifiers["language"],
+		ViewerID: viewer.ID,
+		Sort:     sort,
+		Order:    r.URL.Query().Get("order"),
+	}, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to search repositories")
+		return
+	}
+
+	owners := map[int64]*User{}
+	items := make([]map[string]interface{}, 0, len(repos))
+	for _, repo := range repos {
+		owner, ok := owners[repo.OwnerID]
+		if !ok {
+			if owner, err = p.store.GetUserByID(repo.OwnerID); err != nil {
+				writeError(w, http.StatusInternalServerError, "failed to load repository owner")
+				return
+			}
+			owners[repo.OwnerID] = owner
+		}
+		item := repositoryToResponse(repo, owner)
+		item["score"] = 1.0
+		items = append(items, item)
+	}
+
+	writeSearchResults(w, total, items)
+}
+
+// searchUsers handles GET /search/users. q supports free text over logins and
+// names and the type: qualifier (user or org); sort is joined or repositories.
+func (p *GitHubPlugin) searchUsers(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeValidationError(w, "Search", "q", "missing")
+		return
+	}
+	sort := r.URL.Query().Get("sort")
+	if _, ok := userSearchSorts[sort]; sort != "" && !ok {
+		writeValidationError(w, "Search", "sort", "invalid")
+		return
+	}
+
+	terms, qualifiers := parseSearchQuery(q, "type")
+	userType := ""
+	switch strings.ToLower(qualifiers["type"]) {
+	case "":
+	case "user":
+		userType = "User"
+	case "org":
+		userType = "Organization"
+	default:
+		writeValidationError(w, "Search", "q", "invalid")
+		return
+	}
+
+	limit, offset := searchPage(r)
+	users, total, err := p.store.SearchUsers(UserSearch{
+		Terms: terms,
+		Type:  userType,
+		Sort:  sort,
+		Order: r.URL.Query().Get("order"),
+	}, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to search users")
+		return
+	}
+
+	items := make([]map[string]interface{}, 0, len(users))
+	for _, user := range users {
+		items = append(items, map[string]interface{}{
+			"login":      user.Login,
+			"id":         user.ID,
+			"type":       user.Type,
+			"avatar_url": user.AvatarURL,
+			"url":        "/users/" + user.Login,
+			"html_url":   fmt.Sprintf("https://github.com/%s", user.Login),
+			"score":      1.0,
+		})
+	}
+
+	writeSearchResults(w, total, items)
+}
@@ -0,0 +1,102 @@
+// ABOUTME: Tests for GitHub repository and user search
+// ABOUTME: Covers name substring matches, qualifiers, star sorting, and login search
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func search(t *testing.T, plugin *GitHubPlugin, handler http.HandlerFunc, query string) (int, []map[string]interface{}) {
+	t.Helper()
+	w := authedRequest(plugin, func(w http.ResponseWriter, r *http.Request) {
+		r.URL.RawQuery = query
+		handler(w, r)
+	}, "GET", "ghp_alice", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET ?%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+	}
+	var resp struct {
+		TotalCount        int                      `json:"total_count"`
+		IncompleteResults *bool                    `json:"incomplete_results"`
+		Items             []map[string]interface{} `json:"items"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.IncompleteResults == nil {
+		t.Fatalf("GET ?%s: expected incomplete_results in the envelope", query)
+	}
+	return resp.TotalCount, resp.Items
+}
+
+func TestSearchRepositories(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	bob, _ := store.GetOrCreateUser("bob", "ghp_bob")
+	api, _ := store.CreateRepository(alice.ID, "payments-api", "Payment processing", false)
+	worker, _ := store.CreateRepository(bob.ID, "payments-worker", "Background jobs", false)
+	store.CreateRepository(bob.ID, "docs", "How to use the payments API", false)
+	store.CreateRepository(bob.ID, "payments-secret", "Private", true)
+	store.SetRepositoryLanguage(api.ID, "Go")
+	store.SetRepositoryLanguage(worker.ID, "Python")
+	store.SetRepositoryStars(worker.ID, 42)
+
+	total, items := search(t, plugin, plugin.searchRepositories, "q=payments")
+	if total != 3 || len(items) != 3 {
+		t.Fatalf("Expected 3 public matches by name or description, got %d: %v", total, items)
+	}
+
+	_, items = search(t, plugin, plugin.searchRepositories, "q=payments&sort=stars")
+	if items[0]["full_name"] != "bob/payments-worker" {
+		t.Errorf("Expected the starred repo first, got %v", items[0]["full_name"])
+	}
+
+	total, items = search(t, plugin, plugin.searchRepositories, "q=pay+user:alice")
+	if total != 1 || items[0]["full_name"] != "alice/payments-api" || items[0]["language"] != "Go" {
+		t.Errorf("Expected alice/payments-api, got %v", items)
+	}
+
+	total, items = search(t, plugin, plugin.searchRepositories, "q=language:python")
+	if total != 1 || items[0]["full_name"] != "bob/payments-worker" {
+		t.Errorf("Expected the Python repo, got %v", items)
+	}
+
+	if total, _ := search(t, plugin, plugin.searchRepositories, "q=100%25"); total != 0 {
+		t.Errorf("Expected %% to match literally, got %d results", total)
+	}
+
+	w := authedRequest(plugin, plugin.searchRepositories, "GET", "ghp_alice", "", nil)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 without q, got %d", w.Code)
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	store.GetOrCreateUser("alice", "ghp_alice")
+	store.GetOrCreateUser("alicia", "ghp_alicia")
+	store.GetOrCreateUser("bob", "ghp_bob")
+
+	total, items := search(t, plugin, plugin.searchUsers, "q=ali")
+	if total != 2 || len(items) != 2 {
+		t.Fatalf("Expected alice and alicia, got %d: %v", total, items)
+	}
+
+	_, items = search(t, plugin, plugin.searchUsers, "q=alicia")
+	if len(items) != 1 || items[0]["login"] != "alicia" || items[0]["url"] != "/users/alicia" {
+		t.Errorf("Expected alicia, got %v", items)
+	}
+
+	if total, _ := search(t, plugin, plugin.searchUsers, "q=ali+type:org"); total != 0 {
+		t.Errorf("Expected no organizations, got %d", total)
+	}
+}
@@ -0,0 +1,225 @@
+// ABOUTME: Secret scanning alert endpoints for GitHub repositories
+// ABOUTME: Lists leaked-secret alerts and resolves or reopens them
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Secret scanning alert states and the reasons an alert can be resolved
+var (
+	secretScanningStates      = []string{"open", "resolved"}
+	secretScanningResolutions = []string{"false_positive", "wont_fix", "revoked", "pattern_deleted", "used_in_tests"}
+)
+
+// secretTypeDisplayNames names the secret types ISH seeds; other types are
+// shown as-is
+var secretTypeDisplayNames = map[string]string{
+	"github_personal_access_token": "GitHub Personal Access Token",
+	"aws_access_key_id":            "AWS Access Key ID",
+	"slack_api_token":              "Slack API Token",
+	"stripe_api_key":               "Stripe API Key",
+	"google_api_key":               "Google API Key",
+}
+
+// listSecretScanningAlerts handles GET /repos/{owner}/{repo}/secret-scanning/alerts
+func (p *GitHubPlugin) listSecretScanningAlerts(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	if state != "" && !slices.Contains(secretScanningStates, state) {
+		writeValidationError(w, "SecretScanningAlert", "state", "invalid")
+		return
+	}
+	var secretTypes []string
+	if param := r.URL.Query().Get("secret_type"); param != "" {
+		secretTypes = strings.Split(param, ",")
+	}
+
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	alerts, err := p.store.ListSecretScanningAlerts(repo.ID, state, secretTypes)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list secret scanning alerts")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(alerts))
+	for _, alert := range alerts {
+		response = append(response, p.secretScanningAlertToResponse(alert, repo))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// getSecretScanningAlert handles GET /repos/{owner}/{repo}/secret-scanning/alerts/{alert_number}
+func (p *GitHubPlugin) getSecretScanningAlert(w http.ResponseWriter, r *http.Request) {
+	repo, alert, ok := p.lookupSecretScanningAlert(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.secretScanningAlertToResponse(alert, repo))
+}
+
+// updateSecretScanningAlert handles PATCH /repos/{owner}/{repo}/secret-scanning/alerts/{alert_number}.
+// Only state and resolution can change; resolving requires a resolution and
+// reopening clears it.
+func (p *GitHubPlugin) updateSecretScanningAlert(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	var req struct {
+		State      string  `json:"state"`
+		Resolution *string `json:"resolution"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.State == "" {
+		writeValidationError(w, "SecretScanningAlert", "state", "missing_field")
+		return
+	}
+	if !slices.Contains(secretScanningStates, req.State) {
+		writeValidationError(w, "SecretScanningAlert", "state", "invalid")
+		return
+	}
+	if req.State == "resolved" {
+		if req.Resolution == nil || *req.Resolution == "" {
+			writeValidationError(w, "SecretScanningAlert", "resolution", "missing_field")
+			return
+		}
+		if !slices.Contains(secretScanningResolutions, *req.Resolution) {
+			writeValidationError(w, "SecretScanningAlert", "resolution", "invalid")
+			return
+		}
+	}
+
+	repo, alert, ok := p.lookupSecretScanningAlert(w, r)
+	if !ok {
+		return
+	}
+
+	action := ""
+	switch {
+	case req.State == "resolved":
+		if alert.State != "resolved" {
+			action = "resolved"
+			now := p.store.now()
+			alert.ResolvedAt = &now
+			alert.ResolvedByID = user.ID
+		}
+		alert.Resolution = *req.Resolution
+	case alert.State == "resolved":
+		action = "reopened"
+		alert.Resolution = ""
+		alert.ResolvedAt = nil
+		alert.ResolvedByID = 0
+	}
+	alert.State = req.State
+
+	if err := p.store.UpdateSecretScanningAlert(alert); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update secret scanning alert")
+		return
+	}
+
+	response := p.secretScanningAlertToResponse(alert, repo)
+	if action != "" {
+		p.fireWebhooksAsync(repo.ID, "secret_scanning_alert", map[string]interface{}{
+			"action": action,
+			"alert":  response,
+			"repository": map[string]interface{}{
+				"id":        repo.ID,
+				"name":      repo.Name,
+				"full_name": repo.FullName,
+			},
+			"sender": map[string]interface{}{"login": user.Login, "id": user.ID},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// lookupSecretScanningAlert resolves the repository and {alert_number} from
+// the URL, writing an error response if either doesn't exist
+func (p *GitHubPlugin) lookupSecretScanningAlert(w http.ResponseWriter, r *http.Request) (*Repository, *SecretScanningAlert, bool) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return nil, nil, false
+	}
+
+	number, err := strconv.Atoi(chi.URLParam(r, "alert_number"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid alert number")
+		return nil, nil, false
+	}
+
+	alert, err := p.store.GetSecretScanningAlert(repo.ID, number)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "secret scanning alert not found")
+		return nil, nil, false
+	}
+
+	return repo, alert, true
+}
+
+func (p *GitHubPlugin) secretScanningAlertToResponse(alert *SecretScanningAlert, repo *Repository) map[string]interface{} {
+	url := fmt.Sprintf("/repos/%s/secret-scanning/alerts/%d", repo.FullName, alert.Number)
+	displayName := secretTypeDisplayNames[alert.SecretType]
+	if displayName == "" {
+		displayName = alert.SecretType
+	}
+
+	response := map[string]interface{}{
+		"number":                   alert.Number,
+		"created_at":               alert.CreatedAt.Format(time.RFC3339),
+		"updated_at":               alert.UpdatedAt.Format(time.RFC3339),
+		"url":                      url,
+		"html_url":                 fmt.Sprintf("https://github.com/%s/security/secret-scanning/%d", repo.FullName, alert.Number),
+		"locations_url":            url + "/locations",
+		"state":                    alert.State,
+		"resolution":               nilIfEmpty(alert.Resolution),
+		"resolved_at":              nil,
+		"resolved_by":              nil,
+		"secret_type":              alert.SecretType,
+		"secret_type_display_name": displayName,
+		"secret":                   alert.Secret,
+		"validity":                 "unknown",
+	}
+
+	if alert.ResolvedAt != nil {
+		response["resolved_at"] = alert.ResolvedAt.Format(time.RFC3339)
+	}
+	if alert.ResolvedByID != 0 {
+		if resolver, err := p.store.GetUserByID(alert.ResolvedByID); err == nil {
+			response["resolved_by"] = map[string]interface{}{
+				"login": resolver.Login,
+				"id":    resolver.ID,
+				"type":  resolver.Type,
+			}
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,117 @@
+// ABOUTME: Repository statistics endpoints: code frequency, participation and punch card
+// ABOUTME: Like GitHub, the first request returns 202 while stats are computed; later requests read the cache
+
+package github
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Kinds of repository statistics cached in github_computed_stats
+const (
+	statsCodeFrequency = "code_frequency"
+	statsParticipation = "participation"
+	statsPunchCard     = "punch_card"
+)
+
+// statsWeeks is how many weeks of history weekly statistics cover
+const statsWeeks = 52
+
+// repoStats serves GET /repos/{owner}/{repo}/stats/{kind}. Stats that
+// haven't been computed yet are computed and cached, and the request gets
+// 202 Accepted with an empty object; the next request returns them with 200.
+func (p *GitHubPlugin) repoStats(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner := chi.URLParam(r, "owner")
+		repoName := chi.URLParam(r, "repo")
+
+		repo, err := p.store.GetRepositoryByFullName(owner + "/" + repoName)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "repository not found")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		data, err := p.store.GetComputedStats(repo.ID, kind)
+		if err == nil {
+			w.Write(data)
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusInternalServerError, "failed to get statistics")
+			return
+		}
+
+		data, err = json.Marshal(generateRepoStats(repo.ID, kind, p.store.now()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to compute statistics")
+			return
+		}
+		if err := p.store.SaveComputedStats(repo.ID, kind, data); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to compute statistics")
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("{}"))
+	}
+}
+
+// generateRepoStats makes up a repository's statistics of one kind. The
+// random numbers are seeded with the repository ID, so a repository always
+// gets the same figures.
+func generateRepoStats(repoID int64, kind string, now time.Time) interface{} {
+	rng := rand.New(rand.NewSource(repoID))
+
+	switch kind {
+	case statsCodeFrequency:
+		// [week, additions, deletions] with deletions negative, oldest week first
+		weeks := make([][3]int64, statsWeeks)
+		for i, week := range statsWeekStarts(now) {
+			weeks[i] = [3]int64{week.Unix(), int64(rng.Intn(2000)), -int64(rng.Intn(800))}
+		}
+		return weeks
+
+	case statsParticipation:
+		all := make([]int, statsWeeks)
+		ownerCommits := make([]int, statsWeeks)
+		for i := range all {
+			all[i] = rng.Intn(40)
+			ownerCommits[i] = rng.Intn(all[i] + 1)
+		}
+		return map[string][]int{"all": all, "owner": ownerCommits}
+
+	default:
+		// [day, hour, commits] for every hour of the week, Sunday first,
+		// busiest during working hours on weekdays
+		punchCard := make([][3]int, 0, 7*24)
+		for day := 0; day < 7; day++ {
+			for hour := 0; hour < 24; hour++ {
+				commits := rng.Intn(3)
+				if day >= 1 && day <= 5 && hour >= 9 && hour < 18 {
+					commits += rng.Intn(15)
+				}
+				punchCard = append(punchCard, [3]int{day, hour, commits})
+			}
+		}
+		return punchCard
+	}
+}
+
+// statsWeekStarts returns the starts of the last statsWeeks weeks, oldest
+// first, ending with the week containing now. Weeks start on Sunday at midnight UTC.
+func statsWeekStarts(now time.Time) []time.Time {
+	now = now.UTC()
+	current := time.Date(now.Year(), now.Month(), now.Day()-int(now.Weekday()), 0, 0, 0, 0, time.UTC)
+	weeks := make([]time.Time, statsWeeks)
+	for i := range weeks {
+		weeks[i] = current.AddDate(0, 0, -7*(statsWeeks-1-i))
+	}
+	return weeks
+}
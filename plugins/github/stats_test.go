@@ -0,0 +1,92 @@
+// ABOUTME: Tests for repository statistics endpoints
+// ABOUTME: Verifies the 202 then 200 progression, cached data shapes, and per-repository determinism
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRepoStatsAcceptedThenCached(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	for _, kind := range []string{statsCodeFrequency, statsParticipation, statsPunchCard} {
+		path := "/repos/alice/test-repo/stats/" + kind
+		if w := serveGitHub(plugin, "GET", path, ""); w.Code != http.StatusAccepted {
+			t.Fatalf("%s: expected 202 on the first request, got %d: %s", kind, w.Code, w.Body.String())
+		}
+		w := serveGitHub(plugin, "GET", path, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200 once computed, got %d: %s", kind, w.Code, w.Body.String())
+		}
+
+		switch kind {
+		case statsCodeFrequency:
+			var weeks [][3]int64
+			if err := json.Unmarshal(w.Body.Bytes(), &weeks); err != nil {
+				t.Fatalf("code_frequency: %v", err)
+			}
+			if len(weeks) != statsWeeks || weeks[0][1] < 0 || weeks[0][2] > 0 {
+				t.Fatalf("unexpected code frequency: %v", weeks)
+			}
+			if time.Unix(weeks[1][0], 0).Sub(time.Unix(weeks[0][0], 0)) != 7*24*time.Hour {
+				t.Errorf("expected weekly buckets, got %d then %d", weeks[0][0], weeks[1][0])
+			}
+		case statsParticipation:
+			var participation struct {
+				All   []int `json:"all"`
+				Owner []int `json:"owner"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &participation); err != nil {
+				t.Fatalf("participation: %v", err)
+			}
+			if len(participation.All) != statsWeeks || len(participation.Owner) != statsWeeks {
+				t.Fatalf("expected %d weeks, got %+v", statsWeeks, participation)
+			}
+			for i := range participation.All {
+				if participation.Owner[i] > participation.All[i] {
+					t.Errorf("week %d: owner commits %d exceed all commits %d", i, participation.Owner[i], participation.All[i])
+				}
+			}
+		case statsPunchCard:
+			var punchCard [][3]int
+			if err := json.Unmarshal(w.Body.Bytes(), &punchCard); err != nil {
+				t.Fatalf("punch_card: %v", err)
+			}
+			if len(punchCard) != 7*24 || punchCard[25] != [3]int{1, 1, punchCard[25][2]} {
+				t.Fatalf("unexpected punch card: %v", punchCard)
+			}
+		}
+	}
+
+	if w := serveGitHub(plugin, "GET", "/repos/alice/missing/stats/punch_card", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing repository, got %d", w.Code)
+	}
+}
+
+func TestRepoStatsDeterministic(t *testing.T) {
+	now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	first, _ := json.Marshal(generateRepoStats(7, statsPunchCard, now))
+	again, _ := json.Marshal(generateRepoStats(7, statsPunchCard, now))
+	other, _ := json.Marshal(generateRepoStats(8, statsPunchCard, now))
+	if string(first) != string(again) {
+		t.Error("expected the same repository to get the same stats")
+	}
+	if string(first) == string(other) {
+		t.Error("expected different repositories to get different stats")
+	}
+
+	weeks := statsWeekStarts(now)
+	if last := weeks[len(weeks)-1]; !last.Equal(time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the last week to start on Sunday 2024-03-10, got %v", last)
+	}
+}
@@ -0,0 +1,470 @@
+// ABOUTME: Commit status and check run endpoints for GitHub repositories
+// ABOUTME: Handles per-context statuses, the combined status rollup, and check run lifecycle
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Commit status states
+var statusStates = []string{"error", "failure", "pending", "success"}
+
+// Check run statuses and conclusions
+var (
+	checkRunStatuses    = []string{"queued", "in_progress", "completed"}
+	checkRunConclusions = []string{"action_required", "cancelled", "failure", "neutral", "success", "skipped", "stale", "timed_out"}
+)
+
+var fullSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// combinedState rolls up the latest status per context: any error or failure
+// fails the commit, success requires every context to succeed, and anything
+// else (including no statuses) is pending.
+func combinedState(statuses []*CommitStatus) string {
+	if len(statuses) == 0 {
+		return "pending"
+	}
+	state := "success"
+	for _, status := range statuses {
+		switch status.State {
+		case "error", "failure":
+			return "failure"
+		case "pending":
+			state = "pending"
+		}
+	}
+	return state
+}
+
+// createCommitStatus handles POST /repos/{owner}/{repo}/statuses/{sha}
+func (p *GitHubPlugin) createCommitStatus(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+
+	var req struct {
+		State       string `json:"state"`
+		TargetURL   string `json:"target_url"`
+		Description string `json:"description"`
+		Context     string `json:"context"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.State == "" {
+		writeValidationError(w, "Status", "state", "missing_field")
+		return
+	}
+	if !slices.Contains(statusStates, req.State) {
+		writeValidationError(w, "Status", "state", "invalid")
+		return
+	}
+
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	sha := chi.URLParam(r, "sha")
+	if !fullSHAPattern.MatchString(sha) {
+		writeError(w, http.StatusUnprocessableEntity, "No commit found for SHA: "+sha)
+		return
+	}
+
+	status, err := p.store.CreateCommitStatus(repo.ID, sha, req.State, req.Context, req.Description, req.TargetURL, user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create status")
+		return
+	}
+
+	response := commitStatusToResponse(status, user, repo)
+	p.fireWebhooksAsync(repo.ID, "status", map[string]interface{}{
+		"id":          status.ID,
+		"sha":         status.SHA,
+		"name":        repo.FullName,
+		"state":       status.State,
+		"context":     status.Context,
+		"description": status.Description,
+		"target_url":  status.TargetURL,
+		"sender":      map[string]interface{}{"login": user.Login, "id": user.ID},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// listCommitStatuses handles GET /repos/{owner}/{repo}/commits/{ref}/statuses
+func (p *GitHubPlugin) listCommitStatuses(w http.ResponseWriter, r *http.Request) {
+	repo, sha, ok := p.resolveCommitRef(w, r)
+	if !ok {
+		return
+	}
+
+	statuses, err := p.store.ListCommitStatuses(repo.ID, sha)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list statuses")
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(statuses))
+	for _, status := range statuses {
+		creator, _ := p.store.GetUserByID(status.CreatorID)
+		response = append(response, commitStatusToResponse(status, creator, repo))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// getCombinedStatus handles GET /repos/{owner}/{repo}/commits/{ref}/status
+func (p *GitHubPlugin) getCombinedStatus(w http.ResponseWriter, r *http.Request) {
+	repo, sha, ok := p.resolveCommitRef(w, r)
+	if !ok {
+		return
+	}
+
+	statuses, err := p.store.LatestCommitStatuses(repo.ID, sha)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get statuses")
+		return
+	}
+
+	items := make([]map[string]interface{}, 0, len(statuses))
+	for _, status := range statuses {
+		creator, _ := p.store.GetUserByID(status.CreatorID)
+		items = append(items, commitStatusToResponse(status, creator, repo))
+	}
+
+	owner, _ := p.store.GetUserByID(repo.OwnerID)
+	base := fmt.Sprintf("/repos/%s/commits/%s", repo.FullName, sha)
+	response := map[string]interface{}{
+		"state":       combinedState(statuses),
+		"statuses":    items,
+		"sha":         sha,
+		"total_count": len(statuses),
+		"repository":  repositoryToResponse(repo, owner),
+		"commit_url":  base,
+		"url":         base + "/status",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// resolveCommitRef looks up the repository and resolves {ref} (a branch name
+// or SHA) to a commit SHA, writing an error response if the repo is missing
+func (p *GitHubPlugin) resolveCommitRef(w http.ResponseWriter, r *http.Request) (*Repository, string, bool) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return nil, "", false
+	}
+
+	sha, err := p.store.ResolveRef(repo.ID, chi.URLParam(r, "ref"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to resolve ref")
+		return nil, "", false
+	}
+
+	return repo, sha, true
+}
+
+func commitStatusToResponse(status *CommitStatus, creator *User, repo *Repository) map[string]interface{} {
+	response := map[string]interface{}{
+		"id":          status.ID,
+		"url":         fmt.Sprintf("/repos/%s/statuses/%s", repo.FullName, status.SHA),
+		"state":       status.State,
+		"description": nilIfEmpty(status.Description),
+		"target_url":  nilIfEmpty(status.TargetURL),
+		"context":     status.Context,
+		"created_at":  status.CreatedAt.Format(time.RFC3339),
+		"updated_at":  status.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if creator != nil {
+		response["creator"] = map[string]interface{}{
+			"login": creator.Login,
+			"id":    creator.ID,
+			"type":  creator.Type,
+		}
+	}
+
+	return response
+}
+
+// checkRunRequest is the body accepted by check run create and update
+type checkRunRequest struct {
+	Name        *string    `json:"name"`
+	HeadSHA     string     `json:"head_sha"`
+	DetailsURL  *string    `json:"details_url"`
+	ExternalID  *string    `json:"external_id"`
+	Status      *string    `json:"status"`
+	Conclusion  *string    `json:"conclusion"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	Output      *struct {
+		Title   string `json:"title"`
+		Summary string `json:"summary"`
+		Text    string `json:"text"`
+	} `json:"output"`
+}
+
+// applyCheckRunRequest copies request fields onto a check run and enforces
+// GitHub's status/conclusion rules. It returns the invalid field name, if any.
+func (p *GitHubPlugin) applyCheckRunRequest(run *CheckRun, req *checkRunRequest) string {
+	if req.Name != nil {
+		run.Name = *req.Name
+	}
+	if req.DetailsURL != nil {
+		run.DetailsURL = *req.DetailsURL
+	}
+	if req.ExternalID != nil {
+		run.ExternalID = *req.ExternalID
+	}
+	if req.Output != nil {
+		run.OutputTitle = req.Output.Title
+		run.OutputSummary = req.Output.Summary
+		run.OutputText = req.Output.Text
+	}
+	if req.StartedAt != nil {
+		run.StartedAt = req.StartedAt
+	}
+
+	if req.Status != nil {
+		if !slices.Contains(checkRunStatuses, *req.Status) {
+			return "status"
+		}
+		run.Status = *req.Status
+	}
+	if req.Conclusion != nil {
+		if !slices.Contains(checkRunConclusions, *req.Conclusion) {
+			return "conclusion"
+		}
+		// Providing a conclusion completes the run
+		run.Conclusion = *req.Conclusion
+		run.Status = "completed"
+	}
+
+	if run.Status == "completed" {
+		if run.Conclusion == "" {
+			return "conclusion"
+		}
+		if req.CompletedAt != nil {
+			run.CompletedAt = req.CompletedAt
+		} else if run.CompletedAt == nil {
+			now := p.store.now()
+			run.CompletedAt = &now
+		}
+	}
+	if run.Status != "queued" && run.StartedAt == nil {
+		now := p.store.now()
+		run.StartedAt = &now
+	}
+
+	return ""
+}
+
+// createCheckRun handles POST /repos/{owner}/{repo}/check-runs
+func (p *GitHubPlugin) createCheckRun(w http.ResponseWriter, r *http.Request) {
+	var req checkRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		writeValidationError(w, "CheckRun", "name", "missing_field")
+		return
+	}
+	if !fullSHAPattern.MatchString(req.HeadSHA) {
+		writeValidationError(w, "CheckRun", "head_sha", "invalid")
+		return
+	}
+
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	run := &CheckRun{RepoID: repo.ID, HeadSHA: req.HeadSHA, Status: "queued"}
+	if field := p.applyCheckRunRequest(run, &req); field != "" {
+		writeValidationError(w, "CheckRun", field, "invalid")
+		return
+	}
+
+	if err := p.store.CreateCheckRun(run); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create check run")
+		return
+	}
+
+	response := checkRunToResponse(run, repo)
+	p.fireWebhooksAsync(repo.ID, "check_run", map[string]interface{}{
+		"action":    "created",
+		"check_run": response,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// getCheckRun handles GET /repos/{owner}/{repo}/check-runs/{check_run_id}
+func (p *GitHubPlugin) getCheckRun(w http.ResponseWriter, r *http.Request) {
+	repo, run, ok := p.lookupCheckRun(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(checkRunToResponse(run, repo))
+}
+
+// updateCheckRun handles PATCH /repos/{owner}/{repo}/check-runs/{check_run_id}
+func (p *GitHubPlugin) updateCheckRun(w http.ResponseWriter, r *http.Request) {
+	var req checkRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	repo, run, ok := p.lookupCheckRun(w, r)
+	if !ok {
+		return
+	}
+
+	wasCompleted := run.Status == "completed"
+	if field := p.applyCheckRunRequest(run, &req); field != "" {
+		writeValidationError(w, "CheckRun", field, "invalid")
+		return
+	}
+
+	if err := p.store.UpdateCheckRun(run); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update check run")
+		return
+	}
+
+	response := checkRunToResponse(run, repo)
+	action := "updated"
+	if !wasCompleted && run.Status == "completed" {
+		action = "completed"
+	}
+	p.fireWebhooksAsync(repo.ID, "check_run", map[string]interface{}{
+		"action":    action,
+		"check_run": response,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// listCheckRunsForRef handles GET /repos/{owner}/{repo}/commits/{ref}/check-runs
+func (p *GitHubPlugin) listCheckRunsForRef(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && !slices.Contains(checkRunStatuses, status) {
+		writeValidationError(w, "CheckRun", "status", "invalid")
+		return
+	}
+
+	repo, sha, ok := p.resolveCommitRef(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := p.store.ListCheckRuns(repo.ID, sha, r.URL.Query().Get("check_name"), status)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list check runs")
+		return
+	}
+
+	items := make([]map[string]interface{}, 0, len(runs))
+	for _, run := range runs {
+		items = append(items, checkRunToResponse(run, repo))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total_count": len(runs),
+		"check_runs":  items,
+	})
+}
+
+// lookupCheckRun resolves the repository and {check_run_id} from the URL,
+// writing an error response if either doesn't exist
+func (p *GitHubPlugin) lookupCheckRun(w http.ResponseWriter, r *http.Request) (*Repository, *CheckRun, bool) {
+	fullName := chi.URLParam(r, "owner") + "/" + chi.URLParam(r, "repo")
+	repo, err := p.store.GetRepositoryByFullName(fullName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return nil, nil, false
+	}
+
+	var id int64
+	if _, err := fmt.Sscanf(chi.URLParam(r, "check_run_id"), "%d", &id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid check run id")
+		return nil, nil, false
+	}
+
+	run, err := p.store.GetCheckRun(repo.ID, id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "check run not found")
+		return nil, nil, false
+	}
+
+	return repo, run, true
+}
+
+func checkRunToResponse(run *CheckRun, repo *Repository) map[string]interface{} {
+	url := fmt.Sprintf("/repos/%s/check-runs/%d", repo.FullName, run.ID)
+	response := map[string]interface{}{
+		"id":          run.ID,
+		"head_sha":    run.HeadSHA,
+		"external_id": run.ExternalID,
+		"url":         url,
+		"details_url": nilIfEmpty(run.DetailsURL),
+		"status":      run.Status,
+		"conclusion":  nilIfEmpty(run.Conclusion),
+		"name":        run.Name,
+		"output": map[string]interface{}{
+			"title":             nilIfEmpty(run.OutputTitle),
+			"summary":           nilIfEmpty(run.OutputSummary),
+			"text":              nilIfEmpty(run.OutputText),
+			"annotations_count": 0,
+			"annotations_url":   url + "/annotations",
+		},
+		"started_at":   nil,
+		"completed_at": nil,
+	}
+
+	if run.StartedAt != nil {
+		response["started_at"] = run.StartedAt.Format(time.RFC3339)
+	}
+	if run.CompletedAt != nil {
+		response["completed_at"] = run.CompletedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+// nilIfEmpty renders empty optional strings as JSON null
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
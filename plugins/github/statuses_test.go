@@ -0,0 +1,149 @@
+// ABOUTME: Tests for GitHub commit status and check run endpoints
+// ABOUTME: Covers the combined status rollup, branch ref resolution, and check run completion
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+const testSHA = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+
+func TestCombinedStatusFailingContext(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	statusParams := map[string]string{"owner": "alice", "repo": "test-repo", "sha": testSHA}
+	refParams := map[string]string{"owner": "alice", "repo": "test-repo", "ref": testSHA}
+
+	combined := func() map[string]interface{} {
+		w := authedRequest(plugin, plugin.getCombinedStatus, "GET", "ghp_alice", "", refParams)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	if state := combined()["state"]; state != "pending" {
+		t.Errorf("Expected pending with no statuses, got %v", state)
+	}
+
+	for _, body := range []string{
+		`{"state": "success", "context": "ci/build"}`,
+		`{"state": "success", "context": "ci/lint"}`,
+	} {
+		w := authedRequest(plugin, plugin.createCommitStatus, "POST", "ghp_alice", body, statusParams)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	if state := combined()["state"]; state != "success" {
+		t.Errorf("Expected success when all contexts pass, got %v", state)
+	}
+
+	// One failing context fails the rollup
+	authedRequest(plugin, plugin.createCommitStatus, "POST", "ghp_alice",
+		`{"state": "failure", "context": "ci/test", "description": "3 tests failed"}`, statusParams)
+	resp := combined()
+	if resp["state"] != "failure" {
+		t.Errorf("Expected failure with a failing context, got %v", resp["state"])
+	}
+	if resp["total_count"] != float64(3) {
+		t.Errorf("Expected 3 contexts, got %v", resp["total_count"])
+	}
+
+	// A newer status for the same context replaces the old one in the rollup
+	authedRequest(plugin, plugin.createCommitStatus, "POST", "ghp_alice",
+		`{"state": "success", "context": "ci/test"}`, statusParams)
+	resp = combined()
+	if resp["state"] != "success" || resp["total_count"] != float64(3) {
+		t.Errorf("Expected success across 3 contexts after retry, got %v (%v)", resp["state"], resp["total_count"])
+	}
+
+	w := authedRequest(plugin, plugin.listCommitStatuses, "GET", "ghp_alice", "", refParams)
+	var all []map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&all)
+	if len(all) != 4 {
+		t.Errorf("Expected all 4 statuses in history, got %d", len(all))
+	}
+}
+
+func TestCreateCommitStatusValidation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	params := map[string]string{"owner": "alice", "repo": "test-repo", "sha": testSHA}
+	w := authedRequest(plugin, plugin.createCommitStatus, "POST", "ghp_alice", `{"state": "passed"}`, params)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for invalid state, got %d", w.Code)
+	}
+
+	params["sha"] = "main"
+	w = authedRequest(plugin, plugin.createCommitStatus, "POST", "ghp_alice", `{"state": "success"}`, params)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for non-SHA, got %d", w.Code)
+	}
+}
+
+func TestCheckRunLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	db.Exec(`INSERT INTO github_branches (repo_id, name, commit_sha) VALUES (?, 'main', ?)`, repo.ID, testSHA)
+
+	params := map[string]string{"owner": "alice", "repo": "test-repo"}
+	w := authedRequest(plugin, plugin.createCheckRun, "POST", "ghp_alice",
+		`{"name": "tests", "head_sha": "`+testSHA+`", "status": "in_progress"}`, params)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var run map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&run)
+	if run["status"] != "in_progress" || run["conclusion"] != nil || run["started_at"] == nil {
+		t.Errorf("Unexpected new check run: %v", run)
+	}
+
+	// Completing without a conclusion is invalid
+	params["check_run_id"] = "1"
+	w = authedRequest(plugin, plugin.updateCheckRun, "PATCH", "ghp_alice", `{"status": "completed"}`, params)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422, got %d", w.Code)
+	}
+
+	w = authedRequest(plugin, plugin.updateCheckRun, "PATCH", "ghp_alice",
+		`{"conclusion": "failure", "output": {"title": "2 failures", "summary": "See logs"}}`, params)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	json.NewDecoder(w.Body).Decode(&run)
+	if run["status"] != "completed" || run["conclusion"] != "failure" || run["completed_at"] == nil {
+		t.Errorf("Expected completed failure, got %v", run)
+	}
+
+	// Check runs can be listed by branch name
+	w = authedRequest(plugin, plugin.listCheckRunsForRef, "GET", "ghp_alice", "",
+		map[string]string{"owner": "alice", "repo": "test-repo", "ref": "main"})
+	var list map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&list)
+	if list["total_count"] != float64(1) {
+		t.Errorf("Expected 1 check run for main, got %v", list["total_count"])
+	}
+}
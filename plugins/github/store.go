@@ -7,14 +7,18 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 type GitHubStore struct {
-	db *sql.DB
+	db    *sql.DB
+	clock core.Clock
 }
 
 type User struct {
@@ -52,9 +56,11 @@ type Repository struct {
 	WatchersCount   int
 	ForksCount      int
 	OpenIssuesCount int
+	Language        string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	PushedAt        *time.Time
+	Topics          []string
 }
 
 type Issue struct {
@@ -70,6 +76,7 @@ type Issue struct {
 	LabelIDs      string
 	MilestoneID   *int64
 	Locked        bool
+	LockReason    string
 	CommentsCount int
 	IsPullRequest bool
 	CreatedAt     time.Time
@@ -89,6 +96,8 @@ type PullRequest struct {
 	MergeCommitSHA        string
 	MergedAt              *time.Time
 	MergedByID            *int64
+	BaseSHA               string
+	MergeableState        string
 	Draft                 bool
 	ReviewCommentsCount   int
 	CommitsCount          int
@@ -106,6 +115,20 @@ type Comment struct {
 	UpdatedAt time.Time
 }
 
+// IssueEvent is an entry in an issue's timeline, such as it being locked
+type IssueEvent struct {
+	ID         int64
+	IssueID    int64
+	ActorID    int64
+	Event      string
+	LockReason string
+	// CommitID and SourceIssueID record the commit or merged pull request
+	// that closed the issue with a closing keyword
+	CommitID      string
+	SourceIssueID *int64
+	CreatedAt     time.Time
+}
+
 type Review struct {
 	ID            int64
 	PullRequestID int64
@@ -117,6 +140,166 @@ type Review struct {
 	DismissedAt   *time.Time
 }
 
+type Reaction struct {
+	ID          int64
+	SubjectType string
+	SubjectID   int64
+	UserID      int64
+	Content     string
+	CreatedAt   time.Time
+}
+
+type CommitStatus struct {
+	ID          int64
+	RepoID      int64
+	SHA         string
+	State       string
+	Context     string
+	Description string
+	TargetURL   string
+	CreatorID   int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type CheckRun struct {
+	ID            int64
+	RepoID        int64
+	HeadSHA       string
+	Name          string
+	Status        string
+	Conclusion    string
+	DetailsURL    string
+	ExternalID    string
+	OutputTitle   string
+	OutputSummary string
+	OutputText    string
+	StartedAt     *time.Time
+	CompletedAt   *time.Time
+}
+
+// Collaborator is a user given access to a repository they don't own
+type Collaborator struct {
+	User
+	Permission string
+}
+
+// SecretScanningAlert is a leaked secret found in a repository. Secret holds
+// the masked value, never the original.
+type SecretScanningAlert struct {
+	ID           int64
+	RepoID       int64
+	Number       int
+	State        string
+	SecretType   string
+	Secret       string
+	Resolution   string
+	ResolvedByID int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	ResolvedAt   *time.Time
+}
+
+// Workflow is a GitHub Actions workflow file in a repository
+type Workflow struct {
+	ID        int64
+	RepoID    int64
+	Name      string
+	Path      string
+	State     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WorkflowRun is one run of a workflow. Conclusion is empty until the run completes.
+type WorkflowRun struct {
+	ID          int64
+	RepoID      int64
+	WorkflowID  int64
+	RunNumber   int64
+	Event       string
+	HeadBranch  string
+	HeadSHA     string
+	Status      string
+	Conclusion  string
+	ActorID     int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	// Inputs is the JSON object a workflow_dispatch run was started with, or
+	// empty for other events
+	Inputs string
+}
+
+// RepositoryDispatchEvent is a custom event triggered through the
+// repository dispatch endpoint. ClientPayload is a JSON object.
+type RepositoryDispatchEvent struct {
+	ID            int64
+	RepoID        int64
+	EventType     string
+	ClientPayload string
+	CreatedAt     time.Time
+}
+
+// BranchProtection is the protection configured for one branch. Required
+// reviews and status checks are only enforced when their Require flag is set.
+type BranchProtection struct {
+	ID                           int64
+	RepoID                       int64
+	Branch                       string
+	RequireReviews               bool
+	RequiredApprovingReviewCount int
+	DismissStaleReviews          bool
+	RequireCodeOwnerReviews      bool
+	RequireStatusChecks          bool
+	StrictStatusChecks           bool
+	StatusCheckContexts          []string
+	EnforceAdmins                bool
+	CreatedAt                    time.Time
+	UpdatedAt                    time.Time
+}
+
+// Repository template types
+const (
+	TemplateTypeIssue       = "issue"
+	TemplateTypePullRequest = "pull_request"
+)
+
+// RepoTemplate is an issue or pull request template. The default template
+// for a type has an empty Name; named issue templates are the files in
+// .github/ISSUE_TEMPLATE/.
+type RepoTemplate struct {
+	RepoID    int64
+	Type      string
+	Name      string
+	Content   string
+	UpdatedAt time.Time
+}
+
+// RepoFile is a file written through the contents API. Deleted files are
+// kept so they also hide a template or workflow at the same path.
+type RepoFile struct {
+	RepoID  int64
+	Path    string
+	Content string
+	Deleted bool
+}
+
+// GitCommit is a commit on a repository's default branch
+type GitCommit struct {
+	SHA            string
+	RepoID         int64
+	AuthorLogin    string
+	AuthorName     string
+	AuthorEmail    string
+	CommitterLogin string
+	Message        string
+	ParentSHA      string
+	TreeSHA        string
+	CreatedAt      time.Time
+}
+
 type Webhook struct {
 	ID          int64
 	RepoID      int64
@@ -125,240 +308,82 @@ type Webhook struct {
 	Secret      string
 	Events      string
 	Active      bool
+	DelayMs     int
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+// Webhook delivery states. A failed delivery is retried at NextRetryAt until
+// it has made maxDeliveryAttempts attempts, when it is exhausted.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+	DeliveryStatusExhausted = "exhausted"
+)
+
+// WebhookDelivery is one event queued for a webhook. DeliveredAt is the time
+// of the latest attempt and is nil until the first one.
 type WebhookDelivery struct {
 	ID           int64
 	WebhookID    int64
 	EventType    string
 	Payload      string
-	DeliveredAt  time.Time
+	Status       string
+	Attempts     int
+	NextRetryAt  *time.Time
+	Redelivery   bool
+	DeliveredAt  *time.Time
 	StatusCode   int
 	ErrorMessage string
 }
 
 func NewGitHubStore(db *sql.DB) (*GitHubStore, error) {
-	store := &GitHubStore{db: db}
-	if err := store.initTables(); err != nil {
-		return nil, err
-	}
-	return store, nil
-}
-
-func (s *GitHubStore) initTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS github_users (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			login TEXT UNIQUE NOT NULL,
-			name TEXT,
-			email TEXT,
-			avatar_url TEXT,
-			type TEXT DEFAULT 'User',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_users_login ON github_users(login)`,
-
-		`CREATE TABLE IF NOT EXISTS github_tokens (
-			token TEXT PRIMARY KEY,
-			user_id INTEGER NOT NULL,
-			token_type TEXT DEFAULT 'personal',
-			scopes TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (user_id) REFERENCES github_users(id)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_tokens_user ON github_tokens(user_id)`,
-
-		`CREATE TABLE IF NOT EXISTS github_repositories (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			owner_id INTEGER NOT NULL,
-			name TEXT NOT NULL,
-			full_name TEXT NOT NULL,
-			description TEXT,
-			private INTEGER DEFAULT 0,
-			default_branch TEXT DEFAULT 'main',
-			fork INTEGER DEFAULT 0,
-			archived INTEGER DEFAULT 0,
-			disabled INTEGER DEFAULT 0,
-			stargazers_count INTEGER DEFAULT 0,
-			watchers_count INTEGER DEFAULT 0,
-			forks_count INTEGER DEFAULT 0,
-			open_issues_count INTEGER DEFAULT 0,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			pushed_at TIMESTAMP,
-			FOREIGN KEY (owner_id) REFERENCES github_users(id),
-			UNIQUE(owner_id, name)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_repos_owner ON github_repositories(owner_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_repos_full_name ON github_repositories(full_name)`,
-
-		`CREATE TABLE IF NOT EXISTS github_branches (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			repo_id INTEGER NOT NULL,
-			name TEXT NOT NULL,
-			commit_sha TEXT NOT NULL,
-			protected INTEGER DEFAULT 0,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
-			UNIQUE(repo_id, name)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_branches_repo ON github_branches(repo_id)`,
-
-		`CREATE TABLE IF NOT EXISTS github_commits (
-			sha TEXT PRIMARY KEY,
-			repo_id INTEGER NOT NULL,
-			author_login TEXT,
-			author_name TEXT NOT NULL,
-			author_email TEXT NOT NULL,
-			committer_login TEXT,
-			message TEXT NOT NULL,
-			parent_sha TEXT,
-			tree_sha TEXT NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_commits_repo ON github_commits(repo_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_commits_parent ON github_commits(parent_sha)`,
-
-		`CREATE TABLE IF NOT EXISTS github_issues (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			repo_id INTEGER NOT NULL,
-			number INTEGER NOT NULL,
-			title TEXT NOT NULL,
-			body TEXT,
-			state TEXT DEFAULT 'open',
-			state_reason TEXT,
-			user_id INTEGER NOT NULL,
-			assignee_ids TEXT,
-			label_ids TEXT,
-			milestone_id INTEGER,
-			locked INTEGER DEFAULT 0,
-			comments_count INTEGER DEFAULT 0,
-			is_pull_request INTEGER DEFAULT 0,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			closed_at TIMESTAMP,
-			FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE,
-			FOREIGN KEY (user_id) REFERENCES github_users(id),
-			UNIQUE(repo_id, number)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_issues_repo ON github_issues(repo_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_issues_state ON github_issues(state)`,
-		`CREATE INDEX IF NOT EXISTS idx_issues_number ON github_issues(repo_id, number)`,
-
-		`CREATE TABLE IF NOT EXISTS github_pull_requests (
-			issue_id INTEGER PRIMARY KEY,
-			head_repo_id INTEGER NOT NULL,
-			head_ref TEXT NOT NULL,
-			base_repo_id INTEGER NOT NULL,
-			base_ref TEXT NOT NULL,
-			merged INTEGER DEFAULT 0,
-			mergeable INTEGER DEFAULT 1,
-			rebaseable INTEGER DEFAULT 1,
-			merge_commit_sha TEXT,
-			merged_at TIMESTAMP,
-			merged_by_id INTEGER,
-			draft INTEGER DEFAULT 0,
-			review_comments_count INTEGER DEFAULT 0,
-			commits_count INTEGER DEFAULT 1,
-			additions INTEGER DEFAULT 0,
-			deletions INTEGER DEFAULT 0,
-			changed_files INTEGER DEFAULT 0,
-			FOREIGN KEY (issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
-			FOREIGN KEY (head_repo_id) REFERENCES github_repositories(id),
-			FOREIGN KEY (base_repo_id) REFERENCES github_repositories(id),
-			FOREIGN KEY (merged_by_id) REFERENCES github_users(id)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_prs_head_repo ON github_pull_requests(head_repo_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_prs_base_repo ON github_pull_requests(base_repo_id)`,
-
-		`CREATE TABLE IF NOT EXISTS github_comments (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			issue_id INTEGER NOT NULL,
-			user_id INTEGER NOT NULL,
-			body TEXT NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (issue_id) REFERENCES github_issues(id) ON DELETE CASCADE,
-			FOREIGN KEY (user_id) REFERENCES github_users(id)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_comments_issue ON github_comments(issue_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_comments_created ON github_comments(created_at)`,
-
-		`CREATE TABLE IF NOT EXISTS github_reviews (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			pull_request_id INTEGER NOT NULL,
-			user_id INTEGER NOT NULL,
-			state TEXT NOT NULL,
-			body TEXT,
-			commit_sha TEXT,
-			submitted_at TIMESTAMP,
-			dismissed_at TIMESTAMP,
-			FOREIGN KEY (pull_request_id) REFERENCES github_issues(id) ON DELETE CASCADE,
-			FOREIGN KEY (user_id) REFERENCES github_users(id)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_reviews_pr ON github_reviews(pull_request_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_reviews_state ON github_reviews(state)`,
-
-		`CREATE TABLE IF NOT EXISTS github_review_comments (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			pull_request_id INTEGER NOT NULL,
-			review_id INTEGER,
-			user_id INTEGER NOT NULL,
-			body TEXT NOT NULL,
-			path TEXT NOT NULL,
-			position INTEGER,
-			commit_sha TEXT NOT NULL,
-			in_reply_to_id INTEGER,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (pull_request_id) REFERENCES github_issues(id) ON DELETE CASCADE,
-			FOREIGN KEY (review_id) REFERENCES github_reviews(id) ON DELETE CASCADE,
-			FOREIGN KEY (user_id) REFERENCES github_users(id),
-			FOREIGN KEY (in_reply_to_id) REFERENCES github_review_comments(id)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_review_comments_pr ON github_review_comments(pull_request_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_review_comments_review ON github_review_comments(review_id)`,
-
-		`CREATE TABLE IF NOT EXISTS github_webhooks (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			repo_id INTEGER NOT NULL,
-			url TEXT NOT NULL,
-			content_type TEXT DEFAULT 'json',
-			secret TEXT,
-			events TEXT NOT NULL,
-			active INTEGER DEFAULT 1,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (repo_id) REFERENCES github_repositories(id) ON DELETE CASCADE
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_webhooks_repo ON github_webhooks(repo_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_webhooks_active ON github_webhooks(active)`,
-
-		`CREATE TABLE IF NOT EXISTS github_webhook_deliveries (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			webhook_id INTEGER NOT NULL,
-			event_type TEXT NOT NULL,
-			payload TEXT NOT NULL,
-			delivered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			status_code INTEGER,
-			error_message TEXT,
-			FOREIGN KEY (webhook_id) REFERENCES github_webhooks(id) ON DELETE CASCADE
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_deliveries_webhook ON github_webhook_deliveries(webhook_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_deliveries_delivered ON github_webhook_deliveries(delivered_at DESC)`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to create tables: %w", err)
+	return &GitHubStore{db: db}, nil
+}
+
+// SetClock overrides the clock used for timestamps (nil uses core.Now)
+func (s *GitHubStore) SetClock(c core.Clock) {
+	s.clock = c
+}
+
+// now returns the current time from the store's clock
+func (s *GitHubStore) now() time.Time {
+	if s.clock != nil {
+		return s.clock.Now()
+	}
+	return core.Now()
+}
+
+// auditor is what audit rows are written through: the store's database, or
+// the transaction making the change so both commit together
+type auditor interface {
+	core.Execer
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// audit records a mutation of a GitHub resource in the shared audit log. The
+// actor is recorded by login; an actorID of 0 means the actor is unknown.
+func (s *GitHubStore) audit(resourceType string, resourceID int64, action string, actorID int64, changes any) error {
+	return auditIn(s.db, resourceType, resourceID, action, actorID, changes)
+}
+
+// auditIn is audit within q, usually the transaction making the change
+func auditIn(q auditor, resourceType string, resourceID int64, action string, actorID int64, changes any) error {
+	var actor string
+	if actorID != 0 {
+		if err := q.QueryRow(`SELECT login FROM github_users WHERE id = ?`, actorID).Scan(&actor); err != nil && err != sql.ErrNoRows {
+			return err
 		}
 	}
-	return nil
+	return core.LogAudit(q, core.AuditEntry{
+		PluginName:   "github",
+		ResourceType: resourceType,
+		ResourceID:   fmt.Sprint(resourceID),
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
 }
 
 // GetOrCreateUser retrieves or creates a user (auto-accept pattern)
@@ -384,10 +409,11 @@ func (s *GitHubStore) GetOrCreateUser(login, token string) (*User, error) {
 		}
 
 		// User exists, create token if not exists
+		now := s.now()
 		_, err = s.db.Exec(`
 			INSERT OR IGNORE INTO github_tokens (token, user_id, token_type, created_at, last_used_at)
-			VALUES (?, ?, 'personal', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		`, token, user.ID)
+			VALUES (?, ?, 'personal', ?, ?)
+		`, token, user.ID, now, now)
 		if err != nil {
 			return nil, err
 		}
@@ -399,10 +425,11 @@ func (s *GitHubStore) GetOrCreateUser(login, token string) (*User, error) {
 	}
 
 	// Create new user
+	now := s.now()
 	result, err := s.db.Exec(`
 		INSERT INTO github_users (login, type, created_at, updated_at)
-		VALUES (?, 'User', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-	`, login)
+		VALUES (?, 'User', ?, ?)
+	`, login, now, now)
 	if err != nil {
 		return nil, err
 	}
@@ -415,8 +442,8 @@ func (s *GitHubStore) GetOrCreateUser(login, token string) (*User, error) {
 	// Create token
 	_, err = s.db.Exec(`
 		INSERT INTO github_tokens (token, user_id, token_type, created_at, last_used_at)
-		VALUES (?, ?, 'personal', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-	`, token, userID)
+		VALUES (?, ?, 'personal', ?, ?)
+	`, token, userID, now, now)
 	if err != nil {
 		return nil, err
 	}
@@ -425,14 +452,15 @@ func (s *GitHubStore) GetOrCreateUser(login, token string) (*User, error) {
 		ID:        userID,
 		Login:     login,
 		Type:      "User",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	return &user, nil
 }
 
-// ValidateToken checks if token exists and returns associated user
+// ValidateToken checks if token exists and hasn't expired, and returns
+// associated user
 func (s *GitHubStore) ValidateToken(token string) (*User, error) {
 	var user User
 	var name, email, avatarURL sql.NullString
@@ -440,8 +468,8 @@ func (s *GitHubStore) ValidateToken(token string) (*User, error) {
 		SELECT u.id, u.login, u.name, u.email, u.avatar_url, u.type, u.created_at, u.updated_at
 		FROM github_users u
 		JOIN github_tokens t ON u.id = t.user_id
-		WHERE t.token = ?
-	`, token).Scan(&user.ID, &user.Login, &name, &email, &avatarURL, &user.Type, &user.CreatedAt, &user.UpdatedAt)
+		WHERE t.token = ? AND (t.expires_at IS NULL OR t.expires_at > ?)
+	`, token, s.now()).Scan(&user.ID, &user.Login, &name, &email, &avatarURL, &user.Type, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return nil, err
@@ -459,7 +487,7 @@ func (s *GitHubStore) ValidateToken(token string) (*User, error) {
 	}
 
 	// Update last_used_at
-	_, err = s.db.Exec(`UPDATE github_tokens SET last_used_at = CURRENT_TIMESTAMP WHERE token = ?`, token)
+	_, err = s.db.Exec(`UPDATE github_tokens SET last_used_at = ? WHERE token = ?`, s.now(), token)
 	if err != nil {
 		// Log but don't fail validation since user was already authenticated
 		// Token tracking is best-effort
@@ -514,7 +542,7 @@ func (s *GitHubStore) CreateRepository(ownerID int64, name, description string,
 	}
 	fullName := fmt.Sprintf("%s/%s", ownerLogin, name)
 
-	now := time.Now()
+	now := s.now()
 	result, err := s.db.Exec(`
 		INSERT INTO github_repositories (owner_id, name, full_name, description, private, default_branch, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, 'main', ?, ?)
@@ -529,6 +557,10 @@ func (s *GitHubStore) CreateRepository(ownerID int64, name, description string,
 		return nil, err
 	}
 
+	if err := s.audit("repository", id, core.AuditCreate, ownerID, map[string]any{"full_name": fullName, "private": private}); err != nil {
+		return nil, err
+	}
+
 	return &Repository{
 		ID:            id,
 		OwnerID:       ownerID,
@@ -551,14 +583,14 @@ func (s *GitHubStore) GetRepository(ownerID int64, name string) (*Repository, er
 	err := s.db.QueryRow(`
 		SELECT id, owner_id, name, full_name, description, private, default_branch, fork, archived, disabled,
 			stargazers_count, watchers_count, forks_count, open_issues_count,
-			created_at, updated_at, pushed_at
+			created_at, updated_at, pushed_at, COALESCE(language, '')
 		FROM github_repositories
 		WHERE owner_id = ? AND name = ?
 	`, ownerID, name).Scan(
 		&repo.ID, &repo.OwnerID, &repo.Name, &repo.FullName, &description, &repo.Private,
 		&repo.DefaultBranch, &repo.Fork, &repo.Archived, &repo.Disabled,
 		&repo.StargazersCount, &repo.WatchersCount, &repo.ForksCount, &repo.OpenIssuesCount,
-		&repo.CreatedAt, &repo.UpdatedAt, &pushedAt,
+		&repo.CreatedAt, &repo.UpdatedAt, &pushedAt, &repo.Language,
 	)
 
 	if err != nil {
@@ -571,6 +603,9 @@ func (s *GitHubStore) GetRepository(ownerID int64, name string) (*Repository, er
 	if pushedAt.Valid {
 		repo.PushedAt = &pushedAt.Time
 	}
+	if err := s.attachTopics(&repo); err != nil {
+		return nil, err
+	}
 
 	return &repo, nil
 }
@@ -584,14 +619,14 @@ func (s *GitHubStore) GetRepositoryByFullName(fullName string) (*Repository, err
 	err := s.db.QueryRow(`
 		SELECT id, owner_id, name, full_name, description, private, default_branch, fork, archived, disabled,
 			stargazers_count, watchers_count, forks_count, open_issues_count,
-			created_at, updated_at, pushed_at
+			created_at, updated_at, pushed_at, COALESCE(language, '')
 		FROM github_repositories
 		WHERE full_name = ?
 	`, fullName).Scan(
 		&repo.ID, &repo.OwnerID, &repo.Name, &repo.FullName, &description, &repo.Private,
 		&repo.DefaultBranch, &repo.Fork, &repo.Archived, &repo.Disabled,
 		&repo.StargazersCount, &repo.WatchersCount, &repo.ForksCount, &repo.OpenIssuesCount,
-		&repo.CreatedAt, &repo.UpdatedAt, &pushedAt,
+		&repo.CreatedAt, &repo.UpdatedAt, &pushedAt, &repo.Language,
 	)
 
 	if err != nil {
@@ -604,6 +639,9 @@ func (s *GitHubStore) GetRepositoryByFullName(fullName string) (*Repository, err
 	if pushedAt.Valid {
 		repo.PushedAt = &pushedAt.Time
 	}
+	if err := s.attachTopics(&repo); err != nil {
+		return nil, err
+	}
 
 	return &repo, nil
 }
@@ -613,7 +651,7 @@ func (s *GitHubStore) ListUserRepositories(ownerID int64) ([]*Repository, error)
 	rows, err := s.db.Query(`
 		SELECT id, owner_id, name, full_name, description, private, default_branch, fork, archived, disabled,
 			stargazers_count, watchers_count, forks_count, open_issues_count,
-			created_at, updated_at, pushed_at
+			created_at, updated_at, pushed_at, COALESCE(language, '')
 		FROM github_repositories
 		WHERE owner_id = ?
 		ORDER BY created_at DESC
@@ -634,7 +672,7 @@ func (s *GitHubStore) ListUserRepositories(ownerID int64) ([]*Repository, error)
 			&repo.ID, &repo.OwnerID, &repo.Name, &repo.FullName, &description, &repo.Private,
 			&repo.DefaultBranch, &repo.Fork, &repo.Archived, &repo.Disabled,
 			&repo.StargazersCount, &repo.WatchersCount, &repo.ForksCount, &repo.OpenIssuesCount,
-			&repo.CreatedAt, &repo.UpdatedAt, &pushedAt,
+			&repo.CreatedAt, &repo.UpdatedAt, &pushedAt, &repo.Language,
 		)
 		if err != nil {
 			return nil, err
@@ -650,12 +688,240 @@ func (s *GitHubStore) ListUserRepositories(ownerID int64) ([]*Repository, error)
 		repos = append(repos, &repo)
 	}
 
-	return repos, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	return repos, s.attachTopics(repos...)
+}
+
+// GetRepositoryByID gets a repository by ID
+func (s *GitHubStore) GetRepositoryByID(id int64) (*Repository, error) {
+	var repo Repository
+	var description sql.NullString
+	var pushedAt sql.NullTime
+
+	err := s.db.QueryRow(`
+		SELECT id, owner_id, name, full_name, description, private, default_branch, fork, archived, disabled,
+			stargazers_count, watchers_count, forks_count, open_issues_count,
+			created_at, updated_at, pushed_at, COALESCE(language, '')
+		FROM github_repositories
+		WHERE id = ?
+	`, id).Scan(
+		&repo.ID, &repo.OwnerID, &repo.Name, &repo.FullName, &description, &repo.Private,
+		&repo.DefaultBranch, &repo.Fork, &repo.Archived, &repo.Disabled,
+		&repo.StargazersCount, &repo.WatchersCount, &repo.ForksCount, &repo.OpenIssuesCount,
+		&repo.CreatedAt, &repo.UpdatedAt, &pushedAt, &repo.Language,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		repo.Description = description.String
+	}
+	if pushedAt.Valid {
+		repo.PushedAt = &pushedAt.Time
+	}
+	if err := s.attachTopics(&repo); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// ForkRepository creates a fork of sourceRepo owned by ownerID under the
+// source's name. See ForkRepositoryNamed.
+func (s *GitHubStore) ForkRepository(ownerID int64, sourceRepo *Repository) (*Repository, error) {
+	return s.ForkRepositoryNamed(ownerID, sourceRepo, "")
+}
+
+// ForkRepositoryNamed creates a fork of source owned by ownerID, copying its
+// description, default branch, and topics, records source in the fork's
+// fork_of, and increments the source's forks_count. If name is empty the
+// source name is used; a numeric suffix is appended when the owner already
+// has a repository with that name (e.g. when forking your own repository).
+// Uses a transaction so the fork and the count update are atomic
+func (s *GitHubStore) ForkRepositoryNamed(ownerID int64, source *Repository, name string) (*Repository, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // Will be no-op if tx.Commit() succeeds
+
+	var ownerLogin string
+	if err := tx.QueryRow(`SELECT login FROM github_users WHERE id = ?`, ownerID).Scan(&ownerLogin); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = source.Name
+	}
+	baseName := name
+	for i := 1; ; i++ {
+		var exists int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM github_repositories WHERE owner_id = ? AND name = ?`, ownerID, name).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", baseName, i)
+	}
+	fullName := fmt.Sprintf("%s/%s", ownerLogin, name)
+
+	now := s.now()
+	result, err := tx.Exec(`
+		INSERT INTO github_repositories (owner_id, name, full_name, description, private, default_branch, fork, fork_of, created_at, updated_at, pushed_at)
+		VALUES (?, ?, ?, ?, ?, ?, 1, ?, ?, ?, ?)
+	`, ownerID, name, fullName, source.Description, source.Private, source.DefaultBranch, source.ID, now, now, source.PushedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	forkID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the default branch so the fork starts at the same commit
+	_, err = tx.Exec(`
+		INSERT INTO github_branches (repo_id, name, commit_sha, protected, created_at)
+		SELECT ?, name, commit_sha, 0, ?
+		FROM github_branches
+		WHERE repo_id = ? AND name = ?
+	`, forkID, now, source.ID, source.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO github_repo_topics (repo_id, topic, position)
+		SELECT ?, topic, position
+		FROM github_repo_topics
+		WHERE repo_id = ?
+	`, forkID, source.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE github_repositories
+		SET forks_count = forks_count + 1
+		WHERE id = ?
+	`, source.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditIn(tx, "repository", forkID, core.AuditCreate, ownerID, map[string]any{
+		"full_name": fullName,
+		"parent_id": source.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetRepositoryByID(forkID)
+}
+
+// forkSortOrders maps the sort parameter of GET /repos/{owner}/{repo}/forks to ORDER BY clauses
+var forkSortOrders = map[string]string{
+	"newest":     "r.created_at DESC, r.id DESC",
+	"oldest":     "r.created_at ASC, r.id ASC",
+	"stargazers": "r.stargazers_count DESC, r.id DESC",
+	"watchers":   "r.watchers_count DESC, r.id DESC",
+}
+
+// ListForks lists the direct forks of a repository. sort is one of newest
+// (the default), oldest, stargazers, or watchers
+func (s *GitHubStore) ListForks(parentID int64, sort string) ([]*Repository, error) {
+	order, ok := forkSortOrders[sort]
+	if !ok {
+		order = forkSortOrders["newest"]
+	}
+
+	// Forks are loaded one at a time, the same way as a single repository, so
+	// both responses carry the same fields
+	rows, err := s.db.Query(`
+		SELECT r.id
+		FROM github_repositories r
+		WHERE r.fork_of = ?
+		ORDER BY `+order, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	repos := make([]*Repository, 0, len(ids))
+	for _, id := range ids {
+		repo, err := s.GetRepositoryByID(id)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// GetForkLineage returns the parent and source repository IDs of a fork. The
+// source is the root of the fork network, found by following fork_of.
+// Returns sql.ErrNoRows if the repository is not a fork
+func (s *GitHubStore) GetForkLineage(repoID int64) (parentID, sourceID int64, err error) {
+	var forkOf sql.NullInt64
+	if err := s.db.QueryRow(`SELECT fork_of FROM github_repositories WHERE id = ?`, repoID).Scan(&forkOf); err != nil {
+		return 0, 0, err
+	}
+	if !forkOf.Valid {
+		return 0, 0, sql.ErrNoRows
+	}
+
+	err = s.db.QueryRow(`
+		WITH RECURSIVE upstream(id, fork_of, depth) AS (
+			SELECT id, fork_of, 0 FROM github_repositories WHERE id = ?
+			UNION ALL
+			SELECT r.id, r.fork_of, u.depth + 1
+			FROM github_repositories r JOIN upstream u ON r.id = u.fork_of
+		)
+		SELECT id FROM upstream ORDER BY depth DESC LIMIT 1
+	`, forkOf.Int64).Scan(&sourceID)
+	return forkOf.Int64, sourceID, err
 }
 
 // CreateIssue creates a new issue with auto-incrementing number per repo
 // Uses a transaction to prevent race conditions in number assignment
 func (s *GitHubStore) CreateIssue(repoID, userID int64, title, body string, isPR bool) (*Issue, error) {
+	// An empty body gets the repository's default template, as on github.com
+	if body == "" {
+		templateType := TemplateTypeIssue
+		if isPR {
+			templateType = TemplateTypePullRequest
+		}
+		var err error
+		if body, err = s.DefaultTemplateBody(repoID, templateType); err != nil {
+			return nil, err
+		}
+	}
+
 	// Retry loop to handle race condition in issue number assignment
 	// The UNIQUE(repo_id, number) constraint will catch duplicate numbers
 	maxRetries := 3
@@ -697,7 +963,7 @@ func (s *GitHubStore) createIssueAttempt(repoID, userID int64, title, body strin
 		number = maxNumber.Int64 + 1
 	}
 
-	now := time.Now()
+	now := s.now()
 	isPRInt := 0
 	if isPR {
 		isPRInt = 1
@@ -723,6 +989,10 @@ func (s *GitHubStore) createIssueAttempt(repoID, userID int64, title, body strin
 		return nil, err
 	}
 
+	if err := s.audit("issue", id, core.AuditCreate, userID, map[string]any{"repo_id": repoID, "number": number, "title": title}); err != nil {
+		return nil, err
+	}
+
 	return &Issue{
 		ID:            id,
 		RepoID:        repoID,
@@ -740,18 +1010,18 @@ func (s *GitHubStore) createIssueAttempt(repoID, userID int64, title, body strin
 // GetIssueByNumber gets an issue by repo ID and number
 func (s *GitHubStore) GetIssueByNumber(repoID int64, number int) (*Issue, error) {
 	var issue Issue
-	var body, stateReason, assigneeIDs, labelIDs sql.NullString
+	var body, stateReason, assigneeIDs, labelIDs, lockReason sql.NullString
 	var milestoneID sql.NullInt64
 	var closedAt sql.NullTime
 
 	err := s.db.QueryRow(`
 		SELECT id, repo_id, number, title, body, state, state_reason, user_id, assignee_ids, label_ids, milestone_id,
-			locked, comments_count, is_pull_request, created_at, updated_at, closed_at
+			locked, lock_reason, comments_count, is_pull_request, created_at, updated_at, closed_at
 		FROM github_issues
 		WHERE repo_id = ? AND number = ?
 	`, repoID, number).Scan(
 		&issue.ID, &issue.RepoID, &issue.Number, &issue.Title, &body, &issue.State, &stateReason,
-		&issue.UserID, &assigneeIDs, &labelIDs, &milestoneID, &issue.Locked, &issue.CommentsCount,
+		&issue.UserID, &assigneeIDs, &labelIDs, &milestoneID, &issue.Locked, &lockReason, &issue.CommentsCount,
 		&issue.IsPullRequest, &issue.CreatedAt, &issue.UpdatedAt, &closedAt,
 	)
 
@@ -765,6 +1035,7 @@ func (s *GitHubStore) GetIssueByNumber(repoID int64, number int) (*Issue, error)
 	if stateReason.Valid {
 		issue.StateReason = stateReason.String
 	}
+	issue.LockReason = lockReason.String
 	if assigneeIDs.Valid {
 		issue.AssigneeIDs = assigneeIDs.String
 	}
@@ -782,11 +1053,21 @@ func (s *GitHubStore) GetIssueByNumber(repoID int64, number int) (*Issue, error)
 	return &issue, nil
 }
 
+// GetIssueByID gets an issue or pull request by its global ID
+func (s *GitHubStore) GetIssueByID(id int64) (*Issue, error) {
+	var repoID, number int64
+	err := s.db.QueryRow(`SELECT repo_id, number FROM github_issues WHERE id = ?`, id).Scan(&repoID, &number)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetIssueByNumber(repoID, int(number))
+}
+
 // ListIssues lists issues for a repository (excludes PRs by default)
 func (s *GitHubStore) ListIssues(repoID int64, state string, includePRs bool) ([]*Issue, error) {
 	query := `
 		SELECT id, repo_id, number, title, body, state, state_reason, user_id, assignee_ids, label_ids, milestone_id,
-			locked, comments_count, is_pull_request, created_at, updated_at, closed_at
+			locked, lock_reason, comments_count, is_pull_request, created_at, updated_at, closed_at
 		FROM github_issues
 		WHERE repo_id = ?
 	`
@@ -813,13 +1094,13 @@ func (s *GitHubStore) ListIssues(repoID int64, state string, includePRs bool) ([
 	var issues []*Issue
 	for rows.Next() {
 		var issue Issue
-		var body, stateReason, assigneeIDs, labelIDs sql.NullString
+		var body, stateReason, assigneeIDs, labelIDs, lockReason sql.NullString
 		var milestoneID sql.NullInt64
 		var closedAt sql.NullTime
 
 		err := rows.Scan(
 			&issue.ID, &issue.RepoID, &issue.Number, &issue.Title, &body, &issue.State, &stateReason,
-			&issue.UserID, &assigneeIDs, &labelIDs, &milestoneID, &issue.Locked, &issue.CommentsCount,
+			&issue.UserID, &assigneeIDs, &labelIDs, &milestoneID, &issue.Locked, &lockReason, &issue.CommentsCount,
 			&issue.IsPullRequest, &issue.CreatedAt, &issue.UpdatedAt, &closedAt,
 		)
 		if err != nil {
@@ -832,6 +1113,7 @@ func (s *GitHubStore) ListIssues(repoID int64, state string, includePRs bool) ([
 		if stateReason.Valid {
 			issue.StateReason = stateReason.String
 		}
+		issue.LockReason = lockReason.String
 		if assigneeIDs.Valid {
 			issue.AssigneeIDs = assigneeIDs.String
 		}
@@ -854,7 +1136,7 @@ func (s *GitHubStore) ListIssues(repoID int64, state string, includePRs bool) ([
 
 // UpdateIssue updates an issue
 func (s *GitHubStore) UpdateIssue(issue *Issue) error {
-	now := time.Now()
+	now := s.now()
 	issue.UpdatedAt = now
 
 	_, err := s.db.Exec(`
@@ -862,13 +1144,27 @@ func (s *GitHubStore) UpdateIssue(issue *Issue) error {
 		SET title = ?, body = ?, state = ?, state_reason = ?, updated_at = ?, closed_at = ?
 		WHERE id = ?
 	`, issue.Title, issue.Body, issue.State, issue.StateReason, issue.UpdatedAt, issue.ClosedAt, issue.ID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return s.audit("issue", issue.ID, core.AuditUpdate, 0, map[string]any{"title": issue.Title, "state": issue.State})
 }
 
 // CreatePullRequest creates a new pull request (issue + PR record) atomically
 // Uses a transaction to ensure both the issue and PR are created together
 func (s *GitHubStore) CreatePullRequest(repoID, userID int64, title, body, headRef, baseRef string) (*Issue, *PullRequest, error) {
+	// Remember where the base branch is so later pushes to it make the PR stale
+	baseSHA, err := s.ResolveRef(repoID, baseRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body == "" {
+		if body, err = s.DefaultTemplateBody(repoID, TemplateTypePullRequest); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Start transaction for atomic PR+Issue creation
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -888,7 +1184,7 @@ func (s *GitHubStore) CreatePullRequest(repoID, userID int64, title, body, headR
 		number = maxNumber.Int64 + 1
 	}
 
-	now := time.Now()
+	now := s.now()
 
 	// Create the issue with is_pull_request=1
 	result, err := tx.Exec(`
@@ -907,9 +1203,9 @@ func (s *GitHubStore) CreatePullRequest(repoID, userID int64, title, body, headR
 
 	// Create the PR record
 	_, err = tx.Exec(`
-		INSERT INTO github_pull_requests (issue_id, head_repo_id, head_ref, base_repo_id, base_ref, merged, mergeable, rebaseable)
-		VALUES (?, ?, ?, ?, ?, 0, 1, 1)
-	`, issueID, repoID, headRef, repoID, baseRef)
+		INSERT INTO github_pull_requests (issue_id, head_repo_id, head_ref, base_repo_id, base_ref, merged, mergeable, rebaseable, base_sha)
+		VALUES (?, ?, ?, ?, ?, 0, 1, 1, ?)
+	`, issueID, repoID, headRef, repoID, baseRef, baseSHA)
 
 	if err != nil {
 		return nil, nil, err
@@ -920,6 +1216,16 @@ func (s *GitHubStore) CreatePullRequest(repoID, userID int64, title, body, headR
 		return nil, nil, err
 	}
 
+	if err := s.audit("pull_request", issueID, core.AuditCreate, userID, map[string]any{
+		"repo_id":  repoID,
+		"number":   number,
+		"title":    title,
+		"head_ref": headRef,
+		"base_ref": baseRef,
+	}); err != nil {
+		return nil, nil, err
+	}
+
 	issue := &Issue{
 		ID:            issueID,
 		RepoID:        repoID,
@@ -939,9 +1245,11 @@ func (s *GitHubStore) CreatePullRequest(repoID, userID int64, title, body, headR
 		HeadRef:    headRef,
 		BaseRepoID: repoID,
 		BaseRef:    baseRef,
-		Merged:     false,
-		Mergeable:  true,
-		Rebaseable: true,
+		Merged:         false,
+		Mergeable:      true,
+		Rebaseable:     true,
+		BaseSHA:        baseSHA,
+		MergeableState: "clean",
 	}
 
 	return issue, pr, nil
@@ -964,16 +1272,17 @@ func (s *GitHubStore) GetPullRequest(repoID int64, number int) (*Issue, *PullReq
 	var mergeCommitSHA sql.NullString
 	var mergedAt sql.NullTime
 	var mergedByID sql.NullInt64
+	var baseSHA sql.NullString
 
 	err = s.db.QueryRow(`
 		SELECT issue_id, head_repo_id, head_ref, base_repo_id, base_ref, merged, mergeable, rebaseable,
-			merge_commit_sha, merged_at, merged_by_id, draft, review_comments_count, commits_count,
+			merge_commit_sha, merged_at, merged_by_id, base_sha, draft, review_comments_count, commits_count,
 			additions, deletions, changed_files
 		FROM github_pull_requests
 		WHERE issue_id = ?
 	`, issue.ID).Scan(
 		&pr.IssueID, &pr.HeadRepoID, &pr.HeadRef, &pr.BaseRepoID, &pr.BaseRef, &pr.Merged, &pr.Mergeable, &pr.Rebaseable,
-		&mergeCommitSHA, &mergedAt, &mergedByID, &pr.Draft, &pr.ReviewCommentsCount, &pr.CommitsCount,
+		&mergeCommitSHA, &mergedAt, &mergedByID, &baseSHA, &pr.Draft, &pr.ReviewCommentsCount, &pr.CommitsCount,
 		&pr.Additions, &pr.Deletions, &pr.ChangedFiles,
 	)
 
@@ -991,10 +1300,79 @@ func (s *GitHubStore) GetPullRequest(repoID int64, number int) (*Issue, *PullReq
 		id := mergedByID.Int64
 		pr.MergedByID = &id
 	}
+	pr.BaseSHA = baseSHA.String
+
+	if err := s.refreshMergeability(&pr); err != nil {
+		return nil, nil, err
+	}
 
 	return issue, &pr, nil
 }
 
+// refreshMergeability recomputes whether an open PR can merge: it conflicts
+// once its base branch has moved past the SHA it was based on, and is blocked
+// when the head commit's statuses are failing. A changed result is saved.
+func (s *GitHubStore) refreshMergeability(pr *PullRequest) error {
+	if pr.Merged {
+		pr.MergeableState = "clean"
+		return nil
+	}
+
+	mergeable := true
+	if pr.BaseSHA != "" {
+		current, err := s.ResolveRef(pr.BaseRepoID, pr.BaseRef)
+		if err != nil {
+			return err
+		}
+		mergeable = current == pr.BaseSHA
+	}
+	if mergeable != pr.Mergeable {
+		if _, err := s.db.Exec(`UPDATE github_pull_requests SET mergeable = ? WHERE issue_id = ?`, mergeable, pr.IssueID); err != nil {
+			return err
+		}
+		pr.Mergeable = mergeable
+	}
+
+	if !mergeable {
+		pr.MergeableState = "dirty"
+		return nil
+	}
+	headSHA, err := s.ResolveRef(pr.HeadRepoID, pr.HeadRef)
+	if err != nil {
+		return err
+	}
+	statuses, err := s.LatestCommitStatuses(pr.HeadRepoID, headSHA)
+	if err != nil {
+		return err
+	}
+	if len(statuses) > 0 && combinedState(statuses) == "failure" {
+		pr.MergeableState = "blocked"
+	} else {
+		pr.MergeableState = "clean"
+	}
+	return nil
+}
+
+// UpdatePullRequestBranch brings a PR up to date with its base branch by
+// rebasing it on the base branch's current SHA
+func (s *GitHubStore) UpdatePullRequestBranch(pr *PullRequest, actorID int64) error {
+	current, err := s.ResolveRef(pr.BaseRepoID, pr.BaseRef)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`
+		UPDATE github_pull_requests SET base_sha = ?, mergeable = 1 WHERE issue_id = ?
+	`, current, pr.IssueID); err != nil {
+		return err
+	}
+	pr.BaseSHA = current
+	pr.Mergeable = true
+	if err := s.refreshMergeability(pr); err != nil {
+		return err
+	}
+	return s.audit("pull_request", pr.IssueID, core.AuditUpdate, actorID, map[string]any{"base_sha": current})
+}
+
 // ListPullRequests lists pull requests for a repository
 func (s *GitHubStore) ListPullRequests(repoID int64, state string) ([]*Issue, error) {
 	// List issues where is_pull_request=1
@@ -1003,7 +1381,7 @@ func (s *GitHubStore) ListPullRequests(repoID int64, state string) ([]*Issue, er
 
 // MergePullRequest marks a PR as merged and closes the issue
 func (s *GitHubStore) MergePullRequest(issueID, mergedByID int64) error {
-	now := time.Now()
+	now := s.now()
 
 	// Update the PR record
 	_, err := s.db.Exec(`
@@ -1022,8 +1400,11 @@ func (s *GitHubStore) MergePullRequest(issueID, mergedByID int64) error {
 		SET state = 'closed', closed_at = ?, updated_at = ?
 		WHERE id = ?
 	`, now, now, issueID)
-
-	return err
+	if err != nil {
+		return err
+	}
+
+	return s.audit("pull_request", issueID, core.AuditUpdate, mergedByID, map[string]any{"merged": true, "state": "closed"})
 }
 
 // CreateComment creates a new comment and increments the issue's comments_count
@@ -1036,7 +1417,7 @@ func (s *GitHubStore) CreateComment(issueID, userID int64, body string) (*Commen
 	}
 	defer tx.Rollback() // Will be no-op if tx.Commit() succeeds
 
-	now := time.Now()
+	now := s.now()
 
 	// Insert the comment
 	result, err := tx.Exec(`
@@ -1069,6 +1450,10 @@ func (s *GitHubStore) CreateComment(issueID, userID int64, body string) (*Commen
 		return nil, err
 	}
 
+	if err := s.audit("comment", commentID, core.AuditCreate, userID, map[string]any{"issue_id": issueID, "body": body}); err != nil {
+		return nil, err
+	}
+
 	return &Comment{
 		ID:        commentID,
 		IssueID:   issueID,
@@ -1125,7 +1510,7 @@ func (s *GitHubStore) ListComments(issueID int64) ([]*Comment, error) {
 
 // UpdateComment updates a comment's body and updated_at timestamp
 func (s *GitHubStore) UpdateComment(comment *Comment) error {
-	now := time.Now()
+	now := s.now()
 	comment.UpdatedAt = now
 
 	_, err := s.db.Exec(`
@@ -1133,8 +1518,11 @@ func (s *GitHubStore) UpdateComment(comment *Comment) error {
 		SET body = ?, updated_at = ?
 		WHERE id = ?
 	`, comment.Body, comment.UpdatedAt, comment.ID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return s.audit("comment", comment.ID, core.AuditUpdate, comment.UserID, map[string]any{"body": comment.Body})
 }
 
 // DeleteComment deletes a comment (hard delete) and decrements the issue's comment count
@@ -1157,7 +1545,7 @@ func (s *GitHubStore) DeleteComment(commentID int64) error {
 		return err
 	}
 
-	// Delete the comment
+	// Delete the comment and its reactions
 	_, err = tx.Exec(`
 		DELETE FROM github_comments
 		WHERE id = ?
@@ -1167,6 +1555,15 @@ func (s *GitHubStore) DeleteComment(commentID int64) error {
 		return err
 	}
 
+	_, err = tx.Exec(`
+		DELETE FROM github_reactions
+		WHERE subject_type = ? AND subject_id = ?
+	`, ReactionSubjectIssueComment, commentID)
+
+	if err != nil {
+		return err
+	}
+
 	// Decrement the issue's comments_count
 	_, err = tx.Exec(`
 		UPDATE github_issues
@@ -1181,358 +1578,2155 @@ func (s *GitHubStore) DeleteComment(commentID int64) error {
 		return err
 	}
 
-	return tx.Commit()
-}
-
-// generateCommitSHA creates a fake 40-character hex SHA for reviews
-func generateCommitSHA() (string, error) {
-	bytes := make([]byte, 20)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
+	if err := tx.Commit(); err != nil {
+		return err
 	}
-	return hex.EncodeToString(bytes), nil
-}
 
-// CreateReview creates a new review for a pull request
-func (s *GitHubStore) CreateReview(pullRequestID, userID int64, state, body string) (*Review, error) {
-	// Generate a fake commit SHA
-	commitSHA, err := generateCommitSHA()
-	if err != nil {
-		return nil, err
-	}
+	return s.audit("comment", commentID, core.AuditDelete, 0, map[string]any{"issue_id": issueID})
+}
 
-	result, err := s.db.Exec(`
-		INSERT INTO github_reviews (pull_request_id, user_id, state, body, commit_sha)
-		VALUES (?, ?, ?, ?, ?)
-	`, pullRequestID, userID, state, body, commitSHA)
+// LockReasons are the reasons GitHub accepts for locking an issue
+var LockReasons = []string{"off-topic", "too heated", "resolved", "spam"}
 
-	if err != nil {
-		return nil, err
+// SetIssueLock locks or unlocks an issue's conversation, recording a locked
+// or unlocked event when the lock state changes. Locking an already locked
+// issue only updates the reason.
+func (s *GitHubStore) SetIssueLock(issue *Issue, actorID int64, locked bool, reason string) error {
+	if !locked {
+		reason = ""
 	}
+	changed := issue.Locked != locked
+	now := s.now()
 
-	reviewID, err := result.LastInsertId()
+	tx, err := s.db.Begin()
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer tx.Rollback() // Will be no-op if tx.Commit() succeeds
 
-	return &Review{
-		ID:            reviewID,
-		PullRequestID: pullRequestID,
-		UserID:        userID,
-		State:         state,
-		Body:          body,
-		CommitSHA:     commitSHA,
-	}, nil
-}
-
-// GetReview gets a review by ID
-func (s *GitHubStore) GetReview(reviewID int64) (*Review, error) {
-	var review Review
-	var body sql.NullString
-	var submittedAt, dismissedAt sql.NullTime
-
-	err := s.db.QueryRow(`
-		SELECT id, pull_request_id, user_id, state, body, commit_sha, submitted_at, dismissed_at
-		FROM github_reviews
+	_, err = tx.Exec(`
+		UPDATE github_issues
+		SET locked = ?, lock_reason = ?, updated_at = ?
 		WHERE id = ?
-	`, reviewID).Scan(&review.ID, &review.PullRequestID, &review.UserID, &review.State, &body, &review.CommitSHA, &submittedAt, &dismissedAt)
-
+	`, locked, reason, now, issue.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if body.Valid {
-		review.Body = body.String
-	}
-	if submittedAt.Valid {
-		review.SubmittedAt = &submittedAt.Time
+	if changed {
+		event := "unlocked"
+		if locked {
+			event = "locked"
+		}
+		_, err = tx.Exec(`
+			INSERT INTO github_issue_events (issue_id, actor_id, event, lock_reason, created_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, issue.ID, actorID, event, reason, now)
+		if err != nil {
+			return err
+		}
 	}
-	if dismissedAt.Valid {
-		review.DismissedAt = &dismissedAt.Time
+
+	if err := tx.Commit(); err != nil {
+		return err
 	}
 
-	return &review, nil
+	issue.Locked = locked
+	issue.LockReason = reason
+	issue.UpdatedAt = now
+	return s.audit("issue", issue.ID, core.AuditUpdate, actorID, map[string]any{"locked": locked, "lock_reason": reason})
 }
 
-// ListReviews lists all reviews for a pull request
-func (s *GitHubStore) ListReviews(pullRequestID int64) ([]*Review, error) {
+// ListIssueEvents lists an issue's events, oldest first
+func (s *GitHubStore) ListIssueEvents(issueID int64) ([]*IssueEvent, error) {
 	rows, err := s.db.Query(`
-		SELECT id, pull_request_id, user_id, state, body, commit_sha, submitted_at, dismissed_at
-		FROM github_reviews
-		WHERE pull_request_id = ?
+		SELECT id, issue_id, actor_id, event, lock_reason, commit_id, source_issue_id, created_at
+		FROM github_issue_events
+		WHERE issue_id = ?
 		ORDER BY id ASC
-	`, pullRequestID)
-
+	`, issueID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var reviews []*Review
+	var events []*IssueEvent
 	for rows.Next() {
-		var review Review
-		var body sql.NullString
-		var submittedAt, dismissedAt sql.NullTime
-
-		err := rows.Scan(&review.ID, &review.PullRequestID, &review.UserID, &review.State, &body, &review.CommitSHA, &submittedAt, &dismissedAt)
-		if err != nil {
+		var event IssueEvent
+		var lockReason, commitID sql.NullString
+		var sourceIssueID sql.NullInt64
+		if err := rows.Scan(&event.ID, &event.IssueID, &event.ActorID, &event.Event, &lockReason, &commitID, &sourceIssueID, &event.CreatedAt); err != nil {
 			return nil, err
 		}
-
-		if body.Valid {
-			review.Body = body.String
-		}
-		if submittedAt.Valid {
-			review.SubmittedAt = &submittedAt.Time
-		}
-		if dismissedAt.Valid {
-			review.DismissedAt = &dismissedAt.Time
+		event.LockReason = lockReason.String
+		event.CommitID = commitID.String
+		if sourceIssueID.Valid {
+			event.SourceIssueID = &sourceIssueID.Int64
 		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
 
-		reviews = append(reviews, &review)
+// CloseIssueByReference closes an open issue named by a closing keyword such
+// as "Fixes #1", recording a closed event that points at the commit or
+// merged pull request (sourceIssueID, zero for none) that closed it
+func (s *GitHubStore) CloseIssueByReference(issue *Issue, actorID int64, commitID string, sourceIssueID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback() // Will be no-op if tx.Commit() succeeds
 
-	return reviews, rows.Err()
+	now := s.now()
+	_, err = tx.Exec(`
+		UPDATE github_issues
+		SET state = 'closed', state_reason = 'completed', closed_at = ?, updated_at = ?
+		WHERE id = ?
+	`, now, now, issue.ID)
+	if err != nil {
+		return err
+	}
+
+	var source sql.NullInt64
+	if sourceIssueID != 0 {
+		source = sql.NullInt64{Int64: sourceIssueID, Valid: true}
+	}
+	_, err = tx.Exec(`
+		INSERT INTO github_issue_events (issue_id, actor_id, event, commit_id, source_issue_id, created_at)
+		VALUES (?, ?, 'closed', ?, ?, ?)
+	`, issue.ID, actorID, nullString(commitID), source, now)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	issue.State = "closed"
+	issue.StateReason = "completed"
+	issue.ClosedAt = &now
+	issue.UpdatedAt = now
+	return s.audit("issue", issue.ID, core.AuditUpdate, actorID, map[string]any{"state": "closed", "state_reason": "completed"})
 }
 
-// SubmitReview sets the submitted_at timestamp for a review
-func (s *GitHubStore) SubmitReview(reviewID int64) error {
-	now := time.Now()
-	_, err := s.db.Exec(`
-		UPDATE github_reviews
-		SET submitted_at = ?
-		WHERE id = ?
-	`, now, reviewID)
+// IssueReference records that one issue's body mentions another issue in
+// the same repository
+type IssueReference struct {
+	ID            int64
+	SourceIssueID int64
+	TargetIssueID int64
+	CreatedAt     time.Time
+}
 
-	return err
+// AddIssueReferences records that the source issue references each target.
+// References already recorded keep their original time.
+func (s *GitHubStore) AddIssueReferences(sourceIssueID int64, targetIssueIDs []int64) error {
+	now := s.now()
+	for _, targetID := range targetIssueIDs {
+		_, err := s.db.Exec(`
+			INSERT OR IGNORE INTO github_issue_references (source_issue_id, target_issue_id, created_at)
+			VALUES (?, ?, ?)
+		`, sourceIssueID, targetID, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// DismissReview sets the dismissed_at timestamp and changes state to DISMISSED
-func (s *GitHubStore) DismissReview(reviewID int64) error {
-	now := time.Now()
-	_, err := s.db.Exec(`
-		UPDATE github_reviews
-		SET state = 'DISMISSED', dismissed_at = ?
-		WHERE id = ?
-	`, now, reviewID)
+// ListIssueReferencesTo lists the references made to an issue, oldest first
+func (s *GitHubStore) ListIssueReferencesTo(targetIssueID int64) ([]*IssueReference, error) {
+	return s.listIssueReferences("target_issue_id", targetIssueID)
+}
 
-	return err
+// ListIssueReferencesFrom lists the references an issue makes, oldest first
+func (s *GitHubStore) ListIssueReferencesFrom(sourceIssueID int64) ([]*IssueReference, error) {
+	return s.listIssueReferences("source_issue_id", sourceIssueID)
 }
 
-// CreateWebhook creates a new webhook for a repository
-func (s *GitHubStore) CreateWebhook(repoID int64, url, contentType, secret string, events []string) (*Webhook, error) {
-	// Validate the webhook URL for SSRF protection
-	if err := validateWebhookURL(url); err != nil {
+func (s *GitHubStore) listIssueReferences(column string, issueID int64) ([]*IssueReference, error) {
+	rows, err := s.db.Query(`
+		SELECT id, source_issue_id, target_issue_id, created_at
+		FROM github_issue_references
+		WHERE `+column+` = ?
+		ORDER BY id ASC
+	`, issueID)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	now := time.Now()
-	eventsStr := ""
-	if len(events) > 0 {
-		// Join events with commas
-		for i, event := range events {
-			if i > 0 {
-				eventsStr += ","
-			}
-			eventsStr += event
+	var refs []*IssueReference
+	for rows.Next() {
+		var ref IssueReference
+		if err := rows.Scan(&ref.ID, &ref.SourceIssueID, &ref.TargetIssueID, &ref.CreatedAt); err != nil {
+			return nil, err
 		}
+		refs = append(refs, &ref)
 	}
+	return refs, rows.Err()
+}
 
-	result, err := s.db.Exec(`
-		INSERT INTO github_webhooks (repo_id, url, content_type, secret, events, active, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, 1, ?, ?)
-	`, repoID, url, contentType, secret, eventsStr, now, now)
+// Reaction subject types
+const (
+	ReactionSubjectIssue         = "issue"
+	ReactionSubjectIssueComment  = "issue_comment"
+	ReactionSubjectReviewComment = "pull_request_review_comment"
+)
+
+// ReactionContents lists the reaction emoji GitHub accepts, in display order
+var ReactionContents = []string{"+1", "-1", "laugh", "hooray", "confused", "heart", "rocket", "eyes"}
+
+// CreateReaction adds a reaction to an issue or comment. GitHub treats a repeat
+// reaction as a no-op, so an existing reaction is returned with created=false.
+func (s *GitHubStore) CreateReaction(subjectType string, subjectID, userID int64, content string) (reaction *Reaction, created bool, err error) {
+	var existing Reaction
+	err = s.db.QueryRow(`
+		SELECT id, subject_type, subject_id, user_id, content, created_at
+		FROM github_reactions
+		WHERE subject_type = ? AND subject_id = ? AND user_id = ? AND content = ?
+	`, subjectType, subjectID, userID, content).Scan(&existing.ID, &existing.SubjectType, &existing.SubjectID,
+		&existing.UserID, &existing.Content, &existing.CreatedAt)
+	if err == nil {
+		return &existing, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
 
+	now := s.now()
+	result, err := s.db.Exec(`
+		INSERT INTO github_reactions (subject_type, subject_id, user_id, content, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, subjectType, subjectID, userID, content, now)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return &Webhook{
+	if err := s.audit("reaction", id, core.AuditCreate, userID, map[string]any{
+		"subject_type": subjectType,
+		"subject_id":   subjectID,
+		"content":      content,
+	}); err != nil {
+		return nil, false, err
+	}
+
+	return &Reaction{
 		ID:          id,
-		RepoID:      repoID,
-		URL:         url,
-		ContentType: contentType,
-		Secret:      secret,
-		Events:      eventsStr,
-		Active:      true,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		UserID:      userID,
+		Content:     content,
 		CreatedAt:   now,
-		UpdatedAt:   now,
-	}, nil
+	}, true, nil
 }
 
-// ListWebhooks lists all webhooks for a repository
-func (s *GitHubStore) ListWebhooks(repoID int64) ([]*Webhook, error) {
-	rows, err := s.db.Query(`
-		SELECT id, repo_id, url, content_type, secret, events, active, created_at, updated_at
-		FROM github_webhooks
-		WHERE repo_id = ?
-		ORDER BY id ASC
-	`, repoID)
+// GetReaction retrieves a reaction by ID
+func (s *GitHubStore) GetReaction(reactionID int64) (*Reaction, error) {
+	var reaction Reaction
+
+	err := s.db.QueryRow(`
+		SELECT id, subject_type, subject_id, user_id, content, created_at
+		FROM github_reactions
+		WHERE id = ?
+	`, reactionID).Scan(&reaction.ID, &reaction.SubjectType, &reaction.SubjectID,
+		&reaction.UserID, &reaction.Content, &reaction.CreatedAt)
+
+	if err != nil {
+		return nil, err
+	}
 
+	return &reaction, nil
+}
+
+// ListReactions lists reactions on a subject, optionally filtered by content
+func (s *GitHubStore) ListReactions(subjectType string, subjectID int64, content string) ([]*Reaction, error) {
+	query := `
+		SELECT id, subject_type, subject_id, user_id, content, created_at
+		FROM github_reactions
+		WHERE subject_type = ? AND subject_id = ?`
+	args := []interface{}{subjectType, subjectID}
+	if content != "" {
+		query += ` AND content = ?`
+		args = append(args, content)
+	}
+	query += ` ORDER BY id ASC`
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var webhooks []*Webhook
+	var reactions []*Reaction
 	for rows.Next() {
-		var webhook Webhook
-		var secret sql.NullString
-
-		err := rows.Scan(
-			&webhook.ID, &webhook.RepoID, &webhook.URL, &webhook.ContentType,
-			&secret, &webhook.Events, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt,
-		)
+		var reaction Reaction
+		err := rows.Scan(&reaction.ID, &reaction.SubjectType, &reaction.SubjectID,
+			&reaction.UserID, &reaction.Content, &reaction.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
-
-		if secret.Valid {
-			webhook.Secret = secret.String
-		}
-
-		webhooks = append(webhooks, &webhook)
+		reactions = append(reactions, &reaction)
 	}
 
-	return webhooks, rows.Err()
+	return reactions, rows.Err()
 }
 
-// GetWebhook gets a webhook by ID
-func (s *GitHubStore) GetWebhook(webhookID int64) (*Webhook, error) {
-	var webhook Webhook
-	var secret sql.NullString
-
-	err := s.db.QueryRow(`
-		SELECT id, repo_id, url, content_type, secret, events, active, created_at, updated_at
-		FROM github_webhooks
-		WHERE id = ?
-	`, webhookID).Scan(
-		&webhook.ID, &webhook.RepoID, &webhook.URL, &webhook.ContentType,
-		&secret, &webhook.Events, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt,
-	)
-
+// CountReactions returns reaction counts by content for a subject
+func (s *GitHubStore) CountReactions(subjectType string, subjectID int64) (map[string]int, error) {
+	rows, err := s.db.Query(`
+		SELECT content, COUNT(*)
+		FROM github_reactions
+		WHERE subject_type = ? AND subject_id = ?
+		GROUP BY content
+	`, subjectType, subjectID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	if secret.Valid {
-		webhook.Secret = secret.String
+	counts := make(map[string]int)
+	for rows.Next() {
+		var content string
+		var count int
+		if err := rows.Scan(&content, &count); err != nil {
+			return nil, err
+		}
+		counts[content] = count
 	}
 
-	return &webhook, nil
+	return counts, rows.Err()
 }
 
-// UpdateWebhook updates a webhook
-func (s *GitHubStore) UpdateWebhook(webhook *Webhook) error {
+// DeleteReaction deletes a reaction
+func (s *GitHubStore) DeleteReaction(reactionID int64) error {
+	if _, err := s.db.Exec(`DELETE FROM github_reactions WHERE id = ?`, reactionID); err != nil {
+		return err
+	}
+	return s.audit("reaction", reactionID, core.AuditDelete, 0, nil)
+}
+
+// ReviewCommentExists reports whether a pull request review comment exists
+func (s *GitHubStore) ReviewCommentExists(commentID int64) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM github_review_comments WHERE id = ?`, commentID).Scan(&count)
+	return count > 0, err
+}
+
+// ResolveRef returns the commit SHA a branch name points at, or the ref
+// itself when it isn't a known branch
+func (s *GitHubStore) ResolveRef(repoID int64, ref string) (string, error) {
+	var sha string
+	err := s.db.QueryRow(`
+		SELECT commit_sha FROM github_branches WHERE repo_id = ? AND name = ?
+	`, repoID, ref).Scan(&sha)
+	if err == sql.ErrNoRows {
+		return ref, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// CreateCommitStatus records a status for a commit. Statuses are append-only;
+// the newest one per context is the current state.
+func (s *GitHubStore) CreateCommitStatus(repoID int64, sha, state, context, description, targetURL string, creatorID int64) (*CommitStatus, error) {
+	if context == "" {
+		context = "default"
+	}
+
+	now := s.now()
+	result, err := s.db.Exec(`
+		INSERT INTO github_commit_statuses (repo_id, sha, state, context, description, target_url, creator_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, repoID, sha, state, context, description, targetURL, creatorID, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit("commit_status", id, core.AuditCreate, creatorID, map[string]any{"sha": sha, "state": state, "context": context}); err != nil {
+		return nil, err
+	}
+
+	return &CommitStatus{
+		ID:          id,
+		RepoID:      repoID,
+		SHA:         sha,
+		State:       state,
+		Context:     context,
+		Description: description,
+		TargetURL:   targetURL,
+		CreatorID:   creatorID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// ListCommitStatuses lists every status for a commit, newest first
+func (s *GitHubStore) ListCommitStatuses(repoID int64, sha string) ([]*CommitStatus, error) {
+	return s.queryCommitStatuses(`
+		SELECT id, repo_id, sha, state, context, description, target_url, creator_id, created_at, updated_at
+		FROM github_commit_statuses
+		WHERE repo_id = ? AND sha = ?
+		ORDER BY id DESC
+	`, repoID, sha)
+}
+
+// LatestCommitStatuses returns the newest status for each context on a commit
+func (s *GitHubStore) LatestCommitStatuses(repoID int64, sha string) ([]*CommitStatus, error) {
+	return s.queryCommitStatuses(`
+		SELECT id, repo_id, sha, state, context, description, target_url, creator_id, created_at, updated_at
+		FROM github_commit_statuses
+		WHERE id IN (
+			SELECT MAX(id) FROM github_commit_statuses
+			WHERE repo_id = ? AND sha = ?
+			GROUP BY context
+		)
+		ORDER BY context
+	`, repoID, sha)
+}
+
+func (s *GitHubStore) queryCommitStatuses(query string, args ...interface{}) ([]*CommitStatus, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []*CommitStatus
+	for rows.Next() {
+		var status CommitStatus
+		var description, targetURL sql.NullString
+		if err := rows.Scan(&status.ID, &status.RepoID, &status.SHA, &status.State, &status.Context,
+			&description, &targetURL, &status.CreatorID, &status.CreatedAt, &status.UpdatedAt); err != nil {
+			return nil, err
+		}
+		status.Description = description.String
+		status.TargetURL = targetURL.String
+		statuses = append(statuses, &status)
+	}
+
+	return statuses, rows.Err()
+}
+
+// CreateCheckRun creates a check run for a commit
+func (s *GitHubStore) CreateCheckRun(run *CheckRun) error {
+	result, err := s.db.Exec(`
+		INSERT INTO github_check_runs (repo_id, head_sha, name, status, conclusion, details_url, external_id,
+			output_title, output_summary, output_text, started_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.RepoID, run.HeadSHA, run.Name, run.Status, nullString(run.Conclusion), run.DetailsURL, run.ExternalID,
+		run.OutputTitle, run.OutputSummary, run.OutputText, run.StartedAt, run.CompletedAt)
+	if err != nil {
+		return err
+	}
+
+	run.ID, err = result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	return s.audit("check_run", run.ID, core.AuditCreate, 0, map[string]any{"name": run.Name, "head_sha": run.HeadSHA, "status": run.Status})
+}
+
+// UpdateCheckRun saves all mutable fields of a check run
+func (s *GitHubStore) UpdateCheckRun(run *CheckRun) error {
+	_, err := s.db.Exec(`
+		UPDATE github_check_runs
+		SET name = ?, status = ?, conclusion = ?, details_url = ?, external_id = ?,
+			output_title = ?, output_summary = ?, output_text = ?, started_at = ?, completed_at = ?
+		WHERE id = ?
+	`, run.Name, run.Status, nullString(run.Conclusion), run.DetailsURL, run.ExternalID,
+		run.OutputTitle, run.OutputSummary, run.OutputText, run.StartedAt, run.CompletedAt, run.ID)
+	if err != nil {
+		return err
+	}
+	return s.audit("check_run", run.ID, core.AuditUpdate, 0, map[string]any{"status": run.Status, "conclusion": run.Conclusion})
+}
+
+// GetCheckRun retrieves a check run by ID within a repository
+func (s *GitHubStore) GetCheckRun(repoID, checkRunID int64) (*CheckRun, error) {
+	runs, err := s.queryCheckRuns(`
+		SELECT id, repo_id, head_sha, name, status, conclusion, details_url, external_id,
+			output_title, output_summary, output_text, started_at, completed_at
+		FROM github_check_runs
+		WHERE repo_id = ? AND id = ?
+	`, repoID, checkRunID)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return runs[0], nil
+}
+
+// ListCheckRuns lists check runs for a commit, newest first, optionally
+// filtered by name and status
+func (s *GitHubStore) ListCheckRuns(repoID int64, sha, name, status string) ([]*CheckRun, error) {
+	query := `
+		SELECT id, repo_id, head_sha, name, status, conclusion, details_url, external_id,
+			output_title, output_summary, output_text, started_at, completed_at
+		FROM github_check_runs
+		WHERE repo_id = ? AND head_sha = ?`
+	args := []interface{}{repoID, sha}
+	if name != "" {
+		query += ` AND name = ?`
+		args = append(args, name)
+	}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY id DESC`
+
+	return s.queryCheckRuns(query, args...)
+}
+
+func (s *GitHubStore) queryCheckRuns(query string, args ...interface{}) ([]*CheckRun, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*CheckRun
+	for rows.Next() {
+		var run CheckRun
+		var conclusion, detailsURL, externalID, title, summary, text sql.NullString
+		var startedAt, completedAt sql.NullTime
+		if err := rows.Scan(&run.ID, &run.RepoID, &run.HeadSHA, &run.Name, &run.Status, &conclusion,
+			&detailsURL, &externalID, &title, &summary, &text, &startedAt, &completedAt); err != nil {
+			return nil, err
+		}
+		run.Conclusion = conclusion.String
+		run.DetailsURL = detailsURL.String
+		run.ExternalID = externalID.String
+		run.OutputTitle = title.String
+		run.OutputSummary = summary.String
+		run.OutputText = text.String
+		if startedAt.Valid {
+			run.StartedAt = &startedAt.Time
+		}
+		if completedAt.Valid {
+			run.CompletedAt = &completedAt.Time
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, rows.Err()
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// generateCommitSHA creates a fake 40-character hex SHA for reviews
+func generateCommitSHA() (string, error) {
+	bytes := make([]byte, 20)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// CreateReview creates a new review for a pull request
+func (s *GitHubStore) CreateReview(pullRequestID, userID int64, state, body string) (*Review, error) {
+	// Generate a fake commit SHA
+	commitSHA, err := generateCommitSHA()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO github_reviews (pull_request_id, user_id, state, body, commit_sha)
+		VALUES (?, ?, ?, ?, ?)
+	`, pullRequestID, userID, state, body, commitSHA)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reviewID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit("review", reviewID, core.AuditCreate, userID, map[string]any{"pull_request_id": pullRequestID, "state": state}); err != nil {
+		return nil, err
+	}
+
+	return &Review{
+		ID:            reviewID,
+		PullRequestID: pullRequestID,
+		UserID:        userID,
+		State:         state,
+		Body:          body,
+		CommitSHA:     commitSHA,
+	}, nil
+}
+
+// GetReview gets a review by ID
+func (s *GitHubStore) GetReview(reviewID int64) (*Review, error) {
+	var review Review
+	var body sql.NullString
+	var submittedAt, dismissedAt sql.NullTime
+
+	err := s.db.QueryRow(`
+		SELECT id, pull_request_id, user_id, state, body, commit_sha, submitted_at, dismissed_at
+		FROM github_reviews
+		WHERE id = ?
+	`, reviewID).Scan(&review.ID, &review.PullRequestID, &review.UserID, &review.State, &body, &review.CommitSHA, &submittedAt, &dismissedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if body.Valid {
+		review.Body = body.String
+	}
+	if submittedAt.Valid {
+		review.SubmittedAt = &submittedAt.Time
+	}
+	if dismissedAt.Valid {
+		review.DismissedAt = &dismissedAt.Time
+	}
+
+	return &review, nil
+}
+
+// ListReviews lists all reviews for a pull request
+func (s *GitHubStore) ListReviews(pullRequestID int64) ([]*Review, error) {
+	rows, err := s.db.Query(`
+		SELECT id, pull_request_id, user_id, state, body, commit_sha, submitted_at, dismissed_at
+		FROM github_reviews
+		WHERE pull_request_id = ?
+		ORDER BY id ASC
+	`, pullRequestID)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*Review
+	for rows.Next() {
+		var review Review
+		var body sql.NullString
+		var submittedAt, dismissedAt sql.NullTime
+
+		err := rows.Scan(&review.ID, &review.PullRequestID, &review.UserID, &review.State, &body, &review.CommitSHA, &submittedAt, &dismissedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if body.Valid {
+			review.Body = body.String
+		}
+		if submittedAt.Valid {
+			review.SubmittedAt = &submittedAt.Time
+		}
+		if dismissedAt.Valid {
+			review.DismissedAt = &dismissedAt.Time
+		}
+
+		reviews = append(reviews, &review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// SubmitReview sets the submitted_at timestamp for a review, submitted by actorID
+func (s *GitHubStore) SubmitReview(reviewID, actorID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := s.now()
+	_, err = tx.Exec(`
+		UPDATE github_reviews
+		SET submitted_at = ?
+		WHERE id = ?
+	`, now, reviewID)
+	if err != nil {
+		return err
+	}
+	if err := auditIn(tx, "review", reviewID, core.AuditUpdate, actorID, map[string]any{"submitted_at": now}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DismissReview sets the dismissed_at timestamp and changes state to DISMISSED,
+// recording actorID as the user who dismissed it
+func (s *GitHubStore) DismissReview(reviewID, actorID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := s.now()
+	_, err = tx.Exec(`
+		UPDATE github_reviews
+		SET state = 'DISMISSED', dismissed_at = ?
+		WHERE id = ?
+	`, now, reviewID)
+	if err != nil {
+		return err
+	}
+	if err := auditIn(tx, "review", reviewID, core.AuditUpdate, actorID, map[string]any{"state": "DISMISSED", "dismissed_at": now}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateWebhook creates a new webhook for a repository. Deliveries to it are
+// held back by delayMs milliseconds.
+func (s *GitHubStore) CreateWebhook(repoID int64, url, contentType, secret string, events []string, delayMs int) (*Webhook, error) {
+	if delayMs < 0 {
+		return nil, fmt.Errorf("webhook delay cannot be negative")
+	}
+
 	// Validate the webhook URL for SSRF protection
-	if err := validateWebhookURL(webhook.URL); err != nil {
+	if err := validateWebhookURL(url); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	eventsStr := ""
+	if len(events) > 0 {
+		// Join events with commas
+		for i, event := range events {
+			if i > 0 {
+				eventsStr += ","
+			}
+			eventsStr += event
+		}
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO github_webhooks (repo_id, url, content_type, secret, events, active, delay_ms, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, 1, ?, ?, ?)
+	`, repoID, url, contentType, secret, eventsStr, delayMs, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit("webhook", id, core.AuditCreate, 0, map[string]any{"repo_id": repoID, "url": url, "events": eventsStr}); err != nil {
+		return nil, err
+	}
+
+	return &Webhook{
+		ID:          id,
+		RepoID:      repoID,
+		URL:         url,
+		ContentType: contentType,
+		Secret:      secret,
+		Events:      eventsStr,
+		Active:      true,
+		DelayMs:     delayMs,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// ListWebhooks lists all webhooks for a repository
+func (s *GitHubStore) ListWebhooks(repoID int64) ([]*Webhook, error) {
+	rows, err := s.db.Query(`
+		SELECT id, repo_id, url, content_type, secret, events, active, delay_ms, created_at, updated_at
+		FROM github_webhooks
+		WHERE repo_id = ?
+		ORDER BY id ASC
+	`, repoID)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		var webhook Webhook
+		var secret sql.NullString
+
+		err := rows.Scan(
+			&webhook.ID, &webhook.RepoID, &webhook.URL, &webhook.ContentType,
+			&secret, &webhook.Events, &webhook.Active, &webhook.DelayMs, &webhook.CreatedAt, &webhook.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if secret.Valid {
+			webhook.Secret = secret.String
+		}
+
+		webhooks = append(webhooks, &webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// GetWebhook gets a webhook by ID
+func (s *GitHubStore) GetWebhook(webhookID int64) (*Webhook, error) {
+	var webhook Webhook
+	var secret sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT id, repo_id, url, content_type, secret, events, active, delay_ms, created_at, updated_at
+		FROM github_webhooks
+		WHERE id = ?
+	`, webhookID).Scan(
+		&webhook.ID, &webhook.RepoID, &webhook.URL, &webhook.ContentType,
+		&secret, &webhook.Events, &webhook.Active, &webhook.DelayMs, &webhook.CreatedAt, &webhook.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.Valid {
+		webhook.Secret = secret.String
+	}
+
+	return &webhook, nil
+}
+
+// UpdateWebhook updates a webhook
+func (s *GitHubStore) UpdateWebhook(webhook *Webhook) error {
+	// Validate the webhook URL for SSRF protection
+	if err := validateWebhookURL(webhook.URL); err != nil {
+		return err
+	}
+
+	now := s.now()
+	webhook.UpdatedAt = now
+
+	_, err := s.db.Exec(`
+		UPDATE github_webhooks
+		SET url = ?, content_type = ?, secret = ?, events = ?, active = ?, updated_at = ?
+		WHERE id = ?
+	`, webhook.URL, webhook.ContentType, webhook.Secret, webhook.Events, webhook.Active, webhook.UpdatedAt, webhook.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.audit("webhook", webhook.ID, core.AuditUpdate, 0, map[string]any{"url": webhook.URL, "events": webhook.Events, "active": webhook.Active})
+}
+
+// DeleteWebhook deletes a webhook
+func (s *GitHubStore) DeleteWebhook(webhookID int64) error {
+	if _, err := s.db.Exec(`DELETE FROM github_webhooks WHERE id = ?`, webhookID); err != nil {
+		return err
+	}
+	return s.audit("webhook", webhookID, core.AuditDelete, 0, nil)
+}
+
+// QueueWebhookDelivery records a pending delivery of an event to a webhook,
+// due at dueAt. redelivery marks a delivery requested again through the API.
+func (s *GitHubStore) QueueWebhookDelivery(webhookID int64, eventType, payload string, dueAt time.Time, redelivery bool) (*WebhookDelivery, error) {
+	dueAt = dueAt.UTC()
+	result, err := s.db.Exec(`
+		INSERT INTO github_webhook_deliveries (webhook_id, event_type, payload, delivered_at, status_code, error_message, status, attempts, next_retry_at, redelivery)
+		VALUES (?, ?, ?, NULL, 0, '', ?, 0, ?, ?)
+	`, webhookID, eventType, payload, DeliveryStatusPending, dueAt, redelivery)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookDelivery{
+		ID:          id,
+		WebhookID:   webhookID,
+		EventType:   eventType,
+		Payload:     payload,
+		Status:      DeliveryStatusPending,
+		NextRetryAt: &dueAt,
+		Redelivery:  redelivery,
+	}, nil
+}
+
+// UpdateWebhookDelivery saves the outcome of a delivery attempt
+func (s *GitHubStore) UpdateWebhookDelivery(d *WebhookDelivery) error {
+	_, err := s.db.Exec(`
+		UPDATE github_webhook_deliveries
+		SET status = ?, attempts = ?, next_retry_at = ?, delivered_at = ?, status_code = ?, error_message = ?
+		WHERE id = ?
+	`, d.Status, d.Attempts, utcTime(d.NextRetryAt), utcTime(d.DeliveredAt), d.StatusCode, d.ErrorMessage, d.ID)
+	return err
+}
+
+// utcTime converts an optional time to UTC for storage, so stored times
+// compare correctly as text
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+const webhookDeliveryColumns = `id, webhook_id, event_type, payload, status, attempts, next_retry_at, redelivery, delivered_at, COALESCE(status_code, 0), COALESCE(error_message, '')`
+
+// DueWebhookDeliveries lists pending and failed deliveries whose next attempt
+// is due at now, oldest first
+func (s *GitHubStore) DueWebhookDeliveries(now time.Time) ([]*WebhookDelivery, error) {
+	return s.queryWebhookDeliveries(`
+		SELECT `+webhookDeliveryColumns+` FROM github_webhook_deliveries
+		WHERE status IN (?, ?) AND next_retry_at <= ?
+		ORDER BY next_retry_at, id
+	`, DeliveryStatusPending, DeliveryStatusFailed, now.UTC())
+}
+
+// ListWebhookDeliveries lists a webhook's deliveries, newest first
+func (s *GitHubStore) ListWebhookDeliveries(webhookID int64) ([]*WebhookDelivery, error) {
+	return s.queryWebhookDeliveries(`
+		SELECT `+webhookDeliveryColumns+` FROM github_webhook_deliveries
+		WHERE webhook_id = ? ORDER BY id DESC
+	`, webhookID)
+}
+
+// GetWebhookDelivery retrieves one of a webhook's deliveries
+func (s *GitHubStore) GetWebhookDelivery(webhookID, deliveryID int64) (*WebhookDelivery, error) {
+	deliveries, err := s.queryWebhookDeliveries(`
+		SELECT `+webhookDeliveryColumns+` FROM github_webhook_deliveries
+		WHERE webhook_id = ? AND id = ?
+	`, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return deliveries[0], nil
+}
+
+func (s *GitHubStore) queryWebhookDeliveries(query string, args ...interface{}) ([]*WebhookDelivery, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		var nextRetryAt, deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&nextRetryAt, &d.Redelivery, &deliveredAt, &d.StatusCode, &d.ErrorMessage); err != nil {
+			return nil, err
+		}
+		if nextRetryAt.Valid {
+			d.NextRetryAt = &nextRetryAt.Time
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// eventMatches checks if an event type matches any event in a comma-separated list
+// Returns true if the eventType exactly matches one of the events in the list
+func eventMatches(eventsList, eventType string) bool {
+	// Split by comma and check for exact matches
+	events := strings.Split(eventsList, ",")
+	for _, event := range events {
+		// Trim whitespace and compare
+		if strings.TrimSpace(event) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetActiveWebhooksForEvent gets all active webhooks for a repo that subscribe to an event
+func (s *GitHubStore) GetActiveWebhooksForEvent(repoID int64, eventType string) ([]*Webhook, error) {
+	rows, err := s.db.Query(`
+		SELECT id, repo_id, url, content_type, secret, events, active, delay_ms, created_at, updated_at
+		FROM github_webhooks
+		WHERE repo_id = ? AND active = 1
+	`, repoID)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		var webhook Webhook
+		var secret sql.NullString
+
+		err := rows.Scan(
+			&webhook.ID, &webhook.RepoID, &webhook.URL, &webhook.ContentType,
+			&secret, &webhook.Events, &webhook.Active, &webhook.DelayMs, &webhook.CreatedAt, &webhook.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if secret.Valid {
+			webhook.Secret = secret.String
+		}
+
+		// Check if this webhook subscribes to this event type
+		// Events are stored as comma-separated list
+		// Empty Events means subscribe to all events
+		if webhook.Events == "" || eventMatches(webhook.Events, eventType) {
+			webhooks = append(webhooks, &webhook)
+		}
+	}
+
+	return webhooks, rows.Err()
+}
+
+// ListAllRepositories retrieves repositories across all accounts for admin view
+func (s *GitHubStore) ListAllRepositories(limit, offset int) ([]Repository, error) {
+	rows, err := s.db.Query(`
+		SELECT id, owner_id, name, full_name, description, private, default_branch, fork, archived, disabled,
+			stargazers_count, watchers_count, forks_count, open_issues_count,
+			created_at, updated_at, pushed_at, COALESCE(language, '')
+		FROM github_repositories
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repos []Repository
+	for rows.Next() {
+		var repo Repository
+		var description sql.NullString
+		var pushedAt sql.NullTime
+
+		err := rows.Scan(
+			&repo.ID, &repo.OwnerID, &repo.Name, &repo.FullName, &description, &repo.Private,
+			&repo.DefaultBranch, &repo.Fork, &repo.Archived, &repo.Disabled,
+			&repo.StargazersCount, &repo.WatchersCount, &repo.ForksCount, &repo.OpenIssuesCount,
+			&repo.CreatedAt, &repo.UpdatedAt, &pushedAt, &repo.Language,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if description.Valid {
+			repo.Description = description.String
+		}
+		if pushedAt.Valid {
+			repo.PushedAt = &pushedAt.Time
+		}
+
+		repos = append(repos, repo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// ListAllIssues retrieves issues across all repositories for admin view
+func (s *GitHubStore) ListAllIssues(limit, offset int) ([]Issue, error) {
+	rows, err := s.db.Query(`
+		SELECT id, repo_id, number, title, body, state, state_reason, user_id, assignee_ids, label_ids, milestone_id,
+			locked, lock_reason, comments_count, is_pull_request, created_at, updated_at, closed_at
+		FROM github_issues
+		WHERE is_pull_request = 0
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []Issue
+	for rows.Next() {
+		var issue Issue
+		var body, stateReason, assigneeIDs, labelIDs, lockReason sql.NullString
+		var milestoneID sql.NullInt64
+		var closedAt sql.NullTime
+
+		err := rows.Scan(
+			&issue.ID, &issue.RepoID, &issue.Number, &issue.Title, &body, &issue.State, &stateReason,
+			&issue.UserID, &assigneeIDs, &labelIDs, &milestoneID, &issue.Locked, &lockReason, &issue.CommentsCount,
+			&issue.IsPullRequest, &issue.CreatedAt, &issue.UpdatedAt, &closedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if body.Valid {
+			issue.Body = body.String
+		}
+		if stateReason.Valid {
+			issue.StateReason = stateReason.String
+		}
+		issue.LockReason = lockReason.String
+		if assigneeIDs.Valid {
+			issue.AssigneeIDs = assigneeIDs.String
+		}
+		if labelIDs.Valid {
+			issue.LabelIDs = labelIDs.String
+		}
+		if milestoneID.Valid {
+			id := milestoneID.Int64
+			issue.MilestoneID = &id
+		}
+		if closedAt.Valid {
+			issue.ClosedAt = &closedAt.Time
+		}
+
+		issues = append(issues, issue)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+// ListAllPullRequests retrieves pull requests across all repositories for admin view
+func (s *GitHubStore) ListAllPullRequests(limit, offset int) ([]PullRequest, error) {
+	rows, err := s.db.Query(`
+		SELECT pr.issue_id, pr.head_repo_id, pr.head_ref, pr.base_repo_id, pr.base_ref, pr.merged, pr.mergeable, pr.rebaseable,
+			pr.merge_commit_sha, pr.merged_at, pr.merged_by_id, pr.draft, pr.review_comments_count, pr.commits_count,
+			pr.additions, pr.deletions, pr.changed_files
+		FROM github_pull_requests pr
+		JOIN github_issues i ON pr.issue_id = i.id
+		ORDER BY i.created_at DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prs []PullRequest
+	for rows.Next() {
+		var pr PullRequest
+		var mergeCommitSHA sql.NullString
+		var mergedAt sql.NullTime
+		var mergedByID sql.NullInt64
+
+		err := rows.Scan(
+			&pr.IssueID, &pr.HeadRepoID, &pr.HeadRef, &pr.BaseRepoID, &pr.BaseRef, &pr.Merged, &pr.Mergeable, &pr.Rebaseable,
+			&mergeCommitSHA, &mergedAt, &mergedByID, &pr.Draft, &pr.ReviewCommentsCount, &pr.CommitsCount,
+			&pr.Additions, &pr.Deletions, &pr.ChangedFiles,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if mergeCommitSHA.Valid {
+			pr.MergeCommitSHA = mergeCommitSHA.String
+		}
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		if mergedByID.Valid {
+			id := mergedByID.Int64
+			pr.MergedByID = &id
+		}
+
+		prs = append(prs, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+// ListAllWebhooks retrieves webhooks across all repositories for admin view
+func (s *GitHubStore) ListAllWebhooks(limit, offset int) ([]Webhook, error) {
+	rows, err := s.db.Query(`
+		SELECT id, repo_id, url, content_type, secret, events, active, delay_ms, created_at, updated_at
+		FROM github_webhooks
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		var webhook Webhook
+		var secret sql.NullString
+
+		err := rows.Scan(
+			&webhook.ID, &webhook.RepoID, &webhook.URL, &webhook.ContentType,
+			&secret, &webhook.Events, &webhook.Active, &webhook.DelayMs, &webhook.CreatedAt, &webhook.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if secret.Valid {
+			webhook.Secret = secret.String
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// SetRepoTemplate creates or replaces a repository template. Templates are
+// configured through the admin API, so no actor is recorded.
+func (s *GitHubStore) SetRepoTemplate(repoID int64, templateType, name, content string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO github_repo_templates (repo_id, template_type, name, content, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(repo_id, template_type, name) DO UPDATE SET content = excluded.content, updated_at = excluded.updated_at
+	`, repoID, templateType, name, content, s.now())
+	if err != nil {
+		return err
+	}
+	if err := auditIn(tx, "repo_template", repoID, core.AuditUpdate, 0, map[string]any{
+		"type": templateType,
+		"name": name,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteRepoTemplate removes a repository template
+func (s *GitHubStore) DeleteRepoTemplate(repoID int64, templateType, name string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM github_repo_templates WHERE repo_id = ? AND template_type = ? AND name = ?
+	`, repoID, templateType, name)
+	if err != nil {
+		return err
+	}
+	if err := auditIn(tx, "repo_template", repoID, core.AuditDelete, 0, map[string]any{
+		"type": templateType,
+		"name": name,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListRepoFiles lists the files written to a repository through the contents API, including deleted ones
+func (s *GitHubStore) ListRepoFiles(repoID int64) ([]*RepoFile, error) {
+	rows, err := s.db.Query(`
+		SELECT repo_id, path, content, deleted FROM github_repo_files WHERE repo_id = ? ORDER BY path
+	`, repoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*RepoFile
+	for rows.Next() {
+		var f RepoFile
+		if err := rows.Scan(&f.RepoID, &f.Path, &f.Content, &f.Deleted); err != nil {
+			return nil, err
+		}
+		files = append(files, &f)
+	}
+	return files, rows.Err()
+}
+
+// CommitRepoFile writes or deletes a file and records the change as a commit
+// on the repository's default branch, which moves to the new commit. The
+// commit's parent, date, and SHA are filled in from the branch's current head.
+func (s *GitHubStore) CommitRepoFile(repo *Repository, file RepoFile, commit *GitCommit, actorID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // Will be no-op if tx.Commit() succeeds
+
+	var parent sql.NullString
+	err = tx.QueryRow(`
+		SELECT commit_sha FROM github_branches WHERE repo_id = ? AND name = ?
+	`, repo.ID, repo.DefaultBranch).Scan(&parent)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	commit.RepoID = repo.ID
+	commit.ParentSHA = parent.String
+	commit.CreatedAt = s.now()
+	commit.SHA = gitCommitSHA(commit)
+
+	_, err = tx.Exec(`
+		INSERT INTO github_repo_files (repo_id, path, content, deleted, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(repo_id, path) DO UPDATE SET content = excluded.content, deleted = excluded.deleted, updated_at = excluded.updated_at
+	`, repo.ID, file.Path, file.Content, file.Deleted, commit.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO github_commits (sha, repo_id, author_login, author_name, author_email, committer_login, message, parent_sha, tree_sha, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, commit.SHA, repo.ID, commit.AuthorLogin, commit.AuthorName, commit.AuthorEmail, commit.CommitterLogin,
+		commit.Message, nullString(commit.ParentSHA), commit.TreeSHA, commit.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO github_branches (repo_id, name, commit_sha, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(repo_id, name) DO UPDATE SET commit_sha = excluded.commit_sha
+	`, repo.ID, repo.DefaultBranch, commit.SHA, commit.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE github_repositories SET pushed_at = ?, updated_at = ? WHERE id = ?
+	`, commit.CreatedAt, commit.CreatedAt, repo.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	action := core.AuditUpdate
+	if file.Deleted {
+		action = core.AuditDelete
+	}
+	return s.audit("repo_file", repo.ID, action, actorID, map[string]any{"path": file.Path, "commit": commit.SHA})
+}
+
+// GetRepoTemplate gets one repository template
+func (s *GitHubStore) GetRepoTemplate(repoID int64, templateType, name string) (*RepoTemplate, error) {
+	t := RepoTemplate{RepoID: repoID, Type: templateType, Name: name}
+	err := s.db.QueryRow(`
+		SELECT content, updated_at FROM github_repo_templates
+		WHERE repo_id = ? AND template_type = ? AND name = ?
+	`, repoID, templateType, name).Scan(&t.Content, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListRepoTemplates lists a repository's templates of one type by name
+func (s *GitHubStore) ListRepoTemplates(repoID int64, templateType string) ([]*RepoTemplate, error) {
+	rows, err := s.db.Query(`
+		SELECT name, content, updated_at FROM github_repo_templates
+		WHERE repo_id = ? AND template_type = ?
+		ORDER BY name
+	`, repoID, templateType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*RepoTemplate
+	for rows.Next() {
+		t := RepoTemplate{RepoID: repoID, Type: templateType}
+		if err := rows.Scan(&t.Name, &t.Content, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, &t)
+	}
+	return templates, rows.Err()
+}
+
+// DefaultTemplateBody returns the body of a repository's default template
+// of one type, or "" when it has none
+func (s *GitHubStore) DefaultTemplateBody(repoID int64, templateType string) (string, error) {
+	t, err := s.GetRepoTemplate(repoID, templateType, "")
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return t.Content, nil
+}
+
+// ListRepoTopics lists a repository's topics in the order they were set
+func (s *GitHubStore) ListRepoTopics(repoID int64) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT topic FROM github_repo_topics WHERE repo_id = ? ORDER BY position
+	`, repoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	topics := []string{}
+	for rows.Next() {
+		var topic string
+		if err := rows.Scan(&topic); err != nil {
+			return nil, err
+		}
+		topics = append(topics, topic)
+	}
+	return topics, rows.Err()
+}
+
+// ReplaceRepoTopics replaces all of a repository's topics
+func (s *GitHubStore) ReplaceRepoTopics(repoID int64, topics []string, actorID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM github_repo_topics WHERE repo_id = ?`, repoID); err != nil {
+		return err
+	}
+	for i, topic := range topics {
+		if _, err := tx.Exec(`
+			INSERT INTO github_repo_topics (repo_id, topic, position) VALUES (?, ?, ?)
+		`, repoID, topic, i); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE github_repositories SET updated_at = ? WHERE id = ?`, s.now(), repoID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return s.audit("repository", repoID, core.AuditUpdate, actorID, map[string]any{"topics": topics})
+}
+
+// GetComputedStats returns a repository's cached statistics of one kind as
+// JSON, or sql.ErrNoRows when they haven't been computed yet
+func (s *GitHubStore) GetComputedStats(repoID int64, kind string) (json.RawMessage, error) {
+	var data string
+	err := s.db.QueryRow(`
+		SELECT data FROM github_computed_stats WHERE repo_id = ? AND kind = ?
+	`, repoID, kind).Scan(&data)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+// SaveComputedStats caches a repository's statistics of one kind
+func (s *GitHubStore) SaveComputedStats(repoID int64, kind string, data json.RawMessage) error {
+	_, err := s.db.Exec(`
+		INSERT INTO github_computed_stats (repo_id, kind, data, computed_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(repo_id, kind) DO UPDATE SET data = excluded.data, computed_at = excluded.computed_at
+	`, repoID, kind, string(data), s.now())
+	return err
+}
+
+// GetUserByLogin retrieves a user by login
+func (s *GitHubStore) GetUserByLogin(login string) (*User, error) {
+	var id int64
+	if err := s.db.QueryRow(`SELECT id FROM github_users WHERE login = ?`, login).Scan(&id); err != nil {
+		return nil, err
+	}
+	return s.GetUserByID(id)
+}
+
+// AddCollaborator gives a user access to a repository, or changes the
+// permission of an existing collaborator
+func (s *GitHubStore) AddCollaborator(repoID, userID int64, permission string, actorID int64) error {
+	_, err := s.db.Exec(`
+		INSERT INTO github_repo_collaborators (repo_id, user_id, permission, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (repo_id, user_id) DO UPDATE SET permission = excluded.permission
+	`, repoID, userID, permission, s.now())
+	if err != nil {
 		return err
 	}
+	return s.audit("repository", repoID, core.AuditUpdate, actorID, map[string]any{"collaborator_id": userID, "permission": permission})
+}
 
-	now := time.Now()
-	webhook.UpdatedAt = now
+// RemoveCollaborator takes a user's access to a repository away, reporting
+// whether they were a collaborator
+func (s *GitHubStore) RemoveCollaborator(repoID, userID, actorID int64) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM github_repo_collaborators WHERE repo_id = ? AND user_id = ?`, repoID, userID)
+	if err != nil {
+		return false, err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil || removed == 0 {
+		return false, err
+	}
+	return true, s.audit("repository", repoID, core.AuditUpdate, actorID, map[string]any{"removed_collaborator_id": userID})
+}
+
+// ListCollaborators lists a repository's collaborators in the order they were added
+func (s *GitHubStore) ListCollaborators(repoID int64) ([]*Collaborator, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.login, u.name, u.email, u.avatar_url, u.type, u.created_at, u.updated_at, c.permission
+		FROM github_repo_collaborators c
+		JOIN github_users u ON u.id = c.user_id
+		WHERE c.repo_id = ?
+		ORDER BY c.created_at, u.id
+	`, repoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collaborators []*Collaborator
+	for rows.Next() {
+		var c Collaborator
+		var name, email, avatarURL sql.NullString
+		if err := rows.Scan(&c.ID, &c.Login, &name, &email, &avatarURL, &c.Type, &c.CreatedAt, &c.UpdatedAt, &c.Permission); err != nil {
+			return nil, err
+		}
+		c.Name = name.String
+		c.Email = email.String
+		c.AvatarURL = avatarURL.String
+		collaborators = append(collaborators, &c)
+	}
+	return collaborators, rows.Err()
+}
+
+// attachTopics loads the topics of each repository
+func (s *GitHubStore) attachTopics(repos ...*Repository) error {
+	for _, repo := range repos {
+		topics, err := s.ListRepoTopics(repo.ID)
+		if err != nil {
+			return err
+		}
+		repo.Topics = topics
+	}
+	return nil
+}
+
+const workflowColumns = `id, repo_id, name, path, state, created_at, updated_at`
+
+// CreateWorkflow adds a workflow file to a repository
+func (s *GitHubStore) CreateWorkflow(repoID int64, name, path string) (*Workflow, error) {
+	now := s.now()
+	result, err := s.db.Exec(`
+		INSERT INTO github_workflows (repo_id, name, path, state, created_at, updated_at)
+		VALUES (?, ?, ?, 'active', ?, ?)
+	`, repoID, name, path, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit("workflow", id, core.AuditCreate, 0, map[string]any{"repo_id": repoID, "path": path}); err != nil {
+		return nil, err
+	}
+
+	return &Workflow{ID: id, RepoID: repoID, Name: name, Path: path, State: "active", CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// ListWorkflows lists a repository's workflows in creation order
+func (s *GitHubStore) ListWorkflows(repoID int64) ([]*Workflow, error) {
+	return s.queryWorkflows(`SELECT `+workflowColumns+` FROM github_workflows WHERE repo_id = ? ORDER BY id`, repoID)
+}
+
+// GetWorkflow finds a workflow by its ID or, as the API allows, its file
+// name (e.g. "ci.yml")
+func (s *GitHubStore) GetWorkflow(repoID int64, idOrFile string) (*Workflow, error) {
+	workflows, err := s.queryWorkflows(`
+		SELECT `+workflowColumns+` FROM github_workflows
+		WHERE repo_id = ? AND (CAST(id AS TEXT) = ? OR path = ? OR path LIKE ?)
+		ORDER BY id LIMIT 1
+	`, repoID, idOrFile, idOrFile, "%/"+idOrFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(workflows) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return workflows[0], nil
+}
+
+func (s *GitHubStore) queryWorkflows(query string, args ...interface{}) ([]*Workflow, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workflows []*Workflow
+	for rows.Next() {
+		var wf Workflow
+		if err := rows.Scan(&wf.ID, &wf.RepoID, &wf.Name, &wf.Path, &wf.State, &wf.CreatedAt, &wf.UpdatedAt); err != nil {
+			return nil, err
+		}
+		workflows = append(workflows, &wf)
+	}
+	return workflows, rows.Err()
+}
+
+const workflowRunColumns = `id, repo_id, workflow_id, run_number, event, head_branch, head_sha, status,
+	conclusion, actor_id, created_at, updated_at, started_at, completed_at, inputs`
+
+// CreateWorkflowRun records a new run, numbering it after the workflow's
+// previous runs. ID, RunNumber, and the timestamps are filled in.
+func (s *GitHubStore) CreateWorkflowRun(run *WorkflowRun) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var maxNumber sql.NullInt64
+	if err := tx.QueryRow(`SELECT MAX(run_number) FROM github_workflow_runs WHERE workflow_id = ?`, run.WorkflowID).Scan(&maxNumber); err != nil {
+		return err
+	}
+	run.RunNumber = maxNumber.Int64 + 1
+
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = s.now()
+	}
+	run.UpdatedAt = run.CreatedAt
+	result, err := tx.Exec(`
+		INSERT INTO github_workflow_runs (repo_id, workflow_id, run_number, event, head_branch, head_sha, status,
+			conclusion, actor_id, created_at, updated_at, started_at, completed_at, inputs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.RepoID, run.WorkflowID, run.RunNumber, run.Event, run.HeadBranch, run.HeadSHA, run.Status,
+		nullString(run.Conclusion), run.ActorID, run.CreatedAt, run.UpdatedAt, run.StartedAt, run.CompletedAt, nullString(run.Inputs))
+	if err != nil {
+		return err
+	}
+	if run.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return s.audit("workflow_run", run.ID, core.AuditCreate, run.ActorID, map[string]any{"workflow_id": run.WorkflowID, "head_sha": run.HeadSHA, "status": run.Status})
+}
 
+// UpdateWorkflowRun saves a run's status, conclusion, and timestamps
+func (s *GitHubStore) UpdateWorkflowRun(run *WorkflowRun) error {
+	run.UpdatedAt = s.now()
 	_, err := s.db.Exec(`
-		UPDATE github_webhooks
-		SET url = ?, content_type = ?, secret = ?, events = ?, active = ?, updated_at = ?
+		UPDATE github_workflow_runs
+		SET status = ?, conclusion = ?, updated_at = ?, started_at = ?, completed_at = ?
 		WHERE id = ?
-	`, webhook.URL, webhook.ContentType, webhook.Secret, webhook.Events, webhook.Active, webhook.UpdatedAt, webhook.ID)
+	`, run.Status, nullString(run.Conclusion), run.UpdatedAt, run.StartedAt, run.CompletedAt, run.ID)
+	if err != nil {
+		return err
+	}
+	return s.audit("workflow_run", run.ID, core.AuditUpdate, 0, map[string]any{"status": run.Status, "conclusion": run.Conclusion})
+}
+
+// GetWorkflowRun retrieves a run by ID within a repository
+func (s *GitHubStore) GetWorkflowRun(repoID, runID int64) (*WorkflowRun, error) {
+	runs, err := s.queryWorkflowRuns(`SELECT `+workflowRunColumns+` FROM github_workflow_runs WHERE repo_id = ? AND id = ?`, repoID, runID)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return runs[0], nil
+}
+
+// WorkflowRunFilter narrows a run listing; empty fields match everything.
+// Status matches either a run's status or its conclusion, as the API does.
+type WorkflowRunFilter struct {
+	WorkflowID int64
+	Branch     string
+	Event      string
+	Status     string
+	HeadSHA    string
+}
+
+// ListWorkflowRuns lists a repository's runs, newest first, returning one
+// page and the total number of matching runs
+func (s *GitHubStore) ListWorkflowRuns(repoID int64, filter WorkflowRunFilter, limit, offset int) ([]*WorkflowRun, int, error) {
+	where := ` WHERE repo_id = ?`
+	args := []interface{}{repoID}
+	if filter.WorkflowID != 0 {
+		where += ` AND workflow_id = ?`
+		args = append(args, filter.WorkflowID)
+	}
+	if filter.Branch != "" {
+		where += ` AND head_branch = ?`
+		args = append(args, filter.Branch)
+	}
+	if filter.Event != "" {
+		where += ` AND event = ?`
+		args = append(args, filter.Event)
+	}
+	if filter.Status != "" {
+		where += ` AND (status = ? OR conclusion = ?)`
+		args = append(args, filter.Status, filter.Status)
+	}
+	if filter.HeadSHA != "" {
+		where += ` AND head_sha = ?`
+		args = append(args, filter.HeadSHA)
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM github_workflow_runs`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	runs, err := s.queryWorkflowRuns(`SELECT `+workflowRunColumns+` FROM github_workflow_runs`+where+
+		` ORDER BY id DESC LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	return runs, total, err
+}
+
+func (s *GitHubStore) queryWorkflowRuns(query string, args ...interface{}) ([]*WorkflowRun, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*WorkflowRun
+	for rows.Next() {
+		var run WorkflowRun
+		var conclusion, inputs sql.NullString
+		var actorID sql.NullInt64
+		var startedAt, completedAt sql.NullTime
+		if err := rows.Scan(&run.ID, &run.RepoID, &run.WorkflowID, &run.RunNumber, &run.Event, &run.HeadBranch,
+			&run.HeadSHA, &run.Status, &conclusion, &actorID, &run.CreatedAt, &run.UpdatedAt, &startedAt, &completedAt, &inputs); err != nil {
+			return nil, err
+		}
+		run.Conclusion = conclusion.String
+		run.Inputs = inputs.String
+		run.ActorID = actorID.Int64
+		if startedAt.Valid {
+			run.StartedAt = &startedAt.Time
+		}
+		if completedAt.Valid {
+			run.CompletedAt = &completedAt.Time
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
+
+// ListWorkflowDispatchRuns lists the most recent workflow_dispatch runs
+// across all repositories, newest first
+func (s *GitHubStore) ListWorkflowDispatchRuns(limit int) ([]*WorkflowRun, error) {
+	return s.queryWorkflowRuns(`SELECT `+workflowRunColumns+` FROM github_workflow_runs
+		WHERE event = 'workflow_dispatch' ORDER BY id DESC LIMIT ?`, limit)
+}
+
+// CreateRepositoryDispatchEvent records a repository dispatch event with its
+// client payload, a JSON object
+func (s *GitHubStore) CreateRepositoryDispatchEvent(repoID int64, eventType, clientPayload string, actorID int64) (*RepositoryDispatchEvent, error) {
+	now := s.now()
+	result, err := s.db.Exec(`
+		INSERT INTO github_repository_dispatch_events (repo_id, event_type, client_payload, created_at)
+		VALUES (?, ?, ?, ?)
+	`, repoID, eventType, clientPayload, now)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit("repository_dispatch", id, core.AuditCreate, actorID, map[string]any{"repo_id": repoID, "event_type": eventType}); err != nil {
+		return nil, err
+	}
+	return &RepositoryDispatchEvent{ID: id, RepoID: repoID, EventType: eventType, ClientPayload: clientPayload, CreatedAt: now}, nil
+}
+
+// ListRepositoryDispatchEvents lists the most recent repository dispatch
+// events across all repositories, newest first
+func (s *GitHubStore) ListRepositoryDispatchEvents(limit int) ([]*RepositoryDispatchEvent, error) {
+	rows, err := s.db.Query(`
+		SELECT id, repo_id, event_type, client_payload, created_at
+		FROM github_repository_dispatch_events ORDER BY id DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*RepositoryDispatchEvent
+	for rows.Next() {
+		var event RepositoryDispatchEvent
+		if err := rows.Scan(&event.ID, &event.RepoID, &event.EventType, &event.ClientPayload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+// BranchExists reports whether a repository has a branch. The default
+// branch always exists, even before anything has been committed to it.
+func (s *GitHubStore) BranchExists(repo *Repository, branch string) (bool, error) {
+	if branch == repo.DefaultBranch {
+		return true, nil
+	}
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM github_branches WHERE repo_id = ? AND name = ?`, repo.ID, branch).Scan(&count)
+	return count > 0, err
+}
+
+// SetBranchProtection creates or replaces a branch's protection and marks
+// the branch protected
+func (s *GitHubStore) SetBranchProtection(bp *BranchProtection, actorID int64) error {
+	contexts := bp.StatusCheckContexts
+	if contexts == nil {
+		contexts = []string{}
+	}
+	encoded, err := json.Marshal(contexts)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := s.now()
+	_, err = tx.Exec(`
+		INSERT INTO github_branch_protection (repo_id, branch, require_reviews, required_approving_review_count,
+			dismiss_stale_reviews, require_code_owner_reviews, require_status_checks, strict_status_checks,
+			status_check_contexts, enforce_admins, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(repo_id, branch) DO UPDATE SET
+			require_reviews = excluded.require_reviews,
+			required_approving_review_count = excluded.required_approving_review_count,
+			dismiss_stale_reviews = excluded.dismiss_stale_reviews,
+			require_code_owner_reviews = excluded.require_code_owner_reviews,
+			require_status_checks = excluded.require_status_checks,
+			strict_status_checks = excluded.strict_status_checks,
+			status_check_contexts = excluded.status_check_contexts,
+			enforce_admins = excluded.enforce_admins,
+			updated_at = excluded.updated_at
+	`, bp.RepoID, bp.Branch, bp.RequireReviews, bp.RequiredApprovingReviewCount,
+		bp.DismissStaleReviews, bp.RequireCodeOwnerReviews, bp.RequireStatusChecks, bp.StrictStatusChecks,
+		string(encoded), bp.EnforceAdmins, now, now)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE github_branches SET protected = 1 WHERE repo_id = ? AND name = ?`, bp.RepoID, bp.Branch); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	saved, err := s.GetBranchProtection(bp.RepoID, bp.Branch)
+	if err != nil {
+		return err
+	}
+	*bp = *saved
+	return s.audit("branch_protection", bp.ID, core.AuditUpdate, actorID, map[string]any{
+		"branch":                          bp.Branch,
+		"required_approving_review_count": bp.RequiredApprovingReviewCount,
+		"status_check_contexts":           bp.StatusCheckContexts,
+	})
+}
+
+// GetBranchProtection returns a branch's protection, or sql.ErrNoRows when
+// the branch isn't protected
+func (s *GitHubStore) GetBranchProtection(repoID int64, branch string) (*BranchProtection, error) {
+	var bp BranchProtection
+	var contexts string
+	err := s.db.QueryRow(`
+		SELECT id, repo_id, branch, require_reviews, required_approving_review_count, dismiss_stale_reviews,
+			require_code_owner_reviews, require_status_checks, strict_status_checks, status_check_contexts,
+			enforce_admins, created_at, updated_at
+		FROM github_branch_protection WHERE repo_id = ? AND branch = ?
+	`, repoID, branch).Scan(&bp.ID, &bp.RepoID, &bp.Branch, &bp.RequireReviews, &bp.RequiredApprovingReviewCount,
+		&bp.DismissStaleReviews, &bp.RequireCodeOwnerReviews, &bp.RequireStatusChecks, &bp.StrictStatusChecks,
+		&contexts, &bp.EnforceAdmins, &bp.CreatedAt, &bp.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(contexts), &bp.StatusCheckContexts); err != nil {
+		return nil, err
+	}
+	return &bp, nil
+}
+
+// DeleteBranchProtection removes a branch's protection, returning
+// sql.ErrNoRows when the branch wasn't protected
+func (s *GitHubStore) DeleteBranchProtection(repoID int64, branch string, actorID int64) error {
+	bp, err := s.GetBranchProtection(repoID, branch)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`DELETE FROM github_branch_protection WHERE id = ?`, bp.ID); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`UPDATE github_branches SET protected = 0 WHERE repo_id = ? AND name = ?`, repoID, branch); err != nil {
+		return err
+	}
+	return s.audit("branch_protection", bp.ID, core.AuditDelete, actorID, map[string]any{"branch": branch})
+}
+
+// maskSecret hides all but the first four characters of a secret
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-4)
+}
+
+// CreateSecretScanningAlert records an open alert for a secret found in a
+// repository, numbered after the repository's previous alerts. Only the
+// masked secret is stored.
+func (s *GitHubStore) CreateSecretScanningAlert(repoID int64, secretType, secret string) (*SecretScanningAlert, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var maxNumber sql.NullInt64
+	if err := tx.QueryRow(`SELECT MAX(number) FROM github_secret_scanning_alerts WHERE repo_id = ?`, repoID).Scan(&maxNumber); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	alert := &SecretScanningAlert{
+		RepoID:     repoID,
+		Number:     int(maxNumber.Int64) + 1,
+		State:      "open",
+		SecretType: secretType,
+		Secret:     maskSecret(secret),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	result, err := tx.Exec(`
+		INSERT INTO github_secret_scanning_alerts (repo_id, number, state, secret_type, secret, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, alert.RepoID, alert.Number, alert.State, alert.SecretType, alert.Secret, alert.CreatedAt, alert.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if alert.ID, err = result.LastInsertId(); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return alert, s.audit("secret_scanning_alert", alert.ID, core.AuditCreate, 0, map[string]any{"number": alert.Number, "secret_type": alert.SecretType})
+}
+
+// UpdateSecretScanningAlert saves an alert's state and resolution
+func (s *GitHubStore) UpdateSecretScanningAlert(alert *SecretScanningAlert) error {
+	alert.UpdatedAt = s.now()
+	var resolvedBy sql.NullInt64
+	if alert.ResolvedByID != 0 {
+		resolvedBy = sql.NullInt64{Int64: alert.ResolvedByID, Valid: true}
+	}
+	_, err := s.db.Exec(`
+		UPDATE github_secret_scanning_alerts
+		SET state = ?, resolution = ?, resolved_by_id = ?, updated_at = ?, resolved_at = ?
+		WHERE id = ?
+	`, alert.State, nullString(alert.Resolution), resolvedBy, alert.UpdatedAt, alert.ResolvedAt, alert.ID)
+	if err != nil {
+		return err
+	}
+	return s.audit("secret_scanning_alert", alert.ID, core.AuditUpdate, alert.ResolvedByID, map[string]any{"state": alert.State, "resolution": alert.Resolution})
+}
+
+// GetSecretScanningAlert retrieves an alert by number within a repository
+func (s *GitHubStore) GetSecretScanningAlert(repoID int64, number int) (*SecretScanningAlert, error) {
+	alerts, err := s.querySecretScanningAlerts(`
+		SELECT id, repo_id, number, state, secret_type, secret, resolution, resolved_by_id, created_at, updated_at, resolved_at
+		FROM github_secret_scanning_alerts
+		WHERE repo_id = ? AND number = ?
+	`, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return alerts[0], nil
+}
+
+// ListSecretScanningAlerts lists a repository's alerts, newest first,
+// optionally filtered by state and by any of the given secret types
+func (s *GitHubStore) ListSecretScanningAlerts(repoID int64, state string, secretTypes []string) ([]*SecretScanningAlert, error) {
+	query := `
+		SELECT id, repo_id, number, state, secret_type, secret, resolution, resolved_by_id, created_at, updated_at, resolved_at
+		FROM github_secret_scanning_alerts
+		WHERE repo_id = ?`
+	args := []interface{}{repoID}
+	if state != "" {
+		query += ` AND state = ?`
+		args = append(args, state)
+	}
+	if len(secretTypes) > 0 {
+		query += ` AND secret_type IN (?` + strings.Repeat(`, ?`, len(secretTypes)-1) + `)`
+		for _, secretType := range secretTypes {
+			args = append(args, secretType)
+		}
+	}
+	query += ` ORDER BY number DESC`
+
+	return s.querySecretScanningAlerts(query, args...)
+}
+
+func (s *GitHubStore) querySecretScanningAlerts(query string, args ...interface{}) ([]*SecretScanningAlert, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	return err
+	var alerts []*SecretScanningAlert
+	for rows.Next() {
+		var alert SecretScanningAlert
+		var resolution sql.NullString
+		var resolvedBy sql.NullInt64
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&alert.ID, &alert.RepoID, &alert.Number, &alert.State, &alert.SecretType, &alert.Secret,
+			&resolution, &resolvedBy, &alert.CreatedAt, &alert.UpdatedAt, &resolvedAt); err != nil {
+			return nil, err
+		}
+		alert.Resolution = resolution.String
+		alert.ResolvedByID = resolvedBy.Int64
+		if resolvedAt.Valid {
+			alert.ResolvedAt = &resolvedAt.Time
+		}
+		alerts = append(alerts, &alert)
+	}
+	return alerts, rows.Err()
 }
 
-// DeleteWebhook deletes a webhook
-func (s *GitHubStore) DeleteWebhook(webhookID int64) error {
-	_, err := s.db.Exec(`DELETE FROM github_webhooks WHERE id = ?`, webhookID)
+// SetRepositoryLanguage records a repository's primary language
+func (s *GitHubStore) SetRepositoryLanguage(repoID int64, language string) error {
+	_, err := s.db.Exec(`UPDATE github_repositories SET language = ? WHERE id = ?`, nullString(language), repoID)
 	return err
 }
 
-// CreateWebhookDelivery logs a webhook delivery attempt
-func (s *GitHubStore) CreateWebhookDelivery(webhookID int64, eventType, payload string, statusCode int, errorMsg string) error {
-	_, err := s.db.Exec(`
-		INSERT INTO github_webhook_deliveries (webhook_id, event_type, payload, delivered_at, status_code, error_message)
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?, ?)
-	`, webhookID, eventType, payload, statusCode, errorMsg)
-
+// SetRepositoryStars sets a repository's star count; watchers follow stars
+// as they do on GitHub
+func (s *GitHubStore) SetRepositoryStars(repoID int64, stars int) error {
+	_, err := s.db.Exec(`UPDATE github_repositories SET stargazers_count = ?, watchers_count = ? WHERE id = ?`, stars, stars, repoID)
 	return err
 }
 
-// eventMatches checks if an event type matches any event in a comma-separated list
-// Returns true if the eventType exactly matches one of the events in the list
-func eventMatches(eventsList, eventType string) bool {
-	// Split by comma and check for exact matches
-	events := strings.Split(eventsList, ",")
-	for _, event := range events {
-		// Trim whitespace and compare
-		if strings.TrimSpace(event) == eventType {
-			return true
-		}
-	}
-	return false
+// RepositorySearch narrows a repository search; empty fields match
+// everything. Every term must appear in the name or description. Private
+// repositories only match for their owner, ViewerID.
+type RepositorySearch struct {
+	Terms    []string
+	Owner    string
+	Language string
+	ViewerID int64
+	Sort     string
+	Order    string
 }
 
-// GetActiveWebhooksForEvent gets all active webhooks for a repo that subscribe to an event
-func (s *GitHubStore) GetActiveWebhooksForEvent(repoID int64, eventType string) ([]*Webhook, error) {
-	rows, err := s.db.Query(`
-		SELECT id, repo_id, url, content_type, secret, events, active, created_at, updated_at
-		FROM github_webhooks
-		WHERE repo_id = ? AND active = 1
-	`, repoID)
+// repositorySearchSorts maps the sort parameter of GET /search/repositories to columns
+var repositorySearchSorts = map[string]string{
+	"stars":   "r.stargazers_count",
+	"forks":   "r.forks_count",
+	"updated": "r.updated_at",
+}
 
-	if err != nil {
-		return nil, err
+// SearchRepositories returns one page of repositories matching search and
+// the total number of matches. Without a sort, exact name matches come
+// first, then the most starred.
+func (s *GitHubStore) SearchRepositories(search RepositorySearch, limit, offset int) ([]*Repository, int, error) {
+	where := ` WHERE (r.private = 0 OR r.owner_id = ?)`
+	args := []interface{}{search.ViewerID}
+	for _, term := range search.Terms {
+		pattern := "%" + escapeLike(term) + "%"
+		where += ` AND (r.name LIKE ? ESCAPE '\' OR COALESCE(r.description, '') LIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern)
+	}
+	if search.Owner != "" {
+		where += ` AND u.login = ? COLLATE NOCASE`
+		args = append(args, search.Owner)
+	}
+	if search.Language != "" {
+		where += ` AND r.language = ? COLLATE NOCASE`
+		args = append(args, search.Language)
 	}
-	defer rows.Close()
-
-	var webhooks []*Webhook
-	for rows.Next() {
-		var webhook Webhook
-		var secret sql.NullString
-
-		err := rows.Scan(
-			&webhook.ID, &webhook.RepoID, &webhook.URL, &webhook.ContentType,
-			&secret, &webhook.Events, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
 
-		if secret.Valid {
-			webhook.Secret = secret.String
-		}
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM github_repositories r JOIN github_users u ON u.id = r.owner_id`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
 
-		// Check if this webhook subscribes to this event type
-		// Events are stored as comma-separated list
-		// Empty Events means subscribe to all events
-		if webhook.Events == "" || eventMatches(webhook.Events, eventType) {
-			webhooks = append(webhooks, &webhook)
+	order := "r.stargazers_count DESC, r.id ASC"
+	if column, ok := repositorySearchSorts[search.Sort]; ok {
+		direction := "DESC"
+		if search.Order == "asc" {
+			direction = "ASC"
 		}
+		order = column + " " + direction + ", r.id ASC"
+	} else if len(search.Terms) > 0 {
+		order = "(r.name = ? COLLATE NOCASE) DESC, " + order
+		args = append(args, strings.Join(search.Terms, " "))
 	}
 
-	return webhooks, rows.Err()
-}
-
-// ListAllRepositories retrieves repositories across all accounts for admin view
-func (s *GitHubStore) ListAllRepositories(limit, offset int) ([]Repository, error) {
 	rows, err := s.db.Query(`
-		SELECT id, owner_id, name, full_name, description, private, default_branch, fork, archived, disabled,
-			stargazers_count, watchers_count, forks_count, open_issues_count,
-			created_at, updated_at, pushed_at
-		FROM github_repositories
-		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?
-	`, limit, offset)
+		SELECT r.id, r.owner_id, r.name, r.full_name, r.description, r.private, r.default_branch, r.fork, r.archived, r.disabled,
+			r.stargazers_count, r.watchers_count, r.forks_count, r.open_issues_count,
+			r.created_at, r.updated_at, r.pushed_at, COALESCE(r.language, '')
+		FROM github_repositories r
+		JOIN github_users u ON u.id = r.owner_id`+where+`
+		ORDER BY `+order+` LIMIT ? OFFSET ?`, append(args, limit, offset)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var repos []Repository
+	var repos []*Repository
 	for rows.Next() {
 		var repo Repository
 		var description sql.NullString
@@ -1542,179 +3736,289 @@ func (s *GitHubStore) ListAllRepositories(limit, offset int) ([]Repository, erro
 			&repo.ID, &repo.OwnerID, &repo.Name, &repo.FullName, &description, &repo.Private,
 			&repo.DefaultBranch, &repo.Fork, &repo.Archived, &repo.Disabled,
 			&repo.StargazersCount, &repo.WatchersCount, &repo.ForksCount, &repo.OpenIssuesCount,
-			&repo.CreatedAt, &repo.UpdatedAt, &pushedAt,
+			&repo.CreatedAt, &repo.UpdatedAt, &pushedAt, &repo.Language,
 		)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
-		if description.Valid {
-			repo.Description = description.String
-		}
+		repo.Description = description.String
 		if pushedAt.Valid {
 			repo.PushedAt = &pushedAt.Time
 		}
-
-		repos = append(repos, repo)
+		repos = append(repos, &repo)
 	}
-
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	rows.Close()
 
-	return repos, nil
+	return repos, total, s.attachTopics(repos...)
 }
 
-// ListAllIssues retrieves issues across all repositories for admin view
-func (s *GitHubStore) ListAllIssues(limit, offset int) ([]Issue, error) {
+// UserSearch narrows a user search; every term must appear in the login or
+// name, and Type, when set, is User or Organization
+type UserSearch struct {
+	Terms []string
+	Type  string
+	Sort  string
+	Order string
+}
+
+// userSearchSorts maps the sort parameter of GET /search/users to ORDER BY expressions
+var userSearchSorts = map[string]string{
+	"joined":       "u.created_at",
+	"repositories": "(SELECT COUNT(*) FROM github_repositories r WHERE r.owner_id = u.id)",
+}
+
+// SearchUsers returns one page of users matching search and the total
+// number of matches. Without a sort, exact login matches come first.
+func (s *GitHubStore) SearchUsers(search UserSearch, limit, offset int) ([]*User, int, error) {
+	where := ` WHERE 1 = 1`
+	var args []interface{}
+	for _, term := range search.Terms {
+		pattern := "%" + escapeLike(term) + "%"
+		where += ` AND (u.login LIKE ? ESCAPE '\' OR COALESCE(u.name, '') LIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern)
+	}
+	if search.Type != "" {
+		where += ` AND u.type = ? COLLATE NOCASE`
+		args = append(args, search.Type)
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM github_users u`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	order := "u.id ASC"
+	if expr, ok := userSearchSorts[search.Sort]; ok {
+		direction := "DESC"
+		if search.Order == "asc" {
+			direction = "ASC"
+		}
+		order = expr + " " + direction + ", u.id ASC"
+	} else if len(search.Terms) > 0 {
+		order = "(u.login = ? COLLATE NOCASE) DESC, " + order
+		args = append(args, strings.Join(search.Terms, " "))
+	}
+
 	rows, err := s.db.Query(`
-		SELECT id, repo_id, number, title, body, state, state_reason, user_id, assignee_ids, label_ids, milestone_id,
-			locked, comments_count, is_pull_request, created_at, updated_at, closed_at
-		FROM github_issues
-		WHERE is_pull_request = 0
-		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?
-	`, limit, offset)
+		SELECT u.id, u.login, u.name, u.email, u.avatar_url, u.type, u.created_at, u.updated_at
+		FROM github_users u`+where+`
+		ORDER BY `+order+` LIMIT ? OFFSET ?`, append(args, limit, offset)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var issues []Issue
+	var users []*User
 	for rows.Next() {
-		var issue Issue
-		var body, stateReason, assigneeIDs, labelIDs sql.NullString
-		var milestoneID sql.NullInt64
-		var closedAt sql.NullTime
-
-		err := rows.Scan(
-			&issue.ID, &issue.RepoID, &issue.Number, &issue.Title, &body, &issue.State, &stateReason,
-			&issue.UserID, &assigneeIDs, &labelIDs, &milestoneID, &issue.Locked, &issue.CommentsCount,
-			&issue.IsPullRequest, &issue.CreatedAt, &issue.UpdatedAt, &closedAt,
-		)
-		if err != nil {
-			return nil, err
+		var user User
+		var name, email, avatarURL sql.NullString
+		if err := rows.Scan(&user.ID, &user.Login, &name, &email, &avatarURL, &user.Type, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, 0, err
 		}
+		user.Name = name.String
+		user.Email = email.String
+		user.AvatarURL = avatarURL.String
+		users = append(users, &user)
+	}
+	return users, total, rows.Err()
+}
 
-		if body.Valid {
-			issue.Body = body.String
-		}
-		if stateReason.Valid {
-			issue.StateReason = stateReason.String
-		}
-		if assigneeIDs.Valid {
-			issue.AssigneeIDs = assigneeIDs.String
-		}
-		if labelIDs.Valid {
-			issue.LabelIDs = labelIDs.String
-		}
-		if milestoneID.Valid {
-			id := milestoneID.Int64
-			issue.MilestoneID = &id
-		}
-		if closedAt.Valid {
-			issue.ClosedAt = &closedAt.Time
-		}
+// escapeLike escapes LIKE wildcards so a search term matches literally
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
 
-		issues = append(issues, issue)
-	}
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *GitHubStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db,
+		"github_users",
+		"github_tokens",
+		"github_app_installations",
+		"github_repositories",
+		"github_branches",
+		"github_commits",
+		"github_issues",
+		"github_pull_requests",
+		"github_comments",
+		"github_reviews",
+		"github_review_comments",
+		"github_issue_events",
+		"github_issue_references",
+		"github_reactions",
+		"github_commit_statuses",
+		"github_check_runs",
+		"github_secret_scanning_alerts",
+		"github_workflows",
+		"github_workflow_runs",
+		"github_repository_dispatch_events",
+		"github_branch_protection",
+		"github_computed_stats",
+		"github_repo_templates",
+		"github_repo_files",
+		"github_repo_topics",
+		"github_repo_collaborators",
+		"github_webhooks",
+		"github_webhook_deliveries",
+	)
+}
 
-	if err := rows.Err(); err != nil {
+// appSlug names the GitHub App that every installation belongs to
+const appSlug = "ish-app"
+
+// appID is the ID of the ish-app GitHub App
+const appID = 1
+
+// Installation is an installation of the GitHub App on a user or organization account
+type Installation struct {
+	ID                  int64
+	AccountID           int64
+	Permissions         map[string]string
+	RepositorySelection string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// appBotUser returns the bot user installation tokens act as, creating it
+// when the first token is minted
+func (s *GitHubStore) appBotUser() (*User, error) {
+	login := appSlug + "[bot]"
+	if user, err := s.GetUserByLogin(login); err == nil {
+		return user, nil
+	} else if err != sql.ErrNoRows {
 		return nil, err
 	}
 
-	return issues, nil
+	now := s.now()
+	result, err := s.db.Exec(`
+		INSERT INTO github_users (login, type, created_at, updated_at)
+		VALUES (?, 'Bot', ?, ?)
+	`, login, now, now)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUserByID(id)
 }
 
-// ListAllPullRequests retrieves pull requests across all repositories for admin view
-func (s *GitHubStore) ListAllPullRequests(limit, offset int) ([]PullRequest, error) {
-	rows, err := s.db.Query(`
-		SELECT pr.issue_id, pr.head_repo_id, pr.head_ref, pr.base_repo_id, pr.base_ref, pr.merged, pr.mergeable, pr.rebaseable,
-			pr.merge_commit_sha, pr.merged_at, pr.merged_by_id, pr.draft, pr.review_comments_count, pr.commits_count,
-			pr.additions, pr.deletions, pr.changed_files
-		FROM github_pull_requests pr
-		JOIN github_issues i ON pr.issue_id = i.id
-		ORDER BY i.created_at DESC
-		LIMIT ? OFFSET ?
-	`, limit, offset)
+// CreateInstallation installs the app on an account with the given permissions
+func (s *GitHubStore) CreateInstallation(accountID int64, permissions map[string]string) (*Installation, error) {
+	encoded, err := json.Marshal(permissions)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var prs []PullRequest
-	for rows.Next() {
-		var pr PullRequest
-		var mergeCommitSHA sql.NullString
-		var mergedAt sql.NullTime
-		var mergedByID sql.NullInt64
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-		err := rows.Scan(
-			&pr.IssueID, &pr.HeadRepoID, &pr.HeadRef, &pr.BaseRepoID, &pr.BaseRef, &pr.Merged, &pr.Mergeable, &pr.Rebaseable,
-			&mergeCommitSHA, &mergedAt, &mergedByID, &pr.Draft, &pr.ReviewCommentsCount, &pr.CommitsCount,
-			&pr.Additions, &pr.Deletions, &pr.ChangedFiles,
-		)
-		if err != nil {
-			return nil, err
-		}
+	now := s.now()
+	result, err := tx.Exec(`
+		INSERT INTO github_app_installations (account_id, permissions, repository_selection, created_at, updated_at)
+		VALUES (?, ?, 'all', ?, ?)
+	`, accountID, string(encoded), now, now)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := auditIn(tx, "installation", id, core.AuditCreate, accountID, map[string]any{"permissions": permissions}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetInstallation(id)
+}
 
-		if mergeCommitSHA.Valid {
-			pr.MergeCommitSHA = mergeCommitSHA.String
-		}
-		if mergedAt.Valid {
-			pr.MergedAt = &mergedAt.Time
-		}
-		if mergedByID.Valid {
-			id := mergedByID.Int64
-			pr.MergedByID = &id
-		}
+const installationColumns = `id, account_id, permissions, repository_selection, created_at, updated_at`
 
-		prs = append(prs, pr)
+func scanInstallation(row interface{ Scan(...any) error }) (*Installation, error) {
+	var inst Installation
+	var permissions string
+	if err := row.Scan(&inst.ID, &inst.AccountID, &permissions, &inst.RepositorySelection, &inst.CreatedAt, &inst.UpdatedAt); err != nil {
+		return nil, err
 	}
-
-	if err := rows.Err(); err != nil {
+	if err := json.Unmarshal([]byte(permissions), &inst.Permissions); err != nil {
 		return nil, err
 	}
+	return &inst, nil
+}
 
-	return prs, nil
+// GetInstallation returns an installation by ID
+func (s *GitHubStore) GetInstallation(id int64) (*Installation, error) {
+	return scanInstallation(s.db.QueryRow(`SELECT `+installationColumns+` FROM github_app_installations WHERE id = ?`, id))
 }
 
-// ListAllWebhooks retrieves webhooks across all repositories for admin view
-func (s *GitHubStore) ListAllWebhooks(limit, offset int) ([]Webhook, error) {
-	rows, err := s.db.Query(`
-		SELECT id, repo_id, url, content_type, secret, events, active, created_at, updated_at
-		FROM github_webhooks
-		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?
-	`, limit, offset)
+// ListInstallations returns every installation of the app, oldest first
+func (s *GitHubStore) ListInstallations() ([]Installation, error) {
+	rows, err := s.db.Query(`SELECT ` + installationColumns + ` FROM github_app_installations ORDER BY id`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var webhooks []Webhook
+	var installations []Installation
 	for rows.Next() {
-		var webhook Webhook
-		var secret sql.NullString
-
-		err := rows.Scan(
-			&webhook.ID, &webhook.RepoID, &webhook.URL, &webhook.ContentType,
-			&secret, &webhook.Events, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt,
-		)
+		inst, err := scanInstallation(rows)
 		if err != nil {
 			return nil, err
 		}
+		installations = append(installations, *inst)
+	}
+	return installations, rows.Err()
+}
 
-		if secret.Valid {
-			webhook.Secret = secret.String
-		}
-
-		webhooks = append(webhooks, webhook)
+// CreateInstallationToken mints a token that authenticates as the
+// installation's bot user until it expires
+func (s *GitHubStore) CreateInstallationToken(inst *Installation, permissions map[string]string, ttl time.Duration) (string, time.Time, error) {
+	bot, err := s.appBotUser()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	token, err := generateToken("ghs")
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	encoded, err := json.Marshal(permissions)
+	if err != nil {
+		return "", time.Time{}, err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", time.Time{}, err
 	}
+	defer tx.Rollback()
 
-	return webhooks, nil
+	now := s.now()
+	expiresAt := now.Add(ttl)
+	_, err = tx.Exec(`
+		INSERT INTO github_tokens (token, user_id, token_type, scopes, installation_id, expires_at, created_at, last_used_at)
+		VALUES (?, ?, 'installation', ?, ?, ?, ?, ?)
+	`, token, bot.ID, string(encoded), inst.ID, expiresAt, now, now)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	// The token itself is a secret, so only what it grants is logged
+	if err := auditIn(tx, "installation_token", inst.ID, core.AuditCreate, bot.ID, map[string]any{
+		"permissions": permissions,
+		"expires_at":  expiresAt,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
 }
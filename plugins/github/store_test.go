@@ -6,7 +6,10 @@ package github
 import (
 	"database/sql"
 	"testing"
+	"time"
 
+	"github.com/2389/ish/internal/migrations"
+	"github.com/2389/ish/plugins/core"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -15,6 +18,12 @@ func setupTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
+	// Each connection to :memory: is its own empty database, so background
+	// webhook deliveries must share the test's one connection
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
 	return db
 }
 
@@ -40,10 +49,18 @@ func TestNewGitHubStore(t *testing.T) {
 		"github_issues",
 		"github_pull_requests",
 		"github_comments",
+		"github_issue_events",
 		"github_reviews",
 		"github_review_comments",
 		"github_webhooks",
 		"github_webhook_deliveries",
+		"github_reactions",
+		"github_commit_statuses",
+		"github_check_runs",
+		"github_workflows",
+		"github_workflow_runs",
+		"github_repo_templates",
+		"github_repo_topics",
 	}
 
 	for _, table := range tables {
@@ -114,3 +131,31 @@ func TestValidateTokenNilStore(t *testing.T) {
 		t.Fatal("ValidateToken should return false when store is nil")
 	}
 }
+
+func TestFrozenClockIssueTimestamps(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+
+	frozen := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	store.SetClock(core.NewFrozenClock(frozen))
+
+	user, _ := store.GetOrCreateUser("alice", "ghp_token")
+	repo, _ := store.CreateRepository(user.ID, "clock-repo", "", false)
+
+	issue, err := store.CreateIssue(repo.ID, user.ID, "Frozen", "Body", false)
+	if err != nil {
+		t.Fatalf("CreateIssue failed: %v", err)
+	}
+	if !issue.CreatedAt.Equal(frozen) {
+		t.Errorf("Expected created_at %v, got %v", frozen, issue.CreatedAt)
+	}
+
+	stored, err := store.GetIssueByNumber(repo.ID, int(issue.Number))
+	if err != nil {
+		t.Fatalf("GetIssueByNumber failed: %v", err)
+	}
+	if !stored.CreatedAt.Equal(frozen) || !stored.UpdatedAt.Equal(frozen) {
+		t.Errorf("Expected stored timestamps %v, got created=%v updated=%v", frozen, stored.CreatedAt, stored.UpdatedAt)
+	}
+}
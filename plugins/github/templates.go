@@ -0,0 +1,163 @@
+// ABOUTME: Issue and pull request templates for GitHub repositories
+// ABOUTME: Seeds the default templates and lets the admin API configure them
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Paths GitHub reads templates from
+const (
+	issueTemplatePath       = ".github/ISSUE_TEMPLATE.md"
+	pullRequestTemplatePath = ".github/PULL_REQUEST_TEMPLATE.md"
+	issueTemplateDir        = ".github/ISSUE_TEMPLATE"
+)
+
+// defaultIssueTemplate and defaultPullRequestTemplate are seeded into every repository
+const defaultIssueTemplate = `## Description
+
+A clear description of the problem or request.
+
+## Steps to Reproduce
+
+1.
+2.
+3.
+
+## Expected Behavior
+
+## Actual Behavior
+`
+
+const defaultPullRequestTemplate = `## Summary
+
+What does this change and why?
+
+## Testing
+
+How was this verified?
+
+## Checklist
+
+- [ ] Tests added or updated
+- [ ] Documentation updated
+`
+
+// seedIssueTemplates are the named templates seeded into .github/ISSUE_TEMPLATE/
+var seedIssueTemplates = map[string]string{
+	"bug_report.md": `---
+name: Bug report
+about: Report something that isn't working
+labels: bug
+---
+
+## Describe the bug
+
+## To Reproduce
+
+## Expected behavior
+`,
+	"feature_request.md": `---
+name: Feature request
+about: Suggest an idea for this project
+labels: enhancement
+---
+
+## Problem
+
+## Proposed solution
+
+## Alternatives considered
+`,
+}
+
+// seedRepoTemplates gives a repository the default issue and pull request templates
+func (s *GitHubStore) seedRepoTemplates(repoID int64) error {
+	if err := s.SetRepoTemplate(repoID, TemplateTypeIssue, "", defaultIssueTemplate); err != nil {
+		return err
+	}
+	if err := s.SetRepoTemplate(repoID, TemplateTypePullRequest, "", defaultPullRequestTemplate); err != nil {
+		return err
+	}
+	for name, content := range seedIssueTemplates {
+		if err := s.SetRepoTemplate(repoID, TemplateTypeIssue, name, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templatePath is the contents API path a template is served at
+func templatePath(t *RepoTemplate) string {
+	switch {
+	case t.Type == TemplateTypePullRequest:
+		return pullRequestTemplatePath
+	case t.Name == "":
+		return issueTemplatePath
+	}
+	return issueTemplateDir + "/" + t.Name
+}
+
+// configureTemplate handles POST /admin/github/repos/{id}/templates. The body
+// names the template type ("issue" or "pull_request"), an optional file name
+// for templates in .github/ISSUE_TEMPLATE/, and the content; empty content
+// removes the template.
+func (p *GitHubPlugin) configureTemplate(w http.ResponseWriter, r *http.Request) {
+	repoID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid repository ID")
+		return
+	}
+	repo, err := p.store.GetRepositoryByID(repoID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	var req struct {
+		Type    string `json:"type"`
+		Name    string `json:"name"`
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Type != TemplateTypeIssue && req.Type != TemplateTypePullRequest {
+		writeError(w, http.StatusBadRequest, "type must be issue or pull_request")
+		return
+	}
+	if req.Name != "" && (req.Type != TemplateTypeIssue || strings.Contains(req.Name, "/")) {
+		writeError(w, http.StatusBadRequest, "name is only allowed for issue templates and cannot contain /")
+		return
+	}
+
+	if req.Content == "" {
+		if err := p.store.DeleteRepoTemplate(repo.ID, req.Type, req.Name); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to delete template")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err := p.store.SetRepoTemplate(repo.ID, req.Type, req.Name, req.Content); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to save template")
+		return
+	}
+
+	t := &RepoTemplate{RepoID: repo.ID, Type: req.Type, Name: req.Name, Content: req.Content}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"repository_id": repo.ID,
+		"type":          t.Type,
+		"name":          t.Name,
+		"path":          templatePath(t),
+		"content":       t.Content,
+	})
+}
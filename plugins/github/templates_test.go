@@ -0,0 +1,145 @@
+// ABOUTME: Tests for GitHub issue and pull request templates
+// ABOUTME: Covers body substitution, the contents API, and the admin configuration endpoint
+
+package github
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// serveGitHub sends one request through the plugin's routes as ghp_alice
+func serveGitHub(plugin *GitHubPlugin, method, path, body string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer ghp_alice")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTemplateSubstitutedForEmptyBody(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	// Without templates an empty body stays empty
+	w := serveGitHub(plugin, "POST", "/repos/alice/test-repo/issues", `{"title": "No template"}`)
+	var issue map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &issue)
+	if w.Code != http.StatusCreated || issue["body"] != "" {
+		t.Fatalf("Expected 201 with an empty body, got %d: %v", w.Code, issue["body"])
+	}
+
+	if err := store.seedRepoTemplates(repo.ID); err != nil {
+		t.Fatalf("seedRepoTemplates() error = %v", err)
+	}
+
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/issues", `{"title": "Templated"}`)
+	json.Unmarshal(w.Body.Bytes(), &issue)
+	if issue["body"] != defaultIssueTemplate {
+		t.Errorf("Issue body = %q, want the issue template", issue["body"])
+	}
+
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/issues", `{"title": "Written", "body": "My own words"}`)
+	json.Unmarshal(w.Body.Bytes(), &issue)
+	if issue["body"] != "My own words" {
+		t.Errorf("Issue body = %q, want the body as given", issue["body"])
+	}
+
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/pulls", `{"title": "PR", "head": "feature", "base": "main"}`)
+	var pr map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &pr)
+	if w.Code != http.StatusCreated || pr["body"] != defaultPullRequestTemplate {
+		t.Errorf("PR body = %q, want the pull request template (status %d)", pr["body"], w.Code)
+	}
+}
+
+func TestTemplateContents(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	store.seedRepoTemplates(repo.ID)
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github/PULL_REQUEST_TEMPLATE.md", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var file map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &file)
+	content, _ := base64.StdEncoding.DecodeString(file["content"].(string))
+	if string(content) != defaultPullRequestTemplate || file["encoding"] != "base64" || file["type"] != "file" {
+		t.Errorf("Unexpected template file: %v", file)
+	}
+	if file["sha"] != blobSHA(defaultPullRequestTemplate) {
+		t.Errorf("sha = %v, want the git blob SHA", file["sha"])
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github/ISSUE_TEMPLATE/", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var entries []map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &entries)
+	if len(entries) != 2 || entries[0]["path"] != ".github/ISSUE_TEMPLATE/bug_report.md" || entries[0]["content"] != nil {
+		t.Errorf("Unexpected directory listing: %v", entries)
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/README.md", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for other paths, got %d", w.Code)
+	}
+}
+
+func TestConfigureTemplate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	repo, _ := store.CreateRepository(alice.ID, "test-repo", "", false)
+	adminPath := "/admin/github/repos/" + strconv.FormatInt(repo.ID, 10) + "/templates"
+
+	w := serveGitHub(plugin, "POST", adminPath, `{"type": "issue", "content": "Custom issue template"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = serveGitHub(plugin, "POST", "/repos/alice/test-repo/issues", `{"title": "Templated"}`)
+	var issue map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &issue)
+	if issue["body"] != "Custom issue template" {
+		t.Errorf("Issue body = %q, want the configured template", issue["body"])
+	}
+
+	w = serveGitHub(plugin, "POST", adminPath, `{"type": "issue", "content": ""}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 removing a template, got %d", w.Code)
+	}
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/contents/.github/ISSUE_TEMPLATE.md", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after removing the template, got %d", w.Code)
+	}
+
+	w = serveGitHub(plugin, "POST", adminPath, `{"type": "wiki", "content": "x"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown type, got %d", w.Code)
+	}
+}
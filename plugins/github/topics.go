@@ -0,0 +1,90 @@
+// ABOUTME: Repository topic endpoints for GitHub repositories
+// ABOUTME: Reads and replaces topics, validating names against GitHub's rules
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// topicPattern matches GitHub's allowed topic names: lowercase letters,
+// numbers and hyphens, starting with a letter or number, at most 50 characters
+var topicPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+// maxTopics is the most topics a repository can have
+const maxTopics = 20
+
+// getTopics handles GET /repos/{owner}/{repo}/topics. Clients written for
+// the topics preview send Accept: application/vnd.github.mercy-preview+json;
+// like GitHub today, that media type is accepted but not required.
+func (p *GitHubPlugin) getTopics(w http.ResponseWriter, r *http.Request) {
+	owner := chi.URLParam(r, "owner")
+	repoName := chi.URLParam(r, "repo")
+
+	repo, err := p.store.GetRepositoryByFullName(owner + "/" + repoName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"names": repo.Topics})
+}
+
+// replaceTopics handles PUT /repos/{owner}/{repo}/topics. The names array
+// replaces every topic; an empty array clears them.
+func (p *GitHubPlugin) replaceTopics(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserFromContext(r)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "authentication context invalid")
+		return
+	}
+	owner := chi.URLParam(r, "owner")
+	repoName := chi.URLParam(r, "repo")
+
+	repo, err := p.store.GetRepositoryByFullName(owner + "/" + repoName)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	var req struct {
+		Names *[]string `json:"names"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Names == nil {
+		writeValidationError(w, "Repository", "names", "missing_field")
+		return
+	}
+
+	topics := []string{}
+	for _, name := range *req.Names {
+		if !topicPattern.MatchString(name) {
+			writeValidationError(w, "Repository", "topics", "invalid")
+			return
+		}
+		if !slices.Contains(topics, name) {
+			topics = append(topics, name)
+		}
+	}
+	if len(topics) > maxTopics {
+		writeValidationError(w, "Repository", "topics", "too_many")
+		return
+	}
+
+	if err := p.store.ReplaceRepoTopics(repo.ID, topics, user.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update topics")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"names": topics})
+}
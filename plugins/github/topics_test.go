@@ -0,0 +1,61 @@
+// ABOUTME: Tests for GitHub repository topics
+// ABOUTME: Covers setting, reading and validating topics and their place in the repository response
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestRepositoryTopics(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	w := serveGitHub(plugin, "GET", "/repos/alice/test-repo/topics", "")
+	if w.Code != http.StatusOK || w.Body.String() != "{\"names\":[]}\n" {
+		t.Fatalf("Expected no topics, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveGitHub(plugin, "PUT", "/repos/alice/test-repo/topics", `{"names": ["go", "api-emulator", "go"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo/topics", "")
+	var topics struct {
+		Names []string `json:"names"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &topics)
+	if !slices.Equal(topics.Names, []string{"go", "api-emulator"}) {
+		t.Errorf("names = %v, want [go api-emulator]", topics.Names)
+	}
+
+	w = serveGitHub(plugin, "GET", "/repos/alice/test-repo", "")
+	var repo struct {
+		Topics []string `json:"topics"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &repo)
+	if !slices.Equal(repo.Topics, []string{"go", "api-emulator"}) {
+		t.Errorf("repository topics = %v, want [go api-emulator]", repo.Topics)
+	}
+
+	for _, body := range []string{`{"names": ["Go"]}`, `{"names": ["-go"]}`, `{"names": ["go_lang"]}`, `{}`} {
+		w = serveGitHub(plugin, "PUT", "/repos/alice/test-repo/topics", body)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("PUT %s: expected 422, got %d", body, w.Code)
+		}
+	}
+
+	w = serveGitHub(plugin, "PUT", "/repos/alice/test-repo/topics", `{"names": []}`)
+	if w.Code != http.StatusOK || w.Body.String() != "{\"names\":[]}\n" {
+		t.Errorf("Expected topics cleared, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -5,15 +5,16 @@ package github
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
-	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 // isPrivateIP checks if an IP address is private or internal
@@ -106,6 +107,10 @@ func validateWebhookURL(urlStr string) error {
 	return nil
 }
 
+// checkDeliveryURL validates a webhook URL when a delivery is sent. Tests
+// replace it to deliver to local receivers.
+var checkDeliveryURL = validateWebhookURL
+
 // generateHMAC creates X-Hub-Signature-256 header value
 func generateHMAC(payload []byte, secret string) string {
 	mac := hmac.New(sha256.New, []byte(secret))
@@ -114,22 +119,17 @@ func generateHMAC(payload []byte, secret string) string {
 	return "sha256=" + signature
 }
 
-// fireWebhook sends an HTTP POST request to the webhook URL with the event payload
-// Validates URL at delivery time to prevent DNS rebinding attacks
-func fireWebhook(webhook *Webhook, eventType string, payload interface{}) error {
+// fireWebhook sends an HTTP POST request to the webhook URL with the event
+// payload, labelled with the delivery's ID. Validates URL at delivery time to
+// prevent DNS rebinding attacks.
+func fireWebhook(ctx context.Context, webhook *Webhook, eventType string, deliveryID int64, payloadBytes []byte) error {
 	// Validate URL at delivery time to prevent DNS rebinding attacks
-	if err := validateWebhookURL(webhook.URL); err != nil {
+	if err := checkDeliveryURL(webhook.URL); err != nil {
 		return fmt.Errorf("webhook URL validation failed at delivery: %w", err)
 	}
 
-	// Serialize payload to JSON
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
 	// Create HTTP request
-	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewReader(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -141,7 +141,7 @@ func fireWebhook(webhook *Webhook, eventType string, payload interface{}) error
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
 	req.Header.Set("X-GitHub-Event", eventType)
-	req.Header.Set("X-GitHub-Delivery", fmt.Sprintf("%d", webhook.ID))
+	req.Header.Set("X-GitHub-Delivery", fmt.Sprintf("%d", deliveryID))
 
 	// Add HMAC signature if secret is configured
 	if webhook.Secret != "" {
@@ -149,11 +149,8 @@ func fireWebhook(webhook *Webhook, eventType string, payload interface{}) error
 		req.Header.Set("X-Hub-Signature-256", signature)
 	}
 
-	// Send request with timeout
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-	resp, err := client.Do(req)
+	// Send request with the shared delivery timeout
+	resp, err := core.WebhookClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send webhook: %w", err)
 	}
@@ -166,3 +163,16 @@ func fireWebhook(webhook *Webhook, eventType string, payload interface{}) error
 
 	return nil
 }
+
+// deliveryOutcome maps a fireWebhook error to the status code and message
+// recorded in the delivery log. Timeouts log status 0 since no response arrived.
+func deliveryOutcome(err error) (int, string) {
+	switch {
+	case err == nil:
+		return 200, ""
+	case core.IsTimeout(err):
+		return 0, core.WebhookTimeoutError
+	default:
+		return 500, err.Error()
+	}
+}
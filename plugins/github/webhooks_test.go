@@ -4,7 +4,14 @@
 package github
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 func TestValidateWebhookURL(t *testing.T) {
@@ -129,3 +136,52 @@ func TestGenerateHMAC(t *testing.T) {
 		})
 	}
 }
+
+func TestDeliveryOutcome(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer receiver.Close()
+
+	core.SetWebhookTimeout(20 * time.Millisecond)
+	t.Cleanup(func() { core.SetWebhookTimeout(core.DefaultWebhookTimeout) })
+
+	_, timeoutErr := core.WebhookClient().Post(receiver.URL, "application/json", nil)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantMsg    string
+	}{
+		{"delivered", nil, 200, ""},
+		{"timeout", fmt.Errorf("failed to send webhook: %w", timeoutErr), 0, "timeout"},
+		{"rejected", errors.New("webhook returned status 404"), 500, "webhook returned status 404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, msg := deliveryOutcome(tt.err)
+			if status != tt.wantStatus || msg != tt.wantMsg {
+				t.Errorf("deliveryOutcome() = %d, %q, want %d, %q", status, msg, tt.wantStatus, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestCreateWebhookNegativeDelay(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store, _ := NewGitHubStore(db)
+	plugin := &GitHubPlugin{store: store}
+
+	alice, _ := store.GetOrCreateUser("alice", "ghp_alice")
+	store.CreateRepository(alice.ID, "test-repo", "", false)
+
+	w := serveGitHub(plugin, "POST", "/repos/alice/test-repo/hooks", `{"config": {"url": "https://example.com/hook", "delay_ms": -1}, "events": ["push"]}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a negative delay_ms, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := store.CreateWebhook(1, "https://example.com/hook", "json", "", nil, -1); err == nil {
+		t.Error("CreateWebhook() accepted a negative delay")
+	}
+}
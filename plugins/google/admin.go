@@ -0,0 +1,85 @@
+// ABOUTME: core.MailAdmin implementation for the Google plugin
+// ABOUTME: Lets the admin UI create, inspect, relabel, and delete Gmail messages
+
+package google
+
+import (
+	"context"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// adminUserID owns messages created from the admin UI, matching seeded data
+const adminUserID = "me"
+
+// CreateMailMessage implements core.MailAdmin
+func (p *GooglePlugin) CreateMailMessage(ctx context.Context, input core.MailMessageInput) (string, error) {
+	userID := input.UserID
+	if userID == "" {
+		userID = adminUserID
+	}
+	var msg *GmailMessageView
+	var err error
+	if input.Date.IsZero() {
+		msg, err = p.store.CreateGmailMessageFromForm(userID, input.From, input.To, input.Subject, input.Body, input.Labels)
+	} else {
+		msg, err = p.store.ImportGmailMessage(userID, input.From, input.To, input.Subject, input.Body, input.Date, input.Labels)
+	}
+	if err != nil {
+		return "", err
+	}
+	return msg.ID, nil
+}
+
+// GetMailMessage implements core.MailAdmin
+func (p *GooglePlugin) GetMailMessage(ctx context.Context, id string) (*core.MailMessage, error) {
+	userID, err := p.store.GetGmailMessageOwner(id)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := p.store.GetGmailMessageDetail(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make([]core.MailHeader, len(detail.Headers))
+	for i, h := range detail.Headers {
+		headers[i] = core.MailHeader{Name: h.Name, Value: h.Value}
+	}
+	return &core.MailMessage{
+		ID:           detail.ID,
+		UserID:       detail.UserID,
+		ThreadID:     detail.ThreadID,
+		Snippet:      detail.Snippet,
+		LabelIDs:     detail.LabelIDs,
+		InternalDate: detail.InternalDate,
+		Headers:      headers,
+		Body:         detail.Body,
+	}, nil
+}
+
+// DeleteMailMessage implements core.MailAdmin
+func (p *GooglePlugin) DeleteMailMessage(ctx context.Context, id string) error {
+	userID, err := p.store.GetGmailMessageOwner(id)
+	if err != nil {
+		return err
+	}
+	return p.store.DeleteGmailMessage(userID, id)
+}
+
+// SetMailMessageLabels implements core.MailAdmin
+func (p *GooglePlugin) SetMailMessageLabels(ctx context.Context, id string, labels []string) error {
+	userID, err := p.store.GetGmailMessageOwner(id)
+	if err != nil {
+		return err
+	}
+	if labels == nil {
+		labels = []string{}
+	}
+	return p.store.UpdateGmailMessageLabels(userID, id, labels)
+}
+
+// ListMailLabels implements core.MailAdmin
+func (p *GooglePlugin) ListMailLabels(ctx context.Context) ([]string, error) {
+	return p.store.ListGmailLabelIDs()
+}
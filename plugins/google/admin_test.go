@@ -0,0 +1,561 @@
+// ABOUTME: End-to-end tests for the Gmail admin pages backed by the Google plugin.
+// ABOUTME: Drives the admin create, view, relabel, delete, and bulk delete flows through HTTP.
+
+package google
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/admin"
+	"github.com/2389/ish/internal/store"
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// setupAdminRouter wires the registered Google plugin to a fresh database and mounts the admin routes
+func setupAdminRouter(t *testing.T) (*GooglePlugin, chi.Router) {
+	t.Helper()
+
+	registered, ok := core.Get("google")
+	if !ok {
+		t.Fatal("google plugin not registered")
+	}
+	p := registered.(*GooglePlugin)
+
+	// Share the store database with the plugin, as the server does
+	s, err := store.New(filepath.Join(t.TempDir(), "admin.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	if err := p.SetDB(s.GetDB()); err != nil {
+		t.Fatalf("failed to set database: %v", err)
+	}
+
+	r := chi.NewRouter()
+	admin.NewHandlers(s).RegisterRoutes(r)
+	return p, r
+}
+
+func adminRequest(r chi.Router, method, path string, form url.Values) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminGmailCreateFlow(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	w := adminRequest(r, "GET", "/admin/gmail/new", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for form, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `<option value="STARRED"`) {
+		t.Error("expected label multi-select in form")
+	}
+
+	form := url.Values{
+		"from":    {"alice@example.com"},
+		"to":      {"bob@example.com"},
+		"subject": {"Quarterly <report>"},
+		"body":    {"Numbers are up.\nSee attached."},
+		"labels":  {"INBOX", "IMPORTANT"},
+	}
+	w = adminRequest(r, "POST", "/admin/gmail", form)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303 after create, got %d: %s", w.Code, w.Body.String())
+	}
+	location := w.Header().Get("Location")
+	id := strings.TrimPrefix(location, "/admin/gmail/")
+	if id == "" || id == location {
+		t.Fatalf("expected redirect to message detail, got %q", location)
+	}
+
+	detail, err := p.store.GetGmailMessageDetail("me", id)
+	if err != nil {
+		t.Fatalf("created message not stored: %v", err)
+	}
+	if detail.To != "bob@example.com" || detail.Body != "Numbers are up.\nSee attached." {
+		t.Errorf("unexpected stored message: %+v", detail)
+	}
+	if len(detail.LabelIDs) != 2 || detail.LabelIDs[1] != "IMPORTANT" {
+		t.Errorf("expected INBOX and IMPORTANT labels, got %v", detail.LabelIDs)
+	}
+
+	w = adminRequest(r, "GET", location, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for detail, got %d", w.Code)
+	}
+	page := w.Body.String()
+	for _, want := range []string{"alice@example.com", "bob@example.com", "Quarterly &lt;report&gt;", "Numbers are up.\nSee attached."} {
+		if !strings.Contains(page, want) {
+			t.Errorf("detail page missing %q", want)
+		}
+	}
+
+	w = adminRequest(r, "PATCH", location+"/labels", url.Values{"labels": {"STARRED"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for label update, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "STARRED") {
+		t.Error("expected updated labels in response")
+	}
+	detail, _ = p.store.GetGmailMessageDetail("me", id)
+	if len(detail.LabelIDs) != 1 || detail.LabelIDs[0] != "STARRED" {
+		t.Errorf("expected only STARRED, got %v", detail.LabelIDs)
+	}
+}
+
+func TestAdminGmailCreateRequiresFields(t *testing.T) {
+	_, r := setupAdminRouter(t)
+
+	w := adminRequest(r, "POST", "/admin/gmail", url.Values{"body": {"no sender"}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without from/subject, got %d", w.Code)
+	}
+}
+
+func TestAdminGmailDeleteFlow(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	msg, err := p.store.CreateGmailMessageFromForm("me", "carol@example.com", "", "Delete me", "bye", []string{"INBOX"})
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	w := adminRequest(r, "DELETE", "/admin/gmail/"+msg.ID, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for delete, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := p.store.GetGmailMessage("me", msg.ID); err == nil {
+		t.Error("expected message to be deleted")
+	}
+
+	for _, req := range []struct{ method, path string }{
+		{"GET", "/admin/gmail/" + msg.ID},
+		{"DELETE", "/admin/gmail/" + msg.ID},
+		{"PATCH", "/admin/gmail/" + msg.ID + "/labels"},
+	} {
+		w = adminRequest(r, req.method, req.path, url.Values{})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected 404 after delete, got %d", req.method, req.path, w.Code)
+		}
+	}
+}
+
+// countRows returns the number of rows in a Google table
+func countRows(t *testing.T, p *GooglePlugin, table string) int {
+	t.Helper()
+	var n int
+	if err := p.store.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("failed to count %s: %v", table, err)
+	}
+	return n
+}
+
+// seedBulkDeleteData creates a message with an attachment plus one record of each other resource type
+func seedBulkDeleteData(t *testing.T, p *GooglePlugin) {
+	t.Helper()
+	msg, err := p.store.CreateGmailMessageFromForm("me", "carol@example.com", "", "Wipe me", "bye", []string{"INBOX"})
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	if _, err := p.store.db.Exec(`INSERT INTO gmail_attachments (id, message_id, filename, mime_type, size, data) VALUES ('att1', ?, 'a.txt', 'text/plain', 3, 'YWJj')`, msg.ID); err != nil {
+		t.Fatalf("failed to create attachment: %v", err)
+	}
+	if _, err := p.store.CreateCalendarEvent(&CalendarEvent{CalendarID: "primary", Summary: "Keep me", Attendees: "[]"}, ""); err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	if _, err := p.store.CreateTask(&Task{ListID: "@default", Title: "Keep me", Status: "needsAction"}, ""); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+}
+
+func TestAdminBulkDeleteResourceType(t *testing.T) {
+	p, r := setupAdminRouter(t)
+	seedBulkDeleteData(t, p)
+
+	gmailTables := []string{"gmail_messages", "gmail_threads", "gmail_attachments"}
+	before := make(map[string]int)
+	for _, table := range gmailTables {
+		if before[table] = countRows(t, p, table); before[table] == 0 {
+			t.Fatalf("expected seeded rows in %s", table)
+		}
+	}
+
+	w := adminRequest(r, "DELETE", "/admin/gmail", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for bulk delete, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Undo string `json:"undo"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	for _, table := range gmailTables {
+		if n := countRows(t, p, table); n != 0 {
+			t.Errorf("expected %s to be empty, has %d rows", table, n)
+		}
+	}
+	if countRows(t, p, "calendar_events") != 1 || countRows(t, p, "tasks") != 1 {
+		t.Error("expected other Google resource types to be untouched")
+	}
+
+	w = adminRequest(r, "POST", resp.Undo, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for undo, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, table := range gmailTables {
+		if n := countRows(t, p, table); n != before[table] {
+			t.Errorf("expected %d rows restored to %s, has %d", before[table], table, n)
+		}
+	}
+
+	w = adminRequest(r, "POST", resp.Undo, nil)
+	if w.Code != http.StatusGone {
+		t.Errorf("expected 410 for a second undo, got %d", w.Code)
+	}
+}
+
+func TestAdminBulkDeleteCascadesAcrossPlugin(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	for _, path := range []string{"/admin/google", "/admin/all"} {
+		seedBulkDeleteData(t, p)
+
+		w := adminRequest(r, "DELETE", path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("DELETE %s: expected 200, got %d: %s", path, w.Code, w.Body.String())
+		}
+
+		for _, tables := range p.TruncateGroups() {
+			for _, table := range tables {
+				if n := countRows(t, p, table); n != 0 {
+					t.Errorf("DELETE %s: expected %s to be empty, has %d rows", path, table, n)
+				}
+			}
+		}
+	}
+
+	w := adminRequest(r, "DELETE", "/admin/nonexistent", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown target, got %d", w.Code)
+	}
+}
+
+func patchDocument(r chi.Router, path, patch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("PATCH", path, strings.NewReader(patch))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminJSONPatchPersonDisplayName(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	person, err := p.store.CreatePersonFromForm("me", "Ada Byron", "ada@example.com")
+	if err != nil {
+		t.Fatalf("failed to create person: %v", err)
+	}
+	path := "/admin/api/people/" + person.ID
+
+	w := patchDocument(r, path, `[{"op":"replace","path":"/names/0/displayName","value":"Ada Lovelace"}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	view, err := p.store.GetPersonView("me", person.ResourceName)
+	if err != nil {
+		t.Fatalf("failed to load person: %v", err)
+	}
+	if view.DisplayName != "Ada Lovelace" || view.Email != "ada@example.com" {
+		t.Errorf("expected only the display name to change, got %+v", view)
+	}
+
+	w = patchDocument(r, path, `[{"op":"replace","path":"/resourceName","value":"people/other"}]`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 when patching resourceName, got %d", w.Code)
+	}
+
+	w = patchDocument(r, path, `[{"op":"replace","path":"/names","value":"not a list"}]`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 when the result no longer parses as a person, got %d", w.Code)
+	}
+
+	w = patchDocument(r, path, `[{"op":"remove","path":"/nicknames"}]`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 when removing a missing member, got %d", w.Code)
+	}
+	view, _ = p.store.GetPersonView("me", person.ResourceName)
+	if view.DisplayName != "Ada Lovelace" {
+		t.Errorf("expected rejected patches to leave the person unchanged, got %+v", view)
+	}
+}
+
+func TestAdminJSONPatchGmailPayload(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	msg, err := p.store.CreateGmailMessageFromForm("me", "carol@example.com", "", "Patch me", "body", []string{"INBOX"})
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	path := "/admin/api/gmail/" + msg.ID
+
+	w := patchDocument(r, path, `[{"op":"add","path":"/labelIds/-","value":"STARRED"},{"op":"replace","path":"/snippet","value":"patched"}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	stored, _ := p.store.GetGmailMessage("me", msg.ID)
+	if stored.Snippet != "patched" || len(stored.LabelIDs) != 2 || stored.LabelIDs[1] != "STARRED" {
+		t.Errorf("unexpected stored message: %+v", stored)
+	}
+
+	w = patchDocument(r, path, `[{"op":"remove","path":"/id"}]`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 when removing id, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("PATCH", path, strings.NewReader(`[]`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 without the JSON Patch media type, got %d", w.Code)
+	}
+}
+
+func TestAdminPatchEventJSONFields(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	evt, err := p.store.CreateCalendarEventFromForm("Standup", "", "2025-03-01T09:00:00Z", "2025-03-01T09:15:00Z")
+	if err != nil {
+		t.Fatalf("failed to create event: %v", err)
+	}
+	path := "/admin/google/events/" + evt.ID
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch(`{"attendees": [{"email": "ada@example.com"}], "recurrence": ["RRULE:FREQ=DAILY"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	stored, _ := p.store.GetCalendarEventByID(evt.ID)
+	if stored.Attendees != `[{"email":"ada@example.com"}]` || stored.Recurrence != `["RRULE:FREQ=DAILY"]` {
+		t.Errorf("unexpected stored fields: attendees=%s recurrence=%s", stored.Attendees, stored.Recurrence)
+	}
+
+	if w := patch(`{"recurrence": "daily"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a recurrence that isn't a list, got %d", w.Code)
+	}
+	if w := patch(`{"attendees": [`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unparseable JSON, got %d", w.Code)
+	}
+
+	// The detail page renders the stored JSON in an editor
+	w = adminRequest(r, "GET", "/admin/plugins/google/events/"+evt.ID, nil)
+	if !strings.Contains(w.Body.String(), `data-json-field="recurrence"`) || !strings.Contains(w.Body.String(), "RRULE:FREQ=DAILY") {
+		t.Errorf("expected the detail page to show the recurrence editor, got %s", w.Body.String())
+	}
+}
+
+func TestAdminImportMbox(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	mbox := "From ada@example.com Sat Mar  1 09:00:00 2025\n" +
+		"From: ada@example.com\nSubject: Engines\nDate: Sat, 01 Mar 2025 09:00:00 +0000\n" +
+		"Content-Transfer-Encoding: base64\n\nQW5hbHl0aWNhbCBlbmdpbmUgbm90ZXM=\n\n" +
+		"From grace@example.com Sun Mar  2 10:00:00 2025\n" +
+		"From: grace@example.com\nSubject: Compilers\nContent-Transfer-Encoding: quoted-printable\n\n" +
+		"A=3DB\n"
+
+	var upload bytes.Buffer
+	form := multipart.NewWriter(&upload)
+	form.WriteField("user", "ada")
+	file, _ := form.CreateFormFile("mbox", "inbox.mbox")
+	file.Write([]byte(mbox))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/admin/gmail/import/mbox", &upload)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result struct {
+		Imported int    `json:"imported"`
+		User     string `json:"user"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Imported != 2 || result.User != "ada" {
+		t.Errorf("unexpected import result: %s", w.Body.String())
+	}
+
+	w = adminRequest(r, "GET", "/admin/plugins/google/messages.json", nil)
+	var list struct {
+		Data []map[string]any `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	subjects := map[string]bool{}
+	for _, msg := range list.Data {
+		subjects[fmt.Sprint(msg["subject"])] = true
+	}
+	if len(list.Data) != 2 || !subjects["Engines"] || !subjects["Compilers"] {
+		t.Fatalf("expected both imported messages in the list, got %v", list.Data)
+	}
+
+	messages, _, err := p.store.ListGmailMessages("ada", 10, "", "")
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("expected 2 messages in ada's mailbox, got %d (%v)", len(messages), err)
+	}
+	for _, msg := range messages {
+		if len(msg.LabelIDs) != 1 || msg.LabelIDs[0] != "INBOX" {
+			t.Errorf("expected imported messages in INBOX, got %v", msg.LabelIDs)
+		}
+		detail, _ := p.store.GetGmailMessageDetail("ada", msg.ID)
+		if detail.Subject == "Engines" && (detail.Body != "Analytical engine notes" || msg.InternalDate != time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC).UnixMilli()) {
+			t.Errorf("expected the decoded body and original date, got %q at %d", detail.Body, msg.InternalDate)
+		}
+		if detail.Subject == "Compilers" && detail.Body != "A=B" {
+			t.Errorf("expected the quoted-printable body decoded, got %q", detail.Body)
+		}
+	}
+}
+
+func TestAdminExportMessagesCSV(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	tricky := `Quarterly "numbers", part 2`
+	if _, err := p.store.CreateGmailMessageFromForm("me", "carol@example.com", "", tricky, "see attached", []string{"INBOX"}); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	if _, err := p.store.CreateGmailMessageFromForm("me", "dave@example.com", "", "Lunch\non Friday", "pizza?", []string{"INBOX"}); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	w := adminRequest(r, "GET", "/admin/google/messages.csv", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected text/csv, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="google-messages.csv"` {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+	if !strings.Contains(w.Body.String(), `"Quarterly ""numbers"", part 2"`) {
+		t.Errorf("expected quotes and commas to be escaped, got:\n%s", w.Body.String())
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d records", len(records))
+	}
+	if got := strings.Join(records[0], ","); got != "Subject,From,Date" {
+		t.Errorf("expected headers to match the list columns, got %q", got)
+	}
+
+	bySubject := map[string][]string{}
+	for _, record := range records[1:] {
+		bySubject[record[0]] = record
+	}
+	if row, ok := bySubject[tricky]; !ok || row[1] != "carol@example.com" {
+		t.Errorf("expected row for %q from carol, got %v", tricky, records)
+	}
+	if row, ok := bySubject["Lunch\non Friday"]; !ok || row[1] != "dave@example.com" {
+		t.Errorf("expected multi-line subject to round-trip, got %v", records)
+	}
+	for _, record := range records[1:] {
+		if _, err := time.Parse(time.RFC3339, record[2]); err != nil {
+			t.Errorf("expected RFC3339 date, got %q", record[2])
+		}
+	}
+
+	if w := adminRequest(r, "GET", "/admin/google/widgets.csv", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown resource, got %d", w.Code)
+	}
+}
+
+func TestAdminScenarioRoundTrip(t *testing.T) {
+	p, r := setupAdminRouter(t)
+
+	for i, subject := range []string{"Kickoff", `Budget "final", v2`, "Retro\nnotes"} {
+		if _, err := p.store.CreateGmailMessageFromForm("me", fmt.Sprintf("user%d@example.com", i), "me@example.com", subject, "body "+subject, []string{"INBOX"}); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+	want, err := p.store.ListAllGmailMessages()
+	if err != nil {
+		t.Fatalf("failed to list messages: %v", err)
+	}
+
+	w := adminRequest(r, "GET", "/admin/export/scenario", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	bundle := w.Body.String()
+
+	// A fresh instance starts empty and matches the original after import
+	p, fresh := setupAdminRouter(t)
+	if got, _ := p.store.ListAllGmailMessages(); len(got) != 0 {
+		t.Fatalf("expected fresh store to be empty, got %d messages", len(got))
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		req := httptest.NewRequest("POST", "/admin/import/scenario", strings.NewReader(bundle))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		fresh.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("import: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		// Importing twice gives the same result rather than duplicates
+		got, err := p.store.ListAllGmailMessages()
+		if err != nil {
+			t.Fatalf("failed to list messages: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("import %d: expected %d messages, got %d", attempt+1, len(want), len(got))
+		}
+		for i := range want {
+			if got[i].ID != want[i].ID || got[i].Subject != want[i].Subject || got[i].From != want[i].From || got[i].Date != want[i].Date {
+				t.Errorf("import %d: message %d = %+v, want %+v", attempt+1, i, got[i], want[i])
+			}
+		}
+	}
+
+	detail, err := p.store.GetGmailMessage("me", want[0].ID)
+	if err != nil {
+		t.Fatalf("imported message not readable through the API store: %v", err)
+	}
+	if detail.ThreadID == "" {
+		t.Error("expected imported message to keep its thread")
+	}
+}
@@ -0,0 +1,117 @@
+// ABOUTME: Tests for the Google plugin's audit logging.
+// ABOUTME: Drives each mutating route as user:alice and checks it leaves an audit row naming alice.
+
+package google
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func TestMutatingRoutesAreAudited(t *testing.T) {
+	p, r := setupGmailRouter(t)
+	srv, _ := channelReceiver(t)
+
+	// mutate sends a request as alice and checks the newest entry for
+	// resourceType records action by alice, returning the decoded response
+	mutate := func(method, path, body, resourceType, action string) map[string]any {
+		t.Helper()
+		w := sendGmailJSON(r, method, path, body)
+		if w.Code >= 300 {
+			t.Fatalf("%s %s: got %d: %s", method, path, w.Code, w.Body.String())
+		}
+		entries, err := core.ListAudit(p.store.db, core.AuditQuery{PluginName: "google", ResourceType: resourceType, Limit: 1})
+		if err != nil {
+			t.Fatalf("ListAudit failed: %v", err)
+		}
+		if len(entries) == 0 || entries[0].Action != action || entries[0].Actor != "alice" {
+			t.Errorf("%s %s: expected a %s %s entry by alice, got %+v", method, path, resourceType, action, entries)
+		}
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	// Gmail
+	msg := mutate("POST", "/gmail/v1/users/me/messages", `{"raw":"`+rawMessage("Subject: Hi", "Hello")+`"}`, "gmail_message", core.AuditCreate)
+	msgPath := "/gmail/v1/users/me/messages/" + msg["id"].(string)
+	mutate("POST", "/gmail/v1/users/me/messages/send", `{"raw":"`+rawMessage("To: bob@example.com\r\nSubject: Yo", "Hey")+`"}`, "gmail_message", core.AuditCreate)
+	mutate("POST", msgPath+"/modify", `{"addLabelIds":["STARRED"]}`, "gmail_message", core.AuditUpdate)
+	mutate("POST", "/gmail/v1/users/me/messages/batchModify", `{"ids":["`+msg["id"].(string)+`"],"removeLabelIds":["STARRED"]}`, "gmail_message", core.AuditUpdate)
+	mutate("POST", msgPath+"/trash", "", "gmail_message", core.AuditUpdate)
+	mutate("DELETE", msgPath, "", "gmail_message", core.AuditDelete)
+
+	label := mutate("POST", "/gmail/v1/users/me/labels", `{"name":"Receipts"}`, "gmail_label", core.AuditCreate)
+	labelPath := "/gmail/v1/users/me/labels/" + label["id"].(string)
+	mutate("PUT", labelPath, `{"name":"Invoices"}`, "gmail_label", core.AuditUpdate)
+	mutate("PATCH", labelPath, `{"name":"Bills"}`, "gmail_label", core.AuditUpdate)
+	mutate("DELETE", labelPath, "", "gmail_label", core.AuditDelete)
+	mutate("PUT", "/gmail/v1/users/me/settings/vacation", `{"enableAutoReply":true,"responseSubject":"Away"}`, "gmail_vacation", core.AuditUpdate)
+
+	// Calendar
+	event := mutate("POST", "/calendar/v3/calendars/primary/events",
+		`{"summary":"Standup","start":{"dateTime":"2024-01-01T09:00:00Z"},"end":{"dateTime":"2024-01-01T09:15:00Z"}}`,
+		"calendar_event", core.AuditCreate)
+	eventPath := "/calendar/v3/calendars/primary/events/" + event["id"].(string)
+	mutate("PUT", eventPath, `{"summary":"Daily standup"}`, "calendar_event", core.AuditUpdate)
+	mutate("PATCH", eventPath, `{"location":"Room 1"}`, "calendar_event", core.AuditUpdate)
+	mutate("DELETE", eventPath, "", "calendar_event", core.AuditDelete)
+	channel := mutate("POST", "/calendar/v3/calendars/primary/events/watch",
+		`{"id":"chan-audit","type":"web_hook","address":"`+srv.URL+`"}`, "calendar_channel", core.AuditCreate)
+	mutate("POST", "/calendar/v3/channels/stop", `{"id":"chan-audit","resourceId":"`+channel["resourceId"].(string)+`"}`,
+		"calendar_channel", core.AuditDelete)
+
+	// People and contact groups
+	person := mutate("POST", "/people/v1/people:createContact", `{"names":[{"displayName":"Pat"}]}`, "person", core.AuditCreate)
+	personPath := "/people/v1/" + person["resourceName"].(string)
+	mutate("PATCH", personPath+":updateContact", `{"names":[{"displayName":"Pat Doe"}]}`, "person", core.AuditUpdate)
+
+	var img bytes.Buffer
+	png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	mutate("PATCH", personPath+":updateContactPhoto", `{"photoBytes":"`+base64.StdEncoding.EncodeToString(img.Bytes())+`"}`,
+		"person_photo", core.AuditCreate)
+	mutate("DELETE", personPath+":deleteContactPhoto", "", "person_photo", core.AuditDelete)
+
+	group := mutate("POST", "/people/v1/contactGroups", `{"contactGroup":{"name":"Team"}}`, "contact_group", core.AuditCreate)
+	groupPath := "/people/v1/" + group["resourceName"].(string)
+	mutate("POST", groupPath+"/members:modify", `{"resourceNamesToAdd":["`+person["resourceName"].(string)+`"]}`,
+		"contact_group", core.AuditUpdate)
+	mutate("DELETE", groupPath, "", "contact_group", core.AuditDelete)
+	mutate("DELETE", personPath+":deleteContact", "", "person", core.AuditDelete)
+
+	// Tasks
+	task := mutate("POST", "/tasks/v1/lists/@default/tasks", `{"title":"Write tests"}`, "task", core.AuditCreate)
+	taskPath := "/tasks/v1/lists/@default/tasks/" + task["id"].(string)
+	mutate("PUT", taskPath, `{"title":"Write more tests"}`, "task", core.AuditUpdate)
+	mutate("PATCH", taskPath, `{"status":"completed"}`, "task", core.AuditUpdate)
+	mutate("DELETE", taskPath, "", "task", core.AuditDelete)
+
+	// Seeding goes straight to the store, so check the writes only it makes
+	for _, resourceType := range []string{"calendar", "task_list", "gmail_thread"} {
+		if err := seedAuditedResource(p.store, resourceType); err != nil {
+			t.Fatalf("failed to create %s: %v", resourceType, err)
+		}
+		entries, _ := core.ListAudit(p.store.db, core.AuditQuery{PluginName: "google", ResourceType: resourceType})
+		if len(entries) != 1 || entries[0].Action != core.AuditCreate || entries[0].Actor != "alice" {
+			t.Errorf("expected a %s create entry by alice, got %+v", resourceType, entries)
+		}
+	}
+}
+
+// seedAuditedResource creates one of alice's store-only resources
+func seedAuditedResource(s *GoogleStore, resourceType string) error {
+	switch resourceType {
+	case "calendar":
+		return s.CreateCalendar(&Calendar{ID: "work", UserID: "alice", Summary: "Work"})
+	case "task_list":
+		return s.CreateTaskList(&TaskList{UserID: "alice", Title: "Errands"})
+	default:
+		return s.CreateGmailThread(&GmailThread{ID: "thread-audit", UserID: "alice", Snippet: "Hi"})
+	}
+}
@@ -7,30 +7,37 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"strconv"
 
+	"github.com/2389/ish/internal/auth"
 	"github.com/go-chi/chi/v5"
 )
 
 func (p *GooglePlugin) registerCalendarRoutes(r chi.Router) {
+	r.Get("/calendar/v3/colors", p.getColors)
+	r.Post("/calendar/v3/channels/stop", p.stopChannel)
+
 	// Standard Google Calendar API v3 routes
 	r.Route("/calendar/v3/calendars/{calendarId}", func(r chi.Router) {
 		r.Get("/events", p.listEvents)
 		r.Post("/events", p.createEvent)
+		r.Post("/events/watch", p.watchEvents)
 		r.Get("/events/{eventId}", p.getEvent)
 		r.Put("/events/{eventId}", p.updateEvent)
 		r.Patch("/events/{eventId}", p.updateEvent)
 		r.Delete("/events/{eventId}", p.deleteEvent)
+		r.Get("/events/{eventId}/attendees", p.listEventAttendees)
 	})
 
 	// Alias routes without /calendar/v3/ prefix (some clients strip this)
 	r.Route("/calendars/{calendarId}", func(r chi.Router) {
 		r.Get("/events", p.listEvents)
 		r.Post("/events", p.createEvent)
+		r.Post("/events/watch", p.watchEvents)
 		r.Get("/events/{eventId}", p.getEvent)
 		r.Put("/events/{eventId}", p.updateEvent)
 		r.Patch("/events/{eventId}", p.updateEvent)
 		r.Delete("/events/{eventId}", p.deleteEvent)
+		r.Get("/events/{eventId}/attendees", p.listEventAttendees)
 	})
 }
 
@@ -42,11 +49,10 @@ func (p *GooglePlugin) listEvents(w http.ResponseWriter, r *http.Request) {
 
 	calendarID := urlParam(r, "calendarId")
 
-	maxResults := 250
-	if mr := r.URL.Query().Get("maxResults"); mr != "" {
-		if v, err := strconv.Atoi(mr); err == nil && v > 0 {
-			maxResults = v
-		}
+	maxResults, err := pageSizeParam(r, "maxResults", 250)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
 	}
 	pageToken := r.URL.Query().Get("pageToken")
 	timeMin := r.URL.Query().Get("timeMin")
@@ -56,7 +62,6 @@ func (p *GooglePlugin) listEvents(w http.ResponseWriter, r *http.Request) {
 	var events []CalendarEvent
 	var nextToken string
 	var nextSyncToken string
-	var err error
 
 	if syncToken != "" {
 		// Incremental sync - get events since last sync
@@ -71,7 +76,7 @@ func (p *GooglePlugin) listEvents(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		writeError(w, 500, "Internal error", "INTERNAL")
+		writeListError(w, err)
 		return
 	}
 
@@ -111,6 +116,9 @@ func (p *GooglePlugin) listEvents(w http.ResponseWriter, r *http.Request) {
 				item["recurrence"] = recurrence
 			}
 		}
+		if e.ColorID != "" {
+			item["colorId"] = e.ColorID
+		}
 		if e.UpdatedAt != "" {
 			item["updated"] = e.UpdatedAt
 		}
@@ -147,6 +155,11 @@ func (p *GooglePlugin) getEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	writeJSON(w, eventToResponse(evt))
+}
+
+// eventToResponse converts a CalendarEvent to Calendar API response format
+func eventToResponse(evt *CalendarEvent) map[string]any {
 	var attendees []any
 	if err := json.Unmarshal([]byte(evt.Attendees), &attendees); err != nil {
 		log.Printf("Failed to unmarshal attendees: %v", err)
@@ -181,11 +194,14 @@ func (p *GooglePlugin) getEvent(w http.ResponseWriter, r *http.Request) {
 			resp["recurrence"] = recurrence
 		}
 	}
+	if evt.ColorID != "" {
+		resp["colorId"] = evt.ColorID
+	}
 	if evt.UpdatedAt != "" {
 		resp["updated"] = evt.UpdatedAt
 	}
 
-	writeJSON(w, resp)
+	return resp
 }
 
 func (p *GooglePlugin) createEvent(w http.ResponseWriter, r *http.Request) {
@@ -207,6 +223,7 @@ func (p *GooglePlugin) createEvent(w http.ResponseWriter, r *http.Request) {
 			Email string `json:"email"`
 		} `json:"attendees"`
 		Recurrence []string `json:"recurrence"`
+		ColorID    string   `json:"colorId"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -228,6 +245,10 @@ func (p *GooglePlugin) createEvent(w http.ResponseWriter, r *http.Request) {
 		writeError(w, 400, "Missing required fields: summary, start, end", "INVALID_REQUEST")
 		return
 	}
+	if !validEventColorID(req.ColorID) {
+		writeError(w, 400, "Invalid colorId: "+req.ColorID, "INVALID_ARGUMENT")
+		return
+	}
 
 	// Convert attendees to JSON
 	attendeesJSON, _ := json.Marshal(req.Attendees)
@@ -248,11 +269,13 @@ func (p *GooglePlugin) createEvent(w http.ResponseWriter, r *http.Request) {
 		EndTime:     endTime,
 		Attendees:   string(attendeesJSON),
 		Recurrence:  recurrenceJSON,
-	})
+		ColorID:     req.ColorID,
+	}, auth.UserFromContext(r.Context()))
 	if err != nil {
 		writeError(w, 500, "Failed to create event", "INTERNAL")
 		return
 	}
+	p.notifyCalendarChannels(calendarID)
 
 	// Parse attendees back to array
 	var attendees []any
@@ -276,6 +299,9 @@ func (p *GooglePlugin) createEvent(w http.ResponseWriter, r *http.Request) {
 		"updated":     event.UpdatedAt,
 	}
 
+	if event.ColorID != "" {
+		resp["colorId"] = event.ColorID
+	}
 	if event.Recurrence != "" {
 		var recurrence []string
 		if err := json.Unmarshal([]byte(event.Recurrence), &recurrence); err != nil {
@@ -317,16 +343,21 @@ func (p *GooglePlugin) updateEvent(w http.ResponseWriter, r *http.Request) {
 			DateTime string `json:"dateTime"`
 			Date     string `json:"date"`
 		} `json:"end"`
-		Attendees *[]struct {
-			Email string `json:"email"`
-		} `json:"attendees"`
-		Recurrence *[]string `json:"recurrence"`
+		Attendees         *[]map[string]any `json:"attendees"`
+		AttendeesStrategy string            `json:"attendees_strategy"`
+		Recurrence        *[]string         `json:"recurrence"`
+		ColorID           *string           `json:"colorId"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, 400, "Invalid request body", "INVALID_REQUEST")
 		return
 	}
+	strategy, err := attendeesStrategy(r, req.AttendeesStrategy)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
 
 	// Update fields if provided
 	if req.Summary != nil {
@@ -353,19 +384,34 @@ func (p *GooglePlugin) updateEvent(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if req.Attendees != nil {
-		bytes, _ := json.Marshal(*req.Attendees)
+		attendees := *req.Attendees
+		if strategy == attendeesMerge {
+			if attendees, err = mergeAttendees(eventAttendees(existing), attendees); err != nil {
+				writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+				return
+			}
+		}
+		bytes, _ := json.Marshal(attendees)
 		existing.Attendees = string(bytes)
 	}
 	if req.Recurrence != nil {
 		bytes, _ := json.Marshal(*req.Recurrence)
 		existing.Recurrence = string(bytes)
 	}
+	if req.ColorID != nil {
+		if !validEventColorID(*req.ColorID) {
+			writeError(w, 400, "Invalid colorId: "+*req.ColorID, "INVALID_ARGUMENT")
+			return
+		}
+		existing.ColorID = *req.ColorID
+	}
 
-	updated, err := p.store.UpdateCalendarEvent(existing)
+	updated, err := p.store.UpdateCalendarEvent(existing, auth.UserFromContext(r.Context()))
 	if err != nil {
 		writeError(w, 500, "Failed to update event", "INTERNAL")
 		return
 	}
+	p.notifyCalendarChannels(calendarID)
 
 	// Parse attendees back to array
 	var attendees []any
@@ -388,6 +434,9 @@ func (p *GooglePlugin) updateEvent(w http.ResponseWriter, r *http.Request) {
 		"updated":     updated.UpdatedAt,
 	}
 
+	if updated.ColorID != "" {
+		resp["colorId"] = updated.ColorID
+	}
 	if updated.Recurrence != "" {
 		var recurrence []string
 		if err := json.Unmarshal([]byte(updated.Recurrence), &recurrence); err != nil {
@@ -409,11 +458,12 @@ func (p *GooglePlugin) deleteEvent(w http.ResponseWriter, r *http.Request) {
 	calendarID := urlParam(r, "calendarId")
 	eventID := urlParam(r, "eventId")
 
-	err := p.store.DeleteCalendarEvent(calendarID, eventID)
+	err := p.store.DeleteCalendarEvent(calendarID, eventID, auth.UserFromContext(r.Context()))
 	if err != nil {
 		writeError(w, 404, "Event not found", "NOT_FOUND")
 		return
 	}
+	p.notifyCalendarChannels(calendarID)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,96 @@
+// ABOUTME: Calendar event attendee handling for Google plugin.
+// ABOUTME: Lists an event's attendees and merges patched attendees by email instead of replacing them.
+
+package google
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// How an update's attendees array combines with the event's attendees
+const (
+	attendeesReplace = "replace"
+	attendeesMerge   = "merge"
+)
+
+// attendeesStrategy reads the attendees_strategy query parameter, or the
+// body field when the query doesn't set it; replace is the default
+func attendeesStrategy(r *http.Request, bodyValue string) (string, error) {
+	strategy := r.URL.Query().Get("attendees_strategy")
+	if strategy == "" {
+		strategy = bodyValue
+	}
+	switch strategy {
+	case "", attendeesReplace:
+		return attendeesReplace, nil
+	case attendeesMerge:
+		return attendeesMerge, nil
+	}
+	return "", errors.New("Invalid attendees_strategy: " + strategy)
+}
+
+// mergeAttendees combines update into existing keyed by email, compared
+// case-insensitively. Fields of an attendee already on the event are
+// overwritten by the update's; new attendees are appended in order.
+func mergeAttendees(existing, update []map[string]any) ([]map[string]any, error) {
+	merged := make([]map[string]any, 0, len(existing)+len(update))
+	index := make(map[string]int, len(existing))
+	for _, attendee := range existing {
+		if email, _ := attendee["email"].(string); email != "" {
+			index[strings.ToLower(email)] = len(merged)
+		}
+		merged = append(merged, attendee)
+	}
+
+	for _, attendee := range update {
+		email, _ := attendee["email"].(string)
+		if email == "" {
+			return nil, errors.New("Missing attendee email")
+		}
+		key := strings.ToLower(email)
+		if i, ok := index[key]; ok {
+			combined := make(map[string]any, len(merged[i])+len(attendee))
+			for field, value := range merged[i] {
+				combined[field] = value
+			}
+			for field, value := range attendee {
+				combined[field] = value
+			}
+			merged[i] = combined
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, attendee)
+	}
+	return merged, nil
+}
+
+// eventAttendees parses an event's stored attendees
+func eventAttendees(evt *CalendarEvent) []map[string]any {
+	var attendees []map[string]any
+	json.Unmarshal([]byte(evt.Attendees), &attendees)
+	if attendees == nil {
+		attendees = []map[string]any{}
+	}
+	return attendees
+}
+
+// listEventAttendees handles GET /calendar/v3/calendars/{calendarId}/events/{eventId}/attendees,
+// a convenience for reading just an event's attendees
+func (p *GooglePlugin) listEventAttendees(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	evt, err := p.store.GetCalendarEvent(urlParam(r, "calendarId"), urlParam(r, "eventId"))
+	if err != nil {
+		writeError(w, 404, "Event not found", "NOT_FOUND")
+		return
+	}
+
+	writeJSON(w, map[string]any{"attendees": eventAttendees(evt)})
+}
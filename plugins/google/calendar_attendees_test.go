@@ -0,0 +1,102 @@
+// ABOUTME: Tests for Calendar event attendee updates and the attendees list.
+// ABOUTME: Covers the replace and merge strategies for patched attendees.
+
+package google
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// createEventWithAttendees creates an event with two attendees and returns its path
+func createEventWithAttendees(t *testing.T, r chi.Router) string {
+	t.Helper()
+	w := calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events",
+		`{"summary":"Planning","start":{"dateTime":"2024-01-01T09:00:00Z"},"end":{"dateTime":"2024-01-01T10:00:00Z"},
+		"attendees":[{"email":"bob@example.com"},{"email":"carol@example.com"}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]any
+	json.NewDecoder(w.Body).Decode(&created)
+	return "/calendar/v3/calendars/primary/events/" + created["id"].(string)
+}
+
+// listAttendees fetches an event's attendees from the convenience endpoint
+func listAttendees(t *testing.T, r chi.Router, path string) []map[string]any {
+	t.Helper()
+	w := calendarRequest(r, "GET", path+"/attendees", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 listing attendees, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Attendees []map[string]any `json:"attendees"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp.Attendees
+}
+
+func TestCalendarPatchAttendeesReplace(t *testing.T) {
+	_, r := setupGmailRouter(t)
+	path := createEventWithAttendees(t, r)
+
+	w := calendarRequest(r, "PATCH", path, `{"attendees":[{"email":"dave@example.com"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	attendees := listAttendees(t, r, path)
+	if len(attendees) != 1 || attendees[0]["email"] != "dave@example.com" {
+		t.Errorf("expected attendees replaced by dave, got %v", attendees)
+	}
+
+	w = calendarRequest(r, "PATCH", path+"?attendees_strategy=append", `{"attendees":[]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown strategy, got %d", w.Code)
+	}
+}
+
+func TestCalendarPatchAttendeesMerge(t *testing.T) {
+	_, r := setupGmailRouter(t)
+	path := createEventWithAttendees(t, r)
+
+	// Emails match case-insensitively; new attendees are appended
+	w := calendarRequest(r, "PATCH", path+"?attendees_strategy=merge",
+		`{"attendees":[{"email":"Carol@example.com","responseStatus":"accepted"},{"email":"dave@example.com","optional":true}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	attendees := listAttendees(t, r, path)
+	if len(attendees) != 3 {
+		t.Fatalf("expected 3 attendees after merging, got %v", attendees)
+	}
+	if attendees[0]["email"] != "bob@example.com" {
+		t.Errorf("expected bob to be kept first, got %v", attendees[0])
+	}
+	if attendees[1]["responseStatus"] != "accepted" {
+		t.Errorf("expected carol's response to be merged in, got %v", attendees[1])
+	}
+	if attendees[2]["email"] != "dave@example.com" || attendees[2]["optional"] != true {
+		t.Errorf("expected dave appended as optional, got %v", attendees[2])
+	}
+
+	// The strategy can also be given in the body
+	w = calendarRequest(r, "PATCH", path, `{"attendees_strategy":"merge","attendees":[{"email":"erin@example.com"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if attendees := listAttendees(t, r, path); len(attendees) != 4 {
+		t.Errorf("expected 4 attendees, got %v", attendees)
+	}
+
+	w = calendarRequest(r, "PATCH", path+"?attendees_strategy=merge", `{"attendees":[{"displayName":"No email"}]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 merging an attendee without an email, got %d", w.Code)
+	}
+
+	if w := calendarRequest(r, "GET", "/calendar/v3/calendars/primary/events/missing/attendees", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing event, got %d", w.Code)
+	}
+}
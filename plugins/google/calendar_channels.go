@@ -0,0 +1,201 @@
+// ABOUTME: Calendar push notification channels for the Google plugin.
+// ABOUTME: Implements events.watch and channels.stop, and notifies channel addresses when events change.
+
+package google
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/internal/auth"
+	"github.com/2389/ish/plugins/core"
+)
+
+// defaultChannelTTL is how long a channel lives when the request sets no expiration
+const defaultChannelTTL = 7 * 24 * time.Hour
+
+// watchEvents handles POST /calendar/v3/calendars/{calendarId}/events/watch,
+// opening a channel that is notified whenever the calendar's events change
+func (p *GooglePlugin) watchEvents(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	var req struct {
+		ID         string          `json:"id"`
+		Type       string          `json:"type"`
+		Address    string          `json:"address"`
+		Token      string          `json:"token"`
+		Expiration json.RawMessage `json:"expiration"`
+		Params     struct {
+			TTL string `json:"ttl"`
+		} `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_REQUEST")
+		return
+	}
+	if req.ID == "" {
+		writeError(w, 400, "Missing required field: id", "INVALID_ARGUMENT")
+		return
+	}
+	if req.Type != "web_hook" && req.Type != "webhook" {
+		writeError(w, 400, "Unsupported channel type: "+req.Type, "INVALID_ARGUMENT")
+		return
+	}
+	if address, err := url.Parse(req.Address); err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+		writeError(w, 400, "Invalid channel address: "+req.Address, "INVALID_ARGUMENT")
+		return
+	}
+
+	now := p.store.now()
+	expiration := now.Add(defaultChannelTTL).UnixMilli()
+	if raw := strings.Trim(string(req.Expiration), `"`); raw != "" && raw != "null" {
+		ms, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || ms <= now.UnixMilli() {
+			writeError(w, 400, "Invalid expiration: "+raw, "INVALID_ARGUMENT")
+			return
+		}
+		expiration = ms
+	} else if req.Params.TTL != "" {
+		ttl, err := strconv.ParseInt(req.Params.TTL, 10, 64)
+		if err != nil || ttl <= 0 {
+			writeError(w, 400, "Invalid ttl: "+req.Params.TTL, "INVALID_ARGUMENT")
+			return
+		}
+		expiration = now.Add(time.Duration(ttl) * time.Second).UnixMilli()
+	}
+
+	channel := &CalendarChannel{
+		ID:         req.ID,
+		ResourceID: newChannelResourceID(),
+		CalendarID: urlParam(r, "calendarId"),
+		Address:    req.Address,
+		Token:      req.Token,
+		Expiration: expiration,
+	}
+	if err := p.store.CreateCalendarChannel(channel, auth.UserFromContext(r.Context())); err != nil {
+		writeError(w, 400, "Channel id not unique: "+req.ID, "ALREADY_EXISTS")
+		return
+	}
+
+	// Like Google, confirm the channel with a sync message before any changes
+	p.notifyCalendarChannel(*channel, "sync")
+
+	resp := map[string]any{
+		"kind":        "api#channel",
+		"id":          channel.ID,
+		"resourceId":  channel.ResourceID,
+		"resourceUri": channelResourceURI(channel.CalendarID),
+		"expiration":  strconv.FormatInt(channel.Expiration, 10),
+	}
+	if channel.Token != "" {
+		resp["token"] = channel.Token
+	}
+	writeJSON(w, resp)
+}
+
+// stopChannel handles POST /calendar/v3/channels/stop
+func (p *GooglePlugin) stopChannel(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	var req struct {
+		ID         string `json:"id"`
+		ResourceID string `json:"resourceId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_REQUEST")
+		return
+	}
+	if req.ID == "" || req.ResourceID == "" {
+		writeError(w, 400, "Missing required fields: id, resourceId", "INVALID_ARGUMENT")
+		return
+	}
+
+	stopped, err := p.store.StopCalendarChannel(req.ID, req.ResourceID, auth.UserFromContext(r.Context()))
+	if err != nil {
+		writeError(w, 500, "Failed to stop channel", "INTERNAL")
+		return
+	}
+	if !stopped {
+		writeError(w, 404, "Channel not found", "NOT_FOUND")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notifyCalendarChannels tells every channel watching calendarID that its
+// events changed
+func (p *GooglePlugin) notifyCalendarChannels(calendarID string) {
+	channels, err := p.store.ListCalendarChannels(calendarID, p.store.now())
+	if err != nil {
+		log.Printf("Calendar: Failed to list channels for %s: %v", calendarID, err)
+		return
+	}
+	for _, channel := range channels {
+		p.notifyCalendarChannel(channel, "exists")
+	}
+}
+
+// notifyCalendarChannel numbers a notification and delivers it in the background
+func (p *GooglePlugin) notifyCalendarChannel(channel CalendarChannel, state string) {
+	number, err := p.store.NextCalendarChannelMessage(channel.ID)
+	if err != nil {
+		log.Printf("Calendar: Failed to number notification for channel %s: %v", channel.ID, err)
+		return
+	}
+	go deliverChannelNotification(channel, state, number)
+}
+
+// deliverChannelNotification POSTs an empty-bodied notification to the
+// channel address, describing it with X-Goog-* headers as Google does
+func deliverChannelNotification(channel CalendarChannel, state string, number int64) {
+	req, err := http.NewRequest(http.MethodPost, channel.Address, nil)
+	if err != nil {
+		log.Printf("Calendar: Invalid channel address %s: %v", channel.Address, err)
+		return
+	}
+	req.Header.Set("X-Goog-Channel-ID", channel.ID)
+	if channel.Token != "" {
+		req.Header.Set("X-Goog-Channel-Token", channel.Token)
+	}
+	req.Header.Set("X-Goog-Channel-Expiration", time.UnixMilli(channel.Expiration).UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Goog-Resource-ID", channel.ResourceID)
+	req.Header.Set("X-Goog-Resource-URI", channelResourceURI(channel.CalendarID))
+	req.Header.Set("X-Goog-Resource-State", state)
+	req.Header.Set("X-Goog-Message-Number", strconv.FormatInt(number, 10))
+
+	resp, err := core.WebhookClient().Do(req)
+	if err != nil {
+		log.Printf("Calendar: Error notifying channel %s at %s: %v", channel.ID, channel.Address, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("Calendar: Notification to %s returned status %d", channel.Address, resp.StatusCode)
+	}
+}
+
+// channelResourceURI is the watched resource, as reported in notifications
+func channelResourceURI(calendarID string) string {
+	return "https://www.googleapis.com/calendar/v3/calendars/" + url.PathEscape(calendarID) + "/events?alt=json"
+}
+
+// newChannelResourceID returns an opaque ID for a watched resource
+func newChannelResourceID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,114 @@
+// ABOUTME: Tests for Calendar watch channels.
+// ABOUTME: Verifies a receiver is notified when a watched calendar changes, and that stopped channels go quiet.
+
+package google
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// channelReceiver captures the X-Goog-* headers of each notification it receives
+func channelReceiver(t *testing.T) (*httptest.Server, <-chan http.Header) {
+	t.Helper()
+	received := make(chan http.Header, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, received
+}
+
+func nextNotification(t *testing.T, received <-chan http.Header) http.Header {
+	t.Helper()
+	select {
+	case h := <-received:
+		return h
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a channel notification")
+		return nil
+	}
+}
+
+func TestCalendarWatchNotifiesOnEventCreate(t *testing.T) {
+	_, r := setupGmailRouter(t)
+	srv, received := channelReceiver(t)
+
+	w := calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events/watch",
+		`{"id":"chan-1","type":"web_hook","address":"`+srv.URL+`","token":"secret"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var channel map[string]any
+	json.NewDecoder(w.Body).Decode(&channel)
+	if channel["kind"] != "api#channel" || channel["id"] != "chan-1" || channel["resourceId"] == "" {
+		t.Fatalf("unexpected channel: %v", channel)
+	}
+
+	sync := nextNotification(t, received)
+	if sync.Get("X-Goog-Resource-State") != "sync" || sync.Get("X-Goog-Message-Number") != "1" {
+		t.Errorf("expected sync message 1 first, got %v", sync)
+	}
+
+	w = calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events",
+		`{"summary":"Standup","start":{"dateTime":"2024-01-01T09:00:00Z"},"end":{"dateTime":"2024-01-01T09:15:00Z"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	h := nextNotification(t, received)
+	if h.Get("X-Goog-Resource-State") != "exists" {
+		t.Errorf("expected resource state exists, got %q", h.Get("X-Goog-Resource-State"))
+	}
+	if h.Get("X-Goog-Channel-ID") != "chan-1" || h.Get("X-Goog-Channel-Token") != "secret" {
+		t.Errorf("unexpected channel headers: %v", h)
+	}
+	if h.Get("X-Goog-Resource-ID") != channel["resourceId"] || h.Get("X-Goog-Message-Number") != "2" {
+		t.Errorf("unexpected resource headers: %v", h)
+	}
+}
+
+func TestCalendarStopChannel(t *testing.T) {
+	p, r := setupGmailRouter(t)
+	srv, received := channelReceiver(t)
+
+	w := calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events/watch",
+		`{"id":"chan-2","type":"web_hook","address":"`+srv.URL+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var channel map[string]any
+	json.NewDecoder(w.Body).Decode(&channel)
+	nextNotification(t, received)
+
+	w = calendarRequest(r, "POST", "/calendar/v3/channels/stop", `{"id":"chan-2","resourceId":"wrong"}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a mismatched resourceId, got %d", w.Code)
+	}
+	w = calendarRequest(r, "POST", "/calendar/v3/channels/stop",
+		`{"id":"chan-2","resourceId":"`+channel["resourceId"].(string)+`"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	channels, err := p.store.ListCalendarChannels("primary", time.Now())
+	if err != nil || len(channels) != 0 {
+		t.Errorf("expected no channels after stop, got %v (err %v)", channels, err)
+	}
+}
+
+func TestCalendarWatchRejectsDuplicateID(t *testing.T) {
+	_, r := setupGmailRouter(t)
+	srv, _ := channelReceiver(t)
+
+	body := `{"id":"chan-3","type":"web_hook","address":"` + srv.URL + `"}`
+	if w := calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events/watch", body); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events/watch", body); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a duplicate channel id, got %d", w.Code)
+	}
+}
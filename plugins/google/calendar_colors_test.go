@@ -0,0 +1,102 @@
+// ABOUTME: Tests for the Calendar colors palette and event colorId handling.
+// ABOUTME: Verifies valid colorIds round-trip and unknown ones are rejected.
+
+package google
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// calendarRequest sends a JSON request to a Calendar endpoint as user:alice
+func calendarRequest(r chi.Router, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer user:alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCalendarColorsPalette(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	w := calendarRequest(r, "GET", "/calendar/v3/colors", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Kind     string                   `json:"kind"`
+		Calendar map[string]calendarColor `json:"calendar"`
+		Event    map[string]calendarColor `json:"event"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Kind != "calendar#colors" || len(resp.Calendar) != 24 || len(resp.Event) != 11 {
+		t.Errorf("unexpected palette: kind=%q calendar=%d event=%d", resp.Kind, len(resp.Calendar), len(resp.Event))
+	}
+	if resp.Event["11"].Background != "#dc2127" {
+		t.Errorf("expected event color 11 to be #dc2127, got %q", resp.Event["11"].Background)
+	}
+}
+
+func TestCalendarEventColorIDRoundTrip(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	w := calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events",
+		`{"summary":"Standup","colorId":"5","start":{"dateTime":"2024-01-01T09:00:00Z"},"end":{"dateTime":"2024-01-01T09:15:00Z"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]any
+	json.NewDecoder(w.Body).Decode(&created)
+	if created["colorId"] != "5" {
+		t.Errorf("expected colorId 5 in create response, got %v", created["colorId"])
+	}
+	path := "/calendar/v3/calendars/primary/events/" + created["id"].(string)
+
+	w = calendarRequest(r, "PATCH", path, `{"colorId":"9"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for patch, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = calendarRequest(r, "GET", path, "")
+	var fetched map[string]any
+	json.NewDecoder(w.Body).Decode(&fetched)
+	if fetched["colorId"] != "9" {
+		t.Errorf("expected colorId 9 after patch, got %v", fetched["colorId"])
+	}
+
+	w = calendarRequest(r, "GET", "/calendar/v3/calendars/primary/events", "")
+	var list struct {
+		Items []map[string]any `json:"items"`
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Items) != 1 || list.Items[0]["colorId"] != "9" {
+		t.Errorf("expected colorId 9 in list, got %v", list.Items)
+	}
+}
+
+func TestCalendarEventInvalidColorIDRejected(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	w := calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events",
+		`{"summary":"Standup","colorId":"12","start":{"dateTime":"2024-01-01T09:00:00Z"},"end":{"dateTime":"2024-01-01T09:15:00Z"}}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown colorId on create, got %d", w.Code)
+	}
+
+	w = calendarRequest(r, "POST", "/calendar/v3/calendars/primary/events",
+		`{"summary":"Standup","start":{"dateTime":"2024-01-01T09:00:00Z"},"end":{"dateTime":"2024-01-01T09:15:00Z"}}`)
+	var created map[string]any
+	json.NewDecoder(w.Body).Decode(&created)
+
+	w = calendarRequest(r, "PATCH", "/calendar/v3/calendars/primary/events/"+created["id"].(string), `{"colorId":"purple"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown colorId on patch, got %d", w.Code)
+	}
+}
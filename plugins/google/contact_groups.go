@@ -0,0 +1,328 @@
+// ABOUTME: People API contact group handlers for Google plugin.
+// ABOUTME: Lists, creates, deletes and batch-gets contact groups and modifies their members.
+
+package google
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/2389/ish/internal/auth"
+	"github.com/go-chi/chi/v5"
+)
+
+// systemContactGroupNames are the formatted names of the system contact groups
+var systemContactGroupNames = map[string]string{
+	"myContacts": "My Contacts",
+	"starred":    "Starred",
+	"all":        "All Contacts",
+}
+
+func (p *GooglePlugin) registerContactGroupRoutes(r chi.Router) {
+	r.Get("/contactGroups", p.listContactGroups)
+	r.Post("/contactGroups", p.createContactGroup)
+	r.Get("/contactGroups:batchGet", p.batchGetContactGroups)
+	r.Post("/contactGroups/batchGet", p.batchGetContactGroups)
+	r.Get("/contactGroups/{groupId}", p.getContactGroup)
+	r.Delete("/contactGroups/{groupId}", p.deleteContactGroup)
+	r.Post("/contactGroups/{groupId}/members:modify", p.modifyContactGroupMembers)
+}
+
+// contactGroupResourceName returns the group named in the URL, accepting
+// either the bare ID or the full contactGroups/ resource name
+func contactGroupResourceName(r *http.Request) string {
+	groupID := urlParam(r, "groupId")
+	if strings.HasPrefix(groupID, "contactGroups/") {
+		return groupID
+	}
+	return "contactGroups/" + groupID
+}
+
+// contactGroupToResponse converts a ContactGroup to People API response
+// format, listing memberResourceNames when members is non-nil
+func contactGroupToResponse(g *ContactGroup, members []string) map[string]any {
+	formattedName := g.Name
+	if g.GroupType == ContactGroupTypeSystem {
+		formattedName = systemContactGroupNames[g.Name]
+	}
+	resp := map[string]any{
+		"resourceName":  g.ResourceName,
+		"etag":          g.UpdatedAt,
+		"metadata":      map[string]any{"updateTime": g.UpdatedAt},
+		"groupType":     g.GroupType,
+		"name":          g.Name,
+		"formattedName": formattedName,
+		"memberCount":   g.MemberCount,
+	}
+	if members != nil {
+		resp["memberResourceNames"] = members
+	}
+	return resp
+}
+
+// maxMembersParam reads the maxMembers query parameter; 0, the default,
+// leaves members out of the response
+func maxMembersParam(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("maxMembers")
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("Invalid value for maxMembers: %q", raw)
+	}
+	return v, nil
+}
+
+// contactGroupMembers returns up to maxMembers member resource names for a
+// response, or nil when none were asked for
+func (p *GooglePlugin) contactGroupMembers(userID, resourceName string, maxMembers int) ([]string, error) {
+	if maxMembers <= 0 {
+		return nil, nil
+	}
+	members, err := p.store.ListContactGroupMembers(userID, resourceName, maxMembers)
+	if members == nil {
+		members = []string{}
+	}
+	return members, err
+}
+
+func (p *GooglePlugin) listContactGroups(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := auth.UserFromContext(r.Context())
+
+	pageSize, err := pageSizeParam(r, "pageSize", 30)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
+
+	groups, nextPageToken, err := p.store.ListContactGroups(userID, pageSize, r.URL.Query().Get("pageToken"))
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	items := make([]map[string]any, len(groups))
+	for i := range groups {
+		items[i] = contactGroupToResponse(&groups[i], nil)
+	}
+
+	resp := map[string]any{
+		"contactGroups": items,
+		"totalItems":    len(items),
+	}
+	if nextPageToken != "" {
+		resp["nextPageToken"] = nextPageToken
+	}
+
+	writeJSON(w, resp)
+}
+
+func (p *GooglePlugin) getContactGroup(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := auth.UserFromContext(r.Context())
+	resourceName := contactGroupResourceName(r)
+
+	maxMembers, err := maxMembersParam(r)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
+
+	group, err := p.store.GetContactGroup(userID, resourceName)
+	if errors.Is(err, ErrContactGroupNotFound) {
+		writeError(w, 404, "Contact group not found", "NOT_FOUND")
+		return
+	}
+	if err != nil {
+		writeError(w, 500, "Internal error", "INTERNAL")
+		return
+	}
+
+	members, err := p.contactGroupMembers(userID, resourceName, maxMembers)
+	if err != nil {
+		writeError(w, 500, "Internal error", "INTERNAL")
+		return
+	}
+
+	writeJSON(w, contactGroupToResponse(group, members))
+}
+
+func (p *GooglePlugin) createContactGroup(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := auth.UserFromContext(r.Context())
+
+	var req struct {
+		ContactGroup struct {
+			Name string `json:"name"`
+		} `json:"contactGroup"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_ARGUMENT")
+		return
+	}
+	name := strings.TrimSpace(req.ContactGroup.Name)
+	if name == "" {
+		writeError(w, 400, "contactGroup.name is required", "INVALID_ARGUMENT")
+		return
+	}
+
+	group, err := p.store.CreateContactGroup(userID, name)
+	if errors.Is(err, ErrContactGroupExists) {
+		writeError(w, 409, "Contact group name already exists", "ALREADY_EXISTS")
+		return
+	}
+	if err != nil {
+		writeError(w, 500, "Failed to create contact group", "INTERNAL")
+		return
+	}
+
+	writeJSON(w, contactGroupToResponse(group, nil))
+}
+
+func (p *GooglePlugin) deleteContactGroup(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := auth.UserFromContext(r.Context())
+	deleteContacts, _ := strconv.ParseBool(r.URL.Query().Get("deleteContacts"))
+
+	err := p.store.DeleteContactGroup(userID, contactGroupResourceName(r), deleteContacts)
+	switch {
+	case errors.Is(err, ErrContactGroupNotFound):
+		writeError(w, 404, "Contact group not found", "NOT_FOUND")
+		return
+	case errors.Is(err, ErrSystemContactGroup):
+		writeError(w, 400, "Cannot delete a system contact group", "FAILED_PRECONDITION")
+		return
+	case err != nil:
+		writeError(w, 500, "Failed to delete contact group", "INTERNAL")
+		return
+	}
+
+	writeJSON(w, map[string]any{})
+}
+
+// batchGetContactGroups handles both GET /contactGroups:batchGet, which takes
+// resourceNames query parameters, and POST /contactGroups/batchGet, which
+// takes a JSON body with resourceNames and maxMembers
+func (p *GooglePlugin) batchGetContactGroups(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := auth.UserFromContext(r.Context())
+
+	var req struct {
+		ResourceNames []string `json:"resourceNames"`
+		MaxMembers    int      `json:"maxMembers"`
+	}
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, 400, "Invalid request body", "INVALID_ARGUMENT")
+			return
+		}
+	} else {
+		req.ResourceNames = r.URL.Query()["resourceNames"]
+		maxMembers, err := maxMembersParam(r)
+		if err != nil {
+			writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+			return
+		}
+		req.MaxMembers = maxMembers
+	}
+	if len(req.ResourceNames) == 0 {
+		writeError(w, 400, "resourceNames is required", "INVALID_ARGUMENT")
+		return
+	}
+	if len(req.ResourceNames) > 200 {
+		writeError(w, 400, "At most 200 resourceNames may be requested", "INVALID_ARGUMENT")
+		return
+	}
+
+	responses := make([]map[string]any, 0, len(req.ResourceNames))
+	for _, resourceName := range req.ResourceNames {
+		entry := map[string]any{"requestedResourceName": resourceName}
+		group, err := p.store.GetContactGroup(userID, resourceName)
+		if errors.Is(err, ErrContactGroupNotFound) {
+			entry["status"] = map[string]any{"code": 5, "message": "Contact group not found"}
+			responses = append(responses, entry)
+			continue
+		}
+		if err != nil {
+			writeError(w, 500, "Internal error", "INTERNAL")
+			return
+		}
+		members, err := p.contactGroupMembers(userID, resourceName, req.MaxMembers)
+		if err != nil {
+			writeError(w, 500, "Internal error", "INTERNAL")
+			return
+		}
+		entry["contactGroup"] = contactGroupToResponse(group, members)
+		entry["status"] = map[string]any{}
+		responses = append(responses, entry)
+	}
+
+	writeJSON(w, map[string]any{"responses": responses})
+}
+
+func (p *GooglePlugin) modifyContactGroupMembers(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := auth.UserFromContext(r.Context())
+
+	var req struct {
+		ResourceNamesToAdd    []string `json:"resourceNamesToAdd"`
+		ResourceNamesToRemove []string `json:"resourceNamesToRemove"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_ARGUMENT")
+		return
+	}
+	if len(req.ResourceNamesToAdd) == 0 && len(req.ResourceNamesToRemove) == 0 {
+		writeError(w, 400, "resourceNamesToAdd or resourceNamesToRemove is required", "INVALID_ARGUMENT")
+		return
+	}
+
+	notFound, err := p.store.ModifyContactGroupMembers(userID, contactGroupResourceName(r), req.ResourceNamesToAdd, req.ResourceNamesToRemove)
+	switch {
+	case errors.Is(err, ErrContactGroupNotFound):
+		writeError(w, 404, "Contact group not found", "NOT_FOUND")
+		return
+	case errors.Is(err, ErrContactGroupNotModifiable):
+		writeError(w, 400, "Cannot modify the members of this contact group", "FAILED_PRECONDITION")
+		return
+	case err != nil:
+		writeError(w, 500, "Failed to modify contact group members", "INTERNAL")
+		return
+	}
+
+	resp := map[string]any{}
+	if len(notFound) > 0 {
+		resp["notFoundResourceNames"] = notFound
+	}
+	writeJSON(w, resp)
+}
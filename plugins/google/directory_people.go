@@ -0,0 +1,157 @@
+// ABOUTME: People API directory handlers for Google plugin.
+// ABOUTME: Lists and searches the domain directory, which is separate from each user's personal contacts.
+
+package google
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// directorySourceTypes are the values accepted by the sources parameter
+var directorySourceTypes = map[string]bool{
+	DirectorySourceDomainContact: true,
+	DirectorySourceDomainProfile: true,
+}
+
+// directorySources reads the sources parameter shared by the directory
+// endpoints. It may be repeated or comma separated, and at least one source
+// is required.
+func directorySources(r *http.Request) ([]string, error) {
+	var sources []string
+	for _, value := range r.URL.Query()["sources"] {
+		for _, source := range strings.Split(value, ",") {
+			source = strings.TrimSpace(source)
+			if source == "" {
+				continue
+			}
+			if !directorySourceTypes[source] {
+				return nil, fmt.Errorf("Invalid value at 'sources': %q", source)
+			}
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("Must specify at least one source")
+	}
+	return sources, nil
+}
+
+// directoryPersonToResponse converts a DirectoryPerson to People API response
+// format, naming the entry's source in its metadata
+func directoryPersonToResponse(person *DirectoryPerson) map[string]any {
+	var data map[string]any
+	if err := json.Unmarshal([]byte(person.Data), &data); err != nil {
+		log.Printf("Failed to unmarshal directory person data: %v", err)
+		data = map[string]any{}
+	}
+
+	resp := map[string]any{
+		"resourceName": person.ResourceName,
+		"etag":         person.UpdatedAt,
+		"metadata": map[string]any{
+			"sources": []map[string]any{{
+				"type":       strings.TrimPrefix(person.SourceType, "DIRECTORY_SOURCE_TYPE_"),
+				"id":         strings.TrimPrefix(person.ResourceName, "people/"),
+				"updateTime": person.UpdatedAt,
+			}},
+		},
+	}
+	for k, v := range data {
+		resp[k] = v
+	}
+	return resp
+}
+
+func directoryPeopleToResponse(people []DirectoryPerson) []map[string]any {
+	result := make([]map[string]any, len(people))
+	for i := range people {
+		result[i] = directoryPersonToResponse(&people[i])
+	}
+	return result
+}
+
+func (p *GooglePlugin) listDirectoryPeople(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	sources, err := directorySources(r)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
+	pageSize, err := pageSizeParam(r, "pageSize", 100)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
+	syncToken := r.URL.Query().Get("syncToken")
+
+	people, nextPageToken, nextSyncToken, err := p.store.ListDirectoryPeople(sources, pageSize, r.URL.Query().Get("pageToken"), syncToken)
+	if errors.Is(err, ErrInvalidSyncToken) {
+		writeError(w, 400, "Invalid sync token", "INVALID_ARGUMENT")
+		return
+	}
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"people": directoryPeopleToResponse(people),
+	}
+	if nextPageToken != "" {
+		resp["nextPageToken"] = nextPageToken
+	}
+	// Like Google, a full listing only returns a sync token when asked for one
+	if nextSyncToken != "" && (syncToken != "" || r.URL.Query().Get("requestSyncToken") == "true") {
+		resp["nextSyncToken"] = nextSyncToken
+	}
+
+	writeJSON(w, resp)
+}
+
+func (p *GooglePlugin) searchDirectoryPeople(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("query"))
+	if query == "" {
+		writeError(w, 400, "query is required", "INVALID_ARGUMENT")
+		return
+	}
+	sources, err := directorySources(r)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
+	pageSize, err := pageSizeParam(r, "pageSize", 100)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
+
+	people, total, nextPageToken, err := p.store.SearchDirectoryPeople(query, sources, pageSize, r.URL.Query().Get("pageToken"))
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"people":    directoryPeopleToResponse(people),
+		"totalSize": total,
+	}
+	if nextPageToken != "" {
+		resp["nextPageToken"] = nextPageToken
+	}
+
+	writeJSON(w, resp)
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/2389/ish/plugins/core"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -299,6 +300,16 @@ func getCalendarDiscoveryDoc(r *http.Request) map[string]interface{} {
 					},
 				},
 			},
+			"colors": map[string]interface{}{
+				"methods": map[string]interface{}{
+					"get": map[string]interface{}{
+						"id":          "calendar.colors.get",
+						"path":        "colors",
+						"httpMethod":  "GET",
+						"description": "Returns the color definitions for calendars and events",
+					},
+				},
+			},
 		},
 	}
 }
@@ -351,6 +362,61 @@ func getPeopleDiscoveryDoc(r *http.Request) map[string]interface{} {
 							},
 						},
 					},
+					"listDirectoryPeople": map[string]interface{}{
+						"id":          "people.people.listDirectoryPeople",
+						"path":        "people:listDirectoryPeople",
+						"httpMethod":  "GET",
+						"description": "Provides a list of domain profiles and domain contacts in the authenticated user's domain directory",
+						"parameters": map[string]interface{}{
+							"sources": map[string]interface{}{
+								"type":     "string",
+								"repeated": true,
+								"location": "query",
+							},
+							"pageSize": map[string]interface{}{
+								"type":     "integer",
+								"location": "query",
+							},
+							"pageToken": map[string]interface{}{
+								"type":     "string",
+								"location": "query",
+							},
+							"requestSyncToken": map[string]interface{}{
+								"type":     "boolean",
+								"location": "query",
+							},
+							"syncToken": map[string]interface{}{
+								"type":     "string",
+								"location": "query",
+							},
+						},
+					},
+					"searchDirectoryPeople": map[string]interface{}{
+						"id":          "people.people.searchDirectoryPeople",
+						"path":        "people:searchDirectoryPeople",
+						"httpMethod":  "GET",
+						"description": "Provides a list of domain profiles and domain contacts in the authenticated user's domain directory that match the search query",
+						"parameters": map[string]interface{}{
+							"query": map[string]interface{}{
+								"type":     "string",
+								"required": true,
+								"location": "query",
+							},
+							"sources": map[string]interface{}{
+								"type":     "string",
+								"repeated": true,
+								"location": "query",
+							},
+							"pageSize": map[string]interface{}{
+								"type":     "integer",
+								"location": "query",
+							},
+							"pageToken": map[string]interface{}{
+								"type":     "string",
+								"location": "query",
+							},
+						},
+					},
 					"createContact": map[string]interface{}{
 						"id":          "people.people.createContact",
 						"path":        "people:createContact",
@@ -415,5 +481,5 @@ func getBaseURL(r *http.Request) string {
 		scheme = "https"
 	}
 	host := r.Host
-	return scheme + "://" + host
+	return scheme + "://" + host + core.BasePath(r)
 }
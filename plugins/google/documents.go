@@ -0,0 +1,134 @@
+// ABOUTME: core.DocumentAdmin implementation for the Google plugin
+// ABOUTME: Exposes contacts and Gmail messages as JSON documents for admin JSON Patch edits
+
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// DocumentTypes implements core.DocumentAdmin
+func (p *GooglePlugin) DocumentTypes() []string {
+	return []string{"people", "gmail"}
+}
+
+// ImmutablePaths implements core.DocumentAdmin
+func (p *GooglePlugin) ImmutablePaths(docType string) []string {
+	switch docType {
+	case "people":
+		return []string{"/resourceName"}
+	case "gmail":
+		return []string{"/id", "/threadId", "/internalDate"}
+	}
+	return nil
+}
+
+// GetDocument implements core.DocumentAdmin. People are addressed by the ID
+// after "people/", Gmail messages by message ID.
+func (p *GooglePlugin) GetDocument(ctx context.Context, docType, id string) (map[string]any, error) {
+	switch docType {
+	case "people":
+		person, err := p.store.GetPersonByResourceName(personResourceName(id))
+		if err != nil {
+			return nil, err
+		}
+		doc := map[string]any{}
+		if err := json.Unmarshal([]byte(person.Data), &doc); err != nil {
+			return nil, fmt.Errorf("stored person data: %w", err)
+		}
+		doc["resourceName"] = person.ResourceName
+		return doc, nil
+
+	case "gmail":
+		userID, err := p.store.GetGmailMessageOwner(id)
+		if errors.Is(err, core.ErrMessageNotFound) {
+			return nil, core.ErrDocumentNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		msg, err := p.store.GetGmailMessage(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		payload := map[string]any{}
+		if err := json.Unmarshal([]byte(msg.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("stored message payload: %w", err)
+		}
+		return map[string]any{
+			"id":           msg.ID,
+			"threadId":     msg.ThreadID,
+			"labelIds":     msg.LabelIDs,
+			"snippet":      msg.Snippet,
+			"internalDate": strconv.FormatInt(msg.InternalDate, 10),
+			"payload":      payload,
+		}, nil
+	}
+	return nil, core.ErrDocumentNotFound
+}
+
+// PutDocument implements core.DocumentAdmin, checking the edited document
+// still has the shape the Google APIs serve before storing it
+func (p *GooglePlugin) PutDocument(ctx context.Context, docType, id string, doc map[string]any) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	switch docType {
+	case "people":
+		var person struct {
+			Names          []map[string]any `json:"names"`
+			EmailAddresses []map[string]any `json:"emailAddresses"`
+			PhoneNumbers   []map[string]any `json:"phoneNumbers"`
+		}
+		if err := json.Unmarshal(raw, &person); err != nil {
+			return fmt.Errorf("%w: %v", core.ErrInvalidDocument, err)
+		}
+		data := make(map[string]any, len(doc))
+		for k, v := range doc {
+			if k != "resourceName" {
+				data[k] = v
+			}
+		}
+		return p.store.ReplacePersonData(personResourceName(id), data)
+
+	case "gmail":
+		var msg struct {
+			LabelIDs []string       `json:"labelIds"`
+			Snippet  string         `json:"snippet"`
+			Payload  map[string]any `json:"payload"`
+		}
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return fmt.Errorf("%w: %v", core.ErrInvalidDocument, err)
+		}
+		if msg.Payload == nil {
+			return fmt.Errorf("%w: payload must be an object", core.ErrInvalidDocument)
+		}
+		payload, err := json.Marshal(msg.Payload)
+		if err != nil {
+			return err
+		}
+		err = p.store.UpdateGmailMessageContent(id, msg.LabelIDs, msg.Snippet, string(payload))
+		if errors.Is(err, core.ErrMessageNotFound) {
+			return core.ErrDocumentNotFound
+		}
+		return err
+	}
+	return core.ErrDocumentNotFound
+}
+
+// personResourceName accepts either "c123" or "people/c123"
+func personResourceName(id string) string {
+	if strings.HasPrefix(id, "people/") {
+		return id
+	}
+	return "people/" + id
+}
@@ -4,8 +4,10 @@
 package google
 
 import (
+	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -13,31 +15,62 @@ import (
 
 	"github.com/2389/ish/internal/auth"
 	"github.com/2389/ish/internal/autoreply"
+	"github.com/2389/ish/plugins/core"
 	"github.com/go-chi/chi/v5"
 )
 
-// googleStoreAdapter adapts GoogleStore to autoreply.GmailMessageSender interface
+// googleStoreAdapter adapts GoogleStore to autoreply.GmailMessageInserter interface
 type googleStoreAdapter struct {
 	store *GoogleStore
 }
 
-func (a *googleStoreAdapter) SendGmailMessage(userID, from, to, subject, body string) (any, error) {
-	return a.store.SendGmailMessage(userID, from, to, subject, body)
+func (a *googleStoreAdapter) InsertGmailMessage(userID, threadID, from, to, subject, body string, labels []string) (any, error) {
+	return a.store.InsertGmailMessage(userID, threadID, from, to, subject, body, labels)
 }
 
 func (p *GooglePlugin) registerGmailRoutes(r chi.Router) {
 	r.Route("/gmail/v1/users/{userId}", func(r chi.Router) {
 		r.Get("/profile", p.getProfile)
 		r.Get("/messages", p.listMessages)
-		r.Post("/messages/send", p.sendMessage)
+		r.Post("/messages", p.insertMessage)
+		r.Post("/messages/send", p.idempotent(p.dryRunnable((*GooglePlugin).sendMessage)))
+		r.Post("/messages/batchModify", p.batchModifyMessages)
 		r.Get("/messages/{messageId}", p.getMessage)
 		r.Delete("/messages/{messageId}", p.deleteMessage)
 		r.Post("/messages/{messageId}/trash", p.trashMessage)
+		r.Post("/messages/{messageId}/modify", p.modifyMessage)
 		r.Get("/messages/{messageId}/attachments/{attachmentId}", p.getAttachment)
 		r.Get("/history", p.listHistory)
+		p.registerGmailLabelRoutes(r)
+		p.registerGmailSettingsRoutes(r)
 	})
 }
 
+// idempotent lets clients retry a send with an Idempotency-Key without sending twice
+func (p *GooglePlugin) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if p.store == nil {
+			next(w, r)
+			return
+		}
+		core.HandleIdempotent(p.store.db, auth.UserFromContext(r.Context()), w, r, next)
+	}
+}
+
+// dryRunnable serves handler normally, or for a dry run against a copy of the
+// plugin whose store writes are rolled back afterwards
+func (p *GooglePlugin) dryRunnable(handler func(*GooglePlugin, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if p.store == nil || !core.IsDryRun(r) {
+			handler(p, w, r)
+			return
+		}
+		core.HandleDryRun(p.store.db, w, r, func(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+			handler(&GooglePlugin{store: &GoogleStore{db: db, clock: p.store.clock}, dryRun: true}, w, r)
+		})
+	}
+}
+
 func (p *GooglePlugin) listMessages(w http.ResponseWriter, r *http.Request) {
 	if p.store == nil {
 		writeError(w, 500, "Plugin not initialized", "INTERNAL")
@@ -53,18 +86,17 @@ func (p *GooglePlugin) listMessages(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[DEBUG] Gmail list: userID=%q, authHeader=%q, remoteAddr=%s",
 		userID, r.Header.Get("Authorization"), r.RemoteAddr)
 
-	maxResults := 100
-	if mr := r.URL.Query().Get("maxResults"); mr != "" {
-		if v, err := strconv.Atoi(mr); err == nil && v > 0 {
-			maxResults = v
-		}
+	maxResults, err := pageSizeParam(r, "maxResults", 100)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
 	}
 	pageToken := r.URL.Query().Get("pageToken")
 	query := r.URL.Query().Get("q")
 
 	messages, nextToken, err := p.store.ListGmailMessages(userID, maxResults, pageToken, query)
 	if err != nil {
-		writeError(w, 500, "Internal error", "INTERNAL")
+		writeListError(w, err)
 		return
 	}
 
@@ -108,23 +140,30 @@ func (p *GooglePlugin) getMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse payload JSON
-	var payload map[string]any
-	if err := json.Unmarshal([]byte(msg.Payload), &payload); err != nil {
+	resp, err := messageToResponse(msg)
+	if err != nil {
 		writeError(w, 500, "Failed to parse message payload", "INTERNAL")
 		return
 	}
 
-	resp := map[string]any{
+	writeJSON(w, resp)
+}
+
+// messageToResponse converts a GmailMessage to Gmail API response format
+func messageToResponse(msg *GmailMessage) (map[string]any, error) {
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(msg.Payload), &payload); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
 		"id":           msg.ID,
 		"threadId":     msg.ThreadID,
 		"labelIds":     msg.LabelIDs,
 		"snippet":      msg.Snippet,
 		"internalDate": strconv.FormatInt(msg.InternalDate, 10),
 		"payload":      payload,
-	}
-
-	writeJSON(w, resp)
+	}, nil
 }
 
 func (p *GooglePlugin) deleteMessage(w http.ResponseWriter, r *http.Request) {
@@ -215,6 +254,89 @@ func (p *GooglePlugin) trashMessage(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, resp)
 }
 
+// modifyLabelsRequest is the body of messages.modify and messages.batchModify
+type modifyLabelsRequest struct {
+	IDs            []string `json:"ids"`
+	AddLabelIDs    []string `json:"addLabelIds"`
+	RemoveLabelIDs []string `json:"removeLabelIds"`
+}
+
+func (p *GooglePlugin) modifyMessage(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := urlParam(r, "userId")
+	if userID == "me" {
+		userID = auth.UserFromContext(r.Context())
+	}
+	messageID := urlParam(r, "messageId")
+
+	var req modifyLabelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_ARGUMENT")
+		return
+	}
+
+	msg, err := p.store.ModifyGmailMessageLabels(userID, messageID, req.AddLabelIDs, req.RemoveLabelIDs)
+	if err != nil {
+		writeError(w, 404, "Message not found", "NOT_FOUND")
+		return
+	}
+
+	var payload map[string]any
+	if msg.Payload != "" {
+		if err := json.Unmarshal([]byte(msg.Payload), &payload); err != nil {
+			writeError(w, 500, "Failed to parse message payload", "INTERNAL")
+			return
+		}
+	}
+
+	resp := map[string]any{
+		"id":           msg.ID,
+		"threadId":     msg.ThreadID,
+		"labelIds":     msg.LabelIDs,
+		"snippet":      msg.Snippet,
+		"internalDate": strconv.FormatInt(msg.InternalDate, 10),
+		"payload":      payload,
+	}
+
+	writeJSON(w, resp)
+}
+
+func (p *GooglePlugin) batchModifyMessages(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := urlParam(r, "userId")
+	if userID == "me" {
+		userID = auth.UserFromContext(r.Context())
+	}
+
+	var req modifyLabelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_ARGUMENT")
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeError(w, 400, "ids is required", "INVALID_ARGUMENT")
+		return
+	}
+
+	// Like Gmail, IDs that don't name one of the user's messages are skipped
+	for _, id := range req.IDs {
+		if _, err := p.store.ModifyGmailMessageLabels(userID, id, req.AddLabelIDs, req.RemoveLabelIDs); err != nil && err.Error() != "message not found" {
+			writeError(w, 500, "Failed to modify messages", "INTERNAL")
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (p *GooglePlugin) getProfile(w http.ResponseWriter, r *http.Request) {
 	if p.store == nil {
 		writeError(w, 500, "Plugin not initialized", "INTERNAL")
@@ -270,6 +392,14 @@ func (p *GooglePlugin) getAttachment(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, resp)
 }
 
+// historyFields maps each historyTypes value to the history record field that carries it
+var historyFields = map[string]string{
+	GmailHistoryMessageAdded:   "messagesAdded",
+	GmailHistoryMessageDeleted: "messagesDeleted",
+	GmailHistoryLabelAdded:     "labelsAdded",
+	GmailHistoryLabelRemoved:   "labelsRemoved",
+}
+
 func (p *GooglePlugin) listHistory(w http.ResponseWriter, r *http.Request) {
 	if p.store == nil {
 		writeError(w, 500, "Plugin not initialized", "INTERNAL")
@@ -281,43 +411,55 @@ func (p *GooglePlugin) listHistory(w http.ResponseWriter, r *http.Request) {
 		userID = auth.UserFromContext(r.Context())
 	}
 
-	startHistoryID := int64(1)
+	startHistoryID := int64(0)
 	if sid := r.URL.Query().Get("startHistoryId"); sid != "" {
 		if v, err := strconv.ParseInt(sid, 10, 64); err == nil {
 			startHistoryID = v
 		}
 	}
 
-	maxResults := 100
-	if mr := r.URL.Query().Get("maxResults"); mr != "" {
-		if v, err := strconv.Atoi(mr); err == nil && v > 0 {
-			maxResults = v
-		}
+	maxResults, err := pageSizeParam(r, "maxResults", 100)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
 	}
 	pageToken := r.URL.Query().Get("pageToken")
 
-	entries, historyID, nextToken, err := p.store.ListGmailHistory(userID, startHistoryID, maxResults, pageToken)
+	historyTypes := r.URL.Query()["historyTypes"]
+	for _, t := range historyTypes {
+		if _, ok := historyFields[t]; !ok {
+			writeError(w, 400, "Invalid historyTypes value: "+t, "INVALID_ARGUMENT")
+			return
+		}
+	}
+
+	entries, historyID, nextToken, err := p.store.ListGmailHistory(userID, startHistoryID, historyTypes, maxResults, pageToken)
 	if err != nil {
-		writeError(w, 500, "Internal error", "INTERNAL")
+		writeListError(w, err)
 		return
 	}
 
 	// Convert entries to response format
 	historyList := make([]map[string]any, len(entries))
 	for i, entry := range entries {
-		messagesAdded := make([]map[string]any, len(entry.MessagesAdded))
-		for j, msg := range entry.MessagesAdded {
-			messagesAdded[j] = map[string]any{
-				"message": map[string]any{
-					"id":       msg.ID,
-					"threadId": msg.ThreadID,
-					"labelIds": msg.LabelIDs,
-				},
-			}
+		message := map[string]any{
+			"id":       entry.MessageID,
+			"threadId": entry.ThreadID,
+		}
+		change := map[string]any{
+			"message": map[string]any{
+				"id":       entry.MessageID,
+				"threadId": entry.ThreadID,
+				"labelIds": entry.LabelIDs,
+			},
+		}
+		if entry.Type == GmailHistoryLabelAdded || entry.Type == GmailHistoryLabelRemoved {
+			change["labelIds"] = entry.ChangedLabelIDs
 		}
 		historyList[i] = map[string]any{
-			"id":            strconv.FormatInt(entry.ID, 10),
-			"messagesAdded": messagesAdded,
+			"id":                      strconv.FormatInt(entry.ID, 10),
+			"messages":                []map[string]any{message},
+			historyFields[entry.Type]: []map[string]any{change},
 		}
 	}
 
@@ -348,26 +490,22 @@ func (p *GooglePlugin) sendMessage(w http.ResponseWriter, r *http.Request) {
 		userID, r.Header.Get("Authorization"), r.RemoteAddr)
 
 	var req struct {
-		Raw string `json:"raw"`
+		Raw      string `json:"raw"`
+		ThreadID string `json:"threadId"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, 400, "Invalid request body", "INVALID_REQUEST")
 		return
 	}
 
-	// Decode base64 email
-	decoded, err := base64.URLEncoding.DecodeString(req.Raw)
-	if err != nil {
-		// Try URL-safe variant
-		decoded, err = base64.RawURLEncoding.DecodeString(req.Raw)
-		if err != nil {
-			writeError(w, 400, "Invalid base64 encoding", "INVALID_REQUEST")
-			return
-		}
+	decoded, ok := decodeRawMessage(req.Raw)
+	if !ok {
+		writeError(w, 400, "Invalid base64 encoding", "INVALID_REQUEST")
+		return
 	}
 
 	// Parse email headers
-	headers, body := parseEmail(string(decoded))
+	headers, body := parseEmail(decoded)
 
 	to := headers["To"]
 	subject := headers["Subject"]
@@ -376,18 +514,32 @@ func (p *GooglePlugin) sendMessage(w http.ResponseWriter, r *http.Request) {
 		from = userID + "@example.com"
 	}
 
-	// Create message with SENT label
-	msg, err := p.store.SendGmailMessage(userID, from, to, subject, body)
+	// Create message with SENT label, replying into threadId when given
+	msg, err := p.store.SendGmailMessageInThread(userID, req.ThreadID, from, to, subject, body)
 	if err != nil {
-		writeError(w, 500, "Failed to send message", "INTERNAL")
+		if errors.Is(err, ErrThreadNotFound) {
+			writeError(w, 404, "Thread not found", "NOT_FOUND")
+		} else {
+			writeError(w, 500, "Failed to send message", "INTERNAL")
+		}
 		return
 	}
 
 	log.Printf("[DEBUG] Gmail send stored: msgID=%s, userID=%q", msg.ID, userID)
 
 	// Trigger auto-reply (runs in background)
-	autoReply := autoreply.New(&googleStoreAdapter{store: p.store})
-	autoReply.GenerateReply(userID, from, to, subject, body, msg.ThreadID)
+	if !p.dryRun {
+		autoReply := autoreply.New(&googleStoreAdapter{store: p.store})
+		autoReply.GenerateReply(autoreply.Message{
+			UserID:        userID,
+			ThreadID:      msg.ThreadID,
+			From:          from,
+			To:            to,
+			Subject:       subject,
+			Body:          body,
+			AutoSubmitted: headers["Auto-Submitted"],
+		})
+	}
 
 	resp := map[string]any{
 		"id":       msg.ID,
@@ -398,6 +550,68 @@ func (p *GooglePlugin) sendMessage(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, resp)
 }
 
+func (p *GooglePlugin) insertMessage(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := urlParam(r, "userId")
+	if userID == "me" {
+		userID = auth.UserFromContext(r.Context())
+	}
+
+	var req struct {
+		Raw      string   `json:"raw"`
+		ThreadID string   `json:"threadId"`
+		LabelIDs []string `json:"labelIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_REQUEST")
+		return
+	}
+
+	decoded, ok := decodeRawMessage(req.Raw)
+	if !ok {
+		writeError(w, 400, "Invalid base64 encoding", "INVALID_REQUEST")
+		return
+	}
+
+	headers, body := parseEmail(decoded)
+
+	msg, err := p.store.InsertGmailMessage(userID, req.ThreadID, headers["From"], headers["To"], headers["Subject"], body, req.LabelIDs)
+	if err != nil {
+		if errors.Is(err, ErrThreadNotFound) {
+			writeError(w, 404, "Thread not found", "NOT_FOUND")
+		} else {
+			writeError(w, 500, "Failed to insert message", "INTERNAL")
+		}
+		return
+	}
+	p.sendVacationReply(userID, msg, headers, req.LabelIDs)
+
+	resp := map[string]any{
+		"id":       msg.ID,
+		"threadId": msg.ThreadID,
+		"labelIds": msg.LabelIDs,
+	}
+
+	writeJSON(w, resp)
+}
+
+// decodeRawMessage decodes a base64url "raw" message, with or without padding
+func decodeRawMessage(raw string) (string, bool) {
+	decoded, err := base64.URLEncoding.DecodeString(raw)
+	if err != nil {
+		// Try URL-safe variant
+		decoded, err = base64.RawURLEncoding.DecodeString(raw)
+		if err != nil {
+			return "", false
+		}
+	}
+	return string(decoded), true
+}
+
 // parseEmail parses an RFC 2822 email message into headers and body
 func parseEmail(email string) (map[string]string, string) {
 	headers := make(map[string]string)
@@ -0,0 +1,287 @@
+// ABOUTME: Gmail labels API handlers for Google plugin.
+// ABOUTME: Label CRUD with the color palette and visibility values Gmail accepts.
+
+package google
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/2389/ish/internal/auth"
+	"github.com/go-chi/chi/v5"
+)
+
+// gmailLabelColors is the palette Gmail allows for a label's textColor and backgroundColor
+var gmailLabelColors = func() map[string]bool {
+	colors := map[string]bool{}
+	for _, color := range strings.Fields(`
+		#000000 #434343 #666666 #999999 #cccccc #efefef #f3f3f3 #ffffff
+		#fb4c2f #ffad47 #fad165 #16a766 #43d692 #4a86e8 #a479e2 #f691b3
+		#f6c5be #ffe6c7 #fef1d1 #b9e4d0 #c6f3de #c9daf8 #e4d7f5 #fcdee8
+		#efa093 #ffd6a2 #fce8b3 #89d3b2 #a0eac9 #a4c2f4 #d0bcf1 #fbc8d9
+		#e66550 #ffbc6b #fcda83 #44b984 #68dfa9 #6d9eeb #b694e8 #f7a7c0
+		#cc3a21 #eaa041 #f2c960 #149e60 #3dc789 #3c78d8 #8e63ce #e07798
+		#ac2b16 #cf8933 #d5ae49 #0b804b #2a9c68 #285bac #653e9b #b65775
+		#822111 #a46a21 #aa8831 #076239 #1a764d #1c4587 #41236d #83334c
+		#464646 #e7e7e7 #0d3472 #b6cff5 #0d3b44 #98d7e4 #3d188e #e3d7ff
+		#711a36 #fbd3e0 #8a1c0a #f2b2a8 #7a2e0b #ffc8af #7a4706 #ffdeb5
+		#594c05 #fbe983 #684e07 #fdedc1 #0b4f30 #b3efd3 #04502e #a2dcc1
+		#c2c2c2 #4986e7 #2da2bb #b99aff #994a64 #f691b2 #ff7537 #ffad46
+		#662e37 #ebdbde #cca6ac #094228 #42d692 #16a765`) {
+		colors[color] = true
+	}
+	return colors
+}()
+
+// Visibility values Gmail accepts for labels
+var (
+	gmailLabelListVisibilities   = map[string]bool{"labelShow": true, "labelShowIfUnread": true, "labelHide": true}
+	gmailMessageListVisibilities = map[string]bool{"show": true, "hide": true}
+)
+
+func (p *GooglePlugin) registerGmailLabelRoutes(r chi.Router) {
+	r.Get("/labels", p.listLabels)
+	r.Post("/labels", p.createLabel)
+	r.Get("/labels/{labelId}", p.getLabel)
+	r.Put("/labels/{labelId}", p.updateLabel)
+	r.Patch("/labels/{labelId}", p.updateLabel)
+	r.Delete("/labels/{labelId}", p.deleteLabel)
+}
+
+// gmailLabelRequest is a label resource in a request body. Fields left out
+// are nil, so a patch only changes the fields it names.
+type gmailLabelRequest struct {
+	Name                  *string `json:"name"`
+	LabelListVisibility   *string `json:"labelListVisibility"`
+	MessageListVisibility *string `json:"messageListVisibility"`
+	Color                 *struct {
+		TextColor       string `json:"textColor"`
+		BackgroundColor string `json:"backgroundColor"`
+	} `json:"color"`
+}
+
+// apply validates the request and copies the fields it sets onto l
+func (req *gmailLabelRequest) apply(l *GmailLabel) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return errors.New("Label name must not be empty")
+		}
+		l.Name = name
+	}
+	if req.LabelListVisibility != nil {
+		if *req.LabelListVisibility != "" && !gmailLabelListVisibilities[*req.LabelListVisibility] {
+			return errors.New("Invalid labelListVisibility: " + *req.LabelListVisibility)
+		}
+		l.LabelListVisibility = *req.LabelListVisibility
+	}
+	if req.MessageListVisibility != nil {
+		if *req.MessageListVisibility != "" && !gmailMessageListVisibilities[*req.MessageListVisibility] {
+			return errors.New("Invalid messageListVisibility: " + *req.MessageListVisibility)
+		}
+		l.MessageListVisibility = *req.MessageListVisibility
+	}
+	if req.Color != nil {
+		// Both colors empty removes the color
+		text := strings.ToLower(req.Color.TextColor)
+		background := strings.ToLower(req.Color.BackgroundColor)
+		if text != "" || background != "" {
+			if !gmailLabelColors[text] {
+				return errors.New("Label color " + req.Color.TextColor + " is not on the allowed color palette")
+			}
+			if !gmailLabelColors[background] {
+				return errors.New("Label color " + req.Color.BackgroundColor + " is not on the allowed color palette")
+			}
+		}
+		l.TextColor, l.BackgroundColor = text, background
+	}
+	return nil
+}
+
+// gmailLabelToResponse converts a GmailLabel to Gmail API response format
+func gmailLabelToResponse(l *GmailLabel) map[string]any {
+	resp := map[string]any{
+		"id":   l.ID,
+		"name": l.Name,
+		"type": "user",
+	}
+	if l.LabelListVisibility != "" {
+		resp["labelListVisibility"] = l.LabelListVisibility
+	}
+	if l.MessageListVisibility != "" {
+		resp["messageListVisibility"] = l.MessageListVisibility
+	}
+	if l.TextColor != "" {
+		resp["color"] = map[string]any{
+			"textColor":       l.TextColor,
+			"backgroundColor": l.BackgroundColor,
+		}
+	}
+	return resp
+}
+
+// gmailSystemLabelToResponse describes a built-in label, whose name is its ID
+func gmailSystemLabelToResponse(id string) map[string]any {
+	return map[string]any{
+		"id":   id,
+		"name": id,
+		"type": "system",
+	}
+}
+
+// gmailUserID returns the mailbox named in the URL, resolving "me" to the caller
+func gmailUserID(r *http.Request) string {
+	userID := urlParam(r, "userId")
+	if userID == "me" {
+		userID = auth.UserFromContext(r.Context())
+	}
+	return userID
+}
+
+func (p *GooglePlugin) listLabels(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	labels, err := p.store.ListGmailLabels(gmailUserID(r))
+	if err != nil {
+		writeError(w, 500, "Internal error", "INTERNAL")
+		return
+	}
+
+	items := make([]map[string]any, 0, len(gmailSystemLabels)+len(labels))
+	for _, id := range gmailSystemLabels {
+		items = append(items, gmailSystemLabelToResponse(id))
+	}
+	for _, l := range labels {
+		items = append(items, gmailLabelToResponse(l))
+	}
+	writeJSON(w, map[string]any{"labels": items})
+}
+
+func (p *GooglePlugin) getLabel(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	labelID := urlParam(r, "labelId")
+	if isGmailSystemLabel(labelID) {
+		writeJSON(w, gmailSystemLabelToResponse(labelID))
+		return
+	}
+
+	label, err := p.store.GetGmailLabel(gmailUserID(r), labelID)
+	if errors.Is(err, ErrGmailLabelNotFound) {
+		writeError(w, 404, "Requested entity was not found.", "NOT_FOUND")
+		return
+	}
+	if err != nil {
+		writeError(w, 500, "Internal error", "INTERNAL")
+		return
+	}
+	writeJSON(w, gmailLabelToResponse(label))
+}
+
+func (p *GooglePlugin) createLabel(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	var req gmailLabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_ARGUMENT")
+		return
+	}
+	if req.Name == nil {
+		writeError(w, 400, "Label name is required", "INVALID_ARGUMENT")
+		return
+	}
+	label := &GmailLabel{UserID: gmailUserID(r)}
+	if err := req.apply(label); err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
+
+	err := p.store.CreateGmailLabel(label)
+	if errors.Is(err, ErrGmailLabelExists) {
+		writeError(w, 409, "Label name exists or conflicts", "ALREADY_EXISTS")
+		return
+	}
+	if err != nil {
+		writeError(w, 500, "Failed to create label", "INTERNAL")
+		return
+	}
+	writeJSON(w, gmailLabelToResponse(label))
+}
+
+// updateLabel handles both PUT and PATCH; either way, only the fields in the
+// body change
+func (p *GooglePlugin) updateLabel(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	labelID := urlParam(r, "labelId")
+	if isGmailSystemLabel(labelID) {
+		writeError(w, 400, "Invalid update request", "INVALID_ARGUMENT")
+		return
+	}
+
+	label, err := p.store.GetGmailLabel(gmailUserID(r), labelID)
+	if errors.Is(err, ErrGmailLabelNotFound) {
+		writeError(w, 404, "Requested entity was not found.", "NOT_FOUND")
+		return
+	}
+	if err != nil {
+		writeError(w, 500, "Internal error", "INTERNAL")
+		return
+	}
+
+	var req gmailLabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_ARGUMENT")
+		return
+	}
+	if err := req.apply(label); err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
+	}
+
+	err = p.store.UpdateGmailLabel(label)
+	if errors.Is(err, ErrGmailLabelExists) {
+		writeError(w, 409, "Label name exists or conflicts", "ALREADY_EXISTS")
+		return
+	}
+	if err != nil {
+		writeError(w, 500, "Failed to update label", "INTERNAL")
+		return
+	}
+	writeJSON(w, gmailLabelToResponse(label))
+}
+
+func (p *GooglePlugin) deleteLabel(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	err := p.store.DeleteGmailLabel(gmailUserID(r), urlParam(r, "labelId"))
+	switch {
+	case errors.Is(err, ErrGmailLabelNotFound):
+		writeError(w, 404, "Requested entity was not found.", "NOT_FOUND")
+		return
+	case errors.Is(err, ErrSystemGmailLabel):
+		writeError(w, 400, "Invalid delete request", "INVALID_ARGUMENT")
+		return
+	case err != nil:
+		writeError(w, 500, "Failed to delete label", "INTERNAL")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
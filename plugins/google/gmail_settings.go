@@ -0,0 +1,205 @@
+// ABOUTME: Gmail settings API handlers for Google plugin.
+// ABOUTME: The vacation responder, which answers mail inserted into the inbox, and send-as aliases.
+
+package google
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (p *GooglePlugin) registerGmailSettingsRoutes(r chi.Router) {
+	r.Get("/settings/vacation", p.getVacationSettings)
+	r.Put("/settings/vacation", p.updateVacationSettings)
+	r.Get("/settings/sendAs", p.listSendAs)
+}
+
+// gmailAddress returns a mailbox's email address
+func gmailAddress(userID string) string {
+	return userID + "@example.com"
+}
+
+// gmailVacationToResponse converts GmailVacationSettings to Gmail API
+// response format. Times are int64s, which the API encodes as strings.
+func gmailVacationToResponse(v *GmailVacationSettings) map[string]any {
+	resp := map[string]any{
+		"enableAutoReply":       v.EnableAutoReply,
+		"responseSubject":       v.ResponseSubject,
+		"responseBodyPlainText": v.ResponseBodyPlain,
+		"responseBodyHtml":      v.ResponseBodyHTML,
+		"restrictToContacts":    v.RestrictToContacts,
+		"restrictToDomain":      v.RestrictToDomain,
+	}
+	if v.StartTime != 0 {
+		resp["startTime"] = strconv.FormatInt(v.StartTime, 10)
+	}
+	if v.EndTime != 0 {
+		resp["endTime"] = strconv.FormatInt(v.EndTime, 10)
+	}
+	return resp
+}
+
+// parseGmailInt64 reads an int64 sent as a JSON string or number; null or
+// absent is zero
+func parseGmailInt64(raw json.RawMessage) (int64, error) {
+	value := strings.Trim(string(raw), `"`)
+	if value == "" || value == "null" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func (p *GooglePlugin) getVacationSettings(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	v, err := p.store.GetGmailVacationSettings(gmailUserID(r))
+	if err != nil {
+		writeError(w, 500, "Failed to get vacation settings", "INTERNAL")
+		return
+	}
+	writeJSON(w, gmailVacationToResponse(v))
+}
+
+// updateVacationSettings replaces the vacation responder; fields left out
+// are reset, as with Gmail's updateVacation
+func (p *GooglePlugin) updateVacationSettings(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	var req struct {
+		EnableAutoReply       bool            `json:"enableAutoReply"`
+		ResponseSubject       string          `json:"responseSubject"`
+		ResponseBodyPlainText string          `json:"responseBodyPlainText"`
+		ResponseBodyHTML      string          `json:"responseBodyHtml"`
+		RestrictToContacts    bool            `json:"restrictToContacts"`
+		RestrictToDomain      bool            `json:"restrictToDomain"`
+		StartTime             json.RawMessage `json:"startTime"`
+		EndTime               json.RawMessage `json:"endTime"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_REQUEST")
+		return
+	}
+
+	v := &GmailVacationSettings{
+		UserID:             gmailUserID(r),
+		EnableAutoReply:    req.EnableAutoReply,
+		ResponseSubject:    req.ResponseSubject,
+		ResponseBodyPlain:  req.ResponseBodyPlainText,
+		ResponseBodyHTML:   req.ResponseBodyHTML,
+		RestrictToContacts: req.RestrictToContacts,
+		RestrictToDomain:   req.RestrictToDomain,
+	}
+	var err error
+	if v.StartTime, err = parseGmailInt64(req.StartTime); err != nil {
+		writeError(w, 400, "Invalid startTime", "INVALID_ARGUMENT")
+		return
+	}
+	if v.EndTime, err = parseGmailInt64(req.EndTime); err != nil {
+		writeError(w, 400, "Invalid endTime", "INVALID_ARGUMENT")
+		return
+	}
+	if v.StartTime != 0 && v.EndTime != 0 && v.EndTime <= v.StartTime {
+		writeError(w, 400, "Vacation end time must be after the start time", "INVALID_ARGUMENT")
+		return
+	}
+
+	if err := p.store.SetGmailVacationSettings(v); err != nil {
+		writeError(w, 500, "Failed to update vacation settings", "INTERNAL")
+		return
+	}
+	writeJSON(w, gmailVacationToResponse(v))
+}
+
+// listSendAs lists the mailbox's send-as aliases. Mailboxes have no aliases
+// besides their primary address.
+func (p *GooglePlugin) listSendAs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"sendAs": []map[string]any{{
+			"sendAsEmail":    gmailAddress(gmailUserID(r)),
+			"displayName":    "",
+			"replyToAddress": "",
+			"signature":      "",
+			"isPrimary":      true,
+			"isDefault":      true,
+			"treatAsAlias":   false,
+		}},
+	})
+}
+
+// sendVacationReply answers a message delivered to a mailbox's inbox when its
+// vacation responder is on. Each sender gets one reply per vacation. Like
+// Gmail, it doesn't answer the mailbox itself, automated mail (Auto-Submitted
+// or bulk Precedence), or senders outside the domain or contacts when the
+// responder is restricted to them.
+func (p *GooglePlugin) sendVacationReply(userID string, inbound *GmailMessage, headers map[string]string, labels []string) {
+	if !slices.Contains(labels, "INBOX") {
+		return
+	}
+	v, err := p.store.GetGmailVacationSettings(userID)
+	if err != nil || !v.Active(p.store.now()) {
+		return
+	}
+
+	address, err := mail.ParseAddress(headers["From"])
+	if err != nil {
+		return
+	}
+	sender := address.Address
+	own := gmailAddress(userID)
+	if strings.EqualFold(sender, own) {
+		return
+	}
+	if auto := strings.ToLower(headers["Auto-Submitted"]); auto != "" && auto != "no" {
+		return
+	}
+	switch strings.ToLower(headers["Precedence"]) {
+	case "bulk", "list", "junk":
+		return
+	}
+	if v.RestrictToDomain && !strings.EqualFold(emailDomain(sender), emailDomain(own)) {
+		return
+	}
+	if v.RestrictToContacts {
+		if ok, err := p.store.IsContact(userID, sender); err != nil || !ok {
+			return
+		}
+	}
+
+	if first, err := p.store.ClaimGmailVacationReply(userID, sender); err != nil || !first {
+		return
+	}
+
+	subject := v.ResponseSubject
+	if subject == "" {
+		subject = headers["Subject"]
+		if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+			subject = "Re: " + subject
+		}
+	}
+	body := v.ResponseBodyPlain
+	if body == "" {
+		body = v.ResponseBodyHTML
+	}
+
+	if _, err := p.store.SendGmailMessageInThread(userID, inbound.ThreadID, own, headers["From"], subject, body); err != nil {
+		log.Printf("Gmail vacation reply for %s failed: %v", userID, err)
+	}
+}
+
+// emailDomain returns the part of an address after the @
+func emailDomain(address string) string {
+	return address[strings.LastIndex(address, "@")+1:]
+}
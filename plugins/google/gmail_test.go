@@ -0,0 +1,678 @@
+// ABOUTME: Tests for Gmail API handlers in Google plugin.
+// ABOUTME: Covers send/insert behavior such as reply threading, label changes and history.
+
+package google
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/internal/auth"
+	"github.com/go-chi/chi/v5"
+)
+
+// setupGmailRouter returns a router with the Google plugin routes and auth middleware
+func setupGmailRouter(t *testing.T) (*GooglePlugin, chi.Router) {
+	t.Helper()
+	p := setupTestPlugin(t)
+	r := chi.NewRouter()
+	r.Use(auth.Middleware)
+	p.RegisterRoutes(r)
+	return p, r
+}
+
+// postGmailJSON sends a JSON body to a Gmail endpoint as user:alice
+func postGmailJSON(t *testing.T, r chi.Router, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer user:alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func rawMessage(headers, body string) string {
+	return base64.URLEncoding.EncodeToString([]byte(headers + "\r\n\r\n" + body))
+}
+
+// headerValue returns a header value from a stored message payload
+func headerValue(t *testing.T, payload, name string) string {
+	t.Helper()
+	var p struct {
+		Headers []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		t.Fatalf("failed to parse payload: %v", err)
+	}
+	for _, h := range p.Headers {
+		if h.Name == name {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+func TestGmailSendReplyThreading(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	// Original message starts a new thread
+	raw := rawMessage("From: alice@example.com\r\nTo: bob@example.com\r\nSubject: Lunch", "Lunch tomorrow?")
+	w := postGmailJSON(t, r, "/gmail/v1/users/me/messages/send", `{"raw":"`+raw+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("send got status %d: %s", w.Code, w.Body.String())
+	}
+	var original map[string]any
+	json.NewDecoder(w.Body).Decode(&original)
+	threadID := original["threadId"].(string)
+
+	origMsg, err := p.store.GetGmailMessage("alice", original["id"].(string))
+	if err != nil {
+		t.Fatalf("failed to load original: %v", err)
+	}
+	origMessageID := headerValue(t, origMsg.Payload, "Message-ID")
+	if origMessageID == "" {
+		t.Fatal("original message missing Message-ID header")
+	}
+
+	// Reply into the same thread
+	raw = rawMessage("From: alice@example.com\r\nTo: bob@example.com\r\nSubject: Re: Lunch", "Actually, make it noon.")
+	w = postGmailJSON(t, r, "/gmail/v1/users/me/messages/send", `{"raw":"`+raw+`","threadId":"`+threadID+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reply got status %d: %s", w.Code, w.Body.String())
+	}
+	var reply map[string]any
+	json.NewDecoder(w.Body).Decode(&reply)
+	if reply["threadId"] != threadID {
+		t.Fatalf("reply threadId = %v, want %s", reply["threadId"], threadID)
+	}
+
+	replyMsg, err := p.store.GetGmailMessage("alice", reply["id"].(string))
+	if err != nil {
+		t.Fatalf("failed to load reply: %v", err)
+	}
+	if got := headerValue(t, replyMsg.Payload, "In-Reply-To"); got != origMessageID {
+		t.Errorf("In-Reply-To = %q, want %q", got, origMessageID)
+	}
+	if got := headerValue(t, replyMsg.Payload, "References"); got != origMessageID {
+		t.Errorf("References = %q, want %q", got, origMessageID)
+	}
+
+	// A second reply chains References through both earlier messages
+	w = postGmailJSON(t, r, "/gmail/v1/users/me/messages/send", `{"raw":"`+raw+`","threadId":"`+threadID+`"}`)
+	var second map[string]any
+	json.NewDecoder(w.Body).Decode(&second)
+	secondMsg, _ := p.store.GetGmailMessage("alice", second["id"].(string))
+	wantRefs := origMessageID + " " + headerValue(t, replyMsg.Payload, "Message-ID")
+	if got := headerValue(t, secondMsg.Payload, "References"); got != wantRefs {
+		t.Errorf("References = %q, want %q", got, wantRefs)
+	}
+}
+
+func TestGmailSendIdempotencyKey(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	raw := rawMessage("From: alice@example.com\r\nTo: bob@example.com\r\nSubject: Once", "Only send this once")
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/gmail/v1/users/me/messages/send", strings.NewReader(`{"raw":"`+raw+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer user:alice")
+		req.Header.Set("Idempotency-Key", "send-once")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send()
+	second := send()
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("send got status %d then %d: %s", first.Code, second.Code, second.Body.String())
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("retry response = %s, want %s", second.Body.String(), first.Body.String())
+	}
+
+	messages, _, err := p.store.ListGmailMessages("alice", 10, "", "")
+	if err != nil {
+		t.Fatalf("failed to list messages: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Errorf("got %d messages, want 1", len(messages))
+	}
+}
+
+func TestGmailSendAutoReply(t *testing.T) {
+	t.Setenv("ISH_AUTO_REPLY", "true")
+	t.Setenv("ISH_REPLY_DELAY_MIN", "0")
+	t.Setenv("ISH_REPLY_DELAY_MAX", "0")
+	t.Setenv("ISH_AUTO_REPLY_TEMPLATE", "{{.To}} got {{.Subject}}")
+	p, r := setupGmailRouter(t)
+
+	raw := rawMessage("From: alice@example.com\r\nTo: bob@example.com, noreply@example.com, carol@elsewhere.com\r\nSubject: Lunch", "Free at noon?")
+	w := postGmailJSON(t, r, "/gmail/v1/users/me/messages/send", `{"raw":"`+raw+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("send got status %d: %s", w.Code, w.Body.String())
+	}
+	var sent struct {
+		ID       string `json:"id"`
+		ThreadID string `json:"threadId"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &sent)
+
+	// The reply is written in the background
+	var messages []GmailMessage
+	deadline := time.Now().Add(2 * time.Second)
+	for len(messages) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		messages, _, _ = p.store.ListGmailMessages("alice", 10, "", "")
+	}
+	// Give a reply from the filtered recipients time to show up if one were coming
+	time.Sleep(50 * time.Millisecond)
+	messages, _, _ = p.store.ListGmailMessages("alice", 10, "", "")
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want the sent message and one reply from bob", len(messages))
+	}
+
+	var reply GmailMessage
+	for _, m := range messages {
+		if m.ID != sent.ID {
+			reply = m
+		}
+	}
+	if reply.ThreadID != sent.ThreadID {
+		t.Errorf("reply threadId = %q, want %q", reply.ThreadID, sent.ThreadID)
+	}
+	if !slices.Contains(reply.LabelIDs, "INBOX") || !slices.Contains(reply.LabelIDs, "UNREAD") {
+		t.Errorf("reply labels = %v, want INBOX and UNREAD", reply.LabelIDs)
+	}
+	if reply.Snippet != "bob@example.com got Lunch" {
+		t.Errorf("reply snippet = %q, want the rendered template", reply.Snippet)
+	}
+	if !strings.Contains(reply.Payload, "Re: Lunch") || !strings.Contains(reply.Payload, "In-Reply-To") {
+		t.Errorf("reply payload = %s, want a threaded Re: subject", reply.Payload)
+	}
+}
+
+func TestGmailSendRejectsForeignThread(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	// Thread owned by a different user
+	other, err := p.store.SendGmailMessage("mallory", "mallory@example.com", "x@example.com", "secret", "body")
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	raw := rawMessage("To: bob@example.com\r\nSubject: Re: secret", "hi")
+	for _, path := range []string{"/gmail/v1/users/me/messages/send", "/gmail/v1/users/me/messages"} {
+		w := postGmailJSON(t, r, path, `{"raw":"`+raw+`","threadId":"`+other.ThreadID+`"}`)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s with foreign threadId got status %d, want 404", path, w.Code)
+		}
+	}
+}
+
+func TestGmailInsertIntoThread(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	sent, err := p.store.SendGmailMessage("alice", "alice@example.com", "bob@example.com", "Plans", "See you")
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	raw := rawMessage("From: bob@example.com\r\nTo: alice@example.com\r\nSubject: Re: Plans", "Sounds good")
+	w := postGmailJSON(t, r, "/gmail/v1/users/me/messages",
+		`{"raw":"`+raw+`","threadId":"`+sent.ThreadID+`","labelIds":["INBOX","UNREAD"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("insert got status %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["threadId"] != sent.ThreadID {
+		t.Errorf("threadId = %v, want %s", resp["threadId"], sent.ThreadID)
+	}
+	labels := resp["labelIds"].([]any)
+	if len(labels) != 2 || labels[0] != "INBOX" {
+		t.Errorf("labelIds = %v, want [INBOX UNREAD]", labels)
+	}
+}
+
+func TestGmailListDefaultUser(t *testing.T) {
+	p := setupTestPlugin(t)
+	r := chi.NewRouter()
+	r.Use(auth.Middleware)
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireUser(p.AnonymousRoutes()))
+		p.RegisterRoutes(r)
+	})
+
+	msg, err := p.store.CreateGmailMessageFromForm("quickstart", "alice@example.com", "", "Hello", "hi there", []string{"INBOX"})
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	if _, err := p.store.CreateGmailMessageFromForm("someone-else", "bob@example.com", "", "Private", "not yours", []string{"INBOX"}); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	list := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/gmail/v1/users/me/messages", nil))
+		return w
+	}
+
+	// Without a default user, credentials are required
+	if w := list(); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", w.Code)
+	}
+
+	auth.SetDefaultUser("quickstart")
+	t.Cleanup(func() { auth.SetDefaultUser("") })
+
+	w := list()
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with default user, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Messages) != 1 || resp.Messages[0].ID != msg.ID {
+		t.Errorf("expected only the default user's message %s, got %+v", msg.ID, resp.Messages)
+	}
+
+	// Discovery documents never need credentials
+	auth.SetDefaultUser("")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/discovery/v1/apis/gmail/v1/rest", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for discovery doc, got %d", w.Code)
+	}
+}
+
+// gmailHistory is the subset of a users.history.list response these tests read
+type gmailHistory struct {
+	HistoryID string `json:"historyId"`
+	History   []struct {
+		ID            string            `json:"id"`
+		MessagesAdded []json.RawMessage `json:"messagesAdded"`
+		LabelsAdded   []struct {
+			Message  struct{ ID string } `json:"message"`
+			LabelIDs []string            `json:"labelIds"`
+		} `json:"labelsAdded"`
+		LabelsRemoved   []json.RawMessage `json:"labelsRemoved"`
+		MessagesDeleted []json.RawMessage `json:"messagesDeleted"`
+	} `json:"history"`
+}
+
+func getGmailHistory(t *testing.T, r chi.Router, query string) (int, gmailHistory) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/gmail/v1/users/me/history?"+query, nil)
+	req.Header.Set("Authorization", "Bearer user:alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp gmailHistory
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode history: %v", err)
+		}
+	}
+	return w.Code, resp
+}
+
+func TestGmailHistoryRecordsLabelChanges(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	_, start := getGmailHistory(t, r, "startHistoryId=1")
+	msg, err := p.store.CreateGmailMessageFromForm("alice", "bob@example.com", "", "Hello", "hi", []string{"INBOX", "UNREAD"})
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	w := postGmailJSON(t, r, "/gmail/v1/users/me/messages/"+msg.ID+"/modify",
+		`{"addLabelIds":["STARRED"],"removeLabelIds":["UNREAD"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("modify got status %d: %s", w.Code, w.Body.String())
+	}
+	var modified struct {
+		LabelIDs []string `json:"labelIds"`
+	}
+	json.NewDecoder(w.Body).Decode(&modified)
+	if strings.Join(modified.LabelIDs, ",") != "INBOX,STARRED" {
+		t.Errorf("labelIds after modify = %v, want [INBOX STARRED]", modified.LabelIDs)
+	}
+
+	code, all := getGmailHistory(t, r, "startHistoryId="+start.HistoryID)
+	if code != http.StatusOK {
+		t.Fatalf("history got status %d", code)
+	}
+	if len(all.History) != 3 {
+		t.Fatalf("expected added, labelAdded and labelRemoved records, got %+v", all.History)
+	}
+	if len(all.History[0].MessagesAdded) != 1 || len(all.History[2].LabelsRemoved) != 1 {
+		t.Errorf("unexpected history order: %+v", all.History)
+	}
+	added := all.History[1].LabelsAdded
+	if len(added) != 1 || added[0].Message.ID != msg.ID || strings.Join(added[0].LabelIDs, ",") != "STARRED" {
+		t.Errorf("expected a labelAdded record for STARRED, got %+v", all.History[1])
+	}
+	if all.HistoryID != all.History[2].ID {
+		t.Errorf("historyId = %s, want the latest record %s", all.HistoryID, all.History[2].ID)
+	}
+
+	_, onlyAdded := getGmailHistory(t, r, "startHistoryId="+start.HistoryID+"&historyTypes=messageAdded")
+	if len(onlyAdded.History) != 1 || len(onlyAdded.History[0].MessagesAdded) != 1 {
+		t.Errorf("historyTypes=messageAdded should exclude label changes, got %+v", onlyAdded.History)
+	}
+
+	// Records at or before startHistoryId are not returned
+	_, since := getGmailHistory(t, r, "startHistoryId="+all.HistoryID)
+	if len(since.History) != 0 {
+		t.Errorf("expected no history after the latest id, got %+v", since.History)
+	}
+
+	if code, _ := getGmailHistory(t, r, "historyTypes=bogus"); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown historyTypes value, got %d", code)
+	}
+}
+
+func TestGmailBatchModifyAndDeleteHistory(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	m1, _ := p.store.CreateGmailMessageFromForm("alice", "bob@example.com", "", "One", "1", []string{"INBOX"})
+	m2, _ := p.store.CreateGmailMessageFromForm("alice", "bob@example.com", "", "Two", "2", []string{"INBOX"})
+	_, start := getGmailHistory(t, r, "")
+
+	w := postGmailJSON(t, r, "/gmail/v1/users/me/messages/batchModify",
+		`{"ids":["`+m1.ID+`","`+m2.ID+`","missing"],"addLabelIds":["IMPORTANT"]}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("batchModify got status %d: %s", w.Code, w.Body.String())
+	}
+	for _, id := range []string{m1.ID, m2.ID} {
+		msg, _ := p.store.GetGmailMessage("alice", id)
+		if strings.Join(msg.LabelIDs, ",") != "INBOX,IMPORTANT" {
+			t.Errorf("message %s labels = %v, want [INBOX IMPORTANT]", id, msg.LabelIDs)
+		}
+	}
+
+	req := httptest.NewRequest("DELETE", "/gmail/v1/users/me/messages/"+m1.ID, nil)
+	req.Header.Set("Authorization", "Bearer user:alice")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	_, labels := getGmailHistory(t, r, "startHistoryId="+start.HistoryID+"&historyTypes=labelAdded")
+	if len(labels.History) != 2 {
+		t.Errorf("expected a labelAdded record per modified message, got %+v", labels.History)
+	}
+	_, deleted := getGmailHistory(t, r, "startHistoryId="+start.HistoryID+"&historyTypes=messageDeleted")
+	if len(deleted.History) != 1 || len(deleted.History[0].MessagesDeleted) != 1 {
+		t.Errorf("expected one messageDeleted record, got %+v", deleted.History)
+	}
+}
+
+// sendGmailJSON sends a JSON body to a Gmail endpoint as user:alice
+func sendGmailJSON(r chi.Router, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer user:alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGmailLabelColorAndVisibility(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	w := postGmailJSON(t, r, "/gmail/v1/users/me/labels", `{"name": "Receipts", "labelListVisibility": "labelShow", "messageListVisibility": "show"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 creating a label, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]any
+	json.Unmarshal(w.Body.Bytes(), &created)
+	labelPath := "/gmail/v1/users/me/labels/" + created["id"].(string)
+
+	// A palette color survives a patch, and fields the patch leaves out are kept
+	w = sendGmailJSON(r, "PATCH", labelPath, `{"color": {"textColor": "#FFFFFF", "backgroundColor": "#16a766"}, "labelListVisibility": "labelHide"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 patching the label, got %d: %s", w.Code, w.Body.String())
+	}
+	w = sendGmailJSON(r, "GET", labelPath, "")
+	var label struct {
+		Name                  string `json:"name"`
+		LabelListVisibility   string `json:"labelListVisibility"`
+		MessageListVisibility string `json:"messageListVisibility"`
+		Color                 struct {
+			TextColor       string `json:"textColor"`
+			BackgroundColor string `json:"backgroundColor"`
+		} `json:"color"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &label)
+	if label.Name != "Receipts" || label.LabelListVisibility != "labelHide" || label.MessageListVisibility != "show" {
+		t.Errorf("Unexpected label after patch: %+v", label)
+	}
+	if label.Color.TextColor != "#ffffff" || label.Color.BackgroundColor != "#16a766" {
+		t.Errorf("Color = %+v, want #ffffff on #16a766", label.Color)
+	}
+
+	// Colors off the palette and unknown visibilities are rejected, leaving the label as it was
+	for _, body := range []string{
+		`{"color": {"textColor": "#ffffff", "backgroundColor": "#123456"}}`,
+		`{"color": {"textColor": "#ffffff"}}`,
+		`{"labelListVisibility": "visible"}`,
+		`{"messageListVisibility": "labelShow"}`,
+	} {
+		if w := sendGmailJSON(r, "PATCH", labelPath, body); w.Code != http.StatusBadRequest {
+			t.Errorf("PATCH %s = %d, want 400", body, w.Code)
+		}
+	}
+	if w := postGmailJSON(t, r, "/gmail/v1/users/me/labels", `{"name": "Bad", "color": {"textColor": "red", "backgroundColor": "#16a766"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Create with an invalid color = %d, want 400", w.Code)
+	}
+	w = sendGmailJSON(r, "GET", labelPath, "")
+	json.Unmarshal(w.Body.Bytes(), &label)
+	if label.Color.BackgroundColor != "#16a766" || label.LabelListVisibility != "labelHide" {
+		t.Errorf("Rejected patches changed the label: %+v", label)
+	}
+}
+
+func TestGmailLabelsCRUD(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	w := postGmailJSON(t, r, "/gmail/v1/users/me/labels", `{"name": "Travel"}`)
+	var created map[string]any
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created["type"] != "user" {
+		t.Fatalf("Unexpected created label: %s", w.Body.String())
+	}
+	if w := postGmailJSON(t, r, "/gmail/v1/users/me/labels", `{"name": "Travel"}`); w.Code != http.StatusConflict {
+		t.Errorf("Duplicate label name = %d, want 409", w.Code)
+	}
+
+	w = sendGmailJSON(r, "GET", "/gmail/v1/users/me/labels", "")
+	var list struct {
+		Labels []map[string]any `json:"labels"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Labels) != len(gmailSystemLabels)+1 || list.Labels[0]["type"] != "system" || list.Labels[len(list.Labels)-1]["name"] != "Travel" {
+		t.Errorf("Unexpected label list: %v", list.Labels)
+	}
+
+	if w := sendGmailJSON(r, "PATCH", "/gmail/v1/users/me/labels/INBOX", `{"name": "Mine"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Patching a system label = %d, want 400", w.Code)
+	}
+	labelPath := "/gmail/v1/users/me/labels/" + created["id"].(string)
+	if w := sendGmailJSON(r, "DELETE", labelPath, ""); w.Code != http.StatusNoContent {
+		t.Errorf("Delete = %d, want 204", w.Code)
+	}
+	if w := sendGmailJSON(r, "GET", labelPath, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Get after delete = %d, want 404", w.Code)
+	}
+}
+
+// sentGmailMessages returns alice's sent messages
+func sentGmailMessages(t *testing.T, p *GooglePlugin) []GmailMessage {
+	t.Helper()
+	messages, _, err := p.store.ListGmailMessages("alice", 100, "", "in:sent")
+	if err != nil {
+		t.Fatalf("failed to list sent messages: %v", err)
+	}
+	return messages
+}
+
+func TestGmailVacationSettings(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	w := sendGmailJSON(r, "GET", "/gmail/v1/users/me/settings/vacation", "")
+	var settings map[string]any
+	json.NewDecoder(w.Body).Decode(&settings)
+	if w.Code != http.StatusOK || settings["enableAutoReply"] != false {
+		t.Fatalf("expected the responder to start disabled, got %d: %v", w.Code, settings)
+	}
+
+	w = sendGmailJSON(r, "PUT", "/gmail/v1/users/me/settings/vacation",
+		`{"enableAutoReply":true,"responseSubject":"Away","responseBodyPlainText":"Back Monday","startTime":"1700000000000","endTime":1800000000000}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update got status %d: %s", w.Code, w.Body.String())
+	}
+	w = sendGmailJSON(r, "GET", "/gmail/v1/users/me/settings/vacation", "")
+	settings = nil
+	json.NewDecoder(w.Body).Decode(&settings)
+	if settings["enableAutoReply"] != true || settings["responseBodyPlainText"] != "Back Monday" ||
+		settings["startTime"] != "1700000000000" || settings["endTime"] != "1800000000000" {
+		t.Errorf("unexpected settings: %v", settings)
+	}
+
+	w = sendGmailJSON(r, "PUT", "/gmail/v1/users/me/settings/vacation", `{"enableAutoReply":true,"startTime":"2","endTime":"1"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an end time before the start time, got %d", w.Code)
+	}
+
+	w = sendGmailJSON(r, "GET", "/gmail/v1/users/me/settings/sendAs", "")
+	var sendAs struct {
+		SendAs []map[string]any `json:"sendAs"`
+	}
+	json.NewDecoder(w.Body).Decode(&sendAs)
+	if len(sendAs.SendAs) != 1 || sendAs.SendAs[0]["sendAsEmail"] != "alice@example.com" || sendAs.SendAs[0]["isPrimary"] != true {
+		t.Errorf("unexpected sendAs: %v", sendAs.SendAs)
+	}
+}
+
+func TestGmailVacationReply(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	insert := func(headers string) string {
+		t.Helper()
+		w := postGmailJSON(t, r, "/gmail/v1/users/me/messages",
+			`{"raw":"`+rawMessage(headers, "Are you around?")+`","labelIds":["INBOX","UNREAD"]}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("insert got status %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp["threadId"].(string)
+	}
+
+	// Nothing is sent while the responder is off
+	insert("From: Bob <bob@example.com>\r\nTo: alice@example.com\r\nSubject: Question")
+	if sent := sentGmailMessages(t, p); len(sent) != 0 {
+		t.Fatalf("expected no reply while disabled, got %d", len(sent))
+	}
+
+	w := sendGmailJSON(r, "PUT", "/gmail/v1/users/me/settings/vacation",
+		`{"enableAutoReply":true,"responseBodyPlainText":"I'm out of the office.","restrictToDomain":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update got status %d: %s", w.Code, w.Body.String())
+	}
+
+	threadID := insert("From: Bob <bob@example.com>\r\nTo: alice@example.com\r\nSubject: Question")
+	sent := sentGmailMessages(t, p)
+	if len(sent) != 1 {
+		t.Fatalf("expected one vacation reply, got %d", len(sent))
+	}
+	reply, err := p.store.GetGmailMessage("alice", sent[0].ID)
+	if err != nil {
+		t.Fatalf("failed to load reply: %v", err)
+	}
+	if reply.ThreadID != threadID {
+		t.Errorf("reply threadId = %s, want %s", reply.ThreadID, threadID)
+	}
+	if got := headerValue(t, reply.Payload, "To"); got != "Bob <bob@example.com>" {
+		t.Errorf("reply To = %q", got)
+	}
+	if got := headerValue(t, reply.Payload, "Subject"); got != "Re: Question" {
+		t.Errorf("reply Subject = %q, want Re: Question", got)
+	}
+	if reply.Snippet != "I'm out of the office." {
+		t.Errorf("reply snippet = %q", reply.Snippet)
+	}
+
+	// Outside senders, automated mail and the mailbox itself get no reply
+	insert("From: carol@elsewhere.test\r\nTo: alice@example.com\r\nSubject: Hello")
+	insert("From: bob@example.com\r\nTo: alice@example.com\r\nSubject: Receipt\r\nAuto-Submitted: auto-generated")
+	insert("From: alice@example.com\r\nTo: alice@example.com\r\nSubject: Note to self")
+	if sent := sentGmailMessages(t, p); len(sent) != 1 {
+		t.Errorf("expected no further replies, got %d sent", len(sent))
+	}
+
+	// The responder stops once its end time has passed
+	past := time.Now().Add(-time.Hour).UnixMilli()
+	sendGmailJSON(r, "PUT", "/gmail/v1/users/me/settings/vacation",
+		`{"enableAutoReply":true,"responseBodyPlainText":"Away","endTime":"`+strconv.FormatInt(past, 10)+`"}`)
+	insert("From: bob@example.com\r\nTo: alice@example.com\r\nSubject: Again")
+	if sent := sentGmailMessages(t, p); len(sent) != 1 {
+		t.Errorf("expected no reply after the end time, got %d sent", len(sent))
+	}
+}
+
+func TestGmailVacationReplyOncePerSender(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	insert := func(from string) {
+		t.Helper()
+		w := postGmailJSON(t, r, "/gmail/v1/users/me/messages",
+			`{"raw":"`+rawMessage("From: "+from+"\r\nTo: alice@example.com\r\nSubject: Hi", "Ping")+`","labelIds":["INBOX"]}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("insert got status %d: %s", w.Code, w.Body.String())
+		}
+	}
+	setVacation := func() {
+		t.Helper()
+		now := time.Now()
+		w := sendGmailJSON(r, "PUT", "/gmail/v1/users/me/settings/vacation",
+			`{"enableAutoReply":true,"responseBodyPlainText":"Away","startTime":"`+
+				strconv.FormatInt(now.Add(-time.Hour).UnixMilli(), 10)+`","endTime":"`+
+				strconv.FormatInt(now.Add(time.Hour).UnixMilli(), 10)+`"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("update got status %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	setVacation()
+	insert("Bob <bob@example.com>")
+	insert("BOB@example.com")
+	if sent := sentGmailMessages(t, p); len(sent) != 1 {
+		t.Fatalf("expected one reply to bob's two messages, got %d", len(sent))
+	}
+
+	insert("carol@example.com")
+	if sent := sentGmailMessages(t, p); len(sent) != 2 {
+		t.Fatalf("expected carol to get a reply too, got %d sent", len(sent))
+	}
+
+	// A new vacation answers everyone again
+	setVacation()
+	insert("bob@example.com")
+	if sent := sentGmailMessages(t, p); len(sent) != 3 {
+		t.Errorf("expected bob to be answered again after the responder was reset, got %d sent", len(sent))
+	}
+}
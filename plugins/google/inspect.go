@@ -0,0 +1,48 @@
+// ABOUTME: core.Inspector implementation for the Google plugin
+// ABOUTME: Looks up Gmail, Calendar, People, and Tasks records by ID and renders them as the APIs do
+
+package google
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *GooglePlugin) Inspect(resource, id string) (interface{}, error) {
+	switch resource {
+	case "messages":
+		owner, err := p.store.GetGmailMessageOwner(id)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := p.store.GetGmailMessage(owner, id)
+		if err != nil {
+			return nil, err
+		}
+		return messageToResponse(msg)
+	case "events":
+		evt, err := p.store.GetCalendarEventByID(id)
+		if err != nil {
+			return nil, err
+		}
+		return eventToResponse(evt), nil
+	case "contacts":
+		if !strings.HasPrefix(id, "people/") {
+			id = "people/" + id
+		}
+		person, err := p.store.GetPersonByResourceName(id)
+		if err != nil {
+			return nil, err
+		}
+		return personToResponse(person), nil
+	case "tasks":
+		task, err := p.store.GetTaskByID(id)
+		if err != nil {
+			return nil, err
+		}
+		return taskToResponse(task), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
@@ -0,0 +1,171 @@
+// ABOUTME: Page size and page token handling shared by Google list endpoints.
+// ABOUTME: Clamps maxResults/pageSize to a safe range and signs page tokens carrying the query's filters and offset.
+
+package google
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// maxPageSize caps maxResults/pageSize on every Google list endpoint
+const maxPageSize = 500
+
+// pageSizeParam reads a page size query parameter, clamping it to 1..maxPageSize.
+// A missing or zero value uses def, as Google does; negative or non-numeric
+// values are rejected.
+func pageSizeParam(r *http.Request, name string, def int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid value for %s: %q is not an integer", name, raw)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("Invalid value for %s: %d must not be negative", name, v)
+	}
+	if v == 0 {
+		return def, nil
+	}
+	return clampPageSize(v), nil
+}
+
+// clampPageSize keeps page sizes within 1..maxPageSize so the +1 lookahead
+// query limit and the next page offset can never overflow
+func clampPageSize(n int) int {
+	if n < 1 {
+		return 1
+	}
+	if n > maxPageSize {
+		return maxPageSize
+	}
+	return n
+}
+
+// PageTokenSecretEnv names the environment variable holding the key that
+// signs page tokens. Without it the key is generated on first use and kept in
+// the database, so tokens survive a restart against the same database.
+const PageTokenSecretEnv = "ISH_PAGE_TOKEN_SECRET"
+
+// ErrInvalidPageToken is returned when a page token was tampered with, signed
+// by another key, or issued for a different query
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageTokenKey signs every page token this process issues. It starts random
+// and SetDB replaces it with the key from loadPageTokenKey.
+var pageTokenKey = randomPageTokenKey()
+
+// pageTokenMACSize is how many bytes of HMAC-SHA256 each token carries
+const pageTokenMACSize = 16
+
+func randomPageTokenKey() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}
+
+// loadPageTokenKey returns PageTokenSecretEnv if set, otherwise the key stored
+// in db, generating and storing one on first use
+func loadPageTokenKey(db *sql.DB) ([]byte, error) {
+	if secret := os.Getenv(PageTokenSecretEnv); secret != "" {
+		return []byte(secret), nil
+	}
+	// INSERT OR IGNORE keeps the first key if two processes race on a new database
+	if _, err := db.Exec(`INSERT OR IGNORE INTO google_secrets (name, value) VALUES ('page_token_key', ?)`, randomPageTokenKey()); err != nil {
+		return nil, fmt.Errorf("failed to store page token key: %w", err)
+	}
+	var key []byte
+	if err := db.QueryRow(`SELECT value FROM google_secrets WHERE name = 'page_token_key'`).Scan(&key); err != nil {
+		return nil, fmt.Errorf("failed to load page token key: %w", err)
+	}
+	return key, nil
+}
+
+// pageToken is the query context carried from one page to the next. AsOf
+// is the newest rowid when the first page was read; later pages skip rows
+// inserted after it so new data can't shift the offset.
+type pageToken struct {
+	Filters map[string]string `json:"f,omitempty"`
+	Offset  int               `json:"o"`
+	AsOf    int64             `json:"a,omitempty"`
+}
+
+// encodePageToken returns an opaque, signed token for page
+func encodePageToken(page pageToken) string {
+	payload, _ := json.Marshal(page)
+	return base64.RawURLEncoding.EncodeToString(append(payload, pageTokenMAC(payload)...))
+}
+
+// decodePageToken returns the query context stored in a page token, or an
+// empty one for an empty token. Tokens that don't verify, or that were issued
+// for different filters than the request's, return ErrInvalidPageToken.
+func decodePageToken(token string, filters map[string]string) (pageToken, error) {
+	if token == "" {
+		return pageToken{Filters: filters}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) <= pageTokenMACSize {
+		return pageToken{}, ErrInvalidPageToken
+	}
+	payload, mac := raw[:len(raw)-pageTokenMACSize], raw[len(raw)-pageTokenMACSize:]
+	if !hmac.Equal(mac, pageTokenMAC(payload)) {
+		return pageToken{}, ErrInvalidPageToken
+	}
+
+	var page pageToken
+	if err := json.Unmarshal(payload, &page); err != nil || page.Offset < 0 || page.AsOf < 0 {
+		return pageToken{}, ErrInvalidPageToken
+	}
+	if !maps.Equal(page.Filters, filters) {
+		return pageToken{}, ErrInvalidPageToken
+	}
+	return page, nil
+}
+
+// next returns the token for the page after one holding n rows
+func (t pageToken) next(n int) string {
+	t.Offset += n
+	return encodePageToken(t)
+}
+
+// openPage decodes a list request's page token. On the first page it records
+// the newest rowid in table so every later page sees the same rows.
+func (s *GoogleStore) openPage(table, token string, filters map[string]string) (pageToken, error) {
+	page, err := decodePageToken(token, filters)
+	if err != nil || token != "" {
+		return page, err
+	}
+	var newest sql.NullInt64
+	if err := s.db.QueryRow("SELECT MAX(rowid) FROM " + table).Scan(&newest); err != nil {
+		return page, err
+	}
+	page.AsOf = newest.Int64
+	return page, nil
+}
+
+func pageTokenMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, pageTokenKey)
+	mac.Write(payload)
+	return mac.Sum(nil)[:pageTokenMACSize]
+}
+
+// writeListError reports a failed list query, answering 400 for a bad page token
+func writeListError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrInvalidPageToken) {
+		writeError(w, 400, "Invalid page token", "INVALID_ARGUMENT")
+		return
+	}
+	writeError(w, 500, "Internal error", "INTERNAL")
+}
@@ -0,0 +1,200 @@
+// ABOUTME: Tests for page size validation and page tokens on Google list endpoints.
+// ABOUTME: Covers clamping, negative values, signed token round-trips, stored signing keys, and stable iteration.
+
+package google
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestListMaxResultsClampedAtUpperBound(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	for i := 0; i < maxPageSize+2; i++ {
+		if _, err := p.store.CreateGmailMessageFromForm("alice", "bob@example.com", "", fmt.Sprintf("Message %d", i), "body", []string{"INBOX"}); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/gmail/v1/users/me/messages?maxResults=2147483647", nil)
+	req.Header.Set("Authorization", "Bearer user:alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Messages      []map[string]any `json:"messages"`
+		NextPageToken string           `json:"nextPageToken"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Messages) != maxPageSize {
+		t.Errorf("expected page clamped to %d messages, got %d", maxPageSize, len(resp.Messages))
+	}
+	if resp.NextPageToken == "" {
+		t.Error("expected nextPageToken for the remaining messages")
+	}
+}
+
+func TestPageSizeParam(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		want  int
+	}{
+		"missing":      {"", 100},
+		"zero":         {"maxResults=0", 100},
+		"in range":     {"maxResults=7", 7},
+		"over the cap": {"maxResults=100000", maxPageSize},
+	}
+	for name, tt := range tests {
+		got, err := pageSizeParam(httptest.NewRequest("GET", "/?"+tt.query, nil), "maxResults", 100)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: expected %d, got %d (%v)", name, tt.want, got, err)
+		}
+	}
+}
+
+func TestListNegativePageSizeRejected(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	paths := []string{
+		"/gmail/v1/users/me/messages?maxResults=-1",
+		"/gmail/v1/users/me/history?startHistoryId=1&maxResults=-5",
+		"/calendar/v3/calendars/primary/events?maxResults=-1",
+		"/v1/people/me/connections?pageSize=-1",
+		"/v1/people:searchContacts?query=a&pageSize=-1",
+		"/tasks/v1/lists/@default/tasks?maxResults=-1",
+		"/gmail/v1/users/me/messages?maxResults=ten",
+	}
+	for _, path := range paths {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer user:alice")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: expected 400, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	filters := map[string]string{"userId": "alice", "q": "label:INBOX"}
+	token := encodePageToken(pageToken{Filters: filters, Offset: 40, AsOf: 7})
+
+	page, err := decodePageToken(token, filters)
+	if err != nil {
+		t.Fatalf("decodePageToken failed: %v", err)
+	}
+	if page.Offset != 40 || page.AsOf != 7 || page.Filters["q"] != "label:INBOX" || page.Filters["userId"] != "alice" {
+		t.Errorf("expected the embedded filters and offset back, got %+v", page)
+	}
+	if strings.Contains(token, "40") || strings.Contains(token, "INBOX") {
+		t.Errorf("expected an opaque token, got %q", token)
+	}
+
+	if page, err := decodePageToken("", filters); err != nil || page.Offset != 0 {
+		t.Errorf("expected an empty token to start at offset 0, got %+v, %v", page, err)
+	}
+}
+
+func TestDecodePageTokenRejectsInvalidTokens(t *testing.T) {
+	filters := map[string]string{"userId": "alice"}
+	token := encodePageToken(pageToken{Filters: filters, Offset: 20})
+	tampered := []byte(token)
+	tampered[2] ^= 1
+
+	tests := map[string]struct {
+		token   string
+		filters map[string]string
+	}{
+		"tampered":        {string(tampered), filters},
+		"other query":     {token, map[string]string{"userId": "bob"}},
+		"legacy offset":   {"MjA=", filters},
+		"negative offset": {encodePageToken(pageToken{Filters: filters, Offset: -100}), filters},
+	}
+	for name, tt := range tests {
+		if _, err := decodePageToken(tt.token, tt.filters); !errors.Is(err, ErrInvalidPageToken) {
+			t.Errorf("%s: expected ErrInvalidPageToken, got %v", name, err)
+		}
+	}
+}
+
+func TestPageTokensSurviveRestart(t *testing.T) {
+	t.Setenv(PageTokenSecretEnv, "")
+	p := setupTestPlugin(t)
+	filters := map[string]string{"userId": "alice"}
+	token := encodePageToken(pageToken{Filters: filters, Offset: 20})
+
+	// A new plugin on the same database loads the stored key
+	if err := (&GooglePlugin{}).SetDB(p.store.db); err != nil {
+		t.Fatalf("SetDB failed: %v", err)
+	}
+	if page, err := decodePageToken(token, filters); err != nil || page.Offset != 20 {
+		t.Errorf("expected the token to decode after a restart, got %+v, %v", page, err)
+	}
+
+	// ISH_PAGE_TOKEN_SECRET takes precedence over the stored key
+	t.Setenv(PageTokenSecretEnv, "shared-secret")
+	if err := (&GooglePlugin{}).SetDB(p.store.db); err != nil {
+		t.Fatalf("SetDB failed: %v", err)
+	}
+	if _, err := decodePageToken(token, filters); !errors.Is(err, ErrInvalidPageToken) {
+		t.Errorf("expected the stored key to be replaced by %s, got %v", PageTokenSecretEnv, err)
+	}
+}
+
+func TestListPagesIgnoreMessagesAddedBetweenPages(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	list := func(query string) (ids []string, next string, code int) {
+		req := httptest.NewRequest("GET", "/gmail/v1/users/me/messages?maxResults=2"+query, nil)
+		req.Header.Set("Authorization", "Bearer user:alice")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp struct {
+			Messages      []map[string]any `json:"messages"`
+			NextPageToken string           `json:"nextPageToken"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		for _, m := range resp.Messages {
+			ids = append(ids, m["id"].(string))
+		}
+		return ids, resp.NextPageToken, w.Code
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := p.store.CreateGmailMessageFromForm("alice", "bob@example.com", "", fmt.Sprintf("Message %d", i), "body", []string{"INBOX"}); err != nil {
+			t.Fatalf("failed to create message: %v", err)
+		}
+	}
+	first, next, _ := list("")
+	if _, err := p.store.CreateGmailMessageFromForm("alice", "bob@example.com", "", "Late arrival", "body", []string{"INBOX"}); err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	second, _, code := list("&pageToken=" + next)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	seen := map[string]bool{}
+	for _, id := range append(first, second...) {
+		if seen[id] {
+			t.Errorf("message %s was returned on both pages", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("expected the 4 original messages across both pages, got %d", len(seen))
+	}
+
+	if _, _, code := list("&q=label:SENT&pageToken=" + next); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a token from another query, got %d", code)
+	}
+}
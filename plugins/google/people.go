@@ -4,10 +4,10 @@
 package google
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/2389/ish/internal/auth"
@@ -22,8 +22,14 @@ func (p *GooglePlugin) registerPeopleRoutes(r chi.Router) {
 		r.Get("/people/{resourceId}", p.getPerson)
 		r.Patch("/people/{resourceId}:updateContact", p.updateContact)
 		r.Delete("/people/{resourceId}:deleteContact", p.deleteContact)
+		r.Patch("/people/{resourceId}:updateContactPhoto", p.updateContactPhoto)
+		r.Delete("/people/{resourceId}:deleteContactPhoto", p.deleteContactPhoto)
+		r.Get("/photos/{photoId}", p.getContactPhoto)
 		r.Get("/people:searchContacts", p.searchContacts)
 		r.Post("/people:createContact", p.createContact)
+		r.Get("/people:listDirectoryPeople", p.listDirectoryPeople)
+		r.Get("/people:searchDirectoryPeople", p.searchDirectoryPeople)
+		p.registerContactGroupRoutes(r)
 	}
 
 	r.Route("/v1", registerPeopleV1Routes)
@@ -38,11 +44,10 @@ func (p *GooglePlugin) listConnections(w http.ResponseWriter, r *http.Request) {
 
 	userID := auth.UserFromContext(r.Context())
 
-	pageSize := 100
-	if ps := r.URL.Query().Get("pageSize"); ps != "" {
-		if v, err := strconv.Atoi(ps); err == nil && v > 0 {
-			pageSize = v
-		}
+	pageSize, err := pageSizeParam(r, "pageSize", 100)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
 	}
 	pageToken := r.URL.Query().Get("pageToken")
 	syncToken := r.URL.Query().Get("syncToken")
@@ -50,7 +55,7 @@ func (p *GooglePlugin) listConnections(w http.ResponseWriter, r *http.Request) {
 	// Use the new connections endpoint with sync token support
 	people, nextPageToken, nextSyncToken, err := p.store.ListPeopleConnections(userID, pageSize, pageToken, syncToken)
 	if err != nil {
-		writeError(w, 500, "Internal error", "INTERNAL")
+		writeListError(w, err)
 		return
 	}
 
@@ -97,17 +102,16 @@ func (p *GooglePlugin) searchContacts(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserFromContext(r.Context())
 	query := r.URL.Query().Get("query")
 
-	pageSize := 10
-	if ps := r.URL.Query().Get("pageSize"); ps != "" {
-		if v, err := strconv.Atoi(ps); err == nil && v > 0 {
-			pageSize = v
-		}
+	pageSize, err := pageSizeParam(r, "pageSize", 10)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
 	}
 	pageToken := r.URL.Query().Get("pageToken")
 
 	people, nextToken, err := p.store.SearchPeople(userID, query, pageSize, pageToken)
 	if err != nil {
-		writeError(w, 500, "Internal error", "INTERNAL")
+		writeListError(w, err)
 		return
 	}
 
@@ -164,6 +168,11 @@ func (p *GooglePlugin) getPerson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	writeJSON(w, personToResponse(person))
+}
+
+// personToResponse converts a Person to People API response format
+func personToResponse(person *Person) map[string]any {
 	var data map[string]any
 	if err := json.Unmarshal([]byte(person.Data), &data); err != nil {
 		log.Printf("Failed to unmarshal person data: %v", err)
@@ -178,7 +187,7 @@ func (p *GooglePlugin) getPerson(w http.ResponseWriter, r *http.Request) {
 		resp[k] = v
 	}
 
-	writeJSON(w, resp)
+	return resp
 }
 
 func (p *GooglePlugin) createContact(w http.ResponseWriter, r *http.Request) {
@@ -321,3 +330,130 @@ func (p *GooglePlugin) deleteContact(w http.ResponseWriter, r *http.Request) {
 	// Return 204 No Content on success
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// maxContactPhotoBytes bounds uploaded contact photos
+const maxContactPhotoBytes = 5 << 20
+
+// contactPhotoTypes are the image formats accepted as contact photos
+var contactPhotoTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// decodePhotoBytes decodes base64 photo bytes, accepting the standard and URL-safe alphabets
+func decodePhotoBytes(encoded string) ([]byte, bool) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if data, err := enc.DecodeString(encoded); err == nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+func (p *GooglePlugin) updateContactPhoto(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := auth.UserFromContext(r.Context())
+	resourceID := urlParam(r, "resourceId")
+	resourceName := "people/" + resourceID
+
+	// Handle full resource name format
+	if strings.HasPrefix(resourceID, "people/") {
+		resourceName = resourceID
+	}
+
+	var req struct {
+		PhotoBytes string `json:"photoBytes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, 400, "Invalid request body", "INVALID_ARGUMENT")
+		return
+	}
+	if req.PhotoBytes == "" {
+		writeError(w, 400, "photoBytes is required", "INVALID_ARGUMENT")
+		return
+	}
+	data, ok := decodePhotoBytes(req.PhotoBytes)
+	if !ok {
+		writeError(w, 400, "photoBytes is not valid base64", "INVALID_ARGUMENT")
+		return
+	}
+	if len(data) > maxContactPhotoBytes {
+		writeError(w, 400, "Photo is too large", "INVALID_ARGUMENT")
+		return
+	}
+	contentType := http.DetectContentType(data)
+	if !contactPhotoTypes[contentType] {
+		writeError(w, 400, "Photo must be a JPEG, PNG, GIF or WebP image", "INVALID_ARGUMENT")
+		return
+	}
+
+	person, err := p.store.SetPersonPhoto(userID, resourceName, contentType, data, getBaseURL(r))
+	if err != nil {
+		if err.Error() == "person not found" {
+			writeError(w, 404, "Contact not found", "NOT_FOUND")
+		} else {
+			writeError(w, 500, "Failed to update contact photo", "INTERNAL")
+		}
+		return
+	}
+
+	writeJSON(w, map[string]any{"person": personToResponse(person)})
+}
+
+func (p *GooglePlugin) deleteContactPhoto(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	userID := auth.UserFromContext(r.Context())
+	resourceID := urlParam(r, "resourceId")
+	resourceName := "people/" + resourceID
+
+	// Handle full resource name format
+	if strings.HasPrefix(resourceID, "people/") {
+		resourceName = resourceID
+	}
+
+	person, err := p.store.DeletePersonPhoto(userID, resourceName)
+	if err != nil {
+		if err.Error() == "person not found" {
+			writeError(w, 404, "Contact not found", "NOT_FOUND")
+		} else {
+			writeError(w, 500, "Failed to delete contact photo", "INTERNAL")
+		}
+		return
+	}
+
+	// Like Google, the person is only returned when personFields asks for it
+	resp := map[string]any{}
+	if r.URL.Query().Get("personFields") != "" {
+		resp["person"] = personToResponse(person)
+	}
+	writeJSON(w, resp)
+}
+
+// getContactPhoto serves a contact photo's bytes. Photo URLs are public, like
+// Google's, so image tags can load them without a token.
+func (p *GooglePlugin) getContactPhoto(w http.ResponseWriter, r *http.Request) {
+	if p.store == nil {
+		writeError(w, 500, "Plugin not initialized", "INTERNAL")
+		return
+	}
+
+	photo, err := p.store.GetPersonPhoto(urlParam(r, "photoId"))
+	if err != nil {
+		writeError(w, 404, "Photo not found", "NOT_FOUND")
+		return
+	}
+
+	w.Header().Set("Content-Type", photo.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Write(photo.Data)
+}
@@ -0,0 +1,312 @@
+// ABOUTME: Tests for People API handlers in Google plugin.
+// ABOUTME: Covers contact photos, the contact group lifecycle, and the domain directory.
+
+package google
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// sendPeopleJSON sends a People API request as user:alice
+func sendPeopleJSON(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer user:alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPeopleContactPhoto(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	w := sendPeopleJSON(r, "POST", "/people/v1/people:createContact", `{"names": [{"displayName": "Photo Person"}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("createContact got status %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]any
+	json.NewDecoder(w.Body).Decode(&created)
+	resourceID := strings.TrimPrefix(created["resourceName"].(string), "people/")
+
+	var img bytes.Buffer
+	png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	photoBytes := base64.StdEncoding.EncodeToString(img.Bytes())
+
+	w = sendPeopleJSON(r, "PATCH", "/people/v1/people/"+resourceID+":updateContactPhoto", `{"photoBytes": "`+photoBytes+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("updateContactPhoto got status %d: %s", w.Code, w.Body.String())
+	}
+	var updated struct {
+		Person struct {
+			Photos []struct {
+				URL string `json:"url"`
+			} `json:"photos"`
+		} `json:"person"`
+	}
+	json.NewDecoder(w.Body).Decode(&updated)
+	if len(updated.Person.Photos) != 1 || !strings.Contains(updated.Person.Photos[0].URL, "/people/v1/photos/") {
+		t.Fatalf("expected a photo URL on the person, got %+v", updated.Person.Photos)
+	}
+
+	// Photo URLs are served without a token
+	photoPath := updated.Person.Photos[0].URL[strings.Index(updated.Person.Photos[0].URL, "/people/v1/photos/"):]
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", photoPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET photo got status %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("photo Content-Type = %q, want image/png", ct)
+	}
+	if !bytes.Equal(w.Body.Bytes(), img.Bytes()) {
+		t.Error("photo bytes differ from the upload")
+	}
+
+	invalid := map[string]string{
+		"invalid base64": "not base64!",
+		"not an image":   base64.StdEncoding.EncodeToString([]byte("just some text")),
+	}
+	for name, encoded := range invalid {
+		w = sendPeopleJSON(r, "PATCH", "/people/v1/people/"+resourceID+":updateContactPhoto", `{"photoBytes": "`+encoded+`"}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want 400", name, w.Code)
+		}
+	}
+
+	w = sendPeopleJSON(r, "DELETE", "/people/v1/people/"+resourceID+":deleteContactPhoto?personFields=photos", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("deleteContactPhoto got status %d: %s", w.Code, w.Body.String())
+	}
+	var deleted map[string]map[string]any
+	json.NewDecoder(w.Body).Decode(&deleted)
+	if _, ok := deleted["person"]["photos"]; ok {
+		t.Errorf("expected photos removed, got %v", deleted["person"])
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", photoPath, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET deleted photo got status %d, want 404", w.Code)
+	}
+}
+
+func TestContactGroupLifecycle(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	// System groups exist without being created
+	w := sendPeopleJSON(r, "GET", "/v1/contactGroups", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list contact groups got status %d: %s", w.Code, w.Body.String())
+	}
+	var list struct {
+		ContactGroups []map[string]any `json:"contactGroups"`
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.ContactGroups) != 3 {
+		t.Fatalf("expected the 3 system groups, got %v", list.ContactGroups)
+	}
+	for _, g := range list.ContactGroups {
+		if g["groupType"] != ContactGroupTypeSystem {
+			t.Errorf("group %v is not a system group", g["resourceName"])
+		}
+	}
+
+	w = sendPeopleJSON(r, "DELETE", "/v1/contactGroups/starred", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("deleting a system group got status %d, want 400", w.Code)
+	}
+
+	w = sendPeopleJSON(r, "POST", "/v1/contactGroups", `{"contactGroup": {"name": "Family"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create contact group got status %d: %s", w.Code, w.Body.String())
+	}
+	var group map[string]any
+	json.NewDecoder(w.Body).Decode(&group)
+	groupName := group["resourceName"].(string)
+	if group["groupType"] != ContactGroupTypeUser || group["name"] != "Family" || group["memberCount"] != float64(0) {
+		t.Errorf("unexpected created group: %v", group)
+	}
+
+	w = sendPeopleJSON(r, "POST", "/v1/contactGroups", `{"contactGroup": {"name": "Family"}}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("duplicate group name got status %d, want 409", w.Code)
+	}
+
+	var contacts []string
+	for _, name := range []string{"Ann", "Ben"} {
+		w = sendPeopleJSON(r, "POST", "/v1/people:createContact", `{"names": [{"displayName": "`+name+`"}]}`)
+		var person map[string]any
+		json.NewDecoder(w.Body).Decode(&person)
+		contacts = append(contacts, person["resourceName"].(string))
+	}
+
+	modifyPath := "/v1/" + groupName + "/members:modify"
+	w = sendPeopleJSON(r, "POST", modifyPath, `{"resourceNamesToAdd": ["`+contacts[0]+`", "`+contacts[1]+`", "people/missing"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("members:modify got status %d: %s", w.Code, w.Body.String())
+	}
+	var modified struct {
+		NotFoundResourceNames []string `json:"notFoundResourceNames"`
+	}
+	json.NewDecoder(w.Body).Decode(&modified)
+	if len(modified.NotFoundResourceNames) != 1 || modified.NotFoundResourceNames[0] != "people/missing" {
+		t.Errorf("notFoundResourceNames = %v, want [people/missing]", modified.NotFoundResourceNames)
+	}
+
+	w = sendPeopleJSON(r, "POST", modifyPath, `{"resourceNamesToRemove": ["`+contacts[0]+`"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("members:modify remove got status %d: %s", w.Code, w.Body.String())
+	}
+
+	w = sendPeopleJSON(r, "GET", "/v1/"+groupName+"?maxMembers=10", "")
+	json.NewDecoder(w.Body).Decode(&group)
+	members, _ := group["memberResourceNames"].([]any)
+	if group["memberCount"] != float64(1) || len(members) != 1 || members[0] != contacts[1] {
+		t.Errorf("expected only %s in the group, got %v", contacts[1], group)
+	}
+
+	w = sendPeopleJSON(r, "POST", "/v1/contactGroups/batchGet", `{"resourceNames": ["`+groupName+`", "contactGroups/all", "contactGroups/nope"]}`)
+	var batch struct {
+		Responses []struct {
+			ContactGroup map[string]any `json:"contactGroup"`
+			Status       map[string]any `json:"status"`
+		} `json:"responses"`
+	}
+	json.NewDecoder(w.Body).Decode(&batch)
+	if len(batch.Responses) != 3 {
+		t.Fatalf("batchGet returned %d responses, want 3", len(batch.Responses))
+	}
+	if batch.Responses[1].ContactGroup["memberCount"] != float64(2) {
+		t.Errorf("all contacts memberCount = %v, want 2", batch.Responses[1].ContactGroup["memberCount"])
+	}
+	if batch.Responses[2].ContactGroup != nil || batch.Responses[2].Status["code"] != float64(5) {
+		t.Errorf("missing group should report NOT_FOUND, got %+v", batch.Responses[2])
+	}
+
+	w = sendPeopleJSON(r, "DELETE", "/v1/"+groupName, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete contact group got status %d: %s", w.Code, w.Body.String())
+	}
+	w = sendPeopleJSON(r, "GET", "/v1/"+groupName, "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("deleted group got status %d, want 404", w.Code)
+	}
+	// Deleting the group keeps its contacts
+	w = sendPeopleJSON(r, "GET", "/v1/"+contacts[1], "")
+	if w.Code != http.StatusOK {
+		t.Errorf("group member was deleted with the group: status %d", w.Code)
+	}
+}
+
+func TestDirectoryPeople(t *testing.T) {
+	p, r := setupGmailRouter(t)
+	if _, err := p.seedDirectoryPeople(); err != nil {
+		t.Fatalf("seedDirectoryPeople: %v", err)
+	}
+	sendPeopleJSON(r, "POST", "/people/v1/people:createContact", `{"names": [{"displayName": "Personal Friend"}]}`)
+
+	const profiles = "sources=DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE"
+
+	// A coworker is found in the directory but not in personal contacts
+	w := sendPeopleJSON(r, "GET", "/people/v1/people:searchDirectoryPeople?query=Priya&readMask=names,emailAddresses&"+profiles, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("searchDirectoryPeople got status %d: %s", w.Code, w.Body.String())
+	}
+	var search struct {
+		People []struct {
+			ResourceName   string `json:"resourceName"`
+			EmailAddresses []struct {
+				Value string `json:"value"`
+			} `json:"emailAddresses"`
+		} `json:"people"`
+		TotalSize int `json:"totalSize"`
+	}
+	json.NewDecoder(w.Body).Decode(&search)
+	if search.TotalSize != 1 || len(search.People) != 1 || search.People[0].EmailAddresses[0].Value != "priya.raman@example.com" {
+		t.Fatalf("expected Priya from the directory, got %+v", search)
+	}
+	w = sendPeopleJSON(r, "GET", "/people/v1/people:searchContacts?query=Priya", "")
+	var contacts struct {
+		Results []any `json:"results"`
+	}
+	json.NewDecoder(w.Body).Decode(&contacts)
+	if len(contacts.Results) != 0 {
+		t.Errorf("directory coworker showed up in personal contacts: %v", contacts.Results)
+	}
+	w = sendPeopleJSON(r, "GET", "/people/v1/people:searchDirectoryPeople?query=Personal&"+profiles, "")
+	json.NewDecoder(w.Body).Decode(&search)
+	if search.TotalSize != 0 {
+		t.Errorf("personal contact showed up in the directory: %+v", search)
+	}
+
+	for _, path := range []string{
+		"/people/v1/people:searchDirectoryPeople?query=Priya",
+		"/people/v1/people:listDirectoryPeople?sources=DIRECTORY_SOURCE_TYPE_UNSPECIFIED",
+	} {
+		if w := sendPeopleJSON(r, "GET", path, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s got status %d, want 400", path, w.Code)
+		}
+	}
+
+	// Page through both sources, collecting a sync token from the last page
+	var listed []string
+	var syncToken string
+	pageToken := ""
+	for {
+		w = sendPeopleJSON(r, "GET", "/people/v1/people:listDirectoryPeople?sources=DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE,DIRECTORY_SOURCE_TYPE_DOMAIN_CONTACT&pageSize=2&requestSyncToken=true&pageToken="+pageToken, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("listDirectoryPeople got status %d: %s", w.Code, w.Body.String())
+		}
+		var page struct {
+			People []struct {
+				ResourceName string `json:"resourceName"`
+			} `json:"people"`
+			NextPageToken string `json:"nextPageToken"`
+			NextSyncToken string `json:"nextSyncToken"`
+		}
+		json.NewDecoder(w.Body).Decode(&page)
+		for _, person := range page.People {
+			listed = append(listed, person.ResourceName)
+		}
+		if page.NextPageToken == "" {
+			syncToken = page.NextSyncToken
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+	if len(listed) != len(directorySeed) || syncToken == "" {
+		t.Fatalf("expected all %d directory entries and a sync token, got %v (token %q)", len(directorySeed), listed, syncToken)
+	}
+
+	// Syncing returns only entries changed since the token
+	p.store.UpsertDirectoryPerson(&DirectoryPerson{
+		ResourceName: "people/1001002",
+		SourceType:   DirectorySourceDomainProfile,
+		Data:         `{"names":[{"displayName":"Marcus Webb"}],"organizations":[{"title":"Director of Engineering"}]}`,
+	})
+	w = sendPeopleJSON(r, "GET", "/people/v1/people:listDirectoryPeople?"+profiles+"&syncToken="+url.QueryEscape(syncToken), "")
+	var changes struct {
+		People []struct {
+			ResourceName string `json:"resourceName"`
+		} `json:"people"`
+		NextSyncToken string `json:"nextSyncToken"`
+	}
+	json.NewDecoder(w.Body).Decode(&changes)
+	if len(changes.People) != 1 || changes.People[0].ResourceName != "people/1001002" || changes.NextSyncToken == "" {
+		t.Errorf("expected only the changed entry, got %s", w.Body.String())
+	}
+	w = sendPeopleJSON(r, "GET", "/people/v1/people:listDirectoryPeople?"+profiles+"&syncToken="+url.QueryEscape(changes.NextSyncToken), "")
+	json.NewDecoder(w.Body).Decode(&changes)
+	if len(changes.People) != 0 {
+		t.Errorf("expected no changes after the latest sync token, got %s", w.Body.String())
+	}
+}
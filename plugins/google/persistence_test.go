@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/2389/ish/internal/auth"
+	"github.com/2389/ish/internal/migrations"
 	"github.com/go-chi/chi/v5"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -25,6 +26,9 @@ func setupTestPlugin(t *testing.T) *GooglePlugin {
 	if err != nil {
 		t.Fatalf("failed to open in-memory database: %v", err)
 	}
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
 
 	p := &GooglePlugin{}
 	if err := p.SetDB(db); err != nil {
@@ -6,6 +6,8 @@ package google
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/2389/ish/plugins/core"
 	"github.com/go-chi/chi/v5"
@@ -17,12 +19,21 @@ func init() {
 
 type GooglePlugin struct {
 	store *GoogleStore
+
+	// dryRun marks the throwaway copy that serves a dry run, which never
+	// triggers auto-replies
+	dryRun bool
 }
 
 func (p *GooglePlugin) Name() string {
 	return "google"
 }
 
+// InjectedErrorBody is the error Google APIs return for an internal failure
+func (p *GooglePlugin) InjectedErrorBody() string {
+	return `{"error":{"code":500,"message":"Internal error encountered.","status":"INTERNAL"}}`
+}
+
 func (p *GooglePlugin) Health() core.HealthStatus {
 	return core.HealthStatus{
 		Status:  "healthy",
@@ -46,6 +57,50 @@ func (p *GooglePlugin) Schema() core.PluginSchema {
 	return getGoogleSchema()
 }
 
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *GooglePlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "gmail", Table: "gmail_messages", Resource: "messages"},
+		{Key: "calendar", Table: "calendar_events", Resource: "events"},
+		{Key: "contacts", Table: "people", Resource: "contacts"},
+		{Key: "tasks", Table: "tasks", Resource: "tasks"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *GooglePlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"gmail": {"gmail_attachments", "gmail_history", "gmail_labels", "gmail_messages", "gmail_threads", "gmail_vacation_replies", "gmail_vacation_settings"},
+		"calendar": {"calendar_channels", "calendar_events", "calendars"},
+		"contacts": {"people_contact_group_members", "people_contact_groups", "people_photos", "people", "directory_people", "sync_tokens"},
+		"tasks": {"tasks", "task_lists"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *GooglePlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *GooglePlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
+
+// AnonymousRoutes implements core.AnonymousRoutesPlugin. Discovery documents
+// and contact photo URLs are public; every other Google API needs a token or
+// the default user.
+func (p *GooglePlugin) AnonymousRoutes() []string {
+	return []string{
+		"GET /discovery/v1/apis/{api}/{version}/rest",
+		"GET /people/v1/photos/{photoId}",
+		"GET /v1/photos/{photoId}",
+	}
+}
+
 // Seed implementation is in seed.go
 
 func (p *GooglePlugin) ValidateToken(token string) bool {
@@ -59,7 +114,12 @@ func (p *GooglePlugin) SetDB(db *sql.DB) error {
 	if err != nil {
 		return err
 	}
+	key, err := loadPageTokenKey(db)
+	if err != nil {
+		return err
+	}
 	p.store = store
+	pageTokenKey = key
 	return nil
 }
 
@@ -95,10 +155,63 @@ func (p *GooglePlugin) ListResources(ctx context.Context, slug string, opts core
 	}
 }
 
-// GetResource implements core.DataProvider
+// GetResource implements core.DataProvider. Messages and events include
+// their JSON-valued fields for the admin JSON editor.
 func (p *GooglePlugin) GetResource(ctx context.Context, slug string, id string) (map[string]interface{}, error) {
-	// Not implemented yet
-	return nil, nil
+	switch slug {
+	case "messages":
+		userID, err := p.store.GetGmailMessageOwner(id)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := p.store.GetGmailMessage(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		detail, err := p.store.GetGmailMessageDetail(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"id":      msg.ID,
+			"subject": detail.Subject,
+			"from":    detail.From,
+			"to":      detail.To,
+			"body":    detail.Body,
+			"date":    time.UnixMilli(msg.InternalDate).UTC().Format(time.RFC3339),
+			"payload": rawJSON(msg.Payload),
+		}, nil
+	case "events":
+		evt, err := p.store.GetCalendarEventByID(id)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"id":          evt.ID,
+			"summary":     evt.Summary,
+			"description": evt.Description,
+			"start":       evt.StartTime,
+			"end":         evt.EndTime,
+			"location":    evt.Location,
+			"attendees":   rawJSON(evt.Attendees),
+			"recurrence":  rawJSON(evt.Recurrence),
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// rawJSON passes a stored JSON column through without decoding it. Empty
+// columns become null and anything unparseable is quoted as a string.
+func rawJSON(stored string) json.RawMessage {
+	if stored == "" {
+		return nil
+	}
+	if !json.Valid([]byte(stored)) {
+		quoted, _ := json.Marshal(stored)
+		return quoted
+	}
+	return json.RawMessage(stored)
 }
 
 // Conversion helpers
@@ -108,6 +221,8 @@ func convertMessagesToMaps(messages []GmailMessageView) []map[string]interface{}
 		result[i] = map[string]interface{}{
 			"id":      msg.ID,
 			"subject": msg.Subject,
+			"from":    msg.From,
+			"date":    msg.Date,
 			"snippet": msg.Snippet,
 		}
 	}
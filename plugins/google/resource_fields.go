@@ -0,0 +1,86 @@
+// ABOUTME: core.ResourceEditor implementation for the Google plugin
+// ABOUTME: Saves event attendees and recurrence and message payloads edited in the admin JSON editor
+
+package google
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// UpdateResourceFields implements core.ResourceEditor, checking each value
+// has the shape the Google APIs serve before storing it
+func (p *GooglePlugin) UpdateResourceFields(ctx context.Context, slug, id string, fields map[string]json.RawMessage) error {
+	switch slug {
+	case "events":
+		evt, err := p.store.GetCalendarEventByID(id)
+		if err != nil {
+			return fmt.Errorf("%w: %v", core.ErrResourceNotFound, err)
+		}
+		for name, value := range fields {
+			switch name {
+			case "attendees":
+				var attendees []map[string]any
+				if err := json.Unmarshal(value, &attendees); err != nil {
+					return fmt.Errorf("%w: attendees must be an array of objects", core.ErrInvalidField)
+				}
+				if attendees == nil {
+					value = json.RawMessage("[]")
+				}
+				evt.Attendees = compactJSON(value)
+			case "recurrence":
+				var rules []string
+				if err := json.Unmarshal(value, &rules); err != nil {
+					return fmt.Errorf("%w: recurrence must be an array of strings", core.ErrInvalidField)
+				}
+				evt.Recurrence = ""
+				if len(rules) > 0 {
+					evt.Recurrence = compactJSON(value)
+				}
+			default:
+				return fmt.Errorf("%w: %s can't be edited as JSON", core.ErrInvalidField, name)
+			}
+		}
+		_, err = p.store.UpdateCalendarEvent(evt, "")
+		return err
+
+	case "messages":
+		userID, err := p.store.GetGmailMessageOwner(id)
+		if errors.Is(err, core.ErrMessageNotFound) {
+			return core.ErrResourceNotFound
+		}
+		if err != nil {
+			return err
+		}
+		msg, err := p.store.GetGmailMessage(userID, id)
+		if err != nil {
+			return err
+		}
+		for name, value := range fields {
+			if name != "payload" {
+				return fmt.Errorf("%w: %s can't be edited as JSON", core.ErrInvalidField, name)
+			}
+			var payload map[string]any
+			if err := json.Unmarshal(value, &payload); err != nil || payload == nil {
+				return fmt.Errorf("%w: payload must be an object", core.ErrInvalidField)
+			}
+			msg.Payload = compactJSON(value)
+		}
+		return p.store.UpdateGmailMessageContent(id, msg.LabelIDs, msg.Snippet, msg.Payload)
+	}
+	return core.ErrResourceNotFound
+}
+
+// compactJSON strips the whitespace the editor's pretty-printing adds before storing
+func compactJSON(value json.RawMessage) string {
+	var out bytes.Buffer
+	if err := json.Compact(&out, value); err != nil {
+		return string(value)
+	}
+	return out.String()
+}
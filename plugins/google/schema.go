@@ -19,6 +19,7 @@ func getGoogleSchema() core.PluginSchema {
 					{Name: "to", Type: "email", Display: "To", Required: true, Editable: true},
 					{Name: "body", Type: "text", Display: "Body", Required: false, Editable: true},
 					{Name: "date", Type: "datetime", Display: "Date", Required: false, Editable: false},
+					{Name: "payload", Type: "json", Display: "Payload", Required: false, Editable: true},
 				},
 				Actions: []core.ActionSchema{
 					{Name: "delete", HTTPMethod: "DELETE", Endpoint: "/gmail/v1/users/me/messages/{id}", Confirm: true},
@@ -35,6 +36,8 @@ func getGoogleSchema() core.PluginSchema {
 					{Name: "start", Type: "datetime", Display: "Start", Required: true, Editable: true},
 					{Name: "end", Type: "datetime", Display: "End", Required: true, Editable: true},
 					{Name: "location", Type: "string", Display: "Location", Required: false, Editable: true},
+					{Name: "attendees", Type: "json", Display: "Attendees", Required: false, Editable: true},
+					{Name: "recurrence", Type: "json", Display: "Recurrence", Required: false, Editable: true},
 				},
 				Actions: []core.ActionSchema{
 					{Name: "delete", HTTPMethod: "DELETE", Endpoint: "/calendar/v3/calendars/primary/events/{id}", Confirm: true},
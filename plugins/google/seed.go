@@ -37,12 +37,54 @@ func (p *GooglePlugin) Seed(ctx context.Context, size string) (core.SeedData, er
 		log.Printf("Generator returned error: %v, falling back to static", err)
 	} else if err == nil && genData != nil && (len(genData.Emails) > 0 || len(genData.Events) > 0 || len(genData.Contacts) > 0) {
 		// Generation succeeded (either AI or generator's static fallback), use it
-		return p.seedFromAI(ctx, userID, genData, numTasks)
+		return p.withDirectory(p.seedFromAI(ctx, userID, genData, numTasks))
 	}
 
 	// Fall back to plugin's own static data if generation produced no results
 	log.Println("Using static seed data for Google plugin")
-	return p.seedStatic(ctx, userID, numMessages, numEvents, numPeople, numTasks)
+	return p.withDirectory(p.seedStatic(ctx, userID, numMessages, numEvents, numPeople, numTasks))
+}
+
+// withDirectory seeds the domain directory, which is shared by every user,
+// and adds it to a user's seed results
+func (p *GooglePlugin) withDirectory(data core.SeedData, err error) (core.SeedData, error) {
+	if err != nil {
+		return data, err
+	}
+	n, err := p.seedDirectoryPeople()
+	if err != nil {
+		return core.SeedData{}, err
+	}
+	data.Summary += fmt.Sprintf(", %d directory people", n)
+	data.Records["directory_people"] = n
+	return data, nil
+}
+
+// directorySeed is the domain directory: coworkers' profiles and a shared
+// domain contact, none of whom are in anyone's personal contacts
+var directorySeed = []struct {
+	id, source, data string
+}{
+	{"1001001", DirectorySourceDomainProfile, `{"names":[{"displayName":"Priya Raman","givenName":"Priya","familyName":"Raman"}],"emailAddresses":[{"value":"priya.raman@example.com","type":"work"}],"organizations":[{"name":"Example Inc","title":"Staff Engineer","department":"Platform"}],"phoneNumbers":[{"value":"+1-555-0201","type":"work"}]}`},
+	{"1001002", DirectorySourceDomainProfile, `{"names":[{"displayName":"Marcus Webb","givenName":"Marcus","familyName":"Webb"}],"emailAddresses":[{"value":"marcus.webb@example.com","type":"work"}],"organizations":[{"name":"Example Inc","title":"Engineering Manager","department":"Platform"}],"phoneNumbers":[{"value":"+1-555-0202","type":"work"}]}`},
+	{"1001003", DirectorySourceDomainProfile, `{"names":[{"displayName":"Sofia Alvarez","givenName":"Sofia","familyName":"Alvarez"}],"emailAddresses":[{"value":"sofia.alvarez@example.com","type":"work"}],"organizations":[{"name":"Example Inc","title":"Product Designer","department":"Design"}]}`},
+	{"1001004", DirectorySourceDomainProfile, `{"names":[{"displayName":"Kenji Watanabe","givenName":"Kenji","familyName":"Watanabe"}],"emailAddresses":[{"value":"kenji.watanabe@example.com","type":"work"}],"organizations":[{"name":"Example Inc","title":"Account Executive","department":"Sales"}]}`},
+	{"1001005", DirectorySourceDomainContact, `{"names":[{"displayName":"IT Help Desk"}],"emailAddresses":[{"value":"helpdesk@example.com","type":"work"}],"phoneNumbers":[{"value":"+1-555-0299","type":"work"}]}`},
+}
+
+// seedDirectoryPeople writes the domain directory, returning how many entries it holds
+func (p *GooglePlugin) seedDirectoryPeople() (int, error) {
+	for _, entry := range directorySeed {
+		person := &DirectoryPerson{
+			ResourceName: "people/" + entry.id,
+			SourceType:   entry.source,
+			Data:         entry.data,
+		}
+		if err := p.store.UpsertDirectoryPerson(person); err != nil {
+			return 0, err
+		}
+	}
+	return len(directorySeed), nil
 }
 
 // seedFromAI creates seed data using AI-generated content
@@ -54,7 +96,7 @@ func (p *GooglePlugin) seedFromAI(ctx context.Context, userID string, genData *s
 
 	// Create messages from AI data
 	for _, email := range genData.Emails {
-		_, err := p.store.CreateGmailMessageFromForm(userID, email.From, email.Subject, email.Body, email.Labels)
+		_, err := p.store.CreateGmailMessageFromForm(userID, email.From, email.To, email.Subject, email.Body, email.Labels)
 		if err != nil {
 			log.Printf("Failed to create AI message: %v", err)
 			continue
@@ -115,7 +157,7 @@ func (p *GooglePlugin) seedFromAI(ctx context.Context, userID string, genData *s
 				Due:    "",
 				Status: "needsAction",
 			}
-			_, err := p.store.CreateTask(task)
+			_, err := p.store.CreateTask(task, userID)
 			if err != nil {
 				log.Printf("Failed to create task '%s' in list '%s': %v", title, list.title, err)
 				continue
@@ -241,7 +283,7 @@ func (p *GooglePlugin) seedStatic(ctx context.Context, userID string, numMessage
 	totalMessages := 0
 	for i := 0; i < numMessages && i < len(messages); i++ {
 		msg := messages[i]
-		_, err := p.store.CreateGmailMessageFromForm(userID, msg.from, msg.subject, msg.body, msg.labels)
+		_, err := p.store.CreateGmailMessageFromForm(userID, msg.from, "", msg.subject, msg.body, msg.labels)
 		if err != nil {
 			log.Printf("Failed to create static message: %v", err)
 			continue
@@ -356,7 +398,7 @@ func (p *GooglePlugin) seedStatic(ctx context.Context, userID string, numMessage
 			OrganizerEmail: "harper@example.com",
 			OrganizerName:  "Harper",
 		}
-		_, err := p.store.CreateCalendarEvent(event)
+		_, err := p.store.CreateCalendarEvent(event, userID)
 		if err != nil {
 			log.Printf("Failed to create static event: %v", err)
 			continue
@@ -529,7 +571,7 @@ func (p *GooglePlugin) seedStatic(ctx context.Context, userID string, numMessage
 			Due:    t.due,
 			Status: t.status,
 		}
-		_, err := p.store.CreateTask(task)
+		_, err := p.store.CreateTask(task, userID)
 		if err != nil {
 			log.Printf("Failed to create static task: %v", err)
 			continue
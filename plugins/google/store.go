@@ -7,143 +7,71 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 // GoogleStore handles all database operations for the Google plugin
 type GoogleStore struct {
-	db *sql.DB
+	db    *sql.DB
+	clock core.Clock
 }
 
 // NewGoogleStore creates a new GoogleStore with the given database
 func NewGoogleStore(db *sql.DB) (*GoogleStore, error) {
-	store := &GoogleStore{db: db}
-	if err := store.initTables(); err != nil {
-		return nil, err
+	return &GoogleStore{db: db}, nil
+}
+
+// SetClock overrides the clock used for timestamps. IDs and page tokens
+// still come from the real clock so they stay unique when time is frozen.
+func (s *GoogleStore) SetClock(c core.Clock) {
+	s.clock = c
+}
+
+// audit records a mutation of a Google resource in the shared audit log
+func (s *GoogleStore) audit(resourceType, resourceID, action, actor string, changes any) error {
+	return auditIn(s.db, resourceType, resourceID, action, actor, changes)
+}
+
+// auditIn records a mutation through db, which is usually the transaction
+// making the change so the entry commits or rolls back with it
+func auditIn(db core.Execer, resourceType, resourceID, action, actor string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "google",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *GoogleStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
 	}
-	return store, nil
-}
-
-// initTables creates all Google plugin tables if they don't exist
-func (s *GoogleStore) initTables() error {
-	queries := []string{
-		// Gmail tables
-		`CREATE TABLE IF NOT EXISTS gmail_messages (
-			id TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			thread_id TEXT,
-			label_ids TEXT,
-			snippet TEXT,
-			internal_date INTEGER,
-			payload TEXT,
-			history_id INTEGER,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_gmail_messages_user_id ON gmail_messages(user_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_gmail_messages_thread_id ON gmail_messages(thread_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_gmail_messages_internal_date ON gmail_messages(internal_date)`,
-		`CREATE INDEX IF NOT EXISTS idx_gmail_messages_label_ids ON gmail_messages(label_ids)`,
-
-		`CREATE TABLE IF NOT EXISTS gmail_threads (
-			id TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			snippet TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_gmail_threads_user_id ON gmail_threads(user_id)`,
-
-		`CREATE TABLE IF NOT EXISTS gmail_attachments (
-			id TEXT PRIMARY KEY,
-			message_id TEXT NOT NULL,
-			filename TEXT,
-			mime_type TEXT,
-			size INTEGER,
-			data TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_gmail_attachments_message_id ON gmail_attachments(message_id)`,
-
-		// Calendar tables
-		`CREATE TABLE IF NOT EXISTS calendars (
-			id TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			summary TEXT,
-			sync_token TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_calendars_user_id ON calendars(user_id)`,
-
-		`CREATE TABLE IF NOT EXISTS calendar_events (
-			id TEXT PRIMARY KEY,
-			calendar_id TEXT NOT NULL,
-			summary TEXT,
-			description TEXT,
-			start_time TEXT,
-			end_time TEXT,
-			attendees TEXT,
-			location TEXT,
-			organizer_email TEXT,
-			organizer_name TEXT,
-			recurrence TEXT,
-			updated_at TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_calendar_events_calendar_id ON calendar_events(calendar_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_calendar_events_start_time ON calendar_events(start_time)`,
-
-		// People tables
-		`CREATE TABLE IF NOT EXISTS people (
-			resource_name TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			data TEXT,
-			updated_at TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_people_user_id ON people(user_id)`,
-
-		`CREATE TABLE IF NOT EXISTS sync_tokens (
-			id TEXT PRIMARY KEY,
-			resource_type TEXT NOT NULL,
-			user_id TEXT NOT NULL,
-			token TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_sync_tokens_resource_user ON sync_tokens(resource_type, user_id)`,
-
-		// Tasks tables
-		`CREATE TABLE IF NOT EXISTS task_lists (
-			id TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			title TEXT,
-			updated_at TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_task_lists_user_id ON task_lists(user_id)`,
-
-		`CREATE TABLE IF NOT EXISTS tasks (
-			id TEXT PRIMARY KEY,
-			list_id TEXT NOT NULL,
-			title TEXT,
-			notes TEXT,
-			due TEXT,
-			status TEXT DEFAULT 'needsAction',
-			completed TEXT,
-			updated_at TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_tasks_list_id ON tasks(list_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status)`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return err
-		}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
 	}
-	return nil
+	return tx.Commit()
+}
+
+// now returns the current time from the store's clock
+func (s *GoogleStore) now() time.Time {
+	if s.clock != nil {
+		return s.clock.Now()
+	}
+	return core.Now()
 }
 
 // Gmail types and methods
@@ -167,12 +95,15 @@ type GmailMessage struct {
 type GmailMessageView struct {
 	ID       string
 	Subject  string
+	From     string
+	Date     string // RFC3339, from internal_date
 	Snippet  string
 	LabelIDs []string
 }
 
 type GmailMessageDetail struct {
 	ID           string
+	UserID       string
 	ThreadID     string
 	Subject      string
 	From         string
@@ -182,6 +113,16 @@ type GmailMessageDetail struct {
 	Snippet      string
 	LabelIDs     []string
 	InternalDate int64
+	Headers      []GmailHeader
+}
+
+// gmailSystemLabels are the built-in Gmail labels every mailbox has
+var gmailSystemLabels = []string{"INBOX", "SENT", "DRAFT", "UNREAD", "STARRED", "IMPORTANT", "SPAM", "TRASH"}
+
+// GmailHeader is a single header from a stored message payload
+type GmailHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 type GmailProfile struct {
@@ -191,9 +132,24 @@ type GmailProfile struct {
 	HistoryID     int64
 }
 
+// Gmail history record types, as named by the historyTypes parameter
+const (
+	GmailHistoryMessageAdded   = "messageAdded"
+	GmailHistoryMessageDeleted = "messageDeleted"
+	GmailHistoryLabelAdded     = "labelAdded"
+	GmailHistoryLabelRemoved   = "labelRemoved"
+)
+
+// GmailHistoryEntry is one recorded change to a message
 type GmailHistoryEntry struct {
-	ID            int64
-	MessagesAdded []GmailMessage
+	ID        int64
+	Type      string
+	MessageID string
+	ThreadID  string
+	// LabelIDs are the message's labels after the change
+	LabelIDs []string
+	// ChangedLabelIDs are the labels a labelAdded or labelRemoved entry added or removed
+	ChangedLabelIDs []string
 }
 
 type GmailAttachment struct {
@@ -206,11 +162,16 @@ type GmailAttachment struct {
 }
 
 func (s *GoogleStore) CreateGmailThread(t *GmailThread) error {
-	_, err := s.db.Exec(
-		"INSERT INTO gmail_threads (id, user_id, snippet) VALUES (?, ?, ?)",
-		t.ID, t.UserID, t.Snippet,
-	)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			"INSERT INTO gmail_threads (id, user_id, snippet) VALUES (?, ?, ?)",
+			t.ID, t.UserID, t.Snippet,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "gmail_thread", t.ID, core.AuditCreate, t.UserID, map[string]any{"snippet": t.Snippet})
+	})
 }
 
 func (s *GoogleStore) CreateGmailMessage(m *GmailMessage) error {
@@ -219,7 +180,10 @@ func (s *GoogleStore) CreateGmailMessage(m *GmailMessage) error {
 		"INSERT INTO gmail_messages (id, user_id, thread_id, label_ids, snippet, internal_date, payload) VALUES (?, ?, ?, ?, ?, ?, ?)",
 		m.ID, m.UserID, m.ThreadID, string(labelJSON), m.Snippet, m.InternalDate, m.Payload,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	return s.recordGmailHistory(m.UserID, GmailHistoryMessageAdded, m.ID, m.ThreadID, m.LabelIDs, nil)
 }
 
 // GmailQueryFilters holds parsed Gmail query filters.
@@ -272,16 +236,14 @@ func parseGmailQuery(query string) GmailQueryFilters {
 }
 
 func (s *GoogleStore) ListGmailMessages(userID string, maxResults int, pageToken string, query string) ([]GmailMessage, string, error) {
-	offset := 0
-	if pageToken != "" {
-		decoded, err := base64.StdEncoding.DecodeString(pageToken)
-		if err == nil {
-			offset, _ = strconv.Atoi(string(decoded))
-		}
+	maxResults = clampPageSize(maxResults)
+	page, err := s.openPage("gmail_messages", pageToken, map[string]string{"userId": userID, "q": query})
+	if err != nil {
+		return nil, "", err
 	}
 
-	sqlQuery := "SELECT id, user_id, thread_id, label_ids, snippet, internal_date, payload FROM gmail_messages WHERE user_id = ?"
-	args := []any{userID}
+	sqlQuery := "SELECT id, user_id, thread_id, label_ids, snippet, internal_date, payload FROM gmail_messages WHERE user_id = ? AND rowid <= ?"
+	args := []any{userID, page.AsOf}
 
 	// Parse Gmail query syntax
 	if query != "" {
@@ -302,7 +264,7 @@ func (s *GoogleStore) ListGmailMessages(userID string, maxResults int, pageToken
 	}
 
 	sqlQuery += " ORDER BY internal_date DESC LIMIT ? OFFSET ?"
-	args = append(args, maxResults+1, offset) // +1 to check if there's more
+	args = append(args, maxResults+1, page.Offset) // +1 to check if there's more
 
 	rows, err := s.db.Query(sqlQuery, args...)
 	if err != nil {
@@ -325,7 +287,7 @@ func (s *GoogleStore) ListGmailMessages(userID string, maxResults int, pageToken
 	var nextToken string
 	if len(messages) > maxResults {
 		messages = messages[:maxResults]
-		nextToken = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset + maxResults)))
+		nextToken = page.next(maxResults)
 	}
 
 	return messages, nextToken, nil
@@ -356,6 +318,7 @@ func (s *GoogleStore) GetGmailMessageDetail(userID, messageID string) (*GmailMes
 
 	detail := &GmailMessageDetail{
 		ID:           m.ID,
+		UserID:       m.UserID,
 		ThreadID:     m.ThreadID,
 		Snippet:      m.Snippet,
 		LabelIDs:     m.LabelIDs,
@@ -364,11 +327,8 @@ func (s *GoogleStore) GetGmailMessageDetail(userID, messageID string) (*GmailMes
 
 	// Parse payload for headers and body
 	var p struct {
-		Headers []struct {
-			Name  string `json:"name"`
-			Value string `json:"value"`
-		} `json:"headers"`
-		Body struct {
+		Headers []GmailHeader `json:"headers"`
+		Body    struct {
 			Data string `json:"data"`
 		} `json:"body"`
 		Parts []struct {
@@ -379,6 +339,7 @@ func (s *GoogleStore) GetGmailMessageDetail(userID, messageID string) (*GmailMes
 		} `json:"parts"`
 	}
 	json.Unmarshal([]byte(m.Payload), &p)
+	detail.Headers = p.Headers
 
 	for _, h := range p.Headers {
 		switch h.Name {
@@ -413,7 +374,7 @@ func (s *GoogleStore) GetGmailMessageDetail(userID, messageID string) (*GmailMes
 }
 
 func (s *GoogleStore) ListAllGmailMessages() ([]GmailMessageView, error) {
-	rows, err := s.db.Query("SELECT id, snippet, label_ids, payload FROM gmail_messages ORDER BY internal_date DESC")
+	rows, err := s.db.Query("SELECT id, snippet, label_ids, internal_date, payload FROM gmail_messages ORDER BY internal_date DESC")
 	if err != nil {
 		return nil, err
 	}
@@ -423,12 +384,16 @@ func (s *GoogleStore) ListAllGmailMessages() ([]GmailMessageView, error) {
 	for rows.Next() {
 		var m GmailMessageView
 		var labelJSON, payload string
-		if err := rows.Scan(&m.ID, &m.Snippet, &labelJSON, &payload); err != nil {
+		var internalDate sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.Snippet, &labelJSON, &internalDate, &payload); err != nil {
 			return nil, err
 		}
 		json.Unmarshal([]byte(labelJSON), &m.LabelIDs)
+		if internalDate.Valid {
+			m.Date = time.UnixMilli(internalDate.Int64).UTC().Format(time.RFC3339)
+		}
 
-		// Extract subject from payload
+		// Extract subject and sender from payload
 		var p struct {
 			Headers []struct {
 				Name  string `json:"name"`
@@ -437,9 +402,11 @@ func (s *GoogleStore) ListAllGmailMessages() ([]GmailMessageView, error) {
 		}
 		json.Unmarshal([]byte(payload), &p)
 		for _, h := range p.Headers {
-			if h.Name == "Subject" {
+			switch h.Name {
+			case "Subject":
 				m.Subject = h.Value
-				break
+			case "From":
+				m.From = h.Value
 			}
 		}
 		messages = append(messages, m)
@@ -448,6 +415,15 @@ func (s *GoogleStore) ListAllGmailMessages() ([]GmailMessageView, error) {
 }
 
 func (s *GoogleStore) DeleteGmailMessage(userID, id string) error {
+	var threadID sql.NullString
+	err := s.db.QueryRow("SELECT thread_id FROM gmail_messages WHERE id = ? AND user_id = ?", id, userID).Scan(&threadID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("message not found")
+	}
+	if err != nil {
+		return err
+	}
+
 	result, err := s.db.Exec("DELETE FROM gmail_messages WHERE id = ? AND user_id = ?", id, userID)
 	if err != nil {
 		return err
@@ -456,20 +432,385 @@ func (s *GoogleStore) DeleteGmailMessage(userID, id string) error {
 	if rows == 0 {
 		return fmt.Errorf("message not found")
 	}
-	return nil
+	return s.recordGmailHistory(userID, GmailHistoryMessageDeleted, id, threadID.String, nil, nil)
 }
 
 func (s *GoogleStore) UpdateGmailMessageLabels(userID, messageID string, labelIDs []string) error {
+	before, err := s.GetGmailMessage(userID, messageID)
+	if err != nil {
+		return err
+	}
+
 	// Convert slice to JSON for database storage (consistent with other label storage)
 	labelJSON, err := json.Marshal(labelIDs)
 	if err != nil {
 		return err
 	}
 	_, err = s.db.Exec("UPDATE gmail_messages SET label_ids = ? WHERE id = ? AND user_id = ?", string(labelJSON), messageID, userID)
-	return err
+	if err != nil {
+		return err
+	}
+	return s.recordLabelChanges(userID, messageID, before.ThreadID, before.LabelIDs, labelIDs)
+}
+
+// ModifyGmailMessageLabels adds and then removes labels on a message, as the
+// Gmail modify endpoint does, and returns the updated message
+func (s *GoogleStore) ModifyGmailMessageLabels(userID, messageID string, add, remove []string) (*GmailMessage, error) {
+	msg, err := s.GetGmailMessage(userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []string{}
+	for _, label := range append(msg.LabelIDs, add...) {
+		if !slices.Contains(labels, label) && !slices.Contains(remove, label) {
+			labels = append(labels, label)
+		}
+	}
+	if err := s.UpdateGmailMessageLabels(userID, messageID, labels); err != nil {
+		return nil, err
+	}
+	msg.LabelIDs = labels
+	return msg, nil
+}
+
+// UpdateGmailMessageContent replaces a message's labels, snippet, and payload, for admin edits
+func (s *GoogleStore) UpdateGmailMessageContent(messageID string, labelIDs []string, snippet, payload string) error {
+	userID, err := s.GetGmailMessageOwner(messageID)
+	if err != nil {
+		return err
+	}
+	before, err := s.GetGmailMessage(userID, messageID)
+	if err != nil {
+		return err
+	}
+
+	labelJSON, err := json.Marshal(labelIDs)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.Exec("UPDATE gmail_messages SET label_ids = ?, snippet = ?, payload = ? WHERE id = ?",
+		string(labelJSON), snippet, payload, messageID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return core.ErrMessageNotFound
+	}
+	if err := s.audit("gmail_message", messageID, core.AuditUpdate, userID, map[string]any{"snippet": snippet}); err != nil {
+		return err
+	}
+	return s.recordLabelChanges(userID, messageID, before.ThreadID, before.LabelIDs, labelIDs)
+}
+
+// GetGmailMessageOwner returns the ID of the user whose mailbox holds the message
+func (s *GoogleStore) GetGmailMessageOwner(messageID string) (string, error) {
+	var userID string
+	err := s.db.QueryRow("SELECT user_id FROM gmail_messages WHERE id = ?", messageID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", core.ErrMessageNotFound
+	}
+	return userID, err
+}
+
+// ListGmailLabelIDs returns the system labels plus every label in use on a stored message
+func (s *GoogleStore) ListGmailLabelIDs() ([]string, error) {
+	rows, err := s.db.Query("SELECT label_ids FROM gmail_messages")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := append([]string{}, gmailSystemLabels...)
+	seen := make(map[string]bool)
+	for _, l := range labels {
+		seen[l] = true
+	}
+	var custom []string
+	for rows.Next() {
+		var labelJSON string
+		if err := rows.Scan(&labelJSON); err != nil {
+			return nil, err
+		}
+		var ids []string
+		json.Unmarshal([]byte(labelJSON), &ids)
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				custom = append(custom, id)
+			}
+		}
+	}
+	sort.Strings(custom)
+	return append(labels, custom...), rows.Err()
+}
+
+var (
+	ErrGmailLabelNotFound = errors.New("label not found")
+	ErrGmailLabelExists   = errors.New("label name already exists")
+	ErrSystemGmailLabel   = errors.New("system labels cannot be modified")
+)
+
+// GmailLabel is a user-created Gmail label. Colors are empty when the label
+// has no color.
+type GmailLabel struct {
+	ID                    string
+	UserID                string
+	Name                  string
+	LabelListVisibility   string
+	MessageListVisibility string
+	TextColor             string
+	BackgroundColor       string
+}
+
+// isGmailSystemLabel reports whether id is one of the built-in labels
+func isGmailSystemLabel(id string) bool {
+	for _, label := range gmailSystemLabels {
+		if label == id {
+			return true
+		}
+	}
+	return false
+}
+
+const gmailLabelColumns = "id, user_id, name, COALESCE(label_list_visibility, ''), COALESCE(message_list_visibility, ''), COALESCE(text_color, ''), COALESCE(background_color, '')"
+
+func scanGmailLabel(row interface{ Scan(...any) error }) (*GmailLabel, error) {
+	var l GmailLabel
+	err := row.Scan(&l.ID, &l.UserID, &l.Name, &l.LabelListVisibility, &l.MessageListVisibility, &l.TextColor, &l.BackgroundColor)
+	return &l, err
+}
+
+// ListGmailLabels lists a user's own labels by name
+func (s *GoogleStore) ListGmailLabels(userID string) ([]*GmailLabel, error) {
+	rows, err := s.db.Query("SELECT "+gmailLabelColumns+" FROM gmail_labels WHERE user_id = ? ORDER BY name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var labels []*GmailLabel
+	for rows.Next() {
+		l, err := scanGmailLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
+}
+
+// GetGmailLabel returns one of a user's own labels
+func (s *GoogleStore) GetGmailLabel(userID, id string) (*GmailLabel, error) {
+	l, err := scanGmailLabel(s.db.QueryRow("SELECT "+gmailLabelColumns+" FROM gmail_labels WHERE user_id = ? AND id = ?", userID, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrGmailLabelNotFound
+	}
+	return l, err
+}
+
+// gmailLabelNameTaken reports whether another label of the user, or a system
+// label, already has name
+func (s *GoogleStore) gmailLabelNameTaken(userID, name, exceptID string) (bool, error) {
+	if isGmailSystemLabel(strings.ToUpper(name)) {
+		return true, nil
+	}
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM gmail_labels WHERE user_id = ? AND name = ? AND id != ?", userID, name, exceptID).Scan(&count)
+	return count > 0, err
+}
+
+// CreateGmailLabel adds a label for l.UserID, filling in its ID
+func (s *GoogleStore) CreateGmailLabel(l *GmailLabel) error {
+	taken, err := s.gmailLabelNameTaken(l.UserID, l.Name, "")
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrGmailLabelExists
+	}
+
+	l.ID = fmt.Sprintf("Label_%d", time.Now().UnixNano())
+	_, err = s.db.Exec(
+		"INSERT INTO gmail_labels (id, user_id, name, label_list_visibility, message_list_visibility, text_color, background_color) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		l.ID, l.UserID, l.Name, l.LabelListVisibility, l.MessageListVisibility, l.TextColor, l.BackgroundColor,
+	)
+	if err != nil {
+		return err
+	}
+	return s.audit("gmail_label", l.ID, core.AuditCreate, l.UserID, map[string]any{"name": l.Name})
+}
+
+// UpdateGmailLabel saves every field of an existing label
+func (s *GoogleStore) UpdateGmailLabel(l *GmailLabel) error {
+	if isGmailSystemLabel(l.ID) {
+		return ErrSystemGmailLabel
+	}
+	taken, err := s.gmailLabelNameTaken(l.UserID, l.Name, l.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrGmailLabelExists
+	}
+
+	result, err := s.db.Exec(
+		"UPDATE gmail_labels SET name = ?, label_list_visibility = ?, message_list_visibility = ?, text_color = ?, background_color = ? WHERE user_id = ? AND id = ?",
+		l.Name, l.LabelListVisibility, l.MessageListVisibility, l.TextColor, l.BackgroundColor, l.UserID, l.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrGmailLabelNotFound
+	}
+	return s.audit("gmail_label", l.ID, core.AuditUpdate, l.UserID, map[string]any{
+		"name":                  l.Name,
+		"labelListVisibility":   l.LabelListVisibility,
+		"messageListVisibility": l.MessageListVisibility,
+		"textColor":             l.TextColor,
+		"backgroundColor":       l.BackgroundColor,
+	})
+}
+
+// DeleteGmailLabel deletes a user label. Messages keep the label ID, as they
+// do for any label ID they were given.
+func (s *GoogleStore) DeleteGmailLabel(userID, id string) error {
+	if isGmailSystemLabel(id) {
+		return ErrSystemGmailLabel
+	}
+	result, err := s.db.Exec("DELETE FROM gmail_labels WHERE user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrGmailLabelNotFound
+	}
+	return s.audit("gmail_label", id, core.AuditDelete, userID, nil)
+}
+
+// GmailVacationSettings is a mailbox's vacation responder. StartTime and
+// EndTime are epoch milliseconds; zero leaves that end of the window open.
+type GmailVacationSettings struct {
+	UserID             string
+	EnableAutoReply    bool
+	ResponseSubject    string
+	ResponseBodyPlain  string
+	ResponseBodyHTML   string
+	RestrictToContacts bool
+	RestrictToDomain   bool
+	StartTime          int64
+	EndTime            int64
+}
+
+// Active reports whether the responder answers mail received at t
+func (v *GmailVacationSettings) Active(t time.Time) bool {
+	ms := t.UnixMilli()
+	return v.EnableAutoReply && (v.StartTime == 0 || ms >= v.StartTime) && (v.EndTime == 0 || ms < v.EndTime)
+}
+
+// GetGmailVacationSettings returns a mailbox's vacation responder, which is
+// disabled until it has been set
+func (s *GoogleStore) GetGmailVacationSettings(userID string) (*GmailVacationSettings, error) {
+	v := GmailVacationSettings{UserID: userID}
+	err := s.db.QueryRow(`
+		SELECT enable_auto_reply, response_subject, response_body_plain, response_body_html,
+			restrict_to_contacts, restrict_to_domain, start_time, end_time
+		FROM gmail_vacation_settings WHERE user_id = ?
+	`, userID).Scan(&v.EnableAutoReply, &v.ResponseSubject, &v.ResponseBodyPlain, &v.ResponseBodyHTML,
+		&v.RestrictToContacts, &v.RestrictToDomain, &v.StartTime, &v.EndTime)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// SetGmailVacationSettings replaces a mailbox's vacation responder. Senders
+// it has already answered are forgotten, so a new vacation replies to them again.
+func (s *GoogleStore) SetGmailVacationSettings(v *GmailVacationSettings) error {
+	if _, err := s.db.Exec("DELETE FROM gmail_vacation_replies WHERE user_id = ?", v.UserID); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO gmail_vacation_settings (user_id, enable_auto_reply, response_subject, response_body_plain,
+			response_body_html, restrict_to_contacts, restrict_to_domain, start_time, end_time, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			enable_auto_reply = excluded.enable_auto_reply,
+			response_subject = excluded.response_subject,
+			response_body_plain = excluded.response_body_plain,
+			response_body_html = excluded.response_body_html,
+			restrict_to_contacts = excluded.restrict_to_contacts,
+			restrict_to_domain = excluded.restrict_to_domain,
+			start_time = excluded.start_time,
+			end_time = excluded.end_time,
+			updated_at = excluded.updated_at
+	`, v.UserID, v.EnableAutoReply, v.ResponseSubject, v.ResponseBodyPlain, v.ResponseBodyHTML,
+		v.RestrictToContacts, v.RestrictToDomain, v.StartTime, v.EndTime, s.now())
+	if err != nil {
+		return err
+	}
+	return s.audit("gmail_vacation", v.UserID, core.AuditUpdate, v.UserID, map[string]any{
+		"enableAutoReply": v.EnableAutoReply,
+		"responseSubject": v.ResponseSubject,
+	})
+}
+
+// ClaimGmailVacationReply records that a mailbox's vacation responder is
+// answering sender, reporting false if it already has. Senders match
+// case-insensitively.
+func (s *GoogleStore) ClaimGmailVacationReply(userID, sender string) (bool, error) {
+	result, err := s.db.Exec(`
+		INSERT OR IGNORE INTO gmail_vacation_replies (user_id, sender, replied_at) VALUES (?, ?, ?)
+	`, userID, strings.ToLower(sender), s.now())
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// IsContact reports whether one of a user's contacts has the email address
+func (s *GoogleStore) IsContact(userID, email string) (bool, error) {
+	rows, err := s.db.Query("SELECT data FROM people WHERE user_id = ?", userID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data sql.NullString
+		if err := rows.Scan(&data); err != nil {
+			return false, err
+		}
+		var person struct {
+			EmailAddresses []struct {
+				Value string `json:"value"`
+			} `json:"emailAddresses"`
+		}
+		json.Unmarshal([]byte(data.String), &person)
+		for _, address := range person.EmailAddresses {
+			if strings.EqualFold(address.Value, email) {
+				return true, nil
+			}
+		}
+	}
+	return false, rows.Err()
+}
+
+func (s *GoogleStore) CreateGmailMessageFromForm(userID, from, to, subject, body string, labels []string) (*GmailMessageView, error) {
+	return s.storeGmailMessage(userID, from, to, subject, body, time.Time{}, labels)
 }
 
-func (s *GoogleStore) CreateGmailMessageFromForm(userID, from, subject, body string, labels []string) (*GmailMessageView, error) {
+// ImportGmailMessage stores a message read from an mbox, keeping its
+// original date as the internal date and Date header
+func (s *GoogleStore) ImportGmailMessage(userID, from, to, subject, body string, date time.Time, labels []string) (*GmailMessageView, error) {
+	return s.storeGmailMessage(userID, from, to, subject, body, date, labels)
+}
+
+// storeGmailMessage creates a message in a new thread. A zero date means now,
+// without a Date header.
+func (s *GoogleStore) storeGmailMessage(userID, from, to, subject, body string, date time.Time, labels []string) (*GmailMessageView, error) {
 	id := fmt.Sprintf("msg_%d", time.Now().UnixNano())
 	threadID := fmt.Sprintf("thr_%d", time.Now().UnixNano())
 
@@ -478,11 +819,20 @@ func (s *GoogleStore) CreateGmailMessageFromForm(userID, from, subject, body str
 		threadID, userID, truncate(body, 100))
 
 	// Build payload using json.Marshal to properly escape special characters
+	headers := []map[string]string{
+		{"name": "From", "value": from},
+	}
+	if to != "" {
+		headers = append(headers, map[string]string{"name": "To", "value": to})
+	}
+	headers = append(headers, map[string]string{"name": "Subject", "value": subject})
+	internalDate := s.now()
+	if !date.IsZero() {
+		internalDate = date
+		headers = append(headers, map[string]string{"name": "Date", "value": date.Format(time.RFC1123Z)})
+	}
 	payloadData := map[string]any{
-		"headers": []map[string]string{
-			{"name": "From", "value": from},
-			{"name": "Subject", "value": subject},
-		},
+		"headers": headers,
 		"body": map[string]string{
 			"data": base64.URLEncoding.EncodeToString([]byte(body)),
 		},
@@ -493,11 +843,14 @@ func (s *GoogleStore) CreateGmailMessageFromForm(userID, from, subject, body str
 
 	_, err := s.db.Exec(
 		"INSERT INTO gmail_messages (id, user_id, thread_id, label_ids, snippet, internal_date, payload) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		id, userID, threadID, string(labelJSON), truncate(body, 100), time.Now().UnixMilli(), string(payloadBytes),
+		id, userID, threadID, string(labelJSON), truncate(body, 100), internalDate.UnixMilli(), string(payloadBytes),
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.recordGmailHistory(userID, GmailHistoryMessageAdded, id, threadID, labels, nil); err != nil {
+		return nil, err
+	}
 
 	return &GmailMessageView{
 		ID:       id,
@@ -507,41 +860,81 @@ func (s *GoogleStore) CreateGmailMessageFromForm(userID, from, subject, body str
 	}, nil
 }
 
-// SendGmailMessage creates a sent message and returns it
+// SendGmailMessage creates a sent message in a new thread and returns it
 func (s *GoogleStore) SendGmailMessage(userID, from, to, subject, body string) (*GmailMessage, error) {
-	id := fmt.Sprintf("msg_%d", time.Now().UnixNano())
-	threadID := fmt.Sprintf("thr_%d", time.Now().UnixNano())
+	return s.createGmailMessage(userID, "", from, to, subject, body, []string{"SENT"})
+}
+
+// SendGmailMessageInThread creates a sent message. When threadID is set the
+// message joins that thread as a reply; otherwise a new thread is started.
+func (s *GoogleStore) SendGmailMessageInThread(userID, threadID, from, to, subject, body string) (*GmailMessage, error) {
+	return s.createGmailMessage(userID, threadID, from, to, subject, body, []string{"SENT"})
+}
+
+// InsertGmailMessage stores a message directly in the mailbox with the given
+// labels, optionally attaching it to an existing thread.
+func (s *GoogleStore) InsertGmailMessage(userID, threadID, from, to, subject, body string, labels []string) (*GmailMessage, error) {
+	if labels == nil {
+		labels = []string{}
+	}
+	return s.createGmailMessage(userID, threadID, from, to, subject, body, labels)
+}
 
+// createGmailMessage stores a message with threading headers. Replies into an
+// existing thread get In-Reply-To and References built from the latest message.
+func (s *GoogleStore) createGmailMessage(userID, threadID, from, to, subject, body string, labels []string) (*GmailMessage, error) {
+	id := fmt.Sprintf("msg_%d", time.Now().UnixNano())
 	snippet := truncate(body, 100)
 
-	// Create thread first
-	s.db.Exec("INSERT INTO gmail_threads (id, user_id, snippet) VALUES (?, ?, ?)",
-		threadID, userID, snippet)
+	headers := []map[string]string{
+		{"name": "From", "value": from},
+		{"name": "To", "value": to},
+		{"name": "Subject", "value": subject},
+		{"name": "Message-ID", "value": gmailMessageIDHeader(id)},
+	}
+
+	if threadID != "" {
+		parentID, references, err := s.latestThreadHeaders(userID, threadID)
+		if err != nil {
+			return nil, err
+		}
+		if references != "" {
+			references += " " + parentID
+		} else {
+			references = parentID
+		}
+		headers = append(headers,
+			map[string]string{"name": "In-Reply-To", "value": parentID},
+			map[string]string{"name": "References", "value": references},
+		)
+	} else {
+		threadID = fmt.Sprintf("thr_%d", time.Now().UnixNano())
+		// Create thread first
+		s.db.Exec("INSERT INTO gmail_threads (id, user_id, snippet) VALUES (?, ?, ?)",
+			threadID, userID, snippet)
+	}
 
 	// Build payload with all headers
 	payloadData := map[string]any{
-		"headers": []map[string]string{
-			{"name": "From", "value": from},
-			{"name": "To", "value": to},
-			{"name": "Subject", "value": subject},
-		},
+		"headers": headers,
 		"body": map[string]string{
 			"data": base64.URLEncoding.EncodeToString([]byte(body)),
 		},
 	}
 	payloadBytes, _ := json.Marshal(payloadData)
-
-	// Messages that are sent get the SENT label
-	labels := []string{"SENT"}
 	labelJSON, _ := json.Marshal(labels)
+	internalDate := s.now().UnixMilli()
 
 	_, err := s.db.Exec(
 		"INSERT INTO gmail_messages (id, user_id, thread_id, label_ids, snippet, internal_date, payload) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		id, userID, threadID, string(labelJSON), snippet, time.Now().UnixMilli(), string(payloadBytes),
+		id, userID, threadID, string(labelJSON), snippet, internalDate, string(payloadBytes),
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.recordGmailHistory(userID, GmailHistoryMessageAdded, id, threadID, labels, nil); err != nil {
+		return nil, err
+	}
 
 	return &GmailMessage{
 		ID:           id,
@@ -549,11 +942,56 @@ func (s *GoogleStore) SendGmailMessage(userID, from, to, subject, body string) (
 		ThreadID:     threadID,
 		LabelIDs:     labels,
 		Snippet:      snippet,
-		InternalDate: time.Now().UnixMilli(),
+		InternalDate: internalDate,
 		Payload:      string(payloadBytes),
 	}, nil
 }
 
+// ErrThreadNotFound is returned when replying into a thread the user doesn't have
+var ErrThreadNotFound = errors.New("thread not found")
+
+// gmailMessageIDHeader returns the RFC 2822 Message-ID used for a stored message
+func gmailMessageIDHeader(messageID string) string {
+	return "<" + messageID + "@ish.local>"
+}
+
+// latestThreadHeaders returns the Message-ID and References headers of the
+// newest message in a thread owned by userID. Messages stored without a
+// Message-ID header (e.g. seed data) get one derived from their ID.
+func (s *GoogleStore) latestThreadHeaders(userID, threadID string) (string, string, error) {
+	var messageID, payload string
+	err := s.db.QueryRow(
+		"SELECT id, payload FROM gmail_messages WHERE user_id = ? AND thread_id = ? ORDER BY internal_date DESC, id DESC LIMIT 1",
+		userID, threadID,
+	).Scan(&messageID, &payload)
+	if err == sql.ErrNoRows {
+		return "", "", ErrThreadNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	var p struct {
+		Headers []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+	}
+	json.Unmarshal([]byte(payload), &p)
+
+	parentID := gmailMessageIDHeader(messageID)
+	var references string
+	for _, h := range p.Headers {
+		switch strings.ToLower(h.Name) {
+		case "message-id":
+			parentID = h.Value
+		case "references":
+			references = h.Value
+		}
+	}
+	return parentID, references, nil
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -563,10 +1001,9 @@ func truncate(s string, max int) string {
 
 // GetGmailProfile returns the user's Gmail profile with current historyId.
 func (s *GoogleStore) GetGmailProfile(userID string) (*GmailProfile, error) {
-	var historyID int64
-	err := s.db.QueryRow("SELECT COALESCE(history_id, 1) FROM users WHERE id = ?", userID).Scan(&historyID)
+	historyID, err := s.currentGmailHistoryID(userID)
 	if err != nil {
-		historyID = 1
+		return nil, err
 	}
 
 	var msgCount, threadCount int
@@ -581,71 +1018,138 @@ func (s *GoogleStore) GetGmailProfile(userID string) (*GmailProfile, error) {
 	}, nil
 }
 
-// ListGmailHistory returns messages added since the given historyId.
-func (s *GoogleStore) ListGmailHistory(userID string, startHistoryID int64, maxResults int, pageToken string) ([]GmailHistoryEntry, int64, string, error) {
-	offset := 0
-	if pageToken != "" {
-		decoded, err := base64.StdEncoding.DecodeString(pageToken)
-		if err == nil {
-			offset, _ = strconv.Atoi(string(decoded))
-		}
+// ListGmailHistory returns changes recorded after startHistoryID, oldest
+// first, limited to the given types when any are named. It also returns the
+// mailbox's current historyId.
+func (s *GoogleStore) ListGmailHistory(userID string, startHistoryID int64, historyTypes []string, maxResults int, pageToken string) ([]GmailHistoryEntry, int64, string, error) {
+	maxResults = clampPageSize(maxResults)
+	page, err := s.openPage("gmail_history", pageToken, map[string]string{
+		"userId":         userID,
+		"startHistoryId": strconv.FormatInt(startHistoryID, 10),
+		"historyTypes":   strings.Join(historyTypes, ","),
+	})
+	if err != nil {
+		return nil, 0, "", err
 	}
 
-	// Get current history ID
-	var currentHistoryID int64
-	s.db.QueryRow("SELECT COALESCE(history_id, 1) FROM users WHERE id = ?", userID).Scan(&currentHistoryID)
-
-	// Get messages with history_id >= startHistoryID
-	sqlQuery := `SELECT id, user_id, thread_id, label_ids, snippet, internal_date, payload, COALESCE(history_id, 0)
-				 FROM gmail_messages WHERE user_id = ? AND COALESCE(history_id, 0) >= ?
-				 ORDER BY history_id ASC LIMIT ? OFFSET ?`
-
-	rows, err := s.db.Query(sqlQuery, userID, startHistoryID, maxResults+1, offset)
+	currentHistoryID, err := s.currentGmailHistoryID(userID)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	sqlQuery := `SELECT id, type, message_id, COALESCE(thread_id, ''), COALESCE(label_ids, '[]'), COALESCE(changed_label_ids, '[]')
+				 FROM gmail_history WHERE user_id = ? AND id > ? AND rowid <= ?`
+	args := []any{userID, startHistoryID, page.AsOf}
+	if len(historyTypes) > 0 {
+		sqlQuery += " AND type IN (?" + strings.Repeat(", ?", len(historyTypes)-1) + ")"
+		for _, t := range historyTypes {
+			args = append(args, t)
+		}
+	}
+	sqlQuery += " ORDER BY id ASC LIMIT ? OFFSET ?"
+	args = append(args, maxResults+1, page.Offset)
+
+	rows, err := s.db.Query(sqlQuery, args...)
 	if err != nil {
 		return nil, 0, "", err
 	}
 	defer rows.Close()
 
-	var messages []GmailMessage
+	var entries []GmailHistoryEntry
 	for rows.Next() {
-		var m GmailMessage
-		var labelJSON string
-		var historyID int64
-		err := rows.Scan(&m.ID, &m.UserID, &m.ThreadID, &labelJSON, &m.Snippet, &m.InternalDate, &m.Payload, &historyID)
-		if err != nil {
+		var e GmailHistoryEntry
+		var labelJSON, changedJSON string
+		if err := rows.Scan(&e.ID, &e.Type, &e.MessageID, &e.ThreadID, &labelJSON, &changedJSON); err != nil {
 			return nil, 0, "", err
 		}
-		json.Unmarshal([]byte(labelJSON), &m.LabelIDs)
-		messages = append(messages, m)
+		json.Unmarshal([]byte(labelJSON), &e.LabelIDs)
+		json.Unmarshal([]byte(changedJSON), &e.ChangedLabelIDs)
+		entries = append(entries, e)
 	}
-
-	var nextToken string
-	if len(messages) > maxResults {
-		messages = messages[:maxResults]
-		nextToken = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset + maxResults)))
+	if err := rows.Err(); err != nil {
+		return nil, 0, "", err
 	}
 
-	// Group into history entries (one entry per message for simplicity)
-	var entries []GmailHistoryEntry
-	for i, msg := range messages {
-		entries = append(entries, GmailHistoryEntry{
-			ID:            startHistoryID + int64(i),
-			MessagesAdded: []GmailMessage{msg},
-		})
+	var nextToken string
+	if len(entries) > maxResults {
+		entries = entries[:maxResults]
+		nextToken = page.next(maxResults)
 	}
 
 	return entries, currentHistoryID, nextToken, nil
 }
 
-// IncrementHistoryID increments the user's history ID and returns the new value.
-func (s *GoogleStore) IncrementHistoryID(userID string) (int64, error) {
-	_, err := s.db.Exec("UPDATE users SET history_id = COALESCE(history_id, 0) + 1 WHERE id = ?", userID)
+// currentGmailHistoryID returns the ID of the user's latest history record, or 0
+// for an untouched mailbox so that syncing from it picks up the first change
+func (s *GoogleStore) currentGmailHistoryID(userID string) (int64, error) {
+	var historyID int64
+	err := s.db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM gmail_history WHERE user_id = ?", userID).Scan(&historyID)
+	return historyID, err
+}
+
+// recordGmailHistory appends a history record and stamps the message with its historyId
+func (s *GoogleStore) recordGmailHistory(userID, historyType, messageID, threadID string, labelIDs, changedLabelIDs []string) error {
+	if labelIDs == nil {
+		labelIDs = []string{}
+	}
+	if changedLabelIDs == nil {
+		changedLabelIDs = []string{}
+	}
+	labelJSON, _ := json.Marshal(labelIDs)
+	changedJSON, _ := json.Marshal(changedLabelIDs)
+
+	result, err := s.db.Exec(
+		"INSERT INTO gmail_history (user_id, type, message_id, thread_id, label_ids, changed_label_ids) VALUES (?, ?, ?, ?, ?, ?)",
+		userID, historyType, messageID, threadID, string(labelJSON), string(changedJSON),
+	)
 	if err != nil {
-		return 0, err
+		return err
+	}
+	historyID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("UPDATE gmail_messages SET history_id = ? WHERE id = ?", historyID, messageID); err != nil {
+		return err
+	}
+
+	action := core.AuditUpdate
+	switch historyType {
+	case GmailHistoryMessageAdded:
+		action = core.AuditCreate
+	case GmailHistoryMessageDeleted:
+		action = core.AuditDelete
+	}
+	return s.audit("gmail_message", messageID, action, userID, map[string]any{
+		"history_type":      historyType,
+		"thread_id":         threadID,
+		"label_ids":         labelIDs,
+		"changed_label_ids": changedLabelIDs,
+	})
+}
+
+// recordLabelChanges records labelAdded and labelRemoved entries for the difference between two label sets
+func (s *GoogleStore) recordLabelChanges(userID, messageID, threadID string, before, after []string) error {
+	var added, removed []string
+	for _, label := range after {
+		if !slices.Contains(before, label) {
+			added = append(added, label)
+		}
+	}
+	for _, label := range before {
+		if !slices.Contains(after, label) {
+			removed = append(removed, label)
+		}
+	}
+	if len(added) > 0 {
+		if err := s.recordGmailHistory(userID, GmailHistoryLabelAdded, messageID, threadID, after, added); err != nil {
+			return err
+		}
 	}
-	var newID int64
-	err = s.db.QueryRow("SELECT history_id FROM users WHERE id = ?", userID).Scan(&newID)
-	return newID, err
+	if len(removed) > 0 {
+		return s.recordGmailHistory(userID, GmailHistoryLabelRemoved, messageID, threadID, after, removed)
+	}
+	return nil
 }
 
 // GetGmailAttachment retrieves an attachment by message and attachment ID.
@@ -693,31 +1197,127 @@ type CalendarEvent struct {
 	OrganizerEmail string
 	OrganizerName  string
 	Recurrence     string
+	ColorID        string
 	UpdatedAt      string
 }
 
-func (s *GoogleStore) CreateCalendar(c *Calendar) error {
-	_, err := s.db.Exec(
-		"INSERT INTO calendars (id, user_id, summary) VALUES (?, ?, ?)",
-		c.ID, c.UserID, c.Summary,
+// CalendarChannel is a push notification channel watching a calendar's
+// events. Expiration is in milliseconds since the epoch, as the API reports it.
+type CalendarChannel struct {
+	ID            string
+	ResourceID    string
+	CalendarID    string
+	Address       string
+	Token         string
+	Expiration    int64
+	MessageNumber int64
+}
+
+// CreateCalendarChannel stores a new watch channel opened by actor
+func (s *GoogleStore) CreateCalendarChannel(c *CalendarChannel, actor string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO calendar_channels (id, resource_id, calendar_id, address, token, expiration) VALUES (?, ?, ?, ?, ?, ?)`,
+			c.ID, c.ResourceID, c.CalendarID, c.Address, c.Token, c.Expiration,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "calendar_channel", c.ID, core.AuditCreate, actor, map[string]any{
+			"calendar_id": c.CalendarID,
+			"address":     c.Address,
+			"expiration":  c.Expiration,
+		})
+	})
+}
+
+// ListCalendarChannels returns the channels watching a calendar that haven't expired by now
+func (s *GoogleStore) ListCalendarChannels(calendarID string, now time.Time) ([]CalendarChannel, error) {
+	rows, err := s.db.Query(
+		`SELECT id, resource_id, calendar_id, address, COALESCE(token, ''), expiration, message_number
+		 FROM calendar_channels WHERE calendar_id = ? AND expiration > ? ORDER BY created_at, id`,
+		calendarID, now.UnixMilli(),
 	)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []CalendarChannel
+	for rows.Next() {
+		var c CalendarChannel
+		if err := rows.Scan(&c.ID, &c.ResourceID, &c.CalendarID, &c.Address, &c.Token, &c.Expiration, &c.MessageNumber); err != nil {
+			return nil, err
+		}
+		channels = append(channels, c)
+	}
+	return channels, rows.Err()
+}
+
+// NextCalendarChannelMessage numbers the next notification sent on a channel
+func (s *GoogleStore) NextCalendarChannelMessage(channelID string) (int64, error) {
+	var n int64
+	err := s.db.QueryRow(
+		`UPDATE calendar_channels SET message_number = message_number + 1 WHERE id = ? RETURNING message_number`,
+		channelID,
+	).Scan(&n)
+	return n, err
+}
+
+// StopCalendarChannel deletes a channel for actor, reporting whether one
+// matched both IDs
+func (s *GoogleStore) StopCalendarChannel(channelID, resourceID, actor string) (bool, error) {
+	var stopped bool
+	err := s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`DELETE FROM calendar_channels WHERE id = ? AND resource_id = ?`, channelID, resourceID)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil || n == 0 {
+			return err
+		}
+		stopped = true
+		return auditIn(tx, "calendar_channel", channelID, core.AuditDelete, actor, map[string]any{"resource_id": resourceID})
+	})
+	return stopped, err
 }
 
-func (s *GoogleStore) CreateCalendarEvent(e *CalendarEvent) (*CalendarEvent, error) {
+func (s *GoogleStore) CreateCalendar(c *Calendar) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			"INSERT INTO calendars (id, user_id, summary) VALUES (?, ?, ?)",
+			c.ID, c.UserID, c.Summary,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "calendar", c.ID, core.AuditCreate, c.UserID, map[string]any{"summary": c.Summary})
+	})
+}
+
+// CreateCalendarEvent inserts an event on behalf of actor, the user making
+// the change
+func (s *GoogleStore) CreateCalendarEvent(e *CalendarEvent, actor string) (*CalendarEvent, error) {
 	// Generate ID if not provided
 	if e.ID == "" {
 		e.ID = fmt.Sprintf("evt_%d", time.Now().UnixNano())
 	}
 
 	// Set updated_at timestamp
-	e.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-
-	_, err := s.db.Exec(
-		`INSERT INTO calendar_events (id, calendar_id, summary, description, start_time, end_time, attendees, location, recurrence, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		e.ID, e.CalendarID, e.Summary, e.Description, e.StartTime, e.EndTime, e.Attendees, e.Location, e.Recurrence, e.UpdatedAt,
-	)
+	e.UpdatedAt = s.now().UTC().Format(time.RFC3339)
+
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO calendar_events (id, calendar_id, summary, description, start_time, end_time, attendees, location, recurrence, color_id, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.ID, e.CalendarID, e.Summary, e.Description, e.StartTime, e.EndTime, e.Attendees, e.Location, e.Recurrence, e.ColorID, e.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "calendar_event", e.ID, core.AuditCreate, actor, calendarEventChanges(e))
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -725,18 +1325,16 @@ func (s *GoogleStore) CreateCalendarEvent(e *CalendarEvent) (*CalendarEvent, err
 }
 
 func (s *GoogleStore) ListCalendarEvents(calendarID string, maxResults int, pageToken string, timeMin string, timeMax string) ([]CalendarEvent, string, error) {
-	offset := 0
-	if pageToken != "" {
-		decoded, err := base64.StdEncoding.DecodeString(pageToken)
-		if err == nil {
-			offset, _ = strconv.Atoi(string(decoded))
-		}
+	maxResults = clampPageSize(maxResults)
+	page, err := s.openPage("calendar_events", pageToken, map[string]string{"calendarId": calendarID, "timeMin": timeMin, "timeMax": timeMax})
+	if err != nil {
+		return nil, "", err
 	}
 
 	sqlQuery := `SELECT id, calendar_id, summary, description, start_time, end_time, attendees,
 		COALESCE(location, ''), COALESCE(organizer_email, ''), COALESCE(organizer_name, ''),
-		COALESCE(recurrence, ''), COALESCE(updated_at, '') FROM calendar_events WHERE calendar_id = ?`
-	args := []any{calendarID}
+		COALESCE(recurrence, ''), COALESCE(color_id, ''), COALESCE(updated_at, '') FROM calendar_events WHERE calendar_id = ? AND rowid <= ?`
+	args := []any{calendarID, page.AsOf}
 
 	if timeMin != "" {
 		sqlQuery += " AND start_time >= ?"
@@ -748,7 +1346,7 @@ func (s *GoogleStore) ListCalendarEvents(calendarID string, maxResults int, page
 	}
 
 	sqlQuery += " ORDER BY start_time ASC LIMIT ? OFFSET ?"
-	args = append(args, maxResults+1, offset)
+	args = append(args, maxResults+1, page.Offset)
 
 	rows, err := s.db.Query(sqlQuery, args...)
 	if err != nil {
@@ -760,7 +1358,7 @@ func (s *GoogleStore) ListCalendarEvents(calendarID string, maxResults int, page
 	for rows.Next() {
 		var e CalendarEvent
 		err := rows.Scan(&e.ID, &e.CalendarID, &e.Summary, &e.Description, &e.StartTime, &e.EndTime, &e.Attendees,
-			&e.Location, &e.OrganizerEmail, &e.OrganizerName, &e.Recurrence, &e.UpdatedAt)
+			&e.Location, &e.OrganizerEmail, &e.OrganizerName, &e.Recurrence, &e.ColorID, &e.UpdatedAt)
 		if err != nil {
 			return nil, "", err
 		}
@@ -770,7 +1368,7 @@ func (s *GoogleStore) ListCalendarEvents(calendarID string, maxResults int, page
 	var nextToken string
 	if len(events) > maxResults {
 		events = events[:maxResults]
-		nextToken = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset + maxResults)))
+		nextToken = page.next(maxResults)
 	}
 
 	return events, nextToken, nil
@@ -781,10 +1379,10 @@ func (s *GoogleStore) GetCalendarEvent(calendarID, eventID string) (*CalendarEve
 	err := s.db.QueryRow(
 		`SELECT id, calendar_id, summary, description, start_time, end_time, attendees,
 		COALESCE(location, ''), COALESCE(organizer_email, ''), COALESCE(organizer_name, ''),
-		COALESCE(recurrence, ''), COALESCE(updated_at, '') FROM calendar_events WHERE calendar_id = ? AND id = ?`,
+		COALESCE(recurrence, ''), COALESCE(color_id, ''), COALESCE(updated_at, '') FROM calendar_events WHERE calendar_id = ? AND id = ?`,
 		calendarID, eventID,
 	).Scan(&e.ID, &e.CalendarID, &e.Summary, &e.Description, &e.StartTime, &e.EndTime, &e.Attendees,
-		&e.Location, &e.OrganizerEmail, &e.OrganizerName, &e.Recurrence, &e.UpdatedAt)
+		&e.Location, &e.OrganizerEmail, &e.OrganizerName, &e.Recurrence, &e.ColorID, &e.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("event not found")
 	}
@@ -794,10 +1392,23 @@ func (s *GoogleStore) GetCalendarEvent(calendarID, eventID string) (*CalendarEve
 	return &e, nil
 }
 
+// GetCalendarEventByID gets an event without knowing which calendar holds it
+func (s *GoogleStore) GetCalendarEventByID(eventID string) (*CalendarEvent, error) {
+	var calendarID string
+	err := s.db.QueryRow("SELECT calendar_id FROM calendar_events WHERE id = ?", eventID).Scan(&calendarID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("event not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCalendarEvent(calendarID, eventID)
+}
+
 func (s *GoogleStore) ListAllCalendarEvents() ([]CalendarEvent, error) {
 	rows, err := s.db.Query(`SELECT id, calendar_id, summary, description, start_time, end_time, attendees,
 		COALESCE(location, ''), COALESCE(organizer_email, ''), COALESCE(organizer_name, ''),
-		COALESCE(recurrence, ''), COALESCE(updated_at, '') FROM calendar_events ORDER BY start_time`)
+		COALESCE(recurrence, ''), COALESCE(color_id, ''), COALESCE(updated_at, '') FROM calendar_events ORDER BY start_time`)
 	if err != nil {
 		return nil, err
 	}
@@ -807,7 +1418,7 @@ func (s *GoogleStore) ListAllCalendarEvents() ([]CalendarEvent, error) {
 	for rows.Next() {
 		var e CalendarEvent
 		if err := rows.Scan(&e.ID, &e.CalendarID, &e.Summary, &e.Description, &e.StartTime, &e.EndTime, &e.Attendees,
-			&e.Location, &e.OrganizerEmail, &e.OrganizerName, &e.Recurrence, &e.UpdatedAt); err != nil {
+			&e.Location, &e.OrganizerEmail, &e.OrganizerName, &e.Recurrence, &e.ColorID, &e.UpdatedAt); err != nil {
 			return nil, err
 		}
 		events = append(events, e)
@@ -822,15 +1433,7 @@ func (s *GoogleStore) CreateCalendarEventFromForm(summary, description, start, e
 	startTime := start + ":00Z"
 	endTime := end + ":00Z"
 
-	_, err := s.db.Exec(
-		"INSERT INTO calendar_events (id, calendar_id, summary, description, start_time, end_time, attendees) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		id, "primary", summary, description, startTime, endTime, "[]",
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return &CalendarEvent{
+	e := &CalendarEvent{
 		ID:          id,
 		CalendarID:  "primary",
 		Summary:     summary,
@@ -838,31 +1441,65 @@ func (s *GoogleStore) CreateCalendarEventFromForm(summary, description, start, e
 		StartTime:   startTime,
 		EndTime:     endTime,
 		Attendees:   "[]",
-	}, nil
+	}
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			"INSERT INTO calendar_events (id, calendar_id, summary, description, start_time, end_time, attendees) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			id, "primary", summary, description, startTime, endTime, "[]",
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "calendar_event", id, core.AuditCreate, "", calendarEventChanges(e))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
 }
 
-// UpdateCalendarEvent updates an existing calendar event
-func (s *GoogleStore) UpdateCalendarEvent(e *CalendarEvent) (*CalendarEvent, error) {
+// UpdateCalendarEvent updates an existing calendar event on behalf of actor
+func (s *GoogleStore) UpdateCalendarEvent(e *CalendarEvent, actor string) (*CalendarEvent, error) {
 	// Update timestamp
-	e.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-
-	_, err := s.db.Exec(
-		`UPDATE calendar_events SET summary = ?, description = ?, start_time = ?, end_time = ?,
-		 attendees = ?, location = ?, recurrence = ?, updated_at = ?
-		 WHERE calendar_id = ? AND id = ?`,
-		e.Summary, e.Description, e.StartTime, e.EndTime, e.Attendees, e.Location, e.Recurrence, e.UpdatedAt,
-		e.CalendarID, e.ID,
-	)
+	e.UpdatedAt = s.now().UTC().Format(time.RFC3339)
+
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`UPDATE calendar_events SET summary = ?, description = ?, start_time = ?, end_time = ?,
+			 attendees = ?, location = ?, recurrence = ?, color_id = ?, updated_at = ?
+			 WHERE calendar_id = ? AND id = ?`,
+			e.Summary, e.Description, e.StartTime, e.EndTime, e.Attendees, e.Location, e.Recurrence, e.ColorID, e.UpdatedAt,
+			e.CalendarID, e.ID,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "calendar_event", e.ID, core.AuditUpdate, actor, calendarEventChanges(e))
+	})
 	if err != nil {
 		return nil, err
 	}
 	return e, nil
 }
 
-// DeleteCalendarEvent deletes a calendar event
-func (s *GoogleStore) DeleteCalendarEvent(calendarID, eventID string) error {
-	_, err := s.db.Exec("DELETE FROM calendar_events WHERE calendar_id = ? AND id = ?", calendarID, eventID)
-	return err
+// calendarEventChanges summarizes an event for the audit log
+func calendarEventChanges(e *CalendarEvent) map[string]any {
+	return map[string]any{
+		"calendar_id": e.CalendarID,
+		"summary":     e.Summary,
+		"start_time":  e.StartTime,
+		"end_time":    e.EndTime,
+	}
+}
+
+// DeleteCalendarEvent deletes a calendar event on behalf of actor
+func (s *GoogleStore) DeleteCalendarEvent(calendarID, eventID, actor string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM calendar_events WHERE calendar_id = ? AND id = ?", calendarID, eventID); err != nil {
+			return err
+		}
+		return auditIn(tx, "calendar_event", eventID, core.AuditDelete, actor, map[string]any{"calendar_id": calendarID})
+	})
 }
 
 // GetCalendarSyncToken returns the current sync token for a calendar.
@@ -888,6 +1525,8 @@ func (s *GoogleStore) UpdateCalendarSyncToken(calendarID string) (string, error)
 
 // ListCalendarEventsSince returns events updated since the given sync token.
 func (s *GoogleStore) ListCalendarEventsSince(calendarID string, syncToken string, maxResults int) ([]CalendarEvent, string, error) {
+	maxResults = clampPageSize(maxResults)
+
 	// Decode the sync token to get a timestamp
 	var sinceTime int64
 	if syncToken != "" {
@@ -903,7 +1542,7 @@ func (s *GoogleStore) ListCalendarEventsSince(calendarID string, syncToken strin
 	// Use COALESCE in WHERE clause to handle NULL updated_at values (treat them as epoch 0)
 	sqlQuery := `SELECT id, calendar_id, summary, description, start_time, end_time, attendees,
 		COALESCE(location, ''), COALESCE(organizer_email, ''), COALESCE(organizer_name, ''),
-		COALESCE(recurrence, ''), COALESCE(updated_at, '') FROM calendar_events
+		COALESCE(recurrence, ''), COALESCE(color_id, ''), COALESCE(updated_at, '') FROM calendar_events
 		WHERE calendar_id = ? AND COALESCE(updated_at, '1970-01-01T00:00:00Z') > ? ORDER BY updated_at ASC LIMIT ?`
 
 	rows, err := s.db.Query(sqlQuery, calendarID, sinceTimestamp, maxResults)
@@ -916,7 +1555,7 @@ func (s *GoogleStore) ListCalendarEventsSince(calendarID string, syncToken strin
 	for rows.Next() {
 		var e CalendarEvent
 		if err := rows.Scan(&e.ID, &e.CalendarID, &e.Summary, &e.Description, &e.StartTime, &e.EndTime, &e.Attendees,
-			&e.Location, &e.OrganizerEmail, &e.OrganizerName, &e.Recurrence, &e.UpdatedAt); err != nil {
+			&e.Location, &e.OrganizerEmail, &e.OrganizerName, &e.Recurrence, &e.ColorID, &e.UpdatedAt); err != nil {
 			return nil, "", err
 		}
 		events = append(events, e)
@@ -935,6 +1574,15 @@ type Person struct {
 	Data         string
 }
 
+// PersonPhoto is a contact's profile photo
+type PersonPhoto struct {
+	ID           string
+	UserID       string
+	ResourceName string
+	ContentType  string
+	Data         []byte
+}
+
 type PersonView struct {
 	ID           string
 	ResourceName string
@@ -947,20 +1595,25 @@ func (s *GoogleStore) CreatePerson(p *Person) error {
 		"INSERT INTO people (resource_name, user_id, data) VALUES (?, ?, ?)",
 		p.ResourceName, p.UserID, p.Data,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	var changes any
+	if json.Valid([]byte(p.Data)) {
+		changes = json.RawMessage(p.Data)
+	}
+	return s.audit("person", p.ResourceName, core.AuditCreate, p.UserID, changes)
 }
 
 func (s *GoogleStore) SearchPeople(userID string, query string, pageSize int, pageToken string) ([]Person, string, error) {
-	offset := 0
-	if pageToken != "" {
-		decoded, err := base64.StdEncoding.DecodeString(pageToken)
-		if err == nil {
-			offset, _ = strconv.Atoi(string(decoded))
-		}
+	pageSize = clampPageSize(pageSize)
+	page, err := s.openPage("people", pageToken, map[string]string{"userId": userID, "query": query})
+	if err != nil {
+		return nil, "", err
 	}
 
-	sqlQuery := "SELECT resource_name, user_id, data FROM people WHERE user_id = ?"
-	args := []any{userID}
+	sqlQuery := "SELECT resource_name, user_id, data FROM people WHERE user_id = ? AND rowid <= ?"
+	args := []any{userID, page.AsOf}
 
 	if query != "" {
 		sqlQuery += " AND data LIKE ? ESCAPE '\\'"
@@ -971,7 +1624,7 @@ func (s *GoogleStore) SearchPeople(userID string, query string, pageSize int, pa
 	}
 
 	sqlQuery += " ORDER BY resource_name ASC LIMIT ? OFFSET ?"
-	args = append(args, pageSize+1, offset)
+	args = append(args, pageSize+1, page.Offset)
 
 	rows, err := s.db.Query(sqlQuery, args...)
 	if err != nil {
@@ -992,7 +1645,7 @@ func (s *GoogleStore) SearchPeople(userID string, query string, pageSize int, pa
 	var nextToken string
 	if len(people) > pageSize {
 		people = people[:pageSize]
-		nextToken = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset + pageSize)))
+		nextToken = page.next(pageSize)
 	}
 
 	return people, nextToken, nil
@@ -1096,6 +1749,9 @@ func (s *GoogleStore) CreatePersonFromForm(userID, name, email string) (*PersonV
 	if err != nil {
 		return nil, err
 	}
+	if err := s.audit("person", resourceName, core.AuditCreate, userID, personData); err != nil {
+		return nil, err
+	}
 
 	return &PersonView{
 		ID:           id,
@@ -1117,7 +1773,46 @@ func (s *GoogleStore) DeletePerson(userID, resourceName string) error {
 	if rowsAffected == 0 {
 		return fmt.Errorf("person not found")
 	}
-	return nil
+	if _, err := s.db.Exec("DELETE FROM people_photos WHERE resource_name = ? AND user_id = ?", resourceName, userID); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("DELETE FROM people_contact_group_members WHERE person_resource_name = ? AND user_id = ?", resourceName, userID); err != nil {
+		return err
+	}
+	return s.audit("person", resourceName, core.AuditDelete, userID, nil)
+}
+
+// GetPersonByResourceName returns a person regardless of owner, for admin edits
+func (s *GoogleStore) GetPersonByResourceName(resourceName string) (*Person, error) {
+	var p Person
+	err := s.db.QueryRow(
+		"SELECT resource_name, user_id, data FROM people WHERE resource_name = ?",
+		resourceName,
+	).Scan(&p.ResourceName, &p.UserID, &p.Data)
+	if err == sql.ErrNoRows {
+		return nil, core.ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ReplacePersonData overwrites a person's stored data, unlike UpdatePerson which merges fields
+func (s *GoogleStore) ReplacePersonData(resourceName string, data map[string]any) error {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.Exec("UPDATE people SET data = ?, updated_at = ? WHERE resource_name = ?",
+		string(dataJSON), s.now().Format(time.RFC3339), resourceName)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return core.ErrDocumentNotFound
+	}
+	return s.audit("person", resourceName, core.AuditUpdate, "", data)
 }
 
 func (s *GoogleStore) UpdatePerson(userID, resourceName string, data map[string]any) (*Person, error) {
@@ -1151,10 +1846,13 @@ func (s *GoogleStore) UpdatePerson(userID, resourceName string, data map[string]
 
 	// Update in database
 	_, err = s.db.Exec("UPDATE people SET data = ?, updated_at = ? WHERE resource_name = ? AND user_id = ?",
-		string(dataJSON), time.Now().Format(time.RFC3339), resourceName, userID)
+		string(dataJSON), s.now().Format(time.RFC3339), resourceName, userID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.audit("person", resourceName, core.AuditUpdate, userID, data); err != nil {
+		return nil, err
+	}
 
 	return &Person{
 		ResourceName: resourceName,
@@ -1163,6 +1861,390 @@ func (s *GoogleStore) UpdatePerson(userID, resourceName string, data map[string]
 	}, nil
 }
 
+// SetPersonPhoto replaces a contact's photo and lists it in the person's
+// photos field, served from baseURL. Each upload gets a new ID, so the URL
+// changes with the photo.
+func (s *GoogleStore) SetPersonPhoto(userID, resourceName, contentType string, data []byte, baseURL string) (*Person, error) {
+	if _, err := s.GetPerson(userID, resourceName); err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprintf("photo_%d", time.Now().UnixNano())
+	photos := []map[string]any{{
+		"metadata": map[string]any{"primary": true, "source": map[string]any{"type": "CONTACT"}},
+		"url":      baseURL + "/people/v1/photos/" + id,
+	}}
+
+	var person *Person
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO people_photos (id, user_id, resource_name, content_type, data) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(user_id, resource_name) DO UPDATE SET id = excluded.id, content_type = excluded.content_type, data = excluded.data, created_at = CURRENT_TIMESTAMP
+		`, id, userID, resourceName, contentType, data)
+		if err != nil {
+			return err
+		}
+		if err := auditIn(tx, "person_photo", id, core.AuditCreate, userID, map[string]any{
+			"resource_name": resourceName,
+			"content_type":  contentType,
+			"size":          len(data),
+		}); err != nil {
+			return err
+		}
+		person, err = s.setPersonPhotos(tx, userID, resourceName, photos)
+		return err
+	})
+	return person, err
+}
+
+// DeletePersonPhoto removes a contact's photo
+func (s *GoogleStore) DeletePersonPhoto(userID, resourceName string) (*Person, error) {
+	if _, err := s.GetPerson(userID, resourceName); err != nil {
+		return nil, err
+	}
+
+	var person *Person
+	err := s.withTx(func(tx *sql.Tx) error {
+		var id string
+		err := tx.QueryRow("DELETE FROM people_photos WHERE resource_name = ? AND user_id = ? RETURNING id", resourceName, userID).Scan(&id)
+		if err == nil {
+			err = auditIn(tx, "person_photo", id, core.AuditDelete, userID, map[string]any{"resource_name": resourceName})
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		person, err = s.setPersonPhotos(tx, userID, resourceName, nil)
+		return err
+	})
+	return person, err
+}
+
+// setPersonPhotos sets the photos field of a person's data in tx, removing it when photos is nil
+func (s *GoogleStore) setPersonPhotos(tx *sql.Tx, userID, resourceName string, photos []map[string]any) (*Person, error) {
+	var person Person
+	err := tx.QueryRow(
+		"SELECT resource_name, user_id, data FROM people WHERE user_id = ? AND resource_name = ?",
+		userID, resourceName,
+	).Scan(&person.ResourceName, &person.UserID, &person.Data)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(person.Data), &data); err != nil || data == nil {
+		data = make(map[string]any)
+	}
+	if photos == nil {
+		delete(data, "photos")
+	} else {
+		data["photos"] = photos
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("UPDATE people SET data = ?, updated_at = ? WHERE resource_name = ? AND user_id = ?",
+		string(dataJSON), s.now().Format(time.RFC3339), resourceName, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auditIn(tx, "person", resourceName, core.AuditUpdate, userID, map[string]any{"photos": photos}); err != nil {
+		return nil, err
+	}
+
+	person.Data = string(dataJSON)
+	return &person, nil
+}
+
+// GetPersonPhoto returns a contact photo by ID
+func (s *GoogleStore) GetPersonPhoto(id string) (*PersonPhoto, error) {
+	var photo PersonPhoto
+	err := s.db.QueryRow(
+		"SELECT id, user_id, resource_name, content_type, data FROM people_photos WHERE id = ?", id,
+	).Scan(&photo.ID, &photo.UserID, &photo.ResourceName, &photo.ContentType, &photo.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+// Contact group types, as the People API reports them in groupType
+const (
+	ContactGroupTypeUser   = "USER_CONTACT_GROUP"
+	ContactGroupTypeSystem = "SYSTEM_CONTACT_GROUP"
+)
+
+// allContactsGroup is the system group every contact belongs to. Its members
+// are derived from the people table rather than stored.
+const allContactsGroup = "contactGroups/all"
+
+// systemContactGroups are created for every user and can't be deleted
+var systemContactGroups = []struct{ resourceName, name string }{
+	{"contactGroups/myContacts", "myContacts"},
+	{"contactGroups/starred", "starred"},
+	{allContactsGroup, "all"},
+}
+
+var (
+	ErrContactGroupNotFound      = errors.New("contact group not found")
+	ErrContactGroupExists        = errors.New("contact group already exists")
+	ErrSystemContactGroup        = errors.New("system contact groups cannot be deleted")
+	ErrContactGroupNotModifiable = errors.New("contact group members cannot be modified")
+)
+
+// ContactGroup is a People API contact group
+type ContactGroup struct {
+	ResourceName string
+	UserID       string
+	Name         string
+	GroupType    string
+	MemberCount  int
+	UpdatedAt    string
+}
+
+// ensureSystemContactGroups creates a user's system contact groups if they don't exist yet
+func (s *GoogleStore) ensureSystemContactGroups(userID string) error {
+	for _, g := range systemContactGroups {
+		_, err := s.db.Exec(
+			"INSERT OR IGNORE INTO people_contact_groups (user_id, resource_name, name, group_type, updated_at) VALUES (?, ?, ?, ?, ?)",
+			userID, g.resourceName, g.name, ContactGroupTypeSystem, s.now().Format(time.RFC3339),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contactGroupMemberCount counts a group's members
+func (s *GoogleStore) contactGroupMemberCount(userID, resourceName string) (int, error) {
+	var count int
+	var err error
+	if resourceName == allContactsGroup {
+		err = s.db.QueryRow("SELECT COUNT(*) FROM people WHERE user_id = ?", userID).Scan(&count)
+	} else {
+		err = s.db.QueryRow(
+			"SELECT COUNT(*) FROM people_contact_group_members WHERE user_id = ? AND group_resource_name = ?",
+			userID, resourceName,
+		).Scan(&count)
+	}
+	return count, err
+}
+
+// ListContactGroups lists a user's contact groups, system groups first
+func (s *GoogleStore) ListContactGroups(userID string, pageSize int, pageToken string) ([]ContactGroup, string, error) {
+	if err := s.ensureSystemContactGroups(userID); err != nil {
+		return nil, "", err
+	}
+	pageSize = clampPageSize(pageSize)
+	page, err := s.openPage("people_contact_groups", pageToken, map[string]string{"userId": userID})
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT resource_name, user_id, name, group_type, COALESCE(updated_at, '') FROM people_contact_groups
+		WHERE user_id = ? AND rowid <= ? ORDER BY group_type = ? DESC, name ASC LIMIT ? OFFSET ?`,
+		userID, page.AsOf, ContactGroupTypeSystem, pageSize+1, page.Offset,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	var groups []ContactGroup
+	for rows.Next() {
+		var g ContactGroup
+		if err := rows.Scan(&g.ResourceName, &g.UserID, &g.Name, &g.GroupType, &g.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, "", err
+		}
+		groups = append(groups, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var nextToken string
+	if len(groups) > pageSize {
+		groups = groups[:pageSize]
+		nextToken = page.next(pageSize)
+	}
+	for i := range groups {
+		if groups[i].MemberCount, err = s.contactGroupMemberCount(userID, groups[i].ResourceName); err != nil {
+			return nil, "", err
+		}
+	}
+	return groups, nextToken, nil
+}
+
+// GetContactGroup returns one of a user's contact groups
+func (s *GoogleStore) GetContactGroup(userID, resourceName string) (*ContactGroup, error) {
+	if err := s.ensureSystemContactGroups(userID); err != nil {
+		return nil, err
+	}
+	var g ContactGroup
+	err := s.db.QueryRow(
+		"SELECT resource_name, user_id, name, group_type, COALESCE(updated_at, '') FROM people_contact_groups WHERE user_id = ? AND resource_name = ?",
+		userID, resourceName,
+	).Scan(&g.ResourceName, &g.UserID, &g.Name, &g.GroupType, &g.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrContactGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if g.MemberCount, err = s.contactGroupMemberCount(userID, resourceName); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// CreateContactGroup creates a user contact group. Names are unique per user.
+func (s *GoogleStore) CreateContactGroup(userID, name string) (*ContactGroup, error) {
+	if err := s.ensureSystemContactGroups(userID); err != nil {
+		return nil, err
+	}
+	var exists int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM people_contact_groups WHERE user_id = ? AND name = ?", userID, name).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists > 0 {
+		return nil, ErrContactGroupExists
+	}
+
+	g := &ContactGroup{
+		ResourceName: fmt.Sprintf("contactGroups/%x", time.Now().UnixNano()),
+		UserID:       userID,
+		Name:         name,
+		GroupType:    ContactGroupTypeUser,
+		UpdatedAt:    s.now().Format(time.RFC3339),
+	}
+	_, err = s.db.Exec(
+		"INSERT INTO people_contact_groups (user_id, resource_name, name, group_type, updated_at) VALUES (?, ?, ?, ?, ?)",
+		g.UserID, g.ResourceName, g.Name, g.GroupType, g.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit("contact_group", g.ResourceName, core.AuditCreate, userID, map[string]any{"name": name}); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// DeleteContactGroup deletes a user contact group, and its member contacts too
+// when deleteContacts is set
+func (s *GoogleStore) DeleteContactGroup(userID, resourceName string, deleteContacts bool) error {
+	g, err := s.GetContactGroup(userID, resourceName)
+	if err != nil {
+		return err
+	}
+	if g.GroupType == ContactGroupTypeSystem {
+		return ErrSystemContactGroup
+	}
+
+	if deleteContacts {
+		members, err := s.ListContactGroupMembers(userID, resourceName, 0)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			if err := s.DeletePerson(userID, member); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := s.db.Exec("DELETE FROM people_contact_group_members WHERE user_id = ? AND group_resource_name = ?", userID, resourceName); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("DELETE FROM people_contact_groups WHERE user_id = ? AND resource_name = ?", userID, resourceName); err != nil {
+		return err
+	}
+	return s.audit("contact_group", resourceName, core.AuditDelete, userID, nil)
+}
+
+// ListContactGroupMembers returns the resource names of a group's members,
+// at most limit of them when limit is positive
+func (s *GoogleStore) ListContactGroupMembers(userID, resourceName string, limit int) ([]string, error) {
+	query := "SELECT person_resource_name FROM people_contact_group_members WHERE user_id = ? AND group_resource_name = ? ORDER BY person_resource_name"
+	args := []any{userID, resourceName}
+	if resourceName == allContactsGroup {
+		query = "SELECT resource_name FROM people WHERE user_id = ? ORDER BY resource_name"
+		args = []any{userID}
+	}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var members []string
+	for rows.Next() {
+		var member string
+		if err := rows.Scan(&member); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// ModifyContactGroupMembers adds and removes contacts from a group. It
+// returns the resource names that aren't one of the user's contacts; those
+// are skipped.
+func (s *GoogleStore) ModifyContactGroupMembers(userID, resourceName string, add, remove []string) ([]string, error) {
+	if _, err := s.GetContactGroup(userID, resourceName); err != nil {
+		return nil, err
+	}
+	if resourceName == allContactsGroup {
+		return nil, ErrContactGroupNotModifiable
+	}
+
+	var notFound []string
+	for _, member := range add {
+		if _, err := s.GetPerson(userID, member); err != nil {
+			notFound = append(notFound, member)
+			continue
+		}
+		_, err := s.db.Exec(
+			"INSERT OR IGNORE INTO people_contact_group_members (user_id, group_resource_name, person_resource_name) VALUES (?, ?, ?)",
+			userID, resourceName, member,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, member := range remove {
+		result, err := s.db.Exec(
+			"DELETE FROM people_contact_group_members WHERE user_id = ? AND group_resource_name = ? AND person_resource_name = ?",
+			userID, resourceName, member,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := result.RowsAffected(); n == 0 && !slices.Contains(notFound, member) {
+			if _, err := s.GetPerson(userID, member); err != nil {
+				notFound = append(notFound, member)
+			}
+		}
+	}
+
+	if _, err := s.db.Exec("UPDATE people_contact_groups SET updated_at = ? WHERE user_id = ? AND resource_name = ?",
+		s.now().Format(time.RFC3339), userID, resourceName); err != nil {
+		return nil, err
+	}
+	changes := map[string]any{"resourceNamesToAdd": add, "resourceNamesToRemove": remove}
+	if err := s.audit("contact_group", resourceName, core.AuditUpdate, userID, changes); err != nil {
+		return nil, err
+	}
+	return notFound, nil
+}
+
 // GetPeopleSyncToken returns the current sync token for a user's contacts.
 func (s *GoogleStore) GetPeopleSyncToken(userID string) (string, error) {
 	var token string
@@ -1190,6 +2272,8 @@ func (s *GoogleStore) UpdatePeopleSyncToken(userID string) (string, error) {
 
 // ListPeopleSince returns people updated since the given sync token.
 func (s *GoogleStore) ListPeopleSince(userID string, syncToken string, pageSize int) ([]Person, string, error) {
+	pageSize = clampPageSize(pageSize)
+
 	// Decode the sync token to get a timestamp
 	var sinceTime int64
 	if syncToken != "" {
@@ -1228,6 +2312,7 @@ func (s *GoogleStore) ListPeopleSince(userID string, syncToken string, pageSize
 
 // ListPeopleConnections lists people for the connections endpoint with sync token support.
 func (s *GoogleStore) ListPeopleConnections(userID string, pageSize int, pageToken string, syncToken string) ([]Person, string, string, error) {
+	pageSize = clampPageSize(pageSize)
 	if syncToken != "" {
 		// Incremental sync
 		people, newSyncToken, err := s.ListPeopleSince(userID, syncToken, pageSize)
@@ -1235,17 +2320,14 @@ func (s *GoogleStore) ListPeopleConnections(userID string, pageSize int, pageTok
 	}
 
 	// Full sync with pagination
-	offset := 0
-	if pageToken != "" {
-		decoded, err := base64.StdEncoding.DecodeString(pageToken)
-		if err == nil {
-			offset, _ = strconv.Atoi(string(decoded))
-		}
+	page, err := s.openPage("people", pageToken, map[string]string{"userId": userID})
+	if err != nil {
+		return nil, "", "", err
 	}
 
-	sqlQuery := "SELECT resource_name, user_id, data FROM people WHERE user_id = ? ORDER BY resource_name ASC LIMIT ? OFFSET ?"
+	sqlQuery := "SELECT resource_name, user_id, data FROM people WHERE user_id = ? AND rowid <= ? ORDER BY resource_name ASC LIMIT ? OFFSET ?"
 
-	rows, err := s.db.Query(sqlQuery, userID, pageSize+1, offset)
+	rows, err := s.db.Query(sqlQuery, userID, page.AsOf, pageSize+1, page.Offset)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -1263,7 +2345,7 @@ func (s *GoogleStore) ListPeopleConnections(userID string, pageSize int, pageTok
 	var nextPageToken string
 	if len(people) > pageSize {
 		people = people[:pageSize]
-		nextPageToken = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset + pageSize)))
+		nextPageToken = page.next(pageSize)
 	}
 
 	// If no more pages, generate sync token
@@ -1275,6 +2357,207 @@ func (s *GoogleStore) ListPeopleConnections(userID string, pageSize int, pageTok
 	return people, nextPageToken, nextSyncToken, nil
 }
 
+// Directory types and methods
+
+// Directory source types, as the People API names them in the sources parameter
+const (
+	DirectorySourceDomainContact = "DIRECTORY_SOURCE_TYPE_DOMAIN_CONTACT"
+	DirectorySourceDomainProfile = "DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE"
+)
+
+// ErrInvalidSyncToken is returned for a directory sync token this store didn't issue
+var ErrInvalidSyncToken = errors.New("invalid sync token")
+
+// DirectoryPerson is an entry in the domain directory. The directory is
+// shared by every user and kept apart from each user's personal contacts.
+// ChangeSeq increases with every write so sync tokens can find changes.
+type DirectoryPerson struct {
+	ResourceName string
+	SourceType   string
+	Data         string
+	ChangeSeq    int64
+	UpdatedAt    string
+}
+
+// UpsertDirectoryPerson creates or replaces a directory entry
+func (s *GoogleStore) UpsertDirectoryPerson(p *DirectoryPerson) error {
+	var existing int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM directory_people WHERE resource_name = ?", p.ResourceName).Scan(&existing); err != nil {
+		return err
+	}
+	if err := s.db.QueryRow("SELECT COALESCE(MAX(change_seq), 0) + 1 FROM directory_people").Scan(&p.ChangeSeq); err != nil {
+		return err
+	}
+	p.UpdatedAt = s.now().UTC().Format(time.RFC3339)
+
+	_, err := s.db.Exec(`
+		INSERT INTO directory_people (resource_name, source_type, data, change_seq, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(resource_name) DO UPDATE SET
+			source_type = excluded.source_type, data = excluded.data,
+			change_seq = excluded.change_seq, updated_at = excluded.updated_at`,
+		p.ResourceName, p.SourceType, p.Data, p.ChangeSeq, p.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	action := core.AuditCreate
+	if existing > 0 {
+		action = core.AuditUpdate
+	}
+	var changes any
+	if json.Valid([]byte(p.Data)) {
+		changes = json.RawMessage(p.Data)
+	}
+	return s.audit("directory_person", p.ResourceName, action, "", changes)
+}
+
+// directorySourceFilter returns the SQL condition and arguments matching
+// entries from any of sources
+func directorySourceFilter(sources []string) (string, []any) {
+	args := make([]any, len(sources))
+	for i, source := range sources {
+		args[i] = source
+	}
+	return "source_type IN (?" + strings.Repeat(", ?", len(sources)-1) + ")", args
+}
+
+// scanDirectoryPeople reads rows of resource_name, source_type, data, change_seq, updated_at
+func scanDirectoryPeople(rows *sql.Rows) ([]DirectoryPerson, error) {
+	defer rows.Close()
+	var people []DirectoryPerson
+	for rows.Next() {
+		var p DirectoryPerson
+		var updatedAt sql.NullString
+		if err := rows.Scan(&p.ResourceName, &p.SourceType, &p.Data, &p.ChangeSeq, &updatedAt); err != nil {
+			return nil, err
+		}
+		p.UpdatedAt = updatedAt.String
+		people = append(people, p)
+	}
+	return people, rows.Err()
+}
+
+// directorySyncToken encodes the newest change sequence a client has seen
+func directorySyncToken(seq int64) string {
+	return base64.StdEncoding.EncodeToString([]byte("directory:" + strconv.FormatInt(seq, 10)))
+}
+
+// parseDirectorySyncToken returns the change sequence stored in a sync token
+func parseDirectorySyncToken(token string) (int64, error) {
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidSyncToken
+	}
+	raw, ok := strings.CutPrefix(string(decoded), "directory:")
+	if !ok {
+		return 0, ErrInvalidSyncToken
+	}
+	seq, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, ErrInvalidSyncToken
+	}
+	return seq, nil
+}
+
+// currentDirectorySyncToken returns a sync token covering every change so far
+func (s *GoogleStore) currentDirectorySyncToken() (string, error) {
+	var seq int64
+	if err := s.db.QueryRow("SELECT COALESCE(MAX(change_seq), 0) FROM directory_people").Scan(&seq); err != nil {
+		return "", err
+	}
+	return directorySyncToken(seq), nil
+}
+
+// ListDirectoryPeople lists directory entries from the given sources. A full
+// listing is paged by pageToken and returns a sync token with its last page.
+// With a syncToken only entries changed since it are returned, oldest change
+// first, along with the token to use next.
+func (s *GoogleStore) ListDirectoryPeople(sources []string, pageSize int, pageToken, syncToken string) ([]DirectoryPerson, string, string, error) {
+	pageSize = clampPageSize(pageSize)
+	where, args := directorySourceFilter(sources)
+
+	if syncToken != "" {
+		since, err := parseDirectorySyncToken(syncToken)
+		if err != nil {
+			return nil, "", "", err
+		}
+		rows, err := s.db.Query(`SELECT resource_name, source_type, data, change_seq, updated_at FROM directory_people
+			WHERE `+where+` AND change_seq > ? ORDER BY change_seq ASC LIMIT ?`, append(args, since, pageSize+1)...)
+		if err != nil {
+			return nil, "", "", err
+		}
+		people, err := scanDirectoryPeople(rows)
+		if err != nil {
+			return nil, "", "", err
+		}
+		// Later changes are picked up by syncing again from the last one returned
+		if len(people) > pageSize {
+			people = people[:pageSize]
+			return people, "", directorySyncToken(people[pageSize-1].ChangeSeq), nil
+		}
+		next, err := s.currentDirectorySyncToken()
+		return people, "", next, err
+	}
+
+	page, err := s.openPage("directory_people", pageToken, map[string]string{"sources": strings.Join(sources, ",")})
+	if err != nil {
+		return nil, "", "", err
+	}
+	rows, err := s.db.Query(`SELECT resource_name, source_type, data, change_seq, updated_at FROM directory_people
+		WHERE `+where+` AND rowid <= ? ORDER BY resource_name ASC LIMIT ? OFFSET ?`, append(args, page.AsOf, pageSize+1, page.Offset)...)
+	if err != nil {
+		return nil, "", "", err
+	}
+	people, err := scanDirectoryPeople(rows)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if len(people) > pageSize {
+		return people[:pageSize], page.next(pageSize), "", nil
+	}
+	next, err := s.currentDirectorySyncToken()
+	return people, "", next, err
+}
+
+// SearchDirectoryPeople finds directory entries from the given sources whose
+// names, emails or other fields contain query, returning a page of matches and
+// the total number of matches
+func (s *GoogleStore) SearchDirectoryPeople(query string, sources []string, pageSize int, pageToken string) ([]DirectoryPerson, int, string, error) {
+	pageSize = clampPageSize(pageSize)
+	page, err := s.openPage("directory_people", pageToken, map[string]string{"query": query, "sources": strings.Join(sources, ",")})
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	where, args := directorySourceFilter(sources)
+	escaped := strings.ReplaceAll(query, "\\", "\\\\")
+	escaped = strings.ReplaceAll(escaped, "%", "\\%")
+	escaped = strings.ReplaceAll(escaped, "_", "\\_")
+	where += " AND data LIKE ? ESCAPE '\\' AND rowid <= ?"
+	args = append(args, "%"+escaped+"%", page.AsOf)
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM directory_people WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, "", err
+	}
+	rows, err := s.db.Query(`SELECT resource_name, source_type, data, change_seq, updated_at FROM directory_people
+		WHERE `+where+` ORDER BY resource_name ASC LIMIT ? OFFSET ?`, append(args, pageSize+1, page.Offset)...)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	people, err := scanDirectoryPeople(rows)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	var nextToken string
+	if len(people) > pageSize {
+		people = people[:pageSize]
+		nextToken = page.next(pageSize)
+	}
+	return people, total, nextToken, nil
+}
+
 // Tasks types and methods
 
 type TaskList struct {
@@ -1300,13 +2583,18 @@ func (s *GoogleStore) CreateTaskList(tl *TaskList) error {
 	if tl.ID == "" {
 		tl.ID = fmt.Sprintf("tasklist_%d", time.Now().UnixNano())
 	}
-	tl.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	tl.UpdatedAt = s.now().UTC().Format(time.RFC3339)
 
-	_, err := s.db.Exec(
-		"INSERT INTO task_lists (id, user_id, title, updated_at) VALUES (?, ?, ?, ?)",
-		tl.ID, tl.UserID, tl.Title, tl.UpdatedAt,
-	)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			"INSERT INTO task_lists (id, user_id, title, updated_at) VALUES (?, ?, ?, ?)",
+			tl.ID, tl.UserID, tl.Title, tl.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "task_list", tl.ID, core.AuditCreate, tl.UserID, map[string]any{"title": tl.Title})
+	})
 }
 
 // GetTaskList retrieves a task list by ID
@@ -1322,18 +2610,24 @@ func (s *GoogleStore) GetTaskList(listID string) (*TaskList, error) {
 	return &tl, err
 }
 
-// CreateTask creates a new task
-func (s *GoogleStore) CreateTask(t *Task) (*Task, error) {
+// CreateTask creates a new task on behalf of actor, the user making the change
+func (s *GoogleStore) CreateTask(t *Task, actor string) (*Task, error) {
 	if t.ID == "" {
 		t.ID = fmt.Sprintf("task_%d", time.Now().UnixNano())
 	}
-	t.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	t.UpdatedAt = s.now().UTC().Format(time.RFC3339)
 
-	_, err := s.db.Exec(
-		`INSERT INTO tasks (id, list_id, title, notes, due, status, completed, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		t.ID, t.ListID, t.Title, t.Notes, t.Due, t.Status, t.Completed, t.UpdatedAt,
-	)
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO tasks (id, list_id, title, notes, due, status, completed, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.ID, t.ListID, t.Title, t.Notes, t.Due, t.Status, t.Completed, t.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "task", t.ID, core.AuditCreate, actor, map[string]any{"list_id": t.ListID, "title": t.Title, "status": t.Status})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -1355,17 +2649,61 @@ func (s *GoogleStore) GetTask(listID, taskID string) (*Task, error) {
 	return &t, err
 }
 
+// GetTaskByID gets a task without knowing which list holds it
+func (s *GoogleStore) GetTaskByID(taskID string) (*Task, error) {
+	var listID string
+	err := s.db.QueryRow("SELECT list_id FROM tasks WHERE id = ?", taskID).Scan(&listID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("task not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTask(listID, taskID)
+}
+
 // ListTasks lists tasks in a task list
-func (s *GoogleStore) ListTasks(listID string, showCompleted bool, maxResults int64) ([]*Task, error) {
+// TaskFilter narrows ListTasks results. Zero times leave a bound unset;
+// minimums are inclusive and maximums exclusive, as in the Tasks API.
+type TaskFilter struct {
+	ShowCompleted bool
+	DueMin        time.Time
+	DueMax        time.Time
+	CompletedMin  time.Time
+	CompletedMax  time.Time
+	UpdatedMin    time.Time
+}
+
+func (s *GoogleStore) ListTasks(listID string, filter TaskFilter, maxResults int64) ([]*Task, error) {
 	query := `SELECT id, list_id, title, COALESCE(notes, ''), COALESCE(due, ''), status,
 			  COALESCE(completed, ''), COALESCE(updated_at, '') FROM tasks
 			  WHERE list_id = ?`
 	args := []any{listID}
 
-	if !showCompleted {
+	if !filter.ShowCompleted {
 		query += " AND status != 'completed'"
 	}
 
+	// Compare as julian days so stored timestamps with offsets or fractional seconds still order correctly
+	bounds := []struct {
+		column string
+		op     string
+		bound  time.Time
+	}{
+		{"due", ">=", filter.DueMin},
+		{"due", "<", filter.DueMax},
+		{"completed", ">=", filter.CompletedMin},
+		{"completed", "<", filter.CompletedMax},
+		{"updated_at", ">=", filter.UpdatedMin},
+	}
+	for _, b := range bounds {
+		if b.bound.IsZero() {
+			continue
+		}
+		query += fmt.Sprintf(" AND julianday(%s) %s julianday(?)", b.column, b.op)
+		args = append(args, b.bound.UTC().Format(time.RFC3339Nano))
+	}
+
 	query += " ORDER BY updated_at DESC LIMIT ?"
 	args = append(args, maxResults)
 
@@ -1386,25 +2724,35 @@ func (s *GoogleStore) ListTasks(listID string, showCompleted bool, maxResults in
 	return tasks, nil
 }
 
-// UpdateTask updates an existing task
-func (s *GoogleStore) UpdateTask(t *Task) (*Task, error) {
-	t.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+// UpdateTask updates an existing task on behalf of actor
+func (s *GoogleStore) UpdateTask(t *Task, actor string) (*Task, error) {
+	t.UpdatedAt = s.now().UTC().Format(time.RFC3339)
 
-	_, err := s.db.Exec(
-		`UPDATE tasks SET title = ?, notes = ?, due = ?, status = ?, completed = ?, updated_at = ?
-		 WHERE list_id = ? AND id = ?`,
-		t.Title, t.Notes, t.Due, t.Status, t.Completed, t.UpdatedAt, t.ListID, t.ID,
-	)
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`UPDATE tasks SET title = ?, notes = ?, due = ?, status = ?, completed = ?, updated_at = ?
+			 WHERE list_id = ? AND id = ?`,
+			t.Title, t.Notes, t.Due, t.Status, t.Completed, t.UpdatedAt, t.ListID, t.ID,
+		)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "task", t.ID, core.AuditUpdate, actor, map[string]any{"title": t.Title, "status": t.Status, "due": t.Due})
+	})
 	if err != nil {
 		return nil, err
 	}
 	return t, nil
 }
 
-// DeleteTask deletes a task
-func (s *GoogleStore) DeleteTask(listID, taskID string) error {
-	_, err := s.db.Exec("DELETE FROM tasks WHERE list_id = ? AND id = ?", listID, taskID)
-	return err
+// DeleteTask deletes a task on behalf of actor
+func (s *GoogleStore) DeleteTask(listID, taskID, actor string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM tasks WHERE list_id = ? AND id = ?", listID, taskID); err != nil {
+			return err
+		}
+		return auditIn(tx, "task", taskID, core.AuditDelete, actor, map[string]any{"list_id": listID})
+	})
 }
 
 // ListAllTasks lists all tasks for admin UI
@@ -1439,5 +2787,28 @@ func (s *GoogleStore) CreateTaskFromForm(title, notes, due, status string) (*Tas
 		Due:    due,
 		Status: status,
 	}
-	return s.CreateTask(task)
+	return s.CreateTask(task, "")
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *GoogleStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db,
+		"gmail_messages",
+		"gmail_threads",
+		"gmail_attachments",
+		"gmail_history",
+		"gmail_labels",
+		"gmail_vacation_settings",
+		"gmail_vacation_replies",
+		"calendars",
+		"calendar_events",
+		"calendar_channels",
+		"people",
+		"people_photos",
+		"people_contact_groups",
+		"people_contact_group_members",
+		"directory_people",
+		"task_lists",
+		"tasks",
+	)
 }
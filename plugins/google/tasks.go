@@ -7,9 +7,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
-	"strconv"
 	"time"
 
+	"github.com/2389/ish/internal/auth"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -46,7 +46,7 @@ func (p *GooglePlugin) listTaskLists(w http.ResponseWriter, r *http.Request) {
 				"kind":    "tasks#taskList",
 				"id":      "@default",
 				"title":   "My Tasks",
-				"updated": time.Now().UTC().Format(time.RFC3339),
+				"updated": p.store.now().UTC().Format(time.RFC3339),
 			},
 		},
 	}
@@ -62,19 +62,37 @@ func (p *GooglePlugin) listTasks(w http.ResponseWriter, r *http.Request) {
 
 	listID := urlParam(r, "tasklist")
 
-	showCompleted := true
+	filter := TaskFilter{ShowCompleted: true}
 	if sc := r.URL.Query().Get("showCompleted"); sc == "false" {
-		showCompleted = false
+		filter.ShowCompleted = false
+	}
+
+	for name, bound := range map[string]*time.Time{
+		"dueMin":       &filter.DueMin,
+		"dueMax":       &filter.DueMax,
+		"completedMin": &filter.CompletedMin,
+		"completedMax": &filter.CompletedMax,
+		"updatedMin":   &filter.UpdatedMin,
+	} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, 400, "Invalid "+name+": must be an RFC 3339 timestamp", "INVALID_ARGUMENT")
+			return
+		}
+		*bound = t
 	}
 
-	maxResults := int64(100)
-	if mr := r.URL.Query().Get("maxResults"); mr != "" {
-		if v, err := strconv.ParseInt(mr, 10, 64); err == nil && v > 0 {
-			maxResults = v
-		}
+	maxResults, err := pageSizeParam(r, "maxResults", 100)
+	if err != nil {
+		writeError(w, 400, err.Error(), "INVALID_ARGUMENT")
+		return
 	}
 
-	tasks, err := p.store.ListTasks(listID, showCompleted, maxResults)
+	tasks, err := p.store.ListTasks(listID, filter, int64(maxResults))
 	if err != nil {
 		writeError(w, 500, "Internal error", "INTERNAL")
 		return
@@ -144,7 +162,7 @@ func (p *GooglePlugin) createTask(w http.ResponseWriter, r *http.Request) {
 		Status: "needsAction",
 	}
 
-	created, err := p.store.CreateTask(task)
+	created, err := p.store.CreateTask(task, auth.UserFromContext(r.Context()))
 	if err != nil {
 		writeError(w, 500, "Failed to create task", "INTERNAL")
 		return
@@ -184,6 +202,11 @@ func (p *GooglePlugin) getTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	writeJSON(w, taskToResponse(task))
+}
+
+// taskToResponse converts a Task to Tasks API response format
+func taskToResponse(task *Task) map[string]any {
 	resp := map[string]any{
 		"kind":    "tasks#task",
 		"id":      task.ID,
@@ -202,7 +225,7 @@ func (p *GooglePlugin) getTask(w http.ResponseWriter, r *http.Request) {
 		resp["completed"] = task.Completed
 	}
 
-	writeJSON(w, resp)
+	return resp
 }
 
 func (p *GooglePlugin) updateTask(w http.ResponseWriter, r *http.Request) {
@@ -247,13 +270,13 @@ func (p *GooglePlugin) updateTask(w http.ResponseWriter, r *http.Request) {
 		existing.Status = *req.Status
 		// If marking as completed, set completed timestamp
 		if *req.Status == "completed" && existing.Completed == "" {
-			existing.Completed = time.Now().UTC().Format(time.RFC3339)
+			existing.Completed = p.store.now().UTC().Format(time.RFC3339)
 		} else if *req.Status != "completed" {
 			existing.Completed = ""
 		}
 	}
 
-	updated, err := p.store.UpdateTask(existing)
+	updated, err := p.store.UpdateTask(existing, auth.UserFromContext(r.Context()))
 	if err != nil {
 		writeError(w, 500, "Failed to update task", "INTERNAL")
 		return
@@ -289,7 +312,7 @@ func (p *GooglePlugin) deleteTask(w http.ResponseWriter, r *http.Request) {
 	listID := urlParam(r, "tasklist")
 	taskID := urlParam(r, "task")
 
-	err := p.store.DeleteTask(listID, taskID)
+	err := p.store.DeleteTask(listID, taskID, auth.UserFromContext(r.Context()))
 	if err != nil {
 		writeError(w, 404, "Task not found", "NOT_FOUND")
 		return
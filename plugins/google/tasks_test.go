@@ -0,0 +1,103 @@
+// ABOUTME: Tests for Tasks API list filtering.
+// ABOUTME: Covers due, completed, and updated time bounds combined with showCompleted.
+
+package google
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// listTaskTitles lists tasks on the default list and returns their titles
+func listTaskTitles(t *testing.T, r http.Handler, query string) []string {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/tasks/v1/lists/@default/tasks?"+query, nil)
+	req.Header.Set("Authorization", "Bearer user:alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET ?%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items []struct {
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	titles := make([]string, len(resp.Items))
+	for i, item := range resp.Items {
+		titles[i] = item.Title
+	}
+	return titles
+}
+
+func createTestTask(t *testing.T, p *GooglePlugin, task Task) {
+	t.Helper()
+	task.ListID = "@default"
+	if task.Status == "" {
+		task.Status = "needsAction"
+	}
+	if _, err := p.store.CreateTask(&task, ""); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+}
+
+func TestListTasksDueMaxExcludesLaterTasks(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	createTestTask(t, p, Task{Title: "soon", Due: "2024-03-01T00:00:00Z"})
+	createTestTask(t, p, Task{Title: "later", Due: "2024-03-10T00:00:00Z"})
+	createTestTask(t, p, Task{Title: "undated"})
+
+	titles := listTaskTitles(t, r, "dueMax=2024-03-05T00:00:00Z")
+	if len(titles) != 1 || titles[0] != "soon" {
+		t.Errorf("expected only the task due before dueMax, got %v", titles)
+	}
+
+	// Offsets in the bound are honoured: 2024-03-10T00:00Z is 2024-03-09T20:00-04:00
+	titles = listTaskTitles(t, r, "dueMin=2024-03-09T20:00:00-04:00")
+	if len(titles) != 1 || titles[0] != "later" {
+		t.Errorf("expected only the task due on or after dueMin, got %v", titles)
+	}
+}
+
+func TestListTasksCompletedMinExcludesEarlierCompletions(t *testing.T) {
+	p, r := setupGmailRouter(t)
+
+	createTestTask(t, p, Task{Title: "old", Status: "completed", Completed: "2024-01-01T09:00:00Z"})
+	createTestTask(t, p, Task{Title: "recent", Status: "completed", Completed: "2024-02-01T09:00:00Z"})
+	createTestTask(t, p, Task{Title: "open"})
+
+	titles := listTaskTitles(t, r, "completedMin=2024-01-15T00:00:00Z")
+	if len(titles) != 1 || titles[0] != "recent" {
+		t.Errorf("expected only the task completed after completedMin, got %v", titles)
+	}
+
+	titles = listTaskTitles(t, r, "completedMax=2024-01-15T00:00:00Z")
+	if len(titles) != 1 || titles[0] != "old" {
+		t.Errorf("expected only the task completed before completedMax, got %v", titles)
+	}
+
+	// showCompleted=false still hides completed tasks when a completion bound is set
+	titles = listTaskTitles(t, r, "completedMin=2024-01-15T00:00:00Z&showCompleted=false")
+	if len(titles) != 0 {
+		t.Errorf("expected no tasks with showCompleted=false, got %v", titles)
+	}
+}
+
+func TestListTasksRejectsInvalidTimeBound(t *testing.T) {
+	_, r := setupGmailRouter(t)
+
+	for _, query := range []string{"dueMin=tomorrow", "updatedMin=2024-01-01"} {
+		req := httptest.NewRequest("GET", "/tasks/v1/lists/@default/tasks?"+query, nil)
+		req.Header.Set("Authorization", "Bearer user:alice")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET ?%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// ABOUTME: Home Assistant config and discovery endpoints
+// ABOUTME: Reports version, location, unit system, and the components loaded for an instance
+package homeassistant
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+)
+
+// haVersion is the Home Assistant version reported by the REST and WebSocket APIs
+const haVersion = "2024.1.0"
+
+// baseComponents are always loaded since they serve the APIs this plugin emulates
+var baseComponents = []string{"api", "config", "http", "websocket_api"}
+
+// components returns the base components plus the domain of every entity on the instance
+func (p *HomeAssistantPlugin) components(instanceID int64) ([]string, error) {
+	domains, err := p.store.ListDomainsByInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	components := append(slices.Clone(baseComponents), domains...)
+	slices.Sort(components)
+	return slices.Compact(components), nil
+}
+
+// handleGetConfig returns the instance configuration (compatible with Home Assistant /api/config)
+func (p *HomeAssistantPlugin) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	instance, ok := getInstanceFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	components, err := p.components(instance.ID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"components":    components,
+		"config_dir":    "/config",
+		"elevation":     0,
+		"latitude":      0.0,
+		"longitude":     0.0,
+		"location_name": instance.Name,
+		"time_zone":     "UTC",
+		"unit_system": map[string]string{
+			"length":      "km",
+			"mass":        "g",
+			"pressure":    "Pa",
+			"temperature": "°C",
+			"volume":      "L",
+		},
+		"version":                 haVersion,
+		"whitelist_external_dirs": []string{},
+		"allowlist_external_dirs": []string{},
+		"state":                   "RUNNING",
+		"external_url":            nil,
+		"internal_url":            instance.URL,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding config response: %v", err)
+	}
+}
+
+// handleDiscoveryInfo returns discovery details (compatible with Home Assistant /api/discovery_info)
+func (p *HomeAssistantPlugin) handleDiscoveryInfo(w http.ResponseWriter, r *http.Request) {
+	instance, ok := getInstanceFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	response := map[string]interface{}{
+		"base_url":              instance.URL,
+		"external_url":          nil,
+		"internal_url":          instance.URL,
+		"location_name":         instance.Name,
+		"requires_api_password": true,
+		"uuid":                  fmt.Sprintf("ish-homeassistant-%d", instance.ID),
+		"version":               haVersion,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding discovery info response: %v", err)
+	}
+}
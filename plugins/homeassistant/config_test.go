@@ -0,0 +1,140 @@
+// ABOUTME: Tests for the Home Assistant config and discovery endpoints
+// ABOUTME: Verifies components reflect seeded entity domains and the API root and discovery responses
+package homeassistant
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/migrations"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestPlugin(t *testing.T) (*HomeAssistantPlugin, http.Handler) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	p := &HomeAssistantPlugin{}
+	if err := p.SetDB(db); err != nil {
+		t.Fatalf("Failed to set database: %v", err)
+	}
+	r := chi.NewRouter()
+	p.RegisterRoutes(r)
+	return p, r
+}
+
+func getJSON(t *testing.T, r http.Handler, path string, out interface{}) {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Authorization", "Bearer token_home_main")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: expected 200, got %d: %s", path, w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+		t.Fatalf("GET %s: invalid JSON: %v", path, err)
+	}
+}
+
+func TestConfigListsSeededDomains(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	if _, err := p.Seed(context.Background(), "medium"); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+
+	var config struct {
+		Components   []string          `json:"components"`
+		LocationName string            `json:"location_name"`
+		UnitSystem   map[string]string `json:"unit_system"`
+		Version      string            `json:"version"`
+	}
+	getJSON(t, r, "/api/config", &config)
+
+	for _, domain := range []string{"light", "switch", "sensor", "climate", "api", "websocket_api"} {
+		if !slices.Contains(config.Components, domain) {
+			t.Errorf("Expected component %q in %v", domain, config.Components)
+		}
+	}
+	// Medium seeds six entities, so domains past the sixth are not loaded
+	if slices.Contains(config.Components, "lock") {
+		t.Errorf("Expected no lock component, got %v", config.Components)
+	}
+	if config.LocationName != "Home" || config.Version != haVersion || config.UnitSystem["temperature"] == "" {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+}
+
+func TestConfigReflectsNewEntities(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	if _, err := p.store.CreateInstance("http://ha.local:8123", "token_home_main", "Home"); err != nil {
+		t.Fatalf("CreateInstance failed: %v", err)
+	}
+
+	var config struct {
+		Components []string `json:"components"`
+	}
+	getJSON(t, r, "/api/config", &config)
+	if slices.Contains(config.Components, "vacuum") {
+		t.Fatalf("Expected no vacuum component before it exists, got %v", config.Components)
+	}
+
+	req := httptest.NewRequest("POST", "/api/states/vacuum.downstairs", strings.NewReader(`{"state":"docked"}`))
+	req.Header.Set("Authorization", "Bearer token_home_main")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	getJSON(t, r, "/api/config", &config)
+	if !slices.Contains(config.Components, "vacuum") {
+		t.Errorf("Expected vacuum component after setting its state, got %v", config.Components)
+	}
+}
+
+func TestAPIRootAndDiscoveryInfo(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	if _, err := p.store.CreateInstance("http://ha.local:8123", "token_home_main", "Home"); err != nil {
+		t.Fatalf("CreateInstance failed: %v", err)
+	}
+
+	var root struct {
+		Message string `json:"message"`
+	}
+	getJSON(t, r, "/api/", &root)
+	if root.Message != "API running." {
+		t.Errorf("Expected API running message, got %q", root.Message)
+	}
+
+	var info struct {
+		BaseURL      string `json:"base_url"`
+		LocationName string `json:"location_name"`
+		Version      string `json:"version"`
+	}
+	getJSON(t, r, "/api/discovery_info", &info)
+	if info.BaseURL != "http://ha.local:8123" || info.LocationName != "Home" || info.Version != haVersion {
+		t.Errorf("Unexpected discovery info: %+v", info)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/config", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", w.Code)
+	}
+}
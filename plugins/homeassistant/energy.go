@@ -0,0 +1,382 @@
+// ABOUTME: Home Assistant energy monitoring sensors and recorder statistics
+// ABOUTME: Generates deterministic hourly readings and serves POST /api/recorder/statistics_during_period
+package homeassistant
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// energyEpoch is where every sensor's random walk starts, so a reading is the
+// same no matter which window asks for it
+var energyEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// energyStatisticsWindow is how far back recorder statistics go
+const energyStatisticsWindow = 7 * 24 * time.Hour
+
+// energyReversion pulls each walk back toward its base so it stays realistic
+const energyReversion = 0.1
+
+// energySensor describes a seeded energy entity and the random walk behind its readings
+type energySensor struct {
+	entityID     string
+	friendlyName string
+	deviceID     string
+	unit         string
+	deviceClass  string
+	// cumulative sensors are meters: readings are running totals of the walk
+	cumulative bool
+	decimals   int
+
+	base, step, min, max float64
+	// profile scales the walk by UTC hour of day; nil means flat
+	profile func(hour int) float64
+}
+
+// energySensors are seeded on every instance for energy management scenarios
+var energySensors = []energySensor{
+	{
+		entityID: "sensor.grid_consumption", friendlyName: "Grid Consumption", deviceID: "smart_meter",
+		unit: "Wh", deviceClass: "energy", cumulative: true, decimals: 1,
+		base: 450, step: 60, min: 150, max: 1500,
+		profile: func(hour int) float64 {
+			switch {
+			case hour < 6:
+				return 0.6
+			case hour >= 17 && hour < 22:
+				return 1.4
+			}
+			return 1
+		},
+	},
+	{
+		entityID: "sensor.solar_production", friendlyName: "Solar Production", deviceID: "solar_inverter",
+		unit: "Wh", deviceClass: "energy", cumulative: true, decimals: 1,
+		base: 2200, step: 250, min: 200, max: 3500,
+		profile: func(hour int) float64 {
+			if hour < 6 || hour >= 18 {
+				return 0
+			}
+			return math.Sin(math.Pi * float64(hour-6) / 12)
+		},
+	},
+	{
+		entityID: "sensor.battery_charge", friendlyName: "Home Battery Charge", deviceID: "home_battery",
+		unit: "%", deviceClass: "battery", decimals: 1,
+		base: 65, step: 6, min: 10, max: 100,
+	},
+	{
+		entityID: "sensor.electricity_price", friendlyName: "Electricity Price", deviceID: "smart_meter",
+		unit: "USD/kWh", deviceClass: "monetary", decimals: 3,
+		base: 0.22, step: 0.02, min: 0.05, max: 0.6,
+		profile: func(hour int) float64 {
+			switch {
+			case hour < 6:
+				return 0.7
+			case hour >= 17 && hour < 21:
+				return 1.5
+			}
+			return 1
+		},
+	},
+}
+
+// findEnergySensor returns the energy sensor for an entity ID
+func findEnergySensor(entityID string) (energySensor, bool) {
+	for _, sensor := range energySensors {
+		if sensor.entityID == entityID {
+			return sensor, true
+		}
+	}
+	return energySensor{}, false
+}
+
+// stateClass is the recorder state class, which decides the statistics kept
+func (s energySensor) stateClass() string {
+	if s.cumulative {
+		return "total_increasing"
+	}
+	return "measurement"
+}
+
+// attributes returns the state attributes Home Assistant reports for the sensor
+func (s energySensor) attributes() map[string]interface{} {
+	return map[string]interface{}{
+		"unit_of_measurement": s.unit,
+		"device_class":        s.deviceClass,
+		"state_class":         s.stateClass(),
+		"friendly_name":       s.friendlyName,
+	}
+}
+
+// readings returns the sensor's reading at each hour from `from` through `to`,
+// both on hour boundaries at or after energyEpoch. The walk is seeded from the
+// entity ID, so readings are deterministic.
+func (s energySensor) readings(from, to time.Time) []float64 {
+	h := fnv.New64a()
+	h.Write([]byte(s.entityID))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+
+	scale := func(hour int) float64 {
+		if s.profile == nil {
+			return 1
+		}
+		return s.profile(hour)
+	}
+
+	level, total := s.base, 0.0
+	var out []float64
+	for t := energyEpoch; !t.After(to); t = t.Add(time.Hour) {
+		if !t.Before(from) {
+			reading := level * scale(t.Hour())
+			if s.cumulative {
+				reading = total
+			}
+			out = append(out, roundTo(reading, s.decimals))
+		}
+		level += rng.NormFloat64()*s.step + (s.base-level)*energyReversion
+		level = math.Min(math.Max(level, s.min), s.max)
+		if s.cumulative {
+			total += level * scale(t.Hour())
+		}
+	}
+	return out
+}
+
+func roundTo(v float64, decimals int) float64 {
+	pow := math.Pow(10, float64(decimals))
+	return math.Round(v*pow) / pow
+}
+
+// energyStatistic is one period of recorder statistics for a sensor
+type energyStatistic struct {
+	start, end     time.Time
+	mean, min, max float64
+	state, change  float64
+}
+
+// hourlyStatistics summarizes the sensor over each hour from start to end.
+// Meters report their reading at the end of the hour and the energy used in
+// it; other sensors report the mean, min, and max of the hour's readings.
+func (s energySensor) hourlyStatistics(start, end time.Time) []energyStatistic {
+	readings := s.readings(start, end)
+	stats := make([]energyStatistic, 0, len(readings))
+	for i := 0; i+1 < len(readings); i++ {
+		a, b := readings[i], readings[i+1]
+		hour := start.Add(time.Duration(i) * time.Hour)
+		stats = append(stats, energyStatistic{
+			start:  hour,
+			end:    hour.Add(time.Hour),
+			mean:   roundTo((a+b)/2, s.decimals+1),
+			min:    math.Min(a, b),
+			max:    math.Max(a, b),
+			state:  b,
+			change: roundTo(b-a, s.decimals),
+		})
+	}
+	return stats
+}
+
+// dailyStatistics combines hourly statistics by UTC day
+func (s energySensor) dailyStatistics(hourly []energyStatistic) []energyStatistic {
+	var days []energyStatistic
+	for i := 0; i < len(hourly); {
+		day := hourly[i].start.Truncate(24 * time.Hour)
+		j := i
+		for j < len(hourly) && hourly[j].start.Truncate(24*time.Hour).Equal(day) {
+			j++
+		}
+		group := hourly[i:j]
+
+		stat := energyStatistic{
+			start: day,
+			end:   day.Add(24 * time.Hour),
+			min:   group[0].min,
+			max:   group[0].max,
+			state: group[len(group)-1].state,
+		}
+		var mean, change float64
+		for _, hour := range group {
+			mean += hour.mean
+			change += hour.change
+			stat.min = math.Min(stat.min, hour.min)
+			stat.max = math.Max(stat.max, hour.max)
+		}
+		stat.mean = roundTo(mean/float64(len(group)), s.decimals+1)
+		stat.change = roundTo(change, s.decimals)
+		days = append(days, stat)
+		i = j
+	}
+	return days
+}
+
+// statisticToResponse formats a statistic as the recorder returns it, keeping
+// only the requested types when any are given
+func (s energySensor) statisticToResponse(stat energyStatistic, types []string) map[string]interface{} {
+	values := map[string]interface{}{
+		"mean": stat.mean,
+		"min":  stat.min,
+		"max":  stat.max,
+	}
+	if s.cumulative {
+		// Meters never reset, so the sum is the reading itself
+		values = map[string]interface{}{
+			"last_reset": nil,
+			"state":      stat.state,
+			"sum":        stat.state,
+			"change":     stat.change,
+		}
+	}
+
+	resp := map[string]interface{}{
+		"start": stat.start.UnixMilli(),
+		"end":   stat.end.UnixMilli(),
+	}
+	for key, value := range values {
+		if len(types) == 0 || slices.Contains(types, key) {
+			resp[key] = value
+		}
+	}
+	return resp
+}
+
+// parseTimestamp reads the ISO 8601 timestamps Home Assistant accepts,
+// treating those without a zone as UTC
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.New("invalid timestamp")
+}
+
+// handleStatisticsDuringPeriod returns hourly or daily statistics for the
+// instance's energy sensors over at most the past 7 days (a simplified
+// Home Assistant recorder/statistics_during_period)
+func (p *HomeAssistantPlugin) handleStatisticsDuringPeriod(w http.ResponseWriter, r *http.Request) {
+	instance, ok := getInstanceFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBytes)
+	var req struct {
+		StartTime    string   `json:"start_time"`
+		EndTime      string   `json:"end_time"`
+		StatisticIDs []string `json:"statistic_ids"`
+		Period       string   `json:"period"`
+		Types        []string `json:"types"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Period == "" {
+		req.Period = "hour"
+	}
+	if req.Period != "hour" && req.Period != "day" {
+		http.Error(w, "Invalid period. Must be one of: hour, day", http.StatusBadRequest)
+		return
+	}
+
+	// Statistics cover only the past 7 days, so bounds are clamped to them
+	latest := time.Now().UTC().Truncate(time.Hour)
+	earliest := latest.Add(-energyStatisticsWindow)
+	start, end := earliest, latest
+	for _, bound := range []struct {
+		name  string
+		value string
+		dst   *time.Time
+	}{{"start_time", req.StartTime, &start}, {"end_time", req.EndTime, &end}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := parseTimestamp(bound.value)
+		if err != nil {
+			http.Error(w, "Invalid "+bound.name, http.StatusBadRequest)
+			return
+		}
+		t = t.Truncate(time.Hour)
+		if t.Before(earliest) {
+			t = earliest
+		}
+		if t.After(latest) {
+			t = latest
+		}
+		*bound.dst = t
+	}
+
+	ids := req.StatisticIDs
+	if len(ids) == 0 {
+		for _, sensor := range energySensors {
+			ids = append(ids, sensor.entityID)
+		}
+	}
+
+	// Like the recorder, leave out IDs without statistics
+	response := make(map[string][]map[string]interface{})
+	for _, id := range ids {
+		sensor, ok := findEnergySensor(id)
+		if !ok || !start.Before(end) {
+			continue
+		}
+		if _, err := p.store.GetLatestState(instance.ID, id); err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		stats := sensor.hourlyStatistics(start, end)
+		if req.Period == "day" {
+			stats = sensor.dailyStatistics(stats)
+		}
+		rows := make([]map[string]interface{}, 0, len(stats))
+		for _, stat := range stats {
+			rows = append(rows, sensor.statisticToResponse(stat, req.Types))
+		}
+		response[id] = rows
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding statistics response: %v", err)
+	}
+}
+
+// seedEnergyStates records the energy sensors' readings for the past hours,
+// newest first, as the sensors would have reported them
+func (p *HomeAssistantPlugin) seedEnergyStates(instanceID int64, hours int) (int, error) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	from := now.Add(-time.Duration(hours-1) * time.Hour)
+
+	total := 0
+	for _, sensor := range energySensors {
+		attributesJSON, err := json.Marshal(sensor.attributes())
+		if err != nil {
+			return total, err
+		}
+		readings := sensor.readings(from, now)
+		for j := len(readings) - 1; j >= 0; j-- {
+			timestamp := from.Add(time.Duration(j) * time.Hour)
+			state := strconv.FormatFloat(readings[j], 'f', sensor.decimals, 64)
+			if err := p.store.RecordState(instanceID, sensor.entityID, state, string(attributesJSON), timestamp, timestamp); err != nil {
+				return total, err
+			}
+			total++
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,170 @@
+// ABOUTME: Tests for the Home Assistant energy sensors and recorder statistics endpoint
+// ABOUTME: Covers a week of hourly data, deterministic readings, and the request filters
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type statisticsResponse map[string][]map[string]interface{}
+
+func postStatistics(t *testing.T, r http.Handler, body string) statisticsResponse {
+	t.Helper()
+	w := postJSON(t, r, "/api/recorder/statistics_during_period", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp statisticsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	return resp
+}
+
+func seededPlugin(t *testing.T) (*HomeAssistantPlugin, http.Handler) {
+	t.Helper()
+	p, r := setupTestPlugin(t)
+	if _, err := p.Seed(context.Background(), "small"); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	return p, r
+}
+
+func TestStatisticsDuringPeriodReturnsPastWeekHourly(t *testing.T) {
+	_, r := seededPlugin(t)
+
+	resp := postStatistics(t, r, `{}`)
+	if len(resp) != len(energySensors) {
+		t.Fatalf("Expected statistics for every energy sensor, got %d", len(resp))
+	}
+
+	end := time.Now().UTC().Truncate(time.Hour).UnixMilli()
+	for id, rows := range resp {
+		if len(rows) != 168 {
+			t.Fatalf("%s: expected 168 hourly rows, got %d", id, len(rows))
+		}
+		if last := int64(rows[len(rows)-1]["end"].(float64)); last != end {
+			t.Errorf("%s: expected the last hour to end at %d, got %d", id, end, last)
+		}
+		for i, row := range rows {
+			start, rowEnd := int64(row["start"].(float64)), int64(row["end"].(float64))
+			if rowEnd-start != time.Hour.Milliseconds() {
+				t.Fatalf("%s: row %d is not an hour long: %v", id, i, row)
+			}
+			if i > 0 && start != int64(rows[i-1]["end"].(float64)) {
+				t.Fatalf("%s: row %d does not follow the previous hour", id, i)
+			}
+		}
+	}
+
+	for _, row := range resp["sensor.grid_consumption"] {
+		if row["change"].(float64) <= 0 || row["sum"] != row["state"] {
+			t.Fatalf("Expected the grid meter to increase every hour, got %v", row)
+		}
+	}
+	for _, row := range resp["sensor.solar_production"] {
+		hour := time.UnixMilli(int64(row["start"].(float64))).UTC().Hour()
+		if hour < 6 && row["change"].(float64) != 0 {
+			t.Fatalf("Expected no solar production at %02d:00 UTC, got %v", hour, row)
+		}
+	}
+	for _, row := range resp["sensor.battery_charge"] {
+		mean, lo, hi := row["mean"].(float64), row["min"].(float64), row["max"].(float64)
+		if lo < 10 || hi > 100 || mean < lo || mean > hi {
+			t.Fatalf("Expected a battery charge between 10%% and 100%%, got %v", row)
+		}
+		if _, ok := row["sum"]; ok {
+			t.Fatalf("Expected no sum for a measurement sensor, got %v", row)
+		}
+	}
+}
+
+func TestStatisticsDuringPeriodIsDeterministic(t *testing.T) {
+	_, r := seededPlugin(t)
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	window := func(from, to time.Duration) string {
+		return fmt.Sprintf(`{"statistic_ids":["sensor.electricity_price","sensor.solar_production"],"start_time":%q,"end_time":%q}`,
+			now.Add(-from).Format(time.RFC3339), now.Add(-to).Format(time.RFC3339))
+	}
+	first := postStatistics(t, r, window(72*time.Hour, 24*time.Hour))
+	second := postStatistics(t, r, window(48*time.Hour, 0))
+
+	for id, rows := range first {
+		byStart := map[float64]map[string]interface{}{}
+		for _, row := range second[id] {
+			byStart[row["start"].(float64)] = row
+		}
+		shared := 0
+		for _, row := range rows {
+			other, ok := byStart[row["start"].(float64)]
+			if !ok {
+				continue
+			}
+			shared++
+			if fmt.Sprint(row) != fmt.Sprint(other) {
+				t.Fatalf("%s: expected overlapping windows to agree, got %v and %v", id, row, other)
+			}
+		}
+		if shared != 24 {
+			t.Errorf("%s: expected 24 shared hours, got %d", id, shared)
+		}
+	}
+
+	// A fresh store generates the same readings
+	_, fresh := seededPlugin(t)
+	if again := postStatistics(t, fresh, window(72*time.Hour, 24*time.Hour)); fmt.Sprint(again) != fmt.Sprint(first) {
+		t.Error("Expected the same statistics from a fresh store")
+	}
+}
+
+func TestStatisticsDuringPeriodFilters(t *testing.T) {
+	_, r := seededPlugin(t)
+
+	resp := postStatistics(t, r, `{"statistic_ids":["sensor.battery_charge","sensor.unknown"],"types":["mean"]}`)
+	if len(resp) != 1 || len(resp["sensor.battery_charge"]) == 0 {
+		t.Fatalf("Expected only the battery statistics, got %v", resp)
+	}
+	if row := resp["sensor.battery_charge"][0]; len(row) != 3 || row["mean"] == nil {
+		t.Errorf("Expected only start, end, and mean, got %v", row)
+	}
+
+	// Windows are clamped to the past 7 days
+	old := time.Now().AddDate(0, 0, -30).Format(time.RFC3339)
+	resp = postStatistics(t, r, fmt.Sprintf(`{"statistic_ids":["sensor.grid_consumption"],"start_time":%q}`, old))
+	if rows := resp["sensor.grid_consumption"]; len(rows) != 168 {
+		t.Errorf("Expected the window clamped to 168 hours, got %d", len(rows))
+	}
+
+	hourly := postStatistics(t, r, `{"statistic_ids":["sensor.grid_consumption"]}`)["sensor.grid_consumption"]
+	daily := postStatistics(t, r, `{"statistic_ids":["sensor.grid_consumption"],"period":"day"}`)["sensor.grid_consumption"]
+	if len(daily) < 7 || len(daily) > 8 {
+		t.Fatalf("Expected 7 or 8 days, got %d", len(daily))
+	}
+	if daily[len(daily)-1]["state"] != hourly[len(hourly)-1]["state"] {
+		t.Errorf("Expected the last day to end on the last hourly reading, got %v", daily[len(daily)-1])
+	}
+
+	if w := postJSON(t, r, "/api/recorder/statistics_during_period", `{"period":"5minute"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unsupported period, got %d", w.Code)
+	}
+	if w := postJSON(t, r, "/api/recorder/statistics_during_period", `{"start_time":"yesterday"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid start_time, got %d", w.Code)
+	}
+}
+
+func TestStatisticsDuringPeriodSkipsUnseededInstance(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	if _, err := p.store.CreateInstance("http://ha.local:8123", "token_home_main", "Home"); err != nil {
+		t.Fatalf("CreateInstance failed: %v", err)
+	}
+
+	if resp := postStatistics(t, r, `{}`); len(resp) != 0 {
+		t.Errorf("Expected no statistics without energy sensors, got %v", resp)
+	}
+}
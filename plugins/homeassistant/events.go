@@ -0,0 +1,268 @@
+// ABOUTME: Home Assistant event bus: firing, storing, and listing events
+// ABOUTME: Delivers events to WebSocket subscribers and synthesizes state_changed when states change
+package homeassistant
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// matchAll is the event type counted for subscriptions to every event
+	matchAll = "*"
+
+	originLocal  = "LOCAL"
+	originRemote = "REMOTE"
+)
+
+// builtinEventTypes are always listed since the plugin fires them itself
+var builtinEventTypes = []string{"call_service", "state_changed"}
+
+// serviceStates maps services that set an entity to a fixed state
+var serviceStates = map[string]string{
+	"turn_on":     "on",
+	"turn_off":    "off",
+	"lock":        "locked",
+	"unlock":      "unlocked",
+	"open_cover":  "open",
+	"close_cover": "closed",
+}
+
+// eventBus tracks WebSocket event subscriptions
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[*WSClient]map[int]string // client -> subscription ID -> event type
+}
+
+// subscribe registers a subscription; an empty eventType matches every event
+func (b *eventBus) subscribe(client *WSClient, id int, eventType string) {
+	if eventType == "" {
+		eventType = matchAll
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = make(map[*WSClient]map[int]string)
+	}
+	if b.subs[client] == nil {
+		b.subs[client] = make(map[int]string)
+	}
+	b.subs[client][id] = eventType
+}
+
+// unsubscribe removes a subscription and reports whether it existed
+func (b *eventBus) unsubscribe(client *WSClient, id int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[client][id]; !ok {
+		return false
+	}
+	delete(b.subs[client], id)
+	return true
+}
+
+// removeClient drops every subscription of a disconnected client
+func (b *eventBus) removeClient(client *WSClient) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, client)
+}
+
+// instanceID returns the ID of the instance the client authenticated as, or 0 before auth
+func (client *WSClient) instanceID() int64 {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	if client.instance == nil {
+		return 0
+	}
+	return client.instance.ID
+}
+
+// listenerCounts returns the number of subscriptions per event type on an instance
+func (b *eventBus) listenerCounts(instanceID int64) map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	counts := make(map[string]int)
+	for client, subs := range b.subs {
+		if client.instanceID() != instanceID {
+			continue
+		}
+		for _, eventType := range subs {
+			counts[eventType]++
+		}
+	}
+	return counts
+}
+
+// publish sends an event to every matching subscriber on the event's instance
+func (b *eventBus) publish(ev *Event, data map[string]interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for client, subs := range b.subs {
+		if client.instanceID() != ev.InstanceID {
+			continue
+		}
+		for id, eventType := range subs {
+			if eventType != matchAll && eventType != ev.EventType {
+				continue
+			}
+			client.sendMessage(WSMessage{
+				ID:    id,
+				Type:  "event",
+				Event: eventPayload(ev, data),
+			})
+		}
+	}
+}
+
+// eventPayload formats an event the way Home Assistant sends it to subscribers
+func eventPayload(ev *Event, data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"event_type": ev.EventType,
+		"data":       data,
+		"origin":     ev.Origin,
+		"time_fired": ev.TimeFired.Format(time.RFC3339Nano),
+	}
+}
+
+// fireEvent stores an event and delivers it to subscribers
+func (p *HomeAssistantPlugin) fireEvent(instanceID int64, eventType string, data map[string]interface{}, origin string) error {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	ev, err := p.store.RecordEvent(instanceID, eventType, string(dataJSON), origin, time.Now())
+	if err != nil {
+		return err
+	}
+	p.events.publish(ev, data)
+	return nil
+}
+
+// fireStateChanged fires state_changed for an entity moving from oldState (nil if new) to newState
+func (p *HomeAssistantPlugin) fireStateChanged(instanceID int64, entityID string, oldState, newState *State) {
+	data := map[string]interface{}{
+		"entity_id": entityID,
+		"old_state": nil,
+		"new_state": stateObject(newState),
+	}
+	if oldState != nil {
+		data["old_state"] = stateObject(oldState)
+	}
+	if err := p.fireEvent(instanceID, "state_changed", data, originLocal); err != nil {
+		log.Printf("Error firing state_changed for %s: %v", entityID, err)
+	}
+}
+
+// applyServiceState moves an entity to the state a service implies and returns
+// the new state, or nil if the service leaves the state as it was
+func (p *HomeAssistantPlugin) applyServiceState(instanceID int64, service, entityID string) (*State, error) {
+	current, err := p.store.GetLatestState(instanceID, entityID)
+	if err == sql.ErrNoRows {
+		// Services only act on entities that have a state
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	target, ok := serviceStates[service]
+	if service == "toggle" {
+		target, ok = "on", true
+		if current.State == "on" {
+			target = "off"
+		}
+	}
+	if !ok || target == current.State {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if err := p.store.RecordState(instanceID, entityID, target, current.Attributes, now, now); err != nil {
+		return nil, err
+	}
+	newState := &State{
+		InstanceID:  instanceID,
+		EntityID:    entityID,
+		State:       target,
+		Attributes:  current.Attributes,
+		LastChanged: now,
+		LastUpdated: now,
+	}
+	p.fireStateChanged(instanceID, entityID, current, newState)
+	return newState, nil
+}
+
+// stateObject formats a state the way Home Assistant returns it
+func stateObject(state *State) map[string]interface{} {
+	var attributes map[string]interface{}
+	if state.Attributes != "" {
+		if err := json.Unmarshal([]byte(state.Attributes), &attributes); err != nil {
+			log.Printf("Error unmarshaling attributes for entity %s: %v", state.EntityID, err)
+		}
+	}
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"entity_id":    state.EntityID,
+		"state":        state.State,
+		"attributes":   attributes,
+		"last_changed": state.LastChanged.Format(time.RFC3339),
+		"last_updated": state.LastUpdated.Format(time.RFC3339),
+	}
+}
+
+// handleListEvents lists event types with their listener counts (compatible with Home Assistant GET /api/events)
+func (p *HomeAssistantPlugin) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	instance, ok := getInstanceFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	fired, err := p.store.ListEventTypesByInstance(instance.ID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	counts := p.events.listenerCounts(instance.ID)
+	types := make(map[string]bool)
+	for _, eventType := range builtinEventTypes {
+		types[eventType] = true
+	}
+	for _, eventType := range fired {
+		types[eventType] = true
+	}
+	for eventType := range counts {
+		types[eventType] = true
+	}
+
+	names := make([]string, 0, len(types))
+	for eventType := range types {
+		names = append(names, eventType)
+	}
+	sort.Strings(names)
+
+	response := make([]map[string]interface{}, 0, len(names))
+	for _, eventType := range names {
+		response = append(response, map[string]interface{}{
+			"event":          eventType,
+			"listener_count": counts[eventType],
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding events response: %v", err)
+	}
+}
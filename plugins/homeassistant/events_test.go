@@ -0,0 +1,137 @@
+// ABOUTME: Tests for Home Assistant event firing and the event listing endpoint
+// ABOUTME: Covers stored events, synthesized state_changed events, and subscriber delivery
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postJSON(t *testing.T, r http.Handler, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_home_main")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestServiceCallRecordsStateChanged(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	instance, err := p.store.CreateInstance("http://ha.local:8123", "token_home_main", "Home")
+	if err != nil {
+		t.Fatalf("CreateInstance failed: %v", err)
+	}
+
+	if w := postJSON(t, r, "/api/states/light.kitchen", `{"state":"off","attributes":{"brightness":0}}`); w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := postJSON(t, r, "/api/services/light/turn_on", `{"entity_id":"light.kitchen"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	events, err := p.store.ListEventsByInstance(instance.ID, "state_changed", 10, 0)
+	if err != nil {
+		t.Fatalf("ListEventsByInstance failed: %v", err)
+	}
+	// One from creating the state, one from the service call
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 state_changed events, got %d", len(events))
+	}
+
+	var data struct {
+		EntityID string `json:"entity_id"`
+		OldState struct {
+			State string `json:"state"`
+		} `json:"old_state"`
+		NewState struct {
+			State      string                 `json:"state"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"new_state"`
+	}
+	if err := json.Unmarshal([]byte(events[0].EventData), &data); err != nil {
+		t.Fatalf("Invalid event data: %v", err)
+	}
+	if data.EntityID != "light.kitchen" || data.OldState.State != "off" || data.NewState.State != "on" {
+		t.Errorf("Unexpected state_changed data: %s", events[0].EventData)
+	}
+	if _, ok := data.NewState.Attributes["brightness"]; !ok {
+		t.Errorf("Expected attributes carried over to the new state, got %v", data.NewState.Attributes)
+	}
+
+	state, err := p.store.GetLatestState(instance.ID, "light.kitchen")
+	if err != nil || state.State != "on" {
+		t.Errorf("Expected light.kitchen to be on, got %+v (%v)", state, err)
+	}
+
+	// A service that leaves the state unchanged fires no state_changed
+	postJSON(t, r, "/api/services/light/turn_on", `{"entity_id":"light.kitchen"}`)
+	if events, _ := p.store.ListEventsByInstance(instance.ID, "state_changed", 10, 0); len(events) != 2 {
+		t.Errorf("Expected no new state_changed event, got %d events", len(events))
+	}
+	if calls, _ := p.store.ListEventsByInstance(instance.ID, "call_service", 10, 0); len(calls) != 2 {
+		t.Errorf("Expected a call_service event per call, got %d", len(calls))
+	}
+}
+
+func TestFireEventAndListEvents(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	instance, err := p.store.CreateInstance("http://ha.local:8123", "token_home_main", "Home")
+	if err != nil {
+		t.Fatalf("CreateInstance failed: %v", err)
+	}
+
+	if w := postJSON(t, r, "/api/events/doorbell_pressed", `{"door":"front"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	events, _ := p.store.ListEventsByInstance(instance.ID, "doorbell_pressed", 10, 0)
+	if len(events) != 1 || events[0].Origin != originRemote || events[0].EventData != `{"door":"front"}` {
+		t.Fatalf("Expected the fired event to be stored, got %+v", events)
+	}
+
+	// A live subscriber counts as a listener and receives fired events
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &WSClient{instance: instance, send: make(chan []byte, 4), ctx: ctx, cancel: cancel}
+	p.events.subscribe(client, 7, "doorbell_pressed")
+	postJSON(t, r, "/api/events/doorbell_pressed", `{"door":"back"}`)
+
+	select {
+	case raw := <-client.send:
+		var msg WSMessage
+		json.Unmarshal(raw, &msg)
+		if msg.ID != 7 || msg.Type != "event" || msg.Event["event_type"] != "doorbell_pressed" {
+			t.Errorf("Unexpected event message: %s", raw)
+		}
+	default:
+		t.Error("Expected the subscriber to receive the event")
+	}
+
+	var listed []struct {
+		Event         string `json:"event"`
+		ListenerCount int    `json:"listener_count"`
+	}
+	getJSON(t, r, "/api/events", &listed)
+	counts := make(map[string]int)
+	for _, e := range listed {
+		counts[e.Event] = e.ListenerCount
+	}
+	if count, ok := counts["doorbell_pressed"]; !ok || count != 1 {
+		t.Errorf("Expected doorbell_pressed with 1 listener, got %v", listed)
+	}
+	if _, ok := counts["state_changed"]; !ok {
+		t.Errorf("Expected built-in state_changed listed, got %v", listed)
+	}
+
+	p.events.removeClient(client)
+	getJSON(t, r, "/api/events", &listed)
+	for _, e := range listed {
+		if e.ListenerCount != 0 {
+			t.Errorf("Expected no listeners after disconnect, got %v", listed)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// ABOUTME: core.Inspector implementation for the Home Assistant plugin
+// ABOUTME: Renders state rows as the states API does
+
+package homeassistant
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Inspect implements core.Inspector for `ish inspect`. Only states can be
+// looked up on their own; list the other resources instead.
+func (p *HomeAssistantPlugin) Inspect(resource, id string) (interface{}, error) {
+	if resource != "states" {
+		return nil, fmt.Errorf("individual %s lookup not supported - omit --id to list them", resource)
+	}
+	stateID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state ID: %s", id)
+	}
+	state, err := p.store.GetState(stateID)
+	if err != nil {
+		return nil, err
+	}
+	return stateToResponse(*state), nil
+}
@@ -0,0 +1,134 @@
+// ABOUTME: Home Assistant logbook endpoint
+// ABOUTME: Lists entity state changes from recorded states for GET /api/logbook/{timestamp}
+package homeassistant
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// logbookPeriod is how long after its start a logbook query covers without an end_time
+const logbookPeriod = 24 * time.Hour
+
+// StateChange is a recorded state along with its entity's friendly name
+type StateChange struct {
+	EntityID     string
+	FriendlyName string
+	State        string
+	LastChanged  time.Time
+}
+
+// ListStateChanges returns an instance's recorded states oldest first,
+// optionally only for one entity
+func (s *Store) ListStateChanges(instanceID int64, entityID string) ([]StateChange, error) {
+	rows, err := s.db.Query(`
+		SELECT s.entity_id, COALESCE(e.friendly_name, ''), s.state, s.last_changed
+		FROM homeassistant_states s
+		LEFT JOIN homeassistant_entities e ON e.instance_id = s.instance_id AND e.entity_id = s.entity_id
+		WHERE s.instance_id = ? AND (? = '' OR s.entity_id = ?)
+		ORDER BY s.id
+	`, instanceID, entityID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []StateChange
+	for rows.Next() {
+		var c StateChange
+		if err := rows.Scan(&c.EntityID, &c.FriendlyName, &c.State, &c.LastChanged); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Seeded history is recorded newest first, so order by time rather than insertion
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].LastChanged.Before(changes[j].LastChanged)
+	})
+	return changes, nil
+}
+
+// handleLogbook lists state changes from the timestamp in the path, or the
+// past day without one (compatible with Home Assistant GET /api/logbook/{timestamp}).
+// Supports the end_time and entity query parameters.
+func (p *HomeAssistantPlugin) handleLogbook(w http.ResponseWriter, r *http.Request) {
+	instance, ok := getInstanceFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	start := time.Now().Add(-logbookPeriod)
+	if timestamp := chi.URLParam(r, "timestamp"); timestamp != "" {
+		t, err := parseTimestamp(timestamp)
+		if err != nil {
+			http.Error(w, "Invalid datetime", http.StatusBadRequest)
+			return
+		}
+		start = t
+	}
+	end := start.Add(logbookPeriod)
+	if endTime := r.URL.Query().Get("end_time"); endTime != "" {
+		t, err := parseTimestamp(endTime)
+		if err != nil {
+			http.Error(w, "Invalid end_time", http.StatusBadRequest)
+			return
+		}
+		end = t
+	}
+
+	entityID := r.URL.Query().Get("entity")
+	if entityID != "" && !isValidEntityID(entityID) {
+		http.Error(w, "Invalid entity ID format. Must match pattern: domain.entity_name", http.StatusBadRequest)
+		return
+	}
+
+	changes, err := p.store.ListStateChanges(instance.ID, entityID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Updates that leave the state as it was are not changes, so they are skipped
+	previous := make(map[string]string)
+	response := make([]map[string]interface{}, 0)
+	for _, c := range changes {
+		last, seen := previous[c.EntityID]
+		previous[c.EntityID] = c.State
+		if seen && last == c.State {
+			continue
+		}
+		if c.LastChanged.Before(start) || !c.LastChanged.Before(end) {
+			continue
+		}
+		response = append(response, stateChangeToLogbookEntry(c))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding logbook response: %v", err)
+	}
+}
+
+// stateChangeToLogbookEntry formats a state change as a logbook entry
+func stateChangeToLogbookEntry(c StateChange) map[string]interface{} {
+	name := c.FriendlyName
+	if name == "" {
+		name = c.EntityID
+	}
+	return map[string]interface{}{
+		"when":      c.LastChanged.UTC().Format(time.RFC3339),
+		"name":      name,
+		"state":     c.State,
+		"entity_id": c.EntityID,
+	}
+}
@@ -0,0 +1,77 @@
+// ABOUTME: Tests for the Home Assistant logbook endpoint
+// ABOUTME: Verifies state changes are listed in order and filtered by time and entity
+package homeassistant
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type logbookEntry struct {
+	When     string `json:"when"`
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	EntityID string `json:"entity_id"`
+}
+
+func TestLogbookListsStateChanges(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	if _, err := p.store.CreateInstance("http://ha.local:8123", "token_home_main", "Home"); err != nil {
+		t.Fatalf("CreateInstance failed: %v", err)
+	}
+
+	start := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	for _, body := range []struct{ path, state string }{
+		{"/api/states/light.kitchen", "on"},
+		{"/api/states/light.kitchen", "on"},
+		{"/api/states/lock.front_door", "locked"},
+		{"/api/states/light.kitchen", "off"},
+	} {
+		if w := postJSON(t, r, body.path, `{"state":"`+body.state+`"}`); w.Code != http.StatusCreated && w.Code != http.StatusOK {
+			t.Fatalf("Expected the state to be set, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	var entries []logbookEntry
+	getJSON(t, r, "/api/logbook/"+url.PathEscape(start), &entries)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.EntityID+"="+e.State)
+	}
+	want := []string{"light.kitchen=on", "lock.front_door=locked", "light.kitchen=off"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+
+	getJSON(t, r, "/api/logbook/"+url.PathEscape(start)+"?entity=lock.front_door", &entries)
+	if len(entries) != 1 || entries[0].Name == "" {
+		t.Errorf("Expected the lock's one entry with a name, got %+v", entries)
+	}
+
+	getJSON(t, r, "/api/logbook/"+url.PathEscape(start)+"?end_time="+url.QueryEscape(start), &entries)
+	if len(entries) != 0 {
+		t.Errorf("Expected nothing before end_time, got %+v", entries)
+	}
+}
+
+func TestLogbookIncludesSeededEnergyReadings(t *testing.T) {
+	_, r := seededPlugin(t)
+
+	var entries []logbookEntry
+	getJSON(t, r, "/api/logbook?entity=sensor.grid_consumption", &entries)
+	if len(entries) == 0 {
+		t.Fatal("Expected seeded grid readings in the logbook")
+	}
+	for _, e := range entries {
+		if e.Name != "Grid Consumption" {
+			t.Errorf("Expected the friendly name, got %+v", e)
+		}
+	}
+}
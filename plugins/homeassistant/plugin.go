@@ -46,7 +46,8 @@ func getInstanceFromContext(ctx context.Context) (*Instance, bool) {
 }
 
 type HomeAssistantPlugin struct {
-	store *Store
+	store  *Store
+	events eventBus
 }
 
 func (p *HomeAssistantPlugin) Name() string {
@@ -63,11 +64,20 @@ func (p *HomeAssistantPlugin) Health() core.HealthStatus {
 func (p *HomeAssistantPlugin) RegisterRoutes(r chi.Router) {
 	// Home Assistant API endpoints
 	r.Get("/api/", p.requireAuth(p.handleAPIRoot))
+	r.Get("/api/config", p.requireAuth(p.handleGetConfig))
+	r.Get("/api/config/area_registry", p.requireAuth(p.handleRegistry("area")))
+	r.Get("/api/config/device_registry", p.requireAuth(p.handleRegistry("device")))
+	r.Get("/api/config/entity_registry", p.requireAuth(p.handleRegistry("entity")))
+	r.Get("/api/discovery_info", p.requireAuth(p.handleDiscoveryInfo))
 	r.Get("/api/states", p.requireAuth(p.handleGetAllStates))
 	r.Get("/api/states/{entity_id}", p.requireAuth(p.handleGetState))
 	r.Post("/api/states/{entity_id}", p.requireAuth(p.handleSetState))
 	r.Post("/api/services/{domain}/{service}", p.requireAuth(p.handleCallService))
+	r.Get("/api/events", p.requireAuth(p.handleListEvents))
 	r.Post("/api/events/{event_type}", p.requireAuth(p.handleFireEvent))
+	r.Get("/api/logbook", p.requireAuth(p.handleLogbook))
+	r.Get("/api/logbook/{timestamp}", p.requireAuth(p.handleLogbook))
+	r.Post("/api/recorder/statistics_during_period", p.requireAuth(p.handleStatisticsDuringPeriod))
 
 	// WebSocket API endpoint
 	r.Get("/api/websocket", p.handleWebSocket)
@@ -83,6 +93,33 @@ func (p *HomeAssistantPlugin) Schema() core.PluginSchema {
 	}
 }
 
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *HomeAssistantPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "homeassistant", Table: "homeassistant_entities", Resource: "entities"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *HomeAssistantPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"homeassistant": {"homeassistant_events", "homeassistant_service_calls", "homeassistant_states", "homeassistant_entity_registry", "homeassistant_devices", "homeassistant_areas", "homeassistant_entities", "homeassistant_instances"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *HomeAssistantPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *HomeAssistantPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
+
 func (p *HomeAssistantPlugin) ValidateToken(token string) bool {
 	// Development mode: Accept tokens with 'token_' prefix
 	// In production, this should query the database
@@ -243,6 +280,14 @@ func (p *HomeAssistantPlugin) handleGetState(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stateToResponse(state)); err != nil {
+		log.Printf("Error encoding state response: %v", err)
+	}
+}
+
+// stateToResponse formats a state row as GET /api/states/{entity_id} returns it
+func stateToResponse(state State) map[string]interface{} {
 	var attributes map[string]interface{}
 	if state.Attributes != "" {
 		if err := json.Unmarshal([]byte(state.Attributes), &attributes); err != nil {
@@ -251,18 +296,13 @@ func (p *HomeAssistantPlugin) handleGetState(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	response := map[string]interface{}{
+	return map[string]interface{}{
 		"entity_id":    state.EntityID,
 		"state":        state.State,
 		"attributes":   attributes,
 		"last_changed": state.LastChanged.Format(time.RFC3339),
 		"last_updated": state.LastUpdated.Format(time.RFC3339),
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Error encoding state response: %v", err)
-	}
 }
 
 // handleSetState sets or updates an entity state
@@ -319,6 +359,12 @@ func (p *HomeAssistantPlugin) handleSetState(w http.ResponseWriter, r *http.Requ
 		log.Printf("Error creating/updating entity: %v", err)
 	}
 
+	oldState, err := p.store.GetLatestState(instance.ID, entityID)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	// Record state
 	now := time.Now()
 	err = p.store.RecordState(instance.ID, entityID, req.State, string(attributesJSON), now, now)
@@ -327,6 +373,17 @@ func (p *HomeAssistantPlugin) handleSetState(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if oldState == nil || oldState.State != req.State || oldState.Attributes != string(attributesJSON) {
+		p.fireStateChanged(instance.ID, entityID, oldState, &State{
+			InstanceID:  instance.ID,
+			EntityID:    entityID,
+			State:       req.State,
+			Attributes:  string(attributesJSON),
+			LastChanged: now,
+			LastUpdated: now,
+		})
+	}
+
 	response := map[string]interface{}{
 		"entity_id":    entityID,
 		"state":        req.State,
@@ -393,6 +450,24 @@ func (p *HomeAssistantPlugin) handleCallService(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	callData := map[string]interface{}{
+		"domain":       domain,
+		"service":      service,
+		"service_data": req.ServiceData,
+	}
+	if err := p.fireEvent(instance.ID, "call_service", callData, originLocal); err != nil {
+		log.Printf("Error firing call_service event: %v", err)
+	}
+
+	// Services like turn_on change the target entity's state
+	if req.EntityID != "" {
+		if _, err := p.applyServiceState(instance.ID, service, req.EntityID); err != nil {
+			log.Printf("Error applying %s.%s to %s: %v", domain, service, req.EntityID, err)
+			http.Error(w, "Failed to update entity state", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	response := []map[string]interface{}{
 		{
 			"entity_id": req.EntityID,
@@ -433,8 +508,11 @@ func (p *HomeAssistantPlugin) handleFireEvent(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	// For now, just log events (could add events table later)
-	log.Printf("Event fired on instance %s: %s with data: %v", instance.Name, eventType, eventData)
+	if err := p.fireEvent(instance.ID, eventType, eventData, originRemote); err != nil {
+		log.Printf("Error firing event %s: %v", eventType, err)
+		http.Error(w, "Failed to fire event", http.StatusInternalServerError)
+		return
+	}
 
 	response := map[string]interface{}{
 		"message": "Event " + eventType + " fired.",
@@ -473,6 +551,30 @@ func (p *HomeAssistantPlugin) ListResources(ctx context.Context, slug string, op
 			return nil, err
 		}
 		return convertServiceCallsToMaps(calls), nil
+	case "events":
+		events, err := p.store.ListAllEvents(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		return convertEventsToMaps(events), nil
+	case "areas":
+		areas, err := p.store.ListAllAreas(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		return convertAreasToMaps(areas), nil
+	case "devices":
+		devices, err := p.store.ListAllDevices(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		return convertDevicesToMaps(devices), nil
+	case "entity_registry":
+		entries, err := p.store.ListAllEntityRegistry(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		return convertRegistryEntriesToMaps(entries), nil
 	default:
 		return nil, fmt.Errorf("unknown resource: %s", slug)
 	}
@@ -566,3 +668,67 @@ func convertServiceCallToMap(call ServiceCall) map[string]interface{} {
 		"created_at":   call.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 }
+
+func convertEventsToMaps(events []Event) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(events))
+	for i, ev := range events {
+		result[i] = map[string]interface{}{
+			"id":          ev.ID,
+			"instance_id": ev.InstanceID,
+			"event_type":  ev.EventType,
+			"event_data":  ev.EventData,
+			"origin":      ev.Origin,
+			"time_fired":  ev.TimeFired.Format("2006-01-02T15:04:05Z"),
+			"created_at":  ev.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		}
+	}
+	return result
+}
+
+func convertAreasToMaps(areas []Area) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(areas))
+	for i, area := range areas {
+		result[i] = map[string]interface{}{
+			"id":          area.ID,
+			"instance_id": area.InstanceID,
+			"area_id":     area.AreaID,
+			"name":        area.Name,
+			"aliases":     strings.Join(area.Aliases, ", "),
+			"created_at":  area.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		}
+	}
+	return result
+}
+
+func convertDevicesToMaps(devices []Device) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(devices))
+	for i, device := range devices {
+		result[i] = map[string]interface{}{
+			"id":           device.ID,
+			"instance_id":  device.InstanceID,
+			"device_id":    device.DeviceID,
+			"name":         device.Name,
+			"area_id":      device.AreaID,
+			"manufacturer": device.Manufacturer,
+			"model":        device.Model,
+			"created_at":   device.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		}
+	}
+	return result
+}
+
+func convertRegistryEntriesToMaps(entries []RegistryEntry) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		result[i] = map[string]interface{}{
+			"id":            entry.ID,
+			"instance_id":   entry.InstanceID,
+			"entity_id":     entry.EntityID,
+			"friendly_name": entry.FriendlyName,
+			"platform":      entry.Platform,
+			"device_id":     entry.DeviceID,
+			"area_id":       entry.AreaID,
+		}
+	}
+	return result
+}
@@ -0,0 +1,410 @@
+// ABOUTME: Home Assistant area, device, and entity registries
+// ABOUTME: Stores rooms and the devices in them, and serves the registry lists dashboards use to group entities
+package homeassistant
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// Area is a room or zone that devices and entities are assigned to
+type Area struct {
+	ID         int64     `json:"id"`
+	InstanceID int64     `json:"instance_id"`
+	AreaID     string    `json:"area_id"`
+	Name       string    `json:"name"`
+	Aliases    []string  `json:"aliases"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Device is a physical or virtual device that provides entities
+type Device struct {
+	ID           int64     `json:"id"`
+	InstanceID   int64     `json:"instance_id"`
+	DeviceID     string    `json:"device_id"`
+	Name         string    `json:"name"`
+	AreaID       string    `json:"area_id"`
+	Manufacturer string    `json:"manufacturer"`
+	Model        string    `json:"model"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RegistryEntry is an entity with the device it belongs to. AreaID is only
+// set when the entity overrides its device's area, as in Home Assistant.
+type RegistryEntry struct {
+	ID           int64  `json:"id"`
+	InstanceID   int64  `json:"instance_id"`
+	EntityID     string `json:"entity_id"`
+	FriendlyName string `json:"friendly_name"`
+	Platform     string `json:"platform"`
+	DeviceID     string `json:"device_id"`
+	AreaID       string `json:"area_id"`
+}
+
+// CreateOrUpdateArea creates or renames an area
+func (s *Store) CreateOrUpdateArea(instanceID int64, areaID, name string, aliases []string) error {
+	if aliases == nil {
+		aliases = []string{}
+	}
+	aliasesJSON, err := json.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO homeassistant_areas (instance_id, area_id, name, aliases, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(instance_id, area_id) DO UPDATE SET
+				name = excluded.name,
+				aliases = excluded.aliases
+		`, instanceID, areaID, name, string(aliasesJSON), time.Now())
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, instanceID, "area", areaID, core.AuditUpdate, map[string]any{"instance_id": instanceID, "name": name, "aliases": aliases})
+	})
+}
+
+// CreateOrUpdateDevice creates or updates a device and the area it is in
+func (s *Store) CreateOrUpdateDevice(instanceID int64, deviceID, name, areaID, manufacturer, model string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO homeassistant_devices (instance_id, device_id, name, area_id, manufacturer, model, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(instance_id, device_id) DO UPDATE SET
+				name = excluded.name,
+				area_id = excluded.area_id,
+				manufacturer = excluded.manufacturer,
+				model = excluded.model
+		`, instanceID, deviceID, name, nilIfEmpty(areaID), manufacturer, model, time.Now())
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, instanceID, "device", deviceID, core.AuditUpdate, map[string]any{
+			"instance_id":  instanceID,
+			"name":         name,
+			"area_id":      areaID,
+			"manufacturer": manufacturer,
+			"model":        model,
+		})
+	})
+}
+
+// RegisterEntity links an entity to its device, optionally overriding the device's area
+func (s *Store) RegisterEntity(instanceID int64, entityID, deviceID, areaID string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO homeassistant_entity_registry (instance_id, entity_id, device_id, area_id, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(instance_id, entity_id) DO UPDATE SET
+				device_id = excluded.device_id,
+				area_id = excluded.area_id
+		`, instanceID, entityID, nilIfEmpty(deviceID), nilIfEmpty(areaID), time.Now())
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, instanceID, "entity_registry", entityID, core.AuditUpdate, map[string]any{
+			"instance_id": instanceID,
+			"device_id":   deviceID,
+			"area_id":     areaID,
+		})
+	})
+}
+
+// ListAreasByInstance returns an instance's areas sorted by name
+func (s *Store) ListAreasByInstance(instanceID int64) ([]Area, error) {
+	return s.queryAreas(`
+		SELECT id, instance_id, area_id, name, aliases, created_at
+		FROM homeassistant_areas
+		WHERE instance_id = ?
+		ORDER BY name
+	`, instanceID)
+}
+
+// ListAllAreas retrieves all areas for admin view
+func (s *Store) ListAllAreas(limit, offset int) ([]Area, error) {
+	return s.queryAreas(`
+		SELECT id, instance_id, area_id, name, aliases, created_at
+		FROM homeassistant_areas
+		ORDER BY instance_id, name
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+}
+
+func (s *Store) queryAreas(query string, args ...interface{}) ([]Area, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	areas := []Area{}
+	for rows.Next() {
+		var area Area
+		var aliases string
+		if err := rows.Scan(&area.ID, &area.InstanceID, &area.AreaID, &area.Name, &aliases, &area.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(aliases), &area.Aliases); err != nil || area.Aliases == nil {
+			area.Aliases = []string{}
+		}
+		areas = append(areas, area)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return areas, nil
+}
+
+// ListDevicesByInstance returns an instance's devices sorted by name
+func (s *Store) ListDevicesByInstance(instanceID int64) ([]Device, error) {
+	return s.queryDevices(`
+		SELECT id, instance_id, device_id, name, area_id, manufacturer, model, created_at
+		FROM homeassistant_devices
+		WHERE instance_id = ?
+		ORDER BY name
+	`, instanceID)
+}
+
+// ListAllDevices retrieves all devices for admin view
+func (s *Store) ListAllDevices(limit, offset int) ([]Device, error) {
+	return s.queryDevices(`
+		SELECT id, instance_id, device_id, name, area_id, manufacturer, model, created_at
+		FROM homeassistant_devices
+		ORDER BY instance_id, name
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+}
+
+func (s *Store) queryDevices(query string, args ...interface{}) ([]Device, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []Device{}
+	for rows.Next() {
+		var device Device
+		var areaID, manufacturer, model sql.NullString
+		err := rows.Scan(&device.ID, &device.InstanceID, &device.DeviceID, &device.Name, &areaID, &manufacturer, &model, &device.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		device.AreaID = areaID.String
+		device.Manufacturer = manufacturer.String
+		device.Model = model.String
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// ListEntityRegistryByInstance returns every entity on an instance with its
+// registry links. Entities that were never registered have no device.
+func (s *Store) ListEntityRegistryByInstance(instanceID int64) ([]RegistryEntry, error) {
+	return s.queryEntityRegistry(`
+		SELECT e.id, e.instance_id, e.entity_id, e.friendly_name, e.platform, r.device_id, r.area_id
+		FROM homeassistant_entities e
+		LEFT JOIN homeassistant_entity_registry r ON r.instance_id = e.instance_id AND r.entity_id = e.entity_id
+		WHERE e.instance_id = ?
+		ORDER BY e.entity_id
+	`, instanceID)
+}
+
+// ListAllEntityRegistry retrieves all entity registry entries for admin view
+func (s *Store) ListAllEntityRegistry(limit, offset int) ([]RegistryEntry, error) {
+	return s.queryEntityRegistry(`
+		SELECT e.id, e.instance_id, e.entity_id, e.friendly_name, e.platform, r.device_id, r.area_id
+		FROM homeassistant_entities e
+		LEFT JOIN homeassistant_entity_registry r ON r.instance_id = e.instance_id AND r.entity_id = e.entity_id
+		ORDER BY e.instance_id, e.entity_id
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+}
+
+func (s *Store) queryEntityRegistry(query string, args ...interface{}) ([]RegistryEntry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []RegistryEntry{}
+	for rows.Next() {
+		var entry RegistryEntry
+		var friendlyName, platform, deviceID, areaID sql.NullString
+		err := rows.Scan(&entry.ID, &entry.InstanceID, &entry.EntityID, &friendlyName, &platform, &deviceID, &areaID)
+		if err != nil {
+			return nil, err
+		}
+		entry.FriendlyName = friendlyName.String
+		entry.Platform = platform.String
+		entry.DeviceID = deviceID.String
+		entry.AreaID = areaID.String
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// areaToResponse formats an area as the area registry returns it
+func areaToResponse(area Area) map[string]interface{} {
+	return map[string]interface{}{
+		"area_id":  area.AreaID,
+		"name":     area.Name,
+		"aliases":  area.Aliases,
+		"floor_id": nil,
+		"icon":     nil,
+		"labels":   []string{},
+		"picture":  nil,
+	}
+}
+
+// deviceToResponse formats a device as the device registry returns it
+func deviceToResponse(device Device) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             device.DeviceID,
+		"area_id":        nilIfEmpty(device.AreaID),
+		"config_entries": []string{},
+		"disabled_by":    nil,
+		"entry_type":     nil,
+		"identifiers":    [][]string{},
+		"manufacturer":   nilIfEmpty(device.Manufacturer),
+		"model":          nilIfEmpty(device.Model),
+		"name":           device.Name,
+		"name_by_user":   nil,
+		"sw_version":     nil,
+		"via_device_id":  nil,
+	}
+}
+
+// registryEntryToResponse formats an entity as the entity registry returns it
+func registryEntryToResponse(entry RegistryEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              fmt.Sprint(entry.ID),
+		"entity_id":       entry.EntityID,
+		"unique_id":       entry.EntityID,
+		"platform":        entry.Platform,
+		"name":            nil,
+		"original_name":   nilIfEmpty(entry.FriendlyName),
+		"device_id":       nilIfEmpty(entry.DeviceID),
+		"area_id":         nilIfEmpty(entry.AreaID),
+		"config_entry_id": nil,
+		"disabled_by":     nil,
+		"entity_category": nil,
+		"hidden_by":       nil,
+		"icon":            nil,
+	}
+}
+
+// registryList returns one of the registries for an instance in Home Assistant's format
+func (p *HomeAssistantPlugin) registryList(instanceID int64, registry string) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	switch registry {
+	case "area":
+		areas, err := p.store.ListAreasByInstance(instanceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, area := range areas {
+			result = append(result, areaToResponse(area))
+		}
+	case "device":
+		devices, err := p.store.ListDevicesByInstance(instanceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, device := range devices {
+			result = append(result, deviceToResponse(device))
+		}
+	case "entity":
+		entries, err := p.store.ListEntityRegistryByInstance(instanceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			result = append(result, registryEntryToResponse(entry))
+		}
+	default:
+		return nil, fmt.Errorf("unknown registry: %s", registry)
+	}
+	if result == nil {
+		result = []map[string]interface{}{}
+	}
+	return result, nil
+}
+
+// handleRegistry returns a handler for GET /api/config/{registry}_registry
+func (p *HomeAssistantPlugin) handleRegistry(registry string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instance, ok := getInstanceFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		result, err := p.registryList(instance.ID, registry)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Printf("Error encoding %s registry response: %v", registry, err)
+		}
+	}
+}
+
+// handleWSRegistryList answers config/{registry}_registry/list over the WebSocket API
+func (p *HomeAssistantPlugin) handleWSRegistryList(client *WSClient, msg WSMessage, registry string) {
+	client.mu.RLock()
+	instance := client.instance
+	client.mu.RUnlock()
+
+	result, err := p.registryList(instance.ID, registry)
+	if err != nil {
+		client.sendMessage(WSMessage{
+			Type:    "result",
+			ID:      msg.ID,
+			Success: false,
+			Error: &WSError{
+				Code:    "internal_error",
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	client.sendMessage(WSMessage{
+		Type:    "result",
+		ID:      msg.ID,
+		Success: true,
+		Result:  result,
+	})
+}
+
+// nilIfEmpty returns nil for an empty string so it is stored as NULL and encoded as null
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
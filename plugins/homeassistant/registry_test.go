@@ -0,0 +1,113 @@
+// ABOUTME: Tests for the Home Assistant area, device, and entity registry endpoints
+// ABOUTME: Verifies seeded rooms and devices and the links from entities to their devices
+package homeassistant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistriesListSeededHome(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	if _, err := p.Seed(context.Background(), "medium"); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+
+	var areas []struct {
+		AreaID  string   `json:"area_id"`
+		Name    string   `json:"name"`
+		Aliases []string `json:"aliases"`
+	}
+	getJSON(t, r, "/api/config/area_registry", &areas)
+	names := map[string]string{}
+	for _, area := range areas {
+		names[area.AreaID] = area.Name
+	}
+	for _, id := range []string{"living_room", "bedroom", "kitchen"} {
+		if names[id] == "" {
+			t.Errorf("Expected area %q, got %+v", id, areas)
+		}
+	}
+
+	var devices []struct {
+		ID           string  `json:"id"`
+		AreaID       *string `json:"area_id"`
+		Manufacturer string  `json:"manufacturer"`
+	}
+	getJSON(t, r, "/api/config/device_registry", &devices)
+	deviceAreas := map[string]string{}
+	for _, device := range devices {
+		if device.AreaID == nil || device.Manufacturer == "" {
+			t.Errorf("Expected every seeded device to have an area and manufacturer, got %+v", device)
+			continue
+		}
+		deviceAreas[device.ID] = *device.AreaID
+	}
+	if deviceAreas["zooz_kitchen_plug"] != "kitchen" {
+		t.Errorf("Expected the kitchen plug in the kitchen, got %v", deviceAreas)
+	}
+
+	var entities []struct {
+		EntityID string  `json:"entity_id"`
+		DeviceID *string `json:"device_id"`
+		AreaID   *string `json:"area_id"`
+	}
+	getJSON(t, r, "/api/config/entity_registry", &entities)
+	if len(entities) != 6+len(energySensors) {
+		t.Fatalf("Expected the 6 seeded entities and the energy sensors, got %d", len(entities))
+	}
+	for _, entity := range entities {
+		if entity.DeviceID == nil || deviceAreas[*entity.DeviceID] == "" {
+			t.Errorf("Expected %s to link to a seeded device, got %v", entity.EntityID, entity.DeviceID)
+		}
+		if entity.AreaID != nil {
+			t.Errorf("Expected %s to follow its device's area, got %q", entity.EntityID, *entity.AreaID)
+		}
+	}
+}
+
+func TestEntityRegistryIncludesUnregisteredEntities(t *testing.T) {
+	p, r := setupTestPlugin(t)
+	instance, err := p.store.CreateInstance("http://ha.local:8123", "token_home_main", "Home")
+	if err != nil {
+		t.Fatalf("CreateInstance failed: %v", err)
+	}
+	if err := p.store.CreateOrUpdateArea(instance.ID, "office", "Office", nil); err != nil {
+		t.Fatalf("CreateOrUpdateArea failed: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/api/states/vacuum.downstairs", strings.NewReader(`{"state":"docked"}`))
+	req.Header.Set("Authorization", "Bearer token_home_main")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var entities []struct {
+		EntityID string  `json:"entity_id"`
+		DeviceID *string `json:"device_id"`
+		AreaID   *string `json:"area_id"`
+	}
+	getJSON(t, r, "/api/config/entity_registry", &entities)
+	if len(entities) != 1 || entities[0].EntityID != "vacuum.downstairs" || entities[0].DeviceID != nil {
+		t.Fatalf("Expected vacuum.downstairs without a device, got %+v", entities)
+	}
+
+	if err := p.store.RegisterEntity(instance.ID, "vacuum.downstairs", "", "office"); err != nil {
+		t.Fatalf("RegisterEntity failed: %v", err)
+	}
+	getJSON(t, r, "/api/config/entity_registry", &entities)
+	if entities[0].AreaID == nil || *entities[0].AreaID != "office" {
+		t.Errorf("Expected the office area override, got %+v", entities[0])
+	}
+
+	var devices []map[string]any
+	getJSON(t, r, "/api/config/device_registry", &devices)
+	if len(devices) != 0 {
+		t.Errorf("Expected an empty device registry, got %v", devices)
+	}
+}
@@ -64,5 +64,61 @@ func GetResourceSchemas() []core.ResourceSchema {
 			},
 			ListColumns: []string{"domain", "service", "entity_id", "status", "called_at"},
 		},
+		{
+			Name: "Events",
+			Slug: "events",
+			Fields: []core.FieldSchema{
+				{Name: "id", Type: "integer", Display: "ID"},
+				{Name: "instance_id", Type: "integer", Display: "Instance ID"},
+				{Name: "event_type", Type: "string", Display: "Event Type"},
+				{Name: "event_data", Type: "text", Display: "Event Data"},
+				{Name: "origin", Type: "string", Display: "Origin"},
+				{Name: "time_fired", Type: "datetime", Display: "Time Fired"},
+				{Name: "created_at", Type: "datetime", Display: "Created"},
+			},
+			ListColumns: []string{"event_type", "origin", "time_fired"},
+		},
+		{
+			Name: "Areas",
+			Slug: "areas",
+			Fields: []core.FieldSchema{
+				{Name: "id", Type: "integer", Display: "ID"},
+				{Name: "instance_id", Type: "integer", Display: "Instance ID"},
+				{Name: "area_id", Type: "string", Display: "Area ID"},
+				{Name: "name", Type: "string", Display: "Name"},
+				{Name: "aliases", Type: "string", Display: "Aliases"},
+				{Name: "created_at", Type: "datetime", Display: "Created"},
+			},
+			ListColumns: []string{"area_id", "name", "aliases"},
+		},
+		{
+			Name: "Devices",
+			Slug: "devices",
+			Fields: []core.FieldSchema{
+				{Name: "id", Type: "integer", Display: "ID"},
+				{Name: "instance_id", Type: "integer", Display: "Instance ID"},
+				{Name: "device_id", Type: "string", Display: "Device ID"},
+				{Name: "name", Type: "string", Display: "Name"},
+				{Name: "area_id", Type: "string", Display: "Area"},
+				{Name: "manufacturer", Type: "string", Display: "Manufacturer"},
+				{Name: "model", Type: "string", Display: "Model"},
+				{Name: "created_at", Type: "datetime", Display: "Created"},
+			},
+			ListColumns: []string{"name", "area_id", "manufacturer", "model"},
+		},
+		{
+			Name: "Entity Registry",
+			Slug: "entity_registry",
+			Fields: []core.FieldSchema{
+				{Name: "id", Type: "integer", Display: "ID"},
+				{Name: "instance_id", Type: "integer", Display: "Instance ID"},
+				{Name: "entity_id", Type: "string", Display: "Entity ID"},
+				{Name: "friendly_name", Type: "string", Display: "Friendly Name"},
+				{Name: "platform", Type: "string", Display: "Platform"},
+				{Name: "device_id", Type: "string", Display: "Device"},
+				{Name: "area_id", Type: "string", Display: "Area Override"},
+			},
+			ListColumns: []string{"entity_id", "device_id", "area_id", "platform"},
+		},
 	}
 }
@@ -1,5 +1,5 @@
 // ABOUTME: Test data generation for Home Assistant plugin
-// ABOUTME: Creates sample instances, entities, states, and service calls
+// ABOUTME: Creates sample instances, rooms, devices, entities, states, and service calls
 package homeassistant
 
 import (
@@ -47,23 +47,74 @@ func (p *HomeAssistantPlugin) Seed(ctx context.Context, size string) (core.SeedD
 		instanceIDs = append(instanceIDs, instance.ID)
 	}
 
-	// Create sample entities for each instance
+	// Create the rooms of the house and the devices in them
+	areas := []struct {
+		areaID  string
+		name    string
+		aliases []string
+	}{
+		{"living_room", "Living Room", []string{"Lounge"}},
+		{"bedroom", "Bedroom", []string{"Master Bedroom"}},
+		{"kitchen", "Kitchen", nil},
+		{"hallway", "Hallway", []string{"Entryway"}},
+		{"garage", "Garage", nil},
+	}
+	devices := []struct {
+		deviceID     string
+		name         string
+		areaID       string
+		manufacturer string
+		model        string
+	}{
+		{"hue_living_room", "Living Room Hue Bulb", "living_room", "Signify Netherlands B.V.", "Hue White and Color Ambiance A19"},
+		{"hue_bedroom", "Bedroom Hue Bulb", "bedroom", "Signify Netherlands B.V.", "Hue White Ambiance A19"},
+		{"zooz_kitchen_plug", "Kitchen Smart Plug", "kitchen", "Zooz", "ZEN15 Power Switch"},
+		{"aeotec_living_room", "Living Room Multisensor", "living_room", "Aeotec", "MultiSensor 7"},
+		{"aqara_bedroom", "Bedroom Climate Sensor", "bedroom", "Aqara", "Temperature and Humidity Sensor"},
+		{"nest_thermostat", "Nest Thermostat", "hallway", "Google", "Nest Learning Thermostat"},
+		{"august_front_door", "Front Door Lock", "hallway", "August", "Wi-Fi Smart Lock"},
+		{"zooz_hallway_motion", "Hallway Motion Sensor", "hallway", "Zooz", "ZSE18 Motion Sensor"},
+		{"myq_garage", "Garage Door Opener", "garage", "Chamberlain", "myQ Smart Garage Hub"},
+		{"roku_living_room", "Living Room TV", "living_room", "Roku", "Roku Ultra"},
+		{"smart_meter", "Smart Meter", "garage", "Itron", "OpenWay Riva"},
+		{"solar_inverter", "Solar Inverter", "garage", "Enphase", "IQ Gateway"},
+		{"home_battery", "Home Battery", "garage", "Tesla", "Powerwall 2"},
+	}
+
+	totalAreas, totalDevices := 0, 0
+	for _, instanceID := range instanceIDs {
+		for _, area := range areas {
+			if err := p.store.CreateOrUpdateArea(instanceID, area.areaID, area.name, area.aliases); err != nil {
+				return core.SeedData{}, fmt.Errorf("failed to create area %s: %w", area.areaID, err)
+			}
+			totalAreas++
+		}
+		for _, dev := range devices {
+			if err := p.store.CreateOrUpdateDevice(instanceID, dev.deviceID, dev.name, dev.areaID, dev.manufacturer, dev.model); err != nil {
+				return core.SeedData{}, fmt.Errorf("failed to create device %s: %w", dev.deviceID, err)
+			}
+			totalDevices++
+		}
+	}
+
+	// Create sample entities for each instance, each provided by a device
 	entities := []struct {
 		entityID     string
 		friendlyName string
 		domain       string
 		platform     string
+		deviceID     string
 	}{
-		{"light.living_room", "Living Room Light", "light", "hue"},
-		{"light.bedroom", "Bedroom Light", "light", "hue"},
-		{"switch.kitchen_outlet", "Kitchen Outlet", "switch", "zwave"},
-		{"sensor.temperature_living_room", "Living Room Temperature", "sensor", "mqtt"},
-		{"sensor.humidity_bedroom", "Bedroom Humidity", "sensor", "mqtt"},
-		{"climate.thermostat", "Main Thermostat", "climate", "nest"},
-		{"lock.front_door", "Front Door Lock", "lock", "august"},
-		{"binary_sensor.motion_hallway", "Hallway Motion", "binary_sensor", "zwave"},
-		{"cover.garage_door", "Garage Door", "cover", "myq"},
-		{"media_player.living_room_tv", "Living Room TV", "media_player", "roku"},
+		{"light.living_room", "Living Room Light", "light", "hue", "hue_living_room"},
+		{"light.bedroom", "Bedroom Light", "light", "hue", "hue_bedroom"},
+		{"switch.kitchen_outlet", "Kitchen Outlet", "switch", "zwave", "zooz_kitchen_plug"},
+		{"sensor.temperature_living_room", "Living Room Temperature", "sensor", "mqtt", "aeotec_living_room"},
+		{"sensor.humidity_bedroom", "Bedroom Humidity", "sensor", "mqtt", "aqara_bedroom"},
+		{"climate.thermostat", "Main Thermostat", "climate", "nest", "nest_thermostat"},
+		{"lock.front_door", "Front Door Lock", "lock", "august", "august_front_door"},
+		{"binary_sensor.motion_hallway", "Hallway Motion", "binary_sensor", "zwave", "zooz_hallway_motion"},
+		{"cover.garage_door", "Garage Door", "cover", "myq", "myq_garage"},
+		{"media_player.living_room_tv", "Living Room TV", "media_player", "roku", "roku_living_room"},
 	}
 
 	totalEntities := 0
@@ -74,6 +125,19 @@ func (p *HomeAssistantPlugin) Seed(ctx context.Context, size string) (core.SeedD
 			if err != nil {
 				return core.SeedData{}, fmt.Errorf("failed to create entity %s: %w", ent.entityID, err)
 			}
+			if err := p.store.RegisterEntity(instanceID, ent.entityID, ent.deviceID, ""); err != nil {
+				return core.SeedData{}, fmt.Errorf("failed to register entity %s: %w", ent.entityID, err)
+			}
+			totalEntities++
+		}
+		// Every instance monitors its energy use
+		for _, sensor := range energySensors {
+			if err := p.store.CreateOrUpdateEntity(instanceID, sensor.entityID, sensor.friendlyName, "sensor", "energy"); err != nil {
+				return core.SeedData{}, fmt.Errorf("failed to create entity %s: %w", sensor.entityID, err)
+			}
+			if err := p.store.RegisterEntity(instanceID, sensor.entityID, sensor.deviceID, ""); err != nil {
+				return core.SeedData{}, fmt.Errorf("failed to register entity %s: %w", sensor.entityID, err)
+			}
 			totalEntities++
 		}
 	}
@@ -156,6 +220,11 @@ func (p *HomeAssistantPlugin) Seed(ctx context.Context, size string) (core.SeedD
 				totalStates++
 			}
 		}
+		recorded, err := p.seedEnergyStates(instanceID, numStatesPerEntity)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to record energy states: %w", err)
+		}
+		totalStates += recorded
 	}
 
 	// Create sample service calls
@@ -243,10 +312,12 @@ func (p *HomeAssistantPlugin) Seed(ctx context.Context, size string) (core.SeedD
 	}
 
 	return core.SeedData{
-		Summary: fmt.Sprintf("Created %d instances, %d entities, %d states, %d service calls",
-			len(instanceIDs), totalEntities, totalStates, totalServiceCalls),
+		Summary: fmt.Sprintf("Created %d instances, %d areas, %d devices, %d entities, %d states, %d service calls",
+			len(instanceIDs), totalAreas, totalDevices, totalEntities, totalStates, totalServiceCalls),
 		Records: map[string]int{
 			"instances":     len(instanceIDs),
+			"areas":         totalAreas,
+			"devices":       totalDevices,
 			"entities":      totalEntities,
 			"states":        totalStates,
 			"service_calls": totalServiceCalls,
@@ -1,11 +1,13 @@
 // ABOUTME: Home Assistant plugin database store layer
-// ABOUTME: Manages instances, entities, states, and service calls in SQLite
+// ABOUTME: Manages instances, entities, states, service calls, events, and registries in SQLite
 package homeassistant
 
 import (
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 // Store handles Home Assistant data persistence
@@ -13,13 +15,40 @@ type Store struct {
 	db *sql.DB
 }
 
+// auditIn records a mutation of a Home Assistant resource in the shared audit
+// log through tx, the transaction making the change so both commit together.
+// The actor is the name of the instance whose token made the change.
+func auditIn(tx *sql.Tx, instanceID int64, resourceType, resourceID, action string, changes any) error {
+	var actor string
+	if err := tx.QueryRow(`SELECT name FROM homeassistant_instances WHERE id = ?`, instanceID).Scan(&actor); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	return core.LogAudit(tx, core.AuditEntry{
+		PluginName:   "homeassistant",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
 // NewStore creates a new Home Assistant store
 func NewStore(db *sql.DB) (*Store, error) {
-	store := &Store{db: db}
-	if err := store.initSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	return &Store{db: db}, nil
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *Store) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
 	}
-	return store, nil
+	return tx.Commit()
 }
 
 // Instance represents a Home Assistant instance
@@ -69,77 +98,34 @@ type ServiceCall struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
-func (s *Store) initSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS homeassistant_instances (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		url TEXT NOT NULL,
-		token TEXT NOT NULL,
-		name TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS homeassistant_entities (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		instance_id INTEGER NOT NULL,
-		entity_id TEXT NOT NULL,
-		friendly_name TEXT,
-		domain TEXT NOT NULL,
-		platform TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id),
-		UNIQUE(instance_id, entity_id)
-	);
-
-	CREATE TABLE IF NOT EXISTS homeassistant_states (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		instance_id INTEGER NOT NULL,
-		entity_id TEXT NOT NULL,
-		state TEXT NOT NULL,
-		attributes TEXT, -- JSON
-		last_changed DATETIME NOT NULL,
-		last_updated DATETIME NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id)
-	);
-
-	CREATE TABLE IF NOT EXISTS homeassistant_service_calls (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		instance_id INTEGER NOT NULL,
-		domain TEXT NOT NULL,
-		service TEXT NOT NULL,
-		service_data TEXT, -- JSON
-		entity_id TEXT,
-		status TEXT NOT NULL DEFAULT 'pending',
-		called_at DATETIME NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (instance_id) REFERENCES homeassistant_instances(id)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_entities_instance ON homeassistant_entities(instance_id);
-	CREATE INDEX IF NOT EXISTS idx_states_instance ON homeassistant_states(instance_id);
-	CREATE INDEX IF NOT EXISTS idx_states_entity ON homeassistant_states(entity_id);
-	CREATE INDEX IF NOT EXISTS idx_service_calls_instance ON homeassistant_service_calls(instance_id);
-	`
-
-	_, err := s.db.Exec(schema)
-	return err
+// Event represents a fired Home Assistant event
+type Event struct {
+	ID         int64     `json:"id"`
+	InstanceID int64     `json:"instance_id"`
+	EventType  string    `json:"event_type"`
+	EventData  string    `json:"event_data"` // JSON blob
+	Origin     string    `json:"origin"`     // LOCAL or REMOTE
+	TimeFired  time.Time `json:"time_fired"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 // CreateInstance creates a new Home Assistant instance
 func (s *Store) CreateInstance(url, token, name string) (*Instance, error) {
 	now := time.Now()
-	result, err := s.db.Exec(`
-		INSERT INTO homeassistant_instances (url, token, name, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, url, token, name, now, now)
-	if err != nil {
-		return nil, err
-	}
-
-	id, err := result.LastInsertId()
+	var id int64
+	err := s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO homeassistant_instances (url, token, name, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, url, token, name, now, now)
+		if err != nil {
+			return err
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		return auditIn(tx, id, "instance", fmt.Sprint(id), core.AuditCreate, map[string]any{"url": url, "name": name})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -192,25 +178,79 @@ func (s *Store) GetInstanceByToken(token string) (*Instance, error) {
 // CreateOrUpdateEntity creates or updates an entity
 func (s *Store) CreateOrUpdateEntity(instanceID int64, entityID, friendlyName, domain, platform string) error {
 	now := time.Now()
-	_, err := s.db.Exec(`
-		INSERT INTO homeassistant_entities (instance_id, entity_id, friendly_name, domain, platform, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(instance_id, entity_id) DO UPDATE SET
-			friendly_name = excluded.friendly_name,
-			domain = excluded.domain,
-			platform = excluded.platform,
-			updated_at = excluded.updated_at
-	`, instanceID, entityID, friendlyName, domain, platform, now, now)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO homeassistant_entities (instance_id, entity_id, friendly_name, domain, platform, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(instance_id, entity_id) DO UPDATE SET
+				friendly_name = excluded.friendly_name,
+				domain = excluded.domain,
+				platform = excluded.platform,
+				updated_at = excluded.updated_at
+		`, instanceID, entityID, friendlyName, domain, platform, now, now)
+		if err != nil {
+			return err
+		}
+		// Upserts are logged as updates
+		return auditIn(tx, instanceID, "entity", entityID, core.AuditUpdate, map[string]any{
+			"instance_id":   instanceID,
+			"friendly_name": friendlyName,
+			"domain":        domain,
+			"platform":      platform,
+		})
+	})
 }
 
 // RecordState records a state for an entity
 func (s *Store) RecordState(instanceID int64, entityID, state, attributes string, lastChanged, lastUpdated time.Time) error {
-	_, err := s.db.Exec(`
-		INSERT INTO homeassistant_states (instance_id, entity_id, state, attributes, last_changed, last_updated, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, instanceID, entityID, state, attributes, lastChanged, lastUpdated, time.Now())
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO homeassistant_states (instance_id, entity_id, state, attributes, last_changed, last_updated, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, instanceID, entityID, state, attributes, lastChanged, lastUpdated, time.Now())
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, instanceID, "state", entityID, core.AuditUpdate, map[string]any{"instance_id": instanceID, "state": state})
+	})
+}
+
+// GetLatestState returns the most recent state of an entity, or sql.ErrNoRows if it has none
+func (s *Store) GetLatestState(instanceID int64, entityID string) (*State, error) {
+	var st State
+	var attributes sql.NullString
+	err := s.db.QueryRow(`
+		SELECT id, instance_id, entity_id, state, attributes, last_changed, last_updated, created_at
+		FROM homeassistant_states
+		WHERE instance_id = ? AND entity_id = ?
+		ORDER BY last_updated DESC, id DESC
+		LIMIT 1
+	`, instanceID, entityID).Scan(&st.ID, &st.InstanceID, &st.EntityID, &st.State, &attributes, &st.LastChanged, &st.LastUpdated, &st.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if attributes.Valid {
+		st.Attributes = attributes.String
+	}
+	return &st, nil
+}
+
+// GetState returns a single state row by ID
+func (s *Store) GetState(id int64) (*State, error) {
+	var st State
+	var attributes sql.NullString
+	err := s.db.QueryRow(`
+		SELECT id, instance_id, entity_id, state, attributes, last_changed, last_updated, created_at
+		FROM homeassistant_states
+		WHERE id = ?
+	`, id).Scan(&st.ID, &st.InstanceID, &st.EntityID, &st.State, &attributes, &st.LastChanged, &st.LastUpdated, &st.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if attributes.Valid {
+		st.Attributes = attributes.String
+	}
+	return &st, nil
 }
 
 // RecordServiceCall records a service call
@@ -358,6 +398,147 @@ func (s *Store) ListStatesByInstance(instanceID int64, limit, offset int) ([]Sta
 	return states, nil
 }
 
+// ListDomainsByInstance returns the distinct entity domains for an instance in sorted order
+func (s *Store) ListDomainsByInstance(instanceID int64) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT domain
+		FROM homeassistant_entities
+		WHERE instance_id = ?
+		ORDER BY domain
+	`, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []string
+	for rows.Next() {
+		var domain string
+		if err := rows.Scan(&domain); err != nil {
+			return nil, err
+		}
+		domains = append(domains, domain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return domains, nil
+}
+
+// RecordEvent stores a fired event
+func (s *Store) RecordEvent(instanceID int64, eventType, eventData, origin string, firedAt time.Time) (*Event, error) {
+	now := time.Now()
+	result, err := s.db.Exec(`
+		INSERT INTO homeassistant_events (instance_id, event_type, event_data, origin, time_fired, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, instanceID, eventType, eventData, origin, firedAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		ID:         id,
+		InstanceID: instanceID,
+		EventType:  eventType,
+		EventData:  eventData,
+		Origin:     origin,
+		TimeFired:  firedAt,
+		CreatedAt:  now,
+	}, nil
+}
+
+// ListEventTypesByInstance returns the distinct types of events fired on an instance
+func (s *Store) ListEventTypesByInstance(instanceID int64) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT event_type
+		FROM homeassistant_events
+		WHERE instance_id = ?
+		ORDER BY event_type
+	`, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var eventTypes []string
+	for rows.Next() {
+		var eventType string
+		if err := rows.Scan(&eventType); err != nil {
+			return nil, err
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return eventTypes, nil
+}
+
+// ListEventsByInstance returns the events fired on an instance, newest first,
+// optionally filtered to one event type
+func (s *Store) ListEventsByInstance(instanceID int64, eventType string, limit, offset int) ([]Event, error) {
+	query := `
+		SELECT id, instance_id, event_type, event_data, origin, time_fired, created_at
+		FROM homeassistant_events
+		WHERE instance_id = ?`
+	args := []interface{}{instanceID}
+	if eventType != "" {
+		query += " AND event_type = ?"
+		args = append(args, eventType)
+	}
+	query += " ORDER BY time_fired DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	return s.queryEvents(query, args...)
+}
+
+// ListAllEvents retrieves all events for admin view
+func (s *Store) ListAllEvents(limit, offset int) ([]Event, error) {
+	return s.queryEvents(`
+		SELECT id, instance_id, event_type, event_data, origin, time_fired, created_at
+		FROM homeassistant_events
+		ORDER BY time_fired DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+}
+
+func (s *Store) queryEvents(query string, args ...interface{}) ([]Event, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var ev Event
+		var eventData sql.NullString
+		err := rows.Scan(&ev.ID, &ev.InstanceID, &ev.EventType, &eventData, &ev.Origin, &ev.TimeFired, &ev.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if eventData.Valid {
+			ev.EventData = eventData.String
+		}
+		events = append(events, ev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
 // ListAllServiceCalls retrieves all service calls for admin view
 func (s *Store) ListAllServiceCalls(limit, offset int) ([]ServiceCall, error) {
 	rows, err := s.db.Query(`
@@ -394,3 +575,17 @@ func (s *Store) ListAllServiceCalls(limit, offset int) ([]ServiceCall, error) {
 
 	return calls, nil
 }
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *Store) Stats() (map[string]int, error) {
+	return core.CountTables(s.db,
+		"homeassistant_instances",
+		"homeassistant_entities",
+		"homeassistant_states",
+		"homeassistant_service_calls",
+		"homeassistant_events",
+		"homeassistant_areas",
+		"homeassistant_devices",
+		"homeassistant_entity_registry",
+	)
+}
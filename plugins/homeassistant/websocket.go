@@ -44,14 +44,16 @@ var upgrader = websocket.Upgrader{
 
 // WSMessage represents a Home Assistant WebSocket message
 type WSMessage struct {
-	ID          int                    `json:"id,omitempty"`
-	Type        string                 `json:"type"`
-	AccessToken string                 `json:"access_token,omitempty"`
-	HAVersion   string                 `json:"ha_version,omitempty"`
-	Result      interface{}            `json:"result,omitempty"`
-	Success     bool                   `json:"success,omitempty"`
-	Error       *WSError               `json:"error,omitempty"`
-	Event       map[string]interface{} `json:"event,omitempty"`
+	ID           int                    `json:"id,omitempty"`
+	Type         string                 `json:"type"`
+	AccessToken  string                 `json:"access_token,omitempty"`
+	HAVersion    string                 `json:"ha_version,omitempty"`
+	Result       interface{}            `json:"result,omitempty"`
+	Success      bool                   `json:"success,omitempty"`
+	Error        *WSError               `json:"error,omitempty"`
+	Event        map[string]interface{} `json:"event,omitempty"`
+	EventType    string                 `json:"event_type,omitempty"`
+	Subscription int                    `json:"subscription,omitempty"`
 }
 
 // WSError represents a WebSocket error response
@@ -96,7 +98,7 @@ func (p *HomeAssistantPlugin) handleWebSocket(w http.ResponseWriter, r *http.Req
 	// Send auth_required message
 	authRequired := WSMessage{
 		Type:      "auth_required",
-		HAVersion: "2024.1.0",
+		HAVersion: haVersion,
 	}
 	client.sendMessage(authRequired)
 }
@@ -104,7 +106,8 @@ func (p *HomeAssistantPlugin) handleWebSocket(w http.ResponseWriter, r *http.Req
 // readPump handles incoming WebSocket messages
 func (p *HomeAssistantPlugin) readPump(client *WSClient) {
 	defer func() {
-		client.cancel() // Signal writePump to stop
+		p.events.removeClient(client) // Stop event delivery before the send channel closes
+		client.cancel()               // Signal writePump to stop
 		client.closeOnce.Do(func() {
 			close(client.send)
 		})
@@ -248,6 +251,16 @@ func (p *HomeAssistantPlugin) handleWSMessage(client *WSClient, msg WSMessage) {
 		p.handleWSGetStates(client, msg)
 	case "ping":
 		p.handleWSPing(client, msg)
+	case "subscribe_events":
+		p.handleWSSubscribeEvents(client, msg)
+	case "unsubscribe_events":
+		p.handleWSUnsubscribeEvents(client, msg)
+	case "config/area_registry/list":
+		p.handleWSRegistryList(client, msg, "area")
+	case "config/device_registry/list":
+		p.handleWSRegistryList(client, msg, "device")
+	case "config/entity_registry/list":
+		p.handleWSRegistryList(client, msg, "entity")
 	default:
 		client.sendMessage(WSMessage{
 			Type:    "result",
@@ -302,7 +315,7 @@ func (p *HomeAssistantPlugin) handleWSAuth(client *WSClient, msg WSMessage) {
 
 	client.sendMessage(WSMessage{
 		Type:      "auth_ok",
-		HAVersion: "2024.1.0",
+		HAVersion: haVersion,
 	})
 
 	log.Printf("WebSocket client authenticated for instance: %s", instance.Name)
@@ -385,3 +398,34 @@ func (p *HomeAssistantPlugin) handleWSPing(client *WSClient, msg WSMessage) {
 		ID:   msg.ID,
 	})
 }
+
+// handleWSSubscribeEvents subscribes the client to one event type, or every event if none is given
+func (p *HomeAssistantPlugin) handleWSSubscribeEvents(client *WSClient, msg WSMessage) {
+	p.events.subscribe(client, msg.ID, msg.EventType)
+	client.sendMessage(WSMessage{
+		Type:    "result",
+		ID:      msg.ID,
+		Success: true,
+	})
+}
+
+// handleWSUnsubscribeEvents cancels a subscription made with subscribe_events
+func (p *HomeAssistantPlugin) handleWSUnsubscribeEvents(client *WSClient, msg WSMessage) {
+	if !p.events.unsubscribe(client, msg.Subscription) {
+		client.sendMessage(WSMessage{
+			Type:    "result",
+			ID:      msg.ID,
+			Success: false,
+			Error: &WSError{
+				Code:    "not_found",
+				Message: "Subscription not found.",
+			},
+		})
+		return
+	}
+	client.sendMessage(WSMessage{
+		Type:    "result",
+		ID:      msg.ID,
+		Success: true,
+	})
+}
@@ -0,0 +1,441 @@
+// ABOUTME: HTTP handlers for HubSpot CRM v3 endpoints
+// ABOUTME: Implements object CRUD with after-cursor paging, associations, and the properties API
+
+package hubspot
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// objectFromRequest resolves an object type URL parameter, writing a 400 for
+// unsupported types
+func objectFromRequest(w http.ResponseWriter, r *http.Request, param string) (*crmObject, bool) {
+	obj, ok := lookupObject(chi.URLParam(r, param))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "Unable to infer object type from: "+chi.URLParam(r, param))
+		return nil, false
+	}
+	return obj, true
+}
+
+// recordIDFromRequest parses a record ID URL parameter, writing a 404 for
+// IDs that can't exist
+func recordIDFromRequest(w http.ResponseWriter, r *http.Request, param string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", "resource not found")
+		return 0, false
+	}
+	return id, true
+}
+
+// requestedNames splits the comma-separated, repeatable query parameter key
+func requestedNames(query url.Values, key string) []string {
+	var names []string
+	for _, value := range query[key] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// objectResponse renders a record with the requested properties, or the
+// object's defaults when none are requested
+func objectResponse(obj *crmObject, rec *Record, properties []string) map[string]any {
+	if len(properties) == 0 {
+		properties = obj.Defaults
+	}
+	values := make(map[string]any, len(properties))
+	for _, name := range properties {
+		switch name {
+		case "hs_object_id":
+			values[name] = strconv.FormatInt(rec.ID, 10)
+		case "createdate":
+			values[name] = formatTime(rec.CreatedAt)
+		case obj.ModifiedProperty:
+			values[name] = formatTime(rec.UpdatedAt)
+		default:
+			if _, known := obj.property(name); !known {
+				continue
+			}
+			if v, ok := rec.Properties[name]; ok {
+				values[name] = v
+			} else {
+				values[name] = nil
+			}
+		}
+	}
+	return map[string]any{
+		"id":         strconv.FormatInt(rec.ID, 10),
+		"properties": values,
+		"createdAt":  formatTime(rec.CreatedAt),
+		"updatedAt":  formatTime(rec.UpdatedAt),
+		"archived":   false,
+	}
+}
+
+// addAssociations adds the record's associations with each requested object type
+func (p *HubSpotPlugin) addAssociations(resp map[string]any, obj *crmObject, rec *Record, types []string) error {
+	associations := map[string]any{}
+	for _, name := range types {
+		to, ok := lookupObject(name)
+		if !ok {
+			continue
+		}
+		linked, err := p.store.ListAssociations(obj, rec.ID, to)
+		if err != nil {
+			return err
+		}
+		if len(linked) > 0 {
+			associations[to.Name] = map[string]any{"results": associationResults(linked)}
+		}
+	}
+	if len(associations) > 0 {
+		resp["associations"] = associations
+	}
+	return nil
+}
+
+func associationResults(linked []Association) []map[string]string {
+	results := make([]map[string]string, 0, len(linked))
+	for _, a := range linked {
+		results = append(results, map[string]string{"id": strconv.FormatInt(a.ID, 10), "type": a.Type})
+	}
+	return results
+}
+
+// decodeProperties reads a {"properties": {...}} request body and validates it
+func decodeProperties(r *http.Request, obj *crmObject) (map[string]string, error) {
+	var body struct {
+		Properties map[string]any `json:"properties"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, &validationError{Errors: []propertyError{{Message: "Invalid input JSON: " + err.Error(), Error: "INVALID_JSON"}}}
+	}
+	return validateProperties(obj, body.Properties)
+}
+
+func (p *HubSpotPlugin) listObjects(w http.ResponseWriter, r *http.Request) {
+	obj, ok := objectFromRequest(w, r, "objectType")
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	limit := defaultPageSize
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	var after int64
+	if v := query.Get("after"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "after must be a paging cursor from a previous response")
+			return
+		}
+		after = n
+	}
+
+	// Fetch one extra record to learn whether there is another page
+	records, err := p.store.List(obj, after, limit+1)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	hasMore := len(records) > limit
+	if hasMore {
+		records = records[:limit]
+	}
+
+	properties := requestedNames(query, "properties")
+	associationTypes := requestedNames(query, "associations")
+	results := make([]map[string]any, 0, len(records))
+	for i := range records {
+		item := objectResponse(obj, &records[i], properties)
+		if err := p.addAssociations(item, obj, &records[i], associationTypes); err != nil {
+			writeRequestError(w, err)
+			return
+		}
+		results = append(results, item)
+	}
+
+	resp := map[string]any{"results": results}
+	if hasMore {
+		next := strconv.FormatInt(records[len(records)-1].ID, 10)
+		link := url.Values{}
+		for k, v := range query {
+			link[k] = v
+		}
+		link.Set("after", next)
+		resp["paging"] = map[string]any{
+			"next": map[string]string{
+				"after": next,
+				"link":  core.BasePath(r) + "/crm/v3/objects/" + obj.Name + "?" + link.Encode(),
+			},
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (p *HubSpotPlugin) createObject(w http.ResponseWriter, r *http.Request) {
+	obj, ok := objectFromRequest(w, r, "objectType")
+	if !ok {
+		return
+	}
+	properties, err := decodeProperties(r, obj)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	rec, err := p.store.Create(obj, properties)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, objectResponse(obj, rec, responseProperties(obj, properties)))
+}
+
+// responseProperties lists the defaults plus the properties a write set,
+// which is what HubSpot echoes back from a create or update
+func responseProperties(obj *crmObject, written map[string]string) []string {
+	names := append([]string{}, obj.Defaults...)
+	for name := range written {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (p *HubSpotPlugin) getObject(w http.ResponseWriter, r *http.Request) {
+	obj, ok := objectFromRequest(w, r, "objectType")
+	if !ok {
+		return
+	}
+	id, ok := recordIDFromRequest(w, r, "objectId")
+	if !ok {
+		return
+	}
+
+	rec, err := p.store.Get(obj, id)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	resp := objectResponse(obj, rec, requestedNames(r.URL.Query(), "properties"))
+	if err := p.addAssociations(resp, obj, rec, requestedNames(r.URL.Query(), "associations")); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (p *HubSpotPlugin) updateObject(w http.ResponseWriter, r *http.Request) {
+	obj, ok := objectFromRequest(w, r, "objectType")
+	if !ok {
+		return
+	}
+	id, ok := recordIDFromRequest(w, r, "objectId")
+	if !ok {
+		return
+	}
+	properties, err := decodeProperties(r, obj)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	rec, err := p.store.Update(obj, id, properties)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, objectResponse(obj, rec, responseProperties(obj, properties)))
+}
+
+func (p *HubSpotPlugin) deleteObject(w http.ResponseWriter, r *http.Request) {
+	obj, ok := objectFromRequest(w, r, "objectType")
+	if !ok {
+		return
+	}
+	id, ok := recordIDFromRequest(w, r, "objectId")
+	if !ok {
+		return
+	}
+
+	// Deleting a missing record succeeds, as archiving one does in HubSpot
+	if err := p.store.Delete(obj, id); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeRequestError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *HubSpotPlugin) listAssociations(w http.ResponseWriter, r *http.Request) {
+	obj, ok := objectFromRequest(w, r, "objectType")
+	if !ok {
+		return
+	}
+	to, ok := objectFromRequest(w, r, "toObjectType")
+	if !ok {
+		return
+	}
+	id, ok := recordIDFromRequest(w, r, "objectId")
+	if !ok {
+		return
+	}
+	if _, err := p.store.Get(obj, id); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	linked, err := p.store.ListAssociations(obj, id, to)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": associationResults(linked)})
+}
+
+// associationFromRequest resolves both ends of an association path and checks
+// the association type names them
+func associationFromRequest(w http.ResponseWriter, r *http.Request) (from *crmObject, fromID int64, to *crmObject, toID int64, ok bool) {
+	if from, ok = objectFromRequest(w, r, "objectType"); !ok {
+		return
+	}
+	if to, ok = objectFromRequest(w, r, "toObjectType"); !ok {
+		return
+	}
+	if fromID, ok = recordIDFromRequest(w, r, "objectId"); !ok {
+		return
+	}
+	if toID, ok = recordIDFromRequest(w, r, "toObjectId"); !ok {
+		return
+	}
+	if want := associationType(from, to); chi.URLParam(r, "associationType") != want {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR",
+			"Invalid association type "+chi.URLParam(r, "associationType")+"; expected "+want)
+		return nil, 0, nil, 0, false
+	}
+	return from, fromID, to, toID, true
+}
+
+func (p *HubSpotPlugin) createAssociation(w http.ResponseWriter, r *http.Request) {
+	from, fromID, to, toID, ok := associationFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := p.store.Associate(from, fromID, to, toID); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	rec, err := p.store.Get(from, fromID)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	resp := objectResponse(from, rec, nil)
+	if err := p.addAssociations(resp, from, rec, []string{to.Name}); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (p *HubSpotPlugin) deleteAssociation(w http.ResponseWriter, r *http.Request) {
+	from, fromID, to, toID, ok := associationFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := p.store.Disassociate(from, fromID, to, toID); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// propertyResponse renders a property definition as the properties API does
+func propertyResponse(def *propertyDef, order int) map[string]any {
+	options := make([]map[string]any, 0, len(def.Options))
+	for i, opt := range def.Options {
+		options = append(options, map[string]any{
+			"label":        opt.Label,
+			"value":        opt.Value,
+			"displayOrder": i,
+			"hidden":       false,
+		})
+	}
+	return map[string]any{
+		"name":            def.Name,
+		"label":           def.Label,
+		"type":            def.Type,
+		"fieldType":       def.FieldType,
+		"groupName":       def.GroupName,
+		"description":     "",
+		"options":         options,
+		"displayOrder":    order,
+		"calculated":      false,
+		"externalOptions": false,
+		"hasUniqueValue":  def.Name == "hs_object_id",
+		"hidden":          false,
+		"formField":       !def.ReadOnly,
+		"modificationMetadata": map[string]any{
+			"archivable":         true,
+			"readOnlyDefinition": true,
+			"readOnlyValue":      def.ReadOnly,
+		},
+		"archived": false,
+	}
+}
+
+func (p *HubSpotPlugin) listProperties(w http.ResponseWriter, r *http.Request) {
+	obj, ok := objectFromRequest(w, r, "objectType")
+	if !ok {
+		return
+	}
+	results := make([]map[string]any, 0, len(obj.Properties))
+	for i := range obj.Properties {
+		results = append(results, propertyResponse(&obj.Properties[i], i))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results})
+}
+
+func (p *HubSpotPlugin) getProperty(w http.ResponseWriter, r *http.Request) {
+	obj, ok := objectFromRequest(w, r, "objectType")
+	if !ok {
+		return
+	}
+	name := chi.URLParam(r, "propertyName")
+	for i := range obj.Properties {
+		if obj.Properties[i].Name == name {
+			writeJSON(w, http.StatusOK, propertyResponse(&obj.Properties[i], i))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", "Unable to find property "+name+" for object type "+obj.Name)
+}
@@ -0,0 +1,212 @@
+// ABOUTME: Tests for HubSpot CRM v3 handlers
+// ABOUTME: Covers auth, object CRUD and property validation, paging, associations, and the properties API
+
+package hubspot
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/migrations"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestPlugin(t *testing.T) (*HubSpotPlugin, chi.Router) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	plugin := &HubSpotPlugin{}
+	if err := plugin.SetDB(db); err != nil {
+		t.Fatalf("Failed to initialize plugin: %v", err)
+	}
+	if _, err := plugin.Seed(context.Background(), "small"); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	r := chi.NewRouter()
+	plugin.RegisterAuth(r)
+	plugin.RegisterRoutes(r)
+	return plugin, r
+}
+
+func doRequest(t *testing.T, r chi.Router, method, target string, body any, wantStatus int) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("Failed to encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, target, &buf)
+	req.Header.Set("Authorization", "Bearer pat-na1-test")
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != wantStatus {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, target, wantStatus, rr.Code, rr.Body.String())
+	}
+	if rr.Body.Len() == 0 {
+		return nil
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestAuth(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/crm/v3/objects/contacts", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", rr.Code)
+	}
+	var resp map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp["category"] != "INVALID_AUTHENTICATION" || resp["correlationId"] == "" {
+		t.Errorf("unexpected error body: %v", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/crm/v3/objects/contacts?hapikey=demo-key", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected hapikey to authenticate, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestContactCRUD(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	created := doRequest(t, r, "POST", "/crm/v3/objects/contacts", map[string]any{
+		"properties": map[string]any{"email": "mary@jacksonlabs.example", "firstname": "Mary", "lastname": "Jackson", "jobtitle": "Engineer"},
+	}, http.StatusCreated)
+	id := created["id"].(string)
+	props := created["properties"].(map[string]any)
+	if props["email"] != "mary@jacksonlabs.example" || props["jobtitle"] != "Engineer" || props["hs_object_id"] != id {
+		t.Errorf("unexpected created properties: %v", props)
+	}
+	if created["archived"] != false || created["createdAt"] == nil {
+		t.Errorf("unexpected created object: %v", created)
+	}
+
+	got := doRequest(t, r, "GET", "/crm/v3/objects/contacts/"+id+"?properties=jobtitle,lifecyclestage", nil, http.StatusOK)
+	props = got["properties"].(map[string]any)
+	if props["jobtitle"] != "Engineer" || props["lifecyclestage"] != nil || props["email"] != nil {
+		t.Errorf("expected only the requested properties, got %v", props)
+	}
+
+	updated := doRequest(t, r, "PATCH", "/crm/v3/objects/contacts/"+id, map[string]any{
+		"properties": map[string]any{"lifecyclestage": "lead", "jobtitle": ""},
+	}, http.StatusOK)
+	props = updated["properties"].(map[string]any)
+	if props["lifecyclestage"] != "lead" || props["jobtitle"] != nil {
+		t.Errorf("expected lifecyclestage set and jobtitle cleared, got %v", props)
+	}
+
+	doRequest(t, r, "DELETE", "/crm/v3/objects/contacts/"+id, nil, http.StatusNoContent)
+	doRequest(t, r, "GET", "/crm/v3/objects/contacts/"+id, nil, http.StatusNotFound)
+}
+
+func TestPropertyValidation(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	resp := doRequest(t, r, "POST", "/crm/v3/objects/deals", map[string]any{
+		"properties": map[string]any{"dealname": "Bad deal", "amount": "lots", "dealstage": "signed", "favorite_color": "blue", "hs_object_id": "7"},
+	}, http.StatusBadRequest)
+	if resp["category"] != "VALIDATION_ERROR" {
+		t.Fatalf("expected VALIDATION_ERROR, got %v", resp)
+	}
+	message := resp["message"].(string)
+	for _, want := range []string{"INVALID_PROPERTY_VALUE", "INVALID_OPTION", "PROPERTY_DOESNT_EXIST", "READ_ONLY_VALUE"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("expected %s in %q", want, message)
+		}
+	}
+
+	deal := doRequest(t, r, "POST", "/crm/v3/objects/deals", map[string]any{
+		"properties": map[string]any{"dealname": "Good deal", "amount": 1500.5, "dealstage": "qualifiedtobuy", "closedate": "2025-09-30"},
+	}, http.StatusCreated)
+	props := deal["properties"].(map[string]any)
+	if props["amount"] != "1500.5" || props["closedate"] != "2025-09-30T00:00:00.000Z" {
+		t.Errorf("expected amount and closedate normalized, got %v", props)
+	}
+
+	properties := doRequest(t, r, "GET", "/crm/v3/properties/deals/dealstage", nil, http.StatusOK)
+	if properties["type"] != "enumeration" || len(properties["options"].([]any)) != len(dealStages) {
+		t.Errorf("unexpected dealstage definition: %v", properties)
+	}
+	list := doRequest(t, r, "GET", "/crm/v3/properties/contacts", nil, http.StatusOK)
+	if len(list["results"].([]any)) != len(contactObject.Properties) {
+		t.Errorf("expected every contact property, got %d", len(list["results"].([]any)))
+	}
+}
+
+func TestListPaging(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	first := doRequest(t, r, "GET", "/crm/v3/objects/contacts?limit=3&properties=email", nil, http.StatusOK)
+	if len(first["results"].([]any)) != 3 {
+		t.Fatalf("expected 3 results, got %v", first["results"])
+	}
+	next := first["paging"].(map[string]any)["next"].(map[string]any)
+	if !strings.Contains(next["link"].(string), "after="+next["after"].(string)) {
+		t.Errorf("expected the next link to carry the cursor, got %v", next)
+	}
+
+	second := doRequest(t, r, "GET", "/crm/v3/objects/contacts?limit=3&after="+next["after"].(string), nil, http.StatusOK)
+	if len(second["results"].([]any)) != len(seedContacts)-3 || second["paging"] != nil {
+		t.Errorf("expected the remaining contacts without further paging, got %v", second)
+	}
+}
+
+func TestAssociations(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	contact := doRequest(t, r, "POST", "/crm/v3/objects/contacts", map[string]any{
+		"properties": map[string]any{"email": "dorothy@vaughan.example"},
+	}, http.StatusCreated)
+	deal := doRequest(t, r, "POST", "/crm/v3/objects/deals", map[string]any{
+		"properties": map[string]any{"dealname": "Vaughan - Compute Time", "dealstage": "appointmentscheduled"},
+	}, http.StatusCreated)
+	contactID, dealID := contact["id"].(string), deal["id"].(string)
+
+	path := "/crm/v3/objects/contacts/" + contactID + "/associations/deals/" + dealID
+	doRequest(t, r, "PUT", path+"/deal_to_contact", nil, http.StatusBadRequest)
+	resp := doRequest(t, r, "PUT", path+"/contact_to_deal", nil, http.StatusOK)
+	results := resp["associations"].(map[string]any)["deals"].(map[string]any)["results"].([]any)
+	if len(results) != 1 || results[0].(map[string]any)["id"] != dealID || results[0].(map[string]any)["type"] != "contact_to_deal" {
+		t.Errorf("unexpected associations: %v", results)
+	}
+
+	// Associations are visible from both ends
+	got := doRequest(t, r, "GET", "/crm/v3/objects/deals/"+dealID+"?associations=contacts", nil, http.StatusOK)
+	results = got["associations"].(map[string]any)["contacts"].(map[string]any)["results"].([]any)
+	if len(results) != 1 || results[0].(map[string]any)["type"] != "deal_to_contact" {
+		t.Errorf("expected the inverse association on the deal, got %v", results)
+	}
+
+	doRequest(t, r, "DELETE", path+"/contact_to_deal", nil, http.StatusNoContent)
+	list := doRequest(t, r, "GET", "/crm/v3/objects/contacts/"+contactID+"/associations/deals", nil, http.StatusOK)
+	if len(list["results"].([]any)) != 0 {
+		t.Errorf("expected no associations after delete, got %v", list)
+	}
+
+	doRequest(t, r, "PUT", "/crm/v3/objects/contacts/"+contactID+"/associations/deals/99999/contact_to_deal", nil, http.StatusNotFound)
+}
@@ -0,0 +1,32 @@
+// ABOUTME: core.Inspector implementation for the HubSpot plugin
+// ABOUTME: Renders records as the object GET endpoint does, with every property
+
+package hubspot
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *HubSpotPlugin) Inspect(resource, id string) (interface{}, error) {
+	obj, ok := lookupObject(resource)
+	if !ok {
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+	recordID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+	rec, err := p.store.Get(obj, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make([]string, 0, len(obj.Properties))
+	for _, def := range obj.Properties {
+		properties = append(properties, def.Name)
+	}
+	return objectResponse(obj, rec, properties), nil
+}
@@ -0,0 +1,292 @@
+// ABOUTME: CRM object and property definitions for the HubSpot plugin
+// ABOUTME: Describes contacts, companies, and deals and validates property values against their schema
+
+package hubspot
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Property types and field types, as reported by the properties API
+const (
+	typeString      = "string"
+	typeNumber      = "number"
+	typeDate        = "date"
+	typeDateTime    = "datetime"
+	typeEnumeration = "enumeration"
+)
+
+type propertyOption struct {
+	Label string
+	Value string
+}
+
+// propertyDef describes one property of a CRM object
+type propertyDef struct {
+	Name      string
+	Label     string
+	Type      string
+	FieldType string
+	GroupName string
+	Options   []propertyOption
+	// ReadOnly properties are maintained by HubSpot and can't be set by clients
+	ReadOnly bool
+}
+
+// crmObject describes a CRM object type and where its records are stored
+type crmObject struct {
+	Name     string // plural name used in paths, e.g. "contacts"
+	Singular string // used in association type names, e.g. "contact"
+	TypeID   string // HubSpot's object type ID, e.g. "0-1"
+	Table    string
+	// ModifiedProperty holds the last modified time; contacts call it lastmodifieddate
+	ModifiedProperty string
+	// Defaults are the properties returned when a request names none
+	Defaults   []string
+	Properties []propertyDef
+}
+
+func (o *crmObject) property(name string) (*propertyDef, bool) {
+	for i := range o.Properties {
+		if o.Properties[i].Name == name {
+			return &o.Properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// readOnlyProperties are present on every object type
+func readOnlyProperties(modified string) []propertyDef {
+	return []propertyDef{
+		{Name: "createdate", Label: "Create Date", Type: typeDateTime, FieldType: "date", GroupName: "systeminformation", ReadOnly: true},
+		{Name: modified, Label: "Last Modified Date", Type: typeDateTime, FieldType: "date", GroupName: "systeminformation", ReadOnly: true},
+		{Name: "hs_object_id", Label: "Record ID", Type: typeNumber, FieldType: "number", GroupName: "systeminformation", ReadOnly: true},
+	}
+}
+
+var lifecycleStages = []propertyOption{
+	{"Subscriber", "subscriber"},
+	{"Lead", "lead"},
+	{"Marketing Qualified Lead", "marketingqualifiedlead"},
+	{"Sales Qualified Lead", "salesqualifiedlead"},
+	{"Opportunity", "opportunity"},
+	{"Customer", "customer"},
+	{"Evangelist", "evangelist"},
+	{"Other", "other"},
+}
+
+var dealStages = []propertyOption{
+	{"Appointment Scheduled", "appointmentscheduled"},
+	{"Qualified To Buy", "qualifiedtobuy"},
+	{"Presentation Scheduled", "presentationscheduled"},
+	{"Decision Maker Bought-In", "decisionmakerboughtin"},
+	{"Contract Sent", "contractsent"},
+	{"Closed Won", "closedwon"},
+	{"Closed Lost", "closedlost"},
+}
+
+var contactObject = &crmObject{
+	Name:             "contacts",
+	Singular:         "contact",
+	TypeID:           "0-1",
+	Table:            "hubspot_contacts",
+	ModifiedProperty: "lastmodifieddate",
+	Defaults:         []string{"createdate", "email", "firstname", "hs_object_id", "lastmodifieddate", "lastname"},
+	Properties: append([]propertyDef{
+		{Name: "email", Label: "Email", Type: typeString, FieldType: "text", GroupName: "contactinformation"},
+		{Name: "firstname", Label: "First Name", Type: typeString, FieldType: "text", GroupName: "contactinformation"},
+		{Name: "lastname", Label: "Last Name", Type: typeString, FieldType: "text", GroupName: "contactinformation"},
+		{Name: "phone", Label: "Phone Number", Type: typeString, FieldType: "phonenumber", GroupName: "contactinformation"},
+		{Name: "company", Label: "Company Name", Type: typeString, FieldType: "text", GroupName: "contactinformation"},
+		{Name: "jobtitle", Label: "Job Title", Type: typeString, FieldType: "text", GroupName: "contactinformation"},
+		{Name: "city", Label: "City", Type: typeString, FieldType: "text", GroupName: "contactinformation"},
+		{Name: "website", Label: "Website URL", Type: typeString, FieldType: "text", GroupName: "contactinformation"},
+		{Name: "lifecyclestage", Label: "Lifecycle Stage", Type: typeEnumeration, FieldType: "radio", GroupName: "contactinformation", Options: lifecycleStages},
+	}, readOnlyProperties("lastmodifieddate")...),
+}
+
+var companyObject = &crmObject{
+	Name:             "companies",
+	Singular:         "company",
+	TypeID:           "0-2",
+	Table:            "hubspot_companies",
+	ModifiedProperty: "hs_lastmodifieddate",
+	Defaults:         []string{"createdate", "domain", "hs_lastmodifieddate", "hs_object_id", "name"},
+	Properties: append([]propertyDef{
+		{Name: "name", Label: "Company name", Type: typeString, FieldType: "text", GroupName: "companyinformation"},
+		{Name: "domain", Label: "Company Domain Name", Type: typeString, FieldType: "text", GroupName: "companyinformation"},
+		{Name: "industry", Label: "Industry", Type: typeString, FieldType: "text", GroupName: "companyinformation"},
+		{Name: "phone", Label: "Phone Number", Type: typeString, FieldType: "phonenumber", GroupName: "companyinformation"},
+		{Name: "city", Label: "City", Type: typeString, FieldType: "text", GroupName: "companyinformation"},
+		{Name: "state", Label: "State/Region", Type: typeString, FieldType: "text", GroupName: "companyinformation"},
+		{Name: "country", Label: "Country/Region", Type: typeString, FieldType: "text", GroupName: "companyinformation"},
+		{Name: "numberofemployees", Label: "Number of Employees", Type: typeNumber, FieldType: "number", GroupName: "companyinformation"},
+		{Name: "annualrevenue", Label: "Annual Revenue", Type: typeNumber, FieldType: "number", GroupName: "companyinformation"},
+		{Name: "description", Label: "Description", Type: typeString, FieldType: "textarea", GroupName: "companyinformation"},
+	}, readOnlyProperties("hs_lastmodifieddate")...),
+}
+
+var dealObject = &crmObject{
+	Name:             "deals",
+	Singular:         "deal",
+	TypeID:           "0-3",
+	Table:            "hubspot_deals",
+	ModifiedProperty: "hs_lastmodifieddate",
+	Defaults:         []string{"amount", "closedate", "createdate", "dealname", "dealstage", "hs_lastmodifieddate", "hs_object_id", "pipeline"},
+	Properties: append([]propertyDef{
+		{Name: "dealname", Label: "Deal Name", Type: typeString, FieldType: "text", GroupName: "dealinformation"},
+		{Name: "amount", Label: "Amount", Type: typeNumber, FieldType: "number", GroupName: "dealinformation"},
+		{Name: "dealstage", Label: "Deal Stage", Type: typeEnumeration, FieldType: "radio", GroupName: "dealinformation", Options: dealStages},
+		{Name: "pipeline", Label: "Pipeline", Type: typeEnumeration, FieldType: "select", GroupName: "dealinformation", Options: []propertyOption{{"Sales Pipeline", "default"}}},
+		{Name: "closedate", Label: "Close Date", Type: typeDateTime, FieldType: "date", GroupName: "dealinformation"},
+		{Name: "dealtype", Label: "Deal Type", Type: typeEnumeration, FieldType: "radio", GroupName: "dealinformation", Options: []propertyOption{{"New Business", "newbusiness"}, {"Existing Business", "existingbusiness"}}},
+		{Name: "description", Label: "Deal Description", Type: typeString, FieldType: "textarea", GroupName: "dealinformation"},
+	}, readOnlyProperties("hs_lastmodifieddate")...),
+}
+
+var crmObjects = []*crmObject{contactObject, companyObject, dealObject}
+
+// lookupObject resolves an object type from a path segment, accepting the
+// plural name, singular name, or object type ID
+func lookupObject(name string) (*crmObject, bool) {
+	name = strings.ToLower(name)
+	for _, o := range crmObjects {
+		if name == o.Name || name == o.Singular || name == o.TypeID {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// associationType names the association from one object type to another,
+// e.g. contact_to_deal
+func associationType(from, to *crmObject) string {
+	return from.Singular + "_to_" + to.Singular
+}
+
+// propertyError describes one invalid property value, in HubSpot's format
+type propertyError struct {
+	IsValid bool   `json:"isValid"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Name    string `json:"name"`
+}
+
+// validationError reports the invalid properties of a create or update
+type validationError struct {
+	Errors []propertyError
+}
+
+func (e *validationError) Error() string {
+	detail, _ := json.Marshal(e.Errors)
+	return "Property values were not valid: " + string(detail)
+}
+
+// validateProperties checks client-supplied property values against the
+// object's schema and returns them as the strings HubSpot stores. Values may
+// be sent as JSON strings, numbers, or booleans; null clears a property.
+func validateProperties(o *crmObject, input map[string]any) (map[string]string, error) {
+	values := make(map[string]string, len(input))
+	var errs []propertyError
+	invalid := func(name, code, message string) {
+		errs = append(errs, propertyError{Message: message, Error: code, Name: name})
+	}
+
+	for name, raw := range input {
+		def, ok := o.property(name)
+		if !ok {
+			invalid(name, "PROPERTY_DOESNT_EXIST", fmt.Sprintf("Property %q does not exist", name))
+			continue
+		}
+		if def.ReadOnly {
+			invalid(name, "READ_ONLY_VALUE", fmt.Sprintf("%q is a read only property; its value cannot be set.", name))
+			continue
+		}
+
+		var value string
+		switch v := raw.(type) {
+		case nil:
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		case int:
+			value = strconv.Itoa(v)
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			invalid(name, "INVALID_PROPERTY_VALUE", fmt.Sprintf("%v was not a valid value for %s", raw, name))
+			continue
+		}
+		if value == "" {
+			values[name] = ""
+			continue
+		}
+
+		normalized, err := normalizeValue(def, value)
+		if err != nil {
+			code := "INVALID_PROPERTY_VALUE"
+			if def.Type == typeEnumeration {
+				code = "INVALID_OPTION"
+			}
+			invalid(name, code, err.Error())
+			continue
+		}
+		values[name] = normalized
+	}
+
+	if len(errs) > 0 {
+		return nil, &validationError{Errors: errs}
+	}
+	return values, nil
+}
+
+// normalizeValue checks a value against its property type
+func normalizeValue(def *propertyDef, value string) (string, error) {
+	switch def.Type {
+	case typeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("%s was not a valid number.", value)
+		}
+	case typeEnumeration:
+		for _, opt := range def.Options {
+			if opt.Value == value {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("%s was not one of the allowed options for %s", value, def.Name)
+	case typeDate, typeDateTime:
+		t, err := parseDateValue(value)
+		if err != nil {
+			return "", fmt.Errorf("%s was not a valid date.", value)
+		}
+		if def.Type == typeDate {
+			return t.Format("2006-01-02"), nil
+		}
+		return formatTime(t), nil
+	}
+	return value, nil
+}
+
+// parseDateValue accepts the date forms HubSpot does: ISO 8601 dates and
+// datetimes, and millisecond timestamps
+func parseDateValue(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// formatTime renders a time the way HubSpot does, in UTC with milliseconds
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
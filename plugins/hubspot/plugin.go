@@ -0,0 +1,185 @@
+// ABOUTME: HubSpot CRM API plugin for ISH
+// ABOUTME: Simulates CRM v3 contacts, companies, and deals with associations, authenticated by API key or Bearer token
+
+package hubspot
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+func init() {
+	core.Register(&HubSpotPlugin{})
+}
+
+type HubSpotPlugin struct {
+	store *HubSpotStore
+}
+
+func (p *HubSpotPlugin) Name() string {
+	return "hubspot"
+}
+
+func (p *HubSpotPlugin) Health() core.HealthStatus {
+	return core.HealthStatus{
+		Status:  "healthy",
+		Message: "HubSpot plugin operational",
+	}
+}
+
+func (p *HubSpotPlugin) RegisterRoutes(r chi.Router) {
+	r.Route("/crm/v3", func(r chi.Router) {
+		r.Get("/objects/{objectType}", p.requireAuth(p.listObjects))
+		r.Post("/objects/{objectType}", p.requireAuth(p.createObject))
+		r.Get("/objects/{objectType}/{objectId}", p.requireAuth(p.getObject))
+		r.Patch("/objects/{objectType}/{objectId}", p.requireAuth(p.updateObject))
+		r.Delete("/objects/{objectType}/{objectId}", p.requireAuth(p.deleteObject))
+
+		r.Get("/objects/{objectType}/{objectId}/associations/{toObjectType}", p.requireAuth(p.listAssociations))
+		r.Put("/objects/{objectType}/{objectId}/associations/{toObjectType}/{toObjectId}/{associationType}", p.requireAuth(p.createAssociation))
+		r.Delete("/objects/{objectType}/{objectId}/associations/{toObjectType}/{toObjectId}/{associationType}", p.requireAuth(p.deleteAssociation))
+
+		r.Get("/properties/{objectType}", p.requireAuth(p.listProperties))
+		r.Get("/properties/{objectType}/{propertyName}", p.requireAuth(p.getProperty))
+	})
+}
+
+func (p *HubSpotPlugin) RegisterAuth(r chi.Router) {
+	// HubSpot uses private app tokens or API keys, checked per request
+}
+
+// requireAuth accepts a private app token as "Authorization: Bearer" or a
+// legacy API key in the hapikey query parameter
+func (p *HubSpotPlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			token = r.URL.Query().Get("hapikey")
+		}
+		if !p.ValidateToken(strings.TrimSpace(token)) {
+			writeError(w, http.StatusUnauthorized, "INVALID_AUTHENTICATION",
+				"Authentication credentials not found. This API supports both API Key and OAuth 2.0 authentication.")
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+func (p *HubSpotPlugin) ValidateToken(token string) bool {
+	return token != ""
+}
+
+func (p *HubSpotPlugin) SetDB(db *sql.DB) error {
+	store, err := NewHubSpotStore(db)
+	if err != nil {
+		return err
+	}
+	p.store = store
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("HubSpot: Failed to encode response: %v", err)
+	}
+}
+
+// writeError writes a HubSpot-style error body
+func writeError(w http.ResponseWriter, status int, category, message string) {
+	writeJSON(w, status, map[string]any{
+		"status":        "error",
+		"message":       message,
+		"correlationId": newCorrelationID(),
+		"category":      category,
+	})
+}
+
+// writeRequestError writes a validationError as a 400 and any other error as a 500
+func writeRequestError(w http.ResponseWriter, err error) {
+	var ve *validationError
+	if errors.As(err, &ve) {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", ve.Error())
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "OBJECT_NOT_FOUND", "resource not found")
+		return
+	}
+	log.Printf("HubSpot: %v", err)
+	writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal error")
+}
+
+// newCorrelationID returns a UUID-formatted request ID like HubSpot's
+func newCorrelationID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	s := hex.EncodeToString(b)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// ListResources implements core.DataProvider to expose data to admin UI
+func (p *HubSpotPlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
+	obj, ok := lookupObject(slug)
+	if !ok {
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	records, err := p.store.ListPage(obj, limit, opts.Offset)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]interface{}, 0, len(records))
+	for _, rec := range records {
+		result = append(result, convertRecordToMap(obj, &rec))
+	}
+	return result, nil
+}
+
+// GetResource implements core.DataProvider to fetch individual resources
+func (p *HubSpotPlugin) GetResource(ctx context.Context, slug string, id string) (map[string]interface{}, error) {
+	obj, ok := lookupObject(slug)
+	if !ok {
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+	recordID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+	rec, err := p.store.Get(obj, recordID)
+	if err != nil {
+		return nil, err
+	}
+	return convertRecordToMap(obj, rec), nil
+}
+
+// convertRecordToMap flattens a record's properties for the admin UI
+func convertRecordToMap(obj *crmObject, rec *Record) map[string]interface{} {
+	m := map[string]interface{}{
+		"id":         strconv.FormatInt(rec.ID, 10),
+		"created_at": rec.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		"updated_at": rec.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	for _, def := range obj.Properties {
+		if !def.ReadOnly {
+			m[def.Name] = rec.Properties[def.Name]
+		}
+	}
+	return m
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Admin UI schema definitions for HubSpot plugin
+// ABOUTME: Defines Contacts, Companies, and Deals resources for schema-driven UI
+
+package hubspot
+
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func (p *HubSpotPlugin) Schema() core.PluginSchema {
+	return core.PluginSchema{
+		Resources: []core.ResourceSchema{
+			{
+				Name:        "Contacts",
+				Slug:        "contacts",
+				ListColumns: []string{"firstname", "lastname", "email", "lifecyclestage"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "firstname", Type: "string", Display: "First Name", Required: false, Editable: false},
+					{Name: "lastname", Type: "string", Display: "Last Name", Required: false, Editable: false},
+					{Name: "email", Type: "email", Display: "Email", Required: false, Editable: false},
+					{Name: "phone", Type: "string", Display: "Phone", Required: false, Editable: false},
+					{Name: "company", Type: "string", Display: "Company", Required: false, Editable: false},
+					{Name: "jobtitle", Type: "string", Display: "Job Title", Required: false, Editable: false},
+					{Name: "city", Type: "string", Display: "City", Required: false, Editable: false},
+					{Name: "website", Type: "string", Display: "Website", Required: false, Editable: false},
+					{Name: "lifecyclestage", Type: "string", Display: "Lifecycle Stage", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "updated_at", Type: "datetime", Display: "Last Modified", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Companies",
+				Slug:        "companies",
+				ListColumns: []string{"name", "domain", "industry", "city"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Name", Required: false, Editable: false},
+					{Name: "domain", Type: "string", Display: "Domain", Required: false, Editable: false},
+					{Name: "industry", Type: "string", Display: "Industry", Required: false, Editable: false},
+					{Name: "phone", Type: "string", Display: "Phone", Required: false, Editable: false},
+					{Name: "city", Type: "string", Display: "City", Required: false, Editable: false},
+					{Name: "state", Type: "string", Display: "State/Region", Required: false, Editable: false},
+					{Name: "country", Type: "string", Display: "Country/Region", Required: false, Editable: false},
+					{Name: "numberofemployees", Type: "string", Display: "Employees", Required: false, Editable: false},
+					{Name: "annualrevenue", Type: "string", Display: "Annual Revenue", Required: false, Editable: false},
+					{Name: "description", Type: "text", Display: "Description", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "updated_at", Type: "datetime", Display: "Last Modified", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Deals",
+				Slug:        "deals",
+				ListColumns: []string{"dealname", "dealstage", "amount", "closedate"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "dealname", Type: "string", Display: "Deal Name", Required: false, Editable: false},
+					{Name: "dealstage", Type: "string", Display: "Stage", Required: false, Editable: false},
+					{Name: "pipeline", Type: "string", Display: "Pipeline", Required: false, Editable: false},
+					{Name: "amount", Type: "string", Display: "Amount", Required: false, Editable: false},
+					{Name: "closedate", Type: "string", Display: "Close Date", Required: false, Editable: false},
+					{Name: "dealtype", Type: "string", Display: "Deal Type", Required: false, Editable: false},
+					{Name: "description", Type: "text", Display: "Description", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "updated_at", Type: "datetime", Display: "Last Modified", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+		},
+	}
+}
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *HubSpotPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "hubspot", Table: "hubspot_contacts", Resource: "contacts"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *HubSpotPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"hubspot": {"hubspot_associations", "hubspot_deals", "hubspot_contacts", "hubspot_companies"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *HubSpotPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *HubSpotPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
@@ -0,0 +1,131 @@
+// ABOUTME: Test data generation for HubSpot plugin
+// ABOUTME: Creates companies, their contacts, and a deal pipeline associated with both
+
+package hubspot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+var seedCompanies = []map[string]any{
+	{"name": "Analytical Engines", "domain": "analyticalengines.example", "industry": "Computer Hardware",
+		"city": "San Francisco", "state": "CA", "country": "United States", "numberofemployees": 250, "annualrevenue": 42000000},
+	{"name": "COBOL Works", "domain": "cobolworks.example", "industry": "Information Technology and Services",
+		"city": "New York", "state": "NY", "country": "United States", "numberofemployees": 80},
+	{"name": "Orbital Math", "domain": "orbitalmath.example", "industry": "Aviation & Aerospace",
+		"city": "Hampton", "state": "VA", "country": "United States", "numberofemployees": 1200, "annualrevenue": 310000000},
+}
+
+type seedContact struct {
+	company    int
+	properties map[string]any
+}
+
+var seedContacts = []seedContact{
+	{0, map[string]any{"firstname": "Ada", "lastname": "Lovelace", "email": "ada@analyticalengines.example",
+		"phone": "(415) 555-0101", "jobtitle": "VP Engineering", "city": "San Francisco", "lifecyclestage": "opportunity"}},
+	{0, map[string]any{"firstname": "Charles", "lastname": "Babbage", "email": "charles@analyticalengines.example",
+		"jobtitle": "Founder", "city": "San Francisco", "lifecyclestage": "customer"}},
+	{1, map[string]any{"firstname": "Grace", "lastname": "Hopper", "email": "grace@cobolworks.example",
+		"phone": "(212) 555-0102", "jobtitle": "CTO", "city": "New York", "lifecyclestage": "salesqualifiedlead"}},
+	{2, map[string]any{"firstname": "Katherine", "lastname": "Johnson", "email": "katherine@orbitalmath.example",
+		"phone": "(757) 555-0104", "jobtitle": "Director of Operations", "city": "Hampton", "lifecyclestage": "customer"}},
+	{-1, map[string]any{"firstname": "Alan", "lastname": "Turing", "email": "alan@enigmasolutions.example",
+		"jobtitle": "Head of Research", "city": "London", "lifecyclestage": "lead"}},
+}
+
+type seedDeal struct {
+	contact    int
+	company    int
+	properties map[string]any
+}
+
+var seedDeals = []seedDeal{
+	{0, 0, map[string]any{"dealname": "Analytical Engines - Platform Renewal", "amount": 120000, "dealstage": "contractsent",
+		"pipeline": "default", "closedate": "2025-03-31", "dealtype": "existingbusiness"}},
+	{2, 1, map[string]any{"dealname": "COBOL Works - Migration Services", "amount": 85000, "dealstage": "presentationscheduled",
+		"pipeline": "default", "closedate": "2025-04-15", "dealtype": "newbusiness"}},
+	{3, 2, map[string]any{"dealname": "Orbital Math - Enterprise Licenses", "amount": 240000, "dealstage": "closedwon",
+		"pipeline": "default", "closedate": "2025-01-20", "dealtype": "newbusiness"}},
+	{4, -1, map[string]any{"dealname": "Enigma Solutions - Pilot", "amount": 15000, "dealstage": "appointmentscheduled",
+		"pipeline": "default", "closedate": "2025-05-30", "dealtype": "newbusiness"}},
+}
+
+// Seed creates test data for the HubSpot plugin
+func (p *HubSpotPlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
+	create := func(obj *crmObject, input map[string]any) (int64, error) {
+		properties, err := validateProperties(obj, input)
+		if err != nil {
+			return 0, fmt.Errorf("invalid seed %s: %w", obj.Singular, err)
+		}
+		rec, err := p.store.Create(obj, properties)
+		if err != nil {
+			return 0, err
+		}
+		return rec.ID, nil
+	}
+	associations := 0
+	associate := func(from *crmObject, fromID int64, to *crmObject, toID int64) error {
+		associations++
+		return p.store.Associate(from, fromID, to, toID)
+	}
+
+	companyIDs := make([]int64, 0, len(seedCompanies))
+	for _, input := range seedCompanies {
+		id, err := create(companyObject, input)
+		if err != nil {
+			return core.SeedData{}, err
+		}
+		companyIDs = append(companyIDs, id)
+	}
+
+	contactIDs := make([]int64, 0, len(seedContacts))
+	for _, seed := range seedContacts {
+		input := map[string]any{}
+		for k, v := range seed.properties {
+			input[k] = v
+		}
+		if seed.company >= 0 {
+			input["company"] = seedCompanies[seed.company]["name"]
+		}
+		id, err := create(contactObject, input)
+		if err != nil {
+			return core.SeedData{}, err
+		}
+		contactIDs = append(contactIDs, id)
+		if seed.company >= 0 {
+			if err := associate(contactObject, id, companyObject, companyIDs[seed.company]); err != nil {
+				return core.SeedData{}, err
+			}
+		}
+	}
+
+	for _, seed := range seedDeals {
+		id, err := create(dealObject, seed.properties)
+		if err != nil {
+			return core.SeedData{}, err
+		}
+		if err := associate(contactObject, contactIDs[seed.contact], dealObject, id); err != nil {
+			return core.SeedData{}, err
+		}
+		if seed.company >= 0 {
+			if err := associate(dealObject, id, companyObject, companyIDs[seed.company]); err != nil {
+				return core.SeedData{}, err
+			}
+		}
+	}
+
+	return core.SeedData{
+		Summary: fmt.Sprintf("Created %d companies, %d contacts, %d deals, %d associations",
+			len(seedCompanies), len(seedContacts), len(seedDeals), associations),
+		Records: map[string]int{
+			"companies":    len(seedCompanies),
+			"contacts":     len(seedContacts),
+			"deals":        len(seedDeals),
+			"associations": associations,
+		},
+	}, nil
+}
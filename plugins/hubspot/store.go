@@ -0,0 +1,277 @@
+// ABOUTME: Database layer for HubSpot plugin
+// ABOUTME: Stores contacts, companies, and deals as JSON property sets, plus associations between them
+
+package hubspot
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// Record is a stored CRM object. Properties holds client-set values; the
+// read-only properties are derived from the other fields when rendered.
+type Record struct {
+	ID         int64
+	Properties map[string]string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Association links a record to another record of a given object type
+type Association struct {
+	ID   int64
+	Type string
+}
+
+type HubSpotStore struct {
+	db *sql.DB
+}
+
+// auditIn records a mutation of a HubSpot record in the shared audit log
+// through db, the transaction making the change so both commit together.
+// HubSpot tokens don't identify a user, so no actor is recorded.
+func auditIn(db core.Execer, o *crmObject, id int64, action string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "hubspot",
+		ResourceType: o.Singular,
+		ResourceID:   strconv.FormatInt(id, 10),
+		Action:       action,
+		Changes:      changes,
+	})
+}
+
+func NewHubSpotStore(db *sql.DB) (*HubSpotStore, error) {
+	return &HubSpotStore{db: db}, nil
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *HubSpotStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Create inserts a record with validated property values
+func (s *HubSpotStore) Create(o *crmObject, properties map[string]string) (*Record, error) {
+	stored := make(map[string]string, len(properties))
+	for k, v := range properties {
+		if v != "" {
+			stored[k] = v
+		}
+	}
+	encoded, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	now := core.Now().UTC()
+	var id int64
+	err = s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s (properties, created_at, updated_at) VALUES (?, ?, ?)`, o.Table),
+			string(encoded), now, now)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", o.Singular, err)
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		return auditIn(tx, o, id, core.AuditCreate, stored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Record{ID: id, Properties: stored, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// Get fetches a record by ID, returning sql.ErrNoRows if it doesn't exist
+func (s *HubSpotStore) Get(o *crmObject, id int64) (*Record, error) {
+	row := s.db.QueryRow(fmt.Sprintf(`SELECT id, properties, created_at, updated_at FROM %s WHERE id = ?`, o.Table), id)
+	return scanRecord(row)
+}
+
+// Update merges validated property values into a record. An empty value
+// clears the property.
+func (s *HubSpotStore) Update(o *crmObject, id int64, properties map[string]string) (*Record, error) {
+	rec, err := s.Get(o, id)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range properties {
+		if v == "" {
+			delete(rec.Properties, k)
+		} else {
+			rec.Properties[k] = v
+		}
+	}
+	encoded, err := json.Marshal(rec.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	rec.UpdatedAt = core.Now().UTC()
+	err = s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s SET properties = ?, updated_at = ? WHERE id = ?`, o.Table),
+			string(encoded), rec.UpdatedAt, id); err != nil {
+			return fmt.Errorf("failed to update %s: %w", o.Singular, err)
+		}
+		return auditIn(tx, o, id, core.AuditUpdate, properties)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Delete removes a record and its associations
+func (s *HubSpotStore) Delete(o *crmObject, id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, o.Table), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", o.Singular, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Exec(`DELETE FROM hubspot_associations WHERE (from_type = ? AND from_id = ?) OR (to_type = ? AND to_id = ?)`,
+		o.Name, id, o.Name, id); err != nil {
+		return fmt.Errorf("failed to delete associations: %w", err)
+	}
+	if err := auditIn(tx, o, id, core.AuditDelete, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// List returns up to limit records with IDs after the given cursor, in ID order
+func (s *HubSpotStore) List(o *crmObject, after int64, limit int) ([]Record, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT id, properties, created_at, updated_at FROM %s WHERE id > ? ORDER BY id LIMIT ?`, o.Table),
+		after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", o.Name, err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *rec)
+	}
+	return records, rows.Err()
+}
+
+// ListPage returns records newest first with offset paging, for the admin UI
+func (s *HubSpotStore) ListPage(o *crmObject, limit, offset int) ([]Record, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT id, properties, created_at, updated_at FROM %s ORDER BY id DESC LIMIT ? OFFSET ?`, o.Table),
+		limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", o.Name, err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *rec)
+	}
+	return records, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecord(row rowScanner) (*Record, error) {
+	var rec Record
+	var properties string
+	if err := row.Scan(&rec.ID, &properties, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(properties), &rec.Properties); err != nil {
+		return nil, fmt.Errorf("failed to decode properties: %w", err)
+	}
+	if rec.Properties == nil {
+		rec.Properties = map[string]string{}
+	}
+	return &rec, nil
+}
+
+// Associate links two records in both directions. Both must exist.
+func (s *HubSpotStore) Associate(from *crmObject, fromID int64, to *crmObject, toID int64) error {
+	for _, ref := range []struct {
+		o  *crmObject
+		id int64
+	}{{from, fromID}, {to, toID}} {
+		if _, err := s.Get(ref.o, ref.id); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO hubspot_associations (from_type, from_id, to_type, to_id, association_type)
+		VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)`,
+		from.Name, fromID, to.Name, toID, associationType(from, to),
+		to.Name, toID, from.Name, fromID, associationType(to, from))
+	if err != nil {
+		return fmt.Errorf("failed to associate %s with %s: %w", from.Singular, to.Singular, err)
+	}
+	return nil
+}
+
+// Disassociate removes the link between two records in both directions
+func (s *HubSpotStore) Disassociate(from *crmObject, fromID int64, to *crmObject, toID int64) error {
+	_, err := s.db.Exec(`DELETE FROM hubspot_associations
+		WHERE (from_type = ? AND from_id = ? AND to_type = ? AND to_id = ?)
+		   OR (from_type = ? AND from_id = ? AND to_type = ? AND to_id = ?)`,
+		from.Name, fromID, to.Name, toID, to.Name, toID, from.Name, fromID)
+	if err != nil {
+		return fmt.Errorf("failed to remove association: %w", err)
+	}
+	return nil
+}
+
+// ListAssociations returns the records of type to linked to a record
+func (s *HubSpotStore) ListAssociations(from *crmObject, fromID int64, to *crmObject) ([]Association, error) {
+	rows, err := s.db.Query(`SELECT to_id, association_type FROM hubspot_associations
+		WHERE from_type = ? AND from_id = ? AND to_type = ? ORDER BY to_id`,
+		from.Name, fromID, to.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list associations: %w", err)
+	}
+	defer rows.Close()
+
+	associations := []Association{}
+	for rows.Next() {
+		var a Association
+		if err := rows.Scan(&a.ID, &a.Type); err != nil {
+			return nil, err
+		}
+		associations = append(associations, a)
+	}
+	return associations, rows.Err()
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *HubSpotStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db, "hubspot_contacts", "hubspot_companies", "hubspot_deals", "hubspot_associations")
+}
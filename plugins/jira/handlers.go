@@ -0,0 +1,519 @@
+// ABOUTME: HTTP handlers for Jira REST API v3 endpoints
+// ABOUTME: Implements projects, issues, transitions, comments, and JQL search
+
+package jira
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// jiraTimeFormat is the timestamp layout used throughout the Jira API
+const jiraTimeFormat = "2006-01-02T15:04:05.000-0700"
+
+// Transition represents a workflow transition available on every issue
+type Transition struct {
+	ID       string
+	Name     string
+	ToStatus string
+}
+
+// workflow is the simplified "To Do → In Progress → Done" Jira software workflow
+var workflow = []Transition{
+	{ID: "11", Name: "To Do", ToStatus: "To Do"},
+	{ID: "21", Name: "In Progress", ToStatus: "In Progress"},
+	{ID: "31", Name: "Done", ToStatus: "Done"},
+}
+
+// statusCategories maps each workflow status to its Jira status category
+var statusCategories = map[string]map[string]any{
+	"To Do":       {"id": 2, "key": "new", "name": "To Do", "colorName": "blue-gray"},
+	"In Progress": {"id": 4, "key": "indeterminate", "name": "In Progress", "colorName": "yellow"},
+	"Done":        {"id": 3, "key": "done", "name": "Done", "colorName": "green"},
+}
+
+var statusIDs = map[string]string{"To Do": "10000", "In Progress": "3", "Done": "10001"}
+
+var validIssueTypes = map[string]bool{"Task": true, "Bug": true, "Story": true, "Epic": true, "Subtask": true}
+var validPriorities = map[string]bool{"Highest": true, "High": true, "Medium": true, "Low": true, "Lowest": true}
+
+// baseURL returns the scheme, host, and base path used for "self" links
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + core.BasePath(r)
+}
+
+// textToADF wraps plain text in an Atlassian Document Format document
+func textToADF(text string) any {
+	if text == "" {
+		return nil
+	}
+	var paragraphs []map[string]any
+	for _, line := range strings.Split(text, "\n") {
+		para := map[string]any{"type": "paragraph", "content": []map[string]any{}}
+		if line != "" {
+			para["content"] = []map[string]any{{"type": "text", "text": line}}
+		}
+		paragraphs = append(paragraphs, para)
+	}
+	return map[string]any{"type": "doc", "version": 1, "content": paragraphs}
+}
+
+// adfToText flattens a description or comment body to plain text. Jira v3
+// expects ADF, but plain strings are accepted too for convenience.
+func adfToText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var node struct {
+		Type    string            `json:"type"`
+		Text    string            `json:"text"`
+		Content []json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return ""
+	}
+	if node.Type == "text" {
+		return node.Text
+	}
+	if node.Type == "hardBreak" {
+		return "\n"
+	}
+
+	parts := make([]string, 0, len(node.Content))
+	for _, child := range node.Content {
+		parts = append(parts, adfToText(child))
+	}
+	if node.Type == "doc" || node.Type == "bulletList" || node.Type == "orderedList" {
+		return strings.Join(parts, "\n")
+	}
+	return strings.Join(parts, "")
+}
+
+func formatUser(email string) any {
+	if email == "" {
+		return nil
+	}
+	return map[string]any{
+		"accountId":    email,
+		"emailAddress": email,
+		"displayName":  strings.Split(email, "@")[0],
+		"active":       true,
+	}
+}
+
+func formatProject(r *http.Request, proj *Project) map[string]any {
+	return map[string]any{
+		"id":             strconv.FormatInt(proj.ID, 10),
+		"key":            proj.Key,
+		"name":           proj.Name,
+		"self":           fmt.Sprintf("%s/rest/api/3/project/%d", baseURL(r), proj.ID),
+		"projectTypeKey": proj.ProjectType,
+		"lead":           formatUser(proj.Lead),
+	}
+}
+
+func formatStatus(status string) map[string]any {
+	return map[string]any{
+		"id":             statusIDs[status],
+		"name":           status,
+		"statusCategory": statusCategories[status],
+	}
+}
+
+func formatComment(r *http.Request, issue *Issue, c *Comment) map[string]any {
+	return map[string]any{
+		"id":      strconv.FormatInt(c.ID, 10),
+		"self":    fmt.Sprintf("%s/rest/api/3/issue/%d/comment/%d", baseURL(r), issue.ID, c.ID),
+		"author":  formatUser(c.Author),
+		"body":    textToADF(c.Body),
+		"created": c.CreatedAt.Format(jiraTimeFormat),
+		"updated": c.UpdatedAt.Format(jiraTimeFormat),
+	}
+}
+
+func (p *JiraPlugin) formatIssue(r *http.Request, issue *Issue) map[string]any {
+	fields := map[string]any{
+		"summary":     issue.Summary,
+		"description": textToADF(issue.Description),
+		"status":      formatStatus(issue.Status),
+		"issuetype":   map[string]any{"name": issue.IssueType, "subtask": issue.IssueType == "Subtask"},
+		"priority":    map[string]any{"name": issue.Priority},
+		"assignee":    formatUser(issue.Assignee),
+		"reporter":    formatUser(issue.Reporter),
+		"created":     issue.CreatedAt.Format(jiraTimeFormat),
+		"updated":     issue.UpdatedAt.Format(jiraTimeFormat),
+	}
+	if proj, err := p.store.GetProject(issue.ProjectKey); err == nil {
+		fields["project"] = formatProject(r, proj)
+	}
+
+	return map[string]any{
+		"id":     strconv.FormatInt(issue.ID, 10),
+		"key":    issue.Key,
+		"self":   fmt.Sprintf("%s/rest/api/3/issue/%d", baseURL(r), issue.ID),
+		"fields": fields,
+	}
+}
+
+// lookupIssue loads the issue named in the URL, writing a 404 if missing
+func (p *JiraPlugin) lookupIssue(w http.ResponseWriter, r *http.Request) (*Issue, bool) {
+	issue, err := p.store.GetIssue(chi.URLParam(r, "issueIdOrKey"))
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return nil, false
+	}
+	return issue, true
+}
+
+// issueFields is the "fields" object accepted on create and edit
+type issueFields struct {
+	Project *struct {
+		Key string `json:"key"`
+		ID  string `json:"id"`
+	} `json:"project"`
+	Summary     *string         `json:"summary"`
+	Description json.RawMessage `json:"description"`
+	IssueType   *struct {
+		Name string `json:"name"`
+	} `json:"issuetype"`
+	Priority *struct {
+		Name string `json:"name"`
+	} `json:"priority"`
+	Assignee *struct {
+		AccountID string `json:"accountId"`
+	} `json:"assignee"`
+}
+
+// applyFields copies provided fields onto the issue, returning the offending field on validation failure
+func applyFields(issue *Issue, f issueFields) (string, string) {
+	if f.Summary != nil {
+		if strings.TrimSpace(*f.Summary) == "" {
+			return "summary", "You must specify a summary of the issue."
+		}
+		issue.Summary = *f.Summary
+	}
+	if f.Description != nil {
+		issue.Description = adfToText(f.Description)
+	}
+	if f.IssueType != nil {
+		if !validIssueTypes[f.IssueType.Name] {
+			return "issuetype", "Specify a valid issue type"
+		}
+		issue.IssueType = f.IssueType.Name
+	}
+	if f.Priority != nil {
+		if !validPriorities[f.Priority.Name] {
+			return "priority", "Specify a valid priority"
+		}
+		issue.Priority = f.Priority.Name
+	}
+	if f.Assignee != nil {
+		issue.Assignee = f.Assignee.AccountID
+	}
+	return "", ""
+}
+
+// listProjects handles GET /rest/api/3/project
+func (p *JiraPlugin) listProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := p.store.ListProjects()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	result := make([]map[string]any, 0, len(projects))
+	for _, proj := range projects {
+		result = append(result, formatProject(r, proj))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// createIssue handles POST /rest/api/3/issue
+func (p *JiraPlugin) createIssue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Fields issueFields `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return
+	}
+
+	if req.Fields.Project == nil {
+		writeFieldError(w, http.StatusBadRequest, "project", "Specify a valid project ID or key")
+		return
+	}
+	projectRef := req.Fields.Project.Key
+	if projectRef == "" {
+		projectRef = req.Fields.Project.ID
+	}
+	proj, err := p.store.GetProject(projectRef)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, "project", "Specify a valid project ID or key")
+		return
+	}
+	if req.Fields.Summary == nil {
+		writeFieldError(w, http.StatusBadRequest, "summary", "You must specify a summary of the issue.")
+		return
+	}
+
+	issue := &Issue{ProjectKey: proj.Key, Reporter: userFromContext(r.Context())}
+	if field, msg := applyFields(issue, req.Fields); field != "" {
+		writeFieldError(w, http.StatusBadRequest, field, msg)
+		return
+	}
+
+	if err := p.store.CreateIssue(issue); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to create issue")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":   strconv.FormatInt(issue.ID, 10),
+		"key":  issue.Key,
+		"self": fmt.Sprintf("%s/rest/api/3/issue/%d", baseURL(r), issue.ID),
+	})
+}
+
+// getIssue handles GET /rest/api/3/issue/{issueIdOrKey}
+func (p *JiraPlugin) getIssue(w http.ResponseWriter, r *http.Request) {
+	issue, ok := p.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p.issueWithComments(r, issue))
+}
+
+// issueWithComments formats an issue with its comments embedded, as the
+// single-issue GET returns it
+func (p *JiraPlugin) issueWithComments(r *http.Request, issue *Issue) map[string]any {
+	resp := p.formatIssue(r, issue)
+	comments, err := p.store.ListComments(issue.ID)
+	if err == nil {
+		formatted := make([]map[string]any, 0, len(comments))
+		for _, c := range comments {
+			formatted = append(formatted, formatComment(r, issue, c))
+		}
+		resp["fields"].(map[string]any)["comment"] = map[string]any{
+			"comments":   formatted,
+			"total":      len(formatted),
+			"maxResults": len(formatted),
+			"startAt":    0,
+		}
+	}
+	return resp
+}
+
+// updateIssue handles PUT /rest/api/3/issue/{issueIdOrKey}
+func (p *JiraPlugin) updateIssue(w http.ResponseWriter, r *http.Request) {
+	issue, ok := p.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Fields issueFields `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return
+	}
+	if req.Fields.Project != nil {
+		writeFieldError(w, http.StatusBadRequest, "project", "Field 'project' cannot be set. It is not on the appropriate screen, or unknown.")
+		return
+	}
+
+	if field, msg := applyFields(issue, req.Fields); field != "" {
+		writeFieldError(w, http.StatusBadRequest, field, msg)
+		return
+	}
+
+	if err := p.store.UpdateIssue(issue, userFromContext(r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to update issue")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listTransitions handles GET /rest/api/3/issue/{issueIdOrKey}/transitions
+func (p *JiraPlugin) listTransitions(w http.ResponseWriter, r *http.Request) {
+	issue, ok := p.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	transitions := make([]map[string]any, 0, len(workflow))
+	for _, t := range workflow {
+		if t.ToStatus == issue.Status {
+			continue
+		}
+		transitions = append(transitions, map[string]any{
+			"id":   t.ID,
+			"name": t.Name,
+			"to":   formatStatus(t.ToStatus),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"transitions": transitions})
+}
+
+// transitionIssue handles POST /rest/api/3/issue/{issueIdOrKey}/transitions
+func (p *JiraPlugin) transitionIssue(w http.ResponseWriter, r *http.Request) {
+	issue, ok := p.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Transition struct {
+			ID string `json:"id"`
+		} `json:"transition"`
+		Fields issueFields `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return
+	}
+
+	var target *Transition
+	for i := range workflow {
+		if workflow[i].ID == req.Transition.ID {
+			target = &workflow[i]
+			break
+		}
+	}
+	if target == nil || target.ToStatus == issue.Status {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Transition id '%s' is not valid for this issue.", req.Transition.ID))
+		return
+	}
+
+	if field, msg := applyFields(issue, req.Fields); field != "" {
+		writeFieldError(w, http.StatusBadRequest, field, msg)
+		return
+	}
+	issue.Status = target.ToStatus
+
+	if err := p.store.UpdateIssue(issue, userFromContext(r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to transition issue")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listComments handles GET /rest/api/3/issue/{issueIdOrKey}/comment
+func (p *JiraPlugin) listComments(w http.ResponseWriter, r *http.Request) {
+	issue, ok := p.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	comments, err := p.store.ListComments(issue.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	formatted := make([]map[string]any, 0, len(comments))
+	for _, c := range comments {
+		formatted = append(formatted, formatComment(r, issue, c))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"comments":   formatted,
+		"total":      len(formatted),
+		"maxResults": len(formatted),
+		"startAt":    0,
+	})
+}
+
+// addComment handles POST /rest/api/3/issue/{issueIdOrKey}/comment
+func (p *JiraPlugin) addComment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := p.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Body json.RawMessage `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload. Refer to the REST API documentation and try again.")
+		return
+	}
+
+	body := adfToText(req.Body)
+	if strings.TrimSpace(body) == "" {
+		writeFieldError(w, http.StatusBadRequest, "comment", "Comment body can not be empty!")
+		return
+	}
+
+	comment, err := p.store.CreateComment(issue.ID, userFromContext(r.Context()), body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to add comment")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, formatComment(r, issue, comment))
+}
+
+// searchIssues handles GET /rest/api/3/search
+func (p *JiraPlugin) searchIssues(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	startAt, _ := strconv.Atoi(query.Get("startAt"))
+	if startAt < 0 {
+		startAt = 0
+	}
+	maxResults := 50
+	if mr, err := strconv.Atoi(query.Get("maxResults")); err == nil && mr >= 0 {
+		maxResults = mr
+	}
+	if maxResults > 100 {
+		maxResults = 100
+	}
+
+	jql, err := ParseJQL(query.Get("jql"), userFromContext(r.Context()))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Error in the JQL Query: "+err.Error())
+		return
+	}
+
+	issues, total, err := p.store.SearchIssues(jql, startAt, maxResults)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	formatted := make([]map[string]any, 0, len(issues))
+	for _, issue := range issues {
+		formatted = append(formatted, p.formatIssue(r, issue))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"expand":     "schema,names",
+		"startAt":    startAt,
+		"maxResults": maxResults,
+		"total":      total,
+		"issues":     formatted,
+	})
+}
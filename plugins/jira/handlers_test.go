@@ -0,0 +1,293 @@
+// ABOUTME: Tests for Jira REST API v3 handlers
+// ABOUTME: Covers auth, issue CRUD, transitions, comments, and JQL search
+
+package jira
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/2389/ish/internal/migrations"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const testUser = "tester@example.com"
+
+func setupTestPlugin(t *testing.T) (*JiraPlugin, chi.Router) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	plugin := &JiraPlugin{}
+	if err := plugin.SetDB(db); err != nil {
+		t.Fatalf("Failed to initialize plugin: %v", err)
+	}
+	if _, err := plugin.Seed(context.Background(), "small"); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+	return plugin, r
+}
+
+func doRequest(t *testing.T, r chi.Router, method, target string, body any, wantStatus int) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("Failed to encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, target, &buf)
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(testUser+":api-token")))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != wantStatus {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, target, wantStatus, rr.Code, rr.Body.String())
+	}
+	if rr.Body.Len() == 0 {
+		return nil
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestRequireAuth(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	for _, header := range []string{"", "Bearer abc", "Basic not-base64!", "Basic " + base64.StdEncoding.EncodeToString([]byte("nopassword"))} {
+		req := httptest.NewRequest("GET", "/rest/api/3/project", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", header, rr.Code)
+		}
+	}
+}
+
+func TestListProjects(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	req := httptest.NewRequest("GET", "/rest/api/3/project", nil)
+	req.SetBasicAuth(testUser, "api-token")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rr.Code)
+	}
+
+	var projects []map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &projects); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("Expected 2 projects, got %d", len(projects))
+	}
+}
+
+func TestCreateGetUpdateIssue(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	created := doRequest(t, r, "POST", "/rest/api/3/issue", map[string]any{
+		"fields": map[string]any{
+			"project":   map[string]string{"key": "PROJ"},
+			"summary":   "Write the release notes",
+			"issuetype": map[string]string{"name": "Story"},
+			"description": map[string]any{
+				"type": "doc", "version": 1,
+				"content": []any{map[string]any{"type": "paragraph", "content": []any{
+					map[string]any{"type": "text", "text": "Cover the new search API"},
+				}}},
+			},
+		},
+	}, http.StatusCreated)
+	if created["key"] != "PROJ-4" {
+		t.Fatalf("Expected key PROJ-4, got %v", created["key"])
+	}
+
+	issue := doRequest(t, r, "GET", "/rest/api/3/issue/PROJ-4", nil, http.StatusOK)
+	fields := issue["fields"].(map[string]any)
+	if fields["summary"] != "Write the release notes" {
+		t.Errorf("Unexpected summary: %v", fields["summary"])
+	}
+	if got := adfToText(mustMarshal(t, fields["description"])); got != "Cover the new search API" {
+		t.Errorf("Unexpected description: %q", got)
+	}
+	if fields["status"].(map[string]any)["name"] != "To Do" {
+		t.Errorf("Expected new issue in To Do, got %v", fields["status"])
+	}
+	if fields["reporter"].(map[string]any)["emailAddress"] != testUser {
+		t.Errorf("Expected reporter %s, got %v", testUser, fields["reporter"])
+	}
+
+	doRequest(t, r, "PUT", "/rest/api/3/issue/PROJ-4", map[string]any{
+		"fields": map[string]any{
+			"summary":  "Write the 2.0 release notes",
+			"priority": map[string]string{"name": "High"},
+			"assignee": map[string]string{"accountId": "alice@example.com"},
+		},
+	}, http.StatusNoContent)
+
+	// Lookup by numeric ID should work too
+	issue = doRequest(t, r, "GET", "/rest/api/3/issue/"+created["id"].(string), nil, http.StatusOK)
+	fields = issue["fields"].(map[string]any)
+	if fields["summary"] != "Write the 2.0 release notes" {
+		t.Errorf("Summary not updated: %v", fields["summary"])
+	}
+	if fields["priority"].(map[string]any)["name"] != "High" {
+		t.Errorf("Priority not updated: %v", fields["priority"])
+	}
+
+	doRequest(t, r, "GET", "/rest/api/3/issue/PROJ-999", nil, http.StatusNotFound)
+}
+
+func TestCreateIssueValidation(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	resp := doRequest(t, r, "POST", "/rest/api/3/issue", map[string]any{
+		"fields": map[string]any{"project": map[string]string{"key": "NOPE"}, "summary": "x"},
+	}, http.StatusBadRequest)
+	if _, ok := resp["errors"].(map[string]any)["project"]; !ok {
+		t.Errorf("Expected project field error, got %v", resp)
+	}
+
+	resp = doRequest(t, r, "POST", "/rest/api/3/issue", map[string]any{
+		"fields": map[string]any{"project": map[string]string{"key": "PROJ"}},
+	}, http.StatusBadRequest)
+	if _, ok := resp["errors"].(map[string]any)["summary"]; !ok {
+		t.Errorf("Expected summary field error, got %v", resp)
+	}
+}
+
+func TestTransitionIssue(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	resp := doRequest(t, r, "GET", "/rest/api/3/issue/PROJ-1/transitions", nil, http.StatusOK)
+	if n := len(resp["transitions"].([]any)); n != 2 {
+		t.Fatalf("Expected 2 transitions from To Do, got %d", n)
+	}
+
+	doRequest(t, r, "POST", "/rest/api/3/issue/PROJ-1/transitions", map[string]any{
+		"transition": map[string]string{"id": "21"},
+	}, http.StatusNoContent)
+
+	issue := doRequest(t, r, "GET", "/rest/api/3/issue/PROJ-1", nil, http.StatusOK)
+	if status := issue["fields"].(map[string]any)["status"].(map[string]any)["name"]; status != "In Progress" {
+		t.Errorf("Expected In Progress, got %v", status)
+	}
+
+	doRequest(t, r, "POST", "/rest/api/3/issue/PROJ-1/transitions", map[string]any{
+		"transition": map[string]string{"id": "21"},
+	}, http.StatusBadRequest)
+	doRequest(t, r, "POST", "/rest/api/3/issue/PROJ-1/transitions", map[string]any{
+		"transition": map[string]string{"id": "999"},
+	}, http.StatusBadRequest)
+}
+
+func TestAddComment(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	comment := doRequest(t, r, "POST", "/rest/api/3/issue/ENG-1/comment", map[string]any{
+		"body": "Plain text works too",
+	}, http.StatusCreated)
+	if comment["author"].(map[string]any)["emailAddress"] != testUser {
+		t.Errorf("Unexpected author: %v", comment["author"])
+	}
+
+	resp := doRequest(t, r, "GET", "/rest/api/3/issue/ENG-1/comment", nil, http.StatusOK)
+	if total := resp["total"].(float64); total != 3 {
+		t.Errorf("Expected 3 comments (2 seeded + 1 new), got %v", total)
+	}
+
+	doRequest(t, r, "POST", "/rest/api/3/issue/ENG-1/comment", map[string]any{"body": ""}, http.StatusBadRequest)
+}
+
+func TestSearchJQL(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	search := func(jql string) map[string]any {
+		return doRequest(t, r, "GET", "/rest/api/3/search?jql="+url.QueryEscape(jql), nil, http.StatusOK)
+	}
+
+	resp := search(`project = PROJ AND status = "To Do"`)
+	if total := resp["total"].(float64); total != 1 {
+		t.Fatalf("Expected 1 To Do issue in PROJ, got %v", total)
+	}
+	if key := resp["issues"].([]any)[0].(map[string]any)["key"]; key != "PROJ-1" {
+		t.Errorf("Expected PROJ-1, got %v", key)
+	}
+
+	tests := []struct {
+		jql  string
+		want float64
+	}{
+		{``, 6},
+		{`project = ENG`, 3},
+		{`project = eng AND status != Done`, 3},
+		{`status in ("In Progress", Done)`, 3},
+		{`project = PROJ AND status not in (Done)`, 2},
+		{`assignee is EMPTY`, 2},
+		{`summary ~ "release"`, 0},
+		{`summary ~ "limiting"`, 1},
+		{`issuetype = Bug ORDER BY created DESC`, 1},
+	}
+	for _, tt := range tests {
+		if got := search(tt.jql)["total"].(float64); got != tt.want {
+			t.Errorf("JQL %q: expected %v results, got %v", tt.jql, tt.want, got)
+		}
+	}
+
+	resp = doRequest(t, r, "GET", "/rest/api/3/search?maxResults=2&startAt=1&jql="+url.QueryEscape("ORDER BY key ASC"), nil, http.StatusOK)
+	issues := resp["issues"].([]any)
+	if len(issues) != 2 || resp["total"].(float64) != 6 {
+		t.Fatalf("Expected page of 2 out of 6, got %d of %v", len(issues), resp["total"])
+	}
+
+	doRequest(t, r, "GET", "/rest/api/3/search?jql="+url.QueryEscape("bogus = 1"), nil, http.StatusBadRequest)
+	doRequest(t, r, "GET", "/rest/api/3/search?jql="+url.QueryEscape("project = PROJ OR project = ENG"), nil, http.StatusBadRequest)
+}
+
+func TestSearchCurrentUser(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	doRequest(t, r, "PUT", "/rest/api/3/issue/ENG-2", map[string]any{
+		"fields": map[string]any{"assignee": map[string]string{"accountId": testUser}},
+	}, http.StatusNoContent)
+
+	resp := doRequest(t, r, "GET", "/rest/api/3/search?jql="+url.QueryEscape("assignee = currentUser()"), nil, http.StatusOK)
+	if total := resp["total"].(float64); total != 1 {
+		t.Errorf("Expected 1 issue assigned to current user, got %v", total)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,32 @@
+// ABOUTME: core.Inspector implementation for the Jira plugin
+// ABOUTME: Renders projects and issues as the REST API v3 does
+
+package jira
+
+import (
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// Inspect implements core.Inspector for `ish inspect`. IDs may be numeric
+// IDs or keys (PROJ, PROJ-1).
+func (p *JiraPlugin) Inspect(resource, id string) (interface{}, error) {
+	r := core.InspectRequest()
+	switch resource {
+	case "projects":
+		proj, err := p.store.GetProject(id)
+		if err != nil {
+			return nil, err
+		}
+		return formatProject(r, proj), nil
+	case "issues":
+		issue, err := p.store.GetIssue(id)
+		if err != nil {
+			return nil, err
+		}
+		return p.issueWithComments(r, issue), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
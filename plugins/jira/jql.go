@@ -0,0 +1,260 @@
+// ABOUTME: Minimal JQL (Jira Query Language) parser for the search endpoint
+// ABOUTME: Compiles AND-joined field clauses and ORDER BY into SQL
+
+package jira
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// jqlFields maps JQL field names to jira_issues columns
+var jqlFields = map[string]string{
+	"project":   "project_key",
+	"status":    "status",
+	"issuetype": "issue_type",
+	"type":      "issue_type",
+	"priority":  "priority",
+	"assignee":  "assignee",
+	"reporter":  "reporter",
+	"key":       "key",
+	"issuekey":  "key",
+	"id":        "id",
+	"summary":   "summary",
+	"text":      "summary || ' ' || COALESCE(description, '')",
+	"created":   "created_at",
+	"updated":   "updated_at",
+}
+
+// jqlClause is a single "field op value(s)" condition
+type jqlClause struct {
+	column string
+	op     string // "=", "!=", "~", "in", "not in", "is", "is not"
+	values []string
+}
+
+// JQLQuery is a parsed JQL expression
+type JQLQuery struct {
+	clauses  []jqlClause
+	orderBy  string
+	orderDir string
+}
+
+// ParseJQL parses a subset of JQL: clauses joined by AND using =, !=, ~,
+// IN, NOT IN, IS [NOT] EMPTY, followed by an optional ORDER BY.
+// currentUser() is replaced with the given user.
+func ParseJQL(jql, currentUser string) (*JQLQuery, error) {
+	tokens, err := tokenizeJQL(jql)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &JQLQuery{}
+	pos := 0
+	peek := func() string {
+		if pos < len(tokens) {
+			return tokens[pos]
+		}
+		return ""
+	}
+	next := func() string {
+		tok := peek()
+		pos++
+		return tok
+	}
+	value := func(tok string) string {
+		if strings.EqualFold(tok, "currentUser()") {
+			return currentUser
+		}
+		return strings.Trim(tok, `"'`)
+	}
+
+	for pos < len(tokens) {
+		if strings.EqualFold(peek(), "order") {
+			break
+		}
+
+		field := strings.ToLower(value(next()))
+		column, ok := jqlFields[field]
+		if !ok {
+			return nil, fmt.Errorf("Field '%s' does not exist or you do not have permission to view it.", field)
+		}
+
+		clause := jqlClause{column: column}
+		op := strings.ToLower(next())
+		switch op {
+		case "=", "!=", "~":
+			clause.op = op
+			tok := next()
+			if tok == "" {
+				return nil, fmt.Errorf("Expecting a value after '%s'.", op)
+			}
+			clause.values = []string{value(tok)}
+		case "in":
+			clause.op = "in"
+		case "not":
+			if !strings.EqualFold(next(), "in") {
+				return nil, fmt.Errorf("Expecting 'in' after 'not'.")
+			}
+			clause.op = "not in"
+		case "is":
+			clause.op = "is"
+			if strings.EqualFold(peek(), "not") {
+				next()
+				clause.op = "is not"
+			}
+			if tok := strings.ToLower(next()); tok != "empty" && tok != "null" {
+				return nil, fmt.Errorf("Expecting EMPTY or NULL after '%s'.", clause.op)
+			}
+		default:
+			return nil, fmt.Errorf("Unsupported operator '%s'.", op)
+		}
+
+		if clause.op == "in" || clause.op == "not in" {
+			if next() != "(" {
+				return nil, fmt.Errorf("Expecting '(' after '%s'.", clause.op)
+			}
+			for {
+				tok := next()
+				if tok == "" {
+					return nil, fmt.Errorf("Expecting ')' to close the list.")
+				}
+				if tok == ")" {
+					break
+				}
+				if tok == "," {
+					continue
+				}
+				clause.values = append(clause.values, value(tok))
+			}
+		}
+		q.clauses = append(q.clauses, clause)
+
+		switch strings.ToLower(peek()) {
+		case "and":
+			next()
+		case "or":
+			return nil, fmt.Errorf("OR is not supported by this mock; use IN instead.")
+		case "", "order":
+		default:
+			return nil, fmt.Errorf("Expecting 'AND' or 'ORDER BY' but got '%s'.", peek())
+		}
+	}
+
+	if strings.EqualFold(peek(), "order") {
+		next()
+		if !strings.EqualFold(next(), "by") {
+			return nil, fmt.Errorf("Expecting 'BY' after 'ORDER'.")
+		}
+		field := strings.ToLower(next())
+		column, ok := jqlFields[field]
+		if !ok {
+			return nil, fmt.Errorf("Not able to sort using field '%s'.", field)
+		}
+		q.orderBy = column
+		q.orderDir = "ASC"
+		if dir := strings.ToUpper(peek()); dir == "ASC" || dir == "DESC" {
+			q.orderDir = dir
+			next()
+		}
+	}
+
+	if pos < len(tokens) {
+		return nil, fmt.Errorf("Unexpected token '%s'.", peek())
+	}
+	return q, nil
+}
+
+// tokenizeJQL splits JQL into words, quoted strings, operators, and punctuation
+func tokenizeJQL(jql string) ([]string, error) {
+	var tokens []string
+	runes := []rune(jql)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != c {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("Unterminated quoted string.")
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, "!=")
+			i += 2
+		case c == '=' || c == '~':
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()=!~,"'`, runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			// Keep function calls such as currentUser() as one token
+			if i+1 < len(runes) && runes[i] == '(' && runes[i+1] == ')' {
+				word += "()"
+				i += 2
+			}
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens, nil
+}
+
+// SQL returns a WHERE clause (with leading space) and its arguments.
+// String comparisons are case-insensitive, matching Jira.
+func (q *JQLQuery) SQL() (string, []any) {
+	if len(q.clauses) == 0 {
+		return "", nil
+	}
+
+	var conds []string
+	var args []any
+	for _, c := range q.clauses {
+		col := c.column
+		switch c.op {
+		case "=":
+			conds = append(conds, col+" = ? COLLATE NOCASE")
+			args = append(args, c.values[0])
+		case "!=":
+			conds = append(conds, "COALESCE("+col+", '') != ? COLLATE NOCASE")
+			args = append(args, c.values[0])
+		case "~":
+			conds = append(conds, col+" LIKE ?")
+			args = append(args, "%"+c.values[0]+"%")
+		case "in", "not in":
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(c.values)), ", ")
+			op := "IN"
+			if c.op == "not in" {
+				op = "NOT IN"
+			}
+			conds = append(conds, fmt.Sprintf("%s COLLATE NOCASE %s (%s)", col, op, placeholders))
+			for _, v := range c.values {
+				args = append(args, v)
+			}
+		case "is":
+			conds = append(conds, "("+col+" IS NULL OR "+col+" = '')")
+		case "is not":
+			conds = append(conds, "("+col+" IS NOT NULL AND "+col+" != '')")
+		}
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// OrderSQL returns the ORDER BY clause (with leading space), defaulting to newest first
+func (q *JQLQuery) OrderSQL() string {
+	if q.orderBy == "" {
+		return " ORDER BY created_at DESC, id DESC"
+	}
+	return fmt.Sprintf(" ORDER BY %s %s, id %s", q.orderBy, q.orderDir, q.orderDir)
+}
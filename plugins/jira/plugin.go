@@ -0,0 +1,213 @@
+// ABOUTME: Jira Cloud REST API v3 plugin for ISH
+// ABOUTME: Simulates projects, issues, transitions, comments, and JQL search
+
+package jira
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+type contextKey string
+
+const userKey contextKey = "jira_user"
+
+func init() {
+	core.Register(&JiraPlugin{})
+}
+
+type JiraPlugin struct {
+	store *JiraStore
+}
+
+func (p *JiraPlugin) Name() string {
+	return "jira"
+}
+
+func (p *JiraPlugin) Health() core.HealthStatus {
+	return core.HealthStatus{
+		Status:  "healthy",
+		Message: "Jira plugin operational",
+	}
+}
+
+func (p *JiraPlugin) RegisterRoutes(r chi.Router) {
+	r.Route("/rest/api/3", func(r chi.Router) {
+		r.Get("/project", p.requireAuth(p.listProjects))
+		r.Get("/search", p.requireAuth(p.searchIssues))
+
+		r.Post("/issue", p.requireAuth(p.createIssue))
+		r.Get("/issue/{issueIdOrKey}", p.requireAuth(p.getIssue))
+		r.Put("/issue/{issueIdOrKey}", p.requireAuth(p.updateIssue))
+		r.Get("/issue/{issueIdOrKey}/transitions", p.requireAuth(p.listTransitions))
+		r.Post("/issue/{issueIdOrKey}/transitions", p.requireAuth(p.transitionIssue))
+		r.Get("/issue/{issueIdOrKey}/comment", p.requireAuth(p.listComments))
+		r.Post("/issue/{issueIdOrKey}/comment", p.requireAuth(p.addComment))
+	})
+}
+
+func (p *JiraPlugin) RegisterAuth(r chi.Router) {
+	// Jira Cloud uses HTTP Basic Auth (email + API token), handled per-request
+}
+
+// extractBasicAuth extracts username and password from HTTP Basic Auth header
+func extractBasicAuth(authHeader string) (username, password string, ok bool) {
+	const prefix = "Basic "
+	if !strings.HasPrefix(authHeader, prefix) {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(authHeader[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+
+	username, password, ok = strings.Cut(string(decoded), ":")
+	if !ok || username == "" || password == "" {
+		return "", "", false
+	}
+	return username, password, true
+}
+
+// requireAuth middleware validates HTTP Basic Auth (email + API token).
+// Any non-empty credential pair is accepted; the email becomes the acting user.
+func (p *JiraPlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, _, ok := extractBasicAuth(r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="ISH Jira"`)
+			writeError(w, http.StatusUnauthorized, "You are not authenticated. Authentication required to perform this operation.")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// userFromContext returns the authenticated Jira user email
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey).(string)
+	return user
+}
+
+func (p *JiraPlugin) ValidateToken(token string) bool {
+	_, _, ok := extractBasicAuth("Basic " + token)
+	return ok
+}
+
+func (p *JiraPlugin) SetDB(db *sql.DB) error {
+	store, err := NewJiraStore(db)
+	if err != nil {
+		return err
+	}
+	p.store = store
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Jira: Failed to encode response: %v", err)
+	}
+}
+
+// writeError writes a Jira-style error collection
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"errorMessages": []string{message},
+		"errors":        map[string]string{},
+	})
+}
+
+// writeFieldError writes a Jira-style error collection for a specific field
+func writeFieldError(w http.ResponseWriter, status int, field, message string) {
+	writeJSON(w, status, map[string]any{
+		"errorMessages": []string{},
+		"errors":        map[string]string{field: message},
+	})
+}
+
+// ListResources implements core.DataProvider to expose data to admin UI
+func (p *JiraPlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
+	switch slug {
+	case "projects":
+		projects, err := p.store.ListProjects()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(projects))
+		for _, proj := range projects {
+			result = append(result, convertProjectToMap(proj))
+		}
+		return result, nil
+	case "issues":
+		issues, err := p.store.ListAllIssues(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(issues))
+		for _, issue := range issues {
+			result = append(result, convertIssueToMap(issue))
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+// GetResource implements core.DataProvider to fetch individual resources
+func (p *JiraPlugin) GetResource(ctx context.Context, slug string, id string) (map[string]interface{}, error) {
+	switch slug {
+	case "projects":
+		proj, err := p.store.GetProject(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertProjectToMap(proj), nil
+	case "issues":
+		issue, err := p.store.GetIssue(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertIssueToMap(issue), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+func convertProjectToMap(proj *Project) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         proj.Key,
+		"key":        proj.Key,
+		"name":       proj.Name,
+		"lead":       proj.Lead,
+		"created_at": proj.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func convertIssueToMap(issue *Issue) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          issue.Key,
+		"key":         issue.Key,
+		"summary":     issue.Summary,
+		"description": issue.Description,
+		"status":      issue.Status,
+		"issue_type":  issue.IssueType,
+		"priority":    issue.Priority,
+		"assignee":    issue.Assignee,
+		"reporter":    issue.Reporter,
+		"created_at":  issue.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		"updated_at":  issue.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -0,0 +1,74 @@
+// ABOUTME: Admin UI schema definitions for Jira plugin
+// ABOUTME: Defines Projects and Issues resources for schema-driven UI
+
+package jira
+
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func (p *JiraPlugin) Schema() core.PluginSchema {
+	return core.PluginSchema{
+		Resources: []core.ResourceSchema{
+			{
+				Name:        "Projects",
+				Slug:        "projects",
+				ListColumns: []string{"key", "name", "lead", "created_at"},
+				Fields: []core.FieldSchema{
+					{Name: "key", Type: "string", Display: "Key", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Name", Required: true, Editable: false},
+					{Name: "lead", Type: "email", Display: "Lead", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Issues",
+				Slug:        "issues",
+				ListColumns: []string{"key", "summary", "status", "issue_type", "priority", "assignee"},
+				Fields: []core.FieldSchema{
+					{Name: "key", Type: "string", Display: "Key", Required: true, Editable: false},
+					{Name: "summary", Type: "string", Display: "Summary", Required: true, Editable: true},
+					{Name: "description", Type: "text", Display: "Description", Required: false, Editable: true},
+					{Name: "status", Type: "string", Display: "Status", Required: true, Editable: false},
+					{Name: "issue_type", Type: "string", Display: "Type", Required: true, Editable: false},
+					{Name: "priority", Type: "string", Display: "Priority", Required: false, Editable: true},
+					{Name: "assignee", Type: "email", Display: "Assignee", Required: false, Editable: true},
+					{Name: "reporter", Type: "email", Display: "Reporter", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "updated_at", Type: "datetime", Display: "Updated", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+		},
+	}
+}
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *JiraPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "jira", Table: "jira_issues", Resource: "issues"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *JiraPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"jira": {"jira_comments", "jira_issues", "jira_projects"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *JiraPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *JiraPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
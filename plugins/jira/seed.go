@@ -0,0 +1,64 @@
+// ABOUTME: Test data generation for Jira plugin
+// ABOUTME: Creates sample projects, issues across workflow states, and comments
+
+package jira
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// Seed creates test data for the Jira plugin
+func (p *JiraPlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
+	projects := []struct {
+		key, name, lead string
+	}{
+		{"PROJ", "Sample Project", "harper@example.com"},
+		{"ENG", "Engineering", "alice@example.com"},
+	}
+	for _, proj := range projects {
+		if _, err := p.store.CreateProject(proj.key, proj.name, proj.lead); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create project %s: %w", proj.key, err)
+		}
+	}
+
+	issues := []*Issue{
+		{ProjectKey: "PROJ", Summary: "Set up onboarding checklist", Description: "Document the first-week steps for new hires.", Status: "To Do", IssueType: "Task", Priority: "Medium", Reporter: "harper@example.com"},
+		{ProjectKey: "PROJ", Summary: "Quarterly planning doc", Description: "Draft goals for next quarter.", Status: "In Progress", IssueType: "Story", Priority: "High", Assignee: "harper@example.com", Reporter: "harper@example.com"},
+		{ProjectKey: "PROJ", Summary: "Update brand guidelines", Status: "Done", IssueType: "Task", Priority: "Low", Assignee: "bob@example.com", Reporter: "harper@example.com"},
+		{ProjectKey: "ENG", Summary: "Login fails with expired session cookie", Description: "Users see a blank page instead of the login form.\nRepro: wait 24h then refresh.", Status: "To Do", IssueType: "Bug", Priority: "Highest", Reporter: "bob@example.com"},
+		{ProjectKey: "ENG", Summary: "Migrate CI to new runners", Status: "In Progress", IssueType: "Task", Priority: "Medium", Assignee: "alice@example.com", Reporter: "alice@example.com"},
+		{ProjectKey: "ENG", Summary: "Add rate limiting to public API", Description: "Token bucket per API key.", Status: "To Do", IssueType: "Story", Priority: "High", Assignee: "alice@example.com", Reporter: "harper@example.com"},
+	}
+	for _, issue := range issues {
+		if err := p.store.CreateIssue(issue); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create issue %q: %w", issue.Summary, err)
+		}
+	}
+
+	comments := []struct {
+		issue  *Issue
+		author string
+		body   string
+	}{
+		{issues[1], "alice@example.com", "I added engineering headcount numbers to the doc."},
+		{issues[3], "alice@example.com", "Reproduced locally, looking into it."},
+		{issues[3], "bob@example.com", "Thanks! Happens on Safari too."},
+	}
+	for _, c := range comments {
+		if _, err := p.store.CreateComment(c.issue.ID, c.author, c.body); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create comment on %s: %w", c.issue.Key, err)
+		}
+	}
+
+	return core.SeedData{
+		Summary: fmt.Sprintf("Created %d projects, %d issues, %d comments", len(projects), len(issues), len(comments)),
+		Records: map[string]int{
+			"projects": len(projects),
+			"issues":   len(issues),
+			"comments": len(comments),
+		},
+	}, nil
+}
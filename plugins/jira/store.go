@@ -0,0 +1,323 @@
+// ABOUTME: Database layer for Jira plugin
+// ABOUTME: Manages jira_projects, jira_issues, and jira_comments tables
+
+package jira
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+type JiraStore struct {
+	db *sql.DB
+}
+
+// auditIn records a mutation of a Jira resource in the shared audit log
+// through db, the transaction making the change so both commit together
+func auditIn(db core.Execer, resourceType, resourceID, action, actor string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "jira",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
+func NewJiraStore(db *sql.DB) (*JiraStore, error) {
+	return &JiraStore{db: db}, nil
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *JiraStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type Project struct {
+	ID          int64
+	Key         string
+	Name        string
+	Lead        string
+	ProjectType string
+	CreatedAt   time.Time
+}
+
+type Issue struct {
+	ID          int64
+	Key         string
+	ProjectKey  string
+	Number      int
+	Summary     string
+	Description string
+	Status      string
+	IssueType   string
+	Priority    string
+	Assignee    string
+	Reporter    string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type Comment struct {
+	ID        int64
+	IssueID   int64
+	Author    string
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (s *JiraStore) CreateProject(key, name, lead string) (*Project, error) {
+	now := time.Now()
+	var id int64
+	err := s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`INSERT INTO jira_projects (key, name, lead, created_at) VALUES (?, ?, ?, ?)`,
+			key, name, lead, now)
+		if err != nil {
+			return err
+		}
+		id, _ = result.LastInsertId()
+		return auditIn(tx, "project", key, core.AuditCreate, lead, map[string]any{"name": name, "lead": lead})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Project{ID: id, Key: key, Name: name, Lead: lead, ProjectType: "software", CreatedAt: now}, nil
+}
+
+// GetProject looks up a project by key or numeric ID
+func (s *JiraStore) GetProject(keyOrID string) (*Project, error) {
+	p := &Project{}
+	var lead sql.NullString
+	err := s.db.QueryRow(`SELECT id, key, name, lead, project_type, created_at FROM jira_projects
+		WHERE key = ? OR CAST(id AS TEXT) = ?`, strings.ToUpper(keyOrID), keyOrID).Scan(
+		&p.ID, &p.Key, &p.Name, &lead, &p.ProjectType, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	p.Lead = lead.String
+	return p, nil
+}
+
+func (s *JiraStore) ListProjects() ([]*Project, error) {
+	rows, err := s.db.Query(`SELECT id, key, name, lead, project_type, created_at FROM jira_projects ORDER BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []*Project
+	for rows.Next() {
+		p := &Project{}
+		var lead sql.NullString
+		if err := rows.Scan(&p.ID, &p.Key, &p.Name, &lead, &p.ProjectType, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		p.Lead = lead.String
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// CreateIssue allocates the next PROJ-N key in the project and inserts the issue
+func (s *JiraStore) CreateIssue(issue *Issue) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var maxNumber int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(number), 0) FROM jira_issues WHERE project_key = ?`, issue.ProjectKey).Scan(&maxNumber); err != nil {
+		return err
+	}
+
+	issue.Number = maxNumber + 1
+	issue.Key = fmt.Sprintf("%s-%d", issue.ProjectKey, issue.Number)
+	if issue.Status == "" {
+		issue.Status = "To Do"
+	}
+	if issue.IssueType == "" {
+		issue.IssueType = "Task"
+	}
+	if issue.Priority == "" {
+		issue.Priority = "Medium"
+	}
+	issue.CreatedAt = time.Now()
+	issue.UpdatedAt = issue.CreatedAt
+
+	result, err := tx.Exec(`INSERT INTO jira_issues
+		(key, project_key, number, summary, description, status, issue_type, priority, assignee, reporter, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		issue.Key, issue.ProjectKey, issue.Number, issue.Summary, issue.Description, issue.Status,
+		issue.IssueType, issue.Priority, issue.Assignee, issue.Reporter, issue.CreatedAt, issue.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	issue.ID, _ = result.LastInsertId()
+
+	if err := auditIn(tx, "issue", issue.Key, core.AuditCreate, issue.Reporter, issueChanges(issue)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// issueChanges lists the editable fields of an issue for the audit log
+func issueChanges(issue *Issue) map[string]any {
+	return map[string]any{
+		"summary":     issue.Summary,
+		"description": issue.Description,
+		"status":      issue.Status,
+		"issue_type":  issue.IssueType,
+		"priority":    issue.Priority,
+		"assignee":    issue.Assignee,
+	}
+}
+
+const issueColumns = `id, key, project_key, number, summary, description, status, issue_type, priority, assignee, reporter, created_at, updated_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanIssue(row scanner) (*Issue, error) {
+	issue := &Issue{}
+	var description, assignee, reporter sql.NullString
+	err := row.Scan(&issue.ID, &issue.Key, &issue.ProjectKey, &issue.Number, &issue.Summary, &description,
+		&issue.Status, &issue.IssueType, &issue.Priority, &assignee, &reporter, &issue.CreatedAt, &issue.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	issue.Description = description.String
+	issue.Assignee = assignee.String
+	issue.Reporter = reporter.String
+	return issue, nil
+}
+
+// GetIssue looks up an issue by key (PROJ-123) or numeric ID
+func (s *JiraStore) GetIssue(idOrKey string) (*Issue, error) {
+	row := s.db.QueryRow(`SELECT `+issueColumns+` FROM jira_issues WHERE key = ? OR CAST(id AS TEXT) = ?`,
+		strings.ToUpper(idOrKey), idOrKey)
+	return scanIssue(row)
+}
+
+// UpdateIssue saves an issue's fields on behalf of actor
+func (s *JiraStore) UpdateIssue(issue *Issue, actor string) error {
+	issue.UpdatedAt = time.Now()
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE jira_issues SET summary = ?, description = ?, status = ?, issue_type = ?, priority = ?,
+			assignee = ?, reporter = ?, updated_at = ? WHERE id = ?`,
+			issue.Summary, issue.Description, issue.Status, issue.IssueType, issue.Priority,
+			issue.Assignee, issue.Reporter, issue.UpdatedAt, issue.ID)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "issue", issue.Key, core.AuditUpdate, actor, issueChanges(issue))
+	})
+}
+
+// SearchIssues runs a compiled JQL filter and returns one page of issues plus the total match count
+func (s *JiraStore) SearchIssues(q *JQLQuery, startAt, maxResults int) ([]*Issue, int, error) {
+	where, args := q.SQL()
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM jira_issues`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + issueColumns + ` FROM jira_issues` + where + q.OrderSQL() + ` LIMIT ? OFFSET ?`
+	rows, err := s.db.Query(query, append(args, maxResults, startAt)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var issues []*Issue
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		issues = append(issues, issue)
+	}
+	return issues, total, rows.Err()
+}
+
+// ListAllIssues retrieves issues across all projects for admin view
+func (s *JiraStore) ListAllIssues(limit, offset int) ([]*Issue, error) {
+	rows, err := s.db.Query(`SELECT `+issueColumns+` FROM jira_issues ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []*Issue
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+	return issues, rows.Err()
+}
+
+func (s *JiraStore) CreateComment(issueID int64, author, body string) (*Comment, error) {
+	now := time.Now()
+	var id int64
+	err := s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`INSERT INTO jira_comments (issue_id, author, body, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			issueID, author, body, now, now)
+		if err != nil {
+			return err
+		}
+		id, _ = result.LastInsertId()
+
+		// Commenting counts as an update to the issue
+		if _, err := tx.Exec(`UPDATE jira_issues SET updated_at = ? WHERE id = ?`, now, issueID); err != nil {
+			return err
+		}
+		return auditIn(tx, "comment", fmt.Sprint(id), core.AuditCreate, author, map[string]any{"issue_id": issueID, "body": body})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Comment{ID: id, IssueID: issueID, Author: author, Body: body, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+func (s *JiraStore) ListComments(issueID int64) ([]*Comment, error) {
+	rows, err := s.db.Query(`SELECT id, issue_id, author, body, created_at, updated_at FROM jira_comments
+		WHERE issue_id = ? ORDER BY created_at ASC, id ASC`, issueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*Comment
+	for rows.Next() {
+		c := &Comment{}
+		if err := rows.Scan(&c.ID, &c.IssueID, &c.Author, &c.Body, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *JiraStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db, "jira_projects", "jira_issues", "jira_comments")
+}
@@ -0,0 +1,250 @@
+// ABOUTME: Translates Linear GraphQL filter objects into SQL WHERE clauses
+// ABOUTME: Supports comparators, nested relation filters, and and/or composition
+
+package linear
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// issueFilterFields maps IssueFilter paths to SQL expressions over issueJoins.
+// A relation name on its own (e.g. "assignee") is used for null checks.
+var issueFilterFields = map[string]string{
+	"id":             "i.id",
+	"number":         "i.number",
+	"title":          "i.title",
+	"description":    "i.description",
+	"priority":       "i.priority",
+	"state":          "s.id",
+	"state.id":       "s.id",
+	"state.name":     "i.state",
+	"state.type":     "s.type",
+	"team":           "t.id",
+	"team.id":        "t.id",
+	"team.key":       "t.key",
+	"team.name":      "t.name",
+	"assignee":       "i.assignee_id",
+	"assignee.id":    "a.id",
+	"assignee.name":  "a.name",
+	"assignee.email": "a.email",
+	"assignee.isMe":  "i.assignee_id",
+	"creator":        "i.creator_id",
+	"creator.id":     "c.id",
+	"creator.name":   "c.name",
+	"creator.email":  "c.email",
+	"creator.isMe":   "i.creator_id",
+	"completedAt":    "i.completed_at",
+	"canceledAt":     "i.canceled_at",
+	"startedAt":      "i.started_at",
+}
+
+// comparators are the keys that terminate a filter path
+var comparators = map[string]bool{
+	"eq": true, "neq": true, "in": true, "nin": true, "null": true,
+	"lt": true, "lte": true, "gt": true, "gte": true,
+	"eqIgnoreCase": true, "neqIgnoreCase": true,
+	"contains": true, "containsIgnoreCase": true, "notContains": true, "notContainsIgnoreCase": true,
+	"startsWith": true, "endsWith": true,
+}
+
+// filterCompiler builds SQL for one filter object
+type filterCompiler struct {
+	fields   map[string]string
+	viewerID string
+	args     []any
+}
+
+// compileFilter turns a filter argument into a WHERE clause and its arguments
+func compileFilter(filter any, fields map[string]string, viewerID string) (string, []any, error) {
+	if filter == nil {
+		return "", nil, nil
+	}
+	obj, ok := filter.(map[string]any)
+	if !ok {
+		return "", nil, fmt.Errorf("Argument Validation Error: filter must be an object")
+	}
+	fc := &filterCompiler{fields: fields, viewerID: viewerID}
+	where, err := fc.compile(obj, "")
+	if err != nil {
+		return "", nil, err
+	}
+	return where, fc.args, nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (fc *filterCompiler) hasChildren(path string) bool {
+	for field := range fc.fields {
+		if strings.HasPrefix(field, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (fc *filterCompiler) compile(obj map[string]any, prefix string) (string, error) {
+	var conds []string
+	for _, key := range sortedKeys(obj) {
+		value := obj[key]
+
+		if key == "and" || key == "or" {
+			list, ok := value.([]any)
+			if !ok {
+				return "", fmt.Errorf("Argument Validation Error: %s%s must be a list", prefix, key)
+			}
+			var parts []string
+			for _, item := range list {
+				sub, ok := item.(map[string]any)
+				if !ok {
+					return "", fmt.Errorf("Argument Validation Error: %s%s entries must be objects", prefix, key)
+				}
+				part, err := fc.compile(sub, prefix)
+				if err != nil {
+					return "", err
+				}
+				if part != "" {
+					parts = append(parts, part)
+				}
+			}
+			if len(parts) > 0 {
+				conds = append(conds, "("+strings.Join(parts, " "+strings.ToUpper(key)+" ")+")")
+			}
+			continue
+		}
+
+		path := prefix + key
+		column, isLeaf := fc.fields[path]
+		if !isLeaf && !fc.hasChildren(path) {
+			return "", fmt.Errorf("Argument Validation Error: unknown filter field %q", path)
+		}
+		sub, ok := value.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("Argument Validation Error: filter field %q must be an object", path)
+		}
+
+		for _, op := range sortedKeys(sub) {
+			if comparators[op] && isLeaf {
+				cond, err := fc.comparator(path, column, op, sub[op])
+				if err != nil {
+					return "", err
+				}
+				conds = append(conds, cond)
+				continue
+			}
+			nested, err := fc.compile(map[string]any{op: sub[op]}, path+".")
+			if err != nil {
+				return "", err
+			}
+			if nested != "" {
+				conds = append(conds, nested)
+			}
+		}
+	}
+	return strings.Join(conds, " AND "), nil
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (fc *filterCompiler) comparator(path, column, op string, value any) (string, error) {
+	if strings.HasSuffix(path, ".isMe") {
+		if op != "eq" {
+			return "", fmt.Errorf("Argument Validation Error: %s only supports eq", path)
+		}
+		fc.args = append(fc.args, fc.viewerID)
+		if isMe, _ := value.(bool); isMe {
+			return column + " = ?", nil
+		}
+		return "(" + column + " IS NULL OR " + column + " != ?)", nil
+	}
+
+	switch op {
+	case "null":
+		isNull, ok := value.(bool)
+		if !ok {
+			return "", fmt.Errorf("Argument Validation Error: %s.null must be a boolean", path)
+		}
+		if isNull {
+			return column + " IS NULL", nil
+		}
+		return column + " IS NOT NULL", nil
+	case "in", "nin":
+		list, ok := value.([]any)
+		if !ok {
+			return "", fmt.Errorf("Argument Validation Error: %s.%s must be a list", path, op)
+		}
+		if len(list) == 0 {
+			if op == "in" {
+				return "0", nil
+			}
+			return "1", nil
+		}
+		fc.args = append(fc.args, list...)
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(list)), ", ")
+		if op == "in" {
+			return fmt.Sprintf("%s IN (%s)", column, placeholders), nil
+		}
+		return fmt.Sprintf("(%s IS NULL OR %s NOT IN (%s))", column, column, placeholders), nil
+	}
+
+	if value == nil {
+		return "", fmt.Errorf("Argument Validation Error: %s.%s cannot be null", path, op)
+	}
+
+	switch op {
+	case "eq":
+		fc.args = append(fc.args, value)
+		return column + " = ?", nil
+	case "neq":
+		fc.args = append(fc.args, value)
+		return "(" + column + " IS NULL OR " + column + " != ?)", nil
+	case "lt", "lte", "gt", "gte":
+		sqlOp := map[string]string{"lt": "<", "lte": "<=", "gt": ">", "gte": ">="}[op]
+		fc.args = append(fc.args, value)
+		return column + " " + sqlOp + " ?", nil
+	case "eqIgnoreCase":
+		fc.args = append(fc.args, value)
+		return column + " = ? COLLATE NOCASE", nil
+	case "neqIgnoreCase":
+		fc.args = append(fc.args, value)
+		return "(" + column + " IS NULL OR " + column + " != ? COLLATE NOCASE)", nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("Argument Validation Error: %s.%s must be a string", path, op)
+	}
+	switch op {
+	case "contains":
+		// instr() is case-sensitive, unlike SQLite's LIKE
+		fc.args = append(fc.args, s)
+		return "instr(" + column + ", ?) > 0", nil
+	case "containsIgnoreCase":
+		fc.args = append(fc.args, "%"+escapeLike(s)+"%")
+		return column + ` LIKE ? ESCAPE '\'`, nil
+	case "notContains":
+		fc.args = append(fc.args, s)
+		return "(" + column + " IS NULL OR instr(" + column + ", ?) = 0)", nil
+	case "notContainsIgnoreCase":
+		fc.args = append(fc.args, "%"+escapeLike(s)+"%")
+		return "(" + column + ` IS NULL OR ` + column + ` NOT LIKE ? ESCAPE '\')`, nil
+	case "startsWith":
+		fc.args = append(fc.args, escapeLike(s)+"%")
+		return column + ` LIKE ? ESCAPE '\'`, nil
+	case "endsWith":
+		fc.args = append(fc.args, "%"+escapeLike(s))
+		return column + ` LIKE ? ESCAPE '\'`, nil
+	}
+	return "", fmt.Errorf("Argument Validation Error: unsupported comparator %q", op)
+}
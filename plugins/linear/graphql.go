@@ -0,0 +1,717 @@
+// ABOUTME: Minimal GraphQL document parser built on the standard library
+// ABOUTME: Handles operations, arguments, variables, aliases, fragments, and directives
+
+package linear
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// gqlVariable is a reference to an operation variable ($name)
+type gqlVariable string
+
+// gqlEnum is an unquoted enum literal such as createdAt
+type gqlEnum string
+
+// gqlField is a selected field after fragments have been expanded
+type gqlField struct {
+	Alias      string
+	Name       string
+	Args       map[string]any
+	Selections []*gqlField
+}
+
+// ResponseKey returns the key the field is written under in the response
+func (f *gqlField) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// gqlOperation is a parsed query or mutation ready for execution
+type gqlOperation struct {
+	Type       string // "query" or "mutation"
+	Name       string
+	Selections []*gqlField
+}
+
+// gqlSelection is a raw selection before fragment expansion
+type gqlSelection struct {
+	field      *gqlField
+	children   []*gqlSelection
+	spread     string          // named fragment spread
+	inline     []*gqlSelection // inline fragment
+	directives map[string]map[string]any
+}
+
+type gqlToken struct {
+	kind  string // "punct", "name", "int", "float", "string", "eof"
+	value string
+	pos   int
+}
+
+// tokenizeGraphQL splits a GraphQL document into tokens, dropping whitespace,
+// commas, and comments as the spec allows
+func tokenizeGraphQL(src string) ([]gqlToken, error) {
+	var tokens []gqlToken
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c) || c == ',' || c == '\uFEFF':
+			i++
+		case c == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '.':
+			if i+2 >= len(runes) || runes[i+1] != '.' || runes[i+2] != '.' {
+				return nil, fmt.Errorf("Syntax Error: Unexpected \".\" at position %d", i)
+			}
+			tokens = append(tokens, gqlToken{kind: "punct", value: "...", pos: i})
+			i += 3
+		case strings.ContainsRune("!$()[]{}:=@|&", c):
+			tokens = append(tokens, gqlToken{kind: "punct", value: string(c), pos: i})
+			i++
+		case c == '"':
+			start := i
+			if i+2 < len(runes) && runes[i+1] == '"' && runes[i+2] == '"' {
+				end := strings.Index(string(runes[i+3:]), `"""`)
+				if end < 0 {
+					return nil, fmt.Errorf("Syntax Error: Unterminated string at position %d", start)
+				}
+				body := []rune(string(runes[i+3:])[:end])
+				tokens = append(tokens, gqlToken{kind: "string", value: blockString(string(body)), pos: start})
+				i += 3 + len(body) + 3
+				continue
+			}
+			i++
+			var sb strings.Builder
+			for {
+				if i >= len(runes) || runes[i] == '\n' {
+					return nil, fmt.Errorf("Syntax Error: Unterminated string at position %d", start)
+				}
+				if runes[i] == '"' {
+					i++
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						sb.WriteRune('\n')
+					case 't':
+						sb.WriteRune('\t')
+					case 'r':
+						sb.WriteRune('\r')
+					case 'b':
+						sb.WriteRune('\b')
+					case 'f':
+						sb.WriteRune('\f')
+					case 'u':
+						if i+4 >= len(runes) {
+							return nil, fmt.Errorf("Syntax Error: Invalid unicode escape at position %d", i)
+						}
+						code, err := strconv.ParseUint(string(runes[i+1:i+5]), 16, 32)
+						if err != nil {
+							return nil, fmt.Errorf("Syntax Error: Invalid unicode escape at position %d", i)
+						}
+						sb.WriteRune(rune(code))
+						i += 4
+					default:
+						sb.WriteRune(runes[i])
+					}
+					i++
+					continue
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, gqlToken{kind: "string", value: sb.String(), pos: start})
+		case c == '-' || unicode.IsDigit(c):
+			start := i
+			i++
+			kind := "int"
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE+-", runes[i])) {
+				if strings.ContainsRune(".eE", runes[i]) {
+					kind = "float"
+				}
+				i++
+			}
+			tokens = append(tokens, gqlToken{kind: kind, value: string(runes[start:i]), pos: start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, gqlToken{kind: "name", value: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("Syntax Error: Unexpected character %q at position %d", c, i)
+		}
+	}
+	tokens = append(tokens, gqlToken{kind: "eof", pos: len(runes)})
+	return tokens, nil
+}
+
+// blockString trims the common indentation and blank edge lines of a """block string"""
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+type gqlParser struct {
+	tokens []gqlToken
+	pos    int
+}
+
+func (p *gqlParser) peek() gqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *gqlParser) next() gqlToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != "eof" {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *gqlParser) peekPunct(value string) bool {
+	tok := p.peek()
+	return tok.kind == "punct" && tok.value == value
+}
+
+func (p *gqlParser) expectPunct(value string) error {
+	tok := p.next()
+	if tok.kind != "punct" || tok.value != value {
+		return p.unexpected(tok, fmt.Sprintf("Expected %q", value))
+	}
+	return nil
+}
+
+func (p *gqlParser) expectName() (string, error) {
+	tok := p.next()
+	if tok.kind != "name" {
+		return "", p.unexpected(tok, "Expected Name")
+	}
+	return tok.value, nil
+}
+
+func (p *gqlParser) unexpected(tok gqlToken, expected string) error {
+	found := tok.value
+	if tok.kind == "eof" {
+		found = "<EOF>"
+	}
+	return fmt.Errorf("Syntax Error: %s, found %q at position %d", expected, found, tok.pos)
+}
+
+// parseGraphQL parses a document and returns the operation to execute.
+// operationName selects among several operations; it may be empty when
+// the document contains exactly one.
+func parseGraphQL(src, operationName string, variables map[string]any) (*gqlOperation, error) {
+	tokens, err := tokenizeGraphQL(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &gqlParser{tokens: tokens}
+
+	type rawOperation struct {
+		typ        string
+		name       string
+		defaults   map[string]any
+		selections []*gqlSelection
+	}
+	var operations []rawOperation
+	fragments := map[string][]*gqlSelection{}
+
+	for p.peek().kind != "eof" {
+		tok := p.peek()
+		switch {
+		case tok.kind == "punct" && tok.value == "{":
+			sels, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			operations = append(operations, rawOperation{typ: "query", selections: sels})
+		case tok.kind == "name" && (tok.value == "query" || tok.value == "mutation" || tok.value == "subscription"):
+			p.next()
+			op := rawOperation{typ: tok.value, defaults: map[string]any{}}
+			if p.peek().kind == "name" {
+				op.name = p.next().value
+			}
+			if p.peekPunct("(") {
+				if op.defaults, err = p.parseVariableDefinitions(); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := p.parseDirectives(); err != nil {
+				return nil, err
+			}
+			if op.selections, err = p.parseSelectionSet(); err != nil {
+				return nil, err
+			}
+			operations = append(operations, op)
+		case tok.kind == "name" && tok.value == "fragment":
+			p.next()
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if on, err := p.expectName(); err != nil || on != "on" {
+				return nil, p.unexpected(p.tokens[p.pos-1], `Expected "on"`)
+			}
+			if _, err := p.expectName(); err != nil {
+				return nil, err
+			}
+			if _, err := p.parseDirectives(); err != nil {
+				return nil, err
+			}
+			sels, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			fragments[name] = sels
+		default:
+			return nil, p.unexpected(tok, "Unexpected")
+		}
+	}
+
+	var chosen *rawOperation
+	for i := range operations {
+		if operationName == "" || operations[i].name == operationName {
+			if chosen != nil {
+				return nil, fmt.Errorf("Must provide operation name if query contains multiple operations.")
+			}
+			chosen = &operations[i]
+		}
+	}
+	if chosen == nil {
+		if operationName != "" {
+			return nil, fmt.Errorf("Unknown operation named %q.", operationName)
+		}
+		return nil, fmt.Errorf("Must provide an operation.")
+	}
+	if chosen.typ == "subscription" {
+		return nil, fmt.Errorf("Subscriptions are not supported.")
+	}
+
+	vars := map[string]any{}
+	for name, def := range chosen.defaults {
+		vars[name] = def
+	}
+	for name, value := range variables {
+		vars[name] = value
+	}
+
+	fields, err := expandSelections(chosen.selections, fragments, vars, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return &gqlOperation{Type: chosen.typ, Name: chosen.name, Selections: fields}, nil
+}
+
+// parseVariableDefinitions parses ($name: Type = default, ...) and returns the defaults
+func (p *gqlParser) parseVariableDefinitions() (map[string]any, error) {
+	defaults := map[string]any{}
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	for !p.peekPunct(")") {
+		if err := p.expectPunct("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		if err := p.skipType(); err != nil {
+			return nil, err
+		}
+		if p.peekPunct("=") {
+			p.next()
+			value, err := p.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			defaults[name] = value
+		}
+		if _, err := p.parseDirectives(); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+	return defaults, nil
+}
+
+// skipType consumes a type reference such as [String!]!; types are not validated
+func (p *gqlParser) skipType() error {
+	if p.peekPunct("[") {
+		p.next()
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return err
+	}
+	if p.peekPunct("!") {
+		p.next()
+	}
+	return nil
+}
+
+func (p *gqlParser) parseSelectionSet() ([]*gqlSelection, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	var selections []*gqlSelection
+	for !p.peekPunct("}") {
+		if p.peek().kind == "eof" {
+			return nil, p.unexpected(p.peek(), `Expected "}"`)
+		}
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	p.next()
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("Syntax Error: Selection set cannot be empty")
+	}
+	return selections, nil
+}
+
+func (p *gqlParser) parseSelection() (*gqlSelection, error) {
+	var err error
+	if p.peekPunct("...") {
+		p.next()
+		sel := &gqlSelection{}
+		if p.peek().kind == "name" && p.peek().value != "on" {
+			sel.spread = p.next().value
+			sel.directives, err = p.parseDirectives()
+			return sel, err
+		}
+		if p.peek().kind == "name" && p.peek().value == "on" {
+			p.next()
+			if _, err := p.expectName(); err != nil {
+				return nil, err
+			}
+		}
+		if sel.directives, err = p.parseDirectives(); err != nil {
+			return nil, err
+		}
+		sel.inline, err = p.parseSelectionSet()
+		return sel, err
+	}
+
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	field := &gqlField{Name: name, Args: map[string]any{}}
+	if p.peekPunct(":") {
+		p.next()
+		field.Alias = name
+		if field.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekPunct("(") {
+		p.next()
+		for !p.peekPunct(")") {
+			argName, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			field.Args[argName] = value
+		}
+		p.next()
+	}
+
+	sel := &gqlSelection{field: field}
+	if sel.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peekPunct("{") {
+		if sel.children, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return sel, nil
+}
+
+func (p *gqlParser) parseDirectives() (map[string]map[string]any, error) {
+	directives := map[string]map[string]any{}
+	for p.peekPunct("@") {
+		p.next()
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		args := map[string]any{}
+		if p.peekPunct("(") {
+			p.next()
+			for !p.peekPunct(")") {
+				argName, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				if args[argName], err = p.parseValue(false); err != nil {
+					return nil, err
+				}
+			}
+			p.next()
+		}
+		directives[name] = args
+	}
+	return directives, nil
+}
+
+// parseValue parses an input value literal. Constant values (defaults) may not reference variables.
+func (p *gqlParser) parseValue(constant bool) (any, error) {
+	tok := p.next()
+	switch tok.kind {
+	case "string":
+		return tok.value, nil
+	case "int":
+		n, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, p.unexpected(tok, "Invalid Int")
+		}
+		return n, nil
+	case "float":
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, p.unexpected(tok, "Invalid Float")
+		}
+		return f, nil
+	case "name":
+		switch tok.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return gqlEnum(tok.value), nil
+	case "punct":
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected(tok, "Unexpected variable in constant value")
+			}
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			return gqlVariable(name), nil
+		case "[":
+			list := []any{}
+			for !p.peekPunct("]") {
+				if p.peek().kind == "eof" {
+					return nil, p.unexpected(p.peek(), `Expected "]"`)
+				}
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			obj := map[string]any{}
+			for !p.peekPunct("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				if obj[name], err = p.parseValue(constant); err != nil {
+					return nil, err
+				}
+			}
+			p.next()
+			return obj, nil
+		}
+	}
+	return nil, p.unexpected(tok, "Unexpected")
+}
+
+// resolveValue substitutes variables and unwraps enum literals into plain values
+func resolveValue(value any, vars map[string]any) any {
+	switch v := value.(type) {
+	case gqlVariable:
+		return vars[string(v)]
+	case gqlEnum:
+		return string(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = resolveValue(item, vars)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = resolveValue(item, vars)
+		}
+		return out
+	}
+	return value
+}
+
+// includeSelection evaluates @skip and @include directives
+func includeSelection(directives map[string]map[string]any, vars map[string]any) bool {
+	if args, ok := directives["skip"]; ok {
+		if skip, _ := resolveValue(args["if"], vars).(bool); skip {
+			return false
+		}
+	}
+	if args, ok := directives["include"]; ok {
+		if include, _ := resolveValue(args["if"], vars).(bool); !include {
+			return false
+		}
+	}
+	return true
+}
+
+// expandSelections inlines fragment spreads, applies directives, and resolves
+// argument variables. Type conditions are ignored because every fragment in
+// this schema targets the type it is spread into.
+func expandSelections(selections []*gqlSelection, fragments map[string][]*gqlSelection, vars map[string]any, visiting map[string]bool) ([]*gqlField, error) {
+	var fields []*gqlField
+	for _, sel := range selections {
+		if !includeSelection(sel.directives, vars) {
+			continue
+		}
+		switch {
+		case sel.spread != "":
+			frag, ok := fragments[sel.spread]
+			if !ok {
+				return nil, fmt.Errorf("Unknown fragment %q.", sel.spread)
+			}
+			if visiting[sel.spread] {
+				return nil, fmt.Errorf("Cannot spread fragment %q within itself.", sel.spread)
+			}
+			visiting[sel.spread] = true
+			expanded, err := expandSelections(frag, fragments, vars, visiting)
+			delete(visiting, sel.spread)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, expanded...)
+		case sel.inline != nil:
+			expanded, err := expandSelections(sel.inline, fragments, vars, visiting)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, expanded...)
+		default:
+			field := &gqlField{Alias: sel.field.Alias, Name: sel.field.Name, Args: map[string]any{}}
+			for name, value := range sel.field.Args {
+				field.Args[name] = resolveValue(value, vars)
+			}
+			if sel.children != nil {
+				children, err := expandSelections(sel.children, fragments, vars, visiting)
+				if err != nil {
+					return nil, err
+				}
+				field.Selections = children
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// gqlObject is a JSON object that preserves field order, as GraphQL responses must
+type gqlObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func newGQLObject() *gqlObject {
+	return &gqlObject{values: map[string]any{}}
+}
+
+// Set adds or replaces a key, keeping its original position
+func (o *gqlObject) Set(key string, value any) {
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Get returns the value stored under key
+func (o *gqlObject) Get(key string) any {
+	return o.values[key]
+}
+
+func (o *gqlObject) MarshalJSON() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		sb.Write(k)
+		sb.WriteByte(':')
+		sb.Write(v)
+	}
+	sb.WriteByte('}')
+	return []byte(sb.String()), nil
+}
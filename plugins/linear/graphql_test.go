@@ -0,0 +1,167 @@
+// ABOUTME: Tests for the minimal GraphQL parser
+// ABOUTME: Covers arguments, variables, aliases, fragments, directives, and syntax errors
+
+package linear
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseShorthandQuery(t *testing.T) {
+	op, err := parseGraphQL(`{ issues(first: 10, filter: {state: {name: {eq: "Todo"}}}) { nodes { id title } } }`, "", nil)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if op.Type != "query" || len(op.Selections) != 1 {
+		t.Fatalf("unexpected operation: %+v", op)
+	}
+
+	issues := op.Selections[0]
+	if issues.Name != "issues" {
+		t.Fatalf("expected issues field, got %s", issues.Name)
+	}
+	if n, _ := toInt(issues.Args["first"]); n != 10 {
+		t.Errorf("expected first=10, got %v", issues.Args["first"])
+	}
+	filter := issues.Args["filter"].(map[string]any)
+	eq := filter["state"].(map[string]any)["name"].(map[string]any)["eq"]
+	if eq != "Todo" {
+		t.Errorf("expected nested filter value Todo, got %v", eq)
+	}
+	if got := issues.Selections[0].Selections[1].Name; got != "title" {
+		t.Errorf("expected nested title selection, got %s", got)
+	}
+}
+
+func TestParseVariablesAndDefaults(t *testing.T) {
+	query := `
+		# Update an issue's state
+		mutation UpdateIssue($id: String!, $input: IssueUpdateInput!, $first: Int = 5) {
+			issueUpdate(id: $id, input: $input) { success }
+			other: issues(first: $first, orderBy: updatedAt) { nodes { id } }
+		}`
+	vars := map[string]any{"id": "ENG-1", "input": map[string]any{"stateId": "abc"}}
+
+	op, err := parseGraphQL(query, "", vars)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if op.Type != "mutation" || op.Name != "UpdateIssue" {
+		t.Fatalf("unexpected operation: %s %s", op.Type, op.Name)
+	}
+
+	update := op.Selections[0]
+	if update.Args["id"] != "ENG-1" {
+		t.Errorf("variable not substituted: %v", update.Args["id"])
+	}
+	if update.Args["input"].(map[string]any)["stateId"] != "abc" {
+		t.Errorf("object variable not substituted: %v", update.Args["input"])
+	}
+
+	other := op.Selections[1]
+	if other.ResponseKey() != "other" || other.Name != "issues" {
+		t.Errorf("alias not parsed: key=%s name=%s", other.ResponseKey(), other.Name)
+	}
+	if n, _ := toInt(other.Args["first"]); n != 5 {
+		t.Errorf("default variable value not applied: %v", other.Args["first"])
+	}
+	if other.Args["orderBy"] != "updatedAt" {
+		t.Errorf("enum not resolved to string: %#v", other.Args["orderBy"])
+	}
+}
+
+func TestParseFragmentsAndDirectives(t *testing.T) {
+	query := `
+		query Issues($withTeam: Boolean!) {
+			issues {
+				nodes {
+					...IssueFields
+					team @include(if: $withTeam) { key }
+					priority @skip(if: true)
+					... on Issue { url }
+				}
+			}
+		}
+		fragment IssueFields on Issue { id identifier }`
+
+	op, err := parseGraphQL(query, "", map[string]any{"withTeam": false})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var names []string
+	for _, f := range op.Selections[0].Selections[0].Selections {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "id,identifier,url" {
+		t.Errorf("expected id,identifier,url after expansion, got %s", got)
+	}
+}
+
+func TestParseOperationName(t *testing.T) {
+	query := `query A { viewer { id } } query B { teams { nodes { id } } }`
+
+	if _, err := parseGraphQL(query, "", nil); err == nil {
+		t.Error("expected error for multiple operations without operationName")
+	}
+	op, err := parseGraphQL(query, "B", nil)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if op.Selections[0].Name != "teams" {
+		t.Errorf("expected operation B, got field %s", op.Selections[0].Name)
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	query := `mutation { issueCreate(input: {title: "Say \"hi\" é", description: """
+		Line one
+		  Line two
+	"""}) { success } }`
+
+	op, err := parseGraphQL(query, "", nil)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	input := op.Selections[0].Args["input"].(map[string]any)
+	if input["title"] != `Say "hi" é` {
+		t.Errorf("unexpected title: %q", input["title"])
+	}
+	if input["description"] != "Line one\n  Line two" {
+		t.Errorf("unexpected block string: %q", input["description"])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		`{ issues { nodes { id }`,
+		`{ issues(first: ) { nodes { id } } }`,
+		`{ issues { } }`,
+		`{ issue(id: "unterminated) { id } }`,
+		`{ ...Missing }`,
+		`fragment A on Issue { ...A } { issues { nodes { ...A } } }`,
+		`subscription { issues { nodes { id } } }`,
+	}
+	for _, query := range tests {
+		if _, err := parseGraphQL(query, "", nil); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+}
+
+func TestGQLObjectPreservesOrder(t *testing.T) {
+	obj := newGQLObject()
+	obj.Set("zeta", 1)
+	obj.Set("alpha", "a")
+	obj.Set("zeta", 2)
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(data) != `{"zeta":2,"alpha":"a"}` {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
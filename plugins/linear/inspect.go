@@ -0,0 +1,49 @@
+// ABOUTME: core.Inspector implementation for the Linear plugin
+// ABOUTME: Runs a fixed GraphQL query so records match what the API returns
+
+package linear
+
+import (
+	"errors"
+	"fmt"
+)
+
+// inspectQueries select the fields `ish inspect` prints for each resource
+var inspectQueries = map[string]string{
+	"issues": `query($id: String!) { issue(id: $id) {
+		id identifier number title description priority priorityLabel url branchName
+		createdAt updatedAt startedAt completedAt canceledAt archivedAt
+		state { id name type color }
+		team { id key name }
+		assignee { id name displayName email }
+		creator { id name displayName email }
+	} }`,
+	"teams": `query($id: String!) { team(id: $id) {
+		id key name description createdAt
+		states { nodes { id name type color position } }
+	} }`,
+}
+
+// Inspect implements core.Inspector for `ish inspect`. IDs may be UUIDs or
+// identifiers (ENG-1, ENG).
+func (p *LinearPlugin) Inspect(resource, id string) (interface{}, error) {
+	query, ok := inspectQueries[resource]
+	if !ok {
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+	op, err := parseGraphQL(query, "", map[string]any{"id": id})
+	if err != nil {
+		return nil, err
+	}
+	viewer, err := p.store.GetOrCreateViewer()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &resolver{store: p.store, viewer: viewer}
+	data, errs := res.execute(op)
+	if len(errs) > 0 {
+		return nil, errors.New(errs[0].Message)
+	}
+	return data.Get(op.Selections[0].ResponseKey()), nil
+}
@@ -0,0 +1,214 @@
+// ABOUTME: Linear GraphQL API plugin for ISH
+// ABOUTME: Simulates teams, users, workflow states, and issues behind a single /graphql endpoint
+
+package linear
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxQueryBytes bounds the size of a GraphQL request body
+const maxQueryBytes = 1 << 20
+
+func init() {
+	core.Register(&LinearPlugin{})
+}
+
+type LinearPlugin struct {
+	store *LinearStore
+}
+
+func (p *LinearPlugin) Name() string {
+	return "linear"
+}
+
+func (p *LinearPlugin) Health() core.HealthStatus {
+	return core.HealthStatus{
+		Status:  "healthy",
+		Message: "Linear plugin operational",
+	}
+}
+
+func (p *LinearPlugin) RegisterRoutes(r chi.Router) {
+	r.Post("/graphql", p.handleGraphQL)
+}
+
+func (p *LinearPlugin) RegisterAuth(r chi.Router) {
+	// Linear uses personal API keys or OAuth bearer tokens, checked per request
+}
+
+// extractAPIKey returns the API key from the Authorization header. Linear
+// accepts personal keys bare ("lin_api_...") and OAuth tokens as "Bearer <token>".
+func extractAPIKey(r *http.Request) string {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return auth
+}
+
+func (p *LinearPlugin) ValidateToken(token string) bool {
+	return token != ""
+}
+
+func (p *LinearPlugin) SetDB(db *sql.DB) error {
+	store, err := NewLinearStore(db)
+	if err != nil {
+		return err
+	}
+	p.store = store
+	return nil
+}
+
+// writeGraphQL writes a GraphQL response envelope
+func writeGraphQL(w http.ResponseWriter, status int, data any, errs []gqlError) {
+	resp := newGQLObject()
+	if len(errs) > 0 {
+		resp.Set("errors", errs)
+	}
+	if data != nil {
+		resp.Set("data", data)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Linear: Failed to encode response: %v", err)
+	}
+}
+
+// writeGraphQLError writes a request-level error with a Linear error code
+func writeGraphQLError(w http.ResponseWriter, status int, code, message string) {
+	writeGraphQL(w, status, nil, []gqlError{{
+		Message:    message,
+		Extensions: map[string]any{"code": code, "userPresentableMessage": message},
+	}})
+}
+
+// ClaimsGraphQL implements core.GraphQLPlugin. Linear accepts any API key, so
+// it takes every request that carries one.
+func (p *LinearPlugin) ClaimsGraphQL(r *http.Request) bool {
+	return p.ValidateToken(extractAPIKey(r))
+}
+
+// ServeGraphQL implements core.GraphQLPlugin
+func (p *LinearPlugin) ServeGraphQL(w http.ResponseWriter, r *http.Request) {
+	p.handleGraphQL(w, r)
+}
+
+// handleGraphQL handles POST /graphql
+func (p *LinearPlugin) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if !p.ValidateToken(extractAPIKey(r)) {
+		writeGraphQLError(w, http.StatusUnauthorized, "AUTHENTICATION_ERROR", "Authentication required, not authenticated")
+		return
+	}
+
+	var req struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxQueryBytes)).Decode(&req); err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, "BAD_USER_INPUT", "Invalid JSON body")
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeGraphQLError(w, http.StatusBadRequest, "GRAPHQL_PARSE_FAILED", "Must provide query string.")
+		return
+	}
+
+	op, err := parseGraphQL(req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, "GRAPHQL_PARSE_FAILED", err.Error())
+		return
+	}
+
+	viewer, err := p.store.GetOrCreateViewer()
+	if err != nil {
+		writeGraphQLError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", "Failed to load viewer")
+		return
+	}
+
+	res := &resolver{store: p.store, viewer: viewer}
+	data, errs := res.execute(op)
+	writeGraphQL(w, http.StatusOK, data, errs)
+}
+
+// ListResources implements core.DataProvider to expose data to admin UI
+func (p *LinearPlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
+	switch slug {
+	case "teams":
+		teams, err := p.store.ListTeams()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(teams))
+		for _, team := range teams {
+			result = append(result, convertTeamToMap(team))
+		}
+		return result, nil
+	case "issues":
+		issues, err := p.store.ListAllIssues(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(issues))
+		for _, issue := range issues {
+			result = append(result, convertIssueToMap(issue))
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+// GetResource implements core.DataProvider to fetch individual resources
+func (p *LinearPlugin) GetResource(ctx context.Context, slug string, id string) (map[string]interface{}, error) {
+	switch slug {
+	case "teams":
+		team, err := p.store.GetTeam(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertTeamToMap(team), nil
+	case "issues":
+		issue, err := p.store.GetIssue(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertIssueToMap(issue), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+func convertTeamToMap(team *Team) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          team.Key,
+		"key":         team.Key,
+		"name":        team.Name,
+		"description": team.Description,
+		"created_at":  team.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func convertIssueToMap(issue *Issue) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          issue.Identifier,
+		"identifier":  issue.Identifier,
+		"title":       issue.Title,
+		"description": issue.Description,
+		"state":       issue.State,
+		"priority":    priorityLabels[issue.Priority],
+		"created_at":  issue.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		"updated_at":  issue.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -0,0 +1,365 @@
+// ABOUTME: Tests for the Linear GraphQL endpoint
+// ABOUTME: Covers auth, GraphQL claims, filtered issue queries, pagination, issue mutations, and their audit entries
+
+package linear
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/2389/ish/internal/migrations"
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestPlugin(t *testing.T) (*LinearPlugin, chi.Router) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	plugin := &LinearPlugin{}
+	if err := plugin.SetDB(db); err != nil {
+		t.Fatalf("Failed to initialize plugin: %v", err)
+	}
+	if _, err := plugin.Seed(context.Background(), "small"); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+	return plugin, r
+}
+
+type graphQLResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []gqlError     `json:"errors"`
+}
+
+func doGraphQL(t *testing.T, r chi.Router, query string, variables map[string]any) graphQLResponse {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"query": query, "variables": variables})
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(body))
+	req.Header.Set("Authorization", "lin_api_test")
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var resp graphQLResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v (%s)", err, rr.Body.String())
+	}
+	return resp
+}
+
+// mustData fails the test if the response has errors and returns its data
+func mustData(t *testing.T, resp graphQLResponse) map[string]any {
+	t.Helper()
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Unexpected GraphQL errors: %+v", resp.Errors)
+	}
+	return resp.Data
+}
+
+func nodes(t *testing.T, data map[string]any, field string) []map[string]any {
+	t.Helper()
+	conn, ok := data[field].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected connection at %q, got %v", field, data[field])
+	}
+	var out []map[string]any
+	for _, n := range conn["nodes"].([]any) {
+		out = append(out, n.(map[string]any))
+	}
+	return out
+}
+
+func TestRequireAuth(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewBufferString(`{"query":"{ viewer { id } }"}`))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401, got %d", rr.Code)
+	}
+	var resp graphQLResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "AUTHENTICATION_ERROR" {
+		t.Errorf("Expected AUTHENTICATION_ERROR, got %+v", resp.Errors)
+	}
+}
+
+func TestClaimsGraphQL(t *testing.T) {
+	plugin, _ := setupTestPlugin(t)
+
+	for token, want := range map[string]bool{
+		"lin_api_key": true,
+		"":            false,
+	} {
+		req := httptest.NewRequest("POST", "/graphql", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if got := plugin.ClaimsGraphQL(req); got != want {
+			t.Errorf("ClaimsGraphQL(%q) = %v, want %v", token, got, want)
+		}
+	}
+}
+
+func TestViewerAndTeams(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	data := mustData(t, doGraphQL(t, r, `{
+		viewer { name email }
+		teams { nodes { key name states { nodes { name type } } } }
+	}`, nil))
+
+	viewer := data["viewer"].(map[string]any)
+	if viewer["email"] != "harper@example.com" {
+		t.Errorf("Expected first seeded user as viewer, got %v", viewer["email"])
+	}
+
+	teams := nodes(t, data, "teams")
+	if len(teams) != 2 || teams[1]["key"] != "ENG" {
+		t.Fatalf("Expected DES and ENG teams, got %v", teams)
+	}
+	if states := nodes(t, teams[1], "states"); len(states) != len(defaultWorkflow) {
+		t.Errorf("Expected %d workflow states, got %d", len(defaultWorkflow), len(states))
+	}
+}
+
+func TestIssuesQuery(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	data := mustData(t, doGraphQL(t, r, `query {
+		issues(filter: { state: { name: { eq: "Todo" } } }) {
+			nodes { id identifier title state { name } assignee { name } }
+		}
+	}`, nil))
+
+	issues := nodes(t, data, "issues")
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 Todo issues, got %d", len(issues))
+	}
+	for _, issue := range issues {
+		if issue["state"].(map[string]any)["name"] != "Todo" {
+			t.Errorf("Filter leaked issue in state %v", issue["state"])
+		}
+	}
+}
+
+func TestIssueFilters(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	tests := []struct {
+		name   string
+		filter string
+		want   int
+	}{
+		{"team key", `{ team: { key: { eq: "ENG" } } }`, 5},
+		{"state type", `{ state: { type: { in: ["started", "completed"] } } }`, 3},
+		{"unassigned", `{ assignee: { null: true } }`, 2},
+		{"assigned to me", `{ assignee: { isMe: { eq: true } } }`, 2},
+		{"assignee email", `{ assignee: { email: { eq: "bob@example.com" } } }`, 2},
+		{"priority range", `{ priority: { gte: 1, lte: 2 } }`, 3},
+		{"title contains ignore case", `{ title: { containsIgnoreCase: "LOGIN" } }`, 1},
+		{"title contains is case sensitive", `{ title: { contains: "LOGIN" } }`, 0},
+		{"or", `{ or: [{ state: { name: { eq: "Done" } } }, { state: { name: { eq: "Canceled" } } }] }`, 2},
+		{"and nested", `{ and: [{ team: { key: { eq: "ENG" } } }, { assignee: { name: { neq: "Harper Reed" } } }] }`, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := mustData(t, doGraphQL(t, r, `{ issues(filter: `+tt.filter+`) { nodes { identifier } } }`, nil))
+			if got := len(nodes(t, data, "issues")); got != tt.want {
+				t.Errorf("Expected %d issues, got %d", tt.want, got)
+			}
+		})
+	}
+
+	resp := doGraphQL(t, r, `{ issues(filter: { bogus: { eq: 1 } }) { nodes { id } } }`, nil)
+	if len(resp.Errors) == 0 {
+		t.Error("Expected error for unknown filter field")
+	}
+}
+
+func TestIssuesPagination(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	query := `query Page($after: String) {
+		issues(first: 3, after: $after) {
+			nodes { identifier }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	seen := map[string]bool{}
+	var after any
+	for page := 0; page < 5; page++ {
+		data := mustData(t, doGraphQL(t, r, query, map[string]any{"after": after}))
+		for _, issue := range nodes(t, data, "issues") {
+			if seen[issue["identifier"].(string)] {
+				t.Fatalf("Issue %v returned twice", issue["identifier"])
+			}
+			seen[issue["identifier"].(string)] = true
+		}
+		pageInfo := data["issues"].(map[string]any)["pageInfo"].(map[string]any)
+		if pageInfo["hasNextPage"] != true {
+			break
+		}
+		after = pageInfo["endCursor"]
+	}
+	if len(seen) != 7 {
+		t.Errorf("Expected to page through 7 issues, saw %d", len(seen))
+	}
+}
+
+func TestIssueCreateAndUpdate(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	data := mustData(t, doGraphQL(t, r, `{
+		team(id: "ENG") { id states { nodes { id name } } }
+		users { nodes { id email } }
+	}`, nil))
+	team := data["team"].(map[string]any)
+	stateIDs := map[string]string{}
+	for _, s := range nodes(t, team, "states") {
+		stateIDs[s["name"].(string)] = s["id"].(string)
+	}
+	var aliceID string
+	for _, u := range nodes(t, data, "users") {
+		if u["email"] == "alice@example.com" {
+			aliceID = u["id"].(string)
+		}
+	}
+
+	data = mustData(t, doGraphQL(t, r, `mutation Create($input: IssueCreateInput!) {
+		issueCreate(input: $input) { success issue { id identifier title priorityLabel state { name } creator { email } } }
+	}`, map[string]any{"input": map[string]any{
+		"teamId":      team["id"],
+		"title":       "Ship the GraphQL mock",
+		"description": "Parse queries with the standard library",
+		"priority":    2,
+	}}))
+	created := data["issueCreate"].(map[string]any)
+	issue := created["issue"].(map[string]any)
+	if created["success"] != true || issue["identifier"] != "ENG-6" {
+		t.Fatalf("Unexpected create payload: %v", created)
+	}
+	if issue["state"].(map[string]any)["name"] != defaultStateName {
+		t.Errorf("Expected default state %s, got %v", defaultStateName, issue["state"])
+	}
+	if issue["priorityLabel"] != "High" {
+		t.Errorf("Expected High priority, got %v", issue["priorityLabel"])
+	}
+	if issue["creator"].(map[string]any)["email"] != "harper@example.com" {
+		t.Errorf("Expected viewer as creator, got %v", issue["creator"])
+	}
+
+	// Update by identifier using inline arguments
+	data = mustData(t, doGraphQL(t, r, `mutation {
+		issueUpdate(id: "ENG-6", input: { stateId: "`+stateIDs["Done"]+`", assigneeId: "`+aliceID+`", title: "Shipped" }) {
+			success
+			issue { id title state { name } assignee { email } completedAt }
+		}
+	}`, nil))
+	updated := data["issueUpdate"].(map[string]any)["issue"].(map[string]any)
+	if updated["id"] != issue["id"] || updated["title"] != "Shipped" {
+		t.Errorf("Unexpected update payload: %v", updated)
+	}
+	if updated["state"].(map[string]any)["name"] != "Done" || updated["completedAt"] == nil {
+		t.Errorf("Expected Done with completedAt, got %v / %v", updated["state"], updated["completedAt"])
+	}
+	if updated["assignee"].(map[string]any)["email"] != "alice@example.com" {
+		t.Errorf("Expected alice as assignee, got %v", updated["assignee"])
+	}
+
+	// The new issue is visible to queries
+	data = mustData(t, doGraphQL(t, r, `{ issue(id: "ENG-6") { title state { type } } }`, nil))
+	if data["issue"].(map[string]any)["state"].(map[string]any)["type"] != "completed" {
+		t.Errorf("Expected completed state type, got %v", data["issue"])
+	}
+}
+
+func TestIssueMutationsAreAudited(t *testing.T) {
+	plugin, r := setupTestPlugin(t)
+	viewer, err := plugin.store.GetOrCreateViewer()
+	if err != nil {
+		t.Fatalf("Failed to load viewer: %v", err)
+	}
+
+	mustData(t, doGraphQL(t, r, `mutation { issueCreate(input: { teamId: "ENG", title: "Audit me" }) { success } }`, nil))
+	mustData(t, doGraphQL(t, r, `mutation { issueDelete(id: "ENG-6") { success } }`, nil))
+
+	entries, err := core.ListAudit(plugin.store.db, core.AuditQuery{PluginName: "linear", ResourceType: "issue"})
+	if err != nil {
+		t.Fatalf("ListAudit failed: %v", err)
+	}
+	if len(entries) < 2 {
+		t.Fatalf("Expected create and delete entries, got %+v", entries)
+	}
+	deleted, created := entries[0], entries[1]
+	if deleted.Action != core.AuditDelete || deleted.ResourceID != "ENG-6" || deleted.Actor != viewer.Email {
+		t.Errorf("Unexpected delete entry: %+v", deleted)
+	}
+	if created.Action != core.AuditCreate || created.ResourceID != "ENG-6" || created.Actor != viewer.Email {
+		t.Errorf("Unexpected create entry: %+v", created)
+	}
+	var changes map[string]any
+	if err := json.Unmarshal(created.Changes.(json.RawMessage), &changes); err != nil || changes["title"] != "Audit me" {
+		t.Errorf("Expected the title in the create changes, got %v (%v)", created.Changes, err)
+	}
+}
+
+func TestMutationErrors(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	tests := []string{
+		`mutation { issueCreate(input: { title: "No team" }) { success } }`,
+		`mutation { issueCreate(input: { teamId: "NOPE", title: "x" }) { success } }`,
+		`mutation { issueUpdate(id: "ENG-999", input: { title: "x" }) { success } }`,
+		`mutation { issueUpdate(id: "ENG-1", input: { stateId: "not-a-state" }) { success } }`,
+		`mutation { issueUpdate(id: "ENG-1", input: { priority: 9 }) { success } }`,
+	}
+	for _, query := range tests {
+		resp := doGraphQL(t, r, query, nil)
+		if len(resp.Errors) == 0 {
+			t.Errorf("Expected error for %s", query)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	resp := doGraphQL(t, r, `{ issues { nodes { nonexistent } } }`, nil)
+	if len(resp.Errors) == 0 || resp.Data["issues"] != nil {
+		t.Errorf("Expected field error with null data, got %+v", resp)
+	}
+
+	resp = doGraphQL(t, r, `{ issues { nodes { state } } }`, nil)
+	if len(resp.Errors) == 0 {
+		t.Error("Expected error selecting object field without subfields")
+	}
+
+	body, _ := json.Marshal(map[string]any{"query": "{ issues { nodes { id }"})
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer oauth-token")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for syntax error, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,633 @@
+// ABOUTME: GraphQL execution for the Linear schema subset
+// ABOUTME: Resolves issues, teams, users, workflow states, and issue mutations
+
+package linear
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lazyField resolves a field on demand, receiving the field's arguments.
+// Used for relations and connections so unselected data is never loaded.
+type lazyField func(args map[string]any) (any, error)
+
+// gqlError is a GraphQL error entry
+type gqlError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 250
+)
+
+var priorityLabels = map[int]string{0: "No priority", 1: "Urgent", 2: "High", 3: "Medium", 4: "Low"}
+
+// resolver executes one operation on behalf of the viewer
+type resolver struct {
+	store  *LinearStore
+	viewer *User
+}
+
+// execute runs the operation and returns the data object plus any field errors.
+// A failing top-level field is set to null and reported, per the GraphQL spec.
+func (r *resolver) execute(op *gqlOperation) (*gqlObject, []gqlError) {
+	var root map[string]any
+	if op.Type == "mutation" {
+		root = r.mutationRoot()
+	} else {
+		root = r.queryRoot()
+	}
+
+	data := newGQLObject()
+	var errs []gqlError
+	for _, field := range op.Selections {
+		value, err := r.resolveField(root, field, op.Type)
+		if err != nil {
+			errs = append(errs, gqlError{Message: err.Error(), Path: []any{field.ResponseKey()}})
+			data.Set(field.ResponseKey(), nil)
+			continue
+		}
+		data.Set(field.ResponseKey(), value)
+	}
+	return data, errs
+}
+
+// resolveField looks up a field on a parent object and projects its selection set
+func (r *resolver) resolveField(parent map[string]any, field *gqlField, typeName string) (any, error) {
+	if field.Name == "__typename" {
+		if name, ok := parent["__typename"]; ok {
+			return name, nil
+		}
+		return typeName, nil
+	}
+
+	value, ok := parent[field.Name]
+	if !ok {
+		return nil, fmt.Errorf("Cannot query field %q on type %q.", field.Name, parent["__typename"])
+	}
+	if lazy, ok := value.(lazyField); ok {
+		var err error
+		if value, err = lazy(field.Args); err != nil {
+			return nil, err
+		}
+	}
+	return r.project(value, field)
+}
+
+// project applies a field's selection set to a resolved value
+func (r *resolver) project(value any, field *gqlField) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		if len(field.Selections) == 0 {
+			return nil, fmt.Errorf("Field %q of type %q must have a selection of subfields.", field.Name, v["__typename"])
+		}
+		obj := newGQLObject()
+		for _, sub := range field.Selections {
+			resolved, err := r.resolveField(v, sub, fmt.Sprint(v["__typename"]))
+			if err != nil {
+				return nil, err
+			}
+			// Merge repeated selections of the same object field (e.g. from fragments)
+			if existing, ok := obj.Get(sub.ResponseKey()).(*gqlObject); ok {
+				if incoming, ok := resolved.(*gqlObject); ok {
+					for _, key := range incoming.keys {
+						existing.Set(key, incoming.values[key])
+					}
+					continue
+				}
+			}
+			obj.Set(sub.ResponseKey(), resolved)
+		}
+		return obj, nil
+	case []map[string]any:
+		list := make([]any, 0, len(v))
+		for _, item := range v {
+			projected, err := r.project(item, field)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, projected)
+		}
+		return list, nil
+	}
+
+	if len(field.Selections) > 0 {
+		return nil, fmt.Errorf("Field %q must not have a selection since it has no subfields.", field.Name)
+	}
+	return value, nil
+}
+
+func (r *resolver) queryRoot() map[string]any {
+	return map[string]any{
+		"__typename": "Query",
+		"viewer": lazyField(func(args map[string]any) (any, error) {
+			return r.userToMap(r.viewer), nil
+		}),
+		"issues": lazyField(func(args map[string]any) (any, error) {
+			return r.issueConnection(args, "", nil)
+		}),
+		"issue": lazyField(func(args map[string]any) (any, error) {
+			id, err := requiredString(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			issue, err := r.store.GetIssue(id)
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("Entity not found: Issue - Could not find referenced Issue.")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return r.issueToMap(issue), nil
+		}),
+		"teams": lazyField(func(args map[string]any) (any, error) {
+			teams, err := r.store.ListTeams()
+			if err != nil {
+				return nil, err
+			}
+			nodes := make([]map[string]any, 0, len(teams))
+			for _, team := range teams {
+				nodes = append(nodes, r.teamToMap(team))
+			}
+			return connection(nodes, false, 0), nil
+		}),
+		"team": lazyField(func(args map[string]any) (any, error) {
+			id, err := requiredString(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			team, err := r.store.GetTeam(id)
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("Entity not found: Team - Could not find referenced Team.")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return r.teamToMap(team), nil
+		}),
+		"users": lazyField(func(args map[string]any) (any, error) {
+			users, err := r.store.ListUsers()
+			if err != nil {
+				return nil, err
+			}
+			nodes := make([]map[string]any, 0, len(users))
+			for _, user := range users {
+				nodes = append(nodes, r.userToMap(user))
+			}
+			return connection(nodes, false, 0), nil
+		}),
+		"user": lazyField(func(args map[string]any) (any, error) {
+			id, err := requiredString(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			user, err := r.store.GetUser(id)
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("Entity not found: User - Could not find referenced User.")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return r.userToMap(user), nil
+		}),
+		"workflowStates": lazyField(func(args map[string]any) (any, error) {
+			return r.workflowStateConnection("")
+		}),
+		"workflowState": lazyField(func(args map[string]any) (any, error) {
+			id, err := requiredString(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			state, err := r.store.GetWorkflowState(id)
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("Entity not found: WorkflowState - Could not find referenced WorkflowState.")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return r.stateToMap(state), nil
+		}),
+	}
+}
+
+func (r *resolver) mutationRoot() map[string]any {
+	return map[string]any{
+		"__typename":  "Mutation",
+		"issueCreate": lazyField(r.issueCreate),
+		"issueUpdate": lazyField(r.issueUpdate),
+		"issueDelete": lazyField(r.issueDelete),
+	}
+}
+
+// connection wraps nodes in a Relay-style connection with pageInfo
+func connection(nodes []map[string]any, hasNextPage bool, offset int) map[string]any {
+	pageInfo := map[string]any{
+		"__typename":      "PageInfo",
+		"hasNextPage":     hasNextPage,
+		"hasPreviousPage": offset > 0,
+		"startCursor":     nil,
+		"endCursor":       nil,
+	}
+	edges := make([]map[string]any, 0, len(nodes))
+	for i, node := range nodes {
+		cursor := encodeCursor(offset + i + 1)
+		edges = append(edges, map[string]any{"__typename": "Edge", "node": node, "cursor": cursor})
+		if i == 0 {
+			pageInfo["startCursor"] = cursor
+		}
+		pageInfo["endCursor"] = cursor
+	}
+	return map[string]any{
+		"__typename": "Connection",
+		"nodes":      nodes,
+		"edges":      edges,
+		"pageInfo":   pageInfo,
+	}
+}
+
+// encodeCursor returns an opaque cursor pointing just past the given offset
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(data), "offset:") {
+		return 0, fmt.Errorf("Argument Validation Error: invalid cursor")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("Argument Validation Error: invalid cursor")
+	}
+	return offset, nil
+}
+
+// issueConnection resolves an issues(filter, first, after, orderBy) connection.
+// scopeColumn/scopeValue restrict results to a parent (e.g. a team's issues).
+func (r *resolver) issueConnection(args map[string]any, scopeColumn string, scopeValue any) (any, error) {
+	where, params, err := compileFilter(args["filter"], issueFilterFields, r.viewer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if scopeColumn != "" {
+		if where != "" {
+			where = "(" + where + ") AND "
+		}
+		where += scopeColumn + " = ?"
+		params = append(params, scopeValue)
+	}
+
+	first := defaultPageSize
+	if v, ok := args["first"]; ok && v != nil {
+		n, ok := toInt(v)
+		if !ok || n < 0 {
+			return nil, fmt.Errorf("Argument Validation Error: first must be a non-negative integer")
+		}
+		first = n
+	}
+	if first > maxPageSize {
+		first = maxPageSize
+	}
+
+	offset := 0
+	if after, ok := args["after"].(string); ok && after != "" {
+		if offset, err = decodeCursor(after); err != nil {
+			return nil, err
+		}
+	}
+
+	orderBy, _ := args["orderBy"].(string)
+	issues, hasMore, err := r.store.SearchIssues(where, params, orderBy, first, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]map[string]any, 0, len(issues))
+	for _, issue := range issues {
+		nodes = append(nodes, r.issueToMap(issue))
+	}
+	return connection(nodes, hasMore, offset), nil
+}
+
+func (r *resolver) workflowStateConnection(teamID string) (any, error) {
+	states, err := r.store.ListWorkflowStates(teamID)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]map[string]any, 0, len(states))
+	for _, state := range states {
+		nodes = append(nodes, r.stateToMap(state))
+	}
+	return connection(nodes, false, 0), nil
+}
+
+func formatTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// branchName mimics Linear's suggested git branch name
+func branchName(user *User, issue *Issue) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(issue.Title), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	return fmt.Sprintf("%s/%s-%s", user.DisplayName, strings.ToLower(issue.Identifier), slug)
+}
+
+func (r *resolver) issueToMap(issue *Issue) map[string]any {
+	return map[string]any{
+		"__typename":    "Issue",
+		"id":            issue.ID,
+		"identifier":    issue.Identifier,
+		"number":        issue.Number,
+		"title":         issue.Title,
+		"description":   nullable(issue.Description),
+		"priority":      issue.Priority,
+		"priorityLabel": priorityLabels[issue.Priority],
+		"url":           fmt.Sprintf("https://linear.app/ish/issue/%s", issue.Identifier),
+		"branchName":    branchName(r.viewer, issue),
+		"createdAt":     formatTime(&issue.CreatedAt),
+		"updatedAt":     formatTime(&issue.UpdatedAt),
+		"startedAt":     formatTime(issue.StartedAt),
+		"completedAt":   formatTime(issue.CompletedAt),
+		"canceledAt":    formatTime(issue.CanceledAt),
+		"archivedAt":    nil,
+		"state": lazyField(func(args map[string]any) (any, error) {
+			state, err := r.store.GetWorkflowStateByName(issue.TeamID, issue.State)
+			if err != nil {
+				return nil, err
+			}
+			return r.stateToMap(state), nil
+		}),
+		"team": lazyField(func(args map[string]any) (any, error) {
+			team, err := r.store.GetTeam(issue.TeamID)
+			if err != nil {
+				return nil, err
+			}
+			return r.teamToMap(team), nil
+		}),
+		"assignee": lazyField(func(args map[string]any) (any, error) {
+			return r.optionalUser(issue.AssigneeID)
+		}),
+		"creator": lazyField(func(args map[string]any) (any, error) {
+			return r.optionalUser(issue.CreatorID)
+		}),
+	}
+}
+
+func (r *resolver) optionalUser(id string) (any, error) {
+	if id == "" {
+		return nil, nil
+	}
+	user, err := r.store.GetUser(id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.userToMap(user), nil
+}
+
+func (r *resolver) teamToMap(team *Team) map[string]any {
+	return map[string]any{
+		"__typename":  "Team",
+		"id":          team.ID,
+		"key":         team.Key,
+		"name":        team.Name,
+		"description": nullable(team.Description),
+		"createdAt":   formatTime(&team.CreatedAt),
+		"issues": lazyField(func(args map[string]any) (any, error) {
+			return r.issueConnection(args, "i.team_id", team.ID)
+		}),
+		"states": lazyField(func(args map[string]any) (any, error) {
+			return r.workflowStateConnection(team.ID)
+		}),
+	}
+}
+
+func (r *resolver) userToMap(user *User) map[string]any {
+	return map[string]any{
+		"__typename":  "User",
+		"id":          user.ID,
+		"name":        user.Name,
+		"displayName": user.DisplayName,
+		"email":       user.Email,
+		"active":      user.Active,
+		"isMe":        user.ID == r.viewer.ID,
+		"createdAt":   formatTime(&user.CreatedAt),
+		"assignedIssues": lazyField(func(args map[string]any) (any, error) {
+			return r.issueConnection(args, "i.assignee_id", user.ID)
+		}),
+	}
+}
+
+func (r *resolver) stateToMap(state *WorkflowState) map[string]any {
+	return map[string]any{
+		"__typename": "WorkflowState",
+		"id":         state.ID,
+		"name":       state.Name,
+		"type":       state.Type,
+		"color":      state.Color,
+		"position":   state.Position,
+		"team": lazyField(func(args map[string]any) (any, error) {
+			team, err := r.store.GetTeam(state.TeamID)
+			if err != nil {
+				return nil, err
+			}
+			return r.teamToMap(team), nil
+		}),
+	}
+}
+
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// toInt converts GraphQL literals (int64) and JSON variables (float64) to int
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int64:
+		return int(n), true
+	case int:
+		return n, true
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+func requiredString(args map[string]any, name string) (string, error) {
+	s, ok := args[name].(string)
+	if !ok || s == "" {
+		return "", fmt.Errorf("Argument Validation Error: %s is required", name)
+	}
+	return s, nil
+}
+
+// issueInput is the subset of IssueCreateInput/IssueUpdateInput this mock understands
+type issueInput map[string]any
+
+// apply copies input fields onto the issue, resolving stateId and assigneeId.
+// It returns the new state's type when the state changed.
+func (r *resolver) apply(issue *Issue, input issueInput) (bool, string, error) {
+	if v, ok := input["title"]; ok {
+		title, _ := v.(string)
+		if strings.TrimSpace(title) == "" {
+			return false, "", fmt.Errorf("Argument Validation Error: title must not be empty")
+		}
+		issue.Title = title
+	}
+	if v, ok := input["description"]; ok {
+		issue.Description, _ = v.(string)
+	}
+	if v, ok := input["priority"]; ok && v != nil {
+		priority, ok := toInt(v)
+		if !ok || priority < 0 || priority > 4 {
+			return false, "", fmt.Errorf("Argument Validation Error: priority must be between 0 and 4")
+		}
+		issue.Priority = priority
+	}
+	if v, ok := input["assigneeId"]; ok {
+		assigneeID, _ := v.(string)
+		if assigneeID != "" {
+			user, err := r.store.GetUser(assigneeID)
+			if err != nil {
+				return false, "", fmt.Errorf("Entity not found: User - Could not find referenced User.")
+			}
+			assigneeID = user.ID
+		}
+		issue.AssigneeID = assigneeID
+	}
+
+	v, ok := input["stateId"]
+	if !ok || v == nil {
+		return false, "", nil
+	}
+	stateID, _ := v.(string)
+	state, err := r.store.GetWorkflowState(stateID)
+	if err != nil || state.TeamID != issue.TeamID {
+		return false, "", fmt.Errorf("Entity not found: WorkflowState - Could not find referenced WorkflowState.")
+	}
+	changed := state.Name != issue.State
+	issue.State = state.Name
+	return changed, state.Type, nil
+}
+
+func inputArg(args map[string]any) (issueInput, error) {
+	input, ok := args["input"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("Argument Validation Error: input is required")
+	}
+	return issueInput(input), nil
+}
+
+func (r *resolver) issuePayload(issue *Issue) map[string]any {
+	return map[string]any{
+		"__typename": "IssuePayload",
+		"success":    true,
+		"lastSyncId": issue.UpdatedAt.UnixMilli(),
+		"issue":      r.issueToMap(issue),
+	}
+}
+
+// issueCreate resolves mutation issueCreate(input: IssueCreateInput!)
+func (r *resolver) issueCreate(args map[string]any) (any, error) {
+	input, err := inputArg(args)
+	if err != nil {
+		return nil, err
+	}
+	teamID, _ := input["teamId"].(string)
+	if teamID == "" {
+		return nil, fmt.Errorf("Argument Validation Error: teamId is required")
+	}
+	team, err := r.store.GetTeam(teamID)
+	if err != nil {
+		return nil, fmt.Errorf("Entity not found: Team - Could not find referenced Team.")
+	}
+	if _, ok := input["title"]; !ok {
+		return nil, fmt.Errorf("Argument Validation Error: title is required")
+	}
+
+	issue := &Issue{TeamID: team.ID, CreatorID: r.viewer.ID}
+	if _, _, err := r.apply(issue, input); err != nil {
+		return nil, err
+	}
+	if err := r.store.CreateIssue(issue); err != nil {
+		return nil, err
+	}
+	return r.issuePayload(issue), nil
+}
+
+// issueUpdate resolves mutation issueUpdate(id: String!, input: IssueUpdateInput!)
+func (r *resolver) issueUpdate(args map[string]any) (any, error) {
+	id, err := requiredString(args, "id")
+	if err != nil {
+		return nil, err
+	}
+	input, err := inputArg(args)
+	if err != nil {
+		return nil, err
+	}
+	issue, err := r.store.GetIssue(id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("Entity not found: Issue - Could not find referenced Issue.")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changed, stateType, err := r.apply(issue, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.store.UpdateIssue(issue, changed, stateType, r.viewer.ID); err != nil {
+		return nil, err
+	}
+	return r.issuePayload(issue), nil
+}
+
+// issueDelete resolves mutation issueDelete(id: String!)
+func (r *resolver) issueDelete(args map[string]any) (any, error) {
+	id, err := requiredString(args, "id")
+	if err != nil {
+		return nil, err
+	}
+	issue, err := r.store.GetIssue(id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("Entity not found: Issue - Could not find referenced Issue.")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.store.DeleteIssue(issue.ID, r.viewer.ID); err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"__typename": "IssueArchivePayload",
+		"success":    true,
+		"lastSyncId": time.Now().UnixMilli(),
+		"entity":     r.issueToMap(issue),
+	}, nil
+}
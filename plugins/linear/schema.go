@@ -0,0 +1,71 @@
+// ABOUTME: Admin UI schema definitions for Linear plugin
+// ABOUTME: Defines Teams and Issues resources for schema-driven UI
+
+package linear
+
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func (p *LinearPlugin) Schema() core.PluginSchema {
+	return core.PluginSchema{
+		Resources: []core.ResourceSchema{
+			{
+				Name:        "Teams",
+				Slug:        "teams",
+				ListColumns: []string{"key", "name", "description"},
+				Fields: []core.FieldSchema{
+					{Name: "key", Type: "string", Display: "Key", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Name", Required: true, Editable: false},
+					{Name: "description", Type: "text", Display: "Description", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Issues",
+				Slug:        "issues",
+				ListColumns: []string{"identifier", "title", "state", "priority", "updated_at"},
+				Fields: []core.FieldSchema{
+					{Name: "identifier", Type: "string", Display: "Identifier", Required: true, Editable: false},
+					{Name: "title", Type: "string", Display: "Title", Required: true, Editable: true},
+					{Name: "description", Type: "text", Display: "Description", Required: false, Editable: true},
+					{Name: "state", Type: "string", Display: "State", Required: true, Editable: false},
+					{Name: "priority", Type: "string", Display: "Priority", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "updated_at", Type: "datetime", Display: "Updated", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+		},
+	}
+}
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *LinearPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "linear", Table: "linear_issues", Resource: "issues"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *LinearPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"linear": {"linear_issues", "linear_workflow_states", "linear_users", "linear_teams"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *LinearPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *LinearPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
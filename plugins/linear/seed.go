@@ -0,0 +1,82 @@
+// ABOUTME: Test data generation for Linear plugin
+// ABOUTME: Creates sample teams, members, and issues across workflow states
+
+package linear
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// Seed creates test data for the Linear plugin
+func (p *LinearPlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
+	// The first user created becomes the API viewer
+	members := []struct {
+		name, displayName, email string
+	}{
+		{"Harper Reed", "harper", "harper@example.com"},
+		{"Alice Chen", "alice", "alice@example.com"},
+		{"Bob Martinez", "bob", "bob@example.com"},
+	}
+	users := make(map[string]*User, len(members))
+	for _, m := range members {
+		user, err := p.store.CreateUser(m.name, m.displayName, m.email)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create user %s: %w", m.email, err)
+		}
+		users[m.displayName] = user
+	}
+
+	teams := map[string]*Team{}
+	for _, t := range []struct{ key, name, description string }{
+		{"ENG", "Engineering", "Product engineering"},
+		{"DES", "Design", "Product and brand design"},
+	} {
+		team, err := p.store.CreateTeam(t.key, t.name, t.description)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create team %s: %w", t.key, err)
+		}
+		teams[t.key] = team
+	}
+
+	issues := []struct {
+		team, title, description, state string
+		priority                        int
+		assignee, creator               string
+	}{
+		{"ENG", "Fix flaky login test", "The session test fails about 1 in 20 runs on CI.", "Todo", 2, "alice", "harper"},
+		{"ENG", "Add rate limiting to public API", "Token bucket per API key.", "In Progress", 1, "harper", "alice"},
+		{"ENG", "Upgrade to Go 1.25", "", "Done", 3, "bob", "bob"},
+		{"ENG", "Investigate memory growth in worker", "Heap grows steadily over 48h.", "Backlog", 0, "", "harper"},
+		{"ENG", "Review webhook retry design", "", "In Review", 2, "harper", "bob"},
+		{"DES", "New onboarding illustrations", "Three spot illustrations for the welcome flow.", "Todo", 3, "bob", "harper"},
+		{"DES", "Dark mode color audit", "", "Canceled", 4, "", "alice"},
+	}
+	for _, i := range issues {
+		issue := &Issue{
+			TeamID:      teams[i.team].ID,
+			Title:       i.title,
+			Description: i.description,
+			State:       i.state,
+			Priority:    i.priority,
+			CreatorID:   users[i.creator].ID,
+		}
+		if i.assignee != "" {
+			issue.AssigneeID = users[i.assignee].ID
+		}
+		if err := p.store.CreateIssue(issue); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create issue %q: %w", i.title, err)
+		}
+	}
+
+	return core.SeedData{
+		Summary: fmt.Sprintf("Created %d teams, %d users, %d issues", len(teams), len(users), len(issues)),
+		Records: map[string]int{
+			"teams":  len(teams),
+			"users":  len(users),
+			"issues": len(issues),
+		},
+	}, nil
+}
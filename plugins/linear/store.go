@@ -0,0 +1,508 @@
+// ABOUTME: Database layer for Linear plugin
+// ABOUTME: Manages linear_teams, linear_users, linear_workflow_states, and linear_issues tables
+
+package linear
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/google/uuid"
+)
+
+type LinearStore struct {
+	db *sql.DB
+}
+
+// auditIn records a mutation of a Linear resource in the shared audit log
+// through tx, the transaction making the change so both commit together. The
+// actor is the email of the user with ID actorID, if there is one.
+func auditIn(tx *sql.Tx, resourceType, resourceID, action, actorID string, changes any) error {
+	var actor string
+	if actorID != "" {
+		if err := tx.QueryRow(`SELECT email FROM linear_users WHERE id = ?`, actorID).Scan(&actor); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	return core.LogAudit(tx, core.AuditEntry{
+		PluginName:   "linear",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
+type Team struct {
+	ID          string
+	Key         string
+	Name        string
+	Description string
+	CreatedAt   time.Time
+}
+
+type User struct {
+	ID          string
+	Name        string
+	DisplayName string
+	Email       string
+	Active      bool
+	CreatedAt   time.Time
+}
+
+type WorkflowState struct {
+	ID       string
+	TeamID   string
+	Name     string
+	Type     string // backlog, unstarted, started, completed, canceled
+	Color    string
+	Position float64
+}
+
+type Issue struct {
+	ID          string
+	Identifier  string
+	TeamID      string
+	Number      int
+	Title       string
+	Description string
+	State       string
+	Priority    int
+	AssigneeID  string
+	CreatorID   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	CanceledAt  *time.Time
+}
+
+// defaultWorkflow is the set of states every new team starts with
+var defaultWorkflow = []WorkflowState{
+	{Name: "Backlog", Type: "backlog", Color: "#bec2c8", Position: 0},
+	{Name: "Todo", Type: "unstarted", Color: "#e2e2e2", Position: 1},
+	{Name: "In Progress", Type: "started", Color: "#f2c94c", Position: 2},
+	{Name: "In Review", Type: "started", Color: "#0f783c", Position: 3},
+	{Name: "Done", Type: "completed", Color: "#5e6ad2", Position: 4},
+	{Name: "Canceled", Type: "canceled", Color: "#95a2b3", Position: 5},
+}
+
+// defaultStateName is the state assigned to issues created without a stateId
+const defaultStateName = "Todo"
+
+func NewLinearStore(db *sql.DB) (*LinearStore, error) {
+	return &LinearStore{db: db}, nil
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *LinearStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateTeam creates a team along with its default workflow states
+func (s *LinearStore) CreateTeam(key, name, description string) (*Team, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	team := &Team{ID: uuid.NewString(), Key: strings.ToUpper(key), Name: name, Description: description, CreatedAt: time.Now()}
+	_, err = tx.Exec(`INSERT INTO linear_teams (id, key, name, description, created_at) VALUES (?, ?, ?, ?, ?)`,
+		team.ID, team.Key, team.Name, team.Description, team.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, state := range defaultWorkflow {
+		_, err = tx.Exec(`INSERT INTO linear_workflow_states (id, team_id, name, type, color, position) VALUES (?, ?, ?, ?, ?, ?)`,
+			uuid.NewString(), team.ID, state.Name, state.Type, state.Color, state.Position)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := auditIn(tx, "team", team.ID, core.AuditCreate, "", map[string]any{"key": team.Key, "name": team.Name}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// GetTeam gets a team by ID or key
+func (s *LinearStore) GetTeam(idOrKey string) (*Team, error) {
+	var team Team
+	var description sql.NullString
+	err := s.db.QueryRow(`SELECT id, key, name, description, created_at FROM linear_teams WHERE id = ? OR key = ?`,
+		idOrKey, strings.ToUpper(idOrKey)).Scan(&team.ID, &team.Key, &team.Name, &description, &team.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	team.Description = description.String
+	return &team, nil
+}
+
+// ListTeams lists all teams ordered by key
+func (s *LinearStore) ListTeams() ([]*Team, error) {
+	rows, err := s.db.Query(`SELECT id, key, name, description, created_at FROM linear_teams ORDER BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []*Team
+	for rows.Next() {
+		var team Team
+		var description sql.NullString
+		if err := rows.Scan(&team.ID, &team.Key, &team.Name, &description, &team.CreatedAt); err != nil {
+			return nil, err
+		}
+		team.Description = description.String
+		teams = append(teams, &team)
+	}
+	return teams, rows.Err()
+}
+
+// CreateUser creates a workspace member
+func (s *LinearStore) CreateUser(name, displayName, email string) (*User, error) {
+	user := &User{ID: uuid.NewString(), Name: name, DisplayName: displayName, Email: email, Active: true, CreatedAt: time.Now()}
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO linear_users (id, name, display_name, email, active, created_at) VALUES (?, ?, ?, ?, 1, ?)`,
+			user.ID, user.Name, user.DisplayName, user.Email, user.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "user", user.ID, core.AuditCreate, "", map[string]any{"name": user.Name, "email": user.Email})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUser gets a user by ID or email
+func (s *LinearStore) GetUser(idOrEmail string) (*User, error) {
+	var user User
+	err := s.db.QueryRow(`SELECT id, name, display_name, email, active, created_at FROM linear_users WHERE id = ? OR email = ?`,
+		idOrEmail, idOrEmail).Scan(&user.ID, &user.Name, &user.DisplayName, &user.Email, &user.Active, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers lists all users ordered by name
+func (s *LinearStore) ListUsers() ([]*User, error) {
+	rows, err := s.db.Query(`SELECT id, name, display_name, email, active, created_at FROM linear_users ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Name, &user.DisplayName, &user.Email, &user.Active, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// GetOrCreateViewer returns the user API requests act as: the earliest
+// created member, or a placeholder user on an empty workspace
+func (s *LinearStore) GetOrCreateViewer() (*User, error) {
+	var id string
+	err := s.db.QueryRow(`SELECT id FROM linear_users ORDER BY created_at, rowid LIMIT 1`).Scan(&id)
+	if err == sql.ErrNoRows {
+		return s.CreateUser("ISH User", "ish", "ish@example.com")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUser(id)
+}
+
+const stateColumns = `id, team_id, name, type, color, position`
+
+func scanState(row scanner) (*WorkflowState, error) {
+	var state WorkflowState
+	var color sql.NullString
+	if err := row.Scan(&state.ID, &state.TeamID, &state.Name, &state.Type, &color, &state.Position); err != nil {
+		return nil, err
+	}
+	state.Color = color.String
+	return &state, nil
+}
+
+// GetWorkflowState gets a workflow state by ID
+func (s *LinearStore) GetWorkflowState(id string) (*WorkflowState, error) {
+	return scanState(s.db.QueryRow(`SELECT `+stateColumns+` FROM linear_workflow_states WHERE id = ?`, id))
+}
+
+// GetWorkflowStateByName gets a team's workflow state by name
+func (s *LinearStore) GetWorkflowStateByName(teamID, name string) (*WorkflowState, error) {
+	return scanState(s.db.QueryRow(`SELECT `+stateColumns+` FROM linear_workflow_states WHERE team_id = ? AND name = ?`, teamID, name))
+}
+
+// ListWorkflowStates lists workflow states, optionally restricted to one team
+func (s *LinearStore) ListWorkflowStates(teamID string) ([]*WorkflowState, error) {
+	query := `SELECT ` + stateColumns + ` FROM linear_workflow_states`
+	var args []any
+	if teamID != "" {
+		query += ` WHERE team_id = ?`
+		args = append(args, teamID)
+	}
+	query += ` ORDER BY team_id, position`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []*WorkflowState
+	for rows.Next() {
+		state, err := scanState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+const issueColumns = `i.id, i.identifier, i.team_id, i.number, i.title, i.description, i.state, i.priority,
+	i.assignee_id, i.creator_id, i.created_at, i.updated_at, i.started_at, i.completed_at, i.canceled_at`
+
+// issueJoins makes team, state, assignee, and creator columns available to filters
+const issueJoins = ` FROM linear_issues i
+	JOIN linear_teams t ON t.id = i.team_id
+	LEFT JOIN linear_workflow_states s ON s.team_id = i.team_id AND s.name = i.state
+	LEFT JOIN linear_users a ON a.id = i.assignee_id
+	LEFT JOIN linear_users c ON c.id = i.creator_id`
+
+func scanIssue(row scanner) (*Issue, error) {
+	var issue Issue
+	var description, assigneeID, creatorID sql.NullString
+	var startedAt, completedAt, canceledAt sql.NullTime
+	err := row.Scan(&issue.ID, &issue.Identifier, &issue.TeamID, &issue.Number, &issue.Title, &description,
+		&issue.State, &issue.Priority, &assigneeID, &creatorID, &issue.CreatedAt, &issue.UpdatedAt,
+		&startedAt, &completedAt, &canceledAt)
+	if err != nil {
+		return nil, err
+	}
+	issue.Description = description.String
+	issue.AssigneeID = assigneeID.String
+	issue.CreatorID = creatorID.String
+	if startedAt.Valid {
+		issue.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		issue.CompletedAt = &completedAt.Time
+	}
+	if canceledAt.Valid {
+		issue.CanceledAt = &canceledAt.Time
+	}
+	return &issue, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// stampStateTimes sets started/completed/canceled timestamps for the issue's state type
+func stampStateTimes(issue *Issue, stateType string, now time.Time) {
+	switch stateType {
+	case "started":
+		if issue.StartedAt == nil {
+			issue.StartedAt = &now
+		}
+		issue.CompletedAt, issue.CanceledAt = nil, nil
+	case "completed":
+		issue.CompletedAt, issue.CanceledAt = &now, nil
+	case "canceled":
+		issue.CanceledAt, issue.CompletedAt = &now, nil
+	default:
+		issue.StartedAt, issue.CompletedAt, issue.CanceledAt = nil, nil, nil
+	}
+}
+
+// CreateIssue allocates the next TEAM-N identifier and inserts the issue
+func (s *LinearStore) CreateIssue(issue *Issue) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var teamKey string
+	var maxNumber int
+	if err := tx.QueryRow(`SELECT key FROM linear_teams WHERE id = ?`, issue.TeamID).Scan(&teamKey); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(number), 0) FROM linear_issues WHERE team_id = ?`, issue.TeamID).Scan(&maxNumber); err != nil {
+		return err
+	}
+	if issue.State == "" {
+		issue.State = defaultStateName
+	}
+	var stateType string
+	if err := tx.QueryRow(`SELECT type FROM linear_workflow_states WHERE team_id = ? AND name = ?`, issue.TeamID, issue.State).Scan(&stateType); err != nil {
+		return fmt.Errorf("unknown workflow state %q: %w", issue.State, err)
+	}
+
+	issue.ID = uuid.NewString()
+	issue.Number = maxNumber + 1
+	issue.Identifier = fmt.Sprintf("%s-%d", teamKey, issue.Number)
+	issue.CreatedAt = time.Now()
+	issue.UpdatedAt = issue.CreatedAt
+	stampStateTimes(issue, stateType, issue.CreatedAt)
+
+	_, err = tx.Exec(`INSERT INTO linear_issues
+		(id, identifier, team_id, number, title, description, state, priority, assignee_id, creator_id,
+		 created_at, updated_at, started_at, completed_at, canceled_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		issue.ID, issue.Identifier, issue.TeamID, issue.Number, issue.Title, issue.Description, issue.State,
+		issue.Priority, nullString(issue.AssigneeID), nullString(issue.CreatorID), issue.CreatedAt, issue.UpdatedAt,
+		issue.StartedAt, issue.CompletedAt, issue.CanceledAt)
+	if err != nil {
+		return err
+	}
+
+	err = auditIn(tx, "issue", issue.Identifier, core.AuditCreate, issue.CreatorID, map[string]any{
+		"title":    issue.Title,
+		"state":    issue.State,
+		"priority": issue.Priority,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetIssue gets an issue by UUID or identifier (ENG-123)
+func (s *LinearStore) GetIssue(idOrIdentifier string) (*Issue, error) {
+	return scanIssue(s.db.QueryRow(`SELECT `+issueColumns+` FROM linear_issues i WHERE i.id = ? OR i.identifier = ?`,
+		idOrIdentifier, strings.ToUpper(idOrIdentifier)))
+}
+
+// UpdateIssue saves all mutable fields of an issue on behalf of actorID. When the
+// state changes, stateType is used to maintain the started/completed/canceled timestamps.
+func (s *LinearStore) UpdateIssue(issue *Issue, stateChanged bool, stateType, actorID string) error {
+	issue.UpdatedAt = time.Now()
+	if stateChanged {
+		stampStateTimes(issue, stateType, issue.UpdatedAt)
+	}
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE linear_issues
+			SET title = ?, description = ?, state = ?, priority = ?, assignee_id = ?, updated_at = ?,
+				started_at = ?, completed_at = ?, canceled_at = ?
+			WHERE id = ?`,
+			issue.Title, issue.Description, issue.State, issue.Priority, nullString(issue.AssigneeID), issue.UpdatedAt,
+			issue.StartedAt, issue.CompletedAt, issue.CanceledAt, issue.ID)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "issue", issue.Identifier, core.AuditUpdate, actorID, map[string]any{
+			"title":       issue.Title,
+			"description": issue.Description,
+			"state":       issue.State,
+			"priority":    issue.Priority,
+			"assignee_id": issue.AssigneeID,
+		})
+	})
+}
+
+// DeleteIssue removes an issue on behalf of actorID
+func (s *LinearStore) DeleteIssue(id, actorID string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		var identifier string
+		if err := tx.QueryRow(`SELECT identifier FROM linear_issues WHERE id = ?`, id).Scan(&identifier); err != nil {
+			return err
+		}
+		result, err := tx.Exec(`DELETE FROM linear_issues WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return auditIn(tx, "issue", identifier, core.AuditDelete, actorID, nil)
+	})
+}
+
+// SearchIssues returns a page of issues matching an optional WHERE clause
+// built by compileFilter, along with whether more results exist
+func (s *LinearStore) SearchIssues(where string, args []any, orderBy string, limit, offset int) ([]*Issue, bool, error) {
+	column := "i.created_at"
+	if orderBy == "updatedAt" {
+		column = "i.updated_at"
+	}
+
+	query := `SELECT ` + issueColumns + issueJoins
+	if where != "" {
+		query += ` WHERE ` + where
+	}
+	query += fmt.Sprintf(` ORDER BY %s DESC, i.number DESC LIMIT ? OFFSET ?`, column)
+
+	// Fetch one extra row to learn whether there is another page
+	rows, err := s.db.Query(query, append(args, limit+1, offset)...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var issues []*Issue
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(issues) > limit
+	if hasMore {
+		issues = issues[:limit]
+	}
+	return issues, hasMore, nil
+}
+
+// ListAllIssues lists issues for the admin UI
+func (s *LinearStore) ListAllIssues(limit, offset int) ([]*Issue, error) {
+	issues, _, err := s.SearchIssues("", nil, "createdAt", limit, offset)
+	return issues, err
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *LinearStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db,
+		"linear_teams",
+		"linear_users",
+		"linear_workflow_states",
+		"linear_issues",
+	)
+}
@@ -0,0 +1,482 @@
+// ABOUTME: Database query filters, sorts, and cursor pagination for Notion
+// ABOUTME: Compiles Notion filter objects into predicates evaluated over page properties
+
+package notion
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 100
+)
+
+// pagePredicate reports whether a page matches a compiled filter
+type pagePredicate func(*Page) bool
+
+// compileFilter turns a Notion filter object into a predicate. It supports
+// compound and/or filters, property filters, and timestamp filters.
+func compileFilter(filter map[string]any, schemas map[string]*PropertySchema) (pagePredicate, error) {
+	if clauses, ok := filter["and"]; ok {
+		preds, err := compileClauses(clauses, schemas)
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Page) bool {
+			for _, pred := range preds {
+				if !pred(p) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+	if clauses, ok := filter["or"]; ok {
+		preds, err := compileClauses(clauses, schemas)
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Page) bool {
+			for _, pred := range preds {
+				if pred(p) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	if ts, ok := filter["timestamp"].(string); ok {
+		if ts != "created_time" && ts != "last_edited_time" {
+			return nil, fmt.Errorf("filter.timestamp should be created_time or last_edited_time, instead was %q", ts)
+		}
+		cond, ok := filter[ts].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("filter.%s should be defined", ts)
+		}
+		match, err := compileDateCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Page) bool {
+			t := p.CreatedTime
+			if ts == "last_edited_time" {
+				t = p.LastEditedTime
+			}
+			return match(t.UTC().Format(time.RFC3339))
+		}, nil
+	}
+
+	name, ok := filter["property"].(string)
+	if !ok {
+		return nil, fmt.Errorf("filter should have a property, timestamp, and, or or key")
+	}
+	schema := lookupProperty(schemas, name)
+	if schema == nil {
+		return nil, fmt.Errorf("Could not find property with name or id: %s", name)
+	}
+
+	var condType string
+	var cond map[string]any
+	for k, v := range filter {
+		if k == "property" {
+			continue
+		}
+		c, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("filter.%s should be an object", k)
+		}
+		if cond != nil {
+			return nil, fmt.Errorf("property filter should have exactly one condition type")
+		}
+		condType, cond = k, c
+	}
+	if cond == nil {
+		return nil, fmt.Errorf("property filter for %s is missing a condition", name)
+	}
+	if condType != schema.Type {
+		return nil, fmt.Errorf("database property %s does not match filter %s", schema.Type, condType)
+	}
+
+	return compilePropertyCondition(schema, cond)
+}
+
+func compileClauses(v any, schemas map[string]*PropertySchema) ([]pagePredicate, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("compound filters should be arrays")
+	}
+	preds := make([]pagePredicate, 0, len(items))
+	for _, item := range items {
+		f, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("compound filter items should be objects")
+		}
+		pred, err := compileFilter(f, schemas)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+	return preds, nil
+}
+
+// singleCondition extracts the one operator/value pair of a condition object
+func singleCondition(cond map[string]any) (string, any, error) {
+	if len(cond) != 1 {
+		return "", nil, fmt.Errorf("filter condition should have exactly one operator")
+	}
+	for op, v := range cond {
+		return op, v, nil
+	}
+	return "", nil, nil
+}
+
+// propertyValue returns the raw value of a page property, or nil if unset
+func propertyValue(p *Page, schema *PropertySchema) any {
+	prop, ok := p.Properties[schema.Name]
+	if !ok {
+		return nil
+	}
+	return prop[schema.Type]
+}
+
+func compilePropertyCondition(schema *PropertySchema, cond map[string]any) (pagePredicate, error) {
+	op, operand, err := singleCondition(cond)
+	if err != nil {
+		return nil, err
+	}
+
+	switch schema.Type {
+	case "title", "rich_text", "url", "email", "phone_number":
+		match, err := compileTextCondition(op, operand)
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Page) bool {
+			v := propertyValue(p, schema)
+			if schema.Type == "title" || schema.Type == "rich_text" {
+				return match(plainText(v))
+			}
+			s, _ := v.(string)
+			return match(s)
+		}, nil
+
+	case "select", "status":
+		match, err := compileTextCondition(op, operand)
+		if err != nil || op == "contains" || op == "does_not_contain" || op == "starts_with" || op == "ends_with" {
+			return nil, fmt.Errorf("unsupported %s filter condition: %s", schema.Type, op)
+		}
+		return func(p *Page) bool {
+			opt, _ := propertyValue(p, schema).(map[string]any)
+			name, _ := opt["name"].(string)
+			return match(name)
+		}, nil
+
+	case "multi_select":
+		names := func(p *Page) []string {
+			items, _ := propertyValue(p, schema).([]any)
+			var result []string
+			for _, item := range items {
+				if opt, ok := item.(map[string]any); ok {
+					name, _ := opt["name"].(string)
+					result = append(result, name)
+				}
+			}
+			return result
+		}
+		switch op {
+		case "is_empty":
+			return func(p *Page) bool { return len(names(p)) == 0 }, nil
+		case "is_not_empty":
+			return func(p *Page) bool { return len(names(p)) > 0 }, nil
+		case "contains", "does_not_contain":
+			want, ok := operand.(string)
+			if !ok {
+				return nil, fmt.Errorf("multi_select.%s should be a string", op)
+			}
+			return func(p *Page) bool {
+				return slices.Contains(names(p), want) == (op == "contains")
+			}, nil
+		}
+
+	case "number":
+		if op == "is_empty" || op == "is_not_empty" {
+			return func(p *Page) bool {
+				return (propertyValue(p, schema) == nil) == (op == "is_empty")
+			}, nil
+		}
+		want, ok := operand.(float64)
+		if !ok {
+			return nil, fmt.Errorf("number.%s should be a number", op)
+		}
+		var test func(int) bool
+		switch op {
+		case "equals":
+			test = func(c int) bool { return c == 0 }
+		case "does_not_equal":
+			test = func(c int) bool { return c != 0 }
+		case "greater_than":
+			test = func(c int) bool { return c > 0 }
+		case "less_than":
+			test = func(c int) bool { return c < 0 }
+		case "greater_than_or_equal_to":
+			test = func(c int) bool { return c >= 0 }
+		case "less_than_or_equal_to":
+			test = func(c int) bool { return c <= 0 }
+		}
+		if test != nil {
+			return func(p *Page) bool {
+				n, ok := propertyValue(p, schema).(float64)
+				return ok && test(cmp.Compare(n, want))
+			}, nil
+		}
+
+	case "checkbox":
+		want, ok := operand.(bool)
+		if !ok {
+			return nil, fmt.Errorf("checkbox.%s should be a boolean", op)
+		}
+		if op == "equals" || op == "does_not_equal" {
+			return func(p *Page) bool {
+				got, _ := propertyValue(p, schema).(bool)
+				return (got == want) == (op == "equals")
+			}, nil
+		}
+
+	case "date":
+		match, err := compileDateCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Page) bool {
+			d, _ := propertyValue(p, schema).(map[string]any)
+			start, _ := d["start"].(string)
+			return match(start)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported %s filter condition: %s", schema.Type, op)
+}
+
+// compileTextCondition handles the string operators shared by text-like properties
+func compileTextCondition(op string, operand any) (func(string) bool, error) {
+	switch op {
+	case "is_empty":
+		return func(s string) bool { return s == "" }, nil
+	case "is_not_empty":
+		return func(s string) bool { return s != "" }, nil
+	}
+
+	want, ok := operand.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s should be a string", op)
+	}
+	lower := strings.ToLower(want)
+	switch op {
+	case "equals":
+		return func(s string) bool { return s == want }, nil
+	case "does_not_equal":
+		return func(s string) bool { return s != want }, nil
+	case "contains":
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), lower) }, nil
+	case "does_not_contain":
+		return func(s string) bool { return !strings.Contains(strings.ToLower(s), lower) }, nil
+	case "starts_with":
+		return func(s string) bool { return strings.HasPrefix(strings.ToLower(s), lower) }, nil
+	case "ends_with":
+		return func(s string) bool { return strings.HasSuffix(strings.ToLower(s), lower) }, nil
+	}
+	return nil, fmt.Errorf("unsupported text filter condition: %s", op)
+}
+
+// parseDate accepts the date-only and date-time forms Notion uses
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// compareDates compares two Notion dates. If either side is date-only the
+// comparison is by calendar day, matching how Notion treats all-day dates.
+func compareDates(a, b string) (int, error) {
+	ta, err := parseDate(a)
+	if err != nil {
+		return 0, err
+	}
+	tb, err := parseDate(b)
+	if err != nil {
+		return 0, err
+	}
+	if len(a) == len("2006-01-02") || len(b) == len("2006-01-02") {
+		return strings.Compare(ta.UTC().Format("2006-01-02"), tb.UTC().Format("2006-01-02")), nil
+	}
+	return ta.Compare(tb), nil
+}
+
+// compileDateCondition returns a matcher over ISO 8601 date strings ("" = unset)
+func compileDateCondition(cond map[string]any) (func(string) bool, error) {
+	op, operand, err := singleCondition(cond)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "is_empty":
+		return func(s string) bool { return s == "" }, nil
+	case "is_not_empty":
+		return func(s string) bool { return s != "" }, nil
+	}
+
+	want, ok := operand.(string)
+	if !ok {
+		return nil, fmt.Errorf("date.%s should be an ISO 8601 date string", op)
+	}
+	if _, err := parseDate(want); err != nil {
+		return nil, fmt.Errorf("date.%s should be an ISO 8601 date string", op)
+	}
+
+	var test func(int) bool
+	switch op {
+	case "equals":
+		test = func(c int) bool { return c == 0 }
+	case "before":
+		test = func(c int) bool { return c < 0 }
+	case "after":
+		test = func(c int) bool { return c > 0 }
+	case "on_or_before":
+		test = func(c int) bool { return c <= 0 }
+	case "on_or_after":
+		test = func(c int) bool { return c >= 0 }
+	default:
+		return nil, fmt.Errorf("unsupported date filter condition: %s", op)
+	}
+
+	return func(s string) bool {
+		if s == "" {
+			return false
+		}
+		c, err := compareDates(s, want)
+		return err == nil && test(c)
+	}, nil
+}
+
+// compileSorts turns a Notion sorts array into a comparison function
+func compileSorts(sorts []any, schemas map[string]*PropertySchema) (func(a, b *Page) int, error) {
+	var cmps []func(a, b *Page) int
+	for _, item := range sorts {
+		sort, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("sorts items should be objects")
+		}
+
+		direction, _ := sort["direction"].(string)
+		if direction != "ascending" && direction != "descending" {
+			return nil, fmt.Errorf("sort direction should be ascending or descending, instead was %q", direction)
+		}
+		sign := 1
+		if direction == "descending" {
+			sign = -1
+		}
+
+		var key func(*Page) string
+		if ts, ok := sort["timestamp"].(string); ok {
+			switch ts {
+			case "created_time":
+				key = func(p *Page) string { return p.CreatedTime.UTC().Format(time.RFC3339Nano) }
+			case "last_edited_time":
+				key = func(p *Page) string { return p.LastEditedTime.UTC().Format(time.RFC3339Nano) }
+			default:
+				return nil, fmt.Errorf("sort timestamp should be created_time or last_edited_time")
+			}
+		} else if name, ok := sort["property"].(string); ok {
+			schema := lookupProperty(schemas, name)
+			if schema == nil {
+				return nil, fmt.Errorf("Could not find sort property with name or id: %s", name)
+			}
+			if schema.Type == "number" {
+				cmps = append(cmps, func(a, b *Page) int {
+					na, _ := propertyValue(a, schema).(float64)
+					nb, _ := propertyValue(b, schema).(float64)
+					return sign * cmp.Compare(na, nb)
+				})
+				continue
+			}
+			key = func(p *Page) string { return sortKey(p, schema) }
+		} else {
+			return nil, fmt.Errorf("sort should have a property or timestamp")
+		}
+		cmps = append(cmps, func(a, b *Page) int { return sign * strings.Compare(key(a), key(b)) })
+	}
+
+	return func(a, b *Page) int {
+		for _, c := range cmps {
+			if r := c(a, b); r != 0 {
+				return r
+			}
+		}
+		return 0
+	}, nil
+}
+
+// sortKey renders a property value as a string that sorts naturally.
+// Select options sort by their position in the schema, like Notion does.
+func sortKey(p *Page, schema *PropertySchema) string {
+	v := propertyValue(p, schema)
+	switch schema.Type {
+	case "title", "rich_text":
+		return strings.ToLower(plainText(v))
+	case "select", "status":
+		opt, _ := v.(map[string]any)
+		name, _ := opt["name"].(string)
+		for i, o := range schema.Options {
+			if o.Name == name {
+				return fmt.Sprintf("%04d", i)
+			}
+		}
+		return "9999" + name
+	case "date":
+		d, _ := v.(map[string]any)
+		start, _ := d["start"].(string)
+		return start
+	case "checkbox":
+		if b, _ := v.(bool); b {
+			return "1"
+		}
+		return "0"
+	}
+	s, _ := v.(string)
+	return s
+}
+
+// paginate slices items starting at the item whose ID is cursor. Notion
+// cursors are opaque, but in practice are the ID of the next result.
+func paginate[T any](items []T, id func(T) string, cursor string, pageSize int) ([]T, string, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	start := 0
+	if cursor != "" {
+		cursor = normalizeID(cursor)
+		start = slices.IndexFunc(items, func(item T) bool { return id(item) == cursor })
+		if start < 0 {
+			return nil, "", fmt.Errorf("start_cursor provided is invalid: %s", cursor)
+		}
+	}
+
+	end := min(start+pageSize, len(items))
+	var next string
+	if end < len(items) {
+		next = id(items[end])
+	}
+	return items[start:end], next, nil
+}
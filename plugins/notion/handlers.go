@@ -0,0 +1,589 @@
+// ABOUTME: HTTP handlers for Notion pages, database queries, and block children
+// ABOUTME: Renders pages and blocks in Notion API response format
+
+package notion
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxChildren is Notion's limit on blocks per append request
+const maxChildren = 100
+
+// maxNestingDepth is how many levels of nested children one request may create
+const maxNestingDepth = 2
+
+// blockTypes lists the block types that can be created through the API
+var blockTypes = map[string]bool{
+	"paragraph":          true,
+	"heading_1":          true,
+	"heading_2":          true,
+	"heading_3":          true,
+	"bulleted_list_item": true,
+	"numbered_list_item": true,
+	"to_do":              true,
+	"toggle":             true,
+	"quote":              true,
+	"callout":            true,
+	"code":               true,
+	"divider":            true,
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func userRef(id string) map[string]any {
+	return map[string]any{"object": "user", "id": id}
+}
+
+func renderParent(parentType, parentID string) map[string]any {
+	if parentType == parentWorkspace {
+		return map[string]any{"type": parentWorkspace, parentWorkspace: true}
+	}
+	return map[string]any{"type": parentType, parentType: parentID}
+}
+
+// pageURL builds a notion.so URL from the page title and compact ID
+func pageURL(p *Page) string {
+	words := strings.FieldsFunc(pageTitle(p), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	slug := strings.ReplaceAll(p.ID, "-", "")
+	if len(words) > 0 {
+		slug = strings.Join(words, "-") + "-" + slug
+	}
+	return "https://www.notion.so/" + slug
+}
+
+// renderPage renders a page, filling in empty values for unset schema properties
+func renderPage(p *Page, schemas map[string]*PropertySchema) map[string]any {
+	props := make(map[string]any, len(schemas))
+	for name, schema := range schemas {
+		props[name] = emptyPropertyValue(schema)
+	}
+	for name, value := range p.Properties {
+		props[name] = value
+	}
+
+	var icon, cover any
+	if p.Icon != nil {
+		icon = p.Icon
+	}
+	if p.Cover != nil {
+		cover = p.Cover
+	}
+
+	return map[string]any{
+		"object":           "page",
+		"id":               p.ID,
+		"created_time":     formatTime(p.CreatedTime),
+		"last_edited_time": formatTime(p.LastEditedTime),
+		"created_by":       userRef(p.CreatedBy),
+		"last_edited_by":   userRef(p.LastEditedBy),
+		"cover":            cover,
+		"icon":             icon,
+		"parent":           renderParent(p.ParentType, p.ParentID),
+		"archived":         p.Archived,
+		"in_trash":         p.Archived,
+		"properties":       props,
+		"url":              pageURL(p),
+		"public_url":       nil,
+	}
+}
+
+func renderBlock(b *Block) map[string]any {
+	return map[string]any{
+		"object":           "block",
+		"id":               b.ID,
+		"parent":           renderParent(b.ParentType, b.ParentID),
+		"created_time":     formatTime(b.CreatedTime),
+		"last_edited_time": formatTime(b.LastEditedTime),
+		"created_by":       userRef(b.CreatedBy),
+		"last_edited_by":   userRef(b.LastEditedBy),
+		"has_children":     b.HasChildren,
+		"archived":         b.Archived,
+		"in_trash":         b.Archived,
+		"type":             b.Type,
+		b.Type:             b.Content,
+	}
+}
+
+func renderList(results []any, nextCursor, resultType string) map[string]any {
+	var next any
+	if nextCursor != "" {
+		next = nextCursor
+	}
+	return map[string]any{
+		"object":      "list",
+		"results":     results,
+		"next_cursor": next,
+		"has_more":    nextCursor != "",
+		"type":        resultType,
+		resultType:    map[string]any{},
+	}
+}
+
+// parseBlocks converts request block objects into Blocks, normalizing rich
+// text and pulling nested children out of the type-specific content.
+func parseBlocks(items []any, depth int) ([]*Block, error) {
+	if len(items) > maxChildren {
+		return nil, fmt.Errorf("body.children.length should be ≤ %d, instead was %d", maxChildren, len(items))
+	}
+
+	blocks := make([]*Block, 0, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("body.children[%d] should be an object", i)
+		}
+
+		blockType, _ := obj["type"].(string)
+		if blockType == "" {
+			for k := range obj {
+				if blockTypes[k] {
+					blockType = k
+					break
+				}
+			}
+		}
+		if !blockTypes[blockType] {
+			return nil, fmt.Errorf("body.children[%d] has unsupported block type %q", i, blockType)
+		}
+
+		content := map[string]any{}
+		if raw, ok := obj[blockType].(map[string]any); ok {
+			for k, v := range raw {
+				content[k] = v
+			}
+		}
+
+		if rt, ok := content["rich_text"]; ok {
+			normalized, err := normalizeRichText(rt)
+			if err != nil {
+				return nil, fmt.Errorf("body.children[%d].%s.rich_text %w", i, blockType, err)
+			}
+			content["rich_text"] = normalized
+		} else if blockType != "divider" {
+			content["rich_text"] = []any{}
+		}
+		if blockType != "divider" {
+			if _, ok := content["color"]; !ok {
+				content["color"] = "default"
+			}
+		}
+		switch blockType {
+		case "to_do":
+			if _, ok := content["checked"].(bool); !ok {
+				content["checked"] = false
+			}
+		case "code":
+			if _, ok := content["language"].(string); !ok {
+				content["language"] = "plain text"
+			}
+			delete(content, "color")
+		case "heading_1", "heading_2", "heading_3":
+			if _, ok := content["is_toggleable"].(bool); !ok {
+				content["is_toggleable"] = false
+			}
+		}
+
+		block := &Block{Type: blockType, Content: content}
+		if rawChildren, ok := content["children"]; ok {
+			delete(content, "children")
+			if depth >= maxNestingDepth {
+				return nil, fmt.Errorf("body.children[%d] nests children deeper than %d levels", i, maxNestingDepth)
+			}
+			childItems, ok := rawChildren.([]any)
+			if !ok {
+				return nil, fmt.Errorf("body.children[%d].%s.children should be an array", i, blockType)
+			}
+			children, err := parseBlocks(childItems, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			block.Children = children
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func setCreatedBy(blocks []*Block, userID string) {
+	for _, b := range blocks {
+		b.CreatedBy = userID
+		setCreatedBy(b.Children, userID)
+	}
+}
+
+// decodeBody decodes a JSON request body, treating an empty body as {}
+func decodeBody(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func writeInvalidJSON(w http.ResponseWriter) {
+	writeError(w, http.StatusBadRequest, "invalid_json", "Error parsing JSON body.")
+}
+
+func writeValidationError(w http.ResponseWriter, message string) {
+	writeError(w, http.StatusBadRequest, "validation_error", message)
+}
+
+func writeNotFound(w http.ResponseWriter, kind, id string) {
+	writeError(w, http.StatusNotFound, "object_not_found",
+		fmt.Sprintf("Could not find %s with ID: %s. Make sure the relevant pages and databases are shared with your integration.", kind, normalizeID(id)))
+}
+
+func writeInternalError(w http.ResponseWriter) {
+	writeError(w, http.StatusInternalServerError, "internal_server_error", "Unexpected error occurred.")
+}
+
+// parsePageSize reads the page_size query parameter
+func parsePageSize(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("page_size")
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > maxPageSize {
+		return 0, fmt.Errorf("page_size should be a number between 1 and %d", maxPageSize)
+	}
+	return n, nil
+}
+
+// createPage handles POST /v1/pages
+func (p *NotionPlugin) createPage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Parent     map[string]any `json:"parent"`
+		Properties map[string]any `json:"properties"`
+		Children   []any          `json:"children"`
+		Icon       map[string]any `json:"icon"`
+		Cover      map[string]any `json:"cover"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeInvalidJSON(w)
+		return
+	}
+	if req.Parent == nil {
+		writeValidationError(w, "body failed validation: body.parent should be defined, instead was `undefined`.")
+		return
+	}
+
+	page := &Page{Icon: req.Icon, Cover: req.Cover, CreatedBy: botFromContext(r.Context())}
+	schemas := map[string]*PropertySchema{"title": titleSchema}
+	if id, ok := req.Parent["database_id"].(string); ok {
+		db, err := p.store.GetDatabase(id)
+		if err == sql.ErrNoRows {
+			writeNotFound(w, "database", id)
+			return
+		} else if err != nil {
+			writeInternalError(w)
+			return
+		}
+		page.ParentType, page.ParentID = parentDatabase, db.ID
+		schemas = db.Properties
+	} else if id, ok := req.Parent["page_id"].(string); ok {
+		parent, err := p.store.GetPage(id)
+		if err == sql.ErrNoRows {
+			writeNotFound(w, "page", id)
+			return
+		} else if err != nil {
+			writeInternalError(w)
+			return
+		}
+		page.ParentType, page.ParentID = parentPage, parent.ID
+	} else {
+		writeValidationError(w, "body failed validation: body.parent.database_id or body.parent.page_id should be defined.")
+		return
+	}
+
+	props, err := applyProperties(schemas, nil, req.Properties)
+	if err != nil {
+		writeValidationError(w, err.Error()+".")
+		return
+	}
+	page.Properties = props
+
+	children, err := parseBlocks(req.Children, 0)
+	if err != nil {
+		writeValidationError(w, "body failed validation: "+err.Error()+".")
+		return
+	}
+	setCreatedBy(children, page.CreatedBy)
+
+	if err := p.store.CreatePage(page); err != nil {
+		writeInternalError(w)
+		return
+	}
+	if err := p.store.AppendBlocks(parentPage, page.ID, children); err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, renderPage(page, schemas))
+}
+
+// loadPage fetches a page and its schema, writing an error response on failure
+func (p *NotionPlugin) loadPage(w http.ResponseWriter, id string) (*Page, map[string]*PropertySchema, bool) {
+	page, err := p.store.GetPage(id)
+	if err == sql.ErrNoRows {
+		writeNotFound(w, "page", id)
+		return nil, nil, false
+	} else if err != nil {
+		writeInternalError(w)
+		return nil, nil, false
+	}
+	schemas, err := p.store.pageSchema(page)
+	if err != nil {
+		writeInternalError(w)
+		return nil, nil, false
+	}
+	return page, schemas, true
+}
+
+// getPage handles GET /v1/pages/{page_id}
+func (p *NotionPlugin) getPage(w http.ResponseWriter, r *http.Request) {
+	page, schemas, ok := p.loadPage(w, chi.URLParam(r, "page_id"))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, renderPage(page, schemas))
+}
+
+// updatePage handles PATCH /v1/pages/{page_id}
+func (p *NotionPlugin) updatePage(w http.ResponseWriter, r *http.Request) {
+	page, schemas, ok := p.loadPage(w, chi.URLParam(r, "page_id"))
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Properties map[string]any  `json:"properties"`
+		Archived   *bool           `json:"archived"`
+		InTrash    *bool           `json:"in_trash"`
+		Icon       json.RawMessage `json:"icon"`
+		Cover      json.RawMessage `json:"cover"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeInvalidJSON(w)
+		return
+	}
+
+	archived := req.Archived
+	if archived == nil {
+		archived = req.InTrash
+	}
+	if page.Archived && (archived == nil || *archived) && len(req.Properties) > 0 {
+		writeValidationError(w, "Can't edit block that is archived. You must unarchive the block before editing.")
+		return
+	}
+
+	props, err := applyProperties(schemas, page.Properties, req.Properties)
+	if err != nil {
+		writeValidationError(w, err.Error()+".")
+		return
+	}
+	page.Properties = props
+	if archived != nil {
+		page.Archived = *archived
+	}
+	for _, field := range []struct {
+		raw json.RawMessage
+		dst *map[string]any
+	}{{req.Icon, &page.Icon}, {req.Cover, &page.Cover}} {
+		if len(field.raw) == 0 {
+			continue
+		}
+		var v map[string]any
+		if err := json.Unmarshal(field.raw, &v); err != nil {
+			writeInvalidJSON(w)
+			return
+		}
+		*field.dst = v
+	}
+	page.LastEditedBy = botFromContext(r.Context())
+
+	if err := p.store.UpdatePage(page); err != nil {
+		writeInternalError(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, renderPage(page, schemas))
+}
+
+// queryDatabase handles GET and POST /v1/databases/{database_id}/query
+func (p *NotionPlugin) queryDatabase(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "database_id")
+	db, err := p.store.GetDatabase(id)
+	if err == sql.ErrNoRows {
+		writeNotFound(w, "database", id)
+		return
+	} else if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	var req struct {
+		Filter      map[string]any `json:"filter"`
+		Sorts       []any          `json:"sorts"`
+		StartCursor string         `json:"start_cursor"`
+		PageSize    int            `json:"page_size"`
+	}
+	if r.Method == http.MethodPost {
+		if err := decodeBody(r, &req); err != nil {
+			writeInvalidJSON(w)
+			return
+		}
+		if req.PageSize < 0 || req.PageSize > maxPageSize {
+			writeValidationError(w, fmt.Sprintf("body failed validation: body.page_size should be ≤ %d, instead was %d.", maxPageSize, req.PageSize))
+			return
+		}
+	} else {
+		req.StartCursor = r.URL.Query().Get("start_cursor")
+		if req.PageSize, err = parsePageSize(r); err != nil {
+			writeValidationError(w, err.Error()+".")
+			return
+		}
+	}
+
+	pages, err := p.store.ListDatabasePages(db.ID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	if req.Filter != nil {
+		match, err := compileFilter(req.Filter, db.Properties)
+		if err != nil {
+			writeValidationError(w, err.Error()+".")
+			return
+		}
+		pages = slices.DeleteFunc(pages, func(page *Page) bool { return !match(page) })
+	}
+	if len(req.Sorts) > 0 {
+		less, err := compileSorts(req.Sorts, db.Properties)
+		if err != nil {
+			writeValidationError(w, err.Error()+".")
+			return
+		}
+		slices.SortStableFunc(pages, less)
+	}
+
+	pageItems, next, err := paginate(pages, func(page *Page) string { return page.ID }, req.StartCursor, req.PageSize)
+	if err != nil {
+		writeValidationError(w, err.Error()+".")
+		return
+	}
+
+	results := make([]any, 0, len(pageItems))
+	for _, page := range pageItems {
+		results = append(results, renderPage(page, db.Properties))
+	}
+	writeJSON(w, http.StatusOK, renderList(results, next, "page_or_database"))
+}
+
+// resolveBlockParent finds the page or block a children request refers to
+func (p *NotionPlugin) resolveBlockParent(w http.ResponseWriter, id string) (string, string, bool) {
+	page, err := p.store.GetPage(id)
+	if err == nil {
+		return parentPage, page.ID, true
+	} else if err != sql.ErrNoRows {
+		writeInternalError(w)
+		return "", "", false
+	}
+
+	block, err := p.store.GetBlock(id)
+	if err == sql.ErrNoRows {
+		writeNotFound(w, "block", id)
+		return "", "", false
+	} else if err != nil {
+		writeInternalError(w)
+		return "", "", false
+	}
+	return parentBlock, block.ID, true
+}
+
+// listBlockChildren handles GET /v1/blocks/{block_id}/children
+func (p *NotionPlugin) listBlockChildren(w http.ResponseWriter, r *http.Request) {
+	_, parentID, ok := p.resolveBlockParent(w, chi.URLParam(r, "block_id"))
+	if !ok {
+		return
+	}
+
+	pageSize, err := parsePageSize(r)
+	if err != nil {
+		writeValidationError(w, err.Error()+".")
+		return
+	}
+
+	blocks, err := p.store.ListChildBlocks(parentID)
+	if err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	items, next, err := paginate(blocks, func(b *Block) string { return b.ID }, r.URL.Query().Get("start_cursor"), pageSize)
+	if err != nil {
+		writeValidationError(w, err.Error()+".")
+		return
+	}
+
+	results := make([]any, 0, len(items))
+	for _, b := range items {
+		results = append(results, renderBlock(b))
+	}
+	writeJSON(w, http.StatusOK, renderList(results, next, "block"))
+}
+
+// appendBlockChildren handles POST and PATCH /v1/blocks/{block_id}/children
+func (p *NotionPlugin) appendBlockChildren(w http.ResponseWriter, r *http.Request) {
+	parentType, parentID, ok := p.resolveBlockParent(w, chi.URLParam(r, "block_id"))
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Children []any `json:"children"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeInvalidJSON(w)
+		return
+	}
+	if req.Children == nil {
+		writeValidationError(w, "body failed validation: body.children should be defined, instead was `undefined`.")
+		return
+	}
+
+	blocks, err := parseBlocks(req.Children, 0)
+	if err != nil {
+		writeValidationError(w, "body failed validation: "+err.Error()+".")
+		return
+	}
+	setCreatedBy(blocks, botFromContext(r.Context()))
+
+	if err := p.store.AppendBlocks(parentType, parentID, blocks); err != nil {
+		writeInternalError(w)
+		return
+	}
+
+	results := make([]any, 0, len(blocks))
+	for _, b := range blocks {
+		results = append(results, renderBlock(b))
+	}
+	writeJSON(w, http.StatusOK, renderList(results, "", "block"))
+}
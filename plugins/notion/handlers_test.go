@@ -0,0 +1,410 @@
+// ABOUTME: Tests for Notion API handlers
+// ABOUTME: Covers auth and version checks, pages, database query filters and pagination, and block children
+
+package notion
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/migrations"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestPlugin(t *testing.T) (*NotionPlugin, chi.Router) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	plugin := &NotionPlugin{}
+	if err := plugin.SetDB(db); err != nil {
+		t.Fatalf("Failed to initialize plugin: %v", err)
+	}
+	if _, err := plugin.Seed(context.Background(), "small"); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+	return plugin, r
+}
+
+func doRequest(t *testing.T, r chi.Router, method, target string, body any, wantStatus int) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("Failed to encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, target, &buf)
+	req.Header.Set("Authorization", "Bearer secret_test")
+	req.Header.Set("Notion-Version", "2022-06-28")
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != wantStatus {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, target, wantStatus, rr.Code, rr.Body.String())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func tasksDatabase(t *testing.T, plugin *NotionPlugin) *Database {
+	t.Helper()
+	databases, err := plugin.store.ListDatabases()
+	if err != nil || len(databases) != 1 {
+		t.Fatalf("Expected seeded database, got %v (%v)", databases, err)
+	}
+	return databases[0]
+}
+
+func resultTitles(resp map[string]any) []string {
+	var titles []string
+	for _, item := range resp["results"].([]any) {
+		props := item.(map[string]any)["properties"].(map[string]any)
+		titles = append(titles, plainText(props["Name"].(map[string]any)["title"]))
+	}
+	return titles
+}
+
+func titleInput(text string) map[string]any {
+	return map[string]any{"title": []any{map[string]any{"text": map[string]any{"content": text}}}}
+}
+
+func TestRequireAuthAndVersion(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	tests := []struct {
+		name, auth, version, code string
+		status                    int
+	}{
+		{"missing token", "", "2022-06-28", "unauthorized", http.StatusUnauthorized},
+		{"wrong scheme", "Basic abc", "2022-06-28", "unauthorized", http.StatusUnauthorized},
+		{"missing version", "Bearer secret_x", "", "missing_version", http.StatusBadRequest},
+		{"bad version", "Bearer secret_x", "2099-01-01", "validation_error", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/pages/abc", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.version != "" {
+				req.Header.Set("Notion-Version", tt.version)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected %d, got %d", tt.status, rr.Code)
+			}
+			var resp map[string]any
+			json.Unmarshal(rr.Body.Bytes(), &resp)
+			if resp["object"] != "error" || resp["code"] != tt.code {
+				t.Errorf("Expected error code %s, got %v", tt.code, resp)
+			}
+		})
+	}
+}
+
+func TestCreateAndGetPage(t *testing.T) {
+	plugin, r := setupTestPlugin(t)
+	db := tasksDatabase(t, plugin)
+
+	created := doRequest(t, r, "POST", "/v1/pages", map[string]any{
+		"parent": map[string]any{"database_id": db.ID},
+		"properties": map[string]any{
+			"Name":   titleInput("Write API docs"),
+			"Status": map[string]any{"select": map[string]any{"name": "In progress"}},
+			"Tags":   map[string]any{"multi_select": []any{map[string]any{"name": "engineering"}, map[string]any{"name": "docs"}}},
+		},
+		"children": []any{
+			map[string]any{"paragraph": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Draft outline"}}}}},
+		},
+	}, http.StatusOK)
+
+	if created["object"] != "page" {
+		t.Fatalf("Expected page object, got %v", created["object"])
+	}
+	parent := created["parent"].(map[string]any)
+	if parent["type"] != "database_id" || parent["database_id"] != db.ID {
+		t.Errorf("Unexpected parent: %v", parent)
+	}
+
+	props := created["properties"].(map[string]any)
+	status := props["Status"].(map[string]any)["select"].(map[string]any)
+	if status["name"] != "In progress" || status["color"] != "blue" {
+		t.Errorf("Expected existing select option, got %v", status)
+	}
+	tags := props["Tags"].(map[string]any)["multi_select"].([]any)
+	if len(tags) != 2 || tags[1].(map[string]any)["color"] != "default" {
+		t.Errorf("Expected new multi_select option with default color, got %v", tags)
+	}
+	if props["Estimate"].(map[string]any)["number"] != nil {
+		t.Errorf("Expected unset number property to be null, got %v", props["Estimate"])
+	}
+	if !strings.HasPrefix(created["url"].(string), "https://www.notion.so/Write-API-docs-") {
+		t.Errorf("Unexpected url: %v", created["url"])
+	}
+
+	// Fetch with the dashless ID form Notion URLs use
+	id := created["id"].(string)
+	fetched := doRequest(t, r, "GET", "/v1/pages/"+strings.ReplaceAll(id, "-", ""), nil, http.StatusOK)
+	if fetched["id"] != id {
+		t.Errorf("Expected id %s, got %v", id, fetched["id"])
+	}
+
+	children := doRequest(t, r, "GET", "/v1/blocks/"+id+"/children", nil, http.StatusOK)
+	results := children["results"].([]any)
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 child block, got %d", len(results))
+	}
+	paragraph := results[0].(map[string]any)["paragraph"].(map[string]any)
+	if plainText(paragraph["rich_text"]) != "Draft outline" {
+		t.Errorf("Unexpected paragraph: %v", paragraph)
+	}
+}
+
+func TestCreatePageValidation(t *testing.T) {
+	plugin, r := setupTestPlugin(t)
+	db := tasksDatabase(t, plugin)
+
+	resp := doRequest(t, r, "POST", "/v1/pages", map[string]any{
+		"properties": map[string]any{"Name": titleInput("No parent")},
+	}, http.StatusBadRequest)
+	if resp["code"] != "validation_error" {
+		t.Errorf("Expected validation_error, got %v", resp["code"])
+	}
+
+	doRequest(t, r, "POST", "/v1/pages", map[string]any{
+		"parent":     map[string]any{"database_id": db.ID},
+		"properties": map[string]any{"Nope": titleInput("x")},
+	}, http.StatusBadRequest)
+
+	doRequest(t, r, "POST", "/v1/pages", map[string]any{
+		"parent":     map[string]any{"database_id": db.ID},
+		"properties": map[string]any{"Estimate": map[string]any{"number": "three"}},
+	}, http.StatusBadRequest)
+
+	resp = doRequest(t, r, "POST", "/v1/pages", map[string]any{
+		"parent": map[string]any{"page_id": "00000000-0000-0000-0000-000000000000"},
+	}, http.StatusNotFound)
+	if resp["code"] != "object_not_found" {
+		t.Errorf("Expected object_not_found, got %v", resp["code"])
+	}
+}
+
+func TestUpdatePage(t *testing.T) {
+	plugin, r := setupTestPlugin(t)
+	db := tasksDatabase(t, plugin)
+
+	created := doRequest(t, r, "POST", "/v1/pages", map[string]any{
+		"parent":     map[string]any{"database_id": db.ID},
+		"properties": map[string]any{"Name": titleInput("Temp"), "Estimate": map[string]any{"number": 2}},
+	}, http.StatusOK)
+	id := created["id"].(string)
+
+	updated := doRequest(t, r, "PATCH", "/v1/pages/"+id, map[string]any{
+		"properties": map[string]any{"Status": map[string]any{"select": map[string]any{"name": "Done"}}},
+		"icon":       map[string]any{"type": "emoji", "emoji": "✅"},
+	}, http.StatusOK)
+	props := updated["properties"].(map[string]any)
+	if props["Status"].(map[string]any)["select"].(map[string]any)["name"] != "Done" {
+		t.Errorf("Status not updated: %v", props["Status"])
+	}
+	if props["Estimate"].(map[string]any)["number"] != float64(2) {
+		t.Errorf("Existing properties should be preserved, got %v", props["Estimate"])
+	}
+	if updated["icon"].(map[string]any)["emoji"] != "✅" {
+		t.Errorf("Icon not updated: %v", updated["icon"])
+	}
+
+	archived := doRequest(t, r, "PATCH", "/v1/pages/"+id, map[string]any{"archived": true}, http.StatusOK)
+	if archived["archived"] != true {
+		t.Fatal("Expected page to be archived")
+	}
+	doRequest(t, r, "PATCH", "/v1/pages/"+id, map[string]any{
+		"properties": map[string]any{"Name": titleInput("Edit while archived")},
+	}, http.StatusBadRequest)
+
+	// Archived pages drop out of database queries
+	resp := doRequest(t, r, "POST", "/v1/databases/"+db.ID+"/query", map[string]any{}, http.StatusOK)
+	for _, title := range resultTitles(resp) {
+		if title == "Temp" {
+			t.Error("Archived page should not be returned by query")
+		}
+	}
+}
+
+func TestQueryDatabaseFilters(t *testing.T) {
+	plugin, r := setupTestPlugin(t)
+	db := tasksDatabase(t, plugin)
+	target := "/v1/databases/" + db.ID + "/query"
+
+	tests := []struct {
+		name   string
+		filter map[string]any
+		want   int
+	}{
+		{"select equals", map[string]any{"property": "Status", "select": map[string]any{"equals": "Done"}}, 2},
+		{"select does_not_equal", map[string]any{"property": "Status", "select": map[string]any{"does_not_equal": "Done"}}, 4},
+		{"title contains", map[string]any{"property": "Name", "title": map[string]any{"contains": "page"}}, 1},
+		{"multi_select contains", map[string]any{"property": "Tags", "multi_select": map[string]any{"contains": "engineering"}}, 2},
+		{"multi_select is_empty", map[string]any{"property": "Tags", "multi_select": map[string]any{"is_empty": true}}, 1},
+		{"number greater_than", map[string]any{"property": "Estimate", "number": map[string]any{"greater_than": 2}}, 3},
+		{"checkbox equals", map[string]any{"property": "Blocked", "checkbox": map[string]any{"equals": true}}, 1},
+		{"date before", map[string]any{"property": "Due", "date": map[string]any{"before": "2025-01-20"}}, 2},
+		{"date is_empty", map[string]any{"property": "Due", "date": map[string]any{"is_empty": true}}, 1},
+		{"and", map[string]any{"and": []any{
+			map[string]any{"property": "Status", "select": map[string]any{"equals": "In progress"}},
+			map[string]any{"property": "Priority", "select": map[string]any{"equals": "High"}},
+			map[string]any{"property": "Blocked", "checkbox": map[string]any{"equals": false}},
+		}}, 1},
+		{"or", map[string]any{"or": []any{
+			map[string]any{"property": "Status", "select": map[string]any{"equals": "Done"}},
+			map[string]any{"property": "Priority", "select": map[string]any{"equals": "High"}},
+		}}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, r, "POST", target, map[string]any{"filter": tt.filter}, http.StatusOK)
+			if got := len(resp["results"].([]any)); got != tt.want {
+				t.Errorf("Expected %d results, got %d: %v", tt.want, got, resultTitles(resp))
+			}
+		})
+	}
+
+	invalid := []map[string]any{
+		{"property": "Missing", "select": map[string]any{"equals": "Done"}},
+		{"property": "Status", "number": map[string]any{"equals": 1}},
+		{"property": "Status", "select": map[string]any{"greater_than": "Done"}},
+		{"property": "Due", "date": map[string]any{"before": "not-a-date"}},
+	}
+	for _, filter := range invalid {
+		resp := doRequest(t, r, "POST", target, map[string]any{"filter": filter}, http.StatusBadRequest)
+		if resp["code"] != "validation_error" {
+			t.Errorf("Expected validation_error for %v, got %v", filter, resp["code"])
+		}
+	}
+}
+
+func TestQueryDatabaseSortsAndPagination(t *testing.T) {
+	plugin, r := setupTestPlugin(t)
+	db := tasksDatabase(t, plugin)
+	target := "/v1/databases/" + db.ID + "/query"
+
+	sorts := []any{
+		map[string]any{"property": "Estimate", "direction": "descending"},
+		map[string]any{"property": "Name", "direction": "ascending"},
+	}
+	first := doRequest(t, r, "POST", target, map[string]any{"sorts": sorts, "page_size": 4}, http.StatusOK)
+	if got := strings.Join(resultTitles(first), "|"); got != "Migrate search to new index|Design new landing page|Write onboarding guide|Fix flaky login test" {
+		t.Errorf("Unexpected sort order: %s", got)
+	}
+	if first["has_more"] != true || first["next_cursor"] == nil {
+		t.Fatalf("Expected more results, got %v / %v", first["has_more"], first["next_cursor"])
+	}
+
+	second := doRequest(t, r, "POST", target, map[string]any{
+		"sorts": sorts, "page_size": 4, "start_cursor": first["next_cursor"],
+	}, http.StatusOK)
+	if got := strings.Join(resultTitles(second), "|"); got != "Quarterly roadmap review|Update brand colors" {
+		t.Errorf("Unexpected second page: %s", got)
+	}
+	if second["has_more"] != false || second["next_cursor"] != nil {
+		t.Errorf("Expected last page, got %v / %v", second["has_more"], second["next_cursor"])
+	}
+
+	// GET takes pagination from the query string
+	viaGet := doRequest(t, r, "GET", target+"?page_size=5", nil, http.StatusOK)
+	if len(viaGet["results"].([]any)) != 5 || viaGet["has_more"] != true {
+		t.Errorf("Expected 5 results with more via GET, got %d", len(viaGet["results"].([]any)))
+	}
+
+	doRequest(t, r, "POST", target, map[string]any{"start_cursor": "bogus"}, http.StatusBadRequest)
+	doRequest(t, r, "POST", target, map[string]any{"page_size": 500}, http.StatusBadRequest)
+	doRequest(t, r, "POST", target, map[string]any{"sorts": []any{map[string]any{"property": "Name"}}}, http.StatusBadRequest)
+	doRequest(t, r, "POST", "/v1/databases/00000000-0000-0000-0000-000000000000/query", nil, http.StatusNotFound)
+}
+
+func TestBlockChildren(t *testing.T) {
+	plugin, r := setupTestPlugin(t)
+
+	pages, _ := plugin.store.ListAllPages(50, 0)
+	var notes *Page
+	for _, p := range pages {
+		if pageTitle(p) == "Weekly Sync Notes" {
+			notes = p
+		}
+	}
+	if notes == nil {
+		t.Fatal("Seeded notes page not found")
+	}
+
+	appended := doRequest(t, r, "POST", "/v1/blocks/"+notes.ID+"/children", map[string]any{
+		"children": []any{
+			map[string]any{"type": "to_do", "to_do": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Follow up"}}}}},
+			map[string]any{"toggle": map[string]any{
+				"rich_text": []any{map[string]any{"text": map[string]any{"content": "Details"}}},
+				"children":  []any{map[string]any{"paragraph": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Nested"}}}}}},
+			}},
+		},
+	}, http.StatusOK)
+	results := appended["results"].([]any)
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 appended blocks, got %d", len(results))
+	}
+	todo := results[0].(map[string]any)
+	if todo["to_do"].(map[string]any)["checked"] != false {
+		t.Errorf("Expected to_do checked default false, got %v", todo["to_do"])
+	}
+	toggle := results[1].(map[string]any)
+	if toggle["has_children"] != true {
+		t.Error("Expected toggle to have children")
+	}
+
+	// Appended blocks go after the existing children
+	list := doRequest(t, r, "GET", "/v1/blocks/"+notes.ID+"/children?page_size=100", nil, http.StatusOK)
+	all := list["results"].([]any)
+	if all[len(all)-1].(map[string]any)["id"] != toggle["id"] {
+		t.Error("Expected appended toggle to be the last child")
+	}
+
+	nested := doRequest(t, r, "GET", "/v1/blocks/"+toggle["id"].(string)+"/children", nil, http.StatusOK)
+	child := nested["results"].([]any)[0].(map[string]any)
+	if child["parent"].(map[string]any)["block_id"] != toggle["id"] {
+		t.Errorf("Expected nested block parent to be toggle, got %v", child["parent"])
+	}
+
+	// Paginate children one at a time
+	paged := doRequest(t, r, "GET", "/v1/blocks/"+notes.ID+"/children?page_size=1", nil, http.StatusOK)
+	cursor := paged["next_cursor"].(string)
+	nextPage := doRequest(t, r, "GET", "/v1/blocks/"+notes.ID+"/children?page_size=1&start_cursor="+cursor, nil, http.StatusOK)
+	if nextPage["results"].([]any)[0].(map[string]any)["id"] != all[1].(map[string]any)["id"] {
+		t.Error("Expected cursor to resume at the second child")
+	}
+
+	doRequest(t, r, "POST", "/v1/blocks/"+notes.ID+"/children", map[string]any{
+		"children": []any{map[string]any{"type": "unsupported_block"}},
+	}, http.StatusBadRequest)
+	doRequest(t, r, "POST", "/v1/blocks/"+notes.ID+"/children", map[string]any{}, http.StatusBadRequest)
+	doRequest(t, r, "GET", "/v1/blocks/00000000-0000-0000-0000-000000000000/children", nil, http.StatusNotFound)
+}
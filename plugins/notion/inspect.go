@@ -0,0 +1,37 @@
+// ABOUTME: core.Inspector implementation for the Notion plugin
+// ABOUTME: Renders pages and blocks as the API does; databases in admin format
+
+package notion
+
+import "fmt"
+
+// Inspect implements core.Inspector for `ish inspect`. The API has no
+// database GET, so databases come back in admin format.
+func (p *NotionPlugin) Inspect(resource, id string) (interface{}, error) {
+	switch resource {
+	case "databases":
+		d, err := p.store.GetDatabase(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertDatabaseToMap(d), nil
+	case "pages":
+		page, err := p.store.GetPage(id)
+		if err != nil {
+			return nil, err
+		}
+		schemas, err := p.store.pageSchema(page)
+		if err != nil {
+			return nil, err
+		}
+		return renderPage(page, schemas), nil
+	case "blocks":
+		b, err := p.store.GetBlock(id)
+		if err != nil {
+			return nil, err
+		}
+		return renderBlock(b), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
@@ -0,0 +1,241 @@
+// ABOUTME: Notion API plugin for ISH
+// ABOUTME: Simulates pages, database queries, and block children with Bearer auth and Notion-Version checks
+
+package notion
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const botKey contextKey = "notion_bot"
+
+// supportedVersions lists the Notion-Version header values the mock accepts
+var supportedVersions = map[string]bool{
+	"2021-05-13": true,
+	"2021-08-16": true,
+	"2022-02-22": true,
+	"2022-06-28": true,
+}
+
+func init() {
+	core.Register(&NotionPlugin{})
+}
+
+type NotionPlugin struct {
+	store *NotionStore
+}
+
+func (p *NotionPlugin) Name() string {
+	return "notion"
+}
+
+func (p *NotionPlugin) Health() core.HealthStatus {
+	return core.HealthStatus{
+		Status:  "healthy",
+		Message: "Notion plugin operational",
+	}
+}
+
+func (p *NotionPlugin) RegisterRoutes(r chi.Router) {
+	// Google People mounts a /v1 subrouter, so these are registered flat rather than via r.Route
+	r.Post("/v1/pages", p.requireAuth(p.createPage))
+	r.Get("/v1/pages/{page_id}", p.requireAuth(p.getPage))
+	r.Patch("/v1/pages/{page_id}", p.requireAuth(p.updatePage))
+
+	r.Get("/v1/databases/{database_id}/query", p.requireAuth(p.queryDatabase))
+	r.Post("/v1/databases/{database_id}/query", p.requireAuth(p.queryDatabase))
+
+	r.Get("/v1/blocks/{block_id}/children", p.requireAuth(p.listBlockChildren))
+	r.Patch("/v1/blocks/{block_id}/children", p.requireAuth(p.appendBlockChildren))
+	r.Post("/v1/blocks/{block_id}/children", p.requireAuth(p.appendBlockChildren))
+}
+
+func (p *NotionPlugin) RegisterAuth(r chi.Router) {
+	// Notion uses integration tokens as Bearer auth, checked per request
+}
+
+// requireAuth validates the Bearer integration token and the Notion-Version
+// header. Any non-empty token is accepted and identifies a bot user.
+func (p *NotionPlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !p.ValidateToken(strings.TrimSpace(token)) {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "API token is invalid.")
+			return
+		}
+
+		version := r.Header.Get("Notion-Version")
+		if version == "" {
+			writeError(w, http.StatusBadRequest, "missing_version",
+				"Notion-Version header failed validation: Notion-Version header should be defined, instead was `undefined`.")
+			return
+		}
+		if !supportedVersions[version] {
+			writeError(w, http.StatusBadRequest, "validation_error",
+				fmt.Sprintf("Notion-Version header failed validation: %q is not a valid Notion-Version.", version))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), botKey, botUserID(strings.TrimSpace(token)))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// botUserID derives a stable user ID for the integration behind a token
+func botUserID(token string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("notion:"+token)).String()
+}
+
+// botFromContext returns the user ID of the authenticated integration
+func botFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(botKey).(string)
+	return id
+}
+
+func (p *NotionPlugin) ValidateToken(token string) bool {
+	return token != ""
+}
+
+func (p *NotionPlugin) SetDB(db *sql.DB) error {
+	store, err := NewNotionStore(db)
+	if err != nil {
+		return err
+	}
+	p.store = store
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Notion: Failed to encode response: %v", err)
+	}
+}
+
+// writeError writes a Notion-style error object
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{
+		"object":  "error",
+		"status":  status,
+		"code":    code,
+		"message": message,
+	})
+}
+
+// ListResources implements core.DataProvider to expose data to admin UI
+func (p *NotionPlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
+	switch slug {
+	case "databases":
+		databases, err := p.store.ListDatabases()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(databases))
+		for _, d := range databases {
+			result = append(result, convertDatabaseToMap(d))
+		}
+		return result, nil
+	case "pages":
+		pages, err := p.store.ListAllPages(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(pages))
+		for _, page := range pages {
+			result = append(result, convertPageToMap(page))
+		}
+		return result, nil
+	case "blocks":
+		blocks, err := p.store.ListAllBlocks(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(blocks))
+		for _, b := range blocks {
+			result = append(result, convertBlockToMap(b))
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+// GetResource implements core.DataProvider to fetch individual resources
+func (p *NotionPlugin) GetResource(ctx context.Context, slug string, id string) (map[string]interface{}, error) {
+	switch slug {
+	case "databases":
+		d, err := p.store.GetDatabase(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertDatabaseToMap(d), nil
+	case "pages":
+		page, err := p.store.GetPage(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertPageToMap(page), nil
+	case "blocks":
+		b, err := p.store.GetBlock(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertBlockToMap(b), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+func convertDatabaseToMap(d *Database) map[string]interface{} {
+	names := make([]string, 0, len(d.Properties))
+	for name := range d.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return map[string]interface{}{
+		"id":           d.ID,
+		"title":        d.Title,
+		"properties":   strings.Join(names, ", "),
+		"created_time": d.CreatedTime.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func convertPageToMap(page *Page) map[string]interface{} {
+	parent := page.ParentType
+	if page.ParentID != "" {
+		parent += ":" + page.ParentID
+	}
+	return map[string]interface{}{
+		"id":               page.ID,
+		"title":            pageTitle(page),
+		"parent":           parent,
+		"archived":         page.Archived,
+		"created_time":     page.CreatedTime.Format("2006-01-02T15:04:05Z"),
+		"last_edited_time": page.LastEditedTime.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func convertBlockToMap(b *Block) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           b.ID,
+		"type":         b.Type,
+		"text":         plainText(b.Content["rich_text"]),
+		"parent_id":    b.ParentID,
+		"has_children": b.HasChildren,
+		"created_time": b.CreatedTime.Format("2006-01-02T15:04:05Z"),
+	}
+}
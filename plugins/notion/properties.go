@@ -0,0 +1,273 @@
+// ABOUTME: Property value and rich text normalization for Notion pages
+// ABOUTME: Converts request-shaped values into the fuller shapes Notion returns
+
+package notion
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// titleSchema is the only property a page outside a database can have
+var titleSchema = &PropertySchema{ID: "title", Name: "title", Type: "title"}
+
+func defaultAnnotations() map[string]any {
+	return map[string]any{
+		"bold":          false,
+		"italic":        false,
+		"strikethrough": false,
+		"underline":     false,
+		"code":          false,
+		"color":         "default",
+	}
+}
+
+// normalizeRichText expands request rich text ([{"text": {"content": "..."}}])
+// into response rich text with annotations, plain_text, and href.
+func normalizeRichText(v any) ([]any, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("should be an array of rich text objects")
+	}
+
+	result := make([]any, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("rich text items should be objects")
+		}
+		if t, _ := obj["type"].(string); t != "" && t != "text" {
+			return nil, fmt.Errorf("rich text type %q is not supported", t)
+		}
+		text, ok := obj["text"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("rich text items should have a text object")
+		}
+		content, ok := text["content"].(string)
+		if !ok {
+			return nil, fmt.Errorf("text.content should be a string")
+		}
+
+		var link, href any
+		if l, ok := text["link"].(map[string]any); ok {
+			if url, _ := l["url"].(string); url != "" {
+				link = map[string]any{"url": url}
+				href = url
+			}
+		}
+
+		annotations := defaultAnnotations()
+		if a, ok := obj["annotations"].(map[string]any); ok {
+			for k, v := range a {
+				if _, known := annotations[k]; known {
+					annotations[k] = v
+				}
+			}
+		}
+
+		result = append(result, map[string]any{
+			"type":        "text",
+			"text":        map[string]any{"content": content, "link": link},
+			"annotations": annotations,
+			"plain_text":  content,
+			"href":        href,
+		})
+	}
+	return result, nil
+}
+
+// richText builds response rich text from a plain string
+func richText(content string) []any {
+	rt, _ := normalizeRichText([]any{map[string]any{"text": map[string]any{"content": content}}})
+	return rt
+}
+
+// plainText concatenates the plain_text of a rich text array
+func plainText(v any) string {
+	items, _ := v.([]any)
+	var sb strings.Builder
+	for _, item := range items {
+		if obj, ok := item.(map[string]any); ok {
+			s, _ := obj["plain_text"].(string)
+			sb.WriteString(s)
+		}
+	}
+	return sb.String()
+}
+
+// optionID derives a stable short ID for select options that aren't in the
+// schema yet. Notion creates such options on the fly; we don't persist them.
+func optionID(name string) string {
+	sum := sha1.Sum([]byte(name))
+	return base64.RawURLEncoding.EncodeToString(sum[:3])
+}
+
+func resolveOption(schema *PropertySchema, v any) (map[string]any, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("should be an object with a name or id")
+	}
+	name, _ := obj["name"].(string)
+	id, _ := obj["id"].(string)
+	for _, opt := range schema.Options {
+		if (name != "" && opt.Name == name) || (id != "" && opt.ID == id) {
+			return map[string]any{"id": opt.ID, "name": opt.Name, "color": opt.Color}, nil
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("option %q does not exist", id)
+	}
+	if strings.Contains(name, ",") {
+		return nil, fmt.Errorf("option names cannot contain commas")
+	}
+	return map[string]any{"id": optionID(name), "name": name, "color": "default"}, nil
+}
+
+// normalizePropertyValue validates a request property value against its
+// schema and returns the stored/response shape {"id", "type", <type>: value}.
+func normalizePropertyValue(schema *PropertySchema, raw any) (map[string]any, error) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s should be an object", schema.Name)
+	}
+	v, present := obj[schema.Type]
+	if !present {
+		return nil, fmt.Errorf("%s is expected to be %s", schema.Name, schema.Type)
+	}
+
+	var value any
+	switch schema.Type {
+	case "title", "rich_text":
+		rt, err := normalizeRichText(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s %w", schema.Name, schema.Type, err)
+		}
+		value = rt
+	case "select", "status":
+		if v == nil {
+			break
+		}
+		opt, err := resolveOption(schema, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s %w", schema.Name, schema.Type, err)
+		}
+		value = opt
+	case "multi_select":
+		items, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s.multi_select should be an array", schema.Name)
+		}
+		opts := make([]any, 0, len(items))
+		for _, item := range items {
+			opt, err := resolveOption(schema, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s.multi_select %w", schema.Name, err)
+			}
+			opts = append(opts, opt)
+		}
+		value = opts
+	case "number":
+		if _, ok := v.(float64); !ok && v != nil {
+			return nil, fmt.Errorf("%s.number should be a number", schema.Name)
+		}
+		value = v
+	case "checkbox":
+		if _, ok := v.(bool); !ok {
+			return nil, fmt.Errorf("%s.checkbox should be a boolean", schema.Name)
+		}
+		value = v
+	case "url", "email", "phone_number":
+		if _, ok := v.(string); !ok && v != nil {
+			return nil, fmt.Errorf("%s.%s should be a string", schema.Name, schema.Type)
+		}
+		value = v
+	case "date":
+		if v == nil {
+			break
+		}
+		d, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s.date should be an object", schema.Name)
+		}
+		start, _ := d["start"].(string)
+		if _, err := parseDate(start); err != nil {
+			return nil, fmt.Errorf("%s.date.start should be an ISO 8601 date", schema.Name)
+		}
+		value = map[string]any{"start": start, "end": d["end"], "time_zone": d["time_zone"]}
+	default:
+		return nil, fmt.Errorf("%s properties of type %s cannot be set", schema.Name, schema.Type)
+	}
+
+	return map[string]any{"id": schema.ID, "type": schema.Type, schema.Type: value}, nil
+}
+
+// applyProperties validates request properties against a schema and merges
+// them into existing page properties, returning the merged map.
+func applyProperties(schemas map[string]*PropertySchema, existing map[string]map[string]any, input map[string]any) (map[string]map[string]any, error) {
+	merged := make(map[string]map[string]any, len(existing)+len(input))
+	for k, v := range existing {
+		merged[k] = v
+	}
+
+	for name, raw := range input {
+		schema := lookupProperty(schemas, name)
+		if schema == nil {
+			return nil, fmt.Errorf("%s is not a property that exists", name)
+		}
+		value, err := normalizePropertyValue(schema, raw)
+		if err != nil {
+			return nil, err
+		}
+		merged[schema.Name] = value
+	}
+	return merged, nil
+}
+
+// lookupProperty finds a property schema by name or ID
+func lookupProperty(schemas map[string]*PropertySchema, nameOrID string) *PropertySchema {
+	if s, ok := schemas[nameOrID]; ok {
+		return s
+	}
+	for _, s := range schemas {
+		if s.ID == nameOrID {
+			return s
+		}
+	}
+	return nil
+}
+
+// emptyPropertyValue returns the response shape of an unset property
+func emptyPropertyValue(schema *PropertySchema) map[string]any {
+	var value any
+	switch schema.Type {
+	case "title", "rich_text", "multi_select":
+		value = []any{}
+	case "checkbox":
+		value = false
+	}
+	return map[string]any{"id": schema.ID, "type": schema.Type, schema.Type: value}
+}
+
+// pageSchema returns the property schemas that apply to a page
+func (s *NotionStore) pageSchema(p *Page) (map[string]*PropertySchema, error) {
+	if p.ParentType != parentDatabase {
+		return map[string]*PropertySchema{"title": titleSchema}, nil
+	}
+	db, err := s.GetDatabase(p.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	return db.Properties, nil
+}
+
+// pageTitle returns the plain text of a page's title property
+func pageTitle(p *Page) string {
+	for _, prop := range p.Properties {
+		if prop["type"] == "title" {
+			return plainText(prop["title"])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+// ABOUTME: Admin UI schema definitions for Notion plugin
+// ABOUTME: Defines Databases, Pages, and Blocks resources for schema-driven UI
+
+package notion
+
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func (p *NotionPlugin) Schema() core.PluginSchema {
+	return core.PluginSchema{
+		Resources: []core.ResourceSchema{
+			{
+				Name:        "Databases",
+				Slug:        "databases",
+				ListColumns: []string{"title", "properties", "created_time"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "title", Type: "string", Display: "Title", Required: true, Editable: false},
+					{Name: "properties", Type: "string", Display: "Properties", Required: false, Editable: false},
+					{Name: "created_time", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Pages",
+				Slug:        "pages",
+				ListColumns: []string{"title", "parent", "archived", "last_edited_time"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "title", Type: "string", Display: "Title", Required: false, Editable: false},
+					{Name: "parent", Type: "string", Display: "Parent", Required: true, Editable: false},
+					{Name: "archived", Type: "string", Display: "Archived", Required: false, Editable: false},
+					{Name: "created_time", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "last_edited_time", Type: "datetime", Display: "Last Edited", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Blocks",
+				Slug:        "blocks",
+				ListColumns: []string{"type", "text", "parent_id", "has_children"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "type", Type: "string", Display: "Type", Required: true, Editable: false},
+					{Name: "text", Type: "text", Display: "Text", Required: false, Editable: false},
+					{Name: "parent_id", Type: "string", Display: "Parent", Required: true, Editable: false},
+					{Name: "has_children", Type: "string", Display: "Has Children", Required: false, Editable: false},
+					{Name: "created_time", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+		},
+	}
+}
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *NotionPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "notion", Table: "notion_pages", Resource: "pages"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *NotionPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"notion": {"notion_blocks", "notion_pages", "notion_databases"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *NotionPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *NotionPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
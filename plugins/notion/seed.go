@@ -0,0 +1,162 @@
+// ABOUTME: Test data generation for Notion plugin
+// ABOUTME: Creates a workspace page, a Tasks database with pages, and page content blocks
+
+package notion
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// seedBotID is the integration user that owns seeded content
+var seedBotID = botUserID("secret_ish_seed")
+
+// tasksDatabaseProperties is the schema for the seeded Tasks database
+func tasksDatabaseProperties() map[string]*PropertySchema {
+	return map[string]*PropertySchema{
+		"Name": {ID: "title", Name: "Name", Type: "title"},
+		"Status": {ID: "s%3Ax", Name: "Status", Type: "select", Options: []SelectOption{
+			{ID: "1", Name: "Not started", Color: "gray"},
+			{ID: "2", Name: "In progress", Color: "blue"},
+			{ID: "3", Name: "Done", Color: "green"},
+		}},
+		"Priority": {ID: "p%7Bq", Name: "Priority", Type: "select", Options: []SelectOption{
+			{ID: "h", Name: "High", Color: "red"},
+			{ID: "m", Name: "Medium", Color: "yellow"},
+			{ID: "l", Name: "Low", Color: "default"},
+		}},
+		"Tags": {ID: "t%3Dg", Name: "Tags", Type: "multi_select", Options: []SelectOption{
+			{ID: "eng", Name: "engineering", Color: "purple"},
+			{ID: "des", Name: "design", Color: "pink"},
+			{ID: "ops", Name: "ops", Color: "orange"},
+		}},
+		"Estimate": {ID: "e%5Dn", Name: "Estimate", Type: "number"},
+		"Due":      {ID: "d%3Bu", Name: "Due", Type: "date"},
+		"Blocked":  {ID: "b%40k", Name: "Blocked", Type: "checkbox"},
+	}
+}
+
+// Seed creates test data for the Notion plugin
+func (p *NotionPlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
+	home := &Page{
+		ParentType: parentWorkspace,
+		Properties: map[string]map[string]any{
+			"title": {"id": "title", "type": "title", "title": richText("Team Home")},
+		},
+		Icon:      map[string]any{"type": "emoji", "emoji": "🏠"},
+		CreatedBy: seedBotID,
+	}
+	if err := p.store.CreatePage(home); err != nil {
+		return core.SeedData{}, fmt.Errorf("failed to create home page: %w", err)
+	}
+
+	tasks := &Database{
+		ParentType: parentPage,
+		ParentID:   home.ID,
+		Title:      "Tasks",
+		Properties: tasksDatabaseProperties(),
+		CreatedBy:  seedBotID,
+	}
+	if err := p.store.CreateDatabase(tasks); err != nil {
+		return core.SeedData{}, fmt.Errorf("failed to create tasks database: %w", err)
+	}
+
+	type task struct {
+		name, status, priority, due string
+		tags                        []any
+		estimate                    float64
+		blocked                     bool
+	}
+	taskRows := []task{
+		{"Write onboarding guide", "Done", "Medium", "2025-01-10", []any{"ops"}, 3, false},
+		{"Design new landing page", "In progress", "High", "2025-02-01", []any{"design"}, 5, false},
+		{"Migrate search to new index", "In progress", "High", "2025-01-24", []any{"engineering", "ops"}, 8, true},
+		{"Fix flaky login test", "Not started", "Medium", "2025-01-20", []any{"engineering"}, 2, false},
+		{"Quarterly roadmap review", "Not started", "Low", "", nil, 1, false},
+		{"Update brand colors", "Done", "Low", "2025-01-05", []any{"design"}, 1, false},
+	}
+	for _, row := range taskRows {
+		input := map[string]any{
+			"Name":     map[string]any{"title": []any{map[string]any{"text": map[string]any{"content": row.name}}}},
+			"Status":   map[string]any{"select": map[string]any{"name": row.status}},
+			"Priority": map[string]any{"select": map[string]any{"name": row.priority}},
+			"Estimate": map[string]any{"number": row.estimate},
+			"Blocked":  map[string]any{"checkbox": row.blocked},
+		}
+		if row.due != "" {
+			input["Due"] = map[string]any{"date": map[string]any{"start": row.due}}
+		}
+		if len(row.tags) > 0 {
+			var tags []any
+			for _, t := range row.tags {
+				tags = append(tags, map[string]any{"name": t})
+			}
+			input["Tags"] = map[string]any{"multi_select": tags}
+		}
+
+		props, err := applyProperties(tasks.Properties, nil, input)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("invalid seed task %q: %w", row.name, err)
+		}
+		page := &Page{ParentType: parentDatabase, ParentID: tasks.ID, Properties: props, CreatedBy: seedBotID}
+		if err := p.store.CreatePage(page); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create task %q: %w", row.name, err)
+		}
+	}
+
+	notes := &Page{
+		ParentType: parentPage,
+		ParentID:   home.ID,
+		Properties: map[string]map[string]any{
+			"title": {"id": "title", "type": "title", "title": richText("Weekly Sync Notes")},
+		},
+		CreatedBy: seedBotID,
+	}
+	if err := p.store.CreatePage(notes); err != nil {
+		return core.SeedData{}, fmt.Errorf("failed to create notes page: %w", err)
+	}
+
+	blocks, err := parseBlocks([]any{
+		map[string]any{"heading_1": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Weekly Sync"}}}}},
+		map[string]any{"paragraph": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Attendees: Harper, Alice, Bob"}}}}},
+		map[string]any{"heading_2": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Action items"}}}}},
+		map[string]any{"to_do": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Ship the landing page draft"}}}, "checked": true}},
+		map[string]any{"to_do": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Unblock the search migration"}}}}},
+		map[string]any{"toggle": map[string]any{
+			"rich_text": []any{map[string]any{"text": map[string]any{"content": "Open questions"}}},
+			"children": []any{
+				map[string]any{"bulleted_list_item": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Do we need a staging index?"}}}}},
+				map[string]any{"bulleted_list_item": map[string]any{"rich_text": []any{map[string]any{"text": map[string]any{"content": "Who owns the roadmap review?"}}}}},
+			},
+		}},
+	}, 0)
+	if err != nil {
+		return core.SeedData{}, fmt.Errorf("invalid seed blocks: %w", err)
+	}
+	setCreatedBy(blocks, seedBotID)
+	if err := p.store.AppendBlocks(parentPage, notes.ID, blocks); err != nil {
+		return core.SeedData{}, fmt.Errorf("failed to create note blocks: %w", err)
+	}
+	blockCount := countBlocks(blocks)
+
+	pageCount := len(taskRows) + 2
+	return core.SeedData{
+		Summary: fmt.Sprintf("Created 1 database, %d pages, %d blocks", pageCount, blockCount),
+		Records: map[string]int{
+			"databases": 1,
+			"pages":     pageCount,
+			"blocks":    blockCount,
+		},
+	}, nil
+}
+
+// countBlocks counts blocks including nested children
+func countBlocks(blocks []*Block) int {
+	n := len(blocks)
+	for _, b := range blocks {
+		n += countBlocks(b.Children)
+	}
+	return n
+}
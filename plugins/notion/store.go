@@ -0,0 +1,494 @@
+// ABOUTME: Database layer for Notion plugin
+// ABOUTME: Manages notion_databases, notion_pages, and notion_blocks tables
+
+package notion
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/google/uuid"
+)
+
+// Parent types as they appear in Notion's parent objects
+const (
+	parentDatabase  = "database_id"
+	parentPage      = "page_id"
+	parentBlock     = "block_id"
+	parentWorkspace = "workspace"
+)
+
+type NotionStore struct {
+	db *sql.DB
+}
+
+// auditIn records a mutation of a Notion resource in the shared audit log
+// through db, the transaction making the change so both commit together
+func auditIn(db core.Execer, resourceType, resourceID, action, actor string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "notion",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
+func NewNotionStore(db *sql.DB) (*NotionStore, error) {
+	return &NotionStore{db: db}, nil
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *NotionStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SelectOption is a choice in a select, multi_select, or status property
+type SelectOption struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// PropertySchema describes one column of a database
+type PropertySchema struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Type    string         `json:"type"`
+	Options []SelectOption `json:"options,omitempty"`
+}
+
+type Database struct {
+	ID             string
+	ParentType     string
+	ParentID       string
+	Title          string
+	Properties     map[string]*PropertySchema
+	Archived       bool
+	CreatedBy      string
+	CreatedTime    time.Time
+	LastEditedTime time.Time
+}
+
+// Page properties are stored in their response shape: {"id", "type", <type>: value}
+type Page struct {
+	ID             string
+	ParentType     string
+	ParentID       string
+	Properties     map[string]map[string]any
+	Icon           map[string]any
+	Cover          map[string]any
+	Archived       bool
+	CreatedBy      string
+	LastEditedBy   string
+	CreatedTime    time.Time
+	LastEditedTime time.Time
+}
+
+// Block content holds the type-specific object, e.g. {"rich_text": [...]} for a paragraph.
+// Children is only used when appending nested blocks and is never loaded back.
+type Block struct {
+	ID             string
+	ParentType     string
+	ParentID       string
+	Type           string
+	Content        map[string]any
+	Position       int
+	HasChildren    bool
+	Archived       bool
+	CreatedBy      string
+	LastEditedBy   string
+	CreatedTime    time.Time
+	LastEditedTime time.Time
+	Children       []*Block
+}
+
+// newID returns a dashed UUID, the format Notion uses for object IDs
+func newID() string {
+	return uuid.New().String()
+}
+
+// normalizeID accepts IDs with or without dashes and returns the dashed form.
+// Anything that isn't a UUID is returned unchanged so lookups simply miss.
+func normalizeID(id string) string {
+	if parsed, err := uuid.Parse(id); err == nil {
+		return parsed.String()
+	}
+	return id
+}
+
+// now returns the current time truncated to milliseconds, Notion's timestamp precision
+func now() time.Time {
+	return core.Now().UTC().Truncate(time.Millisecond)
+}
+
+func marshalOptional(v map[string]any) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalOptional(s sql.NullString) map[string]any {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var v map[string]any
+	if err := json.Unmarshal([]byte(s.String), &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// Databases
+
+func (s *NotionStore) CreateDatabase(d *Database) error {
+	if d.ID == "" {
+		d.ID = newID()
+	}
+	d.CreatedTime = now()
+	d.LastEditedTime = d.CreatedTime
+
+	props, err := json.Marshal(d.Properties)
+	if err != nil {
+		return err
+	}
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO notion_databases
+			(id, parent_type, parent_id, title, properties, archived, created_by, created_time, last_edited_time)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			d.ID, d.ParentType, d.ParentID, d.Title, string(props), d.Archived, d.CreatedBy, d.CreatedTime, d.LastEditedTime)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "database", d.ID, core.AuditCreate, d.CreatedBy, map[string]any{"parent_id": d.ParentID, "title": d.Title})
+	})
+}
+
+const databaseColumns = `id, parent_type, parent_id, title, properties, archived, created_by, created_time, last_edited_time`
+
+func scanDatabase(row scanner) (*Database, error) {
+	d := &Database{}
+	var parentID, createdBy sql.NullString
+	var props string
+	if err := row.Scan(&d.ID, &d.ParentType, &parentID, &d.Title, &props, &d.Archived, &createdBy, &d.CreatedTime, &d.LastEditedTime); err != nil {
+		return nil, err
+	}
+	d.ParentID = parentID.String
+	d.CreatedBy = createdBy.String
+	if err := json.Unmarshal([]byte(props), &d.Properties); err != nil {
+		return nil, fmt.Errorf("corrupt properties for database %s: %w", d.ID, err)
+	}
+	return d, nil
+}
+
+func (s *NotionStore) GetDatabase(id string) (*Database, error) {
+	return scanDatabase(s.db.QueryRow(`SELECT `+databaseColumns+` FROM notion_databases WHERE id = ?`, normalizeID(id)))
+}
+
+func (s *NotionStore) ListDatabases() ([]*Database, error) {
+	rows, err := s.db.Query(`SELECT ` + databaseColumns + ` FROM notion_databases ORDER BY created_time`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var databases []*Database
+	for rows.Next() {
+		d, err := scanDatabase(rows)
+		if err != nil {
+			return nil, err
+		}
+		databases = append(databases, d)
+	}
+	return databases, rows.Err()
+}
+
+// Pages
+
+func (s *NotionStore) CreatePage(p *Page) error {
+	if p.ID == "" {
+		p.ID = newID()
+	}
+	p.CreatedTime = now()
+	p.LastEditedTime = p.CreatedTime
+	if p.LastEditedBy == "" {
+		p.LastEditedBy = p.CreatedBy
+	}
+
+	props, err := json.Marshal(p.Properties)
+	if err != nil {
+		return err
+	}
+	icon, err := marshalOptional(p.Icon)
+	if err != nil {
+		return err
+	}
+	cover, err := marshalOptional(p.Cover)
+	if err != nil {
+		return err
+	}
+
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO notion_pages
+			(id, parent_type, parent_id, properties, icon, cover, archived, created_by, last_edited_by, created_time, last_edited_time)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			p.ID, p.ParentType, p.ParentID, string(props), icon, cover, p.Archived,
+			p.CreatedBy, p.LastEditedBy, p.CreatedTime, p.LastEditedTime)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "page", p.ID, core.AuditCreate, p.CreatedBy, map[string]any{"parent_id": p.ParentID, "properties": p.Properties})
+	})
+}
+
+// UpdatePage persists properties, icon, cover, and archived state and bumps last_edited_time
+func (s *NotionStore) UpdatePage(p *Page) error {
+	p.LastEditedTime = now()
+
+	props, err := json.Marshal(p.Properties)
+	if err != nil {
+		return err
+	}
+	icon, err := marshalOptional(p.Icon)
+	if err != nil {
+		return err
+	}
+	cover, err := marshalOptional(p.Cover)
+	if err != nil {
+		return err
+	}
+
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE notion_pages
+			SET properties = ?, icon = ?, cover = ?, archived = ?, last_edited_by = ?, last_edited_time = ?
+			WHERE id = ?`,
+			string(props), icon, cover, p.Archived, p.LastEditedBy, p.LastEditedTime, p.ID)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "page", p.ID, core.AuditUpdate, p.LastEditedBy, map[string]any{"properties": p.Properties, "archived": p.Archived})
+	})
+}
+
+const pageColumns = `id, parent_type, parent_id, properties, icon, cover, archived, created_by, last_edited_by, created_time, last_edited_time`
+
+func scanPage(row scanner) (*Page, error) {
+	p := &Page{}
+	var parentID, icon, cover, createdBy, lastEditedBy sql.NullString
+	var props string
+	if err := row.Scan(&p.ID, &p.ParentType, &parentID, &props, &icon, &cover, &p.Archived,
+		&createdBy, &lastEditedBy, &p.CreatedTime, &p.LastEditedTime); err != nil {
+		return nil, err
+	}
+	p.ParentID = parentID.String
+	p.CreatedBy = createdBy.String
+	p.LastEditedBy = lastEditedBy.String
+	p.Icon = unmarshalOptional(icon)
+	p.Cover = unmarshalOptional(cover)
+	if err := json.Unmarshal([]byte(props), &p.Properties); err != nil {
+		return nil, fmt.Errorf("corrupt properties for page %s: %w", p.ID, err)
+	}
+	return p, nil
+}
+
+func (s *NotionStore) queryPages(query string, args ...any) ([]*Page, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []*Page
+	for rows.Next() {
+		p, err := scanPage(rows)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+	return pages, rows.Err()
+}
+
+func (s *NotionStore) GetPage(id string) (*Page, error) {
+	return scanPage(s.db.QueryRow(`SELECT `+pageColumns+` FROM notion_pages WHERE id = ?`, normalizeID(id)))
+}
+
+// ListDatabasePages returns every non-archived page in a database; filtering,
+// sorting, and pagination happen in the query handler.
+func (s *NotionStore) ListDatabasePages(databaseID string) ([]*Page, error) {
+	return s.queryPages(`SELECT `+pageColumns+` FROM notion_pages
+		WHERE parent_type = ? AND parent_id = ? AND archived = 0
+		ORDER BY created_time DESC, rowid DESC`, parentDatabase, normalizeID(databaseID))
+}
+
+func (s *NotionStore) ListAllPages(limit, offset int) ([]*Page, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	return s.queryPages(`SELECT `+pageColumns+` FROM notion_pages
+		ORDER BY created_time DESC, rowid DESC LIMIT ? OFFSET ?`, limit, offset)
+}
+
+// Blocks
+
+// AppendBlocks adds blocks (and any nested Children) after the existing
+// children of parentID, marking a parent block as having children.
+func (s *NotionStore) AppendBlocks(parentType, parentID string, blocks []*Block) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := appendBlocksTx(tx, parentType, parentID, blocks); err != nil {
+		return err
+	}
+	if err := auditBlocks(tx, blocks); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// auditBlocks logs the creation of appended blocks and their nested children
+func auditBlocks(tx *sql.Tx, blocks []*Block) error {
+	for _, b := range blocks {
+		if err := auditIn(tx, "block", b.ID, core.AuditCreate, b.CreatedBy, map[string]any{"parent_id": b.ParentID, "type": b.Type}); err != nil {
+			return err
+		}
+		if err := auditBlocks(tx, b.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendBlocksTx(tx *sql.Tx, parentType, parentID string, blocks []*Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	var position int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(position), 0) FROM notion_blocks WHERE parent_id = ?`, parentID).Scan(&position); err != nil {
+		return err
+	}
+
+	ts := now()
+	for _, b := range blocks {
+		position++
+		if b.ID == "" {
+			b.ID = newID()
+		}
+		b.ParentType = parentType
+		b.ParentID = parentID
+		b.Position = position
+		b.HasChildren = len(b.Children) > 0
+		b.CreatedTime = ts
+		b.LastEditedTime = ts
+		if b.LastEditedBy == "" {
+			b.LastEditedBy = b.CreatedBy
+		}
+
+		content, err := json.Marshal(b.Content)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO notion_blocks
+			(id, parent_type, parent_id, type, content, position, has_children, archived, created_by, last_edited_by, created_time, last_edited_time)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			b.ID, b.ParentType, b.ParentID, b.Type, string(content), b.Position, b.HasChildren, b.Archived,
+			b.CreatedBy, b.LastEditedBy, b.CreatedTime, b.LastEditedTime); err != nil {
+			return err
+		}
+
+		if err := appendBlocksTx(tx, parentBlock, b.ID, b.Children); err != nil {
+			return err
+		}
+	}
+
+	if parentType == parentBlock {
+		if _, err := tx.Exec(`UPDATE notion_blocks SET has_children = 1, last_edited_time = ? WHERE id = ?`, ts, parentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const blockColumns = `id, parent_type, parent_id, type, content, position, has_children, archived, created_by, last_edited_by, created_time, last_edited_time`
+
+func scanBlock(row scanner) (*Block, error) {
+	b := &Block{}
+	var createdBy, lastEditedBy sql.NullString
+	var content string
+	if err := row.Scan(&b.ID, &b.ParentType, &b.ParentID, &b.Type, &content, &b.Position, &b.HasChildren, &b.Archived,
+		&createdBy, &lastEditedBy, &b.CreatedTime, &b.LastEditedTime); err != nil {
+		return nil, err
+	}
+	b.CreatedBy = createdBy.String
+	b.LastEditedBy = lastEditedBy.String
+	if err := json.Unmarshal([]byte(content), &b.Content); err != nil {
+		return nil, fmt.Errorf("corrupt content for block %s: %w", b.ID, err)
+	}
+	return b, nil
+}
+
+func (s *NotionStore) queryBlocks(query string, args ...any) ([]*Block, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*Block
+	for rows.Next() {
+		b, err := scanBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+func (s *NotionStore) GetBlock(id string) (*Block, error) {
+	return scanBlock(s.db.QueryRow(`SELECT `+blockColumns+` FROM notion_blocks WHERE id = ?`, normalizeID(id)))
+}
+
+// ListChildBlocks returns the non-archived children of a page or block in document order
+func (s *NotionStore) ListChildBlocks(parentID string) ([]*Block, error) {
+	return s.queryBlocks(`SELECT `+blockColumns+` FROM notion_blocks
+		WHERE parent_id = ? AND archived = 0 ORDER BY position`, normalizeID(parentID))
+}
+
+func (s *NotionStore) ListAllBlocks(limit, offset int) ([]*Block, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	return s.queryBlocks(`SELECT `+blockColumns+` FROM notion_blocks
+		ORDER BY created_time DESC, rowid DESC LIMIT ? OFFSET ?`, limit, offset)
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *NotionStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db, "notion_databases", "notion_pages", "notion_blocks")
+}
@@ -0,0 +1,96 @@
+// ABOUTME: OAuth consent screen for testing approval and denial flows.
+// ABOUTME: Shown by the authorize endpoint when ISH_OAUTH_CONSENT=true.
+
+package oauth
+
+import (
+	"html/template"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// consentEnabled reports whether authorize requests should show the consent
+// page instead of auto-approving
+func consentEnabled() bool {
+	return os.Getenv("ISH_OAUTH_CONSENT") == "true"
+}
+
+var consentTemplate = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Authorize {{.Plugin}}</title>
+<style>
+body { font-family: sans-serif; max-width: 28rem; margin: 4rem auto; }
+ul { padding-left: 1.25rem; }
+button { padding: 0.5rem 1.25rem; margin-right: 0.5rem; }
+</style>
+</head>
+<body>
+<h1>Authorize access</h1>
+<p>{{if .ClientID}}<strong>{{.ClientID}}</strong>{{else}}An application{{end}} wants to access your {{.Plugin}} account.</p>
+{{if .Scopes}}<p>It is requesting:</p>
+<ul>
+{{range .Scopes}}<li>{{.}}</li>
+{{end}}</ul>
+{{else}}<p>No scopes were requested.</p>
+{{end}}<form method="POST" action="{{.Action}}">
+<input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="scope" value="{{.Scope}}">
+<input type="hidden" name="client_id" value="{{.ClientID}}">
+<button type="submit" name="decision" value="approve">Approve</button>
+<button type="submit" name="decision" value="deny">Deny</button>
+</form>
+</body>
+</html>
+`))
+
+// renderConsent shows the requested scopes with approve and deny buttons,
+// which post back to the authorize endpoint
+func (p *OAuthPlugin) renderConsent(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	scope := query.Get("scope")
+	data := map[string]any{
+		"Plugin":      chi.URLParam(r, "plugin"),
+		"Action":      r.URL.Path,
+		"RedirectURI": query.Get("redirect_uri"),
+		"State":       query.Get("state"),
+		"Scope":       scope,
+		"Scopes":      strings.Fields(scope),
+		"ClientID":    query.Get("client_id"),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := consentTemplate.Execute(w, data); err != nil {
+		http.Error(w, "Failed to render consent page", http.StatusInternalServerError)
+	}
+}
+
+// handleConsent handles POST /oauth/{plugin}/authorize
+// Redirects with an authorization code when approved, or with the standard
+// access_denied error when denied
+func (p *OAuthPlugin) handleConsent(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI := r.FormValue("redirect_uri")
+	state := r.FormValue("state")
+
+	switch r.FormValue("decision") {
+	case "approve":
+		redirectWithParams(w, r, redirectURI, state, map[string]string{"code": generateRandomToken("code")})
+	case "deny":
+		redirectWithParams(w, r, redirectURI, state, map[string]string{
+			"error":             "access_denied",
+			"error_description": "The user denied the request",
+		})
+	default:
+		http.Error(w, "decision must be approve or deny", http.StatusBadRequest)
+	}
+}
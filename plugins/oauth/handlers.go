@@ -16,16 +16,25 @@ import (
 )
 
 // handleAuthorize handles GET /oauth/{plugin}/authorize
-// Auto-approves and redirects with authorization code
+// Auto-approves and redirects with authorization code, or shows a consent
+// page when ISH_OAUTH_CONSENT=true
 func (p *OAuthPlugin) handleAuthorize(w http.ResponseWriter, r *http.Request) {
 	_ = chi.URLParam(r, "plugin") // Plugin name is in URL but not needed for mock flow
 	redirectURI := r.URL.Query().Get("redirect_uri")
 	state := r.URL.Query().Get("state")
 
-	// Generate authorization code
-	code := generateRandomToken("code")
+	if consentEnabled() {
+		p.renderConsent(w, r)
+		return
+	}
 
-	// Build redirect URL with code
+	// Auto-approve: redirect immediately
+	redirectWithParams(w, r, redirectURI, state, map[string]string{"code": generateRandomToken("code")})
+}
+
+// redirectWithParams redirects to redirectURI with params and state added to
+// its query
+func redirectWithParams(w http.ResponseWriter, r *http.Request, redirectURI, state string, params map[string]string) {
 	u, err := url.Parse(redirectURI)
 	if err != nil {
 		http.Error(w, "Invalid redirect_uri", http.StatusBadRequest)
@@ -33,13 +42,14 @@ func (p *OAuthPlugin) handleAuthorize(w http.ResponseWriter, r *http.Request) {
 	}
 
 	q := u.Query()
-	q.Set("code", code)
+	for key, value := range params {
+		q.Set(key, value)
+	}
 	if state != "" {
 		q.Set("state", state)
 	}
 	u.RawQuery = q.Encode()
 
-	// Auto-approve: redirect immediately
 	http.Redirect(w, r, u.String(), http.StatusFound)
 }
 
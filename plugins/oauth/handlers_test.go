@@ -93,6 +93,80 @@ func TestHandleAuthorize(t *testing.T) {
 	}
 }
 
+func TestHandleAuthorizeConsent(t *testing.T) {
+	t.Setenv("ISH_OAUTH_CONSENT", "true")
+
+	s, cleanup := createTestStore(t)
+	defer cleanup()
+
+	p := &OAuthPlugin{store: s}
+	r := chi.NewRouter()
+	p.RegisterAuth(r)
+
+	// The authorize endpoint shows the requested scopes instead of redirecting
+	req := httptest.NewRequest("GET", "/oauth/google/authorize?redirect_uri="+url.QueryEscape("http://localhost:9001/callback")+
+		"&state=xyz&scope="+url.QueryEscape("email profile"), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"<li>email</li>", "<li>profile</li>", `name="state" value="xyz"`, `value="deny"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected consent page to contain %q", want)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		decision  string
+		wantParam string
+		wantValue string
+	}{
+		{name: "deny", decision: "deny", wantParam: "error", wantValue: "access_denied"},
+		{name: "approve", decision: "approve", wantParam: "code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{
+				"redirect_uri": {"http://localhost:9001/callback"},
+				"state":        {"xyz"},
+				"scope":        {"email profile"},
+				"decision":     {tt.decision},
+			}
+			req := httptest.NewRequest("POST", "/oauth/google/authorize", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusFound {
+				t.Fatalf("Status code = %d, want %d", w.Code, http.StatusFound)
+			}
+			redirectURL, err := url.Parse(w.Header().Get("Location"))
+			if err != nil {
+				t.Fatalf("Failed to parse redirect URL: %v", err)
+			}
+			if redirectURL.Host != "localhost:9001" || redirectURL.Path != "/callback" {
+				t.Errorf("Redirected to %s, want the redirect_uri", redirectURL)
+			}
+
+			got := redirectURL.Query().Get(tt.wantParam)
+			if got == "" || (tt.wantValue != "" && got != tt.wantValue) {
+				t.Errorf("%s = %q, want %q", tt.wantParam, got, tt.wantValue)
+			}
+			if state := redirectURL.Query().Get("state"); state != "xyz" {
+				t.Errorf("State = %q, want %q", state, "xyz")
+			}
+			if tt.decision == "deny" && redirectURL.Query().Get("code") != "" {
+				t.Error("Expected no code when access is denied")
+			}
+		})
+	}
+}
+
 func TestHandleToken(t *testing.T) {
 	s, cleanup := createTestStore(t)
 	defer cleanup()
@@ -0,0 +1,18 @@
+// ABOUTME: core.Inspector implementation for the OAuth plugin
+// ABOUTME: Tokens are never returned on their own by the API, so they use admin format
+
+package oauth
+
+import "fmt"
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *OAuthPlugin) Inspect(resource, id string) (interface{}, error) {
+	if resource != "tokens" {
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+	token, err := p.store.GetToken(id)
+	if err != nil {
+		return nil, err
+	}
+	return convertTokenToMap(token), nil
+}
@@ -39,6 +39,7 @@ func (p *OAuthPlugin) RegisterRoutes(r chi.Router) {
 func (p *OAuthPlugin) RegisterAuth(r chi.Router) {
 	// Register OAuth flow endpoints for all plugins
 	r.Get("/oauth/{plugin}/authorize", p.handleAuthorize)
+	r.Post("/oauth/{plugin}/authorize", p.handleConsent)
 	r.Post("/oauth/{plugin}/token", p.handleToken)
 	r.Post("/oauth/{plugin}/revoke", p.handleRevoke)
 }
@@ -47,6 +48,33 @@ func (p *OAuthPlugin) Schema() core.PluginSchema {
 	return getOAuthSchema()
 }
 
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *OAuthPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "oauth", Table: "oauth_tokens", Resource: "tokens"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *OAuthPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"oauth": {"oauth_tokens"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *OAuthPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *OAuthPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
+
 func (p *OAuthPlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
 	return core.SeedData{
 		Summary: "OAuth tokens are created on-demand during authentication",
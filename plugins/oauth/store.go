@@ -6,6 +6,8 @@ package oauth
 import (
 	"database/sql"
 	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 type OAuthStore struct {
@@ -13,26 +15,7 @@ type OAuthStore struct {
 }
 
 func NewOAuthStore(db *sql.DB) (*OAuthStore, error) {
-	store := &OAuthStore{db: db}
-	if err := store.initTables(); err != nil {
-		return nil, err
-	}
-	return store, nil
-}
-
-func (s *OAuthStore) initTables() error {
-	query := `CREATE TABLE IF NOT EXISTS oauth_tokens (
-		token TEXT PRIMARY KEY,
-		plugin_name TEXT NOT NULL,
-		user_id TEXT,
-		scopes TEXT,
-		expires_at TIMESTAMP,
-		refresh_token TEXT,
-		revoked BOOLEAN DEFAULT 0,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`
-	_, err := s.db.Exec(query)
-	return err
+	return &OAuthStore{db: db}, nil
 }
 
 // OAuthToken represents an OAuth token
@@ -134,3 +117,8 @@ func (s *OAuthStore) ListAllTokens(limit, offset int) ([]*OAuthToken, error) {
 
 	return tokens, nil
 }
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *OAuthStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db, "oauth_tokens")
+}
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/2389/ish/internal/migrations"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -17,6 +18,11 @@ func createTestStore(t *testing.T) (*OAuthStore, func()) {
 	if err != nil {
 		t.Fatalf("Failed to create test database: %v", err)
 	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
 
 	store, err := NewOAuthStore(db)
 	if err != nil {
@@ -0,0 +1,407 @@
+// ABOUTME: HTTP handlers for Salesforce REST API endpoints
+// ABOUTME: Implements sObject describe, record CRUD, and SOQL query with nextRecordsUrl batching
+
+package salesforce
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultBatchSize = 2000
+	minBatchSize     = 200
+
+	// queryLocatorPrefix is the key prefix Salesforce uses for query cursors
+	queryLocatorPrefix = "01g"
+)
+
+// orderedRecord renders a record with attributes first and fields in the
+// order they were selected, matching Salesforce's response layout
+type orderedRecord struct {
+	attributes map[string]string
+	fields     []string
+	values     Record
+}
+
+func (o orderedRecord) MarshalJSON() ([]byte, error) {
+	var buf strings.Builder
+	attrs, err := json.Marshal(o.attributes)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(`{"attributes":`)
+	buf.Write(attrs)
+	for _, name := range o.fields {
+		key, _ := json.Marshal(name)
+		val, err := json.Marshal(o.values[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return []byte(buf.String()), nil
+}
+
+func recordURL(version string, obj *sobject, id any) string {
+	return "/services/data/" + version + "/sobjects/" + obj.Name + "/" + id.(string)
+}
+
+func renderRecord(version string, obj *sobject, rec Record, fields []string) orderedRecord {
+	return orderedRecord{
+		attributes: map[string]string{
+			"type": obj.Name,
+			"url":  recordURL(version, obj, rec["Id"]),
+		},
+		fields: fields,
+		values: rec,
+	}
+}
+
+// sobjectFromRequest resolves the {sobject} URL parameter, writing a 404 for
+// unsupported objects
+func sobjectFromRequest(w http.ResponseWriter, r *http.Request) (*sobject, bool) {
+	obj, ok := lookupSObject(chi.URLParam(r, "sobject"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return nil, false
+	}
+	return obj, true
+}
+
+func sobjectSummary(version string, obj *sobject) map[string]any {
+	base := "/services/data/" + version + "/sobjects/" + obj.Name
+	return map[string]any{
+		"name":         obj.Name,
+		"label":        obj.Label,
+		"labelPlural":  obj.Plural,
+		"keyPrefix":    obj.KeyPrefix,
+		"custom":       false,
+		"createable":   true,
+		"updateable":   true,
+		"deletable":    true,
+		"queryable":    true,
+		"retrieveable": true,
+		"urls": map[string]string{
+			"sobject":     base,
+			"describe":    base + "/describe",
+			"rowTemplate": base + "/{ID}",
+		},
+	}
+}
+
+func (p *SalesforcePlugin) describeGlobal(w http.ResponseWriter, r *http.Request) {
+	version := chi.URLParam(r, "version")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"encoding":     "UTF-8",
+		"maxBatchSize": 200,
+		"sobjects": []map[string]any{
+			sobjectSummary(version, contactObject),
+			sobjectSummary(version, opportunityObject),
+		},
+	})
+}
+
+// sobjectInfo returns the object's basic metadata and its most recently
+// modified records
+func (p *SalesforcePlugin) sobjectInfo(w http.ResponseWriter, r *http.Request) {
+	obj, ok := sobjectFromRequest(w, r)
+	if !ok {
+		return
+	}
+	version := chi.URLParam(r, "version")
+
+	recent, err := p.store.Query(obj, []string{"Id", "Name"}, "", nil, "LastModifiedDate DESC", 10, 0)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	items := make([]orderedRecord, 0, len(recent))
+	for _, rec := range recent {
+		items = append(items, renderRecord(version, obj, rec, []string{"Id", "Name"}))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"objectDescribe": sobjectSummary(version, obj),
+		"recentItems":    items,
+	})
+}
+
+func (p *SalesforcePlugin) describeSObject(w http.ResponseWriter, r *http.Request) {
+	obj, ok := sobjectFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	fields := make([]map[string]any, 0, len(obj.Fields))
+	for _, f := range obj.Fields {
+		field := map[string]any{
+			"name":       f.Name,
+			"label":      f.Label,
+			"type":       f.Type,
+			"createable": f.Createable,
+			"updateable": f.Updateable,
+			"nillable":   !f.Required && f.Type != typeID && f.Type != typeBoolean && f.Type != typeDateTime,
+			"custom":     false,
+		}
+		if f.ReferenceTo != "" {
+			field["referenceTo"] = []string{f.ReferenceTo}
+		} else {
+			field["referenceTo"] = []string{}
+		}
+		if f.Name == "StageName" {
+			values := make([]map[string]any, 0, len(stageDefaults))
+			for _, stage := range stageOrder {
+				values = append(values, map[string]any{"label": stage, "value": stage, "active": true})
+			}
+			field["picklistValues"] = values
+		}
+		fields = append(fields, field)
+	}
+
+	resp := sobjectSummary(chi.URLParam(r, "version"), obj)
+	resp["fields"] = fields
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// stageOrder lists the opportunity stages in pipeline order
+var stageOrder = []string{
+	"Prospecting", "Qualification", "Needs Analysis", "Value Proposition",
+	"Id. Decision Makers", "Perception Analysis", "Proposal/Price Quote",
+	"Negotiation/Review", "Closed Won", "Closed Lost",
+}
+
+// decodeRecordBody reads a JSON object of field values from the request
+func decodeRecordBody(r *http.Request) (map[string]any, error) {
+	var input map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input == nil {
+		return nil, &fieldError{Code: "JSON_PARSER_ERROR", Message: "The request body is not a valid JSON object"}
+	}
+	return input, nil
+}
+
+func (p *SalesforcePlugin) createRecord(w http.ResponseWriter, r *http.Request) {
+	obj, ok := sobjectFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := decodeRecordBody(r)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	values, err := validateInput(obj, input, true)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	rec, err := p.store.Insert(obj, values, userFromContext(r.Context()))
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":      rec["Id"],
+		"success": true,
+		"errors":  []any{},
+	})
+}
+
+func (p *SalesforcePlugin) getRecord(w http.ResponseWriter, r *http.Request) {
+	obj, ok := sobjectFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	fields := obj.fieldNames()
+	if param := r.URL.Query().Get("fields"); param != "" {
+		fields = nil
+		for _, name := range strings.Split(param, ",") {
+			f, ok := obj.field(strings.TrimSpace(name))
+			if !ok {
+				writeError(w, http.StatusBadRequest, "INVALID_FIELD",
+					"No such column '"+strings.TrimSpace(name)+"' on entity '"+obj.Name+"'.")
+				return
+			}
+			fields = append(fields, f.Name)
+		}
+	}
+
+	rec, err := p.store.Get(obj, chi.URLParam(r, "id"))
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, renderRecord(chi.URLParam(r, "version"), obj, rec, fields))
+}
+
+func (p *SalesforcePlugin) updateRecord(w http.ResponseWriter, r *http.Request) {
+	obj, ok := sobjectFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	input, err := decodeRecordBody(r)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	values, err := validateInput(obj, input, false)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	_, err = p.store.Update(obj, chi.URLParam(r, "id"), values, userFromContext(r.Context()))
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *SalesforcePlugin) deleteRecord(w http.ResponseWriter, r *http.Request) {
+	obj, ok := sobjectFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	err := p.store.Delete(obj, chi.URLParam(r, "id"), userFromContext(r.Context()))
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+		return
+	}
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *SalesforcePlugin) query(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if strings.TrimSpace(q) == "" {
+		writeError(w, http.StatusBadRequest, "MALFORMED_QUERY", "A query string has to be specified")
+		return
+	}
+	p.runQuery(w, r, q, 0)
+}
+
+// queryMore returns the next batch for a nextRecordsUrl locator. Locators
+// encode the original SOQL and the position of the batch.
+func (p *SalesforcePlugin) queryMore(w http.ResponseWriter, r *http.Request) {
+	locator := chi.URLParam(r, "locator")
+	encoded, ok := strings.CutPrefix(locator, queryLocatorPrefix)
+	sep := strings.LastIndex(encoded, "-")
+	if !ok || sep < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_QUERY_LOCATOR", "invalid query locator")
+		return
+	}
+	q, err := base64.RawURLEncoding.DecodeString(encoded[:sep])
+	pos, perr := strconv.Atoi(encoded[sep+1:])
+	if err != nil || perr != nil || pos < 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_QUERY_LOCATOR", "invalid query locator")
+		return
+	}
+	p.runQuery(w, r, string(q), pos)
+}
+
+func (p *SalesforcePlugin) runQuery(w http.ResponseWriter, r *http.Request, q string, pos int) {
+	version := chi.URLParam(r, "version")
+
+	parsed, err := parseSOQL(q)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	obj := parsed.Object
+
+	if parsed.Count {
+		count, err := p.store.Count(obj, parsed.Where, parsed.Args)
+		if err != nil {
+			writeRequestError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"totalSize": count,
+			"done":      true,
+			"records":   []any{},
+		})
+		return
+	}
+
+	// Records need their Id for attributes.url even when it isn't selected
+	fields := parsed.Fields
+	columns := fields
+	if !slices.Contains(fields, "Id") {
+		columns = append([]string{"Id"}, fields...)
+	}
+
+	records, err := p.store.Query(obj, columns, parsed.Where, parsed.Args, parsed.OrderBy, parsed.Limit, parsed.Offset)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	batchSize := batchSizeFromRequest(r)
+	end := min(pos+batchSize, len(records))
+	start := min(pos, end)
+
+	rendered := make([]orderedRecord, 0, end-start)
+	for _, rec := range records[start:end] {
+		rendered = append(rendered, renderRecord(version, obj, rec, fields))
+	}
+
+	resp := map[string]any{
+		"totalSize": len(records),
+		"done":      end >= len(records),
+		"records":   rendered,
+	}
+	if end < len(records) {
+		locator := queryLocatorPrefix + base64.RawURLEncoding.EncodeToString([]byte(q)) + "-" + strconv.Itoa(end)
+		resp["nextRecordsUrl"] = "/services/data/" + version + "/query/" + locator
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// batchSizeFromRequest reads batchSize from the Sforce-Query-Options header,
+// clamped to the range Salesforce honours
+func batchSizeFromRequest(r *http.Request) int {
+	for _, opt := range strings.Split(r.Header.Get("Sforce-Query-Options"), ",") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(opt), "batchSize=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		return max(minBatchSize, min(n, defaultBatchSize))
+	}
+	return defaultBatchSize
+}
@@ -0,0 +1,339 @@
+// ABOUTME: Tests for Salesforce REST API handlers
+// ABOUTME: Covers OAuth token issue, sObject CRUD and validation, and SOQL queries with batching
+
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/migrations"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const apiBase = "/services/data/v58.0"
+
+func setupTestPlugin(t *testing.T) (*SalesforcePlugin, chi.Router) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	plugin := &SalesforcePlugin{}
+	if err := plugin.SetDB(db); err != nil {
+		t.Fatalf("Failed to initialize plugin: %v", err)
+	}
+	if _, err := plugin.Seed(context.Background(), "small"); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	r := chi.NewRouter()
+	plugin.RegisterAuth(r)
+	plugin.RegisterRoutes(r)
+	return plugin, r
+}
+
+func doRequest(t *testing.T, r chi.Router, method, target string, body any, wantStatus int) any {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("Failed to encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, target, &buf)
+	req.Header.Set("Authorization", "Bearer 00Dtest!token")
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != wantStatus {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, target, wantStatus, rr.Code, rr.Body.String())
+	}
+	if rr.Body.Len() == 0 {
+		return nil
+	}
+	var resp any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func query(t *testing.T, r chi.Router, soql string, wantStatus int) any {
+	t.Helper()
+	return doRequest(t, r, "GET", apiBase+"/query?q="+url.QueryEscape(soql), nil, wantStatus)
+}
+
+// errorCode returns the errorCode of the first entry in a Salesforce error array
+func errorCode(t *testing.T, resp any) string {
+	t.Helper()
+	errs, ok := resp.([]any)
+	if !ok || len(errs) == 0 {
+		t.Fatalf("Expected error array, got %v", resp)
+	}
+	return errs[0].(map[string]any)["errorCode"].(string)
+}
+
+func TestTokenEndpoint(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	form := url.Values{"grant_type": {"password"}, "client_id": {"ish"}, "username": {"admin@example.com"}, "password": {"pw"}}
+	req := httptest.NewRequest("POST", "/services/oauth2/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Host = "ish.local:9000"
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp["instance_url"] != "http://ish.local:9000" {
+		t.Errorf("Unexpected instance_url: %v", resp["instance_url"])
+	}
+	if resp["token_type"] != "Bearer" || !strings.HasPrefix(resp["access_token"].(string), orgID+"!") {
+		t.Errorf("Unexpected token response: %v", resp)
+	}
+
+	form.Set("grant_type", "jwt-magic")
+	req = httptest.NewRequest("POST", "/services/oauth2/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "unsupported_grant_type") {
+		t.Errorf("Expected unsupported_grant_type, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRequiresSession(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	req := httptest.NewRequest("GET", apiBase+"/sobjects/Contact", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized || !strings.Contains(rr.Body.String(), "INVALID_SESSION_ID") {
+		t.Errorf("Expected INVALID_SESSION_ID, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	resp := doRequest(t, r, "GET", "/services/data/latest/sobjects/Contact", nil, http.StatusNotFound)
+	if errorCode(t, resp) != "NOT_FOUND" {
+		t.Errorf("Expected NOT_FOUND for bad version, got %v", resp)
+	}
+	doRequest(t, r, "GET", apiBase+"/sobjects/Account", nil, http.StatusNotFound)
+}
+
+func TestContactCRUD(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	created := doRequest(t, r, "POST", apiBase+"/sobjects/Contact", map[string]any{
+		"FirstName": "Barbara",
+		"LastName":  "Liskov",
+		"email":     "barbara@substitution.example",
+	}, http.StatusCreated).(map[string]any)
+	id := created["id"].(string)
+	if len(id) != 18 || !strings.HasPrefix(id, "003") || created["success"] != true {
+		t.Fatalf("Unexpected create response: %v", created)
+	}
+
+	contact := doRequest(t, r, "GET", apiBase+"/sobjects/Contact/"+id, nil, http.StatusOK).(map[string]any)
+	if contact["Name"] != "Barbara Liskov" || contact["Email"] != "barbara@substitution.example" {
+		t.Errorf("Unexpected contact: %v", contact)
+	}
+	attrs := contact["attributes"].(map[string]any)
+	if attrs["type"] != "Contact" || attrs["url"] != apiBase+"/sobjects/Contact/"+id {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+
+	// 15-character IDs resolve to the same record
+	doRequest(t, r, "GET", apiBase+"/sobjects/Contact/"+id[:15], nil, http.StatusOK)
+
+	doRequest(t, r, "PATCH", apiBase+"/sobjects/Contact/"+id, map[string]any{
+		"Title":     "Professor",
+		"FirstName": nil,
+	}, http.StatusNoContent)
+
+	contact = doRequest(t, r, "GET", apiBase+"/sobjects/Contact/"+id+"?fields=Name,Title", nil, http.StatusOK).(map[string]any)
+	if contact["Name"] != "Liskov" || contact["Title"] != "Professor" {
+		t.Errorf("Unexpected updated contact: %v", contact)
+	}
+	if _, ok := contact["Email"]; ok {
+		t.Errorf("Expected only requested fields, got %v", contact)
+	}
+
+	doRequest(t, r, "DELETE", apiBase+"/sobjects/Contact/"+id, nil, http.StatusNoContent)
+	doRequest(t, r, "GET", apiBase+"/sobjects/Contact/"+id, nil, http.StatusNotFound)
+}
+
+func TestCreateValidation(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	tests := []struct {
+		object string
+		body   map[string]any
+		code   string
+	}{
+		{"Contact", map[string]any{"FirstName": "Nobody"}, "REQUIRED_FIELD_MISSING"},
+		{"Contact", map[string]any{"LastName": "X", "Shoe_Size__c": 9}, "INVALID_FIELD"},
+		{"Contact", map[string]any{"LastName": "X", "Name": "Override"}, "INVALID_FIELD_FOR_INSERT_UPDATE"},
+		{"Contact", map[string]any{"LastName": "X", "Email": "not-an-email"}, "INVALID_EMAIL_ADDRESS"},
+		{"Opportunity", map[string]any{"Name": "Deal", "StageName": "Prospecting"}, "REQUIRED_FIELD_MISSING"},
+		{"Opportunity", map[string]any{"Name": "Deal", "StageName": "Prospecting", "CloseDate": "next week"}, "INVALID_TYPE_ON_FIELD_IN_RECORD"},
+		{"Opportunity", map[string]any{"Name": "Deal", "StageName": "Prospecting", "CloseDate": "2025-01-01", "ContactId": "003000000000000AAA"}, "INVALID_CROSS_REFERENCE_KEY"},
+	}
+	for _, tt := range tests {
+		resp := doRequest(t, r, "POST", apiBase+"/sobjects/"+tt.object, tt.body, http.StatusBadRequest)
+		if got := errorCode(t, resp); got != tt.code {
+			t.Errorf("%s %v: expected %s, got %s", tt.object, tt.body, tt.code, got)
+		}
+	}
+}
+
+func TestOpportunityStageDerivedFields(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	created := doRequest(t, r, "POST", apiBase+"/sobjects/Opportunity", map[string]any{
+		"Name":      "Expansion",
+		"StageName": "Prospecting",
+		"CloseDate": "2025-09-30",
+		"Amount":    5000,
+	}, http.StatusCreated).(map[string]any)
+	id := created["id"].(string)
+
+	opp := doRequest(t, r, "GET", apiBase+"/sobjects/Opportunity/"+id, nil, http.StatusOK).(map[string]any)
+	if opp["Probability"] != float64(10) || opp["IsClosed"] != false || opp["Amount"] != float64(5000) {
+		t.Errorf("Unexpected new opportunity: %v", opp)
+	}
+
+	doRequest(t, r, "PATCH", apiBase+"/sobjects/Opportunity/"+id, map[string]any{"StageName": "Closed Won"}, http.StatusNoContent)
+	opp = doRequest(t, r, "GET", apiBase+"/sobjects/Opportunity/"+id, nil, http.StatusOK).(map[string]any)
+	if opp["Probability"] != float64(100) || opp["IsClosed"] != true || opp["IsWon"] != true {
+		t.Errorf("Expected closed won opportunity, got %v", opp)
+	}
+}
+
+func TestSOQLQuery(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	resp := query(t, r, "SELECT Id, Name FROM Contact", http.StatusOK).(map[string]any)
+	if resp["totalSize"] != float64(len(seedContacts)) || resp["done"] != true {
+		t.Fatalf("Unexpected query response: %v", resp)
+	}
+	first := resp["records"].([]any)[0].(map[string]any)
+	if first["attributes"].(map[string]any)["type"] != "Contact" || first["Name"] == nil {
+		t.Errorf("Unexpected record: %v", first)
+	}
+
+	tests := []struct {
+		soql  string
+		names []string
+	}{
+		{"SELECT Name FROM Contact WHERE LastName = 'hopper'", []string{"Grace Hopper"}},
+		{"select name from contact where Email like '%enigma%'", []string{"Alan Turing"}},
+		{"SELECT Name FROM Contact WHERE MailingState IN ('CA', 'OR') ORDER BY LastName DESC", []string{"Linus Torvalds", "Ada Lovelace"}},
+		{"SELECT Name FROM Contact WHERE FirstName = null", []string{"Hamilton"}},
+		{"SELECT Name FROM Opportunity WHERE Amount > 100000 ORDER BY Amount", []string{"Analytical Engines - Platform Renewal", "Orbital Math - Enterprise Licenses"}},
+		{"SELECT Name FROM Opportunity WHERE IsClosed = true AND NOT (IsWon = false)", []string{"Orbital Math - Enterprise Licenses"}},
+		{"SELECT Name FROM Opportunity WHERE CloseDate < 2025-02-01 OR StageName = 'Qualification' ORDER BY CloseDate", []string{"Orbital Math - Enterprise Licenses", "Enigma Solutions - Pilot"}},
+		{"SELECT Name FROM Opportunity WHERE Amount = null", []string{"Apollo Flight - Guidance Systems"}},
+		{"SELECT Name FROM Contact ORDER BY LastName LIMIT 2 OFFSET 1", []string{"Grace Hopper", "Katherine Johnson"}},
+	}
+	for _, tt := range tests {
+		resp := query(t, r, tt.soql, http.StatusOK).(map[string]any)
+		var names []string
+		for _, rec := range resp["records"].([]any) {
+			names = append(names, rec.(map[string]any)["Name"].(string))
+		}
+		if strings.Join(names, "|") != strings.Join(tt.names, "|") {
+			t.Errorf("%s: expected %v, got %v", tt.soql, tt.names, names)
+		}
+	}
+
+	resp = query(t, r, "SELECT COUNT() FROM Opportunity WHERE StageName != 'Closed Lost'", http.StatusOK).(map[string]any)
+	if resp["totalSize"] != float64(len(seedOpportunities)-1) || len(resp["records"].([]any)) != 0 {
+		t.Errorf("Unexpected COUNT() response: %v", resp)
+	}
+}
+
+func TestSOQLErrors(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	tests := []struct {
+		soql string
+		code string
+	}{
+		{"SELECT Id FROM Lead", "INVALID_TYPE"},
+		{"SELECT Shoe_Size__c FROM Contact", "INVALID_FIELD"},
+		{"SELECT Id FROM Opportunity WHERE Amount = '100'", "INVALID_QUERY_FILTER_OPERATOR"},
+		{"SELECT Id FROM Contact WHERE", "MALFORMED_QUERY"},
+		{"SELECT Id, Id FROM Contact", "MALFORMED_QUERY"},
+		{"SELECT Id FROM Contact OFFSET 5000", "NUMBER_OUTSIDE_VALID_RANGE"},
+	}
+	for _, tt := range tests {
+		resp := query(t, r, tt.soql, http.StatusBadRequest)
+		if got := errorCode(t, resp); got != tt.code {
+			t.Errorf("%s: expected %s, got %s", tt.soql, tt.code, got)
+		}
+	}
+
+	resp := doRequest(t, r, "GET", apiBase+"/query", nil, http.StatusBadRequest)
+	if errorCode(t, resp) != "MALFORMED_QUERY" {
+		t.Errorf("Expected MALFORMED_QUERY for missing q, got %v", resp)
+	}
+}
+
+func TestQueryMoreBatches(t *testing.T) {
+	plugin, r := setupTestPlugin(t)
+
+	for i := 0; i < 250; i++ {
+		values, _ := validateInput(contactObject, map[string]any{"LastName": "Bulk"}, true)
+		if _, err := plugin.store.Insert(contactObject, values, ""); err != nil {
+			t.Fatalf("Failed to insert contact: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", apiBase+"/query?q="+url.QueryEscape("SELECT Id FROM Contact WHERE LastName = 'Bulk'"), nil)
+	req.Header.Set("Authorization", "Bearer 00Dtest!token")
+	req.Header.Set("Sforce-Query-Options", "batchSize=200")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	var resp map[string]any
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp["totalSize"] != float64(250) || resp["done"] != false || len(resp["records"].([]any)) != 200 {
+		t.Fatalf("Unexpected first batch: totalSize=%v done=%v", resp["totalSize"], resp["done"])
+	}
+	next, _ := resp["nextRecordsUrl"].(string)
+	if !strings.HasPrefix(next, apiBase+"/query/01g") {
+		t.Fatalf("Unexpected nextRecordsUrl: %q", next)
+	}
+
+	more := doRequest(t, r, "GET", next, nil, http.StatusOK).(map[string]any)
+	if more["done"] != true || len(more["records"].([]any)) != 50 {
+		t.Errorf("Unexpected second batch: done=%v records=%d", more["done"], len(more["records"].([]any)))
+	}
+	if _, ok := more["nextRecordsUrl"]; ok {
+		t.Errorf("Expected no nextRecordsUrl on the final batch")
+	}
+}
+
+func TestIDChecksum(t *testing.T) {
+	// Known pair from Salesforce documentation
+	if got := normalizeRecordID("001A0000006Vm9r"); got != "001A0000006Vm9rIAC" {
+		t.Errorf("Expected 001A0000006Vm9rIAC, got %s", got)
+	}
+}
@@ -0,0 +1,20 @@
+// ABOUTME: core.Inspector implementation for the Salesforce plugin
+// ABOUTME: Renders records as the sObject GET endpoint does
+
+package salesforce
+
+// inspectVersion is the API version inspected records link to
+const inspectVersion = "v58.0"
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *SalesforcePlugin) Inspect(resource, id string) (interface{}, error) {
+	obj, err := resourceObject(resource)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := p.store.Get(obj, id)
+	if err != nil {
+		return nil, err
+	}
+	return renderRecord(inspectVersion, obj, rec, obj.fieldNames()), nil
+}
@@ -0,0 +1,337 @@
+// ABOUTME: sObject metadata for the Salesforce plugin
+// ABOUTME: Field definitions, record ID generation, and request value validation
+
+package salesforce
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Field types, named after Salesforce's describe types
+const (
+	typeID       = "id"
+	typeString   = "string"
+	typeEmail    = "email"
+	typePhone    = "phone"
+	typeTextArea = "textarea"
+	typePicklist = "picklist"
+	typeCurrency = "currency"
+	typePercent  = "percent"
+	typeDate     = "date"
+	typeDateTime = "datetime"
+	typeBoolean  = "boolean"
+	typeRef      = "reference"
+)
+
+// dateTimeFormat is how Salesforce renders datetimes in REST responses
+const dateTimeFormat = "2006-01-02T15:04:05.000-0700"
+
+type fieldDef struct {
+	Name       string
+	Label      string
+	Type       string
+	Createable bool
+	Updateable bool
+	Required   bool
+	// ReferenceTo names the sObject a reference field points at
+	ReferenceTo string
+}
+
+type sobject struct {
+	Name      string
+	Label     string
+	Plural    string
+	KeyPrefix string
+	Table     string
+	Fields    []fieldDef
+}
+
+// field looks up a field by name, case-insensitively like Salesforce does
+func (o *sobject) field(name string) (*fieldDef, bool) {
+	for i := range o.Fields {
+		if strings.EqualFold(o.Fields[i].Name, name) {
+			return &o.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+func (o *sobject) fieldNames() []string {
+	names := make([]string, len(o.Fields))
+	for i, f := range o.Fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// auditFields are system fields present on every sObject
+var auditFields = []fieldDef{
+	{Name: "CreatedDate", Label: "Created Date", Type: typeDateTime},
+	{Name: "LastModifiedDate", Label: "Last Modified Date", Type: typeDateTime},
+	{Name: "SystemModstamp", Label: "System Modstamp", Type: typeDateTime},
+}
+
+var contactObject = &sobject{
+	Name:      "Contact",
+	Label:     "Contact",
+	Plural:    "Contacts",
+	KeyPrefix: "003",
+	Table:     "sf_contacts",
+	Fields: append([]fieldDef{
+		{Name: "Id", Label: "Contact ID", Type: typeID},
+		{Name: "FirstName", Label: "First Name", Type: typeString, Createable: true, Updateable: true},
+		{Name: "LastName", Label: "Last Name", Type: typeString, Createable: true, Updateable: true, Required: true},
+		{Name: "Name", Label: "Full Name", Type: typeString},
+		{Name: "Email", Label: "Email", Type: typeEmail, Createable: true, Updateable: true},
+		{Name: "Phone", Label: "Business Phone", Type: typePhone, Createable: true, Updateable: true},
+		{Name: "MobilePhone", Label: "Mobile Phone", Type: typePhone, Createable: true, Updateable: true},
+		{Name: "Title", Label: "Title", Type: typeString, Createable: true, Updateable: true},
+		{Name: "Department", Label: "Department", Type: typeString, Createable: true, Updateable: true},
+		{Name: "MailingStreet", Label: "Mailing Street", Type: typeTextArea, Createable: true, Updateable: true},
+		{Name: "MailingCity", Label: "Mailing City", Type: typeString, Createable: true, Updateable: true},
+		{Name: "MailingState", Label: "Mailing State/Province", Type: typeString, Createable: true, Updateable: true},
+		{Name: "MailingPostalCode", Label: "Mailing Zip/Postal Code", Type: typeString, Createable: true, Updateable: true},
+		{Name: "MailingCountry", Label: "Mailing Country", Type: typeString, Createable: true, Updateable: true},
+		{Name: "LeadSource", Label: "Lead Source", Type: typePicklist, Createable: true, Updateable: true},
+		{Name: "Description", Label: "Contact Description", Type: typeTextArea, Createable: true, Updateable: true},
+	}, auditFields...),
+}
+
+var opportunityObject = &sobject{
+	Name:      "Opportunity",
+	Label:     "Opportunity",
+	Plural:    "Opportunities",
+	KeyPrefix: "006",
+	Table:     "sf_opportunities",
+	Fields: append([]fieldDef{
+		{Name: "Id", Label: "Opportunity ID", Type: typeID},
+		{Name: "Name", Label: "Name", Type: typeString, Createable: true, Updateable: true, Required: true},
+		{Name: "StageName", Label: "Stage", Type: typePicklist, Createable: true, Updateable: true, Required: true},
+		{Name: "Amount", Label: "Amount", Type: typeCurrency, Createable: true, Updateable: true},
+		{Name: "CloseDate", Label: "Close Date", Type: typeDate, Createable: true, Updateable: true, Required: true},
+		{Name: "Probability", Label: "Probability (%)", Type: typePercent, Createable: true, Updateable: true},
+		{Name: "Type", Label: "Opportunity Type", Type: typePicklist, Createable: true, Updateable: true},
+		{Name: "LeadSource", Label: "Lead Source", Type: typePicklist, Createable: true, Updateable: true},
+		{Name: "NextStep", Label: "Next Step", Type: typeString, Createable: true, Updateable: true},
+		{Name: "Description", Label: "Description", Type: typeTextArea, Createable: true, Updateable: true},
+		{Name: "ContactId", Label: "Contact ID", Type: typeRef, Createable: true, Updateable: true, ReferenceTo: "Contact"},
+		{Name: "IsClosed", Label: "Closed", Type: typeBoolean},
+		{Name: "IsWon", Label: "Won", Type: typeBoolean},
+	}, auditFields...),
+}
+
+// sobjects lists the supported sObjects by API name
+var sobjects = map[string]*sobject{
+	"contact":     contactObject,
+	"opportunity": opportunityObject,
+}
+
+// lookupSObject finds an sObject by API name, case-insensitively
+func lookupSObject(name string) (*sobject, bool) {
+	o, ok := sobjects[strings.ToLower(name)]
+	return o, ok
+}
+
+// stageDefaults maps the standard opportunity stages to their default
+// probability and closed/won state
+var stageDefaults = map[string]struct {
+	Probability float64
+	Closed, Won bool
+}{
+	"Prospecting":          {10, false, false},
+	"Qualification":        {10, false, false},
+	"Needs Analysis":       {20, false, false},
+	"Value Proposition":    {50, false, false},
+	"Id. Decision Makers":  {60, false, false},
+	"Perception Analysis":  {70, false, false},
+	"Proposal/Price Quote": {75, false, false},
+	"Negotiation/Review":   {90, false, false},
+	"Closed Won":           {100, true, true},
+	"Closed Lost":          {0, true, false},
+}
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// newRecordID generates an 18-character Salesforce ID with the object's key
+// prefix and the case-insensitive checksum suffix.
+func newRecordID(keyPrefix string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(keyPrefix)
+	// Salesforce IDs carry a pod/instance segment after the prefix
+	sb.WriteString("5g00")
+	for sb.Len() < 15 {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(base62))))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(base62[n.Int64()])
+	}
+	id := sb.String()
+	return id + idChecksum(id), nil
+}
+
+// idChecksum computes the 3-character suffix that turns a 15-character
+// case-sensitive ID into an 18-character case-insensitive one.
+func idChecksum(id15 string) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ012345"
+	var suffix [3]byte
+	for chunk := 0; chunk < 3; chunk++ {
+		bits := 0
+		for i := 0; i < 5; i++ {
+			c := id15[chunk*5+i]
+			if c >= 'A' && c <= 'Z' {
+				bits |= 1 << i
+			}
+		}
+		suffix[chunk] = alphabet[bits]
+	}
+	return string(suffix[:])
+}
+
+// normalizeRecordID converts a 15-character ID to its 18-character form
+func normalizeRecordID(id string) string {
+	if len(id) == 15 {
+		return id + idChecksum(id)
+	}
+	return id
+}
+
+// fieldError is a request problem tied to specific fields, rendered as a
+// Salesforce error with an errorCode and optional field list
+type fieldError struct {
+	Code    string
+	Message string
+	Fields  []string
+}
+
+func (e *fieldError) Error() string {
+	return e.Message
+}
+
+// coerceValue validates a JSON request value for a field and converts it to
+// the form stored in SQLite. nil clears the field.
+func coerceValue(f *fieldDef, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	invalid := func(want string) error {
+		return &fieldError{
+			Code:    "JSON_PARSER_ERROR",
+			Message: fmt.Sprintf("Cannot deserialize instance of %s from %T value for field %s", want, v, f.Name),
+			Fields:  []string{f.Name},
+		}
+	}
+
+	switch f.Type {
+	case typeCurrency, typePercent:
+		n, ok := v.(float64)
+		if !ok {
+			return nil, invalid("double")
+		}
+		return n, nil
+	case typeBoolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, invalid("boolean")
+		}
+		return b, nil
+	case typeDate:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid("date")
+		}
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return nil, &fieldError{
+				Code:    "INVALID_TYPE_ON_FIELD_IN_RECORD",
+				Message: fmt.Sprintf("%s: value not of required type: %s", f.Label, s),
+				Fields:  []string{f.Name},
+			}
+		}
+		return s, nil
+	case typeEmail:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid("string")
+		}
+		if s != "" && !strings.Contains(s, "@") {
+			return nil, &fieldError{
+				Code:    "INVALID_EMAIL_ADDRESS",
+				Message: fmt.Sprintf("%s: invalid email address: %s", f.Label, s),
+				Fields:  []string{f.Name},
+			}
+		}
+		return s, nil
+	case typeRef:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid("reference")
+		}
+		return normalizeRecordID(s), nil
+	default:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid("string")
+		}
+		return s, nil
+	}
+}
+
+// validateInput checks request fields against the sObject definition and
+// returns the coerced values keyed by canonical field name
+func validateInput(o *sobject, input map[string]any, creating bool) (map[string]any, error) {
+	values := make(map[string]any, len(input))
+	var readOnly []string
+	for name, raw := range input {
+		if name == "attributes" {
+			continue
+		}
+		f, ok := o.field(name)
+		if !ok {
+			return nil, &fieldError{
+				Code:    "INVALID_FIELD",
+				Message: fmt.Sprintf("No such column '%s' on sobject of type %s", name, o.Name),
+			}
+		}
+		if (creating && !f.Createable) || (!creating && !f.Updateable) {
+			readOnly = append(readOnly, f.Name)
+			continue
+		}
+		v, err := coerceValue(f, raw)
+		if err != nil {
+			return nil, err
+		}
+		values[f.Name] = v
+	}
+
+	if len(readOnly) > 0 {
+		return nil, &fieldError{
+			Code:    "INVALID_FIELD_FOR_INSERT_UPDATE",
+			Message: fmt.Sprintf("Unable to create/update fields: %s. Please check the security settings of this field and verify that it is read/write for your profile or permission set.", strings.Join(readOnly, ", ")),
+			Fields:  readOnly,
+		}
+	}
+
+	var missing []string
+	for _, f := range o.Fields {
+		if !f.Required {
+			continue
+		}
+		v, present := values[f.Name]
+		if (creating && !present) || (present && (v == nil || v == "")) {
+			missing = append(missing, f.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &fieldError{
+			Code:    "REQUIRED_FIELD_MISSING",
+			Message: fmt.Sprintf("Required fields are missing: [%s]", strings.Join(missing, ", ")),
+			Fields:  missing,
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,304 @@
+// ABOUTME: Salesforce REST API plugin for ISH
+// ABOUTME: Simulates Contact and Opportunity sObjects, SOQL queries, and the OAuth token endpoint
+
+package salesforce
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// orgID is the organization ID baked into access tokens and identity URLs
+const orgID = "00D5g00000ISHMKAA1"
+
+// userID is the Salesforce user every token authenticates as
+const userID = "0055g00000ISHUSAA2"
+
+// contextKey is a type for context keys to avoid collisions
+type contextKey string
+
+const userKey contextKey = "salesforce_user"
+
+// apiVersionPattern matches the version segment of /services/data paths
+var apiVersionPattern = regexp.MustCompile(`^v\d+\.\d$`)
+
+func init() {
+	core.Register(&SalesforcePlugin{})
+}
+
+type SalesforcePlugin struct {
+	store *SalesforceStore
+}
+
+func (p *SalesforcePlugin) Name() string {
+	return "salesforce"
+}
+
+func (p *SalesforcePlugin) Health() core.HealthStatus {
+	return core.HealthStatus{
+		Status:  "healthy",
+		Message: "Salesforce plugin operational",
+	}
+}
+
+func (p *SalesforcePlugin) RegisterRoutes(r chi.Router) {
+	r.Route("/services/data/{version}", func(r chi.Router) {
+		r.Get("/sobjects", p.requireAuth(p.describeGlobal))
+		r.Get("/sobjects/{sobject}", p.requireAuth(p.sobjectInfo))
+		r.Post("/sobjects/{sobject}", p.requireAuth(p.createRecord))
+		r.Get("/sobjects/{sobject}/describe", p.requireAuth(p.describeSObject))
+		r.Get("/sobjects/{sobject}/{id}", p.requireAuth(p.getRecord))
+		r.Patch("/sobjects/{sobject}/{id}", p.requireAuth(p.updateRecord))
+		r.Delete("/sobjects/{sobject}/{id}", p.requireAuth(p.deleteRecord))
+
+		r.Get("/query", p.requireAuth(p.query))
+		r.Get("/query/{locator}", p.requireAuth(p.queryMore))
+	})
+}
+
+func (p *SalesforcePlugin) RegisterAuth(r chi.Router) {
+	r.Post("/services/oauth2/token", p.issueToken)
+}
+
+// issueToken implements the OAuth 2.0 token endpoint. Every supported grant
+// succeeds and returns the instance_url clients use as their API base.
+func (p *SalesforcePlugin) issueToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, "invalid_request", "invalid request body")
+		return
+	}
+
+	grantType := r.FormValue("grant_type")
+	switch grantType {
+	case "password", "client_credentials", "authorization_code", "refresh_token":
+	case "":
+		writeOAuthError(w, "invalid_request", "grant type not specified")
+		return
+	default:
+		writeOAuthError(w, "unsupported_grant_type", "grant type not supported")
+		return
+	}
+
+	token, err := newAccessToken()
+	if err != nil {
+		log.Printf("Salesforce: Failed to generate token: %v", err)
+		writeOAuthError(w, "server_error", "failed to generate token")
+		return
+	}
+
+	instanceURL := baseURL(r)
+	resp := map[string]any{
+		"access_token": token,
+		"instance_url": instanceURL,
+		"id":           instanceURL + "/id/" + orgID + "/" + userID,
+		"token_type":   "Bearer",
+		"issued_at":    strconv.FormatInt(core.Now().UnixMilli(), 10),
+		"signature":    token[len(orgID)+1:][:32],
+	}
+	if grantType == "authorization_code" {
+		refresh, err := newAccessToken()
+		if err != nil {
+			log.Printf("Salesforce: Failed to generate refresh token: %v", err)
+			writeOAuthError(w, "server_error", "failed to generate token")
+			return
+		}
+		resp["refresh_token"] = refresh
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// newAccessToken generates a session ID in Salesforce's "<org id>!<secret>" form
+func newAccessToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return orgID + "!" + hex.EncodeToString(secret), nil
+}
+
+// requireAuth validates the Bearer session ID and the API version in the path
+func (p *SalesforcePlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !p.ValidateToken(strings.TrimSpace(token)) {
+			writeError(w, http.StatusUnauthorized, "INVALID_SESSION_ID", "Session expired or invalid")
+			return
+		}
+
+		if !apiVersionPattern.MatchString(chi.URLParam(r, "version")) {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "The requested resource does not exist")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userKey, userID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// userFromContext returns the ID of the user the request's session authenticates as
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey).(string)
+	return user
+}
+
+func (p *SalesforcePlugin) ValidateToken(token string) bool {
+	return token != ""
+}
+
+func (p *SalesforcePlugin) SetDB(db *sql.DB) error {
+	store, err := NewSalesforceStore(db)
+	if err != nil {
+		return err
+	}
+	p.store = store
+	return nil
+}
+
+// baseURL returns the scheme, host, and base path used for instance_url
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + core.BasePath(r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Salesforce: Failed to encode response: %v", err)
+	}
+}
+
+// writeError writes a Salesforce-style error array
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, []map[string]any{{
+		"message":   message,
+		"errorCode": code,
+	}})
+}
+
+// writeRequestError writes a fieldError as a 400 with its field list, and
+// any other error as a 500
+func writeRequestError(w http.ResponseWriter, err error) {
+	var fe *fieldError
+	if !errors.As(err, &fe) {
+		log.Printf("Salesforce: %v", err)
+		writeError(w, http.StatusInternalServerError, "UNKNOWN_EXCEPTION", "An unexpected error occurred")
+		return
+	}
+	body := map[string]any{
+		"message":   fe.Message,
+		"errorCode": fe.Code,
+	}
+	if fe.Fields != nil {
+		body["fields"] = fe.Fields
+	}
+	writeJSON(w, http.StatusBadRequest, []map[string]any{body})
+}
+
+// writeOAuthError writes an OAuth 2.0 token endpoint error
+func writeOAuthError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// ListResources implements core.DataProvider to expose data to admin UI
+func (p *SalesforcePlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
+	obj, err := resourceObject(slug)
+	if err != nil {
+		return nil, err
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	records, err := p.store.Query(obj, obj.fieldNames(), "", nil, "CreatedDate DESC", limit, opts.Offset)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]interface{}, 0, len(records))
+	for _, rec := range records {
+		result = append(result, convertRecordToMap(obj, rec))
+	}
+	return result, nil
+}
+
+// GetResource implements core.DataProvider to fetch individual resources
+func (p *SalesforcePlugin) GetResource(ctx context.Context, slug string, id string) (map[string]interface{}, error) {
+	obj, err := resourceObject(slug)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := p.store.Get(obj, id)
+	if err != nil {
+		return nil, err
+	}
+	return convertRecordToMap(obj, rec), nil
+}
+
+func resourceObject(slug string) (*sobject, error) {
+	switch slug {
+	case "contacts":
+		return contactObject, nil
+	case "opportunities":
+		return opportunityObject, nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+// convertRecordToMap flattens a record into snake_case admin fields
+func convertRecordToMap(obj *sobject, rec Record) map[string]interface{} {
+	m := map[string]interface{}{
+		"id":            rec["Id"],
+		"name":          rec["Name"],
+		"description":   rec["Description"],
+		"lead_source":   rec["LeadSource"],
+		"created_date":  adminTime(rec["CreatedDate"]),
+		"modified_date": adminTime(rec["LastModifiedDate"]),
+	}
+	switch obj {
+	case contactObject:
+		m["email"] = rec["Email"]
+		m["phone"] = rec["Phone"]
+		m["title"] = rec["Title"]
+		m["department"] = rec["Department"]
+		m["mailing_city"] = rec["MailingCity"]
+	case opportunityObject:
+		m["stage_name"] = rec["StageName"]
+		m["amount"] = rec["Amount"]
+		m["close_date"] = rec["CloseDate"]
+		m["probability"] = rec["Probability"]
+		m["contact_id"] = rec["ContactId"]
+		m["next_step"] = rec["NextStep"]
+	}
+	return m
+}
+
+// adminTime converts a stored Salesforce datetime to the admin UI format
+func adminTime(v any) string {
+	s, _ := v.(string)
+	t, err := time.Parse(dateTimeFormat, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
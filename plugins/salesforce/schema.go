@@ -0,0 +1,83 @@
+// ABOUTME: Admin UI schema definitions for Salesforce plugin
+// ABOUTME: Defines Contacts and Opportunities resources for schema-driven UI
+
+package salesforce
+
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func (p *SalesforcePlugin) Schema() core.PluginSchema {
+	return core.PluginSchema{
+		Resources: []core.ResourceSchema{
+			{
+				Name:        "Contacts",
+				Slug:        "contacts",
+				ListColumns: []string{"name", "email", "title", "lead_source"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Name", Required: true, Editable: false},
+					{Name: "email", Type: "email", Display: "Email", Required: false, Editable: false},
+					{Name: "phone", Type: "string", Display: "Phone", Required: false, Editable: false},
+					{Name: "title", Type: "string", Display: "Title", Required: false, Editable: false},
+					{Name: "department", Type: "string", Display: "Department", Required: false, Editable: false},
+					{Name: "mailing_city", Type: "string", Display: "Mailing City", Required: false, Editable: false},
+					{Name: "lead_source", Type: "string", Display: "Lead Source", Required: false, Editable: false},
+					{Name: "description", Type: "text", Display: "Description", Required: false, Editable: false},
+					{Name: "created_date", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "modified_date", Type: "datetime", Display: "Last Modified", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Opportunities",
+				Slug:        "opportunities",
+				ListColumns: []string{"name", "stage_name", "amount", "close_date"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Name", Required: true, Editable: false},
+					{Name: "stage_name", Type: "string", Display: "Stage", Required: true, Editable: false},
+					{Name: "amount", Type: "string", Display: "Amount", Required: false, Editable: false},
+					{Name: "close_date", Type: "string", Display: "Close Date", Required: true, Editable: false},
+					{Name: "probability", Type: "string", Display: "Probability (%)", Required: false, Editable: false},
+					{Name: "contact_id", Type: "string", Display: "Contact", Required: false, Editable: false},
+					{Name: "next_step", Type: "string", Display: "Next Step", Required: false, Editable: false},
+					{Name: "lead_source", Type: "string", Display: "Lead Source", Required: false, Editable: false},
+					{Name: "description", Type: "text", Display: "Description", Required: false, Editable: false},
+					{Name: "created_date", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "modified_date", Type: "datetime", Display: "Last Modified", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+		},
+	}
+}
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *SalesforcePlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "salesforce", Table: "sf_contacts", Resource: "contacts"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *SalesforcePlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"salesforce": {"sf_opportunities", "sf_contacts"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *SalesforcePlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *SalesforcePlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
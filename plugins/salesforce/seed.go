@@ -0,0 +1,93 @@
+// ABOUTME: Test data generation for Salesforce plugin
+// ABOUTME: Creates CRM contacts and a pipeline of opportunities across standard stages
+
+package salesforce
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+var seedContacts = []map[string]any{
+	{"FirstName": "Ada", "LastName": "Lovelace", "Email": "ada@analyticalengines.example", "Phone": "(415) 555-0101",
+		"Title": "VP Engineering", "Department": "Engineering", "MailingCity": "San Francisco", "MailingState": "CA",
+		"MailingCountry": "USA", "LeadSource": "Web"},
+	{"FirstName": "Grace", "LastName": "Hopper", "Email": "grace@cobolworks.example", "Phone": "(212) 555-0102",
+		"Title": "CTO", "Department": "Technology", "MailingCity": "New York", "MailingState": "NY",
+		"MailingCountry": "USA", "LeadSource": "Partner Referral"},
+	{"FirstName": "Alan", "LastName": "Turing", "Email": "alan@enigmasolutions.example", "Phone": "+44 20 7946 0103",
+		"Title": "Head of Research", "Department": "Research", "MailingCity": "London",
+		"MailingCountry": "United Kingdom", "LeadSource": "Trade Show"},
+	{"FirstName": "Katherine", "LastName": "Johnson", "Email": "katherine@orbitalmath.example", "Phone": "(757) 555-0104",
+		"Title": "Director of Operations", "Department": "Operations", "MailingCity": "Hampton", "MailingState": "VA",
+		"MailingCountry": "USA", "LeadSource": "Phone Inquiry"},
+	{"FirstName": "Linus", "LastName": "Torvalds", "Email": "linus@kernelco.example", "MobilePhone": "(503) 555-0105",
+		"Title": "Procurement Manager", "Department": "Finance", "MailingCity": "Portland", "MailingState": "OR",
+		"MailingCountry": "USA", "LeadSource": "Web"},
+	{"LastName": "Hamilton", "Email": "m.hamilton@apolloflight.example", "Title": "Chief Architect",
+		"MailingCity": "Boston", "MailingState": "MA", "MailingCountry": "USA", "LeadSource": "Purchased List",
+		"Description": "Prefers to be contacted by email"},
+}
+
+type seedOpportunity struct {
+	contact int
+	fields  map[string]any
+}
+
+var seedOpportunities = []seedOpportunity{
+	{0, map[string]any{"Name": "Analytical Engines - Platform Renewal", "StageName": "Negotiation/Review",
+		"Amount": 120000.0, "CloseDate": "2025-03-31", "Type": "Existing Customer - Upgrade", "NextStep": "Send redlined MSA"}},
+	{1, map[string]any{"Name": "COBOL Works - Migration Services", "StageName": "Proposal/Price Quote",
+		"Amount": 85000.0, "CloseDate": "2025-04-15", "Type": "New Customer", "NextStep": "Review proposal with CTO"}},
+	{2, map[string]any{"Name": "Enigma Solutions - Pilot", "StageName": "Qualification",
+		"Amount": 15000.0, "CloseDate": "2025-05-30", "Type": "New Customer", "LeadSource": "Trade Show"}},
+	{3, map[string]any{"Name": "Orbital Math - Enterprise Licenses", "StageName": "Closed Won",
+		"Amount": 240000.0, "CloseDate": "2025-01-20", "Type": "New Customer"}},
+	{4, map[string]any{"Name": "KernelCo - Support Add-on", "StageName": "Closed Lost",
+		"Amount": 9500.0, "CloseDate": "2025-02-10", "Type": "Existing Customer - Upgrade",
+		"Description": "Lost to in-house tooling"}},
+	{0, map[string]any{"Name": "Analytical Engines - Training Package", "StageName": "Prospecting",
+		"Amount": 12000.0, "CloseDate": "2025-06-30", "Type": "Existing Customer - Upgrade"}},
+	{5, map[string]any{"Name": "Apollo Flight - Guidance Systems", "StageName": "Value Proposition",
+		"CloseDate": "2025-07-15", "Type": "New Customer", "NextStep": "Schedule technical deep dive"}},
+}
+
+// Seed creates test data for the Salesforce plugin
+func (p *SalesforcePlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
+	contactIDs := make([]string, 0, len(seedContacts))
+	for _, fields := range seedContacts {
+		values, err := validateInput(contactObject, fields, true)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("invalid seed contact: %w", err)
+		}
+		rec, err := p.store.Insert(contactObject, values, "")
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create contact: %w", err)
+		}
+		contactIDs = append(contactIDs, rec["Id"].(string))
+	}
+
+	for _, seed := range seedOpportunities {
+		fields := map[string]any{"ContactId": contactIDs[seed.contact]}
+		for k, v := range seed.fields {
+			fields[k] = v
+		}
+		values, err := validateInput(opportunityObject, fields, true)
+		if err != nil {
+			return core.SeedData{}, fmt.Errorf("invalid seed opportunity %q: %w", seed.fields["Name"], err)
+		}
+		if _, err := p.store.Insert(opportunityObject, values, ""); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create opportunity %q: %w", seed.fields["Name"], err)
+		}
+	}
+
+	return core.SeedData{
+		Summary: fmt.Sprintf("Created %d contacts, %d opportunities", len(seedContacts), len(seedOpportunities)),
+		Records: map[string]int{
+			"contacts":      len(seedContacts),
+			"opportunities": len(seedOpportunities),
+		},
+	}, nil
+}
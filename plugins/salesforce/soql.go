@@ -0,0 +1,710 @@
+// ABOUTME: SOQL parser for the Salesforce plugin
+// ABOUTME: Compiles SELECT ... FROM ... WHERE ... ORDER BY ... LIMIT/OFFSET into parameterized SQLite queries
+
+package salesforce
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// maxOffset is the largest OFFSET Salesforce allows in a SOQL query
+const maxOffset = 2000
+
+// soqlQuery is a parsed SOQL statement compiled to SQL fragments
+type soqlQuery struct {
+	Object  *sobject
+	Fields  []string
+	Count   bool
+	Where   string
+	Args    []any
+	OrderBy string
+	Limit   int
+	Offset  int
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokDate
+	tokDateTime
+	tokOp
+	tokComma
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var (
+	dateTimeLiteral = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})`)
+	dateLiteral     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+	numberLiteral   = regexp.MustCompile(`^-?\d+(\.\d+)?`)
+)
+
+func malformed(format string, args ...any) error {
+	return &fieldError{Code: "MALFORMED_QUERY", Message: fmt.Sprintf(format, args...)}
+}
+
+// tokenize splits a SOQL statement into tokens
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		rest := input[i:]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokComma, ","})
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")"})
+			i++
+		case strings.HasPrefix(rest, "!=") || strings.HasPrefix(rest, "<>") ||
+			strings.HasPrefix(rest, "<=") || strings.HasPrefix(rest, ">="):
+			tokens = append(tokens, token{tokOp, rest[:2]})
+			i += 2
+		case c == '=' || c == '<' || c == '>':
+			tokens = append(tokens, token{tokOp, rest[:1]})
+			i++
+		case c == '\'':
+			s, n, err := readString(rest)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tokString, s})
+			i += n
+		case dateTimeLiteral.MatchString(rest):
+			m := dateTimeLiteral.FindString(rest)
+			tokens = append(tokens, token{tokDateTime, m})
+			i += len(m)
+		case dateLiteral.MatchString(rest):
+			m := dateLiteral.FindString(rest)
+			tokens = append(tokens, token{tokDate, m})
+			i += len(m)
+		case numberLiteral.MatchString(rest):
+			m := numberLiteral.FindString(rest)
+			tokens = append(tokens, token{tokNumber, m})
+			i += len(m)
+		case isIdentChar(c):
+			j := i
+			for j < len(input) && (isIdentChar(input[j]) || input[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, input[i:j]})
+			i = j
+		default:
+			return nil, malformed("unexpected token: '%c'", c)
+		}
+	}
+	return append(tokens, token{tokEOF, ""}), nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// readString reads a single-quoted SOQL string literal, returning its value
+// and the number of bytes consumed. LIKE wildcard escapes (\% and \_) are
+// preserved so they can be handled by the comparison.
+func readString(s string) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, malformed("unterminated string literal")
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '%', '_':
+				sb.WriteByte('\\')
+				sb.WriteByte(s[i])
+			default:
+				sb.WriteByte(s[i])
+			}
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", 0, malformed("unterminated string literal")
+}
+
+type soqlParser struct {
+	tokens []token
+	pos    int
+	query  *soqlQuery
+}
+
+// parseSOQL parses and compiles a SOQL statement against the supported sObjects
+func parseSOQL(input string) (*soqlQuery, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &soqlParser{tokens: tokens, query: &soqlQuery{Limit: -1}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.query, nil
+}
+
+func (p *soqlParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *soqlParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the given keyword, consuming it if so
+func (p *soqlParser) keyword(kw string) bool {
+	t := p.peek()
+	if t.kind == tokIdent && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *soqlParser) expectKeyword(kw string) error {
+	if !p.keyword(kw) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *soqlParser) expect(kind tokenKind) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		p.pos--
+		return t, p.unexpected()
+	}
+	return t, nil
+}
+
+func (p *soqlParser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return malformed("unexpected end of query")
+	}
+	return malformed("unexpected token: '%s'", t.text)
+}
+
+func (p *soqlParser) parse() error {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return err
+	}
+
+	var selected []string
+	allFields := false
+	if p.keyword("COUNT") {
+		if _, err := p.expect(tokLParen); err != nil {
+			return err
+		}
+		if _, err := p.expect(tokRParen); err != nil {
+			return malformed("only COUNT() is supported")
+		}
+		p.query.Count = true
+	} else {
+		for {
+			t, err := p.expect(tokIdent)
+			if err != nil {
+				return err
+			}
+			if strings.EqualFold(t.text, "FIELDS") && p.peek().kind == tokLParen {
+				p.next()
+				group, err := p.expect(tokIdent)
+				if err != nil {
+					return err
+				}
+				if !strings.EqualFold(group.text, "ALL") && !strings.EqualFold(group.text, "STANDARD") {
+					return malformed("unknown FIELDS() group: %s", group.text)
+				}
+				if _, err := p.expect(tokRParen); err != nil {
+					return err
+				}
+				allFields = true
+			} else {
+				selected = append(selected, t.text)
+			}
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return err
+	}
+	objName, err := p.expect(tokIdent)
+	if err != nil {
+		return err
+	}
+	obj, ok := lookupSObject(objName.text)
+	if !ok {
+		return &fieldError{
+			Code:    "INVALID_TYPE",
+			Message: fmt.Sprintf("sObject type '%s' is not supported.", objName.text),
+		}
+	}
+	p.query.Object = obj
+
+	if !p.query.Count {
+		seen := map[string]bool{}
+		if allFields {
+			for _, name := range obj.fieldNames() {
+				seen[name] = true
+				p.query.Fields = append(p.query.Fields, name)
+			}
+		}
+		for _, name := range selected {
+			f, err := p.resolveField(name)
+			if err != nil {
+				return err
+			}
+			if seen[f.Name] {
+				if allFields {
+					continue
+				}
+				return malformed("duplicate field selected: %s", f.Name)
+			}
+			seen[f.Name] = true
+			p.query.Fields = append(p.query.Fields, f.Name)
+		}
+	}
+
+	if p.keyword("WHERE") {
+		where, err := p.parseOr()
+		if err != nil {
+			return err
+		}
+		p.query.Where = where
+	}
+
+	if p.keyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return err
+		}
+		if err := p.parseOrderBy(); err != nil {
+			return err
+		}
+	}
+
+	if p.keyword("LIMIT") {
+		n, err := p.parseInt()
+		if err != nil {
+			return err
+		}
+		p.query.Limit = n
+	}
+
+	if p.keyword("OFFSET") {
+		n, err := p.parseInt()
+		if err != nil {
+			return err
+		}
+		if n > maxOffset {
+			return &fieldError{
+				Code:    "NUMBER_OUTSIDE_VALID_RANGE",
+				Message: fmt.Sprintf("Maximum SOQL offset allowed is %d", maxOffset),
+			}
+		}
+		p.query.Offset = n
+	}
+
+	if p.peek().kind != tokEOF {
+		return p.unexpected()
+	}
+	return nil
+}
+
+// resolveField maps a field reference to its definition. A prefix naming
+// the queried object (Contact.Email) is allowed.
+func (p *soqlParser) resolveField(name string) (*fieldDef, error) {
+	obj := p.query.Object
+	if prefix, rest, ok := strings.Cut(name, "."); ok {
+		if !strings.EqualFold(prefix, obj.Name) {
+			return nil, &fieldError{
+				Code:    "INVALID_FIELD",
+				Message: fmt.Sprintf("Didn't understand relationship '%s' in field path.", prefix),
+			}
+		}
+		name = rest
+	}
+	f, ok := obj.field(name)
+	if !ok {
+		return nil, &fieldError{
+			Code:    "INVALID_FIELD",
+			Message: fmt.Sprintf("No such column '%s' on entity '%s'.", name, obj.Name),
+		}
+	}
+	return f, nil
+}
+
+func (p *soqlParser) parseInt() (int, error) {
+	t, err := p.expect(tokNumber)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(t.text)
+	if err != nil || n < 0 {
+		return 0, malformed("expected a non-negative integer, got '%s'", t.text)
+	}
+	return n, nil
+}
+
+func (p *soqlParser) parseOrderBy() error {
+	var terms []string
+	for {
+		t, err := p.expect(tokIdent)
+		if err != nil {
+			return err
+		}
+		f, err := p.resolveField(t.text)
+		if err != nil {
+			return err
+		}
+
+		term := f.Name
+		if isTextType(f.Type) {
+			term += " COLLATE NOCASE"
+		}
+		if p.keyword("DESC") {
+			term += " DESC"
+		} else {
+			p.keyword("ASC")
+			term += " ASC"
+		}
+		if p.keyword("NULLS") {
+			switch {
+			case p.keyword("FIRST"):
+				term += " NULLS FIRST"
+			case p.keyword("LAST"):
+				term += " NULLS LAST"
+			default:
+				return p.unexpected()
+			}
+		}
+		terms = append(terms, term)
+
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	p.query.OrderBy = strings.Join(terms, ", ")
+	return nil
+}
+
+func (p *soqlParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " OR " + right + ")"
+	}
+	return left, nil
+}
+
+func (p *soqlParser) parseAnd() (string, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return "", err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return "", err
+		}
+		left = "(" + left + " AND " + right + ")"
+	}
+	return left, nil
+}
+
+func (p *soqlParser) parseNot() (string, error) {
+	if p.keyword("NOT") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if _, err := p.expect(tokRParen); err != nil {
+			return "", err
+		}
+		return "(" + inner + ")", nil
+	}
+	return p.parseComparison()
+}
+
+func (p *soqlParser) parseComparison() (string, error) {
+	t, err := p.expect(tokIdent)
+	if err != nil {
+		return "", err
+	}
+	f, err := p.resolveField(t.text)
+	if err != nil {
+		return "", err
+	}
+	column := f.Name
+	if isTextType(f.Type) {
+		column += " COLLATE NOCASE"
+	}
+
+	switch {
+	case p.keyword("LIKE"):
+		if !isTextType(f.Type) {
+			return "", &fieldError{
+				Code:    "INVALID_FIELD",
+				Message: fmt.Sprintf("invalid operator on %s field: %s", f.Type, f.Name),
+			}
+		}
+		v, err := p.expect(tokString)
+		if err != nil {
+			return "", err
+		}
+		p.query.Args = append(p.query.Args, v.text)
+		return column + ` LIKE ? ESCAPE '\'`, nil
+	case p.keyword("IN"):
+		return p.parseIn(f, column, "IN")
+	case p.keyword("NOT"):
+		if err := p.expectKeyword("IN"); err != nil {
+			return "", err
+		}
+		return p.parseIn(f, column, "NOT IN")
+	}
+
+	op, err := p.expect(tokOp)
+	if err != nil {
+		return "", err
+	}
+	operator := op.text
+	if operator == "<>" {
+		operator = "!="
+	}
+
+	if p.keyword("null") {
+		switch operator {
+		case "=":
+			return f.Name + " IS NULL", nil
+		case "!=":
+			return f.Name + " IS NOT NULL", nil
+		default:
+			return "", malformed("invalid operator for null comparison: %s", operator)
+		}
+	}
+
+	if lo, hi, ok, err := p.dateRange(f); err != nil {
+		return "", err
+	} else if ok {
+		return compileRange(f.Name, operator, lo, hi, &p.query.Args), nil
+	}
+
+	v, err := p.parseValue(f)
+	if err != nil {
+		return "", err
+	}
+	p.query.Args = append(p.query.Args, v)
+	if operator == "!=" {
+		// SOQL's != matches records where the field is null
+		return "(" + column + " != ? OR " + f.Name + " IS NULL)", nil
+	}
+	return column + " " + operator + " ?", nil
+}
+
+func (p *soqlParser) parseIn(f *fieldDef, column, operator string) (string, error) {
+	if _, err := p.expect(tokLParen); err != nil {
+		return "", err
+	}
+	var placeholders []string
+	for {
+		v, err := p.parseValue(f)
+		if err != nil {
+			return "", err
+		}
+		p.query.Args = append(p.query.Args, v)
+		placeholders = append(placeholders, "?")
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(tokRParen); err != nil {
+		return "", err
+	}
+	return column + " " + operator + " (" + strings.Join(placeholders, ", ") + ")", nil
+}
+
+// parseValue reads a literal and converts it to the stored form for the
+// field, rejecting literals of the wrong type like Salesforce does
+func (p *soqlParser) parseValue(f *fieldDef) (any, error) {
+	t := p.next()
+	wrongType := func(want string) error {
+		return &fieldError{
+			Code:    "INVALID_QUERY_FILTER_OPERATOR",
+			Message: fmt.Sprintf("value of filter criterion for field '%s' must be of type %s", f.Name, want),
+		}
+	}
+
+	switch f.Type {
+	case typeCurrency, typePercent:
+		if t.kind != tokNumber {
+			return nil, wrongType("double and should not be enclosed in quotes")
+		}
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, wrongType("double")
+		}
+		return n, nil
+	case typeBoolean:
+		if t.kind == tokIdent && strings.EqualFold(t.text, "true") {
+			return 1, nil
+		}
+		if t.kind == tokIdent && strings.EqualFold(t.text, "false") {
+			return 0, nil
+		}
+		return nil, wrongType("boolean and should not be enclosed in quotes")
+	case typeDate:
+		if t.kind != tokDate {
+			return nil, wrongType("date and should not be enclosed in quotes")
+		}
+		return t.text, nil
+	case typeDateTime:
+		if t.kind != tokDateTime {
+			return nil, wrongType("dateTime and should not be enclosed in quotes")
+		}
+		parsed, err := parseDateTimeLiteral(t.text)
+		if err != nil {
+			return nil, malformed("invalid dateTime literal: %s", t.text)
+		}
+		return timestamp(parsed), nil
+	default:
+		if t.kind != tokString {
+			return nil, wrongType("string and should be enclosed in quotes")
+		}
+		value := strings.NewReplacer(`\%`, "%", `\_`, "_").Replace(t.text)
+		if f.Type == typeID || f.Type == typeRef {
+			value = normalizeRecordID(value)
+		}
+		return value, nil
+	}
+}
+
+func parseDateTimeLiteral(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999-0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid dateTime: %s", s)
+}
+
+// dateRange consumes a relative date literal (TODAY, YESTERDAY, TOMORROW) and
+// returns the half-open range it covers in the field's stored form
+func (p *soqlParser) dateRange(f *fieldDef) (lo, hi string, ok bool, err error) {
+	t := p.peek()
+	if t.kind != tokIdent {
+		return "", "", false, nil
+	}
+	var offset int
+	switch strings.ToUpper(t.text) {
+	case "TODAY":
+		offset = 0
+	case "YESTERDAY":
+		offset = -1
+	case "TOMORROW":
+		offset = 1
+	default:
+		return "", "", false, nil
+	}
+	if f.Type != typeDate && f.Type != typeDateTime {
+		return "", "", false, &fieldError{
+			Code:    "INVALID_QUERY_FILTER_OPERATOR",
+			Message: fmt.Sprintf("value of filter criterion for field '%s' must be of type %s", f.Name, f.Type),
+		}
+	}
+	p.next()
+
+	now := core.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, offset)
+	end := start.AddDate(0, 0, 1)
+	if f.Type == typeDate {
+		return start.Format("2006-01-02"), end.Format("2006-01-02"), true, nil
+	}
+	return timestamp(start), timestamp(end), true, nil
+}
+
+// compileRange expresses a comparison against a whole day as a range check
+func compileRange(column, operator, lo, hi string, args *[]any) string {
+	switch operator {
+	case "=":
+		*args = append(*args, lo, hi)
+		return "(" + column + " >= ? AND " + column + " < ?)"
+	case "!=":
+		*args = append(*args, lo, hi)
+		return "(" + column + " < ? OR " + column + " >= ?)"
+	case "<":
+		*args = append(*args, lo)
+		return column + " < ?"
+	case "<=":
+		*args = append(*args, hi)
+		return column + " < ?"
+	case ">":
+		*args = append(*args, hi)
+		return column + " >= ?"
+	default:
+		*args = append(*args, lo)
+		return column + " >= ?"
+	}
+}
+
+// isTextType reports whether a field compares as case-insensitive text
+func isTextType(fieldType string) bool {
+	switch fieldType {
+	case typeCurrency, typePercent, typeBoolean, typeDate, typeDateTime:
+		return false
+	}
+	return true
+}
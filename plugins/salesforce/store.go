@@ -0,0 +1,298 @@
+// ABOUTME: Database layer for Salesforce plugin
+// ABOUTME: Stores Contact and Opportunity records in sf_contacts and sf_opportunities using Salesforce field names
+
+package salesforce
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// Record is an sObject record keyed by canonical field name. Values are
+// string, float64, bool, or nil.
+type Record map[string]any
+
+type SalesforceStore struct {
+	db *sql.DB
+}
+
+// auditIn records a mutation of a Salesforce record in the shared audit log
+// through db, the transaction making the change so both commit together
+func auditIn(db core.Execer, o *sobject, id, action, actor string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "salesforce",
+		ResourceType: o.Name,
+		ResourceID:   id,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
+func NewSalesforceStore(db *sql.DB) (*SalesforceStore, error) {
+	return &SalesforceStore{db: db}, nil
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *SalesforceStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// timestamp formats a time the way Salesforce stores and renders datetimes.
+// Stored values are UTC so they compare correctly as text.
+func timestamp(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// applyDerived recomputes the read-only fields Salesforce maintains from
+// other field values. changed holds the fields set by the current request.
+func applyDerived(o *sobject, rec Record, changed map[string]any) {
+	switch o {
+	case contactObject:
+		var parts []string
+		for _, f := range []string{"FirstName", "LastName"} {
+			if v, _ := rec[f].(string); v != "" {
+				parts = append(parts, v)
+			}
+		}
+		rec["Name"] = strings.Join(parts, " ")
+	case opportunityObject:
+		if _, ok := changed["StageName"]; !ok {
+			return
+		}
+		stage, _ := rec["StageName"].(string)
+		defaults := stageDefaults[stage]
+		rec["IsClosed"] = defaults.Closed
+		rec["IsWon"] = defaults.Won
+		if _, ok := changed["Probability"]; !ok {
+			if _, known := stageDefaults[stage]; known {
+				rec["Probability"] = defaults.Probability
+			}
+		}
+	}
+}
+
+// checkReferences verifies that reference fields point at existing records
+func (s *SalesforceStore) checkReferences(o *sobject, values map[string]any) error {
+	for name, v := range values {
+		f, _ := o.field(name)
+		if f == nil || f.Type != typeRef || v == nil {
+			continue
+		}
+		target, _ := lookupSObject(f.ReferenceTo)
+		var exists int
+		err := s.db.QueryRow(fmt.Sprintf(`SELECT 1 FROM %s WHERE Id = ?`, target.Table), v).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return &fieldError{
+				Code:    "INVALID_CROSS_REFERENCE_KEY",
+				Message: fmt.Sprintf("%s: id value of incorrect type: %v", f.Label, v),
+				Fields:  []string{f.Name},
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert creates a record from validated field values on behalf of actor
+func (s *SalesforceStore) Insert(o *sobject, values map[string]any, actor string) (Record, error) {
+	if err := s.checkReferences(o, values); err != nil {
+		return nil, err
+	}
+
+	id, err := newRecordID(o.KeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate id: %w", err)
+	}
+
+	now := timestamp(core.Now())
+	rec := Record{"Id": id, "CreatedDate": now, "LastModifiedDate": now, "SystemModstamp": now}
+	for k, v := range values {
+		rec[k] = v
+	}
+	applyDerived(o, rec, values)
+
+	columns := make([]string, 0, len(rec))
+	placeholders := make([]string, 0, len(rec))
+	args := make([]any, 0, len(rec))
+	for _, f := range o.Fields {
+		v, ok := rec[f.Name]
+		if !ok {
+			continue
+		}
+		columns = append(columns, f.Name)
+		placeholders = append(placeholders, "?")
+		args = append(args, v)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`,
+		o.Table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	err = s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to insert %s: %w", o.Name, err)
+		}
+		return auditIn(tx, o, id, core.AuditCreate, actor, values)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(o, id)
+}
+
+// Get fetches a record by its 15- or 18-character ID
+func (s *SalesforceStore) Get(o *sobject, id string) (Record, error) {
+	records, err := s.Query(o, o.fieldNames(), "Id = ?", []any{normalizeRecordID(id)}, "", -1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return records[0], nil
+}
+
+// Update applies validated field values to an existing record on behalf of actor
+func (s *SalesforceStore) Update(o *sobject, id string, values map[string]any, actor string) (Record, error) {
+	rec, err := s.Get(o, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkReferences(o, values); err != nil {
+		return nil, err
+	}
+
+	for k, v := range values {
+		rec[k] = v
+	}
+	applyDerived(o, rec, values)
+	now := timestamp(core.Now())
+	rec["LastModifiedDate"] = now
+	rec["SystemModstamp"] = now
+
+	sets := make([]string, 0, len(o.Fields))
+	args := make([]any, 0, len(o.Fields)+1)
+	for _, f := range o.Fields {
+		if f.Name == "Id" || f.Name == "CreatedDate" {
+			continue
+		}
+		sets = append(sets, f.Name+" = ?")
+		args = append(args, rec[f.Name])
+	}
+	args = append(args, rec["Id"])
+
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE Id = ?`, o.Table, strings.Join(sets, ", "))
+	err = s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to update %s: %w", o.Name, err)
+		}
+		return auditIn(tx, o, normalizeRecordID(id), core.AuditUpdate, actor, values)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Delete removes a record on behalf of actor
+func (s *SalesforceStore) Delete(o *sobject, id, actor string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE Id = ?`, o.Table), normalizeRecordID(id))
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", o.Name, err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return auditIn(tx, o, normalizeRecordID(id), core.AuditDelete, actor, nil)
+	})
+}
+
+// Query selects records with a SQL filter compiled from SOQL. fields must be
+// canonical field names; a negative limit means no limit.
+func (s *SalesforceStore) Query(o *sobject, fields []string, where string, args []any, orderBy string, limit, offset int) ([]Record, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s`, strings.Join(fields, ", "), o.Table)
+	if where != "" {
+		query += " WHERE " + where
+	}
+	if orderBy != "" {
+		query += " ORDER BY " + orderBy
+	}
+	query += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", o.Name, err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		raw := make([]any, len(fields))
+		ptrs := make([]any, len(fields))
+		for i := range raw {
+			ptrs[i] = &raw[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", o.Name, err)
+		}
+
+		rec := make(Record, len(fields))
+		for i, name := range fields {
+			f, _ := o.field(name)
+			rec[name] = fromColumn(f, raw[i])
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// Count returns the number of records matching a compiled SOQL filter
+func (s *SalesforceStore) Count(o *sobject, where string, args []any) (int, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, o.Table)
+	if where != "" {
+		query += " WHERE " + where
+	}
+	var count int
+	if err := s.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", o.Name, err)
+	}
+	return count, nil
+}
+
+// fromColumn converts a scanned SQLite value to the field's API type
+func fromColumn(f *fieldDef, v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return string(val)
+	case int64:
+		switch f.Type {
+		case typeBoolean:
+			return val != 0
+		case typeCurrency, typePercent:
+			return float64(val)
+		}
+	}
+	return v
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *SalesforceStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db, "sf_contacts", "sf_opportunities")
+}
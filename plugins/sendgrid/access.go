@@ -0,0 +1,223 @@
+// ABOUTME: IP Access Management for SendGrid plugin
+// ABOUTME: Whitelists IPs per account, refuses API requests from elsewhere, and logs each access attempt
+
+package sendgrid
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// accessActivityLimit is how many recent access attempts the activity endpoint returns
+const accessActivityLimit = 20
+
+// clientIP returns the address a request came from, preferring the first
+// X-Forwarded-For entry so scenarios can simulate callers
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// validWhitelistIP reports whether a rule is an IP address or CIDR range
+func validWhitelistIP(rule string) bool {
+	if net.ParseIP(rule) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(rule)
+	return err == nil
+}
+
+// ipAllowed reports whether ip matches any rule. An empty whitelist allows every IP.
+func ipAllowed(rules []*IPWhitelistRule, ip string) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	for _, rule := range rules {
+		if _, network, err := net.ParseCIDR(rule.IP); err == nil && addr != nil && network.Contains(addr) {
+			return true
+		}
+		if ruleIP := net.ParseIP(rule.IP); ruleIP != nil && ruleIP.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccess checks the request's IP against the account's whitelist and
+// records the attempt. Store errors let the request through rather than lock
+// the account out.
+func (p *SendGridPlugin) checkAccess(r *http.Request, account *Account) bool {
+	rules, err := p.store.ListWhitelistIPs(account.ID)
+	if err != nil {
+		log.Printf("SendGrid: Failed to load IP whitelist: %v", err)
+		return true
+	}
+	ip := clientIP(r)
+	allowed := ipAllowed(rules, ip)
+	if err := p.store.RecordAccessAttempt(account.ID, ip, allowed); err != nil {
+		log.Printf("SendGrid: Failed to record access attempt: %v", err)
+	}
+	return allowed
+}
+
+// listWhitelist handles GET /v3/access_settings/whitelist
+func (p *SendGridPlugin) listWhitelist(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	rules, err := p.store.ListWhitelistIPs(account.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list whitelisted IPs", "")
+		return
+	}
+	writeWhitelist(w, http.StatusOK, rules)
+}
+
+// addWhitelist handles POST /v3/access_settings/whitelist
+func (p *SendGridPlugin) addWhitelist(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	var req struct {
+		IPs []struct {
+			IP string `json:"ip"`
+		} `json:"ips"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", "")
+		return
+	}
+	if len(req.IPs) == 0 {
+		writeError(w, http.StatusBadRequest, "ips is required", "ips")
+		return
+	}
+	ips := make([]string, len(req.IPs))
+	for i, entry := range req.IPs {
+		ips[i] = strings.TrimSpace(entry.IP)
+		if !validWhitelistIP(ips[i]) {
+			writeError(w, http.StatusBadRequest, "invalid IP address or CIDR range: "+entry.IP, "ips")
+			return
+		}
+	}
+
+	rules, err := p.store.AddWhitelistIPs(account.ID, ips)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to whitelist IPs", "")
+		return
+	}
+	writeWhitelist(w, http.StatusCreated, rules)
+}
+
+// deleteWhitelist handles DELETE /v3/access_settings/whitelist/{rule_id}, and
+// DELETE /v3/access_settings/whitelist with a body of {"ids": [...]}
+func (p *SendGridPlugin) deleteWhitelist(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	var ids []int64
+	if ruleID := chi.URLParam(r, "rule_id"); ruleID != "" {
+		id, err := strconv.ParseInt(ruleID, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid rule_id", "rule_id")
+			return
+		}
+		ids = []int64{id}
+	} else {
+		var req struct {
+			IDs []int64 `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body", "")
+			return
+		}
+		if len(req.IDs) == 0 {
+			writeError(w, http.StatusBadRequest, "ids is required", "ids")
+			return
+		}
+		ids = req.IDs
+	}
+
+	deleted, err := p.store.DeleteWhitelistIPs(account.ID, ids)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete whitelisted IPs", "")
+		return
+	}
+	if deleted == 0 {
+		writeError(w, http.StatusNotFound, "no whitelisted IPs found", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listAccessActivity handles GET /v3/access_settings/activity
+func (p *SendGridPlugin) listAccessActivity(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	attempts, err := p.store.ListAccessAttempts(account.ID, accessActivityLimit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list access activity", "")
+		return
+	}
+
+	result := make([]map[string]interface{}, 0, len(attempts))
+	for _, attempt := range attempts {
+		outcome := "deny"
+		if attempt.Allowed {
+			outcome = "allow"
+		}
+		result = append(result, map[string]interface{}{
+			"result":       outcome,
+			"ip":           attempt.IP,
+			"request_date": attempt.RequestDate.Unix(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"result": result}); err != nil {
+		log.Printf("SendGrid: Failed to encode access activity response: %v", err)
+	}
+}
+
+func writeWhitelist(w http.ResponseWriter, status int, rules []*IPWhitelistRule) {
+	result := make([]map[string]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, map[string]interface{}{
+			"id":         rule.ID,
+			"ip":         rule.IP,
+			"created_at": rule.CreatedAt.Unix(),
+			"updated_at": rule.CreatedAt.Unix(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"result": result}); err != nil {
+		log.Printf("SendGrid: Failed to encode whitelist response: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// ABOUTME: Tests for SendGrid IP Access Management
+// ABOUTME: Covers adding, listing, and deleting whitelisted IPs, enforcement, and the activity log
+
+package sendgrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// accessRequest sends an authenticated request from the given client IP
+func accessRequest(r chi.Router, apiKey, ip, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", ip)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+type whitelistResponse struct {
+	Result []struct {
+		ID int64  `json:"id"`
+		IP string `json:"ip"`
+	} `json:"result"`
+}
+
+func TestIPWhitelistAddListDelete(t *testing.T) {
+	_, _, apiKey, r := setupEventsRouter(t)
+	const office = "203.0.113.7"
+
+	w := accessRequest(r, apiKey, office, "POST", "/v3/access_settings/whitelist",
+		`{"ips": [{"ip": "203.0.113.7"}, {"ip": "198.51.100.0/24"}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var added whitelistResponse
+	json.Unmarshal(w.Body.Bytes(), &added)
+	if len(added.Result) != 2 || added.Result[0].IP != office {
+		t.Fatalf("Unexpected added rules: %s", w.Body.String())
+	}
+
+	w = accessRequest(r, apiKey, office, "GET", "/v3/access_settings/whitelist", "")
+	var listed whitelistResponse
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	if w.Code != http.StatusOK || len(listed.Result) != 2 || listed.Result[1].IP != "198.51.100.0/24" {
+		t.Fatalf("Unexpected whitelist: %d %s", w.Code, w.Body.String())
+	}
+
+	// Only whitelisted callers get in now
+	if w := accessRequest(r, apiKey, "198.51.100.42", "GET", "/v3/access_settings/whitelist", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected an IP in the whitelisted range to be allowed, got %d", w.Code)
+	}
+	if w := accessRequest(r, apiKey, "192.0.2.99", "GET", "/v3/messages", ""); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 from an IP outside the whitelist, got %d", w.Code)
+	}
+
+	path := "/v3/access_settings/whitelist/" + strconv.FormatInt(added.Result[1].ID, 10)
+	if w := accessRequest(r, apiKey, office, "DELETE", path, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 deleting by rule_id, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := accessRequest(r, apiKey, office, "DELETE", path, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting a missing rule, got %d", w.Code)
+	}
+	if w := accessRequest(r, apiKey, office, "DELETE", "/v3/access_settings/whitelist",
+		`{"ids": [`+strconv.FormatInt(added.Result[0].ID, 10)+`]}`); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 deleting by ids, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = accessRequest(r, apiKey, "192.0.2.99", "GET", "/v3/access_settings/whitelist", "")
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	if w.Code != http.StatusOK || len(listed.Result) != 0 {
+		t.Errorf("Expected an empty whitelist to allow everyone, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestIPWhitelistRejectsInvalidIPs(t *testing.T) {
+	_, _, apiKey, r := setupEventsRouter(t)
+
+	for _, body := range []string{`{"ips": []}`, `{"ips": [{"ip": "not-an-ip"}]}`} {
+		if w := accessRequest(r, apiKey, "203.0.113.7", "POST", "/v3/access_settings/whitelist", body); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestAccessActivity(t *testing.T) {
+	_, _, apiKey, r := setupEventsRouter(t)
+
+	accessRequest(r, apiKey, "203.0.113.7", "POST", "/v3/access_settings/whitelist", `{"ips": [{"ip": "203.0.113.7"}]}`)
+	accessRequest(r, apiKey, "192.0.2.99", "GET", "/v3/messages", "")
+	for i := 0; i < 25; i++ {
+		accessRequest(r, apiKey, "203.0.113.7", "GET", "/v3/messages", "")
+	}
+	accessRequest(r, apiKey, "192.0.2.99", "GET", "/v3/messages", "")
+
+	w := accessRequest(r, apiKey, "203.0.113.7", "GET", "/v3/access_settings/activity", "")
+	var resp struct {
+		Result []struct {
+			Result      string `json:"result"`
+			IP          string `json:"ip"`
+			RequestDate int64  `json:"request_date"`
+		} `json:"result"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Result) != accessActivityLimit {
+		t.Fatalf("Expected the last %d attempts, got %d", accessActivityLimit, len(resp.Result))
+	}
+	// Newest first: this request, then the denied one before it
+	if resp.Result[0].Result != "allow" || resp.Result[1].Result != "deny" || resp.Result[1].IP != "192.0.2.99" {
+		t.Errorf("Unexpected activity: %+v", resp.Result[:2])
+	}
+	if resp.Result[1].RequestDate == 0 {
+		t.Error("Expected a request_date")
+	}
+}
@@ -0,0 +1,252 @@
+// ABOUTME: Event Webhook simulation for SendGrid plugin
+// ABOUTME: Stores the webhook URL per account and delivers engagement events to it
+
+package sendgrid
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// eventTypes are the engagement and delivery events the simulator can send
+var eventTypes = map[string]bool{
+	"delivered":   true,
+	"open":        true,
+	"click":       true,
+	"bounce":      true,
+	"spam_report": true,
+	"unsubscribe": true,
+}
+
+// validateEventWebhookURL validates webhook URLs to prevent SSRF attacks
+func validateEventWebhookURL(urlStr string) error {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook URL must use http or https")
+	}
+
+	host := u.Hostname()
+	if host == "localhost" {
+		return fmt.Errorf("webhook URL cannot target private IP addresses")
+	}
+	if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()) {
+		return fmt.Errorf("webhook URL cannot target private IP addresses")
+	}
+	return nil
+}
+
+// getEventWebhookSettings handles GET /v3/user/webhooks/event/settings
+func (p *SendGridPlugin) getEventWebhookSettings(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	cfg, err := p.store.GetWebhookConfig(account.ID)
+	if err == sql.ErrNoRows {
+		cfg = &WebhookConfig{AccountID: account.ID}
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to retrieve webhook settings", "")
+		return
+	}
+
+	writeWebhookSettings(w, cfg)
+}
+
+// updateEventWebhookSettings handles PATCH /v3/user/webhooks/event/settings
+func (p *SendGridPlugin) updateEventWebhookSettings(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	var req struct {
+		Enabled *bool  `json:"enabled"`
+		URL     string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", "")
+		return
+	}
+	if req.URL == "" {
+		writeError(w, http.StatusBadRequest, "url is required", "url")
+		return
+	}
+	if err := validateEventWebhookURL(req.URL); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "url")
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	cfg, err := p.store.SetWebhookConfig(account.ID, req.URL, enabled)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update webhook settings", "")
+		return
+	}
+
+	writeWebhookSettings(w, cfg)
+}
+
+func writeWebhookSettings(w http.ResponseWriter, cfg *WebhookConfig) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled": cfg.Enabled,
+		"url":     cfg.URL,
+	}); err != nil {
+		log.Printf("SendGrid: Failed to encode webhook settings: %v", err)
+	}
+}
+
+// simulateEvent handles POST /admin/sendgrid/simulate/event.
+// It builds an Event Webhook payload and POSTs it to the configured URL of
+// the account that sent sg_message_id, or of the first account with a
+// webhook configured when the message is not one ISH sent.
+func (p *SendGridPlugin) simulateEvent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		EventType   string `json:"event_type"`
+		Email       string `json:"email"`
+		SGMessageID string `json:"sg_message_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", "")
+		return
+	}
+	if !eventTypes[req.EventType] {
+		writeError(w, http.StatusBadRequest, "event_type must be one of delivered, open, click, bounce, spam_report, unsubscribe", "event_type")
+		return
+	}
+
+	var cfg *WebhookConfig
+	var err error
+	msg, msgErr := p.store.GetMessage(req.SGMessageID)
+	if msgErr == nil {
+		if req.Email == "" {
+			req.Email = msg.ToEmail
+		}
+		cfg, err = p.store.GetWebhookConfig(msg.AccountID)
+	} else {
+		cfg, err = p.store.GetFirstEnabledWebhookConfig()
+	}
+	if err == sql.ErrNoRows || (err == nil && !cfg.Enabled) {
+		writeError(w, http.StatusBadRequest, "event webhook is not configured", "url")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to retrieve webhook settings", "")
+		return
+	}
+
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid email address", "email")
+		return
+	}
+	if req.SGMessageID == "" {
+		req.SGMessageID = newEventID()
+	}
+
+	now := time.Now()
+	events := []map[string]interface{}{buildEvent(req.EventType, req.Email, req.SGMessageID, now)}
+	if msgErr == nil {
+		// Events on messages ISH sent also count toward the activity feed and stats
+		if err := p.store.RecordEvent(msg.AccountID, msg.ID, req.EventType, req.Email, now); err != nil {
+			log.Printf("SendGrid: Failed to record %s event: %v", req.EventType, err)
+		}
+	}
+	delivery := deliverEvents(cfg, req.EventType, events)
+	if err := p.store.CreateWebhookDelivery(delivery); err != nil {
+		log.Printf("SendGrid: Failed to log webhook delivery: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"url":         cfg.URL,
+		"status_code": delivery.StatusCode,
+		"error":       delivery.ErrorMessage,
+		"events":      events,
+	}); err != nil {
+		log.Printf("SendGrid: Failed to encode simulate response: %v", err)
+	}
+}
+
+// buildEvent returns one Event Webhook event in SendGrid's format
+func buildEvent(eventType, email, sgMessageID string, at time.Time) map[string]interface{} {
+	event := map[string]interface{}{
+		"email":         email,
+		"timestamp":     at.Unix(),
+		"event":         eventType,
+		"sg_event_id":   newEventID(),
+		"sg_message_id": sgMessageID,
+	}
+
+	switch eventType {
+	case "delivered":
+		event["response"] = "250 OK"
+	case "open":
+		event["useragent"] = "Mozilla/5.0 (ISH)"
+		event["ip"] = "203.0.113.1"
+	case "click":
+		event["url"] = "https://example.com/"
+		event["useragent"] = "Mozilla/5.0 (ISH)"
+		event["ip"] = "203.0.113.1"
+	case "bounce":
+		event["type"] = "bounce"
+		event["status"] = "5.1.1"
+		event["reason"] = "550 5.1.1 The email account that you tried to reach does not exist"
+	}
+	return event
+}
+
+// newEventID returns a random identifier shaped like SendGrid's base64 event IDs
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// deliverEvents POSTs an Event Webhook batch and records the outcome
+func deliverEvents(cfg *WebhookConfig, eventType string, events []map[string]interface{}) *WebhookDelivery {
+	payload, _ := json.Marshal(events)
+	delivery := &WebhookDelivery{
+		AccountID: cfg.AccountID,
+		URL:       cfg.URL,
+		EventType: eventType,
+		Payload:   string(payload),
+	}
+
+	resp, err := core.WebhookClient().Post(cfg.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("SendGrid: Error delivering event to %s: %v", cfg.URL, err)
+		delivery.ErrorMessage = err.Error()
+		if core.IsTimeout(err) {
+			delivery.ErrorMessage = core.WebhookTimeoutError
+		}
+		return delivery
+	}
+	defer resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 300 {
+		log.Printf("SendGrid: Event delivery to %s returned status %d", cfg.URL, resp.StatusCode)
+	}
+	return delivery
+}
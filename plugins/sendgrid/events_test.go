@@ -0,0 +1,153 @@
+// ABOUTME: Tests for SendGrid Event Webhook simulation
+// ABOUTME: Verifies payload format, delivery logging, and webhook settings validation
+
+package sendgrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func setupEventsRouter(t *testing.T) (*SendGridPlugin, *Account, string, chi.Router) {
+	t.Helper()
+	db, plugin := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	account, err := plugin.store.CreateAccount("test@example.com", "Test User")
+	if err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+	apiKey, err := plugin.store.CreateAPIKey(account.ID, "Test Key", "mail.send")
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+	return plugin, account, apiKey.Key, r
+}
+
+func simulateEvent(r http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/admin/sendgrid/simulate/event", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSimulateEventPayload(t *testing.T) {
+	plugin, account, _, r := setupEventsRouter(t)
+
+	received := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected application/json, got %q", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+	}))
+	defer receiver.Close()
+
+	// Receivers in tests listen on loopback, which the settings API rejects
+	if _, err := plugin.store.SetWebhookConfig(account.ID, receiver.URL, true); err != nil {
+		t.Fatalf("Failed to configure webhook: %v", err)
+	}
+	msg, err := plugin.store.CreateMessage(account.ID, "sender@example.com", "", "recipient@example.com", "", "Hi", "hello", "")
+	if err != nil {
+		t.Fatalf("Failed to create message: %v", err)
+	}
+
+	w := simulateEvent(r, `{"event_type":"bounce","sg_message_id":"`+msg.ID+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var events []map[string]interface{}
+	if err := json.Unmarshal(<-received, &events); err != nil {
+		t.Fatalf("Payload is not a JSON array: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+	if event["event"] != "bounce" || event["email"] != "recipient@example.com" || event["sg_message_id"] != msg.ID {
+		t.Errorf("Unexpected event fields: %v", event)
+	}
+	if ts, ok := event["timestamp"].(float64); !ok || ts <= 0 {
+		t.Errorf("Expected a unix timestamp, got %v", event["timestamp"])
+	}
+	if id, ok := event["sg_event_id"].(string); !ok || id == "" {
+		t.Errorf("Expected an sg_event_id, got %v", event["sg_event_id"])
+	}
+	if event["type"] != "bounce" || event["reason"] == nil {
+		t.Errorf("Expected bounce type and reason, got %v", event)
+	}
+
+	deliveries, err := plugin.store.ListWebhookDeliveries(account.ID)
+	if err != nil {
+		t.Fatalf("Failed to list deliveries: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].StatusCode != http.StatusOK || deliveries[0].EventType != "bounce" {
+		t.Errorf("Expected one logged delivery with status 200, got %+v", deliveries)
+	}
+}
+
+func TestSimulateEventValidation(t *testing.T) {
+	_, _, _, r := setupEventsRouter(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unknown event type", `{"event_type":"dropped","email":"a@example.com"}`},
+		{"webhook not configured", `{"event_type":"open","email":"a@example.com"}`},
+		{"malformed body", `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := simulateEvent(r, tt.body); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestEventWebhookSettings(t *testing.T) {
+	_, _, apiKey, r := setupEventsRouter(t)
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/v3/user/webhooks/event/settings", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := patch(`{"enabled":true,"url":"http://127.0.0.1:9000/events"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a loopback URL, got %d", w.Code)
+	}
+	if w := patch(`{"enabled":true,"url":"https://hooks.example.com/sendgrid"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest("GET", "/v3/user/webhooks/event/settings", nil)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var settings struct {
+		Enabled bool   `json:"enabled"`
+		URL     string `json:"url"`
+	}
+	json.NewDecoder(w.Body).Decode(&settings)
+	if !settings.Enabled || settings.URL != "https://hooks.example.com/sendgrid" {
+		t.Errorf("Unexpected settings: %+v", settings)
+	}
+}
@@ -151,7 +151,8 @@ func (p *SendGridPlugin) getMailSettings(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// listMessages handles GET /v3/messages
+// listMessages handles GET /v3/messages, the email activity feed, filtered by
+// the optional to_email and status query parameters
 func (p *SendGridPlugin) listMessages(w http.ResponseWriter, r *http.Request) {
 	account, ok := getAccountFromContext(r.Context())
 	if !ok {
@@ -174,7 +175,11 @@ func (p *SendGridPlugin) listMessages(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	messages, err := p.store.ListMessages(account.ID, limit, offset)
+	filter := MessageFilter{
+		ToEmail: r.URL.Query().Get("to_email"),
+		Status:  r.URL.Query().Get("status"),
+	}
+	messages, err := p.store.ListMessages(account.ID, filter, limit, offset)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to list messages", "")
 		return
@@ -223,7 +228,15 @@ func (p *SendGridPlugin) getMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := map[string]interface{}{
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(messageToResponse(message)); err != nil {
+		log.Printf("SendGrid: Failed to encode message response: %v", err)
+	}
+}
+
+// messageToResponse converts a Message to the GET /v3/messages/{id} response format
+func messageToResponse(message *Message) map[string]interface{} {
+	return map[string]interface{}{
 		"msg_id":       message.ID,
 		"from_email":   message.FromEmail,
 		"from_name":    message.FromName,
@@ -235,11 +248,6 @@ func (p *SendGridPlugin) getMessage(w http.ResponseWriter, r *http.Request) {
 		"status":       message.Status,
 		"sent_at":      message.SentAt.Format("2006-01-02T15:04:05Z"),
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("SendGrid: Failed to encode message response: %v", err)
-	}
 }
 
 // listBounces handles GET /v3/suppression/bounces
@@ -0,0 +1,22 @@
+// ABOUTME: core.Inspector implementation for the SendGrid plugin
+// ABOUTME: Looks up sent messages by ID and renders them as the v3 API does
+
+package sendgrid
+
+import "fmt"
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *SendGridPlugin) Inspect(resource, id string) (interface{}, error) {
+	switch resource {
+	case "messages":
+		message, err := p.store.GetMessage(id)
+		if err != nil {
+			return nil, err
+		}
+		return messageToResponse(message), nil
+	case "suppressions":
+		return nil, fmt.Errorf("suppressions have no ID; omit --id to list them")
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/2389/ish/internal/migrations"
 	"github.com/go-chi/chi/v5"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -20,6 +21,9 @@ func setupTestDB(t *testing.T) (*sql.DB, *SendGridPlugin) {
 	if err != nil {
 		t.Fatalf("Failed to open database: %v", err)
 	}
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
 
 	plugin := &SendGridPlugin{}
 	if err := plugin.SetDB(db); err != nil {
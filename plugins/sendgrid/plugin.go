@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/2389/ish/plugins/core"
@@ -61,6 +62,9 @@ func (p *SendGridPlugin) RegisterRoutes(r chi.Router) {
 	r.Get("/v3/messages", p.requireAuth(p.listMessages))
 	r.Get("/v3/messages/{message_id}", p.requireAuth(p.getMessage))
 
+	// Stats API (daily delivery and engagement counts)
+	r.Get("/v3/stats", p.requireAuth(p.getStats))
+
 	// Suppression Management (bounces, blocks, spam reports)
 	r.Get("/v3/suppression/bounces", p.requireAuth(p.listBounces))
 	r.Delete("/v3/suppression/bounces/{email}", p.requireAuth(p.deleteBounce))
@@ -70,6 +74,25 @@ func (p *SendGridPlugin) RegisterRoutes(r chi.Router) {
 
 	r.Get("/v3/suppression/spam_reports", p.requireAuth(p.listSpamReports))
 	r.Delete("/v3/suppression/spam_reports/{email}", p.requireAuth(p.deleteSpamReport))
+
+	// Event Webhook settings
+	r.Get("/v3/user/webhooks/event/settings", p.requireAuth(p.getEventWebhookSettings))
+	r.Patch("/v3/user/webhooks/event/settings", p.requireAuth(p.updateEventWebhookSettings))
+
+	// IP Access Management
+	r.Get("/v3/access_settings/whitelist", p.requireAuth(p.listWhitelist))
+	r.Post("/v3/access_settings/whitelist", p.requireAuth(p.addWhitelist))
+	r.Delete("/v3/access_settings/whitelist", p.requireAuth(p.deleteWhitelist))
+	r.Delete("/v3/access_settings/whitelist/{rule_id}", p.requireAuth(p.deleteWhitelist))
+	r.Get("/v3/access_settings/activity", p.requireAuth(p.listAccessActivity))
+
+	// Email Address Validation
+	r.Post("/v3/validations/email", p.requireAuth(p.validateEmail))
+	r.Post("/v3/validations/email/jobs", p.requireAuth(p.createValidationJob))
+	r.Get("/v3/validations/email/jobs/{job_id}", p.requireAuth(p.getValidationJob))
+
+	// Event Webhook simulation
+	r.Post("/admin/sendgrid/simulate/event", p.simulateEvent)
 }
 
 func (p *SendGridPlugin) RegisterAuth(r chi.Router) {
@@ -107,6 +130,12 @@ func (p *SendGridPlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// Once an account whitelists IPs, requests from anywhere else are refused
+		if !p.checkAccess(r, account) {
+			writeError(w, http.StatusForbidden, "access forbidden", "")
+			return
+		}
+
 		// Store account in context for handlers
 		ctx := r.Context()
 		ctx = setAccountInContext(ctx, account)
@@ -177,10 +206,52 @@ func (p *SendGridPlugin) Schema() core.PluginSchema {
 				},
 				Actions: []core.ActionSchema{},
 			},
+			{
+				Name:        "Email Validations",
+				Slug:        "validations",
+				ListColumns: []string{"id", "email", "verdict", "score", "source", "created_at"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "email", Type: "string", Display: "Email", Required: true, Editable: false},
+					{Name: "verdict", Type: "string", Display: "Verdict", Required: true, Editable: false},
+					{Name: "score", Type: "string", Display: "Score", Required: true, Editable: false},
+					{Name: "source", Type: "string", Display: "Source", Required: false, Editable: false},
+					{Name: "job_id", Type: "string", Display: "Bulk Job", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
 		},
 	}
 }
 
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *SendGridPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "sendgrid", Table: "sendgrid_messages", Resource: "messages"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *SendGridPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"sendgrid": {"sendgrid_email_validations", "sendgrid_access_activity", "sendgrid_ip_whitelist", "sendgrid_events", "sendgrid_webhook_deliveries", "sendgrid_webhook_config", "sendgrid_suppressions", "sendgrid_messages", "sendgrid_api_keys", "sendgrid_accounts"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *SendGridPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *SendGridPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
+
 // ListResources implements core.DataProvider to expose data to admin UI
 func (p *SendGridPlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
 	switch slug {
@@ -196,6 +267,16 @@ func (p *SendGridPlugin) ListResources(ctx context.Context, slug string, opts co
 			return nil, err
 		}
 		return convertSuppressionsToMaps(suppressions), nil
+	case "validations":
+		validations, err := p.store.ListAllEmailValidations(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(validations))
+		for _, v := range validations {
+			result = append(result, convertEmailValidationToMap(v))
+		}
+		return result, nil
 	default:
 		return nil, fmt.Errorf("unknown resource: %s", slug)
 	}
@@ -214,6 +295,16 @@ func (p *SendGridPlugin) GetResource(ctx context.Context, slug string, id string
 		// Suppressions don't have individual GET by ID in current store
 		// Would need to add GetSuppression method if needed
 		return nil, fmt.Errorf("individual suppression lookup not supported - use ListResources to view all")
+	case "validations":
+		validationID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validation ID: %s", id)
+		}
+		validation, err := p.store.GetEmailValidation(validationID)
+		if err != nil {
+			return nil, err
+		}
+		return convertEmailValidationToMap(validation), nil
 	default:
 		return nil, fmt.Errorf("unknown resource: %s", slug)
 	}
@@ -255,4 +346,17 @@ func convertSuppressionsToMaps(suppressions []*Suppression) []map[string]interfa
 	return result
 }
 
+// convertEmailValidationToMap converts a validation result to map for admin UI
+func convertEmailValidationToMap(v *EmailValidation) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         fmt.Sprintf("%d", v.ID),
+		"email":      v.Email,
+		"verdict":    v.Verdict,
+		"score":      fmt.Sprintf("%.2f", v.Score),
+		"source":     v.Source,
+		"job_id":     v.JobID,
+		"created_at": v.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
 // Seed is implemented in seed.go
@@ -6,6 +6,7 @@ package sendgrid
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/2389/ish/plugins/core"
 )
@@ -129,7 +130,7 @@ func (p *SendGridPlugin) Seed(ctx context.Context, size string) (core.SeedData,
 	totalMessages := 0
 	for i := 0; i < numMessagesPerAccount && i < len(messages); i++ {
 		msg := messages[i]
-		_, err := p.store.CreateMessage(
+		sent, err := p.store.CreateMessage(
 			accountIDs[0],
 			msg.fromEmail,
 			msg.fromName,
@@ -142,6 +143,19 @@ func (p *SendGridPlugin) Seed(ctx context.Context, size string) (core.SeedData,
 		if err != nil {
 			return core.SeedData{}, fmt.Errorf("failed to create message: %w", err)
 		}
+		// Synthesize engagement: every other message is opened, every third clicked
+		engagement := []string{}
+		if i%2 == 0 {
+			engagement = append(engagement, "open")
+		}
+		if i%3 == 0 {
+			engagement = append(engagement, "click")
+		}
+		for _, eventType := range engagement {
+			if err := p.store.RecordEvent(accountIDs[0], sent.ID, eventType, sent.ToEmail, time.Now()); err != nil {
+				return core.SeedData{}, fmt.Errorf("failed to record %s event: %w", eventType, err)
+			}
+		}
 		totalMessages++
 	}
 
@@ -0,0 +1,139 @@
+// ABOUTME: SendGrid Stats API for daily delivery and engagement counts
+// ABOUTME: Aggregates stored message events into the v3/stats response format
+
+package sendgrid
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	statsDateLayout = "2006-01-02"
+	// maxStatsDays bounds the date range of a single stats request
+	maxStatsDays = 366
+)
+
+// statsMetrics are the metric names SendGrid reports for every day
+var statsMetrics = []string{
+	"blocks", "bounce_drops", "bounces", "clicks", "deferred", "delivered",
+	"invalid_emails", "opens", "processed", "requests", "spam_report_drops",
+	"spam_reports", "unique_clicks", "unique_opens", "unsubscribe_drops", "unsubscribes",
+}
+
+// eventMetrics maps event types to the metric each occurrence counts toward
+var eventMetrics = map[string][]string{
+	"processed":   {"processed", "requests"},
+	"delivered":   {"delivered"},
+	"open":        {"opens"},
+	"click":       {"clicks"},
+	"bounce":      {"bounces"},
+	"spam_report": {"spam_reports"},
+	"unsubscribe": {"unsubscribes"},
+}
+
+// uniqueMetrics maps event types to the metric counting distinct messages
+var uniqueMetrics = map[string]string{
+	"open":  "unique_opens",
+	"click": "unique_clicks",
+}
+
+// getStats handles GET /v3/stats
+func (p *SendGridPlugin) getStats(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("start_date") == "" {
+		writeError(w, http.StatusBadRequest, "missing required argument", "start_date")
+		return
+	}
+	start, err := time.Parse(statsDateLayout, query.Get("start_date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "start_date must be in YYYY-MM-DD format", "start_date")
+		return
+	}
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if query.Get("end_date") != "" {
+		if end, err = time.Parse(statsDateLayout, query.Get("end_date")); err != nil {
+			writeError(w, http.StatusBadRequest, "end_date must be in YYYY-MM-DD format", "end_date")
+			return
+		}
+	}
+	if end.Before(start) {
+		writeError(w, http.StatusBadRequest, "end_date must be on or after start_date", "end_date")
+		return
+	}
+	if end.Sub(start) >= maxStatsDays*24*time.Hour {
+		writeError(w, http.StatusBadRequest, "date range must not exceed 366 days", "end_date")
+		return
+	}
+	if by := query.Get("aggregated_by"); by != "" && by != "day" {
+		writeError(w, http.StatusBadRequest, "aggregated_by must be day", "aggregated_by")
+		return
+	}
+
+	events, err := p.store.ListEvents(account.ID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to retrieve stats", "")
+		return
+	}
+
+	days := dailyStats(events, start, end)
+	response := make([]map[string]interface{}, 0, len(days))
+	for _, day := range days {
+		response = append(response, map[string]interface{}{
+			"date":  day.date,
+			"stats": []map[string]interface{}{{"metrics": day.metrics}},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("SendGrid: Failed to encode stats response: %v", err)
+	}
+}
+
+type dayStats struct {
+	date    string
+	metrics map[string]int
+}
+
+// dailyStats counts events per UTC day from start through end, including days without events
+func dailyStats(events []*Event, start, end time.Time) []dayStats {
+	var days []dayStats
+	index := make(map[string]int)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		metrics := make(map[string]int, len(statsMetrics))
+		for _, name := range statsMetrics {
+			metrics[name] = 0
+		}
+		index[d.Format(statsDateLayout)] = len(days)
+		days = append(days, dayStats{date: d.Format(statsDateLayout), metrics: metrics})
+	}
+
+	seen := make(map[string]bool)
+	for _, e := range events {
+		date := e.CreatedAt.UTC().Format(statsDateLayout)
+		i, ok := index[date]
+		if !ok {
+			continue
+		}
+		for _, name := range eventMetrics[e.EventType] {
+			days[i].metrics[name]++
+		}
+		if name, ok := uniqueMetrics[e.EventType]; ok {
+			key := date + "|" + e.EventType + "|" + e.MessageID
+			if !seen[key] {
+				seen[key] = true
+				days[i].metrics[name]++
+			}
+		}
+	}
+	return days
+}
@@ -0,0 +1,141 @@
+// ABOUTME: Tests for the SendGrid Stats API and activity feed filters
+// ABOUTME: Verifies daily delivered counts after sending mail and filtering messages by recipient and status
+
+package sendgrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func sendTestMail(t *testing.T, r http.Handler, apiKey, to string) {
+	t.Helper()
+	body := `{"personalizations":[{"to":[{"email":"` + to + `"}]}],"from":{"email":"sender@example.com"},"subject":"Hi","content":[{"type":"text/plain","value":"hello"}]}`
+	req := httptest.NewRequest("POST", "/v3/mail/send", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func getWithKey(r http.Handler, apiKey, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestStatsCountDeliveredToday(t *testing.T) {
+	_, _, apiKey, r := setupEventsRouter(t)
+
+	sendTestMail(t, r, apiKey, "one@example.com")
+	sendTestMail(t, r, apiKey, "two@example.com")
+
+	today := time.Now().UTC().Format("2006-01-02")
+	w := getWithKey(r, apiKey, "/v3/stats?start_date="+today)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var stats []struct {
+		Date  string `json:"date"`
+		Stats []struct {
+			Metrics map[string]int `json:"metrics"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Invalid stats response: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Date != today || len(stats[0].Stats) != 1 {
+		t.Fatalf("Expected one entry for %s, got %s", today, w.Body.String())
+	}
+	metrics := stats[0].Stats[0].Metrics
+	if metrics["delivered"] != 2 || metrics["requests"] != 2 {
+		t.Errorf("Expected delivered=2 and requests=2, got %v", metrics)
+	}
+	if opens, ok := metrics["opens"]; !ok || opens != 0 {
+		t.Errorf("Expected opens=0 reported, got %v", metrics)
+	}
+
+	if w := getWithKey(r, apiKey, "/v3/stats"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without start_date, got %d", w.Code)
+	}
+}
+
+func TestStatsCountsEngagementAndEmptyDays(t *testing.T) {
+	plugin, account, apiKey, r := setupEventsRouter(t)
+
+	msg, err := plugin.store.CreateMessage(account.ID, "sender@example.com", "", "reader@example.com", "", "Hi", "hello", "")
+	if err != nil {
+		t.Fatalf("Failed to create message: %v", err)
+	}
+	now := time.Now()
+	for _, eventType := range []string{"open", "open", "click"} {
+		if err := plugin.store.RecordEvent(account.ID, msg.ID, eventType, msg.ToEmail, now); err != nil {
+			t.Fatalf("RecordEvent failed: %v", err)
+		}
+	}
+
+	start := now.UTC().AddDate(0, 0, -2).Format("2006-01-02")
+	w := getWithKey(r, apiKey, "/v3/stats?start_date="+start)
+	var stats []struct {
+		Stats []struct {
+			Metrics map[string]int `json:"metrics"`
+		} `json:"stats"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 days, got %s", w.Body.String())
+	}
+	if stats[0].Stats[0].Metrics["delivered"] != 0 {
+		t.Errorf("Expected no deliveries two days ago, got %v", stats[0].Stats[0].Metrics)
+	}
+	metrics := stats[2].Stats[0].Metrics
+	if metrics["opens"] != 2 || metrics["unique_opens"] != 1 || metrics["clicks"] != 1 || metrics["unique_clicks"] != 1 {
+		t.Errorf("Unexpected engagement metrics: %v", metrics)
+	}
+}
+
+func TestListMessagesFilters(t *testing.T) {
+	plugin, account, apiKey, r := setupEventsRouter(t)
+
+	sendTestMail(t, r, apiKey, "one@example.com")
+	sendTestMail(t, r, apiKey, "two@example.com")
+	bounced, err := plugin.store.CreateMessage(account.ID, "sender@example.com", "", "two@example.com", "", "Hi", "hello", "")
+	if err != nil {
+		t.Fatalf("Failed to create message: %v", err)
+	}
+	if err := plugin.store.RecordEvent(account.ID, bounced.ID, "bounce", bounced.ToEmail, time.Now()); err != nil {
+		t.Fatalf("RecordEvent failed: %v", err)
+	}
+
+	list := func(query string) []map[string]interface{} {
+		t.Helper()
+		w := getWithKey(r, apiKey, "/v3/messages"+query)
+		var response struct {
+			Messages []map[string]interface{} `json:"messages"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Invalid messages response: %v", err)
+		}
+		return response.Messages
+	}
+
+	if got := list("?to_email=two@example.com"); len(got) != 2 {
+		t.Errorf("Expected 2 messages to two@example.com, got %d", len(got))
+	}
+	got := list("?to_email=two@example.com&status=not_delivered")
+	if len(got) != 1 || got[0]["msg_id"] != bounced.ID {
+		t.Errorf("Expected only the bounced message, got %v", got)
+	}
+	if got := list("?status=delivered"); len(got) != 2 {
+		t.Errorf("Expected 2 delivered messages, got %d", len(got))
+	}
+}
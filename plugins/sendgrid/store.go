@@ -1,5 +1,5 @@
 // ABOUTME: Database operations and schema for SendGrid plugin
-// ABOUTME: Handles accounts, API keys, messages, and suppressions
+// ABOUTME: Handles accounts, API keys, messages, suppressions, Event Webhook settings, and email validations
 
 package sendgrid
 
@@ -7,8 +7,10 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
+	"github.com/2389/ish/plugins/core"
 	"github.com/google/uuid"
 )
 
@@ -52,78 +54,77 @@ type Suppression struct {
 	CreatedAt time.Time
 }
 
+// MessageFilter narrows the activity feed; empty fields match every message
+type MessageFilter struct {
+	ToEmail string
+	Status  string
+}
+
+// Event is a delivery or engagement event on a sent message
+type Event struct {
+	ID        int64
+	AccountID int64
+	MessageID string
+	EventType string
+	Email     string
+	CreatedAt time.Time
+}
+
+// WebhookConfig is an account's Event Webhook settings
+type WebhookConfig struct {
+	AccountID int64
+	URL       string
+	Enabled   bool
+}
+
+// WebhookDelivery is a logged Event Webhook POST
+type WebhookDelivery struct {
+	ID           int64
+	AccountID    int64
+	URL          string
+	EventType    string
+	Payload      string
+	StatusCode   int
+	ErrorMessage string
+	DeliveredAt  time.Time
+}
+
+// IPWhitelistRule is an IP address or CIDR range allowed to use an account's API keys
+type IPWhitelistRule struct {
+	ID        int64
+	AccountID int64
+	IP        string
+	CreatedAt time.Time
+}
+
+// AccessAttempt is an authenticated API request checked against the IP whitelist
+type AccessAttempt struct {
+	ID          int64
+	AccountID   int64
+	IP          string
+	Allowed     bool
+	RequestDate time.Time
+}
+
+// EmailValidation is a stored email address validation result. JobID is set
+// when the address was checked as part of a bulk job.
+type EmailValidation struct {
+	ID        int64
+	AccountID int64
+	JobID     string
+	Email     string
+	Verdict   string
+	Score     float64
+	Source    string
+	CreatedAt time.Time
+}
+
 type SendGridStore struct {
 	db *sql.DB
 }
 
 func NewSendGridStore(db *sql.DB) (*SendGridStore, error) {
-	store := &SendGridStore{db: db}
-	if err := store.createTables(); err != nil {
-		return nil, err
-	}
-	return store, nil
-}
-
-func (s *SendGridStore) createTables() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS sendgrid_accounts (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		email TEXT NOT NULL UNIQUE,
-		name TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS sendgrid_api_keys (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		account_id INTEGER NOT NULL,
-		key TEXT NOT NULL UNIQUE,
-		name TEXT NOT NULL,
-		scopes TEXT NOT NULL DEFAULT 'mail.send',
-		last_used_at DATETIME,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_sendgrid_api_keys_key ON sendgrid_api_keys(key);
-	CREATE INDEX IF NOT EXISTS idx_sendgrid_api_keys_account ON sendgrid_api_keys(account_id);
-
-	CREATE TABLE IF NOT EXISTS sendgrid_messages (
-		id TEXT PRIMARY KEY,
-		account_id INTEGER NOT NULL,
-		from_email TEXT NOT NULL,
-		from_name TEXT,
-		to_email TEXT NOT NULL,
-		to_name TEXT,
-		subject TEXT,
-		text_content TEXT,
-		html_content TEXT,
-		status TEXT NOT NULL DEFAULT 'delivered',
-		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_sendgrid_messages_account ON sendgrid_messages(account_id);
-	CREATE INDEX IF NOT EXISTS idx_sendgrid_messages_to_email ON sendgrid_messages(to_email);
-	CREATE INDEX IF NOT EXISTS idx_sendgrid_messages_sent_at ON sendgrid_messages(sent_at);
-
-	CREATE TABLE IF NOT EXISTS sendgrid_suppressions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		account_id INTEGER NOT NULL,
-		email TEXT NOT NULL,
-		type TEXT NOT NULL CHECK(type IN ('bounce', 'block', 'spam_report')),
-		reason TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (account_id) REFERENCES sendgrid_accounts(id) ON DELETE CASCADE,
-		UNIQUE(account_id, email, type)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_sendgrid_suppressions_account ON sendgrid_suppressions(account_id);
-	CREATE INDEX IF NOT EXISTS idx_sendgrid_suppressions_email ON sendgrid_suppressions(email);
-	CREATE INDEX IF NOT EXISTS idx_sendgrid_suppressions_type ON sendgrid_suppressions(type);
-	`
-
-	_, err := s.db.Exec(schema)
-	return err
+	return &SendGridStore{db: db}, nil
 }
 
 // ValidateAPIKey validates an API key and returns the associated account
@@ -160,18 +161,55 @@ func (s *SendGridStore) ValidateAPIKey(apiKey string) (*Account, error) {
 	return &account, nil
 }
 
-// CreateAccount creates a new SendGrid account
-func (s *SendGridStore) CreateAccount(email, name string) (*Account, error) {
-	result, err := s.db.Exec(`
-		INSERT INTO sendgrid_accounts (email, name)
-		VALUES (?, ?)
-	`, email, name)
-
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *SendGridStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-	id, err := result.LastInsertId()
+// auditIn records a mutation of a SendGrid resource in the shared audit log
+// within tx, the transaction making the change so both commit together. The
+// actor is recorded by the email of accountID, the account whose API key
+// made the change.
+func auditIn(tx *sql.Tx, resourceType, resourceID, action string, accountID int64, changes any) error {
+	var actor string
+	if err := tx.QueryRow(`SELECT email FROM sendgrid_accounts WHERE id = ?`, accountID).Scan(&actor); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	return core.LogAudit(tx, core.AuditEntry{
+		PluginName:   "sendgrid",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
+// CreateAccount creates a new SendGrid account
+func (s *SendGridStore) CreateAccount(email, name string) (*Account, error) {
+	var id int64
+	err := s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO sendgrid_accounts (email, name)
+			VALUES (?, ?)
+		`, email, name)
+		if err != nil {
+			return err
+		}
+
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		return auditIn(tx, "account", fmt.Sprint(id), core.AuditCreate, id, map[string]any{"email": email, "name": name})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -200,16 +238,21 @@ func (s *SendGridStore) CreateAPIKey(accountID int64, name, scopes string) (*API
 	// Generate a SendGrid-style API key (SG. prefix)
 	key := fmt.Sprintf("SG.%s", uuid.New().String())
 
-	result, err := s.db.Exec(`
-		INSERT INTO sendgrid_api_keys (account_id, key, name, scopes)
-		VALUES (?, ?, ?, ?)
-	`, accountID, key, name, scopes)
-
-	if err != nil {
-		return nil, err
-	}
+	var id int64
+	err := s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO sendgrid_api_keys (account_id, key, name, scopes)
+			VALUES (?, ?, ?, ?)
+		`, accountID, key, name, scopes)
+		if err != nil {
+			return err
+		}
 
-	id, err := result.LastInsertId()
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		return auditIn(tx, "api_key", fmt.Sprint(id), core.AuditCreate, accountID, map[string]any{"account_id": accountID, "name": name, "scopes": scopes})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -234,19 +277,31 @@ func (s *SendGridStore) GetAPIKey(keyID int64) (*APIKey, error) {
 	return &apiKey, nil
 }
 
-// CreateMessage creates a new message record
+// CreateMessage creates a new message record along with its processed and delivered events
 func (s *SendGridStore) CreateMessage(accountID int64, fromEmail, fromName, toEmail, toName, subject, textContent, htmlContent string) (*Message, error) {
 	messageID := uuid.New().String()
 
-	_, err := s.db.Exec(`
-		INSERT INTO sendgrid_messages (id, account_id, from_email, from_name, to_email, to_name, subject, text_content, html_content, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'delivered')
-	`, messageID, accountID, fromEmail, fromName, toEmail, toName, subject, textContent, htmlContent)
-
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO sendgrid_messages (id, account_id, from_email, from_name, to_email, to_name, subject, text_content, html_content, status)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'delivered')
+		`, messageID, accountID, fromEmail, fromName, toEmail, toName, subject, textContent, htmlContent)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "message", messageID, core.AuditCreate, accountID, map[string]any{"from_email": fromEmail, "to_email": toEmail, "subject": subject})
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+	for _, eventType := range []string{"processed", "delivered"} {
+		if err := s.RecordEvent(accountID, messageID, eventType, toEmail, now); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.GetMessage(messageID)
 }
 
@@ -268,15 +323,25 @@ func (s *SendGridStore) GetMessage(messageID string) (*Message, error) {
 	return &msg, nil
 }
 
-// ListMessages retrieves messages for an account
-func (s *SendGridStore) ListMessages(accountID int64, limit, offset int) ([]*Message, error) {
-	rows, err := s.db.Query(`
+// ListMessages retrieves messages for an account that match the filter
+func (s *SendGridStore) ListMessages(accountID int64, filter MessageFilter, limit, offset int) ([]*Message, error) {
+	query := `
 		SELECT id, account_id, from_email, from_name, to_email, to_name, subject, text_content, html_content, status, sent_at
 		FROM sendgrid_messages
-		WHERE account_id = ?
-		ORDER BY sent_at DESC
-		LIMIT ? OFFSET ?
-	`, accountID, limit, offset)
+		WHERE account_id = ?`
+	args := []any{accountID}
+	if filter.ToEmail != "" {
+		query += ` AND to_email = ? COLLATE NOCASE`
+		args = append(args, filter.ToEmail)
+	}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	query += ` ORDER BY sent_at DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(query, args...)
 
 	if err != nil {
 		return nil, err
@@ -304,16 +369,22 @@ func (s *SendGridStore) ListMessages(accountID int64, limit, offset int) ([]*Mes
 
 // CreateSuppression creates a new suppression entry
 func (s *SendGridStore) CreateSuppression(accountID int64, email, suppressionType, reason string) (*Suppression, error) {
-	result, err := s.db.Exec(`
-		INSERT INTO sendgrid_suppressions (account_id, email, type, reason)
-		VALUES (?, ?, ?, ?)
-	`, accountID, email, suppressionType, reason)
-
-	if err != nil {
-		return nil, err
-	}
+	var id int64
+	err := s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO sendgrid_suppressions (account_id, email, type, reason)
+			VALUES (?, ?, ?, ?)
+		`, accountID, email, suppressionType, reason)
+		if err != nil {
+			return err
+		}
 
-	id, err := result.LastInsertId()
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		// Suppressions are addressed by email, so the email stands in for the row ID
+		return auditIn(tx, "suppression", email, core.AuditCreate, accountID, map[string]any{"account_id": accountID, "type": suppressionType, "reason": reason})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -372,12 +443,16 @@ func (s *SendGridStore) ListSuppressions(accountID int64, suppressionType string
 
 // DeleteSuppression deletes a suppression by email and type
 func (s *SendGridStore) DeleteSuppression(accountID int64, email, suppressionType string) error {
-	_, err := s.db.Exec(`
-		DELETE FROM sendgrid_suppressions
-		WHERE account_id = ? AND email = ? AND type = ?
-	`, accountID, email, suppressionType)
-
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM sendgrid_suppressions
+			WHERE account_id = ? AND email = ? AND type = ?
+		`, accountID, email, suppressionType)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "suppression", email, core.AuditDelete, accountID, map[string]any{"account_id": accountID, "type": suppressionType})
+	})
 }
 
 // GetProductionAPIKey retrieves the production API key for an account
@@ -460,3 +535,332 @@ func (s *SendGridStore) ListAllSuppressions(limit, offset int) ([]*Suppression,
 
 	return suppressions, nil
 }
+
+// RecordEvent stores a delivery or engagement event on a message. A bounce
+// marks the message not_delivered, as the activity feed reports it.
+func (s *SendGridStore) RecordEvent(accountID int64, messageID, eventType, email string, at time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO sendgrid_events (account_id, message_id, event_type, email, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, accountID, messageID, eventType, email, at.UTC())
+	if err != nil {
+		return err
+	}
+	if eventType == "bounce" {
+		_, err = s.db.Exec(`UPDATE sendgrid_messages SET status = 'not_delivered' WHERE id = ?`, messageID)
+	}
+	return err
+}
+
+// ListEvents returns an account's events from start up to but not including end, oldest first
+func (s *SendGridStore) ListEvents(accountID int64, start, end time.Time) ([]*Event, error) {
+	rows, err := s.db.Query(`
+		SELECT id, account_id, message_id, event_type, email, created_at
+		FROM sendgrid_events
+		WHERE account_id = ? AND created_at >= ? AND created_at < ?
+		ORDER BY created_at, id
+	`, accountID, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.AccountID, &e.MessageID, &e.EventType, &e.Email, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+// SetWebhookConfig creates or replaces an account's Event Webhook settings
+func (s *SendGridStore) SetWebhookConfig(accountID int64, url string, enabled bool) (*WebhookConfig, error) {
+	err := s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO sendgrid_webhook_config (account_id, url, enabled, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(account_id) DO UPDATE SET url = excluded.url, enabled = excluded.enabled, updated_at = excluded.updated_at
+		`, accountID, url, enabled)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "webhook_config", fmt.Sprint(accountID), core.AuditUpdate, accountID, map[string]any{"url": url, "enabled": enabled})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetWebhookConfig(accountID)
+}
+
+// GetWebhookConfig returns an account's Event Webhook settings, or sql.ErrNoRows if none are set
+func (s *SendGridStore) GetWebhookConfig(accountID int64) (*WebhookConfig, error) {
+	var cfg WebhookConfig
+	err := s.db.QueryRow(`
+		SELECT account_id, url, enabled FROM sendgrid_webhook_config WHERE account_id = ?
+	`, accountID).Scan(&cfg.AccountID, &cfg.URL, &cfg.Enabled)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// GetFirstEnabledWebhookConfig returns the enabled Event Webhook with the lowest account ID
+func (s *SendGridStore) GetFirstEnabledWebhookConfig() (*WebhookConfig, error) {
+	var cfg WebhookConfig
+	err := s.db.QueryRow(`
+		SELECT account_id, url, enabled FROM sendgrid_webhook_config
+		WHERE enabled = 1 ORDER BY account_id LIMIT 1
+	`).Scan(&cfg.AccountID, &cfg.URL, &cfg.Enabled)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// CreateWebhookDelivery logs an Event Webhook delivery attempt
+func (s *SendGridStore) CreateWebhookDelivery(d *WebhookDelivery) error {
+	result, err := s.db.Exec(`
+		INSERT INTO sendgrid_webhook_deliveries (account_id, url, event_type, payload, status_code, error_message)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, d.AccountID, d.URL, d.EventType, d.Payload, d.StatusCode, d.ErrorMessage)
+	if err != nil {
+		return err
+	}
+	d.ID, err = result.LastInsertId()
+	return err
+}
+
+// ListWebhookDeliveries returns an account's Event Webhook deliveries, newest first
+func (s *SendGridStore) ListWebhookDeliveries(accountID int64) ([]*WebhookDelivery, error) {
+	rows, err := s.db.Query(`
+		SELECT id, account_id, url, event_type, payload, COALESCE(status_code, 0), COALESCE(error_message, ''), delivered_at
+		FROM sendgrid_webhook_deliveries
+		WHERE account_id = ?
+		ORDER BY id DESC
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.AccountID, &d.URL, &d.EventType, &d.Payload, &d.StatusCode, &d.ErrorMessage, &d.DeliveredAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// AddWhitelistIPs adds IP addresses or CIDR ranges to an account's whitelist
+func (s *SendGridStore) AddWhitelistIPs(accountID int64, ips []string) ([]*IPWhitelistRule, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int64, 0, len(ips))
+	for _, ip := range ips {
+		result, err := tx.Exec(`INSERT INTO sendgrid_ip_whitelist (account_id, ip) VALUES (?, ?)`, accountID, ip)
+		if err != nil {
+			return nil, err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := auditIn(tx, "ip_whitelist", fmt.Sprint(accountID), core.AuditCreate, accountID, map[string]any{"ips": ips}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.ListWhitelistIPs(accountID)
+	if err != nil {
+		return nil, err
+	}
+	added := make([]*IPWhitelistRule, 0, len(ids))
+	for _, rule := range rules {
+		if slices.Contains(ids, rule.ID) {
+			added = append(added, rule)
+		}
+	}
+	return added, nil
+}
+
+// ListWhitelistIPs returns an account's whitelisted IPs, oldest first
+func (s *SendGridStore) ListWhitelistIPs(accountID int64) ([]*IPWhitelistRule, error) {
+	rows, err := s.db.Query(`
+		SELECT id, account_id, ip, created_at FROM sendgrid_ip_whitelist
+		WHERE account_id = ? ORDER BY id
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*IPWhitelistRule
+	for rows.Next() {
+		var rule IPWhitelistRule
+		if err := rows.Scan(&rule.ID, &rule.AccountID, &rule.IP, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, &rule)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteWhitelistIPs removes whitelist rules by ID and returns how many the account owned
+func (s *SendGridStore) DeleteWhitelistIPs(accountID int64, ids []int64) (int64, error) {
+	var deleted int64
+	err := s.withTx(func(tx *sql.Tx) error {
+		for _, id := range ids {
+			result, err := tx.Exec(`DELETE FROM sendgrid_ip_whitelist WHERE account_id = ? AND id = ?`, accountID, id)
+			if err != nil {
+				return err
+			}
+			n, _ := result.RowsAffected()
+			deleted += n
+		}
+		if deleted == 0 {
+			return nil
+		}
+		return auditIn(tx, "ip_whitelist", fmt.Sprint(accountID), core.AuditDelete, accountID, map[string]any{"ids": ids})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// RecordAccessAttempt logs an authenticated request and whether its IP was allowed
+func (s *SendGridStore) RecordAccessAttempt(accountID int64, ip string, allowed bool) error {
+	_, err := s.db.Exec(`
+		INSERT INTO sendgrid_access_activity (account_id, ip, allowed) VALUES (?, ?, ?)
+	`, accountID, ip, allowed)
+	return err
+}
+
+// ListAccessAttempts returns an account's most recent access attempts, newest first
+func (s *SendGridStore) ListAccessAttempts(accountID int64, limit int) ([]*AccessAttempt, error) {
+	rows, err := s.db.Query(`
+		SELECT id, account_id, ip, allowed, request_date FROM sendgrid_access_activity
+		WHERE account_id = ? ORDER BY id DESC LIMIT ?
+	`, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*AccessAttempt
+	for rows.Next() {
+		var a AccessAttempt
+		if err := rows.Scan(&a.ID, &a.AccountID, &a.IP, &a.Allowed, &a.RequestDate); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, &a)
+	}
+	return attempts, rows.Err()
+}
+
+// CreateEmailValidations stores validation results in one transaction
+func (s *SendGridStore) CreateEmailValidations(validations []*EmailValidation) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, v := range validations {
+		result, err := tx.Exec(`
+			INSERT INTO sendgrid_email_validations (account_id, job_id, email, verdict, score, source)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, v.AccountID, v.JobID, v.Email, v.Verdict, v.Score, v.Source)
+		if err != nil {
+			return err
+		}
+		if v.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListEmailValidationsByJob returns the results of an account's bulk validation job, in submission order
+func (s *SendGridStore) ListEmailValidationsByJob(accountID int64, jobID string) ([]*EmailValidation, error) {
+	return s.queryEmailValidations(`
+		SELECT id, account_id, job_id, email, verdict, score, source, created_at
+		FROM sendgrid_email_validations
+		WHERE account_id = ? AND job_id = ?
+		ORDER BY id
+	`, accountID, jobID)
+}
+
+// ListAllEmailValidations returns validation results across all accounts, newest first (for admin UI)
+func (s *SendGridStore) ListAllEmailValidations(limit, offset int) ([]*EmailValidation, error) {
+	return s.queryEmailValidations(`
+		SELECT id, account_id, job_id, email, verdict, score, source, created_at
+		FROM sendgrid_email_validations
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+}
+
+// GetEmailValidation retrieves a validation result by ID
+func (s *SendGridStore) GetEmailValidation(id int64) (*EmailValidation, error) {
+	validations, err := s.queryEmailValidations(`
+		SELECT id, account_id, job_id, email, verdict, score, source, created_at
+		FROM sendgrid_email_validations
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(validations) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return validations[0], nil
+}
+
+func (s *SendGridStore) queryEmailValidations(query string, args ...any) ([]*EmailValidation, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var validations []*EmailValidation
+	for rows.Next() {
+		var v EmailValidation
+		if err := rows.Scan(&v.ID, &v.AccountID, &v.JobID, &v.Email, &v.Verdict, &v.Score, &v.Source, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		validations = append(validations, &v)
+	}
+	return validations, rows.Err()
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *SendGridStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db,
+		"sendgrid_accounts",
+		"sendgrid_api_keys",
+		"sendgrid_messages",
+		"sendgrid_events",
+		"sendgrid_suppressions",
+		"sendgrid_webhook_config",
+		"sendgrid_webhook_deliveries",
+		"sendgrid_ip_whitelist",
+		"sendgrid_access_activity",
+		"sendgrid_email_validations",
+	)
+}
@@ -0,0 +1,236 @@
+// ABOUTME: Email Address Validation for SendGrid plugin
+// ABOUTME: Returns deterministic Valid/Risky/Invalid verdicts for single addresses and bulk jobs
+
+package sendgrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Email validation verdicts
+const (
+	verdictValid   = "Valid"
+	verdictRisky   = "Risky"
+	verdictInvalid = "Invalid"
+)
+
+// trustedValidationDomains always validate with a high score, so scenarios
+// can rely on them being accepted
+var trustedValidationDomains = []string{"example.com", "test.com"}
+
+// validationChecks is the outcome of each check behind a verdict
+type validationChecks struct {
+	ValidSyntax bool
+	HasMX       bool
+	SpamTagged  bool
+}
+
+// validateEmailAddress decides an address's verdict and score from the
+// address alone, without DNS lookups. Addresses with no @ or bad syntax are
+// Invalid, a +spam tag makes an address Risky, and anything else is Valid,
+// scoring highest at the trusted domains.
+func validateEmailAddress(email string) (string, float64, validationChecks) {
+	local, host, found := strings.Cut(email, "@")
+	parsed, err := mail.ParseAddress(email)
+	if !found || local == "" || !strings.Contains(host, ".") || err != nil || parsed.Address != email {
+		return verdictInvalid, 0, validationChecks{}
+	}
+
+	checks := validationChecks{ValidSyntax: true, HasMX: true}
+	if strings.Contains(strings.ToLower(local), "+spam") {
+		checks.SpamTagged = true
+		return verdictRisky, 0.3, checks
+	}
+	for _, domain := range trustedValidationDomains {
+		if strings.EqualFold(host, domain) {
+			return verdictValid, 0.97, checks
+		}
+	}
+	return verdictValid, 0.85, checks
+}
+
+// newEmailValidation checks an address for an account and returns the result to store
+func newEmailValidation(accountID int64, jobID, email, source string) *EmailValidation {
+	email = strings.TrimSpace(email)
+	verdict, score, _ := validateEmailAddress(email)
+	return &EmailValidation{
+		AccountID: accountID,
+		JobID:     jobID,
+		Email:     email,
+		Verdict:   verdict,
+		Score:     score,
+		Source:    strings.ToUpper(source),
+	}
+}
+
+// emailValidationToResponse converts an EmailValidation to SendGrid's result
+// object. The checks are recomputed, since they follow from the address.
+func emailValidationToResponse(v *EmailValidation, ip string) map[string]interface{} {
+	_, _, checks := validateEmailAddress(v.Email)
+	local, host, _ := strings.Cut(v.Email, "@")
+	result := map[string]interface{}{
+		"email":   v.Email,
+		"verdict": v.Verdict,
+		"score":   v.Score,
+		"local":   local,
+		"host":    host,
+		"checks": map[string]interface{}{
+			"domain": map[string]interface{}{
+				"has_valid_address_syntax":        checks.ValidSyntax,
+				"has_mx_or_a_record":              checks.HasMX,
+				"is_suspected_disposable_address": false,
+			},
+			"local_part": map[string]interface{}{
+				"is_suspected_role_address": false,
+			},
+			"additional": map[string]interface{}{
+				"has_known_bounces":     false,
+				"has_suspected_bounces": checks.SpamTagged,
+			},
+		},
+		"ip_address": ip,
+	}
+	if v.Source != "" {
+		result["source"] = v.Source
+	}
+	return result
+}
+
+// validateEmail handles POST /v3/validations/email
+func (p *SendGridPlugin) validateEmail(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	var req struct {
+		Email  string `json:"email"`
+		Source string `json:"source"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", "")
+		return
+	}
+	if strings.TrimSpace(req.Email) == "" {
+		writeError(w, http.StatusBadRequest, "email is required", "email")
+		return
+	}
+
+	validation := newEmailValidation(account.ID, "", req.Email, req.Source)
+	if err := p.store.CreateEmailValidations([]*EmailValidation{validation}); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to validate email", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"result": emailValidationToResponse(validation, clientIP(r))}); err != nil {
+		log.Printf("SendGrid: Failed to encode validation response: %v", err)
+	}
+}
+
+// createValidationJob handles POST /v3/validations/email/jobs. The body is
+// either a JSON array of addresses or {"emails": [...], "source": "..."}.
+// Jobs run immediately, so the response already holds every result.
+func (p *SendGridPlugin) createValidationJob(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	var req struct {
+		Emails []string `json:"emails"`
+		Source string   `json:"source"`
+	}
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", "")
+		return
+	}
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		err = json.Unmarshal(body, &req.Emails)
+	} else {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", "")
+		return
+	}
+	if len(req.Emails) == 0 {
+		writeError(w, http.StatusBadRequest, "emails is required", "emails")
+		return
+	}
+
+	jobID := uuid.New().String()
+	validations := make([]*EmailValidation, len(req.Emails))
+	for i, email := range req.Emails {
+		validations[i] = newEmailValidation(account.ID, jobID, email, req.Source)
+	}
+	if err := p.store.CreateEmailValidations(validations); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to validate emails", "")
+		return
+	}
+
+	writeValidationJob(w, http.StatusCreated, jobID, validations, clientIP(r))
+}
+
+// getValidationJob handles GET /v3/validations/email/jobs/{job_id}
+func (p *SendGridPlugin) getValidationJob(w http.ResponseWriter, r *http.Request) {
+	account, ok := getAccountFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required", "")
+		return
+	}
+
+	jobID := chi.URLParam(r, "job_id")
+	validations, err := p.store.ListEmailValidationsByJob(account.ID, jobID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get validation job", "")
+		return
+	}
+	if len(validations) == 0 {
+		writeError(w, http.StatusNotFound, "validation job not found", "job_id")
+		return
+	}
+
+	writeValidationJob(w, http.StatusOK, jobID, validations, clientIP(r))
+}
+
+func writeValidationJob(w http.ResponseWriter, status int, jobID string, validations []*EmailValidation, ip string) {
+	results := make([]map[string]interface{}, 0, len(validations))
+	for _, v := range validations {
+		results = append(results, emailValidationToResponse(v, ip))
+	}
+	finished := validations[len(validations)-1].CreatedAt
+	if finished.IsZero() {
+		finished = time.Now()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": map[string]interface{}{
+			"id":                 jobID,
+			"status":             "Done",
+			"segments":           1,
+			"segments_processed": 1,
+			"started_at":         finished.Unix(),
+			"finished_at":        finished.Unix(),
+			"errors":             []interface{}{},
+			"results":            results,
+		},
+	}); err != nil {
+		log.Printf("SendGrid: Failed to encode validation job response: %v", err)
+	}
+}
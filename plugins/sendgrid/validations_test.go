@@ -0,0 +1,148 @@
+// ABOUTME: Tests for SendGrid Email Address Validation
+// ABOUTME: Covers each verdict, bulk validation jobs, and stored results for the admin UI
+
+package sendgrid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+type validationResult struct {
+	Email   string  `json:"email"`
+	Verdict string  `json:"verdict"`
+	Score   float64 `json:"score"`
+	Source  string  `json:"source"`
+	Checks  struct {
+		Domain struct {
+			HasValidAddressSyntax bool `json:"has_valid_address_syntax"`
+			HasMXOrARecord        bool `json:"has_mx_or_a_record"`
+		} `json:"domain"`
+	} `json:"checks"`
+}
+
+func TestValidateEmailVerdicts(t *testing.T) {
+	_, _, apiKey, r := setupEventsRouter(t)
+
+	tests := []struct {
+		email   string
+		verdict string
+		hasMX   bool
+	}{
+		{"alice@example.com", "Valid", true},
+		{"bob@test.com", "Valid", true},
+		{"carol+spam@example.com", "Risky", true},
+		{"not-an-email", "Invalid", false},
+		{"dave@localhost", "Invalid", false},
+	}
+	for _, tt := range tests {
+		w := accessRequest(r, apiKey, "203.0.113.7", "POST", "/v3/validations/email",
+			`{"email": "`+tt.email+`", "source": "signup"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.email, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Result validationResult `json:"result"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Result.Email != tt.email || resp.Result.Verdict != tt.verdict {
+			t.Errorf("%s: expected verdict %s, got %s", tt.email, tt.verdict, w.Body.String())
+		}
+		if resp.Result.Checks.Domain.HasMXOrARecord != tt.hasMX {
+			t.Errorf("%s: expected has_mx_or_a_record %v", tt.email, tt.hasMX)
+		}
+		if resp.Result.Source != "SIGNUP" {
+			t.Errorf("%s: expected source SIGNUP, got %q", tt.email, resp.Result.Source)
+		}
+	}
+
+	w := accessRequest(r, apiKey, "203.0.113.7", "POST", "/v3/validations/email", `{}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without an email, got %d", w.Code)
+	}
+}
+
+func TestValidateEmailScores(t *testing.T) {
+	valid, validScore, _ := validateEmailAddress("alice@example.com")
+	other, otherScore, _ := validateEmailAddress("alice@elsewhere.org")
+	risky, riskyScore, _ := validateEmailAddress("alice+spam@elsewhere.org")
+	_, invalidScore, _ := validateEmailAddress("alice@@example.com")
+	if valid != "Valid" || other != "Valid" || risky != "Risky" {
+		t.Fatalf("Unexpected verdicts: %s, %s, %s", valid, other, risky)
+	}
+	if !(validScore > otherScore && otherScore > riskyScore && riskyScore > invalidScore) {
+		t.Errorf("Expected scores to fall with confidence, got %v, %v, %v, %v", validScore, otherScore, riskyScore, invalidScore)
+	}
+}
+
+func TestValidationJobs(t *testing.T) {
+	plugin, _, apiKey, r := setupEventsRouter(t)
+
+	type jobResponse struct {
+		Result struct {
+			ID      string             `json:"id"`
+			Status  string             `json:"status"`
+			Results []validationResult `json:"results"`
+		} `json:"result"`
+	}
+
+	// A bare array of addresses
+	w := accessRequest(r, apiKey, "203.0.113.7", "POST", "/v3/validations/email/jobs",
+		`["alice@example.com", "bob+spam@test.com", "nobody"]`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var job jobResponse
+	json.Unmarshal(w.Body.Bytes(), &job)
+	if job.Result.ID == "" || job.Result.Status != "Done" || len(job.Result.Results) != 3 {
+		t.Fatalf("Unexpected job: %s", w.Body.String())
+	}
+	for i, verdict := range []string{"Valid", "Risky", "Invalid"} {
+		if job.Result.Results[i].Verdict != verdict {
+			t.Errorf("Result %d: expected %s, got %s", i, verdict, job.Result.Results[i].Verdict)
+		}
+	}
+
+	w = accessRequest(r, apiKey, "203.0.113.7", "GET", "/v3/validations/email/jobs/"+job.Result.ID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var fetched jobResponse
+	json.Unmarshal(w.Body.Bytes(), &fetched)
+	if len(fetched.Result.Results) != 3 || fetched.Result.Results[0].Email != "alice@example.com" {
+		t.Errorf("Unexpected fetched job: %s", w.Body.String())
+	}
+
+	// An object with a source
+	w = accessRequest(r, apiKey, "203.0.113.7", "POST", "/v3/validations/email/jobs",
+		`{"emails": ["carol@example.com"], "source": "import"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, body := range []string{`[]`, `{"emails": []}`, `"alice@example.com"`} {
+		if w := accessRequest(r, apiKey, "203.0.113.7", "POST", "/v3/validations/email/jobs", body); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, w.Code)
+		}
+	}
+	if w := accessRequest(r, apiKey, "203.0.113.7", "GET", "/v3/validations/email/jobs/missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing job, got %d", w.Code)
+	}
+
+	// Every result is kept for the admin UI
+	rows, err := plugin.ListResources(context.Background(), "validations", core.ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	if len(rows) != 4 || rows[0]["email"] != "carol@example.com" || rows[0]["source"] != "IMPORT" {
+		t.Fatalf("Unexpected stored validations: %v", rows)
+	}
+	row, err := plugin.GetResource(context.Background(), "validations", rows[0]["id"].(string))
+	if err != nil || row["verdict"] != "Valid" {
+		t.Errorf("Unexpected stored validation %v: %v", row, err)
+	}
+}
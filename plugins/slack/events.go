@@ -0,0 +1,157 @@
+// ABOUTME: Events API simulation for Slack plugin
+// ABOUTME: Injects user messages and delivers event_callback payloads to app endpoints
+
+package slack
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// validateEventURL validates event delivery URLs to prevent SSRF attacks
+func validateEventURL(urlStr string) error {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return fmt.Errorf("invalid event URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("event URL must use http or https")
+	}
+
+	host := u.Hostname()
+	if host == "localhost" {
+		return fmt.Errorf("event URL cannot target private IP addresses")
+	}
+	if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()) {
+		return fmt.Errorf("event URL cannot target private IP addresses")
+	}
+	return nil
+}
+
+// simulateMessage handles POST /admin/slack/simulate/message.
+// It records a message as if a workspace user had posted it and, when
+// event_url is set, delivers the matching Events API payload to it.
+func (p *SlackPlugin) simulateMessage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Channel  string `json:"channel"`
+		User     string `json:"user"`
+		Text     string `json:"text"`
+		ThreadTS string `json:"thread_ts"`
+		EventURL string `json:"event_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "invalid_json")
+		return
+	}
+	if req.Text == "" {
+		writeError(w, "no_text")
+		return
+	}
+	if req.EventURL != "" {
+		if err := validateEventURL(req.EventURL); err != nil {
+			writeError(w, "invalid_event_url")
+			return
+		}
+	}
+
+	channel, err := p.store.GetChannel(req.Channel)
+	if err == sql.ErrNoRows {
+		writeError(w, "channel_not_found")
+		return
+	}
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	user, err := p.store.GetUser(req.User)
+	if err == sql.ErrNoRows {
+		writeError(w, "user_not_found")
+		return
+	}
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	msg := &Message{
+		ChannelID: channel.ID,
+		ThreadTS:  req.ThreadTS,
+		UserID:    user.ID,
+		Text:      req.Text,
+	}
+	if err := p.store.CreateMessage(msg); err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	event := buildMessageEvent(channel, msg)
+	if req.EventURL != "" {
+		go deliverEvent(req.EventURL, event)
+	}
+
+	writeOK(w, map[string]any{
+		"channel": channel.ID,
+		"ts":      msg.TS,
+		"event":   event,
+	})
+}
+
+// buildMessageEvent wraps a message in an Events API event_callback envelope
+func buildMessageEvent(channel *Channel, msg *Message) map[string]any {
+	inner := map[string]any{
+		"type":         "message",
+		"channel":      channel.ID,
+		"user":         msg.UserID,
+		"text":         msg.Text,
+		"ts":           msg.TS,
+		"event_ts":     msg.TS,
+		"channel_type": "channel",
+	}
+	if channel.IsPrivate {
+		inner["channel_type"] = "group"
+	}
+	if msg.ThreadTS != "" {
+		inner["thread_ts"] = msg.ThreadTS
+	}
+
+	return map[string]any{
+		"token":      "ish-verification-token",
+		"team_id":    "TISH000001",
+		"api_app_id": "AISH000001",
+		"event":      inner,
+		"type":       "event_callback",
+		"event_id":   generateID("Ev"),
+		"event_time": msg.CreatedAt.Unix(),
+		"authorizations": []map[string]any{
+			{"team_id": "TISH000001", "user_id": BotUserID, "is_bot": true},
+		},
+	}
+}
+
+func deliverEvent(eventURL string, event map[string]any) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Slack: Failed to encode event: %v", err)
+		return
+	}
+
+	resp, err := core.WebhookClient().Post(eventURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("Slack: Error delivering event to %s: %v", eventURL, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("Slack: Event delivery to %s returned status %d", eventURL, resp.StatusCode)
+	}
+}
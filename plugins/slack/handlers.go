@@ -0,0 +1,493 @@
+// ABOUTME: HTTP handlers for Slack Web API methods
+// ABOUTME: Implements chat, conversations, reactions, users, and files endpoints
+
+package slack
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+	maxUploadBytes   = 32 << 20
+)
+
+// readParams collects method arguments from a JSON body, a form body, or
+// the query string. Slack accepts all three for most Web API methods.
+func readParams(r *http.Request) (map[string]string, error) {
+	params := make(map[string]string)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			return nil, err
+		}
+		for k, v := range body {
+			switch val := v.(type) {
+			case string:
+				params[k] = val
+			case nil:
+			default:
+				encoded, _ := json.Marshal(val)
+				params[k] = string(encoded)
+			}
+		}
+		for k, v := range r.URL.Query() {
+			if _, exists := params[k]; !exists && len(v) > 0 {
+				params[k] = v[0]
+			}
+		}
+		return params, nil
+	}
+
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxUploadBytes); err != nil {
+			return nil, err
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	for k, v := range r.Form {
+		if len(v) > 0 {
+			params[k] = v[0]
+		}
+	}
+	return params, nil
+}
+
+// encodeCursor and decodeCursor map list offsets to opaque Slack cursors
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("offset:%d", offset)))
+}
+
+func decodeCursor(cursor string) (int, bool) {
+	if cursor == "" {
+		return 0, true
+	}
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, false
+	}
+	return offset, true
+}
+
+// parseLimit reads the "limit" parameter, applying Slack's default and cap
+func parseLimit(raw string) int {
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
+// postMessage handles POST /api/chat.postMessage
+func (p *SlackPlugin) postMessage(w http.ResponseWriter, r *http.Request) {
+	params, err := readParams(r)
+	if err != nil {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	if params["channel"] == "" {
+		writeError(w, "channel_not_found")
+		return
+	}
+	text := params["text"]
+	if text == "" && params["blocks"] == "" && params["attachments"] == "" {
+		writeError(w, "no_text")
+		return
+	}
+
+	channel, err := p.store.GetChannel(params["channel"])
+	if err == sql.ErrNoRows {
+		writeError(w, "channel_not_found")
+		return
+	}
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+	if channel.IsArchived {
+		writeError(w, "is_archived")
+		return
+	}
+
+	if threadTS := params["thread_ts"]; threadTS != "" {
+		if _, err := p.store.GetMessage(channel.ID, threadTS); err != nil {
+			writeError(w, "thread_not_found")
+			return
+		}
+	}
+
+	msg := &Message{
+		ChannelID: channel.ID,
+		ThreadTS:  params["thread_ts"],
+		UserID:    BotUserID,
+		BotID:     BotID,
+		Username:  params["username"],
+		Text:      text,
+	}
+	if err := p.store.CreateMessage(msg); err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	writeOK(w, map[string]any{
+		"channel": channel.ID,
+		"ts":      msg.TS,
+		"message": messageResponse(msg),
+	})
+}
+
+// listConversations handles GET /api/conversations.list
+func (p *SlackPlugin) listConversations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := parseLimit(query.Get("limit"))
+	offset, ok := decodeCursor(query.Get("cursor"))
+	if !ok {
+		writeError(w, "invalid_cursor")
+		return
+	}
+	excludeArchived := query.Get("exclude_archived") == "true" || query.Get("exclude_archived") == "1"
+
+	// Fetch one extra row to know whether another page exists
+	channels, err := p.store.ListChannels(excludeArchived, limit+1, offset)
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	nextCursor := ""
+	if len(channels) > limit {
+		channels = channels[:limit]
+		nextCursor = encodeCursor(offset + limit)
+	}
+
+	result := make([]map[string]any, 0, len(channels))
+	for _, ch := range channels {
+		members, _ := p.store.CountChannelMembers(ch.ID)
+		result = append(result, channelResponse(ch, members))
+	}
+
+	writeOK(w, map[string]any{
+		"channels":          result,
+		"response_metadata": map[string]any{"next_cursor": nextCursor},
+	})
+}
+
+// conversationHistory handles GET /api/conversations.history
+func (p *SlackPlugin) conversationHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("channel") == "" {
+		writeError(w, "channel_not_found")
+		return
+	}
+
+	channel, err := p.store.GetChannel(query.Get("channel"))
+	if err == sql.ErrNoRows {
+		writeError(w, "channel_not_found")
+		return
+	}
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	limit := parseLimit(query.Get("limit"))
+	offset, ok := decodeCursor(query.Get("cursor"))
+	if !ok {
+		writeError(w, "invalid_cursor")
+		return
+	}
+
+	messages, err := p.store.ListMessages(channel.ID, query.Get("oldest"), query.Get("latest"), limit+1, offset)
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	hasMore := len(messages) > limit
+	nextCursor := ""
+	if hasMore {
+		messages = messages[:limit]
+		nextCursor = encodeCursor(offset + limit)
+	}
+
+	result := make([]map[string]any, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, messageResponse(msg))
+	}
+
+	writeOK(w, map[string]any{
+		"messages":          result,
+		"has_more":          hasMore,
+		"pin_count":         0,
+		"response_metadata": map[string]any{"next_cursor": nextCursor},
+	})
+}
+
+// addReaction handles POST /api/reactions.add
+func (p *SlackPlugin) addReaction(w http.ResponseWriter, r *http.Request) {
+	params, err := readParams(r)
+	if err != nil {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	name := strings.Trim(params["name"], ":")
+	if name == "" {
+		writeError(w, "invalid_name")
+		return
+	}
+	if params["timestamp"] == "" {
+		writeError(w, "no_item_specified")
+		return
+	}
+
+	channel, err := p.store.GetChannel(params["channel"])
+	if err == sql.ErrNoRows {
+		writeError(w, "channel_not_found")
+		return
+	}
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	err = p.store.AddReaction(channel.ID, params["timestamp"], name, BotUserID)
+	switch {
+	case err == sql.ErrNoRows:
+		writeError(w, "message_not_found")
+		return
+	case err == ErrAlreadyReacted:
+		writeError(w, "already_reacted")
+		return
+	case err != nil:
+		writeError(w, "internal_error")
+		return
+	}
+
+	writeOK(w, nil)
+}
+
+// listUsers handles GET /api/users.list
+func (p *SlackPlugin) listUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := parseLimit(query.Get("limit"))
+	offset, ok := decodeCursor(query.Get("cursor"))
+	if !ok {
+		writeError(w, "invalid_cursor")
+		return
+	}
+
+	users, err := p.store.ListUsers(limit+1, offset)
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	nextCursor := ""
+	if len(users) > limit {
+		users = users[:limit]
+		nextCursor = encodeCursor(offset + limit)
+	}
+
+	members := make([]map[string]any, 0, len(users))
+	for _, u := range users {
+		members = append(members, userResponse(u))
+	}
+
+	writeOK(w, map[string]any{
+		"members":           members,
+		"cache_ts":          0,
+		"response_metadata": map[string]any{"next_cursor": nextCursor},
+	})
+}
+
+// getUserInfo handles GET /api/users.info
+func (p *SlackPlugin) getUserInfo(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user")
+	if userID == "" {
+		writeError(w, "user_not_found")
+		return
+	}
+
+	user, err := p.store.GetUser(userID)
+	if err == sql.ErrNoRows {
+		writeError(w, "user_not_found")
+		return
+	}
+	if err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	writeOK(w, map[string]any{"user": userResponse(user)})
+}
+
+// uploadFile handles POST /api/files.upload. Only metadata is stored;
+// the uploaded bytes are counted and discarded.
+func (p *SlackPlugin) uploadFile(w http.ResponseWriter, r *http.Request) {
+	params, err := readParams(r)
+	if err != nil {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	file := &File{
+		Name:     params["filename"],
+		Title:    params["title"],
+		Filetype: params["filetype"],
+		UserID:   BotUserID,
+	}
+
+	if r.MultipartForm != nil {
+		if upload, header, err := r.FormFile("file"); err == nil {
+			size, _ := io.Copy(io.Discard, upload)
+			upload.Close()
+			file.Size = size
+			if file.Name == "" {
+				file.Name = header.Filename
+			}
+			file.Mimetype = header.Header.Get("Content-Type")
+		}
+	}
+	if file.Size == 0 && params["content"] != "" {
+		file.Size = int64(len(params["content"]))
+		if file.Mimetype == "" {
+			file.Mimetype = "text/plain"
+		}
+	}
+	if file.Size == 0 {
+		writeError(w, "no_file_data")
+		return
+	}
+
+	if file.Name == "" {
+		file.Name = "-.txt"
+	}
+	if file.Title == "" {
+		file.Title = file.Name
+	}
+	if file.Filetype == "" {
+		file.Filetype = strings.TrimPrefix(filepath.Ext(file.Name), ".")
+		if file.Filetype == "" {
+			file.Filetype = "text"
+		}
+	}
+
+	if channels := params["channels"]; channels != "" {
+		for _, name := range strings.Split(channels, ",") {
+			channel, err := p.store.GetChannel(strings.TrimSpace(name))
+			if err != nil {
+				writeError(w, "channel_not_found")
+				return
+			}
+			file.Channels = append(file.Channels, channel.ID)
+		}
+	}
+
+	if err := p.store.CreateFile(file); err != nil {
+		writeError(w, "internal_error")
+		return
+	}
+
+	writeOK(w, map[string]any{"file": fileResponse(file)})
+}
+
+func channelResponse(ch *Channel, numMembers int) map[string]any {
+	return map[string]any{
+		"id":          ch.ID,
+		"name":        ch.Name,
+		"is_channel":  !ch.IsPrivate,
+		"is_group":    false,
+		"is_im":       false,
+		"is_private":  ch.IsPrivate,
+		"is_archived": ch.IsArchived,
+		"is_member":   true,
+		"created":     ch.CreatedAt.Unix(),
+		"creator":     ch.Creator,
+		"topic":       map[string]any{"value": ch.Topic, "creator": ch.Creator, "last_set": ch.CreatedAt.Unix()},
+		"purpose":     map[string]any{"value": ch.Purpose, "creator": ch.Creator, "last_set": ch.CreatedAt.Unix()},
+		"num_members": numMembers,
+	}
+}
+
+func messageResponse(msg *Message) map[string]any {
+	m := map[string]any{
+		"type": "message",
+		"ts":   msg.TS,
+		"text": msg.Text,
+	}
+	if msg.UserID != "" {
+		m["user"] = msg.UserID
+	}
+	if msg.BotID != "" {
+		m["bot_id"] = msg.BotID
+	}
+	if msg.Username != "" {
+		m["username"] = msg.Username
+	}
+	if msg.ThreadTS != "" {
+		m["thread_ts"] = msg.ThreadTS
+	}
+	if len(msg.Reactions) > 0 {
+		m["reactions"] = msg.Reactions
+	}
+	return m
+}
+
+func userResponse(u *User) map[string]any {
+	return map[string]any{
+		"id":        u.ID,
+		"name":      u.Name,
+		"real_name": u.RealName,
+		"deleted":   u.Deleted,
+		"is_bot":    u.IsBot,
+		"is_admin":  u.IsAdmin,
+		"tz":        u.TZ,
+		"profile": map[string]any{
+			"real_name":    u.RealName,
+			"display_name": u.Name,
+			"email":        u.Email,
+			"title":        u.Title,
+		},
+		"updated": u.CreatedAt.Unix(),
+	}
+}
+
+func fileResponse(f *File) map[string]any {
+	channels := f.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+	return map[string]any{
+		"id":       f.ID,
+		"created":  f.CreatedAt.Unix(),
+		"name":     f.Name,
+		"title":    f.Title,
+		"filetype": f.Filetype,
+		"mimetype": f.Mimetype,
+		"size":     f.Size,
+		"user":     f.UserID,
+		"channels": channels,
+	}
+}
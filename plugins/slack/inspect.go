@@ -0,0 +1,50 @@
+// ABOUTME: core.Inspector implementation for the Slack plugin
+// ABOUTME: Looks up channels, users, messages, and files and renders them as the Web API does
+
+package slack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Inspect implements core.Inspector for `ish inspect`. Message IDs are
+// "<channel>:<ts>", as in the admin UI.
+func (p *SlackPlugin) Inspect(resource, id string) (interface{}, error) {
+	switch resource {
+	case "channels":
+		channel, err := p.store.GetChannel(id)
+		if err != nil {
+			return nil, err
+		}
+		members, err := p.store.CountChannelMembers(channel.ID)
+		if err != nil {
+			return nil, err
+		}
+		return channelResponse(channel, members), nil
+	case "users":
+		user, err := p.store.GetUser(id)
+		if err != nil {
+			return nil, err
+		}
+		return userResponse(user), nil
+	case "messages":
+		channelID, ts, ok := strings.Cut(id, ":")
+		if !ok {
+			return nil, fmt.Errorf("message ID must be in the form channel:ts")
+		}
+		msg, err := p.store.GetMessage(channelID, ts)
+		if err != nil {
+			return nil, err
+		}
+		return messageResponse(msg), nil
+	case "files":
+		file, err := p.store.GetFile(id)
+		if err != nil {
+			return nil, err
+		}
+		return fileResponse(file), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
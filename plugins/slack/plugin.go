@@ -0,0 +1,271 @@
+// ABOUTME: Slack Web API plugin for ISH
+// ABOUTME: Simulates Slack chat, conversations, reactions, users, and files methods
+
+package slack
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+type contextKey string
+
+const tokenKey contextKey = "slack_token"
+
+const (
+	// botTokenPrefix is the prefix Slack uses for bot (workspace) tokens
+	botTokenPrefix = "xoxb-"
+
+	// BotUserID and BotID identify the app that posts messages via the API
+	BotUserID = "UISHBOT0001"
+	BotID     = "BISHBOT0001"
+)
+
+func init() {
+	core.Register(&SlackPlugin{})
+}
+
+type SlackPlugin struct {
+	store *SlackStore
+}
+
+func (p *SlackPlugin) Name() string {
+	return "slack"
+}
+
+func (p *SlackPlugin) Health() core.HealthStatus {
+	return core.HealthStatus{
+		Status:  "healthy",
+		Message: "Slack plugin operational",
+	}
+}
+
+func (p *SlackPlugin) RegisterRoutes(r chi.Router) {
+	// Slack Web API methods
+	r.Post("/api/chat.postMessage", p.requireAuth(p.postMessage))
+	r.Get("/api/conversations.list", p.requireAuth(p.listConversations))
+	r.Get("/api/conversations.history", p.requireAuth(p.conversationHistory))
+	r.Post("/api/reactions.add", p.requireAuth(p.addReaction))
+	r.Get("/api/users.list", p.requireAuth(p.listUsers))
+	r.Get("/api/users.info", p.requireAuth(p.getUserInfo))
+	r.Post("/api/files.upload", p.requireAuth(p.uploadFile))
+
+	// Admin simulation endpoints
+	r.Post("/admin/slack/simulate/message", p.simulateMessage)
+}
+
+func (p *SlackPlugin) RegisterAuth(r chi.Router) {
+	// Slack uses workspace bot tokens (xoxb-) via Bearer header or token param
+}
+
+func (p *SlackPlugin) ValidateToken(token string) bool {
+	return strings.HasPrefix(token, botTokenPrefix) && len(token) > len(botTokenPrefix)
+}
+
+func (p *SlackPlugin) SetDB(db *sql.DB) error {
+	store, err := NewSlackStore(db)
+	if err != nil {
+		return err
+	}
+	p.store = store
+	return nil
+}
+
+// extractToken reads the token from the Authorization header, falling back
+// to the "token" form/query parameter that older Slack clients send
+func extractToken(r *http.Request) string {
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+	}
+	return r.FormValue("token")
+}
+
+// requireAuth middleware validates the Slack bot token
+func (p *SlackPlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := extractToken(r)
+		if token == "" {
+			writeError(w, "not_authed")
+			return
+		}
+		if !p.ValidateToken(token) {
+			writeError(w, "invalid_auth")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), tokenKey, token)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// writeOK writes a Slack success envelope merged with the given fields
+func writeOK(w http.ResponseWriter, fields map[string]any) {
+	resp := map[string]any{"ok": true}
+	for k, v := range fields {
+		resp[k] = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Slack: Failed to encode response: %v", err)
+	}
+}
+
+// writeError writes a Slack error envelope. Slack reports method errors
+// with HTTP 200 and ok=false rather than an HTTP error status.
+func writeError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"ok":    false,
+		"error": code,
+	}); err != nil {
+		log.Printf("Slack: Failed to encode error response: %v", err)
+	}
+}
+
+// ListResources implements core.DataProvider to expose data to admin UI
+func (p *SlackPlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
+	switch slug {
+	case "channels":
+		channels, err := p.store.ListChannels(false, opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(channels))
+		for _, ch := range channels {
+			result = append(result, convertChannelToMap(ch))
+		}
+		return result, nil
+	case "users":
+		users, err := p.store.ListUsers(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(users))
+		for _, u := range users {
+			result = append(result, convertUserToMap(u))
+		}
+		return result, nil
+	case "messages":
+		messages, err := p.store.ListAllMessages(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(messages))
+		for _, msg := range messages {
+			result = append(result, convertMessageToMap(msg))
+		}
+		return result, nil
+	case "files":
+		files, err := p.store.ListAllFiles(opts.Limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(files))
+		for _, f := range files {
+			result = append(result, convertFileToMap(f))
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+// GetResource implements core.DataProvider to fetch individual resources
+func (p *SlackPlugin) GetResource(ctx context.Context, slug string, id string) (map[string]interface{}, error) {
+	switch slug {
+	case "channels":
+		channel, err := p.store.GetChannel(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertChannelToMap(channel), nil
+	case "users":
+		user, err := p.store.GetUser(id)
+		if err != nil {
+			return nil, err
+		}
+		return convertUserToMap(user), nil
+	case "messages":
+		// Message IDs are "<channel>:<ts>" since ts is only unique per channel
+		channelID, ts, ok := strings.Cut(id, ":")
+		if !ok {
+			return nil, fmt.Errorf("message ID must be in the form channel:ts")
+		}
+		msg, err := p.store.GetMessage(channelID, ts)
+		if err != nil {
+			return nil, err
+		}
+		return convertMessageToMap(msg), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+func convertChannelToMap(ch *Channel) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          ch.ID,
+		"name":        ch.Name,
+		"topic":       ch.Topic,
+		"purpose":     ch.Purpose,
+		"is_private":  fmt.Sprintf("%t", ch.IsPrivate),
+		"is_archived": fmt.Sprintf("%t", ch.IsArchived),
+		"created_at":  ch.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func convertUserToMap(u *User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         u.ID,
+		"name":       u.Name,
+		"real_name":  u.RealName,
+		"email":      u.Email,
+		"title":      u.Title,
+		"is_bot":     fmt.Sprintf("%t", u.IsBot),
+		"created_at": u.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func convertMessageToMap(msg *Message) map[string]interface{} {
+	m := map[string]interface{}{
+		"id":         msg.ChannelID + ":" + msg.TS,
+		"channel_id": msg.ChannelID,
+		"ts":         msg.TS,
+		"user_id":    msg.UserID,
+		"text":       msg.Text,
+		"created_at": msg.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if msg.ThreadTS != "" {
+		m["thread_ts"] = msg.ThreadTS
+	}
+	if msg.BotID != "" {
+		m["bot_id"] = msg.BotID
+	}
+	if len(msg.Reactions) > 0 {
+		names := make([]string, 0, len(msg.Reactions))
+		for _, r := range msg.Reactions {
+			names = append(names, fmt.Sprintf(":%s: %d", r.Name, r.Count))
+		}
+		m["reactions"] = strings.Join(names, " ")
+	}
+	return m
+}
+
+func convertFileToMap(f *File) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         f.ID,
+		"name":       f.Name,
+		"title":      f.Title,
+		"filetype":   f.Filetype,
+		"size":       fmt.Sprintf("%d", f.Size),
+		"channels":   strings.Join(f.Channels, ","),
+		"created_at": f.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -0,0 +1,106 @@
+// ABOUTME: Admin UI schema definitions for Slack plugin
+// ABOUTME: Defines Channels, Users, Messages, and Files resources for schema-driven UI
+
+package slack
+
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func (p *SlackPlugin) Schema() core.PluginSchema {
+	return core.PluginSchema{
+		Resources: []core.ResourceSchema{
+			{
+				Name:        "Channels",
+				Slug:        "channels",
+				ListColumns: []string{"id", "name", "topic", "is_private", "created_at"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Name", Required: true, Editable: false},
+					{Name: "topic", Type: "string", Display: "Topic", Required: false, Editable: false},
+					{Name: "purpose", Type: "text", Display: "Purpose", Required: false, Editable: false},
+					{Name: "is_private", Type: "string", Display: "Private", Required: false, Editable: false},
+					{Name: "is_archived", Type: "string", Display: "Archived", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Users",
+				Slug:        "users",
+				ListColumns: []string{"id", "name", "real_name", "email", "is_bot"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Username", Required: true, Editable: false},
+					{Name: "real_name", Type: "string", Display: "Real Name", Required: false, Editable: false},
+					{Name: "email", Type: "email", Display: "Email", Required: false, Editable: false},
+					{Name: "title", Type: "string", Display: "Title", Required: false, Editable: false},
+					{Name: "is_bot", Type: "string", Display: "Bot", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Messages",
+				Slug:        "messages",
+				ListColumns: []string{"channel_id", "ts", "user_id", "text", "created_at"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "channel_id", Type: "string", Display: "Channel", Required: true, Editable: false},
+					{Name: "ts", Type: "string", Display: "Timestamp", Required: true, Editable: false},
+					{Name: "thread_ts", Type: "string", Display: "Thread", Required: false, Editable: false},
+					{Name: "user_id", Type: "string", Display: "User", Required: false, Editable: false},
+					{Name: "bot_id", Type: "string", Display: "Bot", Required: false, Editable: false},
+					{Name: "text", Type: "text", Display: "Text", Required: false, Editable: false},
+					{Name: "reactions", Type: "string", Display: "Reactions", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Files",
+				Slug:        "files",
+				ListColumns: []string{"id", "name", "filetype", "size", "created_at"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Name", Required: true, Editable: false},
+					{Name: "title", Type: "string", Display: "Title", Required: false, Editable: false},
+					{Name: "filetype", Type: "string", Display: "Type", Required: false, Editable: false},
+					{Name: "size", Type: "string", Display: "Size (bytes)", Required: false, Editable: false},
+					{Name: "channels", Type: "string", Display: "Channels", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+		},
+	}
+}
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *SlackPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "slack", Table: "slack_messages", Resource: "messages"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *SlackPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"slack": {"slack_files", "slack_messages", "slack_channels", "slack_users"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *SlackPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *SlackPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
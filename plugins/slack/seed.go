@@ -0,0 +1,66 @@
+// ABOUTME: Test data generation for Slack plugin
+// ABOUTME: Creates a bot user, workspace members, channels, and a few messages
+
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// Seed creates test data for the Slack plugin
+func (p *SlackPlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
+	users := []*User{
+		{ID: BotUserID, Name: "ish-bot", RealName: "ISH Bot", IsBot: true},
+		{Name: "harper", RealName: "Harper Reed", Email: "harper@example.com", Title: "CEO", IsAdmin: true},
+		{Name: "alice", RealName: "Alice Johnson", Email: "alice@example.com", Title: "Engineer"},
+		{Name: "bob", RealName: "Bob Smith", Email: "bob@example.com", Title: "Designer"},
+		{Name: "carol", RealName: "Carol Davis", Email: "carol@example.com", Title: "Product Manager"},
+	}
+	for _, u := range users {
+		if err := p.store.CreateUser(u); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create user %s: %w", u.Name, err)
+		}
+	}
+
+	channels := []*Channel{
+		{Name: "general", Topic: "Company-wide announcements", Purpose: "This channel is for workspace-wide communication", Creator: users[1].ID},
+		{Name: "random", Topic: "Non-work banter", Purpose: "A place for everything else", Creator: users[1].ID},
+		{Name: "engineering", Topic: "Builds, deploys, and incidents", Purpose: "Engineering discussion", Creator: users[2].ID},
+		{Name: "leadership", Topic: "Planning", Purpose: "Private leadership discussion", IsPrivate: true, Creator: users[1].ID},
+	}
+	for _, ch := range channels {
+		if err := p.store.CreateChannel(ch); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create channel %s: %w", ch.Name, err)
+		}
+	}
+
+	messages := []struct {
+		channel *Channel
+		user    *User
+		text    string
+	}{
+		{channels[0], users[1], "Welcome to the workspace, everyone!"},
+		{channels[0], users[2], "Thanks Harper, excited to be here."},
+		{channels[1], users[3], "Anyone up for lunch at noon?"},
+		{channels[2], users[2], "Deploy to staging is green :white_check_mark:"},
+		{channels[2], users[4], "Can we get the release notes drafted by Friday?"},
+	}
+	for _, m := range messages {
+		msg := &Message{ChannelID: m.channel.ID, UserID: m.user.ID, Text: m.text}
+		if err := p.store.CreateMessage(msg); err != nil {
+			return core.SeedData{}, fmt.Errorf("failed to create message: %w", err)
+		}
+	}
+
+	return core.SeedData{
+		Summary: fmt.Sprintf("Created %d users, %d channels, %d messages", len(users), len(channels), len(messages)),
+		Records: map[string]int{
+			"users":    len(users),
+			"channels": len(channels),
+			"messages": len(messages),
+		},
+	}, nil
+}
@@ -0,0 +1,500 @@
+// ABOUTME: Database layer for Slack plugin
+// ABOUTME: Manages slack_channels, slack_users, slack_messages, and slack_files tables
+
+package slack
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+type SlackStore struct {
+	db *sql.DB
+}
+
+// auditIn records a mutation of a Slack resource in the shared audit log
+// through db, the transaction making the change so both commit together
+func auditIn(db core.Execer, resourceType, resourceID, action, actor string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "slack",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
+func NewSlackStore(db *sql.DB) (*SlackStore, error) {
+	return &SlackStore{db: db}, nil
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *SlackStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type User struct {
+	ID        string
+	Name      string
+	RealName  string
+	Email     string
+	Title     string
+	IsBot     bool
+	IsAdmin   bool
+	Deleted   bool
+	TZ        string
+	CreatedAt time.Time
+}
+
+type Channel struct {
+	ID         string
+	Name       string
+	Topic      string
+	Purpose    string
+	IsPrivate  bool
+	IsArchived bool
+	Creator    string
+	CreatedAt  time.Time
+}
+
+type Reaction struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+	Count int      `json:"count"`
+}
+
+type Message struct {
+	ChannelID string
+	TS        string
+	ThreadTS  string
+	UserID    string
+	BotID     string
+	Username  string
+	Text      string
+	Reactions []Reaction
+	CreatedAt time.Time
+}
+
+type File struct {
+	ID        string
+	Name      string
+	Title     string
+	Filetype  string
+	Mimetype  string
+	Size      int64
+	UserID    string
+	Channels  []string
+	CreatedAt time.Time
+}
+
+const idAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// generateID creates a Slack-style ID such as C024BE91L or U0G9QF9C6
+func generateID(prefix string) string {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%s%d", prefix, time.Now().UnixNano())
+	}
+	for i := range b {
+		b[i] = idAlphabet[int(b[i])%len(idAlphabet)]
+	}
+	return prefix + string(b)
+}
+
+// formatTS converts a time to a Slack message timestamp ("1700000000.000100")
+func formatTS(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/1000)
+}
+
+func (s *SlackStore) CreateUser(user *User) error {
+	if user.ID == "" {
+		user.ID = generateID("U")
+	}
+	if user.TZ == "" {
+		user.TZ = "America/Los_Angeles"
+	}
+	user.CreatedAt = time.Now()
+
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO slack_users (id, name, real_name, email, title, is_bot, is_admin, tz, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			user.ID, user.Name, user.RealName, user.Email, user.Title, user.IsBot, user.IsAdmin, user.TZ, user.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "user", user.ID, core.AuditCreate, "", map[string]any{"name": user.Name, "email": user.Email})
+	})
+}
+
+func (s *SlackStore) GetUser(id string) (*User, error) {
+	row := s.db.QueryRow(`SELECT id, name, real_name, email, title, is_bot, is_admin, deleted, tz, created_at
+		FROM slack_users WHERE id = ?`, id)
+	return scanUser(row)
+}
+
+func (s *SlackStore) ListUsers(limit, offset int) ([]*User, error) {
+	rows, err := s.db.Query(`SELECT id, name, real_name, email, title, is_bot, is_admin, deleted, tz, created_at
+		FROM slack_users ORDER BY created_at ASC, id ASC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row scanner) (*User, error) {
+	user := &User{}
+	var realName, email, title, tz sql.NullString
+	err := row.Scan(&user.ID, &user.Name, &realName, &email, &title,
+		&user.IsBot, &user.IsAdmin, &user.Deleted, &tz, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	user.RealName = realName.String
+	user.Email = email.String
+	user.Title = title.String
+	user.TZ = tz.String
+	return user, nil
+}
+
+func (s *SlackStore) CreateChannel(channel *Channel) error {
+	if channel.ID == "" {
+		channel.ID = generateID("C")
+	}
+	channel.CreatedAt = time.Now()
+
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO slack_channels (id, name, topic, purpose, is_private, is_archived, creator, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			channel.ID, channel.Name, channel.Topic, channel.Purpose, channel.IsPrivate, channel.IsArchived, channel.Creator, channel.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "channel", channel.ID, core.AuditCreate, channel.Creator, map[string]any{"name": channel.Name, "is_private": channel.IsPrivate})
+	})
+}
+
+// GetChannel looks up a channel by ID, or by name when given "#name" or a bare name
+func (s *SlackStore) GetChannel(idOrName string) (*Channel, error) {
+	name := strings.TrimPrefix(idOrName, "#")
+	row := s.db.QueryRow(`SELECT id, name, topic, purpose, is_private, is_archived, creator, created_at
+		FROM slack_channels WHERE id = ? OR name = ?`, idOrName, name)
+	return scanChannel(row)
+}
+
+func (s *SlackStore) ListChannels(excludeArchived bool, limit, offset int) ([]*Channel, error) {
+	query := `SELECT id, name, topic, purpose, is_private, is_archived, creator, created_at FROM slack_channels`
+	if excludeArchived {
+		query += ` WHERE is_archived = 0`
+	}
+	query += ` ORDER BY created_at ASC, id ASC LIMIT ? OFFSET ?`
+
+	rows, err := s.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []*Channel
+	for rows.Next() {
+		channel, err := scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	return channels, rows.Err()
+}
+
+func scanChannel(row scanner) (*Channel, error) {
+	channel := &Channel{}
+	var topic, purpose, creator sql.NullString
+	err := row.Scan(&channel.ID, &channel.Name, &topic, &purpose,
+		&channel.IsPrivate, &channel.IsArchived, &creator, &channel.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	channel.Topic = topic.String
+	channel.Purpose = purpose.String
+	channel.Creator = creator.String
+	return channel, nil
+}
+
+// CountChannelMembers returns the number of distinct users who have posted in a channel
+func (s *SlackStore) CountChannelMembers(channelID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(DISTINCT user_id) FROM slack_messages WHERE channel_id = ? AND user_id != ''`, channelID).Scan(&count)
+	return count, err
+}
+
+func (s *SlackStore) CreateMessage(msg *Message) error {
+	if msg.TS == "" {
+		msg.TS = formatTS(time.Now())
+	}
+	msg.CreatedAt = time.Now()
+
+	// Two messages in the same channel cannot share a ts; bump the
+	// microsecond component until the insert succeeds.
+	for attempt := 0; attempt < 10; attempt++ {
+		err := s.withTx(func(tx *sql.Tx) error {
+			_, err := tx.Exec(`INSERT INTO slack_messages (channel_id, ts, thread_ts, user_id, bot_id, username, text, reactions, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				msg.ChannelID, msg.TS, msg.ThreadTS, msg.UserID, msg.BotID, msg.Username, msg.Text, "[]", msg.CreatedAt)
+			if err != nil {
+				return err
+			}
+			return auditIn(tx, "message", msg.ChannelID+"/"+msg.TS, core.AuditCreate, msg.UserID, map[string]any{"thread_ts": msg.ThreadTS, "text": msg.Text})
+		})
+		if err == nil {
+			return nil
+		}
+		if !strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return err
+		}
+		msg.TS = bumpTS(msg.TS)
+	}
+	return fmt.Errorf("failed to allocate unique message ts in channel %s", msg.ChannelID)
+}
+
+func bumpTS(ts string) string {
+	var sec, usec int64
+	fmt.Sscanf(ts, "%d.%d", &sec, &usec)
+	usec++
+	if usec >= 1000000 {
+		sec++
+		usec = 0
+	}
+	return fmt.Sprintf("%d.%06d", sec, usec)
+}
+
+func (s *SlackStore) GetMessage(channelID, ts string) (*Message, error) {
+	row := s.db.QueryRow(`SELECT channel_id, ts, thread_ts, user_id, bot_id, username, text, reactions, created_at
+		FROM slack_messages WHERE channel_id = ? AND ts = ?`, channelID, ts)
+	return scanMessage(row)
+}
+
+// ListMessages returns messages in a channel newest first, bounded by the
+// optional oldest/latest timestamps (exclusive, matching Slack's default)
+func (s *SlackStore) ListMessages(channelID, oldest, latest string, limit, offset int) ([]*Message, error) {
+	query := `SELECT channel_id, ts, thread_ts, user_id, bot_id, username, text, reactions, created_at
+		FROM slack_messages WHERE channel_id = ?`
+	args := []any{channelID}
+	if oldest != "" {
+		query += ` AND CAST(ts AS REAL) > CAST(? AS REAL)`
+		args = append(args, oldest)
+	}
+	if latest != "" {
+		query += ` AND CAST(ts AS REAL) < CAST(? AS REAL)`
+		args = append(args, latest)
+	}
+	query += ` ORDER BY CAST(ts AS REAL) DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// ListAllMessages retrieves messages across all channels for admin view
+func (s *SlackStore) ListAllMessages(limit, offset int) ([]*Message, error) {
+	rows, err := s.db.Query(`SELECT channel_id, ts, thread_ts, user_id, bot_id, username, text, reactions, created_at
+		FROM slack_messages ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+func scanMessage(row scanner) (*Message, error) {
+	msg := &Message{}
+	var threadTS, userID, botID, username, text, reactions sql.NullString
+	err := row.Scan(&msg.ChannelID, &msg.TS, &threadTS, &userID, &botID, &username, &text, &reactions, &msg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	msg.ThreadTS = threadTS.String
+	msg.UserID = userID.String
+	msg.BotID = botID.String
+	msg.Username = username.String
+	msg.Text = text.String
+	if reactions.Valid && reactions.String != "" {
+		if err := json.Unmarshal([]byte(reactions.String), &msg.Reactions); err != nil {
+			return nil, fmt.Errorf("invalid reactions JSON: %w", err)
+		}
+	}
+	return msg, nil
+}
+
+// ErrAlreadyReacted is returned when a user adds the same reaction twice
+var ErrAlreadyReacted = errors.New("already_reacted")
+
+// AddReaction records a reaction by userID on the message at channelID/ts
+func (s *SlackStore) AddReaction(channelID, ts, name, userID string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		return addReaction(tx, channelID, ts, name, userID)
+	})
+}
+
+// addReaction reads and rewrites the message's reactions within tx, so
+// concurrent reactions can't overwrite each other
+func addReaction(tx *sql.Tx, channelID, ts, name, userID string) error {
+	msg, err := scanMessage(tx.QueryRow(`SELECT channel_id, ts, thread_ts, user_id, bot_id, username, text, reactions, created_at
+		FROM slack_messages WHERE channel_id = ? AND ts = ?`, channelID, ts))
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range msg.Reactions {
+		if msg.Reactions[i].Name != name {
+			continue
+		}
+		for _, u := range msg.Reactions[i].Users {
+			if u == userID {
+				return ErrAlreadyReacted
+			}
+		}
+		msg.Reactions[i].Users = append(msg.Reactions[i].Users, userID)
+		msg.Reactions[i].Count = len(msg.Reactions[i].Users)
+		found = true
+		break
+	}
+	if !found {
+		msg.Reactions = append(msg.Reactions, Reaction{Name: name, Users: []string{userID}, Count: 1})
+	}
+
+	reactionsJSON, err := json.Marshal(msg.Reactions)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE slack_messages SET reactions = ? WHERE channel_id = ? AND ts = ?`, string(reactionsJSON), channelID, ts)
+	if err != nil {
+		return err
+	}
+	return auditIn(tx, "message", channelID+"/"+ts, core.AuditUpdate, userID, map[string]any{"reaction_added": name})
+}
+
+func (s *SlackStore) CreateFile(file *File) error {
+	if file.ID == "" {
+		file.ID = generateID("F")
+	}
+	file.CreatedAt = time.Now()
+
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO slack_files (id, name, title, filetype, mimetype, size, user_id, channels, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			file.ID, file.Name, file.Title, file.Filetype, file.Mimetype, file.Size, file.UserID, strings.Join(file.Channels, ","), file.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "file", file.ID, core.AuditCreate, file.UserID, map[string]any{"name": file.Name, "channels": file.Channels})
+	})
+}
+
+// GetFile retrieves one uploaded file's metadata
+func (s *SlackStore) GetFile(id string) (*File, error) {
+	file := &File{}
+	var title, filetype, mimetype, userID, channels sql.NullString
+	err := s.db.QueryRow(`SELECT id, name, title, filetype, mimetype, size, user_id, channels, created_at
+		FROM slack_files WHERE id = ?`, id).Scan(&file.ID, &file.Name, &title, &filetype, &mimetype, &file.Size, &userID, &channels, &file.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	file.Title = title.String
+	file.Filetype = filetype.String
+	file.Mimetype = mimetype.String
+	file.UserID = userID.String
+	if channels.String != "" {
+		file.Channels = strings.Split(channels.String, ",")
+	}
+	return file, nil
+}
+
+// ListAllFiles retrieves uploaded file metadata for admin view
+func (s *SlackStore) ListAllFiles(limit, offset int) ([]*File, error) {
+	rows, err := s.db.Query(`SELECT id, name, title, filetype, mimetype, size, user_id, channels, created_at
+		FROM slack_files ORDER BY created_at DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*File
+	for rows.Next() {
+		file := &File{}
+		var title, filetype, mimetype, userID, channels sql.NullString
+		if err := rows.Scan(&file.ID, &file.Name, &title, &filetype, &mimetype, &file.Size, &userID, &channels, &file.CreatedAt); err != nil {
+			return nil, err
+		}
+		file.Title = title.String
+		file.Filetype = filetype.String
+		file.Mimetype = mimetype.String
+		file.UserID = userID.String
+		if channels.String != "" {
+			file.Channels = strings.Split(channels.String, ",")
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *SlackStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db,
+		"slack_users",
+		"slack_channels",
+		"slack_messages",
+		"slack_files",
+	)
+}
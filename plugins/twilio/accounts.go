@@ -0,0 +1,173 @@
+// ABOUTME: Accounts API for Twilio plugin
+// ABOUTME: Creates subaccounts and updates account names and status (active/suspended/closed)
+
+package twilio
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var accountStatuses = map[string]bool{
+	AccountStatusActive:    true,
+	AccountStatusSuspended: true,
+	AccountStatusClosed:    true,
+}
+
+func accountToResponse(acct *Account) map[string]interface{} {
+	// Twilio reports a main account as its own owner
+	owner := acct.OwnerAccountSid
+	if owner == "" {
+		owner = acct.AccountSid
+	}
+	uri := "/2010-04-01/Accounts/" + acct.AccountSid
+	return map[string]interface{}{
+		"sid":               acct.AccountSid,
+		"owner_account_sid": owner,
+		"auth_token":        acct.AuthToken,
+		"friendly_name":     acct.FriendlyName,
+		"status":            acct.Status,
+		"type":              "Full",
+		"date_created":      acct.CreatedAt.Format(time.RFC1123Z),
+		"date_updated":      acct.UpdatedAt.Format(time.RFC1123Z),
+		"uri":               uri + ".json",
+		"subresource_uris": map[string]string{
+			"messages":               uri + "/Messages.json",
+			"calls":                  uri + "/Calls.json",
+			"incoming_phone_numbers": uri + "/IncomingPhoneNumbers.json",
+		},
+	}
+}
+
+// createAccount handles POST /2010-04-01/Accounts.json, creating a subaccount
+// of the authenticated account
+func (p *TwilioPlugin) createAccount(w http.ResponseWriter, r *http.Request) {
+	accountSid := r.Context().Value(accountSidKey).(string)
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, 21602, "Invalid form body")
+		return
+	}
+
+	owner, err := p.store.GetAccount(accountSid)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+	if owner.OwnerAccountSid != "" {
+		writeError(w, http.StatusForbidden, 20403, "Subaccounts cannot create subaccounts")
+		return
+	}
+
+	friendlyName := r.FormValue("FriendlyName")
+	if friendlyName == "" {
+		friendlyName = "SubAccount Created at " + time.Now().UTC().Format("2006-01-02 03:04 pm")
+	}
+
+	account, err := p.store.CreateSubaccount(accountSid, friendlyName)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	writeTwilioResource(w, r, http.StatusCreated, "Account", accountToResponse(account))
+}
+
+// listAccounts handles GET /2010-04-01/Accounts.json, returning the
+// authenticated account and its subaccounts
+func (p *TwilioPlugin) listAccounts(w http.ResponseWriter, r *http.Request) {
+	accountSid := r.Context().Value(accountSidKey).(string)
+
+	accounts, err := p.store.ListOwnedAccounts(accountSid)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	responseAccounts := make([]map[string]interface{}, len(accounts))
+	for i := range accounts {
+		responseAccounts[i] = accountToResponse(&accounts[i])
+	}
+
+	writeTwilioList(w, r, twilioList{
+		Key:     "accounts",
+		Element: "Accounts",
+		Item:    "Account",
+		Items:   responseAccounts,
+		Meta:    map[string]interface{}{"page": 0, "page_size": len(responseAccounts)},
+	})
+}
+
+// visibleAccount returns the account named in the URL if the caller is that
+// account or its owner
+func (p *TwilioPlugin) visibleAccount(r *http.Request) (*Account, bool) {
+	callerSid := r.Context().Value(accountSidKey).(string)
+	account, err := p.store.GetAccount(chi.URLParam(r, "AccountSid"))
+	if err != nil {
+		return nil, false
+	}
+	if account.AccountSid != callerSid && account.OwnerAccountSid != callerSid {
+		return nil, false
+	}
+	return account, true
+}
+
+// getAccount handles GET /2010-04-01/Accounts/{AccountSid}.json
+func (p *TwilioPlugin) getAccount(w http.ResponseWriter, r *http.Request) {
+	account, ok := p.visibleAccount(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, 20404, "Account not found")
+		return
+	}
+
+	writeTwilioResource(w, r, http.StatusOK, "Account", accountToResponse(account))
+}
+
+// updateAccount handles POST /2010-04-01/Accounts/{AccountSid}.json. Only the
+// parent account may change a subaccount's Status; closing is permanent.
+func (p *TwilioPlugin) updateAccount(w http.ResponseWriter, r *http.Request) {
+	callerSid := r.Context().Value(accountSidKey).(string)
+
+	account, ok := p.visibleAccount(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, 20404, "Account not found")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, 21602, "Invalid form body")
+		return
+	}
+
+	status := r.FormValue("Status")
+	if status != "" {
+		if !accountStatuses[status] {
+			writeError(w, http.StatusBadRequest, 20001, "Status must be active, suspended, or closed")
+			return
+		}
+		if account.OwnerAccountSid != callerSid {
+			writeError(w, http.StatusForbidden, 20403, "Only the parent account can change a subaccount's status")
+			return
+		}
+	}
+
+	updated, err := p.store.UpdateAccount(account.AccountSid, r.FormValue("FriendlyName"), status)
+	if errors.Is(err, ErrAccountClosed) {
+		writeError(w, http.StatusBadRequest, 20001, "Closed accounts cannot be modified")
+		return
+	}
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, 20404, "Account not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	writeTwilioResource(w, r, http.StatusOK, "Account", accountToResponse(updated))
+}
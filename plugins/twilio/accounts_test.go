@@ -0,0 +1,143 @@
+// ABOUTME: Tests for the Twilio Accounts API
+// ABOUTME: Covers subaccount creation and how status changes affect credential validation
+
+package twilio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func setupAccountsRouter(t *testing.T) (*TwilioStore, *Account, chi.Router) {
+	t.Helper()
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	store, err := NewTwilioStore(db)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	plugin := &TwilioPlugin{store: store}
+
+	main, err := store.GetOrCreateAccount("AC123")
+	if err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+	return store, main, r
+}
+
+func accountRequest(r http.Handler, method, path string, acct *Account, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", basicAuth(acct.AccountSid, acct.AuthToken))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func createSubaccount(t *testing.T, r http.Handler, owner *Account) *Account {
+	t.Helper()
+	w := accountRequest(r, "POST", "/2010-04-01/Accounts.json", owner, url.Values{"FriendlyName": {"Customer A"}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Sid             string `json:"sid"`
+		AuthToken       string `json:"auth_token"`
+		OwnerAccountSid string `json:"owner_account_sid"`
+		FriendlyName    string `json:"friendly_name"`
+		Status          string `json:"status"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !strings.HasPrefix(resp.Sid, "AC") || resp.AuthToken == "" {
+		t.Fatalf("Expected a new SID and auth token, got %+v", resp)
+	}
+	if resp.OwnerAccountSid != owner.AccountSid || resp.FriendlyName != "Customer A" || resp.Status != "active" {
+		t.Errorf("Unexpected subaccount: %+v", resp)
+	}
+	return &Account{AccountSid: resp.Sid, AuthToken: resp.AuthToken}
+}
+
+func TestSuspendedAccountFailsValidation(t *testing.T) {
+	store, main, r := setupAccountsRouter(t)
+	sub := createSubaccount(t, r, main)
+
+	if !store.ValidateAccount(sub.AccountSid, sub.AuthToken) {
+		t.Fatal("Expected new subaccount credentials to validate")
+	}
+
+	w := accountRequest(r, "POST", "/2010-04-01/Accounts/"+sub.AccountSid+".json", main, url.Values{"Status": {"suspended"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 suspending, got %d: %s", w.Code, w.Body.String())
+	}
+	if store.ValidateAccount(sub.AccountSid, sub.AuthToken) {
+		t.Error("Expected suspended account credentials to fail validation")
+	}
+	if w := accountRequest(r, "GET", "/2010-04-01/Accounts/"+sub.AccountSid+"/Messages.json", sub, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 calling the API as a suspended account, got %d", w.Code)
+	}
+
+	// Reactivating restores access; closing is permanent
+	accountRequest(r, "POST", "/2010-04-01/Accounts/"+sub.AccountSid+".json", main, url.Values{"Status": {"active"}})
+	if !store.ValidateAccount(sub.AccountSid, sub.AuthToken) {
+		t.Error("Expected reactivated account credentials to validate")
+	}
+	accountRequest(r, "POST", "/2010-04-01/Accounts/"+sub.AccountSid+".json", main, url.Values{"Status": {"closed"}})
+	if store.ValidateAccount(sub.AccountSid, sub.AuthToken) {
+		t.Error("Expected closed account credentials to fail validation")
+	}
+	w = accountRequest(r, "POST", "/2010-04-01/Accounts/"+sub.AccountSid+".json", main, url.Values{"Status": {"active"}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 reopening a closed account, got %d", w.Code)
+	}
+}
+
+func TestAccountsAccessControl(t *testing.T) {
+	store, main, r := setupAccountsRouter(t)
+	sub := createSubaccount(t, r, main)
+	other, _ := store.GetOrCreateAccount("AC999")
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		caller   *Account
+		form     url.Values
+		wantCode int
+	}{
+		{"owner reads subaccount", "GET", "/2010-04-01/Accounts/" + sub.AccountSid + ".json", main, nil, http.StatusOK},
+		{"subaccount reads itself", "GET", "/2010-04-01/Accounts/" + sub.AccountSid + ".json", sub, nil, http.StatusOK},
+		{"unrelated account", "GET", "/2010-04-01/Accounts/" + sub.AccountSid + ".json", other, nil, http.StatusNotFound},
+		{"subaccount renames itself", "POST", "/2010-04-01/Accounts/" + sub.AccountSid + ".json", sub, url.Values{"FriendlyName": {"Renamed"}}, http.StatusOK},
+		{"subaccount changes own status", "POST", "/2010-04-01/Accounts/" + sub.AccountSid + ".json", sub, url.Values{"Status": {"suspended"}}, http.StatusForbidden},
+		{"invalid status", "POST", "/2010-04-01/Accounts/" + sub.AccountSid + ".json", main, url.Values{"Status": {"paused"}}, http.StatusBadRequest},
+		{"subaccount creates subaccount", "POST", "/2010-04-01/Accounts.json", sub, nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := accountRequest(r, tt.method, tt.path, tt.caller, tt.form); w.Code != tt.wantCode {
+				t.Errorf("Expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := accountRequest(r, "GET", "/2010-04-01/Accounts.json", main, nil)
+	var list struct {
+		Accounts []struct {
+			Sid string `json:"sid"`
+		} `json:"accounts"`
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Accounts) != 2 || list.Accounts[0].Sid != main.AccountSid || list.Accounts[1].Sid != sub.AccountSid {
+		t.Errorf("Expected the main account then its subaccount, got %+v", list.Accounts)
+	}
+}
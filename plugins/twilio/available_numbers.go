@@ -0,0 +1,298 @@
+// ABOUTME: AvailablePhoneNumbers search and IncomingPhoneNumbers provisioning for Twilio plugin
+// ABOUTME: Synthesizes North American numbers matching AreaCode/Contains filters and buys them into an account
+
+package twilio
+
+import (
+	"hash/fnv"
+	"maps"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// numberRegion is where an area code's numbers are located
+type numberRegion struct {
+	Locality  string
+	Region    string
+	Latitude  string
+	Longitude string
+}
+
+// availableNumberAreaCodes lists the area codes searched in each supported
+// country when no AreaCode is given, and where they are
+var availableNumberAreaCodes = map[string]map[string]numberRegion{
+	"US": {
+		"415": {"San Francisco", "CA", "37.774900", "-122.419400"},
+		"212": {"New York", "NY", "40.712800", "-74.006000"},
+		"312": {"Chicago", "IL", "41.878100", "-87.629800"},
+		"206": {"Seattle", "WA", "47.606200", "-122.332100"},
+		"512": {"Austin", "TX", "30.267200", "-97.743100"},
+		"617": {"Boston", "MA", "42.360100", "-71.058900"},
+	},
+	"CA": {
+		"416": {"Toronto", "ON", "43.653200", "-79.383200"},
+		"604": {"Vancouver", "BC", "49.282700", "-123.120700"},
+		"514": {"Montreal", "QC", "45.501700", "-73.567300"},
+	},
+}
+
+// Available number page sizes
+const (
+	defaultAvailableNumbers = 20
+	maxAvailableNumbers     = 50
+)
+
+// keypadDigits maps letters in a Contains pattern to phone keypad digits
+var keypadDigits = map[rune]byte{
+	'A': '2', 'B': '2', 'C': '2', 'D': '3', 'E': '3', 'F': '3',
+	'G': '4', 'H': '4', 'I': '4', 'J': '5', 'K': '5', 'L': '5',
+	'M': '6', 'N': '6', 'O': '6', 'P': '7', 'Q': '7', 'R': '7', 'S': '7',
+	'T': '8', 'U': '8', 'V': '8', 'W': '9', 'X': '9', 'Y': '9', 'Z': '9',
+}
+
+// containsPattern normalizes a Contains filter to digits and '*' wildcards,
+// converting letters to keypad digits. It reports false for anything else or
+// a pattern longer than a national number.
+func containsPattern(contains string) (string, bool) {
+	contains = strings.TrimPrefix(contains, "+1")
+	var pattern []byte
+	for _, c := range strings.ToUpper(contains) {
+		switch {
+		case c >= '0' && c <= '9', c == '*':
+			pattern = append(pattern, byte(c))
+		case keypadDigits[c] != 0:
+			pattern = append(pattern, keypadDigits[c])
+		default:
+			return "", false
+		}
+	}
+	return string(pattern), len(pattern) <= 10
+}
+
+// validAreaCode reports whether s is a three-digit North American area code
+func validAreaCode(s string) bool {
+	return len(s) == 3 && s[0] >= '2' && s[0] <= '9' && s[1] >= '0' && s[1] <= '9' && s[2] >= '0' && s[2] <= '9'
+}
+
+// synthesizeNumbers makes up to limit E.164 numbers in one of areaCodes whose
+// ten-digit national number contains pattern, skipping numbers taken reports
+// as in use. The same search always yields the same candidates.
+func synthesizeNumbers(areaCodes []string, pattern string, limit int, taken func(string) bool) []string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(areaCodes, ",") + "|" + pattern))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+
+	var numbers []string
+	seen := map[string]bool{}
+	for attempt := 0; attempt < limit*50 && len(numbers) < limit; attempt++ {
+		digits := []byte(areaCodes[rng.Intn(len(areaCodes))] + "0000000")
+		digits[3] = byte('2' + rng.Intn(8))
+		for i := 4; i < 10; i++ {
+			digits[i] = byte('0' + rng.Intn(10))
+		}
+
+		if pattern != "" {
+			offset := rng.Intn(10 - len(pattern) + 1)
+			fits := true
+			for i := 0; i < len(pattern) && fits; i++ {
+				c, pos := pattern[i], offset+i
+				switch {
+				case c == '*':
+				case pos < 3:
+					// The area code is fixed by the search
+					fits = digits[pos] == c
+				case pos == 3:
+					// Exchange codes can't start with 0 or 1
+					fits = c >= '2'
+					digits[pos] = c
+				default:
+					digits[pos] = c
+				}
+			}
+			if !fits {
+				continue
+			}
+		}
+
+		number := "+1" + string(digits)
+		if seen[number] || taken(number) {
+			continue
+		}
+		seen[number] = true
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// availableNumbers synthesizes numbers that no account has provisioned
+func (p *TwilioPlugin) availableNumbers(areaCodes []string, pattern string, limit int) ([]string, error) {
+	var lookupErr error
+	numbers := synthesizeNumbers(areaCodes, pattern, limit, func(number string) bool {
+		inUse, err := p.store.PhoneNumberInUse(number)
+		if err != nil {
+			lookupErr = err
+		}
+		return inUse
+	})
+	return numbers, lookupErr
+}
+
+// formatNANPNumber formats +14155550123 as (415) 555-0123
+func formatNANPNumber(number string) string {
+	digits := strings.TrimPrefix(number, "+1")
+	if len(digits) != 10 {
+		return number
+	}
+	return "(" + digits[:3] + ") " + digits[3:6] + "-" + digits[6:]
+}
+
+func availableNumberToResponse(number, country string) map[string]interface{} {
+	region := availableNumberAreaCodes[country][number[2:5]]
+	return map[string]interface{}{
+		"friendly_name":        formatNANPNumber(number),
+		"phone_number":         number,
+		"lata":                 nil,
+		"locality":             region.Locality,
+		"rate_center":          strings.ToUpper(region.Locality),
+		"latitude":             region.Latitude,
+		"longitude":            region.Longitude,
+		"region":               region.Region,
+		"postal_code":          nil,
+		"iso_country":          country,
+		"address_requirements": "none",
+		"beta":                 false,
+		"capabilities": map[string]interface{}{
+			"voice": true,
+			"SMS":   true,
+			"MMS":   true,
+			"fax":   false,
+		},
+	}
+}
+
+// listAvailableLocalNumbers handles GET
+// /2010-04-01/Accounts/{AccountSid}/AvailablePhoneNumbers/{CountryCode}/Local.json.
+// Numbers are made up on demand; AreaCode limits them to one area code and
+// Contains to numbers matching a pattern of digits, letters and * wildcards.
+func (p *TwilioPlugin) listAvailableLocalNumbers(w http.ResponseWriter, r *http.Request) {
+	country := strings.ToUpper(chi.URLParam(r, "CountryCode"))
+	defaults, ok := availableNumberAreaCodes[country]
+	if !ok {
+		writeError(w, http.StatusNotFound, 20404, "The requested resource was not found")
+		return
+	}
+
+	query := r.URL.Query()
+	var areaCodes []string
+	if areaCode := query.Get("AreaCode"); areaCode != "" {
+		if !validAreaCode(areaCode) {
+			writeError(w, http.StatusBadRequest, 21451, "Invalid area code")
+			return
+		}
+		areaCodes = []string{areaCode}
+	} else {
+		areaCodes = slices.Sorted(maps.Keys(defaults))
+	}
+
+	pattern, ok := containsPattern(query.Get("Contains"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, 21421, "Contains must be digits, letters and * wildcards")
+		return
+	}
+
+	limit := defaultAvailableNumbers
+	if size := query.Get("PageSize"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, 20001, "Invalid PageSize")
+			return
+		}
+		limit = min(n, maxAvailableNumbers)
+	}
+
+	numbers, err := p.availableNumbers(areaCodes, pattern, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	items := make([]map[string]interface{}, len(numbers))
+	for i, number := range numbers {
+		items[i] = availableNumberToResponse(number, country)
+	}
+	writeTwilioList(w, r, twilioList{
+		Key:     "available_phone_numbers",
+		Element: "AvailablePhoneNumbers",
+		Item:    "AvailablePhoneNumber",
+		Items:   items,
+		Meta:    map[string]interface{}{"uri": r.URL.RequestURI()},
+	})
+}
+
+// provisionPhoneNumber handles POST /2010-04-01/Accounts/{AccountSid}/IncomingPhoneNumbers.json,
+// buying PhoneNumber, or the first available number in AreaCode, into the account
+func (p *TwilioPlugin) provisionPhoneNumber(w http.ResponseWriter, r *http.Request) {
+	accountSid := r.Context().Value(accountSidKey).(string)
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, 21602, "Invalid form body")
+		return
+	}
+
+	number := r.FormValue("PhoneNumber")
+	switch {
+	case number != "":
+		digits := strings.TrimPrefix(number, "+1")
+		if !validatePhoneNumber(number) || !strings.HasPrefix(number, "+1") || len(digits) != 10 ||
+			!validAreaCode(digits[:3]) || digits[3] < '2' {
+			writeError(w, http.StatusBadRequest, 21421, "PhoneNumber is invalid")
+			return
+		}
+		inUse, err := p.store.PhoneNumberInUse(number)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+			return
+		}
+		if inUse {
+			writeError(w, http.StatusBadRequest, 21422, "PhoneNumber is not available")
+			return
+		}
+	case r.FormValue("AreaCode") != "":
+		areaCode := r.FormValue("AreaCode")
+		if !validAreaCode(areaCode) {
+			writeError(w, http.StatusBadRequest, 21451, "Invalid area code")
+			return
+		}
+		numbers, err := p.availableNumbers([]string{areaCode}, "", 1)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+			return
+		}
+		if len(numbers) == 0 {
+			writeError(w, http.StatusBadRequest, 21452, "No phone numbers found in area code "+areaCode)
+			return
+		}
+		number = numbers[0]
+	default:
+		writeError(w, http.StatusBadRequest, 21421, "A PhoneNumber or AreaCode is required")
+		return
+	}
+
+	friendlyName := r.FormValue("FriendlyName")
+	if friendlyName == "" {
+		friendlyName = formatNANPNumber(number)
+	}
+
+	pn, err := p.store.CreatePhoneNumber(accountSid, number, friendlyName)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	writeTwilioResource(w, r, http.StatusCreated, "IncomingPhoneNumber", phoneNumberToResponse(pn))
+}
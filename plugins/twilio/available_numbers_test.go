@@ -0,0 +1,109 @@
+// ABOUTME: Tests for the AvailablePhoneNumbers search and number provisioning
+// ABOUTME: Covers AreaCode/Contains filters and buying a returned number into the account
+
+package twilio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type availableNumbersPage struct {
+	AvailablePhoneNumbers []struct {
+		PhoneNumber  string `json:"phone_number"`
+		FriendlyName string `json:"friendly_name"`
+		Locality     string `json:"locality"`
+		IsoCountry   string `json:"iso_country"`
+	} `json:"available_phone_numbers"`
+}
+
+func searchAvailableNumbers(t *testing.T, r http.Handler, acct *Account, query string) availableNumbersPage {
+	t.Helper()
+	w := accountRequest(r, "GET", "/2010-04-01/Accounts/"+acct.AccountSid+"/AvailablePhoneNumbers/US/Local.json?"+query, acct, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var page availableNumbersPage
+	json.NewDecoder(w.Body).Decode(&page)
+	return page
+}
+
+func TestSearchAndProvisionPhoneNumber(t *testing.T) {
+	store, main, r := setupAccountsRouter(t)
+
+	page := searchAvailableNumbers(t, r, main, "AreaCode=415&PageSize=5")
+	if len(page.AvailablePhoneNumbers) != 5 {
+		t.Fatalf("Expected 5 numbers, got %d", len(page.AvailablePhoneNumbers))
+	}
+	for _, n := range page.AvailablePhoneNumbers {
+		if !strings.HasPrefix(n.PhoneNumber, "+1415") || n.Locality != "San Francisco" || n.IsoCountry != "US" {
+			t.Errorf("Expected a 415 San Francisco number, got %+v", n)
+		}
+	}
+	chosen := page.AvailablePhoneNumbers[2].PhoneNumber
+
+	w := accountRequest(r, "POST", "/2010-04-01/Accounts/"+main.AccountSid+"/IncomingPhoneNumbers.json", main,
+		url.Values{"PhoneNumber": {chosen}, "FriendlyName": {"Support line"}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var provisioned struct {
+		Sid          string `json:"sid"`
+		PhoneNumber  string `json:"phone_number"`
+		FriendlyName string `json:"friendly_name"`
+	}
+	json.NewDecoder(w.Body).Decode(&provisioned)
+	if provisioned.PhoneNumber != chosen || provisioned.FriendlyName != "Support line" || !strings.HasPrefix(provisioned.Sid, "PN") {
+		t.Fatalf("Unexpected provisioned number: %+v", provisioned)
+	}
+
+	numbers, _ := store.ListPhoneNumbers(main.AccountSid)
+	if len(numbers) != 1 || numbers[0].PhoneNumber != chosen {
+		t.Fatalf("Expected %s in the account's numbers, got %+v", chosen, numbers)
+	}
+
+	// A provisioned number is no longer available
+	for _, n := range searchAvailableNumbers(t, r, main, "AreaCode=415&PageSize=5").AvailablePhoneNumbers {
+		if n.PhoneNumber == chosen {
+			t.Errorf("Expected %s to drop out of the search once provisioned", chosen)
+		}
+	}
+	w = accountRequest(r, "POST", "/2010-04-01/Accounts/"+main.AccountSid+"/IncomingPhoneNumbers.json", main,
+		url.Values{"PhoneNumber": {chosen}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 provisioning a taken number, got %d", w.Code)
+	}
+}
+
+func TestSearchAvailableNumbersContains(t *testing.T) {
+	_, main, r := setupAccountsRouter(t)
+
+	page := searchAvailableNumbers(t, r, main, "AreaCode=212&Contains=555*&PageSize=3")
+	if len(page.AvailablePhoneNumbers) != 3 {
+		t.Fatalf("Expected 3 numbers, got %d", len(page.AvailablePhoneNumbers))
+	}
+	for _, n := range page.AvailablePhoneNumbers {
+		if !strings.HasPrefix(n.PhoneNumber, "+1212") || !strings.Contains(n.PhoneNumber[5:], "555") {
+			t.Errorf("Expected a 212 number containing 555, got %s", n.PhoneNumber)
+		}
+	}
+
+	// Letters match their keypad digits
+	for _, n := range searchAvailableNumbers(t, r, main, "Contains=CAKE&PageSize=3").AvailablePhoneNumbers {
+		if !strings.Contains(n.PhoneNumber, "2253") {
+			t.Errorf("Expected a number containing 2253 (CAKE), got %s", n.PhoneNumber)
+		}
+	}
+
+	w := accountRequest(r, "GET", "/2010-04-01/Accounts/"+main.AccountSid+"/AvailablePhoneNumbers/ZZ/Local.json", main, nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unsupported country, got %d", w.Code)
+	}
+	w = accountRequest(r, "GET", "/2010-04-01/Accounts/"+main.AccountSid+"/AvailablePhoneNumbers/US/Local.json?AreaCode=12", main, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid area code, got %d", w.Code)
+	}
+}
@@ -0,0 +1,448 @@
+// ABOUTME: Studio Flows simulation for Twilio plugin
+// ABOUTME: Stores flow definitions and runs executions through say, gather, send-message and connect-call-to widgets
+
+package twilio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// flowDefinition is the JSON definition of a Studio flow
+type flowDefinition struct {
+	Description  string                 `json:"description"`
+	States       []flowState            `json:"states"`
+	InitialState string                 `json:"initial_state"`
+	Flags        map[string]interface{} `json:"flags,omitempty"`
+}
+
+// flowState is one widget in a flow
+type flowState struct {
+	Name        string                 `json:"name"`
+	Type        string                 `json:"type"`
+	Transitions []flowTransition       `json:"transitions"`
+	Properties  map[string]interface{} `json:"properties"`
+}
+
+// flowTransition moves an execution to the Next widget when Event happens
+type flowTransition struct {
+	Event string `json:"event"`
+	Next  string `json:"next,omitempty"`
+}
+
+// flowWidgetTypes maps the widget types executions support, by their Studio
+// names and simplified aliases, to the widget that runs them
+var flowWidgetTypes = map[string]string{
+	"trigger":              "trigger",
+	"say-play":             "say",
+	"say":                  "say",
+	"gather-input-on-call": "gather",
+	"gather":               "gather",
+	"send-message":         "send_message",
+	"send_message":         "send_message",
+	"connect-call-to":      "connect_call_to",
+	"connect_call_to":      "connect_call_to",
+}
+
+// maxFlowSteps stops an execution that loops between widgets
+const maxFlowSteps = 100
+
+// parseFlowDefinition parses and validates a flow definition. Without an
+// initial_state the flow starts at its first widget.
+func parseFlowDefinition(raw string) (*flowDefinition, error) {
+	var def flowDefinition
+	if err := json.Unmarshal([]byte(raw), &def); err != nil {
+		return nil, errors.New("Definition must be a JSON object")
+	}
+	if len(def.States) == 0 {
+		return nil, errors.New("Definition must have at least one state")
+	}
+
+	names := make(map[string]bool, len(def.States))
+	for _, state := range def.States {
+		if state.Name == "" {
+			return nil, errors.New("Every state must have a name")
+		}
+		if names[state.Name] {
+			return nil, fmt.Errorf("Duplicate state name %q", state.Name)
+		}
+		if _, ok := flowWidgetTypes[state.Type]; !ok {
+			return nil, fmt.Errorf("State %q has unsupported widget type %q", state.Name, state.Type)
+		}
+		names[state.Name] = true
+	}
+	for _, state := range def.States {
+		for _, t := range state.Transitions {
+			if t.Next != "" && !names[t.Next] {
+				return nil, fmt.Errorf("State %q transitions to unknown state %q", state.Name, t.Next)
+			}
+		}
+	}
+
+	if def.InitialState == "" {
+		def.InitialState = def.States[0].Name
+	}
+	if !names[def.InitialState] {
+		return nil, fmt.Errorf("Unknown initial_state %q", def.InitialState)
+	}
+	return &def, nil
+}
+
+func (def *flowDefinition) state(name string) *flowState {
+	for i := range def.States {
+		if def.States[i].Name == name {
+			return &def.States[i]
+		}
+	}
+	return nil
+}
+
+// next returns the widget the state moves to on event, or "" to end the execution
+func (s *flowState) next(event string) string {
+	for _, t := range s.Transitions {
+		if t.Event == event {
+			return t.Next
+		}
+	}
+	return ""
+}
+
+// property returns a string widget property, or "" if it is missing
+func (s *flowState) property(name string) string {
+	value, _ := s.Properties[name].(string)
+	return value
+}
+
+// flowVariablePattern matches Liquid-style {{flow.data.name}} variables
+var flowVariablePattern = regexp.MustCompile(`{{\s*([\w.]+)\s*}}`)
+
+// renderFlowTemplate substitutes {{dotted.path}} variables from the execution
+// context; unknown variables render as empty, as in Studio
+func renderFlowTemplate(template string, context map[string]interface{}) string {
+	return flowVariablePattern.ReplaceAllStringFunc(template, func(match string) string {
+		var value interface{} = context
+		for _, key := range strings.Split(flowVariablePattern.FindStringSubmatch(match)[1], ".") {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			value = m[key]
+		}
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// runFlow executes a flow from its initial state for a contact, returning the
+// execution context. Widgets' results are recorded under widgets.{name}.
+// A gather widget's caller input is simulated by the parameter named after
+// the widget: digits are a keypress, anything else speech, and no parameter
+// a timeout.
+func (p *TwilioPlugin) runFlow(accountSid, flowSid string, def *flowDefinition, to, from string, params map[string]interface{}) map[string]interface{} {
+	widgets := map[string]interface{}{}
+	context := map[string]interface{}{
+		"flow": map[string]interface{}{
+			"flow_sid":  flowSid,
+			"channel":   map[string]interface{}{"address": from},
+			"data":      params,
+			"variables": map[string]interface{}{},
+		},
+		"contact": map[string]interface{}{
+			"channel": map[string]interface{}{"address": to},
+		},
+		"widgets": widgets,
+	}
+
+	name := def.InitialState
+	for step := 0; name != "" && step < maxFlowSteps; step++ {
+		state := def.state(name)
+		var event string
+		switch flowWidgetTypes[state.Type] {
+		case "trigger":
+			event = "incomingRequest"
+
+		case "say":
+			widgets[name] = map[string]interface{}{"Say": renderFlowTemplate(state.property("say"), context)}
+			event = "audioComplete"
+
+		case "gather":
+			input, ok := params[name]
+			switch {
+			case !ok:
+				event = "timeout"
+			case strings.Trim(fmt.Sprint(input), "0123456789*#") == "":
+				widgets[name] = map[string]interface{}{"Digits": fmt.Sprint(input)}
+				event = "keypress"
+			default:
+				widgets[name] = map[string]interface{}{"SpeechResult": fmt.Sprint(input)}
+				event = "speech"
+			}
+
+		case "send_message":
+			event = p.runSendMessageWidget(accountSid, state, context, widgets)
+
+		case "connect_call_to":
+			event = p.runConnectCallWidget(accountSid, state, context, widgets)
+		}
+		name = state.next(event)
+	}
+	return context
+}
+
+// runSendMessageWidget sends a message to the contact, or the widget's to,
+// from the flow's number, or the widget's from
+func (p *TwilioPlugin) runSendMessageWidget(accountSid string, state *flowState, context, widgets map[string]interface{}) string {
+	to := renderFlowTemplate(state.property("to"), context)
+	if to == "" {
+		to = renderFlowTemplate("{{contact.channel.address}}", context)
+	}
+	from := renderFlowTemplate(state.property("from"), context)
+	if from == "" {
+		from = renderFlowTemplate("{{flow.channel.address}}", context)
+	}
+	body := renderFlowTemplate(state.property("body"), context)
+
+	if !validatePhoneNumber(to) || !validatePhoneNumber(from) || body == "" {
+		widgets[state.Name] = map[string]interface{}{"status": "failed"}
+		return "failed"
+	}
+	message, err := p.store.CreateMessage(accountSid, from, to, body)
+	if err != nil {
+		widgets[state.Name] = map[string]interface{}{"status": "failed"}
+		return "failed"
+	}
+
+	if err := p.QueueMessageWebhook(message.Sid, "queued", 0); err != nil {
+		log.Printf("Failed to queue webhook for message %s: %v", message.Sid, err)
+	}
+	nextAt := time.Now().Add(p.deliveryDelay)
+	if err := p.store.SetMessageStatus(message.Sid, message.Status, &nextAt); err != nil {
+		log.Printf("Failed to schedule status for message %s: %v", message.Sid, err)
+	}
+
+	widgets[state.Name] = map[string]interface{}{
+		"sid":    message.Sid,
+		"to":     to,
+		"from":   from,
+		"body":   body,
+		"status": message.Status,
+	}
+	return "sent"
+}
+
+// runConnectCallWidget places a call from the flow's number, or the widget's
+// caller_id, to the widget's to; the call then runs its usual lifecycle
+func (p *TwilioPlugin) runConnectCallWidget(accountSid string, state *flowState, context, widgets map[string]interface{}) string {
+	to := renderFlowTemplate(state.property("to"), context)
+	from := renderFlowTemplate(state.property("caller_id"), context)
+	if from == "" {
+		from = renderFlowTemplate("{{flow.channel.address}}", context)
+	}
+
+	if !validatePhoneNumber(to) || !validatePhoneNumber(from) {
+		widgets[state.Name] = map[string]interface{}{"DialCallStatus": "failed"}
+		return "hangup"
+	}
+	call, err := p.store.CreateCall(accountSid, from, to)
+	if err != nil {
+		widgets[state.Name] = map[string]interface{}{"DialCallStatus": "failed"}
+		return "hangup"
+	}
+
+	if err := p.QueueCallWebhook(call.Sid, "initiated", 0); err != nil {
+		log.Printf("Failed to queue webhook for call %s: %v", call.Sid, err)
+	}
+	go p.SimulateCallLifecycle(call.Sid)
+
+	widgets[state.Name] = map[string]interface{}{
+		"DialCallSid":    call.Sid,
+		"DialCallStatus": call.Status,
+	}
+	return "callCompleted"
+}
+
+// writeStudioJSON writes a Studio resource. Unlike the 2010-04-01 API,
+// Studio only speaks JSON and its paths have no extension.
+func writeStudioJSON(w http.ResponseWriter, status int, resource map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resource)
+}
+
+func flowToResponse(flow *Flow) map[string]interface{} {
+	var definition interface{}
+	json.Unmarshal([]byte(flow.Definition), &definition)
+	url := "https://studio.twilio.com/v1/Flows/" + flow.Sid
+	return map[string]interface{}{
+		"sid":           flow.Sid,
+		"account_sid":   flow.AccountSid,
+		"friendly_name": flow.FriendlyName,
+		"definition":    definition,
+		"status":        flow.Status,
+		"revision":      flow.Revision,
+		"valid":         true,
+		"errors":        []interface{}{},
+		"warnings":      []interface{}{},
+		"date_created":  flow.DateCreated.UTC().Format(time.RFC3339),
+		"date_updated":  flow.DateUpdated.UTC().Format(time.RFC3339),
+		"webhook_url":   "https://webhooks.twilio.com/v1/Accounts/" + flow.AccountSid + "/Flows/" + flow.Sid,
+		"url":           url,
+		"links": map[string]interface{}{
+			"executions": url + "/Executions",
+		},
+	}
+}
+
+func flowExecutionToResponse(exec *FlowExecution) map[string]interface{} {
+	var context interface{}
+	json.Unmarshal([]byte(exec.Context), &context)
+	url := "https://studio.twilio.com/v1/Flows/" + exec.FlowSid + "/Executions/" + exec.Sid
+	return map[string]interface{}{
+		"sid":                     exec.Sid,
+		"account_sid":             exec.AccountSid,
+		"flow_sid":                exec.FlowSid,
+		"contact_channel_address": exec.ContactChannelAddress,
+		"context":                 context,
+		"status":                  exec.Status,
+		"date_created":            exec.DateCreated.UTC().Format(time.RFC3339),
+		"date_updated":            exec.DateUpdated.UTC().Format(time.RFC3339),
+		"url":                     url,
+		"links": map[string]interface{}{
+			"steps":             url + "/Steps",
+			"execution_context": url + "/Context",
+		},
+	}
+}
+
+// createFlow handles POST /v1/Flows, taking FriendlyName, Status (draft or
+// published) and a JSON Definition
+func (p *TwilioPlugin) createFlow(w http.ResponseWriter, r *http.Request) {
+	accountSid := r.Context().Value(accountSidKey).(string)
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, 21602, "Invalid form body")
+		return
+	}
+
+	friendlyName := r.FormValue("FriendlyName")
+	status := r.FormValue("Status")
+	definition := r.FormValue("Definition")
+	if friendlyName == "" || status == "" || definition == "" {
+		writeError(w, http.StatusBadRequest, 20001, "Missing required parameter FriendlyName, Status, or Definition")
+		return
+	}
+	if status != "draft" && status != "published" {
+		writeError(w, http.StatusBadRequest, 20001, "Status must be draft or published")
+		return
+	}
+	if _, err := parseFlowDefinition(definition); err != nil {
+		writeError(w, http.StatusBadRequest, 20001, "Invalid Definition: "+err.Error())
+		return
+	}
+
+	flow, err := p.store.CreateFlow(accountSid, friendlyName, status, definition)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	writeStudioJSON(w, http.StatusCreated, flowToResponse(flow))
+}
+
+// flowForRequest loads the {FlowSid} flow, writing a 404 unless it belongs to
+// the requesting account
+func (p *TwilioPlugin) flowForRequest(w http.ResponseWriter, r *http.Request) (*Flow, bool) {
+	accountSid := r.Context().Value(accountSidKey).(string)
+	flow, err := p.store.GetFlow(chi.URLParam(r, "FlowSid"))
+	if err != nil || flow.AccountSid != accountSid {
+		writeError(w, http.StatusNotFound, 20404, "Flow not found")
+		return nil, false
+	}
+	return flow, true
+}
+
+func (p *TwilioPlugin) getFlow(w http.ResponseWriter, r *http.Request) {
+	flow, ok := p.flowForRequest(w, r)
+	if !ok {
+		return
+	}
+	writeStudioJSON(w, http.StatusOK, flowToResponse(flow))
+}
+
+// createFlowExecution handles POST /v1/Flows/{FlowSid}/Executions, taking To,
+// From and optional JSON Parameters. The flow runs to completion before the
+// response, so the execution is returned ended with its final context.
+func (p *TwilioPlugin) createFlowExecution(w http.ResponseWriter, r *http.Request) {
+	flow, ok := p.flowForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, 21602, "Invalid form body")
+		return
+	}
+
+	to := r.FormValue("To")
+	from := r.FormValue("From")
+	if to == "" || from == "" {
+		writeError(w, http.StatusBadRequest, 20001, "Missing required parameter To or From")
+		return
+	}
+	if !validatePhoneNumber(to) || !validatePhoneNumber(from) {
+		writeError(w, http.StatusBadRequest, 21211, "Invalid phone number format. Must be E.164 format (e.g., +15551234567)")
+		return
+	}
+
+	params := map[string]interface{}{}
+	if raw := r.FormValue("Parameters"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &params); err != nil || params == nil {
+			writeError(w, http.StatusBadRequest, 20001, "Parameters must be a JSON object")
+			return
+		}
+	}
+
+	def, err := parseFlowDefinition(flow.Definition)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+	context, err := json.Marshal(p.runFlow(flow.AccountSid, flow.Sid, def, to, from, params))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	exec, err := p.store.CreateFlowExecution(flow.AccountSid, flow.Sid, to, "ended", string(context))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	writeStudioJSON(w, http.StatusCreated, flowExecutionToResponse(exec))
+}
+
+func (p *TwilioPlugin) getFlowExecution(w http.ResponseWriter, r *http.Request) {
+	flow, ok := p.flowForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	exec, err := p.store.GetFlowExecution(flow.Sid, chi.URLParam(r, "ExecutionSid"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, 20404, "Execution not found")
+		return
+	}
+
+	writeStudioJSON(w, http.StatusOK, flowExecutionToResponse(exec))
+}
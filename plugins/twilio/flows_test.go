@@ -0,0 +1,151 @@
+// ABOUTME: Tests for the Studio Flows simulation
+// ABOUTME: Covers creating flows and running executions through say, gather and send-message widgets
+
+package twilio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type flowExecution struct {
+	Sid     string                 `json:"sid"`
+	FlowSid string                 `json:"flow_sid"`
+	Status  string                 `json:"status"`
+	Context map[string]interface{} `json:"context"`
+}
+
+func createTestFlow(t *testing.T, r http.Handler, acct *Account, definition string) string {
+	t.Helper()
+	w := accountRequest(r, "POST", "/v1/Flows", acct, url.Values{
+		"FriendlyName": {"IVR"},
+		"Status":       {"published"},
+		"Definition":   {definition},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var flow struct {
+		Sid string `json:"sid"`
+	}
+	json.NewDecoder(w.Body).Decode(&flow)
+	if !strings.HasPrefix(flow.Sid, "FW") {
+		t.Fatalf("Expected an FW flow SID, got %q", flow.Sid)
+	}
+	return flow.Sid
+}
+
+func executeTestFlow(t *testing.T, r http.Handler, acct *Account, flowSid, parameters string) flowExecution {
+	t.Helper()
+	form := url.Values{"To": {"+15557654321"}, "From": {"+15551234567"}}
+	if parameters != "" {
+		form.Set("Parameters", parameters)
+	}
+	w := accountRequest(r, "POST", "/v1/Flows/"+flowSid+"/Executions", acct, form)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var exec flowExecution
+	json.NewDecoder(w.Body).Decode(&exec)
+	return exec
+}
+
+func TestFlowExecutionTwoWidgets(t *testing.T) {
+	store, main, r := setupAccountsRouter(t)
+
+	flowSid := createTestFlow(t, r, main, `{
+		"states": [
+			{"name": "greeting", "type": "say", "transitions": [{"event": "audioComplete", "next": "confirm"}],
+			 "properties": {"say": "Hello {{flow.data.name}}"}},
+			{"name": "confirm", "type": "send_message", "transitions": [{"event": "sent"}],
+			 "properties": {"body": "Thanks for calling, {{flow.data.name}}"}}
+		]
+	}`)
+
+	exec := executeTestFlow(t, r, main, flowSid, `{"name": "Ada"}`)
+	if !strings.HasPrefix(exec.Sid, "FN") || exec.FlowSid != flowSid || exec.Status != "ended" {
+		t.Fatalf("Unexpected execution: %+v", exec)
+	}
+	widgets, _ := exec.Context["widgets"].(map[string]interface{})
+	greeting, _ := widgets["greeting"].(map[string]interface{})
+	if greeting["Say"] != "Hello Ada" {
+		t.Errorf("Expected the greeting to be spoken with the name filled in, got %v", widgets["greeting"])
+	}
+	confirm, _ := widgets["confirm"].(map[string]interface{})
+	if confirm["body"] != "Thanks for calling, Ada" || confirm["to"] != "+15557654321" || confirm["from"] != "+15551234567" {
+		t.Errorf("Unexpected send-message result: %v", widgets["confirm"])
+	}
+
+	msg, err := store.GetMessage(confirm["sid"].(string))
+	if err != nil || msg.Body != "Thanks for calling, Ada" || msg.AccountSid != main.AccountSid {
+		t.Errorf("Expected the flow's message to be stored, got %+v (%v)", msg, err)
+	}
+
+	w := accountRequest(r, "GET", "/v1/Flows/"+flowSid+"/Executions/"+exec.Sid, main, nil)
+	var fetched flowExecution
+	json.NewDecoder(w.Body).Decode(&fetched)
+	if w.Code != http.StatusOK || fetched.Sid != exec.Sid || fetched.Context["widgets"] == nil {
+		t.Errorf("Expected to fetch the execution with its context, got %d: %+v", w.Code, fetched)
+	}
+
+	w = accountRequest(r, "GET", "/v1/Flows/"+flowSid, main, nil)
+	var flow map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&flow)
+	if w.Code != http.StatusOK || flow["friendly_name"] != "IVR" || flow["definition"] == nil {
+		t.Errorf("Expected to fetch the flow, got %d: %v", w.Code, flow)
+	}
+}
+
+func TestFlowExecutionGather(t *testing.T) {
+	_, main, r := setupAccountsRouter(t)
+
+	flowSid := createTestFlow(t, r, main, `{
+		"initial_state": "Trigger",
+		"states": [
+			{"name": "Trigger", "type": "trigger", "transitions": [{"event": "incomingRequest", "next": "menu"}], "properties": {}},
+			{"name": "menu", "type": "gather-input-on-call", "properties": {"say": "Press 1 for sales"},
+			 "transitions": [{"event": "keypress", "next": "pressed"}, {"event": "timeout", "next": "goodbye"}]},
+			{"name": "pressed", "type": "say-play", "transitions": [], "properties": {"say": "You pressed {{widgets.menu.Digits}}"}},
+			{"name": "goodbye", "type": "say-play", "transitions": [], "properties": {"say": "Goodbye"}}
+		]
+	}`)
+
+	widgets := executeTestFlow(t, r, main, flowSid, `{"menu": "1"}`).Context["widgets"].(map[string]interface{})
+	if said, _ := widgets["pressed"].(map[string]interface{}); said["Say"] != "You pressed 1" || widgets["goodbye"] != nil {
+		t.Errorf("Expected the keypress branch, got %v", widgets)
+	}
+
+	widgets = executeTestFlow(t, r, main, flowSid, "").Context["widgets"].(map[string]interface{})
+	if widgets["goodbye"] == nil || widgets["pressed"] != nil {
+		t.Errorf("Expected the timeout branch without input, got %v", widgets)
+	}
+}
+
+func TestCreateFlowValidation(t *testing.T) {
+	_, main, r := setupAccountsRouter(t)
+
+	for name, definition := range map[string]string{
+		"not json":        `states`,
+		"no states":       `{"states": []}`,
+		"unknown widget":  `{"states": [{"name": "a", "type": "run-function"}]}`,
+		"unknown next":    `{"states": [{"name": "a", "type": "say", "transitions": [{"event": "audioComplete", "next": "b"}]}]}`,
+		"unknown initial": `{"initial_state": "b", "states": [{"name": "a", "type": "say"}]}`,
+	} {
+		w := accountRequest(r, "POST", "/v1/Flows", main, url.Values{
+			"FriendlyName": {"Bad"}, "Status": {"draft"}, "Definition": {definition},
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+
+	// Flows belong to the account that created them
+	flowSid := createTestFlow(t, r, main, `{"states": [{"name": "a", "type": "say"}]}`)
+	sub := createSubaccount(t, r, main)
+	if w := accountRequest(r, "GET", "/v1/Flows/"+flowSid, sub, nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 fetching another account's flow, got %d", w.Code)
+	}
+}
@@ -0,0 +1,156 @@
+// ABOUTME: Content negotiation for Twilio API responses
+// ABOUTME: Paths ending in .json get JSON; paths without an extension get Twilio's XML format
+
+package twilio
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// wantsXML reports whether a request asked for XML. Like Twilio, XML is the
+// default and the .json extension selects JSON.
+func wantsXML(r *http.Request) bool {
+	return !strings.HasSuffix(r.URL.Path, ".json")
+}
+
+// twilioList is a page of resources, rendered in JSON as
+// {key: [...items], ...meta} and in XML as
+// <Element meta...><Item>...</Item></Element>
+type twilioList struct {
+	Key     string // JSON key for the items, e.g. "messages"
+	Element string // XML list element, e.g. "Messages"
+	Item    string // XML item element, e.g. "Message"
+	Items   []map[string]interface{}
+	Meta    map[string]interface{} // page, page_size, and other paging fields
+}
+
+// writeTwilioResource writes one resource in the format the request asked
+// for. In XML it is wrapped as <TwilioResponse><Element>...</Element></TwilioResponse>.
+func writeTwilioResource(w http.ResponseWriter, r *http.Request, status int, element string, resource map[string]interface{}) {
+	if !wantsXML(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resource)
+		return
+	}
+
+	writeTwilioXML(w, status, func(enc *xml.Encoder) error {
+		return encodeXMLValue(enc, element, resource)
+	})
+}
+
+// writeTwilioList writes a page of resources in the format the request asked for
+func writeTwilioList(w http.ResponseWriter, r *http.Request, list twilioList) {
+	if !wantsXML(r) {
+		response := make(map[string]interface{}, len(list.Meta)+1)
+		for key, value := range list.Meta {
+			response[key] = value
+		}
+		response[list.Key] = list.Items
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	writeTwilioXML(w, http.StatusOK, func(enc *xml.Encoder) error {
+		// Paging fields are attributes on the list element, named without
+		// underscores (page_size becomes pagesize)
+		start := xml.StartElement{Name: xml.Name{Local: list.Element}}
+		for _, key := range sortedKeys(list.Meta) {
+			if list.Meta[key] == nil {
+				continue
+			}
+			start.Attr = append(start.Attr, xml.Attr{
+				Name:  xml.Name{Local: strings.ReplaceAll(key, "_", "")},
+				Value: fmt.Sprint(list.Meta[key]),
+			})
+		}
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			if err := encodeXMLValue(enc, list.Item, item); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	})
+}
+
+// writeTwilioXML writes a <TwilioResponse> document whose body is written by encodeBody
+func writeTwilioXML(w http.ResponseWriter, status int, encodeBody func(enc *xml.Encoder) error) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+
+	enc := xml.NewEncoder(w)
+	root := xml.StartElement{Name: xml.Name{Local: "TwilioResponse"}}
+	if err := enc.EncodeToken(root); err != nil {
+		return
+	}
+	if err := encodeBody(enc); err != nil {
+		return
+	}
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return
+	}
+	enc.Flush()
+}
+
+// encodeXMLValue writes a JSON-style value as an element. Object keys become
+// child elements in Twilio's CamelCase (account_sid becomes AccountSid), and
+// null becomes an empty element.
+func encodeXMLValue(enc *xml.Encoder, name string, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	var fields map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		fields = v
+	case map[string]string:
+		fields = make(map[string]interface{}, len(v))
+		for key, s := range v {
+			fields[key] = s
+		}
+	case nil:
+		return enc.EncodeElement("", start)
+	default:
+		return enc.EncodeElement(fmt.Sprint(v), start)
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(fields) {
+		if err := encodeXMLValue(enc, xmlElementName(key), fields[key]); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlElementName converts a JSON field name to Twilio's XML element name
+func xmlElementName(key string) string {
+	parts := strings.Split(key, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
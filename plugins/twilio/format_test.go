@@ -0,0 +1,91 @@
+// ABOUTME: Tests for Twilio response content negotiation
+// ABOUTME: Checks XML for extensionless paths and JSON for .json paths
+
+package twilio
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMessagesContentNegotiation(t *testing.T) {
+	store, acct, r := setupAccountsRouter(t)
+	message, err := store.CreateMessage("AC123", "+15559876543", "+15551234567", "Hello <world>")
+	if err != nil {
+		t.Fatalf("CreateMessage() error = %v", err)
+	}
+
+	// Without an extension the list is XML
+	w := accountRequest(r, "GET", "/2010-04-01/Accounts/AC123/Messages", acct, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml" {
+		t.Errorf("Content-Type = %q, want application/xml", ct)
+	}
+	var list struct {
+		XMLName  xml.Name `xml:"TwilioResponse"`
+		Messages struct {
+			PageSize string `xml:"pagesize,attr"`
+			Message  []struct {
+				Sid        string `xml:"Sid"`
+				AccountSid string `xml:"AccountSid"`
+				Body       string `xml:"Body"`
+			} `xml:"Message"`
+		} `xml:"Messages"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode XML: %v\n%s", err, w.Body.String())
+	}
+	if len(list.Messages.Message) != 1 || list.Messages.PageSize != "50" {
+		t.Fatalf("Expected one message on a 50-item page, got %+v", list.Messages)
+	}
+	got := list.Messages.Message[0]
+	if got.Sid != message.Sid || got.AccountSid != "AC123" || got.Body != "Hello <world>" {
+		t.Errorf("Unexpected XML message: %+v", got)
+	}
+
+	// With .json the same list is JSON
+	w = accountRequest(r, "GET", "/2010-04-01/Accounts/AC123/Messages.json", acct, nil)
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var jsonList struct {
+		Messages []map[string]interface{} `json:"messages"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &jsonList); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if len(jsonList.Messages) != 1 || jsonList.Messages[0]["sid"] != message.Sid {
+		t.Errorf("Unexpected JSON messages: %v", jsonList.Messages)
+	}
+
+	// Single resources and creates negotiate the same way
+	w = accountRequest(r, "GET", "/2010-04-01/Accounts/AC123/Messages/"+message.Sid, acct, nil)
+	var single struct {
+		XMLName xml.Name `xml:"TwilioResponse"`
+		Message struct {
+			Sid             string `xml:"Sid"`
+			DateSent        string `xml:"DateSent"`
+			SubresourceUris struct {
+				Media string `xml:"Media"`
+			} `xml:"SubresourceUris"`
+		} `xml:"Message"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &single); err != nil {
+		t.Fatalf("Failed to decode XML: %v\n%s", err, w.Body.String())
+	}
+	if single.Message.Sid != message.Sid || !strings.HasSuffix(single.Message.SubresourceUris.Media, "/Media.json") {
+		t.Errorf("Unexpected XML message: %+v", single.Message)
+	}
+
+	form := url.Values{"To": {"+15551234567"}, "From": {"+15559876543"}, "Body": {"Sent as XML"}}
+	w = accountRequest(r, "POST", "/2010-04-01/Accounts/AC123/Messages", acct, form)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), "<TwilioResponse><Message>") {
+		t.Errorf("Expected a 201 XML message, got %d: %s", w.Code, w.Body.String())
+	}
+}
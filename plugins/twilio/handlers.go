@@ -32,8 +32,9 @@ func (p *TwilioPlugin) sendMessage(w http.ResponseWriter, r *http.Request) {
 	to := r.FormValue("To")
 	from := r.FormValue("From")
 	body := r.FormValue("Body")
+	mediaURLs := r.Form["MediaUrl"]
 
-	if to == "" || from == "" || body == "" {
+	if to == "" || from == "" || (body == "" && len(mediaURLs) == 0) {
 		writeError(w, http.StatusBadRequest, 21602, "Missing required parameter To, From, or Body")
 		return
 	}
@@ -50,23 +51,48 @@ func (p *TwilioPlugin) sendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(mediaURLs) > maxMediaPerMessage {
+		writeError(w, http.StatusBadRequest, 21623, "Number of media files exceeds allowed limit of 10")
+		return
+	}
+	for _, mediaURL := range mediaURLs {
+		if !validMediaURL(mediaURL) {
+			writeError(w, http.StatusBadRequest, 21620, "Invalid media URL(s)")
+			return
+		}
+	}
+
 	message, err := p.store.CreateMessage(accountSid, from, to, body)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
 		return
 	}
+	if len(mediaURLs) > 0 {
+		for _, mediaURL := range mediaURLs {
+			if _, err := p.store.AddMessageMedia(accountSid, message.Sid, mediaContentType(mediaURL), mediaURL); err != nil {
+				writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+				return
+			}
+		}
+		if message, err = p.store.GetMessage(message.Sid); err != nil {
+			writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+			return
+		}
+	}
 
 	// Queue immediate webhook for "queued" status
 	if err := p.QueueMessageWebhook(message.Sid, "queued", 0); err != nil {
 		log.Printf("Failed to queue webhook for message %s: %v", message.Sid, err)
 	}
 
-	// Start async lifecycle simulation
-	go p.SimulateMessageLifecycle(message.Sid)
+	// The status worker takes it from here, moving it to sending after the delivery delay
+	nextAt := time.Now().Add(p.deliveryDelay)
+	if err := p.store.SetMessageStatus(message.Sid, message.Status, &nextAt); err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(messageToResponse(message))
+	writeTwilioResource(w, r, http.StatusCreated, "Message", messageToResponse(message))
 }
 
 func (p *TwilioPlugin) getMessage(w http.ResponseWriter, r *http.Request) {
@@ -78,8 +104,7 @@ func (p *TwilioPlugin) getMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(messageToResponse(message))
+	writeTwilioResource(w, r, http.StatusOK, "Message", messageToResponse(message))
 }
 
 func (p *TwilioPlugin) listMessages(w http.ResponseWriter, r *http.Request) {
@@ -103,11 +128,12 @@ func (p *TwilioPlugin) listMessages(w http.ResponseWriter, r *http.Request) {
 		responseMessages[i] = messageToResponse(&msg)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"messages":  responseMessages,
-		"page":      0,
-		"page_size": pageSize,
+	writeTwilioList(w, r, twilioList{
+		Key:     "messages",
+		Element: "Messages",
+		Item:    "Message",
+		Items:   responseMessages,
+		Meta:    map[string]interface{}{"page": 0, "page_size": pageSize},
 	})
 }
 
@@ -123,10 +149,15 @@ func messageToResponse(msg *Message) map[string]interface{} {
 		"date_created":  msg.DateCreated.Format(time.RFC1123Z),
 		"date_updated":  msg.DateUpdated.Format(time.RFC1123Z),
 		"num_segments":  msg.NumSegments,
+		"num_media":     strconv.Itoa(msg.NumMedia),
 		"price":         msg.Price,
 		"price_unit":    msg.PriceUnit,
 		"error_code":    nil,
 		"error_message": nil,
+		"uri":           messageURI(msg.AccountSid, msg.Sid) + ".json",
+		"subresource_uris": map[string]interface{}{
+			"media": messageURI(msg.AccountSid, msg.Sid) + "/Media.json",
+		},
 	}
 
 	if msg.DateSent != nil {
@@ -185,9 +216,7 @@ func (p *TwilioPlugin) initiateCall(w http.ResponseWriter, r *http.Request) {
 	// Start async lifecycle simulation
 	go p.SimulateCallLifecycle(call.Sid)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(callToResponse(call))
+	writeTwilioResource(w, r, http.StatusCreated, "Call", callToResponse(call))
 }
 
 func (p *TwilioPlugin) getCall(w http.ResponseWriter, r *http.Request) {
@@ -199,8 +228,7 @@ func (p *TwilioPlugin) getCall(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(callToResponse(call))
+	writeTwilioResource(w, r, http.StatusOK, "Call", callToResponse(call))
 }
 
 func (p *TwilioPlugin) listCalls(w http.ResponseWriter, r *http.Request) {
@@ -224,11 +252,12 @@ func (p *TwilioPlugin) listCalls(w http.ResponseWriter, r *http.Request) {
 		responseCalls[i] = callToResponse(&call)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"calls":     responseCalls,
-		"page":      0,
-		"page_size": pageSize,
+	writeTwilioList(w, r, twilioList{
+		Key:     "calls",
+		Element: "Calls",
+		Item:    "Call",
+		Items:   responseCalls,
+		Meta:    map[string]interface{}{"page": 0, "page_size": pageSize},
 	})
 }
 
@@ -264,25 +293,31 @@ func (p *TwilioPlugin) listPhoneNumbers(w http.ResponseWriter, r *http.Request)
 	}
 
 	responseNumbers := make([]map[string]interface{}, len(numbers))
-	for i, num := range numbers {
-		responseNumbers[i] = map[string]interface{}{
-			"sid":                      num.Sid,
-			"account_sid":              num.AccountSid,
-			"phone_number":             num.PhoneNumber,
-			"friendly_name":            num.FriendlyName,
-			"voice_url":                num.VoiceURL,
-			"voice_method":             num.VoiceMethod,
-			"sms_url":                  num.SmsURL,
-			"sms_method":               num.SmsMethod,
-			"status_callback":          num.StatusCallback,
-			"status_callback_method":   num.StatusCallbackMethod,
-			"date_created":             num.CreatedAt.Format(time.RFC1123Z),
-			"date_updated":             num.UpdatedAt.Format(time.RFC1123Z),
-		}
+	for i := range numbers {
+		responseNumbers[i] = phoneNumberToResponse(&numbers[i])
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"incoming_phone_numbers": responseNumbers,
+	writeTwilioList(w, r, twilioList{
+		Key:     "incoming_phone_numbers",
+		Element: "IncomingPhoneNumbers",
+		Item:    "IncomingPhoneNumber",
+		Items:   responseNumbers,
 	})
 }
+
+func phoneNumberToResponse(num *PhoneNumber) map[string]interface{} {
+	return map[string]interface{}{
+		"sid":                    num.Sid,
+		"account_sid":            num.AccountSid,
+		"phone_number":           num.PhoneNumber,
+		"friendly_name":          num.FriendlyName,
+		"voice_url":              num.VoiceURL,
+		"voice_method":           num.VoiceMethod,
+		"sms_url":                num.SmsURL,
+		"sms_method":             num.SmsMethod,
+		"status_callback":        num.StatusCallback,
+		"status_callback_method": num.StatusCallbackMethod,
+		"date_created":           num.CreatedAt.Format(time.RFC1123Z),
+		"date_updated":           num.UpdatedAt.Format(time.RFC1123Z),
+	}
+}
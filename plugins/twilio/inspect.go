@@ -0,0 +1,38 @@
+// ABOUTME: core.Inspector implementation for the Twilio plugin
+// ABOUTME: Looks up records by SID and renders them as the REST API does
+
+package twilio
+
+import "fmt"
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *TwilioPlugin) Inspect(resource, id string) (interface{}, error) {
+	switch resource {
+	case "accounts":
+		account, err := p.store.GetAccount(id)
+		if err != nil {
+			return nil, err
+		}
+		return accountToResponse(account), nil
+	case "messages":
+		message, err := p.store.GetMessage(id)
+		if err != nil {
+			return nil, err
+		}
+		return messageToResponse(message), nil
+	case "calls":
+		call, err := p.store.GetCall(id)
+		if err != nil {
+			return nil, err
+		}
+		return callToResponse(call), nil
+	case "phone_numbers":
+		phoneNumber, err := p.store.GetPhoneNumber(id)
+		if err != nil {
+			return nil, err
+		}
+		return phoneNumberToResponse(phoneNumber), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
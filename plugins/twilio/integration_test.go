@@ -5,6 +5,7 @@ package twilio
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -12,10 +13,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 func TestFullSMSFlow(t *testing.T) {
+	t.Setenv(DeliveryDelayEnv, "100")
+	t.Setenv(DeliveryFailureRateEnv, "0")
 	plugin, db := setupTestPlugin(t)
 	defer db.Close()
 
@@ -56,23 +61,23 @@ func TestFullSMSFlow(t *testing.T) {
 	json.NewDecoder(rr.Body).Decode(&response)
 	messageSid := response["sid"].(string)
 
-	// Wait for lifecycle to complete (sent at 100ms, delivered at 600ms)
+	// Wait for the lifecycle to complete (sending at 100ms, sent at 200ms, delivered at 300ms)
 	time.Sleep(1000 * time.Millisecond)
 
-	// Verify message status has progressed (at least to sent, ideally delivered)
+	// With no delivery failures the message always ends up delivered
 	msg, err := plugin.store.GetMessage(messageSid)
 	if err != nil {
 		t.Fatalf("Failed to get message: %v", err)
 	}
-	if msg.Status != "sent" && msg.Status != "delivered" {
-		t.Fatalf("Expected status sent or delivered, got %s", msg.Status)
+	if msg.Status != "delivered" {
+		t.Fatalf("Expected status delivered, got %s", msg.Status)
 	}
 
-	// Verify webhooks were queued (at least 2 for queued, sent; may have 3 with delivered)
+	// Verify webhooks were queued for queued, sending, sent and delivered
 	var webhookCount int
 	db.QueryRow("SELECT COUNT(*) FROM twilio_webhook_queue WHERE resource_sid = ?", messageSid).Scan(&webhookCount)
-	if webhookCount < 2 {
-		t.Fatalf("Expected at least 2 webhooks queued, got %d", webhookCount)
+	if webhookCount < 4 {
+		t.Fatalf("Expected at least 4 webhooks queued, got %d", webhookCount)
 	}
 }
 
@@ -193,3 +198,93 @@ func TestAuthValidation(t *testing.T) {
 		t.Fatalf("Expected 401 with missing auth, got %d", rr.Code)
 	}
 }
+
+func TestWebhookDeliveryTimeout(t *testing.T) {
+	plugin, db := setupTestPlugin(t)
+	defer db.Close()
+
+	core.SetWebhookTimeout(50 * time.Millisecond)
+	t.Cleanup(func() { core.SetWebhookTimeout(core.DefaultWebhookTimeout) })
+
+	// A receiver that hangs well past the timeout
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	if err := plugin.store.QueueWebhook("SMTIMEOUT", receiver.URL, "MessageStatus=sent", time.Now()); err != nil {
+		t.Fatalf("QueueWebhook failed: %v", err)
+	}
+
+	start := time.Now()
+	plugin.processWebhookQueue()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Expected delivery to give up after the timeout, took %v", elapsed)
+	}
+
+	var status, errorMessage string
+	var statusCode, attempts int
+	err := db.QueryRow(`
+		SELECT status, status_code, error_message, attempts
+		FROM twilio_webhook_queue WHERE resource_sid = 'SMTIMEOUT'
+	`).Scan(&status, &statusCode, &errorMessage, &attempts)
+	if err != nil {
+		t.Fatalf("Failed to read delivery: %v", err)
+	}
+	if status != "failed" || statusCode != 0 || errorMessage != "timeout" || attempts != 1 {
+		t.Errorf("Expected failed delivery with status 0 and error \"timeout\", got status=%s code=%d error=%q attempts=%d",
+			status, statusCode, errorMessage, attempts)
+	}
+}
+
+func TestWebhookDeliveryDelay(t *testing.T) {
+	plugin, db := setupTestPlugin(t)
+	defer db.Close()
+	defer plugin.Shutdown(context.Background())
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+
+	plugin.store.GetOrCreateAccount("ACDELAY")
+	pn, _ := plugin.store.CreatePhoneNumber("ACDELAY", "+15551234567", "Delayed")
+	path := "/admin/twilio/status_callback/" + pn.Sid
+
+	w := postForm(r, path, url.Values{"status_callback": {"http://example.com/status"}, "delay_ms": {"-1"}})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for a negative delay, got %d", w.Code)
+	}
+	w = postForm(r, path, url.Values{"status_callback": {"http://example.com/status"}, "delay_ms": {"200"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 configuring the status callback, got %d: %s", w.Code, w.Body.String())
+	}
+
+	delivered := make(chan time.Time, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- time.Now()
+	}))
+	defer receiver.Close()
+
+	msg, _ := plugin.store.CreateMessage("ACDELAY", "+15551234567", "+15559876543", "Delayed callback")
+	start := time.Now()
+	if err := plugin.QueueMessageWebhook(msg.Sid, "sent", 0); err != nil {
+		t.Fatalf("QueueMessageWebhook failed: %v", err)
+	}
+	// Loopback receivers fail URL validation, so point the queued callback at ours directly
+	if _, err := db.Exec("UPDATE twilio_webhook_queue SET webhook_url = ? WHERE resource_sid = ?", receiver.URL, msg.Sid); err != nil {
+		t.Fatalf("Failed to retarget webhook: %v", err)
+	}
+
+	// The plugin's worker delivers it once the delay has passed
+	select {
+	case at := <-delivered:
+		if elapsed := at.Sub(start); elapsed < 200*time.Millisecond {
+			t.Errorf("Webhook delivered after %v, want at least 200ms", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Webhook was never delivered")
+	}
+}
@@ -0,0 +1,153 @@
+// ABOUTME: Message media (MMS) endpoints for the Twilio plugin
+// ABOUTME: Lists media per message and per account, and deletes media from messages
+
+package twilio
+
+import (
+	"database/sql"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxMediaPerMessage is the most MediaUrl values one message may carry
+const maxMediaPerMessage = 10
+
+// defaultMediaContentType is assumed for media URLs without a recognizable extension
+const defaultMediaContentType = "image/jpeg"
+
+// validMediaURL reports whether a MediaUrl is an absolute http or https URL
+func validMediaURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// mediaContentType guesses a media URL's content type from its file
+// extension, since the emulator never fetches the file
+func mediaContentType(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return defaultMediaContentType
+	}
+	if contentType := mime.TypeByExtension(path.Ext(u.Path)); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil {
+			return mediaType
+		}
+	}
+	return defaultMediaContentType
+}
+
+// messageURI is the API path of a message, without the .json extension
+func messageURI(accountSid, messageSid string) string {
+	return "/2010-04-01/Accounts/" + accountSid + "/Messages/" + messageSid
+}
+
+func mediaToResponse(media *Media) map[string]interface{} {
+	return map[string]interface{}{
+		"sid":          media.Sid,
+		"account_sid":  media.AccountSid,
+		"parent_sid":   media.MessageSid,
+		"content_type": media.ContentType,
+		"date_created": media.DateCreated.Format(time.RFC1123Z),
+		"date_updated": media.DateUpdated.Format(time.RFC1123Z),
+		"uri":          messageURI(media.AccountSid, media.MessageSid) + "/Media/" + media.Sid + ".json",
+	}
+}
+
+// writeMediaList writes a page of media in Twilio's list format
+func writeMediaList(w http.ResponseWriter, r *http.Request, media []Media, pageSize int) {
+	items := make([]map[string]interface{}, len(media))
+	for i := range media {
+		items[i] = mediaToResponse(&media[i])
+	}
+
+	writeTwilioList(w, r, twilioList{
+		Key:     "media_list",
+		Element: "MediaList",
+		Item:    "Media",
+		Items:   items,
+		Meta: map[string]interface{}{
+			"page":           0,
+			"page_size":      pageSize,
+			"uri":            r.URL.Path,
+			"first_page_uri": r.URL.Path,
+			"next_page_uri":  nil,
+		},
+	})
+}
+
+// accountMessage returns the message named in the URL if it belongs to the
+// authenticated account, writing a 404 otherwise
+func (p *TwilioPlugin) accountMessage(w http.ResponseWriter, r *http.Request) (*Message, bool) {
+	accountSid := r.Context().Value(accountSidKey).(string)
+
+	message, err := p.store.GetMessage(chi.URLParam(r, "MessageSid"))
+	if err != nil || message.AccountSid != accountSid {
+		writeError(w, http.StatusNotFound, 20404, "Message not found")
+		return nil, false
+	}
+	return message, true
+}
+
+// listMessageMedia handles GET .../Messages/{MessageSid}/Media.json
+func (p *TwilioPlugin) listMessageMedia(w http.ResponseWriter, r *http.Request) {
+	message, ok := p.accountMessage(w, r)
+	if !ok {
+		return
+	}
+
+	media, err := p.store.ListMessageMedia(message.Sid)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	writeMediaList(w, r, media, maxMediaPerMessage)
+}
+
+// deleteMessageMedia handles DELETE .../Messages/{MessageSid}/Media/{MediaSid}.json
+func (p *TwilioPlugin) deleteMessageMedia(w http.ResponseWriter, r *http.Request) {
+	message, ok := p.accountMessage(w, r)
+	if !ok {
+		return
+	}
+
+	err := p.store.DeleteMedia(message.AccountSid, message.Sid, chi.URLParam(r, "MediaSid"))
+	if err == sql.ErrNoRows {
+		writeError(w, http.StatusNotFound, 20404, "Media not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listAccountMedia handles GET /2010-04-01/Accounts/{AccountSid}/Media.json,
+// listing media across all of the account's messages
+func (p *TwilioPlugin) listAccountMedia(w http.ResponseWriter, r *http.Request) {
+	accountSid := r.Context().Value(accountSidKey).(string)
+
+	pageSize := 50
+	if ps := r.URL.Query().Get("PageSize"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 1000 {
+			pageSize = parsed
+		}
+	}
+
+	media, err := p.store.ListAccountMedia(accountSid, pageSize)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	writeMediaList(w, r, media, pageSize)
+}
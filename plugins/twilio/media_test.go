@@ -0,0 +1,124 @@
+// ABOUTME: Tests for Twilio message media
+// ABOUTME: Covers sending MediaUrl, listing media per message and per account, and deleting media
+
+package twilio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// mediaList decodes a media list response
+func mediaList(t *testing.T, body []byte) []map[string]interface{} {
+	t.Helper()
+	var resp struct {
+		MediaList []map[string]interface{} `json:"media_list"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("Failed to decode media list: %v", err)
+	}
+	return resp.MediaList
+}
+
+func TestMessageMediaListAndDelete(t *testing.T) {
+	store, acct, r := setupAccountsRouter(t)
+
+	form := url.Values{
+		"To":       {"+15551234567"},
+		"From":     {"+15559876543"},
+		"MediaUrl": {"https://example.com/cat.png", "https://example.com/clip"},
+	}
+	w := accountRequest(r, "POST", "/2010-04-01/Accounts/AC123/Messages.json", acct, form)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var msg struct {
+		Sid             string            `json:"sid"`
+		NumMedia        string            `json:"num_media"`
+		SubresourceURIs map[string]string `json:"subresource_uris"`
+	}
+	json.NewDecoder(w.Body).Decode(&msg)
+	if msg.NumMedia != "2" {
+		t.Errorf("num_media = %q, want 2", msg.NumMedia)
+	}
+	mediaPath := "/2010-04-01/Accounts/AC123/Messages/" + msg.Sid + "/Media.json"
+	if msg.SubresourceURIs["media"] != mediaPath {
+		t.Errorf("subresource_uris.media = %q, want %q", msg.SubresourceURIs["media"], mediaPath)
+	}
+
+	w = accountRequest(r, "GET", mediaPath, acct, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	media := mediaList(t, w.Body.Bytes())
+	if len(media) != 2 {
+		t.Fatalf("Expected 2 media items, got %d", len(media))
+	}
+	contentTypes := map[interface{}]bool{media[0]["content_type"]: true, media[1]["content_type"]: true}
+	if !contentTypes["image/png"] || !contentTypes["image/jpeg"] {
+		t.Errorf("content types = %v, want image/png and the image/jpeg default", contentTypes)
+	}
+	for _, m := range media {
+		if m["uri"] == "" || m["date_created"] == "" || m["parent_sid"] != msg.Sid {
+			t.Errorf("Incomplete media item: %v", m)
+		}
+	}
+
+	// Media on another message shows up in the account-wide list
+	other, _ := store.CreateMessage("AC123", "+15559876543", "+15551234567", "Another")
+	store.AddMessageMedia("AC123", other.Sid, "image/gif", "https://example.com/wave.gif")
+	w = accountRequest(r, "GET", "/2010-04-01/Accounts/AC123/Media.json", acct, nil)
+	if got := len(mediaList(t, w.Body.Bytes())); got != 3 {
+		t.Errorf("Expected 3 media items across the account, got %d", got)
+	}
+
+	deletePath := "/2010-04-01/Accounts/AC123/Messages/" + msg.Sid + "/Media/" + media[0]["sid"].(string)
+	w = accountRequest(r, "DELETE", deletePath, acct, nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	w = accountRequest(r, "DELETE", deletePath+".json", acct, nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting twice, got %d", w.Code)
+	}
+
+	w = accountRequest(r, "GET", "/2010-04-01/Accounts/AC123/Messages/"+msg.Sid+"/Media.json", acct, nil)
+	if remaining := mediaList(t, w.Body.Bytes()); len(remaining) != 1 || remaining[0]["sid"] == media[0]["sid"] {
+		t.Errorf("Expected only the undeleted media, got %v", remaining)
+	}
+	w = accountRequest(r, "GET", "/2010-04-01/Accounts/AC123/Messages/"+msg.Sid+".json", acct, nil)
+	json.NewDecoder(w.Body).Decode(&msg)
+	if msg.NumMedia != "1" {
+		t.Errorf("num_media after delete = %q, want 1", msg.NumMedia)
+	}
+
+	// Other accounts can't see the message's media
+	stranger, _ := store.GetOrCreateAccount("AC999")
+	w = accountRequest(r, "GET", mediaPath, stranger, nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for another account, got %d", w.Code)
+	}
+}
+
+func TestSendMessageMediaValidation(t *testing.T) {
+	_, acct, r := setupAccountsRouter(t)
+
+	tests := map[string]url.Values{
+		"relative url": {"To": {"+15551234567"}, "From": {"+15559876543"}, "MediaUrl": {"/cat.png"}},
+		"too many": {"To": {"+15551234567"}, "From": {"+15559876543"}, "MediaUrl": {
+			"https://example.com/1.png", "https://example.com/2.png", "https://example.com/3.png",
+			"https://example.com/4.png", "https://example.com/5.png", "https://example.com/6.png",
+			"https://example.com/7.png", "https://example.com/8.png", "https://example.com/9.png",
+			"https://example.com/10.png", "https://example.com/11.png",
+		}},
+		"no body or media": {"To": {"+15551234567"}, "From": {"+15559876543"}},
+	}
+	for name, form := range tests {
+		w := accountRequest(r, "POST", "/2010-04-01/Accounts/AC123/Messages.json", acct, form)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+}
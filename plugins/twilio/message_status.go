@@ -0,0 +1,111 @@
+// ABOUTME: Message status progression for the Twilio plugin
+// ABOUTME: A background worker moves sent messages through queued → sending → sent → delivered, firing status callbacks
+
+package twilio
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables that tune message status progression
+const (
+	DeliveryDelayEnv       = "ISH_TWILIO_DELIVERY_DELAY_MS"
+	DeliveryFailureRateEnv = "ISH_TWILIO_DELIVERY_FAILURE_RATE"
+)
+
+const (
+	// DefaultDeliveryDelay is the time between status transitions when
+	// ISH_TWILIO_DELIVERY_DELAY_MS is unset
+	DefaultDeliveryDelay = 500 * time.Millisecond
+	// DefaultDeliveryFailureRate is the share of messages that end up
+	// undelivered when ISH_TWILIO_DELIVERY_FAILURE_RATE is unset
+	DefaultDeliveryFailureRate = 0.05
+
+	// messageStatusPollInterval is how often the worker looks for messages due a transition
+	messageStatusPollInterval = 50 * time.Millisecond
+)
+
+// deliveryConfigFromEnv reads the delay between transitions and the failure
+// rate, returning the defaults for unset variables
+func deliveryConfigFromEnv() (time.Duration, float64, error) {
+	delay, rate := DefaultDeliveryDelay, DefaultDeliveryFailureRate
+	if value := os.Getenv(DeliveryDelayEnv); value != "" {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 {
+			return 0, 0, fmt.Errorf("invalid %s %q: expected a non-negative number of milliseconds", DeliveryDelayEnv, value)
+		}
+		delay = time.Duration(ms) * time.Millisecond
+	}
+	if value := os.Getenv(DeliveryFailureRateEnv); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return 0, 0, fmt.Errorf("invalid %s %q: expected a number from 0 to 1", DeliveryFailureRateEnv, value)
+		}
+		rate = parsed
+	}
+	return delay, rate, nil
+}
+
+// nextMessageStatus is the status a message moves to from status, and
+// whether that is final. failed decides whether a sent message is delivered.
+func nextMessageStatus(status string, failed bool) (next string, final bool) {
+	switch status {
+	case "queued":
+		return "sending", false
+	case "sending":
+		return "sent", false
+	case "sent":
+		if failed {
+			return "undelivered", true
+		}
+		return "delivered", true
+	}
+	return status, true
+}
+
+// StartMessageStatusWorker advances messages that are due a status
+// transition until ctx is done
+func (p *TwilioPlugin) StartMessageStatusWorker(ctx context.Context) {
+	ticker := time.NewTicker(messageStatusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.processMessageStatuses(time.Now())
+		}
+	}
+}
+
+// processMessageStatuses moves every message due a transition at now to its
+// next status, scheduling the one after, and queues its status callback
+func (p *TwilioPlugin) processMessageStatuses(now time.Time) {
+	messages, err := p.store.GetMessagesDueStatus(now)
+	if err != nil {
+		log.Printf("Error fetching messages due a status change: %v", err)
+		return
+	}
+
+	for _, msg := range messages {
+		status, final := nextMessageStatus(msg.Status, p.deliveryRand.Float64() < p.deliveryFailureRate)
+		var nextAt *time.Time
+		if !final {
+			at := now.Add(p.deliveryDelay)
+			nextAt = &at
+		}
+		if err := p.store.SetMessageStatus(msg.Sid, status, nextAt); err != nil {
+			log.Printf("Error updating status of message %s: %v", msg.Sid, err)
+			continue
+		}
+		if err := p.QueueMessageWebhook(msg.Sid, status, 0); err != nil {
+			log.Printf("Failed to queue webhook for message %s: %v", msg.Sid, err)
+		}
+	}
+}
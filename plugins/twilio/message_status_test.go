@@ -0,0 +1,177 @@
+// ABOUTME: Tests for message status progression
+// ABOUTME: Covers the queued → sending → sent → delivered state machine, failures, and env config
+
+package twilio
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// sendProgressingMessage sends a message from a number with a status
+// callback through a plugin with no background workers, so the test drives
+// every transition
+func sendProgressingMessage(t *testing.T, failureRate float64) (*TwilioPlugin, string) {
+	t.Helper()
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	store, err := NewTwilioStore(db)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	plugin := &TwilioPlugin{store: store, deliveryDelay: 500 * time.Millisecond, deliveryFailureRate: failureRate, deliveryRand: rand.New(rand.NewSource(1))}
+
+	account, _ := store.GetOrCreateAccount("AC123")
+	phoneNumber, _ := store.CreatePhoneNumber("AC123", "+15559876543", "Sender")
+	if err := store.SetStatusCallback(phoneNumber.Sid, "https://example.com/status", 0); err != nil {
+		t.Fatalf("Failed to set status callback: %v", err)
+	}
+
+	form := url.Values{"To": {"+15551234567"}, "From": {"+15559876543"}, "Body": {"Hello"}}
+	req := httptest.NewRequest("POST", "/2010-04-01/Accounts/AC123/Messages.json", bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Authorization", basicAuth("AC123", account.AuthToken))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	plugin.requireAuth(plugin.sendMessage).ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.NewDecoder(rr.Body).Decode(&response)
+	return plugin, response["sid"].(string)
+}
+
+// callbackStatuses lists the MessageStatus of every status callback queued for a message
+func callbackStatuses(t *testing.T, plugin *TwilioPlugin, sid string) []string {
+	t.Helper()
+	rows, err := plugin.store.db.Query(`SELECT payload FROM twilio_webhook_queue WHERE resource_sid = ? ORDER BY id`, sid)
+	if err != nil {
+		t.Fatalf("Failed to query webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	var statuses []string
+	for rows.Next() {
+		var payload string
+		rows.Scan(&payload)
+		values, _ := url.ParseQuery(payload)
+		statuses = append(statuses, values.Get("MessageStatus"))
+	}
+	return statuses
+}
+
+func TestMessageStatusProgression(t *testing.T) {
+	plugin, sid := sendProgressingMessage(t, 0)
+	now := time.Now()
+
+	// Nothing happens until the delay has passed
+	plugin.processMessageStatuses(now.Add(400 * time.Millisecond))
+	if msg, _ := plugin.store.GetMessage(sid); msg.Status != "queued" {
+		t.Fatalf("Expected queued before the delay, got %s", msg.Status)
+	}
+
+	for i, want := range []string{"sending", "sent", "delivered"} {
+		plugin.processMessageStatuses(now.Add(time.Duration(i+1) * 600 * time.Millisecond))
+		msg, _ := plugin.store.GetMessage(sid)
+		if msg.Status != want {
+			t.Fatalf("Transition %d: expected %s, got %s", i+1, want, msg.Status)
+		}
+		if want == "sending" && msg.DateSent != nil {
+			t.Errorf("date_sent set before the message was sent")
+		}
+		if want == "sent" && msg.DateSent == nil {
+			t.Errorf("Expected date_sent once sent")
+		}
+	}
+
+	// Delivered is final
+	plugin.processMessageStatuses(now.Add(time.Hour))
+	if msg, _ := plugin.store.GetMessage(sid); msg.Status != "delivered" {
+		t.Errorf("Expected delivered to be final, got %s", msg.Status)
+	}
+
+	got := callbackStatuses(t, plugin, sid)
+	want := []string{"queued", "sending", "sent", "delivered"}
+	if len(got) != len(want) {
+		t.Fatalf("Status callbacks = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Status callbacks = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMessageStatusUndelivered(t *testing.T) {
+	plugin, sid := sendProgressingMessage(t, 1)
+	now := time.Now()
+
+	for i := 1; i <= 4; i++ {
+		plugin.processMessageStatuses(now.Add(time.Duration(i) * time.Second))
+	}
+
+	if msg, _ := plugin.store.GetMessage(sid); msg.Status != "undelivered" {
+		t.Fatalf("Expected undelivered, got %s", msg.Status)
+	}
+	if got := callbackStatuses(t, plugin, sid); len(got) != 4 || got[3] != "undelivered" {
+		t.Errorf("Unexpected status callbacks: %v", got)
+	}
+}
+
+func TestDeliveryConfigFromEnv(t *testing.T) {
+	delay, rate, err := deliveryConfigFromEnv()
+	if err != nil || delay != DefaultDeliveryDelay || rate != DefaultDeliveryFailureRate {
+		t.Errorf("Defaults = %v, %v, %v", delay, rate, err)
+	}
+
+	t.Setenv(DeliveryDelayEnv, "50")
+	t.Setenv(DeliveryFailureRateEnv, "0.5")
+	delay, rate, err = deliveryConfigFromEnv()
+	if err != nil || delay != 50*time.Millisecond || rate != 0.5 {
+		t.Errorf("Configured = %v, %v, %v", delay, rate, err)
+	}
+
+	for _, tt := range []struct{ env, value string }{
+		{DeliveryDelayEnv, "-1"},
+		{DeliveryDelayEnv, "soon"},
+		{DeliveryFailureRateEnv, "1.5"},
+	} {
+		t.Setenv(DeliveryDelayEnv, "")
+		t.Setenv(DeliveryFailureRateEnv, "")
+		t.Setenv(tt.env, tt.value)
+		if _, _, err := deliveryConfigFromEnv(); err == nil {
+			t.Errorf("Expected an error for %s=%s", tt.env, tt.value)
+		}
+	}
+}
+
+func TestDeliveryFailuresFollowRandomSeed(t *testing.T) {
+	t.Setenv(core.RandomSeedEnv, "42")
+	t.Setenv(DeliveryFailureRateEnv, "0.5")
+
+	outcomes := func() []bool {
+		plugin, db := setupTestPlugin(t)
+		defer db.Close()
+		defer plugin.stopWorker()
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			failed = append(failed, plugin.deliveryRand.Float64() < plugin.deliveryFailureRate)
+		}
+		return failed
+	}
+	first, second := outcomes(), outcomes()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected the same delivery failures with the same seed, got %v and %v", first, second)
+		}
+	}
+}
@@ -8,8 +8,11 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/2389/ish/plugins/core"
 	"github.com/go-chi/chi/v5"
@@ -26,6 +29,16 @@ func init() {
 
 type TwilioPlugin struct {
 	store *TwilioStore
+
+	// deliveryDelay separates a message's status transitions, and
+	// deliveryFailureRate is the share of messages that end up undelivered,
+	// drawn from deliveryRand so ISH_RANDOM_SEED makes it repeatable
+	deliveryDelay       time.Duration
+	deliveryFailureRate float64
+	deliveryRand        *rand.Rand
+
+	stopWorker context.CancelFunc
+	workers    sync.WaitGroup
 }
 
 func (p *TwilioPlugin) Name() string {
@@ -40,22 +53,47 @@ func (p *TwilioPlugin) Health() core.HealthStatus {
 }
 
 func (p *TwilioPlugin) RegisterRoutes(r chi.Router) {
-	// SMS API
-	r.Route("/2010-04-01/Accounts/{AccountSid}/Messages.json", func(r chi.Router) {
-		r.Post("/", p.requireAuth(p.sendMessage))
-		r.Get("/", p.requireAuth(p.listMessages))
-	})
-	r.Get("/2010-04-01/Accounts/{AccountSid}/Messages/{MessageSid}.json", p.requireAuth(p.getMessage))
-
-	// Voice API
-	r.Route("/2010-04-01/Accounts/{AccountSid}/Calls.json", func(r chi.Router) {
-		r.Post("/", p.requireAuth(p.initiateCall))
-		r.Get("/", p.requireAuth(p.listCalls))
-	})
-	r.Get("/2010-04-01/Accounts/{AccountSid}/Calls/{CallSid}.json", p.requireAuth(p.getCall))
-
-	// Phone Numbers API
-	r.Get("/2010-04-01/Accounts/{AccountSid}/IncomingPhoneNumbers.json", p.requireAuth(p.listPhoneNumbers))
+	// REST resources are served as XML without an extension and as JSON
+	// with .json; see writeTwilioResource
+	for _, ext := range []string{"", ".json"} {
+		// Accounts API (subaccounts)
+		r.Post("/2010-04-01/Accounts"+ext, p.requireAuth(p.createAccount))
+		r.Get("/2010-04-01/Accounts"+ext, p.requireAuth(p.listAccounts))
+		r.Get("/2010-04-01/Accounts/{AccountSid}"+ext, p.requireAuth(p.getAccount))
+		r.Post("/2010-04-01/Accounts/{AccountSid}"+ext, p.requireAuth(p.updateAccount))
+
+		// SMS API
+		r.Post("/2010-04-01/Accounts/{AccountSid}/Messages"+ext, p.requireAuth(p.idempotent(p.sendMessage)))
+		r.Get("/2010-04-01/Accounts/{AccountSid}/Messages"+ext, p.requireAuth(p.listMessages))
+		r.Get("/2010-04-01/Accounts/{AccountSid}/Messages/{MessageSid}"+ext, p.requireAuth(p.getMessage))
+
+		// Message media
+		r.Get("/2010-04-01/Accounts/{AccountSid}/Messages/{MessageSid}/Media"+ext, p.requireAuth(p.listMessageMedia))
+		r.Delete("/2010-04-01/Accounts/{AccountSid}/Messages/{MessageSid}/Media/{MediaSid}"+ext, p.requireAuth(p.deleteMessageMedia))
+		r.Get("/2010-04-01/Accounts/{AccountSid}/Media"+ext, p.requireAuth(p.listAccountMedia))
+
+		// Voice API
+		r.Post("/2010-04-01/Accounts/{AccountSid}/Calls"+ext, p.requireAuth(p.initiateCall))
+		r.Get("/2010-04-01/Accounts/{AccountSid}/Calls"+ext, p.requireAuth(p.listCalls))
+		r.Get("/2010-04-01/Accounts/{AccountSid}/Calls/{CallSid}"+ext, p.requireAuth(p.getCall))
+
+		// Phone Numbers API
+		r.Get("/2010-04-01/Accounts/{AccountSid}/IncomingPhoneNumbers"+ext, p.requireAuth(p.listPhoneNumbers))
+		r.Post("/2010-04-01/Accounts/{AccountSid}/IncomingPhoneNumbers"+ext, p.requireAuth(p.provisionPhoneNumber))
+		r.Get("/2010-04-01/Accounts/{AccountSid}/AvailablePhoneNumbers/{CountryCode}/Local"+ext, p.requireAuth(p.listAvailableLocalNumbers))
+	}
+
+	// Studio flows
+	r.Post("/v1/Flows", p.requireAuth(p.createFlow))
+	r.Get("/v1/Flows/{FlowSid}", p.requireAuth(p.getFlow))
+	r.Post("/v1/Flows/{FlowSid}/Executions", p.requireAuth(p.createFlowExecution))
+	r.Get("/v1/Flows/{FlowSid}/Executions/{ExecutionSid}", p.requireAuth(p.getFlowExecution))
+
+	// TwiML for simulated incoming calls and messages, requested like a number's voice_url/sms_url
+	r.Post("/twiml/voice", p.serveTwiML("voice"))
+	r.Post("/twiml/sms", p.serveTwiML("sms"))
+	r.Post("/admin/twilio/twiml/{phone_number_sid}", p.configureTwiML)
+	r.Post("/admin/twilio/status_callback/{phone_number_sid}", p.configureStatusCallback)
 }
 
 func (p *TwilioPlugin) RegisterAuth(r chi.Router) {
@@ -83,6 +121,15 @@ func extractBasicAuth(authHeader string) (username, password string, ok bool) {
 	return credentials[0], credentials[1], true
 }
 
+// idempotent lets clients retry a send with an Idempotency-Key without
+// sending twice. It must run inside requireAuth.
+func (p *TwilioPlugin) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountSid, _ := r.Context().Value(accountSidKey).(string)
+		core.HandleIdempotent(p.store.db, accountSid, w, r, next)
+	}
+}
+
 // requireAuth middleware validates HTTP Basic Auth (Account SID + Auth Token)
 func (p *TwilioPlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -130,12 +177,45 @@ func (p *TwilioPlugin) SetDB(db *sql.DB) error {
 	}
 	p.store = store
 
-	// Start webhook worker
-	go p.StartWebhookWorker(context.Background())
+	delay, failureRate, err := deliveryConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	rng, err := core.NewRand("twilio:delivery")
+	if err != nil {
+		return err
+	}
+	p.deliveryDelay, p.deliveryFailureRate, p.deliveryRand = delay, failureRate, rng
+
+	// Start the webhook and message status workers, replacing any bound to a
+	// previous database
+	if p.stopWorker != nil {
+		p.stopWorker()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stopWorker = cancel
+	p.workers.Add(2)
+	go func() {
+		defer p.workers.Done()
+		p.StartWebhookWorker(ctx)
+	}()
+	go func() {
+		defer p.workers.Done()
+		p.StartMessageStatusWorker(ctx)
+	}()
 
 	return nil
 }
 
+// Shutdown implements core.Shutdowner, stopping the workers after any
+// delivery or status change in progress completes
+func (p *TwilioPlugin) Shutdown(ctx context.Context) error {
+	if p.stopWorker != nil {
+		p.stopWorker()
+	}
+	return core.WaitGroupContext(ctx, &p.workers)
+}
+
 // ListResources implements core.DataProvider to expose data to admin UI
 func (p *TwilioPlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
 	switch slug {
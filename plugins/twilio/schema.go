@@ -3,7 +3,11 @@
 
 package twilio
 
-import "github.com/2389/ish/plugins/core"
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
 
 func (p *TwilioPlugin) Schema() core.PluginSchema {
 	return core.PluginSchema{
@@ -66,3 +70,41 @@ func (p *TwilioPlugin) Schema() core.PluginSchema {
 		},
 	}
 }
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *TwilioPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "twilio", Table: "twilio_messages", Resource: "messages"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *TwilioPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"twilio": {
+			"twilio_flow_executions",
+			"twilio_flows",
+			"twilio_webhook_queue",
+			"twilio_webhook_configs",
+			"twilio_twiml_responses",
+			"twilio_calls",
+			"twilio_message_media",
+			"twilio_messages",
+			"twilio_phone_numbers",
+			"twilio_accounts",
+		},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *TwilioPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *TwilioPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
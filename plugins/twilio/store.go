@@ -8,126 +8,66 @@ import (
 	"crypto/subtle"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"time"
+
+	"github.com/2389/ish/plugins/core"
 )
 
 type TwilioStore struct {
 	db *sql.DB
 }
 
+// withTx runs fn in a transaction, committing only if it succeeds
+func (s *TwilioStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// auditIn records a mutation of a Twilio resource in the shared audit log
+// through db, the transaction making the change so both commit together. The
+// actor is the SID of the account making the change.
+func auditIn(db core.Execer, resourceType, resourceID, action, actor string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "twilio",
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
 type Account struct {
 	AccountSid   string
 	AuthToken    string
 	FriendlyName string
 	Status       string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// OwnerAccountSid is the parent of a subaccount, and empty for a main account
+	OwnerAccountSid string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
+// Account statuses
+const (
+	AccountStatusActive    = "active"
+	AccountStatusSuspended = "suspended"
+	AccountStatusClosed    = "closed"
+)
+
+// ErrAccountClosed is returned when updating an account that has been closed
+var ErrAccountClosed = errors.New("account is closed")
+
 func NewTwilioStore(db *sql.DB) (*TwilioStore, error) {
-	store := &TwilioStore{db: db}
-	if err := store.initTables(); err != nil {
-		return nil, err
-	}
-	return store, nil
-}
-
-func (s *TwilioStore) initTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS twilio_accounts (
-			account_sid TEXT PRIMARY KEY,
-			auth_token TEXT NOT NULL,
-			friendly_name TEXT,
-			status TEXT DEFAULT 'active',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-
-		`CREATE TABLE IF NOT EXISTS twilio_phone_numbers (
-			sid TEXT PRIMARY KEY,
-			account_sid TEXT NOT NULL,
-			phone_number TEXT NOT NULL,
-			friendly_name TEXT,
-			voice_url TEXT,
-			voice_method TEXT DEFAULT 'POST',
-			sms_url TEXT,
-			sms_method TEXT DEFAULT 'POST',
-			status_callback TEXT,
-			status_callback_method TEXT DEFAULT 'POST',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_phone_numbers_account ON twilio_phone_numbers(account_sid)`,
-
-		`CREATE TABLE IF NOT EXISTS twilio_messages (
-			sid TEXT PRIMARY KEY,
-			account_sid TEXT NOT NULL,
-			from_number TEXT NOT NULL,
-			to_number TEXT NOT NULL,
-			body TEXT,
-			status TEXT DEFAULT 'queued',
-			direction TEXT,
-			date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			date_sent TIMESTAMP,
-			date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			num_segments INTEGER DEFAULT 1,
-			price REAL,
-			price_unit TEXT DEFAULT 'USD',
-			FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_messages_account ON twilio_messages(account_sid)`,
-		`CREATE INDEX IF NOT EXISTS idx_messages_status ON twilio_messages(status)`,
-		`CREATE INDEX IF NOT EXISTS idx_messages_date ON twilio_messages(date_created)`,
-
-		`CREATE TABLE IF NOT EXISTS twilio_calls (
-			sid TEXT PRIMARY KEY,
-			account_sid TEXT NOT NULL,
-			from_number TEXT NOT NULL,
-			to_number TEXT NOT NULL,
-			status TEXT DEFAULT 'initiated',
-			direction TEXT,
-			duration INTEGER,
-			date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			date_updated TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			answered_by TEXT,
-			FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_calls_account ON twilio_calls(account_sid)`,
-		`CREATE INDEX IF NOT EXISTS idx_calls_status ON twilio_calls(status)`,
-
-		`CREATE TABLE IF NOT EXISTS twilio_webhook_configs (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			account_sid TEXT NOT NULL,
-			resource_type TEXT NOT NULL,
-			event_type TEXT NOT NULL,
-			url TEXT NOT NULL,
-			method TEXT DEFAULT 'POST',
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (account_sid) REFERENCES twilio_accounts(account_sid)
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_webhook_configs_account ON twilio_webhook_configs(account_sid)`,
-
-		`CREATE TABLE IF NOT EXISTS twilio_webhook_queue (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			resource_sid TEXT NOT NULL,
-			webhook_url TEXT NOT NULL,
-			payload TEXT NOT NULL,
-			scheduled_at TIMESTAMP NOT NULL,
-			delivered_at TIMESTAMP,
-			status TEXT DEFAULT 'pending',
-			attempts INTEGER DEFAULT 0,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_webhook_queue_schedule ON twilio_webhook_queue(scheduled_at, status)`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return err
-		}
-	}
-	return nil
+	return &TwilioStore{db: db}, nil
 }
 
 func generateAuthToken() (string, error) {
@@ -140,26 +80,9 @@ func generateAuthToken() (string, error) {
 
 func (s *TwilioStore) GetOrCreateAccount(accountSid string) (*Account, error) {
 	// Try to get existing account
-	var account Account
-	var friendlyName sql.NullString
-	err := s.db.QueryRow(`
-		SELECT account_sid, auth_token, friendly_name, status, created_at, updated_at
-		FROM twilio_accounts
-		WHERE account_sid = ?
-	`, accountSid).Scan(
-		&account.AccountSid,
-		&account.AuthToken,
-		&friendlyName,
-		&account.Status,
-		&account.CreatedAt,
-		&account.UpdatedAt,
-	)
-
-	if err == nil {
-		if friendlyName.Valid {
-			account.FriendlyName = friendlyName.String
-		}
-		return &account, nil
+	account, err := s.GetAccount(accountSid)
+	if err != sql.ErrNoRows {
+		return account, err
 	}
 
 	// Account doesn't exist, create it
@@ -177,8 +100,15 @@ func (s *TwilioStore) GetOrCreateAccount(accountSid string) (*Account, error) {
 	}
 
 	// Fetch the newly created account
-	err = s.db.QueryRow(`
-		SELECT account_sid, auth_token, friendly_name, status, created_at, updated_at
+	return s.GetAccount(accountSid)
+}
+
+// GetAccount returns an account by SID, or sql.ErrNoRows if it does not exist
+func (s *TwilioStore) GetAccount(accountSid string) (*Account, error) {
+	var account Account
+	var friendlyName, ownerSid sql.NullString
+	err := s.db.QueryRow(`
+		SELECT account_sid, auth_token, friendly_name, status, owner_account_sid, created_at, updated_at
 		FROM twilio_accounts
 		WHERE account_sid = ?
 	`, accountSid).Scan(
@@ -186,6 +116,7 @@ func (s *TwilioStore) GetOrCreateAccount(accountSid string) (*Account, error) {
 		&account.AuthToken,
 		&friendlyName,
 		&account.Status,
+		&ownerSid,
 		&account.CreatedAt,
 		&account.UpdatedAt,
 	)
@@ -193,11 +124,104 @@ func (s *TwilioStore) GetOrCreateAccount(accountSid string) (*Account, error) {
 		return nil, err
 	}
 
-	if friendlyName.Valid {
-		account.FriendlyName = friendlyName.String
+	account.FriendlyName = friendlyName.String
+	account.OwnerAccountSid = ownerSid.String
+	return &account, nil
+}
+
+// CreateSubaccount creates an active account owned by ownerSid with a new SID and auth token
+func (s *TwilioStore) CreateSubaccount(ownerSid, friendlyName string) (*Account, error) {
+	accountSid, err := generateSID("AC")
+	if err != nil {
+		return nil, err
+	}
+	authToken, err := generateAuthToken()
+	if err != nil {
+		return nil, err
 	}
 
-	return &account, nil
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO twilio_accounts (account_sid, auth_token, friendly_name, owner_account_sid)
+			VALUES (?, ?, ?, ?)
+		`, accountSid, authToken, friendlyName, ownerSid)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "account", accountSid, core.AuditCreate, ownerSid, map[string]any{"friendly_name": friendlyName})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAccount(accountSid)
+}
+
+// ListOwnedAccounts returns an account followed by its subaccounts, oldest first
+func (s *TwilioStore) ListOwnedAccounts(accountSid string) ([]Account, error) {
+	rows, err := s.db.Query(`
+		SELECT account_sid, auth_token, friendly_name, status, owner_account_sid, created_at, updated_at
+		FROM twilio_accounts
+		WHERE account_sid = ? OR owner_account_sid = ?
+		ORDER BY account_sid = ? DESC, created_at, account_sid
+	`, accountSid, accountSid, accountSid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		var acct Account
+		var friendlyName, ownerSid sql.NullString
+		err := rows.Scan(
+			&acct.AccountSid, &acct.AuthToken, &friendlyName,
+			&acct.Status, &ownerSid, &acct.CreatedAt, &acct.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		acct.FriendlyName = friendlyName.String
+		acct.OwnerAccountSid = ownerSid.String
+		accounts = append(accounts, acct)
+	}
+	return accounts, rows.Err()
+}
+
+// UpdateAccount changes an account's friendly name and status; empty values
+// are left unchanged. Closed accounts cannot be changed.
+func (s *TwilioStore) UpdateAccount(accountSid, friendlyName, status string) (*Account, error) {
+	account, err := s.GetAccount(accountSid)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status == AccountStatusClosed {
+		return nil, ErrAccountClosed
+	}
+
+	if friendlyName == "" {
+		friendlyName = account.FriendlyName
+	}
+	if status == "" {
+		status = account.Status
+	}
+
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE twilio_accounts
+			SET friendly_name = ?, status = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE account_sid = ?
+		`, friendlyName, status, accountSid)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "account", accountSid, core.AuditUpdate, accountSid, map[string]any{"friendly_name": friendlyName, "status": status})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAccount(accountSid)
 }
 
 func (s *TwilioStore) ValidateAccount(accountSid, authToken string) bool {
@@ -226,10 +250,17 @@ type Message struct {
 	DateSent    *time.Time
 	DateUpdated time.Time
 	NumSegments int
+	NumMedia    int
 	Price       float64
 	PriceUnit   string
 }
 
+// messageColumns are the twilio_messages columns scanned into a Message, in order
+const messageColumns = `sid, account_sid, from_number, to_number, body, status, direction,
+		       date_created, date_sent, date_updated, num_segments,
+		       (SELECT COUNT(*) FROM twilio_message_media WHERE message_sid = twilio_messages.sid),
+		       price, price_unit`
+
 func generateSID(prefix string) (string, error) {
 	bytes := make([]byte, 16)
 	if _, err := rand.Read(bytes); err != nil {
@@ -250,11 +281,16 @@ func (s *TwilioStore) CreateMessage(accountSid, from, to, body string) (*Message
 		numSegments = 1
 	}
 
-	_, err = s.db.Exec(`
-		INSERT INTO twilio_messages (sid, account_sid, from_number, to_number, body, status, direction, num_segments, price, price_unit)
-		VALUES (?, ?, ?, ?, ?, 'queued', 'outbound-api', ?, ?, 'USD')
-	`, sid, accountSid, from, to, body, numSegments, float64(numSegments)*0.0075)
-
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO twilio_messages (sid, account_sid, from_number, to_number, body, status, direction, num_segments, price, price_unit)
+			VALUES (?, ?, ?, ?, ?, 'queued', 'outbound-api', ?, ?, 'USD')
+		`, sid, accountSid, from, to, body, numSegments, float64(numSegments)*0.0075)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "message", sid, core.AuditCreate, accountSid, map[string]any{"from": from, "to": to, "body": body})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -267,14 +303,13 @@ func (s *TwilioStore) GetMessage(sid string) (*Message, error) {
 	var dateSent sql.NullTime
 
 	err := s.db.QueryRow(`
-		SELECT sid, account_sid, from_number, to_number, body, status, direction,
-		       date_created, date_sent, date_updated, num_segments, price, price_unit
+		SELECT `+messageColumns+`
 		FROM twilio_messages
 		WHERE sid = ?
 	`, sid).Scan(
 		&msg.Sid, &msg.AccountSid, &msg.FromNumber, &msg.ToNumber, &msg.Body,
 		&msg.Status, &msg.Direction, &msg.DateCreated, &dateSent, &msg.DateUpdated,
-		&msg.NumSegments, &msg.Price, &msg.PriceUnit,
+		&msg.NumSegments, &msg.NumMedia, &msg.Price, &msg.PriceUnit,
 	)
 
 	if err != nil {
@@ -289,19 +324,68 @@ func (s *TwilioStore) GetMessage(sid string) (*Message, error) {
 }
 
 func (s *TwilioStore) UpdateMessageStatus(sid, status string) error {
+	return s.SetMessageStatus(sid, status, nil)
+}
+
+// SetMessageStatus changes a message's status and when it is next due a
+// status transition; a nil nextStatusAt leaves it where it is
+func (s *TwilioStore) SetMessageStatus(sid, status string, nextStatusAt *time.Time) error {
 	now := time.Now()
-	_, err := s.db.Exec(`
-		UPDATE twilio_messages
-		SET status = ?, date_updated = ?, date_sent = CASE WHEN ? IN ('sent', 'delivered') AND date_sent IS NULL THEN ? ELSE date_sent END
-		WHERE sid = ?
-	`, status, now, status, now, sid)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE twilio_messages
+			SET status = ?, next_status_at = ?, date_updated = ?,
+				date_sent = CASE WHEN ? IN ('sent', 'delivered', 'undelivered') AND date_sent IS NULL THEN ? ELSE date_sent END
+			WHERE sid = ?
+		`, status, nextStatusAt, now, status, now, sid)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "message", sid, core.AuditUpdate, "", map[string]any{"status": status})
+	})
+}
+
+// GetMessagesDueStatus returns messages whose next status transition is due at now
+func (s *TwilioStore) GetMessagesDueStatus(now time.Time) ([]Message, error) {
+	rows, err := s.db.Query(`
+		SELECT `+messageColumns+`
+		FROM twilio_messages
+		WHERE status IN ('queued', 'sending', 'sent') AND next_status_at <= ?
+		ORDER BY next_status_at ASC
+		LIMIT 100
+	`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var dateSent sql.NullTime
+
+		err := rows.Scan(
+			&msg.Sid, &msg.AccountSid, &msg.FromNumber, &msg.ToNumber, &msg.Body,
+			&msg.Status, &msg.Direction, &msg.DateCreated, &dateSent, &msg.DateUpdated,
+			&msg.NumSegments, &msg.NumMedia, &msg.Price, &msg.PriceUnit,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if dateSent.Valid {
+			msg.DateSent = &dateSent.Time
+		}
+
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
 }
 
 func (s *TwilioStore) ListMessages(accountSid string, limit int) ([]Message, error) {
 	rows, err := s.db.Query(`
-		SELECT sid, account_sid, from_number, to_number, body, status, direction,
-		       date_created, date_sent, date_updated, num_segments, price, price_unit
+		SELECT `+messageColumns+`
 		FROM twilio_messages
 		WHERE account_sid = ?
 		ORDER BY date_created DESC
@@ -320,7 +404,7 @@ func (s *TwilioStore) ListMessages(accountSid string, limit int) ([]Message, err
 		err := rows.Scan(
 			&msg.Sid, &msg.AccountSid, &msg.FromNumber, &msg.ToNumber, &msg.Body,
 			&msg.Status, &msg.Direction, &msg.DateCreated, &dateSent, &msg.DateUpdated,
-			&msg.NumSegments, &msg.Price, &msg.PriceUnit,
+			&msg.NumSegments, &msg.NumMedia, &msg.Price, &msg.PriceUnit,
 		)
 		if err != nil {
 			return nil, err
@@ -336,6 +420,115 @@ func (s *TwilioStore) ListMessages(accountSid string, limit int) ([]Message, err
 	return messages, nil
 }
 
+// Media is a file attached to a message
+type Media struct {
+	Sid         string
+	AccountSid  string
+	MessageSid  string
+	ContentType string
+	URL         string
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// AddMessageMedia attaches the file at url to a message
+func (s *TwilioStore) AddMessageMedia(accountSid, messageSid, contentType, url string) (*Media, error) {
+	sid, err := generateSID("ME")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO twilio_message_media (sid, account_sid, message_sid, content_type, url)
+			VALUES (?, ?, ?, ?, ?)
+		`, sid, accountSid, messageSid, contentType, url)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "media", sid, core.AuditCreate, accountSid, map[string]any{"message_sid": messageSid, "url": url})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetMedia(sid)
+}
+
+// GetMedia returns a media item by SID, or sql.ErrNoRows if it does not exist
+func (s *TwilioStore) GetMedia(sid string) (*Media, error) {
+	var media Media
+	err := s.db.QueryRow(`
+		SELECT sid, account_sid, message_sid, content_type, url, date_created, date_updated
+		FROM twilio_message_media
+		WHERE sid = ?
+	`, sid).Scan(
+		&media.Sid, &media.AccountSid, &media.MessageSid, &media.ContentType,
+		&media.URL, &media.DateCreated, &media.DateUpdated,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// ListMessageMedia returns the media attached to a message, oldest first
+func (s *TwilioStore) ListMessageMedia(messageSid string) ([]Media, error) {
+	return s.queryMedia(`
+		SELECT sid, account_sid, message_sid, content_type, url, date_created, date_updated
+		FROM twilio_message_media
+		WHERE message_sid = ?
+		ORDER BY date_created, sid
+	`, messageSid)
+}
+
+// ListAccountMedia returns the media attached to any of an account's messages, newest first
+func (s *TwilioStore) ListAccountMedia(accountSid string, limit int) ([]Media, error) {
+	return s.queryMedia(`
+		SELECT sid, account_sid, message_sid, content_type, url, date_created, date_updated
+		FROM twilio_message_media
+		WHERE account_sid = ?
+		ORDER BY date_created DESC, sid
+		LIMIT ?
+	`, accountSid, limit)
+}
+
+func (s *TwilioStore) queryMedia(query string, args ...any) ([]Media, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []Media
+	for rows.Next() {
+		var m Media
+		err := rows.Scan(&m.Sid, &m.AccountSid, &m.MessageSid, &m.ContentType, &m.URL, &m.DateCreated, &m.DateUpdated)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
+
+// DeleteMedia removes a media item from a message, returning sql.ErrNoRows
+// if the message has no such media
+func (s *TwilioStore) DeleteMedia(accountSid, messageSid, mediaSid string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			DELETE FROM twilio_message_media WHERE sid = ? AND message_sid = ? AND account_sid = ?
+		`, mediaSid, messageSid, accountSid)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return auditIn(tx, "media", mediaSid, core.AuditDelete, accountSid, nil)
+	})
+}
+
 type Call struct {
 	Sid         string
 	AccountSid  string
@@ -355,11 +548,16 @@ func (s *TwilioStore) CreateCall(accountSid, from, to string) (*Call, error) {
 		return nil, err
 	}
 
-	_, err = s.db.Exec(`
-		INSERT INTO twilio_calls (sid, account_sid, from_number, to_number, status, direction)
-		VALUES (?, ?, ?, ?, 'initiated', 'outbound-api')
-	`, sid, accountSid, from, to)
-
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO twilio_calls (sid, account_sid, from_number, to_number, status, direction)
+			VALUES (?, ?, ?, ?, 'initiated', 'outbound-api')
+		`, sid, accountSid, from, to)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "call", sid, core.AuditCreate, accountSid, map[string]any{"from": from, "to": to})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -399,22 +597,30 @@ func (s *TwilioStore) GetCall(sid string) (*Call, error) {
 	return &call, nil
 }
 
+// UpdateCallStatus advances a simulated call, so no account is recorded as the actor
 func (s *TwilioStore) UpdateCallStatus(sid, status string, duration *int) error {
-	if duration != nil {
-		_, err := s.db.Exec(`
-			UPDATE twilio_calls
-			SET status = ?, date_updated = ?, duration = ?
-			WHERE sid = ?
-		`, status, time.Now(), *duration, sid)
-		return err
-	}
-
-	_, err := s.db.Exec(`
-		UPDATE twilio_calls
-		SET status = ?, date_updated = ?
-		WHERE sid = ?
-	`, status, time.Now(), sid)
-	return err
+	return s.withTx(func(tx *sql.Tx) error {
+		changes := map[string]any{"status": status}
+		var err error
+		if duration != nil {
+			_, err = tx.Exec(`
+				UPDATE twilio_calls
+				SET status = ?, date_updated = ?, duration = ?
+				WHERE sid = ?
+			`, status, time.Now(), *duration, sid)
+			changes["duration"] = *duration
+		} else {
+			_, err = tx.Exec(`
+				UPDATE twilio_calls
+				SET status = ?, date_updated = ?
+				WHERE sid = ?
+			`, status, time.Now(), sid)
+		}
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "call", sid, core.AuditUpdate, "", changes)
+	})
 }
 
 func (s *TwilioStore) ListCalls(accountSid string, limit int) ([]Call, error) {
@@ -472,6 +678,7 @@ type PhoneNumber struct {
 	SmsMethod            string
 	StatusCallback       string
 	StatusCallbackMethod string
+	WebhookDelayMs       int
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 }
@@ -482,11 +689,16 @@ func (s *TwilioStore) CreatePhoneNumber(accountSid, phoneNumber, friendlyName st
 		return nil, err
 	}
 
-	_, err = s.db.Exec(`
-		INSERT INTO twilio_phone_numbers (sid, account_sid, phone_number, friendly_name)
-		VALUES (?, ?, ?, ?)
-	`, sid, accountSid, phoneNumber, friendlyName)
-
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO twilio_phone_numbers (sid, account_sid, phone_number, friendly_name)
+			VALUES (?, ?, ?, ?)
+		`, sid, accountSid, phoneNumber, friendlyName)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "phone_number", sid, core.AuditCreate, accountSid, map[string]any{"phone_number": phoneNumber, "friendly_name": friendlyName})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -501,14 +713,14 @@ func (s *TwilioStore) GetPhoneNumber(sid string) (*PhoneNumber, error) {
 	err := s.db.QueryRow(`
 		SELECT sid, account_sid, phone_number, friendly_name, voice_url, voice_method,
 		       sms_url, sms_method, status_callback, status_callback_method,
-		       created_at, updated_at
+		       webhook_delay_ms, created_at, updated_at
 		FROM twilio_phone_numbers
 		WHERE sid = ?
 	`, sid).Scan(
 		&pn.Sid, &pn.AccountSid, &pn.PhoneNumber, &friendlyName,
 		&voiceURL, &voiceMethod, &smsURL, &smsMethod,
 		&statusCallback, &statusCallbackMethod,
-		&pn.CreatedAt, &pn.UpdatedAt,
+		&pn.WebhookDelayMs, &pn.CreatedAt, &pn.UpdatedAt,
 	)
 
 	if err != nil {
@@ -540,11 +752,18 @@ func (s *TwilioStore) GetPhoneNumber(sid string) (*PhoneNumber, error) {
 	return &pn, nil
 }
 
+// PhoneNumberInUse reports whether any account has provisioned a phone number
+func (s *TwilioStore) PhoneNumberInUse(phoneNumber string) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM twilio_phone_numbers WHERE phone_number = ?`, phoneNumber).Scan(&count)
+	return count > 0, err
+}
+
 func (s *TwilioStore) ListPhoneNumbers(accountSid string) ([]PhoneNumber, error) {
 	rows, err := s.db.Query(`
 		SELECT sid, account_sid, phone_number, friendly_name, voice_url, voice_method,
 		       sms_url, sms_method, status_callback, status_callback_method,
-		       created_at, updated_at
+		       webhook_delay_ms, created_at, updated_at
 		FROM twilio_phone_numbers
 		WHERE account_sid = ?
 		ORDER BY created_at DESC
@@ -563,7 +782,7 @@ func (s *TwilioStore) ListPhoneNumbers(accountSid string) ([]PhoneNumber, error)
 			&pn.Sid, &pn.AccountSid, &pn.PhoneNumber, &friendlyName,
 			&voiceURL, &voiceMethod, &smsURL, &smsMethod,
 			&statusCallback, &statusCallbackMethod,
-			&pn.CreatedAt, &pn.UpdatedAt,
+			&pn.WebhookDelayMs, &pn.CreatedAt, &pn.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -598,15 +817,17 @@ func (s *TwilioStore) ListPhoneNumbers(accountSid string) ([]PhoneNumber, error)
 }
 
 type WebhookQueueItem struct {
-	ID          int
-	ResourceSid string
-	WebhookURL  string
-	Payload     string
-	ScheduledAt time.Time
-	DeliveredAt *time.Time
-	Status      string
-	Attempts    int
-	CreatedAt   time.Time
+	ID           int
+	ResourceSid  string
+	WebhookURL   string
+	Payload      string
+	ScheduledAt  time.Time
+	DeliveredAt  *time.Time
+	Status       string
+	Attempts     int
+	StatusCode   int
+	ErrorMessage string
+	CreatedAt    time.Time
 }
 
 func (s *TwilioStore) QueueWebhook(resourceSid, webhookURL, payload string, scheduledAt time.Time) error {
@@ -619,7 +840,8 @@ func (s *TwilioStore) QueueWebhook(resourceSid, webhookURL, payload string, sche
 
 func (s *TwilioStore) GetPendingWebhooks(now time.Time) ([]WebhookQueueItem, error) {
 	rows, err := s.db.Query(`
-		SELECT id, resource_sid, webhook_url, payload, scheduled_at, delivered_at, status, attempts, created_at
+		SELECT id, resource_sid, webhook_url, payload, scheduled_at, delivered_at, status, attempts,
+			COALESCE(status_code, 0), COALESCE(error_message, ''), created_at
 		FROM twilio_webhook_queue
 		WHERE status = 'pending' AND scheduled_at <= ? AND attempts < 3
 		ORDER BY scheduled_at ASC
@@ -636,7 +858,7 @@ func (s *TwilioStore) GetPendingWebhooks(now time.Time) ([]WebhookQueueItem, err
 		var deliveredAt sql.NullTime
 
 		err := rows.Scan(&w.ID, &w.ResourceSid, &w.WebhookURL, &w.Payload,
-			&w.ScheduledAt, &deliveredAt, &w.Status, &w.Attempts, &w.CreatedAt)
+			&w.ScheduledAt, &deliveredAt, &w.Status, &w.Attempts, &w.StatusCode, &w.ErrorMessage, &w.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -651,29 +873,30 @@ func (s *TwilioStore) GetPendingWebhooks(now time.Time) ([]WebhookQueueItem, err
 	return webhooks, nil
 }
 
-func (s *TwilioStore) MarkWebhookDelivered(id int) error {
+// MarkWebhookDelivered records the receiver's response to a delivered webhook
+func (s *TwilioStore) MarkWebhookDelivered(id, statusCode int) error {
 	_, err := s.db.Exec(`
 		UPDATE twilio_webhook_queue
-		SET status = 'delivered', delivered_at = ?
+		SET status = 'delivered', delivered_at = ?, status_code = ?, error_message = NULL
 		WHERE id = ?
-	`, time.Now(), id)
+	`, time.Now(), statusCode, id)
 	return err
 }
 
-func (s *TwilioStore) MarkWebhookFailed(id int) error {
+// MarkWebhookFailed records a failed delivery attempt; statusCode is 0 when no response arrived
+func (s *TwilioStore) MarkWebhookFailed(id, statusCode int, errorMessage string) error {
 	_, err := s.db.Exec(`
 		UPDATE twilio_webhook_queue
-		SET status = 'failed', attempts = attempts + 1
+		SET status = 'failed', attempts = attempts + 1, status_code = ?, error_message = ?
 		WHERE id = ?
-	`, id)
+	`, statusCode, errorMessage, id)
 	return err
 }
 
 // ListAllMessages retrieves messages across all accounts for admin view
 func (s *TwilioStore) ListAllMessages(limit, offset int) ([]Message, error) {
 	rows, err := s.db.Query(`
-		SELECT sid, account_sid, from_number, to_number, body, status, direction,
-		       date_created, date_sent, date_updated, num_segments, price, price_unit
+		SELECT `+messageColumns+`
 		FROM twilio_messages
 		ORDER BY date_created DESC
 		LIMIT ? OFFSET ?
@@ -691,7 +914,7 @@ func (s *TwilioStore) ListAllMessages(limit, offset int) ([]Message, error) {
 		err := rows.Scan(
 			&msg.Sid, &msg.AccountSid, &msg.FromNumber, &msg.ToNumber, &msg.Body,
 			&msg.Status, &msg.Direction, &msg.DateCreated, &dateSent, &msg.DateUpdated,
-			&msg.NumSegments, &msg.Price, &msg.PriceUnit,
+			&msg.NumSegments, &msg.NumMedia, &msg.Price, &msg.PriceUnit,
 		)
 		if err != nil {
 			return nil, err
@@ -843,3 +1066,162 @@ func (s *TwilioStore) ListAllPhoneNumbers(limit, offset int) ([]PhoneNumber, err
 
 	return phoneNumbers, nil
 }
+
+// SetTwiML stores the TwiML a phone number answers calls ("voice") or messages ("sms") with
+func (s *TwilioStore) SetTwiML(phoneNumberSid, kind, twiml string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO twilio_twiml_responses (phone_number_sid, type, twiml)
+			VALUES (?, ?, ?)
+			ON CONFLICT (phone_number_sid, type) DO UPDATE SET twiml = excluded.twiml, updated_at = CURRENT_TIMESTAMP
+		`, phoneNumberSid, kind, twiml)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "phone_number", phoneNumberSid, core.AuditUpdate, "", map[string]any{kind + "_twiml": twiml})
+	})
+}
+
+// SetStatusCallback sets the status callback URL for a phone number and the
+// delay, in milliseconds, before each callback is delivered
+func (s *TwilioStore) SetStatusCallback(phoneNumberSid, statusCallback string, delayMs int) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE twilio_phone_numbers
+			SET status_callback = ?, webhook_delay_ms = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE sid = ?
+		`, statusCallback, delayMs, phoneNumberSid)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "phone_number", phoneNumberSid, core.AuditUpdate, "", map[string]any{"status_callback": statusCallback, "webhook_delay_ms": delayMs})
+	})
+}
+
+// GetTwiMLForNumber returns the TwiML configured for a phone number, or
+// sql.ErrNoRows if the number has none of that type
+func (s *TwilioStore) GetTwiMLForNumber(phoneNumber, kind string) (string, error) {
+	var twiml string
+	err := s.db.QueryRow(`
+		SELECT t.twiml
+		FROM twilio_twiml_responses t
+		JOIN twilio_phone_numbers pn ON pn.sid = t.phone_number_sid
+		WHERE pn.phone_number = ? AND t.type = ?
+	`, phoneNumber, kind).Scan(&twiml)
+	return twiml, err
+}
+
+// Flow is a Studio flow: a JSON definition of widgets and the transitions between them
+type Flow struct {
+	Sid          string
+	AccountSid   string
+	FriendlyName string
+	Status       string
+	Definition   string
+	Revision     int
+	DateCreated  time.Time
+	DateUpdated  time.Time
+}
+
+func (s *TwilioStore) CreateFlow(accountSid, friendlyName, status, definition string) (*Flow, error) {
+	sid, err := generateSID("FW")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO twilio_flows (sid, account_sid, friendly_name, status, definition)
+			VALUES (?, ?, ?, ?, ?)
+		`, sid, accountSid, friendlyName, status, definition)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "flow", sid, core.AuditCreate, accountSid, map[string]any{"friendly_name": friendlyName, "status": status})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetFlow(sid)
+}
+
+func (s *TwilioStore) GetFlow(sid string) (*Flow, error) {
+	var flow Flow
+	err := s.db.QueryRow(`
+		SELECT sid, account_sid, friendly_name, status, definition, revision, date_created, date_updated
+		FROM twilio_flows
+		WHERE sid = ?
+	`, sid).Scan(&flow.Sid, &flow.AccountSid, &flow.FriendlyName, &flow.Status, &flow.Definition,
+		&flow.Revision, &flow.DateCreated, &flow.DateUpdated)
+	if err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// FlowExecution is one run of a Studio flow for a contact. Context holds the
+// JSON flow, contact, trigger and widget variables the run produced.
+type FlowExecution struct {
+	Sid                   string
+	AccountSid            string
+	FlowSid               string
+	ContactChannelAddress string
+	Status                string
+	Context               string
+	DateCreated           time.Time
+	DateUpdated           time.Time
+}
+
+func (s *TwilioStore) CreateFlowExecution(accountSid, flowSid, contactAddress, status, context string) (*FlowExecution, error) {
+	sid, err := generateSID("FN")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO twilio_flow_executions (sid, account_sid, flow_sid, contact_channel_address, status, context)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, sid, accountSid, flowSid, contactAddress, status, context)
+		if err != nil {
+			return err
+		}
+		return auditIn(tx, "flow_execution", sid, core.AuditCreate, accountSid, map[string]any{"flow_sid": flowSid, "status": status})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetFlowExecution(flowSid, sid)
+}
+
+func (s *TwilioStore) GetFlowExecution(flowSid, sid string) (*FlowExecution, error) {
+	var exec FlowExecution
+	err := s.db.QueryRow(`
+		SELECT sid, account_sid, flow_sid, contact_channel_address, status, context, date_created, date_updated
+		FROM twilio_flow_executions
+		WHERE flow_sid = ? AND sid = ?
+	`, flowSid, sid).Scan(&exec.Sid, &exec.AccountSid, &exec.FlowSid, &exec.ContactChannelAddress,
+		&exec.Status, &exec.Context, &exec.DateCreated, &exec.DateUpdated)
+	if err != nil {
+		return nil, err
+	}
+	return &exec, nil
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *TwilioStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db,
+		"twilio_accounts",
+		"twilio_phone_numbers",
+		"twilio_messages",
+		"twilio_message_media",
+		"twilio_calls",
+		"twilio_twiml_responses",
+		"twilio_webhook_configs",
+		"twilio_webhook_queue",
+		"twilio_flows",
+		"twilio_flow_executions",
+	)
+}
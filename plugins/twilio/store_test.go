@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/2389/ish/internal/migrations"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -15,6 +16,12 @@ func setupTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
+	// Every connection to :memory: is a separate database, so the workers
+	// must share the one the tables were created on
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
 	return db
 }
 
@@ -35,6 +42,7 @@ func TestNewTwilioStore(t *testing.T) {
 		"twilio_accounts",
 		"twilio_phone_numbers",
 		"twilio_messages",
+		"twilio_message_media",
 		"twilio_calls",
 		"twilio_webhook_configs",
 		"twilio_webhook_queue",
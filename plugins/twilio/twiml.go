@@ -0,0 +1,121 @@
+// ABOUTME: TwiML endpoints for simulated Twilio call and message flows
+// ABOUTME: Serves per-number TwiML to voice_url/sms_url requests and lets the admin configure it
+
+package twilio
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultTwiML is what a number answers with when no TwiML is configured
+var defaultTwiML = map[string]string{
+	"voice": "<Response><Say>Hello from ISH</Say></Response>",
+	"sms":   "<Response><Message>Hello from ISH</Message></Response>",
+}
+
+// serveTwiML handles POST /twiml/voice and /twiml/sms. Point a number's
+// voice_url or sms_url here; Twilio's request names the number called or
+// texted in To, and the response is that number's configured TwiML.
+func (p *TwilioPlugin) serveTwiML(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			writeError(w, http.StatusBadRequest, 21602, "Invalid form body")
+			return
+		}
+
+		twiml, err := p.store.GetTwiMLForNumber(r.FormValue("To"), kind)
+		if errors.Is(err, sql.ErrNoRows) {
+			twiml = defaultTwiML[kind]
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		if !strings.HasPrefix(strings.TrimSpace(twiml), "<?xml") {
+			io.WriteString(w, xml.Header)
+		}
+		io.WriteString(w, twiml)
+	}
+}
+
+// configureTwiML handles POST /admin/twilio/twiml/{phone_number_sid}, taking
+// form fields type ("voice" or "sms", default voice) and twiml
+func (p *TwilioPlugin) configureTwiML(w http.ResponseWriter, r *http.Request) {
+	sid := chi.URLParam(r, "phone_number_sid")
+	if _, err := p.store.GetPhoneNumber(sid); err != nil {
+		writeError(w, http.StatusNotFound, 20404, "Phone number not found")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, 21602, "Invalid form body")
+		return
+	}
+	kind := r.FormValue("type")
+	if kind == "" {
+		kind = "voice"
+	}
+	if _, ok := defaultTwiML[kind]; !ok {
+		writeError(w, http.StatusBadRequest, 21602, "Type must be voice or sms")
+		return
+	}
+	twiml := strings.TrimSpace(r.FormValue("twiml"))
+	if err := validateTwiML(twiml); err != nil {
+		writeError(w, http.StatusBadRequest, 12100, "Document parse failure: "+err.Error())
+		return
+	}
+
+	if err := p.store.SetTwiML(sid, kind, twiml); err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{
+		"phone_number_sid": sid,
+		"type":             kind,
+		"twiml":            twiml,
+	}); err != nil {
+		log.Printf("Twilio: Failed to encode TwiML response: %v", err)
+	}
+}
+
+// validateTwiML checks that a document is well-formed XML with a <Response> root
+func validateTwiML(twiml string) error {
+	if twiml == "" {
+		return errors.New("twiml is required")
+	}
+	var root struct {
+		XMLName xml.Name
+	}
+	dec := xml.NewDecoder(strings.NewReader(twiml))
+	if err := dec.Decode(&root); err != nil {
+		return err
+	}
+	if root.XMLName.Local != "Response" {
+		return errors.New("root element must be <Response>")
+	}
+	// Anything after the root element other than whitespace is malformed
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if data, ok := tok.(xml.CharData); !ok || len(strings.TrimSpace(string(data))) > 0 {
+			return errors.New("unexpected content after </Response>")
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// ABOUTME: Tests for TwiML endpoints
+// ABOUTME: Parses the XML served for calls and messages, and checks admin configuration per number
+
+package twilio
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// twimlResponse is the subset of TwiML these tests read back
+type twimlResponse struct {
+	XMLName xml.Name  `xml:"Response"`
+	Say     []string  `xml:"Say"`
+	Message []string  `xml:"Message"`
+	Play    []string  `xml:"Play"`
+	Hangup  *struct{} `xml:"Hangup"`
+}
+
+func setupTwiMLRouter(t *testing.T) (*TwilioStore, chi.Router) {
+	t.Helper()
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	store, err := NewTwilioStore(db)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	plugin := &TwilioPlugin{store: store}
+
+	r := chi.NewRouter()
+	plugin.RegisterRoutes(r)
+	return store, r
+}
+
+func postForm(r http.Handler, path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func parseTwiML(t *testing.T, w *httptest.ResponseRecorder) twimlResponse {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml" {
+		t.Errorf("Expected application/xml, got %q", ct)
+	}
+	if !strings.HasPrefix(w.Body.String(), "<?xml") {
+		t.Errorf("Expected an XML declaration, got %q", w.Body.String())
+	}
+	var resp twimlResponse
+	if err := xml.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Response is not TwiML: %v\n%s", err, w.Body.String())
+	}
+	return resp
+}
+
+func TestTwiMLDefaults(t *testing.T) {
+	_, r := setupTwiMLRouter(t)
+
+	voice := parseTwiML(t, postForm(r, "/twiml/voice", url.Values{"To": {"+15550001111"}, "From": {"+15552223333"}}))
+	if len(voice.Say) != 1 || voice.Say[0] != "Hello from ISH" {
+		t.Errorf("Expected default <Say>Hello from ISH</Say>, got %+v", voice)
+	}
+
+	sms := parseTwiML(t, postForm(r, "/twiml/sms", url.Values{"To": {"+15550001111"}, "Body": {"hi"}}))
+	if len(sms.Message) != 1 || sms.Message[0] != "Hello from ISH" {
+		t.Errorf("Expected default <Message>Hello from ISH</Message>, got %+v", sms)
+	}
+}
+
+func TestTwiMLConfiguredPerNumber(t *testing.T) {
+	store, r := setupTwiMLRouter(t)
+	store.GetOrCreateAccount("AC123")
+	pn, err := store.CreatePhoneNumber("AC123", "+15550001111", "Support line")
+	if err != nil {
+		t.Fatalf("Failed to create phone number: %v", err)
+	}
+
+	voiceTwiML := `<Response><Play>https://example.com/hold.mp3</Play><Hangup/></Response>`
+	w := postForm(r, "/admin/twilio/twiml/"+pn.Sid, url.Values{"twiml": {voiceTwiML}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 configuring voice TwiML, got %d: %s", w.Code, w.Body.String())
+	}
+	w = postForm(r, "/admin/twilio/twiml/"+pn.Sid, url.Values{"type": {"sms"}, "twiml": {`<Response><Message>Thanks, we'll text back</Message></Response>`}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 configuring SMS TwiML, got %d: %s", w.Code, w.Body.String())
+	}
+
+	voice := parseTwiML(t, postForm(r, "/twiml/voice", url.Values{"To": {"+15550001111"}}))
+	if len(voice.Play) != 1 || voice.Hangup == nil || len(voice.Say) != 0 {
+		t.Errorf("Expected the configured <Play> and <Hangup>, got %+v", voice)
+	}
+	sms := parseTwiML(t, postForm(r, "/twiml/sms", url.Values{"To": {"+15550001111"}}))
+	if len(sms.Message) != 1 || sms.Message[0] != "Thanks, we'll text back" {
+		t.Errorf("Expected the configured SMS reply, got %+v", sms)
+	}
+
+	// Other numbers keep the default
+	other := parseTwiML(t, postForm(r, "/twiml/voice", url.Values{"To": {"+15559998888"}}))
+	if len(other.Say) != 1 {
+		t.Errorf("Expected the default for an unconfigured number, got %+v", other)
+	}
+
+	// Reconfiguring replaces the previous TwiML
+	postForm(r, "/admin/twilio/twiml/"+pn.Sid, url.Values{"twiml": {`<Response><Say>Updated</Say></Response>`}})
+	voice = parseTwiML(t, postForm(r, "/twiml/voice", url.Values{"To": {"+15550001111"}}))
+	if len(voice.Say) != 1 || voice.Say[0] != "Updated" || len(voice.Play) != 0 {
+		t.Errorf("Expected the updated TwiML, got %+v", voice)
+	}
+}
+
+func TestConfigureTwiMLValidation(t *testing.T) {
+	store, r := setupTwiMLRouter(t)
+	store.GetOrCreateAccount("AC123")
+	pn, _ := store.CreatePhoneNumber("AC123", "+15550001111", "")
+
+	tests := []struct {
+		name     string
+		path     string
+		form     url.Values
+		wantCode int
+	}{
+		{"unknown number", "/admin/twilio/twiml/PNmissing", url.Values{"twiml": {"<Response/>"}}, http.StatusNotFound},
+		{"bad type", "/admin/twilio/twiml/" + pn.Sid, url.Values{"type": {"fax"}, "twiml": {"<Response/>"}}, http.StatusBadRequest},
+		{"missing twiml", "/admin/twilio/twiml/" + pn.Sid, url.Values{}, http.StatusBadRequest},
+		{"malformed xml", "/admin/twilio/twiml/" + pn.Sid, url.Values{"twiml": {"<Response><Say>hi</Response>"}}, http.StatusBadRequest},
+		{"wrong root", "/admin/twilio/twiml/" + pn.Sid, url.Values{"twiml": {"<Say>hi</Say>"}}, http.StatusBadRequest},
+		{"trailing content", "/admin/twilio/twiml/" + pn.Sid, url.Values{"twiml": {"<Response/><Say>hi</Say>"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := postForm(r, tt.path, tt.form); w.Code != tt.wantCode {
+				t.Errorf("Expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
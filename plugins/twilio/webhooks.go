@@ -5,13 +5,18 @@ package twilio
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
 )
 
 // isPrivateIP checks if a hostname is a private or internal address
@@ -105,27 +110,76 @@ func (p *TwilioPlugin) deliverWebhook(webhook WebhookQueueItem) {
 	values, err := url.ParseQuery(webhook.Payload)
 	if err != nil {
 		log.Printf("Error parsing webhook payload: %v", err)
-		p.store.MarkWebhookFailed(webhook.ID)
+		p.store.MarkWebhookFailed(webhook.ID, 0, err.Error())
 		return
 	}
 
-	// Send POST request
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.PostForm(webhook.WebhookURL, values)
+	// Send POST request with the shared delivery timeout
+	resp, err := core.WebhookClient().PostForm(webhook.WebhookURL, values)
 	if err != nil {
 		log.Printf("Error delivering webhook to %s: %v", webhook.WebhookURL, err)
-		p.store.MarkWebhookFailed(webhook.ID)
+		errorMessage := err.Error()
+		if core.IsTimeout(err) {
+			errorMessage = core.WebhookTimeoutError
+		}
+		p.store.MarkWebhookFailed(webhook.ID, 0, errorMessage)
 		return
 	}
 	defer resp.Body.Close()
 
 	// Mark as delivered
-	if err := p.store.MarkWebhookDelivered(webhook.ID); err != nil {
+	if err := p.store.MarkWebhookDelivered(webhook.ID, resp.StatusCode); err != nil {
 		log.Printf("Error marking webhook delivered: %v", err)
 	}
 }
 
-// QueueMessageWebhook schedules a webhook for a message status change
+// configureStatusCallback handles POST /admin/twilio/status_callback/{phone_number_sid},
+// taking form fields status_callback and delay_ms. The delay holds back every
+// status callback for the number so receivers' retry and backoff handling can
+// be exercised; it defaults to 0.
+func (p *TwilioPlugin) configureStatusCallback(w http.ResponseWriter, r *http.Request) {
+	sid := chi.URLParam(r, "phone_number_sid")
+	if _, err := p.store.GetPhoneNumber(sid); err != nil {
+		writeError(w, http.StatusNotFound, 20404, "Phone number not found")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, 21602, "Invalid form body")
+		return
+	}
+	statusCallback := strings.TrimSpace(r.FormValue("status_callback"))
+	if err := validateWebhookURL(statusCallback); err != nil {
+		writeError(w, http.StatusBadRequest, 21609, "Invalid StatusCallback: "+err.Error())
+		return
+	}
+	delayMs := 0
+	if raw := r.FormValue("delay_ms"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, 21602, "delay_ms must be a non-negative integer")
+			return
+		}
+		delayMs = parsed
+	}
+
+	if err := p.store.SetStatusCallback(sid, statusCallback, delayMs); err != nil {
+		writeError(w, http.StatusInternalServerError, 20005, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"phone_number_sid": sid,
+		"status_callback":  statusCallback,
+		"delay_ms":         delayMs,
+	}); err != nil {
+		log.Printf("Twilio: Failed to encode status callback response: %v", err)
+	}
+}
+
+// QueueMessageWebhook schedules a webhook for a message status change, adding
+// the sending number's configured delivery delay
 func (p *TwilioPlugin) QueueMessageWebhook(messageSid, status string, delay time.Duration) error {
 	msg, err := p.store.GetMessage(messageSid)
 	if err != nil {
@@ -142,6 +196,7 @@ func (p *TwilioPlugin) QueueMessageWebhook(messageSid, status string, delay time
 	for _, pn := range phoneNumbers {
 		if pn.PhoneNumber == msg.FromNumber && pn.StatusCallback != "" {
 			webhookURL = pn.StatusCallback
+			delay += time.Duration(pn.WebhookDelayMs) * time.Millisecond
 			break
 		}
 	}
@@ -168,7 +223,8 @@ func (p *TwilioPlugin) QueueMessageWebhook(messageSid, status string, delay time
 	return p.store.QueueWebhook(messageSid, webhookURL, payload.Encode(), time.Now().Add(delay))
 }
 
-// QueueCallWebhook schedules a webhook for a call status change
+// QueueCallWebhook schedules a webhook for a call status change, adding the
+// calling number's configured delivery delay
 func (p *TwilioPlugin) QueueCallWebhook(callSid, status string, delay time.Duration) error {
 	call, err := p.store.GetCall(callSid)
 	if err != nil {
@@ -184,6 +240,7 @@ func (p *TwilioPlugin) QueueCallWebhook(callSid, status string, delay time.Durat
 	for _, pn := range phoneNumbers {
 		if pn.PhoneNumber == call.FromNumber && pn.StatusCallback != "" {
 			webhookURL = pn.StatusCallback
+			delay += time.Duration(pn.WebhookDelayMs) * time.Millisecond
 			break
 		}
 	}
@@ -211,21 +268,6 @@ func (p *TwilioPlugin) QueueCallWebhook(callSid, status string, delay time.Durat
 	return p.store.QueueWebhook(callSid, webhookURL, payload.Encode(), time.Now().Add(delay))
 }
 
-// SimulateMessageLifecycle progresses a message through realistic status transitions
-func (p *TwilioPlugin) SimulateMessageLifecycle(messageSid string) {
-	// queued → sent (100ms)
-	time.AfterFunc(100*time.Millisecond, func() {
-		p.store.UpdateMessageStatus(messageSid, "sent")
-		p.QueueMessageWebhook(messageSid, "sent", 0)
-	})
-
-	// sent → delivered (500ms)
-	time.AfterFunc(600*time.Millisecond, func() {
-		p.store.UpdateMessageStatus(messageSid, "delivered")
-		p.QueueMessageWebhook(messageSid, "delivered", 0)
-	})
-}
-
 // SimulateCallLifecycle progresses a call through realistic status transitions
 func (p *TwilioPlugin) SimulateCallLifecycle(callSid string) {
 	// initiated → ringing (200ms)
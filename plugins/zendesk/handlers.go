@@ -0,0 +1,526 @@
+// ABOUTME: HTTP handlers for Zendesk Support API endpoints
+// ABOUTME: Implements ticket CRUD with page paging, ticket comments, and the users list
+
+package zendesk
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"html"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 100
+)
+
+// statuses lists ticket statuses in workflow order, which search comparisons
+// like status<solved follow
+var statuses = []string{"new", "open", "pending", "hold", "solved", "closed"}
+
+var priorities = []string{"low", "normal", "high", "urgent"}
+
+var ticketTypes = []string{"problem", "incident", "question", "task"}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// baseURL returns the scheme, host, and base path used for "url" fields
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + core.BasePath(r)
+}
+
+// nullableID renders an optional reference as null when unset
+func nullableID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullableString renders an optional enum as null when unset
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func ticketResponse(r *http.Request, t *Ticket) map[string]any {
+	return map[string]any{
+		"id":           t.ID,
+		"url":          baseURL(r) + "/api/v2/tickets/" + strconv.FormatInt(t.ID, 10) + ".json",
+		"subject":      t.Subject,
+		"raw_subject":  t.Subject,
+		"description":  t.Description,
+		"status":       t.Status,
+		"priority":     nullableString(t.Priority),
+		"type":         nullableString(t.Type),
+		"requester_id": t.RequesterID,
+		"submitter_id": t.SubmitterID,
+		"assignee_id":  nullableID(t.AssigneeID),
+		"tags":         []string{},
+		"via":          map[string]string{"channel": "api"},
+		"created_at":   formatTime(t.CreatedAt),
+		"updated_at":   formatTime(t.UpdatedAt),
+	}
+}
+
+func commentResponse(c *Comment) map[string]any {
+	return map[string]any{
+		"id":          c.ID,
+		"type":        "Comment",
+		"author_id":   c.AuthorID,
+		"body":        c.Body,
+		"html_body":   `<div class="zd-comment" dir="auto"><p>` + html.EscapeString(c.Body) + `</p></div>`,
+		"plain_body":  c.Body,
+		"public":      c.Public,
+		"attachments": []any{},
+		"created_at":  formatTime(c.CreatedAt),
+	}
+}
+
+func userResponse(r *http.Request, u *User) map[string]any {
+	return map[string]any{
+		"id":         u.ID,
+		"url":        baseURL(r) + "/api/v2/users/" + strconv.FormatInt(u.ID, 10) + ".json",
+		"name":       u.Name,
+		"email":      u.Email,
+		"role":       u.Role,
+		"active":     true,
+		"verified":   true,
+		"created_at": formatTime(u.CreatedAt),
+		"updated_at": formatTime(u.UpdatedAt),
+	}
+}
+
+// pagination holds the page and per_page query parameters of a list request
+type pagination struct {
+	Page    int
+	PerPage int
+}
+
+func (pg pagination) offset() int {
+	return (pg.Page - 1) * pg.PerPage
+}
+
+// paginationFromRequest parses page and per_page, writing a 400 for values
+// out of range
+func paginationFromRequest(w http.ResponseWriter, r *http.Request) (pagination, bool) {
+	pg := pagination{Page: 1, PerPage: defaultPageSize}
+	query := r.URL.Query()
+	if v := query.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "InvalidPaginationParameter", "description": "page must be a positive integer"})
+			return pg, false
+		}
+		pg.Page = n
+	}
+	if v := query.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "InvalidPaginationParameter", "description": "per_page must be between 1 and 100"})
+			return pg, false
+		}
+		pg.PerPage = n
+	}
+	return pg, true
+}
+
+// addPageLinks sets next_page, previous_page, and count on a list response
+// from the endpoint at path. The links repeat the request's query with the
+// page changed.
+func addPageLinks(resp map[string]any, r *http.Request, path string, pg pagination, total int) {
+	link := func(page int) any {
+		query := url.Values{}
+		for k, v := range r.URL.Query() {
+			query[k] = v
+		}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(pg.PerPage))
+		return baseURL(r) + path + ".json?" + query.Encode()
+	}
+	resp["next_page"], resp["previous_page"] = nil, nil
+	if pg.offset()+pg.PerPage < total {
+		resp["next_page"] = link(pg.Page + 1)
+	}
+	if pg.Page > 1 {
+		resp["previous_page"] = link(pg.Page - 1)
+	}
+	resp["count"] = total
+}
+
+// ticketIDFromRequest parses the ticket_id URL parameter, writing a 404 for
+// IDs that can't exist
+func ticketIDFromRequest(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ticket_id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeNotFound(w)
+		return 0, false
+	}
+	return id, true
+}
+
+type commentInput struct {
+	Body     string `json:"body"`
+	Public   *bool  `json:"public"`
+	AuthorID *int64 `json:"author_id"`
+}
+
+type requesterInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// ticketInput is the "ticket" object of a create or update request. Absent
+// fields are left unchanged; assignee_id may be null to unassign.
+type ticketInput struct {
+	Subject     *string         `json:"subject"`
+	Description *string         `json:"description"`
+	Comment     *commentInput   `json:"comment"`
+	Status      *string         `json:"status"`
+	Priority    *string         `json:"priority"`
+	Type        *string         `json:"type"`
+	RequesterID *int64          `json:"requester_id"`
+	Requester   *requesterInput `json:"requester"`
+	AssigneeID  json.RawMessage `json:"assignee_id"`
+}
+
+// decodeTicket reads a {"ticket": {...}} request body, writing a 400 when it
+// isn't one
+func decodeTicket(w http.ResponseWriter, r *http.Request) (*ticketInput, bool) {
+	var body struct {
+		Ticket *ticketInput `json:"ticket"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Ticket == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Unprocessable Entity", "description": "Expected a ticket object"})
+		return nil, false
+	}
+	return body.Ticket, true
+}
+
+// applyTicketInput validates in and copies it onto t, writing a 422 and
+// returning false on the first invalid field. It returns the changed fields.
+func (p *ZendeskPlugin) applyTicketInput(w http.ResponseWriter, r *http.Request, in *ticketInput, t *Ticket) (map[string]any, bool) {
+	changes := map[string]any{}
+	if in.Subject != nil {
+		t.Subject = *in.Subject
+		changes["subject"] = t.Subject
+	}
+	if in.Status != nil {
+		if !slices.Contains(statuses, *in.Status) {
+			writeInvalid(w, "status", "Status: is not valid")
+			return nil, false
+		}
+		t.Status = *in.Status
+		changes["status"] = t.Status
+	}
+	if in.Priority != nil {
+		if *in.Priority != "" && !slices.Contains(priorities, *in.Priority) {
+			writeInvalid(w, "priority", "Priority: is not valid")
+			return nil, false
+		}
+		t.Priority = *in.Priority
+		changes["priority"] = t.Priority
+	}
+	if in.Type != nil {
+		if *in.Type != "" && !slices.Contains(ticketTypes, *in.Type) {
+			writeInvalid(w, "type", "Type: is not valid")
+			return nil, false
+		}
+		t.Type = *in.Type
+		changes["type"] = t.Type
+	}
+
+	if in.Requester != nil {
+		requester, err := p.store.GetUserByEmail(in.Requester.Email)
+		if errors.Is(err, sql.ErrNoRows) && strings.Contains(in.Requester.Email, "@") {
+			name := in.Requester.Name
+			if name == "" {
+				name, _, _ = strings.Cut(in.Requester.Email, "@")
+			}
+			requester, err = p.store.CreateUser(name, in.Requester.Email, "end-user", userFromContext(r.Context()).Email)
+		}
+		if err != nil {
+			writeInvalid(w, "requester", "Requester: is invalid")
+			return nil, false
+		}
+		t.RequesterID = requester.ID
+		changes["requester_id"] = t.RequesterID
+	} else if in.RequesterID != nil {
+		if _, err := p.store.GetUser(*in.RequesterID); err != nil {
+			writeInvalid(w, "requester", "Requester: is invalid")
+			return nil, false
+		}
+		t.RequesterID = *in.RequesterID
+		changes["requester_id"] = t.RequesterID
+	}
+
+	if len(in.AssigneeID) > 0 {
+		var assigneeID *int64
+		if err := json.Unmarshal(in.AssigneeID, &assigneeID); err != nil {
+			writeInvalid(w, "assignee", "Assignee: is invalid")
+			return nil, false
+		}
+		if assigneeID == nil || *assigneeID == 0 {
+			t.AssigneeID = 0
+		} else {
+			assignee, err := p.store.GetUser(*assigneeID)
+			if err != nil || assignee.Role == "end-user" {
+				writeInvalid(w, "assignee", "Assignee: is not an agent")
+				return nil, false
+			}
+			t.AssigneeID = assignee.ID
+		}
+		changes["assignee_id"] = nullableID(t.AssigneeID)
+	}
+	return changes, true
+}
+
+func (p *ZendeskPlugin) listTickets(w http.ResponseWriter, r *http.Request) {
+	pg, ok := paginationFromRequest(w, r)
+	if !ok {
+		return
+	}
+	total, err := p.store.CountTickets(TicketFilter{})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	tickets, err := p.store.ListTickets(TicketFilter{}, pg.PerPage, pg.offset())
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	results := make([]map[string]any, 0, len(tickets))
+	for i := range tickets {
+		results = append(results, ticketResponse(r, &tickets[i]))
+	}
+	resp := map[string]any{"tickets": results}
+	addPageLinks(resp, r, "/api/v2/tickets", pg, total)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (p *ZendeskPlugin) createTicket(w http.ResponseWriter, r *http.Request) {
+	in, ok := decodeTicket(w, r)
+	if !ok {
+		return
+	}
+	user := userFromContext(r.Context())
+
+	t := &Ticket{RequesterID: user.ID, SubmitterID: user.ID}
+	changes, ok := p.applyTicketInput(w, r, in, t)
+	if !ok {
+		return
+	}
+	// The description is the first comment's body
+	switch {
+	case in.Comment != nil && in.Comment.Body != "":
+		t.Description = in.Comment.Body
+	case in.Description != nil:
+		t.Description = *in.Description
+	}
+	if strings.TrimSpace(t.Description) == "" {
+		writeInvalid(w, "base", "Description: cannot be blank")
+		return
+	}
+	if _, set := changes["status"]; !set {
+		t.Status = "new"
+		if t.AssigneeID != 0 {
+			t.Status = "open"
+		}
+	}
+
+	created, err := p.store.CreateTicket(t, userFromContext(r.Context()).Email)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Location", baseURL(r)+"/api/v2/tickets/"+strconv.FormatInt(created.ID, 10)+".json")
+	writeJSON(w, http.StatusCreated, map[string]any{"ticket": ticketResponse(r, created)})
+}
+
+func (p *ZendeskPlugin) getTicket(w http.ResponseWriter, r *http.Request) {
+	id, ok := ticketIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	t, err := p.store.GetTicket(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ticket": ticketResponse(r, t)})
+}
+
+func (p *ZendeskPlugin) updateTicket(w http.ResponseWriter, r *http.Request) {
+	id, ok := ticketIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	t, err := p.store.GetTicket(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	in, ok := decodeTicket(w, r)
+	if !ok {
+		return
+	}
+	if t.Status == "closed" {
+		writeInvalid(w, "status", "Status: closed prevents ticket update")
+		return
+	}
+
+	changes, ok := p.applyTicketInput(w, r, in, t)
+	if !ok {
+		return
+	}
+	// Assigning a new ticket opens it unless the update sets a status
+	if _, set := changes["status"]; !set && t.Status == "new" && t.AssigneeID != 0 {
+		t.Status = "open"
+		changes["status"] = t.Status
+	}
+	if err := p.store.UpdateTicket(t, changes, userFromContext(r.Context()).Email); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if in.Comment != nil && strings.TrimSpace(in.Comment.Body) != "" {
+		if _, ok := p.addComment(w, r, t, in.Comment); !ok {
+			return
+		}
+		if t, err = p.store.GetTicket(id); err != nil {
+			writeStoreError(w, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ticket": ticketResponse(r, t)})
+}
+
+// addComment validates and stores a comment on t, writing an error and
+// returning false if it can't be added. Comments default to public and to
+// being authored by the acting user.
+func (p *ZendeskPlugin) addComment(w http.ResponseWriter, r *http.Request, t *Ticket, in *commentInput) (*Comment, bool) {
+	if strings.TrimSpace(in.Body) == "" {
+		writeInvalid(w, "body", "Body: cannot be blank")
+		return nil, false
+	}
+	authorID := userFromContext(r.Context()).ID
+	if in.AuthorID != nil {
+		if _, err := p.store.GetUser(*in.AuthorID); err != nil {
+			writeInvalid(w, "author_id", "Author: is invalid")
+			return nil, false
+		}
+		authorID = *in.AuthorID
+	}
+	public := true
+	if in.Public != nil {
+		public = *in.Public
+	}
+
+	c, err := p.store.AddComment(t.ID, in.Body, authorID, public, userFromContext(r.Context()).Email)
+	if err != nil {
+		writeStoreError(w, err)
+		return nil, false
+	}
+	return c, true
+}
+
+func (p *ZendeskPlugin) listComments(w http.ResponseWriter, r *http.Request) {
+	id, ok := ticketIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if _, err := p.store.GetTicket(id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	comments, err := p.store.ListComments(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	results := make([]map[string]any, 0, len(comments))
+	for i := range comments {
+		results = append(results, commentResponse(&comments[i]))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"comments":      results,
+		"next_page":     nil,
+		"previous_page": nil,
+		"count":         len(results),
+	})
+}
+
+func (p *ZendeskPlugin) createComment(w http.ResponseWriter, r *http.Request) {
+	id, ok := ticketIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	t, err := p.store.GetTicket(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	var body struct {
+		Comment *commentInput `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Comment == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Unprocessable Entity", "description": "Expected a comment object"})
+		return
+	}
+	if t.Status == "closed" {
+		writeInvalid(w, "status", "Status: closed prevents ticket update")
+		return
+	}
+
+	c, ok := p.addComment(w, r, t, body.Comment)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"comment": commentResponse(c)})
+}
+
+func (p *ZendeskPlugin) listUsers(w http.ResponseWriter, r *http.Request) {
+	pg, ok := paginationFromRequest(w, r)
+	if !ok {
+		return
+	}
+	total, err := p.store.CountUsers()
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	users, err := p.store.ListUsers(pg.PerPage, pg.offset())
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	results := make([]map[string]any, 0, len(users))
+	for i := range users {
+		results = append(results, userResponse(r, &users[i]))
+	}
+	resp := map[string]any{"users": results}
+	addPageLinks(resp, r, "/api/v2/users", pg, total)
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,327 @@
+// ABOUTME: Tests for Zendesk Support API handlers
+// ABOUTME: Covers API token auth, ticket CRUD and validation, auditing, comments, paging, users, and search
+
+package zendesk
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/2389/ish/internal/migrations"
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestPlugin(t *testing.T) (*ZendeskPlugin, chi.Router) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := migrations.Apply(db, migrations.All); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	plugin := &ZendeskPlugin{}
+	if err := plugin.SetDB(db); err != nil {
+		t.Fatalf("Failed to initialize plugin: %v", err)
+	}
+	if _, err := plugin.Seed(context.Background(), "small"); err != nil {
+		t.Fatalf("Failed to seed: %v", err)
+	}
+
+	r := chi.NewRouter()
+	plugin.RegisterAuth(r)
+	plugin.RegisterRoutes(r)
+	return plugin, r
+}
+
+// doRequest sends a request as the seeded agent Alan
+func doRequest(t *testing.T, r chi.Router, method, target string, body any, wantStatus int) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("Failed to encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, target, &buf)
+	req.SetBasicAuth("alan@support.example/token", "zd-api-token")
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != wantStatus {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, target, wantStatus, rr.Code, rr.Body.String())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestAuth(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	for name, setAuth := range map[string]func(*http.Request){
+		"none":            func(*http.Request) {},
+		"password":        func(req *http.Request) { req.SetBasicAuth("alan@support.example", "hunter2") },
+		"bearer":          func(req *http.Request) { req.Header.Set("Authorization", "Bearer abc") },
+		"token, no email": func(req *http.Request) { req.SetBasicAuth("alan/token", "abc") },
+	} {
+		req := httptest.NewRequest("GET", "/api/v2/tickets", nil)
+		setAuth(req)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized || !strings.Contains(rr.Body.String(), "Couldn't authenticate you") {
+			t.Errorf("%s: expected 401, got %d: %s", name, rr.Code, rr.Body.String())
+		}
+	}
+
+	// A new email becomes an agent on first use and submits its own tickets
+	req := httptest.NewRequest("POST", "/api/v2/tickets", strings.NewReader(`{"ticket": {"subject": "Hi", "comment": {"body": "Hello"}}}`))
+	req.SetBasicAuth("new.agent@support.example/token", "abc")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for a new agent, got %d: %s", rr.Code, rr.Body.String())
+	}
+	users := doRequest(t, r, "GET", "/api/v2/users", nil, http.StatusOK)["users"].([]any)
+	newest := users[len(users)-1].(map[string]any)
+	if newest["email"] != "new.agent@support.example" || newest["role"] != "agent" {
+		t.Errorf("expected the new agent to be listed last, got %v", newest)
+	}
+}
+
+func TestTicketCRUD(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	created := doRequest(t, r, "POST", "/api/v2/tickets.json", map[string]any{
+		"ticket": map[string]any{
+			"subject":   "Printer on fire",
+			"comment":   map[string]any{"body": "The printer on floor 3 is on fire."},
+			"priority":  "urgent",
+			"type":      "incident",
+			"requester": map[string]any{"name": "Mary Jackson", "email": "mary@jacksonlabs.example"},
+		},
+	}, http.StatusCreated)["ticket"].(map[string]any)
+	id := jsonID(created["id"])
+	if created["status"] != "new" || created["description"] != "The printer on floor 3 is on fire." || created["assignee_id"] != nil {
+		t.Errorf("unexpected created ticket: %v", created)
+	}
+	if !strings.HasSuffix(created["url"].(string), "/api/v2/tickets/"+id+".json") {
+		t.Errorf("unexpected url: %v", created["url"])
+	}
+
+	got := doRequest(t, r, "GET", "/api/v2/tickets/"+id, nil, http.StatusOK)["ticket"].(map[string]any)
+	if got["subject"] != "Printer on fire" || got["priority"] != "urgent" || got["type"] != "incident" {
+		t.Errorf("unexpected ticket: %v", got)
+	}
+	requesterID := got["requester_id"]
+
+	// Assigning a new ticket opens it; an update comment joins the thread
+	updated := doRequest(t, r, "PUT", "/api/v2/tickets/"+id+".json", map[string]any{
+		"ticket": map[string]any{"assignee_id": 2, "comment": map[string]any{"body": "Extinguisher deployed.", "public": false}},
+	}, http.StatusOK)["ticket"].(map[string]any)
+	if updated["status"] != "open" || jsonID(updated["assignee_id"]) != "2" || updated["requester_id"] != requesterID {
+		t.Errorf("unexpected updated ticket: %v", updated)
+	}
+
+	doRequest(t, r, "PUT", "/api/v2/tickets/"+id, map[string]any{"ticket": map[string]any{"status": "escalated"}}, http.StatusUnprocessableEntity)
+	doRequest(t, r, "PUT", "/api/v2/tickets/"+id, map[string]any{"ticket": map[string]any{"priority": "critical"}}, http.StatusUnprocessableEntity)
+	// Customers can't be assigned tickets
+	doRequest(t, r, "PUT", "/api/v2/tickets/"+id, map[string]any{"ticket": map[string]any{"assignee_id": 3}}, http.StatusUnprocessableEntity)
+
+	updated = doRequest(t, r, "PUT", "/api/v2/tickets/"+id, map[string]any{
+		"ticket": map[string]any{"status": "closed", "assignee_id": nil},
+	}, http.StatusOK)["ticket"].(map[string]any)
+	if updated["status"] != "closed" || updated["assignee_id"] != nil {
+		t.Errorf("expected a closed, unassigned ticket, got %v", updated)
+	}
+	resp := doRequest(t, r, "PUT", "/api/v2/tickets/"+id, map[string]any{"ticket": map[string]any{"status": "open"}}, http.StatusUnprocessableEntity)
+	if resp["error"] != "RecordInvalid" {
+		t.Errorf("expected RecordInvalid for updating a closed ticket, got %v", resp)
+	}
+
+	doRequest(t, r, "POST", "/api/v2/tickets", map[string]any{"ticket": map[string]any{"subject": "No description"}}, http.StatusUnprocessableEntity)
+	resp = doRequest(t, r, "GET", "/api/v2/tickets/9999", nil, http.StatusNotFound)
+	if resp["error"] != "RecordNotFound" {
+		t.Errorf("unexpected 404 body: %v", resp)
+	}
+}
+
+func TestTicketWritesAreAudited(t *testing.T) {
+	p, r := setupTestPlugin(t)
+
+	created := doRequest(t, r, "POST", "/api/v2/tickets.json", map[string]any{
+		"ticket": map[string]any{"subject": "Audit me", "comment": map[string]any{"body": "Please."}},
+	}, http.StatusCreated)["ticket"].(map[string]any)
+	entries, err := core.ListAudit(p.store.db, core.AuditQuery{PluginName: "zendesk", ResourceType: "ticket", Limit: 1})
+	if err != nil {
+		t.Fatalf("ListAudit failed: %v", err)
+	}
+	if len(entries) == 0 || entries[0].ResourceID != jsonID(created["id"]) || entries[0].Actor != "alan@support.example" {
+		t.Errorf("expected a ticket entry by alan@support.example, got %+v", entries)
+	}
+
+	// A write whose audit fails is rolled back with it
+	var before, after int
+	p.store.db.QueryRow(`SELECT COUNT(*) FROM zendesk_tickets`).Scan(&before)
+	if _, err := p.store.db.Exec(`DROP TABLE audit_log`); err != nil {
+		t.Fatalf("Failed to drop audit_log: %v", err)
+	}
+	doRequest(t, r, "POST", "/api/v2/tickets.json", map[string]any{
+		"ticket": map[string]any{"subject": "Unaudited", "comment": map[string]any{"body": "Nope."}},
+	}, http.StatusInternalServerError)
+	p.store.db.QueryRow(`SELECT COUNT(*) FROM zendesk_tickets`).Scan(&after)
+	if after != before {
+		t.Errorf("expected the ticket insert to roll back, had %d tickets and now %d", before, after)
+	}
+}
+
+func TestComments(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	// Ticket 1 is the seeded new ticket, with only its description comment
+	created := doRequest(t, r, "POST", "/api/v2/tickets/1/comments", map[string]any{
+		"comment": map[string]any{"body": "Internal: checking the auth logs", "public": false},
+	}, http.StatusCreated)["comment"].(map[string]any)
+	if created["public"] != false || jsonID(created["author_id"]) != "2" {
+		t.Errorf("expected a private comment by Alan, got %v", created)
+	}
+	doRequest(t, r, "POST", "/api/v2/tickets/1/comments", map[string]any{
+		"comment": map[string]any{"body": "Could you try clearing your cookies?", "author_id": 1},
+	}, http.StatusCreated)
+
+	comments := doRequest(t, r, "GET", "/api/v2/tickets/1/comments", nil, http.StatusOK)["comments"].([]any)
+	if len(comments) != 3 {
+		t.Fatalf("expected 3 comments, got %d", len(comments))
+	}
+	first, last := comments[0].(map[string]any), comments[2].(map[string]any)
+	if !strings.HasPrefix(first["body"].(string), "I reset my password") || first["public"] != true {
+		t.Errorf("expected the description as the first public comment, got %v", first)
+	}
+	if last["public"] != true || jsonID(last["author_id"]) != "1" {
+		t.Errorf("expected a public comment by Grace, got %v", last)
+	}
+
+	doRequest(t, r, "POST", "/api/v2/tickets/1/comments", map[string]any{"comment": map[string]any{"body": " "}}, http.StatusUnprocessableEntity)
+	doRequest(t, r, "POST", "/api/v2/tickets/1/comments", map[string]any{"comment": map[string]any{"body": "Hi", "author_id": 999}}, http.StatusUnprocessableEntity)
+	// Ticket 5 is closed
+	doRequest(t, r, "POST", "/api/v2/tickets/5/comments", map[string]any{"comment": map[string]any{"body": "Reopen?"}}, http.StatusUnprocessableEntity)
+	doRequest(t, r, "POST", "/api/v2/tickets/999/comments", map[string]any{"comment": map[string]any{"body": "Hi"}}, http.StatusNotFound)
+}
+
+func TestListPaging(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	resp := doRequest(t, r, "GET", "/api/v2/tickets.json?per_page=2", nil, http.StatusOK)
+	tickets := resp["tickets"].([]any)
+	if len(tickets) != 2 || resp["count"] != float64(5) || resp["previous_page"] != nil {
+		t.Fatalf("unexpected first page: %v", resp)
+	}
+	next, _ := resp["next_page"].(string)
+	if !strings.Contains(next, "/api/v2/tickets.json?") || !strings.Contains(next, "page=2") {
+		t.Fatalf("unexpected next_page: %v", resp["next_page"])
+	}
+
+	resp = doRequest(t, r, "GET", "/api/v2/tickets?per_page=2&page=3", nil, http.StatusOK)
+	tickets = resp["tickets"].([]any)
+	if len(tickets) != 1 || resp["next_page"] != nil || resp["previous_page"] == nil {
+		t.Errorf("unexpected last page: %v", resp)
+	}
+	if status := tickets[0].(map[string]any)["status"]; status != "closed" {
+		t.Errorf("expected the closed ticket last, got %v", status)
+	}
+
+	doRequest(t, r, "GET", "/api/v2/tickets?per_page=101", nil, http.StatusBadRequest)
+
+	users := doRequest(t, r, "GET", "/api/v2/users.json", nil, http.StatusOK)
+	if users["count"] != float64(5) || len(users["users"].([]any)) != 5 {
+		t.Errorf("expected the 5 seeded users, got %v", users)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	_, r := setupTestPlugin(t)
+
+	search := func(query string) []map[string]any {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/v2/search.json", nil)
+		q := req.URL.Query()
+		q.Set("query", query)
+		req.URL.RawQuery = q.Encode()
+		req.SetBasicAuth("alan@support.example/token", "zd-api-token")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("search %q: expected 200, got %d: %s", query, rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Results []map[string]any `json:"results"`
+			Count   int              `json:"count"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		if resp.Count != len(resp.Results) {
+			t.Errorf("search %q: count %d for %d results", query, resp.Count, len(resp.Results))
+		}
+		return resp.Results
+	}
+	subjects := func(results []map[string]any) []string {
+		var s []string
+		for _, result := range results {
+			s = append(s, result["subject"].(string))
+		}
+		return s
+	}
+
+	results := search("type:ticket status:open")
+	if len(results) != 1 || results[0]["result_type"] != "ticket" || results[0]["subject"] != "Invoice shows the wrong billing address" {
+		t.Errorf("status:open: unexpected results %v", subjects(results))
+	}
+	if results := search("type:ticket status<solved"); len(results) != 3 {
+		t.Errorf("status<solved: expected new, open, and pending tickets, got %v", subjects(results))
+	}
+	if results := search("type:ticket status:solved status:closed"); len(results) != 2 {
+		t.Errorf("repeated status: expected 2 tickets, got %v", subjects(results))
+	}
+	if results := search("type:ticket assignee:none"); len(results) != 1 || results[0]["status"] != "new" {
+		t.Errorf("assignee:none: unexpected results %v", subjects(results))
+	}
+	if results := search("type:ticket assignee:me"); len(results) != 2 {
+		t.Errorf("assignee:me: expected Alan's 2 tickets, got %v", subjects(results))
+	}
+	if results := search("requester:ada@analyticalengines.example priority:low"); len(results) != 1 || results[0]["subject"] != "How do I add a teammate?" {
+		t.Errorf("requester and priority: unexpected results %v", subjects(results))
+	}
+	if results := search(`"billing address"`); len(results) != 1 {
+		t.Errorf("phrase: unexpected results %v", subjects(results))
+	}
+	if results := search("type:user analyticalengines"); len(results) != 2 || results[0]["result_type"] != "user" {
+		t.Errorf("type:user: unexpected results %v", results)
+	}
+	if results := search("type:ticket status:escalated"); len(results) != 0 {
+		t.Errorf("unknown status: expected no results, got %v", subjects(results))
+	}
+
+	doRequest(t, r, "GET", "/api/v2/search", nil, http.StatusBadRequest)
+}
+
+// jsonID renders a decoded JSON number as an ID string
+func jsonID(v any) string {
+	n, ok := v.(float64)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,48 @@
+// ABOUTME: core.Inspector implementation for the Zendesk plugin
+// ABOUTME: Renders a ticket with its comments, or a user
+
+package zendesk
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+// Inspect implements core.Inspector for `ish inspect`
+func (p *ZendeskPlugin) Inspect(resource, id string) (interface{}, error) {
+	recordID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+	r := core.InspectRequest()
+
+	switch resource {
+	case "tickets":
+		t, err := p.store.GetTicket(recordID)
+		if err != nil {
+			return nil, err
+		}
+		comments, err := p.store.ListComments(recordID)
+		if err != nil {
+			return nil, err
+		}
+		resp := ticketResponse(r, t)
+		rendered := make([]map[string]any, 0, len(comments))
+		for i := range comments {
+			rendered = append(rendered, commentResponse(&comments[i]))
+		}
+		resp["comments"] = rendered
+		return resp, nil
+	case "users":
+		u, err := p.store.GetUser(recordID)
+		if err != nil {
+			return nil, err
+		}
+		return userResponse(r, u), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
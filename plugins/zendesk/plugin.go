@@ -0,0 +1,262 @@
+// ABOUTME: Zendesk Support API plugin for ISH
+// ABOUTME: Simulates tickets, ticket comments, users, and search, authenticated by email/token Basic auth
+
+package zendesk
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/2389/ish/plugins/core"
+	"github.com/go-chi/chi/v5"
+)
+
+func init() {
+	core.Register(&ZendeskPlugin{})
+}
+
+type contextKey string
+
+const userKey contextKey = "zendesk_user"
+
+type ZendeskPlugin struct {
+	store *ZendeskStore
+}
+
+func (p *ZendeskPlugin) Name() string {
+	return "zendesk"
+}
+
+func (p *ZendeskPlugin) Health() core.HealthStatus {
+	return core.HealthStatus{
+		Status:  "healthy",
+		Message: "Zendesk plugin operational",
+	}
+}
+
+func (p *ZendeskPlugin) RegisterRoutes(r chi.Router) {
+	r.Route("/api/v2", func(r chi.Router) {
+		r.Use(stripJSONSuffix)
+
+		r.Get("/tickets", p.requireAuth(p.listTickets))
+		r.Post("/tickets", p.requireAuth(p.createTicket))
+		r.Get("/tickets/{ticket_id}", p.requireAuth(p.getTicket))
+		r.Put("/tickets/{ticket_id}", p.requireAuth(p.updateTicket))
+		r.Get("/tickets/{ticket_id}/comments", p.requireAuth(p.listComments))
+		r.Post("/tickets/{ticket_id}/comments", p.requireAuth(p.createComment))
+
+		r.Get("/users", p.requireAuth(p.listUsers))
+		r.Get("/search", p.requireAuth(p.search))
+	})
+}
+
+func (p *ZendeskPlugin) RegisterAuth(r chi.Router) {
+	// Zendesk API tokens are sent as Basic auth, checked per request
+}
+
+// stripJSONSuffix routes /api/v2/tickets.json the same as /api/v2/tickets,
+// since Zendesk clients usually include the extension
+func stripJSONSuffix(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			rctx.RoutePath = strings.TrimSuffix(rctx.RoutePath, ".json")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseAPIToken extracts the email from Basic credentials in Zendesk's
+// "{email}/token:{api_token}" form
+func parseAPIToken(authHeader string) (email string, ok bool) {
+	encoded, ok := strings.CutPrefix(authHeader, "Basic ")
+	if !ok {
+		return "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	username, token, ok := strings.Cut(string(decoded), ":")
+	if !ok || token == "" {
+		return "", false
+	}
+	email, ok = strings.CutSuffix(username, "/token")
+	if !ok || !strings.Contains(email, "@") {
+		return "", false
+	}
+	return email, true
+}
+
+// requireAuth accepts any API token for a well-formed email. The email
+// becomes the acting user, and an agent is created for it on first use.
+func (p *ZendeskPlugin) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		email, ok := parseAPIToken(r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Web Password"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Couldn't authenticate you"})
+			return
+		}
+
+		user, err := p.store.GetUserByEmail(email)
+		if errors.Is(err, sql.ErrNoRows) {
+			name, _, _ := strings.Cut(email, "@")
+			// Agents are provisioned on first sign-in, so they create themselves
+			user, err = p.store.CreateUser(name, email, "agent", email)
+		}
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// userFromContext returns the authenticated Zendesk user
+func userFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(userKey).(*User)
+	return user
+}
+
+func (p *ZendeskPlugin) ValidateToken(token string) bool {
+	_, ok := parseAPIToken("Basic " + token)
+	return ok
+}
+
+func (p *ZendeskPlugin) SetDB(db *sql.DB) error {
+	store, err := NewZendeskStore(db)
+	if err != nil {
+		return err
+	}
+	p.store = store
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Zendesk: Failed to encode response: %v", err)
+	}
+}
+
+// writeNotFound writes Zendesk's RecordNotFound error
+func writeNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "RecordNotFound", "description": "Not found"})
+}
+
+// writeInvalid writes a RecordInvalid error with one message per field
+func writeInvalid(w http.ResponseWriter, field, description string) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"error":       "RecordInvalid",
+		"description": "Record validation errors",
+		"details": map[string]any{
+			field: []map[string]string{{"description": description, "error": "InvalidValue"}},
+		},
+	})
+}
+
+// writeStoreError writes a 404 for missing records and a 500 otherwise
+func writeStoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		writeNotFound(w)
+		return
+	}
+	log.Printf("Zendesk: %v", err)
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "InternalError", "description": "internal error"})
+}
+
+// ListResources implements core.DataProvider to expose data to admin UI
+func (p *ZendeskPlugin) ListResources(ctx context.Context, slug string, opts core.ListOptions) ([]map[string]interface{}, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	switch slug {
+	case "tickets":
+		tickets, err := p.store.ListTickets(TicketFilter{}, limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(tickets))
+		for i := range tickets {
+			result = append(result, convertTicketToMap(&tickets[i]))
+		}
+		return result, nil
+	case "users":
+		users, err := p.store.ListUsers(limit, opts.Offset)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(users))
+		for i := range users {
+			result = append(result, convertUserToMap(&users[i]))
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+// GetResource implements core.DataProvider to fetch individual resources
+func (p *ZendeskPlugin) GetResource(ctx context.Context, slug string, id string) (map[string]interface{}, error) {
+	recordID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+	switch slug {
+	case "tickets":
+		t, err := p.store.GetTicket(recordID)
+		if err != nil {
+			return nil, err
+		}
+		return convertTicketToMap(t), nil
+	case "users":
+		u, err := p.store.GetUser(recordID)
+		if err != nil {
+			return nil, err
+		}
+		return convertUserToMap(u), nil
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", slug)
+	}
+}
+
+func convertTicketToMap(t *Ticket) map[string]interface{} {
+	assignee := ""
+	if t.AssigneeID != 0 {
+		assignee = strconv.FormatInt(t.AssigneeID, 10)
+	}
+	return map[string]interface{}{
+		"id":           strconv.FormatInt(t.ID, 10),
+		"subject":      t.Subject,
+		"description":  t.Description,
+		"status":       t.Status,
+		"priority":     t.Priority,
+		"type":         t.Type,
+		"requester_id": strconv.FormatInt(t.RequesterID, 10),
+		"assignee_id":  assignee,
+		"created_at":   formatTime(t.CreatedAt),
+		"updated_at":   formatTime(t.UpdatedAt),
+	}
+}
+
+func convertUserToMap(u *User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         strconv.FormatInt(u.ID, 10),
+		"name":       u.Name,
+		"email":      u.Email,
+		"role":       u.Role,
+		"created_at": formatTime(u.CreatedAt),
+	}
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Admin UI schema definitions for Zendesk plugin
+// ABOUTME: Defines Tickets and Users resources for schema-driven UI
+
+package zendesk
+
+import (
+	"database/sql"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+func (p *ZendeskPlugin) Schema() core.PluginSchema {
+	return core.PluginSchema{
+		Resources: []core.ResourceSchema{
+			{
+				Name:        "Tickets",
+				Slug:        "tickets",
+				ListColumns: []string{"subject", "status", "priority", "type", "updated_at"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "subject", Type: "string", Display: "Subject", Required: false, Editable: false},
+					{Name: "description", Type: "text", Display: "Description", Required: false, Editable: false},
+					{Name: "status", Type: "string", Display: "Status", Required: false, Editable: false},
+					{Name: "priority", Type: "string", Display: "Priority", Required: false, Editable: false},
+					{Name: "type", Type: "string", Display: "Type", Required: false, Editable: false},
+					{Name: "requester_id", Type: "string", Display: "Requester", Required: false, Editable: false},
+					{Name: "assignee_id", Type: "string", Display: "Assignee", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+					{Name: "updated_at", Type: "datetime", Display: "Updated", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+			{
+				Name:        "Users",
+				Slug:        "users",
+				ListColumns: []string{"name", "email", "role"},
+				Fields: []core.FieldSchema{
+					{Name: "id", Type: "string", Display: "ID", Required: true, Editable: false},
+					{Name: "name", Type: "string", Display: "Name", Required: false, Editable: false},
+					{Name: "email", Type: "email", Display: "Email", Required: false, Editable: false},
+					{Name: "role", Type: "string", Display: "Role", Required: false, Editable: false},
+					{Name: "created_at", Type: "datetime", Display: "Created", Required: false, Editable: false},
+				},
+				Actions: []core.ActionSchema{},
+			},
+		},
+	}
+}
+
+// CountedTables implements core.CountedPlugin for admin nav badges
+func (p *ZendeskPlugin) CountedTables() []core.CountedTable {
+	return []core.CountedTable{
+		{Key: "zendesk", Table: "zendesk_tickets", Resource: "tickets"},
+	}
+}
+
+// TruncateGroups implements core.Truncatable for admin bulk delete
+func (p *ZendeskPlugin) TruncateGroups() map[string][]string {
+	return map[string][]string{
+		"zendesk": {"zendesk_comments", "zendesk_tickets", "zendesk_users"},
+	}
+}
+
+// Stats implements core.StatsProvider with live row counts from the store
+func (p *ZendeskPlugin) Stats() (map[string]int, error) {
+	if p.store == nil {
+		return map[string]int{}, nil
+	}
+	return p.store.Stats()
+}
+
+// Truncate implements core.Truncatable
+func (p *ZendeskPlugin) Truncate(db *sql.DB) error {
+	return core.TruncateTables(db, core.GroupTables(p.TruncateGroups())...)
+}
@@ -0,0 +1,201 @@
+// ABOUTME: Zendesk search endpoint for ISH
+// ABOUTME: Parses the search query language's keyword filters and plain words into ticket and user queries
+
+package zendesk
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// searchQuery is a parsed search query. Types is empty when the query doesn't
+// narrow results to tickets or users.
+type searchQuery struct {
+	Types   []string
+	Tickets TicketFilter
+	Words   []string
+}
+
+// splitSearchQuery splits on spaces outside double quotes, dropping the quotes
+func splitSearchQuery(query string) []string {
+	var terms []string
+	var term strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+// cutKeyword splits a "keyword:value" term, also accepting the <, >, <=, and
+// >= comparisons Zendesk allows on ordered fields
+func cutKeyword(term string) (keyword, op, value string, ok bool) {
+	i := strings.IndexAny(term, ":<>")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	keyword, op, value = term[:i], term[i:i+1], term[i+1:]
+	if op != ":" && strings.HasPrefix(value, "=") {
+		op, value = op+"=", value[1:]
+	}
+	return strings.ToLower(keyword), op, value, value != ""
+}
+
+// compareStatuses returns the statuses that satisfy "status op value"
+func compareStatuses(op, value string) []string {
+	i := slices.Index(statuses, value)
+	if i < 0 {
+		// Nothing matches an unknown status
+		return []string{""}
+	}
+	switch op {
+	case "<":
+		return statuses[:i]
+	case "<=":
+		return statuses[:i+1]
+	case ">":
+		return statuses[i+1:]
+	case ">=":
+		return statuses[i:]
+	default:
+		return []string{value}
+	}
+}
+
+// resolveUser finds the user a query value names: "me", an ID, or an email.
+// It returns -1 when no user matches, so the filter matches nothing.
+func (p *ZendeskPlugin) resolveUser(r *http.Request, value string) (int64, error) {
+	if value == "me" {
+		return userFromContext(r.Context()).ID, nil
+	}
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return id, nil
+	}
+	user, err := p.store.GetUserByEmail(value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+// parseSearchQuery turns a query such as "type:ticket status:open printer"
+// into filters. Repeating a keyword matches any of its values.
+func (p *ZendeskPlugin) parseSearchQuery(r *http.Request, query string) (*searchQuery, error) {
+	q := &searchQuery{}
+	for _, term := range splitSearchQuery(query) {
+		keyword, op, value, ok := cutKeyword(term)
+		if !ok {
+			q.Words = append(q.Words, term)
+			continue
+		}
+		value = strings.ToLower(value)
+		switch keyword {
+		case "type":
+			q.Types = append(q.Types, value)
+		case "status":
+			q.Tickets.Statuses = append(q.Tickets.Statuses, compareStatuses(op, value)...)
+		case "priority":
+			q.Tickets.Priority = value
+		case "ticket_type":
+			q.Tickets.Type = value
+		case "assignee":
+			if value == "none" {
+				q.Tickets.Unassigned = true
+				continue
+			}
+			id, err := p.resolveUser(r, value)
+			if err != nil {
+				return nil, err
+			}
+			q.Tickets.AssigneeID = id
+		case "requester":
+			id, err := p.resolveUser(r, value)
+			if err != nil {
+				return nil, err
+			}
+			q.Tickets.RequesterID = id
+		default:
+			q.Words = append(q.Words, term)
+		}
+	}
+	q.Tickets.Text = q.Words
+	return q, nil
+}
+
+func (q *searchQuery) includes(resultType string) bool {
+	return len(q.Types) == 0 || slices.Contains(q.Types, resultType)
+}
+
+func (p *ZendeskPlugin) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("query"))
+	if query == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid", "description": "query parameter is required"})
+		return
+	}
+	pg, ok := paginationFromRequest(w, r)
+	if !ok {
+		return
+	}
+	q, err := p.parseSearchQuery(r, query)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	results := []map[string]any{}
+	if q.includes("ticket") {
+		tickets, err := p.store.ListTickets(q.Tickets, -1, 0)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		for i := range tickets {
+			item := ticketResponse(r, &tickets[i])
+			item["result_type"] = "ticket"
+			results = append(results, item)
+		}
+	}
+	// Users have none of the ticket fields, so ticket keywords rule them out
+	ticketOnly := len(q.Tickets.Statuses) > 0 || q.Tickets.Priority != "" || q.Tickets.Type != "" ||
+		q.Tickets.AssigneeID != 0 || q.Tickets.Unassigned || q.Tickets.RequesterID != 0
+	if q.includes("user") && !ticketOnly {
+		users, err := p.store.SearchUsers(q.Words)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		for i := range users {
+			item := userResponse(r, &users[i])
+			item["result_type"] = "user"
+			results = append(results, item)
+		}
+	}
+
+	total := len(results)
+	start := min(pg.offset(), total)
+	end := min(start+pg.PerPage, total)
+	resp := map[string]any{"results": results[start:end], "facets": nil}
+	addPageLinks(resp, r, "/api/v2/search", pg, total)
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,123 @@
+// ABOUTME: Test data generation for Zendesk plugin
+// ABOUTME: Creates agents, customers, and five tickets spread across the ticket workflow with comment threads
+
+package zendesk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+var seedUsers = []struct {
+	name, email, role string
+}{
+	{"Grace Hopper", "grace@support.example", "admin"},
+	{"Alan Turing", "alan@support.example", "agent"},
+	{"Ada Lovelace", "ada@analyticalengines.example", "end-user"},
+	{"Katherine Johnson", "katherine@orbitalmath.example", "end-user"},
+	{"Charles Babbage", "charles@analyticalengines.example", "end-user"},
+}
+
+type seedComment struct {
+	author int
+	body   string
+	public bool
+}
+
+// Users are indexes into seedUsers; an assignee of -1 leaves the ticket unassigned
+var seedTickets = []struct {
+	subject, description, status, priority, ticketType string
+	requester, assignee                                int
+	comments                                           []seedComment
+}{
+	{
+		subject: "Can't log in after password reset", status: "new", priority: "high", ticketType: "problem",
+		description: "I reset my password this morning and now the login page says my credentials are invalid.",
+		requester:   2, assignee: -1,
+	},
+	{
+		subject: "Invoice shows the wrong billing address", status: "open", priority: "normal", ticketType: "question",
+		description: "Our latest invoice still has our old office address. Can you update it to 1 Orbit Way, Hampton VA?",
+		requester:   3, assignee: 1,
+		comments: []seedComment{
+			{1, "Thanks Katherine, I've asked billing to reissue the invoice with the new address.", true},
+			{1, "Billing says the address change needs a signed form first.", false},
+		},
+	},
+	{
+		subject: "Export to CSV times out", status: "pending", priority: "urgent", ticketType: "incident",
+		description: "Exporting more than about 10,000 rows to CSV fails with a gateway timeout.",
+		requester:   4, assignee: 0,
+		comments: []seedComment{
+			{0, "We've reproduced this. Could you tell us roughly how many rows your largest export has?", true},
+		},
+	},
+	{
+		subject: "How do I add a teammate?", status: "solved", priority: "low", ticketType: "question",
+		description: "Where in settings do I invite a new teammate to our workspace?",
+		requester:   2, assignee: 1,
+		comments: []seedComment{
+			{1, "You can invite teammates from Settings > Members > Invite.", true},
+			{2, "Found it, thank you!", true},
+		},
+	},
+	{
+		subject: "Request for a product demo", status: "closed", priority: "normal", ticketType: "task",
+		description: "We'd like a demo of the reporting features for our operations team.",
+		requester:   3, assignee: 0,
+		comments: []seedComment{
+			{0, "Demo scheduled for Thursday at 10am. Closing this ticket; reply if you need to reschedule.", true},
+		},
+	},
+}
+
+// Seed creates test data for the Zendesk plugin
+func (p *ZendeskPlugin) Seed(ctx context.Context, size string) (core.SeedData, error) {
+	userIDs := make([]int64, 0, len(seedUsers))
+	for _, seed := range seedUsers {
+		user, err := p.store.CreateUser(seed.name, seed.email, seed.role, "")
+		if err != nil {
+			return core.SeedData{}, err
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+
+	comments := 0
+	for _, seed := range seedTickets {
+		ticket := &Ticket{
+			Subject:     seed.subject,
+			Description: seed.description,
+			Status:      seed.status,
+			Priority:    seed.priority,
+			Type:        seed.ticketType,
+			RequesterID: userIDs[seed.requester],
+			SubmitterID: userIDs[seed.requester],
+		}
+		if seed.assignee >= 0 {
+			ticket.AssigneeID = userIDs[seed.assignee]
+		}
+		created, err := p.store.CreateTicket(ticket, "")
+		if err != nil {
+			return core.SeedData{}, err
+		}
+		// The description is stored as the first comment
+		comments++
+		for _, c := range seed.comments {
+			if _, err := p.store.AddComment(created.ID, c.body, userIDs[c.author], c.public, ""); err != nil {
+				return core.SeedData{}, err
+			}
+			comments++
+		}
+	}
+
+	return core.SeedData{
+		Summary: fmt.Sprintf("Created %d users, %d tickets, %d comments", len(seedUsers), len(seedTickets), comments),
+		Records: map[string]int{
+			"users":    len(seedUsers),
+			"tickets":  len(seedTickets),
+			"comments": comments,
+		},
+	}, nil
+}
@@ -0,0 +1,388 @@
+// ABOUTME: Database layer for Zendesk plugin
+// ABOUTME: Stores users, tickets, and ticket comments
+
+package zendesk
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2389/ish/plugins/core"
+)
+
+type User struct {
+	ID        int64
+	Name      string
+	Email     string
+	Role      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Ticket is a support request. Priority and Type are empty when unset, and
+// AssigneeID is zero when nobody is assigned.
+type Ticket struct {
+	ID          int64
+	Subject     string
+	Description string
+	Status      string
+	Priority    string
+	Type        string
+	RequesterID int64
+	SubmitterID int64
+	AssigneeID  int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type Comment struct {
+	ID        int64
+	TicketID  int64
+	Body      string
+	AuthorID  int64
+	Public    bool
+	CreatedAt time.Time
+}
+
+// TicketFilter narrows ListTickets. Zero values match everything.
+type TicketFilter struct {
+	Statuses    []string
+	Priority    string
+	Type        string
+	RequesterID int64
+	AssigneeID  int64
+	// Unassigned matches only tickets without an assignee
+	Unassigned bool
+	// Text matches tickets whose subject or description contains every word
+	Text []string
+}
+
+type ZendeskStore struct {
+	db *sql.DB
+}
+
+// auditIn records a mutation of a Zendesk record in the shared audit log
+// through db, the transaction making the change so both commit together.
+// actor is the email of the user making the change.
+func auditIn(db core.Execer, resourceType string, id int64, action, actor string, changes any) error {
+	return core.LogAudit(db, core.AuditEntry{
+		PluginName:   "zendesk",
+		ResourceType: resourceType,
+		ResourceID:   strconv.FormatInt(id, 10),
+		Action:       action,
+		Actor:        actor,
+		Changes:      changes,
+	})
+}
+
+func NewZendeskStore(db *sql.DB) (*ZendeskStore, error) {
+	return &ZendeskStore{db: db}, nil
+}
+
+// CreateUser adds a user with the given role on behalf of actor
+func (s *ZendeskStore) CreateUser(name, email, role, actor string) (*User, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := core.Now().UTC()
+	result, err := tx.Exec(`INSERT INTO zendesk_users (name, email, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		name, email, role, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := auditIn(tx, "user", id, core.AuditCreate, actor, map[string]string{"name": name, "email": email, "role": role}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &User{ID: id, Name: name, Email: email, Role: role, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// GetUser fetches a user by ID, returning sql.ErrNoRows if it doesn't exist
+func (s *ZendeskStore) GetUser(id int64) (*User, error) {
+	return scanUser(s.db.QueryRow(`SELECT id, name, email, role, created_at, updated_at FROM zendesk_users WHERE id = ?`, id))
+}
+
+// GetUserByEmail fetches a user by email, ignoring case
+func (s *ZendeskStore) GetUserByEmail(email string) (*User, error) {
+	return scanUser(s.db.QueryRow(`SELECT id, name, email, role, created_at, updated_at FROM zendesk_users WHERE email = ? COLLATE NOCASE`, email))
+}
+
+// ListUsers returns up to limit users in ID order, skipping offset. A
+// negative limit returns them all.
+func (s *ZendeskStore) ListUsers(limit, offset int) ([]User, error) {
+	rows, err := s.db.Query(`SELECT id, name, email, role, created_at, updated_at FROM zendesk_users ORDER BY id LIMIT ? OFFSET ?`,
+		limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
+// SearchUsers returns users whose name or email contains every word
+func (s *ZendeskStore) SearchUsers(words []string) ([]User, error) {
+	query := `SELECT id, name, email, role, created_at, updated_at FROM zendesk_users WHERE 1=1`
+	var args []any
+	for _, word := range words {
+		query += ` AND (name LIKE ? OR email LIKE ?)`
+		pattern := "%" + word + "%"
+		args = append(args, pattern, pattern)
+	}
+	rows, err := s.db.Query(query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers returns the number of users
+func (s *ZendeskStore) CountUsers() (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM zendesk_users`).Scan(&n)
+	return n, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (*User, error) {
+	var u User
+	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// CreateTicket inserts a ticket and its description as the first public
+// comment, authored by the submitter, on behalf of actor
+func (s *ZendeskStore) CreateTicket(t *Ticket, actor string) (*Ticket, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := core.Now().UTC()
+	result, err := tx.Exec(`INSERT INTO zendesk_tickets (subject, description, status, priority, type, requester_id, submitter_id, assignee_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.Subject, t.Description, t.Status, t.Priority, t.Type, t.RequesterID, t.SubmitterID, t.AssigneeID, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ticket: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if t.Description != "" {
+		if _, err := tx.Exec(`INSERT INTO zendesk_comments (ticket_id, body, author_id, public, created_at) VALUES (?, ?, ?, 1, ?)`,
+			id, t.Description, t.SubmitterID, now); err != nil {
+			return nil, fmt.Errorf("failed to create comment: %w", err)
+		}
+	}
+	if err := auditIn(tx, "ticket", id, core.AuditCreate, actor, map[string]any{
+		"subject": t.Subject, "status": t.Status, "requester_id": t.RequesterID,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	created := *t
+	created.ID, created.CreatedAt, created.UpdatedAt = id, now, now
+	return &created, nil
+}
+
+// GetTicket fetches a ticket by ID, returning sql.ErrNoRows if it doesn't exist
+func (s *ZendeskStore) GetTicket(id int64) (*Ticket, error) {
+	return scanTicket(s.db.QueryRow(`SELECT `+ticketColumns+` FROM zendesk_tickets WHERE id = ?`, id))
+}
+
+const ticketColumns = `id, subject, description, status, priority, type, requester_id, submitter_id, assignee_id, created_at, updated_at`
+
+func scanTicket(row rowScanner) (*Ticket, error) {
+	var t Ticket
+	if err := row.Scan(&t.ID, &t.Subject, &t.Description, &t.Status, &t.Priority, &t.Type,
+		&t.RequesterID, &t.SubmitterID, &t.AssigneeID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UpdateTicket saves a ticket's mutable fields and bumps its updated_at on
+// behalf of actor
+func (s *ZendeskStore) UpdateTicket(t *Ticket, changes map[string]any, actor string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t.UpdatedAt = core.Now().UTC()
+	result, err := tx.Exec(`UPDATE zendesk_tickets SET subject = ?, status = ?, priority = ?, type = ?, requester_id = ?, assignee_id = ?, updated_at = ?
+		WHERE id = ?`,
+		t.Subject, t.Status, t.Priority, t.Type, t.RequesterID, t.AssigneeID, t.UpdatedAt, t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update ticket: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if err := auditIn(tx, "ticket", t.ID, core.AuditUpdate, actor, changes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListTickets returns tickets matching filter in ID order. A negative limit
+// returns them all.
+func (s *ZendeskStore) ListTickets(filter TicketFilter, limit, offset int) ([]Ticket, error) {
+	where, args := filter.where()
+	rows, err := s.db.Query(`SELECT `+ticketColumns+` FROM zendesk_tickets WHERE `+where+` ORDER BY id LIMIT ? OFFSET ?`,
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []Ticket
+	for rows.Next() {
+		t, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, *t)
+	}
+	return tickets, rows.Err()
+}
+
+// CountTickets returns the number of tickets matching filter
+func (s *ZendeskStore) CountTickets(filter TicketFilter) (int, error) {
+	where, args := filter.where()
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM zendesk_tickets WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func (f TicketFilter) where() (string, []any) {
+	clauses := []string{"1=1"}
+	var args []any
+	if len(f.Statuses) > 0 {
+		clauses = append(clauses, "status IN (?"+strings.Repeat(", ?", len(f.Statuses)-1)+")")
+		for _, status := range f.Statuses {
+			args = append(args, status)
+		}
+	}
+	if f.Priority != "" {
+		clauses = append(clauses, "priority = ?")
+		args = append(args, f.Priority)
+	}
+	if f.Type != "" {
+		clauses = append(clauses, "type = ?")
+		args = append(args, f.Type)
+	}
+	if f.RequesterID != 0 {
+		clauses = append(clauses, "requester_id = ?")
+		args = append(args, f.RequesterID)
+	}
+	if f.Unassigned {
+		clauses = append(clauses, "assignee_id = 0")
+	} else if f.AssigneeID != 0 {
+		clauses = append(clauses, "assignee_id = ?")
+		args = append(args, f.AssigneeID)
+	}
+	for _, word := range f.Text {
+		clauses = append(clauses, "(subject LIKE ? OR description LIKE ?)")
+		pattern := "%" + word + "%"
+		args = append(args, pattern, pattern)
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// AddComment appends a comment to a ticket and bumps the ticket's updated_at
+// on behalf of actor, who may post it as another author
+func (s *ZendeskStore) AddComment(ticketID int64, body string, authorID int64, public bool, actor string) (*Comment, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := core.Now().UTC()
+	result, err := tx.Exec(`INSERT INTO zendesk_comments (ticket_id, body, author_id, public, created_at) VALUES (?, ?, ?, ?, ?)`,
+		ticketID, body, authorID, public, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE zendesk_tickets SET updated_at = ? WHERE id = ?`, now, ticketID); err != nil {
+		return nil, fmt.Errorf("failed to update ticket: %w", err)
+	}
+	if err := auditIn(tx, "comment", id, core.AuditCreate, actor, map[string]any{"ticket_id": ticketID, "public": public}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &Comment{ID: id, TicketID: ticketID, Body: body, AuthorID: authorID, Public: public, CreatedAt: now}, nil
+}
+
+// ListComments returns a ticket's comments oldest first
+func (s *ZendeskStore) ListComments(ticketID int64) ([]Comment, error) {
+	rows, err := s.db.Query(`SELECT id, ticket_id, body, author_id, public, created_at FROM zendesk_comments WHERE ticket_id = ? ORDER BY id`,
+		ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.TicketID, &c.Body, &c.AuthorID, &c.Public, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// Stats implements core.StatsProvider, counting the rows in each of the plugin's tables
+func (s *ZendeskStore) Stats() (map[string]int, error) {
+	return core.CountTables(s.db, "zendesk_users", "zendesk_tickets", "zendesk_comments")
+}
@@ -72,7 +72,7 @@ func StartTestServer(t *testing.T) *TestServer {
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(logging.Middleware(s))
+	r.Use(logging.Middleware(s, logging.Options{}))
 	r.Use(auth.Middleware)
 
 	// Register all plugins